@@ -2,21 +2,48 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/auth/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/currency"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/mail"
+	coretypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	inboundTypes "github.com/Abdelrahman-habib/expense-tracker/internal/inbound/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/validate"
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Clerk    ClerkConfig
-	Logger   LoggerConfig
-	Cache    CacheConfig
-	Auth     types.Config
+	Server          ServerConfig
+	Database        DatabaseConfig
+	Clerk           ClerkConfig
+	Logger          LoggerConfig
+	Cache           CacheConfig
+	Auth            types.Config
+	Admin           AdminConfig
+	Jobs            JobsConfig
+	Trash           TrashConfig
+	Janitor         JanitorConfig
+	Wallets         WalletsConfig
+	Projects        ProjectsConfig
+	Contacts        ContactsConfig
+	Quotas          QuotasConfig
+	Events          EventsConfig
+	Exports         ExportsConfig
+	Backups         BackupsConfig
+	ExportSchedules ExportSchedulesConfig
+	Mail            MailConfig
+	Currency        CurrencyConfig
+	Features        FeaturesConfig
+	Pagination      PaginationConfig
+	Inbound         InboundConfig
+	Tracing         TracingConfig
 }
 
 type ServerConfig struct {
@@ -25,7 +52,35 @@ type ServerConfig struct {
 	WriteTimeout   time.Duration
 	IdleTimeout    time.Duration
 	RequestTimeout time.Duration
-	Middleware     MiddlewareConfig
+	// RouteTimeouts override RequestTimeout for the routes they match, so slow endpoints
+	// like exports get more time without raising it for every request
+	RouteTimeouts []RouteTimeout
+	// QueryParamsMode is what list and search endpoints do with unknown query parameters,
+	// warn names them in the response meta and strict rejects the request
+	QueryParamsMode coretypes.QueryParamsMode
+	// MaxSearchWindow is how many results deep search pagination may go before
+	// clients have to refine their query, 0 leaves it unbounded
+	MaxSearchWindow int32
+	// SearchTimeout bounds the trigram searches, a query running past it is answered as
+	// too complex rather than holding the connection until the request times out
+	SearchTimeout time.Duration
+	// RejectEmptyUpdates refuses update bodies naming no field, off by default so the
+	// empty object keeps every field as it is
+	RejectEmptyUpdates bool
+	Middleware         MiddlewareConfig
+	Compression        CompressionConfig
+}
+
+// RouteTimeout is the request timeout of the route registered with Pattern, a chi route
+// pattern including the /api/v1 prefix like /api/v1/contacts/{id}
+type RouteTimeout struct {
+	Pattern string
+	Timeout time.Duration
+}
+
+type CompressionConfig struct {
+	// MinSize is the smallest response body in bytes worth compressing
+	MinSize int
 }
 
 type MiddlewareConfig struct {
@@ -37,11 +92,31 @@ type MiddlewareConfig struct {
 	AllowCredentials bool
 	MaxAge           int
 
+	// TrustedProxies lists the CIDRs of proxies allowed to report the client address in X-Forwarded-For
+	TrustedProxies []string
+
 	// Rate limiting configuration
 	RateLimit struct {
 		RequestsPerMinute int
 		WindowLength      time.Duration
 	}
+
+	// MaxInFlight caps the requests served at once across all users, those past it are
+	// answered with 503 instead of queueing for a database connection. 0 sizes it to
+	// InFlightPerConn requests per connection of the pool.
+	MaxInFlight int
+}
+
+// InFlightPerConn is how many requests share a database connection when MaxInFlight
+// isn't set, most of a request's time is spent outside its queries
+const InFlightPerConn = 4
+
+// InFlightLimit returns MaxInFlight, or InFlightPerConn times the pool size when it's not set
+func (m MiddlewareConfig) InFlightLimit(maxConns int32) int {
+	if m.MaxInFlight > 0 {
+		return m.MaxInFlight
+	}
+	return InFlightPerConn * int(maxConns)
 }
 
 type DatabaseConfig struct {
@@ -58,6 +133,12 @@ type DatabaseConfig struct {
 	HealthCheck time.Duration
 	SSLMode     string
 	SearchPath  string
+	// StrictTagOwnership rejects writes carrying tags the user doesn't own
+	// instead of silently dropping them
+	StrictTagOwnership bool
+	// UniqueContactEmails allows at most one live contact per email and user, off by
+	// default since duplicates are sometimes legitimate
+	UniqueContactEmails bool
 }
 
 type ClerkConfig struct {
@@ -70,10 +151,525 @@ type LoggerConfig struct {
 	Level       string
 }
 
+type AdminConfig struct {
+	// UserIDs lists the users allowed to access the admin endpoints
+	UserIDs []string
+}
+
+type JobsConfig struct {
+	// Workers is the number of background workers processing jobs
+	Workers int
+	// ChunkSize is the number of rows written per transaction
+	ChunkSize int
+	// MaxRetries is the number of times a failed chunk is retried
+	MaxRetries   int
+	RetryDelay   time.Duration
+	PollInterval time.Duration
+	// StaleAfter is how long a running job can go without a heartbeat before it's taken
+	// for abandoned by a stopped instance and requeued. A restarted instance picks its own
+	// interrupted jobs up once they turn stale.
+	StaleAfter time.Duration
+}
+
+type InboundConfig struct {
+	// Provider is the format of the inbound parse webhook, sendgrid or mailgun
+	Provider string
+	// Secret verifies the webhook requests, the Mailgun signing key or the basic auth
+	// password of the SendGrid destination URL. Requests are rejected while it's empty.
+	Secret string
+	// MaxBytes caps the size of a posted email with its attachments
+	MaxBytes int64
+	// Domain is the domain the provider receives mail for, users forward receipts to an
+	// address on it whose local part is a token the server hands out. No inbound addresses
+	// are handed out and every email is rejected while it's empty.
+	Domain string
+}
+
+// TracingConfig sets up the OpenTelemetry spans of requests, exported over OTLP/HTTP
+type TracingConfig struct {
+	Enabled bool
+	// Endpoint is the URL of the OTLP/HTTP collector, e.g. http://localhost:4318
+	Endpoint string
+	// SampleRatio is the share of new traces recorded from 0 to 1, requests carrying a
+	// traceparent follow the caller's decision
+	SampleRatio float64
+	ServiceName string
+}
+
+type TrashConfig struct {
+	// Retention is how long deleted items stay in the trash before the janitor purges them
+	Retention time.Duration
+}
+
+// JanitorConfig sets up the background cleanup of rows nobody reads anymore, zero
+// values fall back to the defaults
+type JanitorConfig struct {
+	// Interval is how often the janitor runs
+	Interval time.Duration
+	// BatchSize caps the rows a single delete removes, so no statement holds its locks for long
+	BatchSize int32
+	// SessionRetention is how long expired sessions are kept, 0 removes them once they expire
+	SessionRetention time.Duration
+	// JobRetention is how long completed and failed jobs stay readable
+	JobRetention time.Duration
+}
+
+// WalletsConfig sets how wallet amounts are stored
+type WalletsConfig struct {
+	// Rounding is how balances are rounded to their currency's decimal places before
+	// they are stored, half_up (the default) or half_even (banker's rounding)
+	Rounding validate.RoundingMode
+}
+
+// ProjectsConfig sets which fields a project needs before it goes live, drafts may
+// leave them empty until they are published
+type ProjectsConfig struct {
+	// RequireStartDate makes a start date required to create or publish a live project
+	RequireStartDate bool
+	// RequireBudget makes a budget required to create or publish a live project
+	RequireBudget bool
+}
+
+// ContactsConfig sets how the email addresses of contacts are checked
+type ContactsConfig struct {
+	// VerifyEmailMX refuses addresses whose domain has no mail server, a lookup that
+	// fails or times out accepts the address
+	VerifyEmailMX bool
+	// MXLookupTimeout bounds the MX lookup of a domain
+	MXLookupTimeout time.Duration
+	// EmailTypos are the domain typos suggested a correction for, as typo=domain pairs.
+	// Empty suggests validate.DefaultEmailTypoDomains.
+	EmailTypos []string
+}
+
+// QuotasConfig caps the resources of each user outside the trash, 0 leaves a resource
+// unlimited
+type QuotasConfig struct {
+	MaxContacts int64
+	MaxProjects int64
+	MaxWallets  int64
+}
+
+// EventsConfig sizes the stream of entity changes at /events/stream
+type EventsConfig struct {
+	// BufferSize is how many events a client can fall behind by before it is disconnected
+	BufferSize int
+	// ReplaySize is how many of each user's latest events are kept for Last-Event-ID replays
+	ReplaySize int
+	// Heartbeat is how often an idle stream is sent a comment to keep proxies from closing it
+	Heartbeat time.Duration
+}
+
+// EmailChecker returns the checker of contact email addresses, it fails for a typo that
+// isn't a typo=domain pair
+func (c ContactsConfig) EmailChecker() (*validate.EmailChecker, error) {
+	typos := validate.DefaultEmailTypoDomains
+	if len(c.EmailTypos) > 0 {
+		typos = make(map[string]string, len(c.EmailTypos))
+		for _, pair := range c.EmailTypos {
+			typo, domain, ok := strings.Cut(pair, "=")
+			if !ok || strings.TrimSpace(typo) == "" || strings.TrimSpace(domain) == "" {
+				return nil, fmt.Errorf("invalid contacts.emailTypos entry %q, expected typo=domain", pair)
+			}
+			typos[typo] = domain
+		}
+	}
+
+	var resolver validate.MXResolver
+	if c.VerifyEmailMX {
+		resolver = net.DefaultResolver
+	}
+	return validate.NewEmailChecker(typos, resolver, c.MXLookupTimeout), nil
+}
+
+// ExportsConfig sets which exports are streamed right away and where the files of
+// background exports are kept
+type ExportsConfig struct {
+	// SyncMaxRows is the most rows an export streams in the response, larger ones have
+	// to go through an export job. 0 streams every export.
+	SyncMaxRows int
+	// Dir is the directory of the blob store background exports are written to
+	Dir string
+}
+
+// BackupsConfig sets how long full account backups are kept and how their download links
+// are signed, the archives go to the exports blob store
+type BackupsConfig struct {
+	// Retention is how long a completed backup can be downloaded before the janitor deletes
+	// it, at most janitor.jobRetention so no archive outlives its job
+	Retention time.Duration
+	// LinkTTL is how long a download link works once GET /me/backup/{jobId} hands it out
+	LinkTTL time.Duration
+	// SigningKey signs the download links, empty generates a key on start so the links
+	// stop working when the process restarts
+	SigningKey string
+}
+
+// ExportSchedulesConfig sets up the scheduler delivering scheduled exports, zero values
+// fall back to the defaults
+type ExportSchedulesConfig struct {
+	// Interval is how often the scheduler looks for due schedules
+	Interval time.Duration
+	// Lease is how long a run holds its schedule, a run that hasn't finished by then,
+	// such as one cut short by a crash, is claimed again
+	Lease time.Duration
+	// WebhookTimeout bounds the delivery of an export to a webhook
+	WebhookTimeout time.Duration
+}
+
+// MailConfig sets up the SMTP server the emails of the server are sent through, email
+// delivery is off while Host is empty
+type MailConfig struct {
+	Host     string
+	Port     int
+	Username string
+	// Password is set through MAIL_PASSWORD
+	Password string
+	// From is the address emails are sent from
+	From string
+}
+
+// Mailer returns the mailer of the SMTP server, nil when none is configured
+func (c MailConfig) Mailer() mail.Mailer {
+	if c.Host == "" {
+		return nil
+	}
+	return mail.NewSMTPMailer(c.Host, c.Port, c.Username, c.Password, c.From)
+}
+
+// CurrencyConfig holds the exchange rates amounts of different currencies are converted
+// with, by the endpoints taking convert_to. Without rates conversions are refused.
+type CurrencyConfig struct {
+	// Base is the currency the rates are quoted in
+	Base string
+	// Rates maps currency codes to the worth of one unit in the base currency
+	Rates map[string]float64
+}
+
+// Converter returns the converter of the configured rates, nil when there are none
+func (c CurrencyConfig) Converter() (currency.Converter, error) {
+	if len(c.Rates) == 0 {
+		return nil, nil
+	}
+	rates, err := currency.NewStaticRates(c.Base, c.Rates)
+	if err != nil {
+		return nil, fmt.Errorf("invalid currency settings: %w", err)
+	}
+	return rates, nil
+}
+
+// FeaturesConfig maps feature flags to whether they are enabled
+type FeaturesConfig map[string]bool
+
+const (
+	// FeatureFullTextSearch gates the full-text search endpoint
+	FeatureFullTextSearch = "fulltext_search"
+)
+
+// Enabled reports whether a feature flag is on, unknown flags are off
+func (f FeaturesConfig) Enabled(flag string) bool {
+	return f[strings.ToLower(flag)]
+}
+
+const featureEnvPrefix = "FEATURES_"
+
+// applyFeatureOverrides lets FEATURES_<FLAG>=true|false environment variables turn flags on or off,
+// including flags the config files don't mention
+func applyFeatureOverrides(features FeaturesConfig, environ []string) (FeaturesConfig, error) {
+	if features == nil {
+		features = FeaturesConfig{}
+	}
+	for _, entry := range environ {
+		key, value, _ := strings.Cut(entry, "=")
+		flag, ok := strings.CutPrefix(key, featureEnvPrefix)
+		if !ok || flag == "" {
+			continue
+		}
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for feature flag %s: %w", value, key, err)
+		}
+		features[strings.ToLower(flag)] = enabled
+	}
+	return features, nil
+}
+
 type CacheConfig struct {
 	Host     string
 	Port     int
 	Password string
+	// AggregateTTL is how long heavy aggregate reads are shared between requests of
+	// the same user, 0 turns the micro-cache off
+	AggregateTTL time.Duration
+}
+
+// LimitsConfig holds page size limits, zero values are inherited
+type LimitsConfig struct {
+	DefaultLimit       int32 `mapstructure:"default_limit"`
+	MaxLimit           int32 `mapstructure:"max_limit"`
+	DefaultSearchLimit int32 `mapstructure:"default_search_limit"`
+	MaxSearchLimit     int32 `mapstructure:"max_search_limit"`
+	// MinTrimmedResults is the fewest results trim=auto cuts a search down to
+	MinTrimmedResults int32 `mapstructure:"min_trimmed_results"`
+}
+
+// over returns base with the limits set here replacing its own
+func (l LimitsConfig) over(base coretypes.LimitPolicy) coretypes.LimitPolicy {
+	if l.DefaultLimit > 0 {
+		base.DefaultLimit = l.DefaultLimit
+	}
+	if l.MaxLimit > 0 {
+		base.MaxLimit = l.MaxLimit
+	}
+	if l.DefaultSearchLimit > 0 {
+		base.DefaultSearchLimit = l.DefaultSearchLimit
+	}
+	if l.MaxSearchLimit > 0 {
+		base.MaxSearchLimit = l.MaxSearchLimit
+	}
+	if l.MinTrimmedResults > 0 {
+		base.MinTrimmedResults = l.MinTrimmedResults
+	}
+	return base
+}
+
+// PaginationConfig sets the page size limits of list and search endpoints. The
+// top-level limits apply everywhere, each entity section overrides them for its
+// own endpoints (pagination.contacts.max_limit etc.). The cursor settings apply to
+// every paginated list.
+type PaginationConfig struct {
+	LimitsConfig `mapstructure:",squash"`
+	Contacts     LimitsConfig
+	Projects     LimitsConfig
+	Wallets      LimitsConfig
+	// CursorTTL is how long a next_token is accepted after it was issued
+	CursorTTL time.Duration `mapstructure:"cursor_ttl"`
+	// CursorKey signs the next_tokens, empty generates a key on start so the tokens stop
+	// being accepted when the process restarts and by other instances
+	CursorKey string `mapstructure:"cursor_key"`
+	// LegacyCursors accepts unsigned next_tokens and those issued before they were bound
+	// to a user, only turned on for the migration period
+	LegacyCursors bool `mapstructure:"legacy_cursors"`
+}
+
+// GlobalPolicy returns the limits of endpoints without a section of their own
+func (p PaginationConfig) GlobalPolicy() coretypes.LimitPolicy {
+	policy := p.LimitsConfig.over(coretypes.DefaultLimitPolicy())
+	if p.CursorTTL > 0 {
+		policy.CursorTTL = p.CursorTTL
+	}
+	if p.CursorKey != "" {
+		policy.CursorKey = []byte(p.CursorKey)
+	}
+	policy.AllowLegacyCursors = p.LegacyCursors
+	return policy
+}
+
+// ContactsPolicy returns the limits of the contact endpoints
+func (p PaginationConfig) ContactsPolicy() coretypes.LimitPolicy {
+	return p.Contacts.over(p.GlobalPolicy())
+}
+
+// ProjectsPolicy returns the limits of the project endpoints
+func (p PaginationConfig) ProjectsPolicy() coretypes.LimitPolicy {
+	return p.Projects.over(p.GlobalPolicy())
+}
+
+// WalletsPolicy returns the limits of the wallet endpoints
+func (p PaginationConfig) WalletsPolicy() coretypes.LimitPolicy {
+	return p.Wallets.over(p.GlobalPolicy())
+}
+
+// Validate checks no limit or cursor TTL is negative and every default fits under its maximum
+func (p PaginationConfig) Validate() error {
+	if p.CursorTTL < 0 {
+		return fmt.Errorf("invalid pagination.cursor_ttl %s, it can't be negative", p.CursorTTL)
+	}
+	sections := []struct {
+		name   string
+		limits LimitsConfig
+		policy coretypes.LimitPolicy
+	}{
+		{"pagination", p.LimitsConfig, p.GlobalPolicy()},
+		{"pagination.contacts", p.Contacts, p.ContactsPolicy()},
+		{"pagination.projects", p.Projects, p.ProjectsPolicy()},
+		{"pagination.wallets", p.Wallets, p.WalletsPolicy()},
+	}
+	for _, section := range sections {
+		limits, policy := section.limits, section.policy
+		if limits.DefaultLimit < 0 || limits.MaxLimit < 0 || limits.DefaultSearchLimit < 0 || limits.MaxSearchLimit < 0 || limits.MinTrimmedResults < 0 {
+			return fmt.Errorf("invalid %s limits, they can't be negative", section.name)
+		}
+		if policy.DefaultLimit > policy.MaxLimit {
+			return fmt.Errorf("invalid %s limits, default_limit %d is above max_limit %d", section.name, policy.DefaultLimit, policy.MaxLimit)
+		}
+		if policy.DefaultSearchLimit > policy.MaxSearchLimit {
+			return fmt.Errorf("invalid %s limits, default_search_limit %d is above max_search_limit %d", section.name, policy.DefaultSearchLimit, policy.MaxSearchLimit)
+		}
+	}
+	return nil
+}
+
+// MaxAggregateTTL caps the aggregate micro-cache, it only smooths out bursts of
+// requests and must not serve visibly stale data
+const MaxAggregateTTL = 500 * time.Millisecond
+
+// sslModes are the sslmode values libpq and pgx accept
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// ValidationError lists every problem Validate found, so a misconfigured deployment
+// can be fixed in one go instead of one restart per mistake
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid config, %d problem(s):\n  - %s", len(e.Problems), strings.Join(e.Problems, "\n  - "))
+}
+
+// Validate checks the invariants of the whole config, returning a *ValidationError
+// listing every one that doesn't hold. Load calls it once the config is decoded.
+func (c *Config) Validate() error {
+	var problems []string
+	invalid := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+	check := func(err error) {
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		invalid("server.port %d is out of range, expected 1 to 65535", c.Server.Port)
+	}
+	timeouts := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"server.timeout.read", c.Server.ReadTimeout},
+		{"server.timeout.write", c.Server.WriteTimeout},
+		{"server.timeout.idle", c.Server.IdleTimeout},
+		{"server.timeout.request", c.Server.RequestTimeout},
+		{"server.timeout.search", c.Server.SearchTimeout},
+	}
+	for _, timeout := range timeouts {
+		if timeout.value <= 0 {
+			invalid("%s %s must be positive", timeout.name, timeout.value)
+		}
+	}
+	patterns := make(map[string]bool, len(c.Server.RouteTimeouts))
+	for _, route := range c.Server.RouteTimeouts {
+		switch {
+		case !strings.HasPrefix(route.Pattern, "/"):
+			invalid("server.timeout.routes pattern %q must start with /", route.Pattern)
+		case patterns[route.Pattern]:
+			invalid("server.timeout.routes pattern %s is listed twice", route.Pattern)
+		case route.Timeout <= 0:
+			invalid("server.timeout.routes timeout %s of %s must be positive", route.Timeout, route.Pattern)
+		}
+		patterns[route.Pattern] = true
+	}
+	if c.Server.Middleware.MaxInFlight < 0 {
+		invalid("server.middleware.maxInFlight %d, expected 0 (sized to the pool) or more", c.Server.Middleware.MaxInFlight)
+	}
+	if !c.Server.QueryParamsMode.Valid() {
+		invalid("server.queryParamsMode %q, expected warn or strict", c.Server.QueryParamsMode)
+	}
+
+	required := []struct {
+		name  string
+		value string
+	}{
+		{"database.host", c.Database.Host},
+		{"database.port", c.Database.Port},
+		{"database.username", c.Database.Username},
+		{"database.database", c.Database.Database},
+	}
+	for _, field := range required {
+		if strings.TrimSpace(field.value) == "" {
+			invalid("%s is required", field.name)
+		}
+	}
+	if c.Database.Port != "" {
+		if port, err := strconv.Atoi(c.Database.Port); err != nil || port < 1 || port > 65535 {
+			invalid("database.port %q is not a port number", c.Database.Port)
+		}
+	}
+	if !slices.Contains(sslModes, c.Database.SSLMode) {
+		invalid("database.sslMode %q, expected one of %s", c.Database.SSLMode, strings.Join(sslModes, ", "))
+	}
+	if c.Database.MaxConns < 1 {
+		invalid("database.maxConns %d, expected at least 1", c.Database.MaxConns)
+	}
+	if c.Database.MinConns < 0 || c.Database.MinConns > c.Database.MaxConns {
+		invalid("database.minConns %d, expected 0 up to maxConns (%d)", c.Database.MinConns, c.Database.MaxConns)
+	}
+	if c.Database.MaxLifetime <= 0 || c.Database.MaxIdleTime <= 0 || c.Database.HealthCheck <= 0 {
+		invalid("database.maxLifetime, maxIdleTime and healthCheck must be positive")
+	}
+
+	if c.Cache.AggregateTTL < 0 || c.Cache.AggregateTTL > MaxAggregateTTL {
+		invalid("cache.aggregateTTL %s, expected 0 (off) up to %s", c.Cache.AggregateTTL, MaxAggregateTTL)
+	}
+
+	check(c.Pagination.Validate())
+
+	if c.Janitor.Interval < 0 || c.Janitor.BatchSize < 0 || c.Janitor.SessionRetention < 0 || c.Janitor.JobRetention < 0 {
+		invalid("janitor settings can't be negative")
+	}
+
+	if !c.Wallets.Rounding.Valid() {
+		invalid("wallets.rounding %q, expected half_up or half_even", c.Wallets.Rounding)
+	}
+
+	if c.Contacts.MXLookupTimeout < 0 {
+		invalid("contacts.mxLookupTimeout %s can't be negative", c.Contacts.MXLookupTimeout)
+	}
+	_, err := c.Contacts.EmailChecker()
+	check(err)
+
+	if c.Quotas.MaxContacts < 0 || c.Quotas.MaxProjects < 0 || c.Quotas.MaxWallets < 0 {
+		invalid("quotas can't be negative, expected 0 (unlimited) or more")
+	}
+
+	if c.Events.BufferSize < 0 || c.Events.ReplaySize < 0 || c.Events.Heartbeat <= 0 {
+		invalid("events sizes can't be negative and events.heartbeat must be positive")
+	}
+
+	if c.Exports.SyncMaxRows < 0 {
+		invalid("exports.syncMaxRows %d, expected 0 (no limit) or more", c.Exports.SyncMaxRows)
+	}
+
+	if c.Backups.Retention <= 0 || c.Backups.LinkTTL <= 0 {
+		invalid("backups.retention %s and backups.linkTTL %s must be positive", c.Backups.Retention, c.Backups.LinkTTL)
+	} else if c.Janitor.JobRetention > 0 && c.Backups.Retention > c.Janitor.JobRetention {
+		invalid("backups.retention %s is longer than janitor.jobRetention %s", c.Backups.Retention, c.Janitor.JobRetention)
+	}
+
+	if c.ExportSchedules.Interval < 0 || c.ExportSchedules.Lease < 0 || c.ExportSchedules.WebhookTimeout < 0 {
+		invalid("exportSchedules settings can't be negative")
+	}
+
+	if c.Mail.Host != "" && c.Mail.From == "" {
+		invalid("mail.from is required to send emails through %s", c.Mail.Host)
+	}
+
+	_, err = c.Currency.Converter()
+	check(err)
+
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		invalid("tracing.sampleRatio %g, expected 0 up to 1", c.Tracing.SampleRatio)
+	}
+
+	if c.Inbound.Provider != "sendgrid" && c.Inbound.Provider != "mailgun" {
+		invalid("inbound.provider %q, expected sendgrid or mailgun", c.Inbound.Provider)
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
 }
 
 // Load reads configuration from environment variables and files
@@ -106,6 +702,17 @@ func Load() (*Config, error) {
 		}
 	}
 
+	config, err := decode()
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("config: %+v\n", *config)
+	return config, nil
+}
+
+// decode turns the settings viper read into a validated Config
+func decode() (*Config, error) {
 	var config Config
 	if err := viper.Unmarshal(&config); err != nil {
 		return nil, fmt.Errorf("error unmarshaling config: %w", err)
@@ -124,6 +731,17 @@ func Load() (*Config, error) {
 	if d, err := time.ParseDuration(viper.GetString("server.timeout.request")); err == nil {
 		config.Server.RequestTimeout = d
 	}
+	if d, err := time.ParseDuration(viper.GetString("server.timeout.search")); err == nil {
+		config.Server.SearchTimeout = d
+	}
+	if err := viper.UnmarshalKey("server.timeout.routes", &config.Server.RouteTimeouts); err != nil {
+		return nil, fmt.Errorf("error unmarshaling server.timeout.routes: %w", err)
+	}
+
+	config.Server.QueryParamsMode = coretypes.QueryParamsMode(strings.ToLower(string(config.Server.QueryParamsMode)))
+	config.Wallets.Rounding = validate.RoundingMode(strings.ToLower(string(config.Wallets.Rounding)))
+	config.Inbound.Provider = strings.ToLower(config.Inbound.Provider)
+	config.Inbound.Domain = strings.ToLower(config.Inbound.Domain)
 
 	// Parse auth durations
 	if d, err := time.ParseDuration(viper.GetString("auth.jwt.access_token_ttl")); err == nil {
@@ -133,7 +751,17 @@ func Load() (*Config, error) {
 		config.Auth.JWT.RefreshTokenTTL = d
 	}
 
-	fmt.Printf("config: %+v\n", config)
+	features, err := applyFeatureOverrides(config.Features, os.Environ())
+	if err != nil {
+		return nil, err
+	}
+	config.Features = features
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	config.Server.Middleware.MaxInFlight = config.Server.Middleware.InFlightLimit(config.Database.MaxConns)
+
 	return &config, nil
 }
 
@@ -144,6 +772,11 @@ func setDefaults() {
 	viper.SetDefault("server.timeout.write", "15s")
 	viper.SetDefault("server.timeout.idle", "60s")
 	viper.SetDefault("server.timeout.request", "60s")
+	viper.SetDefault("server.timeout.search", "3s")
+	viper.SetDefault("server.queryParamsMode", coretypes.QueryParamsWarn)
+	viper.SetDefault("server.maxSearchWindow", 500)
+	viper.SetDefault("server.rejectEmptyUpdates", false)
+	viper.SetDefault("server.compression.minSize", 1024)
 
 	// Middleware defaults
 	viper.SetDefault("server.middleware.allowedOrigins", []string{"https://*", "http://*"})
@@ -154,6 +787,8 @@ func setDefaults() {
 	viper.SetDefault("server.middleware.maxAge", 300)
 	viper.SetDefault("server.middleware.rateLimit.requestsPerMinute", 100)
 	viper.SetDefault("server.middleware.rateLimit.windowLength", "1m")
+	viper.SetDefault("server.middleware.trustedProxies", []string{})
+	viper.SetDefault("server.middleware.maxInFlight", 0)
 
 	// Database defaults
 	viper.SetDefault("database.maxConns", 25)
@@ -162,6 +797,11 @@ func setDefaults() {
 	viper.SetDefault("database.maxIdleTime", "30m")
 	viper.SetDefault("database.healthCheck", "1m")
 	viper.SetDefault("database.sslMode", "require")
+	viper.SetDefault("database.strictTagOwnership", false)
+	viper.SetDefault("database.uniqueContactEmails", false)
+
+	// Cache defaults
+	viper.SetDefault("cache.aggregateTTL", "0s")
 
 	// Logger defaults
 	viper.SetDefault("logger.environment", "development")
@@ -169,10 +809,100 @@ func setDefaults() {
 
 	// Auth defaults
 	viper.SetDefault("auth.jwt.access_token_ttl", "15m")
-	viper.SetDefault("auth.jwt.refresh_token_ttl", "7d")
+	viper.SetDefault("auth.jwt.refresh_token_ttl", "168h")
 	viper.SetDefault("auth.cookie.path", "/")
 	viper.SetDefault("auth.cookie.secure", true)
 	viper.SetDefault("auth.cookie.same_site", "strict")
+	viper.SetDefault("auth.jit_provisioning", true)
+
+	// Admin defaults
+	viper.SetDefault("admin.userIDs", []string{})
+
+	// Jobs defaults
+	viper.SetDefault("jobs.workers", 2)
+	viper.SetDefault("jobs.chunkSize", 200)
+	viper.SetDefault("jobs.maxRetries", 3)
+	viper.SetDefault("jobs.retryDelay", "500ms")
+	viper.SetDefault("jobs.pollInterval", "5s")
+	viper.SetDefault("jobs.staleAfter", "2m")
+
+	// Trash defaults
+	viper.SetDefault("trash.retention", "720h")
+
+	// Janitor defaults
+	viper.SetDefault("janitor.interval", "1h")
+	viper.SetDefault("janitor.batchSize", 500)
+	viper.SetDefault("janitor.sessionRetention", "0s")
+	viper.SetDefault("janitor.jobRetention", "168h")
+
+	// Wallets defaults
+	viper.SetDefault("wallets.rounding", string(validate.RoundHalfUp))
+
+	// Projects defaults
+	viper.SetDefault("projects.requireStartDate", false)
+	viper.SetDefault("projects.requireBudget", false)
+
+	// Contacts defaults
+	viper.SetDefault("contacts.verifyEmailMX", false)
+	viper.SetDefault("contacts.mxLookupTimeout", "2s")
+
+	// Quota defaults, unlimited
+	viper.SetDefault("quotas.maxContacts", 0)
+	viper.SetDefault("quotas.maxProjects", 0)
+	viper.SetDefault("quotas.maxWallets", 0)
+
+	// Events defaults
+	viper.SetDefault("events.bufferSize", 64)
+	viper.SetDefault("events.replaySize", 100)
+	viper.SetDefault("events.heartbeat", "15s")
+
+	// Exports defaults
+	viper.SetDefault("exports.syncMaxRows", 10000)
+	viper.SetDefault("exports.dir", "./data/exports")
+
+	// Backups defaults
+	viper.SetDefault("backups.retention", "24h")
+	viper.SetDefault("backups.linkTTL", "15m")
+	viper.SetDefault("backups.signingKey", "")
+
+	// Export schedule defaults
+	viper.SetDefault("exportSchedules.interval", "1m")
+	viper.SetDefault("exportSchedules.lease", "15m")
+	viper.SetDefault("exportSchedules.webhookTimeout", "30s")
+
+	// Mail defaults, off until a server is configured
+	viper.SetDefault("mail.port", 587)
+
+	// Currency defaults
+	viper.SetDefault("currency.base", "USD")
+
+	// Feature flag defaults
+	viper.SetDefault("features."+FeatureFullTextSearch, true)
+
+	// Inbound email defaults
+	viper.SetDefault("inbound.provider", "sendgrid")
+	viper.SetDefault("inbound.maxBytes", inboundTypes.DefaultMaxEmailBytes)
+
+	// Tracing defaults, off until a collector is configured
+	viper.SetDefault("tracing.enabled", false)
+	viper.SetDefault("tracing.endpoint", "http://localhost:4318")
+	viper.SetDefault("tracing.sampleRatio", 1.0)
+	viper.SetDefault("tracing.serviceName", "expense-tracker")
+
+	// Pagination defaults, the entity sections inherit the global limits when unset
+	viper.SetDefault("pagination.default_limit", coretypes.DefaultLimit)
+	viper.SetDefault("pagination.max_limit", coretypes.MaxLimit)
+	viper.SetDefault("pagination.default_search_limit", coretypes.DefaultSearchLimit)
+	viper.SetDefault("pagination.max_search_limit", coretypes.MaxSearchLimit)
+	viper.SetDefault("pagination.min_trimmed_results", coretypes.DefaultMinTrimmedResults)
+	viper.SetDefault("pagination.cursor_ttl", coretypes.DefaultCursorTTL.String())
+	viper.SetDefault("pagination.cursor_key", "")
+	viper.SetDefault("pagination.legacy_cursors", false)
+	for _, entity := range []string{"contacts", "projects", "wallets"} {
+		for _, key := range []string{"default_limit", "max_limit", "default_search_limit", "max_search_limit", "min_trimmed_results"} {
+			viper.SetDefault("pagination."+entity+"."+key, 0)
+		}
+	}
 }
 
 // GetDSN returns the formatted database connection string
@@ -0,0 +1,215 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	coretypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyFeatureOverrides(t *testing.T) {
+	features, err := applyFeatureOverrides(FeaturesConfig{"fulltext_search": true, "transactions": false}, []string{
+		"PATH=/usr/bin",
+		"FEATURES_TRANSACTIONS=true",
+		"FEATURES_FULLTEXT_SEARCH=0",
+		"FEATURES_NEW_REPORTS=TRUE",
+		"FEATURES_=true",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, FeaturesConfig{
+		"fulltext_search": false,
+		"transactions":    true,
+		"new_reports":     true,
+	}, features)
+
+	features, err = applyFeatureOverrides(nil, nil)
+	require.NoError(t, err)
+	assert.False(t, features.Enabled("transactions"))
+
+	_, err = applyFeatureOverrides(nil, []string{"FEATURES_TRANSACTIONS=maybe"})
+	assert.Error(t, err)
+}
+
+func loadPagination(t *testing.T, yaml string) PaginationConfig {
+	t.Helper()
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.SetConfigType("yaml")
+	viper.AutomaticEnv()
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	setDefaults()
+	require.NoError(t, viper.ReadConfig(strings.NewReader(yaml)))
+
+	// decode like Load does so environment overrides apply, the other sections are left out
+	var config struct{ Pagination PaginationConfig }
+	require.NoError(t, viper.Unmarshal(&config))
+	return config.Pagination
+}
+
+func TestPaginationConfig(t *testing.T) {
+	pagination := loadPagination(t, `
+pagination:
+  max_search_limit: 60
+  contacts:
+    max_limit: 200
+  projects:
+    default_limit: 5
+    max_limit: 50
+  wallets:
+    min_trimmed_results: 5
+`)
+	require.NoError(t, pagination.Validate())
+
+	global := coretypes.DefaultLimitPolicy()
+	global.MaxSearchLimit = 60
+	assert.Equal(t, global, pagination.GlobalPolicy())
+
+	wallets := pagination.WalletsPolicy()
+	assert.Equal(t, int32(5), wallets.MinTrimmedResults)
+	wallets.MinTrimmedResults = global.MinTrimmedResults
+	assert.Equal(t, global, wallets)
+
+	contacts := pagination.ContactsPolicy()
+	assert.Equal(t, int32(200), contacts.MaxLimit)
+	assert.Equal(t, int32(coretypes.DefaultLimit), contacts.DefaultLimit)
+	assert.Equal(t, int32(60), contacts.MaxSearchLimit)
+
+	projects := pagination.ProjectsPolicy()
+	assert.Equal(t, int32(5), projects.DefaultLimit)
+	assert.Equal(t, int32(50), projects.MaxLimit)
+}
+
+func TestPaginationConfig_EnvOverride(t *testing.T) {
+	t.Setenv("PAGINATION_WALLETS_MAX_LIMIT", "150")
+	pagination := loadPagination(t, `pagination: {}`)
+	assert.Equal(t, int32(150), pagination.WalletsPolicy().MaxLimit)
+	assert.Equal(t, int32(coretypes.MaxLimit), pagination.ContactsPolicy().MaxLimit)
+}
+
+func TestPaginationConfig_Cursors(t *testing.T) {
+	pagination := loadPagination(t, `pagination: {}`)
+	assert.Equal(t, coretypes.DefaultCursorTTL, pagination.GlobalPolicy().CursorTTL)
+	assert.False(t, pagination.ProjectsPolicy().AllowLegacyCursors)
+	assert.Empty(t, pagination.GlobalPolicy().CursorKey)
+
+	pagination = loadPagination(t, "pagination:\n  cursor_ttl: 30m\n  cursor_key: secret\n  legacy_cursors: true\n")
+	require.NoError(t, pagination.Validate())
+	assert.Equal(t, 30*time.Minute, pagination.ContactsPolicy().CursorTTL)
+	assert.Equal(t, []byte("secret"), pagination.WalletsPolicy().CursorKey)
+	assert.True(t, pagination.WalletsPolicy().AllowLegacyCursors)
+}
+
+func TestPaginationConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+	}{
+		{name: "negative limit", yaml: "pagination:\n  wallets:\n    max_limit: -1\n"},
+		{name: "default above max", yaml: "pagination:\n  contacts:\n    default_limit: 20\n    max_limit: 15\n"},
+		{name: "negative min trimmed results", yaml: "pagination:\n  min_trimmed_results: -1\n"},
+		{name: "negative cursor ttl", yaml: "pagination:\n  cursor_ttl: -1h\n"},
+		{name: "global default above an entity max", yaml: "pagination:\n  default_search_limit: 30\n  projects:\n    max_search_limit: 25\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, loadPagination(t, tt.yaml).Validate())
+		})
+	}
+}
+
+func TestMiddlewareConfig_InFlightLimit(t *testing.T) {
+	assert.Equal(t, 40, MiddlewareConfig{}.InFlightLimit(10))
+	assert.Equal(t, 25, MiddlewareConfig{MaxInFlight: 25}.InFlightLimit(10))
+}
+
+func loadShipped(t *testing.T) *Config {
+	t.Helper()
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.SetConfigFile("config.yaml")
+	setDefaults()
+	require.NoError(t, viper.ReadInConfig())
+
+	config, err := decode()
+	require.NoError(t, err)
+	return config
+}
+
+func TestDecode_ShippedConfig(t *testing.T) {
+	config := loadShipped(t)
+	assert.Equal(t, "postgres", config.Database.Username)
+	assert.Equal(t, "expense_tracker", config.Database.Database)
+	assert.Equal(t, int32(10), config.Database.MaxConns)
+	assert.Equal(t, 40, config.Server.Middleware.MaxInFlight)
+	assert.Contains(t, config.Server.RouteTimeouts, RouteTimeout{Pattern: "/api/v1/contacts/export", Timeout: 5 * time.Minute})
+	assert.Equal(t, 3*time.Second, config.Server.SearchTimeout)
+	assert.Equal(t, BackupsConfig{Retention: 24 * time.Hour, LinkTTL: 15 * time.Minute}, config.Backups)
+}
+
+func TestConfig_Validate(t *testing.T) {
+	config := loadShipped(t)
+	config.Database.Host = ""
+	config.Database.Port = "postgres"
+	config.Database.SSLMode = "on"
+	config.Database.MinConns = 20
+	config.Server.WriteTimeout = 0
+	config.Inbound.Provider = "postmark"
+
+	err := config.Validate()
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Len(t, validationErr.Problems, 6)
+	for _, field := range []string{"database.host", "database.port", "database.sslMode", "database.minConns", "server.timeout.write", "inbound.provider"} {
+		assert.Contains(t, err.Error(), field)
+	}
+}
+
+func TestConfig_Validate_RouteTimeouts(t *testing.T) {
+	config := loadShipped(t)
+	config.Server.RouteTimeouts = []RouteTimeout{
+		{Pattern: "/api/v1/contacts/export", Timeout: time.Minute},
+		{Pattern: "api/v1/search", Timeout: time.Minute},
+		{Pattern: "/api/v1/contacts/export", Timeout: 2 * time.Minute},
+		{Pattern: "/api/v1/wallets", Timeout: 0},
+	}
+
+	err := config.Validate()
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []string{
+		`server.timeout.routes pattern "api/v1/search" must start with /`,
+		"server.timeout.routes pattern /api/v1/contacts/export is listed twice",
+		"server.timeout.routes timeout 0s of /api/v1/wallets must be positive",
+	}, validationErr.Problems)
+}
+
+func TestConfig_Validate_Backups(t *testing.T) {
+	config := loadShipped(t)
+	config.Backups.Retention = 8 * 24 * time.Hour
+
+	err := config.Validate()
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []string{"backups.retention 192h0m0s is longer than janitor.jobRetention 168h0m0s"}, validationErr.Problems)
+
+	config.Backups.Retention = time.Hour
+	config.Backups.LinkTTL = 0
+	require.ErrorAs(t, config.Validate(), &validationErr)
+	assert.Equal(t, []string{"backups.retention 1h0m0s and backups.linkTTL 0s must be positive"}, validationErr.Problems)
+}
+
+func TestConfig_Validate_PoolSizing(t *testing.T) {
+	config := loadShipped(t)
+	config.Database.MaxConns = 0
+	config.Database.MinConns = 0
+
+	err := config.Validate()
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []string{"database.maxConns 0, expected at least 1"}, validationErr.Problems)
+}
@@ -0,0 +1,101 @@
+package anonymize
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const (
+	// KeySize is the size in bytes of the keys generated for a run
+	KeySize = 32
+	// tokenLength is the number of hex characters of the HMAC kept in a pseudonym
+	tokenLength = 12
+	// phoneDigits is the number of digits of a pseudonymous phone number
+	phoneDigits = 10
+	// EmailDomain is the reserved domain pseudonymous emails are given so they never reach anyone
+	EmailDomain = "anonymized.invalid"
+)
+
+// Pseudonymizer replaces PII with pseudonyms derived from a keyed HMAC. The same value
+// always gets the same pseudonym under one key, so duplicates can still be found after
+// anonymizing, while the original can't be recovered once the key is gone.
+type Pseudonymizer struct {
+	key []byte
+}
+
+// NewPseudonymizer creates a pseudonymizer keyed with key
+func NewPseudonymizer(key []byte) *Pseudonymizer {
+	return &Pseudonymizer{key: append([]byte(nil), key...)}
+}
+
+// NewRunPseudonymizer creates a pseudonymizer with a random key that is never stored,
+// so pseudonyms only match within the run
+func NewRunPseudonymizer() (*Pseudonymizer, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("generate pseudonymization key: %w", err)
+	}
+	return NewPseudonymizer(key), nil
+}
+
+// sum returns the HMAC of value within the field's namespace, so equal values of
+// different fields don't share a pseudonym
+func (p *Pseudonymizer) sum(field, value string) []byte {
+	mac := hmac.New(sha256.New, p.key)
+	mac.Write([]byte(field))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+func (p *Pseudonymizer) token(field, value string) string {
+	return hex.EncodeToString(p.sum(field, value))[:tokenLength]
+}
+
+// Name returns the pseudonym of a person or contact name, ignoring case and spacing
+func (p *Pseudonymizer) Name(name string) string {
+	return "Person " + p.token("name", normalize(name))
+}
+
+// Email returns a pseudonymous address on the reserved domain, ignoring case
+func (p *Pseudonymizer) Email(email string) string {
+	return p.token("email", normalize(email)) + "@" + EmailDomain
+}
+
+// Phone returns a pseudonymous phone number, only the digits of the original count
+func (p *Pseudonymizer) Phone(phone string) string {
+	digits := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, phone)
+
+	sum := p.sum("phone", digits)
+	pseudonym := make([]byte, phoneDigits)
+	for i := range pseudonym {
+		pseudonym[i] = '0' + sum[i]%10
+	}
+	// +000 isn't a country calling code so the number can't be dialled
+	return "+000" + string(pseudonym)
+}
+
+// Address returns the pseudonym of an address line, ignoring case and spacing
+func (p *Pseudonymizer) Address(address string) string {
+	return "Address " + p.token("address", normalize(address))
+}
+
+// Company returns the pseudonym of a company name, ignoring case and spacing
+func (p *Pseudonymizer) Company(company string) string {
+	return "Company " + p.token("company", normalize(company))
+}
+
+// normalize lowercases the value and collapses its whitespace
+func normalize(value string) string {
+	return strings.ToLower(strings.Join(strings.Fields(value), " "))
+}
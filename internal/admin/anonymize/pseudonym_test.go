@@ -0,0 +1,66 @@
+package anonymize
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/validate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPseudonymizer_Deterministic(t *testing.T) {
+	p := NewPseudonymizer([]byte("key"))
+
+	assert.Equal(t, p.Name("Jane Doe"), p.Name("  jane   DOE "))
+	assert.Equal(t, p.Email("Jane@Example.com"), p.Email(" jane@example.com"))
+	assert.Equal(t, p.Phone("+1 (555) 010-0000"), p.Phone("15550100000"))
+	assert.Equal(t, p.Address("1 Main St"), p.Address("1 main st"))
+	assert.Equal(t, p.Company("Acme Inc"), p.Company("ACME INC"))
+
+	assert.NotEqual(t, p.Name("Jane Doe"), p.Name("John Doe"))
+}
+
+func TestPseudonymizer_KeyedAndNamespaced(t *testing.T) {
+	p := NewPseudonymizer([]byte("key"))
+	other := NewPseudonymizer([]byte("other key"))
+
+	assert.NotEqual(t, p.Name("Jane Doe"), other.Name("Jane Doe"))
+	// the same value in different fields doesn't give away that they match
+	assert.NotEqual(t,
+		strings.TrimPrefix(p.Name("Acme"), "Person "),
+		strings.TrimPrefix(p.Company("Acme"), "Company "))
+}
+
+func TestPseudonymizer_Formats(t *testing.T) {
+	p := NewPseudonymizer([]byte("key"))
+
+	assert.Regexp(t, `^Person [0-9a-f]{12}$`, p.Name("Jane Doe"))
+	assert.Regexp(t, `^[0-9a-f]{12}@anonymized\.invalid$`, p.Email("jane@example.com"))
+	assert.Regexp(t, `^Address [0-9a-f]{12}$`, p.Address("1 Main St"))
+	assert.Regexp(t, `^Company [0-9a-f]{12}$`, p.Company("Acme"))
+
+	// phones still pass the contact validation so anonymized rows can be edited
+	phone := p.Phone("+1 555 0100")
+	assert.Regexp(t, `^\+000\d{10}$`, phone)
+	assert.Regexp(t, regexp.MustCompile(validate.PhoneNumberPattern), phone)
+}
+
+func TestPseudonymizer_NoPlaintext(t *testing.T) {
+	p := NewPseudonymizer([]byte("key"))
+
+	assert.NotContains(t, strings.ToLower(p.Name("Margaret Thatcher")), "margaret")
+	assert.NotContains(t, p.Email("margaret@example.com"), "margaret")
+	assert.NotContains(t, p.Email("margaret@example.com"), "example.com")
+}
+
+func TestNewRunPseudonymizer(t *testing.T) {
+	first, err := NewRunPseudonymizer()
+	require.NoError(t, err)
+	second, err := NewRunPseudonymizer()
+	require.NoError(t, err)
+
+	assert.Len(t, first.key, KeySize)
+	assert.NotEqual(t, first.Name("Jane Doe"), second.Name("Jane Doe"))
+}
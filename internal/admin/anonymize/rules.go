@@ -0,0 +1,54 @@
+package anonymize
+
+import (
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// The scrub rules decide what happens to each PII column. Identifying values that are
+// useful to tell records apart get pseudonyms, free text and postal codes are cleared,
+// and coarse location (city, state, country), amounts and timestamps are kept for reporting.
+
+// ScrubUser returns the anonymized name and email of the user
+func (p *Pseudonymizer) ScrubUser(user db.User) db.AnonymizeUserParams {
+	return db.AnonymizeUserParams{
+		UserID: user.UserID,
+		Name:   p.Name(user.Name),
+		Email:  p.Email(user.Email),
+	}
+}
+
+// ScrubContact returns the anonymized PII columns of the contact
+func (p *Pseudonymizer) ScrubContact(contact db.Contact) db.AnonymizeContactParams {
+	return db.AnonymizeContactParams{
+		ContactID:     contact.ContactID,
+		Name:          p.Name(contact.Name),
+		Phone:         replace(contact.Phone, p.Phone),
+		Email:         replace(contact.Email, p.Email),
+		AddressLine1:  replace(contact.AddressLine1, p.Address),
+		AddressLine2:  replace(contact.AddressLine2, p.Address),
+		ZipPostalCode: pgtype.Text{},
+		Company:       replace(contact.Company, p.Company),
+		Notes:         pgtype.Text{},
+	}
+}
+
+// ScrubProject returns the anonymized PII columns of the project
+func (p *Pseudonymizer) ScrubProject(project db.Project) db.AnonymizeProjectParams {
+	return db.AnonymizeProjectParams{
+		ProjectID:     project.ProjectID,
+		Description:   pgtype.Text{},
+		AddressLine1:  replace(project.AddressLine1, p.Address),
+		AddressLine2:  replace(project.AddressLine2, p.Address),
+		ZipPostalCode: pgtype.Text{},
+		Website:       pgtype.Text{},
+	}
+}
+
+// replace runs pseudonym on a set value, NULL stays NULL
+func replace(value pgtype.Text, pseudonym func(string) string) pgtype.Text {
+	if !value.Valid {
+		return value
+	}
+	return pgtype.Text{String: pseudonym(value.String), Valid: true}
+}
@@ -0,0 +1,87 @@
+package anonymize
+
+import (
+	"testing"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+)
+
+func text(value string) pgtype.Text {
+	return pgtype.Text{String: value, Valid: true}
+}
+
+func TestScrubUser(t *testing.T) {
+	p := NewPseudonymizer([]byte("key"))
+	user := db.User{UserID: uuid.New(), Name: "Jane Doe", Email: "jane@example.com"}
+
+	params := p.ScrubUser(user)
+
+	assert.Equal(t, user.UserID, params.UserID)
+	assert.Equal(t, p.Name("Jane Doe"), params.Name)
+	assert.Equal(t, p.Email("jane@example.com"), params.Email)
+}
+
+func TestScrubContact(t *testing.T) {
+	p := NewPseudonymizer([]byte("key"))
+
+	t.Run("every PII column replaced", func(t *testing.T) {
+		contact := db.Contact{
+			ContactID:     uuid.New(),
+			Name:          "Margaret Thatcher",
+			Phone:         text("+44 20 7219 4272"),
+			Email:         text("margaret@example.com"),
+			AddressLine1:  text("10 Downing Street"),
+			AddressLine2:  text("Westminster"),
+			ZipPostalCode: text("SW1A 2AA"),
+			Company:       text("HM Government"),
+			Notes:         text("Met at the conference"),
+		}
+
+		params := p.ScrubContact(contact)
+
+		assert.Equal(t, contact.ContactID, params.ContactID)
+		assert.Equal(t, p.Name("Margaret Thatcher"), params.Name)
+		assert.Equal(t, text(p.Phone("+44 20 7219 4272")), params.Phone)
+		assert.Equal(t, text(p.Email("margaret@example.com")), params.Email)
+		assert.Equal(t, text(p.Address("10 Downing Street")), params.AddressLine1)
+		assert.Equal(t, text(p.Address("Westminster")), params.AddressLine2)
+		assert.Equal(t, text(p.Company("HM Government")), params.Company)
+		// free text and postal codes are dropped rather than pseudonymized
+		assert.False(t, params.ZipPostalCode.Valid)
+		assert.False(t, params.Notes.Valid)
+	})
+
+	t.Run("missing values stay missing", func(t *testing.T) {
+		params := p.ScrubContact(db.Contact{ContactID: uuid.New(), Name: "Jane"})
+
+		assert.False(t, params.Phone.Valid)
+		assert.False(t, params.Email.Valid)
+		assert.False(t, params.AddressLine1.Valid)
+		assert.False(t, params.AddressLine2.Valid)
+		assert.False(t, params.Company.Valid)
+	})
+}
+
+func TestScrubProject(t *testing.T) {
+	p := NewPseudonymizer([]byte("key"))
+	project := db.Project{
+		ProjectID:     uuid.New(),
+		Name:          "House renovation",
+		Description:   text("Margaret's kitchen"),
+		AddressLine1:  text("10 Downing Street"),
+		ZipPostalCode: text("SW1A 2AA"),
+		Website:       text("https://example.com/margaret"),
+	}
+
+	params := p.ScrubProject(project)
+
+	assert.Equal(t, project.ProjectID, params.ProjectID)
+	assert.Equal(t, text(p.Address("10 Downing Street")), params.AddressLine1)
+	assert.False(t, params.AddressLine2.Valid)
+	assert.False(t, params.Description.Valid)
+	assert.False(t, params.ZipPostalCode.Valid)
+	assert.False(t, params.Website.Valid)
+}
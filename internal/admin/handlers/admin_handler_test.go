@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/admin/types"
+	coreErrors "github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// Mock service
+type mockAdminService struct {
+	mock.Mock
+}
+
+func (m *mockAdminService) GetMigrationStatus(ctx context.Context) (types.MigrationStatus, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(types.MigrationStatus), args.Error(1)
+}
+
+func (m *mockAdminService) ListFeatures() []types.Feature {
+	args := m.Called()
+	return args.Get(0).([]types.Feature)
+}
+
+func (m *mockAdminService) AnonymizeUser(ctx context.Context, userID uuid.UUID, dryRun bool) (types.AnonymizationResult, error) {
+	args := m.Called(ctx, userID, dryRun)
+	return args.Get(0).(types.AnonymizationResult), args.Error(1)
+}
+
+func TestAdminHandler_GetMigrationStatus(t *testing.T) {
+	adminID := uuid.New()
+
+	tests := []struct {
+		name           string
+		userID         *uuid.UUID
+		setupMock      func(*mockAdminService)
+		expectedStatus int
+	}{
+		{
+			name:   "admin gets status",
+			userID: &adminID,
+			setupMock: func(m *mockAdminService) {
+				m.On("GetMigrationStatus", mock.Anything).
+					Return(types.MigrationStatus{CurrentVersion: 1, LatestVersion: 1, UpToDate: true}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "non admin is forbidden",
+			userID:         func() *uuid.UUID { id := uuid.New(); return &id }(),
+			setupMock:      func(m *mockAdminService) {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "missing user",
+			setupMock:      func(m *mockAdminService) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:   "service error",
+			userID: &adminID,
+			setupMock: func(m *mockAdminService) {
+				m.On("GetMigrationStatus", mock.Anything).
+					Return(types.MigrationStatus{}, errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockAdminService)
+			handler := NewAdminHandler(mockService, []uuid.UUID{adminID}, zap.NewNop())
+			tt.setupMock(mockService)
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/migrations", nil)
+			if tt.userID != nil {
+				req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, *tt.userID))
+			}
+			w := httptest.NewRecorder()
+
+			handler.RequireAdmin(http.HandlerFunc(handler.GetMigrationStatus)).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdminHandler_GetFeatures(t *testing.T) {
+	adminID := uuid.New()
+
+	tests := []struct {
+		name           string
+		userID         uuid.UUID
+		setupMock      func(*mockAdminService)
+		expectedStatus int
+	}{
+		{
+			name:   "admin lists flags",
+			userID: adminID,
+			setupMock: func(m *mockAdminService) {
+				m.On("ListFeatures").Return([]types.Feature{{Name: "fulltext_search", Enabled: true}})
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "non admin is forbidden",
+			userID:         uuid.New(),
+			setupMock:      func(m *mockAdminService) {},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockAdminService)
+			handler := NewAdminHandler(mockService, []uuid.UUID{adminID}, zap.NewNop())
+			tt.setupMock(mockService)
+
+			req := httptest.NewRequest(http.MethodGet, "/features", nil)
+			req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, tt.userID))
+			w := httptest.NewRecorder()
+
+			handler.RequireAdmin(http.HandlerFunc(handler.GetFeatures)).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response struct {
+					Data []types.Feature `json:"data"`
+				}
+				assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				assert.Equal(t, []types.Feature{{Name: "fulltext_search", Enabled: true}}, response.Data)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdminHandler_AnonymizeUser(t *testing.T) {
+	adminID := uuid.New()
+	targetID := uuid.New()
+
+	tests := []struct {
+		name           string
+		userID         uuid.UUID
+		targetID       string
+		query          string
+		setupMock      func(*mockAdminService)
+		expectedStatus int
+		expectedDryRun bool
+	}{
+		{
+			name:     "admin anonymizes a user",
+			userID:   adminID,
+			targetID: targetID.String(),
+			setupMock: func(m *mockAdminService) {
+				m.On("AnonymizeUser", mock.Anything, targetID, false).Return(types.AnonymizationResult{
+					UserID: targetID,
+					Counts: types.AnonymizationCounts{Users: 1, Contacts: 3, Projects: 2},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:     "dry run is marked",
+			userID:   adminID,
+			targetID: targetID.String(),
+			query:    "?dry_run=true",
+			setupMock: func(m *mockAdminService) {
+				m.On("AnonymizeUser", mock.Anything, targetID, true).Return(types.AnonymizationResult{
+					UserID:  targetID,
+					Counts:  types.AnonymizationCounts{Users: 1, Contacts: 3, Projects: 2},
+					Samples: &types.AnonymizationSamples{Contacts: []uuid.UUID{uuid.New()}, Projects: []uuid.UUID{}},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedDryRun: true,
+		},
+		{
+			name:           "non admin is forbidden",
+			userID:         uuid.New(),
+			targetID:       targetID.String(),
+			setupMock:      func(m *mockAdminService) {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "invalid user id",
+			userID:         adminID,
+			targetID:       "not-a-uuid",
+			setupMock:      func(m *mockAdminService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown user",
+			userID:   adminID,
+			targetID: targetID.String(),
+			setupMock: func(m *mockAdminService) {
+				m.On("AnonymizeUser", mock.Anything, targetID, false).
+					Return(types.AnonymizationResult{}, &coreErrors.ErrorResponse{Type: coreErrors.ErrorTypeNotFound})
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:     "service error",
+			userID:   adminID,
+			targetID: targetID.String(),
+			setupMock: func(m *mockAdminService) {
+				m.On("AnonymizeUser", mock.Anything, targetID, false).
+					Return(types.AnonymizationResult{}, errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockAdminService)
+			handler := NewAdminHandler(mockService, []uuid.UUID{adminID}, zap.NewNop())
+			tt.setupMock(mockService)
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/users/"+tt.targetID+"/anonymize"+tt.query, nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.targetID)
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			req = req.WithContext(context.WithValue(ctx, requestcontext.UserIDKey, tt.userID))
+			w := httptest.NewRecorder()
+
+			handler.RequireAdmin(http.HandlerFunc(handler.AnonymizeUser)).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response struct {
+					Data types.AnonymizationResult `json:"data"`
+					Meta struct {
+						DryRun bool `json:"dry_run"`
+					} `json:"meta"`
+				}
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, int64(3), response.Data.Counts.Contacts)
+				assert.Equal(t, tt.expectedDryRun, response.Meta.DryRun)
+				assert.Equal(t, tt.expectedDryRun, response.Data.Samples != nil)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+)
+
+// RequireAdmin only lets through users listed in the admin configuration
+func (h *AdminHandler) RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, err := requestcontext.GetUserIDFromContext(r.Context())
+		if err != nil {
+			h.RespondError(w, r, errors.ErrAuthorization(err))
+			return
+		}
+
+		if _, ok := h.admins[userID]; !ok {
+			h.RespondError(w, r, errors.ErrForbidden(fmt.Errorf("user %s is not an admin", userID)))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
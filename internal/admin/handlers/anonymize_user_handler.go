@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// AnonymizeUser godoc
+// @Summary Anonymize a user
+// @Description Irreversibly replaces the PII of the user, their contacts and projects with keyed pseudonyms for compliance exports. Relationship notes are cleared, export schedules are disabled and their targets cleared, and stored export and backup archives are deleted. Amounts, timestamps and coarse location are kept. Once anonymized, writes putting PII back on the user's rows are rejected with 403. Running it again on an anonymized user re-scrubs the rows and keeps the original anonymizedAt. With dry_run=true nothing is changed, the report counts what would be anonymized, samples up to 50 IDs per table and is marked with meta.dry_run.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID" format(uuid)
+// @Param dry_run query boolean false "Report what would be anonymized and roll back"
+// @Success 200 {object} payloads.Response{data=types.AnonymizationResult}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /admin/users/{id}/anonymize [post]
+// @ID AnonymizeUser
+func (h *AdminHandler) AnonymizeUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	if !h.CheckQueryParams(w, r, "dry_run") {
+		return
+	}
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+
+	result, err := h.service.AnonymizeUser(r.Context(), userID, dryRun)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	if dryRun {
+		h.Respond(w, r, payloads.DryRun(result))
+		return
+	}
+	h.Respond(w, r, payloads.OK(result))
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+)
+
+// GetFeatures godoc
+// @Summary List feature flags
+// @Description Lists the feature flags of the running environment and whether each is enabled, routes behind a disabled flag answer 404
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} payloads.Response{data=[]types.Feature}
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /features [get]
+// @ID GetFeatures
+func (h *AdminHandler) GetFeatures(w http.ResponseWriter, r *http.Request) {
+	features := h.service.ListFeatures()
+	h.Respond(w, r, payloads.List(features, len(features)))
+}
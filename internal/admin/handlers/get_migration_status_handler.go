@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+)
+
+// GetMigrationStatus godoc
+// @Summary Get migration status
+// @Description Reports the current goose version, pending migrations, and whether the schema matches the migrations embedded in the binary
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} payloads.Response{data=types.MigrationStatus}
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /admin/migrations [get]
+// @ID GetMigrationStatus
+func (h *AdminHandler) GetMigrationStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := h.service.GetMigrationStatus(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrDatabase(err))
+		return
+	}
+
+	h.Respond(w, r, payloads.OK(status))
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"github.com/Abdelrahman-habib/expense-tracker/internal/admin/service"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type AdminHandler struct {
+	handlers.BaseHandler
+	service service.AdminService
+	admins  map[uuid.UUID]struct{}
+}
+
+func NewAdminHandler(service service.AdminService, adminIDs []uuid.UUID, logger *zap.Logger) *AdminHandler {
+	admins := make(map[uuid.UUID]struct{}, len(adminIDs))
+	for _, id := range adminIDs {
+		admins[id] = struct{}{}
+	}
+
+	return &AdminHandler{
+		BaseHandler: handlers.NewBaseHandler(logger),
+		service:     service,
+		admins:      admins,
+	}
+}
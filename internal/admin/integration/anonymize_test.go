@@ -0,0 +1,506 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/config"
+	adminRoutes "github.com/Abdelrahman-habib/expense-tracker/internal/admin/routes"
+	contactRoutes "github.com/Abdelrahman-habib/expense-tracker/internal/contacts/routes"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/blob"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/jobs/worker"
+	searchRoutes "github.com/Abdelrahman-habib/expense-tracker/internal/search/routes"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"go.uber.org/zap"
+)
+
+type AnonymizeIntegrationTestSuite struct {
+	suite.Suite
+	container testcontainers.Container
+	service   db.Service
+	pool      *pgxpool.Pool
+	blobs     *blob.FileStore
+	router    *chi.Mux
+	adminID   uuid.UUID
+	userID    uuid.UUID
+	ctx       context.Context
+}
+
+func TestAnonymizeIntegrationSuite(t *testing.T) {
+	suite.Run(t, new(AnonymizeIntegrationTestSuite))
+}
+
+func (s *AnonymizeIntegrationTestSuite) SetupSuite() {
+	s.ctx = context.Background()
+	s.adminID = uuid.New()
+
+	var host, port string
+
+	if os.Getenv("CI") == "true" {
+		// Running in GitHub Actions, use service-based PostgreSQL
+		host = "localhost"
+		port = "5432"
+	} else {
+		// Running locally, use TestContainers
+		req := testcontainers.ContainerRequest{
+			Image:        "postgres:15-alpine",
+			ExposedPorts: []string{"5432/tcp"},
+			WaitingFor:   wait.ForListeningPort("5432/tcp"),
+			Env: map[string]string{
+				"POSTGRES_DB":       "testdb",
+				"POSTGRES_USER":     "test",
+				"POSTGRES_PASSWORD": "test",
+			},
+			NetworkMode: "bridge",
+		}
+
+		container, err := testcontainers.GenericContainer(s.ctx, testcontainers.GenericContainerRequest{
+			ContainerRequest: req,
+			Started:          true,
+		})
+		require.NoError(s.T(), err)
+		s.container = container
+
+		host, err = container.Host(s.ctx)
+		require.NoError(s.T(), err)
+		mappedPort, err := container.MappedPort(s.ctx, "5432")
+		require.NoError(s.T(), err)
+		port = mappedPort.Port()
+	}
+
+	cfg := config.DatabaseConfig{
+		Host:        host,
+		Port:        port,
+		Username:    "test",
+		Password:    "test",
+		Database:    "testdb",
+		Schema:      "public",
+		MaxConns:    5,
+		MinConns:    1,
+		MaxLifetime: time.Hour,
+		MaxIdleTime: time.Minute * 30,
+		HealthCheck: time.Minute,
+		SSLMode:     "disable",
+		SearchPath:  "public",
+	}
+
+	dbService := db.NewService(cfg)
+	s.service = dbService
+
+	pool, err := pgxpool.New(s.ctx, cfg.GetDSN())
+	require.NoError(s.T(), err)
+	s.pool = pool
+
+	require.NoError(s.T(), s.runMigrations())
+
+	logger := zap.NewNop()
+	features := config.FeaturesConfig{config.FeatureFullTextSearch: true}
+	jobs := worker.NewRunner(dbService, config.JobsConfig{Workers: 1}, logger)
+
+	s.blobs, err = blob.NewFileStore(s.T().TempDir())
+	require.NoError(s.T(), err)
+
+	router := chi.NewRouter()
+	adminRoutes.New(dbService, s.blobs, logger, config.AdminConfig{UserIDs: []string{s.adminID.String()}}, features).RegisterRoutes(router)
+	contactRoutes.New(dbService, jobs, nil, config.ExportsConfig{}, nil, nil, nil, coreTypes.DefaultLimitPolicy(), logger, nil).RegisterRoutes(router)
+	searchRoutes.New(dbService, logger, features, coreTypes.DefaultLimitPolicy()).RegisterRoutes(router)
+	s.router = router
+}
+
+func (s *AnonymizeIntegrationTestSuite) TearDownSuite() {
+	if s.pool != nil {
+		s.pool.Close()
+	}
+	if s.service != nil {
+		s.service.Close()
+	}
+	if s.container != nil && os.Getenv("CI") != "true" {
+		err := s.container.Terminate(s.ctx)
+		require.NoError(s.T(), err)
+	}
+}
+
+// SetupTest creates a fresh user for each test since anonymizing can't be undone
+func (s *AnonymizeIntegrationTestSuite) SetupTest() {
+	s.userID = uuid.New()
+	_, err := s.pool.Exec(s.ctx, `
+		INSERT INTO users (user_id, external_id, name, email, address_line1, zip_postal_code)
+		VALUES ($1, $2, 'Margaret Thatcher', 'margaret@example.com', '10 Downing Street', 'SW1A 2AA')
+	`, s.userID, s.userID.String())
+	s.Require().NoError(err)
+}
+
+func (s *AnonymizeIntegrationTestSuite) runMigrations() error {
+	migrationsDir := "../../db/sql/migrations"
+
+	sqlDB := stdlib.OpenDBFromPool(s.pool)
+	defer sqlDB.Close()
+
+	if err := goose.SetDialect("postgres"); err != nil {
+		return err
+	}
+	return goose.Up(sqlDB, migrationsDir)
+}
+
+// do sends an authenticated request as the user and decodes the response
+func (s *AnonymizeIntegrationTestSuite) do(userID uuid.UUID, method, path string, body interface{}) (int, map[string]interface{}) {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		s.Require().NoError(err)
+		reader = bytes.NewReader(payload)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+
+	var response map[string]interface{}
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+	return w.Code, response
+}
+
+func (s *AnonymizeIntegrationTestSuite) createContact(payload map[string]interface{}) string {
+	code, response := s.do(s.userID, http.MethodPost, "/contacts", payload)
+	s.Require().Equal(http.StatusCreated, code, response)
+	return response["data"].(map[string]interface{})["contactId"].(string)
+}
+
+// searchCount returns how many results the search at path finds for the user
+func (s *AnonymizeIntegrationTestSuite) searchCount(path string) int {
+	code, response := s.do(s.userID, http.MethodGet, path, nil)
+	s.Require().Equal(http.StatusOK, code, response)
+	results, _ := response["data"].([]interface{})
+	return len(results)
+}
+
+func (s *AnonymizeIntegrationTestSuite) anonymize(userID uuid.UUID) (int, map[string]interface{}) {
+	return s.do(s.adminID, http.MethodPost, "/admin/users/"+userID.String()+"/anonymize", nil)
+}
+
+func (s *AnonymizeIntegrationTestSuite) TestSearchesNoLongerFindOriginalNames() {
+	s.createContact(map[string]interface{}{
+		"name":    "Margaret Thatcher",
+		"email":   "margaret@example.com",
+		"phone":   "+44 20 7219 4272",
+		"company": "Grantham Grocers",
+		"notes":   "Prefers handwritten grocery invoices",
+	})
+	s.createContact(map[string]interface{}{"name": "Denis Thatcher"})
+
+	nameSearch := "/contacts/search?q=" + url.QueryEscape("Thatcher")
+	companySearch := "/contacts/search?q=x&company_q=" + url.QueryEscape("Grantham")
+	notesSearch := "/search/fulltext?types=notes&q=" + url.QueryEscape("handwritten")
+	s.Require().Equal(2, s.searchCount(nameSearch))
+	s.Require().Equal(1, s.searchCount(companySearch))
+	s.Require().Equal(1, s.searchCount(notesSearch))
+
+	code, response := s.anonymize(s.userID)
+	s.Require().Equal(http.StatusOK, code, response)
+
+	s.Zero(s.searchCount(nameSearch))
+	s.Zero(s.searchCount("/contacts/search?q=" + url.QueryEscape("Margaret")))
+	s.Zero(s.searchCount(companySearch))
+	s.Zero(s.searchCount(notesSearch))
+
+	var name, email string
+	err := s.pool.QueryRow(s.ctx, "SELECT name, email FROM users WHERE user_id = $1", s.userID).Scan(&name, &email)
+	s.Require().NoError(err)
+	s.NotContains(name, "Margaret")
+	s.NotContains(email, "margaret")
+}
+
+func (s *AnonymizeIntegrationTestSuite) TestReturnsPerTableCounts() {
+	for _, name := range []string{"Alice", "Bob", "Carol"} {
+		s.createContact(map[string]interface{}{"name": name})
+	}
+	trashed := s.createContact(map[string]interface{}{"name": "Dave"})
+	code, _ := s.do(s.userID, http.MethodDelete, "/contacts/"+trashed, nil)
+	s.Require().Equal(http.StatusOK, code)
+
+	_, err := s.pool.Exec(s.ctx, `
+		INSERT INTO projects (user_id, name, status, description, website)
+		VALUES ($1, 'Kitchen', 'ongoing', 'For Margaret', 'https://example.com')
+	`, s.userID)
+	s.Require().NoError(err)
+
+	code, response := s.anonymize(s.userID)
+	s.Require().Equal(http.StatusOK, code, response)
+
+	data := response["data"].(map[string]interface{})
+	s.Equal(s.userID.String(), data["userId"])
+	s.NotEmpty(data["anonymizedAt"])
+	s.Equal(map[string]interface{}{
+		"users": 1.0, "contacts": 4.0, "projects": 1.0,
+		"relationships": 0.0, "exportSchedules": 0.0, "archives": 0.0,
+	}, data["counts"])
+
+	var description *string
+	err = s.pool.QueryRow(s.ctx, "SELECT description FROM projects WHERE user_id = $1", s.userID).Scan(&description)
+	s.Require().NoError(err)
+	s.Nil(description)
+}
+
+func (s *AnonymizeIntegrationTestSuite) TestDropsForwardedEmails() {
+	_, err := s.pool.Exec(s.ctx, `UPDATE users SET inbound_token = 'mfrggzdfmztwq2lknnwg23tp' WHERE user_id = $1`, s.userID)
+	s.Require().NoError(err)
+	_, err = s.pool.Exec(s.ctx, `
+		INSERT INTO pending_entries (user_id, sender, subject, body, status)
+		VALUES ($1, 'margaret.receipts@example.com', 'Your receipt', 'Thanks Margaret', 'needs_review')
+	`, s.userID)
+	s.Require().NoError(err)
+
+	code, response := s.anonymize(s.userID)
+	s.Require().Equal(http.StatusOK, code, response)
+
+	var entries int
+	s.Require().NoError(s.pool.QueryRow(s.ctx, "SELECT count(*) FROM pending_entries WHERE user_id = $1", s.userID).Scan(&entries))
+	s.Zero(entries)
+
+	var inboundToken *string
+	s.Require().NoError(s.pool.QueryRow(s.ctx, "SELECT inbound_token FROM users WHERE user_id = $1", s.userID).Scan(&inboundToken))
+	s.Nil(inboundToken)
+}
+
+func (s *AnonymizeIntegrationTestSuite) TestKeepsAmountsAndTimestamps() {
+	contactID := s.createContact(map[string]interface{}{"name": "Margaret Thatcher"})
+	_, err := s.pool.Exec(s.ctx, `
+		INSERT INTO wallets (user_id, name, balance, currency)
+		VALUES ($1, 'Cash', 1234.56, 'GBP')
+	`, s.userID)
+	s.Require().NoError(err)
+
+	var createdBefore, updatedBefore time.Time
+	err = s.pool.QueryRow(s.ctx, "SELECT created_at, updated_at FROM contacts WHERE contact_id = $1", contactID).
+		Scan(&createdBefore, &updatedBefore)
+	s.Require().NoError(err)
+
+	code, response := s.anonymize(s.userID)
+	s.Require().Equal(http.StatusOK, code, response)
+
+	var createdAfter, updatedAfter time.Time
+	err = s.pool.QueryRow(s.ctx, "SELECT created_at, updated_at FROM contacts WHERE contact_id = $1", contactID).
+		Scan(&createdAfter, &updatedAfter)
+	s.Require().NoError(err)
+	s.Equal(createdBefore, createdAfter)
+	s.Equal(updatedBefore, updatedAfter)
+
+	var balance string
+	err = s.pool.QueryRow(s.ctx, "SELECT balance::text FROM wallets WHERE user_id = $1", s.userID).Scan(&balance)
+	s.Require().NoError(err)
+	s.Equal("1234.56", balance)
+}
+
+func (s *AnonymizeIntegrationTestSuite) TestBlocksFuturePIIWrites() {
+	contactID := s.createContact(map[string]interface{}{"name": "Margaret Thatcher"})
+
+	code, response := s.anonymize(s.userID)
+	s.Require().Equal(http.StatusOK, code, response)
+	firstAnonymizedAt := response["data"].(map[string]interface{})["anonymizedAt"]
+
+	code, _ = s.do(s.userID, http.MethodPost, "/contacts", map[string]interface{}{"name": "Margaret Thatcher"})
+	s.Equal(http.StatusForbidden, code)
+
+	code, _ = s.do(s.userID, http.MethodPut, "/contacts/"+contactID, map[string]interface{}{"name": "Margaret Thatcher"})
+	s.Equal(http.StatusForbidden, code)
+
+	// trashing doesn't touch PII so it keeps working
+	code, _ = s.do(s.userID, http.MethodDelete, "/contacts/"+contactID, nil)
+	s.Equal(http.StatusOK, code)
+
+	// the marker is irreversible, running again keeps the first timestamp
+	code, response = s.anonymize(s.userID)
+	s.Require().Equal(http.StatusOK, code, response)
+	s.Equal(firstAnonymizedAt, response["data"].(map[string]interface{})["anonymizedAt"])
+}
+
+func (s *AnonymizeIntegrationTestSuite) TestRejectsUnknownUsersAndNonAdmins() {
+	code, _ := s.anonymize(uuid.New())
+	s.Equal(http.StatusNotFound, code)
+
+	code, _ = s.do(s.userID, http.MethodPost, "/admin/users/"+s.userID.String()+"/anonymize", nil)
+	s.Equal(http.StatusForbidden, code)
+
+	var anonymizedAt *time.Time
+	err := s.pool.QueryRow(s.ctx, "SELECT anonymized_at FROM users WHERE user_id = $1", s.userID).Scan(&anonymizedAt)
+	s.Require().NoError(err)
+	s.Nil(anonymizedAt)
+}
+
+// snapshot dumps every table anonymization touches as text, rows in a fixed order
+func (s *AnonymizeIntegrationTestSuite) snapshot() map[string]string {
+	dump := map[string]string{}
+	for _, table := range []string{"users", "contacts", "projects", "pending_entries"} {
+		var rows string
+		err := s.pool.QueryRow(s.ctx, "SELECT coalesce(string_agg(t::text, E'\\n' ORDER BY t::text), '') FROM "+table+" t").Scan(&rows)
+		s.Require().NoError(err)
+		dump[table] = rows
+	}
+	return dump
+}
+
+func (s *AnonymizeIntegrationTestSuite) TestDryRunChangesNothing() {
+	_, err := s.pool.Exec(s.ctx, `UPDATE users SET inbound_token = 'mfrggzdfmztwq2lknnwg23tp' WHERE user_id = $1`, s.userID)
+	s.Require().NoError(err)
+	_, err = s.pool.Exec(s.ctx, `
+		INSERT INTO pending_entries (user_id, sender, subject, body, status)
+		VALUES ($1, 'margaret.receipts@example.com', 'Your receipt', 'Thanks Margaret', 'needs_review')
+	`, s.userID)
+	s.Require().NoError(err)
+	_, err = s.pool.Exec(s.ctx, `
+		INSERT INTO contacts (user_id, name, email)
+		SELECT $1, 'Contact ' || n, 'contact' || n || '@example.com' FROM generate_series(1, 60) n
+	`, s.userID)
+	s.Require().NoError(err)
+	_, err = s.pool.Exec(s.ctx, `
+		INSERT INTO projects (user_id, name, status, description)
+		VALUES ($1, 'Kitchen', 'ongoing', 'For Margaret')
+	`, s.userID)
+	s.Require().NoError(err)
+
+	before := s.snapshot()
+	code, response := s.do(s.adminID, http.MethodPost, "/admin/users/"+s.userID.String()+"/anonymize?dry_run=true", nil)
+	s.Require().Equal(http.StatusOK, code, response)
+	s.Equal(before, s.snapshot())
+
+	s.Equal(true, response["meta"].(map[string]interface{})["dry_run"])
+	data := response["data"].(map[string]interface{})
+	s.Equal(map[string]interface{}{"users": 1.0, "contacts": 60.0, "projects": 1.0}, data["counts"])
+	samples := data["samples"].(map[string]interface{})
+	s.Len(samples["contacts"], 50)
+	s.Len(samples["projects"], 1)
+
+	// the real run still goes through afterwards
+	code, response = s.anonymize(s.userID)
+	s.Require().Equal(http.StatusOK, code, response)
+	s.NotContains(response["data"], "samples")
+	s.NotEqual(before["contacts"], s.snapshot()["contacts"])
+}
+
+// requirePIIBlocked checks the write was rejected by the PII trigger
+func (s *AnonymizeIntegrationTestSuite) requirePIIBlocked(err error) {
+	var pgErr *pgconn.PgError
+	s.Require().True(errors.As(err, &pgErr), "expected a postgres error, got %v", err)
+	s.Equal("ET002", pgErr.Code)
+}
+
+func (s *AnonymizeIntegrationTestSuite) TestClearsRelationshipNotes() {
+	accountant := s.createContact(map[string]interface{}{"name": "Alfred Roberts"})
+	supplier := s.createContact(map[string]interface{}{"name": "Grantham Grocers"})
+	_, err := s.pool.Exec(s.ctx, `
+		INSERT INTO contact_relationships (user_id, from_contact_id, to_contact_id, type, note)
+		VALUES ($1, $2, $3, 'works_for', 'Father of Margaret, call him before noon')
+	`, s.userID, accountant, supplier)
+	s.Require().NoError(err)
+
+	code, response := s.anonymize(s.userID)
+	s.Require().Equal(http.StatusOK, code, response)
+	counts := response["data"].(map[string]interface{})["counts"].(map[string]interface{})
+	s.Equal(1.0, counts["relationships"])
+
+	var note *string
+	err = s.pool.QueryRow(s.ctx, "SELECT note FROM contact_relationships WHERE user_id = $1", s.userID).Scan(&note)
+	s.Require().NoError(err)
+	s.Nil(note)
+
+	_, err = s.pool.Exec(s.ctx, "UPDATE contact_relationships SET note = 'Margaret again' WHERE user_id = $1", s.userID)
+	s.requirePIIBlocked(err)
+}
+
+func (s *AnonymizeIntegrationTestSuite) TestDisablesExportSchedules() {
+	_, err := s.pool.Exec(s.ctx, `
+		INSERT INTO export_schedules (user_id, entity_type, format, cadence, delivery_type, delivery_target, signing_secret, next_run_at)
+		VALUES ($1, 'contacts', 'csv', 'daily', 'email', 'margaret@example.com', 'secret', CURRENT_TIMESTAMP - INTERVAL '1 hour')
+	`, s.userID)
+	s.Require().NoError(err)
+
+	code, response := s.anonymize(s.userID)
+	s.Require().Equal(http.StatusOK, code, response)
+	counts := response["data"].(map[string]interface{})["counts"].(map[string]interface{})
+	s.Equal(1.0, counts["exportSchedules"])
+
+	var target string
+	var disabledAt *time.Time
+	err = s.pool.QueryRow(s.ctx, "SELECT delivery_target, disabled_at FROM export_schedules WHERE user_id = $1", s.userID).
+		Scan(&target, &disabledAt)
+	s.Require().NoError(err)
+	s.Empty(target)
+	s.NotNil(disabledAt)
+
+	// the schedule is due but disabled, schedulers never claim it again
+	now := time.Now().UTC()
+	_, err = db.New(s.pool).ClaimDueExportSchedule(s.ctx, db.ClaimDueExportScheduleParams{
+		Now:          pgtype.Timestamp{Time: now, Valid: true},
+		ClaimedUntil: pgtype.Timestamp{Time: now.Add(time.Minute), Valid: true},
+	})
+	s.ErrorIs(err, pgx.ErrNoRows)
+
+	_, err = s.pool.Exec(s.ctx, "UPDATE export_schedules SET delivery_target = 'margaret@example.com' WHERE user_id = $1", s.userID)
+	s.requirePIIBlocked(err)
+}
+
+func (s *AnonymizeIntegrationTestSuite) TestDeletesStoredArchives() {
+	keys := []string{"exports/" + s.userID.String() + "/contacts.csv", "backups/" + s.userID.String() + "/backup.zip"}
+	for _, key := range keys {
+		err := s.blobs.Put(s.ctx, key, func(w io.Writer) error {
+			_, err := io.WriteString(w, "Margaret Thatcher,margaret@example.com")
+			return err
+		})
+		s.Require().NoError(err)
+		_, err = s.pool.Exec(s.ctx, `
+			INSERT INTO jobs (user_id, type, status, payload, result_key, completed_at)
+			VALUES ($1, 'export', 'completed', '{}', $2, CURRENT_TIMESTAMP)
+		`, s.userID, key)
+		s.Require().NoError(err)
+	}
+
+	// a dry run reports the archives but keeps them
+	code, response := s.do(s.adminID, http.MethodPost, "/admin/users/"+s.userID.String()+"/anonymize?dry_run=true", nil)
+	s.Require().Equal(http.StatusOK, code, response)
+	counts := response["data"].(map[string]interface{})["counts"].(map[string]interface{})
+	s.Equal(2.0, counts["archives"])
+	for _, key := range keys {
+		archive, err := s.blobs.Open(s.ctx, key)
+		s.Require().NoError(err)
+		archive.Close()
+	}
+
+	code, response = s.anonymize(s.userID)
+	s.Require().Equal(http.StatusOK, code, response)
+	counts = response["data"].(map[string]interface{})["counts"].(map[string]interface{})
+	s.Equal(2.0, counts["archives"])
+
+	for _, key := range keys {
+		_, err := s.blobs.Open(s.ctx, key)
+		s.ErrorIs(err, blob.ErrNotFound)
+	}
+	var jobs int
+	s.Require().NoError(s.pool.QueryRow(s.ctx, "SELECT count(*) FROM jobs WHERE user_id = $1", s.userID).Scan(&jobs))
+	s.Zero(jobs)
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/admin/anonymize"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/admin/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/blob"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/bulk"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/google/uuid"
+)
+
+// AnonymizationRepository replaces the PII of a user's rows with pseudonyms
+type AnonymizationRepository interface {
+	// AnonymizeUser anonymizes the user's rows, with dryRun it reports what it would
+	// anonymize and rolls back
+	AnonymizeUser(ctx context.Context, userID uuid.UUID, pseudonymizer *anonymize.Pseudonymizer, dryRun bool) (types.AnonymizationResult, error)
+}
+
+type anonymizationRepository struct {
+	db        bulk.TxBeginner
+	blobs     blob.Store
+	batchSize int
+}
+
+// NewAnonymizationRepository creates an anonymization repository writing batchSize
+// rows per transaction and deleting the user's archives from blobs
+func NewAnonymizationRepository(db bulk.TxBeginner, blobs blob.Store, batchSize int) AnonymizationRepository {
+	if batchSize <= 0 {
+		batchSize = bulk.DefaultChunkSize
+	}
+	return &anonymizationRepository{
+		db:        db,
+		blobs:     blobs,
+		batchSize: batchSize,
+	}
+}
+
+// inTx runs fn in a transaction of conn allowed to write the PII columns of anonymized users
+func inTx(ctx context.Context, conn bulk.TxBeginner, fn func(q *db.Queries) error) (err error) {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	q := db.New(tx)
+	if err = q.AllowAnonymizedWrites(ctx); err != nil {
+		return err
+	}
+	if err = fn(q); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/admin/anonymize"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/admin/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/bulk"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/paging"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/google/uuid"
+)
+
+// AnonymizeUser marks the user as anonymized first, so the PII triggers reject new
+// writes while the batches run, drops the forwarded emails, clears the relationship
+// notes and disables the export schedules, then scrubs contacts and projects and
+// deletes the stored archives batch by batch.
+// A failed run leaves the marker in place and can simply be run again.
+// A dry run does all of it in one transaction that is rolled back and keeps the
+// archives, its result samples the IDs of the contacts and projects it would scrub.
+func (r *anonymizationRepository) AnonymizeUser(ctx context.Context, userID uuid.UUID, pseudonymizer *anonymize.Pseudonymizer, dryRun bool) (types.AnonymizationResult, error) {
+	var result types.AnonymizationResult
+	err := RunMaintenance(ctx, r.db, anonymization, dryRun, func(conn bulk.TxBeginner) error {
+		var err error
+		result, err = r.anonymizeUser(ctx, conn, userID, pseudonymizer, dryRun)
+		return err
+	})
+	if err != nil {
+		return types.AnonymizationResult{}, err
+	}
+	return result, nil
+}
+
+func (r *anonymizationRepository) anonymizeUser(ctx context.Context, conn bulk.TxBeginner, userID uuid.UUID, pseudonymizer *anonymize.Pseudonymizer, dryRun bool) (types.AnonymizationResult, error) {
+	result := types.AnonymizationResult{UserID: userID}
+	var contactSamples, projectSamples *[]uuid.UUID
+	if dryRun {
+		result.Samples = &types.AnonymizationSamples{Contacts: []uuid.UUID{}, Projects: []uuid.UUID{}}
+		contactSamples, projectSamples = &result.Samples.Contacts, &result.Samples.Projects
+	}
+
+	err := inTx(ctx, conn, func(q *db.Queries) error {
+		user, err := q.GetUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+		anonymizedAt, err := q.AnonymizeUser(ctx, pseudonymizer.ScrubUser(user))
+		if err != nil {
+			return err
+		}
+		result.AnonymizedAt = coreTypes.NewTimestamp(anonymizedAt.Time)
+		result.Counts.Users = 1
+		// forwarded emails are raw PII with nothing worth keeping once scrubbed
+		if _, err = q.DeletePendingEntries(ctx, userID); err != nil {
+			return err
+		}
+		// and so are the snapshots of merged contacts
+		if _, err = q.DeleteMergeAudit(ctx, userID); err != nil {
+			return err
+		}
+		if result.Counts.Relationships, err = q.ClearRelationshipNotes(ctx, userID); err != nil {
+			return err
+		}
+		// schedules would keep mailing the scrubbed data to the address they hold
+		result.Counts.ExportSchedules, err = q.DisableExportSchedules(ctx, userID)
+		return err
+	})
+	if err != nil {
+		return types.AnonymizationResult{}, errors.HandleRepositoryError(err, "anonymize", "user")
+	}
+
+	if result.Counts.Contacts, err = r.anonymizeContacts(ctx, conn, userID, pseudonymizer, contactSamples); err != nil {
+		return types.AnonymizationResult{}, errors.HandleRepositoryError(err, "anonymize", "contacts")
+	}
+	if result.Counts.Projects, err = r.anonymizeProjects(ctx, conn, userID, pseudonymizer, projectSamples); err != nil {
+		return types.AnonymizationResult{}, errors.HandleRepositoryError(err, "anonymize", "projects")
+	}
+	if result.Counts.Archives, err = r.deleteArchives(ctx, conn, userID, dryRun); err != nil {
+		return types.AnonymizationResult{}, errors.HandleRepositoryError(err, "anonymize", "archives")
+	}
+
+	return result, nil
+}
+
+// deleteArchives deletes the user's export and backup archives with their jobs, each
+// archive before its job so a failure leaves the job to find the archive again. A dry
+// run only deletes the jobs, which are rolled back.
+func (r *anonymizationRepository) deleteArchives(ctx context.Context, conn bulk.TxBeginner, userID uuid.UUID, dryRun bool) (int64, error) {
+	var count int64
+	for {
+		var deleted int64
+		err := inTx(ctx, conn, func(q *db.Queries) error {
+			archives, err := q.ListUserArchives(ctx, db.ListUserArchivesParams{
+				UserID:    userID,
+				BatchSize: int32(r.batchSize),
+			})
+			if err != nil || len(archives) == 0 {
+				return err
+			}
+
+			jobIDs := make([]uuid.UUID, len(archives))
+			for i, archive := range archives {
+				if !dryRun {
+					if err := r.blobs.Delete(ctx, archive.ResultKey.String); err != nil {
+						return err
+					}
+				}
+				jobIDs[i] = archive.JobID
+			}
+			deleted, err = q.DeleteJobs(ctx, jobIDs)
+			return err
+		})
+		if err != nil {
+			return count, err
+		}
+		count += deleted
+		if deleted < int64(r.batchSize) {
+			return count, nil
+		}
+	}
+}
+
+// anonymizeContacts scrubs the user's contacts, adding their IDs to samples unless nil
+func (r *anonymizationRepository) anonymizeContacts(ctx context.Context, conn bulk.TxBeginner, userID uuid.UUID, pseudonymizer *anonymize.Pseudonymizer, samples *[]uuid.UUID) (int64, error) {
+	var count int64
+	// each batch is scrubbed in its own transaction, the next one starts after its last ID
+	err := paging.ForEachPage(ctx, func(ctx context.Context, after uuid.UUID) ([]db.Contact, uuid.UUID, error) {
+		var batch []db.Contact
+		err := inTx(ctx, conn, func(q *db.Queries) error {
+			var err error
+			batch, err = q.ListContactsForAnonymization(ctx, db.ListContactsForAnonymizationParams{
+				UserID:    userID,
+				ContactID: after,
+				Limit:     int32(r.batchSize),
+			})
+			if err != nil {
+				return err
+			}
+			for _, contact := range batch {
+				if err := q.AnonymizeContact(ctx, pseudonymizer.ScrubContact(contact)); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		return batch, paging.Next(batch, r.batchSize, func(contact db.Contact) uuid.UUID { return contact.ContactID }), err
+	}, func(contact db.Contact) error {
+		count++
+		if samples != nil {
+			*samples = types.AppendSample(*samples, contact.ContactID)
+		}
+		return nil
+	})
+	return count, err
+}
+
+// anonymizeProjects scrubs the user's projects, adding their IDs to samples unless nil
+func (r *anonymizationRepository) anonymizeProjects(ctx context.Context, conn bulk.TxBeginner, userID uuid.UUID, pseudonymizer *anonymize.Pseudonymizer, samples *[]uuid.UUID) (int64, error) {
+	var count int64
+	err := paging.ForEachPage(ctx, func(ctx context.Context, after uuid.UUID) ([]db.Project, uuid.UUID, error) {
+		var batch []db.Project
+		err := inTx(ctx, conn, func(q *db.Queries) error {
+			var err error
+			batch, err = q.ListProjectsForAnonymization(ctx, db.ListProjectsForAnonymizationParams{
+				UserID:    userID,
+				ProjectID: after,
+				Limit:     int32(r.batchSize),
+			})
+			if err != nil {
+				return err
+			}
+			for _, project := range batch {
+				if err := q.AnonymizeProject(ctx, pseudonymizer.ScrubProject(project)); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		return batch, paging.Next(batch, r.batchSize, func(project db.Project) uuid.UUID { return project.ProjectID }), err
+	}, func(project db.Project) error {
+		count++
+		if samples != nil {
+			*samples = types.AppendSample(*samples, project.ProjectID)
+		}
+		return nil
+	})
+	return count, err
+}
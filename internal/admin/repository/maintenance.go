@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/bulk"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+)
+
+// Maintenance describes an admin maintenance operation run with RunMaintenance
+type Maintenance struct {
+	// Name names the operation in errors
+	Name string
+	// Transactional reports whether everything the operation changes is in the database,
+	// so rolling back undoes it. Operations deleting files aren't, they can't be dry run.
+	Transactional bool
+}
+
+// anonymization anonymizes a user's rows
+var anonymization = Maintenance{Name: "anonymization", Transactional: true}
+
+// RunMaintenance runs fn, the operation m, against db. With dryRun fn runs against a
+// single transaction instead: the transactions fn begins become savepoints in it and it
+// is rolled back once fn returns, failed, succeeded or panicked, so fn reads and reports
+// everything it would change while nothing is kept. Dry runs of operations that aren't
+// transactional are refused with a validation error.
+func RunMaintenance(ctx context.Context, db bulk.TxBeginner, m Maintenance, dryRun bool, fn func(db bulk.TxBeginner) error) (err error) {
+	if !dryRun {
+		return fn(db)
+	}
+	if !m.Transactional {
+		return errors.NewValidationError("dry_run: %s changes more than the database and can't be dry run", m.Name)
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// a canceled request still rolls back
+		if rollbackErr := tx.Rollback(context.WithoutCancel(ctx)); rollbackErr != nil && err == nil {
+			err = rollbackErr
+		}
+	}()
+
+	return fn(tx)
+}
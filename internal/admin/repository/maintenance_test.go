@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/bulk"
+	coreErrors "github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTx records how the transaction of a dry run ended
+type fakeTx struct {
+	pgx.Tx
+	committed  bool
+	rolledBack bool
+}
+
+func (t *fakeTx) Commit(ctx context.Context) error {
+	t.committed = true
+	return nil
+}
+
+func (t *fakeTx) Rollback(ctx context.Context) error {
+	t.rolledBack = true
+	return nil
+}
+
+type fakeBeginner struct {
+	tx    *fakeTx
+	begun int
+}
+
+func (b *fakeBeginner) Begin(ctx context.Context) (pgx.Tx, error) {
+	b.begun++
+	return b.tx, nil
+}
+
+func TestRunMaintenance(t *testing.T) {
+	ctx := context.Background()
+	transactional := Maintenance{Name: "cleanup", Transactional: true}
+
+	t.Run("runs against the database", func(t *testing.T) {
+		db := &fakeBeginner{tx: &fakeTx{}}
+		err := RunMaintenance(ctx, db, transactional, false, func(conn bulk.TxBeginner) error {
+			assert.Same(t, db, conn)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Zero(t, db.begun)
+	})
+
+	t.Run("dry run rolls back", func(t *testing.T) {
+		db := &fakeBeginner{tx: &fakeTx{}}
+		err := RunMaintenance(ctx, db, transactional, true, func(conn bulk.TxBeginner) error {
+			assert.Same(t, db.tx, conn)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, db.begun)
+		assert.True(t, db.tx.rolledBack)
+		assert.False(t, db.tx.committed)
+	})
+
+	t.Run("dry run rolls back a failed run", func(t *testing.T) {
+		db := &fakeBeginner{tx: &fakeTx{}}
+		err := RunMaintenance(ctx, db, transactional, true, func(conn bulk.TxBeginner) error {
+			return errors.New("db error")
+		})
+		assert.EqualError(t, err, "db error")
+		assert.True(t, db.tx.rolledBack)
+	})
+
+	t.Run("dry run rolls back on panic", func(t *testing.T) {
+		db := &fakeBeginner{tx: &fakeTx{}}
+		assert.Panics(t, func() {
+			_ = RunMaintenance(ctx, db, transactional, true, func(conn bulk.TxBeginner) error {
+				panic("boom")
+			})
+		})
+		assert.True(t, db.tx.rolledBack)
+	})
+
+	t.Run("refuses dry runs of operations outside the database", func(t *testing.T) {
+		db := &fakeBeginner{tx: &fakeTx{}}
+		called := false
+		err := RunMaintenance(ctx, db, Maintenance{Name: "file cleanup"}, true, func(conn bulk.TxBeginner) error {
+			called = true
+			return nil
+		})
+		var validation *coreErrors.ErrorResponse
+		if assert.ErrorAs(t, err, &validation) {
+			assert.Equal(t, coreErrors.ErrorTypeValidation, validation.Type)
+		}
+		assert.Contains(t, err.Error(), "file cleanup changes more than the database and can't be dry run")
+		assert.False(t, called)
+		assert.Zero(t, db.begun)
+	})
+}
@@ -0,0 +1,54 @@
+package routes
+
+import (
+	"github.com/Abdelrahman-habib/expense-tracker/config"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/admin/handlers"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/admin/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/admin/service"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/blob"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/bulk"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Router encapsulates the admin routes setup
+type Router struct {
+	handler *handlers.AdminHandler
+}
+
+// New creates a new admin router with proper dependency injection
+func New(dbService db.Service, blobs blob.Store, logger *zap.Logger, cfg config.AdminConfig, features config.FeaturesConfig) *Router {
+	// Parse the configured admin IDs, skipping invalid entries
+	adminIDs := make([]uuid.UUID, 0, len(cfg.UserIDs))
+	for _, raw := range cfg.UserIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			logger.Warn("ignoring invalid admin user id", zap.String("user_id", raw), zap.Error(err))
+			continue
+		}
+		adminIDs = append(adminIDs, id)
+	}
+
+	// Initialize service with the db service
+	anonymizer := repository.NewAnonymizationRepository(dbService, blobs, bulk.DefaultChunkSize)
+	adminService := service.NewAdminService(dbService, anonymizer, features, logger)
+
+	// Initialize handler with service
+	handler := handlers.NewAdminHandler(adminService, adminIDs, logger)
+
+	return &Router{
+		handler: handler,
+	}
+}
+
+// RegisterRoutes registers all admin routes
+func (r *Router) RegisterRoutes(router chi.Router) {
+	router.Route("/admin", func(router chi.Router) {
+		router.Use(r.handler.RequireAdmin)
+		router.Get("/migrations", r.handler.GetMigrationStatus)
+		router.Post("/users/{id}/anonymize", r.handler.AnonymizeUser)
+	})
+	router.With(r.handler.RequireAdmin).Get("/features", r.handler.GetFeatures)
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Abdelrahman-habib/expense-tracker/config"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/admin/anonymize"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/admin/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/admin/types"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type AdminService interface {
+	GetMigrationStatus(ctx context.Context) (types.MigrationStatus, error)
+	ListFeatures() []types.Feature
+	AnonymizeUser(ctx context.Context, userID uuid.UUID, dryRun bool) (types.AnonymizationResult, error)
+}
+
+// MigrationsReader reports the migrations state of the database
+type MigrationsReader interface {
+	MigrationsStatus(ctx context.Context) (int64, []db.MigrationState, error)
+}
+
+type adminService struct {
+	migrations MigrationsReader
+	anonymizer repository.AnonymizationRepository
+	features   config.FeaturesConfig
+	logger     *zap.Logger
+}
+
+func NewAdminService(migrations MigrationsReader, anonymizer repository.AnonymizationRepository, features config.FeaturesConfig, logger *zap.Logger) AdminService {
+	return &adminService{
+		migrations: migrations,
+		anonymizer: anonymizer,
+		features:   features,
+		logger:     logger.With(zap.String("component", "admin_service")),
+	}
+}
+
+func (s *adminService) GetMigrationStatus(ctx context.Context) (types.MigrationStatus, error) {
+	s.logger.Info("getting migration status")
+
+	version, states, err := s.migrations.MigrationsStatus(ctx)
+	if err != nil {
+		return types.MigrationStatus{}, err
+	}
+
+	status := types.MigrationStatus{
+		CurrentVersion: version,
+		Pending:        []types.Migration{},
+		Applied:        []types.Migration{},
+	}
+
+	for _, state := range states {
+		migration := types.Migration{
+			Version: state.Version,
+			Source:  state.Source,
+		}
+		if state.Version > status.LatestVersion {
+			status.LatestVersion = state.Version
+		}
+		if !state.Applied {
+			status.Pending = append(status.Pending, migration)
+			continue
+		}
+		appliedAt := state.AppliedAt
+		migration.AppliedAt = coreTypes.TimestampPtr(&appliedAt)
+		status.Applied = append(status.Applied, migration)
+	}
+
+	// a database ahead of the embedded set means the binary is older than the schema
+	status.UpToDate = len(status.Pending) == 0 && status.CurrentVersion == status.LatestVersion
+
+	if !status.UpToDate {
+		s.logger.Warn("database schema does not match embedded migrations",
+			zap.Int64("current_version", status.CurrentVersion),
+			zap.Int64("latest_version", status.LatestVersion),
+			zap.Int("pending", len(status.Pending)))
+	}
+
+	return status, nil
+}
+
+// ListFeatures returns the configured feature flags ordered by name
+func (s *adminService) ListFeatures() []types.Feature {
+	s.logger.Info("listing feature flags")
+
+	features := make([]types.Feature, 0, len(s.features))
+	for name, enabled := range s.features {
+		features = append(features, types.Feature{Name: name, Enabled: enabled})
+	}
+	sort.Slice(features, func(i, j int) bool {
+		return features[i].Name < features[j].Name
+	})
+	return features
+}
+
+// AnonymizeUser irreversibly replaces the user's PII with pseudonyms. Each run gets a
+// fresh key that is never stored, so the pseudonyms can't be traced back afterwards.
+// A dry run only reports what would be anonymized.
+func (s *adminService) AnonymizeUser(ctx context.Context, userID uuid.UUID, dryRun bool) (types.AnonymizationResult, error) {
+	s.logger.Info("anonymizing user", zap.String("user_id", userID.String()), zap.Bool("dry_run", dryRun))
+
+	pseudonymizer, err := anonymize.NewRunPseudonymizer()
+	if err != nil {
+		return types.AnonymizationResult{}, err
+	}
+
+	result, err := s.anonymizer.AnonymizeUser(ctx, userID, pseudonymizer, dryRun)
+	if err != nil {
+		s.logger.Error("failed to anonymize user", zap.String("user_id", userID.String()), zap.Error(err))
+		return types.AnonymizationResult{}, err
+	}
+
+	s.logger.Info("anonymized user",
+		zap.String("user_id", userID.String()),
+		zap.Bool("dry_run", dryRun),
+		zap.Int64("users", result.Counts.Users),
+		zap.Int64("contacts", result.Counts.Contacts),
+		zap.Int64("projects", result.Counts.Projects),
+		zap.Int64("relationships", result.Counts.Relationships),
+		zap.Int64("export_schedules", result.Counts.ExportSchedules),
+		zap.Int64("archives", result.Counts.Archives))
+
+	return result, nil
+}
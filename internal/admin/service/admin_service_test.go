@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/config"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/admin/anonymize"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/admin/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// Mock migrations reader
+type mockMigrationsReader struct {
+	mock.Mock
+}
+
+func (m *mockMigrationsReader) MigrationsStatus(ctx context.Context) (int64, []db.MigrationState, error) {
+	args := m.Called(ctx)
+	if args.Get(1) == nil {
+		return args.Get(0).(int64), nil, args.Error(2)
+	}
+	return args.Get(0).(int64), args.Get(1).([]db.MigrationState), args.Error(2)
+}
+
+// Mock anonymization repository
+type mockAnonymizationRepository struct {
+	mock.Mock
+}
+
+func (m *mockAnonymizationRepository) AnonymizeUser(ctx context.Context, userID uuid.UUID, pseudonymizer *anonymize.Pseudonymizer, dryRun bool) (types.AnonymizationResult, error) {
+	args := m.Called(ctx, userID, pseudonymizer, dryRun)
+	return args.Get(0).(types.AnonymizationResult), args.Error(1)
+}
+
+func TestAdminService_GetMigrationStatus(t *testing.T) {
+	ctx := context.Background()
+	appliedAt := time.Now()
+
+	tests := []struct {
+		name         string
+		version      int64
+		states       []db.MigrationState
+		err          error
+		wantErr      bool
+		wantPending  int
+		wantLatest   int64
+		wantUpToDate bool
+	}{
+		{
+			name:    "all migrations applied",
+			version: 2,
+			states: []db.MigrationState{
+				{Version: 1, Source: "1_init.sql", Applied: true, AppliedAt: appliedAt},
+				{Version: 2, Source: "2_more.sql", Applied: true, AppliedAt: appliedAt},
+			},
+			wantLatest:   2,
+			wantUpToDate: true,
+		},
+		{
+			name:    "pending migrations",
+			version: 1,
+			states: []db.MigrationState{
+				{Version: 1, Source: "1_init.sql", Applied: true, AppliedAt: appliedAt},
+				{Version: 2, Source: "2_more.sql"},
+			},
+			wantPending: 1,
+			wantLatest:  2,
+		},
+		{
+			name:    "database ahead of embedded migrations",
+			version: 3,
+			states: []db.MigrationState{
+				{Version: 1, Source: "1_init.sql", Applied: true, AppliedAt: appliedAt},
+				{Version: 2, Source: "2_more.sql", Applied: true, AppliedAt: appliedAt},
+			},
+			wantLatest: 2,
+		},
+		{
+			name:    "reader error",
+			err:     errors.New("db error"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := new(mockMigrationsReader)
+			service := NewAdminService(reader, nil, nil, zap.NewNop())
+			if tt.err != nil {
+				reader.On("MigrationsStatus", ctx).Return(int64(0), nil, tt.err)
+			} else {
+				reader.On("MigrationsStatus", ctx).Return(tt.version, tt.states, nil)
+			}
+
+			status, err := service.GetMigrationStatus(ctx)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.version, status.CurrentVersion)
+			assert.Equal(t, tt.wantLatest, status.LatestVersion)
+			assert.Len(t, status.Pending, tt.wantPending)
+			assert.Equal(t, tt.wantUpToDate, status.UpToDate)
+			reader.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdminService_ListFeatures(t *testing.T) {
+	service := NewAdminService(new(mockMigrationsReader), nil, config.FeaturesConfig{
+		"transactions":    false,
+		"fulltext_search": true,
+	}, zap.NewNop())
+
+	assert.Equal(t, []types.Feature{
+		{Name: "fulltext_search", Enabled: true},
+		{Name: "transactions", Enabled: false},
+	}, service.ListFeatures())
+
+	empty := NewAdminService(new(mockMigrationsReader), nil, nil, zap.NewNop())
+	assert.Empty(t, empty.ListFeatures())
+}
+
+func TestAdminService_AnonymizeUser(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("returns the counts", func(t *testing.T) {
+		repo := new(mockAnonymizationRepository)
+		service := NewAdminService(new(mockMigrationsReader), repo, nil, zap.NewNop())
+		expected := types.AnonymizationResult{
+			UserID: userID,
+			Counts: types.AnonymizationCounts{Users: 1, Contacts: 5, Projects: 2},
+		}
+		repo.On("AnonymizeUser", ctx, userID, mock.AnythingOfType("*anonymize.Pseudonymizer"), false).Return(expected, nil)
+
+		result, err := service.AnonymizeUser(ctx, userID, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		repo := new(mockAnonymizationRepository)
+		service := NewAdminService(new(mockMigrationsReader), repo, nil, zap.NewNop())
+		expected := types.AnonymizationResult{
+			UserID:  userID,
+			Counts:  types.AnonymizationCounts{Users: 1, Contacts: 1},
+			Samples: &types.AnonymizationSamples{Contacts: []uuid.UUID{uuid.New()}, Projects: []uuid.UUID{}},
+		}
+		repo.On("AnonymizeUser", ctx, userID, mock.AnythingOfType("*anonymize.Pseudonymizer"), true).Return(expected, nil)
+
+		result, err := service.AnonymizeUser(ctx, userID, true)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("uses a new key each run", func(t *testing.T) {
+		repo := new(mockAnonymizationRepository)
+		service := NewAdminService(new(mockMigrationsReader), repo, nil, zap.NewNop())
+		var names []string
+		repo.On("AnonymizeUser", ctx, userID, mock.Anything, false).
+			Run(func(args mock.Arguments) {
+				names = append(names, args.Get(2).(*anonymize.Pseudonymizer).Name("Jane Doe"))
+			}).
+			Return(types.AnonymizationResult{}, nil)
+
+		_, _ = service.AnonymizeUser(ctx, userID, false)
+		_, _ = service.AnonymizeUser(ctx, userID, false)
+
+		assert.Len(t, names, 2)
+		assert.NotEqual(t, names[0], names[1])
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		repo := new(mockAnonymizationRepository)
+		service := NewAdminService(new(mockMigrationsReader), repo, nil, zap.NewNop())
+		repo.On("AnonymizeUser", ctx, userID, mock.Anything, false).Return(types.AnonymizationResult{}, errors.New("db error"))
+
+		_, err := service.AnonymizeUser(ctx, userID, false)
+
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,37 @@
+package types
+
+import (
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/google/uuid"
+)
+
+// AnonymizationCounts is the number of rows anonymized in each table
+// @Description Rows whose PII was replaced or deleted, per table
+type AnonymizationCounts struct {
+	Users    int64 `json:"users" example:"1"`
+	Contacts int64 `json:"contacts" example:"42"`
+	Projects int64 `json:"projects" example:"3"`
+	// Relationships is the number of relationship notes cleared
+	Relationships int64 `json:"relationships" example:"4"`
+	// ExportSchedules is the number of schedules disabled
+	ExportSchedules int64 `json:"exportSchedules" example:"1"`
+	// Archives is the number of stored export and backup archives deleted
+	Archives int64 `json:"archives" example:"2"`
+}
+
+// AnonymizationSamples are IDs of the rows a dry run would anonymize
+// @Description IDs of rows a dry run would anonymize, at most 50 per table
+type AnonymizationSamples struct {
+	Contacts []uuid.UUID `json:"contacts" maxItems:"50"`
+	Projects []uuid.UUID `json:"projects" maxItems:"50"`
+}
+
+// AnonymizationResult represents the outcome of anonymizing a user
+// @Description User whose PII was pseudonymized, when it was first anonymized and the rows touched
+type AnonymizationResult struct {
+	UserID       uuid.UUID           `json:"userId" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
+	AnonymizedAt coreTypes.Timestamp `json:"anonymizedAt" example:"2024-01-01T00:00:00.000Z" swaggertype:"string" format:"date-time"`
+	Counts       AnonymizationCounts `json:"counts"`
+	// Samples is only reported by dry runs
+	Samples *AnonymizationSamples `json:"samples,omitempty"`
+}
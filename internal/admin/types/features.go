@@ -0,0 +1,8 @@
+package types
+
+// Feature represents a feature flag and its current state
+// @Description Feature flag as configured for the running environment
+type Feature struct {
+	Name    string `json:"name" example:"fulltext_search"`
+	Enabled bool   `json:"enabled" example:"true"`
+}
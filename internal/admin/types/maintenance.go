@@ -0,0 +1,14 @@
+package types
+
+import "github.com/google/uuid"
+
+// MaxDryRunSamples is the most IDs a dry run reports for each table it would change
+const MaxDryRunSamples = 50
+
+// AppendSample appends id to the samples of a dry run until they hold MaxDryRunSamples
+func AppendSample(samples []uuid.UUID, id uuid.UUID) []uuid.UUID {
+	if len(samples) >= MaxDryRunSamples {
+		return samples
+	}
+	return append(samples, id)
+}
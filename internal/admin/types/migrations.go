@@ -0,0 +1,21 @@
+package types
+
+import coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+
+// Migration represents a single embedded migration
+// @Description Embedded migration and the time it was applied, if any
+type Migration struct {
+	Version   int64                `json:"version" example:"2025011601"`
+	Source    string               `json:"source" example:"2025011601_create_base_tables.sql"`
+	AppliedAt *coreTypes.Timestamp `json:"appliedAt,omitempty" example:"2024-01-01T00:00:00.000Z" swaggertype:"string" format:"date-time"`
+}
+
+// MigrationStatus represents the state of the database schema
+// @Description Current goose version compared against the migrations embedded in the binary
+type MigrationStatus struct {
+	CurrentVersion int64       `json:"currentVersion" example:"20250204033958"`
+	LatestVersion  int64       `json:"latestVersion" example:"20250204033958"`
+	Pending        []Migration `json:"pending"`
+	Applied        []Migration `json:"applied"`
+	UpToDate       bool        `json:"upToDate" example:"true"`
+}
@@ -4,19 +4,38 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/Abdelrahman-habib/expense-tracker/config"
+	backupService "github.com/Abdelrahman-habib/expense-tracker/internal/backups/service"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/blob"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/quota"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/tracing"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/events"
+	exportScheduleRepository "github.com/Abdelrahman-habib/expense-tracker/internal/exportschedules/repository"
+	exportScheduleService "github.com/Abdelrahman-habib/expense-tracker/internal/exportschedules/service"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/jobs/worker"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/server"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/server/lifecycle"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/version"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.uber.org/zap"
 )
 
+// tracingShutdownTimeout bounds flushing the last spans on shutdown
+const tracingShutdownTimeout = 5 * time.Second
+
 // App represents the application and its dependencies
 type App struct {
 	config     *config.Config
 	logger     *zap.Logger
 	db         db.Service
+	tracing    *sdktrace.TracerProvider
+	jobs       *worker.Runner
+	janitor    *Janitor
+	exports    *exportScheduleService.Scheduler
+	stopJobs   context.CancelFunc
 	httpServer *http.Server
 }
 
@@ -28,39 +47,146 @@ func New(cfg *config.Config) (*App, error) {
 		logger = zap.Must(zap.NewDevelopment())
 	}
 
-	// Initialize database
-	dbService := db.NewService(cfg.Database)
+	// Initialize tracing, nil while it's disabled
+	tracerProvider, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		return nil, err
+	}
+	tracer := tracing.Tracer(tracerProvider)
+
+	// Initialize database, tracing its queries
+	dbService := db.NewService(cfg.Database, db.WithQueryTracer(tracing.NewQueryTracer(tracer)))
+
+	// Initialize background job runner; processors are registered by the routes
+	jobRunner := worker.NewRunner(dbService, cfg.Jobs, logger)
+
+	// Initialize the blob store keeping the files of background exports
+	blobs, err := blob.NewFileStore(cfg.Exports.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize the signer of the backup download links
+	backupLinks, err := backupService.NewLinks(cfg.Backups.SigningKey, cfg.Backups.LinkTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize the exchange rates amounts are converted with, nil without rates
+	rates, err := cfg.Currency.Converter()
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize the checker of contact emails, looking up MX records when configured
+	emails, err := cfg.Contacts.EmailChecker()
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize the quotas capping each user's contacts, projects and wallets, the
+	// resources without one are unlimited
+	quotas := quota.NewChecker(dbService.Queries(), quota.Limits{
+		Contacts: cfg.Quotas.MaxContacts,
+		Projects: cfg.Quotas.MaxProjects,
+		Wallets:  cfg.Quotas.MaxWallets,
+	})
+
+	// Initialize the bus streaming the changes to each user's contacts, projects and wallets
+	bus := events.NewBus(cfg.Events.BufferSize, cfg.Events.ReplaySize)
+
+	// Initialize the janitor removing expired sessions, old backups and jobs and trash past
+	// its retention
+	janitor := NewJanitor(dbService.Queries(), cfg.Janitor, cfg.Trash, cfg.Backups, blobs, logger)
+
+	// Initialize the mailer of the exports delivered by email, nil without an SMTP host
+	mailer := cfg.Mail.Mailer()
+
+	// Initialize the scheduler delivering the scheduled exports when they're due
+	exports := exportScheduleService.NewScheduler(
+		exportScheduleRepository.New(dbService.Queries()),
+		exportScheduleService.NewExporters(dbService.Queries()),
+		mailer, cfg.ExportSchedules, logger,
+	)
 
 	// Create API server
 	apiServer := server.NewAPIServer(server.ServerDependencies{
-		Config: cfg,
-		DB:     dbService,
-		Logger: logger,
+		Config:      cfg,
+		DB:          dbService,
+		Jobs:        jobRunner,
+		Blobs:       blobs,
+		BackupLinks: backupLinks,
+		Rates:       rates,
+		Emails:      emails,
+		Quotas:      quotas,
+		Events:      bus,
+		Mailer:      mailer,
+		Logger:      logger,
+		Tracer:      tracer,
 	})
 
-	// Create HTTP server
+	// Create HTTP server, tracing each request outermost so its span covers everything else
 	httpServer := apiServer.NewHTTPServer()
+	httpServer.Handler = Trace(tracer)(httpServer.Handler)
 
 	return &App{
 		config:     cfg,
 		logger:     logger,
 		db:         dbService,
+		tracing:    tracerProvider,
+		jobs:       jobRunner,
+		janitor:    janitor,
+		exports:    exports,
 		httpServer: httpServer,
 	}, nil
 }
 
 // Start starts the application
 func (a *App) Start() error {
+	// Start background jobs, resuming any interrupted by the last shutdown
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	a.stopJobs = stopJobs
+	if err := a.jobs.Start(jobsCtx); err != nil {
+		stopJobs()
+		return fmt.Errorf("error starting jobs: %w", err)
+	}
+	a.janitor.Start(jobsCtx)
+	a.exports.Start(jobsCtx)
+
 	// Start server with graceful shutdown
 	done := lifecycle.GracefulShutdown(a.httpServer, a.logger)
 
-	a.logger.Info("starting server", zap.String("addr", a.httpServer.Addr))
+	build := version.Get()
+	a.logger.Info("starting server",
+		zap.String("addr", a.httpServer.Addr),
+		zap.String("version", build.Version),
+		zap.String("commit", build.Commit),
+		zap.String("build_time", build.BuildTime),
+		zap.String("go_version", build.GoVersion),
+	)
 	if err := a.httpServer.ListenAndServe(); err != http.ErrServerClosed {
+		stopJobs()
 		return fmt.Errorf("server error: %w", err)
 	}
 
 	<-done
 	a.logger.Info("server shutdown complete")
+
+	// Stop background jobs; unfinished jobs are requeued and resume on the next start
+	stopJobs()
+	a.jobs.Wait()
+	a.janitor.Wait()
+	a.exports.Wait()
+	a.logger.Info("jobs shutdown complete")
+
+	// Flush the spans still buffered
+	if a.tracing != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer cancel()
+		if err := a.tracing.Shutdown(ctx); err != nil {
+			a.logger.Error("error shutting down tracing", zap.Error(err))
+		}
+	}
 	return nil
 }
 
@@ -71,11 +197,26 @@ func (a *App) Stop(ctx context.Context) error {
 		return fmt.Errorf("error shutting down server: %w", err)
 	}
 
+	// Stop background jobs
+	if a.stopJobs != nil {
+		a.stopJobs()
+		a.jobs.Wait()
+		a.janitor.Wait()
+		a.exports.Wait()
+	}
+
 	// Close database connections
 	if err := a.db.Close(); err != nil {
 		return fmt.Errorf("error closing database: %w", err)
 	}
 
+	// Flush the spans still buffered
+	if a.tracing != nil {
+		if err := a.tracing.Shutdown(ctx); err != nil {
+			return fmt.Errorf("error shutting down tracing: %w", err)
+		}
+	}
+
 	// Sync logger
 	if err := a.logger.Sync(); err != nil {
 		return fmt.Errorf("error syncing logger: %w", err)
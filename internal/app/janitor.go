@@ -0,0 +1,212 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/config"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/blob"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultTrashRetention  = 30 * 24 * time.Hour
+	DefaultJanitorInterval = time.Hour
+	DefaultJanitorBatch    = 500
+	DefaultJobRetention    = 7 * 24 * time.Hour
+	DefaultBackupRetention = 24 * time.Hour
+)
+
+// JanitorStore deletes up to a batch of rows that expired before a point in time
+type JanitorStore interface {
+	PurgeExpiredSessions(ctx context.Context, arg db.PurgeExpiredSessionsParams) (int64, error)
+	PurgeFinishedJobs(ctx context.Context, arg db.PurgeFinishedJobsParams) (int64, error)
+	PurgeDeletedWallets(ctx context.Context, arg db.PurgeDeletedWalletsParams) (int64, error)
+	PurgeDeletedProjects(ctx context.Context, arg db.PurgeDeletedProjectsParams) (int64, error)
+	PurgeDeletedContacts(ctx context.Context, arg db.PurgeDeletedContactsParams) (int64, error)
+	ListExpiredBackups(ctx context.Context, arg db.ListExpiredBackupsParams) ([]db.ListExpiredBackupsRow, error)
+	DeleteJobs(ctx context.Context, jobIds []uuid.UUID) (int64, error)
+}
+
+// cleanup deletes a batch of one resource's rows that expired before the cutoff
+type cleanup struct {
+	resource  string
+	retention time.Duration
+	purge     func(ctx context.Context, before pgtype.Timestamp, batch int32) (int64, error)
+}
+
+// Janitor periodically deletes expired sessions, account backups and their archives past
+// the backup retention, finished jobs past their retention and trashed contacts, projects
+// and wallets past the trash retention. Rows go in batches, each its own statement, so no
+// delete holds its locks for long.
+type Janitor struct {
+	cleanups []cleanup
+	batch    int32
+	interval time.Duration
+	now      func() time.Time
+	wg       sync.WaitGroup
+	logger   *zap.Logger
+
+	mu      sync.Mutex
+	cleaned map[string]int64
+}
+
+func NewJanitor(store JanitorStore, cfg config.JanitorConfig, trash config.TrashConfig, backups config.BackupsConfig, blobs blob.Store, logger *zap.Logger) *Janitor {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultJanitorInterval
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultJanitorBatch
+	}
+	if cfg.JobRetention <= 0 {
+		cfg.JobRetention = DefaultJobRetention
+	}
+	if trash.Retention <= 0 {
+		trash.Retention = DefaultTrashRetention
+	}
+	if backups.Retention <= 0 {
+		backups.Retention = DefaultBackupRetention
+	}
+
+	// backups go before jobs, so no archive outlives the job pointing to it, purging a
+	// project detaches the wallets still attached to it
+	cleanups := []cleanup{
+		{"sessions", cfg.SessionRetention, func(ctx context.Context, before pgtype.Timestamp, batch int32) (int64, error) {
+			return store.PurgeExpiredSessions(ctx, db.PurgeExpiredSessionsParams{ExpiredBefore: before, BatchSize: batch})
+		}},
+		{"backups", backups.Retention, func(ctx context.Context, before pgtype.Timestamp, batch int32) (int64, error) {
+			return purgeExpiredBackups(ctx, store, blobs, before, batch)
+		}},
+		{"jobs", cfg.JobRetention, func(ctx context.Context, before pgtype.Timestamp, batch int32) (int64, error) {
+			return store.PurgeFinishedJobs(ctx, db.PurgeFinishedJobsParams{CompletedBefore: before, BatchSize: batch})
+		}},
+		{"wallets", trash.Retention, func(ctx context.Context, before pgtype.Timestamp, batch int32) (int64, error) {
+			return store.PurgeDeletedWallets(ctx, db.PurgeDeletedWalletsParams{DeletedBefore: before, BatchSize: batch})
+		}},
+		{"projects", trash.Retention, func(ctx context.Context, before pgtype.Timestamp, batch int32) (int64, error) {
+			return store.PurgeDeletedProjects(ctx, db.PurgeDeletedProjectsParams{DeletedBefore: before, BatchSize: batch})
+		}},
+		{"contacts", trash.Retention, func(ctx context.Context, before pgtype.Timestamp, batch int32) (int64, error) {
+			return store.PurgeDeletedContacts(ctx, db.PurgeDeletedContactsParams{DeletedBefore: before, BatchSize: batch})
+		}},
+	}
+
+	return &Janitor{
+		cleanups: cleanups,
+		batch:    cfg.BatchSize,
+		interval: cfg.Interval,
+		now:      time.Now,
+		logger:   logger.With(zap.String("component", "janitor")),
+		cleaned:  make(map[string]int64, len(cleanups)),
+	}
+}
+
+// purgeExpiredBackups deletes a batch of completed backups past their retention, each
+// archive before its job so a failure leaves the job to find the archive again
+func purgeExpiredBackups(ctx context.Context, store JanitorStore, blobs blob.Store, before pgtype.Timestamp, batch int32) (int64, error) {
+	expired, err := store.ListExpiredBackups(ctx, db.ListExpiredBackupsParams{CompletedBefore: before, BatchSize: batch})
+	if err != nil || len(expired) == 0 {
+		return 0, err
+	}
+
+	jobIDs := make([]uuid.UUID, len(expired))
+	for i, backup := range expired {
+		if backup.ResultKey.Valid {
+			if err := blobs.Delete(ctx, backup.ResultKey.String); err != nil {
+				return 0, err
+			}
+		}
+		jobIDs[i] = backup.JobID
+	}
+	return store.DeleteJobs(ctx, jobIDs)
+}
+
+// Start cleans up right away and then on every interval until ctx is cancelled; use
+// Wait to block until the janitor has stopped
+func (j *Janitor) Start(ctx context.Context) {
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := j.Run(ctx); err != nil && ctx.Err() == nil {
+				j.logger.Error("failed to clean up", zap.Error(err))
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Wait blocks until the janitor has stopped
+func (j *Janitor) Wait() {
+	j.wg.Wait()
+}
+
+// Run deletes every resource's rows past its retention, batch after batch until a
+// batch comes back short, and returns how many rows were removed. It stops at the
+// first failure, the next run picks up where it left off. Each run logs the rows it
+// removed per resource and the totals since the janitor was created.
+func (j *Janitor) Run(ctx context.Context) (total int64, err error) {
+	started := j.now()
+
+	counts := make([]zap.Field, 0, len(j.cleanups))
+	defer func() {
+		j.logger.Info("janitor run complete",
+			zap.Dict("cleaned", counts...),
+			zap.Any("cleaned_since_start", j.Cleaned()),
+			zap.Int64("count", total),
+			zap.Duration("duration", j.now().Sub(started)),
+			zap.Bool("failed", err != nil))
+	}()
+
+	for _, cleanup := range j.cleanups {
+		cutoff := pgtype.Timestamp{Time: started.UTC().Add(-cleanup.retention), Valid: true}
+
+		var removed int64
+		for {
+			n, err := cleanup.purge(ctx, cutoff, j.batch)
+			removed += n
+			if err != nil {
+				j.record(cleanup.resource, removed)
+				counts = append(counts, zap.Int64(cleanup.resource, removed))
+				return total + removed, fmt.Errorf("clean up %s: %w", cleanup.resource, err)
+			}
+			if n < int64(j.batch) {
+				break
+			}
+		}
+
+		j.record(cleanup.resource, removed)
+		counts = append(counts, zap.Int64(cleanup.resource, removed))
+		total += removed
+	}
+
+	return total, nil
+}
+
+// Cleaned returns how many rows of each resource the janitor removed since it was created
+func (j *Janitor) Cleaned() map[string]int64 {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return maps.Clone(j.cleaned)
+}
+
+func (j *Janitor) record(resource string, removed int64) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.cleaned[resource] += removed
+}
@@ -0,0 +1,263 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/config"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/blob"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// Mock janitor store
+type mockJanitorStore struct {
+	mock.Mock
+}
+
+func (m *mockJanitorStore) PurgeExpiredSessions(ctx context.Context, arg db.PurgeExpiredSessionsParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockJanitorStore) PurgeFinishedJobs(ctx context.Context, arg db.PurgeFinishedJobsParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockJanitorStore) PurgeDeletedWallets(ctx context.Context, arg db.PurgeDeletedWalletsParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockJanitorStore) PurgeDeletedProjects(ctx context.Context, arg db.PurgeDeletedProjectsParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockJanitorStore) PurgeDeletedContacts(ctx context.Context, arg db.PurgeDeletedContactsParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockJanitorStore) ListExpiredBackups(ctx context.Context, arg db.ListExpiredBackupsParams) ([]db.ListExpiredBackupsRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]db.ListExpiredBackupsRow), args.Error(1)
+}
+
+func (m *mockJanitorStore) DeleteJobs(ctx context.Context, jobIds []uuid.UUID) (int64, error) {
+	args := m.Called(ctx, jobIds)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+var janitorNow = time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC)
+
+func setupJanitorTest(t *testing.T, cfg config.JanitorConfig, trash config.TrashConfig, backups config.BackupsConfig) (*mockJanitorStore, blob.Store, *Janitor) {
+	store := new(mockJanitorStore)
+	blobs, err := blob.NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	janitor := NewJanitor(store, cfg, trash, backups, blobs, zap.NewNop())
+	janitor.now = func() time.Time { return janitorNow }
+	return store, blobs, janitor
+}
+
+func before(retention time.Duration) pgtype.Timestamp {
+	return pgtype.Timestamp{Time: janitorNow.Add(-retention), Valid: true}
+}
+
+func TestJanitor_Run(t *testing.T) {
+	cfg := config.JanitorConfig{BatchSize: 2, SessionRetention: time.Hour, JobRetention: 24 * time.Hour}
+	trash := config.TrashConfig{Retention: 48 * time.Hour}
+	backups := config.BackupsConfig{Retention: 12 * time.Hour}
+
+	t.Run("cleans up each resource past its retention", func(t *testing.T) {
+		store, _, janitor := setupJanitorTest(t, cfg, trash, backups)
+
+		var order []string
+		track := func(resource string) func(mock.Arguments) {
+			return func(mock.Arguments) { order = append(order, resource) }
+		}
+		store.On("PurgeExpiredSessions", mock.Anything, db.PurgeExpiredSessionsParams{ExpiredBefore: before(time.Hour), BatchSize: 2}).
+			Return(int64(1), nil).Run(track("sessions"))
+		store.On("ListExpiredBackups", mock.Anything, db.ListExpiredBackupsParams{CompletedBefore: before(12 * time.Hour), BatchSize: 2}).
+			Return([]db.ListExpiredBackupsRow{}, nil).Run(track("backups"))
+		store.On("PurgeFinishedJobs", mock.Anything, db.PurgeFinishedJobsParams{CompletedBefore: before(24 * time.Hour), BatchSize: 2}).
+			Return(int64(0), nil).Run(track("jobs"))
+		store.On("PurgeDeletedWallets", mock.Anything, db.PurgeDeletedWalletsParams{DeletedBefore: before(48 * time.Hour), BatchSize: 2}).
+			Return(int64(1), nil).Run(track("wallets"))
+		store.On("PurgeDeletedProjects", mock.Anything, db.PurgeDeletedProjectsParams{DeletedBefore: before(48 * time.Hour), BatchSize: 2}).
+			Return(int64(1), nil).Run(track("projects"))
+		store.On("PurgeDeletedContacts", mock.Anything, db.PurgeDeletedContactsParams{DeletedBefore: before(48 * time.Hour), BatchSize: 2}).
+			Return(int64(1), nil).Run(track("contacts"))
+
+		cleaned, err := janitor.Run(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), cleaned)
+		assert.Equal(t, []string{"sessions", "backups", "jobs", "wallets", "projects", "contacts"}, order)
+		store.AssertExpectations(t)
+	})
+
+	t.Run("deletes in batches until one comes back short", func(t *testing.T) {
+		store, _, janitor := setupJanitorTest(t, cfg, trash, backups)
+		store.On("PurgeExpiredSessions", mock.Anything, mock.Anything).Return(int64(0), nil)
+		store.On("ListExpiredBackups", mock.Anything, mock.Anything).Return([]db.ListExpiredBackupsRow{}, nil)
+		store.On("PurgeFinishedJobs", mock.Anything, mock.Anything).Return(int64(0), nil)
+		store.On("PurgeDeletedWallets", mock.Anything, mock.Anything).Return(int64(2), nil).Twice()
+		store.On("PurgeDeletedWallets", mock.Anything, mock.Anything).Return(int64(1), nil).Once()
+		store.On("PurgeDeletedProjects", mock.Anything, mock.Anything).Return(int64(2), nil).Once()
+		store.On("PurgeDeletedProjects", mock.Anything, mock.Anything).Return(int64(0), nil).Once()
+		store.On("PurgeDeletedContacts", mock.Anything, mock.Anything).Return(int64(0), nil)
+
+		cleaned, err := janitor.Run(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), cleaned)
+		store.AssertNumberOfCalls(t, "PurgeDeletedWallets", 3)
+		store.AssertNumberOfCalls(t, "PurgeDeletedProjects", 2)
+		store.AssertNumberOfCalls(t, "PurgeDeletedContacts", 1)
+
+		// the counts add up across runs
+		store.On("PurgeDeletedWallets", mock.Anything, mock.Anything).Return(int64(0), nil)
+		store.On("PurgeDeletedProjects", mock.Anything, mock.Anything).Return(int64(0), nil)
+		cleaned, err = janitor.Run(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), cleaned)
+		assert.Equal(t, map[string]int64{"sessions": 0, "backups": 0, "jobs": 0, "wallets": 5, "projects": 2, "contacts": 0}, janitor.Cleaned())
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		store, _, janitor := setupJanitorTest(t, config.JanitorConfig{}, config.TrashConfig{}, config.BackupsConfig{})
+		assert.Equal(t, DefaultJanitorInterval, janitor.interval)
+		assert.Equal(t, int32(DefaultJanitorBatch), janitor.batch)
+
+		store.On("PurgeExpiredSessions", mock.Anything, db.PurgeExpiredSessionsParams{ExpiredBefore: before(0), BatchSize: DefaultJanitorBatch}).Return(int64(0), nil)
+		store.On("ListExpiredBackups", mock.Anything, db.ListExpiredBackupsParams{CompletedBefore: before(DefaultBackupRetention), BatchSize: DefaultJanitorBatch}).Return([]db.ListExpiredBackupsRow{}, nil)
+		store.On("PurgeFinishedJobs", mock.Anything, db.PurgeFinishedJobsParams{CompletedBefore: before(DefaultJobRetention), BatchSize: DefaultJanitorBatch}).Return(int64(0), nil)
+		store.On("PurgeDeletedWallets", mock.Anything, db.PurgeDeletedWalletsParams{DeletedBefore: before(DefaultTrashRetention), BatchSize: DefaultJanitorBatch}).Return(int64(0), nil)
+		store.On("PurgeDeletedProjects", mock.Anything, db.PurgeDeletedProjectsParams{DeletedBefore: before(DefaultTrashRetention), BatchSize: DefaultJanitorBatch}).Return(int64(0), nil)
+		store.On("PurgeDeletedContacts", mock.Anything, db.PurgeDeletedContactsParams{DeletedBefore: before(DefaultTrashRetention), BatchSize: DefaultJanitorBatch}).Return(int64(0), nil)
+
+		_, err := janitor.Run(context.Background())
+		require.NoError(t, err)
+		store.AssertExpectations(t)
+	})
+
+	t.Run("stops at the first failure", func(t *testing.T) {
+		store, _, janitor := setupJanitorTest(t, cfg, trash, backups)
+		store.On("PurgeExpiredSessions", mock.Anything, mock.Anything).Return(int64(1), nil)
+		store.On("ListExpiredBackups", mock.Anything, mock.Anything).Return([]db.ListExpiredBackupsRow{}, nil)
+		store.On("PurgeFinishedJobs", mock.Anything, mock.Anything).Return(int64(0), nil)
+		store.On("PurgeDeletedWallets", mock.Anything, mock.Anything).Return(int64(2), nil).Once()
+		store.On("PurgeDeletedWallets", mock.Anything, mock.Anything).Return(int64(0), errors.New("connection reset")).Once()
+
+		cleaned, err := janitor.Run(context.Background())
+		assert.EqualError(t, err, "clean up wallets: connection reset")
+		assert.Equal(t, int64(3), cleaned)
+		assert.Equal(t, int64(2), janitor.Cleaned()["wallets"])
+		store.AssertNotCalled(t, "PurgeDeletedProjects", mock.Anything, mock.Anything)
+		store.AssertNotCalled(t, "PurgeDeletedContacts", mock.Anything, mock.Anything)
+	})
+
+	t.Run("logs the rows removed per resource after each run", func(t *testing.T) {
+		store, _, janitor := setupJanitorTest(t, cfg, trash, backups)
+		core, logs := observer.New(zapcore.InfoLevel)
+		janitor.logger = zap.New(core)
+
+		store.On("PurgeExpiredSessions", mock.Anything, mock.Anything).Return(int64(1), nil)
+		store.On("ListExpiredBackups", mock.Anything, mock.Anything).Return([]db.ListExpiredBackupsRow{}, nil)
+		store.On("PurgeFinishedJobs", mock.Anything, mock.Anything).Return(int64(0), nil)
+		store.On("PurgeDeletedWallets", mock.Anything, mock.Anything).Return(int64(1), nil)
+		store.On("PurgeDeletedProjects", mock.Anything, mock.Anything).Return(int64(0), nil)
+		store.On("PurgeDeletedContacts", mock.Anything, mock.Anything).Return(int64(0), errors.New("connection reset")).Once()
+		store.On("PurgeDeletedContacts", mock.Anything, mock.Anything).Return(int64(1), nil)
+
+		_, err := janitor.Run(context.Background())
+		require.Error(t, err)
+		_, err = janitor.Run(context.Background())
+		require.NoError(t, err)
+
+		runs := logs.FilterMessage("janitor run complete").All()
+		require.Len(t, runs, 2)
+		pass := map[string]interface{}{"sessions": int64(1), "backups": int64(0), "jobs": int64(0), "wallets": int64(1), "projects": int64(0), "contacts": int64(0)}
+		assert.Equal(t, true, runs[0].ContextMap()["failed"])
+		assert.Equal(t, pass, runs[0].ContextMap()["cleaned"])
+
+		pass["contacts"] = int64(1)
+		assert.Equal(t, false, runs[1].ContextMap()["failed"])
+		assert.Equal(t, pass, runs[1].ContextMap()["cleaned"])
+		assert.Equal(t, map[string]int64{"sessions": 2, "backups": 0, "jobs": 0, "wallets": 2, "projects": 0, "contacts": 1},
+			runs[1].ContextMap()["cleaned_since_start"])
+	})
+
+	t.Run("deletes the archives of expired backups before their jobs", func(t *testing.T) {
+		store, blobs, janitor := setupJanitorTest(t, cfg, trash, backups)
+		ctx := context.Background()
+		require.NoError(t, blobs.Put(ctx, "backups/expired.zip", func(w io.Writer) error {
+			_, err := io.WriteString(w, "PK")
+			return err
+		}))
+		expired, failed := uuid.New(), uuid.New()
+
+		store.On("PurgeExpiredSessions", mock.Anything, mock.Anything).Return(int64(0), nil)
+		store.On("ListExpiredBackups", mock.Anything, mock.Anything).Return([]db.ListExpiredBackupsRow{
+			{JobID: expired, ResultKey: pgtype.Text{String: "backups/expired.zip", Valid: true}},
+			{JobID: failed},
+		}, nil).Once()
+		store.On("DeleteJobs", mock.Anything, []uuid.UUID{expired, failed}).Return(int64(2), nil).
+			Run(func(mock.Arguments) {
+				_, err := blobs.Open(ctx, "backups/expired.zip")
+				assert.ErrorIs(t, err, blob.ErrNotFound)
+			})
+		store.On("ListExpiredBackups", mock.Anything, mock.Anything).Return([]db.ListExpiredBackupsRow{}, nil).Once()
+		store.On("PurgeFinishedJobs", mock.Anything, mock.Anything).Return(int64(0), nil)
+		store.On("PurgeDeletedWallets", mock.Anything, mock.Anything).Return(int64(0), nil)
+		store.On("PurgeDeletedProjects", mock.Anything, mock.Anything).Return(int64(0), nil)
+		store.On("PurgeDeletedContacts", mock.Anything, mock.Anything).Return(int64(0), nil)
+
+		cleaned, err := janitor.Run(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), cleaned)
+		assert.Equal(t, int64(2), janitor.Cleaned()["backups"])
+		store.AssertExpectations(t)
+	})
+}
+
+func TestJanitor_Start(t *testing.T) {
+	store, _, janitor := setupJanitorTest(t, config.JanitorConfig{}, config.TrashConfig{}, config.BackupsConfig{})
+	janitor.interval = time.Millisecond
+
+	ran := make(chan struct{}, 1)
+	store.On("PurgeExpiredSessions", mock.Anything, mock.Anything).Return(int64(0), nil)
+	store.On("ListExpiredBackups", mock.Anything, mock.Anything).Return([]db.ListExpiredBackupsRow{}, nil)
+	store.On("PurgeFinishedJobs", mock.Anything, mock.Anything).Return(int64(0), nil)
+	store.On("PurgeDeletedWallets", mock.Anything, mock.Anything).Return(int64(0), nil)
+	store.On("PurgeDeletedProjects", mock.Anything, mock.Anything).Return(int64(0), nil)
+	store.On("PurgeDeletedContacts", mock.Anything, mock.Anything).Return(int64(0), nil).
+		Run(func(mock.Arguments) {
+			select {
+			case ran <- struct{}{}:
+			default:
+			}
+		})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	janitor.Start(ctx)
+
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("janitor did not run")
+	}
+
+	cancel()
+	janitor.Wait()
+}
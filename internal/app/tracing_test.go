@@ -0,0 +1,123 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Abdelrahman-habib/expense-tracker/config"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/tracing"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/projects/handlers"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/projects/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/projects/service"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/projects/types"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// sqlProjectRepository answers GetProject through the query tracer the way the pgx pool
+// would, the other methods aren't used
+type sqlProjectRepository struct {
+	repository.ProjectRepository
+	queries pgx.QueryTracer
+}
+
+func (r *sqlProjectRepository) GetProject(ctx context.Context, userID, projectID uuid.UUID) (types.Project, error) {
+	if r.queries != nil {
+		ctx = r.queries.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{
+			SQL: "-- name: GetProject :one\nSELECT project_id, name FROM projects WHERE project_id = $1",
+		})
+		r.queries.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+	}
+	return types.Project{ProjectID: projectID, Name: "Website Redesign", Status: "ongoing"}, nil
+}
+
+// serveTracedProject gets a project through the app's tracing middleware and the traced
+// project service and repository, as the routes compose them
+func serveTracedProject(t *testing.T, cfg config.TracingConfig, recorder *tracetest.SpanRecorder, traceparent string) *httptest.ResponseRecorder {
+	provider := tracing.NewProvider(cfg, recorder)
+	tracer := tracing.Tracer(provider)
+
+	repo := repository.NewTracedProjectRepository(&sqlProjectRepository{queries: tracing.NewQueryTracer(tracer)}, tracer)
+	projectService := service.NewTracedProjectService(service.NewProjectService(repo, types.PublishRules{}, nil, "", nil, nil, zap.NewNop()), tracer)
+	handler := handlers.NewProjectHandler(projectService, coreTypes.DefaultLimitPolicy(), zap.NewNop())
+
+	router := chi.NewRouter()
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), requestcontext.UserIDKey, uuid.New())
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	})
+	router.Get("/api/v1/projects/{id}", handler.GetProject)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/projects/"+uuid.NewString(), nil)
+	if traceparent != "" {
+		req.Header.Set("traceparent", traceparent)
+	}
+	w := httptest.NewRecorder()
+	Trace(tracer)(router).ServeHTTP(w, req)
+
+	if provider != nil {
+		require.NoError(t, provider.Shutdown(context.Background()))
+	}
+	return w
+}
+
+func TestTrace_SpanHierarchy(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	w := serveTracedProject(t, config.TracingConfig{Enabled: true, SampleRatio: 1, ServiceName: "test"}, recorder, traceparent)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	require.Len(t, spans, 4)
+
+	request := spans["GET /api/v1/projects/{id}"]
+	require.NotNil(t, request)
+	assert.Equal(t, trace.SpanKindServer, request.SpanKind())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", request.SpanContext().TraceID().String())
+	assert.True(t, request.Parent().IsRemote())
+	assert.Equal(t, "00f067aa0ba902b7", request.Parent().SpanID().String())
+
+	// each span is the child of the layer calling it
+	hierarchy := []string{"GET /api/v1/projects/{id}", "ProjectService.GetProject", "ProjectRepository.GetProject", "GetProject"}
+	for i := 1; i < len(hierarchy); i++ {
+		parent, child := spans[hierarchy[i-1]], spans[hierarchy[i]]
+		require.NotNil(t, child, hierarchy[i])
+		assert.Equal(t, parent.SpanContext().SpanID(), child.Parent().SpanID(), "parent of %s", hierarchy[i])
+		assert.Equal(t, request.SpanContext().TraceID(), child.SpanContext().TraceID())
+	}
+
+	var statement string
+	for _, attr := range spans["GetProject"].Attributes() {
+		if attr.Key == "db.statement.name" {
+			statement = attr.Value.AsString()
+		}
+	}
+	assert.Equal(t, "GetProject", statement)
+}
+
+func TestTrace_Disabled(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+
+	w := serveTracedProject(t, config.TracingConfig{Enabled: false, SampleRatio: 1}, recorder, "")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	assert.Empty(t, recorder.Ended())
+	assert.Empty(t, recorder.Started())
+}
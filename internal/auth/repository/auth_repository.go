@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/auth/types"
@@ -10,6 +11,13 @@ import (
 	"go.uber.org/zap"
 )
 
+var (
+	// ErrUserNotFound is returned when no user has the ID
+	ErrUserNotFound = errors.New("user not found")
+	// ErrUserTaken is returned when the name or email of a new user belongs to another one
+	ErrUserTaken = errors.New("name or email already taken")
+)
+
 // Repository defines the interface for auth-related storage operations
 type Repository interface {
 	// Token operations
@@ -23,8 +31,13 @@ type Repository interface {
 	DeleteSession(ctx context.Context, key string) error
 
 	// OAuth operations
+	GetUserByID(ctx context.Context, userID uuid.UUID) (*types.AuthUser, error)
 	GetUserByExternalID(ctx context.Context, externalID, provider string) (*types.AuthUser, error)
 	CreateUser(ctx context.Context, userData types.OAuthUserData) (*types.AuthUser, error)
+	// ProvisionUser returns the user, creating it with userID, a new one when it is
+	// uuid.Nil, on the first sign in, concurrent calls for the same user create a single
+	// row
+	ProvisionUser(ctx context.Context, userID uuid.UUID, userData types.OAuthUserData) (*types.AuthUser, error)
 	UpdateUserLastLogin(ctx context.Context, userID uuid.UUID) error
 }
 
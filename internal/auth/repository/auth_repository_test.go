@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
@@ -398,6 +399,150 @@ func (s *AuthRepositoryTestSuite) TestCreateUser() {
 	assert.Equal(s.T(), userData.Provider, dbUser.Provider)
 }
 
+// TestProvisionUser tests the ProvisionUser method
+func (s *AuthRepositoryTestSuite) TestProvisionUser() {
+	// an existing user is returned as is
+	user, err := s.repo.ProvisionUser(s.ctx, uuid.Nil, types.OAuthUserData{
+		ExternalID: "test-external-id",
+		Name:       "Renamed User",
+		Email:      "renamed@example.com",
+		Provider:   "google",
+	})
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), s.testUser, user.ID)
+	assert.Equal(s.T(), "Test User", user.Name)
+
+	// a name taken by another user is still an error
+	_, err = s.repo.ProvisionUser(s.ctx, uuid.Nil, types.OAuthUserData{
+		ExternalID: "taken-name-id",
+		Name:       "Test User",
+		Email:      "taken-name@example.com",
+		Provider:   "github",
+	})
+	require.ErrorIs(s.T(), err, repository.ErrUserTaken)
+}
+
+// TestProvisionUser_Concurrent tests concurrent first sign ins create a single user
+func (s *AuthRepositoryTestSuite) TestProvisionUser_Concurrent() {
+	userData := types.OAuthUserData{
+		ExternalID: "concurrent-external-id",
+		Name:       "Concurrent User",
+		Email:      "concurrent@example.com",
+		Provider:   "github",
+	}
+
+	const signIns = 8
+	ids := make(chan uuid.UUID, signIns)
+	errs := make(chan error, signIns)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < signIns; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			user, err := s.repo.ProvisionUser(s.ctx, uuid.Nil, userData)
+			if err != nil {
+				errs <- err
+				return
+			}
+			ids <- user.ID
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(ids)
+	close(errs)
+
+	for err := range errs {
+		s.Fail("provisioning failed", err.Error())
+	}
+	var first uuid.UUID
+	for id := range ids {
+		if first == uuid.Nil {
+			first = id
+		}
+		assert.Equal(s.T(), first, id)
+	}
+
+	var count int
+	err := s.pool.QueryRow(s.ctx, "SELECT COUNT(*) FROM users WHERE external_id = $1 AND provider = $2",
+		userData.ExternalID, userData.Provider).Scan(&count)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 1, count)
+}
+
+// TestProvisionUser_ConcurrentFirstRequests tests the parallel first requests of a
+// token create its user once, with the ID the token carries
+func (s *AuthRepositoryTestSuite) TestProvisionUser_ConcurrentFirstRequests() {
+	userID := uuid.New()
+	userData := types.OAuthUserData{
+		ExternalID: "first-request-external-id",
+		Name:       "First Request User",
+		Email:      "first-request@example.com",
+		Provider:   "google",
+	}
+
+	const requests = 8
+	errs := make(chan error, requests)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			user, err := s.repo.ProvisionUser(s.ctx, userID, userData)
+			if err == nil && user.ID != userID {
+				err = fmt.Errorf("provisioned %s, want %s", user.ID, userID)
+			}
+			errs <- err
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(s.T(), err)
+	}
+	user, err := s.repo.GetUserByID(s.ctx, userID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), userData.Email, user.Email)
+}
+
+// TestProvisionUser_WebhookCreatedFirst tests a token whose user the webhook created
+// first gets that user instead of a second one
+func (s *AuthRepositoryTestSuite) TestProvisionUser_WebhookCreatedFirst() {
+	webhookUser, err := s.repo.CreateUser(s.ctx, types.OAuthUserData{
+		ExternalID: "webhook-external-id",
+		Name:       "Webhook User",
+		Email:      "webhook@example.com",
+		Provider:   "github",
+	})
+	require.NoError(s.T(), err)
+
+	user, err := s.repo.ProvisionUser(s.ctx, uuid.New(), types.OAuthUserData{
+		ExternalID: "webhook-external-id",
+		Name:       "Token User",
+		Email:      "token@example.com",
+		Provider:   "github",
+	})
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), webhookUser.ID, user.ID)
+	assert.Equal(s.T(), "Webhook User", user.Name)
+}
+
+// TestGetUserByID tests a missing user is ErrUserNotFound
+func (s *AuthRepositoryTestSuite) TestGetUserByID() {
+	user, err := s.repo.GetUserByID(s.ctx, s.testUser)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "Test User", user.Name)
+
+	_, err = s.repo.GetUserByID(s.ctx, uuid.New())
+	assert.ErrorIs(s.T(), err, repository.ErrUserNotFound)
+}
+
 // TestUpdateUserLastLogin tests the UpdateUserLastLogin method
 func (s *AuthRepositoryTestSuite) TestUpdateUserLastLogin() {
 	// Get current last_login value
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/auth/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// GetUserByID retrieves a user by the ID its tokens carry, ErrUserNotFound when no row
+// has it
+func (r *authRepository) GetUserByID(ctx context.Context, userID uuid.UUID) (*types.AuthUser, error) {
+	r.logger.Debug("getting user by ID", zap.String("user_id", userID.String()))
+
+	user, err := r.queries.GetUser(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.AuthUser{
+		ID:       user.UserID,
+		Name:     user.Name,
+		Email:    user.Email,
+		Provider: user.Provider,
+	}, nil
+}
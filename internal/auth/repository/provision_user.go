@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/auth/types"
+	coreErrors "github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// ProvisionUser returns the user signing in with OAuth data, creating it on the first
+// sign in, with userID when it isn't uuid.Nil. It is safe to retry and to run
+// concurrently for the same user, like the callbacks of a sign in completed in two tabs
+// or the first requests of a token: the insert that loses the race does nothing and the
+// user the winner created is returned. A user the webhook created first is returned
+// the same way, its ID may differ from userID.
+func (r *authRepository) ProvisionUser(ctx context.Context, userID uuid.UUID, userData types.OAuthUserData) (*types.AuthUser, error) {
+	r.logger.Debug("provisioning user",
+		zap.String("external_id", userData.ExternalID),
+		zap.String("provider", userData.Provider),
+	)
+
+	user, err := r.queries.ProvisionUser(ctx, db.ProvisionUserParams{
+		UserID:     pgtype.UUID{Bytes: userID, Valid: userID != uuid.Nil},
+		Name:       userData.Name,
+		Email:      userData.Email,
+		ExternalID: userData.ExternalID,
+		Provider:   userData.Provider,
+	})
+	var pgErr *pgconn.PgError
+	violated := errors.As(err, &pgErr) && pgErr.Code == coreErrors.UniqueViolationCode
+	if errors.Is(err, pgx.ErrNoRows) || violated {
+		// the insert conflicted, either on the external ID, which ON CONFLICT skips, or,
+		// when both inserts passed the conflict check at once, on another unique index of
+		// the same user. Read the user in a new statement so its snapshot sees the row
+		// committed by the concurrent sign in.
+		existing, readErr := r.GetUserByExternalID(ctx, userData.ExternalID, userData.Provider)
+		if violated && errors.Is(readErr, pgx.ErrNoRows) {
+			// no user has the external ID, the name or email is another user's
+			return nil, fmt.Errorf("%w: %w", ErrUserTaken, err)
+		}
+		if readErr != nil {
+			return nil, fmt.Errorf("failed to read provisioned user: %w", readErr)
+		}
+		return existing, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to provision user: %w", err)
+	}
+
+	return &types.AuthUser{
+		ID:       user.UserID,
+		Name:     user.Name,
+		Email:    user.Email,
+		Provider: user.Provider,
+	}, nil
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/auth/repository"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/auth/types"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/jwtauth/v5"
 	"github.com/google/uuid"
 	"github.com/markbates/goth"
 	"go.uber.org/zap"
@@ -56,7 +58,9 @@ func NewService(cfg *types.Config, repo repository.Repository, logger *zap.Logge
 	}
 }
 
-// Middleware returns an http.Handler that authenticates requests
+// Middleware returns an http.Handler that authenticates requests. The claims of the
+// token and the ID of its user are added to the context, the user is created on its
+// first request when JIT provisioning is on.
 func (s *service) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		cookie, err := r.Cookie(AccessTokenCookie)
@@ -65,16 +69,79 @@ func (s *service) Middleware(next http.Handler) http.Handler {
 			return
 		}
 
-		if _, err := s.token.ValidateAccessToken(r.Context(), cookie.Value); err != nil {
+		token, err := s.token.ValidateAccessToken(r.Context(), cookie.Value)
+		if err != nil {
 			http.Error(w, "Invalid token", http.StatusUnauthorized)
 			return
 		}
 
 		// Add claims to context
-		next.ServeHTTP(w, r)
+		ctx := jwtauth.NewContext(r.Context(), token, nil)
+
+		userID, err := s.tokenUser(ctx, token.PrivateClaims())
+		switch {
+		case errors.Is(err, ErrInvalidToken):
+			http.Error(w, "Invalid token", http.StatusUnauthorized)
+			return
+		case errors.Is(err, ErrUserNotFound):
+			http.Error(w, "User not provisioned", http.StatusForbidden)
+			return
+		case errors.Is(err, repository.ErrUserTaken):
+			http.Error(w, "Name or email already taken", http.StatusConflict)
+			return
+		case err != nil:
+			s.logger.Error("failed to provision user", zap.Error(err))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		ctx = context.WithValue(ctx, requestcontext.UserIDKey, userID)
+		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// tokenUser returns the ID of the user of the token claims. A user without a row, like
+// one whose first request beats the webhook, is created from the claims when JIT
+// provisioning is on, concurrent first requests create a single row and a row the
+// webhook created first is the one used.
+func (s *service) tokenUser(ctx context.Context, claims map[string]interface{}) (uuid.UUID, error) {
+	userIDStr, _ := claims["user_id"].(string)
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return uuid.Nil, ErrInvalidToken
+	}
+
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err == nil {
+		return user.ID, nil
+	}
+	if !errors.Is(err, repository.ErrUserNotFound) {
+		return uuid.Nil, err
+	}
+	if !s.config.JITProvisioning {
+		return uuid.Nil, ErrUserNotFound
+	}
+
+	userData := types.OAuthUserData{}
+	userData.ExternalID, _ = claims["external_id"].(string)
+	userData.Name, _ = claims["name"].(string)
+	userData.Email, _ = claims["email"].(string)
+	userData.Provider, _ = claims["provider"].(string)
+	if userData.Validate() != nil {
+		// tokens issued before they carried the external ID can't be provisioned
+		return uuid.Nil, ErrUserNotFound
+	}
+
+	user, err = s.repo.ProvisionUser(ctx, userID, userData)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	s.logger.Info("provisioned user on first request",
+		zap.String("user_id", user.ID.String()),
+		zap.String("provider", user.Provider))
+	return user.ID, nil
+}
+
 // BeginAuth initiates the OAuth flow
 func (s *service) BeginAuth(w http.ResponseWriter, r *http.Request, provider string, scopes []string) error {
 	return s.oauth.BeginAuth(w, r, provider, scopes)
@@ -91,18 +158,19 @@ func (s *service) CompleteAuth(w http.ResponseWriter, r *http.Request) (*AuthRes
 	// Get or create user
 	user, err := s.repo.GetUserByExternalID(r.Context(), userData.ExternalID, userData.Provider)
 	if err != nil {
-		// Create new user if not found
-		user, err = s.repo.CreateUser(r.Context(), *userData)
+		// Create new user if not found, a concurrent callback may be creating it too
+		user, err = s.repo.ProvisionUser(r.Context(), uuid.Nil, *userData)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create user: %w", err)
 		}
 	}
 
-	// Generate tokens
+	// Generate tokens, the external ID lets a token provision its user
 	claims := map[string]interface{}{
-		"name":     user.Name,
-		"email":    user.Email,
-		"provider": user.Provider,
+		"name":        user.Name,
+		"email":       user.Email,
+		"provider":    user.Provider,
+		"external_id": userData.ExternalID,
 	}
 
 	tokenPair, err := s.token.GenerateTokenPair(r.Context(), user.ID, claims)
@@ -134,18 +202,19 @@ func (s *service) AuthenticateUser(ctx context.Context, user goth.User) (*AuthRe
 	// Get or create user
 	authUser, err := s.repo.GetUserByExternalID(ctx, userData.ExternalID, userData.Provider)
 	if err != nil {
-		// Create new user if not found
-		authUser, err = s.repo.CreateUser(ctx, *userData)
+		// Create new user if not found, a concurrent sign in may be creating it too
+		authUser, err = s.repo.ProvisionUser(ctx, uuid.Nil, *userData)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create user: %w", err)
 		}
 	}
 
-	// Generate tokens
+	// Generate tokens, the external ID lets a token provision its user
 	claims := map[string]interface{}{
-		"name":     authUser.Name,
-		"email":    authUser.Email,
-		"provider": authUser.Provider,
+		"name":        authUser.Name,
+		"email":       authUser.Email,
+		"provider":    authUser.Provider,
+		"external_id": userData.ExternalID,
 	}
 
 	tokenPair, err := s.token.GenerateTokenPair(ctx, authUser.ID, claims)
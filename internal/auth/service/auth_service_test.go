@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/auth/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/auth/types"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// mockAuthRepository mocks the user lookups of the middleware, the other operations
+// aren't used and panic
+type mockAuthRepository struct {
+	mock.Mock
+	repository.Repository
+}
+
+func (m *mockAuthRepository) GetUserByID(ctx context.Context, userID uuid.UUID) (*types.AuthUser, error) {
+	args := m.Called(ctx, userID)
+	user, _ := args.Get(0).(*types.AuthUser)
+	return user, args.Error(1)
+}
+
+func (m *mockAuthRepository) ProvisionUser(ctx context.Context, userID uuid.UUID, userData types.OAuthUserData) (*types.AuthUser, error) {
+	args := m.Called(ctx, userID, userData)
+	user, _ := args.Get(0).(*types.AuthUser)
+	return user, args.Error(1)
+}
+
+const testAccessSecret = "test-access-secret"
+
+func newTestService(repo repository.Repository, jit bool) *service {
+	cfg := &types.Config{
+		JWT:             types.JWTConfig{AccessTokenSecret: testAccessSecret, AccessTokenTTL: time.Minute},
+		JITProvisioning: jit,
+	}
+	return &service{
+		config: cfg,
+		repo:   repo,
+		logger: zap.NewNop(),
+		token:  NewTokenService(cfg, repo, zap.NewNop()),
+	}
+}
+
+// accessToken signs an access token the way CompleteAuth issues them
+func accessToken(t *testing.T, userID uuid.UUID, externalID string) string {
+	claims := map[string]interface{}{
+		"user_id":  userID.String(),
+		"name":     "New User",
+		"email":    "new@example.com",
+		"provider": "google",
+		"exp":      time.Now().Add(time.Minute).Unix(),
+	}
+	if externalID != "" {
+		claims["external_id"] = externalID
+	}
+	_, token, err := jwtauth.New("HS256", []byte(testAccessSecret), nil).Encode(claims)
+	require.NoError(t, err)
+	return token
+}
+
+// serve runs a request with the token through the middleware, returning the status and
+// the user ID the handler saw
+func serve(s *service, token string) (int, uuid.UUID) {
+	var seen uuid.UUID
+	handler := s.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = requestcontext.GetUserIDFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/contacts", nil)
+	req.AddCookie(&http.Cookie{Name: AccessTokenCookie, Value: token})
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w.Code, seen
+}
+
+func TestMiddleware_ExistingUser(t *testing.T) {
+	userID := uuid.New()
+	repo := new(mockAuthRepository)
+	repo.On("GetUserByID", mock.Anything, userID).Return(&types.AuthUser{ID: userID}, nil)
+
+	code, seen := serve(newTestService(repo, true), accessToken(t, userID, "google-123"))
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, userID, seen)
+	repo.AssertNotCalled(t, "ProvisionUser", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMiddleware_ProvisionsOnFirstRequest(t *testing.T) {
+	userData := types.OAuthUserData{ExternalID: "google-123", Name: "New User", Email: "new@example.com", Provider: "google"}
+
+	t.Run("parallel first requests", func(t *testing.T) {
+		userID := uuid.New()
+		repo := new(mockAuthRepository)
+		repo.On("GetUserByID", mock.Anything, userID).Return(nil, repository.ErrUserNotFound)
+		// the repository returns the one row whichever request inserted it
+		repo.On("ProvisionUser", mock.Anything, userID, userData).Return(&types.AuthUser{ID: userID}, nil)
+
+		s := newTestService(repo, true)
+		token := accessToken(t, userID, "google-123")
+
+		const requests = 8
+		var wg sync.WaitGroup
+		codes := make([]int, requests)
+		seen := make([]uuid.UUID, requests)
+		for i := range requests {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				codes[i], seen[i] = serve(s, token)
+			}()
+		}
+		wg.Wait()
+
+		for i := range requests {
+			assert.Equal(t, http.StatusOK, codes[i])
+			assert.Equal(t, userID, seen[i])
+		}
+	})
+
+	t.Run("the webhook created the user first", func(t *testing.T) {
+		tokenUserID, webhookUserID := uuid.New(), uuid.New()
+		repo := new(mockAuthRepository)
+		repo.On("GetUserByID", mock.Anything, tokenUserID).Return(nil, repository.ErrUserNotFound)
+		repo.On("ProvisionUser", mock.Anything, tokenUserID, userData).Return(&types.AuthUser{ID: webhookUserID}, nil)
+
+		code, seen := serve(newTestService(repo, true), accessToken(t, tokenUserID, "google-123"))
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, webhookUserID, seen, "the request acts as the user the webhook created")
+	})
+
+	t.Run("name or email of another user", func(t *testing.T) {
+		userID := uuid.New()
+		repo := new(mockAuthRepository)
+		repo.On("GetUserByID", mock.Anything, userID).Return(nil, repository.ErrUserNotFound)
+		repo.On("ProvisionUser", mock.Anything, userID, userData).Return(nil, repository.ErrUserTaken)
+
+		code, _ := serve(newTestService(repo, true), accessToken(t, userID, "google-123"))
+		assert.Equal(t, http.StatusConflict, code)
+	})
+}
+
+func TestMiddleware_ProvisioningRefused(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		userID := uuid.New()
+		repo := new(mockAuthRepository)
+		repo.On("GetUserByID", mock.Anything, userID).Return(nil, repository.ErrUserNotFound)
+
+		code, _ := serve(newTestService(repo, false), accessToken(t, userID, "google-123"))
+		assert.Equal(t, http.StatusForbidden, code)
+		repo.AssertNotCalled(t, "ProvisionUser", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("token without an external ID", func(t *testing.T) {
+		userID := uuid.New()
+		repo := new(mockAuthRepository)
+		repo.On("GetUserByID", mock.Anything, userID).Return(nil, repository.ErrUserNotFound)
+
+		code, _ := serve(newTestService(repo, true), accessToken(t, userID, ""))
+		assert.Equal(t, http.StatusForbidden, code)
+		repo.AssertNotCalled(t, "ProvisionUser", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
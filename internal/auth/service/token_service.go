@@ -8,6 +8,7 @@ import (
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/auth/repository"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/auth/types"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 	"github.com/go-chi/jwtauth/v5"
 	"github.com/google/uuid"
 	"github.com/lestrrat-go/jwx/v2/jwt"
@@ -87,7 +88,7 @@ func (s *tokenService) GenerateTokenPair(ctx context.Context, userID uuid.UUID,
 	return &types.TokenPair{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
-		ExpiresAt:    time.Now().Add(s.config.JWT.AccessTokenTTL),
+		ExpiresAt:    coreTypes.NewTimestamp(time.Now().Add(s.config.JWT.AccessTokenTTL)),
 	}, nil
 }
 
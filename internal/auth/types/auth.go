@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"time"
 
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
 	"github.com/google/uuid"
@@ -35,9 +36,9 @@ type LogoutResponse struct {
 
 // TokenPair represents an access and refresh token pair
 type TokenPair struct {
-	AccessToken  string    `json:"access_token"`
-	RefreshToken string    `json:"refresh_token"`
-	ExpiresAt    time.Time `json:"expires_at"`
+	AccessToken  string              `json:"access_token"`
+	RefreshToken string              `json:"refresh_token"`
+	ExpiresAt    coreTypes.Timestamp `json:"expires_at" swaggertype:"string" format:"date-time"`
 }
 
 // BeginAuthRequest represents the request to start OAuth flow
@@ -12,6 +12,11 @@ type Config struct {
 
 	// Cookie configuration
 	Cookie CookieConfig `mapstructure:"cookie"`
+
+	// JITProvisioning creates the user of a valid access token on its first request when
+	// no row has it yet, off leaves creating users to sign ins and the webhook and answers
+	// such requests with a 403
+	JITProvisioning bool `mapstructure:"jit_provisioning"`
 }
 
 // JWTConfig holds JWT specific configuration
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	backupTypes "github.com/Abdelrahman-habib/expense-tracker/internal/backups/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	jobTypes "github.com/Abdelrahman-habib/expense-tracker/internal/jobs/types"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+type mockBackupService struct {
+	mock.Mock
+}
+
+func (m *mockBackupService) WriteExportArchive(ctx context.Context, userID uuid.UUID, w io.Writer) error {
+	args := m.Called(ctx, userID, w)
+	if write, ok := args.Get(0).(func(io.Writer) error); ok {
+		return write(w)
+	}
+	return args.Error(0)
+}
+
+func (m *mockBackupService) StartBackup(ctx context.Context, userID uuid.UUID) (backupTypes.BackupJob, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(backupTypes.BackupJob), args.Error(1)
+}
+
+func (m *mockBackupService) GetBackup(ctx context.Context, userID, jobID uuid.UUID) (backupTypes.BackupJob, error) {
+	args := m.Called(ctx, userID, jobID)
+	return args.Get(0).(backupTypes.BackupJob), args.Error(1)
+}
+
+func (m *mockBackupService) OpenBackup(ctx context.Context, jobID uuid.UUID, download backupTypes.SignedDownload) (backupTypes.BackupJob, io.ReadCloser, error) {
+	args := m.Called(ctx, jobID, download)
+	file, _ := args.Get(1).(io.ReadCloser)
+	return args.Get(0).(backupTypes.BackupJob), file, args.Error(2)
+}
+
+func TestBackupHandler_ExportArchive(t *testing.T) {
+	userID := uuid.New()
+
+	newRequest := func(target string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		return req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+	}
+
+	t.Run("streams the archive as an attachment", func(t *testing.T) {
+		mockService := new(mockBackupService)
+		handler := NewBackupHandler(mockService, zap.NewNop())
+		mockService.On("WriteExportArchive", mock.Anything, userID, mock.Anything).Return(func(w io.Writer) error {
+			_, err := io.WriteString(w, "PK")
+			return err
+		})
+
+		w := httptest.NewRecorder()
+		handler.ExportArchive(w, newRequest("/me/export.zip"))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+		expected := fmt.Sprintf(`attachment; filename="export-%s.zip"`, time.Now().UTC().Format(time.DateOnly))
+		assert.Equal(t, expected, w.Header().Get("Content-Disposition"))
+		assert.Equal(t, "PK", w.Body.String())
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("unknown query parameter in strict mode", func(t *testing.T) {
+		mockService := new(mockBackupService)
+		handler := NewBackupHandler(mockService, zap.NewNop())
+
+		req := newRequest("/me/export.zip?format=json")
+		req = req.WithContext(context.WithValue(req.Context(), requestcontext.QueryParamsModeKey, string(types.QueryParamsStrict)))
+		w := httptest.NewRecorder()
+		handler.ExportArchive(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "WriteExportArchive", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		mockService := new(mockBackupService)
+		handler := NewBackupHandler(mockService, zap.NewNop())
+
+		w := httptest.NewRecorder()
+		handler.ExportArchive(w, httptest.NewRequest(http.MethodGet, "/me/export.zip", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestBackupHandler_StartBackup(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("accepted", func(t *testing.T) {
+		mockService := new(mockBackupService)
+		handler := NewBackupHandler(mockService, zap.NewNop())
+		job := backupTypes.BackupJob{Job: jobTypes.Job{JobID: uuid.New(), Type: jobTypes.JobTypeAccountBackup, Status: jobTypes.JobStatusPending, Total: 12}}
+		mockService.On("StartBackup", mock.Anything, userID).Return(job, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/me/backup", nil)
+		req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+		w := httptest.NewRecorder()
+		handler.StartBackup(w, req)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Contains(t, w.Body.String(), job.JobID.String())
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		mockService := new(mockBackupService)
+		handler := NewBackupHandler(mockService, zap.NewNop())
+
+		w := httptest.NewRecorder()
+		handler.StartBackup(w, httptest.NewRequest(http.MethodPost, "/me/backup", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		mockService.AssertNotCalled(t, "StartBackup", mock.Anything, mock.Anything)
+	})
+}
+
+func TestBackupHandler_GetBackup(t *testing.T) {
+	userID := uuid.New()
+
+	newRequest := func(jobID string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/me/backup/"+jobID, nil)
+		routeCtx := chi.NewRouteContext()
+		routeCtx.URLParams.Add("jobId", jobID)
+		ctx := context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx)
+		return req.WithContext(context.WithValue(ctx, requestcontext.UserIDKey, userID))
+	}
+
+	t.Run("returns the job with its download link", func(t *testing.T) {
+		mockService := new(mockBackupService)
+		handler := NewBackupHandler(mockService, zap.NewNop())
+		jobID := uuid.New()
+		job := backupTypes.BackupJob{
+			Job:         jobTypes.Job{JobID: jobID, Status: jobTypes.JobStatusCompleted},
+			DownloadURL: "/backups/" + jobID.String() + "/download?signature=abc",
+		}
+		mockService.On("GetBackup", mock.Anything, userID, jobID).Return(job, nil)
+
+		w := httptest.NewRecorder()
+		handler.GetBackup(w, newRequest(jobID.String()))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"downloadUrl":"/backups/`+jobID.String()+`/download?signature=abc"`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockService := new(mockBackupService)
+		handler := NewBackupHandler(mockService, zap.NewNop())
+		jobID := uuid.New()
+		mockService.On("GetBackup", mock.Anything, userID, jobID).Return(backupTypes.BackupJob{}, errors.NewNotFoundError("backup not found"))
+
+		w := httptest.NewRecorder()
+		handler.GetBackup(w, newRequest(jobID.String()))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("invalid job ID", func(t *testing.T) {
+		mockService := new(mockBackupService)
+		handler := NewBackupHandler(mockService, zap.NewNop())
+
+		w := httptest.NewRecorder()
+		handler.GetBackup(w, newRequest("not-a-uuid"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "GetBackup", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestBackupHandler_DownloadBackup(t *testing.T) {
+	jobID := uuid.New()
+	download := backupTypes.SignedDownload{UserID: uuid.New(), Expires: time.Unix(1704070800, 0), Signature: "abc"}
+
+	newRequest := func(query string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/backups/"+jobID.String()+"/download?"+query, nil)
+		routeCtx := chi.NewRouteContext()
+		routeCtx.URLParams.Add("id", jobID.String())
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+	}
+
+	t.Run("sends the archive", func(t *testing.T) {
+		mockService := new(mockBackupService)
+		handler := NewBackupHandler(mockService, zap.NewNop())
+		completed := types.NewTimestamp(time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC))
+		job := backupTypes.BackupJob{Job: jobTypes.Job{JobID: jobID, Status: jobTypes.JobStatusCompleted, CompletedAt: &completed}}
+		mockService.On("OpenBackup", mock.Anything, jobID, download).Return(job, io.NopCloser(strings.NewReader("PK")), nil)
+
+		w := httptest.NewRecorder()
+		handler.DownloadBackup(w, newRequest(download.Query()))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="backup-2025-02-10.zip"`, w.Header().Get("Content-Disposition"))
+		assert.Equal(t, "PK", w.Body.String())
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid link", func(t *testing.T) {
+		mockService := new(mockBackupService)
+		handler := NewBackupHandler(mockService, zap.NewNop())
+		mockService.On("OpenBackup", mock.Anything, jobID, download).Return(backupTypes.BackupJob{}, nil, errors.NewForbiddenError("the download link is invalid"))
+
+		w := httptest.NewRecorder()
+		handler.DownloadBackup(w, newRequest(download.Query()))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("malformed query", func(t *testing.T) {
+		mockService := new(mockBackupService)
+		handler := NewBackupHandler(mockService, zap.NewNop())
+
+		w := httptest.NewRecorder()
+		handler.DownloadBackup(w, newRequest("user=someone&expires=soon&signature=abc"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "OpenBackup", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/backups/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// DownloadBackup godoc
+// @Summary Download a full account backup
+// @Description Sends the archive of a completed backup. The link comes from GET /me/backup/{jobId}, its signed query authorizes the download so it works without a session until it expires.
+// @Tags Backups
+// @Produce application/zip
+// @Param id path string true "Job ID" format(uuid)
+// @Param user query string true "User ID" format(uuid)
+// @Param expires query int true "Unix time the link expires at"
+// @Param signature query string true "Signature of the link"
+// @Success 200 {file} file "Zip archive of JSON-lines files and manifest.json"
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse "The link is invalid or expired"
+// @Failure 404 {object} errors.ErrorResponse "No such backup, or it expired"
+// @Failure 409 {object} errors.ErrorResponse "The backup is not completed"
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /backups/{id}/download [get]
+// @ID DownloadBackup
+func (h *BackupHandler) DownloadBackup(w http.ResponseWriter, r *http.Request) {
+	if !h.CheckQueryParams(w, r, types.UserParam, types.ExpiresParam, types.SignatureParam) {
+		return
+	}
+
+	jobID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	download, err := types.ParseSignedDownload(r.URL.Query())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	job, file, err := h.service.OpenBackup(r.Context(), jobID, download)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+	defer file.Close()
+
+	filename := fmt.Sprintf("backup-%s.zip", job.CompletedAt.UTC().Format(time.DateOnly))
+	h.SendFile(w, handlers.MediaTypeZip, filename, file)
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+)
+
+// ExportArchive godoc
+// @Summary Export all data as a zip
+// @Description Streams a zip archive of the user's data, one CSV per resource type: contacts.csv, projects.csv and wallets.csv.
+// @Description Each file is written the way the resource's own CSV export is. A file that fails midway keeps the rows written
+// @Description before the failure and is named in an errors.txt entry, the other files are still archived.
+// @Tags Backups
+// @Produce application/zip
+// @Security BearerAuth
+// @Success 200 {file} file "Zip archive of CSV files"
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /me/export.zip [get]
+// @ID ExportArchive
+func (h *BackupHandler) ExportArchive(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	if !h.CheckQueryParams(w, r) {
+		return
+	}
+
+	// the status is out before the first section is read, failures of a section end up
+	// in the archive and only a broken connection cuts it short
+	filename := fmt.Sprintf("export-%s.zip", time.Now().UTC().Format(time.DateOnly))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	// the service logs the failure, there's no way left to tell the client
+	_ = h.service.WriteExportArchive(r.Context(), userID, w)
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// GetBackup godoc
+// @Summary Get a full account backup
+// @Description Reports the progress of a backup job. Once it is completed the downloadUrl downloads the archive without a session until downloadExpiresAt, ask again for a fresh link.
+// @Description The archive is deleted at expiresAt, the backup is not found from then on.
+// @Tags Backups
+// @Produce json
+// @Security BearerAuth
+// @Param jobId path string true "Job ID" format(uuid)
+// @Success 200 {object} payloads.Response{data=types.BackupJob}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse "No such backup, or it expired"
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /me/backup/{jobId} [get]
+// @ID GetBackup
+func (h *BackupHandler) GetBackup(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	jobID, err := uuid.Parse(chi.URLParam(r, "jobId"))
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	job, err := h.service.GetBackup(r.Context(), userID, jobID)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.OK(job))
+}
@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"github.com/Abdelrahman-habib/expense-tracker/internal/backups/service"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
+	"go.uber.org/zap"
+)
+
+type BackupHandler struct {
+	handlers.BaseHandler
+	service service.BackupService
+}
+
+func NewBackupHandler(service service.BackupService, logger *zap.Logger) *BackupHandler {
+	return &BackupHandler{
+		BaseHandler: handlers.NewBaseHandler(logger),
+		service:     service,
+	}
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+)
+
+// StartBackup godoc
+// @Summary Start a full account backup
+// @Description Queues a backup of the whole account and returns the job producing it. The archive is written in the background, poll /me/backup/{jobId} until it is completed and download it from its downloadUrl.
+// @Description The zip holds one JSON-lines file per resource type: contacts.jsonl (with their notes), projects.jsonl, wallets.jsonl, tags.jsonl, attachments.jsonl (metadata only) and ledger_entries.jsonl,
+// @Description and a manifest.json with the schema version and the rows of each file.
+// @Tags Backups
+// @Produce json
+// @Security BearerAuth
+// @Success 202 {object} payloads.Response{data=types.BackupJob}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /me/backup [post]
+// @ID StartBackup
+func (h *BackupHandler) StartBackup(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	if !h.CheckQueryParams(w, r) {
+		return
+	}
+
+	job, err := h.service.StartBackup(r.Context(), userID)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.Accepted(job))
+}
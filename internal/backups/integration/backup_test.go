@@ -0,0 +1,337 @@
+package integration
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/config"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/app"
+	backupRoutes "github.com/Abdelrahman-habib/expense-tracker/internal/backups/routes"
+	backupService "github.com/Abdelrahman-habib/expense-tracker/internal/backups/service"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/backups/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/blob"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	jobTypes "github.com/Abdelrahman-habib/expense-tracker/internal/jobs/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/jobs/worker"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"go.uber.org/zap"
+)
+
+type BackupIntegrationTestSuite struct {
+	suite.Suite
+	container testcontainers.Container
+	service   db.Service
+	pool      *pgxpool.Pool
+	jobs      *worker.Runner
+	blobs     blob.Store
+	router    *chi.Mux
+	userID    uuid.UUID
+	ctx       context.Context
+}
+
+func TestBackupIntegrationSuite(t *testing.T) {
+	suite.Run(t, new(BackupIntegrationTestSuite))
+}
+
+func (s *BackupIntegrationTestSuite) SetupSuite() {
+	s.ctx = context.Background()
+
+	var host, port string
+
+	if os.Getenv("CI") == "true" {
+		// Running in GitHub Actions, use service-based PostgreSQL
+		host = "localhost"
+		port = "5432"
+	} else {
+		// Running locally, use TestContainers
+		req := testcontainers.ContainerRequest{
+			Image:        "postgres:15-alpine",
+			ExposedPorts: []string{"5432/tcp"},
+			WaitingFor:   wait.ForListeningPort("5432/tcp"),
+			Env: map[string]string{
+				"POSTGRES_DB":       "testdb",
+				"POSTGRES_USER":     "test",
+				"POSTGRES_PASSWORD": "test",
+			},
+			NetworkMode: "bridge",
+		}
+
+		container, err := testcontainers.GenericContainer(s.ctx, testcontainers.GenericContainerRequest{
+			ContainerRequest: req,
+			Started:          true,
+		})
+		require.NoError(s.T(), err)
+		s.container = container
+
+		host, err = container.Host(s.ctx)
+		require.NoError(s.T(), err)
+		mappedPort, err := container.MappedPort(s.ctx, "5432")
+		require.NoError(s.T(), err)
+		port = mappedPort.Port()
+	}
+
+	cfg := config.DatabaseConfig{
+		Host:        host,
+		Port:        port,
+		Username:    "test",
+		Password:    "test",
+		Database:    "testdb",
+		Schema:      "public",
+		MaxConns:    5,
+		MinConns:    1,
+		MaxLifetime: time.Hour,
+		MaxIdleTime: time.Minute * 30,
+		HealthCheck: time.Minute,
+		SSLMode:     "disable",
+		SearchPath:  "public",
+	}
+
+	dbService := db.NewService(cfg)
+	s.service = dbService
+
+	pool, err := pgxpool.New(s.ctx, cfg.GetDSN())
+	require.NoError(s.T(), err)
+	s.pool = pool
+
+	require.NoError(s.T(), s.runMigrations())
+
+	// the workers aren't started, the tests run the queued backups with RunPending
+	logger := zap.NewNop()
+	s.jobs = worker.NewRunner(dbService, config.JobsConfig{Workers: 1}, logger)
+	blobs, err := blob.NewFileStore(s.T().TempDir())
+	require.NoError(s.T(), err)
+	s.blobs = blobs
+	links, err := backupService.NewLinks("backup-secret", time.Minute)
+	require.NoError(s.T(), err)
+
+	routes := backupRoutes.New(dbService, s.jobs, blobs, config.BackupsConfig{Retention: time.Hour, LinkTTL: time.Minute}, links, logger)
+	router := chi.NewRouter()
+	routes.RegisterRoutes(router)
+	routes.RegisterPublicRoutes(router)
+	s.router = router
+}
+
+func (s *BackupIntegrationTestSuite) TearDownSuite() {
+	if s.pool != nil {
+		s.pool.Close()
+	}
+	if s.service != nil {
+		s.service.Close()
+	}
+	if s.container != nil && os.Getenv("CI") != "true" {
+		err := s.container.Terminate(s.ctx)
+		require.NoError(s.T(), err)
+	}
+}
+
+// SetupTest creates a user with a little of everything a backup archives
+func (s *BackupIntegrationTestSuite) SetupTest() {
+	s.userID = uuid.New()
+	s.exec(`
+		INSERT INTO users (user_id, external_id, name, email)
+		VALUES ($1, $2, 'Backup User', 'backup@example.com')
+	`, s.userID, s.userID.String())
+
+	s.exec(`INSERT INTO tags (user_id, name) VALUES ($1, 'home'), ($1, 'work')`, s.userID)
+	s.exec(`INSERT INTO contacts (user_id, name, notes) VALUES ($1, 'Ada Lovelace', 'met at the conference'), ($1, 'Grace Hopper', NULL)`, s.userID)
+	s.exec(`INSERT INTO contacts (user_id, name, deleted_at) VALUES ($1, 'In The Trash', NOW())`, s.userID)
+	s.exec(`INSERT INTO projects (user_id, name, status) VALUES ($1, 'Roof repair', 'ongoing')`, s.userID)
+
+	// the opening balance and the adjustment each record a ledger entry
+	var walletID uuid.UUID
+	s.Require().NoError(s.pool.QueryRow(s.ctx, `
+		INSERT INTO wallets (user_id, name, currency, balance) VALUES ($1, 'Cash', 'USD', 100) RETURNING wallet_id
+	`, s.userID).Scan(&walletID))
+	s.exec(`UPDATE wallets SET balance = 80 WHERE wallet_id = $1`, walletID)
+	s.exec(`INSERT INTO wallets (user_id, name, currency) VALUES ($1, 'Savings', 'EUR')`, s.userID)
+
+	var entryID uuid.UUID
+	s.Require().NoError(s.pool.QueryRow(s.ctx, `
+		INSERT INTO pending_entries (user_id, sender, subject, status) VALUES ($1, 'backup@example.com', 'Receipt', 'needs_review') RETURNING entry_id
+	`, s.userID).Scan(&entryID))
+	s.exec(`
+		INSERT INTO pending_entry_attachments (entry_id, filename, content_type, size_bytes, content)
+		VALUES ($1, 'receipt.pdf', 'application/pdf', 8, '%PDF-1.4')
+	`, entryID)
+}
+
+func (s *BackupIntegrationTestSuite) runMigrations() error {
+	migrationsDir := "../../db/sql/migrations"
+
+	sqlDB := stdlib.OpenDBFromPool(s.pool)
+	defer sqlDB.Close()
+
+	if err := goose.SetDialect("postgres"); err != nil {
+		return err
+	}
+	return goose.Up(sqlDB, migrationsDir)
+}
+
+func (s *BackupIntegrationTestSuite) exec(query string, args ...any) {
+	_, err := s.pool.Exec(s.ctx, query, args...)
+	s.Require().NoError(err)
+}
+
+func (s *BackupIntegrationTestSuite) count(query string) int {
+	var count int
+	s.Require().NoError(s.pool.QueryRow(s.ctx, query, s.userID).Scan(&count))
+	return count
+}
+
+// request sends a request as the user, or without a session when authenticated is false
+func (s *BackupIntegrationTestSuite) request(method, target string, authenticated bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	if authenticated {
+		req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, s.userID))
+	}
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+	return rec
+}
+
+// getBackup returns the backup job of the user, decoded from GET /me/backup/{jobId}
+func (s *BackupIntegrationTestSuite) getBackup(jobID uuid.UUID) types.BackupJob {
+	rec := s.request(http.MethodGet, "/me/backup/"+jobID.String(), true)
+	s.Require().Equal(http.StatusOK, rec.Code, rec.Body.String())
+
+	var response struct {
+		Data types.BackupJob `json:"data"`
+	}
+	s.Require().NoError(json.NewDecoder(rec.Body).Decode(&response))
+	return response.Data
+}
+
+// backUp starts a backup, runs it and returns the completed job
+func (s *BackupIntegrationTestSuite) backUp() types.BackupJob {
+	rec := s.request(http.MethodPost, "/me/backup", true)
+	s.Require().Equal(http.StatusAccepted, rec.Code, rec.Body.String())
+	var response struct {
+		Data types.BackupJob `json:"data"`
+	}
+	s.Require().NoError(json.NewDecoder(rec.Body).Decode(&response))
+	s.Equal(jobTypes.JobStatusPending, response.Data.Status)
+
+	s.Require().Equal(1, s.jobs.RunPending(s.ctx))
+	backup := s.getBackup(response.Data.JobID)
+	s.Require().Equal(jobTypes.JobStatusCompleted, backup.Status)
+	return backup
+}
+
+// readArchive returns the lines of each file of a zip archive by name
+func readArchive(t *testing.T, data []byte) map[string][]string {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	files := make(map[string][]string)
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		require.NoError(t, err)
+		lines := []string{}
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		require.NoError(t, scanner.Err())
+		reader.Close()
+		files[file.Name] = lines
+	}
+	return files
+}
+
+func (s *BackupIntegrationTestSuite) TestBackupMatchesTheDatabase() {
+	backup := s.backUp()
+	s.Require().NotEmpty(backup.DownloadURL)
+	s.NotNil(backup.ExpiresAt)
+	s.Equal(backup.Total, backup.Processed)
+
+	// the link works without a session
+	rec := s.request(http.MethodGet, backup.DownloadURL, false)
+	s.Require().Equal(http.StatusOK, rec.Code, rec.Body.String())
+	s.Equal("application/zip", rec.Header().Get("Content-Type"))
+
+	files := readArchive(s.T(), rec.Body.Bytes())
+	var manifest types.Manifest
+	s.Require().NoError(json.Unmarshal([]byte(strings.Join(files[types.ManifestFile], "\n")), &manifest))
+	s.Equal(types.SchemaVersion, manifest.SchemaVersion)
+	s.Equal(s.userID, manifest.UserID)
+
+	expected := map[string]int{
+		"contacts.jsonl":       s.count(`SELECT COUNT(*) FROM contacts WHERE user_id = $1 AND deleted_at IS NULL`),
+		"projects.jsonl":       s.count(`SELECT COUNT(*) FROM projects WHERE user_id = $1 AND deleted_at IS NULL`),
+		"wallets.jsonl":        s.count(`SELECT COUNT(*) FROM wallets WHERE user_id = $1 AND deleted_at IS NULL`),
+		"tags.jsonl":           s.count(`SELECT COUNT(*) FROM tags WHERE user_id = $1`),
+		"attachments.jsonl":    s.count(`SELECT COUNT(*) FROM pending_entry_attachments a JOIN pending_entries e USING (entry_id) WHERE e.user_id = $1`),
+		"ledger_entries.jsonl": s.count(`SELECT COUNT(*) FROM wallet_ledger_entries l JOIN wallets w USING (wallet_id) WHERE w.user_id = $1`),
+	}
+	s.Equal(map[string]int{
+		"contacts.jsonl": 2, "projects.jsonl": 1, "wallets.jsonl": 2, "tags.jsonl": 2, "attachments.jsonl": 1, "ledger_entries.jsonl": 2,
+	}, expected)
+	s.Equal(expected, manifest.Counts)
+	for name, count := range expected {
+		s.Len(files[name], count, name)
+	}
+
+	s.Contains(files["contacts.jsonl"][0]+files["contacts.jsonl"][1], "met at the conference")
+	var attachment map[string]any
+	s.Require().NoError(json.Unmarshal([]byte(files["attachments.jsonl"][0]), &attachment))
+	s.Equal("receipt.pdf", attachment["filename"])
+	s.NotContains(files["attachments.jsonl"][0], "%PDF", "attachment contents stay out of the archive")
+}
+
+func (s *BackupIntegrationTestSuite) TestDownloadNeedsAValidLink() {
+	backup := s.backUp()
+	link, err := url.Parse(backup.DownloadURL)
+	s.Require().NoError(err)
+
+	query := link.Query()
+	query.Set(types.UserParam, uuid.NewString())
+	rec := s.request(http.MethodGet, link.Path+"?"+query.Encode(), false)
+	s.Equal(http.StatusForbidden, rec.Code)
+
+	query = link.Query()
+	query.Set(types.SignatureParam, "0000")
+	rec = s.request(http.MethodGet, link.Path+"?"+query.Encode(), false)
+	s.Equal(http.StatusForbidden, rec.Code)
+
+	// another user's backups aren't theirs to follow
+	other := uuid.New()
+	req := httptest.NewRequest(http.MethodGet, "/me/backup/"+backup.JobID.String(), nil)
+	req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, other))
+	recorder := httptest.NewRecorder()
+	s.router.ServeHTTP(recorder, req)
+	s.Equal(http.StatusNotFound, recorder.Code)
+}
+
+func (s *BackupIntegrationTestSuite) TestJanitorDeletesExpiredBackups() {
+	backup := s.backUp()
+	var key string
+	s.Require().NoError(s.pool.QueryRow(s.ctx, `SELECT result_key FROM jobs WHERE job_id = $1`, backup.JobID).Scan(&key))
+	s.exec(`UPDATE jobs SET completed_at = NOW() - INTERVAL '2 hours' WHERE job_id = $1`, backup.JobID)
+
+	janitor := app.NewJanitor(s.service.Queries(), config.JanitorConfig{}, config.TrashConfig{}, config.BackupsConfig{Retention: time.Hour}, s.blobs, zap.NewNop())
+	_, err := janitor.Run(s.ctx)
+	s.Require().NoError(err)
+	s.Equal(int64(1), janitor.Cleaned()["backups"])
+
+	_, err = s.blobs.Open(s.ctx, key)
+	s.ErrorIs(err, blob.ErrNotFound)
+	s.Zero(s.count(`SELECT COUNT(*) FROM jobs WHERE user_id = $1 AND type = 'account_backup'`))
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/backups/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
+	"github.com/google/uuid"
+)
+
+// Repository reads the rows of a backup no other repository lists across the account
+type Repository interface {
+	// CountRows counts the rows a backup of the user archives, over every section
+	CountRows(ctx context.Context, userID uuid.UUID) (int, error)
+
+	// ListAttachments retrieves up to limit attachments of the user's pending entries
+	// after the afterID cursor, without their contents
+	ListAttachments(ctx context.Context, userID, afterID uuid.UUID, limit int32) ([]types.Attachment, error)
+
+	// ListLedgerEntries retrieves up to limit ledger entries of the user's active wallets
+	// after the afterSeq cursor, in the order they were recorded
+	ListLedgerEntries(ctx context.Context, userID uuid.UUID, afterSeq int64, limit int32) ([]types.LedgerEntry, error)
+}
+
+type backupRepository struct {
+	q *db.Queries
+}
+
+// New creates a new instance of Repository
+func New(q *db.Queries) Repository {
+	return &backupRepository{q: q}
+}
+
+func (r *backupRepository) CountRows(ctx context.Context, userID uuid.UUID) (int, error) {
+	counts, err := r.q.CountBackupRows(ctx, userID)
+	if err != nil {
+		return 0, errors.HandleRepositoryError(err, "count", "backup rows")
+	}
+	return int(counts.Contacts + counts.Projects + counts.Wallets + counts.Tags + counts.Attachments + counts.LedgerEntries), nil
+}
+
+func (r *backupRepository) ListAttachments(ctx context.Context, userID, afterID uuid.UUID, limit int32) ([]types.Attachment, error) {
+	rows, err := r.q.ListBackupAttachments(ctx, db.ListBackupAttachmentsParams{
+		UserID:  userID,
+		AfterID: afterID,
+		Limit:   limit,
+	})
+	if err != nil {
+		return nil, errors.HandleRepositoryError(err, "list", "attachments")
+	}
+
+	attachments := make([]types.Attachment, len(rows))
+	for i, row := range rows {
+		attachments[i] = types.Attachment{
+			AttachmentID: row.AttachmentID,
+			EntryID:      row.EntryID,
+			Filename:     row.Filename,
+			ContentType:  row.ContentType,
+			Size:         row.SizeBytes,
+		}
+	}
+	return attachments, nil
+}
+
+func (r *backupRepository) ListLedgerEntries(ctx context.Context, userID uuid.UUID, afterSeq int64, limit int32) ([]types.LedgerEntry, error) {
+	rows, err := r.q.ListBackupLedgerEntries(ctx, db.ListBackupLedgerEntriesParams{
+		UserID:   userID,
+		AfterSeq: afterSeq,
+		Limit:    limit,
+	})
+	if err != nil {
+		return nil, errors.HandleRepositoryError(err, "list", "ledger entries")
+	}
+
+	entries := make([]types.LedgerEntry, len(rows))
+	for i, row := range rows {
+		entries[i] = types.LedgerEntry{
+			EntryID:     row.EntryID,
+			WalletID:    row.WalletID,
+			Seq:         row.Seq,
+			Description: row.Description,
+			OccurredAt:  coreTypes.NewTimestamp(row.OccurredAt.Time),
+		}
+		if amount := utils.GetFloat64Ptr(row.Amount); amount != nil {
+			entries[i].Amount = *amount
+		}
+	}
+	return entries, nil
+}
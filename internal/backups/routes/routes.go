@@ -0,0 +1,54 @@
+package routes
+
+import (
+	"github.com/Abdelrahman-habib/expense-tracker/config"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/backups/handlers"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/backups/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/backups/service"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/backups/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/blob"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	jobRepository "github.com/Abdelrahman-habib/expense-tracker/internal/jobs/repository"
+	jobTypes "github.com/Abdelrahman-habib/expense-tracker/internal/jobs/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/jobs/worker"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// Router encapsulates the backup routes setup
+type Router struct {
+	handler *handlers.BackupHandler
+}
+
+// New creates a new backup router, registering the task of the full account backups
+// with jobs
+func New(dbService db.Service, jobs *worker.Runner, blobs blob.Store, cfg config.BackupsConfig, links *service.Links, logger *zap.Logger) *Router {
+	q := dbService.Queries()
+	jobs.RegisterTask(jobTypes.JobTypeAccountBackup, service.BackupTask(service.NewBackupSections(q), blobs, jobRepository.New(q)))
+
+	backupService := service.NewBackupService(service.NewSections(q), service.BackupOptions{
+		Repo:      repository.New(q),
+		Jobs:      jobs,
+		Blobs:     blobs,
+		Links:     links,
+		Retention: cfg.Retention,
+	}, logger)
+	handler := handlers.NewBackupHandler(backupService, logger)
+
+	return &Router{
+		handler: handler,
+	}
+}
+
+// RegisterRoutes registers all backup routes
+func (r *Router) RegisterRoutes(router chi.Router) {
+	router.Get("/me/export.zip", r.handler.ExportArchive)
+	router.Post("/me/backup", r.handler.StartBackup)
+	router.Get("/me/backup/{jobId}", r.handler.GetBackup)
+}
+
+// RegisterPublicRoutes registers the download of the backup archives, authorized by the
+// signed query of the link rather than a session
+func (r *Router) RegisterPublicRoutes(router chi.Router) {
+	router.Get(types.DownloadPath, r.handler.DownloadBackup)
+}
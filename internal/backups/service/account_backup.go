@@ -0,0 +1,184 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/backups/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/blob"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/bulk"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	jobTypes "github.com/Abdelrahman-habib/expense-tracker/internal/jobs/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/jobs/worker"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// archiveKeyBytes is the size of the random part of an archive's blob key
+const archiveKeyBytes = 16
+
+// ProgressRecorder saves the progress of a running job, the jobs repository implements it
+type ProgressRecorder interface {
+	UpdateJobProgress(ctx context.Context, jobID uuid.UUID, progress bulk.Progress) error
+}
+
+// StartBackup queues a full account backup of the user
+func (s *backupService) StartBackup(ctx context.Context, userID uuid.UUID) (_ types.BackupJob, err error) {
+	op := s.operation("StartBackup", userID)
+	defer op.End(&err)
+
+	// the count is an estimate for progress, rows may come and go before the job runs
+	count, err := s.backups.Repo.CountRows(ctx, userID)
+	if err != nil {
+		return types.BackupJob{}, err
+	}
+
+	job, err := s.backups.Jobs.Enqueue(ctx, userID, jobTypes.JobTypeAccountBackup, count, struct{}{})
+	if err != nil {
+		return types.BackupJob{}, err
+	}
+	op.With(zap.String("job_id", job.JobID.String()))
+	return types.BackupJob{Job: job}, nil
+}
+
+// GetBackup returns a backup job of the user, with a fresh download link once it is
+// completed. Other jobs and expired backups are not found.
+func (s *backupService) GetBackup(ctx context.Context, userID, jobID uuid.UUID) (_ types.BackupJob, err error) {
+	defer s.operation("GetBackup", userID, zap.String("job_id", jobID.String())).End(&err)
+
+	backup, err := s.getBackup(ctx, userID, jobID)
+	if err != nil {
+		return types.BackupJob{}, err
+	}
+	if backup.Status == jobTypes.JobStatusCompleted {
+		download := s.backups.Links.Sign(jobID, userID)
+		backup.DownloadURL = types.DownloadURL(jobID, download)
+		backup.DownloadExpiresAt = &coreTypes.Timestamp{Time: download.Expires}
+	}
+	return backup, nil
+}
+
+func (s *backupService) getBackup(ctx context.Context, userID, jobID uuid.UUID) (types.BackupJob, error) {
+	job, err := s.backups.Jobs.GetJob(ctx, jobID, userID)
+	if err != nil {
+		return types.BackupJob{}, err
+	}
+	if job.Type != jobTypes.JobTypeAccountBackup {
+		return types.BackupJob{}, errors.NewNotFoundError("backup not found")
+	}
+
+	backup := types.BackupJob{Job: job}
+	if job.Status == jobTypes.JobStatusCompleted && job.CompletedAt != nil {
+		expires := job.CompletedAt.Add(s.backups.Retention)
+		if !s.now().Before(expires) {
+			// the janitor deletes it on its next run
+			return types.BackupJob{}, errors.NewNotFoundError("the backup expired, start a new one")
+		}
+		backup.ExpiresAt = &coreTypes.Timestamp{Time: expires}
+	}
+	return backup, nil
+}
+
+// OpenBackup returns the completed backup a signed download link points to with a reader
+// of its archive, the caller closes the reader. Backups still running or failed conflict.
+func (s *backupService) OpenBackup(ctx context.Context, jobID uuid.UUID, download types.SignedDownload) (_ types.BackupJob, _ io.ReadCloser, err error) {
+	defer s.operation("OpenBackup", download.UserID, zap.String("job_id", jobID.String())).End(&err)
+
+	if err := s.backups.Links.Verify(jobID, download); err != nil {
+		return types.BackupJob{}, nil, err
+	}
+
+	backup, err := s.getBackup(ctx, download.UserID, jobID)
+	if err != nil {
+		return types.BackupJob{}, nil, err
+	}
+	if backup.Status != jobTypes.JobStatusCompleted || backup.ResultKey == nil || backup.CompletedAt == nil {
+		return types.BackupJob{}, nil, errors.NewConflictError("the backup is %s, its archive is ready once it is completed", backup.Status)
+	}
+
+	file, err := s.backups.Blobs.Open(ctx, *backup.ResultKey)
+	if stdErrors.Is(err, blob.ErrNotFound) {
+		return types.BackupJob{}, nil, errors.NewNotFoundError("the backup archive is no longer available, start a new backup")
+	}
+	if err != nil {
+		return types.BackupJob{}, nil, err
+	}
+	return backup, file, nil
+}
+
+// BackupTask returns the job task writing a full account backup to the blob store under
+// backups/<random>.zip, so the key of one archive doesn't lead to another. Every section
+// has to be archived whole, a failing one fails the backup. The manifest is written last,
+// counting the rows of each section, and the progress is saved after every section.
+func BackupTask(sections []Section, blobs blob.Store, progress ProgressRecorder) worker.Task {
+	return func(ctx context.Context, job jobTypes.Job) (int, string, error) {
+		key, err := archiveKey()
+		if err != nil {
+			return 0, "", err
+		}
+
+		created := time.Now()
+		manifest := types.Manifest{
+			SchemaVersion: types.SchemaVersion,
+			UserID:        job.UserID,
+			CreatedAt:     coreTypes.NewTimestamp(created),
+			Counts:        make(map[string]int, len(sections)),
+		}
+
+		var rows int
+		err = blobs.Put(ctx, key, func(w io.Writer) error {
+			archive := zip.NewWriter(w)
+			create := func(name string) (io.Writer, error) {
+				return archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: created})
+			}
+
+			for _, section := range sections {
+				entry, err := create(section.Name)
+				if err != nil {
+					return err
+				}
+				written, err := section.Write(ctx, job.UserID, entry)
+				if err != nil {
+					return fmt.Errorf("back up %s: %w", section.Name, err)
+				}
+				manifest.Counts[section.Name] = written
+				rows += written
+				if err := progress.UpdateJobProgress(ctx, job.JobID, bulk.Progress{Processed: rows, Succeeded: rows}); err != nil {
+					return err
+				}
+			}
+
+			entry, err := create(types.ManifestFile)
+			if err != nil {
+				return err
+			}
+			encoder := json.NewEncoder(entry)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(manifest); err != nil {
+				return err
+			}
+			return archive.Close()
+		})
+		if err != nil {
+			return 0, "", err
+		}
+		return rows, key, nil
+	}
+}
+
+// archiveKey returns a new random blob key of a backup archive
+func archiveKey() (string, error) {
+	random := make([]byte, archiveKeyBytes)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("generate backup key: %w", err)
+	}
+	return fmt.Sprintf("backups/%s.zip", hex.EncodeToString(random)), nil
+}
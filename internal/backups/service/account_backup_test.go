@@ -0,0 +1,263 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/backups/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/blob"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/bulk"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	jobTypes "github.com/Abdelrahman-habib/expense-tracker/internal/jobs/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type mockQueue struct {
+	mock.Mock
+}
+
+func (m *mockQueue) Enqueue(ctx context.Context, userID uuid.UUID, jobType jobTypes.JobType, total int, payload any) (jobTypes.Job, error) {
+	args := m.Called(ctx, userID, jobType, total, payload)
+	return args.Get(0).(jobTypes.Job), args.Error(1)
+}
+
+func (m *mockQueue) GetJob(ctx context.Context, jobID, userID uuid.UUID) (jobTypes.Job, error) {
+	args := m.Called(ctx, jobID, userID)
+	return args.Get(0).(jobTypes.Job), args.Error(1)
+}
+
+type mockRepository struct {
+	mock.Mock
+}
+
+func (m *mockRepository) CountRows(ctx context.Context, userID uuid.UUID) (int, error) {
+	args := m.Called(ctx, userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockRepository) ListAttachments(ctx context.Context, userID, afterID uuid.UUID, limit int32) ([]types.Attachment, error) {
+	args := m.Called(ctx, userID, afterID, limit)
+	return args.Get(0).([]types.Attachment), args.Error(1)
+}
+
+func (m *mockRepository) ListLedgerEntries(ctx context.Context, userID uuid.UUID, afterSeq int64, limit int32) ([]types.LedgerEntry, error) {
+	args := m.Called(ctx, userID, afterSeq, limit)
+	return args.Get(0).([]types.LedgerEntry), args.Error(1)
+}
+
+// recordedProgress keeps every progress a task saved
+type recordedProgress []bulk.Progress
+
+func (r *recordedProgress) UpdateJobProgress(ctx context.Context, jobID uuid.UUID, progress bulk.Progress) error {
+	*r = append(*r, progress)
+	return nil
+}
+
+var backupNow = time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC)
+
+func setupBackupTest(t *testing.T) (*mockQueue, *mockRepository, blob.Store, *backupService) {
+	queue, repo := new(mockQueue), new(mockRepository)
+	blobs, err := blob.NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	links, err := NewLinks("secret", 15*time.Minute)
+	require.NoError(t, err)
+	links.now = func() time.Time { return backupNow }
+
+	service := NewBackupService(nil, BackupOptions{
+		Repo:      repo,
+		Jobs:      queue,
+		Blobs:     blobs,
+		Links:     links,
+		Retention: 24 * time.Hour,
+	}, zap.NewNop()).(*backupService)
+	service.now = func() time.Time { return backupNow }
+	return queue, repo, blobs, service
+}
+
+func TestBackupService_StartBackup(t *testing.T) {
+	queue, repo, _, service := setupBackupTest(t)
+	userID := uuid.New()
+	job := jobTypes.Job{JobID: uuid.New(), UserID: userID, Type: jobTypes.JobTypeAccountBackup, Status: jobTypes.JobStatusPending, Total: 12}
+	repo.On("CountRows", mock.Anything, userID).Return(12, nil)
+	queue.On("Enqueue", mock.Anything, userID, jobTypes.JobTypeAccountBackup, 12, struct{}{}).Return(job, nil)
+
+	backup, err := service.StartBackup(context.Background(), userID)
+	require.NoError(t, err)
+	assert.Equal(t, types.BackupJob{Job: job}, backup)
+	queue.AssertExpectations(t)
+}
+
+func TestBackupService_GetBackup(t *testing.T) {
+	userID, jobID := uuid.New(), uuid.New()
+	completedAt := func(ago time.Duration) *coreTypes.Timestamp {
+		completed := coreTypes.NewTimestamp(backupNow.Add(-ago))
+		return &completed
+	}
+
+	t.Run("a completed backup gets a signed link", func(t *testing.T) {
+		queue, _, _, service := setupBackupTest(t)
+		queue.On("GetJob", mock.Anything, jobID, userID).Return(jobTypes.Job{
+			JobID: jobID, UserID: userID, Type: jobTypes.JobTypeAccountBackup, Status: jobTypes.JobStatusCompleted, CompletedAt: completedAt(time.Hour),
+		}, nil)
+
+		backup, err := service.GetBackup(context.Background(), userID, jobID)
+		require.NoError(t, err)
+		assert.Equal(t, backupNow.Add(23*time.Hour), backup.ExpiresAt.Time)
+		assert.Equal(t, backupNow.Add(15*time.Minute), backup.DownloadExpiresAt.Time)
+
+		link, err := url.Parse(backup.DownloadURL)
+		require.NoError(t, err)
+		assert.Equal(t, "/backups/"+jobID.String()+"/download", link.Path)
+		download, err := types.ParseSignedDownload(link.Query())
+		require.NoError(t, err)
+		assert.NoError(t, service.backups.Links.Verify(jobID, download))
+	})
+
+	t.Run("a running backup has no link", func(t *testing.T) {
+		queue, _, _, service := setupBackupTest(t)
+		queue.On("GetJob", mock.Anything, jobID, userID).Return(jobTypes.Job{
+			JobID: jobID, Type: jobTypes.JobTypeAccountBackup, Status: jobTypes.JobStatusRunning, Total: 10, Processed: 4,
+		}, nil)
+
+		backup, err := service.GetBackup(context.Background(), userID, jobID)
+		require.NoError(t, err)
+		assert.Empty(t, backup.DownloadURL)
+		assert.Nil(t, backup.ExpiresAt)
+		assert.Equal(t, 4, backup.Processed)
+	})
+
+	t.Run("an expired backup is not found", func(t *testing.T) {
+		queue, _, _, service := setupBackupTest(t)
+		queue.On("GetJob", mock.Anything, jobID, userID).Return(jobTypes.Job{
+			JobID: jobID, Type: jobTypes.JobTypeAccountBackup, Status: jobTypes.JobStatusCompleted, CompletedAt: completedAt(24 * time.Hour),
+		}, nil)
+
+		_, err := service.GetBackup(context.Background(), userID, jobID)
+		assert.True(t, errors.IsErrorType(err, errors.ErrorTypeNotFound))
+	})
+
+	t.Run("other jobs are not found", func(t *testing.T) {
+		queue, _, _, service := setupBackupTest(t)
+		queue.On("GetJob", mock.Anything, jobID, userID).Return(jobTypes.Job{
+			JobID: jobID, Type: jobTypes.JobTypeContactExport, Status: jobTypes.JobStatusCompleted, CompletedAt: completedAt(time.Hour),
+		}, nil)
+
+		_, err := service.GetBackup(context.Background(), userID, jobID)
+		assert.True(t, errors.IsErrorType(err, errors.ErrorTypeNotFound))
+	})
+}
+
+func TestBackupService_OpenBackup(t *testing.T) {
+	userID, jobID := uuid.New(), uuid.New()
+	key := "backups/archive.zip"
+	completed := coreTypes.NewTimestamp(backupNow.Add(-time.Hour))
+	job := jobTypes.Job{
+		JobID: jobID, UserID: userID, Type: jobTypes.JobTypeAccountBackup, Status: jobTypes.JobStatusCompleted, ResultKey: &key, CompletedAt: &completed,
+	}
+
+	t.Run("opens the archive of a signed link", func(t *testing.T) {
+		queue, _, blobs, service := setupBackupTest(t)
+		require.NoError(t, blobs.Put(context.Background(), key, func(w io.Writer) error {
+			_, err := io.WriteString(w, "PK")
+			return err
+		}))
+		queue.On("GetJob", mock.Anything, jobID, userID).Return(job, nil)
+
+		backup, file, err := service.OpenBackup(context.Background(), jobID, service.backups.Links.Sign(jobID, userID))
+		require.NoError(t, err)
+		defer file.Close()
+		content, err := io.ReadAll(file)
+		require.NoError(t, err)
+		assert.Equal(t, "PK", string(content))
+		assert.Equal(t, jobID, backup.JobID)
+	})
+
+	t.Run("a link of another job is refused", func(t *testing.T) {
+		queue, _, _, service := setupBackupTest(t)
+
+		_, _, err := service.OpenBackup(context.Background(), jobID, service.backups.Links.Sign(uuid.New(), userID))
+		assert.True(t, errors.IsErrorType(err, errors.ErrorTypeForbidden))
+		queue.AssertNotCalled(t, "GetJob", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("an archive the janitor deleted is not found", func(t *testing.T) {
+		queue, _, _, service := setupBackupTest(t)
+		queue.On("GetJob", mock.Anything, jobID, userID).Return(job, nil)
+
+		_, _, err := service.OpenBackup(context.Background(), jobID, service.backups.Links.Sign(jobID, userID))
+		assert.True(t, errors.IsErrorType(err, errors.ErrorTypeNotFound))
+	})
+
+	t.Run("a failed backup conflicts", func(t *testing.T) {
+		queue, _, _, service := setupBackupTest(t)
+		queue.On("GetJob", mock.Anything, jobID, userID).Return(jobTypes.Job{JobID: jobID, Type: jobTypes.JobTypeAccountBackup, Status: jobTypes.JobStatusFailed}, nil)
+
+		_, _, err := service.OpenBackup(context.Background(), jobID, service.backups.Links.Sign(jobID, userID))
+		assert.True(t, errors.IsErrorType(err, errors.ErrorTypeConflict))
+	})
+}
+
+func TestBackupTask(t *testing.T) {
+	job := jobTypes.Job{JobID: uuid.New(), UserID: uuid.New(), Type: jobTypes.JobTypeAccountBackup}
+
+	t.Run("archives every section and the manifest", func(t *testing.T) {
+		blobs, err := blob.NewFileStore(t.TempDir())
+		require.NoError(t, err)
+		var progress recordedProgress
+		task := BackupTask([]Section{
+			rowsSection("contacts.jsonl", []string{`{"name":"Ada"}`, `{"name":"Grace"}`}, nil),
+			rowsSection("wallets.jsonl", []string{`{"name":"Cash"}`}, nil),
+			rowsSection("tags.jsonl", nil, nil),
+		}, blobs, &progress)
+
+		rows, key, err := task(context.Background(), job)
+		require.NoError(t, err)
+		assert.Equal(t, 3, rows)
+		assert.True(t, strings.HasPrefix(key, "backups/") && strings.HasSuffix(key, ".zip"))
+		assert.NotContains(t, key, job.UserID.String(), "the key doesn't lead to the user's other backups")
+		assert.Equal(t, recordedProgress{{Processed: 2, Succeeded: 2}, {Processed: 3, Succeeded: 3}, {Processed: 3, Succeeded: 3}}, progress)
+
+		file, err := blobs.Open(context.Background(), key)
+		require.NoError(t, err)
+		defer file.Close()
+		var archived bytes.Buffer
+		_, err = io.Copy(&archived, file)
+		require.NoError(t, err)
+
+		names, files := readArchive(t, archived.Bytes())
+		assert.Equal(t, []string{"contacts.jsonl", "wallets.jsonl", "tags.jsonl", types.ManifestFile}, names)
+		assert.Equal(t, "{\"name\":\"Ada\"}\n{\"name\":\"Grace\"}\n", files["contacts.jsonl"])
+
+		var manifest types.Manifest
+		require.NoError(t, json.Unmarshal([]byte(files[types.ManifestFile]), &manifest))
+		assert.Equal(t, types.SchemaVersion, manifest.SchemaVersion)
+		assert.Equal(t, job.UserID, manifest.UserID)
+		assert.Equal(t, map[string]int{"contacts.jsonl": 2, "wallets.jsonl": 1, "tags.jsonl": 0}, manifest.Counts)
+	})
+
+	t.Run("a failing section fails the backup", func(t *testing.T) {
+		blobs, err := blob.NewFileStore(t.TempDir())
+		require.NoError(t, err)
+		var progress recordedProgress
+		task := BackupTask([]Section{
+			rowsSection("contacts.jsonl", []string{`{"name":"Ada"}`}, nil),
+			rowsSection("projects.jsonl", []string{`{"name":"Roof"}`}, fmt.Errorf("connection reset")),
+		}, blobs, &progress)
+
+		_, _, err = task(context.Background(), job)
+		assert.EqualError(t, err, "back up projects.jsonl: connection reset")
+		assert.Len(t, progress, 1)
+	})
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/backups/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/backups/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/blob"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/logging"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/jobs/worker"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ErrorsFile is the archive entry listing the sections that failed, only added when one did
+const ErrorsFile = "errors.txt"
+
+type BackupService interface {
+	WriteExportArchive(ctx context.Context, userID uuid.UUID, w io.Writer) error
+	StartBackup(ctx context.Context, userID uuid.UUID) (types.BackupJob, error)
+	GetBackup(ctx context.Context, userID, jobID uuid.UUID) (types.BackupJob, error)
+	OpenBackup(ctx context.Context, jobID uuid.UUID, download types.SignedDownload) (types.BackupJob, io.ReadCloser, error)
+}
+
+// BackupOptions are what the full account backups run on, the export archive doesn't need them
+type BackupOptions struct {
+	// Repo counts the rows a backup archives, the estimate its progress is reported against
+	Repo repository.Repository
+	// Jobs queues the backups and reads them back
+	Jobs worker.Queue
+	// Blobs keeps the archives until they expire
+	Blobs blob.Store
+	// Links signs the links downloading the archives
+	Links *Links
+	// Retention is how long a completed backup can be downloaded
+	Retention time.Duration
+}
+
+type backupService struct {
+	sections []Section
+	backups  BackupOptions
+	now      func() time.Time
+	logger   *zap.Logger
+}
+
+// NewBackupService returns the service archiving the user's data, one file per section,
+// and running the full account backups
+func NewBackupService(sections []Section, backups BackupOptions, logger *zap.Logger) BackupService {
+	return &backupService{
+		sections: sections,
+		backups:  backups,
+		now:      time.Now,
+		logger:   logger.With(zap.String("component", "backup_service")),
+	}
+}
+
+// operation starts the log of a backup service method
+func (s *backupService) operation(name string, userID uuid.UUID, fields ...zap.Field) *logging.Operation {
+	return logging.Start(logging.WithUser(s.logger, userID), "BackupService."+name, fields...)
+}
+
+// WriteExportArchive writes a zip archive of every section to w, each streamed as the
+// section's export produces it. A section failing midway keeps what it wrote and is named
+// in an errors.txt entry, the other sections are still archived. Only failures to write
+// to w, like a client going away, abort the archive.
+func (s *backupService) WriteExportArchive(ctx context.Context, userID uuid.UUID, w io.Writer) (err error) {
+	op := s.operation("WriteExportArchive", userID)
+	defer op.End(&err)
+
+	out := &trackedWriter{w: w}
+	archive := zip.NewWriter(out)
+	modified := s.now()
+	create := func(name string) (io.Writer, error) {
+		return archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	}
+
+	var failures []string
+	for _, section := range s.sections {
+		entry, err := create(section.Name)
+		if err != nil {
+			return err
+		}
+		written, err := section.Write(ctx, userID, entry)
+		if out.err != nil {
+			return out.err
+		}
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			// the cause stays in the logs, the archive only tells which file is incomplete
+			s.logger.Warn("archive section failed", zap.String("section", section.Name), zap.Int("written", written), zap.Error(err))
+			failures = append(failures, fmt.Sprintf("%s: incomplete, the export failed after %d rows", section.Name, written))
+		}
+		op.With(zap.Int(section.Name, written))
+		if err := archive.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(failures) > 0 {
+		op.With(zap.Strings("failed", failures))
+		entry, err := create(ErrorsFile)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(entry, strings.Join(failures, "\n")+"\n"); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+// trackedWriter keeps the first error writing to w, telling a broken destination from a
+// failing section
+type trackedWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (t *trackedWriter) Write(p []byte) (int, error) {
+	if t.err != nil {
+		return 0, t.err
+	}
+	n, err := t.w.Write(p)
+	t.err = err
+	return n, err
+}
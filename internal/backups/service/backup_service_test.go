@@ -0,0 +1,119 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// rowsSection writes rows as the lines of name, failing with err once they are all out
+func rowsSection(name string, rows []string, err error) Section {
+	return Section{
+		Name: name,
+		Write: func(ctx context.Context, userID uuid.UUID, w io.Writer) (int, error) {
+			for i, row := range rows {
+				if _, err := io.WriteString(w, row+"\n"); err != nil {
+					return i, err
+				}
+			}
+			return len(rows), err
+		},
+	}
+}
+
+// readArchive returns the files of a zip archive by name, in archive order
+func readArchive(t *testing.T, data []byte) ([]string, map[string]string) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	var names []string
+	files := make(map[string]string)
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		reader.Close()
+		names = append(names, file.Name)
+		files[file.Name] = string(content)
+	}
+	return names, files
+}
+
+func TestBackupService_WriteExportArchive(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("archives every section", func(t *testing.T) {
+		service := NewBackupService([]Section{
+			rowsSection("contacts.csv", []string{"name", "Ada"}, nil),
+			rowsSection("projects.csv", []string{"name"}, nil),
+			rowsSection("wallets.csv", []string{"name", "Cash", "Bank"}, nil),
+		}, BackupOptions{}, zap.NewNop())
+
+		var buf bytes.Buffer
+		require.NoError(t, service.WriteExportArchive(context.Background(), userID, &buf))
+
+		names, files := readArchive(t, buf.Bytes())
+		assert.Equal(t, []string{"contacts.csv", "projects.csv", "wallets.csv"}, names)
+		assert.Equal(t, "name\nAda\n", files["contacts.csv"])
+		assert.Equal(t, "name\n", files["projects.csv"])
+		assert.Equal(t, "name\nCash\nBank\n", files["wallets.csv"])
+	})
+
+	t.Run("a failing section is listed in errors.txt", func(t *testing.T) {
+		service := NewBackupService([]Section{
+			rowsSection("contacts.csv", []string{"name", "Ada"}, nil),
+			rowsSection("projects.csv", []string{"name", "Roof"}, fmt.Errorf("connection reset")),
+			rowsSection("wallets.csv", []string{"name", "Cash"}, nil),
+		}, BackupOptions{}, zap.NewNop())
+
+		var buf bytes.Buffer
+		require.NoError(t, service.WriteExportArchive(context.Background(), userID, &buf))
+
+		names, files := readArchive(t, buf.Bytes())
+		assert.Equal(t, []string{"contacts.csv", "projects.csv", "wallets.csv", ErrorsFile}, names)
+		assert.Equal(t, "name\nRoof\n", files["projects.csv"], "rows written before the failure are kept")
+		assert.Equal(t, "name\nCash\n", files["wallets.csv"], "sections after the failure are still archived")
+		assert.Equal(t, "projects.csv: incomplete, the export failed after 2 rows\n", files[ErrorsFile])
+		assert.NotContains(t, files[ErrorsFile], "connection reset")
+	})
+
+	t.Run("a canceled request aborts the archive", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		service := NewBackupService([]Section{
+			{
+				Name: "contacts.csv",
+				Write: func(ctx context.Context, userID uuid.UUID, w io.Writer) (int, error) {
+					cancel()
+					return 0, ctx.Err()
+				},
+			},
+			rowsSection("wallets.csv", []string{"name"}, nil),
+		}, BackupOptions{}, zap.NewNop())
+
+		err := service.WriteExportArchive(ctx, userID, io.Discard)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("a failing destination aborts the archive", func(t *testing.T) {
+		service := NewBackupService([]Section{
+			rowsSection("contacts.csv", []string{"name", "Ada"}, nil),
+		}, BackupOptions{}, zap.NewNop())
+
+		err := service.WriteExportArchive(context.Background(), userID, failingWriter{})
+		assert.EqualError(t, err, "broken pipe")
+	})
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, fmt.Errorf("broken pipe")
+}
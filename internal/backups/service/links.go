@@ -0,0 +1,68 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/backups/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/google/uuid"
+)
+
+// generatedKeyBytes is the size of the signing key generated when none is configured
+const generatedKeyBytes = 32
+
+// Links signs the links downloading backup archives, which work without a session until
+// they expire
+type Links struct {
+	key []byte
+	ttl time.Duration
+	now func() time.Time
+}
+
+// NewLinks returns the signer of download links valid for ttl. Without a key a random one
+// is generated, the links then stop working when the process restarts.
+func NewLinks(key string, ttl time.Duration) (*Links, error) {
+	secret := []byte(key)
+	if key == "" {
+		secret = make([]byte, generatedKeyBytes)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("generate backup link key: %w", err)
+		}
+	}
+	return &Links{key: secret, ttl: ttl, now: time.Now}, nil
+}
+
+// Sign returns the signed download of the user's backup job, valid for the links' TTL
+func (l *Links) Sign(jobID, userID uuid.UUID) types.SignedDownload {
+	expires := l.now().Add(l.ttl).Truncate(time.Second)
+	return types.SignedDownload{
+		UserID:    userID,
+		Expires:   expires,
+		Signature: l.signature(jobID, userID, expires),
+	}
+}
+
+// Verify checks that download was signed for the job and hasn't expired
+func (l *Links) Verify(jobID uuid.UUID, download types.SignedDownload) error {
+	expected := l.signature(jobID, download.UserID, download.Expires)
+	if !hmac.Equal([]byte(expected), []byte(download.Signature)) {
+		return errors.NewForbiddenError("the download link is invalid")
+	}
+	if !l.now().Before(download.Expires) {
+		return errors.NewForbiddenError("the download link expired, get a new one from GET /me/backup/%s", jobID)
+	}
+	return nil
+}
+
+// signature is the hex encoded HMAC-SHA256 of the job, its user and the expiry
+func (l *Links) signature(jobID, userID uuid.UUID, expires time.Time) string {
+	mac := hmac.New(sha256.New, l.key)
+	mac.Write([]byte(jobID.String() + "." + userID.String() + "." + strconv.FormatInt(expires.Unix(), 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
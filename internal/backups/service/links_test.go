@@ -0,0 +1,49 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinks(t *testing.T) {
+	jobID, userID := uuid.New(), uuid.New()
+	now := time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC)
+	links, err := NewLinks("secret", 15*time.Minute)
+	require.NoError(t, err)
+	links.now = func() time.Time { return now }
+
+	download := links.Sign(jobID, userID)
+	assert.Equal(t, userID, download.UserID)
+	assert.Equal(t, now.Add(15*time.Minute), download.Expires)
+	assert.NoError(t, links.Verify(jobID, download))
+
+	t.Run("tampered", func(t *testing.T) {
+		other := download
+		other.UserID = uuid.New()
+		assert.True(t, errors.IsErrorType(links.Verify(jobID, other), errors.ErrorTypeForbidden))
+
+		extended := download
+		extended.Expires = download.Expires.Add(time.Hour)
+		assert.True(t, errors.IsErrorType(links.Verify(jobID, extended), errors.ErrorTypeForbidden))
+
+		assert.True(t, errors.IsErrorType(links.Verify(uuid.New(), download), errors.ErrorTypeForbidden))
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		links.now = func() time.Time { return now.Add(15 * time.Minute) }
+		defer func() { links.now = func() time.Time { return now } }()
+		assert.True(t, errors.IsErrorType(links.Verify(jobID, download), errors.ErrorTypeForbidden))
+	})
+
+	t.Run("another key", func(t *testing.T) {
+		generated, err := NewLinks("", 15*time.Minute)
+		require.NoError(t, err)
+		assert.Len(t, generated.key, generatedKeyBytes)
+		assert.True(t, errors.IsErrorType(generated.Verify(jobID, download), errors.ErrorTypeForbidden))
+	})
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+
+	backupRepository "github.com/Abdelrahman-habib/expense-tracker/internal/backups/repository"
+	backupTypes "github.com/Abdelrahman-habib/expense-tracker/internal/backups/types"
+	contactRepository "github.com/Abdelrahman-habib/expense-tracker/internal/contacts/repository"
+	contactService "github.com/Abdelrahman-habib/expense-tracker/internal/contacts/service"
+	contactTypes "github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/paging"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	projectRepository "github.com/Abdelrahman-habib/expense-tracker/internal/projects/repository"
+	projectService "github.com/Abdelrahman-habib/expense-tracker/internal/projects/service"
+	projectTypes "github.com/Abdelrahman-habib/expense-tracker/internal/projects/types"
+	tagRepository "github.com/Abdelrahman-habib/expense-tracker/internal/tags/repository"
+	tagTypes "github.com/Abdelrahman-habib/expense-tracker/internal/tags/types"
+	walletRepository "github.com/Abdelrahman-habib/expense-tracker/internal/wallets/repository"
+	walletService "github.com/Abdelrahman-habib/expense-tracker/internal/wallets/service"
+	walletTypes "github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+	"github.com/google/uuid"
+)
+
+// backupBatchSize is the number of attachments and ledger entries read per query
+const backupBatchSize int32 = 500
+
+// Section is one file of an export archive, written by the entity's own streaming export
+type Section struct {
+	// Name is the name of the file in the archive
+	Name string
+	// Write writes the user's entities to w, returning how many it wrote
+	Write func(ctx context.Context, userID uuid.UUID, w io.Writer) (int, error)
+}
+
+// NewSections returns the sections of the export archive, one CSV per entity type in the
+// order they are archived
+func NewSections(q *db.Queries) []Section {
+	contacts := contactRepository.New(q)
+	projects := projectRepository.NewProjectRepository(q)
+	wallets := walletRepository.NewWalletRepository(q)
+
+	return []Section{
+		{
+			Name: "contacts.csv",
+			Write: func(ctx context.Context, userID uuid.UUID, w io.Writer) (int, error) {
+				return contactService.WriteContacts(ctx, contacts, userID, contactTypes.ExportFormatCSV, w)
+			},
+		},
+		{
+			Name: "projects.csv",
+			Write: func(ctx context.Context, userID uuid.UUID, w io.Writer) (int, error) {
+				return projectService.WriteProjects(ctx, projects, userID, projectTypes.ExportFormatCSV, w)
+			},
+		},
+		{
+			Name: "wallets.csv",
+			Write: func(ctx context.Context, userID uuid.UUID, w io.Writer) (int, error) {
+				return walletService.WriteWallets(ctx, wallets, userID, walletTypes.ExportFormatCSV, w)
+			},
+		},
+	}
+}
+
+// NewBackupSections returns the sections of a full account backup, one JSON-lines file
+// per entity type each holding the entities as the API returns them. Contact notes are
+// archived with their contacts and attachments without their contents.
+func NewBackupSections(q *db.Queries) []Section {
+	contacts := contactRepository.New(q)
+	projects := projectRepository.NewProjectRepository(q)
+	wallets := walletRepository.NewWalletRepository(q)
+	tags := tagRepository.NewTagRepository(q)
+	backups := backupRepository.New(q)
+
+	return []Section{
+		{
+			Name: "contacts.jsonl",
+			Write: func(ctx context.Context, userID uuid.UUID, w io.Writer) (int, error) {
+				return writeLines(w, func(fn func(contactTypes.Contact) error) (int, error) {
+					return contactService.StreamContacts(ctx, contacts, userID, fn)
+				})
+			},
+		},
+		{
+			Name: "projects.jsonl",
+			Write: func(ctx context.Context, userID uuid.UUID, w io.Writer) (int, error) {
+				return writeLines(w, func(fn func(projectTypes.Project) error) (int, error) {
+					return projectService.StreamProjects(ctx, projects, userID, fn)
+				})
+			},
+		},
+		{
+			Name: "wallets.jsonl",
+			Write: func(ctx context.Context, userID uuid.UUID, w io.Writer) (int, error) {
+				return writeLines(w, func(fn func(walletTypes.Wallet) error) (int, error) {
+					return walletService.StreamWallets(ctx, wallets, userID, fn)
+				})
+			},
+		},
+		{
+			Name: "tags.jsonl",
+			Write: func(ctx context.Context, userID uuid.UUID, w io.Writer) (int, error) {
+				return writeLines(w, func(fn func(tagTypes.Tag) error) (int, error) {
+					// users have a handful of tags, they are read at once
+					list, err := tags.ListTags(ctx, userID)
+					if err != nil {
+						return 0, err
+					}
+					for i, tag := range list {
+						if err := fn(tag); err != nil {
+							return i, err
+						}
+					}
+					return len(list), nil
+				})
+			},
+		},
+		{
+			Name: "attachments.jsonl",
+			Write: func(ctx context.Context, userID uuid.UUID, w io.Writer) (int, error) {
+				return writeLines(w, func(fn func(backupTypes.Attachment) error) (int, error) {
+					return streamPages(ctx, func(ctx context.Context, after uuid.UUID) ([]backupTypes.Attachment, uuid.UUID, error) {
+						attachments, err := backups.ListAttachments(ctx, userID, after, backupBatchSize)
+						return attachments, paging.Next(attachments, int(backupBatchSize), func(attachment backupTypes.Attachment) uuid.UUID {
+							return attachment.AttachmentID
+						}), err
+					}, fn)
+				})
+			},
+		},
+		{
+			Name: "ledger_entries.jsonl",
+			Write: func(ctx context.Context, userID uuid.UUID, w io.Writer) (int, error) {
+				return writeLines(w, func(fn func(backupTypes.LedgerEntry) error) (int, error) {
+					return streamPages(ctx, func(ctx context.Context, afterSeq int64) ([]backupTypes.LedgerEntry, int64, error) {
+						entries, err := backups.ListLedgerEntries(ctx, userID, afterSeq, backupBatchSize)
+						return entries, paging.Next(entries, int(backupBatchSize), func(entry backupTypes.LedgerEntry) int64 {
+							return entry.Seq
+						}), err
+					}, fn)
+				})
+			},
+		},
+	}
+}
+
+// writeLines writes each item stream hands over to w as a line of JSON, returning how
+// many it wrote
+func writeLines[T any](w io.Writer, stream func(fn func(T) error) (int, error)) (int, error) {
+	buffered := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buffered)
+	written, err := stream(func(item T) error {
+		return encoder.Encode(item)
+	})
+	if err != nil {
+		return written, err
+	}
+	return written, buffered.Flush()
+}
+
+// streamPages hands every item fetch returns to fn, returning how many it handed over
+func streamPages[T any, C comparable](ctx context.Context, fetch paging.Fetch[T, C], fn func(T) error) (int, error) {
+	var streamed int
+	err := paging.ForEachPage(ctx, fetch, func(item T) error {
+		if err := fn(item); err != nil {
+			return err
+		}
+		streamed++
+		return nil
+	})
+	return streamed, err
+}
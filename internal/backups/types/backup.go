@@ -0,0 +1,110 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	jobTypes "github.com/Abdelrahman-habib/expense-tracker/internal/jobs/types"
+	"github.com/google/uuid"
+)
+
+// SchemaVersion is the version of the layout of backup archives, raised whenever a file
+// of the archive changes in a way readers of older archives have to know about
+const SchemaVersion = 1
+
+// ManifestFile is the archive entry describing the backup, written after every section
+const ManifestFile = "manifest.json"
+
+// DownloadPath is the public route serving backup archives, the signed query of the link
+// authorizes the download instead of a session
+const DownloadPath = "/backups/{id}/download"
+
+// Query parameters of a signed download link
+const (
+	UserParam      = "user"
+	ExpiresParam   = "expires"
+	SignatureParam = "signature"
+)
+
+// Manifest describes a backup archive, counting the rows of each file
+// @Description Manifest of a backup archive
+type Manifest struct {
+	SchemaVersion int                 `json:"schemaVersion" example:"1"`
+	UserID        uuid.UUID           `json:"userId" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
+	CreatedAt     coreTypes.Timestamp `json:"createdAt" example:"2024-01-01T00:00:00.000Z" swaggertype:"string" format:"date-time"`
+	// Counts is the number of rows of each file, by file name
+	Counts map[string]int `json:"counts"`
+}
+
+// BackupJob is a full account backup, the download link is set once the archive is ready
+// @Description Full account backup, with a short-lived link to download the archive once it is completed
+type BackupJob struct {
+	jobTypes.Job
+	// ExpiresAt is when the archive of a completed backup is deleted
+	ExpiresAt *coreTypes.Timestamp `json:"expiresAt,omitempty" example:"2024-01-02T00:00:00.000Z" swaggertype:"string" format:"date-time"`
+	// DownloadURL downloads the archive without a session until DownloadExpiresAt
+	DownloadURL       string               `json:"downloadUrl,omitempty" example:"/backups/123e4567-e89b-12d3-a456-426614174000/download?expires=1704070800&signature=9f86d0&user=123e4567-e89b-12d3-a456-426614174001"`
+	DownloadExpiresAt *coreTypes.Timestamp `json:"downloadExpiresAt,omitempty" example:"2024-01-01T00:15:00.000Z" swaggertype:"string" format:"date-time"`
+}
+
+// SignedDownload is the signed query of a download link
+type SignedDownload struct {
+	UserID    uuid.UUID
+	Expires   time.Time
+	Signature string
+}
+
+// Attachment describes an attachment of a pending entry, its content isn't archived
+type Attachment struct {
+	AttachmentID uuid.UUID `json:"attachmentId"`
+	EntryID      uuid.UUID `json:"entryId"`
+	Filename     string    `json:"filename"`
+	ContentType  string    `json:"contentType"`
+	Size         int64     `json:"size"`
+}
+
+// LedgerEntry is a change of the balance of one of the user's wallets
+type LedgerEntry struct {
+	EntryID     uuid.UUID           `json:"entryId"`
+	WalletID    uuid.UUID           `json:"walletId"`
+	Seq         int64               `json:"seq"`
+	Amount      float64             `json:"amount"`
+	Description string              `json:"description"`
+	OccurredAt  coreTypes.Timestamp `json:"occurredAt"`
+}
+
+// DownloadURL returns the link downloading the archive of the backup job with download
+func DownloadURL(jobID uuid.UUID, download SignedDownload) string {
+	return strings.Replace(DownloadPath, "{id}", jobID.String(), 1) + "?" + download.Query()
+}
+
+// Query returns the query string of the download link
+func (d SignedDownload) Query() string {
+	return url.Values{
+		UserParam:      {d.UserID.String()},
+		ExpiresParam:   {strconv.FormatInt(d.Expires.Unix(), 10)},
+		SignatureParam: {d.Signature},
+	}.Encode()
+}
+
+// ParseSignedDownload parses the signed query of a download link, the signature is checked
+// by the service
+func ParseSignedDownload(query url.Values) (SignedDownload, error) {
+	userID, err := uuid.Parse(query.Get(UserParam))
+	if err != nil {
+		return SignedDownload{}, fmt.Errorf("%s: must be a valid UUID", UserParam)
+	}
+	expires, err := strconv.ParseInt(query.Get(ExpiresParam), 10, 64)
+	if err != nil {
+		return SignedDownload{}, fmt.Errorf("%s: must be a unix timestamp", ExpiresParam)
+	}
+	signature := query.Get(SignatureParam)
+	if signature == "" {
+		return SignedDownload{}, fmt.Errorf("%s: cannot be blank", SignatureParam)
+	}
+	return SignedDownload{UserID: userID, Expires: time.Unix(expires, 0), Signature: signature}, nil
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/budgets/types"
+	coreErrors "github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/repository"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// Mock service
+type mockBudgetService struct {
+	mock.Mock
+}
+
+func (m *mockBudgetService) ListBudgets(ctx context.Context, userID uuid.UUID) ([]types.Budget, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]types.Budget), args.Error(1)
+}
+
+func (m *mockBudgetService) GetBudget(ctx context.Context, userID, budgetID uuid.UUID) (types.Budget, error) {
+	args := m.Called(ctx, userID, budgetID)
+	return args.Get(0).(types.Budget), args.Error(1)
+}
+
+func (m *mockBudgetService) CreateBudget(ctx context.Context, userID uuid.UUID, payload types.BudgetCreatePayload) (types.Budget, error) {
+	args := m.Called(ctx, userID, payload)
+	return args.Get(0).(types.Budget), args.Error(1)
+}
+
+func (m *mockBudgetService) UpdateBudget(ctx context.Context, userID uuid.UUID, payload types.BudgetUpdatePayload) (types.Budget, error) {
+	args := m.Called(ctx, userID, payload)
+	return args.Get(0).(types.Budget), args.Error(1)
+}
+
+func (m *mockBudgetService) DeleteBudget(ctx context.Context, userID, budgetID uuid.UUID) error {
+	return m.Called(ctx, userID, budgetID).Error(0)
+}
+
+func (m *mockBudgetService) GetBudgetStatus(ctx context.Context, userID, budgetID uuid.UUID) (types.BudgetStatus, error) {
+	args := m.Called(ctx, userID, budgetID)
+	return args.Get(0).(types.BudgetStatus), args.Error(1)
+}
+
+func setupTest(t *testing.T) (*mockBudgetService, *BudgetHandler) {
+	mockService := new(mockBudgetService)
+	handler := NewBudgetHandler(mockService, zap.NewNop())
+	return mockService, handler
+}
+
+// newRequest builds a request carrying the user and the budget ID route parameter
+func newRequest(method, target, body string, userID uuid.UUID, budgetID string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
+	if budgetID != "" {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", budgetID)
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+	}
+	return req.WithContext(ctx)
+}
+
+func TestBudgetHandler_CreateBudget(t *testing.T) {
+	userID := uuid.New()
+	scopeID := uuid.New()
+	valid := func(overrides string) string {
+		return `{"scopeType":"project","scopeId":"` + scopeID.String() + `","amount":500,"currency":"USD","period":"monthly","startDate":"2024-01-31"` + overrides + `}`
+	}
+
+	tests := []struct {
+		name           string
+		payload        string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{name: "successful creation", payload: valid(""), expectedStatus: http.StatusCreated},
+		{name: "tag scope", payload: valid(`,"scopeType":"tag"`), expectedStatus: http.StatusCreated},
+		{name: "overlapping budget", payload: valid(""), serviceErr: coreErrors.NewValidationError("period: overlaps"), expectedStatus: http.StatusBadRequest},
+		{name: "unknown scope type", payload: valid(`,"scopeType":"wallet"`), expectedStatus: http.StatusBadRequest},
+		{name: "missing scope", payload: `{"scopeType":"project","amount":500,"currency":"USD","period":"monthly","startDate":"2024-01-31"}`, expectedStatus: http.StatusBadRequest},
+		{name: "zero amount", payload: valid(`,"amount":0`), expectedStatus: http.StatusBadRequest},
+		{name: "negative amount", payload: valid(`,"amount":-5`), expectedStatus: http.StatusBadRequest},
+		{name: "too many decimals for the currency", payload: valid(`,"amount":10.555`), expectedStatus: http.StatusBadRequest},
+		{name: "unknown currency", payload: valid(`,"currency":"ABC"`), expectedStatus: http.StatusBadRequest},
+		{name: "unknown period", payload: valid(`,"period":"weekly"`), expectedStatus: http.StatusBadRequest},
+		{name: "start date with a time", payload: valid(`,"startDate":"2024-01-31T00:00:00Z"`), expectedStatus: http.StatusBadRequest},
+		{name: "impossible start date", payload: valid(`,"startDate":"2023-02-29"`), expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService, handler := setupTest(t)
+			if tt.expectedStatus == http.StatusCreated || tt.serviceErr != nil {
+				mockService.On("CreateBudget", mock.Anything, userID, mock.AnythingOfType("types.BudgetCreatePayload")).
+					Return(types.Budget{BudgetID: uuid.New()}, tt.serviceErr)
+			}
+
+			w := httptest.NewRecorder()
+			handler.CreateBudget(w, newRequest(http.MethodPost, "/budgets", tt.payload, userID, ""))
+
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBudgetHandler_UpdateBudget_KeepsOmittedFields(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	existing := types.Budget{
+		BudgetID:  uuid.New(),
+		ScopeType: types.ScopeTag,
+		ScopeID:   uuid.New(),
+		Amount:    100,
+		Currency:  "EUR",
+		Period:    types.PeriodQuarterly,
+		StartDate: "2024-01-01",
+	}
+	want := existing.ToUpdatePayload()
+	want.Amount = 250
+
+	mockService.On("GetBudget", mock.Anything, userID, existing.BudgetID).Return(existing, nil)
+	mockService.On("UpdateBudget", mock.Anything, userID, want).Return(existing, nil)
+
+	w := httptest.NewRecorder()
+	handler.UpdateBudget(w, newRequest(http.MethodPut, "/budgets/"+existing.BudgetID.String(), `{"amount":250}`, userID, existing.BudgetID.String()))
+
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestBudgetHandler_GetBudgetStatus(t *testing.T) {
+	userID := uuid.New()
+	budgetID := uuid.New()
+
+	t.Run("current period", func(t *testing.T) {
+		mockService, handler := setupTest(t)
+		mockService.On("GetBudgetStatus", mock.Anything, userID, budgetID).Return(types.BudgetStatus{
+			BudgetID:    budgetID,
+			Period:      types.PeriodMonthly,
+			WindowStart: "2024-02-29",
+			WindowEnd:   "2024-03-31",
+			Amount:      400,
+			Currency:    "USD",
+			Spent:       500,
+			Remaining:   -100,
+			Percentage:  125,
+		}, nil)
+
+		w := httptest.NewRecorder()
+		handler.GetBudgetStatus(w, newRequest(http.MethodGet, "/budgets/"+budgetID.String()+"/status", "", userID, budgetID.String()))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		data := response["data"].(map[string]interface{})
+		assert.Equal(t, "2024-02-29", data["windowStart"])
+		assert.Equal(t, "2024-03-31", data["windowEnd"])
+		assert.Equal(t, float64(-100), data["remaining"])
+		assert.Equal(t, float64(125), data["percentage"])
+	})
+
+	t.Run("missing budget", func(t *testing.T) {
+		mockService, handler := setupTest(t)
+		mockService.On("GetBudgetStatus", mock.Anything, userID, budgetID).Return(types.BudgetStatus{}, repository.ErrNotFound)
+
+		w := httptest.NewRecorder()
+		handler.GetBudgetStatus(w, newRequest(http.MethodGet, "/budgets/"+budgetID.String()+"/status", "", userID, budgetID.String()))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		_, handler := setupTest(t)
+
+		w := httptest.NewRecorder()
+		handler.GetBudgetStatus(w, newRequest(http.MethodGet, "/budgets/nope/status", "", userID, "nope"))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestBudgetHandler_DeleteBudget(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	budgetID := uuid.New()
+	mockService.On("DeleteBudget", mock.Anything, userID, budgetID).Return(nil).Once()
+	mockService.On("DeleteBudget", mock.Anything, userID, budgetID).Return(repository.ErrNotFound).Once()
+
+	w := httptest.NewRecorder()
+	handler.DeleteBudget(w, newRequest(http.MethodDelete, "/budgets/"+budgetID.String(), "", userID, budgetID.String()))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.DeleteBudget(w, newRequest(http.MethodDelete, "/budgets/"+budgetID.String(), "", userID, budgetID.String()))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
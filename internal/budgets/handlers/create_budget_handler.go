@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/budgets/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+)
+
+// CreateBudget godoc
+// @Summary Create a budget
+// @Description Creates a budget of a project or a tag, a scope has at most one budget per period
+// @Tags Budgets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body types.BudgetCreatePayload true "Budget creation request"
+// @Success 201 {object} payloads.Response{data=types.Budget}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401  {object} errors.ErrorResponse
+// @Failure 409  {object} errors.ErrorResponse
+// @Failure 429  {object} errors.ErrorResponse
+// @Failure 500  {object} errors.ErrorResponse
+// @Router /budgets [post]
+// @ID CreateBudget
+func (h *BudgetHandler) CreateBudget(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	var req types.BudgetCreatePayload
+	if !h.Bind(w, r, &req) {
+		return
+	}
+
+	budget, err := h.service.CreateBudget(r.Context(), userID, req)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.Created(budget))
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// DeleteBudget godoc
+// @Summary Delete a budget
+// @Description Deletes a budget, the wallets and ledgers it counted are left alone
+// @Tags Budgets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Budget ID" format(uuid)
+// @Success 200 {object} payloads.Response
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401  {object} errors.ErrorResponse
+// @Failure 404  {object} errors.ErrorResponse
+// @Failure 429  {object} errors.ErrorResponse
+// @Failure 500  {object} errors.ErrorResponse
+// @Router /budgets/{id} [delete]
+// @ID DeleteBudget
+func (h *BudgetHandler) DeleteBudget(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	budgetID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	if err := h.service.DeleteBudget(r.Context(), userID, budgetID); err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.Deleted())
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// GetBudget godoc
+// @Summary Get a budget
+// @Description Retrieves a budget by ID
+// @Tags Budgets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Budget ID" format(uuid)
+// @Success 200 {object} payloads.Response{data=types.Budget}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401  {object} errors.ErrorResponse
+// @Failure 404  {object} errors.ErrorResponse
+// @Failure 429  {object} errors.ErrorResponse
+// @Failure 500  {object} errors.ErrorResponse
+// @Router /budgets/{id} [get]
+// @ID GetBudget
+func (h *BudgetHandler) GetBudget(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	budgetID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	budget, err := h.service.GetBudget(r.Context(), userID, budgetID)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.OK(budget))
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// GetBudgetStatus godoc
+// @Summary Get the status of a budget
+// @Description Returns the window of the budget's current period with what was spent in it so far, the remaining amount and the percentage spent.
+// @Description Spending is what left the ledgers of the user's wallets in the budget's currency that belong to its project or carry its tag, trashed wallets don't count.
+// @Description Periods are counted in UTC days from the start date, a period starting on the 31st starts on the last day of shorter months.
+// @Tags Budgets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Budget ID" format(uuid)
+// @Success 200 {object} payloads.Response{data=types.BudgetStatus}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401  {object} errors.ErrorResponse
+// @Failure 404  {object} errors.ErrorResponse
+// @Failure 429  {object} errors.ErrorResponse
+// @Failure 500  {object} errors.ErrorResponse
+// @Router /budgets/{id}/status [get]
+// @ID GetBudgetStatus
+func (h *BudgetHandler) GetBudgetStatus(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	budgetID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	status, err := h.service.GetBudgetStatus(r.Context(), userID, budgetID)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.OK(status))
+}
@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"github.com/Abdelrahman-habib/expense-tracker/internal/budgets/service"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
+	"go.uber.org/zap"
+)
+
+type BudgetHandler struct {
+	handlers.BaseHandler
+	service service.BudgetService
+}
+
+func NewBudgetHandler(service service.BudgetService, logger *zap.Logger) *BudgetHandler {
+	return &BudgetHandler{
+		BaseHandler: handlers.NewBaseHandler(logger),
+		service:     service,
+	}
+}
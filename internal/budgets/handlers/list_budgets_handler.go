@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+)
+
+// ListBudgets godoc
+// @Summary List budgets
+// @Description Lists the user's budgets, oldest first
+// @Tags Budgets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} payloads.Response{data=[]types.Budget}
+// @Failure 401  {object} errors.ErrorResponse
+// @Failure 429  {object} errors.ErrorResponse
+// @Failure 500  {object} errors.ErrorResponse
+// @Router /budgets [get]
+// @ID ListBudgets
+func (h *BudgetHandler) ListBudgets(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	if !h.CheckQueryParams(w, r) {
+		return
+	}
+
+	budgets, err := h.service.ListBudgets(r.Context(), userID)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.List(budgets, len(budgets)))
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// UpdateBudget godoc
+// @Summary Update a budget
+// @Description Updates a budget, the fields left out keep their value
+// @Tags Budgets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Budget ID" format(uuid)
+// @Param request body types.BudgetUpdatePayload true "Budget update request"
+// @Success 200 {object} payloads.Response{data=types.Budget}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401  {object} errors.ErrorResponse
+// @Failure 404  {object} errors.ErrorResponse
+// @Failure 409  {object} errors.ErrorResponse
+// @Failure 429  {object} errors.ErrorResponse
+// @Failure 500  {object} errors.ErrorResponse
+// @Router /budgets/{id} [put]
+// @ID UpdateBudget
+func (h *BudgetHandler) UpdateBudget(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	budgetID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	// Get existing budget first
+	existingBudget, err := h.service.GetBudget(r.Context(), userID, budgetID)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	// Create update payload from existing budget
+	updatePayload := existingBudget.ToUpdatePayload()
+
+	// Decode and validate onto the current values
+	if !h.BindUpdate(w, r, &updatePayload) {
+		return
+	}
+
+	budget, err := h.service.UpdateBudget(r.Context(), userID, updatePayload)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.Updated(budget))
+}
@@ -0,0 +1,425 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/config"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/budgets/handlers"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/budgets/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/budgets/service"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/budgets/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"go.uber.org/zap"
+)
+
+type BudgetIntegrationTestSuite struct {
+	suite.Suite
+	container   testcontainers.Container
+	service     db.Service
+	pool        *pgxpool.Pool
+	repo        repository.BudgetRepository
+	router      *chi.Mux
+	userID      uuid.UUID
+	otherUserID uuid.UUID
+	ctx         context.Context
+}
+
+func TestBudgetIntegrationSuite(t *testing.T) {
+	suite.Run(t, new(BudgetIntegrationTestSuite))
+}
+
+func (s *BudgetIntegrationTestSuite) SetupSuite() {
+	s.ctx = context.Background()
+	s.userID = uuid.New()
+	s.otherUserID = uuid.New()
+
+	var host, port string
+
+	if os.Getenv("CI") == "true" {
+		// Running in GitHub Actions, use service-based PostgreSQL
+		host = "localhost"
+		port = "5432"
+	} else {
+		// Running locally, use TestContainers
+		req := testcontainers.ContainerRequest{
+			Image:        "postgres:15-alpine",
+			ExposedPorts: []string{"5432/tcp"},
+			WaitingFor:   wait.ForListeningPort("5432/tcp"),
+			Env: map[string]string{
+				"POSTGRES_DB":       "testdb",
+				"POSTGRES_USER":     "test",
+				"POSTGRES_PASSWORD": "test",
+			},
+			NetworkMode: "bridge",
+		}
+
+		container, err := testcontainers.GenericContainer(s.ctx, testcontainers.GenericContainerRequest{
+			ContainerRequest: req,
+			Started:          true,
+		})
+		require.NoError(s.T(), err)
+		s.container = container
+
+		host, err = container.Host(s.ctx)
+		require.NoError(s.T(), err)
+		mappedPort, err := container.MappedPort(s.ctx, "5432")
+		require.NoError(s.T(), err)
+		port = mappedPort.Port()
+	}
+
+	cfg := config.DatabaseConfig{
+		Host:        host,
+		Port:        port,
+		Username:    "test",
+		Password:    "test",
+		Database:    "testdb",
+		Schema:      "public",
+		MaxConns:    5,
+		MinConns:    1,
+		MaxLifetime: time.Hour,
+		MaxIdleTime: time.Minute * 30,
+		HealthCheck: time.Minute,
+		SSLMode:     "disable",
+		SearchPath:  "public",
+	}
+
+	dbService := db.NewService(cfg)
+	s.service = dbService
+
+	pool, err := pgxpool.New(s.ctx, cfg.GetDSN())
+	require.NoError(s.T(), err)
+	s.pool = pool
+
+	require.NoError(s.T(), s.runMigrations())
+
+	// Create test users
+	_, err = s.pool.Exec(s.ctx, `
+		INSERT INTO users (user_id, external_id, name, email)
+		VALUES ($1, 'bgit_test_clerk_id', 'bgit_Test User', 'bgit_test@example.com'),
+		       ($2, 'bgit_other_clerk_id', 'bgit_Other User', 'bgit_other@example.com')
+	`, s.userID, s.otherUserID)
+	require.NoError(s.T(), err)
+
+	logger := zap.NewNop()
+	s.repo = repository.NewBudgetRepository(dbService.Queries())
+	handler := handlers.NewBudgetHandler(service.NewBudgetService(s.repo, logger), logger)
+
+	router := chi.NewRouter()
+	router.Route("/budgets", func(r chi.Router) {
+		r.Get("/", handler.ListBudgets)
+		r.Post("/", handler.CreateBudget)
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", handler.GetBudget)
+			r.Put("/", handler.UpdateBudget)
+			r.Delete("/", handler.DeleteBudget)
+			r.Get("/status", handler.GetBudgetStatus)
+		})
+	})
+	s.router = router
+}
+
+func (s *BudgetIntegrationTestSuite) TearDownSuite() {
+	if s.pool != nil {
+		_, _ = s.pool.Exec(s.ctx, "DELETE FROM users WHERE user_id IN ($1, $2)", s.userID, s.otherUserID)
+		s.pool.Close()
+	}
+	if s.service != nil {
+		s.service.Close()
+	}
+	if s.container != nil && os.Getenv("CI") != "true" {
+		err := s.container.Terminate(s.ctx)
+		require.NoError(s.T(), err)
+	}
+}
+
+func (s *BudgetIntegrationTestSuite) SetupTest() {
+	for _, table := range []string{"budgets", "wallets", "projects", "tags"} {
+		_, err := s.pool.Exec(s.ctx, "DELETE FROM "+table+" WHERE user_id IN ($1, $2)", s.userID, s.otherUserID)
+		s.Require().NoError(err)
+	}
+}
+
+func (s *BudgetIntegrationTestSuite) runMigrations() error {
+	migrationsDir := "../../db/sql/migrations"
+
+	sqlDB := stdlib.OpenDBFromPool(s.pool)
+	defer sqlDB.Close()
+
+	if err := goose.SetDialect("postgres"); err != nil {
+		return err
+	}
+	return goose.Up(sqlDB, migrationsDir)
+}
+
+// do sends an authenticated request as the user and decodes the response
+func (s *BudgetIntegrationTestSuite) do(userID uuid.UUID, method, path string, body interface{}) (int, map[string]interface{}) {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		s.Require().NoError(err)
+		reader = bytes.NewReader(payload)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+
+	var response map[string]interface{}
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+	return w.Code, response
+}
+
+func (s *BudgetIntegrationTestSuite) createProject(userID uuid.UUID, name string) uuid.UUID {
+	var projectID uuid.UUID
+	err := s.pool.QueryRow(s.ctx, `
+		INSERT INTO projects (user_id, name, status) VALUES ($1, $2, 'ongoing') RETURNING project_id
+	`, userID, name).Scan(&projectID)
+	s.Require().NoError(err)
+	return projectID
+}
+
+func (s *BudgetIntegrationTestSuite) createTag(userID uuid.UUID, name string) uuid.UUID {
+	var tagID uuid.UUID
+	err := s.pool.QueryRow(s.ctx, `
+		INSERT INTO tags (user_id, name) VALUES ($1, $2) RETURNING tag_id
+	`, userID, name).Scan(&tagID)
+	s.Require().NoError(err)
+	return tagID
+}
+
+// createWallet adds a wallet without a balance, so without an opening ledger entry
+func (s *BudgetIntegrationTestSuite) createWallet(name, currency string, projectID *uuid.UUID, tags []uuid.UUID) uuid.UUID {
+	var walletID uuid.UUID
+	err := s.pool.QueryRow(s.ctx, `
+		INSERT INTO wallets (user_id, project_id, name, currency, tags) VALUES ($1, $2, $3, $4, $5) RETURNING wallet_id
+	`, s.userID, projectID, name, currency, tags).Scan(&walletID)
+	s.Require().NoError(err)
+	return walletID
+}
+
+// record adds a ledger entry to the wallet, negative amounts are spending
+func (s *BudgetIntegrationTestSuite) record(walletID uuid.UUID, amount float64, occurredAt string) {
+	_, err := s.pool.Exec(s.ctx, `
+		INSERT INTO wallet_ledger_entries (wallet_id, amount, description, occurred_at) VALUES ($1, $2, 'test', $3::text::timestamp)
+	`, walletID, amount, occurredAt)
+	s.Require().NoError(err)
+}
+
+func (s *BudgetIntegrationTestSuite) createBudget(scopeType string, scopeID uuid.UUID, amount float64, currency, period, startDate string) types.Budget {
+	budget, err := s.repo.CreateBudget(s.ctx, s.userID, types.BudgetCreatePayload{
+		ScopeType: scopeType,
+		ScopeID:   scopeID,
+		Amount:    amount,
+		Currency:  currency,
+		Period:    period,
+		StartDate: startDate,
+	})
+	s.Require().NoError(err)
+	return budget
+}
+
+func (s *BudgetIntegrationTestSuite) spend(budgetID uuid.UUID, windowStart, windowEnd string) types.BudgetStatus {
+	start, err := time.Parse(types.DateLayout, windowStart)
+	s.Require().NoError(err)
+	end, err := time.Parse(types.DateLayout, windowEnd)
+	s.Require().NoError(err)
+
+	status, err := s.repo.GetBudgetSpend(s.ctx, s.userID, budgetID, start, end)
+	s.Require().NoError(err)
+	return status
+}
+
+func (s *BudgetIntegrationTestSuite) TestSpendOfProjectBudget() {
+	kitchen := s.createProject(s.userID, "Kitchen")
+	garden := s.createProject(s.userID, "Garden")
+	cash := s.createWallet("Cash", "USD", &kitchen, nil)
+	card := s.createWallet("Card", "USD", &kitchen, nil)
+	euros := s.createWallet("Euros", "EUR", &kitchen, nil)
+	outside := s.createWallet("Garden cash", "USD", &garden, nil)
+	trashed := s.createWallet("Old cash", "USD", &kitchen, nil)
+	_, err := s.pool.Exec(s.ctx, "UPDATE wallets SET deleted_at = CURRENT_TIMESTAMP WHERE wallet_id = $1", trashed)
+	s.Require().NoError(err)
+
+	budget := s.createBudget(types.ScopeProject, kitchen, 400, "USD", types.PeriodMonthly, "2024-01-31")
+
+	s.record(cash, -50.25, "2024-02-29 00:00:00")  // first moment of the window
+	s.record(card, -49.75, "2024-03-30 23:59:59")  // last moment of the window
+	s.record(cash, 1000, "2024-03-01 12:00:00")    // money coming in isn't spending
+	s.record(cash, -70, "2024-02-28 23:59:59")     // the previous period
+	s.record(card, -80, "2024-03-31 00:00:00")     // the next period
+	s.record(euros, -60, "2024-03-10 12:00:00")    // another currency
+	s.record(outside, -90, "2024-03-10 12:00:00")  // another project
+	s.record(trashed, -100, "2024-03-10 12:00:00") // trashed wallets don't count
+
+	status := s.spend(budget.BudgetID, "2024-02-29", "2024-03-31")
+	s.Equal(100.0, status.Spent)
+	s.Equal(300.0, status.Remaining)
+	s.Equal(25.0, status.Percentage)
+
+	// the previous period only has its own spending
+	status = s.spend(budget.BudgetID, "2024-01-31", "2024-02-29")
+	s.Equal(70.0, status.Spent)
+}
+
+func (s *BudgetIntegrationTestSuite) TestSpendOfTagBudget() {
+	groceries := s.createTag(s.userID, "Groceries")
+	travel := s.createTag(s.userID, "Travel")
+	project := s.createProject(s.userID, "Kitchen")
+	tagged := s.createWallet("Cash", "USD", nil, []uuid.UUID{travel, groceries})
+	taggedInProject := s.createWallet("Card", "USD", &project, []uuid.UUID{groceries})
+	untagged := s.createWallet("Savings", "USD", &project, nil)
+	otherTag := s.createWallet("Trip", "USD", nil, []uuid.UUID{travel})
+
+	budget := s.createBudget(types.ScopeTag, groceries, 150, "USD", types.PeriodQuarterly, "2024-11-30")
+
+	s.record(tagged, -100, "2024-12-15 10:00:00")
+	s.record(taggedInProject, -80.5, "2025-01-20 10:00:00")
+	s.record(untagged, -40, "2025-01-20 10:00:00")
+	s.record(otherTag, -30, "2025-01-20 10:00:00")
+	s.record(tagged, -20, "2025-02-28 00:00:00") // the next quarter
+
+	// the quarter crosses the year boundary and overspends the budget
+	status := s.spend(budget.BudgetID, "2024-11-30", "2025-02-28")
+	s.Equal(180.5, status.Spent)
+	s.Equal(-30.5, status.Remaining)
+	s.Equal(120.33, status.Percentage)
+}
+
+func (s *BudgetIntegrationTestSuite) TestSpendOfAnotherUsersBudget() {
+	project := s.createProject(s.userID, "Kitchen")
+	budget := s.createBudget(types.ScopeProject, project, 100, "USD", types.PeriodMonthly, "2024-01-01")
+
+	_, err := s.repo.GetBudgetSpend(s.ctx, s.otherUserID, budget.BudgetID, time.Now(), time.Now())
+	s.Error(err)
+}
+
+func (s *BudgetIntegrationTestSuite) TestStatusOfTheCurrentPeriod() {
+	project := s.createProject(s.userID, "Kitchen")
+	wallet := s.createWallet("Cash", "USD", &project, nil)
+
+	code, response := s.do(s.userID, http.MethodPost, "/budgets", map[string]interface{}{
+		"scopeType": "project", "scopeId": project, "amount": 200, "currency": "USD", "period": "monthly", "startDate": "2020-01-31",
+	})
+	s.Require().Equal(http.StatusCreated, code, response)
+	budgetID := response["data"].(map[string]interface{})["budgetId"].(string)
+
+	code, response = s.do(s.userID, http.MethodGet, "/budgets/"+budgetID+"/status", nil)
+	s.Require().Equal(http.StatusOK, code, response)
+	window := response["data"].(map[string]interface{})
+	s.Equal(0.0, window["spent"])
+
+	// spending at the edges of the window the server picked
+	s.record(wallet, -50, window["windowStart"].(string)+" 00:00:00")
+	s.record(wallet, -25, window["windowEnd"].(string)+" 00:00:00")
+
+	code, response = s.do(s.userID, http.MethodGet, "/budgets/"+budgetID+"/status", nil)
+	s.Require().Equal(http.StatusOK, code, response)
+	data := response["data"].(map[string]interface{})
+	s.Equal(50.0, data["spent"])
+	s.Equal(150.0, data["remaining"])
+	s.Equal(25.0, data["percentage"])
+	s.Equal("monthly", data["period"])
+}
+
+func (s *BudgetIntegrationTestSuite) TestOverlappingBudgetsRejected() {
+	project := s.createProject(s.userID, "Kitchen")
+	tag := s.createTag(s.userID, "Groceries")
+	payload := map[string]interface{}{
+		"scopeType": "project", "scopeId": project, "amount": 200, "currency": "USD", "period": "monthly", "startDate": "2024-01-01",
+	}
+	code, response := s.do(s.userID, http.MethodPost, "/budgets", payload)
+	s.Require().Equal(http.StatusCreated, code, response)
+
+	// a later start still overlaps, periods recur without end
+	payload["startDate"] = "2030-06-15"
+	code, _ = s.do(s.userID, http.MethodPost, "/budgets", payload)
+	s.Equal(http.StatusBadRequest, code)
+
+	// another period or scope doesn't
+	payload["period"] = "yearly"
+	code, response = s.do(s.userID, http.MethodPost, "/budgets", payload)
+	s.Require().Equal(http.StatusCreated, code, response)
+	yearly := response["data"].(map[string]interface{})["budgetId"].(string)
+	code, _ = s.do(s.userID, http.MethodPost, "/budgets", map[string]interface{}{
+		"scopeType": "tag", "scopeId": tag, "amount": 50, "currency": "USD", "period": "monthly", "startDate": "2024-01-01",
+	})
+	s.Equal(http.StatusCreated, code)
+
+	// moving a budget onto the period of another budget of its scope overlaps too
+	code, _ = s.do(s.userID, http.MethodPut, "/budgets/"+yearly, map[string]interface{}{"period": "monthly"})
+	s.Equal(http.StatusBadRequest, code)
+	// but a budget doesn't overlap itself
+	code, response = s.do(s.userID, http.MethodPut, "/budgets/"+yearly, map[string]interface{}{"amount": 2500})
+	s.Require().Equal(http.StatusOK, code, response)
+	s.Equal(2500.0, response["data"].(map[string]interface{})["amount"])
+}
+
+func (s *BudgetIntegrationTestSuite) TestForeignScopeRejected() {
+	theirs := s.createProject(s.otherUserID, "Theirs")
+	theirTag := s.createTag(s.otherUserID, "Theirs")
+
+	for _, scope := range []map[string]interface{}{
+		{"scopeType": "project", "scopeId": theirs},
+		{"scopeType": "tag", "scopeId": theirTag},
+		{"scopeType": "tag", "scopeId": theirs},
+	} {
+		scope["amount"], scope["currency"], scope["period"], scope["startDate"] = 10, "USD", "monthly", "2024-01-01"
+		code, _ := s.do(s.userID, http.MethodPost, "/budgets", scope)
+		s.Equal(http.StatusBadRequest, code, scope)
+	}
+}
+
+func (s *BudgetIntegrationTestSuite) TestCRUD() {
+	tag := s.createTag(s.userID, "Groceries")
+	code, response := s.do(s.userID, http.MethodPost, "/budgets", map[string]interface{}{
+		"scopeType": "tag", "scopeId": tag, "amount": 12.345, "currency": "KWD", "period": "quarterly", "startDate": "2024-02-29",
+	})
+	s.Require().Equal(http.StatusCreated, code, response)
+	created := response["data"].(map[string]interface{})
+	budgetID := created["budgetId"].(string)
+	s.Equal("tag", created["scopeType"])
+	s.Equal(tag.String(), created["scopeId"])
+	s.Equal(12.345, created["amount"])
+	s.Equal("2024-02-29", created["startDate"])
+
+	code, response = s.do(s.userID, http.MethodGet, "/budgets", nil)
+	s.Require().Equal(http.StatusOK, code)
+	s.Len(response["data"], 1)
+
+	code, _ = s.do(s.otherUserID, http.MethodGet, "/budgets/"+budgetID, nil)
+	s.Equal(http.StatusNotFound, code)
+
+	code, _ = s.do(s.userID, http.MethodDelete, "/budgets/"+budgetID, nil)
+	s.Equal(http.StatusOK, code)
+	code, _ = s.do(s.userID, http.MethodGet, "/budgets/"+budgetID+"/status", nil)
+	s.Equal(http.StatusNotFound, code)
+
+	// deleting the tag takes its budgets with it
+	s.createBudget(types.ScopeTag, tag, 10, "USD", types.PeriodMonthly, "2024-01-01")
+	_, err := s.pool.Exec(s.ctx, "DELETE FROM tags WHERE tag_id = $1", tag)
+	s.Require().NoError(err)
+	code, response = s.do(s.userID, http.MethodGet, "/budgets", nil)
+	s.Require().Equal(http.StatusOK, code)
+	s.Empty(response["data"])
+}
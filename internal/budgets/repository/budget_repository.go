@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+)
+
+// BudgetRepositoryImpl implements BudgetRepository interface
+type BudgetRepositoryImpl struct {
+	db *db.Queries
+}
+
+// NewBudgetRepository creates a new instance of BudgetRepository
+func NewBudgetRepository(queries *db.Queries) BudgetRepository {
+	return &BudgetRepositoryImpl{
+		db: queries,
+	}
+}
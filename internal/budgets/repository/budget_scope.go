@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+)
+
+// ScopeExists reports whether the project or the tag a budget is scoped to belongs to
+// the user, projects in the trash don't
+func (r *BudgetRepositoryImpl) ScopeExists(ctx context.Context, userID uuid.UUID, scopeType string, scopeID uuid.UUID) (bool, error) {
+	projectID, tagID := toScope(scopeType, scopeID)
+	exists, err := r.db.BudgetScopeExists(ctx, db.BudgetScopeExistsParams{
+		ProjectID: projectID,
+		TagID:     tagID,
+		UserID:    userID,
+	})
+	if err != nil {
+		return false, errors.HandleRepositoryError(err, "check", "budget scope")
+	}
+	return exists, nil
+}
+
+// FindOverlappingBudget returns another budget of the user with the same scope and
+// period than excludeID, reporting false when there is none
+func (r *BudgetRepositoryImpl) FindOverlappingBudget(ctx context.Context, userID uuid.UUID, scopeType string, scopeID uuid.UUID, period string, excludeID uuid.UUID) (uuid.UUID, bool, error) {
+	projectID, tagID := toScope(scopeType, scopeID)
+	budgetID, err := r.db.FindOverlappingBudget(ctx, db.FindOverlappingBudgetParams{
+		UserID:    userID,
+		Period:    period,
+		ProjectID: projectID,
+		TagID:     tagID,
+		ExcludeID: excludeID,
+	})
+	if err == pgx.ErrNoRows {
+		return uuid.Nil, false, nil
+	}
+	if err != nil {
+		return uuid.Nil, false, errors.HandleRepositoryError(err, "find", "overlapping budget")
+	}
+	return budgetID, true, nil
+}
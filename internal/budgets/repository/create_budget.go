@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/budgets/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+)
+
+// CreateBudget creates a new budget, a scope has at most one budget per period
+func (r *BudgetRepositoryImpl) CreateBudget(ctx context.Context, userID uuid.UUID, payload types.BudgetCreatePayload) (types.Budget, error) {
+	projectID, tagID := toScope(payload.ScopeType, payload.ScopeID)
+	budget, err := r.db.CreateBudget(ctx, db.CreateBudgetParams{
+		UserID:    userID,
+		ProjectID: projectID,
+		TagID:     tagID,
+		Amount:    utils.ToNullableNumeric(&payload.Amount),
+		Currency:  payload.Currency,
+		Period:    payload.Period,
+		StartDate: toDate(payload.StartDate),
+		ActorID:   requestcontext.GetActorIDFromContext(ctx, userID),
+	})
+	if err != nil {
+		return types.Budget{}, errors.HandleRepositoryError(err, "create", "budget")
+	}
+
+	return toBudget(budget), nil
+}
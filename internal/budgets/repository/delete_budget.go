@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+)
+
+// DeleteBudget deletes a budget, the wallets and ledgers it counted are left alone
+func (r *BudgetRepositoryImpl) DeleteBudget(ctx context.Context, userID, budgetID uuid.UUID) error {
+	deleted, err := r.db.DeleteBudget(ctx, db.DeleteBudgetParams{
+		BudgetID: budgetID,
+		UserID:   userID,
+	})
+	if err != nil {
+		return errors.HandleRepositoryError(err, "delete", "budget")
+	}
+	if deleted == 0 {
+		return fmt.Errorf("delete budget %s: %w", budgetID, repository.ErrNotFound)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/budgets/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+)
+
+// GetBudget retrieves a budget by ID
+func (r *BudgetRepositoryImpl) GetBudget(ctx context.Context, userID, budgetID uuid.UUID) (types.Budget, error) {
+	budget, err := r.db.GetBudget(ctx, db.GetBudgetParams{
+		BudgetID: budgetID,
+		UserID:   userID,
+	})
+	if err != nil {
+		return types.Budget{}, errors.HandleRepositoryError(err, "get", "budget")
+	}
+
+	return toBudget(budget), nil
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/budgets/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+)
+
+// GetBudgetSpend sums what left the wallets of the budget from windowStart until the day
+// before windowEnd in a single query, the remaining amount and percentage come with it
+func (r *BudgetRepositoryImpl) GetBudgetSpend(ctx context.Context, userID, budgetID uuid.UUID, windowStart, windowEnd time.Time) (types.BudgetStatus, error) {
+	row, err := r.db.GetBudgetSpend(ctx, db.GetBudgetSpendParams{
+		BudgetID:    budgetID,
+		UserID:      userID,
+		WindowStart: pgtype.Date{Time: windowStart.UTC(), Valid: true},
+		WindowEnd:   pgtype.Date{Time: windowEnd.UTC(), Valid: true},
+	})
+	if err != nil {
+		return types.BudgetStatus{}, errors.HandleRepositoryError(err, "get", "budget spend")
+	}
+
+	return types.BudgetStatus{
+		BudgetID:   budgetID,
+		Spent:      numericValue(row.Spent),
+		Remaining:  numericValue(row.Remaining),
+		Percentage: numericValue(row.Percentage),
+	}, nil
+}
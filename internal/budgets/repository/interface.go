@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/budgets/types"
+	"github.com/google/uuid"
+)
+
+// BudgetRepository defines the interface for budget data access
+type BudgetRepository interface {
+	// ListBudgets retrieves the user's budgets, oldest first
+	ListBudgets(ctx context.Context, userID uuid.UUID) ([]types.Budget, error)
+
+	// GetBudget retrieves a budget by ID
+	GetBudget(ctx context.Context, userID, budgetID uuid.UUID) (types.Budget, error)
+
+	// CreateBudget creates a new budget
+	CreateBudget(ctx context.Context, userID uuid.UUID, payload types.BudgetCreatePayload) (types.Budget, error)
+
+	// UpdateBudget updates an existing budget
+	UpdateBudget(ctx context.Context, userID uuid.UUID, payload types.BudgetUpdatePayload) (types.Budget, error)
+
+	// DeleteBudget deletes a budget
+	DeleteBudget(ctx context.Context, userID, budgetID uuid.UUID) error
+
+	// ScopeExists reports whether the project or the tag a budget is scoped to belongs to the user
+	ScopeExists(ctx context.Context, userID uuid.UUID, scopeType string, scopeID uuid.UUID) (bool, error)
+
+	// FindOverlappingBudget returns another budget of the user with the same scope and
+	// period than excludeID, reporting false when there is none
+	FindOverlappingBudget(ctx context.Context, userID uuid.UUID, scopeType string, scopeID uuid.UUID, period string, excludeID uuid.UUID) (uuid.UUID, bool, error)
+
+	// GetBudgetSpend sums what left the wallets of the budget from windowStart until the
+	// day before windowEnd, the status only has its spend filled
+	GetBudgetSpend(ctx context.Context, userID, budgetID uuid.UUID, windowStart, windowEnd time.Time) (types.BudgetStatus, error)
+}
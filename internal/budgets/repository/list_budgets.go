@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/budgets/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+)
+
+// ListBudgets retrieves the user's budgets, oldest first
+func (r *BudgetRepositoryImpl) ListBudgets(ctx context.Context, userID uuid.UUID) ([]types.Budget, error) {
+	budgets, err := r.db.ListBudgets(ctx, userID)
+	if err != nil {
+		return []types.Budget{}, errors.HandleRepositoryError(err, "list", "budget(s)")
+	}
+
+	return toBudgets(budgets), nil
+}
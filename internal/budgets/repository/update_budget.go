@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/budgets/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+)
+
+// UpdateBudget updates an existing budget
+func (r *BudgetRepositoryImpl) UpdateBudget(ctx context.Context, userID uuid.UUID, payload types.BudgetUpdatePayload) (types.Budget, error) {
+	projectID, tagID := toScope(payload.ScopeType, payload.ScopeID)
+	budget, err := r.db.UpdateBudget(ctx, db.UpdateBudgetParams{
+		BudgetID:  payload.BudgetID,
+		UserID:    userID,
+		ProjectID: projectID,
+		TagID:     tagID,
+		Amount:    utils.ToNullableNumeric(&payload.Amount),
+		Currency:  payload.Currency,
+		Period:    payload.Period,
+		StartDate: toDate(payload.StartDate),
+		ActorID:   requestcontext.GetActorIDFromContext(ctx, userID),
+	})
+	if err != nil {
+		return types.Budget{}, errors.HandleRepositoryError(err, "update", "budget")
+	}
+
+	return toBudget(budget), nil
+}
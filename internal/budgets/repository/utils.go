@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/budgets/types"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
+)
+
+// toBudget converts a db.Budget to domain types.Budget
+func toBudget(b db.Budget) types.Budget {
+	budget := types.Budget{
+		BudgetID:  b.BudgetID,
+		Amount:    numericValue(b.Amount),
+		Currency:  b.Currency,
+		Period:    b.Period,
+		StartDate: b.StartDate.Time.Format(types.DateLayout),
+		CreatedAt: coreTypes.NewTimestamp(b.CreatedAt.Time),
+		UpdatedAt: coreTypes.NewTimestamp(b.UpdatedAt.Time),
+		CreatedBy: utils.GetUUIDPtr(b.CreatedBy),
+		UpdatedBy: utils.GetUUIDPtr(b.UpdatedBy),
+	}
+	if b.ProjectID.Valid {
+		budget.ScopeType, budget.ScopeID = types.ScopeProject, b.ProjectID.Bytes
+	} else {
+		budget.ScopeType, budget.ScopeID = types.ScopeTag, b.TagID.Bytes
+	}
+	return budget
+}
+
+// toBudgets converts a slice of db.Budget to a slice of domain types.Budget
+func toBudgets(budgets []db.Budget) []types.Budget {
+	result := make([]types.Budget, len(budgets))
+	for i, b := range budgets {
+		result[i] = toBudget(b)
+	}
+	return result
+}
+
+// toScope splits a scope into the project and tag columns, the one not in use is null
+func toScope(scopeType string, scopeID uuid.UUID) (projectID, tagID pgtype.UUID) {
+	if scopeType == types.ScopeProject {
+		return utils.ToNullableUUID(scopeID), pgtype.UUID{}
+	}
+	return pgtype.UUID{}, utils.ToNullableUUID(scopeID)
+}
+
+// toDate converts a date written in types.DateLayout, the payloads are validated
+// before they reach the repository
+func toDate(value string) pgtype.Date {
+	date, err := time.Parse(types.DateLayout, value)
+	if err != nil {
+		return pgtype.Date{Valid: false}
+	}
+	return pgtype.Date{Time: date, Valid: true}
+}
+
+// numericValue converts an amount to a float64, zero when it is null
+func numericValue(n pgtype.Numeric) float64 {
+	if value := utils.GetFloat64Ptr(n); value != nil {
+		return *value
+	}
+	return 0
+}
@@ -0,0 +1,40 @@
+package routes
+
+import (
+	"github.com/Abdelrahman-habib/expense-tracker/internal/budgets/handlers"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/budgets/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/budgets/service"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// Router encapsulates the budget routes setup
+type Router struct {
+	handler *handlers.BudgetHandler
+}
+
+// New creates a new budget router with proper dependency injection
+func New(dbService db.Service, logger *zap.Logger) *Router {
+	repo := repository.NewBudgetRepository(dbService.Queries())
+	budgetService := service.NewBudgetService(repo, logger)
+	handler := handlers.NewBudgetHandler(budgetService, logger)
+
+	return &Router{
+		handler: handler,
+	}
+}
+
+// RegisterRoutes registers all budget routes
+func (r *Router) RegisterRoutes(router chi.Router) {
+	router.Route("/budgets", func(router chi.Router) {
+		router.Get("/", r.handler.ListBudgets)
+		router.Post("/", r.handler.CreateBudget)
+		router.Route("/{id}", func(router chi.Router) {
+			router.Get("/", r.handler.GetBudget)
+			router.Put("/", r.handler.UpdateBudget)
+			router.Delete("/", r.handler.DeleteBudget)
+			router.Get("/status", r.handler.GetBudgetStatus)
+		})
+	})
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/budgets/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/budgets/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/logging"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type BudgetService interface {
+	ListBudgets(ctx context.Context, userID uuid.UUID) ([]types.Budget, error)
+	GetBudget(ctx context.Context, userID, budgetID uuid.UUID) (types.Budget, error)
+	CreateBudget(ctx context.Context, userID uuid.UUID, payload types.BudgetCreatePayload) (types.Budget, error)
+	UpdateBudget(ctx context.Context, userID uuid.UUID, payload types.BudgetUpdatePayload) (types.Budget, error)
+	DeleteBudget(ctx context.Context, userID, budgetID uuid.UUID) error
+	GetBudgetStatus(ctx context.Context, userID, budgetID uuid.UUID) (types.BudgetStatus, error)
+}
+
+type budgetService struct {
+	repo   repository.BudgetRepository
+	now    func() time.Time
+	logger *zap.Logger
+}
+
+func NewBudgetService(repo repository.BudgetRepository, logger *zap.Logger) BudgetService {
+	return &budgetService{
+		repo:   repo,
+		now:    time.Now,
+		logger: logger.With(zap.String("component", "budget_service")),
+	}
+}
+
+// operation starts the log of a budget service method, budgetID is uuid.Nil when the
+// method doesn't work on one budget
+func (s *budgetService) operation(name string, userID, budgetID uuid.UUID, fields ...zap.Field) *logging.Operation {
+	logger := logging.WithEntity(logging.WithUser(s.logger, userID), "budget", budgetID)
+	return logging.Start(logger, "BudgetService."+name, fields...)
+}
+
+// validateBudget checks the scope belongs to the user and no other budget of the scope
+// has the same period, budgetID is the budget being updated and uuid.Nil on create
+func (s *budgetService) validateBudget(ctx context.Context, userID, budgetID uuid.UUID, scopeType string, scopeID uuid.UUID, period string) error {
+	if types.PeriodMonths(period) == 0 {
+		return errors.NewValidationError("period: must be monthly, quarterly or yearly")
+	}
+
+	exists, err := s.repo.ScopeExists(ctx, userID, scopeType, scopeID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.NewValidationError("scope_id: %s %s not found", scopeType, scopeID)
+	}
+
+	overlapping, found, err := s.repo.FindOverlappingBudget(ctx, userID, scopeType, scopeID, period, budgetID)
+	if err != nil {
+		return err
+	}
+	if found {
+		return errors.NewValidationError("period: the %s already has a %s budget %s", scopeType, period, overlapping)
+	}
+	return nil
+}
+
+func (s *budgetService) ListBudgets(ctx context.Context, userID uuid.UUID) (_ []types.Budget, err error) {
+	defer s.operation("ListBudgets", userID, uuid.Nil).End(&err)
+	return s.repo.ListBudgets(ctx, userID)
+}
+
+func (s *budgetService) GetBudget(ctx context.Context, userID, budgetID uuid.UUID) (_ types.Budget, err error) {
+	defer s.operation("GetBudget", userID, budgetID).End(&err)
+	return s.repo.GetBudget(ctx, userID, budgetID)
+}
+
+func (s *budgetService) CreateBudget(ctx context.Context, userID uuid.UUID, payload types.BudgetCreatePayload) (_ types.Budget, err error) {
+	op := s.operation("CreateBudget", userID, uuid.Nil,
+		zap.String("scope_type", payload.ScopeType),
+		zap.String("period", payload.Period))
+	defer op.End(&err)
+
+	if err := s.validateBudget(ctx, userID, uuid.Nil, payload.ScopeType, payload.ScopeID, payload.Period); err != nil {
+		return types.Budget{}, err
+	}
+
+	budget, err := s.repo.CreateBudget(ctx, userID, payload)
+	if err != nil {
+		return types.Budget{}, err
+	}
+	op.With(zap.Stringer(logging.FieldEntityID, budget.BudgetID))
+	return budget, nil
+}
+
+func (s *budgetService) UpdateBudget(ctx context.Context, userID uuid.UUID, payload types.BudgetUpdatePayload) (_ types.Budget, err error) {
+	defer s.operation("UpdateBudget", userID, payload.BudgetID,
+		zap.String("scope_type", payload.ScopeType),
+		zap.String("period", payload.Period)).End(&err)
+
+	if err := s.validateBudget(ctx, userID, payload.BudgetID, payload.ScopeType, payload.ScopeID, payload.Period); err != nil {
+		return types.Budget{}, err
+	}
+
+	return s.repo.UpdateBudget(ctx, userID, payload)
+}
+
+func (s *budgetService) DeleteBudget(ctx context.Context, userID, budgetID uuid.UUID) (err error) {
+	defer s.operation("DeleteBudget", userID, budgetID).End(&err)
+	return s.repo.DeleteBudget(ctx, userID, budgetID)
+}
+
+// GetBudgetStatus returns the spending of the budget over the period today falls in,
+// today being the UTC day
+func (s *budgetService) GetBudgetStatus(ctx context.Context, userID, budgetID uuid.UUID) (_ types.BudgetStatus, err error) {
+	defer s.operation("GetBudgetStatus", userID, budgetID).End(&err)
+
+	budget, err := s.repo.GetBudget(ctx, userID, budgetID)
+	if err != nil {
+		return types.BudgetStatus{}, err
+	}
+	start, err := time.Parse(types.DateLayout, budget.StartDate)
+	if err != nil {
+		return types.BudgetStatus{}, err
+	}
+
+	windowStart, windowEnd := currentWindow(budget.Period, start, s.now())
+	status, err := s.repo.GetBudgetSpend(ctx, userID, budgetID, windowStart, windowEnd)
+	if err != nil {
+		return types.BudgetStatus{}, err
+	}
+
+	status.Period = budget.Period
+	status.WindowStart = windowStart.Format(types.DateLayout)
+	status.WindowEnd = windowEnd.Format(types.DateLayout)
+	status.Amount = budget.Amount
+	status.Currency = budget.Currency
+	return status, nil
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/budgets/types"
+	coreErrors "github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type mockBudgetRepository struct {
+	mock.Mock
+}
+
+func (m *mockBudgetRepository) ListBudgets(ctx context.Context, userID uuid.UUID) ([]types.Budget, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]types.Budget), args.Error(1)
+}
+
+func (m *mockBudgetRepository) GetBudget(ctx context.Context, userID, budgetID uuid.UUID) (types.Budget, error) {
+	args := m.Called(ctx, userID, budgetID)
+	return args.Get(0).(types.Budget), args.Error(1)
+}
+
+func (m *mockBudgetRepository) CreateBudget(ctx context.Context, userID uuid.UUID, payload types.BudgetCreatePayload) (types.Budget, error) {
+	args := m.Called(ctx, userID, payload)
+	return args.Get(0).(types.Budget), args.Error(1)
+}
+
+func (m *mockBudgetRepository) UpdateBudget(ctx context.Context, userID uuid.UUID, payload types.BudgetUpdatePayload) (types.Budget, error) {
+	args := m.Called(ctx, userID, payload)
+	return args.Get(0).(types.Budget), args.Error(1)
+}
+
+func (m *mockBudgetRepository) DeleteBudget(ctx context.Context, userID, budgetID uuid.UUID) error {
+	return m.Called(ctx, userID, budgetID).Error(0)
+}
+
+func (m *mockBudgetRepository) ScopeExists(ctx context.Context, userID uuid.UUID, scopeType string, scopeID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, userID, scopeType, scopeID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockBudgetRepository) FindOverlappingBudget(ctx context.Context, userID uuid.UUID, scopeType string, scopeID uuid.UUID, period string, excludeID uuid.UUID) (uuid.UUID, bool, error) {
+	args := m.Called(ctx, userID, scopeType, scopeID, period, excludeID)
+	return args.Get(0).(uuid.UUID), args.Bool(1), args.Error(2)
+}
+
+func (m *mockBudgetRepository) GetBudgetSpend(ctx context.Context, userID, budgetID uuid.UUID, windowStart, windowEnd time.Time) (types.BudgetStatus, error) {
+	args := m.Called(ctx, userID, budgetID, windowStart, windowEnd)
+	return args.Get(0).(types.BudgetStatus), args.Error(1)
+}
+
+func setupTest(t *testing.T, now time.Time) (*mockBudgetRepository, *budgetService) {
+	mockRepo := new(mockBudgetRepository)
+	service := NewBudgetService(mockRepo, zap.NewNop()).(*budgetService)
+	service.now = func() time.Time { return now }
+	return mockRepo, service
+}
+
+func TestBudgetService_CreateBudget(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	projectID := uuid.New()
+	payload := types.BudgetCreatePayload{
+		ScopeType: types.ScopeProject,
+		ScopeID:   projectID,
+		Amount:    500,
+		Currency:  "USD",
+		Period:    types.PeriodMonthly,
+		StartDate: "2024-01-31",
+	}
+
+	t.Run("creates the budget", func(t *testing.T) {
+		mockRepo, service := setupTest(t, time.Now())
+		mockRepo.On("ScopeExists", ctx, userID, types.ScopeProject, projectID).Return(true, nil)
+		mockRepo.On("FindOverlappingBudget", ctx, userID, types.ScopeProject, projectID, types.PeriodMonthly, uuid.Nil).Return(uuid.Nil, false, nil)
+		mockRepo.On("CreateBudget", ctx, userID, payload).Return(types.Budget{BudgetID: uuid.New()}, nil)
+
+		_, err := service.CreateBudget(ctx, userID, payload)
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects a scope of another user", func(t *testing.T) {
+		mockRepo, service := setupTest(t, time.Now())
+		mockRepo.On("ScopeExists", ctx, userID, types.ScopeProject, projectID).Return(false, nil)
+
+		_, err := service.CreateBudget(ctx, userID, payload)
+		assert.True(t, coreErrors.IsErrorType(err, coreErrors.ErrorTypeValidation))
+		mockRepo.AssertNotCalled(t, "CreateBudget", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects an overlapping budget", func(t *testing.T) {
+		mockRepo, service := setupTest(t, time.Now())
+		existing := uuid.New()
+		mockRepo.On("ScopeExists", ctx, userID, types.ScopeProject, projectID).Return(true, nil)
+		mockRepo.On("FindOverlappingBudget", ctx, userID, types.ScopeProject, projectID, types.PeriodMonthly, uuid.Nil).Return(existing, true, nil)
+
+		_, err := service.CreateBudget(ctx, userID, payload)
+		assert.True(t, coreErrors.IsErrorType(err, coreErrors.ErrorTypeValidation))
+		assert.Contains(t, err.Error(), existing.String())
+		mockRepo.AssertNotCalled(t, "CreateBudget", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestBudgetService_UpdateBudget_IgnoresItself(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	tagID := uuid.New()
+	payload := types.BudgetUpdatePayload{
+		BudgetID:  uuid.New(),
+		ScopeType: types.ScopeTag,
+		ScopeID:   tagID,
+		Amount:    100,
+		Currency:  "EUR",
+		Period:    types.PeriodYearly,
+		StartDate: "2024-01-01",
+	}
+
+	mockRepo, service := setupTest(t, time.Now())
+	mockRepo.On("ScopeExists", ctx, userID, types.ScopeTag, tagID).Return(true, nil)
+	mockRepo.On("FindOverlappingBudget", ctx, userID, types.ScopeTag, tagID, types.PeriodYearly, payload.BudgetID).Return(uuid.Nil, false, nil)
+	mockRepo.On("UpdateBudget", ctx, userID, payload).Return(types.Budget{BudgetID: payload.BudgetID}, nil)
+
+	_, err := service.UpdateBudget(ctx, userID, payload)
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestBudgetService_GetBudgetStatus(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	budget := types.Budget{
+		BudgetID:  uuid.New(),
+		Amount:    400,
+		Currency:  "USD",
+		Period:    types.PeriodMonthly,
+		StartDate: "2024-01-31",
+	}
+
+	t.Run("spend of the current period", func(t *testing.T) {
+		mockRepo, service := setupTest(t, time.Date(2024, 3, 5, 15, 0, 0, 0, time.UTC))
+		mockRepo.On("GetBudget", ctx, userID, budget.BudgetID).Return(budget, nil)
+		mockRepo.On("GetBudgetSpend", ctx, userID, budget.BudgetID, date("2024-02-29"), date("2024-03-31")).
+			Return(types.BudgetStatus{BudgetID: budget.BudgetID, Spent: 100, Remaining: 300, Percentage: 25}, nil)
+
+		status, err := service.GetBudgetStatus(ctx, userID, budget.BudgetID)
+		require.NoError(t, err)
+		assert.Equal(t, types.BudgetStatus{
+			BudgetID:    budget.BudgetID,
+			Period:      types.PeriodMonthly,
+			WindowStart: "2024-02-29",
+			WindowEnd:   "2024-03-31",
+			Amount:      400,
+			Currency:    "USD",
+			Spent:       100,
+			Remaining:   300,
+			Percentage:  25,
+		}, status)
+	})
+
+	t.Run("missing budget", func(t *testing.T) {
+		mockRepo, service := setupTest(t, time.Now())
+		mockRepo.On("GetBudget", ctx, userID, budget.BudgetID).Return(types.Budget{}, repository.ErrNotFound)
+
+		_, err := service.GetBudgetStatus(ctx, userID, budget.BudgetID)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+		mockRepo.AssertNotCalled(t, "GetBudgetSpend", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/budgets/types"
+)
+
+// currentWindow returns the period of a budget starting on start that today falls in, as
+// its first day and the first day of the next period. The periods before the start
+// date don't exist, until then the window is the first period.
+func currentWindow(period string, start, today time.Time) (time.Time, time.Time) {
+	months := types.PeriodMonths(period)
+	start, today = toDay(start), toDay(today)
+
+	elapsed := (today.Year()-start.Year())*12 + int(today.Month()) - int(start.Month())
+	k := elapsed / months
+	// the period of today's month may start later in the month than today
+	if k > 0 && addMonths(start, k*months).After(today) {
+		k--
+	}
+	if k < 0 {
+		k = 0
+	}
+
+	return addMonths(start, k*months), addMonths(start, (k+1)*months)
+}
+
+// addMonths moves anchor n months forward keeping its day of the month, clamped to the
+// last day of shorter months. Every period is counted from the anchor rather than the
+// previous period, so a budget starting on Jan 31 runs Feb 29, Mar 31, Apr 30 and so on
+// instead of drifting to the 28th.
+func addMonths(anchor time.Time, n int) time.Time {
+	first := time.Date(anchor.Year(), anchor.Month()+time.Month(n), 1, 0, 0, 0, 0, time.UTC)
+	day := min(anchor.Day(), daysIn(first))
+	return time.Date(first.Year(), first.Month(), day, 0, 0, 0, 0, time.UTC)
+}
+
+// daysIn returns the number of days of the month t is in
+func daysIn(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// toDay returns the UTC calendar day of t at midnight
+func toDay(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
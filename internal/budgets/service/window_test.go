@@ -0,0 +1,90 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/budgets/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func date(value string) time.Time {
+	t, err := time.Parse(types.DateLayout, value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestAddMonths(t *testing.T) {
+	tests := []struct {
+		name   string
+		anchor string
+		months int
+		want   string
+	}{
+		{"same day next month", "2024-01-15", 1, "2024-02-15"},
+		{"Jan 31 clamps to the end of a leap February", "2024-01-31", 1, "2024-02-29"},
+		{"Jan 31 clamps to the end of a common February", "2023-01-31", 1, "2023-02-28"},
+		{"Jan 31 comes back to the 31st in March", "2024-01-31", 2, "2024-03-31"},
+		{"Jan 31 clamps to Apr 30", "2024-01-31", 3, "2024-04-30"},
+		{"Mar 31 clamps to the end of June a quarter later", "2024-03-31", 3, "2024-06-30"},
+		{"crosses the year boundary", "2024-11-30", 2, "2025-01-30"},
+		{"Dec 31 crosses into the next year", "2024-12-31", 1, "2025-01-31"},
+		{"Feb 29 a year later", "2024-02-29", 12, "2025-02-28"},
+		{"Feb 29 four years later", "2024-02-29", 48, "2028-02-29"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, date(tt.want), addMonths(date(tt.anchor), tt.months))
+		})
+	}
+}
+
+func TestCurrentWindow(t *testing.T) {
+	tests := []struct {
+		name      string
+		period    string
+		start     string
+		today     string
+		wantStart string
+		wantEnd   string
+	}{
+		{"first day of a monthly budget", types.PeriodMonthly, "2024-01-15", "2024-01-15", "2024-01-15", "2024-02-15"},
+		{"the day before the next period", types.PeriodMonthly, "2024-01-15", "2024-02-14", "2024-01-15", "2024-02-15"},
+		{"monthly budget rolls over", types.PeriodMonthly, "2024-01-15", "2024-02-15", "2024-02-15", "2024-03-15"},
+		{"Jan 31 monthly in February of a leap year", types.PeriodMonthly, "2024-01-31", "2024-02-15", "2024-01-31", "2024-02-29"},
+		{"Jan 31 monthly on Feb 29", types.PeriodMonthly, "2024-01-31", "2024-02-29", "2024-02-29", "2024-03-31"},
+		{"Jan 31 monthly in a common year", types.PeriodMonthly, "2023-01-31", "2023-02-28", "2023-02-28", "2023-03-31"},
+		{"Jan 31 monthly keeps the 31st after February", types.PeriodMonthly, "2024-01-31", "2024-03-31", "2024-03-31", "2024-04-30"},
+		{"Jan 31 monthly on Mar 30", types.PeriodMonthly, "2024-01-31", "2024-03-30", "2024-02-29", "2024-03-31"},
+		{"monthly across the year boundary", types.PeriodMonthly, "2024-12-20", "2025-01-05", "2024-12-20", "2025-01-20"},
+		{"monthly years later", types.PeriodMonthly, "2020-05-31", "2024-06-30", "2024-06-30", "2024-07-31"},
+		{"quarterly second quarter", types.PeriodQuarterly, "2024-01-01", "2024-05-20", "2024-04-01", "2024-07-01"},
+		{"quarterly from Nov 30 across the year", types.PeriodQuarterly, "2024-11-30", "2025-02-28", "2025-02-28", "2025-05-30"},
+		{"quarterly from Nov 30 before it rolls over", types.PeriodQuarterly, "2024-11-30", "2025-02-27", "2024-11-30", "2025-02-28"},
+		{"yearly from a leap day", types.PeriodYearly, "2024-02-29", "2025-03-01", "2025-02-28", "2026-02-28"},
+		{"yearly back on a leap day", types.PeriodYearly, "2024-02-29", "2028-02-29", "2028-02-29", "2029-02-28"},
+		{"yearly the day before a leap day", types.PeriodYearly, "2024-02-29", "2028-02-28", "2027-02-28", "2028-02-29"},
+		{"yearly across the year boundary", types.PeriodYearly, "2024-07-01", "2025-01-01", "2024-07-01", "2025-07-01"},
+		{"before the start date is the first period", types.PeriodMonthly, "2024-03-10", "2024-01-05", "2024-03-10", "2024-04-10"},
+		{"earlier in the start month", types.PeriodQuarterly, "2024-03-10", "2024-03-01", "2024-03-10", "2024-06-10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := currentWindow(tt.period, date(tt.start), date(tt.today))
+			assert.Equal(t, date(tt.wantStart), start, "window start")
+			assert.Equal(t, date(tt.wantEnd), end, "window end")
+		})
+	}
+}
+
+func TestCurrentWindow_UsesTheUTCDay(t *testing.T) {
+	// late on Jan 31 in New York is already Feb 1 in UTC
+	newYork := time.FixedZone("EST", -5*60*60)
+	start, end := currentWindow(types.PeriodMonthly, date("2024-01-01"), time.Date(2024, 1, 31, 22, 0, 0, 0, newYork))
+	assert.Equal(t, date("2024-02-01"), start)
+	assert.Equal(t, date("2024-03-01"), end)
+}
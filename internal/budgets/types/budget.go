@@ -0,0 +1,131 @@
+package types
+
+import (
+	"net/http"
+
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/validate"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/google/uuid"
+)
+
+// What a budget is scoped to
+const (
+	ScopeProject = "project"
+	ScopeTag     = "tag"
+)
+
+// Periods a budget recurs over
+const (
+	PeriodMonthly   = "monthly"
+	PeriodQuarterly = "quarterly"
+	PeriodYearly    = "yearly"
+)
+
+// DateLayout is how the dates of budgets are written, calendar days in UTC
+const DateLayout = "2006-01-02"
+
+// PeriodMonths returns the number of months a period spans, 0 for an unknown period
+func PeriodMonths(period string) int {
+	switch period {
+	case PeriodMonthly:
+		return 1
+	case PeriodQuarterly:
+		return 3
+	case PeriodYearly:
+		return 12
+	}
+	return 0
+}
+
+// Budget caps the spending on a project or a tag, renewed every period from its start date
+// @Description Budget of a project or a tag, it recurs every period from its start date without end
+type Budget struct {
+	BudgetID  uuid.UUID           `json:"budgetId" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
+	ScopeType string              `json:"scopeType" example:"project" enums:"project,tag"`
+	ScopeID   uuid.UUID           `json:"scopeId" example:"123e4567-e89b-12d3-a456-426614174002" format:"uuid"` // the project or the tag
+	Amount    float64             `json:"amount" example:"500.00"`
+	Currency  string              `json:"currency" example:"USD" minLength:"3" maxLength:"3"`
+	Period    string              `json:"period" example:"monthly" enums:"monthly,quarterly,yearly"`
+	StartDate string              `json:"startDate" example:"2024-01-31" format:"date"`
+	CreatedAt coreTypes.Timestamp `json:"createdAt" example:"2024-01-01T00:00:00.000Z" swaggertype:"string" format:"date-time"`
+	UpdatedAt coreTypes.Timestamp `json:"updatedAt" example:"2024-01-01T00:00:00.000Z" swaggertype:"string" format:"date-time"`
+	CreatedBy *uuid.UUID          `json:"createdBy,omitempty" example:"123e4567-e89b-12d3-a456-426614174001" format:"uuid"` // user who created the budget
+	UpdatedBy *uuid.UUID          `json:"updatedBy,omitempty" example:"123e4567-e89b-12d3-a456-426614174001" format:"uuid"` // user behind the last update
+}
+
+// BudgetCreatePayload represents the payload for creating a budget
+// @Description Payload for creating a budget, a scope has at most one budget per period
+type BudgetCreatePayload struct {
+	ScopeType string    `json:"scopeType" example:"project" enums:"project,tag" validate:"required"`
+	ScopeID   uuid.UUID `json:"scopeId" example:"123e4567-e89b-12d3-a456-426614174002" format:"uuid" validate:"required"`
+	Amount    float64   `json:"amount" example:"500.00" validate:"required"`
+	Currency  string    `json:"currency" example:"USD" minLength:"3" maxLength:"3" validate:"required"`
+	Period    string    `json:"period" example:"monthly" enums:"monthly,quarterly,yearly" validate:"required"`
+	StartDate string    `json:"startDate" example:"2024-01-31" format:"date" validate:"required"` // the first day of the first period
+}
+
+// Bind implements render.Binder interface
+func (c *BudgetCreatePayload) Bind(r *http.Request) error {
+	return validateBudget(c.ScopeType, c.ScopeID, c.Amount, c.Currency, c.Period, c.StartDate)
+}
+
+// BudgetUpdatePayload represents the payload for updating a budget
+// @Description Payload for updating a budget, the fields left out keep their value
+type BudgetUpdatePayload struct {
+	BudgetID  uuid.UUID `json:"-" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
+	ScopeType string    `json:"scopeType" example:"project" enums:"project,tag"`
+	ScopeID   uuid.UUID `json:"scopeId" example:"123e4567-e89b-12d3-a456-426614174002" format:"uuid"`
+	Amount    float64   `json:"amount" example:"500.00"`
+	Currency  string    `json:"currency" example:"USD" minLength:"3" maxLength:"3"`
+	Period    string    `json:"period" example:"monthly" enums:"monthly,quarterly,yearly"`
+	StartDate string    `json:"startDate" example:"2024-01-31" format:"date"`
+}
+
+// Bind implements render.Binder interface
+func (u *BudgetUpdatePayload) Bind(r *http.Request) error {
+	return validateBudget(u.ScopeType, u.ScopeID, u.Amount, u.Currency, u.Period, u.StartDate)
+}
+
+// validateBudget checks the fields shared by the create and update payloads
+func validateBudget(scopeType string, scopeID uuid.UUID, amount float64, currency, period, startDate string) error {
+	return validation.Errors{
+		"scope_type": validation.Validate(scopeType, validation.Required, validation.In(ScopeProject, ScopeTag)),
+		"scope_id":   validation.Validate(scopeID.String(), validation.NotIn(uuid.Nil.String()).Error("cannot be blank")),
+		"amount": validation.Validate(amount, validation.Required,
+			validation.Min(0.0).Exclusive().Error("amount must be positive"),
+			validate.CurrencyPrecision(currency)),
+		"currency":   validation.Validate(currency, validation.Required, is.CurrencyCode),
+		"period":     validation.Validate(period, validation.Required, validation.In(PeriodMonthly, PeriodQuarterly, PeriodYearly)),
+		"start_date": validation.Validate(startDate, validation.Required, validation.Date(DateLayout)),
+	}.Filter()
+}
+
+func (b *Budget) ToUpdatePayload() BudgetUpdatePayload {
+	return BudgetUpdatePayload{
+		BudgetID:  b.BudgetID,
+		ScopeType: b.ScopeType,
+		ScopeID:   b.ScopeID,
+		Amount:    b.Amount,
+		Currency:  b.Currency,
+		Period:    b.Period,
+		StartDate: b.StartDate,
+	}
+}
+
+// BudgetStatus is how much of a budget is spent in its current period
+// @Description Spending of a budget over its current period, counted from the ledgers of the wallets in its scope and currency
+type BudgetStatus struct {
+	BudgetID uuid.UUID `json:"budgetId" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
+	Period   string    `json:"period" example:"monthly" enums:"monthly,quarterly,yearly"`
+	// WindowStart is the first day of the current period
+	WindowStart string `json:"windowStart" example:"2024-02-29" format:"date"`
+	// WindowEnd is the first day of the next period, it isn't part of the current one
+	WindowEnd  string  `json:"windowEnd" example:"2024-03-31" format:"date"`
+	Amount     float64 `json:"amount" example:"500.00"`
+	Currency   string  `json:"currency" example:"USD" minLength:"3" maxLength:"3"`
+	Spent      float64 `json:"spent" example:"125.50"`
+	Remaining  float64 `json:"remaining" example:"374.50"` // negative once the budget is overspent
+	Percentage float64 `json:"percentage" example:"25.1"`  // of the amount spent, past 100 once overspent
+}
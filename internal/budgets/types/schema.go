@@ -0,0 +1,33 @@
+package types
+
+import (
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/schema"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/validate"
+)
+
+// SchemaVersion is bumped whenever a budget payload rule changes
+const SchemaVersion = 1
+
+// Schema describes the budget create and update payloads, mirroring their Bind rules
+func Schema() schema.Entity {
+	return schema.Entity{
+		Name:    "budgets",
+		Title:   "Budget payloads",
+		Version: SchemaVersion,
+		Create:  schema.Object(budgetProperties(), "scopeType", "scopeId", "amount", "currency", "period", "startDate"),
+		// the update is applied over the stored budget so every field is optional
+		Update: schema.Object(budgetProperties()),
+	}
+}
+
+func budgetProperties() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"scopeType": schema.In(schema.String(), ScopeProject, ScopeTag),
+		"scopeId":   schema.UUID(),
+		"amount": schema.Number().Min(0).
+			Describe("greater than zero, limited to the decimal places of the currency"),
+		"currency":  schema.In(schema.String(), validate.CurrencyCodes()...),
+		"period":    schema.In(schema.String(), PeriodMonthly, PeriodQuarterly, PeriodYearly),
+		"startDate": schema.String().As("date"),
+	}
+}
@@ -3,17 +3,20 @@ package handlers
 import (
 	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/service"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 	"go.uber.org/zap"
 )
 
 type ContactHandler struct {
 	handlers.BaseHandler
 	service service.ContactService
+	limits  coreTypes.LimitPolicy
 }
 
-func NewContactHandler(service service.ContactService, logger *zap.Logger) *ContactHandler {
+func NewContactHandler(service service.ContactService, limits coreTypes.LimitPolicy, logger *zap.Logger) *ContactHandler {
 	return &ContactHandler{
 		BaseHandler: handlers.NewBaseHandler(logger),
 		service:     service,
+		limits:      limits,
 	}
 }
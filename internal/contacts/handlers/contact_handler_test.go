@@ -2,22 +2,31 @@ package handlers
 
 import (
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	coreErrors "github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/repository"
 	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	jobTypes "github.com/Abdelrahman-habib/expense-tracker/internal/jobs/types"
 	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
@@ -26,59 +35,218 @@ type mockContactService struct {
 	mock.Mock
 }
 
-func (m *mockContactService) GetContact(ctx context.Context, contactID, userID uuid.UUID) (types.Contact, error) {
-	args := m.Called(ctx, contactID, userID)
+func (m *mockContactService) CreateContactRelationship(ctx context.Context, contactID, userID uuid.UUID, payload types.ContactRelationshipPayload) (types.ContactRelationship, error) {
+	args := m.Called(ctx, contactID, userID, payload)
+	return args.Get(0).(types.ContactRelationship), args.Error(1)
+}
+
+func (m *mockContactService) DeleteContactRelationship(ctx context.Context, contactID, relationshipID, userID uuid.UUID) error {
+	args := m.Called(ctx, contactID, relationshipID, userID)
+	return args.Error(0)
+}
+
+func (m *mockContactService) GetContact(ctx context.Context, contactID, userID uuid.UUID, expand types.ContactExpand) (types.Contact, error) {
+	args := m.Called(ctx, contactID, userID, expand)
 	if args.Get(0) == nil {
 		return types.Contact{}, args.Error(1)
 	}
 	return args.Get(0).(types.Contact), args.Error(1)
 }
 
+func (m *mockContactService) GetContactsByIDs(ctx context.Context, userID uuid.UUID, contactIDs []uuid.UUID) ([]types.Contact, []uuid.UUID, error) {
+	args := m.Called(ctx, userID, contactIDs)
+	return args.Get(0).([]types.Contact), args.Get(1).([]uuid.UUID), args.Error(2)
+}
+
 func (m *mockContactService) ListContacts(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]types.Contact, error) {
 	args := m.Called(ctx, userID, limit, offset)
 	return args.Get(0).([]types.Contact), args.Error(1)
 }
 
-func (m *mockContactService) ListContactsPaginated(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32) ([]types.Contact, error) {
-	args := m.Called(ctx, userID, cursor, cursorID, limit)
+func (m *mockContactService) ListContactsPaginated(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.ContactFilter) ([]types.Contact, error) {
+	args := m.Called(ctx, userID, cursor, cursorID, limit, order, filter)
 	return args.Get(0).([]types.Contact), args.Error(1)
 }
 
+func (m *mockContactService) ListContactIDsPaginated(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.ContactFilter) ([]coreTypes.KeyedID, error) {
+	args := m.Called(ctx, userID, cursor, cursorID, limit, order, filter)
+	return args.Get(0).([]coreTypes.KeyedID), args.Error(1)
+}
+
+// StreamContactsPaginated hands the contacts to fn and then returns the error, so a
+// test fails a stream midway by returning both
+func (m *mockContactService) StreamContactsPaginated(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.ContactFilter, fn func(types.Contact) error) error {
+	args := m.Called(ctx, userID, cursor, cursorID, limit, order, filter)
+	for _, contact := range args.Get(0).([]types.Contact) {
+		if err := fn(contact); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *mockContactService) ExportContacts(ctx context.Context, userID uuid.UUID, fn func(types.Contact) error) error {
+	args := m.Called(ctx, userID)
+	for _, contact := range args.Get(0).([]types.Contact) {
+		if err := fn(contact); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *mockContactService) StartContactExport(ctx context.Context, userID uuid.UUID, format string) (types.ExportJob, error) {
+	args := m.Called(ctx, userID, format)
+	return args.Get(0).(types.ExportJob), args.Error(1)
+}
+
+func (m *mockContactService) GetContactExport(ctx context.Context, userID, jobID uuid.UUID) (types.ExportJob, error) {
+	args := m.Called(ctx, userID, jobID)
+	return args.Get(0).(types.ExportJob), args.Error(1)
+}
+
+func (m *mockContactService) OpenContactExport(ctx context.Context, userID, jobID uuid.UUID) (types.ExportJob, io.ReadCloser, error) {
+	args := m.Called(ctx, userID, jobID)
+	file, _ := args.Get(1).(io.ReadCloser)
+	return args.Get(0).(types.ExportJob), file, args.Error(2)
+}
+
 func (m *mockContactService) CreateContact(ctx context.Context, payload types.ContactCreatePayload, userID uuid.UUID) (types.Contact, error) {
 	args := m.Called(ctx, payload, userID)
 	return args.Get(0).(types.Contact), args.Error(1)
 }
 
-func (m *mockContactService) UpdateContact(ctx context.Context, payload types.ContactUpdatePayload, userID uuid.UUID) (types.Contact, error) {
+// UpdateContact reads the contact with GetContact and merges the update onto it like the
+// service, the update is then matched on the merged payload
+func (m *mockContactService) UpdateContact(ctx context.Context, contactID, userID uuid.UUID, merge func(types.Contact) (types.ContactUpdatePayload, error)) (types.Contact, error) {
+	existing, err := m.GetContact(ctx, contactID, userID, types.ContactExpand{})
+	if err != nil {
+		return types.Contact{}, err
+	}
+	payload, err := merge(existing)
+	if err != nil {
+		return types.Contact{}, err
+	}
 	args := m.Called(ctx, payload, userID)
 	return args.Get(0).(types.Contact), args.Error(1)
 }
 
-func (m *mockContactService) DeleteContact(ctx context.Context, contactID, userID uuid.UUID) error {
-	args := m.Called(ctx, contactID, userID)
+func (m *mockContactService) UpsertContactByExternalRef(ctx context.Context, userID uuid.UUID, ref types.ExternalRef, payload types.ContactUpsertPayload) (types.Contact, bool, error) {
+	args := m.Called(ctx, userID, ref, payload)
+	return args.Get(0).(types.Contact), args.Bool(1), args.Error(2)
+}
+
+func (m *mockContactService) DeleteContact(ctx context.Context, contactID, userID uuid.UUID, version *time.Time) error {
+	args := m.Called(ctx, contactID, userID, version)
 	return args.Error(0)
 }
 
-func (m *mockContactService) SearchContacts(ctx context.Context, userID uuid.UUID, query string, limit int32) ([]types.Contact, error) {
-	args := m.Called(ctx, userID, query, limit)
+func (m *mockContactService) ListDeletedContactsPaginated(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32, order coreTypes.SortOrder) ([]types.Contact, error) {
+	args := m.Called(ctx, userID, cursor, cursorID, limit, order)
+	return args.Get(0).([]types.Contact), args.Error(1)
+}
+
+func (m *mockContactService) RestoreContact(ctx context.Context, contactID, userID uuid.UUID) (types.Contact, error) {
+	args := m.Called(ctx, contactID, userID)
+	return args.Get(0).(types.Contact), args.Error(1)
+}
+
+func (m *mockContactService) SearchContacts(ctx context.Context, userID uuid.UUID, query string, limit, offset int32) ([]types.Contact, error) {
+	args := m.Called(ctx, userID, query, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.Contact), args.Error(1)
+}
+
+func (m *mockContactService) SearchContactsByPhone(ctx context.Context, userID uuid.UUID, phone string, limit, offset int32) ([]types.Contact, error) {
+	args := m.Called(ctx, userID, phone, limit, offset)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]types.Contact), args.Error(1)
 }
 
-func (m *mockContactService) SearchContactsByPhone(ctx context.Context, userID uuid.UUID, phone string, limit int32) ([]types.Contact, error) {
-	args := m.Called(ctx, userID, phone, limit)
+func (m *mockContactService) SearchContactsByCompany(ctx context.Context, userID uuid.UUID, company string, limit, offset int32) ([]types.Contact, error) {
+	args := m.Called(ctx, userID, company, limit, offset)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]types.Contact), args.Error(1)
 }
 
+func (m *mockContactService) SearchContactsPicker(ctx context.Context, userID uuid.UUID, name string, limit, offset int32) ([]types.ContactPickerItem, error) {
+	args := m.Called(ctx, userID, name, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.ContactPickerItem), args.Error(1)
+}
+
+func (m *mockContactService) SearchContactsByPhonePicker(ctx context.Context, userID uuid.UUID, phone string, limit, offset int32) ([]types.ContactPickerItem, error) {
+	args := m.Called(ctx, userID, phone, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.ContactPickerItem), args.Error(1)
+}
+
+func (m *mockContactService) SearchContactsByCompanyPicker(ctx context.Context, userID uuid.UUID, company string, limit, offset int32) ([]types.ContactPickerItem, error) {
+	args := m.Called(ctx, userID, company, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.ContactPickerItem), args.Error(1)
+}
+
+func (m *mockContactService) ListContactCompanies(ctx context.Context, userID uuid.UUID, params types.CompanyListParams) ([]types.CompanyContacts, error) {
+	args := m.Called(ctx, userID, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.CompanyContacts), args.Error(1)
+}
+
+func (m *mockContactService) ListCompanies(ctx context.Context, userID uuid.UUID, limit int32) ([]types.CompanyCount, error) {
+	args := m.Called(ctx, userID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.CompanyCount), args.Error(1)
+}
+
+func (m *mockContactService) ListFacets(ctx context.Context, userID uuid.UUID, field string) ([]coreTypes.Facet, error) {
+	args := m.Called(ctx, userID, field)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]coreTypes.Facet), args.Error(1)
+}
+
+func (m *mockContactService) ImportContacts(ctx context.Context, userID uuid.UUID, contacts []types.ContactCreatePayload) (jobTypes.Job, error) {
+	args := m.Called(ctx, userID, contacts)
+	return args.Get(0).(jobTypes.Job), args.Error(1)
+}
+
+func (m *mockContactService) ValidateContacts(ctx context.Context, userID uuid.UUID, contacts []types.ContactCreatePayload) (types.ContactBatchValidation, error) {
+	args := m.Called(ctx, userID, contacts)
+	return args.Get(0).(types.ContactBatchValidation), args.Error(1)
+}
+
+// testLimits is injected into the handler, its maximums differ from the package
+// defaults (contacts allow bigger pages than the default) so the tests catch limits read from anywhere else
+var testLimits = coreTypes.LimitPolicy{
+	DefaultLimit:       10,
+	MaxLimit:           200,
+	DefaultSearchLimit: 10,
+	MaxSearchLimit:     50,
+	MinTrimmedResults:  2,
+}
+
 func setupTest(t *testing.T) (*mockContactService, *ContactHandler) {
 	mockService := new(mockContactService)
 	logger := zap.NewNop()
-	handler := NewContactHandler(mockService, logger)
+	handler := NewContactHandler(mockService, testLimits, logger)
 	return mockService, handler
 }
 
@@ -192,6 +360,56 @@ func TestContactHandler_CreateContact(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedError:  "phone: invalid phone number format.",
 		},
+		{
+			name: "with links",
+			payload: `{
+				"name": "John Doe",
+				"links": [
+					{"label": "website", "url": "https://john.example.com"},
+					{"label": "other", "url": "http://blog.example.com/john"}
+				]
+			}`,
+			setupAuth: true,
+			setupMock: func() {
+				mockService.On("CreateContact", mock.Anything, mock.MatchedBy(func(p types.ContactCreatePayload) bool {
+					return len(p.Links) == 2 && p.Links[1].Label == types.LinkOther
+				}), userID).Return(types.Contact{ContactID: uuid.New(), Name: "John Doe"}, nil).Once()
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "link with an unknown label",
+			payload: `{
+				"name": "John Doe",
+				"links": [{"label": "myspace", "url": "https://myspace.example.com/john"}]
+			}`,
+			setupAuth:      true,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "links: (0: (label: must be a valid value.).)",
+		},
+		{
+			name: "link that isn't a web URL",
+			payload: `{
+				"name": "John Doe",
+				"links": [{"label": "website", "url": "ftp://john.example.com"}]
+			}`,
+			setupAuth:      true,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "links: (0: (url: must be an absolute http or https URL.).)",
+		},
+		{
+			name: "too many links",
+			payload: fmt.Sprintf(`{
+				"name": "John Doe",
+				"links": [%s]
+			}`, strings.TrimSuffix(strings.Repeat(`{"label": "other", "url": "https://example.com"},`, types.MaxLinksCount+1), ",")),
+			setupAuth:      true,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "links: the length must be no more than 10.",
+		},
 		{
 			name: "address line too long",
 			payload: fmt.Sprintf(`{
@@ -222,6 +440,61 @@ func TestContactHandler_CreateContact(t *testing.T) {
 			expectedStatus: http.StatusUnauthorized,
 			expectedError:  "missing user ID",
 		},
+		{
+			name: "email used by another contact",
+			payload: `{
+				"name": "John Doe",
+				"email": "john@example.com"
+			}`,
+			setupAuth: true,
+			setupMock: func() {
+				mockService.On("CreateContact", mock.Anything, mock.AnythingOfType("types.ContactCreatePayload"), userID).
+					Return(types.Contact{}, coreErrors.NewConflictError("email: already used by another contact."))
+			},
+			expectedStatus: http.StatusConflict,
+			expectedError:  "email: already used by another contact.",
+		},
+		{
+			name: "email with surrounding whitespace",
+			payload: `{
+				"name": "John Doe",
+				"email": "  John@Example.com "
+			}`,
+			setupAuth: true,
+			setupMock: func() {
+				mockService.On("CreateContact", mock.Anything, mock.AnythingOfType("types.ContactCreatePayload"), userID).
+					Return(types.Contact{Name: "John Doe", Email: stringPtr("john@example.com")}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "email domain looks like a typo",
+			payload: `{
+				"name": "John Doe",
+				"email": "john@gamil.com"
+			}`,
+			setupAuth: true,
+			setupMock: func() {
+				mockService.On("CreateContact", mock.Anything, mock.AnythingOfType("types.ContactCreatePayload"), userID).
+					Return(types.Contact{}, coreErrors.NewEmailDomainSuspectError("email", "john@gamil.com", "john@gmail.com"))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "email: john@gamil.com looks like a typo, did you mean john@gmail.com?",
+		},
+		{
+			name: "email domain without a mail server",
+			payload: `{
+				"name": "John Doe",
+				"email": "john@nomail.com"
+			}`,
+			setupAuth: true,
+			setupMock: func() {
+				mockService.On("CreateContact", mock.Anything, mock.AnythingOfType("types.ContactCreatePayload"), userID).
+					Return(types.Contact{}, coreErrors.NewValidationError("email: has a domain that doesn't receive email"))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "email: has a domain that doesn't receive email",
+		},
 		{
 			name: "service error",
 			payload: `{
@@ -275,6 +548,209 @@ func TestContactHandler_CreateContact(t *testing.T) {
 	}
 }
 
+func TestContactHandler_EmailDomainSuspect(t *testing.T) {
+	userID, contactID := uuid.New(), uuid.New()
+	suspect := coreErrors.NewEmailDomainSuspectError("email", "jane@hotmial.com", "jane@hotmail.com")
+
+	// assertSuggestion checks the response carries the suggestion for the client to offer
+	assertSuggestion := func(t *testing.T, w *httptest.ResponseRecorder) {
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.Equal(t, "EMAIL_DOMAIN_SUSPECT", response["type"])
+		assert.Equal(t, "jane@hotmail.com", response["suggestion"])
+		assert.Equal(t, float64(http.StatusBadRequest), response["code"])
+		assert.NotEmpty(t, response["hint"])
+	}
+
+	t.Run("create", func(t *testing.T) {
+		mockService, handler := setupTest(t)
+		mockService.On("CreateContact", mock.Anything, mock.AnythingOfType("types.ContactCreatePayload"), userID).
+			Return(types.Contact{}, suspect)
+
+		req := httptest.NewRequest(http.MethodPost, "/contacts", strings.NewReader(`{"name": "Jane", "email": "jane@hotmial.com"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+		w := httptest.NewRecorder()
+		handler.CreateContact(w, req)
+		assertSuggestion(t, w)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		mockService, handler := setupTest(t)
+		mockService.On("GetContact", mock.Anything, contactID, userID, types.ContactExpand{}).
+			Return(types.Contact{ContactID: contactID, Name: "Jane"}, nil)
+		mockService.On("UpdateContact", mock.Anything, mock.AnythingOfType("types.ContactUpdatePayload"), userID).
+			Return(types.Contact{}, suspect)
+
+		req := httptest.NewRequest(http.MethodPut, "/contacts/"+contactID.String(), strings.NewReader(`{"email": "jane@hotmial.com"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", contactID.String())
+		ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
+		req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+		w := httptest.NewRecorder()
+		handler.UpdateContact(w, req)
+		assertSuggestion(t, w)
+	})
+}
+
+func TestContactHandler_CreateContact_SnakeCase(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+
+	tests := []struct {
+		name           string
+		payload        string
+		expected       func(types.ContactCreatePayload) bool
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "snake_case fields",
+			payload: `{
+				"name": "John Doe",
+				"address_line1": "123 Main St",
+				"state_province": "NY",
+				"zip_postal_code": "10001"
+			}`,
+			expected: func(p types.ContactCreatePayload) bool {
+				return *p.AddressLine1 == "123 Main St" && *p.StateProvince == "NY" && *p.ZipPostalCode == "10001"
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "mixed fields",
+			payload: `{
+				"name": "John Doe",
+				"addressLine1": "123 Main St",
+				"address_line2": "Apt 4B",
+				"stateProvince": "NY",
+				"state_province": "NY"
+			}`,
+			expected: func(p types.ContactCreatePayload) bool {
+				return *p.AddressLine1 == "123 Main St" && *p.AddressLine2 == "Apt 4B" && *p.StateProvince == "NY"
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "conflicting fields",
+			payload: `{
+				"name": "John Doe",
+				"addressLine1": "123 Main St",
+				"address_line1": "456 Main St"
+			}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "address_line1: conflicts with addressLine1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			if tt.expected != nil {
+				mockService.On("CreateContact", mock.Anything, mock.MatchedBy(tt.expected), userID).
+					Return(types.Contact{ContactID: uuid.New(), Name: "John Doe"}, nil)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/contacts", strings.NewReader(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+			req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+
+			w := httptest.NewRecorder()
+			handler.CreateContact(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestContactHandler_CreateContact_AllowUnnamed(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+
+	tests := []struct {
+		name           string
+		query          string
+		payload        string
+		expected       func(types.ContactCreatePayload) bool
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:    "phone only",
+			query:   "?allow_unnamed=true",
+			payload: `{"phone": "+1-555-123-4567"}`,
+			expected: func(p types.ContactCreatePayload) bool {
+				return p.AllowUnnamed && p.Name == "" && *p.Phone == "+1-555-123-4567"
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:    "named contact",
+			query:   "?allow_unnamed=true",
+			payload: `{"name": "John Doe"}`,
+			expected: func(p types.ContactCreatePayload) bool {
+				return p.Name == "John Doe"
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "phone is required",
+			query:          "?allow_unnamed=true",
+			payload:        `{"email": "john@example.com"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "phone: is required for a contact without a name",
+		},
+		{
+			name:           "invalid phone",
+			query:          "?allow_unnamed=true",
+			payload:        `{"phone": "call me"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "phone: invalid phone number format",
+		},
+		{
+			name:           "name is required without the flag",
+			payload:        `{"phone": "+1-555-123-4567"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "name: cannot be blank",
+		},
+		{
+			name:           "the flag can't be set from the body",
+			payload:        `{"phone": "+1-555-123-4567", "allowUnnamed": true}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "name: cannot be blank",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			if tt.expected != nil {
+				mockService.On("CreateContact", mock.Anything, mock.MatchedBy(tt.expected), userID).
+					Return(types.Contact{ContactID: uuid.New(), Name: "Unnamed contact 15551234567"}, nil)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/contacts"+tt.query, strings.NewReader(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+			req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+
+			w := httptest.NewRecorder()
+			handler.CreateContact(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
 func TestContactHandler_GetContact(t *testing.T) {
 	mockService, handler := setupTest(t)
 	userID := uuid.New()
@@ -298,7 +774,7 @@ func TestContactHandler_GetContact(t *testing.T) {
 					Phone:     stringPtr("15551234567"),
 					Tags:      []uuid.UUID{uuid.New()},
 				}
-				mockService.On("GetContact", mock.Anything, contactID, userID).
+				mockService.On("GetContact", mock.Anything, contactID, userID, types.ContactExpand{}).
 					Return(expectedContact, nil)
 			},
 			expectedStatus: http.StatusOK,
@@ -378,12 +854,12 @@ func TestContactHandler_ListContactsPaginated(t *testing.T) {
 					{
 						ContactID: uuid.New(),
 						Name:      "John Doe",
-						CreatedAt: now.Add(-1 * time.Hour),
+						CreatedAt: coreTypes.NewTimestamp(now.Add(-1 * time.Hour)),
 					},
 					{
 						ContactID: uuid.New(),
 						Name:      "Jane Smith",
-						CreatedAt: now.Add(-2 * time.Hour),
+						CreatedAt: coreTypes.NewTimestamp(now.Add(-2 * time.Hour)),
 					},
 				}
 				mockService.On("ListContactsPaginated",
@@ -393,7 +869,9 @@ func TestContactHandler_ListContactsPaginated(t *testing.T) {
 					mock.MatchedBy(func(id *uuid.UUID) bool {
 						return id == nil
 					}),
-					int32(coreTypes.DefaultLimit),
+					testLimits.DefaultLimit,
+					coreTypes.SortOrderDesc,
+					types.ContactFilter{},
 				).Return(contacts, nil)
 			},
 			expectedStatus: http.StatusOK,
@@ -409,7 +887,7 @@ func TestContactHandler_ListContactsPaginated(t *testing.T) {
 					{
 						ContactID: uuid.New(),
 						Name:      "John Doe",
-						CreatedAt: now.Add(-1 * time.Hour),
+						CreatedAt: coreTypes.NewTimestamp(now.Add(-1 * time.Hour)),
 					},
 				}
 				mockService.On("ListContactsPaginated",
@@ -420,6 +898,8 @@ func TestContactHandler_ListContactsPaginated(t *testing.T) {
 						return id == nil
 					}),
 					int32(5),
+					coreTypes.SortOrderDesc,
+					types.ContactFilter{},
 				).Return(contacts, nil)
 			},
 			expectedStatus: http.StatusOK,
@@ -430,19 +910,19 @@ func TestContactHandler_ListContactsPaginated(t *testing.T) {
 			name:      "successful pagination with next_token",
 			setupAuth: true,
 			queryParams: map[string]string{
-				"next_token": coreTypes.EncodeCursor(now, cursorID),
+				"next_token": coreTypes.EncodeCursor(now, cursorID, userID),
 			},
 			setupMock: func() {
 				contacts := []types.Contact{
 					{
 						ContactID: uuid.New(),
 						Name:      "John Doe",
-						CreatedAt: now.Add(-1 * time.Hour),
+						CreatedAt: coreTypes.NewTimestamp(now.Add(-1 * time.Hour)),
 					},
 					{
 						ContactID: uuid.New(),
 						Name:      "Jane Smith",
-						CreatedAt: now.Add(-2 * time.Hour),
+						CreatedAt: coreTypes.NewTimestamp(now.Add(-2 * time.Hour)),
 					},
 				}
 				mockService.On("ListContactsPaginated",
@@ -455,6 +935,8 @@ func TestContactHandler_ListContactsPaginated(t *testing.T) {
 						return *id == cursorID
 					}),
 					int32(10),
+					coreTypes.SortOrderDesc,
+					types.ContactFilter{},
 				).Return(contacts, nil)
 			},
 			expectedStatus: http.StatusOK,
@@ -470,7 +952,9 @@ func TestContactHandler_ListContactsPaginated(t *testing.T) {
 					userID,
 					mock.Anything,
 					mock.Anything,
-					int32(coreTypes.DefaultLimit),
+					testLimits.DefaultLimit,
+					coreTypes.SortOrderDesc,
+					types.ContactFilter{},
 				).Return([]types.Contact{}, nil)
 			},
 			expectedStatus: http.StatusOK,
@@ -510,7 +994,7 @@ func TestContactHandler_ListContactsPaginated(t *testing.T) {
 			name:      "limit above maximum",
 			setupAuth: true,
 			queryParams: map[string]string{
-				"limit": fmt.Sprintf("%d", coreTypes.MaxLimit+1),
+				"limit": fmt.Sprintf("%d", testLimits.MaxLimit+1),
 			},
 			setupMock: func() {
 				mockService.On("ListContactsPaginated",
@@ -518,17 +1002,90 @@ func TestContactHandler_ListContactsPaginated(t *testing.T) {
 					userID,
 					mock.Anything,
 					mock.Anything,
-					int32(coreTypes.MaxLimit),
+					testLimits.MaxLimit,
+					coreTypes.SortOrderDesc,
+					types.ContactFilter{},
 				).Return([]types.Contact{}, nil)
 			},
 			expectedStatus: http.StatusOK,
-			expectedLimit:  fmt.Sprint(coreTypes.MaxLimit),
+			expectedLimit:  fmt.Sprint(testLimits.MaxLimit),
 			expectedLen:    0,
 		},
 		{
-			name:           "missing auth",
-			setupAuth:      false,
-			queryParams:    map[string]string{},
+			name:      "filtered by city and state",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"city":           "  new   york ",
+				"state_province": "NY",
+			},
+			setupMock: func() {
+				mockService.On("ListContactsPaginated",
+					mock.Anything,
+					userID,
+					mock.Anything,
+					mock.Anything,
+					testLimits.DefaultLimit,
+					coreTypes.SortOrderDesc,
+					types.ContactFilter{City: stringPtr("new york"), StateProvince: stringPtr("NY")},
+				).Return([]types.Contact{{ContactID: uuid.New(), Name: "John Doe", City: stringPtr("New York")}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedLen:    1,
+		},
+		{
+			name:      "no contacts in the city",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"city": "Nowhere",
+			},
+			setupMock: func() {
+				mockService.On("ListContactsPaginated",
+					mock.Anything,
+					userID,
+					mock.Anything,
+					mock.Anything,
+					testLimits.DefaultLimit,
+					coreTypes.SortOrderDesc,
+					types.ContactFilter{City: stringPtr("Nowhere")},
+				).Return([]types.Contact{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedLen:    0,
+		},
+		{
+			name:      "blank city doesn't filter",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"city": "   ",
+			},
+			setupMock: func() {
+				mockService.On("ListContactsPaginated",
+					mock.Anything,
+					userID,
+					mock.Anything,
+					mock.Anything,
+					testLimits.DefaultLimit,
+					coreTypes.SortOrderDesc,
+					types.ContactFilter{},
+				).Return([]types.Contact{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedLen:    0,
+		},
+		{
+			name:      "city too long",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"city": strings.Repeat("a", types.MaxAddressLength+1),
+			},
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "city: the length must be between 1 and 255",
+		},
+		{
+			name:           "missing auth",
+			setupAuth:      false,
+			queryParams:    map[string]string{},
 			setupMock:      func() {},
 			expectedStatus: http.StatusUnauthorized,
 			expectedError:  "missing user ID",
@@ -546,6 +1103,8 @@ func TestContactHandler_ListContactsPaginated(t *testing.T) {
 					mock.Anything,
 					mock.Anything,
 					int32(10),
+					coreTypes.SortOrderDesc,
+					types.ContactFilter{},
 				).Return([]types.Contact{}, fmt.Errorf("database error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
@@ -616,6 +1175,212 @@ func TestContactHandler_ListContactsPaginated(t *testing.T) {
 	}
 }
 
+func TestContactHandler_ListContactsPaginated_Stream(t *testing.T) {
+	userID := uuid.New()
+	now := time.Now().UTC()
+	contacts := []types.Contact{
+		{ContactID: uuid.New(), Name: "John Doe", CreatedAt: coreTypes.NewTimestamp(now.Add(-time.Hour))},
+		{ContactID: uuid.New(), Name: "Jane Smith", CreatedAt: coreTypes.NewTimestamp(now.Add(-2 * time.Hour))},
+	}
+	city := "Cairo"
+	list := func(handler *ContactHandler, target string) []map[string]json.RawMessage {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+		w := httptest.NewRecorder()
+		handler.ListContactsPaginated(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-ndjson; charset=utf-8", w.Header().Get("Content-Type"))
+
+		var lines []map[string]json.RawMessage
+		decoder := json.NewDecoder(w.Body)
+		for decoder.More() {
+			var line map[string]json.RawMessage
+			require.NoError(t, decoder.Decode(&line))
+			lines = append(lines, line)
+		}
+		return lines
+	}
+
+	t.Run("partial page has no next token", func(t *testing.T) {
+		mockService, handler := setupTest(t)
+		mockService.On("StreamContactsPaginated", mock.Anything, userID, (*time.Time)(nil), (*uuid.UUID)(nil), int32(5), coreTypes.SortOrderDesc, types.ContactFilter{City: &city}).
+			Return(contacts, nil)
+
+		lines := list(handler, "/contacts?limit=5&city=Cairo&stream=true")
+		require.Len(t, lines, 3)
+		assert.JSONEq(t, `"John Doe"`, string(lines[0]["name"]))
+		assert.JSONEq(t, `"Jane Smith"`, string(lines[1]["name"]))
+		assert.JSONEq(t, `{"count": 2}`, string(lines[2]["meta"]))
+		mockService.AssertNotCalled(t, "ListContactsPaginated")
+	})
+
+	t.Run("full page ends with the next token", func(t *testing.T) {
+		mockService, handler := setupTest(t)
+		mockService.On("StreamContactsPaginated", mock.Anything, userID, (*time.Time)(nil), (*uuid.UUID)(nil), int32(2), coreTypes.SortOrderDesc, types.ContactFilter{}).
+			Return(contacts, nil)
+
+		lines := list(handler, "/contacts?limit=2&stream=true")
+		require.Len(t, lines, 3)
+		var meta struct {
+			Count     int    `json:"count"`
+			NextToken string `json:"next_token"`
+		}
+		require.NoError(t, json.Unmarshal(lines[2]["meta"], &meta))
+		assert.Equal(t, 2, meta.Count)
+		cursor, err := coreTypes.DecodeCursor(meta.NextToken, "", coreTypes.DefaultLimitPolicy())
+		require.NoError(t, err)
+		assert.Equal(t, contacts[1].ContactID, cursor.ID)
+	})
+
+	t.Run("error midway ends with the error line", func(t *testing.T) {
+		mockService, handler := setupTest(t)
+		mockService.On("StreamContactsPaginated", mock.Anything, userID, (*time.Time)(nil), (*uuid.UUID)(nil), int32(2), coreTypes.SortOrderDesc, types.ContactFilter{}).
+			Return(contacts[:1], fmt.Errorf("connection reset"))
+
+		lines := list(handler, "/contacts?limit=2&stream=true")
+		require.Len(t, lines, 2)
+		assert.JSONEq(t, `"John Doe"`, string(lines[0]["name"]))
+		var trailer coreErrors.ErrorResponse
+		require.NoError(t, json.Unmarshal(lines[1]["error"], &trailer))
+		assert.Equal(t, coreErrors.ErrorTypeDatabase, trailer.Type)
+		assert.Equal(t, http.StatusInternalServerError, trailer.Code)
+		assert.NotContains(t, lines[1], "meta")
+	})
+}
+
+func TestContactHandler_ListContactsPaginated_OnlyIDs(t *testing.T) {
+	userID := uuid.New()
+	now := time.Now().UTC()
+	keys := []coreTypes.KeyedID{
+		{ID: uuid.New(), CreatedAt: now.Add(-time.Hour)},
+		{ID: uuid.New(), CreatedAt: now.Add(-2 * time.Hour)},
+	}
+	list := func(handler *ContactHandler, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+		w := httptest.NewRecorder()
+		handler.ListContactsPaginated(w, req)
+		return w
+	}
+	type idPage struct {
+		Data payloads.IDPage `json:"data"`
+		Meta struct {
+			Count     int    `json:"count"`
+			NextToken string `json:"next_token"`
+		} `json:"meta"`
+	}
+
+	t.Run("partial page has no next token", func(t *testing.T) {
+		mockService, handler := setupTest(t)
+		mockService.On("ListContactIDsPaginated", mock.Anything, userID, (*time.Time)(nil), (*uuid.UUID)(nil), int32(5), coreTypes.SortOrderDesc, types.ContactFilter{}).
+			Return(keys, nil)
+
+		w := list(handler, "/contacts?limit=5&only_ids=true")
+		require.Equal(t, http.StatusOK, w.Code)
+		var page idPage
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&page))
+		assert.Equal(t, []uuid.UUID{keys[0].ID, keys[1].ID}, page.Data.IDs)
+		assert.Empty(t, page.Data.NextToken)
+		assert.Empty(t, page.Meta.NextToken)
+		assert.Equal(t, 2, page.Meta.Count)
+		mockService.AssertNotCalled(t, "ListContactsPaginated")
+	})
+
+	t.Run("full page carries the next token", func(t *testing.T) {
+		mockService, handler := setupTest(t)
+		mockService.On("ListContactIDsPaginated", mock.Anything, userID, (*time.Time)(nil), (*uuid.UUID)(nil), int32(2), coreTypes.SortOrderDesc, types.ContactFilter{}).
+			Return(keys, nil)
+
+		w := list(handler, "/contacts?limit=2&only_ids=true")
+		require.Equal(t, http.StatusOK, w.Code)
+		var page idPage
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&page))
+		require.NotEmpty(t, page.Data.NextToken)
+		assert.Equal(t, page.Data.NextToken, page.Meta.NextToken)
+		cursor, err := coreTypes.DecodeCursor(page.Data.NextToken, "", coreTypes.DefaultLimitPolicy())
+		require.NoError(t, err)
+		assert.Equal(t, keys[1].ID, cursor.ID)
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		for name, target := range map[string]string{
+			"not a boolean":  "/contacts?only_ids=yes",
+			"with streaming": "/contacts?only_ids=true&stream=true",
+		} {
+			t.Run(name, func(t *testing.T) {
+				mockService, handler := setupTest(t)
+				w := list(handler, target)
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+				assert.Contains(t, w.Body.String(), "only_ids")
+				mockService.AssertNotCalled(t, "ListContactIDsPaginated")
+			})
+		}
+	})
+}
+
+func TestContactHandler_GetContactsByIDs(t *testing.T) {
+	userID := uuid.New()
+	first, second, foreign := uuid.New(), uuid.New(), uuid.New()
+
+	serve := func(handler *ContactHandler, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/contacts?"+query, nil)
+		req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+		w := httptest.NewRecorder()
+		handler.ListContactsPaginated(w, req)
+		return w
+	}
+
+	t.Run("found in the order asked for and missing in meta", func(t *testing.T) {
+		mockService, handler := setupTest(t)
+		mockService.On("GetContactsByIDs", mock.Anything, userID, []uuid.UUID{second, foreign, first}).
+			Return([]types.Contact{{ContactID: second, Name: "Second"}, {ContactID: first, Name: "First"}}, []uuid.UUID{foreign}, nil)
+
+		// the repeated ID is only fetched once
+		w := serve(handler, "ids="+second.String()+","+foreign.String()+","+first.String()+","+second.String())
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Data []types.Contact `json:"data"`
+			Meta struct {
+				Count   int         `json:"count"`
+				Missing []uuid.UUID `json:"missing"`
+			} `json:"meta"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		require.Len(t, response.Data, 2)
+		assert.Equal(t, second, response.Data[0].ContactID)
+		assert.Equal(t, first, response.Data[1].ContactID)
+		assert.Equal(t, 2, response.Meta.Count)
+		assert.Equal(t, []uuid.UUID{foreign}, response.Meta.Missing)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("at most 100 IDs", func(t *testing.T) {
+		mockService, handler := setupTest(t)
+		ids := make([]string, coreTypes.MaxBatchIDs+1)
+		for i := range ids {
+			ids[i] = uuid.NewString()
+		}
+
+		w := serve(handler, "ids="+strings.Join(ids, ","))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "at most 100 IDs")
+
+		mockService.On("GetContactsByIDs", mock.Anything, userID, mock.Anything).Return([]types.Contact{}, []uuid.UUID{}, nil)
+		w = serve(handler, "ids="+strings.Join(ids[:coreTypes.MaxBatchIDs], ","))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("invalid IDs", func(t *testing.T) {
+		mockService, handler := setupTest(t)
+		for _, query := range []string{"ids=", "ids=not-a-uuid", "ids=" + first.String() + ",nope"} {
+			w := serve(handler, query)
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		}
+		mockService.AssertNotCalled(t, "GetContactsByIDs", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
 func TestContactHandler_SearchContacts(t *testing.T) {
 	mockService, handler := setupTest(t)
 	userID := uuid.New()
@@ -641,7 +1406,7 @@ func TestContactHandler_SearchContacts(t *testing.T) {
 					{ContactID: uuid.New(), Name: "John Doe"},
 					{ContactID: uuid.New(), Name: "Johnny Smith"},
 				}
-				mockService.On("SearchContacts", mock.Anything, userID, "John", int32(20)).
+				mockService.On("SearchContacts", mock.Anything, userID, "John", int32(20), int32(0)).
 					Return(contacts, nil)
 			},
 			expectedStatus: http.StatusOK,
@@ -653,13 +1418,82 @@ func TestContactHandler_SearchContacts(t *testing.T) {
 				assert.Equal(t, "John", meta["query"])
 				assert.Equal(t, float64(20), meta["limit"])
 				assert.Equal(t, float64(2), meta["count"])
+				assert.Equal(t, "full", meta["view"])
+			},
+		},
+		{
+			name:      "picker view by company returns only what a picker shows",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"q":         "John",
+				"company_q": "Acme",
+				"view":      "picker",
+			},
+			setupMock: func() {
+				phone, email := "+1-555-123-4567", "john@acme.com"
+				items := []types.ContactPickerItem{
+					{ContactID: uuid.New(), Name: "John Doe", Phone: &phone, Email: &email},
+				}
+				mockService.On("SearchContactsByCompanyPicker", mock.Anything, userID, "Acme", testLimits.DefaultSearchLimit, int32(0)).
+					Return(items, nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, response map[string]interface{}) {
+				assert.Equal(t, "picker", response["meta"].(map[string]interface{})["view"])
+				data := response["data"].([]interface{})
+				require.Len(t, data, 1)
+				item := data[0].(map[string]interface{})
+				assert.Len(t, item, 4)
+				for _, key := range []string{"contactId", "name", "phone", "email"} {
+					assert.Contains(t, item, key)
+				}
+			},
+		},
+		{
+			name:      "picker view by phone",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"q":        "1555",
+				"by_phone": "true",
+				"view":     "picker",
+			},
+			setupMock: func() {
+				mockService.On("SearchContactsByPhonePicker", mock.Anything, userID, "1555", testLimits.DefaultSearchLimit, int32(0)).
+					Return([]types.ContactPickerItem{{ContactID: uuid.New(), Name: "John Doe"}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, response map[string]interface{}) {
+				assert.Equal(t, "picker", response["meta"].(map[string]interface{})["view"])
+				assert.Len(t, response["data"], 1)
+			},
+		},
+		{
+			name:      "successful search by company",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"q":         "John",
+				"company_q": "Acme",
+			},
+			setupMock: func() {
+				contacts := []types.Contact{
+					{ContactID: uuid.New(), Name: "John Doe", Company: stringPtr("Acme Inc.")},
+				}
+				mockService.On("SearchContactsByCompany", mock.Anything, userID, "Acme", testLimits.DefaultSearchLimit, int32(0)).
+					Return(contacts, nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, response map[string]interface{}) {
+				data := response["data"].([]interface{})
+				assert.Len(t, data, 1)
+				contact := data[0].(map[string]interface{})
+				assert.Equal(t, "Acme Inc.", contact["company"])
 			},
 		},
 		{
 			name:      "successful search by phone",
 			setupAuth: true,
 			queryParams: map[string]string{
-				"q":        "555",
+				"q":        "+1 555",
 				"by_phone": "true",
 				"limit":    "20",
 			},
@@ -667,17 +1501,54 @@ func TestContactHandler_SearchContacts(t *testing.T) {
 				contacts := []types.Contact{
 					{ContactID: uuid.New(), Name: "John Doe", Phone: stringPtr("15551234567")},
 				}
-				mockService.On("SearchContactsByPhone", mock.Anything, userID, "555", int32(20)).
+				mockService.On("SearchContactsByPhone", mock.Anything, userID, "+1 555", int32(20), int32(0)).
 					Return(contacts, nil)
 			},
 			expectedStatus: http.StatusOK,
 			checkResponse: func(t *testing.T, response map[string]interface{}) {
 				metadata := response["meta"].(map[string]interface{})
-				assert.Equal(t, "555", metadata["query"])
+				assert.Equal(t, "+1 555", metadata["query"])
 				assert.Equal(t, float64(20), metadata["limit"])
 				assert.Equal(t, float64(1), metadata["count"])
 			},
 		},
+		{
+			name:      "trim=auto by company cuts the results at the score cliff",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"q":         "John",
+				"company_q": "Acme",
+				"trim":      "auto",
+			},
+			setupMock: func() {
+				scores := []float32{1, 0.97, 0.35, 0.3}
+				contacts := make([]types.Contact, len(scores))
+				for i := range scores {
+					contacts[i] = types.Contact{ContactID: uuid.New(), Name: "John Doe", Company: stringPtr("Acme Inc."), Score: &scores[i]}
+				}
+				mockService.On("SearchContactsByCompany", mock.Anything, userID, "Acme", testLimits.DefaultSearchLimit, int32(0)).
+					Return(contacts, nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, response map[string]interface{}) {
+				assert.Len(t, response["data"], 2)
+				metadata := response["meta"].(map[string]interface{})
+				assert.Equal(t, float64(2), metadata["trimmed"])
+				assert.InDelta(t, 0.97, metadata["cutoff"], 0.001)
+			},
+		},
+		{
+			name:      "trim=auto by phone",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"q":        "1555",
+				"by_phone": "true",
+				"trim":     "auto",
+			},
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "trim: phone searches are not scored",
+		},
 		{
 			name:      "query too long",
 			setupAuth: true,
@@ -704,7 +1575,7 @@ func TestContactHandler_SearchContacts(t *testing.T) {
 				"q": "test",
 			},
 			setupMock: func() {
-				mockService.On("SearchContacts", mock.Anything, userID, "test", int32(coreTypes.DefaultSearchLimit)).
+				mockService.On("SearchContacts", mock.Anything, userID, "test", testLimits.DefaultSearchLimit, int32(0)).
 					Return([]types.Contact(nil), fmt.Errorf("database error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
@@ -720,15 +1591,15 @@ func TestContactHandler_SearchContacts(t *testing.T) {
 					{
 						ContactID: uuid.New(),
 						Name:      "Recent Contact",
-						CreatedAt: time.Now().Add(-1 * time.Hour),
+						CreatedAt: coreTypes.NewTimestamp(time.Now().Add(-1 * time.Hour)),
 					},
 					{
 						ContactID: uuid.New(),
 						Name:      "Older Contact",
-						CreatedAt: time.Now().Add(-2 * time.Hour),
+						CreatedAt: coreTypes.NewTimestamp(time.Now().Add(-2 * time.Hour)),
 					},
 				}
-				mockService.On("SearchContacts", mock.Anything, userID, "", int32(coreTypes.DefaultSearchLimit)).
+				mockService.On("SearchContacts", mock.Anything, userID, "", testLimits.DefaultSearchLimit, int32(0)).
 					Return(contacts, nil)
 			},
 			expectedStatus: http.StatusOK,
@@ -738,7 +1609,7 @@ func TestContactHandler_SearchContacts(t *testing.T) {
 
 				meta := response["meta"].(map[string]interface{})
 				assert.Equal(t, nil, meta["query"])
-				assert.Equal(t, float64(coreTypes.DefaultSearchLimit), meta["limit"])
+				assert.Equal(t, float64(testLimits.DefaultSearchLimit), meta["limit"])
 				assert.Equal(t, float64(2), meta["count"])
 			},
 		},
@@ -753,15 +1624,15 @@ func TestContactHandler_SearchContacts(t *testing.T) {
 					{
 						ContactID: uuid.New(),
 						Name:      "Recent Contact",
-						CreatedAt: time.Now().Add(-1 * time.Hour),
+						CreatedAt: coreTypes.NewTimestamp(time.Now().Add(-1 * time.Hour)),
 					},
 					{
 						ContactID: uuid.New(),
 						Name:      "Older Contact",
-						CreatedAt: time.Now().Add(-2 * time.Hour),
+						CreatedAt: coreTypes.NewTimestamp(time.Now().Add(-2 * time.Hour)),
 					},
 				}
-				mockService.On("SearchContacts", mock.Anything, userID, "", int32(coreTypes.DefaultSearchLimit)).
+				mockService.On("SearchContacts", mock.Anything, userID, "", testLimits.DefaultSearchLimit, int32(0)).
 					Return(contacts, nil)
 			},
 			expectedStatus: http.StatusOK,
@@ -771,7 +1642,7 @@ func TestContactHandler_SearchContacts(t *testing.T) {
 
 				meta := response["meta"].(map[string]interface{})
 				assert.Equal(t, nil, meta["query"])
-				assert.Equal(t, float64(coreTypes.DefaultSearchLimit), meta["limit"])
+				assert.Equal(t, float64(testLimits.DefaultSearchLimit), meta["limit"])
 				assert.Equal(t, float64(2), meta["count"])
 			},
 		},
@@ -783,13 +1654,13 @@ func TestContactHandler_SearchContacts(t *testing.T) {
 				"limit": "1001",
 			},
 			setupMock: func() {
-				mockService.On("SearchContacts", mock.Anything, userID, "John", int32(coreTypes.MaxSearchLimit)).
+				mockService.On("SearchContacts", mock.Anything, userID, "John", testLimits.MaxSearchLimit, int32(0)).
 					Return([]types.Contact{}, nil)
 			},
 			expectedStatus: http.StatusOK,
 			checkResponse: func(t *testing.T, response map[string]interface{}) {
 				meta := response["meta"].(map[string]interface{})
-				assert.Equal(t, float64(coreTypes.MaxSearchLimit), meta["limit"])
+				assert.Equal(t, float64(testLimits.MaxSearchLimit), meta["limit"])
 			},
 		},
 		{
@@ -801,7 +1672,64 @@ func TestContactHandler_SearchContacts(t *testing.T) {
 			},
 			setupMock:      func() {},
 			expectedStatus: http.StatusBadRequest,
-			expectedError:  "query: invalid phone number format.",
+			expectedError:  "query: must be digits, optionally starting with +.",
+		},
+		{
+			name:      "phone search with a plus in the middle",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"q":        "1+555",
+				"by_phone": "true",
+			},
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "query: must be digits, optionally starting with +.",
+		},
+		{
+			name:      "phone search too short",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"q":        "555",
+				"by_phone": "true",
+			},
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "query: must have at least 4 digits.",
+		},
+		{
+			name:      "phone search keeps repeated digits",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"q":        "5550000000",
+				"by_phone": "true",
+			},
+			setupMock: func() {
+				mockService.On("SearchContactsByPhone", mock.Anything, userID, "5550000000", testLimits.DefaultSearchLimit, int32(0)).
+					Return([]types.Contact{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:      "company query too complex",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"q":         "John",
+				"company_q": "zq xj vk wb pf ym gh lt nr cd sa eo iu ba ce di fo gu hy jk lm np qr st vw xz",
+			},
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "query too complex",
+		},
+		{
+			name:      "phone search of a bare country code",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"q":        "+1",
+				"by_phone": "true",
+			},
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "query: must have at least 4 digits.",
 		},
 		{
 			name:      "empty result set",
@@ -810,7 +1738,7 @@ func TestContactHandler_SearchContacts(t *testing.T) {
 				"q": "NonexistentName",
 			},
 			setupMock: func() {
-				mockService.On("SearchContacts", mock.Anything, userID, "NonexistentName", int32(coreTypes.DefaultSearchLimit)).
+				mockService.On("SearchContacts", mock.Anything, userID, "NonexistentName", testLimits.DefaultSearchLimit, int32(0)).
 					Return([]types.Contact{}, nil)
 			},
 			expectedStatus: http.StatusOK,
@@ -871,15 +1799,139 @@ func TestContactHandler_SearchContacts(t *testing.T) {
 	}
 }
 
+func TestContactHandler_SearchContactsWindow(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	const window = int32(30)
+
+	contacts := func(n int) []types.Contact {
+		result := make([]types.Contact, n)
+		for i := range result {
+			result[i] = types.Contact{ContactID: uuid.New(), Name: "John"}
+		}
+		return result
+	}
+
+	tests := []struct {
+		name              string
+		nextToken         string
+		limit             string
+		unbounded         bool
+		setupMock         func()
+		expectedStatus    int
+		expectedError     string
+		expectedNextToken string
+	}{
+		{
+			name:  "full first page links to the next one",
+			limit: "10",
+			setupMock: func() {
+				mockService.On("SearchContacts", mock.Anything, userID, "John", int32(10), int32(0)).Return(contacts(10), nil)
+			},
+			expectedStatus:    http.StatusOK,
+			expectedNextToken: coreTypes.EncodeSearchToken(10),
+		},
+		{
+			name:      "short page ends the results",
+			nextToken: coreTypes.EncodeSearchToken(10),
+			limit:     "10",
+			setupMock: func() {
+				mockService.On("SearchContacts", mock.Anything, userID, "John", int32(10), int32(10)).Return(contacts(4), nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:      "page running past the window is shortened",
+			nextToken: coreTypes.EncodeSearchToken(25),
+			limit:     "10",
+			setupMock: func() {
+				mockService.On("SearchContacts", mock.Anything, userID, "John", int32(5), int32(25)).Return(contacts(5), nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "cursor past the window",
+			nextToken:      coreTypes.EncodeSearchToken(window),
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "search window exceeded, refine your query",
+		},
+		{
+			name:      "zero window leaves the pagination unbounded",
+			nextToken: coreTypes.EncodeSearchToken(1000),
+			limit:     "10",
+			unbounded: true,
+			setupMock: func() {
+				mockService.On("SearchContacts", mock.Anything, userID, "John", int32(10), int32(1000)).Return(contacts(10), nil)
+			},
+			expectedStatus:    http.StatusOK,
+			expectedNextToken: coreTypes.EncodeSearchToken(1010),
+		},
+		{
+			name:           "malformed token",
+			nextToken:      "not-a-token",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid token format",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+
+			query := url.Values{"q": {"John"}}
+			if tt.limit != "" {
+				query.Set("limit", tt.limit)
+			}
+			if tt.nextToken != "" {
+				query.Set("next_token", tt.nextToken)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/contacts/search?"+query.Encode(), nil)
+			ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
+			if tt.unbounded {
+				ctx = context.WithValue(ctx, requestcontext.MaxSearchWindowKey, int32(0))
+			} else {
+				ctx = context.WithValue(ctx, requestcontext.MaxSearchWindowKey, window)
+			}
+			req = req.WithContext(ctx)
+
+			tt.setupMock()
+			w := httptest.NewRecorder()
+			handler.SearchContacts(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var response map[string]interface{}
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+			if tt.expectedError != "" {
+				assert.Contains(t, response["error"], tt.expectedError)
+			} else {
+				meta := response["meta"].(map[string]interface{})
+				if tt.expectedNextToken == "" {
+					assert.NotContains(t, meta, "next_token")
+				} else {
+					assert.Equal(t, tt.expectedNextToken, meta["next_token"])
+				}
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
 func TestContactHandler_DeleteContact(t *testing.T) {
 	mockService, handler := setupTest(t)
 	userID := uuid.New()
 	contactID := uuid.New()
+	updatedAt := time.Date(2025, 3, 4, 10, 0, 0, 123456000, time.UTC)
+	current := types.Contact{ContactID: contactID, Name: "Test Contact", UpdatedAt: coreTypes.NewTimestamp(updatedAt)}
 
 	tests := []struct {
 		name           string
 		contactID      string
 		setupAuth      bool
+		ifMatch        string
 		setupMock      func()
 		expectedStatus int
 	}{
@@ -888,9 +1940,7 @@ func TestContactHandler_DeleteContact(t *testing.T) {
 			contactID: contactID.String(),
 			setupAuth: true,
 			setupMock: func() {
-				mockService.On("GetContact", mock.Anything, contactID, userID).
-					Return(types.Contact{ContactID: contactID}, nil)
-				mockService.On("DeleteContact", mock.Anything, contactID, userID).
+				mockService.On("DeleteContact", mock.Anything, contactID, userID, (*time.Time)(nil)).
 					Return(nil)
 			},
 			expectedStatus: http.StatusOK,
@@ -907,11 +1957,44 @@ func TestContactHandler_DeleteContact(t *testing.T) {
 			contactID: uuid.New().String(),
 			setupAuth: true,
 			setupMock: func() {
-				mockService.On("GetContact", mock.Anything, mock.AnythingOfType("uuid.UUID"), userID).
-					Return(types.Contact{}, fmt.Errorf("not found"))
+				mockService.On("DeleteContact", mock.Anything, mock.AnythingOfType("uuid.UUID"), userID, (*time.Time)(nil)).
+					Return(fmt.Errorf("delete contact: %w", repository.ErrNotFound))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:      "database failure",
+			contactID: contactID.String(),
+			setupAuth: true,
+			setupMock: func() {
+				mockService.On("DeleteContact", mock.Anything, contactID, userID, (*time.Time)(nil)).
+					Return(fmt.Errorf("connection reset"))
 			},
 			expectedStatus: http.StatusInternalServerError,
 		},
+		{
+			name:      "matching If-Match deletes only that version",
+			contactID: contactID.String(),
+			setupAuth: true,
+			ifMatch:   `"1741082400123456"`,
+			setupMock: func() {
+				mockService.On("GetContact", mock.Anything, contactID, userID, types.ContactExpand{}).Return(current, nil)
+				mockService.On("DeleteContact", mock.Anything, contactID, userID, &updatedAt).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:      "contact updated between the read and the delete",
+			contactID: contactID.String(),
+			setupAuth: true,
+			ifMatch:   `"1741082400123456"`,
+			setupMock: func() {
+				mockService.On("GetContact", mock.Anything, contactID, userID, types.ContactExpand{}).Return(current, nil)
+				mockService.On("DeleteContact", mock.Anything, contactID, userID, &updatedAt).
+					Return(fmt.Errorf("delete contact %s: %w", contactID, repository.ErrPreconditionFailed))
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+		},
 		{
 			name:           "missing auth",
 			contactID:      contactID.String(),
@@ -926,6 +2009,9 @@ func TestContactHandler_DeleteContact(t *testing.T) {
 			mockService.ExpectedCalls = nil
 
 			req := httptest.NewRequest(http.MethodDelete, "/contacts/"+tt.contactID, nil)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
 
 			if tt.setupAuth {
 				ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
@@ -957,6 +2043,12 @@ func TestContactHandler_UpdateContact(t *testing.T) {
 	mockService, handler := setupTest(t)
 	userID := uuid.New()
 	contactID := uuid.New()
+	otherID := uuid.New()
+	existingContact := types.Contact{ContactID: contactID, Name: "John Doe"}
+	// the update goes to the contact of the URL
+	ofURL := mock.MatchedBy(func(payload types.ContactUpdatePayload) bool {
+		return payload.ContactID == contactID
+	})
 
 	tests := []struct {
 		name           string
@@ -989,7 +2081,7 @@ func TestContactHandler_UpdateContact(t *testing.T) {
 					Name:      "John Doe",
 					Phone:     stringPtr("15551234567"),
 				}
-				mockService.On("GetContact", mock.Anything, contactID, userID).
+				mockService.On("GetContact", mock.Anything, contactID, userID, types.ContactExpand{}).
 					Return(existingContact, nil)
 
 				updatedContact := types.Contact{
@@ -1020,7 +2112,7 @@ func TestContactHandler_UpdateContact(t *testing.T) {
 					Name:      "John Doe",
 					Phone:     stringPtr("15551234567"),
 				}
-				mockService.On("GetContact", mock.Anything, contactID, userID).
+				mockService.On("GetContact", mock.Anything, contactID, userID, types.ContactExpand{}).
 					Return(existingContact, nil)
 
 				// Should use existing contact data for update
@@ -1041,7 +2133,7 @@ func TestContactHandler_UpdateContact(t *testing.T) {
 					ContactID: contactID,
 					Name:      "John Doe",
 				}
-				mockService.On("GetContact", mock.Anything, contactID, userID).
+				mockService.On("GetContact", mock.Anything, contactID, userID, types.ContactExpand{}).
 					Return(existingContact, nil)
 			},
 			expectedStatus: http.StatusBadRequest,
@@ -1060,7 +2152,7 @@ func TestContactHandler_UpdateContact(t *testing.T) {
 					ContactID: contactID,
 					Name:      "John Doe",
 				}
-				mockService.On("GetContact", mock.Anything, contactID, userID).
+				mockService.On("GetContact", mock.Anything, contactID, userID, types.ContactExpand{}).
 					Return(existingContact, nil)
 			},
 			expectedStatus: http.StatusBadRequest,
@@ -1080,12 +2172,48 @@ func TestContactHandler_UpdateContact(t *testing.T) {
 					ContactID: contactID,
 					Name:      "John Doe",
 				}
-				mockService.On("GetContact", mock.Anything, contactID, userID).
+				mockService.On("GetContact", mock.Anything, contactID, userID, types.ContactExpand{}).
 					Return(existingContact, nil)
 			},
 			expectedStatus: http.StatusBadRequest,
 			expectedError:  "tags: the length must be no more than 10.",
 		},
+		{
+			name:      "matching body ID",
+			contactID: contactID.String(),
+			payload:   fmt.Sprintf(`{"contactId": %q, "name": "John Doe Updated"}`, contactID),
+			setupAuth: true,
+			setupMock: func() {
+				mockService.On("GetContact", mock.Anything, contactID, userID, types.ContactExpand{}).
+					Return(existingContact, nil)
+				mockService.On("UpdateContact", mock.Anything, ofURL, userID).Return(existingContact, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:      "empty body ID",
+			contactID: contactID.String(),
+			payload:   `{"contactId": "", "name": "John Doe Updated"}`,
+			setupAuth: true,
+			setupMock: func() {
+				mockService.On("GetContact", mock.Anything, contactID, userID, types.ContactExpand{}).
+					Return(existingContact, nil)
+				mockService.On("UpdateContact", mock.Anything, ofURL, userID).Return(existingContact, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:      "conflicting body ID",
+			contactID: contactID.String(),
+			payload:   fmt.Sprintf(`{"contact_id": %q, "name": "John Doe Updated"}`, otherID),
+			setupAuth: true,
+			setupMock: func() {
+				mockService.On("GetContact", mock.Anything, contactID, userID, types.ContactExpand{}).
+					Return(existingContact, nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  fmt.Sprintf("contactId: %s doesn't match the ID %s of the URL", otherID, contactID),
+		},
 		{
 			name:      "invalid contact ID",
 			contactID: "not-a-uuid",
@@ -1105,7 +2233,7 @@ func TestContactHandler_UpdateContact(t *testing.T) {
 			}`,
 			setupAuth: true,
 			setupMock: func() {
-				mockService.On("GetContact", mock.Anything, mock.AnythingOfType("uuid.UUID"), userID).
+				mockService.On("GetContact", mock.Anything, mock.AnythingOfType("uuid.UUID"), userID, types.ContactExpand{}).
 					Return(types.Contact{}, fmt.Errorf("not found"))
 			},
 			expectedStatus: http.StatusInternalServerError,
@@ -1122,7 +2250,7 @@ func TestContactHandler_UpdateContact(t *testing.T) {
 					ContactID: contactID,
 					Name:      "John Doe",
 				}
-				mockService.On("GetContact", mock.Anything, contactID, userID).
+				mockService.On("GetContact", mock.Anything, contactID, userID, types.ContactExpand{}).
 					Return(existingContact, nil)
 				mockService.On("UpdateContact", mock.Anything, mock.AnythingOfType("types.ContactUpdatePayload"), userID).
 					Return(types.Contact{}, fmt.Errorf("database error"))
@@ -1130,6 +2258,20 @@ func TestContactHandler_UpdateContact(t *testing.T) {
 			expectedStatus: http.StatusInternalServerError,
 			expectedError:  "database error",
 		},
+		{
+			name:      "lock timed out",
+			contactID: contactID.String(),
+			payload:   `{"name": "John Doe"}`,
+			setupAuth: true,
+			setupMock: func() {
+				mockService.On("GetContact", mock.Anything, contactID, userID, types.ContactExpand{}).
+					Return(existingContact, nil)
+				mockService.On("UpdateContact", mock.Anything, ofURL, userID).
+					Return(types.Contact{}, coreErrors.HandleRepositoryError(&pgconn.PgError{Code: coreErrors.LockNotAvailableCode}, "update", "contact"))
+			},
+			expectedStatus: http.StatusConflict,
+			expectedError:  "modified concurrently",
+		},
 		{
 			name:           "missing auth",
 			contactID:      contactID.String(),
@@ -1183,3 +2325,1307 @@ func TestContactHandler_UpdateContact(t *testing.T) {
 		})
 	}
 }
+
+func TestContactHandler_UpsertContactByExternalRef(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	contact := types.Contact{
+		ContactID:   uuid.New(),
+		Name:        "John Doe",
+		ExternalRef: &types.ExternalRef{Source: "hubspot", ExternalID: "5012"},
+	}
+	ref := types.ExternalRef{Source: "hubspot", ExternalID: "5012"}
+
+	tests := []struct {
+		name           string
+		source         string
+		externalID     string
+		payload        string
+		setupMock      func()
+		expectedStatus int
+	}{
+		{
+			name:       "creates the contact",
+			source:     "hubspot",
+			externalID: "5012",
+			payload:    `{"name": "John Doe"}`,
+			setupMock: func() {
+				mockService.On("UpsertContactByExternalRef", mock.Anything, userID, ref, types.ContactUpsertPayload{Name: stringPtr("John Doe")}).
+					Return(contact, true, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:       "updates the contact",
+			source:     "hubspot",
+			externalID: "5012",
+			payload:    `{"phone": "+1-555-123-4567"}`,
+			setupMock: func() {
+				mockService.On("UpsertContactByExternalRef", mock.Anything, userID, ref, types.ContactUpsertPayload{Phone: stringPtr("+1-555-123-4567")}).
+					Return(contact, false, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid source",
+			source:         "Hub Spot",
+			externalID:     "5012",
+			payload:        `{"name": "John Doe"}`,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "external ID too long",
+			source:         "hubspot",
+			externalID:     strings.Repeat("a", types.MaxExternalIDLength+1),
+			payload:        `{"name": "John Doe"}`,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid email",
+			source:         "hubspot",
+			externalID:     "5012",
+			payload:        `{"email": "not-an-email"}`,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "service error",
+			source:     "hubspot",
+			externalID: "5012",
+			payload:    `{}`,
+			setupMock: func() {
+				mockService.On("UpsertContactByExternalRef", mock.Anything, userID, ref, types.ContactUpsertPayload{}).
+					Return(types.Contact{}, false, coreErrors.NewValidationError("name: is required to create the contact of hubspot/5012."))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			req := httptest.NewRequest(http.MethodPut, "/contacts/external/"+url.PathEscape(tt.source)+"/"+url.PathEscape(tt.externalID), strings.NewReader(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+			ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("source", tt.source)
+			rctx.URLParams.Add("external_id", tt.externalID)
+			req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+
+			tt.setupMock()
+			w := httptest.NewRecorder()
+			handler.UpsertContactByExternalRef(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus < http.StatusBadRequest {
+				var response struct {
+					Data types.Contact `json:"data"`
+				}
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				assert.Equal(t, contact.ContactID, response.Data.ContactID)
+				require.NotNil(t, response.Data.ExternalRef)
+				assert.Equal(t, ref, *response.Data.ExternalRef)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestContactHandler_ListContactCompanies(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+
+	tests := []struct {
+		name           string
+		setupAuth      bool
+		query          string
+		setupMock      func()
+		expectedStatus int
+		checkResponse  func(t *testing.T, response map[string]interface{})
+	}{
+		{
+			name:      "default parameters",
+			setupAuth: true,
+			setupMock: func() {
+				companies := []types.CompanyContacts{
+					{
+						Company:      "Acme Inc.",
+						ContactCount: 5,
+						Contacts: []types.ContactSummary{
+							{ContactID: uuid.New(), Name: "Jane Doe"},
+							{ContactID: uuid.New(), Name: "John Doe"},
+						},
+					},
+				}
+				mockService.On("ListContactCompanies", mock.Anything, userID, types.CompanyListParams{
+					SortBy:        types.CompanySortByCount,
+					Limit:         types.DefaultCompaniesLimit,
+					ContactsLimit: types.DefaultCompanyContactsLimit,
+				}).Return(companies, nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, response map[string]interface{}) {
+				data := response["data"].([]interface{})
+				assert.Len(t, data, 1)
+				company := data[0].(map[string]interface{})
+				assert.Equal(t, "Acme Inc.", company["company"])
+				assert.Equal(t, float64(5), company["contactCount"])
+				assert.Len(t, company["contacts"], 2)
+			},
+		},
+		{
+			name:      "sort by name with capped limits",
+			setupAuth: true,
+			query:     "?sort=name&limit=500&contacts_limit=50",
+			setupMock: func() {
+				mockService.On("ListContactCompanies", mock.Anything, userID, types.CompanyListParams{
+					SortBy:        types.CompanySortByName,
+					Limit:         types.MaxCompaniesLimit,
+					ContactsLimit: types.MaxCompanyContactsLimit,
+				}).Return([]types.CompanyContacts{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid sort",
+			setupAuth:      true,
+			query:          "?sort=size",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid limit",
+			setupAuth:      true,
+			query:          "?limit=abc",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing auth",
+			setupAuth:      false,
+			setupMock:      func() {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+
+			req := httptest.NewRequest(http.MethodGet, "/contacts/by-company"+tt.query, nil)
+			if tt.setupAuth {
+				ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
+				req = req.WithContext(ctx)
+			}
+
+			tt.setupMock()
+			w := httptest.NewRecorder()
+			handler.ListContactCompanies(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response map[string]interface{}
+				err := json.NewDecoder(w.Body).Decode(&response)
+				assert.NoError(t, err)
+				if tt.checkResponse != nil {
+					tt.checkResponse(t, response)
+				}
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestContactHandler_ListCompanies(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+
+	tests := []struct {
+		name           string
+		query          string
+		setupAuth      bool
+		setupMock      func()
+		expectedStatus int
+		checkResponse  func(t *testing.T, response map[string]interface{})
+	}{
+		{
+			name:      "distinct companies with counts",
+			setupAuth: true,
+			setupMock: func() {
+				mockService.On("ListCompanies", mock.Anything, userID, int32(types.DefaultCompaniesLimit)).Return([]types.CompanyCount{
+					{Company: "Acme Inc.", ContactCount: 3},
+					{Company: "Globex", ContactCount: 1},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, response map[string]interface{}) {
+				data := response["data"].([]interface{})
+				assert.Len(t, data, 2)
+				company := data[0].(map[string]interface{})
+				assert.Equal(t, "Acme Inc.", company["company"])
+				assert.Equal(t, float64(3), company["contactCount"])
+			},
+		},
+		{
+			name:      "no companies",
+			setupAuth: true,
+			setupMock: func() {
+				mockService.On("ListCompanies", mock.Anything, userID, int32(types.DefaultCompaniesLimit)).Return([]types.CompanyCount{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, response map[string]interface{}) {
+				assert.Empty(t, response["data"])
+			},
+		},
+		{
+			name:      "service error",
+			setupAuth: true,
+			setupMock: func() {
+				mockService.On("ListCompanies", mock.Anything, userID, int32(types.DefaultCompaniesLimit)).Return(nil, fmt.Errorf("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:      "limit is capped",
+			query:     "?limit=500",
+			setupAuth: true,
+			setupMock: func() {
+				mockService.On("ListCompanies", mock.Anything, userID, int32(types.MaxCompaniesLimit)).Return([]types.CompanyCount{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid limit",
+			query:          "?limit=-1",
+			setupAuth:      true,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "unknown parameters don't change the order",
+			query:     "?sort=count",
+			setupAuth: true,
+			setupMock: func() {
+				mockService.On("ListCompanies", mock.Anything, userID, int32(types.DefaultCompaniesLimit)).Return([]types.CompanyCount{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing auth",
+			setupAuth:      false,
+			setupMock:      func() {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+
+			req := httptest.NewRequest(http.MethodGet, "/contacts/companies"+tt.query, nil)
+			if tt.setupAuth {
+				ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
+				req = req.WithContext(ctx)
+			}
+
+			tt.setupMock()
+			w := httptest.NewRecorder()
+			handler.ListCompanies(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.checkResponse != nil {
+				var response map[string]interface{}
+				err := json.NewDecoder(w.Body).Decode(&response)
+				assert.NoError(t, err)
+				tt.checkResponse(t, response)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestContactHandler_ListContactFacets(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func()
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:  "country values with counts",
+			query: "?field=country",
+			setupMock: func() {
+				mockService.On("ListFacets", mock.Anything, userID, "country").Return([]coreTypes.Facet{
+					{Value: "US", Count: 4},
+					{Value: "DE", Count: 1},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "field not facetable",
+			query:          "?field=email",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  `field: "email" cannot be faceted (allowed: country, city, company)`,
+		},
+		{
+			name:           "missing field",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "field: cannot be blank",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+
+			req := httptest.NewRequest(http.MethodGet, "/contacts/facets"+tt.query, nil)
+			req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+
+			tt.setupMock()
+			w := httptest.NewRecorder()
+			handler.ListContactFacets(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var response map[string]interface{}
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+			if tt.expectedError != "" {
+				assert.Contains(t, response["error"], tt.expectedError)
+			} else {
+				data := response["data"].([]interface{})
+				require.Len(t, data, 2)
+				assert.Equal(t, map[string]interface{}{"value": "US", "count": float64(4)}, data[0])
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestContactHandler_QueryParamsModes(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	searchAllowed := "(allowed: q, company_q, by_phone, limit, next_token, view, trim)"
+
+	tests := []struct {
+		name             string
+		mode             coreTypes.QueryParamsMode
+		path             string
+		handle           http.HandlerFunc
+		setupMock        func()
+		expectedStatus   int
+		expectedError    string
+		expectedWarnings []interface{}
+	}{
+		{
+			name:   "unknown param warned about in warn mode",
+			mode:   coreTypes.QueryParamsWarn,
+			path:   "/contacts?limt=5",
+			handle: handler.ListContactsPaginated,
+			setupMock: func() {
+				mockService.On("ListContactsPaginated", mock.Anything, userID, mock.Anything, mock.Anything,
+					testLimits.DefaultLimit, coreTypes.SortOrderDesc, types.ContactFilter{}).Return([]types.Contact{}, nil)
+			},
+			expectedStatus:   http.StatusOK,
+			expectedWarnings: []interface{}{"unknown query parameter: limt (allowed: city, state_province, limit, order, next_token, stream, only_ids)"},
+		},
+		{
+			name:           "unknown param rejected when strict",
+			mode:           coreTypes.QueryParamsStrict,
+			path:           "/contacts?limt=5",
+			handle:         handler.ListContactsPaginated,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "unknown query parameter: limt (allowed: city, state_province, limit, order, next_token, stream, only_ids)",
+		},
+		{
+			name:   "known params accepted when strict",
+			mode:   coreTypes.QueryParamsStrict,
+			path:   "/contacts?limit=5&order=asc",
+			handle: handler.ListContactsPaginated,
+			setupMock: func() {
+				mockService.On("ListContactsPaginated", mock.Anything, userID, mock.Anything, mock.Anything,
+					int32(5), coreTypes.SortOrderAsc, types.ContactFilter{}).Return([]types.Contact{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "all unknown search params named",
+			mode:           coreTypes.QueryParamsStrict,
+			path:           "/contacts/search?q=john&serach=foo&nexttoken=abc",
+			handle:         handler.SearchContacts,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "unknown query parameters: nexttoken, serach " + searchAllowed,
+		},
+		{
+			name:   "repeated unknown param named once",
+			mode:   coreTypes.QueryParamsWarn,
+			path:   "/contacts/search?q=john&q=jane&tag=a&tag=b",
+			handle: handler.SearchContacts,
+			setupMock: func() {
+				mockService.On("SearchContacts", mock.Anything, userID, "john", testLimits.DefaultSearchLimit, int32(0)).Return([]types.Contact{}, nil)
+			},
+			expectedStatus:   http.StatusOK,
+			expectedWarnings: []interface{}{"unknown query parameter: tag " + searchAllowed},
+		},
+		{
+			name:   "phone search params accepted when strict",
+			mode:   coreTypes.QueryParamsStrict,
+			path:   "/contacts/search?q=5551234567&by_phone=true&limit=5&next_token=" + url.QueryEscape(coreTypes.EncodeSearchToken(5)),
+			handle: handler.SearchContacts,
+			setupMock: func() {
+				mockService.On("SearchContactsByPhone", mock.Anything, userID, "5551234567", int32(5), int32(5)).Return([]types.Contact{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "company search params accepted when strict",
+			mode:   coreTypes.QueryParamsStrict,
+			path:   "/contacts/search?q=acme&company_q=acme&limit=5",
+			handle: handler.SearchContacts,
+			setupMock: func() {
+				mockService.On("SearchContactsByCompany", mock.Anything, userID, "acme", int32(5), int32(0)).Return([]types.Contact{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
+			ctx = context.WithValue(ctx, requestcontext.QueryParamsModeKey, string(tt.mode))
+			req = req.WithContext(requestcontext.WithWarnings(ctx))
+
+			tt.setupMock()
+			w := httptest.NewRecorder()
+			tt.handle(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var response map[string]interface{}
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+			if tt.expectedError != "" {
+				assert.Equal(t, tt.expectedError, response["error"])
+			} else {
+				meta := response["meta"].(map[string]interface{})
+				if tt.expectedWarnings == nil {
+					assert.NotContains(t, meta, "warnings")
+				} else {
+					assert.Equal(t, tt.expectedWarnings, meta["warnings"])
+				}
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestContactHandler_ImportContacts(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	jobID := uuid.New()
+
+	tests := []struct {
+		name           string
+		setupAuth      bool
+		body           string
+		setupMock      func()
+		expectedStatus int
+	}{
+		{
+			name:      "accepted",
+			setupAuth: true,
+			body:      `{"contacts":[{"name":"Jane Doe"},{"name":""}]}`,
+			setupMock: func() {
+				mockService.On("ImportContacts", mock.Anything, userID, []types.ContactCreatePayload{{Name: "Jane Doe"}, {Name: ""}}).
+					Return(jobTypes.Job{JobID: jobID, Status: jobTypes.JobStatusPending, Total: 2}, nil)
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "empty import",
+			setupAuth:      true,
+			body:           `{"contacts":[]}`,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid body",
+			setupAuth:      true,
+			body:           `{"contacts":`,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing auth",
+			body:           `{"contacts":[{"name":"Jane Doe"}]}`,
+			setupMock:      func() {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+
+			req := httptest.NewRequest(http.MethodPost, "/contacts/import", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.setupAuth {
+				ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
+				req = req.WithContext(ctx)
+			}
+
+			tt.setupMock()
+			w := httptest.NewRecorder()
+			handler.ImportContacts(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusAccepted {
+				var response map[string]interface{}
+				err := json.NewDecoder(w.Body).Decode(&response)
+				assert.NoError(t, err)
+				data := response["data"].(map[string]interface{})
+				assert.Equal(t, jobID.String(), data["jobId"])
+				assert.Equal(t, "pending", data["status"])
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestContactHandler_ValidateContacts(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+
+	tests := []struct {
+		name           string
+		setupAuth      bool
+		body           string
+		setupMock      func()
+		expectedStatus int
+	}{
+		{
+			name:      "per row results",
+			setupAuth: true,
+			body:      `{"contacts":[{"name":"Jane Doe"},{"name":""}]}`,
+			setupMock: func() {
+				mockService.On("ValidateContacts", mock.Anything, userID, []types.ContactCreatePayload{{Name: "Jane Doe"}, {Name: ""}}).
+					Return(types.ContactBatchValidation{
+						Valid:   1,
+						Invalid: 1,
+						Results: []types.ContactValidationResult{
+							{Index: 0, Valid: true},
+							{Index: 1, Error: "name: cannot be blank."},
+						},
+					}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "empty batch",
+			setupAuth:      true,
+			body:           `{"contacts":[]}`,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing auth",
+			body:           `{"contacts":[{"name":"Jane Doe"}]}`,
+			setupMock:      func() {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+
+			req := httptest.NewRequest(http.MethodPost, "/contacts/validate-batch", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.setupAuth {
+				ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
+				req = req.WithContext(ctx)
+			}
+
+			tt.setupMock()
+			w := httptest.NewRecorder()
+			handler.ValidateContacts(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response map[string]interface{}
+				err := json.NewDecoder(w.Body).Decode(&response)
+				assert.NoError(t, err)
+				data := response["data"].(map[string]interface{})
+				assert.Equal(t, float64(1), data["valid"])
+				assert.Equal(t, float64(1), data["invalid"])
+				results := data["results"].([]interface{})
+				assert.Len(t, results, 2)
+				assert.Equal(t, "name: cannot be blank.", results[1].(map[string]interface{})["error"])
+				assert.NotContains(t, results[0], "error")
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestContactHandler_ListDeletedContacts(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	deletedAt := time.Now().Add(-time.Hour).UTC()
+	cursorID := uuid.New()
+	cursorToken := coreTypes.EncodeOrderedCursor(deletedAt, cursorID, coreTypes.SortOrderAsc, userID)
+
+	tests := []struct {
+		name            string
+		setupAuth       bool
+		query           string
+		setupMock       func()
+		expectedStatus  int
+		expectNextToken bool
+	}{
+		{
+			name:      "first page",
+			setupAuth: true,
+			query:     "?limit=1",
+			setupMock: func() {
+				mockService.On("ListDeletedContactsPaginated", mock.Anything, userID,
+					(*time.Time)(nil), (*uuid.UUID)(nil), int32(1), coreTypes.SortOrderDesc).
+					Return([]types.Contact{{ContactID: uuid.New(), Name: "John Doe", DeletedAt: coreTypes.TimestampPtr(&deletedAt)}}, nil)
+			},
+			expectedStatus:  http.StatusOK,
+			expectNextToken: true,
+		},
+		{
+			name:      "next page keeps the token's order",
+			setupAuth: true,
+			query:     "?next_token=" + url.QueryEscape(cursorToken),
+			setupMock: func() {
+				mockService.On("ListDeletedContactsPaginated", mock.Anything, userID,
+					mock.MatchedBy(func(cursor *time.Time) bool { return cursor != nil && cursor.Equal(deletedAt) }),
+					&cursorID, testLimits.DefaultLimit, coreTypes.SortOrderAsc).
+					Return([]types.Contact{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid limit",
+			setupAuth:      true,
+			query:          "?limit=abc",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing auth",
+			setupAuth:      false,
+			setupMock:      func() {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+
+			req := httptest.NewRequest(http.MethodGet, "/contacts/trash"+tt.query, nil)
+			if tt.setupAuth {
+				ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
+				req = req.WithContext(ctx)
+			}
+
+			tt.setupMock()
+			w := httptest.NewRecorder()
+			handler.ListDeletedContacts(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response map[string]interface{}
+				err := json.NewDecoder(w.Body).Decode(&response)
+				assert.NoError(t, err)
+
+				meta := response["meta"].(map[string]interface{})
+				if tt.expectNextToken {
+					assert.NotEmpty(t, meta["next_token"])
+				} else {
+					assert.Empty(t, meta["next_token"])
+				}
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestContactHandler_RestoreContact(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	contactID := uuid.New()
+
+	tests := []struct {
+		name           string
+		setupAuth      bool
+		contactID      string
+		setupMock      func()
+		expectedStatus int
+	}{
+		{
+			name:      "successful restore",
+			setupAuth: true,
+			contactID: contactID.String(),
+			setupMock: func() {
+				mockService.On("RestoreContact", mock.Anything, contactID, userID).
+					Return(types.Contact{ContactID: contactID, Name: "John Doe"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:      "not in trash",
+			setupAuth: true,
+			contactID: contactID.String(),
+			setupMock: func() {
+				mockService.On("RestoreContact", mock.Anything, contactID, userID).
+					Return(types.Contact{}, &coreErrors.ErrorResponse{Type: coreErrors.ErrorTypeNotFound, Message: "contact not found"})
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "invalid contact ID",
+			setupAuth:      true,
+			contactID:      "invalid-uuid",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing auth",
+			setupAuth:      false,
+			contactID:      contactID.String(),
+			setupMock:      func() {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+
+			req := httptest.NewRequest(http.MethodPost, "/contacts/"+tt.contactID+"/restore", nil)
+			if tt.setupAuth {
+				ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
+				req = req.WithContext(ctx)
+			}
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.contactID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			tt.setupMock()
+			w := httptest.NewRecorder()
+			handler.RestoreContact(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response map[string]interface{}
+				err := json.NewDecoder(w.Body).Decode(&response)
+				assert.NoError(t, err)
+				assert.Equal(t, float64(http.StatusOK), response["status"])
+				assert.NotNil(t, response["data"])
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestContactHandler_ExportContacts(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	company := "Acme"
+	contacts := []types.Contact{
+		{ContactID: uuid.New(), Name: "Jane, Doe", Company: &company, Tags: []uuid.UUID{uuid.New(), uuid.New()}},
+		{ContactID: uuid.New(), Name: "John Doe"},
+	}
+
+	tests := []struct {
+		name           string
+		rows           []types.Contact
+		err            error
+		expectedStatus int
+		expectedRows   int
+	}{
+		{
+			name:           "streams every contact",
+			rows:           contacts,
+			expectedStatus: http.StatusOK,
+			expectedRows:   2,
+		},
+		{
+			name:           "header only without contacts",
+			rows:           []types.Contact{},
+			expectedStatus: http.StatusOK,
+			expectedRows:   0,
+		},
+		{
+			name:           "error before the first row",
+			rows:           []types.Contact{},
+			err:            &coreErrors.ErrorResponse{Type: coreErrors.ErrorTypeDatabase, Message: "Failed to list contacts"},
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "error after rows were sent cuts the body short",
+			rows:           contacts[:1],
+			err:            &coreErrors.ErrorResponse{Type: coreErrors.ErrorTypeDatabase, Message: "Failed to list contacts"},
+			expectedStatus: http.StatusOK,
+			expectedRows:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.On("ExportContacts", mock.Anything, userID).Return(tt.rows, tt.err)
+
+			req := httptest.NewRequest(http.MethodGet, "/contacts/export", nil)
+			req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+			w := httptest.NewRecorder()
+			handler.ExportContacts(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+			if tt.expectedStatus != http.StatusOK {
+				assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+				return
+			}
+
+			assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+			records, err := csv.NewReader(w.Body).ReadAll()
+			assert.NoError(t, err)
+			assert.Len(t, records, tt.expectedRows+1)
+			assert.Equal(t, types.CSVHeader, records[0])
+			for i, record := range records[1:] {
+				assert.Equal(t, tt.rows[i].CSVRecord(), record)
+			}
+		})
+	}
+
+	t.Run("csv record", func(t *testing.T) {
+		record := contacts[0].CSVRecord()
+		assert.Len(t, record, len(types.CSVHeader))
+		assert.Equal(t, "Jane, Doe", record[1])
+		assert.Equal(t, "Acme", record[2])
+		assert.Equal(t, "", record[3])
+		assert.Equal(t, contacts[0].Tags[0].String()+";"+contacts[0].Tags[1].String(), record[11])
+	})
+}
+
+func TestContactHandler_ExportContacts_Formats(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	company := "Acme; Sons"
+	contacts := []types.Contact{
+		{ContactID: uuid.New(), Name: "Jane Doe", Company: &company},
+		{ContactID: uuid.New(), Name: "John Doe"},
+	}
+
+	tests := []struct {
+		name                string
+		target              string
+		accept              string
+		expectedStatus      int
+		expectedContentType string
+		check               func(t *testing.T, body string)
+	}{
+		{
+			name:                "json through the accept header",
+			target:              "/contacts/export",
+			accept:              "application/json",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json; charset=utf-8",
+			check: func(t *testing.T, body string) {
+				var exported []types.Contact
+				require.NoError(t, json.Unmarshal([]byte(body), &exported))
+				require.Len(t, exported, 2)
+				assert.Equal(t, contacts[0].ContactID, exported[0].ContactID)
+				assert.Equal(t, "John Doe", exported[1].Name)
+			},
+		},
+		{
+			name:                "vcard through the accept header",
+			target:              "/contacts/export",
+			accept:              "text/vcard",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/vcard; charset=utf-8",
+			check: func(t *testing.T, body string) {
+				assert.Equal(t, 2, strings.Count(body, "BEGIN:VCARD\r\n"))
+				assert.Contains(t, body, "UID:urn:uuid:"+contacts[0].ContactID.String()+"\r\n")
+				assert.Contains(t, body, "FN:Jane Doe\r\nORG:Acme\\; Sons\r\n")
+			},
+		},
+		{
+			name:                "format parameter overrides the accept header",
+			target:              "/contacts/export?format=json",
+			accept:              "text/csv",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json; charset=utf-8",
+		},
+		{
+			name:                "csv for generic accept headers",
+			target:              "/contacts/export",
+			accept:              "text/html, */*;q=0.8",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+		},
+		{
+			name:           "unsupported accept header",
+			target:         "/contacts/export",
+			accept:         "application/xml",
+			expectedStatus: http.StatusNotAcceptable,
+		},
+		{
+			name:           "unsupported format parameter",
+			target:         "/contacts/export?format=xml",
+			expectedStatus: http.StatusNotAcceptable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			if tt.expectedStatus == http.StatusOK {
+				mockService.On("ExportContacts", mock.Anything, userID).Return(contacts, nil)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			req.Header.Set("Accept", tt.accept)
+			req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+			w := httptest.NewRecorder()
+			handler.ExportContacts(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+			if tt.expectedContentType != "" {
+				assert.Equal(t, tt.expectedContentType, w.Header().Get("Content-Type"))
+			}
+			if tt.check != nil {
+				tt.check(t, w.Body.String())
+			}
+		})
+	}
+
+	t.Run("vcard folds long lines", func(t *testing.T) {
+		notes := strings.Repeat("é", 60)
+		card := types.Contact{ContactID: uuid.New(), Name: "Jane Doe", Notes: &notes}.VCard()
+		for _, line := range strings.Split(strings.TrimSuffix(card, "\r\n"), "\r\n") {
+			assert.LessOrEqual(t, len(line), 75)
+			assert.True(t, utf8.ValidString(line))
+		}
+		assert.Contains(t, strings.ReplaceAll(card, "\r\n ", ""), "NOTE:"+notes+"\r\n")
+	})
+	t.Run("vcard lists the links", func(t *testing.T) {
+		card := types.Contact{ContactID: uuid.New(), Name: "Jane Doe", Links: []types.ContactLink{
+			{Label: types.LinkWebsite, URL: "https://jane.example.com/a,b"},
+			{Label: types.LinkGitHub, URL: "https://github.com/jane"},
+		}}.VCard()
+		assert.Contains(t, card, "URL;TYPE=website:https://jane.example.com/a,b\r\n")
+		assert.Contains(t, card, "URL;TYPE=github:https://github.com/jane\r\n")
+	})
+}
+
+func TestContactHandler_ContactExportJobs(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	jobID := uuid.New()
+
+	request := func(method, target string) *http.Request {
+		req := httptest.NewRequest(method, target, nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", jobID.String())
+		ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
+		return req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+	}
+
+	t.Run("start defaults to CSV", func(t *testing.T) {
+		mockService.ExpectedCalls = nil
+		job := types.ExportJob{Job: jobTypes.Job{JobID: jobID, Status: jobTypes.JobStatusPending}, Format: types.ExportFormatCSV}
+		mockService.On("StartContactExport", mock.Anything, userID, types.ExportFormatCSV).Return(job, nil)
+
+		w := httptest.NewRecorder()
+		handler.StartContactExport(w, request(http.MethodPost, "/contacts/export-jobs"))
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("start takes a format", func(t *testing.T) {
+		mockService.ExpectedCalls = nil
+		job := types.ExportJob{Job: jobTypes.Job{JobID: jobID}, Format: types.ExportFormatVCard}
+		mockService.On("StartContactExport", mock.Anything, userID, types.ExportFormatVCard).Return(job, nil)
+
+		w := httptest.NewRecorder()
+		handler.StartContactExport(w, request(http.MethodPost, "/contacts/export-jobs?format=vcard"))
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("start rejects unknown formats", func(t *testing.T) {
+		mockService.ExpectedCalls = nil
+		w := httptest.NewRecorder()
+		handler.StartContactExport(w, request(http.MethodPost, "/contacts/export-jobs?format=xml"))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("completed jobs link their file", func(t *testing.T) {
+		mockService.ExpectedCalls = nil
+		job := types.ExportJob{Job: jobTypes.Job{JobID: jobID, Status: jobTypes.JobStatusCompleted}, Format: types.ExportFormatCSV}
+		mockService.On("GetContactExport", mock.Anything, userID, jobID).Return(job, nil)
+
+		w := httptest.NewRecorder()
+		handler.GetContactExport(w, request(http.MethodGet, "/contacts/export-jobs/"+jobID.String()))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Data types.ExportJob `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.Equal(t, "/contacts/export-jobs/"+jobID.String()+"/download", response.Data.DownloadURL)
+	})
+
+	t.Run("running jobs have no link", func(t *testing.T) {
+		mockService.ExpectedCalls = nil
+		job := types.ExportJob{Job: jobTypes.Job{JobID: jobID, Status: jobTypes.JobStatusRunning}, Format: types.ExportFormatCSV}
+		mockService.On("GetContactExport", mock.Anything, userID, jobID).Return(job, nil)
+
+		w := httptest.NewRecorder()
+		handler.GetContactExport(w, request(http.MethodGet, "/contacts/export-jobs/"+jobID.String()))
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "downloadUrl")
+	})
+
+	t.Run("download serves the file as an attachment", func(t *testing.T) {
+		mockService.ExpectedCalls = nil
+		job := types.ExportJob{Job: jobTypes.Job{JobID: jobID, Status: jobTypes.JobStatusCompleted}, Format: types.ExportFormatCSV}
+		mockService.On("OpenContactExport", mock.Anything, userID, jobID).Return(job, io.NopCloser(strings.NewReader("contact_id\n")), nil)
+
+		w := httptest.NewRecorder()
+		handler.DownloadContactExport(w, request(http.MethodGet, "/contacts/export-jobs/"+jobID.String()+"/download"))
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, fmt.Sprintf("attachment; filename=\"contacts-%s.csv\"", jobID), w.Header().Get("Content-Disposition"))
+		assert.Equal(t, "contact_id\n", w.Body.String())
+	})
+
+	t.Run("download conflicts while the export runs", func(t *testing.T) {
+		mockService.ExpectedCalls = nil
+		mockService.On("OpenContactExport", mock.Anything, userID, jobID).
+			Return(types.ExportJob{}, nil, coreErrors.NewConflictError("the export is running, its file is ready once it is completed"))
+
+		w := httptest.NewRecorder()
+		handler.DownloadContactExport(w, request(http.MethodGet, "/contacts/export-jobs/"+jobID.String()+"/download"))
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+func TestContactHandler_PayloadShape(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	contactID := uuid.New()
+
+	bodies := []struct {
+		name    string
+		payload string
+		create  bool
+	}{
+		{name: "array on create", payload: `[{"name": "Mass"}]`, create: true},
+		{name: "scalar on create", payload: `"Mass"`, create: true},
+		{name: "array on update", payload: `[{"name": "Mass"}, {"name": "Update"}]`},
+		{name: "empty array on update", payload: `[]`},
+		{name: "scalar on update", payload: `42`},
+		{name: "null on update", payload: `null`},
+		{name: "only unknown keys on update", payload: `{"fullName": "Mass", "colour": "red"}`},
+	}
+
+	for _, tt := range bodies {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			method, handle := http.MethodPost, handler.CreateContact
+			req := httptest.NewRequest(method, "/contacts", strings.NewReader(tt.payload))
+			if !tt.create {
+				mockService.On("GetContact", mock.Anything, contactID, userID, types.ContactExpand{}).Return(types.Contact{ContactID: contactID, Name: "John Doe"}, nil)
+				method, handle = http.MethodPut, handler.UpdateContact
+				req = httptest.NewRequest(method, "/contacts/"+contactID.String(), strings.NewReader(tt.payload))
+				rctx := chi.NewRouteContext()
+				rctx.URLParams.Add("id", contactID.String())
+				req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+
+			w := httptest.NewRecorder()
+			handle(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			var response coreErrors.ErrorResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+			assert.Equal(t, coreErrors.ErrorTypePayloadShape, response.Type)
+			mockService.AssertNotCalled(t, "CreateContact", mock.Anything, mock.Anything, mock.Anything)
+			mockService.AssertNotCalled(t, "UpdateContact", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestContactHandler_GetContact_ExpandRelationships(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	contactID := uuid.New()
+
+	serve := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/contacts/"+contactID.String()+query, nil)
+		ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", contactID.String())
+		req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+		w := httptest.NewRecorder()
+		handler.GetContact(w, req)
+		return w
+	}
+
+	related := uuid.New()
+	mockService.On("GetContact", mock.Anything, contactID, userID, types.ContactExpand{Relationships: true}).Return(types.Contact{
+		ContactID: contactID,
+		Name:      "John Doe",
+		Relationships: []types.ContactRelationship{
+			{RelationshipID: uuid.New(), Type: types.RelationshipWorksFor, Outgoing: true, Contact: types.RelatedContact{ContactID: related, Name: "Acme Supplies"}},
+		},
+	}, nil).Once()
+
+	w := serve("?expand=relationships")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response struct {
+		Data types.Contact `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	require.Len(t, response.Data.Relationships, 1)
+	assert.Equal(t, related, response.Data.Relationships[0].Contact.ContactID)
+	assert.Equal(t, "Acme Supplies", response.Data.Relationships[0].Contact.Name)
+
+	w = serve("?expand=tags")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestContactHandler_ContactRelationships(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	contactID := uuid.New()
+	relatedID := uuid.New()
+
+	request := func(method, body string, params map[string]string) *http.Request {
+		req := httptest.NewRequest(method, "/contacts/"+params["id"]+"/relationships", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
+		rctx := chi.NewRouteContext()
+		for key, value := range params {
+			rctx.URLParams.Add(key, value)
+		}
+		return req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+	}
+
+	createTests := []struct {
+		name           string
+		body           string
+		setupMock      func()
+		expectedStatus int
+	}{
+		{
+			name: "created",
+			body: `{"contactId": "` + relatedID.String() + `", "type": "works_for", "note": "Handles their taxes"}`,
+			setupMock: func() {
+				payload := types.ContactRelationshipPayload{ContactID: relatedID, Type: types.RelationshipWorksFor, Note: stringPtr("Handles their taxes")}
+				mockService.On("CreateContactRelationship", mock.Anything, contactID, userID, payload).Return(types.ContactRelationship{
+					RelationshipID: uuid.New(),
+					Type:           payload.Type,
+					Outgoing:       true,
+					Contact:        types.RelatedContact{ContactID: relatedID, Name: "Acme Supplies"},
+					Note:           payload.Note,
+				}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "snake case contact id",
+			body: `{"contact_id": "` + relatedID.String() + `", "type": "spouse_of"}`,
+			setupMock: func() {
+				payload := types.ContactRelationshipPayload{ContactID: relatedID, Type: types.RelationshipSpouseOf}
+				mockService.On("CreateContactRelationship", mock.Anything, contactID, userID, payload).Return(types.ContactRelationship{Type: payload.Type}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "unknown type",
+			body:           `{"contactId": "` + relatedID.String() + `", "type": "friend_of"}`,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing contact",
+			body:           `{"type": "works_for"}`,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "duplicate",
+			body: `{"contactId": "` + relatedID.String() + `", "type": "works_for"}`,
+			setupMock: func() {
+				mockService.On("CreateContactRelationship", mock.Anything, contactID, userID, mock.Anything).
+					Return(types.ContactRelationship{}, coreErrors.NewConflictError("contact relationship already exists"))
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name: "related contact not found",
+			body: `{"contactId": "` + relatedID.String() + `", "type": "works_for"}`,
+			setupMock: func() {
+				mockService.On("CreateContactRelationship", mock.Anything, contactID, userID, mock.Anything).
+					Return(types.ContactRelationship{}, fmt.Errorf("relate contact: %w", repository.ErrNotFound))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range createTests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			tt.setupMock()
+
+			w := httptest.NewRecorder()
+			handler.CreateContactRelationship(w, request(http.MethodPost, tt.body, map[string]string{"id": contactID.String()}))
+
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+
+	t.Run("delete", func(t *testing.T) {
+		mockService.ExpectedCalls = nil
+		relationshipID := uuid.New()
+		mockService.On("DeleteContactRelationship", mock.Anything, contactID, relationshipID, userID).Return(nil).Once()
+
+		w := httptest.NewRecorder()
+		handler.DeleteContactRelationship(w, request(http.MethodDelete, "", map[string]string{"id": contactID.String(), "relationshipId": relationshipID.String()}))
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = httptest.NewRecorder()
+		handler.DeleteContactRelationship(w, request(http.MethodDelete, "", map[string]string{"id": contactID.String(), "relationshipId": "invalid"}))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
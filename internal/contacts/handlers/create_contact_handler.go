@@ -5,23 +5,27 @@ import (
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
 	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
-	"github.com/go-chi/render"
 )
 
 // CreateContact godoc
 // @Summary Create a new Contact
-// @Description Creates a new Contact for the authenticated user
+// @Description Creates a new Contact for the authenticated user. With allow_unnamed=true the name can be left
+// @Description out when a phone is given, the contact is then named after the cleaned number.
 // @Tags Contacts
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param request body types.ContactCreatePayload true "Contact creation request"
+// @Param allow_unnamed query bool false "Allow a contact with a phone but no name"
 // @Success 201 {object} payloads.Response{data=types.Contact}
-// @Failure 400 {object} errors.ErrorResponse
+// @Header 201 {string} ETag "version of the contact, for If-Match"
+// @Failure 400 {object} errors.ErrorResponse "Invalid payload, or an email domain that looks like a typo with the suggestion"
 // @Failure 401 {object} errors.ErrorResponse
 // @Failure 404 {object} errors.ErrorResponse
+// @Failure 409 {object} errors.ErrorResponse "Another contact already uses the email"
 // @Failure 429 {object} errors.ErrorResponse
 // @Failure 500 {object} errors.ErrorResponse
 // @Router /contacts [post]
@@ -33,16 +37,21 @@ func (h *ContactHandler) CreateContact(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var req types.ContactCreatePayload
-	if err := render.Bind(r, &req); err != nil {
-		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+	if !h.CheckQueryParams(w, r, "allow_unnamed") {
+		return
+	}
+
+	req := types.ContactCreatePayload{AllowUnnamed: r.URL.Query().Get("allow_unnamed") == "true"}
+	if !h.Bind(w, r, &req) {
 		return
 	}
 
 	contact, err := h.service.CreateContact(r.Context(), req, userID)
 	if err != nil {
 		h.HandleServiceError(w, r, err)
+		return
 	}
 
+	handlers.SetETag(w, contact.UpdatedAt.Time)
 	h.Respond(w, r, payloads.Created(contact))
 }
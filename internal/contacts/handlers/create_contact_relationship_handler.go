@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// CreateContactRelationship godoc
+// @Summary Relate a Contact to another one
+// @Description Relates the Contact to another of the user's contacts, the relationship reads contact <type> related contact. It shows on both contacts with expand=relationships.
+// @Tags Contacts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Contact ID" format(uuid)
+// @Param request body types.ContactRelationshipPayload true "Relationship to create"
+// @Success 201 {object} payloads.Response{data=types.ContactRelationship}
+// @Failure 400 {object} errors.ErrorResponse "Invalid payload or a contact related to itself"
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse "Either contact doesn't exist"
+// @Failure 409 {object} errors.ErrorResponse "The contacts already have a relationship of the type"
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /contacts/{id}/relationships [post]
+// @ID CreateContactRelationship
+func (h *ContactHandler) CreateContactRelationship(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	contactID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	var req types.ContactRelationshipPayload
+	if !h.Bind(w, r, &req) {
+		return
+	}
+
+	relationship, err := h.service.CreateContactRelationship(r.Context(), contactID, userID, req)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.Created(relationship))
+}
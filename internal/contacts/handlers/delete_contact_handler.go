@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"net/http"
+	"time"
 
+	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
 	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
@@ -12,16 +14,18 @@ import (
 
 // DeleteContact godoc
 // @Summary Delete a Contact
-// @Description Deletes a Contact by ID
+// @Description Moves a Contact to the trash, it can be restored until the retention period passes. Its relationships to other contacts are deleted along with it and don't come back with a restore.
 // @Tags Contacts
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Contact ID" format(uuid)
+// @Param If-Match header string false "ETag of the contact as last seen, the delete fails with a 412 if it changed since"
 // @Success 200 {object} payloads.Response
 // @Failure 400 {object} errors.ErrorResponse
 // @Failure 401 {object} errors.ErrorResponse
 // @Failure 404 {object} errors.ErrorResponse
+// @Failure 412 {object} errors.ErrorResponse
 // @Failure 429 {object} errors.ErrorResponse
 // @Failure 500 {object} errors.ErrorResponse
 // @Router /contacts/{id} [delete]
@@ -39,14 +43,16 @@ func (h *ContactHandler) DeleteContact(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if contact exists and belongs to user
-	_, err = h.service.GetContact(r.Context(), contactID, userID)
-	if err != nil {
-		h.HandleServiceError(w, r, err)
+	version, ok := h.CheckIfMatch(w, r, func() (time.Time, error) {
+		contact, err := h.service.GetContact(r.Context(), contactID, userID, types.ContactExpand{})
+		return contact.UpdatedAt.Time, err
+	})
+	if !ok {
 		return
 	}
 
-	err = h.service.DeleteContact(r.Context(), contactID, userID)
+	// a contact that doesn't exist or belongs to another user comes back as not found
+	err = h.service.DeleteContact(r.Context(), contactID, userID, version)
 	if err != nil {
 		h.HandleServiceError(w, r, err)
 		return
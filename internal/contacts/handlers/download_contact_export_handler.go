@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// DownloadContactExport godoc
+// @Summary Download a Contact export
+// @Description Sends the file of a completed Contact export job as an attachment, in the format the export was started with.
+// @Tags Contacts
+// @Produce text/csv
+// @Produce application/json
+// @Produce text/vcard
+// @Security BearerAuth
+// @Param id path string true "Job ID" format(uuid)
+// @Success 200 {string} string "contact_id,name,company,email,phone,address_line1,address_line2,city,state_province,zip_postal_code,country,tags,notes,created_at,updated_at"
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse "No such export, or its file was cleaned up"
+// @Failure 409 {object} errors.ErrorResponse "The export is not completed"
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /contacts/export-jobs/{id}/download [get]
+// @ID DownloadContactExport
+func (h *ContactHandler) DownloadContactExport(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	jobID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	job, file, err := h.service.OpenContactExport(r.Context(), userID, jobID)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+	defer file.Close()
+
+	h.SendFile(w, job.Format, fmt.Sprintf("contacts-%s.%s", job.JobID, types.ExportExtensions[job.Format]), file)
+}
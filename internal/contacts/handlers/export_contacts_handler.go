@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+)
+
+// ExportContacts godoc
+// @Summary Export contacts
+// @Description Streams every contact of the user, newest first. Rows are written as they are read, so large address books don't have to fit in memory.
+// @Description The Accept header picks the format among CSV, a JSON array and vCards, the format query parameter overrides it. CSV is the default.
+// @Tags Contacts
+// @Produce text/csv
+// @Produce application/json
+// @Produce text/vcard
+// @Security BearerAuth
+// @Param format query string false "export format, overrides the Accept header" Enums(csv, json, vcard)
+// @Success 200 {string} string "contact_id,name,company,email,phone,address_line1,address_line2,city,state_province,zip_postal_code,country,tags,notes,created_at,updated_at"
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 406 {object} errors.ErrorResponse
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /contacts/export [get]
+// @ID ExportContacts
+func (h *ContactHandler) ExportContacts(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	if !h.CheckQueryParams(w, r, "format") {
+		return
+	}
+
+	format, err := handlers.NegotiateFormat(r, "format", handlers.MediaTypeCSV, handlers.MediaTypeJSON, handlers.MediaTypeVCard)
+	if err != nil {
+		h.RespondError(w, r, errors.ErrNotAcceptable(err))
+		return
+	}
+
+	switch format {
+	case handlers.MediaTypeJSON:
+		h.StreamJSON(w, r, func(write func(item any) error) error {
+			return h.service.ExportContacts(r.Context(), userID, func(contact types.Contact) error {
+				return write(contact)
+			})
+		})
+	case handlers.MediaTypeVCard:
+		h.StreamText(w, r, handlers.MediaTypeVCard, func(write func(chunk string) error) error {
+			return h.service.ExportContacts(r.Context(), userID, func(contact types.Contact) error {
+				return write(contact.VCard())
+			})
+		})
+	default:
+		h.StreamCSV(w, r, types.CSVHeader, func(write func(record []string) error) error {
+			return h.service.ExportContacts(r.Context(), userID, func(contact types.Contact) error {
+				return write(contact.CSVRecord())
+			})
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	jobTypes "github.com/Abdelrahman-habib/expense-tracker/internal/jobs/types"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// GetContactExport godoc
+// @Summary Get a Contact export job
+// @Description Reports the status of a Contact export job. Once it is completed the downloadUrl links to the file.
+// @Tags Contacts
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Job ID" format(uuid)
+// @Success 200 {object} payloads.Response{data=types.ExportJob}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /contacts/export-jobs/{id} [get]
+// @ID GetContactExport
+func (h *ContactHandler) GetContactExport(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	jobID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	job, err := h.service.GetContactExport(r.Context(), userID, jobID)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	// the file is served next to the job, under whatever prefix the API is mounted at
+	if job.Status == jobTypes.JobStatusCompleted {
+		job.DownloadURL = strings.TrimSuffix(r.URL.Path, "/") + "/download"
+	}
+
+	h.Respond(w, r, payloads.OK(job))
+}
@@ -3,7 +3,9 @@ package handlers
 import (
 	"net/http"
 
+	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
 	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
 	"github.com/go-chi/chi/v5"
@@ -12,13 +14,15 @@ import (
 
 // GetContact godoc
 // @Summary Get a Contact
-// @Description Retrieves a Contact by ID
+// @Description Retrieves a Contact by ID, with expand=relationships along with its relationships to other contacts both ways
 // @Tags Contacts
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Contact ID" format(uuid)
+// @Param expand query string false "comma separated related resources to include" Enums(relationships)
 // @Success 200 {object} payloads.Response{data=types.Contact}
+// @Header 200 {string} ETag "version of the contact, for If-Match"
 // @Failure 400 {object} errors.ErrorResponse
 // @Failure 401 {object} errors.ErrorResponse
 // @Failure 404 {object} errors.ErrorResponse
@@ -39,11 +43,18 @@ func (h *ContactHandler) GetContact(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	contact, err := h.service.GetContact(r.Context(), contactID, userID)
+	expand, err := types.ParseContactExpand(r.URL.Query())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	contact, err := h.service.GetContact(r.Context(), contactID, userID, expand)
 	if err != nil {
 		h.HandleServiceError(w, r, err)
 		return
 	}
 
+	handlers.SetETag(w, contact.UpdatedAt.Time)
 	h.Respond(w, r, payloads.OK(contact))
 }
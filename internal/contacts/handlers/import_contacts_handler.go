@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/render"
+)
+
+// ImportContacts godoc
+// @Summary Import Contacts
+// @Description Queues a bulk import of Contacts and returns the job tracking it. Rows are written in chunks in the background; poll /jobs/{id} for progress and per-row errors.
+// @Tags Contacts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body types.ContactImportPayload true "Contacts to import"
+// @Success 202 {object} payloads.Response{data=jobTypes.Job}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /contacts/import [post]
+// @ID ImportContacts
+func (h *ContactHandler) ImportContacts(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	var req types.ContactImportPayload
+	if err := render.Bind(r, &req); err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	job, err := h.service.ImportContacts(r.Context(), userID, req.Contacts)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.Accepted(job))
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+)
+
+// ListCompanies godoc
+// @Summary List companies
+// @Description Returns the distinct company names across the user's contacts with how many contacts belong to each, ordered by name
+// @Tags Contacts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param limit query integer false "Number of companies to return" minimum(1) maximum(100) default(20)
+// @Success 200 {object} payloads.Response{data=[]types.CompanyCount}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /contacts/companies [get]
+// @ID ListCompanies
+func (h *ContactHandler) ListCompanies(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	if !h.CheckQueryParams(w, r, types.CompanyCountQueryParams...) {
+		return
+	}
+
+	// only the limit can be set, the other parameters keep their defaults
+	params, err := types.ParseCompanyListParams(r.URL.Query())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	companies, err := h.service.ListCompanies(r.Context(), userID, params.Limit)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.List(companies, len(companies)))
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+)
+
+// ListContactCompanies godoc
+// @Summary List contacts grouped by company
+// @Description Returns the user's companies with their contact counts and the first few contacts of each
+// @Tags Contacts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param sort query string false "Sort companies by contact count or name" Enums(count, name) default(count)
+// @Param limit query integer false "Number of companies to return" minimum(1) maximum(100) default(20)
+// @Param contacts_limit query integer false "Number of contacts to include per company" minimum(1) maximum(10) default(3)
+// @Success 200 {object} payloads.Response{data=[]types.CompanyContacts}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /contacts/by-company [get]
+// @ID ListContactCompanies
+func (h *ContactHandler) ListContactCompanies(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	if !h.CheckQueryParams(w, r, types.CompanyListQueryParams...) {
+		return
+	}
+
+	params, err := types.ParseCompanyListParams(r.URL.Query())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	companies, err := h.service.ListContactCompanies(r.Context(), userID, params)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.List(companies, len(companies)))
+}
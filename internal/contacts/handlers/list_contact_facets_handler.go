@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+)
+
+// ListContactFacets godoc
+// @Summary List the values of a contact field
+// @Description Returns the distinct values the user's contacts hold in the field with how many contacts hold each, most common first, for filter dropdowns. Contacts without a value and those in the trash are left out.
+// @Tags Contacts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param field query string true "field to count the values of" Enums(country, city, company)
+// @Success 200 {object} payloads.Response{data=[]coreTypes.Facet}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /contacts/facets [get]
+// @ID ListContactFacets
+func (h *ContactHandler) ListContactFacets(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	if !h.CheckQueryParams(w, r, coreTypes.FacetFieldParam) {
+		return
+	}
+
+	field, err := coreTypes.ParseFacetField(r.URL.Query(), types.FacetFields...)
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	facets, err := h.service.ListFacets(r.Context(), userID, field)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.List(facets, len(facets)))
+}
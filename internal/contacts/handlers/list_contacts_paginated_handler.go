@@ -2,25 +2,37 @@ package handlers
 
 import (
 	"net/http"
+	"slices"
 	"time"
 
+	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
-	"github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
 	"github.com/google/uuid"
 )
 
 // ListContacts godoc
 // @Summary List Contacts with pagination
-// @Description Returns a paginated list of Contacts
+// @Description Returns a paginated list of Contacts. With ids it returns the contacts with those IDs instead, in the order asked for, and names the IDs not found in meta.missing.
+// @Description With only_ids=true the data is only the IDs of the page and the next_token, {"ids":[...],"next_token":...}, read without the rest of the contacts.
+// @Description With stream=true or Accept: application/x-ndjson the page is streamed as newline-delimited JSON instead, one contact per line followed by a {"meta":{"count","next_token"}} line, or an {"error":...} line when the stream fails midway.
 // @Tags Contacts
 // @Accept json
 // @Produce json
+// @Produce application/x-ndjson
 // @Security BearerAuth
 // @Param limit query integer false "Number of Contacts to return" minimum(1) maximum(100) default(10)
+// @Param order query string false "Sort order by creation time" Enums(asc, desc) default(desc)
 // @Param next_token query string false "Token for the next page"
+// @Param city query string false "Only contacts in this city, ignoring case"
+// @Param state_province query string false "Only contacts in this state or province, ignoring case"
+// @Param stream query boolean false "Stream the page as newline-delimited JSON"
+// @Param only_ids query boolean false "Return only the IDs of the page, not with stream"
+// @Param ids query string false "Comma separated IDs of the contacts to fetch, at most 100, other parameters aren't allowed with it"
 // @Success 200 {object} payloads.Response{data=[]types.Contact}
+// @Success 200 {object} payloads.Response{data=payloads.IDPage} "only_ids=true"
 // @Failure 400 {object} errors.ErrorResponse
 // @Failure 401 {object} errors.ErrorResponse
 // @Failure 429 {object} errors.ErrorResponse
@@ -34,13 +46,36 @@ func (h *ContactHandler) ListContactsPaginated(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	if r.URL.Query().Has(coreTypes.IDsQueryParam) {
+		h.getContactsByIDs(w, r, userID)
+		return
+	}
+
+	if !h.CheckQueryParams(w, r, append(slices.Clone(types.ListQueryParams), coreTypes.StreamParam, coreTypes.OnlyIDsParam)...) {
+		return
+	}
+
 	// Parse and validate pagination parameters
-	params, err := types.ParsePaginationParams(r.URL.Query())
+	params, ok := h.ParsePagination(w, r, h.limits, userID, types.ListQueryParams...)
+	if !ok {
+		return
+	}
+
+	filter, err := types.ParseContactFilter(r.URL.Query())
 	if err != nil {
 		h.RespondError(w, r, errors.ErrInvalidRequest(err))
 		return
 	}
 
+	stream, ok := h.ParseStream(w, r)
+	if !ok {
+		return
+	}
+	onlyIDs, ok := h.ParseOnlyIDs(w, r, stream)
+	if !ok {
+		return
+	}
+
 	// Set default cursor values if not provided
 	var cursor *time.Time
 	var cursorID *uuid.UUID
@@ -49,7 +84,22 @@ func (h *ContactHandler) ListContactsPaginated(w http.ResponseWriter, r *http.Re
 		cursorID = &params.Cursor.ID
 	}
 
-	contacts, err := h.service.ListContactsPaginated(r.Context(), userID, cursor, cursorID, params.Limit)
+	if stream {
+		h.streamContacts(w, r, userID, params, cursor, cursorID, filter)
+		return
+	}
+
+	if onlyIDs {
+		keys, err := h.service.ListContactIDsPaginated(r.Context(), userID, cursor, cursorID, params.Limit, params.Order, filter)
+		if err != nil {
+			h.HandleServiceError(w, r, err)
+			return
+		}
+		h.RespondIDs(w, r, params, keys, userID)
+		return
+	}
+
+	contacts, err := h.service.ListContactsPaginated(r.Context(), userID, cursor, cursorID, params.Limit, params.Order, filter)
 	if err != nil {
 		h.HandleServiceError(w, r, err)
 		return
@@ -58,7 +108,7 @@ func (h *ContactHandler) ListContactsPaginated(w http.ResponseWriter, r *http.Re
 	var nextToken string
 	if len(contacts) > 0 && len(contacts) == int(params.Limit) { // Only set next_token if we got a full page
 		lastContact := contacts[len(contacts)-1]
-		nextToken = types.EncodeCursor(lastContact.CreatedAt, lastContact.ContactID)
+		nextToken = params.NextToken(lastContact.CreatedAt.Time, lastContact.ContactID, userID)
 	}
 
 	h.Respond(w, r, payloads.Paginated(
@@ -67,3 +117,39 @@ func (h *ContactHandler) ListContactsPaginated(w http.ResponseWriter, r *http.Re
 		params.Limit,
 	))
 }
+
+// streamContacts streams the page of contacts as NDJSON
+func (h *ContactHandler) streamContacts(w http.ResponseWriter, r *http.Request, userID uuid.UUID, params coreTypes.PaginationParams, cursor *time.Time, cursorID *uuid.UUID, filter types.ContactFilter) {
+	h.StreamNDJSON(w, r, func(write func(item any) error) (string, error) {
+		var last types.Contact
+		count := 0
+		err := h.service.StreamContactsPaginated(r.Context(), userID, cursor, cursorID, params.Limit, params.Order, filter, func(contact types.Contact) error {
+			last = contact
+			count++
+			return write(contact)
+		})
+		if err != nil || count < int(params.Limit) { // Only set next_token if we got a full page
+			return "", err
+		}
+		return params.NextToken(last.CreatedAt.Time, last.ContactID, userID), nil
+	})
+}
+
+// getContactsByIDs responds with the user's contacts of the ids query parameter
+func (h *ContactHandler) getContactsByIDs(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+	if !h.CheckQueryParams(w, r, coreTypes.IDsQueryParam) {
+		return
+	}
+	ids, ok := h.ParseIDs(w, r)
+	if !ok {
+		return
+	}
+
+	contacts, missing, err := h.service.GetContactsByIDs(r.Context(), userID, ids)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.Batch(contacts, len(contacts), missing))
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// RestoreContact godoc
+// @Summary Restore a Contact
+// @Description Moves a trashed Contact back so it shows up in lists and search again
+// @Tags Contacts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Contact ID" format(uuid)
+// @Success 200 {object} payloads.Response{data=types.Contact}
+// @Header 200 {string} ETag "version of the contact, for If-Match"
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Failure 409 {object} errors.ErrorResponse "Another contact already uses the email"
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /contacts/{id}/restore [post]
+// @ID RestoreContact
+func (h *ContactHandler) RestoreContact(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	contactID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	contact, err := h.service.RestoreContact(r.Context(), contactID, userID)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	handlers.SetETag(w, contact.UpdatedAt.Time)
+	h.Respond(w, r, payloads.Restored(contact))
+}
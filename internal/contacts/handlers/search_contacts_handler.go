@@ -1,25 +1,35 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/ranking"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
 )
 
 // SearchContacts godoc
 // @Summary Search Contacts
-// @Description Searches for Contacts based on a query string
+// @Description Searches for Contacts based on a query string. With view=picker each contact only carries its ID, name, phone and email. Name and company searches score each contact, and trim=auto drops the contacts after the largest fall in score.
+// @Description The closest matches come first. Contacts ranking the same are ordered by the shorter name for name searches and by name for company searches, then the newest first, so the order of a search never changes between requests.
 // @Tags Contacts
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param q query string true "Search query" minLength(1) maxLength(100)
+// @Param q query string true "Search query, matched against name and company" minLength(1) maxLength(100)
+// @Param company_q query string false "Search by company instead of name" minLength(1) maxLength(100)
+// @Param by_phone query boolean false "Match q as a phone number prefix, q must then be at least 4 digits with an optional leading +, dashes and spaces are ignored" default(false)
 // @Param limit query integer false "Maximum number of results" minimum(1) maximum(50) default(10)
-// @Success 200 {object} payloads.Response{data=[]types.Contact}
-// @Failure 400 {object} errors.ErrorResponse
+// @Param next_token query string false "Token for the next page of results, paging stops at the configured search window"
+// @Param view query string false "Shape of the contacts, picker returns only what a picker shows" Enums(full, picker) default(full)
+// @Param trim query string false "auto cuts the results after the largest fall in score, keeping at least the configured minimum" Enums(off, auto) default(off)
+// @Success 200 {object} payloads.Response{data=[]types.Contact} "view=full"
+// @Success 200 {object} payloads.Response{data=[]types.ContactPickerItem} "view=picker"
+// @Failure 400 {object} errors.ErrorResponse "Invalid parameters, or QUERY_TOO_COMPLEX for a query with too many distinct words or one that timed out"
 // @Failure 401 {object} errors.ErrorResponse
 // @Failure 429 {object} errors.ErrorResponse
 // @Failure 500 {object} errors.ErrorResponse
@@ -32,30 +42,94 @@ func (h *ContactHandler) SearchContacts(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if !h.CheckQueryParams(w, r, types.SearchQueryParams...) {
+		return
+	}
+
 	// Parse query parameters
 	query := r.URL.Query()
-	params, err := types.ParseAndValidateSearchParams(query)
+	params, err := types.ParseAndValidateSearchParams(query, h.limits)
+	if err != nil {
+		h.RespondInvalidSearch(w, r, err)
+		return
+	}
+	view, err := coreTypes.ParseView(query)
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+	trim, err := coreTypes.ParseTrim(query, view)
 	if err != nil {
 		h.RespondError(w, r, errors.ErrInvalidRequest(err))
 		return
 	}
+	if trim && params.SearchByPhone && params.CompanyQuery == "" {
+		h.RespondError(w, r, errors.ErrInvalidRequest(
+			fmt.Errorf("%s: phone searches are not scored", coreTypes.TrimParam)))
+		return
+	}
+	if !h.CheckSearchWindow(w, r, &params.SearchParams) {
+		return
+	}
+	ctx, cancel := h.SearchContext(r)
+	defer cancel()
+
+	if view == coreTypes.ViewPicker {
+		var items []types.ContactPickerItem
+		if params.CompanyQuery != "" {
+			items, err = h.service.SearchContactsByCompanyPicker(ctx, userID, params.CompanyQuery, params.Limit, params.Offset)
+		} else if params.SearchByPhone {
+			items, err = h.service.SearchContactsByPhonePicker(ctx, userID, params.Query, params.Limit, params.Offset)
+		} else {
+			items, err = h.service.SearchContactsPicker(ctx, userID, params.Query, params.Limit, params.Offset)
+		}
+		if err != nil {
+			h.HandleSearchError(w, r, ctx, err)
+			return
+		}
+		h.Respond(w, r, payloads.PaginatedSearchView(
+			items,
+			params.Query,
+			params.Limit,
+			len(items),
+			params.NextToken(len(items)),
+			view,
+		))
+		return
+	}
 
 	var contacts []types.Contact
-	if params.SearchByPhone {
-		contacts, err = h.service.SearchContactsByPhone(r.Context(), userID, params.Query, params.Limit)
+	if params.CompanyQuery != "" {
+		contacts, err = h.service.SearchContactsByCompany(ctx, userID, params.CompanyQuery, params.Limit, params.Offset)
+	} else if params.SearchByPhone {
+		contacts, err = h.service.SearchContactsByPhone(ctx, userID, params.Query, params.Limit, params.Offset)
 	} else {
-		contacts, err = h.service.SearchContacts(r.Context(), userID, params.Query, params.Limit)
+		contacts, err = h.service.SearchContacts(ctx, userID, params.Query, params.Limit, params.Offset)
 	}
 
 	if err != nil {
-		h.HandleServiceError(w, r, err)
+		h.HandleSearchError(w, r, ctx, err)
 		return
 	}
 
-	h.Respond(w, r, payloads.Search(
+	nextToken := params.NextToken(len(contacts))
+	var cut *ranking.Trim
+	if trim {
+		contacts, cut = ranking.TrimAtCliff(contacts, int(h.limits.MinTrimmedResults), func(contact types.Contact) *float32 {
+			return contact.Score
+		})
+	}
+
+	resp := payloads.PaginatedSearchView(
 		contacts,
 		params.Query,
 		params.Limit,
 		len(contacts),
-	))
+		nextToken,
+		view,
+	)
+	if cut != nil {
+		resp = payloads.WithTrim(resp, cut.Trimmed, cut.Cutoff)
+	}
+	h.Respond(w, r, resp)
 }
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+)
+
+// StartContactExport godoc
+// @Summary Start a Contact export job
+// @Description Queues an export of every Contact of the user and returns the job producing it. The file is written in the background, poll /contacts/export-jobs/{id} until it is completed and download it from its downloadUrl.
+// @Description Meant for address books too large for GET /contacts/export. CSV is the default format.
+// @Tags Contacts
+// @Produce json
+// @Security BearerAuth
+// @Param format query string false "export format" Enums(csv, json, vcard)
+// @Success 202 {object} payloads.Response{data=types.ExportJob}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /contacts/export-jobs [post]
+// @ID StartContactExport
+func (h *ContactHandler) StartContactExport(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	if !h.CheckQueryParams(w, r, "format") {
+		return
+	}
+
+	format := handlers.MediaTypeCSV
+	if value := r.URL.Query().Get("format"); value != "" {
+		format, err = handlers.FormatMediaType("format", value, handlers.MediaTypeCSV, handlers.MediaTypeJSON, handlers.MediaTypeVCard)
+		if err != nil {
+			h.RespondError(w, r, errors.ErrInvalidRequest(err))
+			return
+		}
+	}
+
+	job, err := h.service.StartContactExport(r.Context(), userID, format)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.Accepted(job))
+}
@@ -1,19 +1,21 @@
 package handlers
 
 import (
+	stdErrors "errors"
 	"net/http"
 
+	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
 	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/render"
 	"github.com/google/uuid"
 )
 
 // UpdateContact godoc
 // @Summary Update a Contact
-// @Description Updates an existing Contact
+// @Description Updates an existing Contact. The URL names the contact, a contactId in the body naming another one is refused with ID_MISMATCH. Concurrent updates of a contact apply one after the other.
 // @Tags Contacts
 // @Accept json
 // @Produce json
@@ -21,9 +23,11 @@ import (
 // @Param id path string true "Contact ID" format(uuid)
 // @Param request body types.ContactUpdatePayload true "Contact update request"
 // @Success 200 {object} payloads.Response{data=types.Contact}
-// @Failure 400 {object} errors.ErrorResponse
+// @Header 200 {string} ETag "version of the contact, for If-Match"
+// @Failure 400 {object} errors.ErrorResponse "Invalid payload, or an email domain that looks like a typo with the suggestion"
 // @Failure 401 {object} errors.ErrorResponse
 // @Failure 404 {object} errors.ErrorResponse
+// @Failure 409 {object} errors.ErrorResponse "Another contact already uses the email, or CONCURRENT_MODIFICATION when another update of the contact didn't finish in time"
 // @Failure 429 {object} errors.ErrorResponse
 // @Failure 500 {object} errors.ErrorResponse
 // @Router /contacts/{id} [put]
@@ -41,31 +45,24 @@ func (h *ContactHandler) UpdateContact(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get existing contact first
-	existingContact, err := h.service.GetContact(r.Context(), contactID, userID)
-	if err != nil {
-		h.HandleServiceError(w, r, err)
-		return
-	}
-
-	// Create update payload from existing contact
-	updatePayload := existingContact.ToUpdatePayload()
-
-	// Use render.Bind to decode and validate
-	if err := render.Bind(r, &updatePayload); err != nil {
-		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+	// Decode and validate onto the current values, read under the contact's lock so a
+	// concurrent update can't change them before this one is written
+	contact, err := h.service.UpdateContact(r.Context(), contactID, userID, func(existing types.Contact) (types.ContactUpdatePayload, error) {
+		updatePayload := existing.ToUpdatePayload()
+		// the URL names the contact
+		if !h.BindEntityUpdate(w, r, &updatePayload, "contactId", contactID, &updatePayload.ContactID) {
+			return types.ContactUpdatePayload{}, handlers.ErrResponded
+		}
+		return updatePayload, nil
+	})
+	if stdErrors.Is(err, handlers.ErrResponded) {
 		return
 	}
-
-	contact, err := h.service.UpdateContact(r.Context(), updatePayload, userID)
 	if err != nil {
-		if errors.IsErrorType(err, errors.ErrorTypeNotFound) {
-			h.RespondError(w, r, errors.ErrNotFound())
-			return
-		}
-		h.RespondError(w, r, errors.ErrDatabase(err))
+		h.HandleServiceError(w, r, err)
 		return
 	}
 
+	handlers.SetETag(w, contact.UpdatedAt.Time)
 	h.Respond(w, r, payloads.Updated(contact))
 }
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+)
+
+// UpsertContactByExternalRef godoc
+// @Summary Create or update a Contact by its external reference
+// @Description Creates the contact synced from the source system under the given ID, or updates the fields sent when it already exists. Creating a contact takes a name.
+// @Tags Contacts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param source path string true "Source system, lowercase letters, digits, - or _" maxLength(50)
+// @Param external_id path string true "ID of the contact in the source system" maxLength(255)
+// @Param request body types.ContactUpsertPayload true "Contact fields to set"
+// @Success 200 {object} payloads.Response{data=types.Contact} "Contact updated"
+// @Header 200 {string} ETag "version of the contact, for If-Match"
+// @Success 201 {object} payloads.Response{data=types.Contact} "Contact created"
+// @Header 201 {string} ETag "version of the contact, for If-Match"
+// @Failure 400 {object} errors.ErrorResponse "Invalid payload, or an email domain that looks like a typo with the suggestion"
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 409 {object} errors.ErrorResponse "Another contact already uses the email"
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /contacts/external/{source}/{external_id} [put]
+// @ID UpsertContactByExternalRef
+func (h *ContactHandler) UpsertContactByExternalRef(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	ref, err := types.ParseExternalRef(chi.URLParam(r, "source"), chi.URLParam(r, "external_id"))
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	var req types.ContactUpsertPayload
+	if !h.BindUpdate(w, r, &req) {
+		return
+	}
+
+	contact, created, err := h.service.UpsertContactByExternalRef(r.Context(), userID, ref, req)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	handlers.SetETag(w, contact.UpdatedAt.Time)
+	if created {
+		h.Respond(w, r, payloads.Created(contact))
+		return
+	}
+	h.Respond(w, r, payloads.Updated(contact))
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/render"
+)
+
+// ValidateContacts godoc
+// @Summary Validate Contacts
+// @Description Validates a batch of Contacts as an import would, including tag ownership, without writing any. Returns a result per row in the order they were sent.
+// @Tags Contacts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body types.ContactImportPayload true "Contacts to validate"
+// @Success 200 {object} payloads.Response{data=types.ContactBatchValidation}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /contacts/validate-batch [post]
+// @ID ValidateContacts
+func (h *ContactHandler) ValidateContacts(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	var req types.ContactImportPayload
+	if err := render.Bind(r, &req); err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	result, err := h.service.ValidateContacts(r.Context(), userID, req.Contacts)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.OK(result))
+}
@@ -3,6 +3,7 @@ package integration
 import (
 	"bytes"
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -17,12 +18,22 @@ import (
 	"time"
 
 	"github.com/Abdelrahman-habib/expense-tracker/config"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/app"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/handlers"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/repository"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/service"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/blob"
+	coreErrors "github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
 	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	jobHandlers "github.com/Abdelrahman-habib/expense-tracker/internal/jobs/handlers"
+	jobRepository "github.com/Abdelrahman-habib/expense-tracker/internal/jobs/repository"
+	jobService "github.com/Abdelrahman-habib/expense-tracker/internal/jobs/service"
+	jobTypes "github.com/Abdelrahman-habib/expense-tracker/internal/jobs/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/jobs/worker"
+	tagRepository "github.com/Abdelrahman-habib/expense-tracker/internal/tags/repository"
+	tagTypes "github.com/Abdelrahman-habib/expense-tracker/internal/tags/types"
 	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -40,8 +51,10 @@ type ContactIntegrationTestSuite struct {
 	suite.Suite
 	container testcontainers.Container
 	service   db.Service
+	dbConfig  config.DatabaseConfig
 	pool      *pgxpool.Pool
 	handler   *handlers.ContactHandler
+	jobs      *worker.Runner
 	router    *chi.Mux
 	userID    uuid.UUID
 	ctx       context.Context
@@ -110,6 +123,7 @@ func (s *ContactIntegrationTestSuite) SetupSuite() {
 	// Initialize DB service
 	dbService := db.NewService(cfg)
 	s.service = dbService
+	s.dbConfig = cfg
 
 	// Get connection pool
 	pool, err := pgxpool.New(s.ctx, cfg.GetDSN())
@@ -133,21 +147,45 @@ func (s *ContactIntegrationTestSuite) SetupSuite() {
 	// Initialize components
 	logger := zap.NewNop()
 	repo := repository.New(dbService.Queries())
-	contactService := service.NewContactService(repo, logger)
-	s.handler = handlers.NewContactHandler(contactService, logger)
+	s.jobs = worker.NewRunner(dbService, config.JobsConfig{
+		Workers:      1,
+		ChunkSize:    3,
+		RetryDelay:   10 * time.Millisecond,
+		PollInterval: 50 * time.Millisecond,
+	}, logger)
+	blobs, err := blob.NewFileStore(s.T().TempDir())
+	require.NoError(s.T(), err)
+	s.jobs.Register(jobTypes.JobTypeContactImport, service.ImportProcessor(nil, nil))
+	s.jobs.RegisterTask(jobTypes.JobTypeContactExport, service.ExportTask(repo, blobs))
+	contactService := service.NewContactService(repo, s.jobs, blobs, types.ExportPolicy{SyncMaxRows: exportSyncMaxRows}, nil, nil, nil, logger)
+	s.handler = handlers.NewContactHandler(contactService, coreTypes.DefaultLimitPolicy(), logger)
+	jobHandler := jobHandlers.NewJobHandler(jobService.NewJobService(jobRepository.New(dbService.Queries()), logger), logger)
 
 	// Setup router
 	router := chi.NewRouter()
 	router.Route("/contacts", func(r chi.Router) {
 		r.Get("/search", s.handler.SearchContacts)
+		r.Get("/by-company", s.handler.ListContactCompanies)
 		r.Get("/paginated", s.handler.ListContactsPaginated)
+		r.Get("/trash", s.handler.ListDeletedContacts)
 		r.Post("/", s.handler.CreateContact)
+		r.Post("/import", s.handler.ImportContacts)
+		r.Post("/validate-batch", s.handler.ValidateContacts)
+		r.Get("/export", s.handler.ExportContacts)
+		r.Post("/export-jobs", s.handler.StartContactExport)
+		r.Get("/export-jobs/{id}", s.handler.GetContactExport)
+		r.Get("/export-jobs/{id}/download", s.handler.DownloadContactExport)
+		r.Put("/external/{source}/{external_id}", s.handler.UpsertContactByExternalRef)
 		r.Route("/{id}", func(r chi.Router) {
 			r.Get("/", s.handler.GetContact)
 			r.Put("/", s.handler.UpdateContact)
 			r.Delete("/", s.handler.DeleteContact)
+			r.Post("/restore", s.handler.RestoreContact)
+			r.Post("/relationships", s.handler.CreateContactRelationship)
+			r.Delete("/relationships/{relationshipId}", s.handler.DeleteContactRelationship)
 		})
 	})
+	router.Get("/jobs/{id}", jobHandler.GetJob)
 	s.router = router
 }
 
@@ -194,6 +232,8 @@ func stringPtr(v string) *string {
 func (s *ContactIntegrationTestSuite) clearContacts() {
 	_, err := s.pool.Exec(s.ctx, `DELETE FROM contacts WHERE user_id = $1`, s.userID)
 	require.NoError(s.T(), err)
+	_, err = s.pool.Exec(s.ctx, `DELETE FROM jobs WHERE user_id = $1`, s.userID)
+	require.NoError(s.T(), err)
 }
 
 // Helper method to create a test contact
@@ -208,7 +248,7 @@ func (s *ContactIntegrationTestSuite) createTestContact() types.Contact {
 		City:          stringPtr("New York"),
 		StateProvince: stringPtr("NY"),
 		ZipPostalCode: stringPtr("10001"),
-		Tags:          []uuid.UUID{uuid.New(), uuid.New()},
+		Tags:          s.createTestTags(2),
 	}
 
 	payloadBytes, err := json.Marshal(createPayload)
@@ -369,6 +409,13 @@ func (s *ContactIntegrationTestSuite) testDeleteContact(contact *types.Contact)
 	w = httptest.NewRecorder()
 	s.router.ServeHTTP(w, req)
 	s.Equal(http.StatusNotFound, w.Code)
+
+	// deleting it again finds nothing to delete
+	req = s.newAuthenticatedRequest(http.MethodDelete, "/contacts/"+contact.ContactID.String(), nil)
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	s.Equal(http.StatusNotFound, w.Code)
 }
 
 // Helper method to create multiple test contacts
@@ -400,15 +447,21 @@ func (s *ContactIntegrationTestSuite) createTestContacts(count int) []types.Cont
 		s.Require().NoError(err)
 
 		contactData := response["data"].(map[string]interface{})
-		createdAt, err := time.Parse(time.RFC3339, contactData["createdAt"].(string))
+		contactID := uuid.MustParse(contactData["contactId"].(string))
+		_, err = time.Parse(coreTypes.TimestampLayout, contactData["createdAt"].(string))
+		s.Require().NoError(err)
+
+		// responses carry createdAt to the millisecond, cursors are built from the exact one
+		var createdAt time.Time
+		err = s.pool.QueryRow(s.ctx, "SELECT created_at FROM contacts WHERE contact_id = $1", contactID).Scan(&createdAt)
 		s.Require().NoError(err)
 
 		contacts[count-1-i] = types.Contact{ // Store in reverse order
-			ContactID: uuid.MustParse(contactData["contactId"].(string)),
+			ContactID: contactID,
 			Name:      contactData["name"].(string),
 			Phone:     stringPtr(contactData["phone"].(string)),
 			Email:     stringPtr(contactData["email"].(string)),
-			CreatedAt: createdAt,
+			CreatedAt: coreTypes.NewTimestamp(createdAt),
 		}
 		time.Sleep(time.Millisecond * 10) // Ensure distinct timestamps
 	}
@@ -453,7 +506,7 @@ func (s *ContactIntegrationTestSuite) TestListContactsPaginated() {
 			name: "second page with next_token",
 			queryParams: map[string]string{
 				"limit":      "5",
-				"next_token": coreTypes.EncodeCursor(contacts[4].CreatedAt, contacts[4].ContactID),
+				"next_token": coreTypes.EncodeCursor(contacts[4].CreatedAt.Time, contacts[4].ContactID, s.userID),
 			},
 			expectedStatus:  http.StatusOK,
 			expectedLen:     5,
@@ -782,6 +835,80 @@ func (s *ContactIntegrationTestSuite) TestSearchContacts() {
 	}
 }
 
+func (s *ContactIntegrationTestSuite) TestContactsByCompany() {
+	contacts := []types.ContactCreatePayload{
+		{Name: "Amy Baker", Company: stringPtr("  Acme   Inc. ")},
+		{Name: "Carl Cole", Company: stringPtr("Acme Inc.")},
+		{Name: "Dana Diaz", Company: stringPtr("Globex")},
+		{Name: "Eli Evans"},
+	}
+
+	for _, c := range contacts {
+		payloadBytes, err := json.Marshal(c)
+		s.Require().NoError(err)
+
+		req := s.newAuthenticatedRequest(http.MethodPost, "/contacts", bytes.NewReader(payloadBytes))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+		s.Require().Equal(http.StatusCreated, w.Code)
+	}
+
+	s.Run("grouped by count", func() {
+		req := s.newAuthenticatedRequest(http.MethodGet, "/contacts/by-company?contacts_limit=1", nil)
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+		s.Require().Equal(http.StatusOK, w.Code)
+
+		var response struct {
+			Data []types.CompanyContacts `json:"data"`
+		}
+		s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+		s.Require().Len(response.Data, 2)
+		// normalization merges both spellings of the company
+		s.Equal("Acme Inc.", response.Data[0].Company)
+		s.Equal(int64(2), response.Data[0].ContactCount)
+		s.Len(response.Data[0].Contacts, 1)
+		s.Equal("Globex", response.Data[1].Company)
+	})
+
+	s.Run("grouped by name", func() {
+		req := s.newAuthenticatedRequest(http.MethodGet, "/contacts/by-company?sort=name", nil)
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+		s.Require().Equal(http.StatusOK, w.Code)
+
+		var response struct {
+			Data []types.CompanyContacts `json:"data"`
+		}
+		s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+		s.Require().Len(response.Data, 2)
+		s.Equal("Acme Inc.", response.Data[0].Company)
+		s.Len(response.Data[0].Contacts, 2)
+	})
+
+	s.Run("search by company", func() {
+		req := s.newAuthenticatedRequest(http.MethodGet, "/contacts/search?company_q=Globex", nil)
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+		s.Require().Equal(http.StatusOK, w.Code)
+
+		var response struct {
+			Data []types.Contact `json:"data"`
+		}
+		s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+		s.Require().Len(response.Data, 1)
+		s.Equal("Dana Diaz", response.Data[0].Name)
+	})
+
+	s.Run("invalid sort", func() {
+		req := s.newAuthenticatedRequest(http.MethodGet, "/contacts/by-company?sort=size", nil)
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+		s.Equal(http.StatusBadRequest, w.Code)
+	})
+}
+
 func (s *ContactIntegrationTestSuite) TestConcurrentUpdates() {
 	// Create a contact
 	contact := s.createTestContact()
@@ -811,11 +938,20 @@ func (s *ContactIntegrationTestSuite) TestConcurrentUpdates() {
 			w := httptest.NewRecorder()
 			s.router.ServeHTTP(w, req)
 
-			// All updates should succeed
+			// All updates should succeed, one after the other
 			s.Equal(http.StatusOK, w.Code)
 		}(i)
 	}
 	wg.Wait()
+
+	// the contact holds the name and the phone of the same update
+	fetched, err := s.service.Queries().GetContact(s.ctx, db.GetContactParams{ContactID: contact.ContactID, UserID: s.userID})
+	s.Require().NoError(err)
+	var last int
+	_, err = fmt.Sscanf(fetched.Name, "Updated Name %d", &last)
+	s.Require().NoError(err)
+	phone := types.CleanPhoneNumber(fmt.Sprintf("+1-555-%03d-%04d", last+1, last+1))
+	s.verifyContactState(contact.ContactID, fetched.Name, &phone)
 }
 
 func (s *ContactIntegrationTestSuite) TestDatabaseConstraintsAndValidation() {
@@ -1010,7 +1146,7 @@ func (s *ContactIntegrationTestSuite) TestComplexContactLifecycle() {
 			City:          stringPtr("New York"),
 			StateProvince: stringPtr("NY"),
 			ZipPostalCode: stringPtr("10001"),
-			Tags:          []uuid.UUID{uuid.New(), uuid.New()},
+			Tags:          s.createTestTags(2),
 		}
 
 		payloadBytes, err := json.Marshal(createPayload)
@@ -1046,7 +1182,7 @@ func (s *ContactIntegrationTestSuite) TestComplexContactLifecycle() {
 				Phone:        stringPtr("+1-555-987-6543"),
 				Email:        stringPtr("final@example.com"),
 				AddressLine1: stringPtr("789 Main St"),
-				Tags:         []uuid.UUID{uuid.New(), uuid.New(), uuid.New()},
+				Tags:         s.createTestTags(3),
 			},
 		}
 
@@ -1107,7 +1243,7 @@ func (s *ContactIntegrationTestSuite) TestResponsePayloadStructure() {
 			City:          stringPtr("New York"),
 			StateProvince: stringPtr("NY"),
 			ZipPostalCode: stringPtr("10001"),
-			Tags:          []uuid.UUID{uuid.New(), uuid.New()},
+			Tags:          s.createTestTags(2),
 		}
 
 		payloadBytes, err := json.Marshal(createPayload)
@@ -1153,9 +1289,9 @@ func (s *ContactIntegrationTestSuite) TestResponsePayloadStructure() {
 		s.NotEmpty(data["updatedAt"])
 
 		// Verify timestamps are in correct format
-		_, err = time.Parse(time.RFC3339, data["createdAt"].(string))
+		_, err = time.Parse(coreTypes.TimestampLayout, data["createdAt"].(string))
 		s.NoError(err)
-		_, err = time.Parse(time.RFC3339, data["updatedAt"].(string))
+		_, err = time.Parse(coreTypes.TimestampLayout, data["updatedAt"].(string))
 		s.NoError(err)
 
 		// Verify tags array
@@ -1167,3 +1303,867 @@ func (s *ContactIntegrationTestSuite) TestResponsePayloadStructure() {
 		}
 	})
 }
+
+// startJobs runs the job workers until the returned function is called
+func (s *ContactIntegrationTestSuite) startJobs() func() {
+	ctx, cancel := context.WithCancel(s.ctx)
+	s.Require().NoError(s.jobs.Start(ctx))
+	return func() {
+		cancel()
+		s.jobs.Wait()
+	}
+}
+
+// waitForJob polls GET /jobs/{id} until the job finishes
+func (s *ContactIntegrationTestSuite) waitForJob(jobID string) map[string]interface{} {
+	var job map[string]interface{}
+	s.Require().Eventually(func() bool {
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, s.newAuthenticatedRequest(http.MethodGet, "/jobs/"+jobID, nil))
+		if w.Code != http.StatusOK {
+			return false
+		}
+
+		var response map[string]interface{}
+		s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+		job = response["data"].(map[string]interface{})
+		status := job["status"].(string)
+		return status == string(jobTypes.JobStatusCompleted) || status == string(jobTypes.JobStatusFailed)
+	}, 10*time.Second, 50*time.Millisecond)
+	return job
+}
+
+func (s *ContactIntegrationTestSuite) countContacts() int {
+	var count int
+	err := s.pool.QueryRow(s.ctx, `SELECT COUNT(*) FROM contacts WHERE user_id = $1`, s.userID).Scan(&count)
+	s.Require().NoError(err)
+	return count
+}
+
+func (s *ContactIntegrationTestSuite) TestImportContacts() {
+	stop := s.startJobs()
+	defer stop()
+
+	contacts := make([]types.ContactCreatePayload, 8)
+	for i := range contacts {
+		contacts[i] = types.ContactCreatePayload{
+			Name:    fmt.Sprintf("Imported Contact %d", i),
+			Company: stringPtr("  Acme   Inc. "),
+		}
+	}
+	// an invalid row in the middle of the second chunk
+	contacts[4].Email = stringPtr("nope")
+
+	body, err := json.Marshal(types.ContactImportPayload{Contacts: contacts})
+	s.Require().NoError(err)
+
+	req := s.newAuthenticatedRequest(http.MethodPost, "/contacts/import", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	s.Require().Equal(http.StatusAccepted, w.Code)
+
+	var response map[string]interface{}
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+	data := response["data"].(map[string]interface{})
+	s.Equal(float64(8), data["total"])
+
+	job := s.waitForJob(data["jobId"].(string))
+	s.Equal(string(jobTypes.JobStatusCompleted), job["status"])
+	s.Equal(float64(8), job["processed"])
+	s.Equal(float64(7), job["succeeded"])
+	s.Equal(float64(1), job["failed"])
+
+	rowErrors := job["errors"].([]interface{})
+	s.Require().Len(rowErrors, 1)
+	s.Equal(float64(4), rowErrors[0].(map[string]interface{})["index"])
+
+	s.Equal(7, s.countContacts())
+
+	var company string
+	err = s.pool.QueryRow(s.ctx, `SELECT DISTINCT company FROM contacts WHERE user_id = $1`, s.userID).Scan(&company)
+	s.Require().NoError(err)
+	s.Equal("Acme Inc.", company)
+}
+
+// exportSyncMaxRows keeps synchronous exports small enough for the tests to go over it
+const exportSyncMaxRows = 5
+
+func (s *ContactIntegrationTestSuite) TestExportJobs() {
+	stop := s.startJobs()
+	defer stop()
+
+	contacts := s.createTestContacts(exportSyncMaxRows + 1)
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, s.newAuthenticatedRequest(method, path, nil))
+		return w
+	}
+	decode := func(w *httptest.ResponseRecorder) map[string]interface{} {
+		var response map[string]interface{}
+		s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+		return response["data"].(map[string]interface{})
+	}
+
+	s.Run("synchronous export refuses address books over the threshold", func() {
+		w := serve(http.MethodGet, "/contacts/export")
+		s.Equal(http.StatusBadRequest, w.Code)
+		s.Contains(w.Body.String(), "/contacts/export-jobs")
+	})
+
+	s.Run("export job produces the file", func() {
+		w := serve(http.MethodPost, "/contacts/export-jobs?format=csv")
+		s.Require().Equal(http.StatusAccepted, w.Code)
+		started := decode(w)
+		s.Equal(float64(exportSyncMaxRows+1), started["total"])
+		s.Equal("text/csv", started["format"])
+		jobID := started["jobId"].(string)
+
+		var export map[string]interface{}
+		s.Require().Eventually(func() bool {
+			w := serve(http.MethodGet, "/contacts/export-jobs/"+jobID)
+			s.Require().Equal(http.StatusOK, w.Code)
+			export = decode(w)
+			return export["status"] == string(jobTypes.JobStatusCompleted)
+		}, 10*time.Second, 50*time.Millisecond)
+		s.Equal(float64(exportSyncMaxRows+1), export["processed"])
+		s.Equal("/contacts/export-jobs/"+jobID+"/download", export["downloadUrl"])
+
+		w = serve(http.MethodGet, export["downloadUrl"].(string))
+		s.Require().Equal(http.StatusOK, w.Code)
+		s.Equal("text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		s.Contains(w.Header().Get("Content-Disposition"), "attachment")
+		rows, err := csv.NewReader(w.Body).ReadAll()
+		s.Require().NoError(err)
+		s.Equal(types.CSVHeader, rows[0])
+		s.Len(rows, exportSyncMaxRows+2)
+		s.Equal(contacts[0].ContactID.String(), rows[1][0])
+	})
+
+	s.Run("other jobs aren't exports", func() {
+		job, err := s.jobs.Enqueue(s.ctx, s.userID, jobTypes.JobTypeContactImport, 1, []types.ContactCreatePayload{{Name: "Imported"}})
+		s.Require().NoError(err)
+		s.Equal(http.StatusNotFound, serve(http.MethodGet, "/contacts/export-jobs/"+job.JobID.String()).Code)
+		s.Equal(http.StatusNotFound, serve(http.MethodGet, "/contacts/export-jobs/"+uuid.NewString()+"/download").Code)
+	})
+
+	s.Run("unknown format", func() {
+		s.Equal(http.StatusBadRequest, serve(http.MethodPost, "/contacts/export-jobs?format=xml").Code)
+	})
+}
+
+func (s *ContactIntegrationTestSuite) TestValidateContacts() {
+	_, otherTagID := s.createOtherUser()
+	ownTags := s.createTestTags(1)
+
+	contacts := []types.ContactCreatePayload{
+		{Name: "Valid Contact", Tags: ownTags},
+		{Name: "Bad Email", Email: stringPtr("nope")},
+		{Name: "Foreign Tag", Tags: []uuid.UUID{ownTags[0], otherTagID}},
+	}
+	body, err := json.Marshal(types.ContactImportPayload{Contacts: contacts})
+	s.Require().NoError(err)
+
+	req := s.newAuthenticatedRequest(http.MethodPost, "/contacts/validate-batch", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	s.Require().Equal(http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+	data := response["data"].(map[string]interface{})
+	s.Equal(float64(1), data["valid"])
+	s.Equal(float64(2), data["invalid"])
+
+	results := data["results"].([]interface{})
+	s.Require().Len(results, 3)
+	s.Equal(true, results[0].(map[string]interface{})["valid"])
+	s.Contains(results[1].(map[string]interface{})["error"], "email")
+	s.Equal("tag "+otherTagID.String()+" not owned by user", results[2].(map[string]interface{})["error"])
+
+	// nothing is written
+	s.Equal(0, s.countContacts())
+}
+
+func (s *ContactIntegrationTestSuite) TestImportContactsResume() {
+	contacts := make([]types.ContactCreatePayload, 6)
+	for i := range contacts {
+		contacts[i] = types.ContactCreatePayload{Name: fmt.Sprintf("Resumed Contact %d", i)}
+	}
+
+	job, err := s.jobs.Enqueue(s.ctx, s.userID, jobTypes.JobTypeContactImport, len(contacts), contacts)
+	s.Require().NoError(err)
+
+	// simulate a process that committed the first chunk and died while running
+	_, err = s.pool.Exec(s.ctx, `
+		UPDATE jobs SET status = 'running', processed = 3, succeeded = 3 WHERE job_id = $1
+	`, job.JobID)
+	s.Require().NoError(err)
+
+	stop := s.startJobs()
+	defer stop()
+
+	finished := s.waitForJob(job.JobID.String())
+	s.Equal(string(jobTypes.JobStatusCompleted), finished["status"])
+	s.Equal(float64(6), finished["processed"])
+	s.Equal(float64(6), finished["succeeded"])
+
+	// only the rows after the saved progress are written
+	s.Equal(3, s.countContacts())
+}
+
+func (s *ContactIntegrationTestSuite) TestImportContactsUnknownJobType() {
+	var jobID uuid.UUID
+	err := s.pool.QueryRow(s.ctx, `
+		INSERT INTO jobs (user_id, type, total, payload) VALUES ($1, 'unknown', 1, '[]') RETURNING job_id
+	`, s.userID).Scan(&jobID)
+	s.Require().NoError(err)
+
+	stop := s.startJobs()
+	defer stop()
+
+	job := s.waitForJob(jobID.String())
+	s.Equal(string(jobTypes.JobStatusFailed), job["status"])
+	s.Contains(job["error"], "no processor registered")
+}
+
+func (s *ContactIntegrationTestSuite) TestGetJobNotFound() {
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, s.newAuthenticatedRequest(http.MethodGet, "/jobs/"+uuid.New().String(), nil))
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+// createTestTags creates tags owned by the test user; tags the user doesn't own are dropped on write
+func (s *ContactIntegrationTestSuite) createTestTags(count int) []uuid.UUID {
+	tags := make([]uuid.UUID, count)
+	for i := range tags {
+		err := s.pool.QueryRow(s.ctx, `
+			INSERT INTO tags (user_id, name) VALUES ($1, $2) RETURNING tag_id
+		`, s.userID, fmt.Sprintf("test tag %d", i)).Scan(&tags[i])
+		s.Require().NoError(err)
+	}
+	return tags
+}
+
+// createOtherUser creates a second user owning a single tag, returning both IDs
+func (s *ContactIntegrationTestSuite) createOtherUser() (uuid.UUID, uuid.UUID) {
+	otherUserID := uuid.New()
+	_, err := s.pool.Exec(s.ctx, `
+		INSERT INTO users (user_id, clerk_ex_user_id, name, email)
+		VALUES ($1, $2, $3, $4)
+	`, otherUserID, otherUserID.String(), "cit_other_"+otherUserID.String(), "cit_other_"+otherUserID.String()+"@example.com")
+	s.Require().NoError(err)
+
+	var otherTagID uuid.UUID
+	err = s.pool.QueryRow(s.ctx, `
+		INSERT INTO tags (user_id, name) VALUES ($1, 'other user secret') RETURNING tag_id
+	`, otherUserID).Scan(&otherTagID)
+	s.Require().NoError(err)
+
+	s.T().Cleanup(func() {
+		_, _ = s.pool.Exec(s.ctx, `DELETE FROM users WHERE user_id = $1`, otherUserID)
+	})
+	return otherUserID, otherTagID
+}
+
+func (s *ContactIntegrationTestSuite) TestTagOwnershipAcrossUsers() {
+	_, otherTagID := s.createOtherUser()
+	ownTags := s.createTestTags(1)
+	tagRepo := tagRepository.NewTagRepository(s.service.Queries())
+
+	s.Run("create drops other user's tags", func() {
+		created, err := s.service.Queries().CreateContact(s.ctx, db.CreateContactParams{
+			UserID: s.userID,
+			Name:   "Tag Owner Contact",
+			Tags:   []uuid.UUID{otherTagID, ownTags[0]},
+		})
+		s.Require().NoError(err)
+		s.Equal(ownTags, created.Tags)
+	})
+
+	s.Run("update drops other user's tags", func() {
+		contact := s.createTestContact()
+		body, err := json.Marshal(map[string]interface{}{
+			"name": contact.Name,
+			"tags": []uuid.UUID{otherTagID},
+		})
+		s.Require().NoError(err)
+
+		req := s.newAuthenticatedRequest(http.MethodPut, "/contacts/"+contact.ContactID.String(), bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+		s.Require().Equal(http.StatusOK, w.Code)
+
+		var tags []uuid.UUID
+		err = s.pool.QueryRow(s.ctx, `SELECT tags FROM contacts WHERE contact_id = $1`, contact.ContactID).Scan(&tags)
+		s.Require().NoError(err)
+		s.Empty(tags)
+	})
+
+	s.Run("legacy foreign tags never resolve", func() {
+		// written around the repository, as rows stored before ownership was enforced could be
+		_, err := s.pool.Exec(s.ctx, `
+			INSERT INTO contacts (user_id, name, tags) VALUES ($1, 'Legacy Contact', $2)
+		`, s.userID, []uuid.UUID{otherTagID, ownTags[0]})
+		s.Require().NoError(err)
+
+		tags, err := tagRepo.ListTagsByIDs(s.ctx, s.userID, []uuid.UUID{otherTagID, ownTags[0]})
+		s.Require().NoError(err)
+		s.Require().Len(tags, 1)
+		s.Equal(ownTags[0], tags[0].TagID)
+		s.NotEqual("other user secret", tags[0].Name)
+
+		_, err = tagRepo.GetTag(s.ctx, s.userID, otherTagID)
+		s.True(coreErrors.IsErrorType(err, coreErrors.ErrorTypeNotFound))
+
+		affected, err := tagRepo.UnassignTag(s.ctx, s.userID, tagTypes.TagUnassignPayload{
+			TagID:        otherTagID,
+			ResourceType: tagTypes.ResourceTypeContact,
+			IDs:          []uuid.UUID{uuid.New()},
+		})
+		s.Require().NoError(err)
+		s.Zero(affected)
+	})
+
+	s.Run("strict mode rejects other user's tags", func() {
+		cfg := s.dbConfig
+		cfg.StrictTagOwnership = true
+		strictService := db.NewService(cfg)
+		defer strictService.Close()
+		strictRepo := repository.New(strictService.Queries())
+
+		_, err := strictRepo.CreateContact(s.ctx, types.ContactCreatePayload{
+			Name: "Strict Contact",
+			Tags: []uuid.UUID{ownTags[0], otherTagID},
+		}, s.userID)
+		s.Require().Error(err)
+		s.True(coreErrors.IsErrorType(err, coreErrors.ErrorTypeValidation))
+
+		created, err := strictRepo.CreateContact(s.ctx, types.ContactCreatePayload{
+			Name: "Strict Contact",
+			Tags: ownTags,
+		}, s.userID)
+		s.Require().NoError(err)
+		s.Equal(ownTags, created.Tags)
+	})
+}
+
+func (s *ContactIntegrationTestSuite) TestUnassignTagFromContacts() {
+	tags := s.createTestTags(2)
+	tagRepo := tagRepository.NewTagRepository(s.service.Queries())
+
+	var tagged []uuid.UUID
+	for i := 0; i < 3; i++ {
+		created, err := s.service.Queries().CreateContact(s.ctx, db.CreateContactParams{
+			UserID: s.userID,
+			Name:   fmt.Sprintf("Tagged Contact %d", i),
+			Tags:   tags,
+		})
+		s.Require().NoError(err)
+		tagged = append(tagged, created.ContactID)
+	}
+	untagged, err := s.service.Queries().CreateContact(s.ctx, db.CreateContactParams{
+		UserID: s.userID,
+		Name:   "Untagged Contact",
+	})
+	s.Require().NoError(err)
+
+	affected, err := tagRepo.UnassignTag(s.ctx, s.userID, tagTypes.TagUnassignPayload{
+		TagID:        tags[0],
+		ResourceType: tagTypes.ResourceTypeContact,
+		IDs:          append(tagged[:2:2], untagged.ContactID),
+	})
+	s.Require().NoError(err)
+	s.Equal(int64(2), affected, "contacts without the tag are not counted")
+
+	var remaining []uuid.UUID
+	err = s.pool.QueryRow(s.ctx, `SELECT tags FROM contacts WHERE contact_id = $1`, tagged[0]).Scan(&remaining)
+	s.Require().NoError(err)
+	s.Equal(tags[1:], remaining)
+
+	err = s.pool.QueryRow(s.ctx, `SELECT tags FROM contacts WHERE contact_id = $1`, tagged[2]).Scan(&remaining)
+	s.Require().NoError(err)
+	s.Equal(tags, remaining, "contacts not listed keep the tag")
+
+	// removing it again is a no-op
+	affected, err = tagRepo.UnassignTag(s.ctx, s.userID, tagTypes.TagUnassignPayload{
+		TagID:        tags[0],
+		ResourceType: tagTypes.ResourceTypeContact,
+		IDs:          tagged[:2],
+	})
+	s.Require().NoError(err)
+	s.Zero(affected)
+}
+
+func (s *ContactIntegrationTestSuite) TestUniqueContactEmails() {
+	email := func(address string) *string { return &address }
+
+	// duplicates written while the setting is off are allowed
+	repo := repository.New(s.service.Queries())
+	legacy, err := repo.CreateContact(s.ctx, types.ContactCreatePayload{Name: "Legacy 1", Email: email("shared@example.com")}, s.userID)
+	s.Require().NoError(err)
+	_, err = repo.CreateContact(s.ctx, types.ContactCreatePayload{Name: "Legacy 2", Email: email("shared@example.com")}, s.userID)
+	s.Require().NoError(err)
+
+	cfg := s.dbConfig
+	cfg.UniqueContactEmails = true
+	uniqueService := db.NewService(cfg)
+	defer uniqueService.Close()
+	uniqueRepo := repository.New(uniqueService.Queries())
+
+	first, err := uniqueRepo.CreateContact(s.ctx, types.ContactCreatePayload{Name: "John Doe", Email: email("john@example.com")}, s.userID)
+	s.Require().NoError(err)
+
+	s.Run("rejects a taken email regardless of case", func() {
+		_, err := uniqueRepo.CreateContact(s.ctx, types.ContactCreatePayload{Name: "Johnny", Email: email("John@Example.com")}, s.userID)
+		s.Require().Error(err)
+		s.True(coreErrors.IsErrorType(err, coreErrors.ErrorTypeConflict))
+		s.Equal("email: already used by another contact.", err.Error())
+	})
+
+	s.Run("contacts without an email are not affected", func() {
+		_, err := uniqueRepo.CreateContact(s.ctx, types.ContactCreatePayload{Name: "No Email 1"}, s.userID)
+		s.Require().NoError(err)
+		_, err = uniqueRepo.CreateContact(s.ctx, types.ContactCreatePayload{Name: "No Email 2"}, s.userID)
+		s.Require().NoError(err)
+	})
+
+	s.Run("rejects changing the email to a taken one", func() {
+		other, err := uniqueRepo.CreateContact(s.ctx, types.ContactCreatePayload{Name: "Jane Doe", Email: email("jane@example.com")}, s.userID)
+		s.Require().NoError(err)
+
+		_, err = uniqueRepo.UpdateContact(s.ctx, types.ContactUpdatePayload{ContactID: other.ContactID, Name: "Jane Doe", Email: email("john@example.com")}, s.userID)
+		s.True(coreErrors.IsErrorType(err, coreErrors.ErrorTypeConflict))
+	})
+
+	s.Run("trashed contacts free their email until restored", func() {
+		s.Require().NoError(uniqueRepo.DeleteContact(s.ctx, first.ContactID, s.userID, nil))
+		_, err := uniqueRepo.CreateContact(s.ctx, types.ContactCreatePayload{Name: "New John", Email: email("john@example.com")}, s.userID)
+		s.Require().NoError(err)
+
+		_, err = uniqueRepo.RestoreContact(s.ctx, first.ContactID, s.userID)
+		s.True(coreErrors.IsErrorType(err, coreErrors.ErrorTypeConflict))
+	})
+
+	s.Run("existing duplicates stay editable", func() {
+		updated, err := uniqueRepo.UpdateContact(s.ctx, types.ContactUpdatePayload{ContactID: legacy.ContactID, Name: "Legacy Renamed", Email: email("shared@example.com")}, s.userID)
+		s.Require().NoError(err)
+		s.Equal("Legacy Renamed", updated.Name)
+	})
+}
+
+func (s *ContactIntegrationTestSuite) TestTrashAndRestore() {
+	contact := s.createTestContact()
+	s.testDeleteContact(&contact)
+
+	// the trashed contact is hidden from regular lists
+	req := s.newAuthenticatedRequest(http.MethodGet, "/contacts/paginated", nil)
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var response struct {
+		Data []types.Contact `json:"data"`
+	}
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+	s.Empty(response.Data)
+
+	req = s.newAuthenticatedRequest(http.MethodGet, "/contacts/trash", nil)
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+	s.Require().Len(response.Data, 1)
+	s.Equal(contact.ContactID, response.Data[0].ContactID)
+	s.NotNil(response.Data[0].DeletedAt)
+
+	req = s.newAuthenticatedRequest(http.MethodPost, "/contacts/"+contact.ContactID.String()+"/restore", nil)
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	s.Equal(http.StatusOK, w.Code)
+	s.verifyContactState(contact.ContactID, contact.Name, nil)
+
+	// restoring an active contact finds nothing in the trash
+	req = s.newAuthenticatedRequest(http.MethodPost, "/contacts/"+contact.ContactID.String()+"/restore", nil)
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *ContactIntegrationTestSuite) TestCreatedAndUpdatedBy() {
+	contact := s.createTestContact()
+	get := func() types.Contact {
+		req := s.newAuthenticatedRequest(http.MethodGet, "/contacts/"+contact.ContactID.String(), nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", contact.ContactID.String())
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+		s.Require().Equal(http.StatusOK, w.Code)
+		var response struct {
+			Data types.Contact `json:"data"`
+		}
+		s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+		return response.Data
+	}
+
+	created := get()
+	s.Require().NotNil(created.CreatedBy)
+	s.Require().NotNil(created.UpdatedBy)
+	s.Equal(s.userID, *created.CreatedBy)
+	s.Equal(s.userID, *created.UpdatedBy)
+
+	// the update stamps the acting user and leaves the creator alone
+	_, err := s.pool.Exec(s.ctx, `UPDATE contacts SET updated_by = NULL WHERE contact_id = $1`, contact.ContactID)
+	s.Require().NoError(err)
+	s.testUpdateContactName(&contact)
+	updated := get()
+	s.Require().NotNil(updated.UpdatedBy)
+	s.Equal(s.userID, *updated.UpdatedBy)
+	s.Equal(created.CreatedBy, updated.CreatedBy)
+}
+
+func (s *ContactIntegrationTestSuite) TestPurgeTrash() {
+	expired := s.createTestContact()
+	recent := s.createTestContact()
+	s.testDeleteContact(&expired)
+	s.testDeleteContact(&recent)
+
+	_, err := s.pool.Exec(s.ctx, `UPDATE contacts SET deleted_at = NOW() - INTERVAL '2 days' WHERE contact_id = $1`, expired.ContactID)
+	s.Require().NoError(err)
+
+	blobs, err := blob.NewFileStore(s.T().TempDir())
+	s.Require().NoError(err)
+	janitor := app.NewJanitor(s.service.Queries(), config.JanitorConfig{BatchSize: 1}, config.TrashConfig{Retention: 24 * time.Hour}, config.BackupsConfig{}, blobs, zap.NewNop())
+	_, err = janitor.Run(s.ctx)
+	s.Require().NoError(err)
+	s.Equal(int64(1), janitor.Cleaned()["contacts"])
+
+	var remaining []uuid.UUID
+	rows, err := s.pool.Query(s.ctx, `SELECT contact_id FROM contacts WHERE user_id = $1`, s.userID)
+	s.Require().NoError(err)
+	defer rows.Close()
+	for rows.Next() {
+		var id uuid.UUID
+		s.Require().NoError(rows.Scan(&id))
+		remaining = append(remaining, id)
+	}
+	s.Equal([]uuid.UUID{recent.ContactID}, remaining)
+}
+
+// listContactIDs fetches one page of /contacts/paginated and returns its contact IDs and next_token
+func (s *ContactIntegrationTestSuite) listContactIDs(limit int, nextToken string) ([]uuid.UUID, string) {
+	values := url.Values{"limit": {strconv.Itoa(limit)}}
+	if nextToken != "" {
+		values.Set("next_token", nextToken)
+	}
+	req := s.newAuthenticatedRequest(http.MethodGet, "/contacts/paginated?"+values.Encode(), nil)
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	s.Require().Equal(http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+
+	var ids []uuid.UUID
+	for _, item := range response["data"].([]interface{}) {
+		ids = append(ids, uuid.MustParse(item.(map[string]interface{})["contactId"].(string)))
+	}
+	token, _ := response["meta"].(map[string]interface{})["next_token"].(string)
+	return ids, token
+}
+
+func (s *ContactIntegrationTestSuite) TestPaginationWithConcurrentInserts() {
+	contacts := s.createTestContacts(5) // newest first
+
+	// contacts[1] ends page one and shares its timestamp with contacts[2],
+	// so the next page has to split the tie on contact_id alone
+	_, err := s.pool.Exec(s.ctx, `UPDATE contacts SET created_at = $1 WHERE contact_id = $2`,
+		contacts[1].CreatedAt.Time, contacts[2].ContactID)
+	s.Require().NoError(err)
+
+	firstPage, nextToken := s.listContactIDs(2, "")
+	s.Require().Len(firstPage, 2)
+	s.Require().NotEmpty(nextToken)
+
+	// contacts added while the client is paging land in front of the cursor
+	var late []uuid.UUID
+	for i := 0; i < 3; i++ {
+		payloadBytes, err := json.Marshal(types.ContactCreatePayload{Name: fmt.Sprintf("Late Contact %d", i+1)})
+		s.Require().NoError(err)
+		req := s.newAuthenticatedRequest(http.MethodPost, "/contacts", bytes.NewReader(payloadBytes))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+		s.Require().Equal(http.StatusCreated, w.Code)
+
+		var response map[string]interface{}
+		s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+		late = append(late, uuid.MustParse(response["data"].(map[string]interface{})["contactId"].(string)))
+	}
+
+	seen := make(map[uuid.UUID]int)
+	for _, id := range firstPage {
+		seen[id]++
+	}
+	for pages := 0; nextToken != ""; pages++ {
+		s.Require().Less(pages, 10, "paging should end")
+		var page []uuid.UUID
+		page, nextToken = s.listContactIDs(2, nextToken)
+		for _, id := range page {
+			seen[id]++
+		}
+	}
+
+	s.Len(seen, len(contacts), "every original contact exactly once and none of the late ones")
+	for _, c := range contacts {
+		s.Equal(1, seen[c.ContactID], c.Name)
+	}
+	for _, id := range late {
+		s.Zero(seen[id])
+	}
+
+	s.Run("a fresh first page starts with the late contacts", func() {
+		page, _ := s.listContactIDs(3, "")
+		s.ElementsMatch(late, page)
+	})
+
+	s.Run("rows stamped ahead of the app clock are not skipped", func() {
+		_, err := s.pool.Exec(s.ctx, `UPDATE contacts SET created_at = created_at + INTERVAL '1 hour' WHERE contact_id = $1`,
+			contacts[4].ContactID)
+		s.Require().NoError(err)
+
+		page, _ := s.listContactIDs(1, "")
+		s.Equal([]uuid.UUID{contacts[4].ContactID}, page)
+	})
+}
+
+func (s *ContactIntegrationTestSuite) upsertByExternalRef(path string, body string) (int, types.Contact) {
+	req := s.newAuthenticatedRequest(http.MethodPut, "/contacts/external/"+path, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+
+	var response struct {
+		Data types.Contact `json:"data"`
+	}
+	if w.Code < http.StatusBadRequest {
+		s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+	}
+	return w.Code, response.Data
+}
+
+func (s *ContactIntegrationTestSuite) TestUpsertContactByExternalRef() {
+	var created types.Contact
+
+	s.Run("creates the contact of a new reference", func() {
+		code, contact := s.upsertByExternalRef("hubspot/5012", `{"name":"John Doe","email":"john@example.com","company":"Acme"}`)
+		s.Require().Equal(http.StatusCreated, code)
+		s.Equal("John Doe", contact.Name)
+		s.Require().NotNil(contact.ExternalRef)
+		s.Equal(types.ExternalRef{Source: "hubspot", ExternalID: "5012"}, *contact.ExternalRef)
+		created = contact
+	})
+
+	s.Run("updates the contact of a known reference", func() {
+		code, contact := s.upsertByExternalRef("hubspot/5012", `{"name":"John Smith","email":"john@example.com","company":"Acme"}`)
+		s.Require().Equal(http.StatusOK, code)
+		s.Equal(created.ContactID, contact.ContactID)
+		s.Equal("John Smith", contact.Name)
+	})
+
+	s.Run("leaves the fields not sent untouched", func() {
+		code, contact := s.upsertByExternalRef("hubspot/5012", `{"phone":"+1-555-123-4567"}`)
+		s.Require().Equal(http.StatusOK, code)
+		s.Equal(created.ContactID, contact.ContactID)
+		s.Equal("John Smith", contact.Name)
+		s.Require().NotNil(contact.Email)
+		s.Equal("john@example.com", *contact.Email)
+		s.Require().NotNil(contact.Company)
+		s.Equal("Acme", *contact.Company)
+		s.Require().NotNil(contact.Phone)
+	})
+
+	s.Run("the same ID of another source is another contact", func() {
+		code, contact := s.upsertByExternalRef("salesforce/5012", `{"name":"Jane Doe"}`)
+		s.Require().Equal(http.StatusCreated, code)
+		s.NotEqual(created.ContactID, contact.ContactID)
+	})
+
+	s.Run("creating a contact takes a name", func() {
+		code, _ := s.upsertByExternalRef("hubspot/7001", `{"email":"nameless@example.com"}`)
+		s.Equal(http.StatusBadRequest, code)
+	})
+
+	s.Run("rejects an invalid source", func() {
+		code, _ := s.upsertByExternalRef("Hub%20Spot/5012", `{"name":"John Doe"}`)
+		s.Equal(http.StatusBadRequest, code)
+	})
+
+	s.Run("restores a trashed contact", func() {
+		req := s.newAuthenticatedRequest(http.MethodDelete, "/contacts/"+created.ContactID.String(), nil)
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+		s.Require().Equal(http.StatusOK, w.Code)
+
+		code, contact := s.upsertByExternalRef("hubspot/5012", `{"notes":"synced again"}`)
+		s.Require().Equal(http.StatusOK, code)
+		s.Equal(created.ContactID, contact.ContactID)
+		s.Nil(contact.DeletedAt)
+	})
+
+	s.Run("concurrent upserts of a reference create one contact", func() {
+		const workers = 8
+		codes := make([]int, workers)
+
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				codes[i], _ = s.upsertByExternalRef("hubspot/9000", fmt.Sprintf(`{"name":"Racer %d"}`, i))
+			}(i)
+		}
+		wg.Wait()
+
+		createdCount := 0
+		for _, code := range codes {
+			s.Contains([]int{http.StatusCreated, http.StatusOK}, code)
+			if code == http.StatusCreated {
+				createdCount++
+			}
+		}
+		s.Equal(1, createdCount)
+
+		var count int
+		err := s.pool.QueryRow(s.ctx,
+			`SELECT COUNT(*) FROM contacts WHERE user_id = $1 AND external_source = 'hubspot' AND external_id = '9000'`,
+			s.userID).Scan(&count)
+		s.Require().NoError(err)
+		s.Equal(1, count)
+	})
+}
+
+// relate posts a relationship from the contact and returns the response status and relationship
+func (s *ContactIntegrationTestSuite) relate(from, to uuid.UUID, relationshipType string) (int, types.ContactRelationship) {
+	body, err := json.Marshal(types.ContactRelationshipPayload{ContactID: to, Type: relationshipType})
+	s.Require().NoError(err)
+	req := s.newAuthenticatedRequest(http.MethodPost, "/contacts/"+from.String()+"/relationships", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+
+	var response struct {
+		Data types.ContactRelationship `json:"data"`
+	}
+	if w.Code == http.StatusCreated {
+		s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+	}
+	return w.Code, response.Data
+}
+
+// relationshipsOf gets the contact with its relationships expanded
+func (s *ContactIntegrationTestSuite) relationshipsOf(contactID uuid.UUID) []types.ContactRelationship {
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, s.newAuthenticatedRequest(http.MethodGet, "/contacts/"+contactID.String()+"?expand=relationships", nil))
+	s.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+
+	var response struct {
+		Data types.Contact `json:"data"`
+	}
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+	return response.Data.Relationships
+}
+
+func (s *ContactIntegrationTestSuite) TestContactRelationships() {
+	s.clearContacts()
+	contacts := s.createTestContacts(3)
+	accountant, supplier, spouse := contacts[0], contacts[1], contacts[2]
+
+	code, worksFor := s.relate(accountant.ContactID, supplier.ContactID, types.RelationshipWorksFor)
+	s.Require().Equal(http.StatusCreated, code)
+	s.Equal(supplier.ContactID, worksFor.Contact.ContactID)
+	s.Equal(supplier.Name, worksFor.Contact.Name)
+	code, spouseOf := s.relate(spouse.ContactID, accountant.ContactID, types.RelationshipSpouseOf)
+	s.Require().Equal(http.StatusCreated, code)
+
+	s.Run("read both ways", func() {
+		relationships := s.relationshipsOf(accountant.ContactID)
+		s.Require().Len(relationships, 2)
+		s.Equal(worksFor.RelationshipID, relationships[0].RelationshipID)
+		s.True(relationships[0].Outgoing)
+		s.Equal(supplier.ContactID, relationships[0].Contact.ContactID)
+		s.Equal(spouseOf.RelationshipID, relationships[1].RelationshipID)
+		s.False(relationships[1].Outgoing)
+		s.Equal(spouse.ContactID, relationships[1].Contact.ContactID)
+		s.Equal(spouse.Name, relationships[1].Contact.Name)
+
+		incoming := s.relationshipsOf(supplier.ContactID)
+		s.Require().Len(incoming, 1)
+		s.False(incoming[0].Outgoing)
+		s.Equal(types.RelationshipWorksFor, incoming[0].Type)
+		s.Equal(accountant.ContactID, incoming[0].Contact.ContactID)
+
+		// without expand the relationships are left out
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, s.newAuthenticatedRequest(http.MethodGet, "/contacts/"+accountant.ContactID.String(), nil))
+		s.NotContains(w.Body.String(), "relationships")
+	})
+
+	s.Run("rejected relationships", func() {
+		code, _ := s.relate(accountant.ContactID, supplier.ContactID, types.RelationshipWorksFor)
+		s.Equal(http.StatusConflict, code, "same pair and type")
+		code, _ = s.relate(accountant.ContactID, spouse.ContactID, types.RelationshipSpouseOf)
+		s.Equal(http.StatusConflict, code, "spouse_of reads the same both ways")
+		code, _ = s.relate(accountant.ContactID, accountant.ContactID, types.RelationshipReportsTo)
+		s.Equal(http.StatusBadRequest, code, "self relationship")
+		code, _ = s.relate(accountant.ContactID, uuid.New(), types.RelationshipReportsTo)
+		s.Equal(http.StatusNotFound, code)
+
+		otherUserID, _ := s.createOtherUser()
+		var otherContactID uuid.UUID
+		err := s.pool.QueryRow(s.ctx, `INSERT INTO contacts (user_id, name) VALUES ($1, 'Not Yours') RETURNING contact_id`, otherUserID).Scan(&otherContactID)
+		s.Require().NoError(err)
+		code, _ = s.relate(accountant.ContactID, otherContactID, types.RelationshipReportsTo)
+		s.Equal(http.StatusNotFound, code, "contact of another user")
+
+		// a different type between the same contacts is another relationship
+		code, _ = s.relate(accountant.ContactID, supplier.ContactID, types.RelationshipReferredBy)
+		s.Equal(http.StatusCreated, code)
+	})
+
+	s.Run("delete through the related contact", func() {
+		_, referredBy := s.relate(supplier.ContactID, spouse.ContactID, types.RelationshipReferredBy)
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, s.newAuthenticatedRequest(http.MethodDelete,
+			"/contacts/"+spouse.ContactID.String()+"/relationships/"+referredBy.RelationshipID.String(), nil))
+		s.Equal(http.StatusOK, w.Code)
+
+		w = httptest.NewRecorder()
+		s.router.ServeHTTP(w, s.newAuthenticatedRequest(http.MethodDelete,
+			"/contacts/"+spouse.ContactID.String()+"/relationships/"+referredBy.RelationshipID.String(), nil))
+		s.Equal(http.StatusNotFound, w.Code)
+	})
+
+	s.Run("deleting a contact removes its relationships", func() {
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, s.newAuthenticatedRequest(http.MethodDelete, "/contacts/"+accountant.ContactID.String(), nil))
+		s.Require().Equal(http.StatusOK, w.Code)
+
+		var count int
+		err := s.pool.QueryRow(s.ctx,
+			`SELECT COUNT(*) FROM contact_relationships WHERE from_contact_id = $1 OR to_contact_id = $1`,
+			accountant.ContactID).Scan(&count)
+		s.Require().NoError(err)
+		s.Zero(count)
+		s.Empty(s.relationshipsOf(supplier.ContactID))
+		s.Empty(s.relationshipsOf(spouse.ContactID))
+
+		// restoring the contact doesn't bring them back
+		w = httptest.NewRecorder()
+		s.router.ServeHTTP(w, s.newAuthenticatedRequest(http.MethodPost, "/contacts/"+accountant.ContactID.String()+"/restore", nil))
+		s.Require().Equal(http.StatusOK, w.Code)
+		s.Empty(s.relationshipsOf(accountant.ContactID))
+	})
+}
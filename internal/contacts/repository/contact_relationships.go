@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
+)
+
+// CreateContactRelationship relates the contact to payload.ContactID. Both contacts have to
+// belong to the user, a missing one is not found and a relationship of the same type
+// between them a conflict.
+func (r *contactRepository) CreateContactRelationship(ctx context.Context, contactID, userID uuid.UUID, payload types.ContactRelationshipPayload) (types.ContactRelationship, error) {
+	row, err := r.q.CreateContactRelationship(ctx, db.CreateContactRelationshipParams{
+		Type:          db.ContactRelationshipType(payload.Type),
+		Note:          utils.ToNullableText(payload.Note),
+		FromContactID: contactID,
+		UserID:        userID,
+		ToContactID:   payload.ContactID,
+	})
+	if stdErrors.Is(err, pgx.ErrNoRows) {
+		return types.ContactRelationship{}, fmt.Errorf("relate contact %s to %s: %w", contactID, payload.ContactID, repository.ErrNotFound)
+	}
+	if err != nil {
+		return types.ContactRelationship{}, errors.HandleRepositoryError(err, "create", "contact relationship")
+	}
+
+	return types.ContactRelationship{
+		RelationshipID: row.RelationshipID,
+		Type:           string(row.Type),
+		Outgoing:       true,
+		Contact:        types.RelatedContact{ContactID: row.ContactID, Name: row.ContactName},
+		Note:           utils.PgtextToStringPtr(row.Note),
+		CreatedAt:      coreTypes.NewTimestamp(row.CreatedAt.Time),
+	}, nil
+}
+
+// ListContactRelationships lists the relationships of the contact both ways, oldest first
+func (r *contactRepository) ListContactRelationships(ctx context.Context, contactID, userID uuid.UUID) ([]types.ContactRelationship, error) {
+	rows, err := r.q.ListContactRelationships(ctx, db.ListContactRelationshipsParams{
+		ContactID: contactID,
+		UserID:    userID,
+	})
+	if err != nil {
+		return []types.ContactRelationship{}, errors.HandleRepositoryError(err, "list", "contact relationships")
+	}
+
+	relationships := make([]types.ContactRelationship, len(rows))
+	for i, row := range rows {
+		relationships[i] = types.ContactRelationship{
+			RelationshipID: row.RelationshipID,
+			Type:           string(row.Type),
+			Outgoing:       row.Outgoing,
+			Contact:        types.RelatedContact{ContactID: row.ContactID, Name: row.ContactName},
+			Note:           utils.PgtextToStringPtr(row.Note),
+			CreatedAt:      coreTypes.NewTimestamp(row.CreatedAt.Time),
+		}
+	}
+	return relationships, nil
+}
+
+// DeleteContactRelationship deletes a relationship of the contact, whichever way it goes
+func (r *contactRepository) DeleteContactRelationship(ctx context.Context, contactID, relationshipID, userID uuid.UUID) error {
+	deleted, err := r.q.DeleteContactRelationship(ctx, db.DeleteContactRelationshipParams{
+		RelationshipID: relationshipID,
+		UserID:         userID,
+		ContactID:      contactID,
+	})
+	if err != nil {
+		return errors.HandleRepositoryError(err, "delete", "contact relationship")
+	}
+	if deleted == 0 {
+		return fmt.Errorf("delete contact relationship %s: %w", relationshipID, repository.ErrNotFound)
+	}
+	return nil
+}
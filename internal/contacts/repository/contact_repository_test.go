@@ -2,13 +2,18 @@ package repository_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/repository"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	coreErrors "github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	coreRepository "github.com/Abdelrahman-habib/expense-tracker/internal/core/repository"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
 	"github.com/google/uuid"
@@ -174,7 +179,7 @@ func (s *ContactRepositoryTestSuite) TestCreateContact() {
 				City:          utils.StringPtr("New York"),
 				StateProvince: utils.StringPtr("NY"),
 				ZipPostalCode: utils.StringPtr("10001"),
-				Tags:          []uuid.UUID{uuid.New(), uuid.New()},
+				Tags:          s.createTestTags(2),
 			},
 			wantErr: false,
 		},
@@ -280,6 +285,62 @@ func (s *ContactRepositoryTestSuite) TestGetContact() {
 	}
 }
 
+func (s *ContactRepositoryTestSuite) TestGetContactsByIDs() {
+	otherUser := uuid.New()
+	_, err := s.pool.Exec(s.ctx, `
+		INSERT INTO users (user_id, clerk_ex_user_id, name, email)
+		VALUES ($1, $2, 'crt_Other User', $3)
+	`, otherUser, otherUser.String(), "crt_"+otherUser.String()+"@example.com")
+	s.Require().NoError(err)
+
+	first, err := s.repo.CreateContact(s.ctx, types.ContactCreatePayload{Name: "First"}, s.testUser)
+	s.Require().NoError(err)
+	second, err := s.repo.CreateContact(s.ctx, types.ContactCreatePayload{Name: "Second"}, s.testUser)
+	s.Require().NoError(err)
+	trashed, err := s.repo.CreateContact(s.ctx, types.ContactCreatePayload{Name: "Trashed"}, s.testUser)
+	s.Require().NoError(err)
+	s.Require().NoError(s.repo.DeleteContact(s.ctx, trashed.ContactID, s.testUser, nil))
+	foreign, err := s.repo.CreateContact(s.ctx, types.ContactCreatePayload{Name: "Foreign"}, otherUser)
+	s.Require().NoError(err)
+	unknown := uuid.New()
+
+	ids := []uuid.UUID{second.ContactID, foreign.ContactID, first.ContactID, trashed.ContactID, unknown}
+	contacts, missing, err := s.repo.GetContactsByIDs(s.ctx, s.testUser, ids)
+	s.Require().NoError(err)
+	s.Require().Len(contacts, 2)
+	s.Equal(second.ContactID, contacts[0].ContactID)
+	s.Equal(first.ContactID, contacts[1].ContactID)
+	s.Equal([]uuid.UUID{foreign.ContactID, trashed.ContactID, unknown}, missing)
+}
+
+func (s *ContactRepositoryTestSuite) TestDeleteContact() {
+	created, err := s.repo.CreateContact(s.ctx, types.ContactCreatePayload{Name: "Test Contact"}, s.testUser)
+	require.NoError(s.T(), err)
+
+	tests := []struct {
+		name      string
+		userID    uuid.UUID
+		contactID uuid.UUID
+		wantErr   error
+	}{
+		{name: "wrong user", userID: uuid.New(), contactID: created.ContactID, wantErr: coreRepository.ErrNotFound},
+		{name: "existing contact", userID: s.testUser, contactID: created.ContactID},
+		{name: "already trashed", userID: s.testUser, contactID: created.ContactID, wantErr: coreRepository.ErrNotFound},
+		{name: "non-existent contact", userID: s.testUser, contactID: uuid.New(), wantErr: coreRepository.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			err := s.repo.DeleteContact(s.ctx, tt.contactID, tt.userID, nil)
+			if tt.wantErr != nil {
+				s.True(errors.Is(err, tt.wantErr), "got %v", err)
+				return
+			}
+			s.NoError(err)
+		})
+	}
+}
+
 func (s *ContactRepositoryTestSuite) TestUpdateContact() {
 	// Create a test contact first
 	createPayload := types.ContactCreatePayload{
@@ -292,7 +353,7 @@ func (s *ContactRepositoryTestSuite) TestUpdateContact() {
 		City:          utils.StringPtr("New York"),
 		StateProvince: utils.StringPtr("NY"),
 		ZipPostalCode: utils.StringPtr("10001"),
-		Tags:          []uuid.UUID{uuid.New(), uuid.New()},
+		Tags:          s.createTestTags(2),
 	}
 	created, err := s.repo.CreateContact(s.ctx, createPayload, s.testUser)
 	require.NoError(s.T(), err)
@@ -353,6 +414,117 @@ func (s *ContactRepositoryTestSuite) TestUpdateContact() {
 	}
 }
 
+func (s *ContactRepositoryTestSuite) TestContactLinks() {
+	links := []types.ContactLink{
+		{Label: types.LinkWebsite, URL: "https://acme.example.com"},
+		{Label: types.LinkLinkedIn, URL: "https://www.linkedin.com/in/jane"},
+	}
+	created, err := s.repo.CreateContact(s.ctx, types.ContactCreatePayload{Name: "Linked Contact", Links: links}, s.testUser)
+	s.Require().NoError(err)
+	s.Equal(links, created.Links)
+
+	fetched, err := s.repo.GetContact(s.ctx, created.ContactID, s.testUser)
+	s.Require().NoError(err)
+	s.Equal(links, fetched.Links)
+
+	// an update without links clears them
+	payload := fetched.ToUpdatePayload()
+	payload.Links = nil
+	updated, err := s.repo.UpdateContact(s.ctx, payload, s.testUser)
+	s.Require().NoError(err)
+	s.Empty(updated.Links)
+}
+
+func (s *ContactRepositoryTestSuite) TestDeleteContact_Version() {
+	created, err := s.repo.CreateContact(s.ctx, types.ContactCreatePayload{Name: "Versioned Contact"}, s.testUser)
+	s.Require().NoError(err)
+	read, err := s.repo.GetContact(s.ctx, created.ContactID, s.testUser)
+	s.Require().NoError(err)
+
+	// an update committed between the read and the delete fails it
+	payload := read.ToUpdatePayload()
+	payload.Name = "Renamed Contact"
+	updated, err := s.repo.UpdateContact(s.ctx, payload, s.testUser)
+	s.Require().NoError(err)
+
+	err = s.repo.DeleteContact(s.ctx, created.ContactID, s.testUser, &read.UpdatedAt.Time)
+	s.ErrorIs(err, coreRepository.ErrPreconditionFailed)
+	_, err = s.repo.GetContact(s.ctx, created.ContactID, s.testUser)
+	s.Require().NoError(err, "the contact is still live")
+
+	s.Require().NoError(s.repo.DeleteContact(s.ctx, created.ContactID, s.testUser, &updated.UpdatedAt.Time))
+	err = s.repo.DeleteContact(s.ctx, created.ContactID, s.testUser, &updated.UpdatedAt.Time)
+	s.ErrorIs(err, coreRepository.ErrNotFound, "a trashed contact is missing whatever the version")
+}
+
+func (s *ContactRepositoryTestSuite) TestUpdateContactLocked() {
+	created, err := s.repo.CreateContact(s.ctx, types.ContactCreatePayload{Name: "Locked Contact"}, s.testUser)
+	s.Require().NoError(err)
+	phones := map[string]string{"First": "+1-555-000-0001", "Second": "+1-555-000-0002"}
+
+	s.Run("concurrent updates apply one after the other", func() {
+		// each update sets the name and the phone of its request and appends its name to
+		// the city it read, interleaved both would read the same city and lose an append
+		var wg sync.WaitGroup
+		seen := map[string]types.Contact{}
+		var mu sync.Mutex
+		errs := make(chan error, len(phones))
+		for name, phone := range phones {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := s.repo.UpdateContactLocked(s.ctx, created.ContactID, s.testUser, func(existing types.Contact) (types.ContactUpdatePayload, error) {
+					mu.Lock()
+					seen[name] = existing
+					mu.Unlock()
+					// give the other update time to read if the lock didn't hold it
+					time.Sleep(100 * time.Millisecond)
+
+					payload := existing.ToUpdatePayload()
+					payload.Name = name
+					payload.Phone = utils.StringPtr(phone)
+					city := name
+					if existing.City != nil {
+						city = *existing.City + "," + name
+					}
+					payload.City = &city
+					return payload, nil
+				})
+				errs <- err
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			s.Require().NoError(err)
+		}
+
+		final, err := s.repo.GetContact(s.ctx, created.ContactID, s.testUser)
+		s.Require().NoError(err)
+		s.Require().NotNil(final.City)
+		s.Require().NotNil(final.Phone)
+		// the last update wrote its own name and phone, on top of what the first wrote
+		s.Equal(phones[final.Name], *final.Phone)
+		first := map[string]string{"First": "Second", "Second": "First"}[final.Name]
+		s.Equal(first+","+final.Name, *final.City)
+		s.Equal(first, seen[final.Name].Name)
+		s.Nil(seen[first].City)
+	})
+
+	s.Run("update waiting past the lock timeout is a concurrent modification", func() {
+		tx, err := s.pool.Begin(s.ctx)
+		s.Require().NoError(err)
+		defer func() { _ = tx.Rollback(s.ctx) }()
+		s.Require().NoError(db.New(tx).LockEntity(s.ctx, db.EntityLockKey(created.ContactID)))
+
+		_, err = s.repo.UpdateContactLocked(s.ctx, created.ContactID, s.testUser, func(existing types.Contact) (types.ContactUpdatePayload, error) {
+			s.Fail("merged without holding the lock")
+			return existing.ToUpdatePayload(), nil
+		})
+		s.True(coreErrors.IsErrorType(err, coreErrors.ErrorTypeConcurrentModification), "got %v", err)
+	})
+}
+
 func (s *ContactRepositoryTestSuite) TestListContactsPaginated() {
 	// Create test contacts in order from oldest to newest
 
@@ -393,7 +565,7 @@ func (s *ContactRepositoryTestSuite) TestListContactsPaginated() {
 		},
 		{
 			name:      "get second page",
-			cursor:    createdContacts[2].CreatedAt,
+			cursor:    createdContacts[2].CreatedAt.Time,
 			cursorID:  createdContacts[2].ContactID,
 			limit:     2,
 			wantLen:   2,
@@ -402,7 +574,7 @@ func (s *ContactRepositoryTestSuite) TestListContactsPaginated() {
 		},
 		{
 			name:      "get empty page",
-			cursor:    createdContacts[0].CreatedAt,
+			cursor:    createdContacts[0].CreatedAt.Time,
 			cursorID:  createdContacts[0].ContactID,
 			limit:     2,
 			wantLen:   0,
@@ -413,7 +585,7 @@ func (s *ContactRepositoryTestSuite) TestListContactsPaginated() {
 
 	for _, tt := range tests {
 		s.Run(tt.name, func() {
-			contacts, err := s.repo.ListContactsPaginated(s.ctx, s.testUser, &tt.cursor, &tt.cursorID, tt.limit)
+			contacts, err := s.repo.ListContactsPaginated(s.ctx, s.testUser, &tt.cursor, &tt.cursorID, tt.limit, coreTypes.SortOrderDesc, types.ContactFilter{})
 			if tt.wantErr {
 				s.Error(err)
 				return
@@ -433,8 +605,8 @@ func (s *ContactRepositoryTestSuite) TestListContactsPaginated() {
 			// Verify ordering for non-empty results
 			if len(contacts) > 1 {
 				for i := 1; i < len(contacts); i++ {
-					isCorrectOrder := contacts[i-1].CreatedAt.After(contacts[i].CreatedAt) ||
-						(contacts[i-1].CreatedAt.Equal(contacts[i].CreatedAt) &&
+					isCorrectOrder := contacts[i-1].CreatedAt.After(contacts[i].CreatedAt.Time) ||
+						(contacts[i-1].CreatedAt.Equal(contacts[i].CreatedAt.Time) &&
 							contacts[i-1].ContactID.String() > contacts[i].ContactID.String())
 					s.True(isCorrectOrder, "Contacts should be ordered by created_at DESC and then by contact_id DESC")
 				}
@@ -443,6 +615,41 @@ func (s *ContactRepositoryTestSuite) TestListContactsPaginated() {
 	}
 }
 
+func (s *ContactRepositoryTestSuite) TestListContactsPaginated_ByPlace() {
+	s.cleanContactTable()
+
+	for _, c := range []types.ContactCreatePayload{
+		{Name: "Amy Baker", City: utils.StringPtr("New York"), StateProvince: utils.StringPtr("NY")},
+		{Name: "Carl Cole", City: utils.StringPtr("Albany"), StateProvince: utils.StringPtr("NY")},
+		{Name: "Dana Diaz", City: utils.StringPtr("New York"), StateProvince: utils.StringPtr("Lincolnshire")},
+		{Name: "Eve Evans"},
+	} {
+		_, err := s.repo.CreateContact(s.ctx, c, s.testUser)
+		s.Require().NoError(err)
+	}
+
+	names := func(filter types.ContactFilter, limit int32, cursor *time.Time, cursorID *uuid.UUID) []string {
+		contacts, err := s.repo.ListContactsPaginated(s.ctx, s.testUser, cursor, cursorID, limit, coreTypes.SortOrderAsc, filter)
+		s.Require().NoError(err)
+		names := []string{}
+		for _, c := range contacts {
+			names = append(names, c.Name)
+		}
+		return names
+	}
+
+	s.Equal([]string{"Amy Baker", "Dana Diaz"}, names(types.ContactFilter{City: utils.StringPtr("new york")}, 10, nil, nil))
+	s.Equal([]string{"Amy Baker", "Carl Cole"}, names(types.ContactFilter{StateProvince: utils.StringPtr("ny")}, 10, nil, nil))
+	s.Equal([]string{"Amy Baker"}, names(types.ContactFilter{City: utils.StringPtr("NEW YORK"), StateProvince: utils.StringPtr("NY")}, 10, nil, nil))
+	s.Empty(names(types.ContactFilter{City: utils.StringPtr("Boston")}, 10, nil, nil))
+
+	// the filter holds across pages
+	first, err := s.repo.ListContactsPaginated(s.ctx, s.testUser, nil, nil, 1, coreTypes.SortOrderAsc, types.ContactFilter{City: utils.StringPtr("New York")})
+	s.Require().NoError(err)
+	s.Require().Len(first, 1)
+	s.Equal([]string{"Dana Diaz"}, names(types.ContactFilter{City: utils.StringPtr("New York")}, 10, &first[0].CreatedAt.Time, &first[0].ContactID))
+}
+
 func (s *ContactRepositoryTestSuite) TestSearchContacts() {
 	// Create test contacts with various names
 	contacts := []types.ContactCreatePayload{
@@ -513,7 +720,7 @@ func (s *ContactRepositoryTestSuite) TestSearchContacts() {
 
 	for _, tt := range tests {
 		s.Run(tt.name, func() {
-			contacts, err := s.repo.SearchContacts(s.ctx, s.testUser, tt.query, tt.limit)
+			contacts, err := s.repo.SearchContacts(s.ctx, s.testUser, tt.query, tt.limit, 0)
 			if tt.wantErr {
 				s.Error(err)
 				return
@@ -533,6 +740,47 @@ func (s *ContactRepositoryTestSuite) TestSearchContacts() {
 	}
 }
 
+func (s *ContactRepositoryTestSuite) TestSearchContactsIgnoresAccents() {
+	contacts := []types.ContactCreatePayload{
+		{Name: "Émile Zola"},
+		{Name: "Zoë Müller", Company: utils.StringPtr("Société Générale")},
+		{Name: "Jose Alvarez"},
+		{Name: "Mark Twain"},
+	}
+	for _, c := range contacts {
+		_, err := s.repo.CreateContact(s.ctx, c, s.testUser)
+		s.Require().NoError(err)
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		wantFirst string
+	}{
+		{name: "plain query finds the accented name", query: "emile", wantFirst: "Émile Zola"},
+		{name: "accented query finds the plain name", query: "José", wantFirst: "Jose Alvarez"},
+		{name: "umlauts and diaeresis", query: "zoe muller", wantFirst: "Zoë Müller"},
+		{name: "company", query: "societe", wantFirst: "Zoë Müller"},
+		{name: "misspelled and unaccented, trigram similarity still applies", query: "Emil Zola", wantFirst: "Émile Zola"},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			found, err := s.repo.SearchContacts(s.ctx, s.testUser, tt.query, 10, 0)
+			s.Require().NoError(err)
+			s.Require().NotEmpty(found)
+			s.Equal(tt.wantFirst, found[0].Name)
+		})
+	}
+
+	s.Run("by company", func() {
+		found, err := s.repo.SearchContactsByCompany(s.ctx, s.testUser, "SOCIETE GENERALE", 10, 0)
+		s.Require().NoError(err)
+		s.Require().Len(found, 1)
+		s.Equal("Zoë Müller", found[0].Name)
+	})
+}
+
 func (s *ContactRepositoryTestSuite) TestSearchContactsByPhone() {
 	// Create test contacts with clean phone numbers (no formatting characters)
 	contacts := []types.ContactCreatePayload{
@@ -627,7 +875,7 @@ func (s *ContactRepositoryTestSuite) TestSearchContactsByPhone() {
 
 	for _, tt := range tests {
 		s.Run(tt.name, func() {
-			contacts, err := s.repo.SearchContactsByPhone(s.ctx, s.testUser, tt.query, tt.limit)
+			contacts, err := s.repo.SearchContactsByPhone(s.ctx, s.testUser, tt.query, tt.limit, 0)
 			if tt.wantErr {
 				s.Error(err)
 				return
@@ -647,6 +895,178 @@ func (s *ContactRepositoryTestSuite) TestSearchContactsByPhone() {
 	}
 }
 
+func (s *ContactRepositoryTestSuite) TestListContactCompanies() {
+	contacts := []types.ContactCreatePayload{
+		{Name: "Zed Adams", Company: utils.StringPtr("Acme")},
+		{Name: "Amy Baker", Company: utils.StringPtr("Acme")},
+		{Name: "Carl Cole", Company: utils.StringPtr("Acme")},
+		{Name: "Dana Diaz", Company: utils.StringPtr("Globex")},
+		{Name: "Eli Evans", Company: utils.StringPtr("Globex")},
+		{Name: "Fay Ford", Company: utils.StringPtr("Initech")},
+		{Name: "No Company"},
+	}
+
+	for _, c := range contacts {
+		_, err := s.repo.CreateContact(s.ctx, c, s.testUser)
+		s.Require().NoError(err)
+	}
+
+	tests := []struct {
+		name          string
+		params        types.CompanyListParams
+		wantCompanies []string
+		wantCounts    []int64
+		wantFirst     []string
+	}{
+		{
+			name:          "sort by count",
+			params:        types.CompanyListParams{SortBy: types.CompanySortByCount, Limit: 10, ContactsLimit: 2},
+			wantCompanies: []string{"Acme", "Globex", "Initech"},
+			wantCounts:    []int64{3, 2, 1},
+			wantFirst:     []string{"Amy Baker", "Dana Diaz", "Fay Ford"},
+		},
+		{
+			name:          "sort by name",
+			params:        types.CompanyListParams{SortBy: types.CompanySortByName, Limit: 10, ContactsLimit: 1},
+			wantCompanies: []string{"Acme", "Globex", "Initech"},
+			wantCounts:    []int64{3, 2, 1},
+			wantFirst:     []string{"Amy Baker", "Dana Diaz", "Fay Ford"},
+		},
+		{
+			name:          "limited companies",
+			params:        types.CompanyListParams{SortBy: types.CompanySortByCount, Limit: 1, ContactsLimit: 5},
+			wantCompanies: []string{"Acme"},
+			wantCounts:    []int64{3},
+			wantFirst:     []string{"Amy Baker"},
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			companies, err := s.repo.ListContactCompanies(s.ctx, s.testUser, tt.params)
+			s.NoError(err)
+			s.Len(companies, len(tt.wantCompanies))
+
+			for i, company := range companies {
+				s.Equal(tt.wantCompanies[i], company.Company)
+				s.Equal(tt.wantCounts[i], company.ContactCount)
+				s.Equal(tt.wantFirst[i], company.Contacts[0].Name)
+				s.LessOrEqual(len(company.Contacts), int(tt.params.ContactsLimit))
+			}
+		})
+	}
+}
+
+func (s *ContactRepositoryTestSuite) TestListFacets() {
+	contacts := []types.ContactCreatePayload{
+		{Name: "Dana Diaz", Country: utils.StringPtr("DE"), City: utils.StringPtr("Berlin")},
+		{Name: "Amy Baker", Country: utils.StringPtr("US"), City: utils.StringPtr("Boston")},
+		{Name: "Carl Cole", Country: utils.StringPtr("US"), City: utils.StringPtr("Austin")},
+		{Name: "Trashed", Country: utils.StringPtr("FR")},
+		{Name: "Nowhere"},
+	}
+	for _, c := range contacts {
+		created, err := s.repo.CreateContact(s.ctx, c, s.testUser)
+		s.Require().NoError(err)
+		if c.Name == "Trashed" {
+			s.Require().NoError(s.repo.DeleteContact(s.ctx, created.ContactID, s.testUser, nil))
+		}
+	}
+
+	countries, err := s.repo.ListFacets(s.ctx, s.testUser, "country")
+	s.NoError(err)
+	s.Equal([]coreTypes.Facet{{Value: "US", Count: 2}, {Value: "DE", Count: 1}}, countries)
+
+	// ties are ordered by value
+	cities, err := s.repo.ListFacets(s.ctx, s.testUser, "city")
+	s.NoError(err)
+	s.Equal([]coreTypes.Facet{{Value: "Austin", Count: 1}, {Value: "Berlin", Count: 1}, {Value: "Boston", Count: 1}}, cities)
+
+	companies, err := s.repo.ListFacets(s.ctx, s.testUser, "company")
+	s.NoError(err)
+	s.Empty(companies)
+}
+
+func (s *ContactRepositoryTestSuite) TestStreamsMatchSliceAPI() {
+	tags := s.createTestTags(2)
+	for i := 0; i < 12; i++ {
+		_, err := s.repo.CreateContact(s.ctx, types.ContactCreatePayload{
+			Name:  fmt.Sprintf("Stream Contact %02d", i),
+			Email: utils.StringPtr(fmt.Sprintf("stream%d@example.com", i)),
+			Notes: utils.StringPtr("streamed"),
+			Tags:  tags[:i%2+1],
+		}, s.testUser)
+		s.Require().NoError(err)
+	}
+
+	collect := func(stream func(fn func(types.Contact) error) error) []types.Contact {
+		var streamed []types.Contact
+		s.Require().NoError(stream(func(c types.Contact) error {
+			streamed = append(streamed, c)
+			return nil
+		}))
+		return streamed
+	}
+
+	s.Run("paginated", func() {
+		for _, order := range []coreTypes.SortOrder{coreTypes.SortOrderDesc, coreTypes.SortOrderAsc} {
+			listed, err := s.repo.ListContactsPaginated(s.ctx, s.testUser, nil, nil, 10, order, types.ContactFilter{})
+			s.Require().NoError(err)
+			streamed := collect(func(fn func(types.Contact) error) error {
+				return s.repo.ListContactsPaginatedStream(s.ctx, s.testUser, nil, nil, 10, order, types.ContactFilter{}, fn)
+			})
+			s.Equal(listed, streamed, order)
+		}
+	})
+
+	s.Run("search", func() {
+		found, err := s.repo.SearchContacts(s.ctx, s.testUser, "Stream Contact", 10, 0)
+		s.Require().NoError(err)
+		s.Require().NotEmpty(found)
+		streamed := collect(func(fn func(types.Contact) error) error {
+			return s.repo.SearchContactsStream(s.ctx, s.testUser, "Stream Contact", 10, fn)
+		})
+		s.Equal(found, streamed)
+	})
+
+	s.Run("callback error stops the stream", func() {
+		stop := fmt.Errorf("stop")
+		calls := 0
+		err := s.repo.ListContactsPaginatedStream(s.ctx, s.testUser, nil, nil, 10, coreTypes.SortOrderDesc, types.ContactFilter{}, func(types.Contact) error {
+			calls++
+			return stop
+		})
+		s.ErrorIs(err, stop)
+		s.Equal(1, calls)
+	})
+}
+
+func (s *ContactRepositoryTestSuite) TestSearchContactsByCompany() {
+	contacts := []types.ContactCreatePayload{
+		{Name: "John Smith", Company: utils.StringPtr("Acme Corporation")},
+		{Name: "Jane Doe", Company: utils.StringPtr("Acme")},
+		{Name: "Bob Wilson", Company: utils.StringPtr("Globex")},
+		{Name: "Acme Fan"},
+	}
+
+	for _, c := range contacts {
+		_, err := s.repo.CreateContact(s.ctx, c, s.testUser)
+		s.Require().NoError(err)
+	}
+
+	results, err := s.repo.SearchContactsByCompany(s.ctx, s.testUser, "Acme", 10, 0)
+	s.NoError(err)
+	s.Len(results, 2)
+	s.Equal("Jane Doe", results[0].Name)
+	s.Equal("John Smith", results[1].Name)
+
+	// company is also matched by the general search
+	results, err = s.repo.SearchContacts(s.ctx, s.testUser, "Globex", 10, 0)
+	s.NoError(err)
+	s.Require().Len(results, 1)
+	s.Equal("Bob Wilson", results[0].Name)
+}
+
 func (s *ContactRepositoryTestSuite) runMigrations() error {
 	migrationsDir := "../../db/sql/migrations"
 
@@ -664,3 +1084,15 @@ func (s *ContactRepositoryTestSuite) runMigrations() error {
 
 	return nil
 }
+
+// createTestTags creates tags owned by the test user; tags the user doesn't own are dropped on write
+func (s *ContactRepositoryTestSuite) createTestTags(count int) []uuid.UUID {
+	tags := make([]uuid.UUID, count)
+	for i := range tags {
+		err := s.pool.QueryRow(s.ctx, `
+			INSERT INTO tags (user_id, name) VALUES ($1, $2) RETURNING tag_id
+		`, s.testUser, fmt.Sprintf("test tag %d", i)).Scan(&tags[i])
+		s.Require().NoError(err)
+	}
+	return tags
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+)
+
+func (r *contactRepository) CountContacts(ctx context.Context, userID uuid.UUID) (int64, error) {
+	if userID == uuid.Nil {
+		return 0, fmt.Errorf("invalid user id")
+	}
+
+	count, err := r.q.CountContacts(ctx, userID)
+	if err != nil {
+		return 0, errors.HandleRepositoryError(err, "count", "contacts")
+	}
+	return count, nil
+}
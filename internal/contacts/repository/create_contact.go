@@ -7,7 +7,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
-	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
 )
 
 func (r *contactRepository) CreateContact(ctx context.Context, payload types.ContactCreatePayload, userID uuid.UUID) (types.Contact, error) {
@@ -15,10 +15,10 @@ func (r *contactRepository) CreateContact(ctx context.Context, payload types.Con
 		return types.Contact{}, fmt.Errorf("invalid user id")
 	}
 
-	params := createContactParamsFromPayload(payload, userID)
+	params := createContactParamsFromPayload(payload, userID, requestcontext.GetActorIDFromContext(ctx, userID))
 	contact, err := r.q.CreateContact(ctx, params)
 	if err != nil {
-		return types.Contact{}, errors.HandleRepositoryError(err, "create", "contact")
+		return types.Contact{}, handleWriteError(err, "create")
 	}
 
 	return toContact(contact), nil
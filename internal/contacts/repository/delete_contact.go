@@ -3,25 +3,39 @@ package repository
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/repository"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
 )
 
-func (r *contactRepository) DeleteContact(ctx context.Context, contactID, userID uuid.UUID) error {
+// DeleteContact trashes the contact, with a version only while it is still at that
+// updatedAt. A live contact at another version returns ErrPreconditionFailed.
+func (r *contactRepository) DeleteContact(ctx context.Context, contactID, userID uuid.UUID, version *time.Time) error {
 	if contactID == uuid.Nil || userID == uuid.Nil {
 		return fmt.Errorf("invalid contact id or user id")
 	}
 
-	err := r.q.DeleteContact(ctx, db.DeleteContactParams{
+	deleted, err := r.q.DeleteContact(ctx, db.DeleteContactParams{
 		ContactID: contactID,
 		UserID:    userID,
+		Version:   utils.ToNullableTimestamp(version),
 	})
 	if err != nil {
 		return errors.HandleRepositoryError(err, "delete", "contact")
 	}
+	if deleted == 0 {
+		if version != nil {
+			if _, err := r.GetContact(ctx, contactID, userID); err == nil {
+				return fmt.Errorf("delete contact %s: %w", contactID, repository.ErrPreconditionFailed)
+			}
+		}
+		return fmt.Errorf("delete contact %s: %w", contactID, repository.ErrNotFound)
+	}
 
 	return nil
 }
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+)
+
+func (r *contactRepository) GetContactsByIDs(ctx context.Context, userID uuid.UUID, contactIDs []uuid.UUID) ([]types.Contact, []uuid.UUID, error) {
+	if userID == uuid.Nil {
+		return nil, nil, fmt.Errorf("invalid user id")
+	}
+
+	contacts, err := r.q.GetContactsByIDs(ctx, db.GetContactsByIDsParams{
+		ContactIds: contactIDs,
+		UserID:     userID,
+	})
+	if err != nil {
+		return nil, nil, errors.HandleRepositoryError(err, "get", "contacts")
+	}
+
+	found, missing := repository.OrderByIDs(contactIDs, toContacts(contacts), func(c types.Contact) uuid.UUID {
+		return c.ContactID
+	})
+	return found, missing, nil
+}
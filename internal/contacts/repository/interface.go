@@ -7,6 +7,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 )
 
 // Repository defines the interface for contact operations
@@ -14,6 +15,10 @@ type Repository interface {
 	// GetContact retrieves a contact by ID and user ID
 	GetContact(ctx context.Context, contactID, userID uuid.UUID) (types.Contact, error)
 
+	// GetContactsByIDs retrieves the user's contacts with the IDs in the order asked for,
+	// along with the IDs of those that don't exist, are trashed or belong to another user
+	GetContactsByIDs(ctx context.Context, userID uuid.UUID, contactIDs []uuid.UUID) ([]types.Contact, []uuid.UUID, error)
+
 	// ListContacts retrieves a paginated list of contacts for a user
 	ListContacts(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]types.Contact, error)
 
@@ -23,15 +28,74 @@ type Repository interface {
 	// UpdateContact updates an existing contact
 	UpdateContact(ctx context.Context, payload types.ContactUpdatePayload, userID uuid.UUID) (types.Contact, error)
 
-	// DeleteContact deletes a contact
-	DeleteContact(ctx context.Context, contactID, userID uuid.UUID) error
+	// UpdateContactLocked updates the contact with the payload merge makes of its current
+	// values, holding the contact's advisory lock from the read to the write
+	UpdateContactLocked(ctx context.Context, contactID, userID uuid.UUID, merge func(types.Contact) (types.ContactUpdatePayload, error)) (types.Contact, error)
+
+	// UpsertContactByExternalRef creates the contact of an external reference or updates the
+	// fields the payload carries, reporting whether it was created
+	UpsertContactByExternalRef(ctx context.Context, userID uuid.UUID, ref types.ExternalRef, payload types.ContactUpsertPayload) (types.Contact, bool, error)
+
+	// DeleteContact moves a contact to the trash, with a version only while it is still at
+	// that updatedAt
+	DeleteContact(ctx context.Context, contactID, userID uuid.UUID, version *time.Time) error
+
+	// ListDeletedContactsPaginated retrieves a cursor-paginated list of trashed contacts ordered by deletion time
+	ListDeletedContactsPaginated(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32, order coreTypes.SortOrder) ([]types.Contact, error)
+
+	// RestoreContact moves a trashed contact back to the user's contacts
+	RestoreContact(ctx context.Context, contactID, userID uuid.UUID) (types.Contact, error)
 
-	// ListContactsPaginated retrieves a cursor-paginated list of contacts
-	ListContactsPaginated(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32) ([]types.Contact, error)
+	// ListContactsPaginated retrieves a cursor-paginated list of the contacts matching filter
+	ListContactsPaginated(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.ContactFilter) ([]types.Contact, error)
+
+	// ListContactIDsPaginated is ListContactsPaginated reading only the IDs and the keys the list is paged by
+	ListContactIDsPaginated(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.ContactFilter) ([]coreTypes.KeyedID, error)
+
+	// ListContactsPaginatedStream is ListContactsPaginated handing each contact to fn as it is read,
+	// an error from fn stops the stream and is returned as is
+	ListContactsPaginatedStream(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.ContactFilter, fn func(types.Contact) error) error
+
+	// CountContacts returns how many active contacts the user has
+	CountContacts(ctx context.Context, userID uuid.UUID) (int64, error)
 
 	// SearchContacts searches for contacts by name using trigram similarity
-	SearchContacts(ctx context.Context, userID uuid.UUID, name string, limit int32) ([]types.Contact, error)
+	SearchContacts(ctx context.Context, userID uuid.UUID, name string, limit, offset int32) ([]types.Contact, error)
+
+	// SearchContactsStream is SearchContacts handing each contact to fn as it is read,
+	// an error from fn stops the stream and is returned as is
+	SearchContactsStream(ctx context.Context, userID uuid.UUID, name string, limit int32, fn func(types.Contact) error) error
 
 	// SearchContactsByPhone searches for contacts by phone number
-	SearchContactsByPhone(ctx context.Context, userID uuid.UUID, phone string, limit int32) ([]types.Contact, error)
+	SearchContactsByPhone(ctx context.Context, userID uuid.UUID, phone string, limit, offset int32) ([]types.Contact, error)
+
+	// SearchContactsByCompany searches for contacts by company using trigram similarity
+	SearchContactsByCompany(ctx context.Context, userID uuid.UUID, company string, limit, offset int32) ([]types.Contact, error)
+
+	// SearchContactsPicker searches for contacts by name, reading only the columns of the picker view
+	SearchContactsPicker(ctx context.Context, userID uuid.UUID, name string, limit, offset int32) ([]types.ContactPickerItem, error)
+
+	// SearchContactsByPhonePicker searches for contacts by phone number, reading only the columns of the picker view
+	SearchContactsByPhonePicker(ctx context.Context, userID uuid.UUID, phone string, limit, offset int32) ([]types.ContactPickerItem, error)
+
+	// SearchContactsByCompanyPicker searches for contacts by company, reading only the columns of the picker view
+	SearchContactsByCompanyPicker(ctx context.Context, userID uuid.UUID, company string, limit, offset int32) ([]types.ContactPickerItem, error)
+
+	// ListContactCompanies lists the user's companies with their contact counts and first few contacts
+	ListContactCompanies(ctx context.Context, userID uuid.UUID, params types.CompanyListParams) ([]types.CompanyContacts, error)
+
+	// ListFacets counts the distinct values of a facetable field of the user's contacts, most common first
+	ListFacets(ctx context.Context, userID uuid.UUID, field string) ([]coreTypes.Facet, error)
+
+	// CreateContactRelationship relates the contact to another of the user's contacts
+	CreateContactRelationship(ctx context.Context, contactID, userID uuid.UUID, payload types.ContactRelationshipPayload) (types.ContactRelationship, error)
+
+	// ListContactRelationships lists the relationships of the contact in both directions
+	ListContactRelationships(ctx context.Context, contactID, userID uuid.UUID) ([]types.ContactRelationship, error)
+
+	// DeleteContactRelationship deletes a relationship from or to the contact
+	DeleteContactRelationship(ctx context.Context, contactID, relationshipID, userID uuid.UUID) error
+
+	// ListOwnedTagIDs returns the given tag IDs that belong to the user
+	ListOwnedTagIDs(ctx context.Context, userID uuid.UUID, tagIDs []uuid.UUID) ([]uuid.UUID, error)
 }
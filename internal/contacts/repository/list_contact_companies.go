@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+)
+
+func (r *contactRepository) ListContactCompanies(ctx context.Context, userID uuid.UUID, params types.CompanyListParams) ([]types.CompanyContacts, error) {
+	if userID == uuid.Nil {
+		return nil, fmt.Errorf("invalid user id")
+	}
+
+	rows, err := r.q.ListContactCompanies(ctx, db.ListContactCompaniesParams{
+		UserID:        userID,
+		SortBy:        params.SortBy,
+		Limit:         params.Limit,
+		ContactsLimit: params.ContactsLimit,
+	})
+	if err != nil {
+		return nil, errors.HandleRepositoryError(err, "list", "contact companies")
+	}
+
+	return toCompanyContacts(rows), nil
+}
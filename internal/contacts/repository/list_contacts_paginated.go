@@ -10,28 +10,30 @@ import (
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
 )
 
-func (r *contactRepository) ListContactsPaginated(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32) ([]types.Contact, error) {
+func (r *contactRepository) ListContactsPaginated(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.ContactFilter) ([]types.Contact, error) {
 	if userID == uuid.Nil {
 		return nil, fmt.Errorf("invalid user id")
 	}
 
-	if cursor == nil {
-		now := time.Now()
-		cursor = &now
-	}
-	if cursorID == nil {
-		id := uuid.New()
-		cursorID = &id
+	if cursor == nil || cursorID == nil {
+		start, startID := coreTypes.StartCursor(order)
+		cursor = &start
+		cursorID = &startID
 	}
 
 	contacts, err := r.q.ListContactsPaginated(ctx, db.ListContactsPaginatedParams{
-		UserID:    userID,
-		CreatedAt: pgtype.Timestamp{Time: *cursor, Valid: true},
-		ContactID: *cursorID,
-		Limit:     limit,
+		UserID:        userID,
+		City:          utils.ToNullableText(filter.City),
+		StateProvince: utils.ToNullableText(filter.StateProvince),
+		SortOrder:     string(order),
+		CreatedAt:     pgtype.Timestamp{Time: *cursor, Valid: true},
+		ContactID:     *cursorID,
+		Limit:         limit,
 	})
 	if err != nil {
 		return nil, errors.HandleRepositoryError(err, "list", "contacts")
@@ -39,3 +41,58 @@ func (r *contactRepository) ListContactsPaginated(ctx context.Context, userID uu
 
 	return toContacts(contacts), nil
 }
+
+func (r *contactRepository) ListContactIDsPaginated(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.ContactFilter) ([]coreTypes.KeyedID, error) {
+	if userID == uuid.Nil {
+		return nil, fmt.Errorf("invalid user id")
+	}
+
+	if cursor == nil || cursorID == nil {
+		start, startID := coreTypes.StartCursor(order)
+		cursor = &start
+		cursorID = &startID
+	}
+
+	rows, err := r.q.ListContactIDsPaginated(ctx, db.ListContactIDsPaginatedParams{
+		UserID:        userID,
+		City:          utils.ToNullableText(filter.City),
+		StateProvince: utils.ToNullableText(filter.StateProvince),
+		SortOrder:     string(order),
+		CreatedAt:     pgtype.Timestamp{Time: *cursor, Valid: true},
+		ContactID:     *cursorID,
+		Limit:         limit,
+	})
+	if err != nil {
+		return nil, errors.HandleRepositoryError(err, "list", "contacts")
+	}
+
+	keys := make([]coreTypes.KeyedID, len(rows))
+	for i, row := range rows {
+		keys[i] = coreTypes.KeyedID{ID: row.ContactID, CreatedAt: row.CreatedAt.Time}
+	}
+	return keys, nil
+}
+
+func (r *contactRepository) ListContactsPaginatedStream(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.ContactFilter, fn func(types.Contact) error) error {
+	if userID == uuid.Nil {
+		return fmt.Errorf("invalid user id")
+	}
+
+	if cursor == nil || cursorID == nil {
+		start, startID := coreTypes.StartCursor(order)
+		cursor = &start
+		cursorID = &startID
+	}
+
+	return streamContacts(func(scan func(db.Contact) error) error {
+		return r.q.ListContactsPaginatedStream(ctx, db.ListContactsPaginatedParams{
+			UserID:        userID,
+			City:          utils.ToNullableText(filter.City),
+			StateProvince: utils.ToNullableText(filter.StateProvince),
+			SortOrder:     string(order),
+			CreatedAt:     pgtype.Timestamp{Time: *cursor, Valid: true},
+			ContactID:     *cursorID,
+			Limit:         limit,
+		}, scan)
+	}, fn, "list")
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+)
+
+func (r *contactRepository) ListDeletedContactsPaginated(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32, order coreTypes.SortOrder) ([]types.Contact, error) {
+	if userID == uuid.Nil {
+		return nil, fmt.Errorf("invalid user id")
+	}
+
+	if cursor == nil || cursorID == nil {
+		start, startID := coreTypes.StartCursor(order)
+		cursor = &start
+		cursorID = &startID
+	}
+
+	contacts, err := r.q.ListDeletedContactsPaginated(ctx, db.ListDeletedContactsPaginatedParams{
+		UserID:    userID,
+		SortOrder: string(order),
+		DeletedAt: pgtype.Timestamp{Time: *cursor, Valid: true},
+		ContactID: *cursorID,
+		Limit:     limit,
+	})
+	if err != nil {
+		return nil, errors.HandleRepositoryError(err, "list", "deleted contacts")
+	}
+
+	return toContacts(contacts), nil
+}
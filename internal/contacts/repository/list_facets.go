@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+)
+
+func (r *contactRepository) ListFacets(ctx context.Context, userID uuid.UUID, field string) ([]coreTypes.Facet, error) {
+	if userID == uuid.Nil {
+		return nil, fmt.Errorf("invalid user id")
+	}
+
+	rows, err := r.q.ListContactFacets(ctx, db.ListContactFacetsParams{
+		Field:  field,
+		UserID: userID,
+	})
+	if err != nil {
+		return nil, errors.HandleRepositoryError(err, "list", "contact facets")
+	}
+
+	facets := make([]coreTypes.Facet, len(rows))
+	for i, row := range rows {
+		facets[i] = coreTypes.Facet{
+			Value: row.Value,
+			Count: row.Count,
+		}
+	}
+	return facets, nil
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+)
+
+func (r *contactRepository) ListOwnedTagIDs(ctx context.Context, userID uuid.UUID, tagIDs []uuid.UUID) ([]uuid.UUID, error) {
+	if len(tagIDs) == 0 {
+		return []uuid.UUID{}, nil
+	}
+
+	tags, err := r.q.ListTagsByIDs(ctx, db.ListTagsByIDsParams{
+		UserID: userID,
+		TagIds: tagIDs,
+	})
+	if err != nil {
+		return nil, errors.HandleRepositoryError(err, "list", "tags")
+	}
+
+	owned := make([]uuid.UUID, len(tags))
+	for i, tag := range tags {
+		owned[i] = tag.TagID
+	}
+	return owned, nil
+}
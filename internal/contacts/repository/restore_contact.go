@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+)
+
+func (r *contactRepository) RestoreContact(ctx context.Context, contactID, userID uuid.UUID) (types.Contact, error) {
+	if contactID == uuid.Nil || userID == uuid.Nil {
+		return types.Contact{}, fmt.Errorf("invalid contact id or user id")
+	}
+
+	contact, err := r.q.RestoreContact(ctx, db.RestoreContactParams{
+		ContactID: contactID,
+		UserID:    userID,
+	})
+	if err != nil {
+		return types.Contact{}, handleWriteError(err, "restore")
+	}
+
+	return toContact(contact), nil
+}
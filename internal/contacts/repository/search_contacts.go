@@ -11,19 +11,41 @@ import (
 	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
 )
 
-func (r *contactRepository) SearchContacts(ctx context.Context, userID uuid.UUID, name string, limit int32) ([]types.Contact, error) {
+func (r *contactRepository) SearchContacts(ctx context.Context, userID uuid.UUID, name string, limit, offset int32) ([]types.Contact, error) {
 	if userID == uuid.Nil {
 		return nil, fmt.Errorf("invalid user id")
 	}
 
-	contacts, err := r.q.SearchContacts(ctx, db.SearchContactsParams{
+	rows, err := r.q.SearchContacts(ctx, db.SearchContactsParams{
 		UserID: userID,
 		Name:   name,
 		Limit:  limit,
+		Offset: offset,
 	})
 	if err != nil {
 		return nil, errors.HandleRepositoryError(err, "search", "contacts")
 	}
 
-	return toContacts(contacts), nil
+	contacts := make([]types.Contact, len(rows))
+	for i, row := range rows {
+		contacts[i] = toContact(row.Contact)
+		if name != "" {
+			contacts[i].Score = &row.Score
+		}
+	}
+	return contacts, nil
+}
+
+func (r *contactRepository) SearchContactsStream(ctx context.Context, userID uuid.UUID, name string, limit int32, fn func(types.Contact) error) error {
+	if userID == uuid.Nil {
+		return fmt.Errorf("invalid user id")
+	}
+
+	return streamContacts(func(scan func(db.Contact) error) error {
+		return r.q.SearchContactsStream(ctx, db.SearchContactsParams{
+			UserID: userID,
+			Name:   name,
+			Limit:  limit,
+		}, scan)
+	}, fn, "search")
 }
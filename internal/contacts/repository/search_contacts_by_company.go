@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+)
+
+func (r *contactRepository) SearchContactsByCompany(ctx context.Context, userID uuid.UUID, company string, limit, offset int32) ([]types.Contact, error) {
+	if userID == uuid.Nil {
+		return nil, fmt.Errorf("invalid user id")
+	}
+
+	rows, err := r.q.SearchContactsByCompany(ctx, db.SearchContactsByCompanyParams{
+		UserID:  userID,
+		Company: company,
+		Limit:   limit,
+		Offset:  offset,
+	})
+	if err != nil {
+		return nil, errors.HandleRepositoryError(err, "search", "contacts")
+	}
+
+	contacts := make([]types.Contact, len(rows))
+	for i, row := range rows {
+		contacts[i] = toContact(row.Contact)
+		contacts[i].Score = &row.Score
+	}
+	return contacts, nil
+}
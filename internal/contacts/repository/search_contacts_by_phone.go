@@ -11,7 +11,7 @@ import (
 	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
 )
 
-func (r *contactRepository) SearchContactsByPhone(ctx context.Context, userID uuid.UUID, phone string, limit int32) ([]types.Contact, error) {
+func (r *contactRepository) SearchContactsByPhone(ctx context.Context, userID uuid.UUID, phone string, limit, offset int32) ([]types.Contact, error) {
 	if userID == uuid.Nil {
 		return nil, fmt.Errorf("invalid user id")
 	}
@@ -20,6 +20,7 @@ func (r *contactRepository) SearchContactsByPhone(ctx context.Context, userID uu
 		UserID: userID,
 		Phone:  phone,
 		Limit:  limit,
+		Offset: offset,
 	})
 	if err != nil {
 		return nil, errors.HandleRepositoryError(err, "search", "contacts")
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
+)
+
+func (r *contactRepository) SearchContactsPicker(ctx context.Context, userID uuid.UUID, name string, limit, offset int32) ([]types.ContactPickerItem, error) {
+	if userID == uuid.Nil {
+		return nil, fmt.Errorf("invalid user id")
+	}
+
+	rows, err := r.q.SearchContactsPicker(ctx, db.SearchContactsPickerParams{
+		UserID: userID,
+		Name:   name,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, errors.HandleRepositoryError(err, "search", "contacts")
+	}
+
+	items := make([]types.ContactPickerItem, len(rows))
+	for i, row := range rows {
+		items[i] = toContactPickerItem(row)
+	}
+	return items, nil
+}
+
+func (r *contactRepository) SearchContactsByPhonePicker(ctx context.Context, userID uuid.UUID, phone string, limit, offset int32) ([]types.ContactPickerItem, error) {
+	if userID == uuid.Nil {
+		return nil, fmt.Errorf("invalid user id")
+	}
+
+	rows, err := r.q.SearchContactsByPhonePicker(ctx, db.SearchContactsByPhonePickerParams{
+		UserID: userID,
+		Phone:  phone,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, errors.HandleRepositoryError(err, "search", "contacts")
+	}
+
+	items := make([]types.ContactPickerItem, len(rows))
+	for i, row := range rows {
+		items[i] = toContactPickerItem(db.SearchContactsPickerRow(row))
+	}
+	return items, nil
+}
+
+func (r *contactRepository) SearchContactsByCompanyPicker(ctx context.Context, userID uuid.UUID, company string, limit, offset int32) ([]types.ContactPickerItem, error) {
+	if userID == uuid.Nil {
+		return nil, fmt.Errorf("invalid user id")
+	}
+
+	rows, err := r.q.SearchContactsByCompanyPicker(ctx, db.SearchContactsByCompanyPickerParams{
+		UserID:  userID,
+		Company: company,
+		Limit:   limit,
+		Offset:  offset,
+	})
+	if err != nil {
+		return nil, errors.HandleRepositoryError(err, "search", "contacts")
+	}
+
+	items := make([]types.ContactPickerItem, len(rows))
+	for i, row := range rows {
+		items[i] = toContactPickerItem(db.SearchContactsPickerRow(row))
+	}
+	return items, nil
+}
+
+// toContactPickerItem converts a row of the picker searches, which all read the same columns
+func toContactPickerItem(row db.SearchContactsPickerRow) types.ContactPickerItem {
+	return types.ContactPickerItem{
+		ContactID: row.ContactID,
+		Name:      row.Name,
+		Phone:     utils.PgtextToStringPtr(row.Phone),
+		Email:     utils.PgtextToStringPtr(row.Email),
+	}
+}
@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/tracing"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedRepository records a span around each call of the wrapped repository
+type tracedRepository struct {
+	next   Repository
+	tracer trace.Tracer
+}
+
+// NewTracedRepository wraps next so each call records a span named after the method, a nil
+// tracer returns next as is
+func NewTracedRepository(next Repository, tracer trace.Tracer) Repository {
+	if tracer == nil {
+		return next
+	}
+	return &tracedRepository{next: next, tracer: tracer}
+}
+
+func (t *tracedRepository) GetContact(ctx context.Context, contactID, userID uuid.UUID) (types.Contact, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactRepository.GetContact")
+	contact, err := t.next.GetContact(ctx, contactID, userID)
+	tracing.End(span, err)
+	return contact, err
+}
+
+func (t *tracedRepository) GetContactsByIDs(ctx context.Context, userID uuid.UUID, contactIDs []uuid.UUID) ([]types.Contact, []uuid.UUID, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactRepository.GetContactsByIDs")
+	contacts, missing, err := t.next.GetContactsByIDs(ctx, userID, contactIDs)
+	tracing.End(span, err)
+	return contacts, missing, err
+}
+
+func (t *tracedRepository) ListContacts(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]types.Contact, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactRepository.ListContacts")
+	contacts, err := t.next.ListContacts(ctx, userID, limit, offset)
+	tracing.End(span, err)
+	return contacts, err
+}
+
+func (t *tracedRepository) CreateContact(ctx context.Context, payload types.ContactCreatePayload, userID uuid.UUID) (types.Contact, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactRepository.CreateContact")
+	contact, err := t.next.CreateContact(ctx, payload, userID)
+	tracing.End(span, err)
+	return contact, err
+}
+
+func (t *tracedRepository) UpdateContact(ctx context.Context, payload types.ContactUpdatePayload, userID uuid.UUID) (types.Contact, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactRepository.UpdateContact")
+	contact, err := t.next.UpdateContact(ctx, payload, userID)
+	tracing.End(span, err)
+	return contact, err
+}
+
+func (t *tracedRepository) UpdateContactLocked(ctx context.Context, contactID, userID uuid.UUID, merge func(types.Contact) (types.ContactUpdatePayload, error)) (types.Contact, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactRepository.UpdateContactLocked")
+	contact, err := t.next.UpdateContactLocked(ctx, contactID, userID, merge)
+	tracing.End(span, err)
+	return contact, err
+}
+
+func (t *tracedRepository) UpsertContactByExternalRef(ctx context.Context, userID uuid.UUID, ref types.ExternalRef, payload types.ContactUpsertPayload) (types.Contact, bool, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactRepository.UpsertContactByExternalRef")
+	contact, created, err := t.next.UpsertContactByExternalRef(ctx, userID, ref, payload)
+	tracing.End(span, err)
+	return contact, created, err
+}
+
+func (t *tracedRepository) DeleteContact(ctx context.Context, contactID, userID uuid.UUID, version *time.Time) error {
+	ctx, span := t.tracer.Start(ctx, "ContactRepository.DeleteContact")
+	err := t.next.DeleteContact(ctx, contactID, userID, version)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *tracedRepository) ListDeletedContactsPaginated(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32, order coreTypes.SortOrder) ([]types.Contact, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactRepository.ListDeletedContactsPaginated")
+	contacts, err := t.next.ListDeletedContactsPaginated(ctx, userID, cursor, cursorID, limit, order)
+	tracing.End(span, err)
+	return contacts, err
+}
+
+func (t *tracedRepository) RestoreContact(ctx context.Context, contactID, userID uuid.UUID) (types.Contact, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactRepository.RestoreContact")
+	contact, err := t.next.RestoreContact(ctx, contactID, userID)
+	tracing.End(span, err)
+	return contact, err
+}
+
+func (t *tracedRepository) ListContactsPaginated(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.ContactFilter) ([]types.Contact, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactRepository.ListContactsPaginated")
+	contacts, err := t.next.ListContactsPaginated(ctx, userID, cursor, cursorID, limit, order, filter)
+	tracing.End(span, err)
+	return contacts, err
+}
+
+func (t *tracedRepository) ListContactIDsPaginated(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.ContactFilter) ([]coreTypes.KeyedID, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactRepository.ListContactIDsPaginated")
+	keys, err := t.next.ListContactIDsPaginated(ctx, userID, cursor, cursorID, limit, order, filter)
+	tracing.End(span, err)
+	return keys, err
+}
+
+func (t *tracedRepository) ListContactsPaginatedStream(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.ContactFilter, fn func(types.Contact) error) error {
+	ctx, span := t.tracer.Start(ctx, "ContactRepository.ListContactsPaginatedStream")
+	err := t.next.ListContactsPaginatedStream(ctx, userID, cursor, cursorID, limit, order, filter, fn)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *tracedRepository) SearchContacts(ctx context.Context, userID uuid.UUID, name string, limit, offset int32) ([]types.Contact, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactRepository.SearchContacts")
+	contacts, err := t.next.SearchContacts(ctx, userID, name, limit, offset)
+	tracing.End(span, err)
+	return contacts, err
+}
+
+func (t *tracedRepository) SearchContactsStream(ctx context.Context, userID uuid.UUID, name string, limit int32, fn func(types.Contact) error) error {
+	ctx, span := t.tracer.Start(ctx, "ContactRepository.SearchContactsStream")
+	err := t.next.SearchContactsStream(ctx, userID, name, limit, fn)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *tracedRepository) SearchContactsByPhone(ctx context.Context, userID uuid.UUID, phone string, limit, offset int32) ([]types.Contact, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactRepository.SearchContactsByPhone")
+	contacts, err := t.next.SearchContactsByPhone(ctx, userID, phone, limit, offset)
+	tracing.End(span, err)
+	return contacts, err
+}
+
+func (t *tracedRepository) SearchContactsByCompany(ctx context.Context, userID uuid.UUID, company string, limit, offset int32) ([]types.Contact, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactRepository.SearchContactsByCompany")
+	contacts, err := t.next.SearchContactsByCompany(ctx, userID, company, limit, offset)
+	tracing.End(span, err)
+	return contacts, err
+}
+
+func (t *tracedRepository) SearchContactsPicker(ctx context.Context, userID uuid.UUID, name string, limit, offset int32) ([]types.ContactPickerItem, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactRepository.SearchContactsPicker")
+	items, err := t.next.SearchContactsPicker(ctx, userID, name, limit, offset)
+	tracing.End(span, err)
+	return items, err
+}
+
+func (t *tracedRepository) SearchContactsByPhonePicker(ctx context.Context, userID uuid.UUID, phone string, limit, offset int32) ([]types.ContactPickerItem, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactRepository.SearchContactsByPhonePicker")
+	items, err := t.next.SearchContactsByPhonePicker(ctx, userID, phone, limit, offset)
+	tracing.End(span, err)
+	return items, err
+}
+
+func (t *tracedRepository) SearchContactsByCompanyPicker(ctx context.Context, userID uuid.UUID, company string, limit, offset int32) ([]types.ContactPickerItem, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactRepository.SearchContactsByCompanyPicker")
+	items, err := t.next.SearchContactsByCompanyPicker(ctx, userID, company, limit, offset)
+	tracing.End(span, err)
+	return items, err
+}
+
+func (t *tracedRepository) ListContactCompanies(ctx context.Context, userID uuid.UUID, params types.CompanyListParams) ([]types.CompanyContacts, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactRepository.ListContactCompanies")
+	companyContacts, err := t.next.ListContactCompanies(ctx, userID, params)
+	tracing.End(span, err)
+	return companyContacts, err
+}
+
+func (t *tracedRepository) CountContacts(ctx context.Context, userID uuid.UUID) (int64, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactRepository.CountContacts")
+	count, err := t.next.CountContacts(ctx, userID)
+	tracing.End(span, err)
+	return count, err
+}
+
+func (t *tracedRepository) ListFacets(ctx context.Context, userID uuid.UUID, field string) ([]coreTypes.Facet, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactRepository.ListFacets")
+	facets, err := t.next.ListFacets(ctx, userID, field)
+	tracing.End(span, err)
+	return facets, err
+}
+
+func (t *tracedRepository) ListOwnedTagIDs(ctx context.Context, userID uuid.UUID, tagIDs []uuid.UUID) ([]uuid.UUID, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactRepository.ListOwnedTagIDs")
+	ids, err := t.next.ListOwnedTagIDs(ctx, userID, tagIDs)
+	tracing.End(span, err)
+	return ids, err
+}
+
+func (t *tracedRepository) CreateContactRelationship(ctx context.Context, contactID, userID uuid.UUID, payload types.ContactRelationshipPayload) (types.ContactRelationship, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactRepository.CreateContactRelationship")
+	relationship, err := t.next.CreateContactRelationship(ctx, contactID, userID, payload)
+	tracing.End(span, err)
+	return relationship, err
+}
+
+func (t *tracedRepository) ListContactRelationships(ctx context.Context, contactID, userID uuid.UUID) ([]types.ContactRelationship, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactRepository.ListContactRelationships")
+	relationships, err := t.next.ListContactRelationships(ctx, contactID, userID)
+	tracing.End(span, err)
+	return relationships, err
+}
+
+func (t *tracedRepository) DeleteContactRelationship(ctx context.Context, contactID, relationshipID, userID uuid.UUID) error {
+	ctx, span := t.tracer.Start(ctx, "ContactRepository.DeleteContactRelationship")
+	err := t.next.DeleteContactRelationship(ctx, contactID, relationshipID, userID)
+	tracing.End(span, err)
+	return err
+}
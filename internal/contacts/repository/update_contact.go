@@ -7,7 +7,8 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
-	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
 )
 
 func (r *contactRepository) UpdateContact(ctx context.Context, payload types.ContactUpdatePayload, userID uuid.UUID) (types.Contact, error) {
@@ -15,11 +16,40 @@ func (r *contactRepository) UpdateContact(ctx context.Context, payload types.Con
 		return types.Contact{}, fmt.Errorf("invalid contact id or user id")
 	}
 
-	params := updateContactParamsFromPayload(payload, userID)
+	params := updateContactParamsFromPayload(payload, userID, requestcontext.GetActorIDFromContext(ctx, userID))
 	contact, err := r.q.UpdateContact(ctx, params)
 	if err != nil {
-		return types.Contact{}, errors.HandleRepositoryError(err, "update", "contact")
+		return types.Contact{}, handleWriteError(err, "update")
 	}
 
 	return toContact(contact), nil
 }
+
+// UpdateContactLocked updates the contact with the payload merge makes of its current
+// values. The read and the write run in a transaction holding the contact's advisory
+// lock, so concurrent updates apply one after the other instead of interleaving.
+func (r *contactRepository) UpdateContactLocked(ctx context.Context, contactID, userID uuid.UUID, merge func(types.Contact) (types.ContactUpdatePayload, error)) (types.Contact, error) {
+	var contact types.Contact
+	// the errors of the read, merge and write are returned as they are, the others are
+	// those of the transaction and the lock
+	var updateErr error
+	err := r.q.WithEntityLock(ctx, contactID, func(q *db.Queries) error {
+		locked := &contactRepository{q: q}
+		existing, err := locked.GetContact(ctx, contactID, userID)
+		if err == nil {
+			var payload types.ContactUpdatePayload
+			if payload, err = merge(existing); err == nil {
+				contact, err = locked.UpdateContact(ctx, payload, userID)
+			}
+		}
+		updateErr = err
+		return err
+	})
+	if updateErr != nil {
+		return types.Contact{}, updateErr
+	}
+	if err != nil {
+		return types.Contact{}, handleWriteError(err, "update")
+	}
+	return contact, nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+)
+
+func (r *contactRepository) UpsertContactByExternalRef(ctx context.Context, userID uuid.UUID, ref types.ExternalRef, payload types.ContactUpsertPayload) (types.Contact, bool, error) {
+	if userID == uuid.Nil {
+		return types.Contact{}, false, fmt.Errorf("invalid user id")
+	}
+	actorID := requestcontext.GetActorIDFromContext(ctx, userID)
+
+	// without a name the contact can't be created, only updated
+	if payload.Name == nil {
+		contact, err := r.q.UpdateContactByExternalRef(ctx, db.UpdateContactByExternalRefParams{
+			UserID:         userID,
+			ExternalSource: utils.ToNullableText(&ref.Source),
+			ExternalID:     utils.ToNullableText(&ref.ExternalID),
+			Phone:          utils.ToNullableText(payload.Phone),
+			Email:          utils.ToNullableText(payload.Email),
+			AddressLine1:   utils.ToNullableText(payload.AddressLine1),
+			AddressLine2:   utils.ToNullableText(payload.AddressLine2),
+			Country:        utils.ToNullableText(payload.Country),
+			City:           utils.ToNullableText(payload.City),
+			StateProvince:  utils.ToNullableText(payload.StateProvince),
+			ZipPostalCode:  utils.ToNullableText(payload.ZipPostalCode),
+			Tags:           payload.Tags,
+			Company:        utils.ToNullableText(payload.Company),
+			Notes:          utils.ToNullableText(payload.Notes),
+			ActorID:        actorID,
+		})
+		if err == pgx.ErrNoRows {
+			return types.Contact{}, false, errors.NewValidationError("name: is required to create the contact of %s/%s.", ref.Source, ref.ExternalID)
+		}
+		if err != nil {
+			return types.Contact{}, false, handleWriteError(err, "update")
+		}
+		return toContact(contact), false, nil
+	}
+
+	row, err := r.q.UpsertContactByExternalRef(ctx, db.UpsertContactByExternalRefParams{
+		UserID:         userID,
+		ExternalSource: utils.ToNullableText(&ref.Source),
+		ExternalID:     utils.ToNullableText(&ref.ExternalID),
+		Name:           *payload.Name,
+		Phone:          utils.ToNullableText(payload.Phone),
+		Email:          utils.ToNullableText(payload.Email),
+		AddressLine1:   utils.ToNullableText(payload.AddressLine1),
+		AddressLine2:   utils.ToNullableText(payload.AddressLine2),
+		Country:        utils.ToNullableText(payload.Country),
+		City:           utils.ToNullableText(payload.City),
+		StateProvince:  utils.ToNullableText(payload.StateProvince),
+		ZipPostalCode:  utils.ToNullableText(payload.ZipPostalCode),
+		Tags:           payload.Tags,
+		Company:        utils.ToNullableText(payload.Company),
+		Notes:          utils.ToNullableText(payload.Notes),
+		ActorID:        actorID,
+	})
+	if err != nil {
+		return types.Contact{}, false, handleWriteError(err, "upsert")
+	}
+
+	return toContact(row.Contact), row.Inserted, nil
+}
@@ -1,13 +1,31 @@
 package repository
 
 import (
+	"encoding/json"
+
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
 )
 
+// contactEmailIndex keeps contact emails unique per user while
+// database.uniqueContactEmails is on
+const contactEmailIndex = "contacts_user_id_email_key_idx"
+
+// handleWriteError reports a taken email as a conflict on the field, other errors go
+// through errors.HandleRepositoryError
+func handleWriteError(err error, operation string) error {
+	if errors.IsUniqueViolation(err, contactEmailIndex) {
+		return errors.NewConflictError("email: already used by another contact.")
+	}
+	return errors.HandleRepositoryError(err, operation, "contact")
+}
+
 // toContact converts a db.Contact to domain types.Contact
 func toContact(c db.Contact) types.Contact {
 	return types.Contact{
@@ -22,10 +40,43 @@ func toContact(c db.Contact) types.Contact {
 		City:          utils.PgtextToStringPtr(c.City),
 		StateProvince: utils.PgtextToStringPtr(c.StateProvince),
 		ZipPostalCode: utils.PgtextToStringPtr(c.ZipPostalCode),
+		Company:       utils.PgtextToStringPtr(c.Company),
+		Notes:         utils.PgtextToStringPtr(c.Notes),
 		Tags:          c.Tags,
-		CreatedAt:     c.CreatedAt.Time,
-		UpdatedAt:     c.UpdatedAt.Time,
+		Links:         toLinks(c.Links),
+		CreatedAt:     coreTypes.NewTimestamp(c.CreatedAt.Time),
+		UpdatedAt:     coreTypes.NewTimestamp(c.UpdatedAt.Time),
+		DeletedAt:     utils.GetTimestampPtr(c.DeletedAt),
+		CreatedBy:     utils.GetUUIDPtr(c.CreatedBy),
+		UpdatedBy:     utils.GetUUIDPtr(c.UpdatedBy),
+		ExternalRef:   toExternalRef(c.ExternalSource, c.ExternalID),
+	}
+}
+
+// toExternalRef returns the external reference of a synced contact, nil for the others
+func toExternalRef(source, externalID pgtype.Text) *types.ExternalRef {
+	if !source.Valid || !externalID.Valid {
+		return nil
+	}
+	return &types.ExternalRef{Source: source.String, ExternalID: externalID.String}
+}
+
+// toLinks decodes the links column, which the database keeps an array
+func toLinks(data []byte) []types.ContactLink {
+	var links []types.ContactLink
+	if err := json.Unmarshal(data, &links); err != nil || len(links) == 0 {
+		return nil
 	}
+	return links
+}
+
+// linksParam encodes links for the links column, no links is the empty array
+func linksParam(links []types.ContactLink) []byte {
+	if len(links) == 0 {
+		return []byte("[]")
+	}
+	data, _ := json.Marshal(links)
+	return data
 }
 
 // toContacts converts a slice of db.Contact to a slice of domain types.Contact
@@ -37,8 +88,25 @@ func toContacts(contacts []db.Contact) []types.Contact {
 	return result
 }
 
+// streamContacts runs a streaming query, converting each row before handing it to fn.
+// Errors returned by fn come back untouched rather than reported as database errors.
+func streamContacts(stream func(scan func(db.Contact) error) error, fn func(types.Contact) error, operation string) error {
+	var fnErr error
+	err := stream(func(c db.Contact) error {
+		fnErr = fn(toContact(c))
+		return fnErr
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	if err != nil {
+		return errors.HandleRepositoryError(err, operation, "contacts")
+	}
+	return nil
+}
+
 // createContactParamsFromPayload converts ContactCreatePayload to db.CreateContactParams
-func createContactParamsFromPayload(payload types.ContactCreatePayload, userID uuid.UUID) db.CreateContactParams {
+func createContactParamsFromPayload(payload types.ContactCreatePayload, userID, actorID uuid.UUID) db.CreateContactParams {
 	return db.CreateContactParams{
 		UserID:        userID,
 		Name:          payload.Name,
@@ -51,11 +119,15 @@ func createContactParamsFromPayload(payload types.ContactCreatePayload, userID u
 		StateProvince: utils.ToNullableText(payload.StateProvince),
 		ZipPostalCode: utils.ToNullableText(payload.ZipPostalCode),
 		Tags:          payload.Tags,
+		Company:       utils.ToNullableText(payload.Company),
+		Notes:         utils.ToNullableText(payload.Notes),
+		Links:         linksParam(payload.Links),
+		ActorID:       actorID,
 	}
 }
 
 // updateContactParamsFromPayload converts ContactUpdatePayload to db.UpdateContactParams
-func updateContactParamsFromPayload(payload types.ContactUpdatePayload, userID uuid.UUID) db.UpdateContactParams {
+func updateContactParamsFromPayload(payload types.ContactUpdatePayload, userID, actorID uuid.UUID) db.UpdateContactParams {
 	return db.UpdateContactParams{
 		ContactID:     payload.ContactID,
 		UserID:        userID,
@@ -69,5 +141,31 @@ func updateContactParamsFromPayload(payload types.ContactUpdatePayload, userID u
 		StateProvince: utils.ToNullableText(payload.StateProvince),
 		ZipPostalCode: utils.ToNullableText(payload.ZipPostalCode),
 		Tags:          payload.Tags,
+		Company:       utils.ToNullableText(payload.Company),
+		Notes:         utils.ToNullableText(payload.Notes),
+		Links:         linksParam(payload.Links),
+		ActorID:       actorID,
 	}
 }
+
+// toCompanyContacts groups the flat company rows into companies with their contacts
+func toCompanyContacts(rows []db.ListContactCompaniesRow) []types.CompanyContacts {
+	result := make([]types.CompanyContacts, 0)
+	for _, row := range rows {
+		if len(result) == 0 || result[len(result)-1].Company != row.Company {
+			result = append(result, types.CompanyContacts{
+				Company:      row.Company,
+				ContactCount: row.ContactCount,
+				Contacts:     make([]types.ContactSummary, 0),
+			})
+		}
+		last := &result[len(result)-1]
+		last.Contacts = append(last.Contacts, types.ContactSummary{
+			ContactID: row.ContactID,
+			Name:      row.Name,
+			Email:     utils.PgtextToStringPtr(row.Email),
+			Phone:     utils.PgtextToStringPtr(row.Phone),
+		})
+	}
+	return result
+}
@@ -1,11 +1,21 @@
 package routes
 
 import (
+	"github.com/Abdelrahman-habib/expense-tracker/config"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/handlers"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/repository"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/service"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/blob"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/quota"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/events"
+	jobTypes "github.com/Abdelrahman-habib/expense-tracker/internal/jobs/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/jobs/worker"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/validate"
 	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -15,18 +25,23 @@ type Router struct {
 }
 
 // New creates a new contact router with proper dependency injection
-func New(dbService db.Service, logger *zap.Logger) *Router {
+func New(dbService db.Service, jobs *worker.Runner, blobs blob.Store, exports config.ExportsConfig, emails *validate.EmailChecker, quotas *quota.Checker, bus *events.Bus, limits coreTypes.LimitPolicy, logger *zap.Logger, tracer trace.Tracer) *Router {
 	// Get queries from db service
 	queries := dbService.Queries()
 
 	// Initialize repository
-	repo := repository.New(queries)
+	repo := repository.NewTracedRepository(repository.New(queries), tracer)
 
-	// Initialize service with repository
-	contactservice := service.NewContactService(repo, logger)
+	// Initialize service with repository, the job runner for imports and exports and the
+	// checker of contact emails, capping the contacts of each user at their quota and
+	// publishing their changes to bus
+	policy := types.ExportPolicy{SyncMaxRows: exports.SyncMaxRows}
+	contactservice := service.NewTracedContactService(service.NewContactService(repo, jobs, blobs, policy, emails, quotas, bus, logger), tracer)
+	jobs.Register(jobTypes.JobTypeContactImport, service.ImportProcessor(emails, quotas))
+	jobs.RegisterTask(jobTypes.JobTypeContactExport, service.ExportTask(repo, blobs))
 
 	// Initialize handler with service
-	handler := handlers.NewContactHandler(contactservice, logger)
+	handler := handlers.NewContactHandler(contactservice, limits, logger)
 
 	return &Router{
 		handler: handler,
@@ -39,11 +54,25 @@ func (r *Router) RegisterRoutes(router chi.Router) {
 		router.Get("/", r.handler.ListContactsPaginated)
 		router.Get("/paginated", r.handler.ListContactsPaginated)
 		router.Get("/search", r.handler.SearchContacts)
+		router.Get("/by-company", r.handler.ListContactCompanies)
+		router.Get("/companies", r.handler.ListCompanies)
+		router.Get("/facets", r.handler.ListContactFacets)
+		router.Get("/trash", r.handler.ListDeletedContacts)
+		router.Get("/export", r.handler.ExportContacts)
+		router.Post("/export-jobs", r.handler.StartContactExport)
+		router.Get("/export-jobs/{id}", r.handler.GetContactExport)
+		router.Get("/export-jobs/{id}/download", r.handler.DownloadContactExport)
 		router.Post("/", r.handler.CreateContact)
+		router.Post("/import", r.handler.ImportContacts)
+		router.Post("/validate-batch", r.handler.ValidateContacts)
+		router.Put("/external/{source}/{external_id}", r.handler.UpsertContactByExternalRef)
 		router.Route("/{id}", func(router chi.Router) {
 			router.Get("/", r.handler.GetContact)
 			router.Put("/", r.handler.UpdateContact)
 			router.Delete("/", r.handler.DeleteContact)
+			router.Post("/restore", r.handler.RestoreContact)
+			router.Post("/relationships", r.handler.CreateContactRelationship)
+			router.Delete("/relationships/{relationshipId}", r.handler.DeleteContactRelationship)
 		})
 	})
 }
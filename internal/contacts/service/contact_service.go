@@ -2,45 +2,161 @@ package service
 
 import (
 	"context"
+	stdErrors "errors"
 	"fmt"
+	"io"
+	"slices"
 	"strings"
 	"time"
+	"unicode/utf8"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/blob"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/cache"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/logging"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/quota"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/repository"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/events"
+	jobTypes "github.com/Abdelrahman-habib/expense-tracker/internal/jobs/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/jobs/worker"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/validate"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 type ContactService interface {
-	GetContact(ctx context.Context, contactID, userID uuid.UUID) (types.Contact, error)
+	GetContact(ctx context.Context, contactID, userID uuid.UUID, expand types.ContactExpand) (types.Contact, error)
+	GetContactsByIDs(ctx context.Context, userID uuid.UUID, contactIDs []uuid.UUID) ([]types.Contact, []uuid.UUID, error)
 	ListContacts(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]types.Contact, error)
 	CreateContact(ctx context.Context, payload types.ContactCreatePayload, userID uuid.UUID) (types.Contact, error)
-	UpdateContact(ctx context.Context, payload types.ContactUpdatePayload, userID uuid.UUID) (types.Contact, error)
-	DeleteContact(ctx context.Context, contactID, userID uuid.UUID) error
-	ListContactsPaginated(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32) ([]types.Contact, error)
-	SearchContacts(ctx context.Context, userID uuid.UUID, name string, limit int32) ([]types.Contact, error)
-	SearchContactsByPhone(ctx context.Context, userID uuid.UUID, phone string, limit int32) ([]types.Contact, error)
+	UpdateContact(ctx context.Context, contactID, userID uuid.UUID, merge func(types.Contact) (types.ContactUpdatePayload, error)) (types.Contact, error)
+	UpsertContactByExternalRef(ctx context.Context, userID uuid.UUID, ref types.ExternalRef, payload types.ContactUpsertPayload) (types.Contact, bool, error)
+	DeleteContact(ctx context.Context, contactID, userID uuid.UUID, version *time.Time) error
+	ListDeletedContactsPaginated(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32, order coreTypes.SortOrder) ([]types.Contact, error)
+	RestoreContact(ctx context.Context, contactID, userID uuid.UUID) (types.Contact, error)
+	ListContactsPaginated(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.ContactFilter) ([]types.Contact, error)
+	ListContactIDsPaginated(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.ContactFilter) ([]coreTypes.KeyedID, error)
+	StreamContactsPaginated(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.ContactFilter, fn func(types.Contact) error) error
+	SearchContacts(ctx context.Context, userID uuid.UUID, name string, limit, offset int32) ([]types.Contact, error)
+	SearchContactsByPhone(ctx context.Context, userID uuid.UUID, phone string, limit, offset int32) ([]types.Contact, error)
+	SearchContactsByCompany(ctx context.Context, userID uuid.UUID, company string, limit, offset int32) ([]types.Contact, error)
+	SearchContactsPicker(ctx context.Context, userID uuid.UUID, name string, limit, offset int32) ([]types.ContactPickerItem, error)
+	SearchContactsByPhonePicker(ctx context.Context, userID uuid.UUID, phone string, limit, offset int32) ([]types.ContactPickerItem, error)
+	SearchContactsByCompanyPicker(ctx context.Context, userID uuid.UUID, company string, limit, offset int32) ([]types.ContactPickerItem, error)
+	ListContactCompanies(ctx context.Context, userID uuid.UUID, params types.CompanyListParams) ([]types.CompanyContacts, error)
+	ListCompanies(ctx context.Context, userID uuid.UUID, limit int32) ([]types.CompanyCount, error)
+	ListFacets(ctx context.Context, userID uuid.UUID, field string) ([]coreTypes.Facet, error)
+	ImportContacts(ctx context.Context, userID uuid.UUID, contacts []types.ContactCreatePayload) (jobTypes.Job, error)
+	ValidateContacts(ctx context.Context, userID uuid.UUID, contacts []types.ContactCreatePayload) (types.ContactBatchValidation, error)
+	ExportContacts(ctx context.Context, userID uuid.UUID, fn func(types.Contact) error) error
+	StartContactExport(ctx context.Context, userID uuid.UUID, format string) (types.ExportJob, error)
+	GetContactExport(ctx context.Context, userID, jobID uuid.UUID) (types.ExportJob, error)
+	OpenContactExport(ctx context.Context, userID, jobID uuid.UUID) (types.ExportJob, io.ReadCloser, error)
+	CreateContactRelationship(ctx context.Context, contactID, userID uuid.UUID, payload types.ContactRelationshipPayload) (types.ContactRelationship, error)
+	DeleteContactRelationship(ctx context.Context, contactID, relationshipID, userID uuid.UUID) error
 }
 
 type contactService struct {
-	repo   repository.Repository
-	logger *zap.Logger
+	repo     repository.Repository
+	jobs     worker.Queue
+	blobs    blob.Store
+	exports  types.ExportPolicy
+	emails   *validate.EmailChecker
+	quotas   *quota.Checker
+	events   *events.Bus
+	searches cache.Coalescer
+	logger   *zap.Logger
 }
 
-func NewContactService(repo repository.Repository, logger *zap.Logger) ContactService {
+// NewContactService creates the contact service, emails checks the domain of contact
+// emails and nil only suggests corrections for the default typo domains. quotas caps the
+// contacts of each user, nil leaves them unlimited. The changes to contacts are published
+// to bus, nil publishes nothing.
+func NewContactService(repo repository.Repository, jobs worker.Queue, blobs blob.Store, exports types.ExportPolicy, emails *validate.EmailChecker, quotas *quota.Checker, bus *events.Bus, logger *zap.Logger) ContactService {
 	return &contactService{
-		repo:   repo,
-		logger: logger.With(zap.String("component", "contact_service")),
+		repo:    repo,
+		jobs:    jobs,
+		blobs:   blobs,
+		exports: exports,
+		emails:  orDefaultEmailChecker(emails),
+		quotas:  quotas,
+		events:  bus,
+		logger:  logger.With(zap.String("component", "contact_service")),
+	}
+}
+
+// orDefaultEmailChecker returns emails, or a checker of the default typo domains when it is nil
+func orDefaultEmailChecker(emails *validate.EmailChecker) *validate.EmailChecker {
+	if emails == nil {
+		return validate.NewEmailChecker(validate.DefaultEmailTypoDomains, nil, 0)
+	}
+	return emails
+}
+
+// normalizeCompany trims the company name and collapses repeated whitespace,
+// treating a blank company as unset
+func normalizeCompany(company *string) *string {
+	if company == nil {
+		return nil
+	}
+	normalized := strings.Join(strings.Fields(*company), " ")
+	if normalized == "" {
+		return nil
 	}
+	return &normalized
 }
 
-// cleanPhoneNumber removes any '+' or '-' characters from the phone number
-func cleanPhoneNumber(phone string) string {
-	phone = strings.ReplaceAll(phone, "+", "")
-	phone = strings.ReplaceAll(phone, "-", "")
-	phone = strings.ReplaceAll(phone, " ", "")
-	return phone
+// normalizePlace normalizes a city or state like the list filters do, treating a blank
+// one as unset
+func normalizePlace(place *string) *string {
+	if place == nil {
+		return nil
+	}
+	normalized := types.NormalizePlace(*place)
+	if normalized == "" {
+		return nil
+	}
+	return &normalized
+}
+
+// normalizeEmail trims and lower cases the email of a contact and checks its domain,
+// treating a blank email as unset. A domain that looks like a typo is refused with the
+// corrected address as a suggestion.
+func normalizeEmail(ctx context.Context, emails *validate.EmailChecker, email *string) (*string, error) {
+	if email == nil {
+		return nil, nil
+	}
+	normalized := validate.NormalizeEmail(*email)
+	if normalized == "" {
+		return nil, nil
+	}
+
+	err := emails.Check(ctx, normalized)
+	var typo *validate.EmailTypoError
+	switch {
+	case err == nil:
+		return &normalized, nil
+	case stdErrors.As(err, &typo):
+		return nil, errors.NewEmailDomainSuspectError("email", typo.Email, typo.Suggestion)
+	default:
+		return nil, errors.NewValidationError("email: %v", err)
+	}
+}
+
+// unnamedContactName is the placeholder name of a contact created from its cleaned phone
+// alone, the phone has to hold digits once cleaned for the name to tell contacts apart
+func unnamedContactName(phone *string) (string, error) {
+	if phone == nil || *phone == "" {
+		return "", errors.NewValidationError("phone: is required for a contact without a name")
+	}
+	digits := strings.NewReplacer("(", "", ")", "").Replace(*phone)
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return "", errors.NewValidationError("phone: must be a phone number to name the contact after")
+	}
+	return "Unnamed contact " + digits, nil
 }
 
 // Common validation function
@@ -55,6 +171,10 @@ func validateContact(name string, tags []uuid.UUID) error {
 		return fmt.Errorf("name exceeds maximum length of %d characters", types.MaxNameLength)
 	}
 
+	return validateTags(tags)
+}
+
+func validateTags(tags []uuid.UUID) error {
 	// Validate tags
 	if tags != nil && len(tags) > types.MaxTagsCount {
 		return fmt.Errorf("number of tags exceeds maximum allowed of %d", types.MaxTagsCount)
@@ -74,36 +194,122 @@ func validateContact(name string, tags []uuid.UUID) error {
 	return nil
 }
 
-func (s *contactService) CreateContact(ctx context.Context, payload types.ContactCreatePayload, userID uuid.UUID) (types.Contact, error) {
-	s.logger.Info("creating contact",
-		zap.String("user_id", userID.String()),
-		zap.String("name", payload.Name))
+// operation starts the log of a contact service method, contactID is uuid.Nil when the
+// method doesn't work on one contact
+func (s *contactService) operation(name string, userID, contactID uuid.UUID, fields ...zap.Field) *logging.Operation {
+	logger := logging.WithEntity(logging.WithUser(s.logger, userID), "contact", contactID)
+	return logging.Start(logger, "ContactService."+name, fields...)
+}
+
+// publish tells the user's clients listening for changes that the contact changed
+func (s *contactService) publish(userID, contactID uuid.UUID, action string, updatedAt time.Time) {
+	s.events.Publish(userID, events.Event{Type: events.TypeContact, EntityID: contactID, Action: action, UpdatedAt: coreTypes.NewTimestamp(updatedAt)})
+}
 
-	if err := validateContact(payload.Name, payload.Tags); err != nil {
+func (s *contactService) CreateContact(ctx context.Context, payload types.ContactCreatePayload, userID uuid.UUID) (_ types.Contact, err error) {
+	op := s.operation("CreateContact", userID, uuid.Nil, zap.String("name", payload.Name))
+	defer op.End(&err)
+
+	payload, err = prepareCreatePayload(ctx, payload, s.emails)
+	if err != nil {
+		return types.Contact{}, err
+	}
+	if err := s.quotas.Check(ctx, userID, quota.Contacts, 1); err != nil {
 		return types.Contact{}, err
 	}
 
+	contact, err := s.repo.CreateContact(ctx, payload, userID)
+	if err != nil {
+		return types.Contact{}, err
+	}
+	op.With(zap.Stringer(logging.FieldEntityID, contact.ContactID))
+	s.publish(userID, contact.ContactID, events.ActionCreated, contact.UpdatedAt.Time)
+	return contact, nil
+}
+
+// prepareCreatePayload validates a new contact and normalizes its phone, email, company and place
+func prepareCreatePayload(ctx context.Context, payload types.ContactCreatePayload, emails *validate.EmailChecker) (types.ContactCreatePayload, error) {
 	// Clean phone number if provided
 	if payload.Phone != nil {
-		cleaned := cleanPhoneNumber(*payload.Phone)
+		cleaned := types.CleanPhoneNumber(*payload.Phone)
 		payload.Phone = &cleaned
 	}
 
-	return s.repo.CreateContact(ctx, payload, userID)
+	if payload.AllowUnnamed && payload.Name == "" {
+		name, err := unnamedContactName(payload.Phone)
+		if err != nil {
+			return payload, err
+		}
+		payload.Name = name
+	}
+
+	if err := validateContact(payload.Name, payload.Tags); err != nil {
+		return payload, err
+	}
+
+	email, err := normalizeEmail(ctx, emails, payload.Email)
+	if err != nil {
+		return payload, err
+	}
+	payload.Email = email
+
+	payload.Company = normalizeCompany(payload.Company)
+	if payload.Company != nil && utf8.RuneCountInString(*payload.Company) > types.MaxCompanyLength {
+		return payload, fmt.Errorf("company exceeds maximum length of %d characters", types.MaxCompanyLength)
+	}
+	payload.City = normalizePlace(payload.City)
+	payload.StateProvince = normalizePlace(payload.StateProvince)
+
+	return payload, nil
 }
 
-func (s *contactService) GetContact(ctx context.Context, contactID, userID uuid.UUID) (types.Contact, error) {
-	s.logger.Info("getting contact",
-		zap.String("contact_id", contactID.String()),
-		zap.String("user_id", userID.String()))
-	return s.repo.GetContact(ctx, contactID, userID)
+// GetContact gets the contact, with expand.Relationships along with its relationships both ways
+func (s *contactService) GetContact(ctx context.Context, contactID, userID uuid.UUID, expand types.ContactExpand) (_ types.Contact, err error) {
+	defer s.operation("GetContact", userID, contactID).End(&err)
+
+	contact, err := s.repo.GetContact(ctx, contactID, userID)
+	if err != nil || !expand.Relationships {
+		return contact, err
+	}
+
+	relationships, err := s.repo.ListContactRelationships(ctx, contactID, userID)
+	if err != nil {
+		return types.Contact{}, err
+	}
+	contact.Relationships = relationships
+	return contact, nil
+}
+
+// GetContactsByIDs gets the user's contacts with the IDs in the order asked for, the IDs
+// of the contacts not found are returned apart
+func (s *contactService) GetContactsByIDs(ctx context.Context, userID uuid.UUID, contactIDs []uuid.UUID) (_ []types.Contact, _ []uuid.UUID, err error) {
+	defer s.operation("GetContactsByIDs", userID, uuid.Nil, zap.Int("ids", len(contactIDs))).End(&err)
+	return s.repo.GetContactsByIDs(ctx, userID, contactIDs)
+}
+
+// CreateContactRelationship relates the contact to another of the user's contacts, a
+// contact can't be related to itself
+func (s *contactService) CreateContactRelationship(ctx context.Context, contactID, userID uuid.UUID, payload types.ContactRelationshipPayload) (_ types.ContactRelationship, err error) {
+	defer s.operation("CreateContactRelationship", userID, contactID,
+		zap.String("related_contact_id", payload.ContactID.String()),
+		zap.String("type", payload.Type)).End(&err)
+
+	if payload.ContactID == contactID {
+		return types.ContactRelationship{}, errors.NewValidationError("contact_id: a contact can't be related to itself")
+	}
+	return s.repo.CreateContactRelationship(ctx, contactID, userID, payload)
+}
+
+func (s *contactService) DeleteContactRelationship(ctx context.Context, contactID, relationshipID, userID uuid.UUID) (err error) {
+	defer s.operation("DeleteContactRelationship", userID, contactID,
+		zap.String("relationship_id", relationshipID.String())).End(&err)
+	return s.repo.DeleteContactRelationship(ctx, contactID, relationshipID, userID)
 }
 
-func (s *contactService) ListContacts(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]types.Contact, error) {
-	s.logger.Info("listing contacts",
-		zap.String("user_id", userID.String()),
+func (s *contactService) ListContacts(ctx context.Context, userID uuid.UUID, limit, offset int32) (_ []types.Contact, err error) {
+	defer s.operation("ListContacts", userID, uuid.Nil,
 		zap.Int32("limit", limit),
-		zap.Int32("offset", offset))
+		zap.Int32("offset", offset)).End(&err)
 
 	if limit <= 0 {
 		return nil, fmt.Errorf("limit must be positive")
@@ -112,70 +318,340 @@ func (s *contactService) ListContacts(ctx context.Context, userID uuid.UUID, lim
 	return s.repo.ListContacts(ctx, userID, limit, offset)
 }
 
-func (s *contactService) UpdateContact(ctx context.Context, payload types.ContactUpdatePayload, userID uuid.UUID) (types.Contact, error) {
-	s.logger.Info("updating contact",
-		zap.String("contact_id", payload.ContactID.String()),
-		zap.String("user_id", userID.String()))
+// UpdateContact updates the contact with the payload merge makes of its current values.
+// The contact's advisory lock is held from the read to the write, so concurrent updates
+// apply one after the other instead of one losing the changes of the other.
+func (s *contactService) UpdateContact(ctx context.Context, contactID, userID uuid.UUID, merge func(types.Contact) (types.ContactUpdatePayload, error)) (_ types.Contact, err error) {
+	defer s.operation("UpdateContact", userID, contactID).End(&err)
 
-	if err := validateContact(payload.Name, payload.Tags); err != nil {
+	contact, err := s.repo.UpdateContactLocked(ctx, contactID, userID, func(existing types.Contact) (types.ContactUpdatePayload, error) {
+		payload, err := merge(existing)
+		if err != nil {
+			return types.ContactUpdatePayload{}, err
+		}
+		return s.prepareUpdate(ctx, payload)
+	})
+	if err != nil {
 		return types.Contact{}, err
 	}
+	s.publish(userID, contact.ContactID, events.ActionUpdated, contact.UpdatedAt.Time)
+	return contact, nil
+}
+
+// prepareUpdate validates the payload of a contact update and normalizes its fields
+func (s *contactService) prepareUpdate(ctx context.Context, payload types.ContactUpdatePayload) (_ types.ContactUpdatePayload, err error) {
+	if err := validateContact(payload.Name, payload.Tags); err != nil {
+		return types.ContactUpdatePayload{}, err
+	}
 
 	// Clean phone number if provided
 	if payload.Phone != nil {
-		cleaned := cleanPhoneNumber(*payload.Phone)
+		cleaned := types.CleanPhoneNumber(*payload.Phone)
 		payload.Phone = &cleaned
 	}
 
-	return s.repo.UpdateContact(ctx, payload, userID)
+	if payload.Email, err = normalizeEmail(ctx, s.emails, payload.Email); err != nil {
+		return types.ContactUpdatePayload{}, err
+	}
+
+	payload.Company = normalizeCompany(payload.Company)
+	if payload.Company != nil && utf8.RuneCountInString(*payload.Company) > types.MaxCompanyLength {
+		return types.ContactUpdatePayload{}, fmt.Errorf("company exceeds maximum length of %d characters", types.MaxCompanyLength)
+	}
+	payload.City = normalizePlace(payload.City)
+	payload.StateProvince = normalizePlace(payload.StateProvince)
+	return payload, nil
 }
 
-func (s *contactService) DeleteContact(ctx context.Context, contactID, userID uuid.UUID) error {
-	s.logger.Info("deleting contact",
-		zap.String("contact_id", contactID.String()),
-		zap.String("user_id", userID.String()))
-	return s.repo.DeleteContact(ctx, contactID, userID)
+// UpsertContactByExternalRef creates or updates the contact a sync refers to by its ID in
+// the source system, the bool reports whether the contact was created
+func (s *contactService) UpsertContactByExternalRef(ctx context.Context, userID uuid.UUID, ref types.ExternalRef, payload types.ContactUpsertPayload) (_ types.Contact, _ bool, err error) {
+	op := s.operation("UpsertContactByExternalRef", userID, uuid.Nil,
+		zap.String("source", ref.Source),
+		zap.String("external_id", ref.ExternalID))
+	defer op.End(&err)
+
+	if payload.Name != nil {
+		err = validateContact(*payload.Name, payload.Tags)
+	} else {
+		err = validateTags(payload.Tags)
+	}
+	if err != nil {
+		return types.Contact{}, false, err
+	}
+
+	// Clean phone number if provided
+	if payload.Phone != nil {
+		cleaned := types.CleanPhoneNumber(*payload.Phone)
+		payload.Phone = &cleaned
+	}
+
+	if payload.Email, err = normalizeEmail(ctx, s.emails, payload.Email); err != nil {
+		return types.Contact{}, false, err
+	}
+
+	payload.Company = normalizeCompany(payload.Company)
+	if payload.Company != nil && utf8.RuneCountInString(*payload.Company) > types.MaxCompanyLength {
+		return types.Contact{}, false, fmt.Errorf("company exceeds maximum length of %d characters", types.MaxCompanyLength)
+	}
+	payload.City = normalizePlace(payload.City)
+	payload.StateProvince = normalizePlace(payload.StateProvince)
+
+	contact, created, err := s.repo.UpsertContactByExternalRef(ctx, userID, ref, payload)
+	if err != nil {
+		return types.Contact{}, false, err
+	}
+	op.With(zap.Stringer(logging.FieldEntityID, contact.ContactID), zap.Bool("created", created))
+	action := events.ActionUpdated
+	if created {
+		action = events.ActionCreated
+	}
+	s.publish(userID, contact.ContactID, action, contact.UpdatedAt.Time)
+	return contact, created, nil
+}
+
+// DeleteContact trashes the contact, with a version only while it is still at that
+// updatedAt so a change made since the caller read it fails the delete
+func (s *contactService) DeleteContact(ctx context.Context, contactID, userID uuid.UUID, version *time.Time) (err error) {
+	defer s.operation("DeleteContact", userID, contactID).End(&err)
+	if err := s.repo.DeleteContact(ctx, contactID, userID, version); err != nil {
+		return err
+	}
+	s.publish(userID, contactID, events.ActionDeleted, time.Time{})
+	return nil
 }
 
-func (s *contactService) ListContactsPaginated(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32) ([]types.Contact, error) {
-	s.logger.Info("listing paginated contacts",
-		zap.String("user_id", userID.String()),
+func (s *contactService) ListDeletedContactsPaginated(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32, order coreTypes.SortOrder) (_ []types.Contact, err error) {
+	defer s.operation("ListDeletedContactsPaginated", userID, uuid.Nil,
 		zap.Any("cursor", cursor),
 		zap.Any("cursor_id", cursorID),
-		zap.Int32("limit", limit))
+		zap.Int32("limit", limit),
+		zap.String("order", string(order))).End(&err)
 
 	if limit <= 0 {
 		return nil, fmt.Errorf("limit must be positive")
 	}
 
-	return s.repo.ListContactsPaginated(ctx, userID, cursor, cursorID, limit)
+	return s.repo.ListDeletedContactsPaginated(ctx, userID, cursor, cursorID, limit, order)
 }
 
-func (s *contactService) SearchContacts(ctx context.Context, userID uuid.UUID, name string, limit int32) ([]types.Contact, error) {
-	s.logger.Info("searching contacts by name",
-		zap.String("user_id", userID.String()),
+func (s *contactService) RestoreContact(ctx context.Context, contactID, userID uuid.UUID) (_ types.Contact, err error) {
+	defer s.operation("RestoreContact", userID, contactID).End(&err)
+	if err := s.quotas.Check(ctx, userID, quota.Contacts, 1); err != nil {
+		return types.Contact{}, err
+	}
+	contact, err := s.repo.RestoreContact(ctx, contactID, userID)
+	if err != nil {
+		return types.Contact{}, err
+	}
+	s.publish(userID, contact.ContactID, events.ActionRestored, contact.UpdatedAt.Time)
+	return contact, nil
+}
+
+func (s *contactService) ListContactsPaginated(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.ContactFilter) (_ []types.Contact, err error) {
+	defer s.operation("ListContactsPaginated", userID, uuid.Nil,
+		zap.Any("cursor", cursor),
+		zap.Any("cursor_id", cursorID),
+		zap.Int32("limit", limit),
+		zap.String("order", string(order)),
+		zap.Any("filter", filter)).End(&err)
+
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+
+	return s.repo.ListContactsPaginated(ctx, userID, cursor, cursorID, limit, order, filter)
+}
+
+// ListContactIDsPaginated is ListContactsPaginated returning only the IDs of the contacts
+// and the keys to page on from them
+func (s *contactService) ListContactIDsPaginated(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.ContactFilter) (_ []coreTypes.KeyedID, err error) {
+	defer s.operation("ListContactIDsPaginated", userID, uuid.Nil,
+		zap.Any("cursor", cursor),
+		zap.Any("cursor_id", cursorID),
+		zap.Int32("limit", limit),
+		zap.String("order", string(order)),
+		zap.Any("filter", filter)).End(&err)
+
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+
+	return s.repo.ListContactIDsPaginated(ctx, userID, cursor, cursorID, limit, order, filter)
+}
+
+// StreamContactsPaginated is ListContactsPaginated handing each contact to fn as it is read
+func (s *contactService) StreamContactsPaginated(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.ContactFilter, fn func(types.Contact) error) (err error) {
+	defer s.operation("StreamContactsPaginated", userID, uuid.Nil,
+		zap.Any("cursor", cursor),
+		zap.Any("cursor_id", cursorID),
+		zap.Int32("limit", limit),
+		zap.String("order", string(order)),
+		zap.Any("filter", filter)).End(&err)
+
+	if limit <= 0 {
+		return fmt.Errorf("limit must be positive")
+	}
+
+	return s.repo.ListContactsPaginatedStream(ctx, userID, cursor, cursorID, limit, order, filter, fn)
+}
+
+func (s *contactService) SearchContacts(ctx context.Context, userID uuid.UUID, name string, limit, offset int32) (_ []types.Contact, err error) {
+	defer s.operation("SearchContacts", userID, uuid.Nil,
 		zap.String("name", name),
-		zap.Int32("limit", limit))
+		zap.Int32("limit", limit),
+		zap.Int32("offset", offset)).End(&err)
 
 	if limit <= 0 {
 		return nil, fmt.Errorf("limit must be positive")
 	}
 
-	return s.repo.SearchContacts(ctx, userID, name, limit)
+	key := fmt.Sprintf("%s:contacts:name:%q:%d:%d", userID, name, limit, offset)
+	return cache.Coalesce(ctx, &s.searches, key, func(ctx context.Context) ([]types.Contact, error) {
+		return s.repo.SearchContacts(ctx, userID, name, limit, offset)
+	}, cloneContacts)
 }
 
-func (s *contactService) SearchContactsByPhone(ctx context.Context, userID uuid.UUID, phone string, limit int32) ([]types.Contact, error) {
-	s.logger.Info("searching contacts by phone",
-		zap.String("user_id", userID.String()),
+func (s *contactService) SearchContactsByPhone(ctx context.Context, userID uuid.UUID, phone string, limit, offset int32) (_ []types.Contact, err error) {
+	defer s.operation("SearchContactsByPhone", userID, uuid.Nil,
 		zap.String("phone", phone),
-		zap.Int32("limit", limit))
+		zap.Int32("limit", limit),
+		zap.Int32("offset", offset)).End(&err)
 
 	if limit <= 0 {
 		return nil, fmt.Errorf("limit must be positive")
 	}
 
 	// Clean the phone number query
-	cleanedPhone := cleanPhoneNumber(phone)
+	cleanedPhone := types.CleanPhoneNumber(phone)
+
+	return s.repo.SearchContactsByPhone(ctx, userID, cleanedPhone, limit, offset)
+}
+
+func (s *contactService) SearchContactsByCompany(ctx context.Context, userID uuid.UUID, company string, limit, offset int32) (_ []types.Contact, err error) {
+	defer s.operation("SearchContactsByCompany", userID, uuid.Nil,
+		zap.String("company", company),
+		zap.Int32("limit", limit),
+		zap.Int32("offset", offset)).End(&err)
+
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+
+	normalized := normalizeCompany(&company)
+	if normalized == nil {
+		return nil, fmt.Errorf("company is required")
+	}
+
+	key := fmt.Sprintf("%s:contacts:company:%q:%d:%d", userID, *normalized, limit, offset)
+	return cache.Coalesce(ctx, &s.searches, key, func(ctx context.Context) ([]types.Contact, error) {
+		return s.repo.SearchContactsByCompany(ctx, userID, *normalized, limit, offset)
+	}, cloneContacts)
+}
+
+// SearchContactsPicker is SearchContacts in the picker view
+func (s *contactService) SearchContactsPicker(ctx context.Context, userID uuid.UUID, name string, limit, offset int32) (_ []types.ContactPickerItem, err error) {
+	defer s.operation("SearchContactsPicker", userID, uuid.Nil,
+		zap.String("name", name),
+		zap.Int32("limit", limit),
+		zap.Int32("offset", offset)).End(&err)
+
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+
+	key := fmt.Sprintf("%s:contacts:picker:name:%q:%d:%d", userID, name, limit, offset)
+	return cache.Coalesce(ctx, &s.searches, key, func(ctx context.Context) ([]types.ContactPickerItem, error) {
+		return s.repo.SearchContactsPicker(ctx, userID, name, limit, offset)
+	}, slices.Clone)
+}
+
+// SearchContactsByPhonePicker is SearchContactsByPhone in the picker view
+func (s *contactService) SearchContactsByPhonePicker(ctx context.Context, userID uuid.UUID, phone string, limit, offset int32) (_ []types.ContactPickerItem, err error) {
+	defer s.operation("SearchContactsByPhonePicker", userID, uuid.Nil,
+		zap.String("phone", phone),
+		zap.Int32("limit", limit),
+		zap.Int32("offset", offset)).End(&err)
+
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+
+	return s.repo.SearchContactsByPhonePicker(ctx, userID, types.CleanPhoneNumber(phone), limit, offset)
+}
+
+// SearchContactsByCompanyPicker is SearchContactsByCompany in the picker view
+func (s *contactService) SearchContactsByCompanyPicker(ctx context.Context, userID uuid.UUID, company string, limit, offset int32) (_ []types.ContactPickerItem, err error) {
+	defer s.operation("SearchContactsByCompanyPicker", userID, uuid.Nil,
+		zap.String("company", company),
+		zap.Int32("limit", limit),
+		zap.Int32("offset", offset)).End(&err)
+
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+
+	normalized := normalizeCompany(&company)
+	if normalized == nil {
+		return nil, fmt.Errorf("company is required")
+	}
+
+	key := fmt.Sprintf("%s:contacts:picker:company:%q:%d:%d", userID, *normalized, limit, offset)
+	return cache.Coalesce(ctx, &s.searches, key, func(ctx context.Context) ([]types.ContactPickerItem, error) {
+		return s.repo.SearchContactsByCompanyPicker(ctx, userID, *normalized, limit, offset)
+	}, slices.Clone)
+}
+
+// cloneContacts copies the contacts of a coalesced search for one of its callers
+func cloneContacts(contacts []types.Contact) []types.Contact {
+	cloned := slices.Clone(contacts)
+	for i := range cloned {
+		cloned[i].Tags = slices.Clone(cloned[i].Tags)
+		cloned[i].Links = slices.Clone(cloned[i].Links)
+	}
+	return cloned
+}
+
+func (s *contactService) ListContactCompanies(ctx context.Context, userID uuid.UUID, params types.CompanyListParams) (_ []types.CompanyContacts, err error) {
+	defer s.operation("ListContactCompanies", userID, uuid.Nil,
+		zap.String("sort", params.SortBy),
+		zap.Int32("limit", params.Limit),
+		zap.Int32("contacts_limit", params.ContactsLimit)).End(&err)
+
+	if params.Limit <= 0 || params.ContactsLimit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+
+	return s.repo.ListContactCompanies(ctx, userID, params)
+}
+
+// ListCompanies lists the first limit companies by name with their contact counts. It
+// reads them through the grouped listing, only the counts of its groups are kept.
+func (s *contactService) ListCompanies(ctx context.Context, userID uuid.UUID, limit int32) (_ []types.CompanyCount, err error) {
+	defer s.operation("ListCompanies", userID, uuid.Nil, zap.Int32("limit", limit)).End(&err)
+
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+
+	groups, err := s.repo.ListContactCompanies(ctx, userID, types.CompanyListParams{
+		SortBy:        types.CompanySortByName,
+		Limit:         limit,
+		ContactsLimit: 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	companies := make([]types.CompanyCount, len(groups))
+	for i, group := range groups {
+		companies[i] = types.CompanyCount{Company: group.Company, ContactCount: group.ContactCount}
+	}
+	return companies, nil
+}
+
+func (s *contactService) ListFacets(ctx context.Context, userID uuid.UUID, field string) (_ []coreTypes.Facet, err error) {
+	defer s.operation("ListFacets", userID, uuid.Nil, zap.String("field", field)).End(&err)
 
-	return s.repo.SearchContactsByPhone(ctx, userID, cleanedPhone, limit)
+	return s.repo.ListFacets(ctx, userID, field)
 }
@@ -2,17 +2,33 @@ package service
 
 import (
 	"context"
+	"encoding/csv"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"net"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/blob"
+	coreErrors "github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/logging"
+	coreRepository "github.com/Abdelrahman-habib/expense-tracker/internal/core/repository"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	jobTypes "github.com/Abdelrahman-habib/expense-tracker/internal/jobs/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/validate"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 // Mock repository
@@ -28,6 +44,11 @@ func (m *mockContactRepository) GetContact(ctx context.Context, contactID, userI
 	return args.Get(0).(types.Contact), args.Error(1)
 }
 
+func (m *mockContactRepository) GetContactsByIDs(ctx context.Context, userID uuid.UUID, contactIDs []uuid.UUID) ([]types.Contact, []uuid.UUID, error) {
+	args := m.Called(ctx, userID, contactIDs)
+	return args.Get(0).([]types.Contact), args.Get(1).([]uuid.UUID), args.Error(2)
+}
+
 func (m *mockContactRepository) ListContacts(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]types.Contact, error) {
 	args := m.Called(ctx, userID, limit, offset)
 	return args.Get(0).([]types.Contact), args.Error(1)
@@ -41,6 +62,16 @@ func (m *mockContactRepository) CreateContact(ctx context.Context, payload types
 	return args.Get(0).(types.Contact), args.Error(1)
 }
 
+// UpdateContactLocked merges onto a contact holding only its IDs, the update is then
+// matched on the merged payload
+func (m *mockContactRepository) UpdateContactLocked(ctx context.Context, contactID, userID uuid.UUID, merge func(types.Contact) (types.ContactUpdatePayload, error)) (types.Contact, error) {
+	payload, err := merge(types.Contact{ContactID: contactID, UserID: userID})
+	if err != nil {
+		return types.Contact{}, err
+	}
+	return m.UpdateContact(ctx, payload, userID)
+}
+
 func (m *mockContactRepository) UpdateContact(ctx context.Context, payload types.ContactUpdatePayload, userID uuid.UUID) (types.Contact, error) {
 	args := m.Called(ctx, payload, userID)
 	if args.Get(0) == nil {
@@ -49,30 +80,143 @@ func (m *mockContactRepository) UpdateContact(ctx context.Context, payload types
 	return args.Get(0).(types.Contact), args.Error(1)
 }
 
-func (m *mockContactRepository) DeleteContact(ctx context.Context, contactID, userID uuid.UUID) error {
+func (m *mockContactRepository) UpsertContactByExternalRef(ctx context.Context, userID uuid.UUID, ref types.ExternalRef, payload types.ContactUpsertPayload) (types.Contact, bool, error) {
+	args := m.Called(ctx, userID, ref, payload)
+	if args.Get(0) == nil {
+		return types.Contact{}, false, args.Error(2)
+	}
+	return args.Get(0).(types.Contact), args.Bool(1), args.Error(2)
+}
+
+func (m *mockContactRepository) DeleteContact(ctx context.Context, contactID, userID uuid.UUID, version *time.Time) error {
+	args := m.Called(ctx, contactID, userID, version)
+	return args.Error(0)
+}
+
+func (m *mockContactRepository) ListDeletedContactsPaginated(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32, order coreTypes.SortOrder) ([]types.Contact, error) {
+	args := m.Called(ctx, userID, cursor, cursorID, limit, order)
+	return args.Get(0).([]types.Contact), args.Error(1)
+}
+
+func (m *mockContactRepository) RestoreContact(ctx context.Context, contactID, userID uuid.UUID) (types.Contact, error) {
 	args := m.Called(ctx, contactID, userID)
+	return args.Get(0).(types.Contact), args.Error(1)
+}
+
+func (m *mockContactRepository) ListContactsPaginated(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.ContactFilter) ([]types.Contact, error) {
+	args := m.Called(ctx, userID, cursor, cursorID, limit, order, filter)
+	return args.Get(0).([]types.Contact), args.Error(1)
+}
+
+func (m *mockContactRepository) ListContactIDsPaginated(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.ContactFilter) ([]coreTypes.KeyedID, error) {
+	args := m.Called(ctx, userID, cursor, cursorID, limit, order, filter)
+	return args.Get(0).([]coreTypes.KeyedID), args.Error(1)
+}
+
+func (m *mockContactRepository) CreateContactRelationship(ctx context.Context, contactID, userID uuid.UUID, payload types.ContactRelationshipPayload) (types.ContactRelationship, error) {
+	args := m.Called(ctx, contactID, userID, payload)
+	return args.Get(0).(types.ContactRelationship), args.Error(1)
+}
+
+func (m *mockContactRepository) ListContactRelationships(ctx context.Context, contactID, userID uuid.UUID) ([]types.ContactRelationship, error) {
+	args := m.Called(ctx, contactID, userID)
+	return args.Get(0).([]types.ContactRelationship), args.Error(1)
+}
+
+func (m *mockContactRepository) DeleteContactRelationship(ctx context.Context, contactID, relationshipID, userID uuid.UUID) error {
+	args := m.Called(ctx, contactID, relationshipID, userID)
 	return args.Error(0)
 }
 
-func (m *mockContactRepository) ListContactsPaginated(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32) ([]types.Contact, error) {
-	args := m.Called(ctx, userID, cursor, cursorID, limit)
+func (m *mockContactRepository) CountContacts(ctx context.Context, userID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockContactRepository) SearchContacts(ctx context.Context, userID uuid.UUID, name string, limit, offset int32) ([]types.Contact, error) {
+	args := m.Called(ctx, userID, name, limit, offset)
 	return args.Get(0).([]types.Contact), args.Error(1)
 }
 
-func (m *mockContactRepository) SearchContacts(ctx context.Context, userID uuid.UUID, name string, limit int32) ([]types.Contact, error) {
+func (m *mockContactRepository) ListContactsPaginatedStream(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.ContactFilter, fn func(types.Contact) error) error {
+	args := m.Called(ctx, userID, cursor, cursorID, limit, order, filter)
+	for _, contact := range args.Get(0).([]types.Contact) {
+		if err := fn(contact); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *mockContactRepository) SearchContactsStream(ctx context.Context, userID uuid.UUID, name string, limit int32, fn func(types.Contact) error) error {
 	args := m.Called(ctx, userID, name, limit)
+	for _, contact := range args.Get(0).([]types.Contact) {
+		if err := fn(contact); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *mockContactRepository) SearchContactsByPhone(ctx context.Context, userID uuid.UUID, phone string, limit, offset int32) ([]types.Contact, error) {
+	args := m.Called(ctx, userID, phone, limit, offset)
 	return args.Get(0).([]types.Contact), args.Error(1)
 }
 
-func (m *mockContactRepository) SearchContactsByPhone(ctx context.Context, userID uuid.UUID, phone string, limit int32) ([]types.Contact, error) {
-	args := m.Called(ctx, userID, phone, limit)
+func (m *mockContactRepository) SearchContactsByCompany(ctx context.Context, userID uuid.UUID, company string, limit, offset int32) ([]types.Contact, error) {
+	args := m.Called(ctx, userID, company, limit, offset)
 	return args.Get(0).([]types.Contact), args.Error(1)
 }
 
+func (m *mockContactRepository) SearchContactsPicker(ctx context.Context, userID uuid.UUID, name string, limit, offset int32) ([]types.ContactPickerItem, error) {
+	args := m.Called(ctx, userID, name, limit, offset)
+	return args.Get(0).([]types.ContactPickerItem), args.Error(1)
+}
+
+func (m *mockContactRepository) SearchContactsByPhonePicker(ctx context.Context, userID uuid.UUID, phone string, limit, offset int32) ([]types.ContactPickerItem, error) {
+	args := m.Called(ctx, userID, phone, limit, offset)
+	return args.Get(0).([]types.ContactPickerItem), args.Error(1)
+}
+
+func (m *mockContactRepository) SearchContactsByCompanyPicker(ctx context.Context, userID uuid.UUID, company string, limit, offset int32) ([]types.ContactPickerItem, error) {
+	args := m.Called(ctx, userID, company, limit, offset)
+	return args.Get(0).([]types.ContactPickerItem), args.Error(1)
+}
+
+func (m *mockContactRepository) ListContactCompanies(ctx context.Context, userID uuid.UUID, params types.CompanyListParams) ([]types.CompanyContacts, error) {
+	args := m.Called(ctx, userID, params)
+	return args.Get(0).([]types.CompanyContacts), args.Error(1)
+}
+
+func (m *mockContactRepository) ListFacets(ctx context.Context, userID uuid.UUID, field string) ([]coreTypes.Facet, error) {
+	args := m.Called(ctx, userID, field)
+	return args.Get(0).([]coreTypes.Facet), args.Error(1)
+}
+
+func (m *mockContactRepository) ListOwnedTagIDs(ctx context.Context, userID uuid.UUID, tagIDs []uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(ctx, userID, tagIDs)
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+// Mock job enqueuer
+type mockJobEnqueuer struct {
+	mock.Mock
+}
+
+func (m *mockJobEnqueuer) Enqueue(ctx context.Context, userID uuid.UUID, jobType jobTypes.JobType, total int, payload any) (jobTypes.Job, error) {
+	args := m.Called(ctx, userID, jobType, total, payload)
+	return args.Get(0).(jobTypes.Job), args.Error(1)
+}
+
+func (m *mockJobEnqueuer) GetJob(ctx context.Context, jobID, userID uuid.UUID) (jobTypes.Job, error) {
+	args := m.Called(ctx, jobID, userID)
+	return args.Get(0).(jobTypes.Job), args.Error(1)
+}
+
 func setupTest(t *testing.T) (*mockContactRepository, ContactService) {
 	mockRepo := new(mockContactRepository)
 	logger := zap.NewNop()
-	service := NewContactService(mockRepo, logger)
+	service := NewContactService(mockRepo, new(mockJobEnqueuer), nil, types.ExportPolicy{}, nil, nil, nil, logger)
 	return mockRepo, service
 }
 
@@ -166,13 +310,83 @@ func TestContactService_CreateContact(t *testing.T) {
 
 			// If phone was provided, verify it was cleaned
 			if tt.payload.Phone != nil {
-				cleaned := cleanPhoneNumber(*tt.payload.Phone)
+				cleaned := types.CleanPhoneNumber(*tt.payload.Phone)
 				assert.Equal(t, cleaned, *contact.Phone)
 			}
 		})
 	}
 }
 
+func TestContactService_NormalizesEmail(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	tests := []struct {
+		name       string
+		email      string
+		expected   *string
+		errMsg     string
+		suggestion string
+	}{
+		{name: "trims and lower cases", email: "  John.Doe@Example.COM ", expected: utils.StringPtr("john.doe@example.com")},
+		{name: "blank email is unset", email: "  ", expected: nil},
+		{name: "refuses local domains", email: "john@localhost", errMsg: "email: must have a domain like example.com"},
+		{name: "suggests the domain of a typo", email: "John@Gamil.com", suggestion: "john@gmail.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo, service := setupTest(t)
+			if tt.errMsg == "" && tt.suggestion == "" {
+				mockRepo.On("CreateContact", ctx, mock.MatchedBy(func(p types.ContactCreatePayload) bool {
+					return assert.ObjectsAreEqual(tt.expected, p.Email)
+				}), userID).Return(types.Contact{Name: "John Doe"}, nil)
+			}
+
+			_, err := service.CreateContact(ctx, types.ContactCreatePayload{Name: "John Doe", Email: &tt.email}, userID)
+			switch {
+			case tt.suggestion != "":
+				var suspect *coreErrors.ErrorResponse
+				require.ErrorAs(t, err, &suspect)
+				assert.Equal(t, coreErrors.ErrorTypeEmailSuspect, suspect.Type)
+				assert.Equal(t, tt.suggestion, suspect.Suggestion)
+			case tt.errMsg != "":
+				assert.True(t, coreErrors.IsErrorType(err, coreErrors.ErrorTypeValidation))
+				assert.EqualError(t, err, tt.errMsg)
+			default:
+				assert.NoError(t, err)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+
+	t.Run("refuses domains without a mail server", func(t *testing.T) {
+		resolver := &fakeMXResolver{domains: map[string]bool{"example.com": true}}
+		emails := validate.NewEmailChecker(nil, resolver, time.Second)
+		mockRepo := new(mockContactRepository)
+		service := NewContactService(mockRepo, new(mockJobEnqueuer), nil, types.ExportPolicy{}, emails, nil, nil, zap.NewNop())
+		mockRepo.On("CreateContact", ctx, mock.Anything, userID).Return(types.Contact{Name: "John Doe"}, nil)
+
+		_, err := service.CreateContact(ctx, types.ContactCreatePayload{Name: "John Doe", Email: utils.StringPtr("john@example.com")}, userID)
+		assert.NoError(t, err)
+		_, err = service.CreateContact(ctx, types.ContactCreatePayload{Name: "John Doe", Email: utils.StringPtr("john@nomail.com")}, userID)
+		assert.EqualError(t, err, "email: has a domain that doesn't receive email")
+		mockRepo.AssertNumberOfCalls(t, "CreateContact", 1)
+	})
+}
+
+// fakeMXResolver has a mail server for the domains set in domains
+type fakeMXResolver struct {
+	domains map[string]bool
+}
+
+func (f *fakeMXResolver) LookupMX(ctx context.Context, name string) ([]*net.MX, error) {
+	if !f.domains[name] {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return []*net.MX{{Host: "mx." + name + ".", Pref: 10}}, nil
+}
+
 func TestContactService_GetContact(t *testing.T) {
 	mockRepo, service := setupTest(t)
 	ctx := context.Background()
@@ -210,7 +424,7 @@ func TestContactService_GetContact(t *testing.T) {
 			mockRepo.ExpectedCalls = nil
 			tt.mock()
 
-			contact, err := service.GetContact(ctx, contactID, userID)
+			contact, err := service.GetContact(ctx, contactID, userID, types.ContactExpand{})
 			if tt.wantErr {
 				assert.Error(t, err)
 				return
@@ -223,6 +437,62 @@ func TestContactService_GetContact(t *testing.T) {
 	}
 }
 
+func TestContactService_GetContact_Relationships(t *testing.T) {
+	mockRepo, service := setupTest(t)
+	ctx := context.Background()
+	userID := uuid.New()
+	contactID := uuid.New()
+
+	relationships := []types.ContactRelationship{
+		{RelationshipID: uuid.New(), Type: types.RelationshipWorksFor, Outgoing: true, Contact: types.RelatedContact{ContactID: uuid.New(), Name: "Acme Supplies"}},
+		{RelationshipID: uuid.New(), Type: types.RelationshipSpouseOf, Contact: types.RelatedContact{ContactID: uuid.New(), Name: "Jane Doe"}},
+	}
+	mockRepo.On("GetContact", ctx, contactID, userID).Return(types.Contact{ContactID: contactID, Name: "John Doe"}, nil)
+	mockRepo.On("ListContactRelationships", ctx, contactID, userID).Return(relationships, nil).Once()
+
+	contact, err := service.GetContact(ctx, contactID, userID, types.ContactExpand{Relationships: true})
+	require.NoError(t, err)
+	assert.Equal(t, relationships, contact.Relationships)
+
+	contact, err = service.GetContact(ctx, contactID, userID, types.ContactExpand{})
+	require.NoError(t, err)
+	assert.Nil(t, contact.Relationships)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestContactService_ContactRelationships(t *testing.T) {
+	mockRepo, service := setupTest(t)
+	ctx := context.Background()
+	userID := uuid.New()
+	contactID := uuid.New()
+
+	t.Run("create", func(t *testing.T) {
+		payload := types.ContactRelationshipPayload{ContactID: uuid.New(), Type: types.RelationshipWorksFor}
+		expected := types.ContactRelationship{RelationshipID: uuid.New(), Type: payload.Type, Outgoing: true, Contact: types.RelatedContact{ContactID: payload.ContactID, Name: "Acme Supplies"}}
+		mockRepo.On("CreateContactRelationship", ctx, contactID, userID, payload).Return(expected, nil).Once()
+
+		relationship, err := service.CreateContactRelationship(ctx, contactID, userID, payload)
+		require.NoError(t, err)
+		assert.Equal(t, expected, relationship)
+	})
+
+	t.Run("self relationships are rejected", func(t *testing.T) {
+		payload := types.ContactRelationshipPayload{ContactID: contactID, Type: types.RelationshipReportsTo}
+
+		_, err := service.CreateContactRelationship(ctx, contactID, userID, payload)
+		assert.True(t, coreErrors.IsErrorType(err, coreErrors.ErrorTypeValidation))
+		mockRepo.AssertNotCalled(t, "CreateContactRelationship", ctx, contactID, userID, payload)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		relationshipID := uuid.New()
+		mockRepo.On("DeleteContactRelationship", ctx, contactID, relationshipID, userID).Return(nil).Once()
+		assert.NoError(t, service.DeleteContactRelationship(ctx, contactID, relationshipID, userID))
+	})
+
+	mockRepo.AssertExpectations(t)
+}
+
 func TestContactService_ListContacts(t *testing.T) {
 	mockRepo, service := setupTest(t)
 	ctx := context.Background()
@@ -358,7 +628,9 @@ func TestContactService_UpdateContact(t *testing.T) {
 			mockRepo.ExpectedCalls = nil
 			tt.mock()
 
-			contact, err := service.UpdateContact(ctx, tt.payload, userID)
+			contact, err := service.UpdateContact(ctx, tt.payload.ContactID, userID, func(types.Contact) (types.ContactUpdatePayload, error) {
+				return tt.payload, nil
+			})
 			if tt.wantErr {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), tt.errMsg)
@@ -371,13 +643,89 @@ func TestContactService_UpdateContact(t *testing.T) {
 
 			// If phone was provided, verify it was cleaned
 			if tt.payload.Phone != nil {
-				cleaned := cleanPhoneNumber(*tt.payload.Phone)
+				cleaned := types.CleanPhoneNumber(*tt.payload.Phone)
 				assert.Equal(t, cleaned, *contact.Phone)
 			}
 		})
 	}
 }
 
+func TestContactService_UpsertContactByExternalRef(t *testing.T) {
+	mockRepo, service := setupTest(t)
+	ctx := context.Background()
+	userID := uuid.New()
+	ref := types.ExternalRef{Source: "hubspot", ExternalID: "5012"}
+	tag := uuid.New()
+
+	tests := []struct {
+		name    string
+		payload types.ContactUpsertPayload
+		mock    func()
+		created bool
+		errMsg  string
+	}{
+		{
+			name: "cleans the phone, company and place",
+			payload: types.ContactUpsertPayload{
+				Name:          utils.StringPtr("John Doe"),
+				Phone:         utils.StringPtr("+1-555-123-4567"),
+				Company:       utils.StringPtr("  Acme   Inc. "),
+				City:          utils.StringPtr(" New  York "),
+				StateProvince: utils.StringPtr("  "),
+			},
+			mock: func() {
+				mockRepo.On("UpsertContactByExternalRef", ctx, userID, ref, types.ContactUpsertPayload{
+					Name:    utils.StringPtr("John Doe"),
+					Phone:   utils.StringPtr("15551234567"),
+					Company: utils.StringPtr("Acme Inc."),
+					City:    utils.StringPtr("New York"),
+				}).Return(types.Contact{Name: "John Doe", ExternalRef: &ref}, true, nil)
+			},
+			created: true,
+		},
+		{
+			name:    "updates without a name",
+			payload: types.ContactUpsertPayload{Tags: []uuid.UUID{tag}},
+			mock: func() {
+				mockRepo.On("UpsertContactByExternalRef", ctx, userID, ref, types.ContactUpsertPayload{Tags: []uuid.UUID{tag}}).
+					Return(types.Contact{Name: "John Doe", ExternalRef: &ref}, false, nil)
+			},
+		},
+		{
+			name:    "empty name",
+			payload: types.ContactUpsertPayload{Name: utils.StringPtr("")},
+			mock:    func() {},
+			errMsg:  "contact name is required",
+		},
+		{
+			name:    "duplicate tags",
+			payload: types.ContactUpsertPayload{Tags: []uuid.UUID{tag, tag}},
+			mock:    func() {},
+			errMsg:  "duplicate tag found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.ExpectedCalls = nil
+			mockRepo.Calls = nil
+			tt.mock()
+
+			contact, created, err := service.UpsertContactByExternalRef(ctx, userID, ref, tt.payload)
+			if tt.errMsg != "" {
+				assert.ErrorContains(t, err, tt.errMsg)
+				mockRepo.AssertNotCalled(t, "UpsertContactByExternalRef", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.created, created)
+			assert.Equal(t, &ref, contact.ExternalRef)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
 func TestContactService_DeleteContact(t *testing.T) {
 	mockRepo, service := setupTest(t)
 	ctx := context.Background()
@@ -392,14 +740,14 @@ func TestContactService_DeleteContact(t *testing.T) {
 		{
 			name: "successful delete",
 			mock: func() {
-				mockRepo.On("DeleteContact", ctx, contactID, userID).Return(nil)
+				mockRepo.On("DeleteContact", ctx, contactID, userID, (*time.Time)(nil)).Return(nil)
 			},
 			wantErr: false,
 		},
 		{
 			name: "not found error",
 			mock: func() {
-				mockRepo.On("DeleteContact", ctx, contactID, userID).Return(errors.New("not found"))
+				mockRepo.On("DeleteContact", ctx, contactID, userID, (*time.Time)(nil)).Return(fmt.Errorf("delete contact: %w", coreRepository.ErrNotFound))
 			},
 			wantErr: true,
 		},
@@ -410,9 +758,9 @@ func TestContactService_DeleteContact(t *testing.T) {
 			mockRepo.ExpectedCalls = nil
 			tt.mock()
 
-			err := service.DeleteContact(ctx, contactID, userID)
+			err := service.DeleteContact(ctx, contactID, userID, nil)
 			if tt.wantErr {
-				assert.Error(t, err)
+				assert.ErrorIs(t, err, coreRepository.ErrNotFound)
 				return
 			}
 
@@ -449,15 +797,15 @@ func TestContactService_ListContactsPaginated(t *testing.T) {
 					{
 						ContactID: uuid.New(),
 						Name:      "John Doe",
-						CreatedAt: now.Add(-1 * time.Hour),
+						CreatedAt: coreTypes.NewTimestamp(now.Add(-1 * time.Hour)),
 					},
 					{
 						ContactID: uuid.New(),
 						Name:      "Jane Smith",
-						CreatedAt: now.Add(-2 * time.Hour),
+						CreatedAt: coreTypes.NewTimestamp(now.Add(-2 * time.Hour)),
 					},
 				}
-				mockRepo.On("ListContactsPaginated", ctx, userID, &now, &cursorID, int32(10)).
+				mockRepo.On("ListContactsPaginated", ctx, userID, &now, &cursorID, int32(10), coreTypes.SortOrderDesc, types.ContactFilter{}).
 					Return(contacts, nil)
 			},
 			wantErr: false,
@@ -478,7 +826,7 @@ func TestContactService_ListContactsPaginated(t *testing.T) {
 			cursorID: &cursorID,
 			limit:    10,
 			mock: func() {
-				mockRepo.On("ListContactsPaginated", ctx, userID, &now, &cursorID, int32(10)).
+				mockRepo.On("ListContactsPaginated", ctx, userID, &now, &cursorID, int32(10), coreTypes.SortOrderDesc, types.ContactFilter{}).
 					Return([]types.Contact{}, errors.New("database error"))
 			},
 			wantErr: true,
@@ -491,7 +839,7 @@ func TestContactService_ListContactsPaginated(t *testing.T) {
 			mockRepo.ExpectedCalls = nil
 			tt.mock()
 
-			contacts, err := service.ListContactsPaginated(ctx, userID, tt.cursor, tt.cursorID, tt.limit)
+			contacts, err := service.ListContactsPaginated(ctx, userID, tt.cursor, tt.cursorID, tt.limit, coreTypes.SortOrderDesc, types.ContactFilter{})
 			if tt.wantErr {
 				assert.Error(t, err)
 				if tt.errMsg != "" {
@@ -536,7 +884,7 @@ func TestContactService_SearchContacts(t *testing.T) {
 						Name:      "Johnny Smith",
 					},
 				}
-				mockRepo.On("SearchContacts", ctx, userID, "John", int32(10)).Return(contacts, nil)
+				mockRepo.On("SearchContacts", ctx, userID, "John", int32(10), int32(0)).Return(contacts, nil)
 			},
 			wantErr: false,
 			wantLen: 2,
@@ -554,7 +902,7 @@ func TestContactService_SearchContacts(t *testing.T) {
 			query: "XYZ",
 			limit: 10,
 			mock: func() {
-				mockRepo.On("SearchContacts", ctx, userID, "XYZ", int32(10)).Return([]types.Contact{}, nil)
+				mockRepo.On("SearchContacts", ctx, userID, "XYZ", int32(10), int32(0)).Return([]types.Contact{}, nil)
 			},
 			wantErr: false,
 			wantLen: 0,
@@ -566,7 +914,7 @@ func TestContactService_SearchContacts(t *testing.T) {
 			mockRepo.ExpectedCalls = nil
 			tt.mock()
 
-			contacts, err := service.SearchContacts(ctx, userID, tt.query, tt.limit)
+			contacts, err := service.SearchContacts(ctx, userID, tt.query, tt.limit, 0)
 			if tt.wantErr {
 				assert.Error(t, err)
 				if tt.errMsg != "" {
@@ -582,6 +930,33 @@ func TestContactService_SearchContacts(t *testing.T) {
 	}
 }
 
+func TestContactService_SearchContactsStopsAtTheDeadline(t *testing.T) {
+	mockRepo, service := setupTest(t)
+	userID := uuid.New()
+
+	stopped := make(chan error, 1)
+	mockRepo.On("SearchContacts", mock.Anything, userID, "jo%", int32(10), int32(0)).
+		Run(func(args mock.Arguments) {
+			// a pathological query runs until the driver cancels it
+			ctx := args.Get(0).(context.Context)
+			<-ctx.Done()
+			stopped <- ctx.Err()
+		}).
+		Return([]types.Contact(nil), context.DeadlineExceeded)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := service.SearchContacts(ctx, userID, "jo%", 10, 0)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	select {
+	case err := <-stopped:
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(time.Second):
+		t.Fatal("the search query outlived the search timeout")
+	}
+}
+
 func TestContactService_SearchContactsByPhone(t *testing.T) {
 	mockRepo, service := setupTest(t)
 	ctx := context.Background()
@@ -609,7 +984,7 @@ func TestContactService_SearchContactsByPhone(t *testing.T) {
 					},
 				}
 				// Verify that cleaned phone number is passed to repository
-				mockRepo.On("SearchContactsByPhone", ctx, userID, "15551234567", int32(10)).Return(contacts, nil)
+				mockRepo.On("SearchContactsByPhone", ctx, userID, "15551234567", int32(10), int32(0)).Return(contacts, nil)
 			},
 			wantErr: false,
 			wantLen: 1,
@@ -627,7 +1002,7 @@ func TestContactService_SearchContactsByPhone(t *testing.T) {
 			query: "15551234567",
 			limit: 10,
 			mock: func() {
-				mockRepo.On("SearchContactsByPhone", ctx, userID, "15551234567", int32(10)).
+				mockRepo.On("SearchContactsByPhone", ctx, userID, "15551234567", int32(10), int32(0)).
 					Return([]types.Contact{}, errors.New("database error"))
 			},
 			wantErr: true,
@@ -640,7 +1015,186 @@ func TestContactService_SearchContactsByPhone(t *testing.T) {
 			mockRepo.ExpectedCalls = nil
 			tt.mock()
 
-			contacts, err := service.SearchContactsByPhone(ctx, userID, tt.query, tt.limit)
+			contacts, err := service.SearchContactsByPhone(ctx, userID, tt.query, tt.limit, 0)
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.errMsg != "" {
+					assert.Contains(t, err.Error(), tt.errMsg)
+				}
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Len(t, contacts, tt.wantLen)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestContactService_CreateContactNormalizesCompany(t *testing.T) {
+	mockRepo, service := setupTest(t)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	tests := []struct {
+		name        string
+		company     *string
+		wantCompany *string
+	}{
+		{
+			name:        "trims and collapses whitespace",
+			company:     utils.StringPtr("  Acme    Inc.  "),
+			wantCompany: utils.StringPtr("Acme Inc."),
+		},
+		{
+			name:        "blank company is unset",
+			company:     utils.StringPtr("   "),
+			wantCompany: nil,
+		},
+		{
+			name:        "missing company",
+			company:     nil,
+			wantCompany: nil,
+		},
+		{
+			name:        "non-ASCII company at the limit counts characters",
+			company:     utils.StringPtr(strings.Repeat("é", types.MaxCompanyLength)),
+			wantCompany: utils.StringPtr(strings.Repeat("é", types.MaxCompanyLength)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.ExpectedCalls = nil
+			mockRepo.On("CreateContact", ctx, mock.MatchedBy(func(p types.ContactCreatePayload) bool {
+				if tt.wantCompany == nil {
+					return p.Company == nil
+				}
+				return p.Company != nil && *p.Company == *tt.wantCompany
+			}), userID).Return(types.Contact{Name: "John Doe", Company: tt.wantCompany}, nil)
+
+			contact, err := service.CreateContact(ctx, types.ContactCreatePayload{
+				Name:    "John Doe",
+				Company: tt.company,
+			}, userID)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCompany, contact.Company)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestContactService_CreateUnnamedContact(t *testing.T) {
+	mockRepo, service := setupTest(t)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	tests := []struct {
+		name     string
+		payload  types.ContactCreatePayload
+		wantName string
+		errMsg   string
+	}{
+		{
+			name:     "named after the cleaned phone",
+			payload:  types.ContactCreatePayload{Phone: utils.StringPtr("+1 (555) 123-4567"), AllowUnnamed: true},
+			wantName: "Unnamed contact 15551234567",
+		},
+		{
+			name:     "a given name is kept",
+			payload:  types.ContactCreatePayload{Name: "John Doe", Phone: utils.StringPtr("+1-555-123-4567"), AllowUnnamed: true},
+			wantName: "John Doe",
+		},
+		{
+			name:    "phone is required",
+			payload: types.ContactCreatePayload{AllowUnnamed: true},
+			errMsg:  "phone: is required for a contact without a name",
+		},
+		{
+			name:    "phone without digits",
+			payload: types.ContactCreatePayload{Phone: utils.StringPtr("+ - ()"), AllowUnnamed: true},
+			errMsg:  "phone: must be a phone number to name the contact after",
+		},
+		{
+			name:    "name is required without the flag",
+			payload: types.ContactCreatePayload{Phone: utils.StringPtr("+1-555-123-4567")},
+			errMsg:  "contact name is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.ExpectedCalls = nil
+			if tt.errMsg == "" {
+				mockRepo.On("CreateContact", ctx, mock.MatchedBy(func(p types.ContactCreatePayload) bool {
+					return p.Name == tt.wantName
+				}), userID).Return(types.Contact{Name: tt.wantName}, nil)
+			}
+
+			contact, err := service.CreateContact(ctx, tt.payload, userID)
+			if tt.errMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantName, contact.Name)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestContactService_SearchContactsByCompany(t *testing.T) {
+	mockRepo, service := setupTest(t)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	tests := []struct {
+		name    string
+		company string
+		limit   int32
+		mock    func()
+		wantErr bool
+		wantLen int
+		errMsg  string
+	}{
+		{
+			name:    "successful search with normalization",
+			company: "  Acme   Inc ",
+			limit:   10,
+			mock: func() {
+				contacts := []types.Contact{
+					{ContactID: uuid.New(), Name: "John Doe", Company: utils.StringPtr("Acme Inc")},
+				}
+				mockRepo.On("SearchContactsByCompany", ctx, userID, "Acme Inc", int32(10), int32(0)).Return(contacts, nil)
+			},
+			wantLen: 1,
+		},
+		{
+			name:    "blank company",
+			company: "   ",
+			limit:   10,
+			mock:    func() {},
+			wantErr: true,
+			errMsg:  "company is required",
+		},
+		{
+			name:    "invalid limit",
+			company: "Acme",
+			limit:   0,
+			mock:    func() {},
+			wantErr: true,
+			errMsg:  "limit must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.ExpectedCalls = nil
+			tt.mock()
+
+			contacts, err := service.SearchContactsByCompany(ctx, userID, tt.company, tt.limit, 0)
 			if tt.wantErr {
 				assert.Error(t, err)
 				if tt.errMsg != "" {
@@ -655,3 +1209,573 @@ func TestContactService_SearchContactsByPhone(t *testing.T) {
 		})
 	}
 }
+
+func TestContactService_ListContactCompanies(t *testing.T) {
+	mockRepo, service := setupTest(t)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	tests := []struct {
+		name    string
+		params  types.CompanyListParams
+		mock    func(params types.CompanyListParams)
+		wantErr bool
+		wantLen int
+	}{
+		{
+			name:   "successful list",
+			params: types.CompanyListParams{SortBy: types.CompanySortByCount, Limit: 20, ContactsLimit: 3},
+			mock: func(params types.CompanyListParams) {
+				companies := []types.CompanyContacts{
+					{Company: "Acme", ContactCount: 2, Contacts: []types.ContactSummary{{Name: "A"}, {Name: "B"}}},
+				}
+				mockRepo.On("ListContactCompanies", ctx, userID, params).Return(companies, nil)
+			},
+			wantLen: 1,
+		},
+		{
+			name:    "invalid contacts limit",
+			params:  types.CompanyListParams{SortBy: types.CompanySortByName, Limit: 20, ContactsLimit: 0},
+			mock:    func(params types.CompanyListParams) {},
+			wantErr: true,
+		},
+		{
+			name:   "repository error",
+			params: types.CompanyListParams{SortBy: types.CompanySortByName, Limit: 20, ContactsLimit: 3},
+			mock: func(params types.CompanyListParams) {
+				mockRepo.On("ListContactCompanies", ctx, userID, params).
+					Return([]types.CompanyContacts{}, errors.New("database error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.ExpectedCalls = nil
+			tt.mock(tt.params)
+
+			companies, err := service.ListContactCompanies(ctx, userID, tt.params)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Len(t, companies, tt.wantLen)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestContactService_ListCompanies(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("reads the counts of the groups by name", func(t *testing.T) {
+		mockRepo, service := setupTest(t)
+		params := types.CompanyListParams{SortBy: types.CompanySortByName, Limit: 20, ContactsLimit: 1}
+		mockRepo.On("ListContactCompanies", ctx, userID, params).Return([]types.CompanyContacts{
+			{Company: "Acme", ContactCount: 2, Contacts: []types.ContactSummary{{Name: "A"}}},
+			{Company: "Globex", ContactCount: 1, Contacts: []types.ContactSummary{{Name: "B"}}},
+		}, nil)
+
+		companies, err := service.ListCompanies(ctx, userID, 20)
+		require.NoError(t, err)
+		assert.Equal(t, []types.CompanyCount{
+			{Company: "Acme", ContactCount: 2},
+			{Company: "Globex", ContactCount: 1},
+		}, companies)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		mockRepo, service := setupTest(t)
+		_, err := service.ListCompanies(ctx, userID, 0)
+		assert.Error(t, err)
+		mockRepo.AssertNotCalled(t, "ListContactCompanies", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestContactService_ImportContacts(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	contacts := []types.ContactCreatePayload{{Name: "Jane Doe"}, {Name: "John Doe"}}
+
+	tests := []struct {
+		name     string
+		contacts []types.ContactCreatePayload
+		mock     func(m *mockJobEnqueuer)
+		wantErr  bool
+		errMsg   string
+	}{
+		{
+			name:     "enqueues import job",
+			contacts: contacts,
+			mock: func(m *mockJobEnqueuer) {
+				m.On("Enqueue", ctx, userID, jobTypes.JobTypeContactImport, 2, contacts).
+					Return(jobTypes.Job{JobID: uuid.New(), Status: jobTypes.JobStatusPending, Total: 2}, nil)
+			},
+		},
+		{
+			name:     "no contacts",
+			contacts: []types.ContactCreatePayload{},
+			mock:     func(m *mockJobEnqueuer) {},
+			wantErr:  true,
+			errMsg:   "no contacts to import",
+		},
+		{
+			name:     "too many contacts",
+			contacts: make([]types.ContactCreatePayload, types.MaxImportContacts+1),
+			mock:     func(m *mockJobEnqueuer) {},
+			wantErr:  true,
+			errMsg:   "exceeds maximum",
+		},
+		{
+			name:     "enqueue error",
+			contacts: contacts,
+			mock: func(m *mockJobEnqueuer) {
+				m.On("Enqueue", ctx, userID, jobTypes.JobTypeContactImport, 2, contacts).
+					Return(jobTypes.Job{}, errors.New("database error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobs := new(mockJobEnqueuer)
+			service := NewContactService(new(mockContactRepository), jobs, nil, types.ExportPolicy{}, nil, nil, nil, zap.NewNop())
+			tt.mock(jobs)
+
+			job, err := service.ImportContacts(ctx, userID, tt.contacts)
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.errMsg != "" {
+					assert.Contains(t, err.Error(), tt.errMsg)
+				}
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, 2, job.Total)
+			jobs.AssertExpectations(t)
+		})
+	}
+}
+
+func TestContactService_ValidateContacts(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	ownedTag := uuid.New()
+	foreignTag := uuid.New()
+
+	t.Run("reports each row", func(t *testing.T) {
+		repo := new(mockContactRepository)
+		service := NewContactService(repo, new(mockJobEnqueuer), nil, types.ExportPolicy{}, nil, nil, nil, zap.NewNop())
+		repo.On("ListOwnedTagIDs", ctx, userID, []uuid.UUID{ownedTag, foreignTag}).Return([]uuid.UUID{ownedTag}, nil)
+
+		result, err := service.ValidateContacts(ctx, userID, []types.ContactCreatePayload{
+			{Name: "Jane Doe", Tags: []uuid.UUID{ownedTag}},
+			{Name: ""},
+			{Name: "John Doe", Tags: []uuid.UUID{ownedTag, foreignTag}},
+			{Name: "Acme Rep", Company: utils.StringPtr(strings.Repeat("a", types.MaxCompanyLength+1))},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.Valid)
+		assert.Equal(t, 3, result.Invalid)
+		assert.Len(t, result.Results, 4)
+		assert.Equal(t, types.ContactValidationResult{Index: 0, Valid: true}, result.Results[0])
+		assert.Contains(t, result.Results[1].Error, "name")
+		assert.Equal(t, "tag "+foreignTag.String()+" not owned by user", result.Results[2].Error)
+		assert.Contains(t, result.Results[3].Error, "company")
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("batch without tags", func(t *testing.T) {
+		repo := new(mockContactRepository)
+		service := NewContactService(repo, new(mockJobEnqueuer), nil, types.ExportPolicy{}, nil, nil, nil, zap.NewNop())
+		repo.On("ListOwnedTagIDs", ctx, userID, []uuid.UUID(nil)).Return([]uuid.UUID{}, nil)
+
+		result, err := service.ValidateContacts(ctx, userID, []types.ContactCreatePayload{{Name: "Jane Doe"}})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.Valid)
+		assert.Equal(t, 0, result.Invalid)
+	})
+
+	t.Run("no contacts", func(t *testing.T) {
+		service := NewContactService(new(mockContactRepository), new(mockJobEnqueuer), nil, types.ExportPolicy{}, nil, nil, nil, zap.NewNop())
+
+		_, err := service.ValidateContacts(ctx, userID, nil)
+		assert.EqualError(t, err, "no contacts to validate")
+	})
+
+	t.Run("tag lookup error", func(t *testing.T) {
+		repo := new(mockContactRepository)
+		service := NewContactService(repo, new(mockJobEnqueuer), nil, types.ExportPolicy{}, nil, nil, nil, zap.NewNop())
+		repo.On("ListOwnedTagIDs", ctx, userID, []uuid.UUID{ownedTag}).Return([]uuid.UUID{}, errors.New("database error"))
+
+		_, err := service.ValidateContacts(ctx, userID, []types.ContactCreatePayload{{Name: "Jane Doe", Tags: []uuid.UUID{ownedTag}}})
+		assert.Error(t, err)
+	})
+}
+
+func TestImportProcessor(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("invalid payload", func(t *testing.T) {
+		_, _, err := ImportProcessor(nil, nil)(ctx, jobTypes.Job{Payload: []byte("{")})
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid rows fail on their own", func(t *testing.T) {
+		payload, err := json.Marshal([]types.ContactCreatePayload{
+			{Name: ""},
+			{Name: "Jane Doe", Email: utils.StringPtr("not-an-email")},
+			{Name: "John Doe", Tags: []uuid.UUID{uuid.Nil, uuid.Nil}},
+		})
+		assert.NoError(t, err)
+
+		total, row, err := ImportProcessor(nil, nil)(ctx, jobTypes.Job{UserID: uuid.New(), Payload: payload})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, total)
+
+		// rows failing validation never reach the database, so no queries are needed
+		for i := 0; i < total; i++ {
+			assert.Error(t, row(ctx, nil, i), "row %d", i)
+		}
+	})
+}
+
+func TestContactService_ExportContacts(t *testing.T) {
+	mockRepo, service := setupTest(t)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	full := make([]types.Contact, exportBatchSize)
+	for i := range full {
+		full[i] = types.Contact{ContactID: uuid.New(), Name: fmt.Sprintf("Contact %d", i), CreatedAt: coreTypes.NewTimestamp(start.Add(-time.Duration(i) * time.Minute))}
+	}
+	rest := []types.Contact{{ContactID: uuid.New(), Name: "Oldest", CreatedAt: coreTypes.NewTimestamp(start.Add(-24 * time.Hour))}}
+	last := full[len(full)-1]
+
+	// the second batch continues after the last contact of the first
+	mockRepo.On("ListContactsPaginatedStream", ctx, userID, (*time.Time)(nil), (*uuid.UUID)(nil), exportBatchSize, coreTypes.SortOrderDesc, types.ContactFilter{}).
+		Return(full, nil).Once()
+	mockRepo.On("ListContactsPaginatedStream", ctx, userID, &last.CreatedAt.Time, &last.ContactID, exportBatchSize, coreTypes.SortOrderDesc, types.ContactFilter{}).
+		Return(rest, nil).Once()
+
+	var names []string
+	err := service.ExportContacts(ctx, userID, func(contact types.Contact) error {
+		names = append(names, contact.Name)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, names, int(exportBatchSize)+1)
+	assert.Equal(t, "Contact 0", names[0])
+	assert.Equal(t, "Oldest", names[len(names)-1])
+	mockRepo.AssertExpectations(t)
+
+	t.Run("callback error stops the export", func(t *testing.T) {
+		mockRepo.ExpectedCalls = nil
+		mockRepo.On("ListContactsPaginatedStream", ctx, userID, (*time.Time)(nil), (*uuid.UUID)(nil), exportBatchSize, coreTypes.SortOrderDesc, types.ContactFilter{}).
+			Return(full, nil).Once()
+
+		written := 0
+		stop := errors.New("client went away")
+		err := service.ExportContacts(ctx, userID, func(contact types.Contact) error {
+			written++
+			if written == 3 {
+				return stop
+			}
+			return nil
+		})
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 3, written)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestContactService_ExportContactsLimit(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	repo := new(mockContactRepository)
+	service := NewContactService(repo, new(mockJobEnqueuer), nil, types.ExportPolicy{SyncMaxRows: 10}, nil, nil, nil, zap.NewNop())
+
+	repo.On("CountContacts", ctx, userID).Return(int64(11), nil).Once()
+	err := service.ExportContacts(ctx, userID, func(types.Contact) error { return nil })
+	assert.ErrorContains(t, err, "POST /contacts/export-jobs")
+	repo.AssertNotCalled(t, "ListContactsPaginatedStream", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	repo.On("CountContacts", ctx, userID).Return(int64(10), nil).Once()
+	repo.On("ListContactsPaginatedStream", ctx, userID, (*time.Time)(nil), (*uuid.UUID)(nil), exportBatchSize, coreTypes.SortOrderDesc, types.ContactFilter{}).
+		Return([]types.Contact{{ContactID: uuid.New(), Name: "Jane Doe"}}, nil).Once()
+	assert.NoError(t, service.ExportContacts(ctx, userID, func(types.Contact) error { return nil }))
+	repo.AssertExpectations(t)
+}
+
+func TestContactService_ContactExportJobs(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	repo := new(mockContactRepository)
+	jobs := new(mockJobEnqueuer)
+	blobs, err := blob.NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	service := NewContactService(repo, jobs, blobs, types.ExportPolicy{}, nil, nil, nil, zap.NewNop())
+
+	payload, err := json.Marshal(types.ExportJobPayload{Format: types.ExportFormatCSV})
+	require.NoError(t, err)
+	job := jobTypes.Job{JobID: uuid.New(), UserID: userID, Type: jobTypes.JobTypeContactExport, Status: jobTypes.JobStatusPending, Payload: payload}
+
+	t.Run("start queues a job sized by the address book", func(t *testing.T) {
+		repo.ExpectedCalls, jobs.ExpectedCalls = nil, nil
+		repo.On("CountContacts", ctx, userID).Return(int64(42), nil)
+		jobs.On("Enqueue", ctx, userID, jobTypes.JobTypeContactExport, 42, types.ExportJobPayload{Format: types.ExportFormatCSV}).Return(job, nil)
+
+		started, err := service.StartContactExport(ctx, userID, types.ExportFormatCSV)
+		require.NoError(t, err)
+		assert.Equal(t, job.JobID, started.JobID)
+		assert.Equal(t, types.ExportFormatCSV, started.Format)
+		jobs.AssertExpectations(t)
+	})
+
+	t.Run("unknown formats are rejected", func(t *testing.T) {
+		_, err := service.StartContactExport(ctx, userID, "application/xml")
+		assert.ErrorContains(t, err, "can't be exported")
+	})
+
+	t.Run("other jobs aren't exports", func(t *testing.T) {
+		jobs.ExpectedCalls = nil
+		imported := job
+		imported.Type = jobTypes.JobTypeContactImport
+		jobs.On("GetJob", ctx, imported.JobID, userID).Return(imported, nil)
+
+		_, err := service.GetContactExport(ctx, userID, imported.JobID)
+		assert.ErrorContains(t, err, "export job not found")
+	})
+
+	t.Run("pending exports have no file yet", func(t *testing.T) {
+		jobs.ExpectedCalls = nil
+		jobs.On("GetJob", ctx, job.JobID, userID).Return(job, nil)
+
+		_, _, err := service.OpenContactExport(ctx, userID, job.JobID)
+		assert.ErrorContains(t, err, "the export is pending")
+	})
+
+	t.Run("completed exports open their file", func(t *testing.T) {
+		jobs.ExpectedCalls = nil
+		key := "exports/contacts/done.csv"
+		require.NoError(t, blobs.Put(ctx, key, func(w io.Writer) error {
+			_, err := io.WriteString(w, "contact_id\n")
+			return err
+		}))
+		completed := job
+		completed.Status = jobTypes.JobStatusCompleted
+		completed.ResultKey = &key
+		jobs.On("GetJob", ctx, completed.JobID, userID).Return(completed, nil)
+
+		export, file, err := service.OpenContactExport(ctx, userID, completed.JobID)
+		require.NoError(t, err)
+		defer file.Close()
+		content, err := io.ReadAll(file)
+		require.NoError(t, err)
+		assert.Equal(t, "contact_id\n", string(content))
+		assert.Equal(t, types.ExportFormatCSV, export.Format)
+
+		missing := "exports/contacts/purged.csv"
+		completed.ResultKey = &missing
+		jobs.ExpectedCalls = nil
+		jobs.On("GetJob", ctx, completed.JobID, userID).Return(completed, nil)
+		_, _, err = service.OpenContactExport(ctx, userID, completed.JobID)
+		assert.ErrorContains(t, err, "no longer available")
+	})
+}
+
+func TestExportTask(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	blobs, err := blob.NewFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	tests := []struct {
+		format string
+		check  func(t *testing.T, content string)
+	}{
+		{
+			format: types.ExportFormatCSV,
+			check: func(t *testing.T, content string) {
+				rows, err := csv.NewReader(strings.NewReader(content)).ReadAll()
+				require.NoError(t, err)
+				assert.Equal(t, types.CSVHeader, rows[0])
+				assert.Len(t, rows, 4)
+			},
+		},
+		{
+			format: types.ExportFormatJSON,
+			check: func(t *testing.T, content string) {
+				var contacts []types.Contact
+				require.NoError(t, json.Unmarshal([]byte(content), &contacts))
+				assert.Len(t, contacts, 3)
+				assert.Equal(t, "Contact 1", contacts[0].Name)
+			},
+		},
+		{
+			format: types.ExportFormatVCard,
+			check: func(t *testing.T, content string) {
+				assert.Equal(t, 3, strings.Count(content, "BEGIN:VCARD"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			payload, err := json.Marshal(types.ExportJobPayload{Format: tt.format})
+			require.NoError(t, err)
+			job := jobTypes.Job{JobID: uuid.New(), UserID: userID, Type: jobTypes.JobTypeContactExport, Payload: payload}
+
+			rows, key, err := ExportTask(&generatedContactRepository{total: 3}, blobs)(ctx, job)
+			require.NoError(t, err)
+			assert.Equal(t, 3, rows)
+			assert.Equal(t, fmt.Sprintf("exports/contacts/%s/%s.%s", userID, job.JobID, types.ExportExtensions[tt.format]), key)
+
+			file, err := blobs.Open(ctx, key)
+			require.NoError(t, err)
+			defer file.Close()
+			content, err := io.ReadAll(file)
+			require.NoError(t, err)
+			tt.check(t, string(content))
+		})
+	}
+}
+
+// generatedContactRepository produces contacts on demand so the export benchmark
+// measures the export path rather than a fixture held in memory
+type generatedContactRepository struct {
+	mockContactRepository
+	total  int
+	served int
+}
+
+func (r *generatedContactRepository) ListContactsPaginatedStream(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.ContactFilter, fn func(types.Contact) error) error {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for n := int32(0); n < limit && r.served < r.total; n++ {
+		r.served++
+		if err := fn(types.Contact{
+			ContactID:    uuid.New(),
+			UserID:       userID,
+			Name:         fmt.Sprintf("Contact %d", r.served),
+			Email:        utils.StringPtr(fmt.Sprintf("contact%d@example.com", r.served)),
+			AddressLine1: utils.StringPtr("123 Main Street"),
+			City:         utils.StringPtr("Springfield"),
+			Tags:         []uuid.UUID{uuid.New()},
+			CreatedAt:    coreTypes.NewTimestamp(start.Add(-time.Duration(r.served) * time.Second)),
+			UpdatedAt:    coreTypes.NewTimestamp(start),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BenchmarkExportContacts writes exports of growing size as CSV. peak-heap-B samples the
+// live heap while rows are written and stays flat as the row count grows, since no
+// contact outlives its callback.
+func BenchmarkExportContacts(b *testing.B) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	for _, rows := range []int{1000, 10000} {
+		b.Run(fmt.Sprintf("rows=%d", rows), func(b *testing.B) {
+			b.ReportAllocs()
+			var peak uint64
+			for i := 0; i < b.N; i++ {
+				service := NewContactService(&generatedContactRepository{total: rows}, nil, nil, types.ExportPolicy{}, nil, nil, nil, zap.NewNop())
+				writer := csv.NewWriter(io.Discard)
+
+				var base, stats runtime.MemStats
+				runtime.GC()
+				runtime.ReadMemStats(&base)
+				written := 0
+				err := service.ExportContacts(ctx, userID, func(contact types.Contact) error {
+					written++
+					if written%1000 == 0 {
+						runtime.GC()
+						runtime.ReadMemStats(&stats)
+						if stats.HeapAlloc > base.HeapAlloc && stats.HeapAlloc-base.HeapAlloc > peak {
+							peak = stats.HeapAlloc - base.HeapAlloc
+						}
+					}
+					return writer.Write(contact.CSVRecord())
+				})
+				if err != nil || written != rows {
+					b.Fatalf("exported %d of %d rows: %v", written, rows, err)
+				}
+				writer.Flush()
+			}
+			b.ReportMetric(float64(peak), "peak-heap-B")
+		})
+	}
+}
+
+func TestContactService_Logging(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	contactID := uuid.New()
+
+	tests := []struct {
+		name  string
+		err   error
+		level zapcore.Level
+	}{
+		{name: "success", level: zapcore.DebugLevel},
+		{name: "failure", err: errors.New("database error"), level: zapcore.ErrorLevel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.DebugLevel)
+			mockRepo := new(mockContactRepository)
+			service := NewContactService(mockRepo, new(mockJobEnqueuer), nil, types.ExportPolicy{}, nil, nil, nil, zap.New(core))
+			mockRepo.On("GetContact", ctx, contactID, userID).Return(types.Contact{ContactID: contactID}, tt.err)
+
+			_, err := service.GetContact(ctx, contactID, userID, types.ContactExpand{})
+			assert.Equal(t, tt.err, err)
+
+			require.Equal(t, 1, logs.Len())
+			entry := logs.All()[0]
+			assert.Equal(t, tt.level, entry.Level)
+
+			fields := entry.ContextMap()
+			assert.Equal(t, userID.String(), fields[logging.FieldUserID])
+			assert.Equal(t, "contact", fields[logging.FieldEntityType])
+			assert.Equal(t, contactID.String(), fields[logging.FieldEntityID])
+			assert.Equal(t, "ContactService.GetContact", fields[logging.FieldOperation])
+			assert.Contains(t, fields, logging.FieldDuration)
+			if tt.err != nil {
+				assert.Equal(t, logging.EventFailed, entry.Message)
+				assert.Equal(t, tt.err.Error(), fields["error"])
+			} else {
+				assert.Equal(t, logging.EventSucceeded, entry.Message)
+			}
+		})
+	}
+
+	t.Run("created contacts log their ID", func(t *testing.T) {
+		core, logs := observer.New(zapcore.DebugLevel)
+		mockRepo := new(mockContactRepository)
+		service := NewContactService(mockRepo, new(mockJobEnqueuer), nil, types.ExportPolicy{}, nil, nil, nil, zap.New(core))
+		mockRepo.On("CreateContact", ctx, mock.AnythingOfType("types.ContactCreatePayload"), userID).
+			Return(types.Contact{ContactID: contactID, Name: "John Doe"}, nil)
+
+		_, err := service.CreateContact(ctx, types.ContactCreatePayload{Name: "John Doe"}, userID)
+		require.NoError(t, err)
+
+		require.Equal(t, 1, logs.Len())
+		fields := logs.All()[0].ContextMap()
+		assert.Equal(t, userID.String(), fields[logging.FieldUserID])
+		assert.Equal(t, "contact", fields[logging.FieldEntityType])
+		assert.Equal(t, contactID.String(), fields[logging.FieldEntityID])
+		assert.Equal(t, "ContactService.CreateContact", fields[logging.FieldOperation])
+	})
+}
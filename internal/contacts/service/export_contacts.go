@@ -0,0 +1,230 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/blob"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	jobTypes "github.com/Abdelrahman-habib/expense-tracker/internal/jobs/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/jobs/worker"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// exportBatchSize is the number of contacts read per query while exporting
+const exportBatchSize int32 = 500
+
+// ExportContacts hands every active contact of the user to fn, newest first. Contacts
+// are streamed in keyset batches so only the row being written is held in memory.
+// Address books over the export policy's SyncMaxRows are refused before anything is
+// streamed, they go through StartContactExport.
+func (s *contactService) ExportContacts(ctx context.Context, userID uuid.UUID, fn func(types.Contact) error) (err error) {
+	op := s.operation("ExportContacts", userID, uuid.Nil)
+	defer op.End(&err)
+
+	if s.exports.SyncMaxRows > 0 {
+		count, err := s.repo.CountContacts(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if count > int64(s.exports.SyncMaxRows) {
+			return errors.NewValidationError("%d contacts are too many to export at once, the limit is %d, start an export job with POST /contacts/export-jobs instead", count, s.exports.SyncMaxRows)
+		}
+	}
+
+	exported, err := StreamContacts(ctx, s.repo, userID, fn)
+	op.With(zap.Int("exported", exported))
+	return err
+}
+
+// StreamContacts hands every active contact of the user to fn in keyset batches, newest
+// first, and returns how many it handed over
+func StreamContacts(ctx context.Context, repo repository.Repository, userID uuid.UUID, fn func(types.Contact) error) (int, error) {
+	var cursor *time.Time
+	var cursorID *uuid.UUID
+	var exported int
+	for {
+		var read int32
+		err := repo.ListContactsPaginatedStream(ctx, userID, cursor, cursorID, exportBatchSize, coreTypes.SortOrderDesc, types.ContactFilter{}, func(contact types.Contact) error {
+			read++
+			exported++
+			cursor, cursorID = &contact.CreatedAt.Time, &contact.ContactID
+			return fn(contact)
+		})
+		if err != nil {
+			return exported, err
+		}
+		if read < exportBatchSize {
+			return exported, nil
+		}
+	}
+}
+
+// StartContactExport queues a background export of every contact of the user in format,
+// one of the ExportExtensions media types
+func (s *contactService) StartContactExport(ctx context.Context, userID uuid.UUID, format string) (_ types.ExportJob, err error) {
+	op := s.operation("StartContactExport", userID, uuid.Nil, zap.String("format", format))
+	defer op.End(&err)
+
+	if _, ok := types.ExportExtensions[format]; !ok {
+		return types.ExportJob{}, errors.NewValidationError("format: %q can't be exported", format)
+	}
+
+	// the count is an estimate for progress, contacts may come and go before the job runs
+	count, err := s.repo.CountContacts(ctx, userID)
+	if err != nil {
+		return types.ExportJob{}, err
+	}
+
+	payload := types.ExportJobPayload{Format: format}
+	job, err := s.jobs.Enqueue(ctx, userID, jobTypes.JobTypeContactExport, int(count), payload)
+	if err != nil {
+		return types.ExportJob{}, err
+	}
+	op.With(zap.String("job_id", job.JobID.String()))
+	return types.ExportJob{Job: job, Format: format}, nil
+}
+
+// GetContactExport returns an export job of the user, other jobs are not found
+func (s *contactService) GetContactExport(ctx context.Context, userID, jobID uuid.UUID) (_ types.ExportJob, err error) {
+	defer s.operation("GetContactExport", userID, uuid.Nil, zap.String("job_id", jobID.String())).End(&err)
+	return s.getContactExport(ctx, userID, jobID)
+}
+
+func (s *contactService) getContactExport(ctx context.Context, userID, jobID uuid.UUID) (types.ExportJob, error) {
+	job, err := s.jobs.GetJob(ctx, jobID, userID)
+	if err != nil {
+		return types.ExportJob{}, err
+	}
+	if job.Type != jobTypes.JobTypeContactExport {
+		return types.ExportJob{}, errors.NewNotFoundError("export job not found")
+	}
+
+	var payload types.ExportJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return types.ExportJob{}, fmt.Errorf("decode export job: %w", err)
+	}
+	return types.ExportJob{Job: job, Format: payload.Format}, nil
+}
+
+// OpenContactExport returns a completed export job of the user with a reader of its file,
+// the caller closes the reader. Exports still running or failed conflict.
+func (s *contactService) OpenContactExport(ctx context.Context, userID, jobID uuid.UUID) (_ types.ExportJob, _ io.ReadCloser, err error) {
+	defer s.operation("OpenContactExport", userID, uuid.Nil, zap.String("job_id", jobID.String())).End(&err)
+
+	job, err := s.getContactExport(ctx, userID, jobID)
+	if err != nil {
+		return types.ExportJob{}, nil, err
+	}
+	if job.Status != jobTypes.JobStatusCompleted || job.ResultKey == nil {
+		return types.ExportJob{}, nil, errors.NewConflictError("the export is %s, its file is ready once it is completed", job.Status)
+	}
+
+	file, err := s.blobs.Open(ctx, *job.ResultKey)
+	if stdErrors.Is(err, blob.ErrNotFound) {
+		return types.ExportJob{}, nil, errors.NewNotFoundError("the export file is no longer available, start a new export")
+	}
+	if err != nil {
+		return types.ExportJob{}, nil, err
+	}
+	return job, file, nil
+}
+
+// ExportTask returns the job task writing a contact export to the blob store, under
+// exports/contacts/<user>/<job>.<extension>
+func ExportTask(repo repository.Repository, blobs blob.Store) worker.Task {
+	return func(ctx context.Context, job jobTypes.Job) (int, string, error) {
+		var payload types.ExportJobPayload
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return 0, "", fmt.Errorf("decode export job: %w", err)
+		}
+		extension, ok := types.ExportExtensions[payload.Format]
+		if !ok {
+			return 0, "", fmt.Errorf("unknown export format %q", payload.Format)
+		}
+
+		key := fmt.Sprintf("exports/contacts/%s/%s.%s", job.UserID, job.JobID, extension)
+		var exported int
+		err := blobs.Put(ctx, key, func(w io.Writer) error {
+			var err error
+			exported, err = WriteContacts(ctx, repo, job.UserID, payload.Format, w)
+			return err
+		})
+		if err != nil {
+			return 0, "", err
+		}
+		return exported, key, nil
+	}
+}
+
+// WriteContacts writes every active contact of the user to w in format, one of the
+// ExportExtensions media types, and returns how many it wrote
+func WriteContacts(ctx context.Context, repo repository.Repository, userID uuid.UUID, format string, w io.Writer) (int, error) {
+	return writeExport(w, format, func(fn func(types.Contact) error) (int, error) {
+		return StreamContacts(ctx, repo, userID, fn)
+	})
+}
+
+// writeExport writes the contacts stream produces to w in format, the same documents
+// GET /contacts/export streams
+func writeExport(w io.Writer, format string, stream func(fn func(types.Contact) error) (int, error)) (int, error) {
+	buffered := bufio.NewWriter(w)
+
+	var exported int
+	var err error
+	switch format {
+	case types.ExportFormatJSON:
+		if _, err := buffered.WriteString("["); err != nil {
+			return 0, err
+		}
+		written := 0
+		exported, err = stream(func(contact types.Contact) error {
+			encoded, err := json.Marshal(contact)
+			if err != nil {
+				return err
+			}
+			written++
+			if written > 1 {
+				if err := buffered.WriteByte(','); err != nil {
+					return err
+				}
+			}
+			_, err = buffered.Write(encoded)
+			return err
+		})
+		if err == nil {
+			_, err = buffered.WriteString("]")
+		}
+	case types.ExportFormatVCard:
+		exported, err = stream(func(contact types.Contact) error {
+			_, err := buffered.WriteString(contact.VCard())
+			return err
+		})
+	default:
+		writer := csv.NewWriter(buffered)
+		if err := writer.Write(types.CSVHeader); err != nil {
+			return 0, err
+		}
+		exported, err = stream(func(contact types.Contact) error {
+			return writer.Write(contact.CSVRecord())
+		})
+		writer.Flush()
+		if err == nil {
+			err = writer.Error()
+		}
+	}
+	if err != nil {
+		return exported, err
+	}
+	return exported, buffered.Flush()
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/bulk"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/quota"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	jobTypes "github.com/Abdelrahman-habib/expense-tracker/internal/jobs/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/jobs/worker"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/validate"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func (s *contactService) ImportContacts(ctx context.Context, userID uuid.UUID, contacts []types.ContactCreatePayload) (_ jobTypes.Job, err error) {
+	defer s.operation("ImportContacts", userID, uuid.Nil,
+		zap.Int("count", len(contacts))).End(&err)
+
+	if len(contacts) == 0 {
+		return jobTypes.Job{}, fmt.Errorf("no contacts to import")
+	}
+	if len(contacts) > types.MaxImportContacts {
+		return jobTypes.Job{}, fmt.Errorf("number of contacts exceeds maximum allowed of %d", types.MaxImportContacts)
+	}
+
+	return s.jobs.Enqueue(ctx, userID, jobTypes.JobTypeContactImport, len(contacts), contacts)
+}
+
+// ImportProcessor returns the job processor that writes imported contacts.
+// Each row goes through the same validation and normalization as a single
+// create, so one bad row is reported without failing the rest of the import.
+// emails checks the domain of the emails, nil only checks for the default typos.
+// quotas fails the rows past the user's contact quota, nil leaves them unlimited.
+func ImportProcessor(emails *validate.EmailChecker, quotas *quota.Checker) worker.Processor {
+	emails = orDefaultEmailChecker(emails)
+	return func(ctx context.Context, job jobTypes.Job) (int, bulk.RowFunc, error) {
+		var contacts []types.ContactCreatePayload
+		if err := json.Unmarshal(job.Payload, &contacts); err != nil {
+			return 0, nil, fmt.Errorf("decode contacts: %w", err)
+		}
+
+		row := func(ctx context.Context, q *db.Queries, index int) error {
+			payload, err := prepareImportRow(ctx, contacts[index], nil, emails)
+			if err != nil {
+				return err
+			}
+			// counted in the row's transaction, so the rows imported before it count
+			if err := quotas.Using(q).Check(ctx, job.UserID, quota.Contacts, 1); err != nil {
+				return err
+			}
+
+			_, err = repository.New(q).CreateContact(ctx, payload, job.UserID)
+			return err
+		}
+
+		return len(contacts), row, nil
+	}
+}
+
+// ValidateContacts runs the import validation over every row without writing any,
+// so clients can show row errors before queueing the import
+func (s *contactService) ValidateContacts(ctx context.Context, userID uuid.UUID, contacts []types.ContactCreatePayload) (_ types.ContactBatchValidation, err error) {
+	defer s.operation("ValidateContacts", userID, uuid.Nil,
+		zap.Int("count", len(contacts))).End(&err)
+
+	if len(contacts) == 0 {
+		return types.ContactBatchValidation{}, fmt.Errorf("no contacts to validate")
+	}
+	if len(contacts) > types.MaxImportContacts {
+		return types.ContactBatchValidation{}, fmt.Errorf("number of contacts exceeds maximum allowed of %d", types.MaxImportContacts)
+	}
+
+	// resolve the tags of the whole batch with one query
+	seen := make(map[uuid.UUID]bool)
+	var tagIDs []uuid.UUID
+	for _, contact := range contacts {
+		for _, tag := range contact.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				tagIDs = append(tagIDs, tag)
+			}
+		}
+	}
+	ownedIDs, err := s.repo.ListOwnedTagIDs(ctx, userID, tagIDs)
+	if err != nil {
+		return types.ContactBatchValidation{}, err
+	}
+	owned := make(map[uuid.UUID]bool, len(ownedIDs))
+	for _, tag := range ownedIDs {
+		owned[tag] = true
+	}
+
+	result := types.ContactBatchValidation{Results: make([]types.ContactValidationResult, len(contacts))}
+	for i, contact := range contacts {
+		result.Results[i] = types.ContactValidationResult{Index: i, Valid: true}
+		if _, err := prepareImportRow(ctx, contact, owned, s.emails); err != nil {
+			result.Results[i] = types.ContactValidationResult{Index: i, Error: err.Error()}
+			result.Invalid++
+			continue
+		}
+		result.Valid++
+	}
+	return result, nil
+}
+
+// prepareImportRow validates and normalizes an imported contact like a single create.
+// With owned set, tags outside it fail the row; otherwise they are left to the database
+func prepareImportRow(ctx context.Context, payload types.ContactCreatePayload, owned map[uuid.UUID]bool, emails *validate.EmailChecker) (types.ContactCreatePayload, error) {
+	if err := payload.Bind(nil); err != nil {
+		return payload, err
+	}
+
+	payload, err := prepareCreatePayload(ctx, payload, emails)
+	if err != nil {
+		return payload, err
+	}
+
+	if owned != nil {
+		for _, tag := range payload.Tags {
+			if !owned[tag] {
+				return payload, fmt.Errorf("tag %s not owned by user", tag)
+			}
+		}
+	}
+	return payload, nil
+}
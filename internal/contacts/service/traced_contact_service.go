@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/tracing"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	jobTypes "github.com/Abdelrahman-habib/expense-tracker/internal/jobs/types"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedContactService records a span around each call of the wrapped service
+type tracedContactService struct {
+	next   ContactService
+	tracer trace.Tracer
+}
+
+// NewTracedContactService wraps next so each call records a span named after the method, a nil
+// tracer returns next as is
+func NewTracedContactService(next ContactService, tracer trace.Tracer) ContactService {
+	if tracer == nil {
+		return next
+	}
+	return &tracedContactService{next: next, tracer: tracer}
+}
+
+func (t *tracedContactService) GetContact(ctx context.Context, contactID, userID uuid.UUID, expand types.ContactExpand) (types.Contact, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactService.GetContact")
+	contact, err := t.next.GetContact(ctx, contactID, userID, expand)
+	tracing.End(span, err)
+	return contact, err
+}
+
+func (t *tracedContactService) GetContactsByIDs(ctx context.Context, userID uuid.UUID, contactIDs []uuid.UUID) ([]types.Contact, []uuid.UUID, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactService.GetContactsByIDs")
+	contacts, missing, err := t.next.GetContactsByIDs(ctx, userID, contactIDs)
+	tracing.End(span, err)
+	return contacts, missing, err
+}
+
+func (t *tracedContactService) ListContacts(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]types.Contact, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactService.ListContacts")
+	contacts, err := t.next.ListContacts(ctx, userID, limit, offset)
+	tracing.End(span, err)
+	return contacts, err
+}
+
+func (t *tracedContactService) CreateContact(ctx context.Context, payload types.ContactCreatePayload, userID uuid.UUID) (types.Contact, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactService.CreateContact")
+	contact, err := t.next.CreateContact(ctx, payload, userID)
+	tracing.End(span, err)
+	return contact, err
+}
+
+func (t *tracedContactService) UpdateContact(ctx context.Context, contactID, userID uuid.UUID, merge func(types.Contact) (types.ContactUpdatePayload, error)) (types.Contact, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactService.UpdateContact")
+	contact, err := t.next.UpdateContact(ctx, contactID, userID, merge)
+	tracing.End(span, err)
+	return contact, err
+}
+
+func (t *tracedContactService) UpsertContactByExternalRef(ctx context.Context, userID uuid.UUID, ref types.ExternalRef, payload types.ContactUpsertPayload) (types.Contact, bool, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactService.UpsertContactByExternalRef")
+	contact, created, err := t.next.UpsertContactByExternalRef(ctx, userID, ref, payload)
+	tracing.End(span, err)
+	return contact, created, err
+}
+
+func (t *tracedContactService) DeleteContact(ctx context.Context, contactID, userID uuid.UUID, version *time.Time) error {
+	ctx, span := t.tracer.Start(ctx, "ContactService.DeleteContact")
+	err := t.next.DeleteContact(ctx, contactID, userID, version)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *tracedContactService) ListDeletedContactsPaginated(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32, order coreTypes.SortOrder) ([]types.Contact, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactService.ListDeletedContactsPaginated")
+	contacts, err := t.next.ListDeletedContactsPaginated(ctx, userID, cursor, cursorID, limit, order)
+	tracing.End(span, err)
+	return contacts, err
+}
+
+func (t *tracedContactService) RestoreContact(ctx context.Context, contactID, userID uuid.UUID) (types.Contact, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactService.RestoreContact")
+	contact, err := t.next.RestoreContact(ctx, contactID, userID)
+	tracing.End(span, err)
+	return contact, err
+}
+
+func (t *tracedContactService) ListContactsPaginated(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.ContactFilter) ([]types.Contact, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactService.ListContactsPaginated")
+	contacts, err := t.next.ListContactsPaginated(ctx, userID, cursor, cursorID, limit, order, filter)
+	tracing.End(span, err)
+	return contacts, err
+}
+
+func (t *tracedContactService) ListContactIDsPaginated(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.ContactFilter) ([]coreTypes.KeyedID, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactService.ListContactIDsPaginated")
+	keys, err := t.next.ListContactIDsPaginated(ctx, userID, cursor, cursorID, limit, order, filter)
+	tracing.End(span, err)
+	return keys, err
+}
+
+func (t *tracedContactService) StreamContactsPaginated(ctx context.Context, userID uuid.UUID, cursor *time.Time, cursorID *uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.ContactFilter, fn func(types.Contact) error) error {
+	ctx, span := t.tracer.Start(ctx, "ContactService.StreamContactsPaginated")
+	err := t.next.StreamContactsPaginated(ctx, userID, cursor, cursorID, limit, order, filter, fn)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *tracedContactService) SearchContacts(ctx context.Context, userID uuid.UUID, name string, limit, offset int32) ([]types.Contact, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactService.SearchContacts")
+	contacts, err := t.next.SearchContacts(ctx, userID, name, limit, offset)
+	tracing.End(span, err)
+	return contacts, err
+}
+
+func (t *tracedContactService) SearchContactsByPhone(ctx context.Context, userID uuid.UUID, phone string, limit, offset int32) ([]types.Contact, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactService.SearchContactsByPhone")
+	contacts, err := t.next.SearchContactsByPhone(ctx, userID, phone, limit, offset)
+	tracing.End(span, err)
+	return contacts, err
+}
+
+func (t *tracedContactService) SearchContactsByCompany(ctx context.Context, userID uuid.UUID, company string, limit, offset int32) ([]types.Contact, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactService.SearchContactsByCompany")
+	contacts, err := t.next.SearchContactsByCompany(ctx, userID, company, limit, offset)
+	tracing.End(span, err)
+	return contacts, err
+}
+
+func (t *tracedContactService) SearchContactsPicker(ctx context.Context, userID uuid.UUID, name string, limit, offset int32) ([]types.ContactPickerItem, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactService.SearchContactsPicker")
+	items, err := t.next.SearchContactsPicker(ctx, userID, name, limit, offset)
+	tracing.End(span, err)
+	return items, err
+}
+
+func (t *tracedContactService) SearchContactsByPhonePicker(ctx context.Context, userID uuid.UUID, phone string, limit, offset int32) ([]types.ContactPickerItem, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactService.SearchContactsByPhonePicker")
+	items, err := t.next.SearchContactsByPhonePicker(ctx, userID, phone, limit, offset)
+	tracing.End(span, err)
+	return items, err
+}
+
+func (t *tracedContactService) SearchContactsByCompanyPicker(ctx context.Context, userID uuid.UUID, company string, limit, offset int32) ([]types.ContactPickerItem, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactService.SearchContactsByCompanyPicker")
+	items, err := t.next.SearchContactsByCompanyPicker(ctx, userID, company, limit, offset)
+	tracing.End(span, err)
+	return items, err
+}
+
+func (t *tracedContactService) ListContactCompanies(ctx context.Context, userID uuid.UUID, params types.CompanyListParams) ([]types.CompanyContacts, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactService.ListContactCompanies")
+	companyContacts, err := t.next.ListContactCompanies(ctx, userID, params)
+	tracing.End(span, err)
+	return companyContacts, err
+}
+
+func (t *tracedContactService) ListCompanies(ctx context.Context, userID uuid.UUID, limit int32) ([]types.CompanyCount, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactService.ListCompanies")
+	companyCounts, err := t.next.ListCompanies(ctx, userID, limit)
+	tracing.End(span, err)
+	return companyCounts, err
+}
+
+func (t *tracedContactService) ListFacets(ctx context.Context, userID uuid.UUID, field string) ([]coreTypes.Facet, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactService.ListFacets")
+	facets, err := t.next.ListFacets(ctx, userID, field)
+	tracing.End(span, err)
+	return facets, err
+}
+
+func (t *tracedContactService) ImportContacts(ctx context.Context, userID uuid.UUID, contacts []types.ContactCreatePayload) (jobTypes.Job, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactService.ImportContacts")
+	job, err := t.next.ImportContacts(ctx, userID, contacts)
+	tracing.End(span, err)
+	return job, err
+}
+
+func (t *tracedContactService) ValidateContacts(ctx context.Context, userID uuid.UUID, contacts []types.ContactCreatePayload) (types.ContactBatchValidation, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactService.ValidateContacts")
+	validation, err := t.next.ValidateContacts(ctx, userID, contacts)
+	tracing.End(span, err)
+	return validation, err
+}
+
+func (t *tracedContactService) ExportContacts(ctx context.Context, userID uuid.UUID, fn func(types.Contact) error) error {
+	ctx, span := t.tracer.Start(ctx, "ContactService.ExportContacts")
+	err := t.next.ExportContacts(ctx, userID, fn)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *tracedContactService) StartContactExport(ctx context.Context, userID uuid.UUID, format string) (types.ExportJob, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactService.StartContactExport")
+	job, err := t.next.StartContactExport(ctx, userID, format)
+	tracing.End(span, err)
+	return job, err
+}
+
+func (t *tracedContactService) GetContactExport(ctx context.Context, userID, jobID uuid.UUID) (types.ExportJob, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactService.GetContactExport")
+	job, err := t.next.GetContactExport(ctx, userID, jobID)
+	tracing.End(span, err)
+	return job, err
+}
+
+func (t *tracedContactService) OpenContactExport(ctx context.Context, userID, jobID uuid.UUID) (types.ExportJob, io.ReadCloser, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactService.OpenContactExport")
+	job, file, err := t.next.OpenContactExport(ctx, userID, jobID)
+	tracing.End(span, err)
+	return job, file, err
+}
+
+func (t *tracedContactService) CreateContactRelationship(ctx context.Context, contactID, userID uuid.UUID, payload types.ContactRelationshipPayload) (types.ContactRelationship, error) {
+	ctx, span := t.tracer.Start(ctx, "ContactService.CreateContactRelationship")
+	relationship, err := t.next.CreateContactRelationship(ctx, contactID, userID, payload)
+	tracing.End(span, err)
+	return relationship, err
+}
+
+func (t *tracedContactService) DeleteContactRelationship(ctx context.Context, contactID, relationshipID, userID uuid.UUID) error {
+	ctx, span := t.tracer.Start(ctx, "ContactService.DeleteContactRelationship")
+	err := t.next.DeleteContactRelationship(ctx, contactID, relationshipID, userID)
+	tracing.End(span, err)
+	return err
+}
@@ -1,10 +1,14 @@
 package types
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
-	"time"
+	"strconv"
+	"strings"
 
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/jsoncase"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/validate"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
@@ -17,88 +21,197 @@ const (
 	MaxAddressLength = 255
 	MaxTagsCount     = 10
 	MaxPhoneLength   = 20
+	MaxCompanyLength = 255
+	MaxNotesLength   = 10000
 )
 
+const (
+	DefaultCompaniesLimit       = 20
+	MaxCompaniesLimit           = 100
+	DefaultCompanyContactsLimit = 3
+	MaxCompanyContactsLimit     = 10
+
+	CompanySortByCount = "count"
+	CompanySortByName  = "name"
+)
+
+// MinPhoneSearchDigits is the fewest digits a phone search is run with, a shorter
+// prefix matches too much of the address book to be useful
+const MinPhoneSearchDigits = 4
+
+var (
+	// ErrPhoneSearchFormat is returned for a phone search that isn't made of digits
+	ErrPhoneSearchFormat = validation.NewError("validation_phone_search_format", "must be digits, optionally starting with +")
+	// ErrPhoneSearchTooShort is returned for a phone search with too few digits
+	ErrPhoneSearchTooShort = validation.NewError("validation_phone_search_too_short", fmt.Sprintf("must have at least %d digits", MinPhoneSearchDigits))
+)
+
+// MaxImportContacts caps the number of contacts accepted by a single import
+const MaxImportContacts = 10000
+
 // Contact represents the domain model for a contact
 // @Description Contact information including personal details, contact methods, address and tags
 type Contact struct {
-	ContactID     uuid.UUID   `json:"contactId" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
-	UserID        uuid.UUID   `json:"userId" example:"123e4567-e89b-12d3-a456-426614174001" format:"uuid"`
-	Name          string      `json:"name" example:"John Doe" minLength:"1" maxLength:"255"`
-	Phone         *string     `json:"phone,omitempty" example:"+1-555-123-4567" maxLength:"20" format:"phone"`
-	Email         *string     `json:"email,omitempty" example:"john.doe@example.com" format:"email"`
-	AddressLine1  *string     `json:"addressLine1,omitempty" example:"123 Main St" maxLength:"255"`
-	AddressLine2  *string     `json:"addressLine2,omitempty" example:"Suite 100" maxLength:"255"`
-	Country       *string     `json:"country,omitempty" example:"US" format:"iso-3166-1-alpha-2"`
-	City          *string     `json:"city,omitempty" example:"New York" maxLength:"255"`
-	StateProvince *string     `json:"stateProvince,omitempty" example:"NY" maxLength:"255"`
-	ZipPostalCode *string     `json:"zipPostalCode,omitempty" example:"10001" format:"zip-code"`
-	Tags          []uuid.UUID `json:"tags,omitempty" example:"123e4567-e89b-12d3-a456-426614174000,123e4567-e89b-12d3-a456-426614174001"`
-	CreatedAt     time.Time   `json:"createdAt" example:"2024-01-01T00:00:00Z" format:"date-time"`
-	UpdatedAt     time.Time   `json:"updatedAt" example:"2024-01-01T00:00:00Z" format:"date-time"`
+	ContactID     uuid.UUID        `json:"contactId" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
+	UserID        uuid.UUID        `json:"userId" example:"123e4567-e89b-12d3-a456-426614174001" format:"uuid"`
+	Name          string           `json:"name" example:"John Doe" minLength:"1" maxLength:"255"`
+	Phone         *string          `json:"phone,omitempty" example:"+1-555-123-4567" maxLength:"20" format:"phone"`
+	Email         *string          `json:"email,omitempty" example:"john.doe@example.com" format:"email"`
+	AddressLine1  *string          `json:"addressLine1,omitempty" example:"123 Main St" maxLength:"255"`
+	AddressLine2  *string          `json:"addressLine2,omitempty" example:"Suite 100" maxLength:"255"`
+	Country       *string          `json:"country,omitempty" example:"US" format:"iso-3166-1-alpha-2"`
+	City          *string          `json:"city,omitempty" example:"New York" maxLength:"255"`
+	StateProvince *string          `json:"stateProvince,omitempty" example:"NY" maxLength:"255"`
+	ZipPostalCode *string          `json:"zipPostalCode,omitempty" example:"10001" format:"zip-code"`
+	Company       *string          `json:"company,omitempty" example:"Acme Inc." maxLength:"255"`
+	Notes         *string          `json:"notes,omitempty" example:"Met at the spring trade show, prefers email" maxLength:"10000"`
+	Tags          []uuid.UUID      `json:"tags,omitempty" example:"123e4567-e89b-12d3-a456-426614174000,123e4567-e89b-12d3-a456-426614174001"`
+	Links         []ContactLink    `json:"links,omitempty" maxItems:"10"`
+	CreatedAt     types.Timestamp  `json:"createdAt" example:"2024-01-01T00:00:00.000Z" swaggertype:"string" format:"date-time"`
+	UpdatedAt     types.Timestamp  `json:"updatedAt" example:"2024-01-01T00:00:00.000Z" swaggertype:"string" format:"date-time"`
+	DeletedAt     *types.Timestamp `json:"deletedAt,omitempty" example:"2024-01-02T00:00:00.000Z" swaggertype:"string" format:"date-time"`
+	CreatedBy     *uuid.UUID       `json:"createdBy,omitempty" example:"123e4567-e89b-12d3-a456-426614174001" format:"uuid"` // user who created the contact
+	UpdatedBy     *uuid.UUID       `json:"updatedBy,omitempty" example:"123e4567-e89b-12d3-a456-426614174001" format:"uuid"` // user behind the last update
+	// ExternalRef is set on contacts synced from another system
+	ExternalRef *ExternalRef `json:"externalRef,omitempty"`
+	// Relationships are set with expand=relationships
+	Relationships []ContactRelationship `json:"relationships,omitempty"`
+	// Score is how well the contact matches the query, set by searches with one
+	Score *float32 `json:"score,omitempty" example:"0.83" minimum:"0" maximum:"1"`
+}
+
+// ContactPickerItem is a contact in the picker view of a search, only what a picker shows
+// @Description A contact as listed by a picker
+type ContactPickerItem struct {
+	ContactID uuid.UUID `json:"contactId" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
+	Name      string    `json:"name" example:"John Doe"`
+	Phone     *string   `json:"phone,omitempty" example:"+1-555-123-4567" format:"phone"`
+	Email     *string   `json:"email,omitempty" example:"john.doe@example.com" format:"email"`
 }
 
 // ContactCreatePayload represents the payload for creating a new contact
 // @Description Payload for creating a new contact
 type ContactCreatePayload struct {
-	Name          string      `json:"name" example:"John Doe" minLength:"1" maxLength:"255"`
-	Phone         *string     `json:"phone,omitempty" example:"+1-555-123-4567" maxLength:"20" format:"phone"`
-	Email         *string     `json:"email,omitempty" example:"john.doe@example.com" format:"email"`
-	AddressLine1  *string     `json:"addressLine1,omitempty" example:"123 Main St" maxLength:"255"`
-	AddressLine2  *string     `json:"addressLine2,omitempty" example:"Suite 100" maxLength:"255"`
-	Country       *string     `json:"country,omitempty" example:"US" format:"iso-3166-1-alpha-2"`
-	City          *string     `json:"city,omitempty" example:"New York" maxLength:"255"`
-	StateProvince *string     `json:"stateProvince,omitempty" example:"NY" maxLength:"255"`
-	ZipPostalCode *string     `json:"zipPostalCode,omitempty" example:"10001" format:"zip-code"`
-	Tags          []uuid.UUID `json:"tags,omitempty" example:"123e4567-e89b-12d3-a456-426614174000,123e4567-e89b-12d3-a456-426614174001"`
+	Name          string        `json:"name" example:"John Doe" minLength:"1" maxLength:"255"`
+	Phone         *string       `json:"phone,omitempty" example:"+1-555-123-4567" maxLength:"20" format:"phone"`
+	Email         *string       `json:"email,omitempty" example:"john.doe@example.com" format:"email"`
+	AddressLine1  *string       `json:"addressLine1,omitempty" example:"123 Main St" maxLength:"255"`
+	AddressLine2  *string       `json:"addressLine2,omitempty" example:"Suite 100" maxLength:"255"`
+	Country       *string       `json:"country,omitempty" example:"US" format:"iso-3166-1-alpha-2"`
+	City          *string       `json:"city,omitempty" example:"New York" maxLength:"255"`
+	StateProvince *string       `json:"stateProvince,omitempty" example:"NY" maxLength:"255"`
+	ZipPostalCode *string       `json:"zipPostalCode,omitempty" example:"10001" format:"zip-code"`
+	Company       *string       `json:"company,omitempty" example:"Acme Inc." maxLength:"255"`
+	Notes         *string       `json:"notes,omitempty" example:"Met at the spring trade show, prefers email" maxLength:"10000"`
+	Tags          []uuid.UUID   `json:"tags,omitempty" example:"123e4567-e89b-12d3-a456-426614174000,123e4567-e89b-12d3-a456-426614174001"`
+	Links         []ContactLink `json:"links,omitempty" maxItems:"10"`
+	// AllowUnnamed lets a contact be created from its phone alone, it is named after the
+	// number. It is set with the allow_unnamed query parameter.
+	AllowUnnamed bool `json:"-" swaggerignore:"true"`
 }
 
 // Bind implements render.Binder interface and validates the create contact payload
 func (c *ContactCreatePayload) Bind(r *http.Request) error {
+	unnamed := c.AllowUnnamed && c.Name == ""
 	return validation.Errors{
-		"name":          validation.Validate(c.Name, validation.Required, validation.Length(1, MaxNameLength)),
-		"email":         validation.Validate(c.Email, validation.When(c.Email != nil, is.Email)),
-		"phone":         validation.Validate(c.Phone, validation.When(c.Phone != nil, validation.Length(1, MaxPhoneLength), validate.PhoneNumber)),
+		"name":          validation.Validate(c.Name, validation.When(!unnamed, validation.Required), validation.Length(1, MaxNameLength)),
+		"email":         validation.Validate(c.Email, validation.When(c.Email != nil, validate.EmailFormat)),
+		"phone":         validation.Validate(c.Phone, validation.When(unnamed, validation.Required.Error("is required for a contact without a name")), validation.When(c.Phone != nil, validation.Length(1, MaxPhoneLength), validate.PhoneNumber)),
 		"country":       validation.Validate(c.Country, validation.When(c.Country != nil, is.CountryCode2)),
 		"zip_code":      validation.Validate(c.ZipPostalCode, validation.When(c.ZipPostalCode != nil, validate.Zipcode)),
 		"address_line1": validation.Validate(c.AddressLine1, validation.When(c.AddressLine1 != nil, validation.Length(1, MaxAddressLength))),
 		"address_line2": validation.Validate(c.AddressLine2, validation.When(c.AddressLine2 != nil, validation.Length(1, MaxAddressLength))),
 		"city":          validation.Validate(c.City, validation.When(c.City != nil, validation.Length(1, MaxAddressLength))),
+		"company":       validation.Validate(c.Company, validation.When(c.Company != nil, validation.Length(1, MaxCompanyLength))),
+		"notes":         validation.Validate(c.Notes, validation.When(c.Notes != nil, validation.Length(1, MaxNotesLength))),
 		"tags":          validation.Validate(c.Tags, validation.Length(0, MaxTagsCount), validate.NoDuplicates(), validation.Each(is.UUID)),
+		"links":         validation.Validate(c.Links, validation.Length(0, MaxLinksCount)),
 	}.Filter()
 }
 
+// contactCreateAliases maps the snake_case field names of ContactCreatePayload to their camelCase name
+var contactCreateAliases = jsoncase.AliasesOf(ContactCreatePayload{})
+
+// UnmarshalJSON accepts the snake_case field names older clients send too
+func (c *ContactCreatePayload) UnmarshalJSON(data []byte) error {
+	type payload ContactCreatePayload
+	return contactCreateAliases.Unmarshal(data, (*payload)(c))
+}
+
+// ContactImportPayload represents the payload for importing contacts in bulk
+// @Description Contacts to import; each row is validated on its own while the import runs
+type ContactImportPayload struct {
+	Contacts []ContactCreatePayload `json:"contacts" minItems:"1" maxItems:"10000"`
+}
+
+// Bind implements render.Binder interface and validates the import contacts payload
+func (p *ContactImportPayload) Bind(r *http.Request) error {
+	return validation.Errors{
+		"contacts": validation.Validate(p.Contacts, validation.Required, validation.Length(1, MaxImportContacts)),
+	}.Filter()
+}
+
+// ContactValidationResult reports whether the contact at Index would import
+type ContactValidationResult struct {
+	Index int    `json:"index" example:"3"`
+	Valid bool   `json:"valid" example:"false"`
+	Error string `json:"error,omitempty" example:"name: cannot be blank."`
+}
+
+// ContactBatchValidation holds the outcome of validating a batch of contacts without importing them
+// @Description Per-row validation results of a batch, in the order of the submitted contacts
+type ContactBatchValidation struct {
+	Valid   int                       `json:"valid" example:"9"`
+	Invalid int                       `json:"invalid" example:"1"`
+	Results []ContactValidationResult `json:"results"`
+}
+
 // ContactUpdatePayload represents the payload for updating an existing contact
 // @Description Payload for updating an existing contact
 type ContactUpdatePayload struct {
-	ContactID     uuid.UUID   `json:"-" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
-	Name          string      `json:"name" example:"John Doe" minLength:"1" maxLength:"255"`
-	Phone         *string     `json:"phone,omitempty" example:"+1-555-123-4567" maxLength:"20" format:"phone"`
-	Email         *string     `json:"email,omitempty" example:"john.doe@example.com" format:"email"`
-	AddressLine1  *string     `json:"addressLine1,omitempty" example:"123 Main St" maxLength:"255"`
-	AddressLine2  *string     `json:"addressLine2,omitempty" example:"Suite 100" maxLength:"255"`
-	Country       *string     `json:"country,omitempty" example:"US" format:"iso-3166-1-alpha-2"`
-	City          *string     `json:"city,omitempty" example:"New York" maxLength:"255"`
-	StateProvince *string     `json:"stateProvince,omitempty" example:"NY" maxLength:"255"`
-	ZipPostalCode *string     `json:"zipPostalCode,omitempty" example:"10001" format:"zip-code"`
-	Tags          []uuid.UUID `json:"tags,omitempty" example:"123e4567-e89b-12d3-a456-426614174000,123e4567-e89b-12d3-a456-426614174001"`
+	ContactID     uuid.UUID     `json:"-" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
+	Name          string        `json:"name" example:"John Doe" minLength:"1" maxLength:"255"`
+	Phone         *string       `json:"phone,omitempty" example:"+1-555-123-4567" maxLength:"20" format:"phone"`
+	Email         *string       `json:"email,omitempty" example:"john.doe@example.com" format:"email"`
+	AddressLine1  *string       `json:"addressLine1,omitempty" example:"123 Main St" maxLength:"255"`
+	AddressLine2  *string       `json:"addressLine2,omitempty" example:"Suite 100" maxLength:"255"`
+	Country       *string       `json:"country,omitempty" example:"US" format:"iso-3166-1-alpha-2"`
+	City          *string       `json:"city,omitempty" example:"New York" maxLength:"255"`
+	StateProvince *string       `json:"stateProvince,omitempty" example:"NY" maxLength:"255"`
+	ZipPostalCode *string       `json:"zipPostalCode,omitempty" example:"10001" format:"zip-code"`
+	Company       *string       `json:"company,omitempty" example:"Acme Inc." maxLength:"255"`
+	Notes         *string       `json:"notes,omitempty" example:"Met at the spring trade show, prefers email" maxLength:"10000"`
+	Tags          []uuid.UUID   `json:"tags,omitempty" example:"123e4567-e89b-12d3-a456-426614174000,123e4567-e89b-12d3-a456-426614174001"`
+	Links         []ContactLink `json:"links,omitempty" maxItems:"10"`
 }
 
 // Bind implements render.Binder interface and validates the update contact payload
 func (u *ContactUpdatePayload) Bind(r *http.Request) error {
 	return validation.Errors{
 		"name":          validation.Validate(u.Name, validation.Required, validation.Length(1, MaxNameLength)),
-		"email":         validation.Validate(u.Email, validation.When(u.Email != nil, is.Email)),
+		"email":         validation.Validate(u.Email, validation.When(u.Email != nil, validate.EmailFormat)),
 		"phone":         validation.Validate(u.Phone, validation.When(u.Phone != nil, validation.Length(1, MaxPhoneLength), validate.PhoneNumber)),
 		"country":       validation.Validate(u.Country, validation.When(u.Country != nil, is.CountryCode2)),
 		"zip_code":      validation.Validate(u.ZipPostalCode, validation.When(u.ZipPostalCode != nil, validate.Zipcode)),
 		"address_line1": validation.Validate(u.AddressLine1, validation.When(u.AddressLine1 != nil, validation.Length(1, MaxAddressLength))),
 		"address_line2": validation.Validate(u.AddressLine2, validation.When(u.AddressLine2 != nil, validation.Length(1, MaxAddressLength))),
 		"city":          validation.Validate(u.City, validation.When(u.City != nil, validation.Length(1, MaxAddressLength))),
+		"company":       validation.Validate(u.Company, validation.When(u.Company != nil, validation.Length(1, MaxCompanyLength))),
+		"notes":         validation.Validate(u.Notes, validation.When(u.Notes != nil, validation.Length(1, MaxNotesLength))),
 		"tags":          validation.Validate(u.Tags, validation.Length(0, MaxTagsCount), validate.NoDuplicates(), validation.Each(is.UUID)),
+		"links":         validation.Validate(u.Links, validation.Length(0, MaxLinksCount)),
 	}.Filter()
 }
 
+// contactUpdateAliases maps the snake_case field names of ContactUpdatePayload to their camelCase name
+var contactUpdateAliases = jsoncase.AliasesOf(ContactUpdatePayload{})
+
+// UnmarshalJSON accepts the snake_case field names older clients send too
+func (u *ContactUpdatePayload) UnmarshalJSON(data []byte) error {
+	type payload ContactUpdatePayload
+	return contactUpdateAliases.Unmarshal(data, (*payload)(u))
+}
+
 // ToUpdatePayload converts a Contact to ContactUpdatePayload
 func (c *Contact) ToUpdatePayload() ContactUpdatePayload {
 	return ContactUpdatePayload{
@@ -112,7 +225,10 @@ func (c *Contact) ToUpdatePayload() ContactUpdatePayload {
 		City:          c.City,
 		StateProvince: c.StateProvince,
 		ZipPostalCode: c.ZipPostalCode,
+		Company:       c.Company,
+		Notes:         c.Notes,
 		Tags:          c.Tags,
+		Links:         c.Links,
 	}
 }
 
@@ -120,20 +236,185 @@ func (c *Contact) ToUpdatePayload() ContactUpdatePayload {
 // @Description Search parameters for filtering contacts
 type SearchParams struct {
 	types.SearchParams
-	SearchByPhone bool `json:"searchByPhone" example:"false" description:"Enable phone number search"`
+	SearchByPhone bool   `json:"searchByPhone" example:"false" description:"Enable phone number search"`
+	CompanyQuery  string `json:"companyQuery" example:"Acme" description:"Search contacts by company"`
 }
 
-func ParseAndValidateSearchParams(query url.Values) (SearchParams, error) {
+// SearchQueryParams lists the query parameters accepted when searching contacts
+var SearchQueryParams = []string{"q", "company_q", "by_phone", "limit", "next_token", types.ViewParam, types.TrimParam}
+
+func ParseAndValidateSearchParams(query url.Values, policy types.LimitPolicy) (SearchParams, error) {
 	var params SearchParams
-	searchParams, err := types.ParseAndValidateSearchParams(query)
+	searchParams, err := types.ParseAndValidateSearchParams(query, policy)
 	if err != nil {
 		return SearchParams{}, err
 	}
 	searchByPhone := query.Get("by_phone") == "true"
-	params.Limit = searchParams.Limit
-	params.Query = searchParams.Query
+	params.SearchParams = searchParams
 	params.SearchByPhone = searchByPhone
+	params.CompanyQuery = strings.TrimSpace(query.Get("company_q"))
+	if err := (validation.Errors{
+		"query":     validation.Validate(params.Query, validation.When(searchByPhone, validation.By(validatePhoneSearch))),
+		"company_q": validation.Validate(params.CompanyQuery, validation.Length(types.MinQueryLength, types.MaxQueryLength)),
+	}).Filter(); err != nil {
+		return params, err
+	}
+	// Name and company searches match by trigram similarity, phone searches by prefix
+	// where repeated digits are meaningful
+	if !searchByPhone {
+		if params.Query, err = types.NormalizeTrigramQuery(params.Query); err != nil {
+			return SearchParams{}, err
+		}
+	}
+	if params.CompanyQuery != "" {
+		if params.CompanyQuery, err = types.NormalizeTrigramQuery(params.CompanyQuery); err != nil {
+			return SearchParams{}, err
+		}
+	}
+	return params, nil
+}
+
+// CleanPhoneNumber removes the '+', '-' and space characters from a phone number,
+// the form phone numbers are stored and searched in
+func CleanPhoneNumber(phone string) string {
+	phone = strings.ReplaceAll(phone, "+", "")
+	phone = strings.ReplaceAll(phone, "-", "")
+	phone = strings.ReplaceAll(phone, " ", "")
+	return phone
+}
+
+// validatePhoneSearch accepts a run of at least MinPhoneSearchDigits digits with an
+// optional leading '+', the dashes and spaces dropped by CleanPhoneNumber are allowed
+// in between
+func validatePhoneSearch(value interface{}) error {
+	query, _ := value.(string)
+	rest := strings.TrimPrefix(query, "+")
+	if strings.Contains(rest, "+") {
+		return ErrPhoneSearchFormat
+	}
+	digits := CleanPhoneNumber(rest)
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return ErrPhoneSearchFormat
+		}
+	}
+	if len(digits) < MinPhoneSearchDigits {
+		return ErrPhoneSearchTooShort
+	}
+	return nil
+}
+
+// ContactSummary represents the minimal contact details listed under a company
+// @Description Contact summary listed under a company
+type ContactSummary struct {
+	ContactID uuid.UUID `json:"contactId" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
+	Name      string    `json:"name" example:"John Doe"`
+	Email     *string   `json:"email,omitempty" example:"john.doe@example.com" format:"email"`
+	Phone     *string   `json:"phone,omitempty" example:"+1-555-123-4567" format:"phone"`
+}
+
+// CompanyContacts represents a company with its contact count and first few contacts
+// @Description Company with the number of contacts belonging to it and a preview of those contacts
+type CompanyContacts struct {
+	Company      string           `json:"company" example:"Acme Inc."`
+	ContactCount int64            `json:"contactCount" example:"12"`
+	Contacts     []ContactSummary `json:"contacts"`
+}
+
+// CompanyCount represents a distinct company and how many contacts belong to it
+// @Description Distinct company name with the number of contacts belonging to it
+type CompanyCount struct {
+	Company      string `json:"company" example:"Acme Inc."`
+	ContactCount int64  `json:"contactCount" example:"12"`
+}
+
+// FacetFields are the contact fields the distinct values of can be counted
+var FacetFields = []string{"country", "city", "company"}
+
+// CompanyListParams represents the parameters for listing contacts grouped by company
+type CompanyListParams struct {
+	SortBy        string
+	Limit         int32
+	ContactsLimit int32
+}
+
+// CompanyListQueryParams lists the query parameters accepted when listing contacts by company
+var CompanyListQueryParams = []string{"sort", "limit", "contacts_limit"}
+
+// CompanyCountQueryParams lists the query parameters accepted when listing companies
+var CompanyCountQueryParams = []string{"limit"}
+
+// ParseCompanyListParams parses the sort, limit and contacts_limit query parameters
+func ParseCompanyListParams(query url.Values) (CompanyListParams, error) {
+	params := CompanyListParams{
+		SortBy:        CompanySortByCount,
+		Limit:         DefaultCompaniesLimit,
+		ContactsLimit: DefaultCompanyContactsLimit,
+	}
+
+	if sortBy := query.Get("sort"); sortBy != "" {
+		params.SortBy = sortBy
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		l, err := strconv.ParseInt(limitStr, 10, 32)
+		if err != nil {
+			return CompanyListParams{}, errors.New("limit: invalid format")
+		}
+		if l > MaxCompaniesLimit {
+			l = MaxCompaniesLimit
+		}
+		params.Limit = int32(l)
+	}
+
+	if limitStr := query.Get("contacts_limit"); limitStr != "" {
+		l, err := strconv.ParseInt(limitStr, 10, 32)
+		if err != nil {
+			return CompanyListParams{}, errors.New("contacts_limit: invalid format")
+		}
+		if l > MaxCompanyContactsLimit {
+			l = MaxCompanyContactsLimit
+		}
+		params.ContactsLimit = int32(l)
+	}
+
 	return params, validation.Errors{
-		"query": validation.Validate(params.Query, validation.When(searchByPhone, validate.PhoneNumber)),
+		"sort":           validation.Validate(params.SortBy, validation.In(CompanySortByCount, CompanySortByName)),
+		"limit":          validation.Validate(params.Limit, validation.Min(1)),
+		"contacts_limit": validation.Validate(params.ContactsLimit, validation.Min(1)),
+	}.Filter()
+}
+
+// ListQueryParams lists the query parameters accepted when listing contacts
+var ListQueryParams = append([]string{"city", "state_province"}, types.PaginationQueryParams...)
+
+// ContactFilter narrows a contact list, the zero value lists every contact
+type ContactFilter struct {
+	// City limits the list to the contacts in the city, ignoring case
+	City *string
+	// StateProvince limits the list to the contacts in the state or province, ignoring case
+	StateProvince *string
+}
+
+// NormalizePlace trims a city or state and collapses repeated whitespace, the way they
+// are stored
+func NormalizePlace(place string) string {
+	return strings.Join(strings.Fields(place), " ")
+}
+
+// ParseContactFilter parses the city and state_province query parameters, blank ones
+// don't filter
+func ParseContactFilter(query url.Values) (ContactFilter, error) {
+	var filter ContactFilter
+	if city := NormalizePlace(query.Get("city")); city != "" {
+		filter.City = &city
+	}
+	if state := NormalizePlace(query.Get("state_province")); state != "" {
+		filter.StateProvince = &state
+	}
+
+	return filter, validation.Errors{
+		"city":           validation.Validate(filter.City, validation.When(filter.City != nil, validation.Length(1, MaxAddressLength))),
+		"state_province": validation.Validate(filter.StateProvince, validation.When(filter.StateProvince != nil, validation.Length(1, MaxAddressLength))),
 	}.Filter()
 }
@@ -0,0 +1,170 @@
+package types
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	jobTypes "github.com/Abdelrahman-habib/expense-tracker/internal/jobs/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
+)
+
+// Media types contacts are exported in
+const (
+	ExportFormatCSV   = "text/csv"
+	ExportFormatJSON  = "application/json"
+	ExportFormatVCard = "text/vcard"
+)
+
+// ExportExtensions maps the media types of exports to the extension of their files
+var ExportExtensions = map[string]string{
+	ExportFormatCSV:   "csv",
+	ExportFormatJSON:  "json",
+	ExportFormatVCard: "vcf",
+}
+
+// ExportPolicy sets which exports are streamed right away and which have to run as
+// background jobs
+type ExportPolicy struct {
+	// SyncMaxRows is the most contacts GET /contacts/export streams, larger address
+	// books go through export jobs. 0 streams every export.
+	SyncMaxRows int
+}
+
+// ExportJobPayload is what a background export job needs to produce the file
+type ExportJobPayload struct {
+	// Format is the media type of the file, one of text/csv, application/json and text/vcard
+	Format string `json:"format"`
+}
+
+// ExportJob is a background export of the user's contacts, the download URL is set once
+// the file is ready
+// @Description Background contact export, with the link to download the file once it is completed
+type ExportJob struct {
+	jobTypes.Job
+	Format      string `json:"format" example:"text/csv" enums:"text/csv,application/json,text/vcard"`
+	DownloadURL string `json:"downloadUrl,omitempty" example:"/api/v1/contacts/export-jobs/123e4567-e89b-12d3-a456-426614174000/download"`
+}
+
+// CSVHeader is the header row of a contacts CSV export, in the column order of CSVRecord
+var CSVHeader = []string{
+	"contact_id",
+	"name",
+	"company",
+	"email",
+	"phone",
+	"address_line1",
+	"address_line2",
+	"city",
+	"state_province",
+	"zip_postal_code",
+	"country",
+	"tags",
+	"notes",
+	"created_at",
+	"updated_at",
+}
+
+// CSVRecord returns the contact as a CSV row, empty optional fields become empty cells
+// and tag IDs are joined with semicolons
+func (c Contact) CSVRecord() []string {
+	tags := make([]string, len(c.Tags))
+	for i, tag := range c.Tags {
+		tags[i] = tag.String()
+	}
+
+	return []string{
+		c.ContactID.String(),
+		c.Name,
+		utils.StringPtrToString(c.Company),
+		utils.StringPtrToString(c.Email),
+		utils.StringPtrToString(c.Phone),
+		utils.StringPtrToString(c.AddressLine1),
+		utils.StringPtrToString(c.AddressLine2),
+		utils.StringPtrToString(c.City),
+		utils.StringPtrToString(c.StateProvince),
+		utils.StringPtrToString(c.ZipPostalCode),
+		utils.StringPtrToString(c.Country),
+		strings.Join(tags, ";"),
+		utils.StringPtrToString(c.Notes),
+		c.CreatedAt.String(),
+		c.UpdatedAt.String(),
+	}
+}
+
+// vCardLineLength is the length in octets lines of a vCard are folded at
+const vCardLineLength = 75
+
+// VCard returns the contact as a vCard 4.0 (RFC 6350), lines end with CRLF and long
+// ones are folded. Empty optional fields are left out.
+func (c Contact) VCard() string {
+	var b strings.Builder
+	line := func(property, value string) {
+		writeVCardLine(&b, property+":"+value)
+	}
+
+	line("BEGIN", "VCARD")
+	line("VERSION", "4.0")
+	line("UID", "urn:uuid:"+c.ContactID.String())
+	line("FN", escapeVCard(c.Name))
+	if c.Company != nil {
+		line("ORG", escapeVCard(*c.Company))
+	}
+	if c.Email != nil {
+		line("EMAIL", escapeVCard(*c.Email))
+	}
+	if c.Phone != nil {
+		line("TEL;VALUE=text", escapeVCard(*c.Phone))
+	}
+
+	address := []*string{c.AddressLine2, c.AddressLine1, c.City, c.StateProvince, c.ZipPostalCode, c.Country}
+	hasAddress := false
+	components := make([]string, len(address))
+	for i, component := range address {
+		if component != nil {
+			components[i] = escapeVCard(*component)
+			hasAddress = true
+		}
+	}
+	if hasAddress {
+		// post office box, extended address, street, locality, region, postal code, country
+		line("ADR", ";"+strings.Join(components, ";"))
+	}
+
+	for _, link := range c.Links {
+		// URL values are URIs, which aren't escaped like text
+		line("URL;TYPE="+link.Label, link.URL)
+	}
+	if c.Notes != nil {
+		line("NOTE", escapeVCard(*c.Notes))
+	}
+	line("REV", c.UpdatedAt.UTC().Format("20060102T150405Z"))
+	line("END", "VCARD")
+	return b.String()
+}
+
+// vCardEscaper escapes the characters with a meaning in vCard text values
+var vCardEscaper = strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+// escapeVCard escapes a text value of a vCard
+func escapeVCard(value string) string {
+	return vCardEscaper.Replace(value)
+}
+
+// writeVCardLine writes a content line, folding it into continuation lines starting
+// with a space without splitting UTF-8 characters
+func writeVCardLine(b *strings.Builder, content string) {
+	limit := vCardLineLength
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		b.WriteString(content[:cut])
+		b.WriteString("\r\n ")
+		content = content[cut:]
+		// the leading space of continuation lines counts towards their length
+		limit = vCardLineLength - 1
+	}
+	b.WriteString(content)
+	b.WriteString("\r\n")
+}
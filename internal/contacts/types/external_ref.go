@@ -0,0 +1,84 @@
+package types
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/jsoncase"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/validate"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/google/uuid"
+)
+
+const (
+	MaxExternalSourceLength = 50
+	MaxExternalIDLength     = 255
+)
+
+// externalSourcePattern keeps source names to lowercase slugs like hubspot or sales-force
+var externalSourcePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ExternalRef identifies a contact in the system it is synced from, each reference
+// belongs to at most one contact of a user
+// @Description Source system and ID of a synced contact
+type ExternalRef struct {
+	Source     string `json:"source" example:"hubspot" maxLength:"50"`
+	ExternalID string `json:"externalId" example:"5012" maxLength:"255"`
+}
+
+// ParseExternalRef validates the source and external ID path parameters
+func ParseExternalRef(source, externalID string) (ExternalRef, error) {
+	if len(source) == 0 || len(source) > MaxExternalSourceLength || !externalSourcePattern.MatchString(source) {
+		return ExternalRef{}, fmt.Errorf("source: must be up to %d lowercase letters, digits, - or _", MaxExternalSourceLength)
+	}
+	if len(externalID) == 0 || len(externalID) > MaxExternalIDLength {
+		return ExternalRef{}, fmt.Errorf("external_id: must be 1 to %d characters", MaxExternalIDLength)
+	}
+	return ExternalRef{Source: source, ExternalID: externalID}, nil
+}
+
+// ContactUpsertPayload is what a sync sends for a contact, fields left out or null keep
+// their stored value. Creating a contact takes a name.
+// @Description Payload creating or updating the contact of an external reference
+type ContactUpsertPayload struct {
+	Name          *string     `json:"name,omitempty" example:"John Doe" minLength:"1" maxLength:"255"`
+	Phone         *string     `json:"phone,omitempty" example:"+1-555-123-4567" maxLength:"20" format:"phone"`
+	Email         *string     `json:"email,omitempty" example:"john.doe@example.com" format:"email"`
+	AddressLine1  *string     `json:"addressLine1,omitempty" example:"123 Main St" maxLength:"255"`
+	AddressLine2  *string     `json:"addressLine2,omitempty" example:"Suite 100" maxLength:"255"`
+	Country       *string     `json:"country,omitempty" example:"US" format:"iso-3166-1-alpha-2"`
+	City          *string     `json:"city,omitempty" example:"New York" maxLength:"255"`
+	StateProvince *string     `json:"stateProvince,omitempty" example:"NY" maxLength:"255"`
+	ZipPostalCode *string     `json:"zipPostalCode,omitempty" example:"10001" format:"zip-code"`
+	Company       *string     `json:"company,omitempty" example:"Acme Inc." maxLength:"255"`
+	Notes         *string     `json:"notes,omitempty" example:"Met at the spring trade show, prefers email" maxLength:"10000"`
+	Tags          []uuid.UUID `json:"tags,omitempty" example:"123e4567-e89b-12d3-a456-426614174000,123e4567-e89b-12d3-a456-426614174001"`
+}
+
+// Bind implements render.Binder interface and validates the fields the upsert payload carries
+func (u *ContactUpsertPayload) Bind(r *http.Request) error {
+	return validation.Errors{
+		"name":          validation.Validate(u.Name, validation.When(u.Name != nil, validation.Required, validation.Length(1, MaxNameLength))),
+		"email":         validation.Validate(u.Email, validation.When(u.Email != nil, validate.EmailFormat)),
+		"phone":         validation.Validate(u.Phone, validation.When(u.Phone != nil, validation.Length(1, MaxPhoneLength), validate.PhoneNumber)),
+		"country":       validation.Validate(u.Country, validation.When(u.Country != nil, is.CountryCode2)),
+		"zip_code":      validation.Validate(u.ZipPostalCode, validation.When(u.ZipPostalCode != nil, validate.Zipcode)),
+		"address_line1": validation.Validate(u.AddressLine1, validation.When(u.AddressLine1 != nil, validation.Length(1, MaxAddressLength))),
+		"address_line2": validation.Validate(u.AddressLine2, validation.When(u.AddressLine2 != nil, validation.Length(1, MaxAddressLength))),
+		"city":          validation.Validate(u.City, validation.When(u.City != nil, validation.Length(1, MaxAddressLength))),
+		"company":       validation.Validate(u.Company, validation.When(u.Company != nil, validation.Length(1, MaxCompanyLength))),
+		"notes":         validation.Validate(u.Notes, validation.When(u.Notes != nil, validation.Length(1, MaxNotesLength))),
+		"tags":          validation.Validate(u.Tags, validation.Length(0, MaxTagsCount), validate.NoDuplicates(), validation.Each(is.UUID)),
+	}.Filter()
+}
+
+// contactUpsertAliases maps the snake_case field names of ContactUpsertPayload to their camelCase name
+var contactUpsertAliases = jsoncase.AliasesOf(ContactUpsertPayload{})
+
+// UnmarshalJSON accepts the snake_case field names older clients send too
+func (u *ContactUpsertPayload) UnmarshalJSON(data []byte) error {
+	type payload ContactUpsertPayload
+	return contactUpsertAliases.Unmarshal(data, (*payload)(u))
+}
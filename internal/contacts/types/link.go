@@ -0,0 +1,41 @@
+package types
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/validate"
+)
+
+const (
+	// MaxLinksCount caps the number of links of a contact
+	MaxLinksCount = 10
+	// MaxLinkURLLength caps the length of a link's URL
+	MaxLinkURLLength = 2048
+)
+
+// Link labels, other covers the sites without a label of their own
+const (
+	LinkWebsite   = "website"
+	LinkLinkedIn  = "linkedin"
+	LinkX         = "x"
+	LinkGitHub    = "github"
+	LinkFacebook  = "facebook"
+	LinkInstagram = "instagram"
+	LinkOther     = "other"
+)
+
+// ContactLink is a website or social profile of a contact
+type ContactLink struct {
+	Label string `json:"label" example:"website" enums:"website,linkedin,x,github,facebook,instagram,other" validate:"required"`
+	URL   string `json:"url" example:"https://acme.example.com" maxLength:"2048" format:"uri" validate:"required"`
+}
+
+// Validate checks the label is a known one and the URL an absolute http or https one
+func (l ContactLink) Validate() error {
+	return validation.ValidateStruct(&l,
+		validation.Field(&l.Label, validation.Required, validation.In(
+			LinkWebsite, LinkLinkedIn, LinkX, LinkGitHub, LinkFacebook, LinkInstagram, LinkOther,
+		)),
+		validation.Field(&l.URL, validation.Required, validation.Length(1, MaxLinkURLLength), validate.WebURL),
+	)
+}
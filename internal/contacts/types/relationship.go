@@ -0,0 +1,92 @@
+package types
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/jsoncase"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+)
+
+// MaxRelationshipNoteLength caps the note of a relationship
+const MaxRelationshipNoteLength = 1000
+
+// Relationship types, a relationship reads contact <type> related contact
+const (
+	RelationshipWorksFor   = "works_for"
+	RelationshipReportsTo  = "reports_to"
+	RelationshipSpouseOf   = "spouse_of"
+	RelationshipRelativeOf = "relative_of"
+	RelationshipReferredBy = "referred_by"
+)
+
+// RelatedContact is what a relationship shows of the other contact
+type RelatedContact struct {
+	ContactID uuid.UUID `json:"contactId" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
+	Name      string    `json:"name" example:"Acme Supplies"`
+}
+
+// ContactRelationship links a contact to another one of the user's contacts
+// @Description Relationship between two contacts, outgoing ones read contact <type> related contact and incoming ones related contact <type> contact
+type ContactRelationship struct {
+	RelationshipID uuid.UUID `json:"relationshipId" example:"123e4567-e89b-12d3-a456-426614174002" format:"uuid"`
+	Type           string    `json:"type" example:"works_for" enums:"works_for,reports_to,spouse_of,relative_of,referred_by"`
+	// Outgoing is false when the relationship was created from the related contact
+	Outgoing  bool                `json:"outgoing" example:"true"`
+	Contact   RelatedContact      `json:"contact"`
+	Note      *string             `json:"note,omitempty" example:"Handles their tax filings" maxLength:"1000"`
+	CreatedAt coreTypes.Timestamp `json:"createdAt" example:"2024-01-01T00:00:00.000Z" swaggertype:"string" format:"date-time"`
+}
+
+// ContactRelationshipPayload represents the payload for relating a contact to another one
+// @Description Payload for relating the contact to another of the user's contacts
+type ContactRelationshipPayload struct {
+	ContactID uuid.UUID `json:"contactId" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid" validate:"required"`
+	Type      string    `json:"type" example:"works_for" enums:"works_for,reports_to,spouse_of,relative_of,referred_by" validate:"required"`
+	Note      *string   `json:"note,omitempty" example:"Handles their tax filings" maxLength:"1000"`
+}
+
+// Bind implements render.Binder interface and validates the relationship payload
+func (p *ContactRelationshipPayload) Bind(r *http.Request) error {
+	return validation.Errors{
+		"contact_id": validation.Validate(p.ContactID.String(), validation.NotIn(uuid.Nil.String()).Error("cannot be blank")),
+		"type": validation.Validate(p.Type, validation.Required, validation.In(
+			RelationshipWorksFor, RelationshipReportsTo, RelationshipSpouseOf, RelationshipRelativeOf, RelationshipReferredBy,
+		)),
+		"note": validation.Validate(p.Note, validation.When(p.Note != nil, validation.Length(1, MaxRelationshipNoteLength))),
+	}.Filter()
+}
+
+// contactRelationshipAliases maps the snake_case field names of ContactRelationshipPayload to their camelCase name
+var contactRelationshipAliases = jsoncase.AliasesOf(ContactRelationshipPayload{})
+
+// UnmarshalJSON accepts the snake_case field names older clients send too
+func (p *ContactRelationshipPayload) UnmarshalJSON(data []byte) error {
+	type payload ContactRelationshipPayload
+	return contactRelationshipAliases.Unmarshal(data, (*payload)(p))
+}
+
+// ContactExpand lists the related resources to include in a contact response
+type ContactExpand struct {
+	Relationships bool
+}
+
+// ParseContactExpand parses the comma separated expand query parameter, relationships is
+// the only resource that expands
+func ParseContactExpand(query url.Values) (ContactExpand, error) {
+	var expand ContactExpand
+	for _, name := range strings.Split(query.Get("expand"), ",") {
+		switch strings.TrimSpace(name) {
+		case "":
+		case "relationships":
+			expand.Relationships = true
+		default:
+			return expand, fmt.Errorf("expand: %q can't be expanded, expected relationships", name)
+		}
+	}
+	return expand, nil
+}
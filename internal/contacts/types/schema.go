@@ -0,0 +1,49 @@
+package types
+
+import (
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/schema"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/validate"
+)
+
+// SchemaVersion is bumped whenever a contact payload rule changes
+const SchemaVersion = 2
+
+// Schema describes the contact create and update payloads, mirroring their Bind rules
+func Schema() schema.Entity {
+	return schema.Entity{
+		Name:    "contacts",
+		Title:   "Contact payloads",
+		Version: SchemaVersion,
+		Create:  schema.Object(contactProperties(), "name"),
+		// the update is applied over the stored contact so every field is optional
+		Update: schema.Object(contactProperties()),
+	}
+}
+
+func contactProperties() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"name":          schema.String().Length(1, MaxNameLength),
+		"phone":         schema.String().Length(0, MaxPhoneLength).Match(validate.PhoneNumberPattern).Nullable(),
+		"email":         schema.String().As("email").Nullable(),
+		"addressLine1":  schema.String().Length(0, MaxAddressLength).Nullable(),
+		"addressLine2":  schema.String().Length(0, MaxAddressLength).Nullable(),
+		"country":       schema.In(schema.String(), validate.CountryCodes()...).Nullable(),
+		"city":          schema.String().Length(0, MaxAddressLength).Nullable(),
+		"stateProvince": schema.String().Nullable(),
+		"zipPostalCode": schema.String().Match(validate.ZipcodePattern).Nullable(),
+		"company":       schema.String().Length(0, MaxCompanyLength).Nullable(),
+		"notes":         schema.String().Length(0, MaxNotesLength).Nullable(),
+		"tags":          schema.Array(schema.UUID()).Count(0, MaxTagsCount).Unique().Nullable(),
+		"links":         schema.Array(linkSchema()).Count(0, MaxLinksCount).Nullable(),
+	}
+}
+
+// linkSchema describes a link, mirroring ContactLink.Validate
+func linkSchema() *schema.Schema {
+	return schema.Object(map[string]*schema.Schema{
+		"label": schema.In(schema.String(),
+			LinkWebsite, LinkLinkedIn, LinkX, LinkGitHub, LinkFacebook, LinkInstagram, LinkOther,
+		),
+		"url": schema.String().Length(1, MaxLinkURLLength).As("uri").Describe("absolute http or https URL"),
+	}, "label", "url")
+}
@@ -0,0 +1,116 @@
+// Package blob stores the files background jobs produce, such as contact exports,
+// until their owner downloads them
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotFound is returned when no blob is stored under a key
+var ErrNotFound = errors.New("blob not found")
+
+// Store keeps blobs under slash separated keys such as exports/<user>/<job>.csv
+type Store interface {
+	// Put stores what write writes under key, replacing any blob already there. The
+	// blob only becomes visible once write returns without an error.
+	Put(ctx context.Context, key string, write func(w io.Writer) error) error
+
+	// Open returns a reader of the blob stored under key, or ErrNotFound
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete removes the blob stored under key, deleting a missing blob is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// FileStore is a Store keeping each blob in a file under a directory
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore returns a store keeping its blobs under dir, creating it if needed
+func NewFileStore(dir string) (*FileStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("blob store directory is not set")
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("create blob store directory: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) Put(ctx context.Context, key string, write func(w io.Writer) error) (err error) {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o750); err != nil {
+		return fmt.Errorf("create blob directory: %w", err)
+	}
+
+	// written next to its final name so the rename doesn't cross file systems
+	file, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("create blob: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			file.Close()
+			os.Remove(file.Name())
+		}
+	}()
+
+	if err := write(file); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("write blob: %w", err)
+	}
+	if err := os.Rename(file.Name(), name); err != nil {
+		return fmt.Errorf("store blob: %w", err)
+	}
+	return nil
+}
+
+func (s *FileStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("open %s: %w", key, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open blob: %w", err)
+	}
+	return file, nil
+}
+
+func (s *FileStore) Delete(ctx context.Context, key string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("delete blob: %w", err)
+	}
+	return nil
+}
+
+// path returns the file of key, rejecting keys that would leave the store's directory
+func (s *FileStore) path(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || path.Clean(key) != key || strings.HasPrefix(key, "../") || key == ".." {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
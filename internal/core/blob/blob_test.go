@@ -0,0 +1,69 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	read := func(key string) string {
+		reader, err := store.Open(ctx, key)
+		require.NoError(t, err)
+		defer reader.Close()
+		content, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	t.Run("put, open and delete", func(t *testing.T) {
+		require.NoError(t, store.Put(ctx, "exports/user/job.csv", func(w io.Writer) error {
+			_, err := io.WriteString(w, "contact_id,name\n")
+			return err
+		}))
+		assert.Equal(t, "contact_id,name\n", read("exports/user/job.csv"))
+
+		require.NoError(t, store.Delete(ctx, "exports/user/job.csv"))
+		_, err := store.Open(ctx, "exports/user/job.csv")
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.NoError(t, store.Delete(ctx, "exports/user/job.csv"))
+	})
+
+	t.Run("a failed write leaves nothing behind", func(t *testing.T) {
+		require.NoError(t, store.Put(ctx, "kept.csv", func(w io.Writer) error {
+			_, err := io.WriteString(w, "first")
+			return err
+		}))
+
+		failure := errors.New("database went away")
+		err := store.Put(ctx, "kept.csv", func(w io.Writer) error {
+			_, _ = io.WriteString(w, "partial")
+			return failure
+		})
+		assert.ErrorIs(t, err, failure)
+		assert.Equal(t, "first", read("kept.csv"))
+
+		entries, err := os.ReadDir(store.dir)
+		require.NoError(t, err)
+		for _, entry := range entries {
+			assert.False(t, strings.HasPrefix(entry.Name(), ".upload-"), "temporary file %s left behind", entry.Name())
+		}
+	})
+
+	t.Run("keys stay inside the directory", func(t *testing.T) {
+		for _, key := range []string{"", "/etc/passwd", "../outside", "..", "a/../../b", "a//b"} {
+			_, err := store.Open(ctx, key)
+			assert.ErrorContains(t, err, "invalid blob key", key)
+		}
+	})
+}
@@ -0,0 +1,218 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultChunkSize  = 200
+	DefaultRetryDelay = 500 * time.Millisecond
+	// MaxRowErrors caps the per-row errors kept in a Progress
+	MaxRowErrors = 100
+)
+
+type Config struct {
+	// ChunkSize is the number of rows written per transaction
+	ChunkSize int
+	// MaxRetries is the number of times a failed chunk is retried
+	MaxRetries int
+	// RetryDelay is multiplied by the attempt number between retries
+	RetryDelay time.Duration
+}
+
+// TxBeginner starts the transactions chunks are written in
+type TxBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// RowError reports why the row at Index failed
+type RowError struct {
+	Index int    `json:"index" example:"3"`
+	Error string `json:"error" example:"name: cannot be blank."`
+}
+
+// Progress tracks how far a bulk write has gone
+type Progress struct {
+	Processed int        `json:"processed"`
+	Succeeded int        `json:"succeeded"`
+	Failed    int        `json:"failed"`
+	Errors    []RowError `json:"errors"`
+}
+
+// RowFunc writes the row at index using queries bound to the chunk transaction
+type RowFunc func(ctx context.Context, q *db.Queries, index int) error
+
+// ChunkFunc runs inside the chunk transaction once its rows are written, so
+// progress saved there commits together with the rows
+type ChunkFunc func(ctx context.Context, q *db.Queries, progress Progress) error
+
+type Engine struct {
+	db     TxBeginner
+	cfg    Config
+	logger *zap.Logger
+}
+
+func NewEngine(db TxBeginner, cfg Config, logger *zap.Logger) *Engine {
+	if cfg.ChunkSize <= 0 {
+		cfg.ChunkSize = DefaultChunkSize
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = DefaultRetryDelay
+	}
+
+	return &Engine{
+		db:     db,
+		cfg:    cfg,
+		logger: logger.With(zap.String("component", "bulk_engine")),
+	}
+}
+
+// Run writes rows [progress.Processed, total) in chunks, starting from the
+// given progress so an interrupted run can be resumed. A failing row is rolled
+// back on its own and recorded; a failing chunk is retried as a whole and
+// stops the run once its retries are exhausted.
+func (e *Engine) Run(ctx context.Context, total int, progress Progress, row RowFunc, onChunk ChunkFunc) (Progress, error) {
+	for progress.Processed < total {
+		end := min(progress.Processed+e.cfg.ChunkSize, total)
+
+		next, err := e.runChunkWithRetry(ctx, progress, end, row, onChunk)
+		if err != nil {
+			return progress, err
+		}
+		progress = next
+	}
+
+	return progress, nil
+}
+
+func (e *Engine) runChunkWithRetry(ctx context.Context, progress Progress, end int, row RowFunc, onChunk ChunkFunc) (Progress, error) {
+	var err error
+	for attempt := 0; attempt <= e.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			e.logger.Warn("retrying chunk",
+				zap.Int("start", progress.Processed),
+				zap.Int("end", end),
+				zap.Int("attempt", attempt),
+				zap.Error(err))
+
+			select {
+			case <-ctx.Done():
+				return progress, ctx.Err()
+			case <-time.After(e.cfg.RetryDelay * time.Duration(attempt)):
+			}
+		}
+
+		var next Progress
+		next, err = e.runChunk(ctx, progress, end, row, onChunk)
+		if err == nil {
+			return next, nil
+		}
+		if ctx.Err() != nil {
+			return progress, ctx.Err()
+		}
+	}
+
+	return progress, fmt.Errorf("chunk %d-%d failed after %d attempts: %w", progress.Processed, end, e.cfg.MaxRetries+1, err)
+}
+
+func (e *Engine) runChunk(ctx context.Context, progress Progress, end int, row RowFunc, onChunk ChunkFunc) (next Progress, err error) {
+	tx, err := e.db.Begin(ctx)
+	if err != nil {
+		return progress, fmt.Errorf("begin chunk: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	next = progress.clone()
+	for i := progress.Processed; i < end; i++ {
+		if err = ctx.Err(); err != nil {
+			return progress, err
+		}
+
+		var failure *rowFailure
+		if err = runRow(ctx, tx, i, row); errors.As(err, &failure) {
+			err = nil
+			next.record(i, failure.err)
+			continue
+		}
+		if err != nil {
+			return progress, err
+		}
+		next.record(i, nil)
+	}
+
+	if onChunk != nil {
+		if err = onChunk(ctx, db.New(tx), next); err != nil {
+			return progress, fmt.Errorf("save chunk progress: %w", err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return progress, fmt.Errorf("commit chunk: %w", err)
+	}
+
+	return next, nil
+}
+
+// rowFailure is the error of a row that failed on its own, its chunk goes on without it
+type rowFailure struct {
+	err error
+}
+
+func (f *rowFailure) Error() string { return f.err.Error() }
+
+func (f *rowFailure) Unwrap() error { return f.err }
+
+// runRow writes a single row inside a savepoint so a failing row does not
+// abort the rest of its chunk. The row's own error comes back as a *rowFailure,
+// any other error means the savepoint itself could not be managed.
+func runRow(ctx context.Context, tx pgx.Tx, index int, row RowFunc) error {
+	sp, err := tx.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin savepoint: %w", err)
+	}
+
+	if rowErr := row(ctx, db.New(sp), index); rowErr != nil {
+		if err := sp.Rollback(ctx); err != nil {
+			return fmt.Errorf("rollback savepoint: %w", err)
+		}
+		return &rowFailure{err: rowErr}
+	}
+
+	if err := sp.Commit(ctx); err != nil {
+		return fmt.Errorf("release savepoint: %w", err)
+	}
+
+	return nil
+}
+
+func (p Progress) clone() Progress {
+	p.Errors = append([]RowError(nil), p.Errors...)
+	return p
+}
+
+func (p *Progress) record(index int, err error) {
+	p.Processed++
+	if err == nil {
+		p.Succeeded++
+		return
+	}
+
+	p.Failed++
+	if len(p.Errors) < MaxRowErrors {
+		p.Errors = append(p.Errors, RowError{Index: index, Error: err.Error()})
+	}
+}
@@ -0,0 +1,182 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeTx stands in for both chunk transactions and row savepoints. The
+// embedded pgx.Tx is nil, so rows under test must not run real queries.
+type fakeTx struct {
+	pgx.Tx
+	db        *fakeDB
+	savepoint bool
+}
+
+func (t *fakeTx) Begin(ctx context.Context) (pgx.Tx, error) {
+	if t.db.failSavepoints > 0 {
+		t.db.failSavepoints--
+		return nil, errors.New("connection reset")
+	}
+	return &fakeTx{db: t.db, savepoint: true}, nil
+}
+
+func (t *fakeTx) Commit(ctx context.Context) error {
+	if t.savepoint {
+		return nil
+	}
+	if t.db.failCommits > 0 {
+		t.db.failCommits--
+		return errors.New("connection reset")
+	}
+	t.db.commits++
+	return nil
+}
+
+func (t *fakeTx) Rollback(ctx context.Context) error {
+	if !t.savepoint {
+		t.db.rollbacks++
+	}
+	return nil
+}
+
+type fakeDB struct {
+	commits        int
+	rollbacks      int
+	failCommits    int
+	failSavepoints int
+}
+
+func (d *fakeDB) Begin(ctx context.Context) (pgx.Tx, error) {
+	return &fakeTx{db: d}, nil
+}
+
+func newTestEngine(d *fakeDB, chunkSize, maxRetries int) *Engine {
+	return NewEngine(d, Config{ChunkSize: chunkSize, MaxRetries: maxRetries, RetryDelay: time.Millisecond}, zap.NewNop())
+}
+
+func TestEngine_RunChunks(t *testing.T) {
+	d := &fakeDB{}
+	engine := newTestEngine(d, 200, 0)
+
+	var saved []int
+	progress, err := engine.Run(context.Background(), 450, Progress{},
+		func(ctx context.Context, q *db.Queries, index int) error { return nil },
+		func(ctx context.Context, q *db.Queries, p Progress) error {
+			saved = append(saved, p.Processed)
+			return nil
+		})
+
+	require.NoError(t, err)
+	assert.Equal(t, []int{200, 400, 450}, saved)
+	assert.Equal(t, 3, d.commits)
+	assert.Equal(t, Progress{Processed: 450, Succeeded: 450}, progress)
+}
+
+func TestEngine_RunRowErrors(t *testing.T) {
+	engine := newTestEngine(&fakeDB{}, 50, 0)
+
+	progress, err := engine.Run(context.Background(), 250, Progress{},
+		func(ctx context.Context, q *db.Queries, index int) error {
+			if index%2 == 0 {
+				return fmt.Errorf("row %d is invalid", index)
+			}
+			return nil
+		}, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 250, progress.Processed)
+	assert.Equal(t, 125, progress.Succeeded)
+	assert.Equal(t, 125, progress.Failed)
+	assert.Len(t, progress.Errors, MaxRowErrors)
+	assert.Equal(t, RowError{Index: 0, Error: "row 0 is invalid"}, progress.Errors[0])
+}
+
+func TestEngine_RunSavepointError(t *testing.T) {
+	d := &fakeDB{failSavepoints: 1}
+	engine := newTestEngine(d, 50, 0)
+
+	progress, err := engine.Run(context.Background(), 10, Progress{},
+		func(ctx context.Context, q *db.Queries, index int) error { return nil }, nil)
+
+	// a savepoint that can't be managed fails the chunk, not just the row
+	assert.ErrorContains(t, err, "begin savepoint: connection reset")
+	assert.Equal(t, Progress{}, progress)
+	assert.Equal(t, 1, d.rollbacks)
+}
+
+func TestEngine_RunResume(t *testing.T) {
+	engine := newTestEngine(&fakeDB{}, 100, 0)
+
+	start := Progress{Processed: 200, Succeeded: 199, Failed: 1, Errors: []RowError{{Index: 7, Error: "bad row"}}}
+	first := -1
+	progress, err := engine.Run(context.Background(), 300, start,
+		func(ctx context.Context, q *db.Queries, index int) error {
+			if first < 0 {
+				first = index
+			}
+			return nil
+		}, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 200, first)
+	assert.Equal(t, 300, progress.Processed)
+	assert.Equal(t, 299, progress.Succeeded)
+	assert.Equal(t, 1, progress.Failed)
+	assert.Len(t, progress.Errors, 1)
+	// the starting progress is not mutated
+	assert.Equal(t, 200, start.Processed)
+}
+
+func TestEngine_RunRetriesChunk(t *testing.T) {
+	d := &fakeDB{failCommits: 1}
+	engine := newTestEngine(d, 10, 2)
+
+	calls := 0
+	progress, err := engine.Run(context.Background(), 10, Progress{},
+		func(ctx context.Context, q *db.Queries, index int) error {
+			calls++
+			return nil
+		}, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 20, calls, "the whole chunk is rewritten on retry")
+	assert.Equal(t, 1, d.commits)
+	assert.Equal(t, 1, d.rollbacks)
+	assert.Equal(t, Progress{Processed: 10, Succeeded: 10}, progress)
+}
+
+func TestEngine_RunRetriesExhausted(t *testing.T) {
+	d := &fakeDB{failCommits: 5}
+	engine := newTestEngine(d, 10, 1)
+
+	progress, err := engine.Run(context.Background(), 25, Progress{},
+		func(ctx context.Context, q *db.Queries, index int) error { return nil }, nil)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "chunk 0-10 failed after 2 attempts")
+	assert.Equal(t, Progress{}, progress)
+}
+
+func TestEngine_RunChunkProgressError(t *testing.T) {
+	d := &fakeDB{}
+	engine := newTestEngine(d, 10, 0)
+
+	progress, err := engine.Run(context.Background(), 10, Progress{},
+		func(ctx context.Context, q *db.Queries, index int) error { return nil },
+		func(ctx context.Context, q *db.Queries, p Progress) error { return errors.New("db error") })
+
+	require.Error(t, err)
+	assert.Equal(t, 0, d.commits)
+	assert.Equal(t, 1, d.rollbacks)
+	assert.Equal(t, Progress{}, progress)
+}
@@ -0,0 +1,194 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoize(t *testing.T) {
+	calls := 0
+	load := func() (int, error) {
+		calls++
+		return calls, nil
+	}
+
+	ctx := WithRequestCache(context.Background())
+	first, err := Memoize(ctx, "key", load)
+	require.NoError(t, err)
+	second, err := Memoize(ctx, "key", load)
+	require.NoError(t, err)
+	assert.Equal(t, 1, first)
+	assert.Equal(t, 1, second)
+
+	Invalidate(ctx)
+	third, err := Memoize(ctx, "key", load)
+	require.NoError(t, err)
+	assert.Equal(t, 2, third)
+
+	// without a request cache every call loads
+	_, _ = Memoize(context.Background(), "key", load)
+	_, _ = Memoize(context.Background(), "key", load)
+	assert.Equal(t, 4, calls)
+}
+
+func TestMemoize_ErrorsAreRetried(t *testing.T) {
+	calls := 0
+	failing := func() (int, error) {
+		calls++
+		return 0, errors.New("connection reset")
+	}
+
+	ctx := WithRequestCache(context.Background())
+	_, err := Memoize(ctx, "key", failing)
+	assert.Error(t, err)
+	_, err = Memoize(ctx, "key", failing)
+	assert.Error(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestMicroCache(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewMicroCache(200 * time.Millisecond)
+	c.now = func() time.Time { return now }
+
+	calls := 0
+	load := func() (int, error) {
+		calls++
+		return calls, nil
+	}
+
+	value, err := Remember(c, "projects:user:wallets:1", load)
+	require.NoError(t, err)
+	assert.Equal(t, 1, value)
+
+	now = now.Add(199 * time.Millisecond)
+	value, _ = Remember(c, "projects:user:wallets:1", load)
+	assert.Equal(t, 1, value, "served from the cache within the ttl")
+
+	now = now.Add(time.Millisecond)
+	value, _ = Remember(c, "projects:user:wallets:1", load)
+	assert.Equal(t, 2, value, "reloaded once expired")
+
+	c.Forget("projects:user:")
+	value, _ = Remember(c, "projects:user:wallets:1", load)
+	assert.Equal(t, 3, value, "reloaded once forgotten")
+}
+
+func TestMicroCache_Off(t *testing.T) {
+	c := NewMicroCache(0)
+	assert.Nil(t, c)
+
+	calls := 0
+	load := func() (int, error) {
+		calls++
+		return calls, nil
+	}
+	_, _ = Remember(c, "key", load)
+	_, _ = Remember(c, "key", load)
+	c.Forget("key")
+	assert.Equal(t, 2, calls)
+}
+
+func TestCoalesce(t *testing.T) {
+	var c Coalescer
+	var calls atomic.Int32
+	release := make(chan struct{})
+	load := func(ctx context.Context) ([]int, error) {
+		calls.Add(1)
+		<-release
+		return []int{1, 2, 3}, ctx.Err()
+	}
+
+	const callers = 5
+	results := make([][]int, callers)
+	var wg sync.WaitGroup
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := Coalesce(context.Background(), &c, "key", load, slices.Clone[[]int])
+			assert.NoError(t, err)
+			results[i] = result
+		}()
+	}
+	// let every caller join the call in flight before it returns
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	results[0][0] = 42
+	for _, result := range results[1:] {
+		assert.Equal(t, []int{1, 2, 3}, result, "callers get their own copy")
+	}
+}
+
+func TestCoalesce_CanceledCaller(t *testing.T) {
+	var c Coalescer
+	release := make(chan struct{})
+	load := func(ctx context.Context) (int, error) {
+		<-release
+		return 1, ctx.Err()
+	}
+	identity := func(v int) int { return v }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	canceled := make(chan error)
+	go func() {
+		_, err := Coalesce(ctx, &c, "key", load, identity)
+		canceled <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	waiting := make(chan int)
+	go func() {
+		result, err := Coalesce(context.Background(), &c, "key", load, identity)
+		assert.NoError(t, err, "the leader giving up doesn't cancel the shared call")
+		waiting <- result
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	cancel()
+	assert.ErrorIs(t, <-canceled, context.Canceled)
+	close(release)
+	assert.Equal(t, 1, <-waiting)
+}
+
+func TestCoalesce_Deadline(t *testing.T) {
+	var c Coalescer
+	stopped := make(chan error, 1)
+	load := func(ctx context.Context) (int, error) {
+		// stands in for a query, which the driver cancels once its context is done
+		<-ctx.Done()
+		stopped <- ctx.Err()
+		return 0, ctx.Err()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := Coalesce(ctx, &c, "key", load, func(v int) int { return v })
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	select {
+	case err := <-stopped:
+		assert.ErrorIs(t, err, context.DeadlineExceeded, "the shared call keeps the caller's deadline")
+	case <-time.After(time.Second):
+		t.Fatal("the shared call outlived the caller's deadline")
+	}
+}
+
+func TestCoalesce_Nil(t *testing.T) {
+	result, err := Coalesce(context.Background(), nil, "key", func(context.Context) (int, error) {
+		return 1, nil
+	}, func(v int) int { return v })
+	require.NoError(t, err)
+	assert.Equal(t, 1, result)
+}
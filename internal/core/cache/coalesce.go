@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"context"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Coalescer shares one call between identical reads in flight at the same time, like
+// the searches fired while a user types. Keys must name the user and every parameter
+// of the read so callers only ever share results meant for them. The zero value is ready
+// to use.
+type Coalescer struct {
+	group singleflight.Group
+}
+
+// Coalesce calls load once for all the callers asking for key while it runs. Each caller
+// gets its own copy of the result made by clone. load runs without the callers'
+// cancelation so one of them giving up doesn't fail the others, each caller still
+// returns as soon as its own context is done. The deadline of the caller starting the
+// call is kept, so a search bounded by a timeout stops in the database once it's past.
+// A nil coalescer always loads.
+func Coalesce[T any](ctx context.Context, c *Coalescer, key string, load func(context.Context) (T, error), clone func(T) T) (T, error) {
+	if c == nil {
+		return load(ctx)
+	}
+
+	results := c.group.DoChan(key, func() (any, error) {
+		if ctx.Done() == nil {
+			return load(ctx)
+		}
+		shared := context.WithoutCancel(ctx)
+		if deadline, ok := ctx.Deadline(); ok {
+			var cancel context.CancelFunc
+			shared, cancel = context.WithDeadline(shared, deadline)
+			defer cancel()
+		}
+		return load(shared)
+	})
+
+	select {
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	case res := <-results:
+		if res.Err != nil {
+			var zero T
+			return zero, res.Err
+		}
+		return clone(res.Val.(T)), nil
+	}
+}
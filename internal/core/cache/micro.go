@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// MicroCache shares the results of heavy reads between requests arriving within a
+// fraction of a second of each other, like rapid dashboard refreshes. Keys must name
+// the user and the entity so results are never shared across users.
+type MicroCache struct {
+	ttl     time.Duration
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[string]microEntry
+}
+
+type microEntry struct {
+	value     any
+	expiresAt time.Time
+}
+
+// NewMicroCache creates a micro-cache keeping results for ttl, it returns nil when
+// ttl isn't positive which turns the caching off
+func NewMicroCache(ttl time.Duration) *MicroCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &MicroCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: map[string]microEntry{},
+	}
+}
+
+// Remember returns the result stored for key if it hasn't expired, otherwise it calls
+// load and stores what it returns. Errors aren't stored and a nil cache always loads.
+func Remember[T any](c *MicroCache, key string, load func() (T, error)) (T, error) {
+	if c == nil {
+		return load()
+	}
+
+	now := c.now()
+	c.mu.Lock()
+	entry, found := c.entries[key]
+	c.mu.Unlock()
+	if found && now.Before(entry.expiresAt) {
+		return entry.value.(T), nil
+	}
+
+	result, err := load()
+	if err != nil {
+		return result, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// sweep the expired entries on writes so keys that aren't read again don't pile up
+	for k, e := range c.entries {
+		if !now.Before(e.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = microEntry{value: result, expiresAt: now.Add(c.ttl)}
+	return result, nil
+}
+
+// Forget drops the results whose keys start with prefix, writes call it with the
+// user's prefix so their next read isn't served a stale result
+func (c *MicroCache) Forget(prefix string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k := range c.entries {
+		if strings.HasPrefix(k, prefix) {
+			delete(c.entries, k)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"context"
+	"sync"
+
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+)
+
+// requestCache holds the results of the reads made while handling one request
+type requestCache struct {
+	mu      sync.Mutex
+	entries map[string]any
+}
+
+// WithRequestCache returns a context memoizing repository reads for the rest of the request
+func WithRequestCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, requestcontext.RequestCacheKey, &requestCache{entries: map[string]any{}})
+}
+
+// Memoize returns the result load produced for key earlier in the request, calling it
+// the first time. Errors aren't kept so a failed read is retried, and without a request
+// cache in the context load always runs.
+func Memoize[T any](ctx context.Context, key string, load func() (T, error)) (T, error) {
+	c, ok := ctx.Value(requestcontext.RequestCacheKey).(*requestCache)
+	if !ok {
+		return load()
+	}
+
+	c.mu.Lock()
+	value, found := c.entries[key]
+	c.mu.Unlock()
+	if found {
+		return value.(T), nil
+	}
+
+	result, err := load()
+	if err != nil {
+		return result, err
+	}
+	c.mu.Lock()
+	c.entries[key] = result
+	c.mu.Unlock()
+	return result, nil
+}
+
+// Invalidate drops everything memoized so far in the request, writes call it so the
+// reads after them see their changes
+func Invalidate(ctx context.Context) {
+	c, ok := ctx.Value(requestcontext.RequestCacheKey).(*requestCache)
+	if !ok {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+}
@@ -0,0 +1,65 @@
+// Package currency converts amounts between currencies for the endpoints that total
+// wallets of different currencies
+package currency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrRateUnavailable is returned when there is no exchange rate between two currencies
+var ErrRateUnavailable = errors.New("exchange rate unavailable")
+
+// Converter gives the exchange rates between currencies
+type Converter interface {
+	// Rate returns how many units of to a unit of from is worth, or ErrRateUnavailable
+	Rate(ctx context.Context, from, to string) (float64, error)
+}
+
+// StaticRates is a Converter with fixed rates, each currency's rate is the worth of
+// one of its units in the base currency
+type StaticRates struct {
+	base  string
+	rates map[string]float64
+}
+
+// NewStaticRates returns a converter between base and the currencies of rates, the
+// currency codes are matched regardless of case
+func NewStaticRates(base string, rates map[string]float64) (*StaticRates, error) {
+	base = strings.ToUpper(strings.TrimSpace(base))
+	if base == "" {
+		return nil, fmt.Errorf("the base currency of the exchange rates is not set")
+	}
+
+	normalized := make(map[string]float64, len(rates)+1)
+	for code, rate := range rates {
+		if rate <= 0 {
+			return nil, fmt.Errorf("invalid exchange rate %g for %s, expected more than 0", rate, code)
+		}
+		normalized[strings.ToUpper(strings.TrimSpace(code))] = rate
+	}
+	if rate, ok := normalized[base]; ok && rate != 1 {
+		return nil, fmt.Errorf("invalid exchange rate %g for the base currency %s, expected 1", rate, base)
+	}
+	normalized[base] = 1
+
+	return &StaticRates{base: base, rates: normalized}, nil
+}
+
+func (s *StaticRates) Rate(ctx context.Context, from, to string) (float64, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == to {
+		return 1, nil
+	}
+	fromRate, ok := s.rates[from]
+	if !ok {
+		return 0, fmt.Errorf("%s to %s: %w", from, to, ErrRateUnavailable)
+	}
+	toRate, ok := s.rates[to]
+	if !ok {
+		return 0, fmt.Errorf("%s to %s: %w", from, to, ErrRateUnavailable)
+	}
+	return fromRate / toRate, nil
+}
@@ -0,0 +1,46 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticRates(t *testing.T) {
+	ctx := context.Background()
+	// keys come lower cased out of the config file
+	rates, err := NewStaticRates("usd", map[string]float64{"eur": 1.25, "GBP": 1.5, "USD": 1})
+	require.NoError(t, err)
+
+	tests := []struct {
+		from, to string
+		expected float64
+	}{
+		{"EUR", "USD", 1.25},
+		{"USD", "EUR", 0.8},
+		{"GBP", "EUR", 1.2},
+		{"jpy", "JPY", 1},
+	}
+	for _, tt := range tests {
+		rate, err := rates.Rate(ctx, tt.from, tt.to)
+		require.NoError(t, err)
+		assert.InDelta(t, tt.expected, rate, 1e-12, "%s to %s", tt.from, tt.to)
+	}
+
+	_, err = rates.Rate(ctx, "JPY", "USD")
+	assert.True(t, errors.Is(err, ErrRateUnavailable))
+	_, err = rates.Rate(ctx, "USD", "JPY")
+	assert.True(t, errors.Is(err, ErrRateUnavailable))
+
+	t.Run("invalid rates", func(t *testing.T) {
+		_, err := NewStaticRates("", map[string]float64{"EUR": 1.1})
+		assert.Error(t, err)
+		_, err = NewStaticRates("USD", map[string]float64{"EUR": 0})
+		assert.Error(t, err)
+		_, err = NewStaticRates("USD", map[string]float64{"usd": 2})
+		assert.Error(t, err)
+	})
+}
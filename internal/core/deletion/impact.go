@@ -0,0 +1,104 @@
+package deletion
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/google/uuid"
+)
+
+// Effect is what deleting a resource does to the rows it affects
+type Effect string
+
+const (
+	// EffectTrashed rows go to the trash along with the resource
+	EffectTrashed Effect = "trashed"
+	// EffectDetached rows stay live and lose their link to the resource, at the latest
+	// when it is purged
+	EffectDetached Effect = "detached"
+	// EffectKept rows stay with the trashed resource, they come back when it is restored
+	// and go when it is purged
+	EffectKept Effect = "kept"
+	// EffectBlocked rows keep the delete from going through
+	EffectBlocked Effect = "blocked"
+)
+
+// Balance is the total balance of affected wallets in one currency
+// @Description Balance of the affected wallets in a currency
+type Balance struct {
+	Currency string  `json:"currency" example:"USD" format:"iso-4217"`
+	Balance  float64 `json:"balance" example:"1250.75"`
+}
+
+// Affected is one kind of row a delete affects
+// @Description Rows of one kind a delete affects and what happens to them
+type Affected struct {
+	Kind     string    `json:"kind" example:"wallets"`
+	Count    int64     `json:"count" example:"2"`
+	Effect   Effect    `json:"effect" example:"kept" enums:"trashed,detached,kept,blocked"`
+	Balances []Balance `json:"balances,omitempty"`
+}
+
+// Impact is the blast radius of deleting a resource
+// @Description What deleting a resource affects and whether the delete is blocked
+type Impact struct {
+	Resource string     `json:"resource" example:"project"`
+	ID       uuid.UUID  `json:"id" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
+	Blocked  bool       `json:"blocked" example:"false"`
+	Reasons  []string   `json:"reasons,omitempty"`
+	Affected []Affected `json:"affected"`
+}
+
+// Add records rows the delete affects
+func (i *Impact) Add(affected Affected) {
+	i.Affected = append(i.Affected, affected)
+}
+
+// Block marks the delete as blocked for the reason
+func (i *Impact) Block(reason string) {
+	i.Blocked = true
+	i.Reasons = append(i.Reasons, reason)
+}
+
+// Err returns the conflict a blocked delete answers with, nil when nothing blocks it
+func (i Impact) Err() error {
+	if !i.Blocked {
+		return nil
+	}
+	return errors.NewConflictError("%s", strings.Join(i.Reasons, "; "))
+}
+
+// Check adds what a delete does to one kind of row to the impact, or blocks it. O carries
+// the options of the delete, like what happens to a project's sub-projects.
+type Check[O any] func(ctx context.Context, userID, id uuid.UUID, opts O, impact *Impact) error
+
+// Registry lists the checks of a resource's delete. Previews and deletes run the same
+// checks, so a preview tells what the delete will do; a new kind of child row only needs
+// a new check.
+type Registry[O any] struct {
+	resource string
+	checks   []Check[O]
+}
+
+func NewRegistry[O any](resource string) *Registry[O] {
+	return &Registry[O]{resource: resource}
+}
+
+// Register appends checks, they run in the order they were registered
+func (r *Registry[O]) Register(checks ...Check[O]) *Registry[O] {
+	r.checks = append(r.checks, checks...)
+	return r
+}
+
+// Assess runs the checks and returns the impact of deleting the resource, stopping at the
+// first check that fails
+func (r *Registry[O]) Assess(ctx context.Context, userID, id uuid.UUID, opts O) (Impact, error) {
+	impact := Impact{Resource: r.resource, ID: id, Affected: []Affected{}}
+	for _, check := range r.checks {
+		if err := check(ctx, userID, id, opts, &impact); err != nil {
+			return Impact{}, err
+		}
+	}
+	return impact, nil
+}
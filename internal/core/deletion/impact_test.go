@@ -0,0 +1,73 @@
+package deletion
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	coreErrors "github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_Assess(t *testing.T) {
+	ctx := context.Background()
+	userID, id := uuid.New(), uuid.New()
+
+	t.Run("runs the checks in order", func(t *testing.T) {
+		registry := NewRegistry[bool]("project").Register(
+			func(_ context.Context, gotUser, gotID uuid.UUID, cascade bool, impact *Impact) error {
+				assert.Equal(t, userID, gotUser)
+				assert.Equal(t, id, gotID)
+				impact.Add(Affected{Kind: "projects", Count: 1, Effect: EffectTrashed})
+				return nil
+			},
+			func(_ context.Context, _, _ uuid.UUID, cascade bool, impact *Impact) error {
+				if !cascade {
+					impact.Add(Affected{Kind: "subProjects", Count: 2, Effect: EffectBlocked})
+					impact.Block("project has 2 sub-project(s)")
+				}
+				return nil
+			},
+		)
+
+		impact, err := registry.Assess(ctx, userID, id, false)
+		require.NoError(t, err)
+		assert.Equal(t, "project", impact.Resource)
+		assert.Equal(t, id, impact.ID)
+		assert.True(t, impact.Blocked)
+		assert.Equal(t, []string{"project has 2 sub-project(s)"}, impact.Reasons)
+		assert.Equal(t, []string{"projects", "subProjects"}, []string{impact.Affected[0].Kind, impact.Affected[1].Kind})
+
+		err = impact.Err()
+		assert.True(t, coreErrors.IsErrorType(err, coreErrors.ErrorTypeConflict))
+		assert.EqualError(t, err, "project has 2 sub-project(s)")
+
+		impact, err = registry.Assess(ctx, userID, id, true)
+		require.NoError(t, err)
+		assert.False(t, impact.Blocked)
+		assert.NoError(t, impact.Err())
+	})
+
+	t.Run("stops at the first failure", func(t *testing.T) {
+		called := false
+		registry := NewRegistry[struct{}]("wallet").Register(
+			func(context.Context, uuid.UUID, uuid.UUID, struct{}, *Impact) error {
+				return errors.New("connection reset")
+			},
+			func(context.Context, uuid.UUID, uuid.UUID, struct{}, *Impact) error { called = true; return nil },
+		)
+
+		_, err := registry.Assess(ctx, userID, id, struct{}{})
+		assert.EqualError(t, err, "connection reset")
+		assert.False(t, called)
+	})
+
+	t.Run("nothing affected", func(t *testing.T) {
+		impact, err := NewRegistry[struct{}]("wallet").Assess(ctx, userID, id, struct{}{})
+		require.NoError(t, err)
+		assert.Empty(t, impact.Affected)
+		assert.NotNil(t, impact.Affected)
+	})
+}
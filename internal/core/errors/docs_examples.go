@@ -10,6 +10,24 @@ type errInvalidRequest struct {
 	ErrorText string    `json:"error" example:"invalid request format"`
 }
 
+// ExpiredCursorError represents an expired pagination token response
+type errExpiredCursor struct {
+	Type      ErrorType `json:"type" example:"EXPIRED_CURSOR"`
+	Message   string    `json:"message" example:"Pagination token expired"`
+	Code      int       `json:"code" example:"400"`
+	ErrorText string    `json:"error" example:"next_token has expired"`
+	Hint      string    `json:"hint" example:"restart the pagination without next_token"`
+}
+
+// QueryTooComplexError represents a search query too costly to match
+type errQueryTooComplex struct {
+	Type      ErrorType `json:"type" example:"QUERY_TOO_COMPLEX"`
+	Message   string    `json:"message" example:"Query too complex"`
+	Code      int       `json:"code" example:"400"`
+	ErrorText string    `json:"error" example:"query too complex, search with fewer or shorter words"`
+	Hint      string    `json:"hint" example:"search with fewer or shorter words"`
+}
+
 // AuthorizationError represents an authorization error response
 type errAuthorization struct {
 	Type      ErrorType `json:"type" example:"AUTHORIZATION_ERROR"`
@@ -26,12 +44,29 @@ type errNotFound struct {
 	ErrorText string    `json:"error" example:"Resource not found"`
 }
 
+// MethodNotAllowedError represents a method not allowed error response
+type errMethodNotAllowed struct {
+	Type      ErrorType `json:"type" example:"METHOD_NOT_ALLOWED"`
+	Message   string    `json:"message" example:"Method not allowed"`
+	Code      int       `json:"code" example:"405"`
+	ErrorText string    `json:"error" example:"method PATCH not allowed on /api/v1/wallets"`
+}
+
+// NotAcceptableError represents a not acceptable error response
+type errNotAcceptable struct {
+	Type      ErrorType `json:"type" example:"NOT_ACCEPTABLE"`
+	Message   string    `json:"message" example:"Not acceptable"`
+	Code      int       `json:"code" example:"406"`
+	ErrorText string    `json:"error" example:"none of application/xml can be served, available: text/csv, application/json"`
+}
+
 // InternalError represents an internal server error response
 type errInternal struct {
 	Type      ErrorType `json:"type" example:"INTERNAL_ERROR"`
 	Message   string    `json:"message" example:"Internal server error"`
 	Code      int       `json:"code" example:"500"`
 	ErrorText string    `json:"error" example:"database connection failed"`
+	Meta      ErrorMeta `json:"meta"`
 }
 
 // DatabaseError represents a database error response
@@ -40,6 +75,7 @@ type errDatabase struct {
 	Message   string    `json:"message" example:"Database error occurred"`
 	Code      int       `json:"code" example:"500"`
 	ErrorText string    `json:"error" example:"failed to execute database query"`
+	Meta      ErrorMeta `json:"meta"`
 }
 
 // ExternalServiceError represents an external service error response
@@ -48,6 +84,7 @@ type errExternalService struct {
 	Message   string    `json:"message" example:"External service error"`
 	Code      int       `json:"code" example:"502"`
 	ErrorText string    `json:"error" example:"external API request failed"`
+	Meta      ErrorMeta `json:"meta"`
 }
 
 // RenderError represents a render error response
@@ -82,10 +119,20 @@ type errRateLimit struct {
 	ErrorText string    `json:"error" example:"rate limit exceeded"`
 }
 
+// OverloadedError represents a load shedding error response
+type errOverloaded struct {
+	Type      ErrorType `json:"type" example:"OVERLOADED"`
+	Message   string    `json:"message" example:"Service overloaded"`
+	Code      int       `json:"code" example:"503"`
+	ErrorText string    `json:"error" example:"too many requests in flight, retry later"`
+	Meta      ErrorMeta `json:"meta"`
+}
+
 // UnsupportedError represents an unsupported operation error response
 type errUnsupported struct {
 	Type      ErrorType `json:"type" example:"UNSUPPORTED_ERROR"`
 	Message   string    `json:"message" example:"Unsupported operation"`
 	Code      int       `json:"code" example:"501"`
 	ErrorText string    `json:"error" example:"feature not implemented"`
+	Meta      ErrorMeta `json:"meta"`
 }
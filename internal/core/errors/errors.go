@@ -1,36 +1,70 @@
 package errors
 
 import (
+	stdErrors "errors"
 	"fmt"
 	"net/http"
 
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/version"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
 	"github.com/go-chi/render"
 )
 
 type ErrorType string
 
 const (
-	ErrorTypeValidation      ErrorType = "VALIDATION_ERROR"
-	ErrorTypeDatabase        ErrorType = "DATABASE_ERROR"
-	ErrorTypeAuthorization   ErrorType = "AUTHORIZATION_ERROR"
-	ErrorTypeNotFound        ErrorType = "NOT_FOUND"
-	ErrorTypeInternal        ErrorType = "INTERNAL_ERROR"
-	ErrorTypeExternalService ErrorType = "EXTERNAL_SERVICE"
-	ErrorTypeRender          ErrorType = "RENDER_ERROR"
-	ErrorTypeForbidden       ErrorType = "FORBIDDEN"
-	ErrorTypeConflict        ErrorType = "CONFLICT"
-	ErrorTypeRateLimit       ErrorType = "RATE_LIMIT"
-	ErrorTypeUnsupported     ErrorType = "UNSUPPORTED_ERROR"
+	ErrorTypeValidation         ErrorType = "VALIDATION_ERROR"
+	ErrorTypeDatabase           ErrorType = "DATABASE_ERROR"
+	ErrorTypeAuthorization      ErrorType = "AUTHORIZATION_ERROR"
+	ErrorTypeNotFound           ErrorType = "NOT_FOUND"
+	ErrorTypeMethodNotAllowed   ErrorType = "METHOD_NOT_ALLOWED"
+	ErrorTypeInternal           ErrorType = "INTERNAL_ERROR"
+	ErrorTypeExternalService    ErrorType = "EXTERNAL_SERVICE"
+	ErrorTypeRender             ErrorType = "RENDER_ERROR"
+	ErrorTypeForbidden          ErrorType = "FORBIDDEN"
+	ErrorTypeConflict           ErrorType = "CONFLICT"
+	ErrorTypeRateLimit          ErrorType = "RATE_LIMIT"
+	ErrorTypeUnsupported        ErrorType = "UNSUPPORTED_ERROR"
+	ErrorTypeExpiredCursor      ErrorType = "EXPIRED_CURSOR"
+	ErrorTypeCursorMismatch     ErrorType = "CURSOR_MISMATCH"
+	ErrorTypeNotAcceptable      ErrorType = "NOT_ACCEPTABLE"
+	ErrorTypeOverloaded         ErrorType = "OVERLOADED"
+	ErrorTypePayloadShape       ErrorType = "INVALID_PAYLOAD_SHAPE"
+	ErrorTypeEmailSuspect       ErrorType = "EMAIL_DOMAIN_SUSPECT"
+	ErrorTypeQuotaExceeded      ErrorType = "QUOTA_EXCEEDED"
+	ErrorTypePreconditionFailed ErrorType = "PRECONDITION_FAILED"
+	ErrorTypeIDMismatch         ErrorType = "ID_MISMATCH"
+	// ErrorTypeConcurrentModification is a write that timed out waiting for another write
+	// of the same entity
+	ErrorTypeConcurrentModification ErrorType = "CONCURRENT_MODIFICATION"
+	// ErrorTypeQueryTooComplex is a search query too costly to match
+	ErrorTypeQueryTooComplex ErrorType = "QUERY_TOO_COMPLEX"
 )
 
 // ErrorResponse represents an application error
 // @Description Application error response
 type ErrorResponse struct {
 	Type      ErrorType `json:"type"`
-	Message   string    `json:"message" example:"Invalid request parameters" enums:"Invalid request parameters,Authorization failed,Resource not found,Internal server error,Database error occurred,External service error,Error rendering response,Access forbidden,Resource conflict,Too many requests,Unsupported operation"`
+	Message   string    `json:"message" example:"Invalid request parameters" enums:"Invalid request parameters,Authorization failed,Resource not found,Method not allowed,Internal server error,Database error occurred,External service error,Error rendering response,Access forbidden,Resource conflict,Too many requests,Unsupported operation,Not acceptable,Service overloaded,Precondition failed,ID mismatch,Concurrent modification"`
 	Err       error     `json:"-"` // Internal error details (not exposed to client)
-	Code      int       `json:"code,omitempty" example:"400" enums:"400,401,404,500,502,422,403,409,429,501"`
+	Code      int       `json:"code,omitempty" example:"400" enums:"400,401,404,405,406,500,502,422,403,409,412,429,501,503"`
 	ErrorText string    `json:"error,omitempty" example:"field: required"`
+	// Hint tells the client how to recover from the error
+	Hint string `json:"hint,omitempty" example:"restart the pagination without next_token"`
+	// Suggestion is the corrected value of the field the error is about
+	Suggestion string `json:"suggestion,omitempty" example:"jane@gmail.com"`
+	// Meta is only set on server errors and when the request raised warnings
+	Meta *ErrorMeta `json:"meta,omitempty"`
+}
+
+// ErrorMeta carries what a bug report about a server error needs, along with the
+// warnings raised before the request failed
+type ErrorMeta struct {
+	// Version is the version of the server that failed, set on server errors only
+	Version string `json:"version,omitempty" example:"v1.2.0"`
+	// Warnings are the non-fatal problems found with the request
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 func (e *ErrorResponse) Error() string {
@@ -40,8 +74,36 @@ func (e *ErrorResponse) Error() string {
 	return e.Message
 }
 
+// Unwrap returns the internal error so errors.Is and errors.As see through the response
+func (e *ErrorResponse) Unwrap() error {
+	return e.Err
+}
+
+// Is matches not found and conflict errors with the repository sentinels, so callers
+// check errors.Is(err, repository.ErrNotFound) whichever layer built the error
+func (e *ErrorResponse) Is(target error) bool {
+	switch target {
+	case repository.ErrNotFound:
+		return e.Type == ErrorTypeNotFound
+	case repository.ErrConflict:
+		return e.Type == ErrorTypeConflict
+	}
+	return false
+}
+
+// Render sets the status of the response, server errors also name the version that
+// failed so bug reports carry it. The warnings collected for the request are kept so
+// a client fixing the error sees them too.
 func (e *ErrorResponse) Render(w http.ResponseWriter, r *http.Request) error {
 	render.Status(r, e.Code)
+	warnings := requestcontext.GetWarningsFromContext(r.Context())
+	if e.Code < http.StatusInternalServerError && len(warnings) == 0 {
+		return nil
+	}
+	e.Meta = &ErrorMeta{Warnings: warnings}
+	if e.Code >= http.StatusInternalServerError {
+		e.Meta.Version = version.Version
+	}
 	return nil
 }
 
@@ -54,6 +116,27 @@ func ErrNotFound() render.Renderer {
 	}
 }
 
+func ErrMethodNotAllowed(err error) render.Renderer {
+	return &ErrorResponse{
+		Type:      ErrorTypeMethodNotAllowed,
+		Message:   "Method not allowed",
+		Err:       err,
+		Code:      http.StatusMethodNotAllowed,
+		ErrorText: err.Error(),
+	}
+}
+
+// ErrNotAcceptable reports that none of the formats the request accepts can be served
+func ErrNotAcceptable(err error) render.Renderer {
+	return &ErrorResponse{
+		Type:      ErrorTypeNotAcceptable,
+		Message:   "Not acceptable",
+		Err:       err,
+		Code:      http.StatusNotAcceptable,
+		ErrorText: err.Error(),
+	}
+}
+
 func ErrValidation(err error) render.Renderer {
 	return &ErrorResponse{
 		Type:      ErrorTypeValidation,
@@ -114,6 +197,58 @@ func ErrInvalidRequest(err error) render.Renderer {
 	}
 }
 
+// ErrCursorMismatch is returned for a next_token issued for another sort order or other
+// filters than the request's, the client either sends them back or starts over
+func ErrCursorMismatch(err error) render.Renderer {
+	return &ErrorResponse{
+		Type:      ErrorTypeCursorMismatch,
+		Message:   "Pagination token mismatch",
+		Err:       err,
+		Code:      http.StatusBadRequest,
+		ErrorText: err.Error(),
+		Hint:      "send the next_token with the query parameters it was issued for, or restart the pagination without it",
+	}
+}
+
+// ErrInvalidPayloadShape is returned for a body that isn't the JSON object the endpoint
+// takes, such as an array sent to a single entity endpoint
+func ErrInvalidPayloadShape(err error) render.Renderer {
+	return &ErrorResponse{
+		Type:      ErrorTypePayloadShape,
+		Message:   "Invalid payload shape",
+		Err:       err,
+		Code:      http.StatusBadRequest,
+		ErrorText: err.Error(),
+		Hint:      "send a single JSON object with the fields of the entity",
+	}
+}
+
+// ErrIDMismatch is returned for an update whose body names another entity than its URL,
+// the URL alone decides which entity is updated
+func ErrIDMismatch(err error) render.Renderer {
+	return &ErrorResponse{
+		Type:      ErrorTypeIDMismatch,
+		Message:   "ID mismatch",
+		Err:       err,
+		Code:      http.StatusBadRequest,
+		ErrorText: err.Error(),
+		Hint:      "send the update to the URL of the entity, or leave its ID out of the body",
+	}
+}
+
+// ErrExpiredCursor is returned for a next_token past its TTL or in a format no longer
+// accepted, the client has to start over from the first page
+func ErrExpiredCursor(err error) render.Renderer {
+	return &ErrorResponse{
+		Type:      ErrorTypeExpiredCursor,
+		Message:   "Pagination token expired",
+		Err:       err,
+		Code:      http.StatusBadRequest,
+		ErrorText: err.Error(),
+		Hint:      "restart the pagination without next_token",
+	}
+}
+
 func ErrRender(err error) render.Renderer {
 	return &ErrorResponse{
 		Type:      ErrorTypeRender,
@@ -144,6 +279,45 @@ func ErrConflict(err error) render.Renderer {
 	}
 }
 
+// ErrConcurrentModification is returned for an update that waited too long for another
+// update of the same entity to finish, the client can retry it
+func ErrConcurrentModification(err error) render.Renderer {
+	return &ErrorResponse{
+		Type:      ErrorTypeConcurrentModification,
+		Message:   "Concurrent modification",
+		Err:       err,
+		Code:      http.StatusConflict,
+		ErrorText: err.Error(),
+		Hint:      "another update of the entity is in progress, retry the request",
+	}
+}
+
+// ErrQueryTooComplex is returned for a search query with too many distinct trigrams or
+// one the search timed out matching
+func ErrQueryTooComplex(err error) render.Renderer {
+	return &ErrorResponse{
+		Type:      ErrorTypeQueryTooComplex,
+		Message:   "Query too complex",
+		Err:       err,
+		Code:      http.StatusBadRequest,
+		ErrorText: err.Error(),
+		Hint:      "search with fewer or shorter words",
+	}
+}
+
+// ErrPreconditionFailed is returned when the If-Match header of a request doesn't match
+// the current version of the entity it changes, the client saw an older version
+func ErrPreconditionFailed(err error) render.Renderer {
+	return &ErrorResponse{
+		Type:      ErrorTypePreconditionFailed,
+		Message:   "Precondition failed",
+		Err:       err,
+		Code:      http.StatusPreconditionFailed,
+		ErrorText: err.Error(),
+		Hint:      "fetch the entity again to review its changes and get its current ETag",
+	}
+}
+
 func ErrRateLimit(err error) render.Renderer {
 	return &ErrorResponse{
 		Type:      ErrorTypeRateLimit,
@@ -154,6 +328,18 @@ func ErrRateLimit(err error) render.Renderer {
 	}
 }
 
+// ErrOverloaded answers requests shed while the server is at capacity, the client
+// should retry later
+func ErrOverloaded(err error) render.Renderer {
+	return &ErrorResponse{
+		Type:      ErrorTypeOverloaded,
+		Message:   "Service overloaded",
+		Err:       err,
+		Code:      http.StatusServiceUnavailable,
+		ErrorText: err.Error(),
+	}
+}
+
 func ErrUnsupported(err error) render.Renderer {
 	return &ErrorResponse{
 		Type:      ErrorTypeUnsupported,
@@ -164,9 +350,76 @@ func ErrUnsupported(err error) render.Renderer {
 	}
 }
 
+// NewEmailDomainSuspectError creates the error of an email address whose domain looks
+// like a typo of a common one, suggestion is the address with the domain meant. Unlike
+// the other service errors it is rendered as it is, for the client to offer the suggestion.
+func NewEmailDomainSuspectError(field, email, suggestion string) error {
+	err := fmt.Errorf("%s: %s looks like a typo, did you mean %s?", field, email, suggestion)
+	return &ErrorResponse{
+		Type:       ErrorTypeEmailSuspect,
+		Message:    "Suspect email domain",
+		Err:        err,
+		Code:       http.StatusBadRequest,
+		ErrorText:  err.Error(),
+		Hint:       "send the suggested address, or check the domain of the address",
+		Suggestion: suggestion,
+	}
+}
+
+// NewQuotaExceededError creates the error of a create that would take the user past the
+// quota of a resource. Like NewEmailDomainSuspectError it is rendered as it is.
+func NewQuotaExceededError(resource string, limit int64) error {
+	err := fmt.Errorf("%s: the quota of %d is reached", resource, limit)
+	return &ErrorResponse{
+		Type:      ErrorTypeQuotaExceeded,
+		Message:   "Resource quota exceeded",
+		Err:       err,
+		Code:      http.StatusForbidden,
+		ErrorText: err.Error(),
+		Hint:      "delete some " + resource + ", those in the trash don't count toward the quota",
+	}
+}
+
+// IsErrorType reports whether err, or an error it wraps, is an ErrorResponse of the type
 func IsErrorType(err error, errorType ErrorType) bool {
-	if appErr, ok := err.(*ErrorResponse); ok {
+	var appErr *ErrorResponse
+	if stdErrors.As(err, &appErr) {
 		return appErr.Type == errorType
 	}
 	return false
 }
+
+// NewValidationError creates a validation error for services to return when
+// a request is well formed but breaks a business rule
+func NewValidationError(format string, args ...interface{}) error {
+	return &ErrorResponse{
+		Type:    ErrorTypeValidation,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// NewNotFoundError creates a not found error for services to return when a
+// resource the request refers to doesn't exist or belongs to another user
+func NewNotFoundError(format string, args ...interface{}) error {
+	return &ErrorResponse{
+		Type:    ErrorTypeNotFound,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// NewForbiddenError creates a forbidden error for services to return when the
+// caller may not do what the request asks
+func NewForbiddenError(format string, args ...interface{}) error {
+	return &ErrorResponse{
+		Type:    ErrorTypeForbidden,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// NewConflictError creates a conflict error for writes that collide with existing data
+func NewConflictError(format string, args ...interface{}) error {
+	return &ErrorResponse{
+		Type:    ErrorTypeConflict,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
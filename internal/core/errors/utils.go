@@ -4,8 +4,30 @@ import (
 	"fmt"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
+// TagOwnershipViolationCode is the SQLSTATE raised by owned_tags when strict
+// tag ownership is on and a write carries tags the user doesn't own
+const TagOwnershipViolationCode = "ET001"
+
+// AnonymizedUserCode is the SQLSTATE raised by block_anonymized_pii when a write
+// would put PII back on the rows of an anonymized user
+const AnonymizedUserCode = "ET002"
+
+// UniqueViolationCode is the SQLSTATE raised when a write collides with a unique index
+const UniqueViolationCode = "23505"
+
+// LockNotAvailableCode is the SQLSTATE raised when a lock isn't granted within the
+// lock_timeout of the transaction
+const LockNotAvailableCode = "55P03"
+
+// IsUniqueViolation reports whether err is a collision with the named unique index
+func IsUniqueViolation(err error, index string) bool {
+	pgErr, ok := err.(*pgconn.PgError)
+	return ok && pgErr.Code == UniqueViolationCode && pgErr.ConstraintName == index
+}
+
 // handleRepositoryError is a helper function to handle common database errors
 func HandleRepositoryError(err error, operation, repoName string) error {
 	if err == pgx.ErrNoRows {
@@ -15,6 +37,34 @@ func HandleRepositoryError(err error, operation, repoName string) error {
 			Err:     err,
 		}
 	}
+	if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == TagOwnershipViolationCode {
+		return &ErrorResponse{
+			Type:    ErrorTypeValidation,
+			Message: fmt.Sprintf("Failed to %s %s: tags not owned by user", operation, repoName),
+			Err:     err,
+		}
+	}
+	if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == AnonymizedUserCode {
+		return &ErrorResponse{
+			Type:    ErrorTypeForbidden,
+			Message: fmt.Sprintf("Failed to %s %s: user data is anonymized", operation, repoName),
+			Err:     err,
+		}
+	}
+	if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == LockNotAvailableCode {
+		return &ErrorResponse{
+			Type:    ErrorTypeConcurrentModification,
+			Message: fmt.Sprintf("Failed to %s %s: modified concurrently", operation, repoName),
+			Err:     err,
+		}
+	}
+	if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == UniqueViolationCode {
+		return &ErrorResponse{
+			Type:    ErrorTypeConflict,
+			Message: fmt.Sprintf("Failed to %s %s: already exists", operation, repoName),
+			Err:     err,
+		}
+	}
 	return &ErrorResponse{
 		Type:    ErrorTypeDatabase,
 		Message: fmt.Sprintf("Failed to %s %s", operation, repoName),
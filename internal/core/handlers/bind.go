@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/jsoncase"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+)
+
+// ErrResponded is returned by the callbacks a handler hands to its service, such as the
+// merges binding an update onto the current values, that already responded to the
+// request. The handler returns without responding again.
+var ErrResponded = stdErrors.New("responded to the request")
+
+// Bind decodes and validates the JSON object of a single entity request into payload. It
+// responds with INVALID_PAYLOAD_SHAPE when the body is an array, a scalar or null, or a
+// 400 for an invalid payload, and returns false.
+func (h *BaseHandler) Bind(w http.ResponseWriter, r *http.Request, payload render.Binder) bool {
+	return h.bind(w, r, payload, false)
+}
+
+// BindUpdate binds an update onto payload, which holds the entity's current values so
+// the fields left out keep them. On top of Bind it refuses objects naming none of the
+// payload's fields, a client sending them meant another payload and would update nothing.
+// The empty object is only refused when the server rejects empty updates.
+func (h *BaseHandler) BindUpdate(w http.ResponseWriter, r *http.Request, payload render.Binder) bool {
+	return h.bind(w, r, payload, true)
+}
+
+// BindEntityUpdate binds the update of the entity the URL names by pathID. The URL is
+// the only source of the entity's ID: the body may repeat it under idField, or its
+// snake_case name, but an ID naming another entity is refused with ID_MISMATCH. A null,
+// empty or nil UUID counts as left out. id, the payload's ID field, is set to pathID.
+func (h *BaseHandler) BindEntityUpdate(w http.ResponseWriter, r *http.Request, payload render.Binder, idField string, pathID uuid.UUID, id *uuid.UUID) bool {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return false
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	bodyID, err := payloadID(body, idField)
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return false
+	}
+	if bodyID != uuid.Nil && bodyID != pathID {
+		h.RespondError(w, r, errors.ErrIDMismatch(
+			fmt.Errorf("%s: %s doesn't match the ID %s of the URL", idField, bodyID, pathID)))
+		return false
+	}
+
+	*id = pathID
+	return h.bind(w, r, payload, true)
+}
+
+// payloadID returns the ID a JSON object holds under field or its snake_case name, uuid.Nil
+// when it holds none. Bodies that aren't JSON objects are left to the decoder.
+func payloadID(body []byte, field string) (uuid.UUID, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return uuid.Nil, nil
+	}
+	snake := jsoncase.ToSnake(field)
+	id := uuid.Nil
+	for name, value := range fields {
+		// the decoder matches names regardless of case
+		if !strings.EqualFold(name, field) && name != snake {
+			continue
+		}
+		var raw *string
+		if err := json.Unmarshal(value, &raw); err != nil {
+			return uuid.Nil, fmt.Errorf("%s: must be a UUID string", name)
+		}
+		if raw == nil || strings.TrimSpace(*raw) == "" {
+			continue
+		}
+		parsed, err := uuid.Parse(strings.TrimSpace(*raw))
+		if err != nil {
+			return uuid.Nil, fmt.Errorf("%s: %q is not a valid UUID", name, *raw)
+		}
+		if parsed == uuid.Nil {
+			continue
+		}
+		if id != uuid.Nil && id != parsed {
+			return uuid.Nil, fmt.Errorf("%s: conflicts with %s, send one of them", snake, field)
+		}
+		id = parsed
+	}
+	return id, nil
+}
+
+func (h *BaseHandler) bind(w http.ResponseWriter, r *http.Request, payload render.Binder, update bool) bool {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return false
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := checkPayloadShape(r, body, payload, update); err != nil {
+		h.RespondError(w, r, errors.ErrInvalidPayloadShape(err))
+		return false
+	}
+	if err := render.Bind(r, payload); err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return false
+	}
+	return true
+}
+
+// checkPayloadShape returns an error when body isn't a JSON object or, for updates, names
+// none of the payload's fields. Bodies that aren't valid JSON are left to the decoder.
+func checkPayloadShape(r *http.Request, body []byte, payload any, update bool) error {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || !json.Valid(trimmed) {
+		return nil
+	}
+	if trimmed[0] != '{' {
+		return fmt.Errorf("the payload must be a JSON object, got %s", jsonKind(trimmed[0]))
+	}
+	if !update {
+		return nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(trimmed, &fields); err != nil {
+		return nil
+	}
+	if len(fields) == 0 {
+		if requestcontext.GetRejectEmptyUpdatesFromContext(r.Context()) {
+			return fmt.Errorf("the payload names no field, send the fields to change")
+		}
+		return nil
+	}
+
+	known := payloadFields(payload)
+	unknown := make([]string, 0, len(fields))
+	for name := range fields {
+		if known[strings.ToLower(name)] {
+			return nil
+		}
+		unknown = append(unknown, name)
+	}
+	sort.Strings(unknown)
+	return fmt.Errorf("the payload names none of the fields this endpoint takes, got %s", strings.Join(unknown, ", "))
+}
+
+// jsonKind names the kind of JSON value starting with c
+func jsonKind(c byte) string {
+	switch c {
+	case '[':
+		return "an array"
+	case '"':
+		return "a string"
+	case 't', 'f':
+		return "a boolean"
+	case 'n':
+		return "null"
+	default:
+		return "a number"
+	}
+}
+
+// knownFields caches the field names of the payload types, by type
+var knownFields sync.Map
+
+// payloadFields returns the JSON names the fields of payload are decoded from, in both
+// the camelCase and the snake_case convention. They are lower cased since the decoder
+// matches names regardless of case.
+func payloadFields(payload any) map[string]bool {
+	t := reflect.TypeOf(payload)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if cached, ok := knownFields.Load(t); ok {
+		return cached.(map[string]bool)
+	}
+
+	names := map[string]bool{}
+	if t.Kind() == reflect.Struct {
+		for i := range t.NumField() {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			names[strings.ToLower(name)] = true
+			names[jsoncase.ToSnake(name)] = true
+		}
+	}
+	knownFields.Store(t, names)
+	return names
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type bindPayload struct {
+	Name         string  `json:"name"`
+	AddressLine1 *string `json:"addressLine1,omitempty"`
+	Internal     string  `json:"-"`
+}
+
+func (p *bindPayload) Bind(r *http.Request) error { return nil }
+
+func TestBaseHandler_Bind(t *testing.T) {
+	h := NewBaseHandler(zap.NewNop())
+
+	tests := []struct {
+		name         string
+		body         string
+		update       bool
+		rejectEmpty  bool
+		expectedType errors.ErrorType
+		expectedName string
+	}{
+		{name: "object", body: `{"name": "Jane"}`, expectedName: "Jane"},
+		{name: "array", body: `[{"name": "Jane"}]`, expectedType: errors.ErrorTypePayloadShape},
+		{name: "empty array", body: ` [] `, update: true, expectedType: errors.ErrorTypePayloadShape},
+		{name: "string", body: `"Jane"`, expectedType: errors.ErrorTypePayloadShape},
+		{name: "number", body: `42`, update: true, expectedType: errors.ErrorTypePayloadShape},
+		{name: "null", body: `null`, update: true, expectedType: errors.ErrorTypePayloadShape},
+		{name: "malformed JSON is left to the decoder", body: `{"name":`, expectedType: errors.ErrorTypeValidation},
+		{name: "create ignores unknown fields", body: `{"nom": "Jane"}`, expectedName: "current"},
+		{name: "update with only unknown fields", body: `{"nom": "Jane", "Internal": "x"}`, update: true, expectedType: errors.ErrorTypePayloadShape},
+		{name: "update with a known field among unknown ones", body: `{"nom": "Jane", "name": "Jane"}`, update: true, expectedName: "Jane"},
+		{name: "update matches names regardless of case", body: `{"NAME": "Jane"}`, update: true, expectedName: "Jane"},
+		{name: "update with a snake_case field", body: `{"address_line1": "1 Main St"}`, update: true, expectedName: "current"},
+		{name: "empty update keeps everything", body: `{}`, update: true, expectedName: "current"},
+		{name: "empty update refused", body: `{}`, update: true, rejectEmpty: true, expectedType: errors.ErrorTypePayloadShape},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/contacts/1", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+			if tt.rejectEmpty {
+				r = r.WithContext(context.WithValue(r.Context(), requestcontext.RejectEmptyUpdatesKey, true))
+			}
+			w := httptest.NewRecorder()
+
+			payload := bindPayload{Name: "current"}
+			var ok bool
+			if tt.update {
+				ok = h.BindUpdate(w, r, &payload)
+			} else {
+				ok = h.Bind(w, r, &payload)
+			}
+
+			if tt.expectedType == "" {
+				require.True(t, ok, w.Body.String())
+				assert.Equal(t, tt.expectedName, payload.Name)
+				return
+			}
+			require.False(t, ok)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			var response errors.ErrorResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+			assert.Equal(t, tt.expectedType, response.Type)
+			assert.Equal(t, "current", payload.Name, "a refused body changes nothing")
+		})
+	}
+}
+
+type entityPayload struct {
+	WalletID uuid.UUID `json:"-"`
+	Name     string    `json:"name"`
+}
+
+func (p *entityPayload) Bind(r *http.Request) error { return nil }
+
+func TestBaseHandler_BindEntityUpdate(t *testing.T) {
+	h := NewBaseHandler(zap.NewNop())
+	pathID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	otherID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174001")
+
+	tests := []struct {
+		name         string
+		body         string
+		expectedType errors.ErrorType
+	}{
+		{name: "no ID in the body", body: `{"name": "Savings"}`},
+		{name: "matching ID", body: `{"walletId": "` + pathID.String() + `", "name": "Savings"}`},
+		{name: "matching snake_case ID", body: `{"wallet_id": "` + pathID.String() + `", "name": "Savings"}`},
+		{name: "nil UUID", body: `{"walletId": "00000000-0000-0000-0000-000000000000", "name": "Savings"}`},
+		{name: "empty string", body: `{"walletId": "", "name": "Savings"}`},
+		{name: "null", body: `{"walletId": null, "name": "Savings"}`},
+		{name: "conflicting ID", body: `{"walletId": "` + otherID.String() + `", "name": "Savings"}`, expectedType: errors.ErrorTypeIDMismatch},
+		{name: "conflicting ID regardless of case", body: `{"WALLETID": "` + otherID.String() + `", "name": "Savings"}`, expectedType: errors.ErrorTypeIDMismatch},
+		{name: "invalid ID", body: `{"walletId": "wallet-1", "name": "Savings"}`, expectedType: errors.ErrorTypeValidation},
+		{name: "ID that isn't a string", body: `{"walletId": 1, "name": "Savings"}`, expectedType: errors.ErrorTypeValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/wallets/"+pathID.String(), strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			payload := entityPayload{Name: "current"}
+			ok := h.BindEntityUpdate(w, r, &payload, "walletId", pathID, &payload.WalletID)
+
+			if tt.expectedType == "" {
+				require.True(t, ok, w.Body.String())
+				assert.Equal(t, pathID, payload.WalletID)
+				assert.Equal(t, "Savings", payload.Name)
+				return
+			}
+			require.False(t, ok)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			var response errors.ErrorResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+			assert.Equal(t, tt.expectedType, response.Type)
+			assert.Equal(t, uuid.Nil, payload.WalletID)
+			assert.Equal(t, "current", payload.Name, "a refused body changes nothing")
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// csvFlushEvery is the number of rows written between flushes of a CSV stream
+const csvFlushEvery = 100
+
+// StreamCSV writes a CSV response row by row as stream produces them. Nothing is sent
+// before the first row, so a failure up to then still gets a regular error response;
+// once rows are out the status can't change, the error is logged and the body cut short.
+func (h *BaseHandler) StreamCSV(w http.ResponseWriter, r *http.Request, header []string, stream func(write func(record []string) error) error) {
+	writer := csv.NewWriter(w)
+	controller := http.NewResponseController(w)
+	started := false
+	rows := 0
+
+	start := func() error {
+		started = true
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		return writer.Write(header)
+	}
+
+	err := stream(func(record []string) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+		rows++
+		if rows%csvFlushEvery == 0 {
+			writer.Flush()
+			// writers that can't flush just buffer, the rows still go out at the end
+			controller.Flush()
+		}
+		return writer.Error()
+	})
+	if err != nil {
+		if !started {
+			h.HandleServiceError(w, r, err)
+			return
+		}
+		h.logger.Error("csv stream interrupted", zap.Int("rows", rows), zap.Error(err))
+		writer.Flush()
+		return
+	}
+
+	if !started {
+		if err := start(); err != nil {
+			h.logger.Error("failed to write csv header", zap.Error(err))
+			return
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		h.logger.Error("failed to write csv", zap.Error(err))
+	}
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+)
+
+// ETag is the entity tag of a version of an entity, derived from its updatedAt so it
+// changes with every update. Timestamps are kept to the microsecond the database stores.
+func ETag(updatedAt time.Time) string {
+	return `"` + strconv.FormatInt(updatedAt.UnixMicro(), 10) + `"`
+}
+
+// SetETag sets the ETag header of the entity's version on the response
+func SetETag(w http.ResponseWriter, updatedAt time.Time) {
+	w.Header().Set("ETag", ETag(updatedAt))
+}
+
+// CheckIfMatch honors the If-Match header of a request changing an entity. Without the
+// header it returns true. Otherwise current loads the entity's updatedAt, and when no tag
+// of the header matches its ETag it responds with a 412 and returns false, as it does
+// with the service error when the entity can't be loaded. The version returned is the
+// updatedAt the header matched, the change must be made on the condition the entity is
+// still at it so an update committed in between fails it too. It is nil without the
+// header or when the header matches any version.
+func (h *BaseHandler) CheckIfMatch(w http.ResponseWriter, r *http.Request, current func() (time.Time, error)) (*time.Time, bool) {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		return nil, true
+	}
+	updatedAt, err := current()
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return nil, false
+	}
+	etag := ETag(updatedAt)
+	tags := ifMatchTags(header)
+	if slices.Contains(tags, "*") {
+		return nil, true
+	}
+	if !slices.Contains(tags, etag) {
+		h.RespondError(w, r, errors.ErrPreconditionFailed(
+			fmt.Errorf("If-Match: the current version is %s", etag)))
+		return nil, false
+	}
+	return &updatedAt, true
+}
+
+// ifMatchTags returns the tags an If-Match header lists. If-Match compares strongly, weak
+// tags are kept as they are and never match.
+func ifMatchTags(header string) []string {
+	tags := strings.Split(header, ",")
+	for i, tag := range tags {
+		tags[i] = strings.TrimSpace(tag)
+	}
+	return tags
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/config"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/go-chi/render"
+)
+
+// RequireFeature hides routes behind a feature flag, answering 404 while the flag is off
+// so unreleased endpoints look like they don't exist
+func RequireFeature(features config.FeaturesConfig, flag string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !features.Enabled(flag) {
+				render.Render(w, r, errors.ErrNotFound())
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Abdelrahman-habib/expense-tracker/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireFeature(t *testing.T) {
+	features := config.FeaturesConfig{"transactions": true, "reports": false}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name           string
+		flag           string
+		expectedStatus int
+	}{
+		{name: "enabled flag", flag: "transactions", expectedStatus: http.StatusOK},
+		{name: "flag names ignore case", flag: "Transactions", expectedStatus: http.StatusOK},
+		{name: "disabled flag", flag: "reports", expectedStatus: http.StatusNotFound},
+		{name: "unknown flag", flag: "budgets", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			RequireFeature(features, tt.flag)(ok).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/transactions", nil))
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
@@ -1,11 +1,19 @@
 package handlers
 
 import (
+	"context"
+	stdErrors "errors"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
 	"github.com/go-chi/render"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
@@ -22,6 +30,10 @@ func NewBaseHandler(logger *zap.Logger) BaseHandler {
 
 // Respond is a helper function to handle all responses
 func (h *BaseHandler) Respond(w http.ResponseWriter, r *http.Request, renderer render.Renderer) {
+	if resp, ok := renderer.(*payloads.Response); ok {
+		resp.Meta.Warnings = append(resp.Meta.Warnings, requestcontext.GetWarningsFromContext(r.Context())...)
+		resp.SetLinks(r)
+	}
 	if err := render.Render(w, r, renderer); err != nil {
 		h.logger.Error("failed to render response", zap.Error(err))
 		render.Render(w, r, errors.ErrRender(err))
@@ -52,10 +64,187 @@ func (h *BaseHandler) RespondError(w http.ResponseWriter, r *http.Request, err i
 	render.Render(w, r, errors.ErrInternal(fmt.Errorf("unexpected error type: %v", err)))
 }
 
-func (h *BaseHandler) HandleServiceError(w http.ResponseWriter, r *http.Request, err error) {
-	if errors.IsErrorType(err, errors.ErrorTypeNotFound) {
-		h.RespondError(w, r, errors.ErrNotFound())
+// CheckQueryParams looks for query parameters outside the allowed list. In strict mode it
+// responds with a 400 and returns false, otherwise the unknown parameters are reported as
+// a warning in the response meta
+func (h *BaseHandler) CheckQueryParams(w http.ResponseWriter, r *http.Request, allowed ...string) bool {
+	err := types.ValidateQueryParams(r.URL.Query(), allowed...)
+	if err == nil {
+		return true
+	}
+	if types.QueryParamsMode(requestcontext.GetQueryParamsModeFromContext(r.Context())) == types.QueryParamsStrict {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return false
+	}
+	if !requestcontext.AddWarning(r.Context(), err.Error()) {
+		h.logger.Warn("unknown query parameters", zap.String("path", r.URL.Path), zap.Error(err))
+	}
+	return true
+}
+
+// ParsePagination parses the pagination parameters of a list requested by userID, filters
+// names the query parameters the list is filtered by. It responds with EXPIRED_CURSOR for
+// a stale next_token, CURSOR_MISMATCH for one issued for other filters or a 400 for other
+// invalid parameters and returns false. A limit above the policy's is lowered to it with
+// a warning in the response meta.
+func (h *BaseHandler) ParsePagination(w http.ResponseWriter, r *http.Request, policy types.LimitPolicy, userID uuid.UUID, filters ...string) (types.PaginationParams, bool) {
+	params, err := types.ParsePaginationParams(r.URL.Query(), policy, userID, filters...)
+	if params.LimitCapped {
+		requestcontext.AddWarning(r.Context(), fmt.Sprintf("limit: capped at %d", policy.MaxLimit))
+	}
+	if stdErrors.Is(err, types.ErrExpiredCursor) {
+		h.RespondError(w, r, errors.ErrExpiredCursor(err))
+		return params, false
+	}
+	if stdErrors.Is(err, types.ErrCursorMismatch) {
+		h.RespondError(w, r, errors.ErrCursorMismatch(err))
+		return params, false
+	}
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return params, false
+	}
+	return params, true
+}
+
+// ParseStream reports whether a paginated list is to be streamed as NDJSON, asked for
+// with stream=true or an Accept header ranking application/x-ndjson above
+// application/json. It responds with a 400 and returns false for a stream parameter
+// that isn't a boolean.
+func (h *BaseHandler) ParseStream(w http.ResponseWriter, r *http.Request) (stream bool, ok bool) {
+	if value := r.URL.Query().Get(types.StreamParam); value != "" {
+		stream, err := strconv.ParseBool(value)
+		if err != nil {
+			h.RespondError(w, r, errors.ErrInvalidRequest(fmt.Errorf("%s: must be true or false", types.StreamParam)))
+			return false, false
+		}
+		return stream, true
+	}
+	// an Accept header the list can't satisfy gets the JSON it always did
+	mediaType, err := NegotiateFormat(r, "", MediaTypeJSON, MediaTypeNDJSON)
+	return err == nil && mediaType == MediaTypeNDJSON, true
+}
+
+// ParseOnlyIDs reports whether a paginated list is to return only the IDs of its page,
+// asked for with only_ids=true. It responds with a 400 and returns false for an only_ids
+// parameter that isn't a boolean or when the page is also to be streamed.
+func (h *BaseHandler) ParseOnlyIDs(w http.ResponseWriter, r *http.Request, stream bool) (onlyIDs bool, ok bool) {
+	value := r.URL.Query().Get(types.OnlyIDsParam)
+	if value == "" {
+		return false, true
+	}
+	onlyIDs, err := strconv.ParseBool(value)
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(fmt.Errorf("%s: must be true or false", types.OnlyIDsParam)))
+		return false, false
+	}
+	if onlyIDs && stream {
+		h.RespondError(w, r, errors.ErrInvalidRequest(fmt.Errorf("%s: not supported when streaming", types.OnlyIDsParam)))
+		return false, false
+	}
+	return onlyIDs, true
+}
+
+// RespondIDs responds with the IDs of a page of a paginated list requested by userID,
+// along with the token of the next page when the page is full
+func (h *BaseHandler) RespondIDs(w http.ResponseWriter, r *http.Request, params types.PaginationParams, keys []types.KeyedID, userID uuid.UUID) {
+	var nextToken string
+	if len(keys) > 0 && len(keys) == int(params.Limit) {
+		nextToken = params.NextKeyedToken(keys[len(keys)-1], userID)
+	}
+	h.Respond(w, r, payloads.PaginatedIDs(types.KeyedIDs(keys), nextToken, params.Limit))
+}
+
+// ParseIDs parses the ids query parameter of a list fetching entities by ID, responding
+// with a 400 and returning false when it is blank, holds more than types.MaxBatchIDs IDs
+// or one that isn't a UUID
+func (h *BaseHandler) ParseIDs(w http.ResponseWriter, r *http.Request) ([]uuid.UUID, bool) {
+	ids, err := types.ParseIDs(r.URL.Query().Get(types.IDsQueryParam))
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return nil, false
+	}
+	return ids, true
+}
+
+// CheckSearchWindow bounds the search to the configured window, responding with a 400
+// and returning false when the cursor pages past it. A window of 0 or less leaves the
+// search unbounded.
+func (h *BaseHandler) CheckSearchWindow(w http.ResponseWriter, r *http.Request, params *types.SearchParams) bool {
+	window, ok := requestcontext.GetMaxSearchWindowFromContext(r.Context())
+	if !ok {
+		window = types.DefaultMaxSearchWindow
+	}
+	if window <= 0 {
+		return true
+	}
+	if err := params.ApplyWindow(window); err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return false
+	}
+	return true
+}
+
+// RespondInvalidSearch responds with a 400 to search parameters that failed to parse, a
+// query with too many distinct trigrams is told apart as too complex
+func (h *BaseHandler) RespondInvalidSearch(w http.ResponseWriter, r *http.Request, err error) {
+	if stdErrors.Is(err, types.ErrQueryTooComplex) {
+		h.RespondError(w, r, errors.ErrQueryTooComplex(err))
+		return
+	}
+	h.RespondError(w, r, errors.ErrInvalidRequest(err))
+}
+
+// SearchContext bounds a search to the configured search timeout, the context is released
+// with the returned cancel once the search returned
+func (h *BaseHandler) SearchContext(r *http.Request) (context.Context, context.CancelFunc) {
+	timeout, ok := requestcontext.GetSearchTimeoutFromContext(r.Context())
+	if !ok {
+		timeout = types.DefaultSearchTimeout
+	}
+	return context.WithTimeout(r.Context(), timeout)
+}
+
+// HandleSearchError responds to the error of a search run in ctx, the context SearchContext
+// returned. A search running past the search timeout is answered with a 400 as a query too
+// complex, other errors as HandleServiceError does.
+func (h *BaseHandler) HandleSearchError(w http.ResponseWriter, r *http.Request, ctx context.Context, err error) {
+	if stdErrors.Is(ctx.Err(), context.DeadlineExceeded) && r.Context().Err() == nil {
+		h.RespondError(w, r, errors.ErrQueryTooComplex(types.ErrQueryTooComplex))
 		return
 	}
-	h.RespondError(w, r, errors.ErrDatabase(err))
+	h.HandleServiceError(w, r, err)
+}
+
+// HandleServiceError responds with the status matching the error, the repository sentinels
+// map to 404 and 409 however deeply they are wrapped
+func (h *BaseHandler) HandleServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	h.RespondError(w, r, serviceError(err))
+}
+
+// serviceError returns the error response matching a service error
+func serviceError(err error) render.Renderer {
+	if stdErrors.Is(err, repository.ErrNotFound) {
+		return errors.ErrNotFound()
+	}
+	if errors.IsErrorType(err, errors.ErrorTypeValidation) {
+		return errors.ErrValidation(err)
+	}
+	var rendered *errors.ErrorResponse
+	if stdErrors.As(err, &rendered) && (rendered.Type == errors.ErrorTypeEmailSuspect || rendered.Type == errors.ErrorTypeQuotaExceeded) {
+		return rendered
+	}
+	if errors.IsErrorType(err, errors.ErrorTypeConcurrentModification) {
+		return errors.ErrConcurrentModification(err)
+	}
+	if stdErrors.Is(err, repository.ErrConflict) {
+		return errors.ErrConflict(err)
+	}
+	if stdErrors.Is(err, repository.ErrPreconditionFailed) {
+		return errors.ErrPreconditionFailed(err)
+	}
+	if errors.IsErrorType(err, errors.ErrorTypeForbidden) {
+		return errors.ErrForbidden(err)
+	}
+	return errors.ErrDatabase(err)
 }
@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/version"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRespond_PaginationLinks(t *testing.T) {
+	h := NewBaseHandler(zap.NewNop())
+
+	tests := []struct {
+		name     string
+		target   string
+		response func() *payloads.Response
+		expected *payloads.Links
+	}{
+		{
+			name:   "first page with a next page",
+			target: "/api/v1/wallets?limit=2&currency=EUR",
+			response: func() *payloads.Response {
+				return payloads.Paginated([]string{"a", "b"}, "dG9rZW4y", 2).(*payloads.Response)
+			},
+			expected: &payloads.Links{
+				Self:  "/api/v1/wallets?currency=EUR&limit=2",
+				First: "/api/v1/wallets?currency=EUR&limit=2",
+				Next:  "/api/v1/wallets?currency=EUR&limit=2&next_token=dG9rZW4y",
+			},
+		},
+		{
+			name:   "last page",
+			target: "/api/v1/projects/deleted?next_token=dG9rZW4x&order=asc",
+			response: func() *payloads.Response {
+				return payloads.Paginated([]string{"a"}, "", 10).(*payloads.Response)
+			},
+			expected: &payloads.Links{
+				Self:  "/api/v1/projects/deleted?next_token=dG9rZW4x&order=asc",
+				First: "/api/v1/projects/deleted?order=asc",
+			},
+		},
+		{
+			name:   "search keeps the query",
+			target: "/api/v1/contacts/search?q=john+doe",
+			response: func() *payloads.Response {
+				return payloads.PaginatedSearch([]string{"a"}, "john doe", 1, 1, "MQ==").(*payloads.Response)
+			},
+			expected: &payloads.Links{
+				Self:  "/api/v1/contacts/search?q=john+doe",
+				First: "/api/v1/contacts/search?q=john+doe",
+				Next:  "/api/v1/contacts/search?next_token=MQ%3D%3D&q=john+doe",
+			},
+		},
+		{
+			name:   "without query parameters",
+			target: "/api/v1/wallets",
+			response: func() *payloads.Response {
+				return payloads.Paginated([]string{}, "", 10).(*payloads.Response)
+			},
+			expected: &payloads.Links{Self: "/api/v1/wallets", First: "/api/v1/wallets"},
+		},
+		{
+			name:   "unpaginated responses have no links",
+			target: "/api/v1/wallets/all",
+			response: func() *payloads.Response {
+				return payloads.List([]string{"a"}, 1).(*payloads.Response)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.Respond(w, httptest.NewRequest(http.MethodGet, tt.target, nil), tt.response())
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var body struct {
+				Meta struct {
+					Links *payloads.Links `json:"links"`
+				} `json:"meta"`
+			}
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+			assert.Equal(t, tt.expected, body.Meta.Links)
+		})
+	}
+}
+
+func TestRespond_Warnings(t *testing.T) {
+	h := NewBaseHandler(zap.NewNop())
+
+	tests := []struct {
+		name     string
+		warnings []string
+		respond  func(w http.ResponseWriter, r *http.Request)
+		expected string
+	}{
+		{
+			name:     "success keeps the data",
+			warnings: []string{"limit: capped at 100", "unknown query parameter: page"},
+			respond: func(w http.ResponseWriter, r *http.Request) {
+				h.Respond(w, r, payloads.OK(map[string]string{"name": "Savings"}))
+			},
+			expected: `{"status":200,"message":"Success","data":{"name":"Savings"},"meta":{"warnings":["limit: capped at 100","unknown query parameter: page"]}}`,
+		},
+		{
+			name:     "client error",
+			warnings: []string{"limit: capped at 100"},
+			respond: func(w http.ResponseWriter, r *http.Request) {
+				h.RespondError(w, r, errors.ErrInvalidRequest(fmt.Errorf("order: must be a valid value")))
+			},
+			expected: `{"type":"VALIDATION_ERROR","message":"Invalid request","code":400,"error":"order: must be a valid value","meta":{"warnings":["limit: capped at 100"]}}`,
+		},
+		{
+			name:     "server error",
+			warnings: []string{"limit: capped at 100"},
+			respond: func(w http.ResponseWriter, r *http.Request) {
+				h.HandleServiceError(w, r, fmt.Errorf("connection reset"))
+			},
+			expected: `{"type":"DATABASE_ERROR","message":"Database error occurred","code":500,"error":"connection reset","meta":{"version":"` + version.Version + `","warnings":["limit: capped at 100"]}}`,
+		},
+		{
+			name: "client error without warnings",
+			respond: func(w http.ResponseWriter, r *http.Request) {
+				h.RespondError(w, r, errors.ErrNotFound())
+			},
+			expected: `{"type":"NOT_FOUND","message":"Resource not found","code":404}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			ctx := requestcontext.WithWarnings(r.Context())
+			for _, warning := range tt.warnings {
+				requestcontext.AddWarning(ctx, warning)
+			}
+			w := httptest.NewRecorder()
+			tt.respond(w, r.WithContext(ctx))
+			assert.JSONEq(t, tt.expected, w.Body.String())
+		})
+	}
+}
+
+func TestHandleServiceError(t *testing.T) {
+	h := NewBaseHandler(zap.NewNop())
+
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{name: "wrapped not found sentinel", err: fmt.Errorf("delete contact: %w", repository.ErrNotFound), expected: http.StatusNotFound},
+		{name: "not found error", err: errors.NewNotFoundError("wallet not found"), expected: http.StatusNotFound},
+		{name: "wrapped not found error", err: fmt.Errorf("load: %w", errors.NewNotFoundError("wallet not found")), expected: http.StatusNotFound},
+		{name: "wrapped conflict sentinel", err: fmt.Errorf("rename wallet: %w", repository.ErrConflict), expected: http.StatusConflict},
+		{name: "conflict error", err: errors.NewConflictError("name taken"), expected: http.StatusConflict},
+		{name: "lock timeout", err: errors.HandleRepositoryError(&pgconn.PgError{Code: errors.LockNotAvailableCode}, "update", "wallet"), expected: http.StatusConflict},
+		{name: "validation error", err: errors.NewValidationError("too long"), expected: http.StatusBadRequest},
+		{name: "forbidden error", err: errors.NewForbiddenError("anonymized"), expected: http.StatusForbidden},
+		{name: "quota exceeded", err: fmt.Errorf("create: %w", errors.NewQuotaExceededError("wallets", 5)), expected: http.StatusForbidden},
+		{name: "anything else", err: fmt.Errorf("not found"), expected: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.HandleServiceError(w, httptest.NewRequest(http.MethodDelete, "/", nil), tt.err)
+			assert.Equal(t, tt.expected, w.Code)
+		})
+	}
+}
+
+func TestHandleSearchError(t *testing.T) {
+	h := NewBaseHandler(zap.NewNop())
+
+	t.Run("search timed out", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r = r.WithContext(context.WithValue(r.Context(), requestcontext.SearchTimeoutKey, time.Nanosecond))
+		ctx, cancel := h.SearchContext(r)
+		defer cancel()
+		<-ctx.Done()
+
+		w := httptest.NewRecorder()
+		h.HandleSearchError(w, r, ctx, fmt.Errorf("search: %w", ctx.Err()))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var body errors.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, errors.ErrorTypeQueryTooComplex, body.Type)
+	})
+
+	t.Run("request cancelled", func(t *testing.T) {
+		requestCtx, cancelRequest := context.WithCancel(context.Background())
+		r := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(requestCtx)
+		ctx, cancel := h.SearchContext(r)
+		defer cancel()
+		cancelRequest()
+
+		w := httptest.NewRecorder()
+		h.HandleSearchError(w, r, ctx, fmt.Errorf("search: %w", ctx.Err()))
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("other errors", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		ctx, cancel := h.SearchContext(r)
+		defer cancel()
+
+		w := httptest.NewRecorder()
+		h.HandleSearchError(w, r, ctx, repository.ErrNotFound)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestRespondInvalidSearch(t *testing.T) {
+	h := NewBaseHandler(zap.NewNop())
+
+	for _, tt := range []struct {
+		err      error
+		expected errors.ErrorType
+	}{
+		{err: types.ErrQueryTooComplex, expected: errors.ErrorTypeQueryTooComplex},
+		{err: fmt.Errorf("limit: invalid format"), expected: errors.ErrorTypeValidation},
+	} {
+		w := httptest.NewRecorder()
+		h.RespondInvalidSearch(w, httptest.NewRequest(http.MethodGet, "/", nil), tt.err)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var body errors.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, tt.expected, body.Type)
+	}
+}
+
+func TestParsePagination_QuerySignature(t *testing.T) {
+	h := NewBaseHandler(zap.NewNop())
+	userID := uuid.New()
+	filters := []string{"group_id", "limit", "order", "next_token"}
+
+	issued := types.PaginationParams{Order: types.SortOrderDesc, Signature: types.QuerySignature(url.Values{"group_id": {"none"}}, filters...)}
+	pinned := issued.NextPinnedToken(time.Now().Add(-time.Minute), uuid.New(), userID)
+	unsigned := types.EncodeOrderedCursor(time.Now().Add(-time.Minute), uuid.New(), types.SortOrderDesc, userID)
+
+	tests := []struct {
+		name         string
+		query        string
+		expectedType errors.ErrorType
+	}{
+		{name: "same filters", query: "group_id=none&limit=3&next_token=" + url.QueryEscape(pinned)},
+		{name: "parameters the list doesn't read are left out", query: "group_id=none&_=123&next_token=" + url.QueryEscape(pinned)},
+		{name: "other filters", query: "group_id=" + uuid.NewString() + "&next_token=" + url.QueryEscape(pinned), expectedType: errors.ErrorTypeCursorMismatch},
+		{name: "other order", query: "group_id=none&order=asc&next_token=" + url.QueryEscape(pinned), expectedType: errors.ErrorTypeCursorMismatch},
+		{name: "tokens issued before signatures pass", query: "group_id=" + uuid.NewString() + "&next_token=" + url.QueryEscape(unsigned)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/wallets?"+tt.query, nil)
+			w := httptest.NewRecorder()
+			params, ok := h.ParsePagination(w, r, types.DefaultLimitPolicy(), userID, filters...)
+			if tt.expectedType == "" {
+				require.True(t, ok, w.Body.String())
+				require.NotNil(t, params.Cursor)
+				return
+			}
+			require.False(t, ok)
+			var response errors.ErrorResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+			assert.Equal(t, tt.expectedType, response.Type)
+		})
+	}
+
+	cursor, err := types.DecodeCursor(pinned, issued.Signature, types.DefaultLimitPolicy())
+	require.NoError(t, err)
+	assert.True(t, cursor.Pinned)
+	assert.Equal(t, issued.Signature, cursor.Signature)
+	_, err = types.DecodeCursor(pinned, types.QuerySignature(url.Values{}, filters...), types.DefaultLimitPolicy())
+	assert.ErrorIs(t, err, types.ErrCursorMismatch)
+}
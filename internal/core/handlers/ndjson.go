@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"go.uber.org/zap"
+)
+
+// ndjsonFlushEvery is the number of rows written between flushes of an NDJSON stream,
+// lower than the CSV one as list pages are a lot shorter than exports
+const ndjsonFlushEvery = 20
+
+// NDJSONMeta is the last line of a complete NDJSON stream
+type NDJSONMeta struct {
+	// Count is the number of rows streamed before the meta line
+	Count     int      `json:"count" example:"20"`
+	NextToken string   `json:"next_token,omitempty"`
+	Warnings  []string `json:"warnings,omitempty"`
+}
+
+// ndjsonTrailer is the line ending an NDJSON stream, meta once every row is out and
+// error when the stream was interrupted
+type ndjsonTrailer struct {
+	Meta  *NDJSONMeta           `json:"meta,omitempty"`
+	Error *errors.ErrorResponse `json:"error,omitempty"`
+}
+
+// StreamNDJSON writes a list page as newline-delimited JSON, one row per line as stream
+// produces them, and a closing {"meta":...} line with the row count and the next_token
+// stream returns. Nothing is sent before the first row, so a failure up to then still
+// gets a regular error response; once rows are out the status can't change, the error
+// is logged and the stream ends with an {"error":...} line in place of the meta one.
+func (h *BaseHandler) StreamNDJSON(w http.ResponseWriter, r *http.Request, stream func(write func(item any) error) (string, error)) {
+	writer := bufio.NewWriter(w)
+	encoder := json.NewEncoder(writer)
+	controller := http.NewResponseController(w)
+	started := false
+	rows := 0
+
+	start := func() {
+		started = true
+		w.Header().Set("Content-Type", MediaTypeNDJSON+"; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+	}
+
+	nextToken, err := stream(func(item any) error {
+		// rows are encoded before anything is sent, a row that can't be encoded fails
+		// the request the regular way
+		line, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		if !started {
+			start()
+		}
+		if _, err := writer.Write(append(line, '\n')); err != nil {
+			return err
+		}
+		rows++
+		if rows%ndjsonFlushEvery == 0 {
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+			// writers that can't flush just buffer, the rows still go out at the end
+			controller.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		if !started {
+			h.HandleServiceError(w, r, err)
+			return
+		}
+		h.logger.Error("ndjson stream interrupted", zap.Int("rows", rows), zap.Error(err))
+		// the service errors all map to an ErrorResponse, Render only fills its meta here
+		rendered := serviceError(err).(*errors.ErrorResponse)
+		rendered.Render(w, r)
+		if err := encoder.Encode(ndjsonTrailer{Error: rendered}); err != nil {
+			h.logger.Error("failed to write ndjson error", zap.Error(err))
+		}
+		writer.Flush()
+		return
+	}
+
+	if !started {
+		start()
+	}
+	meta := &NDJSONMeta{
+		Count:     rows,
+		NextToken: nextToken,
+		Warnings:  requestcontext.GetWarningsFromContext(r.Context()),
+	}
+	if err := encoder.Encode(ndjsonTrailer{Meta: meta}); err != nil {
+		h.logger.Error("failed to write ndjson meta", zap.Error(err))
+	}
+	if err := writer.Flush(); err != nil {
+		h.logger.Error("failed to write ndjson", zap.Error(err))
+	}
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/version"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type ndjsonRow struct {
+	N int `json:"n"`
+}
+
+type ndjsonLine struct {
+	N     *int                   `json:"n"`
+	Meta  map[string]interface{} `json:"meta"`
+	Error map[string]interface{} `json:"error"`
+}
+
+func TestStreamNDJSON_FlushesAsRowsAreRead(t *testing.T) {
+	h := NewBaseHandler(zap.NewNop())
+	// the second batch of rows is only read once the client has the first one
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.StreamNDJSON(w, r, func(write func(item any) error) (string, error) {
+			for n := 1; n <= 2*ndjsonFlushEvery; n++ {
+				if n == ndjsonFlushEvery+1 {
+					<-release
+				}
+				if err := write(ndjsonRow{N: n}); err != nil {
+					return "", err
+				}
+			}
+			return "bmV4dA==", nil
+		})
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/x-ndjson; charset=utf-8", resp.Header.Get("Content-Type"))
+
+	lines := bufio.NewScanner(resp.Body)
+	// read runs in a goroutine too, so it asserts rather than requires
+	read := func() ndjsonLine {
+		var line ndjsonLine
+		if assert.True(t, lines.Scan(), "stream ended early: %v", lines.Err()) {
+			assert.NoError(t, json.Unmarshal(lines.Bytes(), &line), lines.Text())
+		}
+		return line
+	}
+
+	received := make(chan struct{})
+	go func() {
+		defer close(received)
+		for n := 1; n <= ndjsonFlushEvery; n++ {
+			line := read()
+			if assert.NotNil(t, line.N) {
+				assert.Equal(t, n, *line.N)
+			}
+		}
+	}()
+	select {
+	case <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the first rows weren't flushed before the stream went on")
+	}
+	close(release)
+
+	for n := ndjsonFlushEvery + 1; n <= 2*ndjsonFlushEvery; n++ {
+		line := read()
+		if assert.NotNil(t, line.N) {
+			assert.Equal(t, n, *line.N)
+		}
+	}
+	meta := read().Meta
+	assert.Equal(t, map[string]interface{}{"count": float64(2 * ndjsonFlushEvery), "next_token": "bmV4dA=="}, meta)
+	assert.False(t, lines.Scan(), "nothing follows the meta line")
+}
+
+func TestStreamNDJSON(t *testing.T) {
+	h := NewBaseHandler(zap.NewNop())
+
+	tests := []struct {
+		name           string
+		rows           int
+		err            error
+		expectedStatus int
+		// expectedLast is the last line of the body
+		expectedLast ndjsonLine
+	}{
+		{
+			name:           "complete stream ends with the meta line",
+			rows:           3,
+			expectedStatus: http.StatusOK,
+			expectedLast:   ndjsonLine{Meta: map[string]interface{}{"count": float64(3), "warnings": []interface{}{"limit: capped at 100"}}},
+		},
+		{
+			name:           "empty stream still has the meta line",
+			expectedStatus: http.StatusOK,
+			expectedLast:   ndjsonLine{Meta: map[string]interface{}{"count": float64(0), "warnings": []interface{}{"limit: capped at 100"}}},
+		},
+		{
+			name:           "error midway ends with the error line",
+			rows:           2,
+			err:            fmt.Errorf("connection reset"),
+			expectedStatus: http.StatusOK,
+			expectedLast: ndjsonLine{Error: map[string]interface{}{
+				"type":    "DATABASE_ERROR",
+				"message": "Database error occurred",
+				"code":    float64(http.StatusInternalServerError),
+				"error":   "connection reset",
+				"meta":    map[string]interface{}{"version": version.Version, "warnings": []interface{}{"limit: capped at 100"}},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/wallets?stream=true", nil)
+			req = req.WithContext(requestcontext.WithWarnings(req.Context()))
+			requestcontext.AddWarning(req.Context(), "limit: capped at 100")
+			w := httptest.NewRecorder()
+
+			h.StreamNDJSON(w, req, func(write func(item any) error) (string, error) {
+				for n := 1; n <= tt.rows; n++ {
+					if err := write(ndjsonRow{N: n}); err != nil {
+						return "", err
+					}
+				}
+				return "", tt.err
+			})
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			lines := bufio.NewScanner(w.Body)
+			var got []ndjsonLine
+			for lines.Scan() {
+				var line ndjsonLine
+				require.NoError(t, json.Unmarshal(lines.Bytes(), &line), lines.Text())
+				got = append(got, line)
+			}
+			require.Len(t, got, tt.rows+1)
+			for n, line := range got[:tt.rows] {
+				if assert.NotNil(t, line.N) {
+					assert.Equal(t, n+1, *line.N)
+				}
+			}
+			assert.Equal(t, tt.expectedLast, got[len(got)-1])
+		})
+	}
+
+	t.Run("error before the first row gets a regular response", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/wallets?stream=true", nil)
+		w := httptest.NewRecorder()
+
+		h.StreamNDJSON(w, req, func(write func(item any) error) (string, error) {
+			return "", repository.ErrNotFound
+		})
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.Equal(t, "NOT_FOUND", response["type"])
+	})
+}
+
+func TestParseStream(t *testing.T) {
+	h := NewBaseHandler(zap.NewNop())
+
+	tests := []struct {
+		name           string
+		target         string
+		accept         string
+		expectedStream bool
+		expectedOK     bool
+	}{
+		{name: "default", target: "/contacts", expectedOK: true},
+		{name: "stream parameter", target: "/contacts?stream=true", expectedStream: true, expectedOK: true},
+		{name: "stream parameter over the accept header", target: "/contacts?stream=false", accept: "application/x-ndjson", expectedOK: true},
+		{name: "accept header", target: "/contacts", accept: "application/x-ndjson", expectedStream: true, expectedOK: true},
+		{name: "json preferred", target: "/contacts", accept: "application/json, application/x-ndjson;q=0.5", expectedOK: true},
+		{name: "unsupported accept header", target: "/contacts", accept: "text/csv", expectedOK: true},
+		{name: "invalid stream parameter", target: "/contacts?stream=yes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+
+			stream, ok := h.ParseStream(w, req)
+			assert.Equal(t, tt.expectedStream, stream)
+			assert.Equal(t, tt.expectedOK, ok)
+			if !ok {
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+				assert.Contains(t, w.Body.String(), "stream: must be true or false")
+			}
+		})
+	}
+}
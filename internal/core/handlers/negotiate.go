@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Media types the export endpoints can produce
+const (
+	MediaTypeCSV   = "text/csv"
+	MediaTypeJSON  = "application/json"
+	MediaTypeVCard = "text/vcard"
+	// MediaTypeNDJSON is newline-delimited JSON, the streaming mode of the paginated lists
+	MediaTypeNDJSON = "application/x-ndjson"
+	// MediaTypeZip is the archive of the account backups, binary so sent without a charset
+	MediaTypeZip = "application/zip"
+)
+
+// formatMediaTypes maps the values of a format query parameter to their media type
+var formatMediaTypes = map[string]string{
+	"csv":   MediaTypeCSV,
+	"json":  MediaTypeJSON,
+	"vcard": MediaTypeVCard,
+}
+
+// acceptedRange is a media range of an Accept header with its quality
+type acceptedRange struct {
+	mediaType string
+	quality   float64
+}
+
+// NegotiateFormat picks the media type of the response among offered, the first being
+// the default. The query parameter named param, when given and present, overrides the
+// Accept header with one of csv, json or vcard. Otherwise the offered type the Accept
+// header ranks highest wins, each type taking the quality of the most specific range
+// matching it and exact matches beating wildcards on equal quality. The error names
+// what was asked for when nothing offered is acceptable.
+func NegotiateFormat(r *http.Request, param string, offered ...string) (string, error) {
+	if param != "" {
+		if format := r.URL.Query().Get(param); strings.TrimSpace(format) != "" {
+			return FormatMediaType(param, format, offered...)
+		}
+	}
+
+	accept := strings.TrimSpace(r.Header.Get("Accept"))
+	if accept == "" {
+		return offered[0], nil
+	}
+
+	ranges := parseAccept(accept)
+	best, bestQuality, bestSpecificity := "", 0.0, -1
+	for _, mediaType := range offered {
+		// the most specific range matching the type sets its quality
+		quality, specificity := 0.0, -1
+		for _, accepted := range ranges {
+			if match := matchMediaRange(accepted.mediaType, mediaType); match > specificity {
+				quality, specificity = accepted.quality, match
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+		if quality > bestQuality || (quality == bestQuality && specificity > bestSpecificity) {
+			best, bestQuality, bestSpecificity = mediaType, quality, specificity
+		}
+	}
+	if best == "" {
+		return "", fmt.Errorf("none of %s can be served, available: %s", accept, strings.Join(offered, ", "))
+	}
+	return best, nil
+}
+
+// FormatMediaType returns the media type of a format named by the query parameter param,
+// one of csv, json or vcard, the error lists the offered ones when it isn't among them
+func FormatMediaType(param, format string, offered ...string) (string, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	mediaType, ok := formatMediaTypes[format]
+	if ok && slices.Contains(offered, mediaType) {
+		return mediaType, nil
+	}
+	return "", fmt.Errorf("%s: %q isn't available, expected one of %s", param, format, formatNames(offered))
+}
+
+// parseAccept reads the media ranges of an Accept header, skipping malformed ones
+func parseAccept(accept string) []acceptedRange {
+	var ranges []acceptedRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil || quality > 1 {
+				continue
+			}
+		}
+		ranges = append(ranges, acceptedRange{mediaType: mediaType, quality: quality})
+	}
+	return ranges
+}
+
+// matchMediaRange reports how specifically the media range matches mediaType, 2 for
+// the exact type, 1 for type/* and 0 for */*, or -1 when it doesn't match
+func matchMediaRange(mediaRange, mediaType string) int {
+	switch {
+	case mediaRange == mediaType:
+		return 2
+	case mediaRange == "*/*":
+		return 0
+	case strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(mediaRange, "*")):
+		return 1
+	}
+	return -1
+}
+
+// formatNames lists the format query values of the offered media types
+func formatNames(offered []string) string {
+	names := make([]string, 0, len(offered))
+	for _, mediaType := range offered {
+		for name, formatType := range formatMediaTypes {
+			if formatType == mediaType {
+				names = append(names, name)
+			}
+		}
+	}
+	return strings.Join(names, ", ")
+}
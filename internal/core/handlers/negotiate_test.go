@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+	offered := []string{MediaTypeCSV, MediaTypeJSON, MediaTypeVCard}
+
+	tests := []struct {
+		name     string
+		target   string
+		accept   string
+		offered  []string
+		expected string
+		err      string
+	}{
+		{name: "defaults to the first offered", target: "/export", expected: MediaTypeCSV},
+		{name: "exact type", target: "/export", accept: "application/json", expected: MediaTypeJSON},
+		{name: "any type", target: "/export", accept: "*/*", expected: MediaTypeCSV},
+		{name: "type wildcard", target: "/export", accept: "text/*", expected: MediaTypeCSV},
+		{name: "exact type beats a wildcard", target: "/export", accept: "text/*, text/vcard", expected: MediaTypeVCard},
+		{name: "highest quality", target: "/export", accept: "text/csv;q=0.5, application/json;q=0.8", expected: MediaTypeJSON},
+		{name: "parameters are ignored", target: "/export", accept: "text/csv; charset=utf-8", expected: MediaTypeCSV},
+		{name: "excluded type", target: "/export", accept: "text/csv;q=0, */*;q=0.1", expected: MediaTypeJSON},
+		{name: "unsupported types skipped", target: "/export", accept: "application/xml, text/vcard;q=0.2", expected: MediaTypeVCard},
+		{
+			name:   "nothing acceptable",
+			target: "/export",
+			accept: "application/xml",
+			err:    "none of application/xml can be served, available: text/csv, application/json, text/vcard",
+		},
+		{name: "query parameter overrides the header", target: "/export?format=json", accept: "text/csv", expected: MediaTypeJSON},
+		{name: "query parameter is case insensitive", target: "/export?format=VCARD", expected: MediaTypeVCard},
+		{
+			name:    "query parameter not offered",
+			target:  "/export?format=vcard",
+			offered: []string{MediaTypeCSV, MediaTypeJSON},
+			err:     `format: "vcard" isn't available, expected one of csv, json`,
+		},
+		{name: "unknown query parameter", target: "/export?format=xml", err: `format: "xml" isn't available`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			formats := offered
+			if tt.offered != nil {
+				formats = tt.offered
+			}
+
+			mediaType, err := NegotiateFormat(req, "format", formats...)
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, mediaType)
+		})
+	}
+
+	t.Run("without an override parameter", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/export?format=json", nil)
+		mediaType, err := NegotiateFormat(req, "", offered...)
+		require.NoError(t, err)
+		assert.Equal(t, MediaTypeCSV, mediaType)
+	})
+}
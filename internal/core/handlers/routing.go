@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/go-chi/render"
+)
+
+// NotFound answers requests to paths without a route with the standard error body
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	render.Render(w, r, errors.ErrNotFound())
+}
+
+// MethodNotAllowed answers requests to a known path with a method it doesn't serve
+// with the standard error body
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	render.Render(w, r, errors.ErrMethodNotAllowed(fmt.Errorf("method %s not allowed on %s", r.Method, r.URL.Path)))
+}
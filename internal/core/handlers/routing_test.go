@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmatchedRoutes(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	r := chi.NewRouter()
+	r.NotFound(NotFound)
+	r.MethodNotAllowed(MethodNotAllowed)
+	r.Route("/api/v1", func(r chi.Router) {
+		r.Route("/wallets", func(r chi.Router) {
+			r.Get("/", ok)
+			r.Post("/", ok)
+		})
+	})
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		expectedStatus int
+		expectedType   errors.ErrorType
+	}{
+		{name: "matched route", method: http.MethodGet, path: "/api/v1/wallets", expectedStatus: http.StatusOK},
+		{name: "unknown top level path", method: http.MethodGet, path: "/unknown", expectedStatus: http.StatusNotFound, expectedType: errors.ErrorTypeNotFound},
+		{name: "unknown nested path", method: http.MethodGet, path: "/api/v1/unknown", expectedStatus: http.StatusNotFound, expectedType: errors.ErrorTypeNotFound},
+		{name: "unsupported method", method: http.MethodPatch, path: "/api/v1/wallets", expectedStatus: http.StatusMethodNotAllowed, expectedType: errors.ErrorTypeMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedType == "" {
+				return
+			}
+
+			assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+			var response errors.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedType, response.Type)
+			assert.Equal(t, tt.expectedStatus, response.Code)
+		})
+	}
+}
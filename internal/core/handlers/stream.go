@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// StreamJSON writes a JSON array response item by item as stream produces them, with
+// the same error handling as StreamCSV
+func (h *BaseHandler) StreamJSON(w http.ResponseWriter, r *http.Request, stream func(write func(item any) error) error) {
+	items := 0
+	h.streamText(w, r, MediaTypeJSON, "[", "]", func(write func(chunk string) error) error {
+		return stream(func(item any) error {
+			encoded, err := json.Marshal(item)
+			if err != nil {
+				return err
+			}
+			separator := ","
+			if items == 0 {
+				separator = ""
+			}
+			items++
+			return write(separator + string(encoded))
+		})
+	})
+}
+
+// StreamText writes a text response chunk by chunk as stream produces them, with the
+// same error handling as StreamCSV
+func (h *BaseHandler) StreamText(w http.ResponseWriter, r *http.Request, contentType string, stream func(write func(chunk string) error) error) {
+	h.streamText(w, r, contentType, "", "", stream)
+}
+
+// SendFile sends file as an attachment named filename. The status is out before the
+// file is read, a read failure is logged and the body cut short.
+func (h *BaseHandler) SendFile(w http.ResponseWriter, contentType, filename string, file io.Reader) {
+	if contentType != MediaTypeZip {
+		contentType += "; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	if written, err := io.Copy(w, file); err != nil {
+		h.logger.Error("file transfer interrupted", zap.String("filename", filename), zap.Int64("bytes", written), zap.Error(err))
+	}
+}
+
+// streamText sends the status along with opening right before the first chunk, so a
+// failure up to then still gets a regular error response. closing ends a complete body.
+func (h *BaseHandler) streamText(w http.ResponseWriter, r *http.Request, contentType, opening, closing string, stream func(write func(chunk string) error) error) {
+	writer := bufio.NewWriter(w)
+	controller := http.NewResponseController(w)
+	started := false
+	chunks := 0
+
+	start := func() error {
+		started = true
+		w.Header().Set("Content-Type", contentType+"; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, err := writer.WriteString(opening)
+		return err
+	}
+
+	err := stream(func(chunk string) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if _, err := writer.WriteString(chunk); err != nil {
+			return err
+		}
+		chunks++
+		if chunks%csvFlushEvery == 0 {
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+			// writers that can't flush just buffer, the chunks still go out at the end
+			controller.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		if !started {
+			h.HandleServiceError(w, r, err)
+			return
+		}
+		h.logger.Error("stream interrupted", zap.String("content_type", contentType), zap.Int("chunks", chunks), zap.Error(err))
+		writer.Flush()
+		return
+	}
+
+	if !started {
+		if err := start(); err != nil {
+			h.logger.Error("failed to start stream", zap.Error(err))
+			return
+		}
+	}
+	if _, err := writer.WriteString(closing); err != nil {
+		h.logger.Error("failed to end stream", zap.Error(err))
+		return
+	}
+	if err := writer.Flush(); err != nil {
+		h.logger.Error("failed to write stream", zap.Error(err))
+	}
+}
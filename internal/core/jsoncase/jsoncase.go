@@ -0,0 +1,114 @@
+// Package jsoncase lets request payloads accept the snake_case field names older clients
+// send next to the camelCase ones the API documents. Responses stay camelCase.
+package jsoncase
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// Aliases maps the snake_case name of a payload's fields to their camelCase JSON name,
+// fields whose name is the same in both conventions are left out
+type Aliases map[string]string
+
+// AliasesOf builds the aliases of a struct from its json tags, so they can't drift from
+// the fields. Call it at init time, it panics on anything but a struct.
+func AliasesOf(payload any) Aliases {
+	t := reflect.TypeOf(payload)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("jsoncase: %s isn't a struct", t))
+	}
+
+	aliases := Aliases{}
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if snake := ToSnake(name); snake != name {
+			aliases[snake] = name
+		}
+	}
+	return aliases
+}
+
+// ToSnake converts a camelCase name to snake_case, addressLine1 becoming address_line1
+func ToSnake(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Unmarshal decodes data into v after renaming the snake_case fields to their camelCase
+// name. A field sent under both names with different values is rejected. v must not
+// implement json.Unmarshaler itself, UnmarshalJSON methods pass a conversion of their
+// receiver to a plain type.
+func (a Aliases) Unmarshal(data []byte, v any) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil || fields == nil {
+		// not an object, or null, which the decoder reports or ignores as usual
+		return json.Unmarshal(data, v)
+	}
+
+	renamed := false
+	for snake, camel := range a {
+		value, ok := fields[snake]
+		if !ok {
+			continue
+		}
+		if existing, ok := fields[camel]; ok {
+			same, err := sameJSON(value, existing)
+			if err != nil {
+				return err
+			}
+			if !same {
+				return fmt.Errorf("%s: conflicts with %s, send one of them", snake, camel)
+			}
+		}
+		fields[camel] = value
+		delete(fields, snake)
+		renamed = true
+	}
+
+	if !renamed {
+		return json.Unmarshal(data, v)
+	}
+	normalized, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(normalized, v)
+}
+
+// sameJSON reports whether two JSON values are equal regardless of formatting
+func sameJSON(a, b json.RawMessage) (bool, error) {
+	var x, y any
+	if err := json.Unmarshal(a, &x); err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(b, &y); err != nil {
+		return false, err
+	}
+	return reflect.DeepEqual(x, y), nil
+}
@@ -0,0 +1,82 @@
+package jsoncase
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type payload struct {
+	Name         string   `json:"name"`
+	AddressLine1 *string  `json:"addressLine1,omitempty"`
+	ProjectID    string   `json:"projectId"`
+	Ignored      string   `json:"-"`
+	Balance      *float64 `json:"balance"`
+}
+
+func TestAliasesOf(t *testing.T) {
+	assert.Equal(t, Aliases{"address_line1": "addressLine1", "project_id": "projectId"}, AliasesOf(payload{}))
+}
+
+func TestAliases_Unmarshal(t *testing.T) {
+	aliases := AliasesOf(payload{})
+
+	tests := []struct {
+		name     string
+		data     string
+		initial  payload
+		expected payload
+		err      string
+	}{
+		{
+			name:     "camelCase",
+			data:     `{"name": "a", "addressLine1": "1 Main St", "projectId": "p"}`,
+			expected: payload{Name: "a", AddressLine1: strPtr("1 Main St"), ProjectID: "p"},
+		},
+		{
+			name:     "snake_case",
+			data:     `{"name": "a", "address_line1": "1 Main St", "project_id": "p"}`,
+			expected: payload{Name: "a", AddressLine1: strPtr("1 Main St"), ProjectID: "p"},
+		},
+		{
+			name:     "both forms with the same value",
+			data:     `{"address_line1": "1 Main St", "addressLine1":"1 Main St"}`,
+			expected: payload{AddressLine1: strPtr("1 Main St")},
+		},
+		{
+			name:     "absent fields keep their value",
+			data:     `{"project_id": "q"}`,
+			initial:  payload{Name: "a", ProjectID: "p"},
+			expected: payload{Name: "a", ProjectID: "q"},
+		},
+		{
+			name: "both forms with different values",
+			data: `{"project_id": "p", "projectId": "q"}`,
+			err:  "project_id: conflicts with projectId, send one of them",
+		},
+		{
+			name: "not an object",
+			data: `["a"]`,
+			err:  "cannot unmarshal array",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.initial
+			err := aliases.Unmarshal([]byte(tt.data), &got)
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
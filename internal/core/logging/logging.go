@@ -0,0 +1,73 @@
+// Package logging holds the conventions of the services' logs: every service method
+// logs once when it ends, with the user, the entity it works on, its name and how long
+// it took, at debug level when it succeeded and at error level when it failed.
+package logging
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Field keys every service log carries
+const (
+	FieldUserID     = "user_id"
+	FieldEntityType = "entity_type"
+	FieldEntityID   = "entity_id"
+	FieldOperation  = "operation"
+	FieldDuration   = "duration"
+)
+
+// Event names, the messages of the logs an Operation writes
+const (
+	EventSucceeded = "operation succeeded"
+	EventFailed    = "operation failed"
+)
+
+// WithUser returns the logger with the user's ID on every entry
+func WithUser(logger *zap.Logger, userID uuid.UUID) *zap.Logger {
+	return logger.With(zap.String(FieldUserID, userID.String()))
+}
+
+// WithEntity returns the logger with the entity's type and ID on every entry, a nil ID,
+// as for listings, only adds the type
+func WithEntity(logger *zap.Logger, entityType string, id uuid.UUID) *zap.Logger {
+	if id == uuid.Nil {
+		return logger.With(zap.String(FieldEntityType, entityType))
+	}
+	return logger.With(zap.String(FieldEntityType, entityType), zap.String(FieldEntityID, id.String()))
+}
+
+// Operation times a service method and logs how it ended
+type Operation struct {
+	logger  *zap.Logger
+	started time.Time
+	fields  []zap.Field
+}
+
+// Start starts timing the named operation, the fields go on the log it ends with
+func Start(logger *zap.Logger, name string, fields ...zap.Field) *Operation {
+	return &Operation{
+		logger:  logger.With(zap.String(FieldOperation, name)),
+		started: time.Now(),
+		fields:  fields,
+	}
+}
+
+// With adds fields learned while the operation ran, such as how many rows it changed
+func (o *Operation) With(fields ...zap.Field) {
+	o.fields = append(o.fields, fields...)
+}
+
+// End logs EventSucceeded at debug level, or EventFailed at error level when *err is
+// set, with the duration. It's meant to be deferred with the address of the method's
+// named error result so every return is logged.
+func (o *Operation) End(err *error) {
+	fields := append(o.fields, zap.Duration(FieldDuration, time.Since(o.started)))
+	if err != nil && *err != nil {
+		o.logger.Error(EventFailed, append(fields, zap.Error(*err))...)
+		return
+	}
+	o.logger.Debug(EventSucceeded, fields...)
+}
@@ -0,0 +1,69 @@
+package logging
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestOperation_End(t *testing.T) {
+	userID, walletID := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name    string
+		err     error
+		level   zapcore.Level
+		message string
+	}{
+		{name: "success", level: zapcore.DebugLevel, message: EventSucceeded},
+		{name: "failure", err: errors.New("connection reset"), level: zapcore.ErrorLevel, message: EventFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.DebugLevel)
+			logger := WithEntity(WithUser(zap.New(core), userID), "wallet", walletID)
+
+			_ = func() (err error) {
+				op := Start(logger, "WalletService.GetWallet", zap.Int("limit", 5))
+				defer op.End(&err)
+				op.With(zap.Int("found", 1))
+				return tt.err
+			}()
+
+			require.Equal(t, 1, logs.Len())
+			entry := logs.All()[0]
+			assert.Equal(t, tt.level, entry.Level)
+			assert.Equal(t, tt.message, entry.Message)
+
+			fields := entry.ContextMap()
+			assert.Equal(t, userID.String(), fields[FieldUserID])
+			assert.Equal(t, "wallet", fields[FieldEntityType])
+			assert.Equal(t, walletID.String(), fields[FieldEntityID])
+			assert.Equal(t, "WalletService.GetWallet", fields[FieldOperation])
+			assert.Contains(t, fields, FieldDuration)
+			assert.EqualValues(t, 5, fields["limit"])
+			assert.EqualValues(t, 1, fields["found"])
+			if tt.err != nil {
+				assert.Equal(t, tt.err.Error(), fields["error"])
+			} else {
+				assert.NotContains(t, fields, "error")
+			}
+		})
+	}
+}
+
+func TestWithEntity_WithoutID(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	WithEntity(zap.New(core), "wallet", uuid.Nil).Debug("listed")
+
+	fields := logs.All()[0].ContextMap()
+	assert.Equal(t, "wallet", fields[FieldEntityType])
+	assert.NotContains(t, fields, FieldEntityID)
+}
@@ -0,0 +1,170 @@
+// Package mail sends the emails the server writes itself, such as scheduled exports
+package mail
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// base64LineLength is the length the base64 lines of attachments are wrapped at
+const base64LineLength = 76
+
+// Attachment is a file sent along with a message, Content is read once while the
+// message is written
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Content     io.Reader
+}
+
+// Message is a plain text email with its attachments
+type Message struct {
+	To          []string
+	Subject     string
+	Body        string
+	Attachments []Attachment
+}
+
+// Mailer sends messages
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// SMTPMailer sends messages through an SMTP server, upgrading the connection with
+// STARTTLS when the server offers it
+type SMTPMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewSMTPMailer returns a mailer sending from the from address through the server at
+// host:port, it authenticates with PLAIN when username is set
+func NewSMTPMailer(host string, port int, username, password, from string) *SMTPMailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &SMTPMailer{
+		addr: net.JoinHostPort(host, strconv.Itoa(port)),
+		from: from,
+		auth: auth,
+	}
+}
+
+// Send writes the message and hands it to the SMTP server, the context is only checked
+// before the message is sent
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	if err := Write(&body, m.from, msg, time.Now()); err != nil {
+		return err
+	}
+	if err := smtp.SendMail(m.addr, m.auth, m.from, msg.To, body.Bytes()); err != nil {
+		return fmt.Errorf("send email: %w", err)
+	}
+	return nil
+}
+
+// Write writes msg sent from the from address at date as a MIME message, a
+// multipart/mixed one when it has attachments
+func Write(w io.Writer, from string, msg Message, date time.Time) error {
+	if len(msg.To) == 0 {
+		return fmt.Errorf("the email has no recipient")
+	}
+
+	header := func(name, value string) string {
+		return name + ": " + value + "\r\n"
+	}
+	var head strings.Builder
+	head.WriteString(header("From", from))
+	head.WriteString(header("To", strings.Join(msg.To, ", ")))
+	head.WriteString(header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject)))
+	head.WriteString(header("Date", date.Format(time.RFC1123Z)))
+	head.WriteString(header("MIME-Version", "1.0"))
+
+	if len(msg.Attachments) == 0 {
+		head.WriteString(header("Content-Type", "text/plain; charset=utf-8"))
+		head.WriteString("\r\n")
+		_, err := io.WriteString(w, head.String()+msg.Body)
+		return err
+	}
+
+	parts := multipart.NewWriter(w)
+	head.WriteString(header("Content-Type", mime.FormatMediaType("multipart/mixed", map[string]string{"boundary": parts.Boundary()})))
+	head.WriteString("\r\n")
+	if _, err := io.WriteString(w, head.String()); err != nil {
+		return err
+	}
+
+	text, err := parts.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain; charset=utf-8"}})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(text, msg.Body); err != nil {
+		return err
+	}
+
+	for _, attachment := range msg.Attachments {
+		contentType := attachment.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		part, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+		})
+		if err != nil {
+			return err
+		}
+		encoder := base64.NewEncoder(base64.StdEncoding, &lineWrapper{w: part})
+		if _, err := io.Copy(encoder, attachment.Content); err != nil {
+			return fmt.Errorf("attach %s: %w", attachment.Filename, err)
+		}
+		if err := encoder.Close(); err != nil {
+			return err
+		}
+	}
+	return parts.Close()
+}
+
+// lineWrapper breaks what is written to it into CRLF terminated lines of base64LineLength
+type lineWrapper struct {
+	w    io.Writer
+	used int
+}
+
+func (l *lineWrapper) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		if l.used == base64LineLength {
+			if _, err := l.w.Write([]byte("\r\n")); err != nil {
+				return written, err
+			}
+			l.used = 0
+		}
+		n := min(len(p), base64LineLength-l.used)
+		if _, err := l.w.Write(p[:n]); err != nil {
+			return written, err
+		}
+		l.used += n
+		written += n
+		p = p[n:]
+	}
+	return written, nil
+}
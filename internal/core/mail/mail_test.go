@@ -0,0 +1,85 @@
+package mail
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite(t *testing.T) {
+	date := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	content := strings.Repeat("wallet_id,name,currency\n", 20)
+
+	var buf bytes.Buffer
+	err := Write(&buf, "exports@example.com", Message{
+		To:      []string{"finance@example.com"},
+		Subject: "Weekly wallets export – March",
+		Body:    "Your export is attached.",
+		Attachments: []Attachment{
+			{Filename: "wallets-2025-03-03.csv", ContentType: "text/csv", Content: strings.NewReader(content)},
+		},
+	}, date)
+	require.NoError(t, err)
+
+	msg, err := mail.ReadMessage(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, "exports@example.com", msg.Header.Get("From"))
+	assert.Equal(t, "finance@example.com", msg.Header.Get("To"))
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Weekly wallets export – March", subject)
+	sent, err := msg.Header.Date()
+	require.NoError(t, err)
+	assert.True(t, date.Equal(sent))
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/mixed", mediaType)
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+
+	text, err := parts.NextPart()
+	require.NoError(t, err)
+	body, err := io.ReadAll(text)
+	require.NoError(t, err)
+	assert.Equal(t, "Your export is attached.", string(body))
+
+	attachment, err := parts.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "wallets-2025-03-03.csv", attachment.FileName())
+	assert.Equal(t, "text/csv", attachment.Header.Get("Content-Type"))
+	encoded, err := io.ReadAll(attachment)
+	require.NoError(t, err)
+	for _, line := range strings.Split(strings.TrimSpace(string(encoded)), "\r\n") {
+		assert.LessOrEqual(t, len(line), base64LineLength)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(encoded), "\r\n", ""))
+	require.NoError(t, err)
+	assert.Equal(t, content, string(decoded))
+
+	_, err = parts.NextPart()
+	assert.Equal(t, io.EOF, err)
+
+	t.Run("plain text", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, Write(&buf, "exports@example.com", Message{To: []string{"a@example.com"}, Subject: "Hi", Body: "Hello"}, date))
+		msg, err := mail.ReadMessage(&buf)
+		require.NoError(t, err)
+		assert.Equal(t, "text/plain; charset=utf-8", msg.Header.Get("Content-Type"))
+		body, err := io.ReadAll(msg.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "Hello", string(body))
+	})
+
+	t.Run("no recipient", func(t *testing.T) {
+		assert.Error(t, Write(io.Discard, "exports@example.com", Message{Subject: "Hi"}, date))
+	})
+}
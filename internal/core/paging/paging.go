@@ -0,0 +1,88 @@
+// Package paging scans everything a paginated query returns, page after page, for the
+// internal full scans of exports and maintenance jobs
+package paging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Stop ends a scan early when returned by the function handed each item, the scan then
+// returns nil
+var Stop = errors.New("stop paging")
+
+// ErrStuckCursor is returned when a page hands back the cursor it was fetched with, the
+// scan would read the same page forever otherwise
+var ErrStuckCursor = errors.New("paging: cursor did not advance")
+
+// Fetch returns the page of items after cursor and the cursor of the page after it. The
+// zero cursor fetches the first page, and returned as the next one it marks the last
+// page, like an empty page does.
+type Fetch[T any, C comparable] func(ctx context.Context, cursor C) (items []T, next C, err error)
+
+// ForEachPage hands every item fetch returns to fn, fetching pages until one comes back
+// empty or without a next cursor. The context is checked between pages, an error from
+// fn ends the scan and is returned as is, except Stop which ends it without error.
+func ForEachPage[T any, C comparable](ctx context.Context, fetch Fetch[T, C], fn func(item T) error) error {
+	var cursor, zero C
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		items, next, err := fetch(ctx, cursor)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err := fn(item); err != nil {
+				if errors.Is(err, Stop) {
+					return nil
+				}
+				return err
+			}
+		}
+		if len(items) == 0 || next == zero {
+			return nil
+		}
+		if next == cursor {
+			return fmt.Errorf("%w: %v", ErrStuckCursor, next)
+		}
+		cursor = next
+	}
+}
+
+// Items is ForEachPage sending the items on a channel, closed once the scan ends. wait
+// returns how the scan ended once the channel is drained. A consumer leaving early
+// cancels ctx so the scan stops instead of blocking on its next send.
+func Items[T any, C comparable](ctx context.Context, fetch Fetch[T, C]) (items <-chan T, wait func() error) {
+	out := make(chan T)
+	done := make(chan struct{})
+	var err error
+	go func() {
+		defer close(done)
+		defer close(out)
+		err = ForEachPage(ctx, fetch, func(item T) error {
+			select {
+			case out <- item:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+	return out, func() error {
+		<-done
+		return err
+	}
+}
+
+// Next returns the cursor of the page after items, read with limit: the cursor of the
+// last item, or the zero cursor when the page came back short and so was the last one
+func Next[T any, C comparable](items []T, limit int, cursor func(item T) C) C {
+	var zero C
+	if len(items) == 0 || len(items) < limit {
+		return zero
+	}
+	return cursor(items[len(items)-1])
+}
@@ -0,0 +1,164 @@
+package paging
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// offsetFetch pages through items by offset, limit at a time, counting the queries
+func offsetFetch(items []int, limit int, queries *int) Fetch[int, int] {
+	return func(ctx context.Context, offset int) ([]int, int, error) {
+		*queries++
+		end := min(offset+limit, len(items))
+		page := items[min(offset, len(items)):end]
+		return page, Next(page, limit, func(int) int { return end }), nil
+	}
+}
+
+func sequence(n int) []int {
+	items := make([]int, n)
+	for i := range items {
+		items[i] = i + 1
+	}
+	return items
+}
+
+func TestForEachPage_PageBoundaries(t *testing.T) {
+	tests := []struct {
+		name            string
+		items           int
+		limit           int
+		expectedQueries int
+	}{
+		{"no items", 0, 1, 1},
+		{"no items limit 2", 0, 2, 1},
+		{"single item limit 1", 1, 1, 2},
+		{"single item limit 2", 1, 2, 1},
+		{"full pages limit 1", 3, 1, 4},
+		{"full pages limit 2", 4, 2, 3},
+		{"short last page limit 2", 5, 2, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var queries int
+			got := []int{}
+			err := ForEachPage(context.Background(), offsetFetch(sequence(tt.items), tt.limit, &queries), func(item int) error {
+				got = append(got, item)
+				return nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, sequence(tt.items), got, "every item once, in order")
+			assert.Equal(t, tt.expectedQueries, queries)
+		})
+	}
+}
+
+func TestForEachPage_StuckCursor(t *testing.T) {
+	var queries int
+	err := ForEachPage(context.Background(), func(ctx context.Context, cursor int) ([]int, int, error) {
+		queries++
+		// every page points at cursor 7, so the second one hands back the cursor it was fetched with
+		return []int{1, 2}, 7, nil
+	}, func(int) error { return nil })
+
+	assert.ErrorIs(t, err, ErrStuckCursor)
+	assert.EqualError(t, err, "paging: cursor did not advance: 7")
+	assert.Equal(t, 2, queries)
+}
+
+func TestForEachPage_EarlyTermination(t *testing.T) {
+	t.Run("stop ends the scan without error", func(t *testing.T) {
+		var queries, seen int
+		err := ForEachPage(context.Background(), offsetFetch(sequence(10), 2, &queries), func(item int) error {
+			seen++
+			if item == 3 {
+				return Stop
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, seen)
+		assert.Equal(t, 2, queries, "no page is fetched past the stop")
+	})
+
+	t.Run("other errors are returned as is", func(t *testing.T) {
+		failed := errors.New("disk full")
+		var queries int
+		err := ForEachPage(context.Background(), offsetFetch(sequence(10), 2, &queries), func(item int) error {
+			if item == 4 {
+				return failed
+			}
+			return nil
+		})
+		assert.Equal(t, failed, err)
+		assert.Equal(t, 2, queries)
+	})
+
+	t.Run("fetch errors are returned as is", func(t *testing.T) {
+		failed := errors.New("connection reset")
+		err := ForEachPage(context.Background(), func(ctx context.Context, cursor int) ([]int, int, error) {
+			return nil, 0, failed
+		}, func(int) error { return nil })
+		assert.Equal(t, failed, err)
+	})
+}
+
+func TestForEachPage_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var queries, seen int
+	err := ForEachPage(ctx, offsetFetch(sequence(10), 2, &queries), func(item int) error {
+		seen++
+		if item == 3 {
+			// the rest of the page is still handed over, the next one isn't fetched
+			cancel()
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 4, seen)
+	assert.Equal(t, 2, queries)
+}
+
+func TestItems(t *testing.T) {
+	t.Run("sends every item", func(t *testing.T) {
+		for _, limit := range []int{1, 2} {
+			var queries int
+			items, wait := Items(context.Background(), offsetFetch(sequence(5), limit, &queries))
+			var got []int
+			for item := range items {
+				got = append(got, item)
+			}
+			require.NoError(t, wait())
+			assert.Equal(t, sequence(5), got, "limit %d", limit)
+		}
+	})
+
+	t.Run("consumer leaving early cancels the scan", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var queries int
+		items, wait := Items(ctx, offsetFetch(sequence(100), 2, &queries))
+		assert.Equal(t, 1, <-items)
+		cancel()
+		assert.ErrorIs(t, wait(), context.Canceled)
+		assert.Less(t, queries, 50)
+	})
+
+	t.Run("scan errors are reported by wait", func(t *testing.T) {
+		items, wait := Items(context.Background(), func(ctx context.Context, cursor int) ([]int, int, error) {
+			return []int{1}, 1, nil
+		})
+		var got []int
+		for item := range items {
+			got = append(got, item)
+		}
+		assert.Equal(t, []int{1, 1}, got)
+		assert.ErrorIs(t, wait(), ErrStuckCursor)
+	})
+}
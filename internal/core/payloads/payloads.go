@@ -4,27 +4,81 @@ import (
 	"net/http"
 
 	"github.com/go-chi/render"
+	"github.com/google/uuid"
 )
 
 const (
-	DeleteMessage = "Resource deleted successfully"
-	UpdateMessage = "Resource updated successfully"
-	CreateMessage = "Resource created successfully"
-	OkMessage     = "Success"
+	DeleteMessage   = "Resource deleted successfully"
+	UpdateMessage   = "Resource updated successfully"
+	CreateMessage   = "Resource created successfully"
+	OkMessage       = "Success"
+	AcceptedMessage = "Request accepted for processing"
+	RestoreMessage  = "Resource restored successfully"
 )
 
 // Response represents the standard API response format
 // @Description Standard API response wrapper
 type Response struct {
 	Status  int         `json:"status" example:"200" enums:"200,202,204"`
-	Message string      `json:"message,omitempty" example:"Success" enums:"Success,Resource created successfully,Resource updated successfully,Resource deleted successfully"`
+	Message string      `json:"message,omitempty" example:"Success" enums:"Success,Request accepted for processing,Resource created successfully,Resource updated successfully,Resource deleted successfully,Resource restored successfully"`
 	Data    interface{} `json:"data,omitempty"`
 	Meta    struct {
-		Query     string `json:"query,omitempty"`
-		Limit     int32  `json:"limit,omitempty"`
-		Count     int    `json:"count,omitempty"`
-		NextToken string `json:"next_token,omitempty"`
+		Query     string   `json:"query,omitempty"`
+		Limit     int32    `json:"limit,omitempty"`
+		Count     int      `json:"count,omitempty"`
+		NextToken string   `json:"next_token,omitempty"`
+		Links     *Links   `json:"links,omitempty"`
+		Warnings  []string `json:"warnings,omitempty"`
+		// View is how much of each entity a search returned, full or picker
+		View string `json:"view,omitempty"`
+		// Trimmed is how many results trim=auto cut after the cliff of their scores
+		Trimmed int `json:"trimmed,omitempty"`
+		// Cutoff is the score of the last result trim=auto kept
+		Cutoff *float32 `json:"cutoff,omitempty"`
+		// Missing are the IDs a fetch by ID found nothing for
+		Missing []uuid.UUID `json:"missing,omitempty"`
+		// DryRun marks the report of a maintenance operation that was rolled back
+		DryRun bool `json:"dry_run,omitempty"`
 	} `json:"meta"`
+
+	// paginated responses get their links from the request when rendered
+	paginated bool
+}
+
+// Links are the URLs of the pages around a paginated response, they keep the query
+// parameters of the request and only swap the next_token
+// @Description Links to navigate a paginated list
+type Links struct {
+	Self  string `json:"self" example:"/api/v1/wallets?limit=20&next_token=MTcwNDA2NzIwMDAwMDAwMDAwMA=="`
+	First string `json:"first" example:"/api/v1/wallets?limit=20"`
+	Next  string `json:"next,omitempty" example:"/api/v1/wallets?limit=20&next_token=MTcwNDE1MzYwMDAwMDAwMDAwMA=="`
+}
+
+// SetLinks fills the links of a paginated response from the request it answers, other
+// responses are left untouched
+func (rd *Response) SetLinks(r *http.Request) {
+	if !rd.paginated {
+		return
+	}
+	query := r.URL.Query()
+	link := func(token string) string {
+		query.Del("next_token")
+		if token != "" {
+			query.Set("next_token", token)
+		}
+		if len(query) == 0 {
+			return r.URL.Path
+		}
+		return r.URL.Path + "?" + query.Encode()
+	}
+
+	rd.Meta.Links = &Links{
+		Self:  link(query.Get("next_token")),
+		First: link(""),
+	}
+	if rd.Meta.NextToken != "" {
+		rd.Meta.Links.Next = link(rd.Meta.NextToken)
+	}
 }
 
 func (rd *Response) Render(w http.ResponseWriter, r *http.Request) error {
@@ -50,10 +104,18 @@ func Created(data interface{}) render.Renderer {
 	return NewResponse(http.StatusCreated, CreateMessage, data)
 }
 
+func Accepted(data interface{}) render.Renderer {
+	return NewResponse(http.StatusAccepted, AcceptedMessage, data)
+}
+
 func Updated(data interface{}) render.Renderer {
 	return NewResponse(http.StatusOK, UpdateMessage, data)
 }
 
+func Restored(data interface{}) render.Renderer {
+	return NewResponse(http.StatusOK, RestoreMessage, data)
+}
+
 func Deleted() render.Renderer {
 	return NewResponse(http.StatusOK, DeleteMessage, nil)
 }
@@ -72,6 +134,13 @@ func List(data interface{}, count int) render.Renderer {
 	return resp
 }
 
+// Batch creates the response of a fetch by ID, with the IDs nothing was found for
+func Batch(data interface{}, count int, missing []uuid.UUID) render.Renderer {
+	resp := List(data, count).(*Response)
+	resp.Meta.Missing = missing
+	return resp
+}
+
 // Search creates a new search response
 func Search(data interface{}, query string, limit int32, count int) render.Renderer {
 	resp := &Response{
@@ -85,6 +154,41 @@ func Search(data interface{}, query string, limit int32, count int) render.Rende
 	return resp
 }
 
+// PaginatedSearch creates a new search response carrying the token for the next page
+func PaginatedSearch(data interface{}, query string, limit int32, count int, nextToken string) render.Renderer {
+	resp := Search(data, query, limit, count).(*Response)
+	resp.Meta.NextToken = nextToken
+	resp.paginated = true
+	return resp
+}
+
+// PaginatedSearchView creates a paginated search response naming the view its entities
+// are in
+func PaginatedSearchView(data interface{}, query string, limit int32, count int, nextToken, view string) render.Renderer {
+	resp := PaginatedSearch(data, query, limit, count, nextToken).(*Response)
+	resp.Meta.View = view
+	return resp
+}
+
+// WithTrim notes on a search response how many results trim=auto cut and the score of
+// the last one it kept. A trimmed search has no next page, what follows the cut ranks
+// lower still.
+func WithTrim(renderer render.Renderer, trimmed int, cutoff float32) render.Renderer {
+	resp := renderer.(*Response)
+	resp.Meta.Trimmed = trimmed
+	resp.Meta.Cutoff = &cutoff
+	resp.Meta.NextToken = ""
+	return resp
+}
+
+// DryRun creates the response of a maintenance operation run with dry_run=true, data
+// reports what it would have changed
+func DryRun(data interface{}) render.Renderer {
+	resp := OK(data).(*Response)
+	resp.Meta.DryRun = true
+	return resp
+}
+
 // Paginated creates a new paginated response
 func Paginated(data interface{}, nextToken string, limit int32) render.Renderer {
 	resp := &Response{
@@ -94,5 +198,20 @@ func Paginated(data interface{}, nextToken string, limit int32) render.Renderer
 	}
 	resp.Meta.NextToken = nextToken
 	resp.Meta.Limit = limit
+	resp.paginated = true
+	return resp
+}
+
+// IDPage is the data of a paginated list requested with only_ids
+// @Description IDs of a page of a list, in the order of the list
+type IDPage struct {
+	IDs       []uuid.UUID `json:"ids"`
+	NextToken string      `json:"next_token,omitempty" example:"MTcwNDE1MzYwMDAwMDAwMDAwMA=="`
+}
+
+// PaginatedIDs creates a paginated response carrying only the IDs of the page
+func PaginatedIDs(ids []uuid.UUID, nextToken string, limit int32) render.Renderer {
+	resp := Paginated(IDPage{IDs: ids, NextToken: nextToken}, nextToken, limit).(*Response)
+	resp.Meta.Count = len(ids)
 	return resp
 }
@@ -0,0 +1,269 @@
+// Package phone normalizes phone numbers to E.164 for the regions it carries numbering
+// metadata for
+package phone
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Type is the kind of line a number belongs to, as far as its prefix tells
+type Type string
+
+const (
+	TypeMobile    Type = "mobile"
+	TypeFixedLine Type = "fixed_line"
+	// TypeFixedLineOrMobile is reported where mobile and fixed lines share prefixes, like
+	// in the North American Numbering Plan
+	TypeFixedLineOrMobile Type = "fixed_line_or_mobile"
+	TypeTollFree          Type = "toll_free"
+	TypeUnknown           Type = "unknown"
+)
+
+var (
+	// ErrInvalidNumber is returned for numbers that can't be normalized
+	ErrInvalidNumber = errors.New("invalid phone number")
+	// ErrUnsupportedRegion is returned for regions without numbering metadata
+	ErrUnsupportedRegion = errors.New("unsupported region")
+)
+
+// E.164 numbers hold at most 15 digits, country calling code included
+const (
+	minDigits = 4
+	maxDigits = 15
+)
+
+// formatting are the characters numbers are commonly written with besides their digits
+const formatting = " -.()/"
+
+// Number is a phone number normalized to E.164
+type Number struct {
+	// E164 is the number as it is stored, + followed by the calling code and the national number
+	E164 string `json:"e164" example:"+442079460958"`
+	// Region is the ISO 3166 alpha-2 code of the region the number belongs to
+	Region string `json:"region" example:"GB"`
+	Type   Type   `json:"type" example:"fixed_line" enums:"mobile,fixed_line,fixed_line_or_mobile,toll_free,unknown"`
+}
+
+// region is the numbering metadata of a region, prefixes are of the national significant
+// number, the number without its calling code and trunk prefix
+type region struct {
+	callingCode string
+	// trunkPrefix is dialed before national numbers within the region
+	trunkPrefix string
+	lengths     []int
+	tollFree    []string
+	mobile      []string
+	fixedLine   []string
+	// shared marks regions whose mobile and fixed lines can't be told apart
+	shared bool
+}
+
+var regions = map[string]region{
+	"AE": {callingCode: "971", trunkPrefix: "0", lengths: []int{8, 9},
+		tollFree: []string{"800"}, mobile: []string{"50", "52", "54", "55", "56", "58"}, fixedLine: []string{"2", "3", "4", "6", "7", "9"}},
+	"CA": {callingCode: "1", trunkPrefix: "1", lengths: []int{10},
+		tollFree: nanpTollFree, shared: true},
+	"DE": {callingCode: "49", trunkPrefix: "0", lengths: []int{6, 7, 8, 9, 10, 11},
+		tollFree: []string{"800"}, mobile: []string{"15", "16", "17"}, fixedLine: []string{"2", "3", "4", "5", "6", "7", "8", "9"}},
+	"EG": {callingCode: "20", trunkPrefix: "0", lengths: []int{9, 10},
+		tollFree: []string{"800"}, mobile: []string{"10", "11", "12", "15"}, fixedLine: []string{"2", "3", "4", "5", "6", "8", "9"}},
+	"FR": {callingCode: "33", trunkPrefix: "0", lengths: []int{9},
+		tollFree: []string{"80"}, mobile: []string{"6", "7"}, fixedLine: []string{"1", "2", "3", "4", "5", "9"}},
+	"GB": {callingCode: "44", trunkPrefix: "0", lengths: []int{9, 10},
+		tollFree: []string{"800", "808"}, mobile: []string{"71", "72", "73", "74", "75", "77", "78", "79"}, fixedLine: []string{"1", "2"}},
+	"SA": {callingCode: "966", trunkPrefix: "0", lengths: []int{9, 10},
+		tollFree: []string{"800"}, mobile: []string{"5"}, fixedLine: []string{"1"}},
+	"US": {callingCode: "1", trunkPrefix: "1", lengths: []int{10},
+		tollFree: nanpTollFree, shared: true},
+}
+
+// nanpTollFree are the toll free area codes of the North American Numbering Plan
+var nanpTollFree = []string{"800", "833", "844", "855", "866", "877", "888"}
+
+// canadianAreaCodes tell Canadian numbers from the US ones sharing calling code 1
+var canadianAreaCodes = []string{
+	"204", "226", "236", "249", "250", "263", "289", "306", "343", "354", "365", "367", "368", "382",
+	"403", "416", "418", "428", "431", "437", "438", "450", "468", "474", "506", "514", "519", "548",
+	"579", "581", "584", "587", "604", "613", "639", "647", "672", "683", "705", "709", "742", "753",
+	"778", "780", "782", "807", "819", "825", "867", "873", "879", "902", "905",
+}
+
+// otherNANPAreaCodes belong to the other countries sharing calling code 1, they have no metadata
+var otherNANPAreaCodes = []string{
+	"242", "246", "264", "268", "284", "340", "345", "441", "473", "649", "658", "664", "670", "671",
+	"684", "721", "758", "767", "784", "787", "809", "829", "849", "868", "869", "876", "939",
+}
+
+// Regions returns the codes of the regions numbers can be normalized for, sorted
+func Regions() []string {
+	codes := make([]string, 0, len(regions))
+	for code := range regions {
+		codes = append(codes, code)
+	}
+	slices.Sort(codes)
+	return codes
+}
+
+// Normalize parses number into E.164. A number starting with + or 00 carries its calling
+// code, any other is a national number of defaultRegion, which is then required. The
+// region reported is the one the number belongs to, which may differ from defaultRegion.
+func Normalize(number, defaultRegion string) (Number, error) {
+	defaultRegion = strings.ToUpper(strings.TrimSpace(defaultRegion))
+	if _, ok := regions[defaultRegion]; defaultRegion != "" && !ok {
+		return Number{}, fmt.Errorf("%w %s (supported: %s)", ErrUnsupportedRegion, defaultRegion, strings.Join(Regions(), ", "))
+	}
+
+	digits, international, err := clean(number)
+	if err != nil {
+		return Number{}, err
+	}
+
+	callingCode := ""
+	if international {
+		callingCode = splitCallingCode(digits)
+		if callingCode == "" {
+			return Number{}, fmt.Errorf("%w: unknown or unsupported country calling code", ErrInvalidNumber)
+		}
+		digits = digits[len(callingCode):]
+	} else {
+		if defaultRegion == "" {
+			return Number{}, fmt.Errorf("%w: a number without a country calling code needs a region", ErrInvalidNumber)
+		}
+		callingCode = regions[defaultRegion].callingCode
+	}
+
+	code, err := regionOf(callingCode, digits, defaultRegion)
+	if err != nil {
+		return Number{}, err
+	}
+	meta := regions[code]
+	// the trunk prefix is left out of E.164, it is often kept after the calling code too
+	national := strings.TrimPrefix(digits, meta.trunkPrefix)
+	if !slices.Contains(meta.lengths, len(national)) {
+		return Number{}, fmt.Errorf("%w: %s numbers have %s digits after the calling code", ErrInvalidNumber, code, joinLengths(meta.lengths))
+	}
+	if meta.callingCode == "1" && (national[0] < '2' || national[3] < '2') {
+		return Number{}, fmt.Errorf("%w: area codes and exchanges can't start with 0 or 1", ErrInvalidNumber)
+	}
+	if national[0] == '0' {
+		return Number{}, fmt.Errorf("%w: the national number can't start with 0", ErrInvalidNumber)
+	}
+
+	return Number{
+		E164:   "+" + meta.callingCode + national,
+		Region: code,
+		Type:   meta.typeOf(national),
+	}, nil
+}
+
+// clean strips the formatting off number, reporting whether it carries a calling code
+func clean(number string) (string, bool, error) {
+	number = strings.TrimSpace(number)
+	international := strings.HasPrefix(number, "+")
+	number = strings.TrimPrefix(number, "+")
+
+	var digits strings.Builder
+	for _, r := range number {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case strings.ContainsRune(formatting, r):
+		default:
+			return "", false, fmt.Errorf("%w: unexpected character %q", ErrInvalidNumber, r)
+		}
+	}
+
+	cleaned := digits.String()
+	// 00 is the international call prefix of most regions
+	if !international && strings.HasPrefix(cleaned, "00") {
+		international = true
+		cleaned = cleaned[2:]
+	}
+	if len(cleaned) < minDigits {
+		return "", false, fmt.Errorf("%w: too short", ErrInvalidNumber)
+	}
+	if len(cleaned) > maxDigits {
+		return "", false, fmt.Errorf("%w: too long, E.164 numbers have at most %d digits", ErrInvalidNumber, maxDigits)
+	}
+	return cleaned, international, nil
+}
+
+// splitCallingCode returns the calling code digits start with, calling codes are prefix
+// free so at most one of their one to three digit prefixes matches
+func splitCallingCode(digits string) string {
+	for size := 1; size <= 3 && size < len(digits); size++ {
+		for _, meta := range regions {
+			if meta.callingCode == digits[:size] {
+				return meta.callingCode
+			}
+		}
+	}
+	return ""
+}
+
+// regionOf returns the region of the number, the regions sharing calling code 1 are told
+// apart by area code and defaultRegion settles nothing else
+func regionOf(callingCode, digits, defaultRegion string) (string, error) {
+	if callingCode != "1" {
+		for code, meta := range regions {
+			if meta.callingCode == callingCode {
+				return code, nil
+			}
+		}
+	}
+
+	national := strings.TrimPrefix(digits, "1")
+	if len(national) < 3 {
+		return "", fmt.Errorf("%w: too short", ErrInvalidNumber)
+	}
+	area := national[:3]
+	switch {
+	case slices.Contains(canadianAreaCodes, area):
+		return "CA", nil
+	case slices.Contains(otherNANPAreaCodes, area):
+		return "", fmt.Errorf("%w: area code %s is outside the supported regions", ErrInvalidNumber, area)
+	case slices.Contains(nanpTollFree, area) && defaultRegion == "CA":
+		// toll free numbers are shared by the US and Canada
+		return "CA", nil
+	}
+	return "US", nil
+}
+
+// typeOf tells the type of the national number from its prefix
+func (r region) typeOf(national string) Type {
+	switch {
+	case hasAnyPrefix(national, r.tollFree):
+		return TypeTollFree
+	case r.shared:
+		return TypeFixedLineOrMobile
+	case hasAnyPrefix(national, r.mobile):
+		return TypeMobile
+	case hasAnyPrefix(national, r.fixedLine):
+		return TypeFixedLine
+	}
+	return TypeUnknown
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// joinLengths writes lengths for an error message, like "9 or 10"
+func joinLengths(lengths []int) string {
+	parts := make([]string, len(lengths))
+	for i, length := range lengths {
+		parts[i] = fmt.Sprint(length)
+	}
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	return strings.Join(parts[:len(parts)-1], ", ") + " or " + parts[len(parts)-1]
+}
@@ -0,0 +1,72 @@
+package phone
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		number   string
+		region   string
+		expected Number
+	}{
+		{"international UK landline", "+44 20 7946 0958", "", Number{"+442079460958", "GB", TypeFixedLine}},
+		{"trunk prefix kept after the calling code", "+44 (0)20 7946 0958", "", Number{"+442079460958", "GB", TypeFixedLine}},
+		{"national UK mobile", "07911 123456", "gb", Number{"+447911123456", "GB", TypeMobile}},
+		{"00 international prefix", "0033 6 12 34 56 78", "", Number{"+33612345678", "FR", TypeMobile}},
+		{"national Egyptian mobile", "010 1234 5678", "EG", Number{"+201012345678", "EG", TypeMobile}},
+		{"Cairo landline", "+20 2 2345 6789", "", Number{"+20223456789", "EG", TypeFixedLine}},
+		{"Saudi toll free", "800 123 4567", "SA", Number{"+9668001234567", "SA", TypeTollFree}},
+		{"US number", "(415) 555-2671", "US", Number{"+14155552671", "US", TypeFixedLineOrMobile}},
+		{"US number with the trunk prefix", "1-415-555-2671", "US", Number{"+14155552671", "US", TypeFixedLineOrMobile}},
+		{"Canadian area code", "+1 416 555 0100", "", Number{"+14165550100", "CA", TypeFixedLineOrMobile}},
+		{"Canadian area code dialed as US", "416.555.0100", "US", Number{"+14165550100", "CA", TypeFixedLineOrMobile}},
+		{"toll free number", "+1 800 555 0199", "", Number{"+18005550199", "US", TypeTollFree}},
+		{"toll free number dialed in Canada", "1 800 555 0199", "CA", Number{"+18005550199", "CA", TypeTollFree}},
+		{"region detected over the default", "+49 30 1234567", "FR", Number{"+49301234567", "DE", TypeFixedLine}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.number, tt.region)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestNormalize_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		number  string
+		region  string
+		message string
+	}{
+		{"letters", "+44 20 CALL NOW", "", `invalid phone number: unexpected character 'C'`},
+		{"no calling code or region", "020 7946 0958", "", "invalid phone number: a number without a country calling code needs a region"},
+		{"unsupported calling code", "+7 495 123 4567", "", "invalid phone number: unknown or unsupported country calling code"},
+		{"wrong length", "+44 20 7946 09", "", "invalid phone number: GB numbers have 9 or 10 digits after the calling code"},
+		{"too short", "12", "US", "invalid phone number: too short"},
+		{"too long", "+44 1234 5678 9012 3456", "", "invalid phone number: too long, E.164 numbers have at most 15 digits"},
+		{"area code starting with 1", "+1 115 555 2671", "", "invalid phone number: US numbers have 10 digits after the calling code"},
+		{"exchange starting with 0", "+1 415 055 2671", "", "invalid phone number: area codes and exchanges can't start with 0 or 1"},
+		{"other NANP country", "+1 876 555 0100", "", "invalid phone number: area code 876 is outside the supported regions"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Normalize(tt.number, tt.region)
+			assert.ErrorIs(t, err, ErrInvalidNumber)
+			assert.EqualError(t, err, tt.message)
+		})
+	}
+
+	_, err := Normalize("+44 20 7946 0958", "ZZ")
+	assert.True(t, errors.Is(err, ErrUnsupportedRegion))
+	assert.EqualError(t, err, "unsupported region ZZ (supported: AE, CA, DE, EG, FR, GB, SA, US)")
+}
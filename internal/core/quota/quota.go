@@ -0,0 +1,92 @@
+// Package quota caps how many contacts, projects and wallets a user can have outside
+// the trash
+package quota
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/google/uuid"
+)
+
+// Resource is a kind of resource with a quota
+type Resource string
+
+const (
+	Contacts Resource = "contacts"
+	Projects Resource = "projects"
+	Wallets  Resource = "wallets"
+)
+
+// Limits are the most resources of each kind a user can have, 0 leaves the kind unlimited
+type Limits struct {
+	Contacts int64
+	Projects int64
+	Wallets  int64
+}
+
+func (l Limits) of(resource Resource) int64 {
+	switch resource {
+	case Contacts:
+		return l.Contacts
+	case Projects:
+		return l.Projects
+	case Wallets:
+		return l.Wallets
+	}
+	return 0
+}
+
+// Counter reads the counts of a user's resources, *db.Queries is one
+type Counter interface {
+	GetUserResourceCounts(ctx context.Context, userID uuid.UUID) (db.GetUserResourceCountsRow, error)
+}
+
+// Checker checks creates against the limits. The counts are columns of the user kept by
+// triggers, a check reads them rather than counting the rows.
+type Checker struct {
+	counter Counter
+	limits  Limits
+}
+
+// NewChecker returns a checker of the limits reading the counts from counter
+func NewChecker(counter Counter, limits Limits) *Checker {
+	return &Checker{counter: counter, limits: limits}
+}
+
+// Using returns a checker of the same limits reading the counts from counter, for checks
+// made inside a transaction to see its own creates
+func (c *Checker) Using(counter Counter) *Checker {
+	if c == nil {
+		return nil
+	}
+	return &Checker{counter: counter, limits: c.limits}
+}
+
+// Check returns the quota exceeded error when adding resources would take the user past
+// the quota of the resource. A nil checker and resources without a quota always pass.
+func (c *Checker) Check(ctx context.Context, userID uuid.UUID, resource Resource, adding int) error {
+	if c == nil || adding <= 0 {
+		return nil
+	}
+	limit := c.limits.of(resource)
+	if limit <= 0 {
+		return nil
+	}
+
+	counts, err := c.counter.GetUserResourceCounts(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("count %s: %w", resource, err)
+	}
+	count := map[Resource]int32{
+		Contacts: counts.ContactCount,
+		Projects: counts.ProjectCount,
+		Wallets:  counts.WalletCount,
+	}[resource]
+	if int64(count)+int64(adding) > limit {
+		return errors.NewQuotaExceededError(string(resource), limit)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package quota
+
+import (
+	"context"
+	stdErrors "errors"
+	"testing"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCounter struct {
+	counts db.GetUserResourceCountsRow
+	err    error
+	calls  int
+}
+
+func (f *fakeCounter) GetUserResourceCounts(ctx context.Context, userID uuid.UUID) (db.GetUserResourceCountsRow, error) {
+	f.calls++
+	return f.counts, f.err
+}
+
+func TestChecker_Check(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	counter := &fakeCounter{counts: db.GetUserResourceCountsRow{ContactCount: 4, ProjectCount: 2, WalletCount: 9}}
+	checker := NewChecker(counter, Limits{Contacts: 5, Wallets: 10})
+
+	require.NoError(t, checker.Check(ctx, userID, Contacts, 1))
+	require.NoError(t, checker.Check(ctx, userID, Wallets, 1))
+
+	err := checker.Check(ctx, userID, Contacts, 2)
+	assert.True(t, errors.IsErrorType(err, errors.ErrorTypeQuotaExceeded))
+	assert.Contains(t, err.Error(), "contacts: the quota of 5 is reached")
+	err = checker.Check(ctx, userID, Wallets, 2)
+	assert.True(t, errors.IsErrorType(err, errors.ErrorTypeQuotaExceeded))
+
+	t.Run("unlimited resources aren't counted", func(t *testing.T) {
+		calls := counter.calls
+		require.NoError(t, checker.Check(ctx, userID, Projects, 100))
+		require.NoError(t, checker.Check(ctx, userID, Contacts, 0))
+		assert.Equal(t, calls, counter.calls)
+	})
+
+	t.Run("nil checker", func(t *testing.T) {
+		var checker *Checker
+		require.NoError(t, checker.Check(ctx, userID, Contacts, 1))
+		assert.Nil(t, checker.Using(counter))
+	})
+
+	t.Run("using another counter", func(t *testing.T) {
+		full := &fakeCounter{counts: db.GetUserResourceCountsRow{ContactCount: 5}}
+		err := checker.Using(full).Check(ctx, userID, Contacts, 1)
+		assert.True(t, errors.IsErrorType(err, errors.ErrorTypeQuotaExceeded))
+		assert.Equal(t, 1, full.calls)
+	})
+
+	t.Run("counter error", func(t *testing.T) {
+		failing := &fakeCounter{err: stdErrors.New("connection reset")}
+		err := NewChecker(failing, Limits{Contacts: 5}).Check(ctx, userID, Contacts, 1)
+		require.Error(t, err)
+		assert.False(t, errors.IsErrorType(err, errors.ErrorTypeQuotaExceeded))
+	})
+}
@@ -0,0 +1,59 @@
+// Package ranking cuts ranked search results down to the ones standing out from the rest
+package ranking
+
+// MinCliffDrop is the smallest fall between consecutive scores, relative to the higher
+// one, counted as a cliff. Gentler slopes and flat stretches are left whole.
+const MinCliffDrop = 0.2
+
+// Trim is how a ranked list was cut at its cliff
+type Trim struct {
+	// Trimmed is how many results were cut
+	Trimmed int
+	// Cutoff is the score of the last result kept
+	Cutoff float32
+}
+
+// FindCliff finds the largest drop, relative to the score before it, between consecutive
+// scores ranked best first and returns how many scores come before it. At least
+// minResults are kept, and ok is false when no drop reaches MinCliffDrop. The first of
+// equal drops wins.
+func FindCliff(scores []float32, minResults int) (keep int, ok bool) {
+	if minResults < 1 {
+		minResults = 1
+	}
+	var largest float32
+	for i := minResults; i < len(scores); i++ {
+		previous := scores[i-1]
+		if previous <= 0 {
+			// nothing falls relative to a score of zero
+			break
+		}
+		if drop := (previous - scores[i]) / previous; drop > largest {
+			keep, largest = i, drop
+		}
+	}
+	if largest < MinCliffDrop {
+		return len(scores), false
+	}
+	return keep, true
+}
+
+// TrimAtCliff cuts results ranked best first at the cliff of their scores, keeping at
+// least minResults. score returns nil for an unranked result, a list holding one is left
+// whole as is a list without a cliff, and the trim is nil then.
+func TrimAtCliff[T any](results []T, minResults int, score func(T) *float32) ([]T, *Trim) {
+	scores := make([]float32, len(results))
+	for i, result := range results {
+		s := score(result)
+		if s == nil {
+			return results, nil
+		}
+		scores[i] = *s
+	}
+
+	keep, ok := FindCliff(scores, minResults)
+	if !ok {
+		return results, nil
+	}
+	return results[:keep], &Trim{Trimmed: len(results) - keep, Cutoff: scores[keep-1]}
+}
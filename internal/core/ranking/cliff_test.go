@@ -0,0 +1,86 @@
+package ranking
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindCliff(t *testing.T) {
+	tests := []struct {
+		name       string
+		scores     []float32
+		minResults int
+		wantKeep   int
+		wantOK     bool
+	}{
+		{name: "empty", scores: nil, minResults: 3, wantKeep: 0},
+		{name: "fewer than the minimum", scores: []float32{0.9, 0.2}, minResults: 3, wantKeep: 2},
+		{name: "flat", scores: []float32{0.5, 0.5, 0.5, 0.5, 0.5}, minResults: 3, wantKeep: 5},
+		{name: "gentle monotone slope", scores: []float32{0.9, 0.85, 0.8, 0.75, 0.7, 0.66}, minResults: 3, wantKeep: 6},
+		{name: "single cliff", scores: []float32{0.95, 0.9, 0.88, 0.86, 0.3, 0.28, 0.25}, minResults: 3, wantKeep: 4, wantOK: true},
+		{name: "multiple cliffs cut at the largest", scores: []float32{0.9, 0.88, 0.87, 0.6, 0.58, 0.2, 0.19}, minResults: 3, wantKeep: 5, wantOK: true},
+		{name: "equal cliffs cut at the first", scores: []float32{0.8, 0.8, 0.8, 0.4, 0.4, 0.2}, minResults: 3, wantKeep: 3, wantOK: true},
+		{name: "cliff above the minimum is skipped", scores: []float32{0.9, 0.3, 0.29, 0.28, 0.1}, minResults: 3, wantKeep: 4, wantOK: true},
+		{name: "only cliff above the minimum", scores: []float32{0.9, 0.3, 0.29, 0.28, 0.27}, minResults: 3, wantKeep: 5},
+		{name: "minimum of one", scores: []float32{0.9, 0.3, 0.29, 0.28}, minResults: 1, wantKeep: 1, wantOK: true},
+		{name: "minimum below one counts as one", scores: []float32{0.9, 0.3, 0.29}, minResults: 0, wantKeep: 1, wantOK: true},
+		{name: "drop just under the threshold", scores: []float32{1, 1, 1, 0.81, 0.81}, minResults: 3, wantKeep: 5},
+		{name: "drop just over the threshold", scores: []float32{1, 1, 1, 0.79, 0.79}, minResults: 3, wantKeep: 3, wantOK: true},
+		{name: "falls to zero", scores: []float32{0.7, 0.6, 0.6, 0, 0}, minResults: 3, wantKeep: 3, wantOK: true},
+		{name: "stops at zero scores", scores: []float32{0.7, 0.6, 0, 0, 0}, minResults: 3, wantKeep: 5},
+		{name: "rising scores are no cliff", scores: []float32{0.5, 0.5, 0.5, 0.9, 0.9}, minResults: 3, wantKeep: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keep, ok := FindCliff(tt.scores, tt.minResults)
+			assert.Equal(t, tt.wantKeep, keep)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.LessOrEqual(t, keep, len(tt.scores))
+		})
+	}
+}
+
+func TestTrimAtCliff(t *testing.T) {
+	type result struct {
+		name  string
+		score *float32
+	}
+	scored := func(scores ...float32) []result {
+		results := make([]result, len(scores))
+		for i := range scores {
+			results[i] = result{name: string(rune('a' + i)), score: &scores[i]}
+		}
+		return results
+	}
+	score := func(r result) *float32 { return r.score }
+
+	t.Run("cuts at the cliff", func(t *testing.T) {
+		results := scored(0.9, 0.85, 0.8, 0.3, 0.25)
+		kept, trim := TrimAtCliff(results, 3, score)
+		assert.Equal(t, results[:3], kept)
+		assert.Equal(t, &Trim{Trimmed: 2, Cutoff: 0.8}, trim)
+	})
+
+	t.Run("no cliff", func(t *testing.T) {
+		results := scored(0.9, 0.85, 0.8, 0.75)
+		kept, trim := TrimAtCliff(results, 3, score)
+		assert.Equal(t, results, kept)
+		assert.Nil(t, trim)
+	})
+
+	t.Run("unranked results are left whole", func(t *testing.T) {
+		results := scored(0.9, 0.85, 0.8, 0.3)
+		results[3].score = nil
+		kept, trim := TrimAtCliff(results, 3, score)
+		assert.Equal(t, results, kept)
+		assert.Nil(t, trim)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		kept, trim := TrimAtCliff([]result{}, 3, score)
+		assert.Empty(t, kept)
+		assert.Nil(t, trim)
+	})
+}
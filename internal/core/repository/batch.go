@@ -0,0 +1,24 @@
+package repository
+
+import "github.com/google/uuid"
+
+// OrderByIDs puts the rows a batch read found in the order of the IDs asked for, and
+// returns the IDs no row was found for. Those that don't exist, are trashed or belong to
+// another user are alike missing.
+func OrderByIDs[T any](ids []uuid.UUID, rows []T, id func(T) uuid.UUID) (found []T, missing []uuid.UUID) {
+	byID := make(map[uuid.UUID]T, len(rows))
+	for _, row := range rows {
+		byID[id(row)] = row
+	}
+
+	found = make([]T, 0, len(rows))
+	missing = make([]uuid.UUID, 0)
+	for _, requested := range ids {
+		if row, ok := byID[requested]; ok {
+			found = append(found, row)
+		} else {
+			missing = append(missing, requested)
+		}
+	}
+	return found, missing
+}
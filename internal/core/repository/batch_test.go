@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderByIDs(t *testing.T) {
+	type row struct{ ID uuid.UUID }
+	a, b, c, foreign := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	// the database returns the rows in no particular order and leaves out foreign ones
+	found, missing := OrderByIDs([]uuid.UUID{c, foreign, a, b}, []row{{a}, {b}, {c}}, func(r row) uuid.UUID { return r.ID })
+	assert.Equal(t, []row{{c}, {a}, {b}}, found)
+	assert.Equal(t, []uuid.UUID{foreign}, missing)
+
+	found, missing = OrderByIDs([]uuid.UUID{a}, nil, func(r row) uuid.UUID { return r.ID })
+	assert.Empty(t, found)
+	assert.Equal(t, []uuid.UUID{a}, missing)
+}
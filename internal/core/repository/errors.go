@@ -0,0 +1,17 @@
+// Package repository holds what the repositories of every resource share, such as
+// the sentinel errors callers check with errors.Is instead of matching messages.
+package repository
+
+import "errors"
+
+var (
+	// ErrNotFound is returned, wrapped, when the row a repository reads or writes doesn't
+	// exist, is trashed or belongs to another user
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is returned, wrapped, when a write collides with existing data such as
+	// a unique name
+	ErrConflict = errors.New("conflict")
+	// ErrPreconditionFailed is returned, wrapped, when a conditional write finds the row
+	// at another version than the one it expected, it changed since the caller read it
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
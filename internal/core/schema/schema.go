@@ -0,0 +1,206 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Draft is the JSON Schema dialect the documents are written in
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is the subset of JSON Schema used to describe request payloads. Modules build
+// them from the same constants their ozzo rules use so the two can't drift apart.
+type Schema struct {
+	Schema      string             `json:"$schema,omitempty"`
+	ID          string             `json:"$id,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Type        any                `json:"type,omitempty"` // a type name, or a list of them once nullable
+	Format      string             `json:"format,omitempty"`
+	Pattern     string             `json:"pattern,omitempty"`
+	Enum        []any              `json:"enum,omitempty"`
+	MinLength   *int               `json:"minLength,omitempty"`
+	MaxLength   *int               `json:"maxLength,omitempty"`
+	Minimum     *float64           `json:"minimum,omitempty"`
+	Maximum     *float64           `json:"maximum,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	MinItems    *int               `json:"minItems,omitempty"`
+	MaxItems    *int               `json:"maxItems,omitempty"`
+	UniqueItems bool               `json:"uniqueItems,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Defs        map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// String returns a string schema
+func String() *Schema {
+	return &Schema{Type: "string"}
+}
+
+// UUID returns a string schema holding a UUID
+func UUID() *Schema {
+	return String().As("uuid")
+}
+
+// Integer returns an integer schema
+func Integer() *Schema {
+	return &Schema{Type: "integer"}
+}
+
+// Number returns a number schema
+func Number() *Schema {
+	return &Schema{Type: "number"}
+}
+
+// Boolean returns a boolean schema
+func Boolean() *Schema {
+	return &Schema{Type: "boolean"}
+}
+
+// Array returns an array schema of items
+func Array(items *Schema) *Schema {
+	return &Schema{Type: "array", Items: items}
+}
+
+// Object returns an object schema with the properties, the required ones named
+func Object(properties map[string]*Schema, required ...string) *Schema {
+	return &Schema{Type: "object", Properties: properties, Required: required}
+}
+
+// Length bounds the length of a string, like validation.Length
+func (s *Schema) Length(min, max int) *Schema {
+	s.MinLength = &min
+	if max > 0 {
+		s.MaxLength = &max
+	}
+	return s
+}
+
+// Min sets the inclusive minimum of a number, like validation.Min
+func (s *Schema) Min(min float64) *Schema {
+	s.Minimum = &min
+	return s
+}
+
+// Max sets the inclusive maximum of a number, like validation.Max
+func (s *Schema) Max(max float64) *Schema {
+	s.Maximum = &max
+	return s
+}
+
+// Count bounds the number of items of an array, like validation.Length on a slice
+func (s *Schema) Count(min, max int) *Schema {
+	s.MinItems = &min
+	if max > 0 {
+		s.MaxItems = &max
+	}
+	return s
+}
+
+// Unique requires the items of an array to be distinct
+func (s *Schema) Unique() *Schema {
+	s.UniqueItems = true
+	return s
+}
+
+// As sets the format of a string
+func (s *Schema) As(format string) *Schema {
+	s.Format = format
+	return s
+}
+
+// Match sets the pattern a string must match
+func (s *Schema) Match(pattern string) *Schema {
+	s.Pattern = pattern
+	return s
+}
+
+// In limits the value to the listed ones, like validation.In
+func In[T any](s *Schema, values ...T) *Schema {
+	s.Enum = make([]any, len(values))
+	for i, value := range values {
+		s.Enum[i] = value
+	}
+	return s
+}
+
+// Nullable also accepts null, it has to come after In so the enum keeps null
+func (s *Schema) Nullable() *Schema {
+	if name, ok := s.Type.(string); ok {
+		s.Type = []string{name, "null"}
+	}
+	if s.Enum != nil {
+		s.Enum = append(s.Enum, nil)
+	}
+	return s
+}
+
+// Describe sets the description of the schema
+func (s *Schema) Describe(description string) *Schema {
+	s.Description = description
+	return s
+}
+
+// Entity describes the create and update payloads of a module. Version has to be
+// bumped whenever one of its rules changes so cached copies are refreshed.
+type Entity struct {
+	Name    string
+	Title   string
+	Version int
+	Create  *Schema
+	Update  *Schema
+}
+
+// ID returns the identifier of the entity's document
+func (e Entity) ID() string {
+	return fmt.Sprintf("urn:expense-tracker:schema:%s:v%d", e.Name, e.Version)
+}
+
+// ETag returns the strong entity tag of the entity's document
+func (e Entity) ETag() string {
+	return fmt.Sprintf("%q", fmt.Sprintf("%s-v%d", e.Name, e.Version))
+}
+
+// Document returns the standalone JSON Schema document of the entity, the payloads
+// are found under #/$defs/create and #/$defs/update
+func (e Entity) Document() *Schema {
+	return &Schema{
+		Schema: Draft,
+		ID:     e.ID(),
+		Title:  e.Title,
+		Defs: map[string]*Schema{
+			"create": e.Create,
+			"update": e.Update,
+		},
+	}
+}
+
+// Registry holds the entities whose schemas are served
+type Registry struct {
+	entities map[string]Entity
+}
+
+// NewRegistry creates a registry of the entities
+func NewRegistry(entities ...Entity) *Registry {
+	r := &Registry{entities: make(map[string]Entity, len(entities))}
+	for _, entity := range entities {
+		r.entities[entity.Name] = entity
+	}
+	return r
+}
+
+// Get returns the entity registered under name
+func (r *Registry) Get(name string) (Entity, bool) {
+	entity, ok := r.entities[name]
+	return entity, ok
+}
+
+// Names returns the names of the registered entities in alphabetical order
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.entities))
+	for name := range r.entities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,54 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   *Schema
+		expected string
+	}{
+		{name: "bounded string", schema: String().Length(1, 10), expected: `{"type":"string","minLength":1,"maxLength":10}`},
+		{name: "string without a maximum", schema: String().Length(0, 0), expected: `{"type":"string","minLength":0}`},
+		{name: "nullable enum", schema: In(String(), "a", "b").Nullable(), expected: `{"type":["string","null"],"enum":["a","b",null]}`},
+		{name: "unique uuid array", schema: Array(UUID()).Count(0, 3).Unique(), expected: `{"type":"array","items":{"type":"string","format":"uuid"},"minItems":0,"maxItems":3,"uniqueItems":true}`},
+		{name: "boolean", schema: Boolean(), expected: `{"type":"boolean"}`},
+		{name: "object", schema: Object(map[string]*Schema{"n": Integer().Min(0)}, "n"), expected: `{"type":"object","properties":{"n":{"type":"integer","minimum":0}},"required":["n"]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := json.Marshal(tt.schema)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(encoded))
+		})
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry(
+		Entity{Name: "wallets", Version: 2, Create: Object(nil), Update: Object(nil)},
+		Entity{Name: "contacts", Version: 1, Create: Object(nil), Update: Object(nil)},
+	)
+
+	assert.Equal(t, []string{"contacts", "wallets"}, registry.Names())
+
+	entity, ok := registry.Get("wallets")
+	require.True(t, ok)
+	assert.Equal(t, `"wallets-v2"`, entity.ETag())
+
+	document := entity.Document()
+	assert.Equal(t, Draft, document.Schema)
+	assert.Equal(t, "urn:expense-tracker:schema:wallets:v2", document.ID)
+	assert.Contains(t, document.Defs, "create")
+	assert.Contains(t, document.Defs, "update")
+
+	_, ok = registry.Get("invoices")
+	assert.False(t, ok)
+}
@@ -0,0 +1,55 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// queryTracer records a span per query named after its sqlc statement
+type queryTracer struct {
+	tracer trace.Tracer
+}
+
+// NewQueryTracer returns the pgx hook tracing queries, or nil for a nil tracer
+func NewQueryTracer(tracer trace.Tracer) pgx.QueryTracer {
+	if tracer == nil {
+		return nil
+	}
+	return &queryTracer{tracer: tracer}
+}
+
+func (q *queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	name := StatementName(data.SQL)
+	ctx, _ = q.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.statement.name", name),
+		))
+	return ctx
+}
+
+func (q *queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.Int64("db.rows_affected", data.CommandTag.RowsAffected()))
+	End(span, data.Err)
+}
+
+// StatementName returns the name of a sqlc query from its "-- name: X :kind" comment,
+// other statements are named after their first keyword, e.g. BEGIN
+func StatementName(sql string) string {
+	sql = strings.TrimSpace(sql)
+	if rest, ok := strings.CutPrefix(sql, "-- name:"); ok {
+		if fields := strings.Fields(rest); len(fields) > 0 {
+			return fields[0]
+		}
+	}
+	if fields := strings.Fields(sql); len(fields) > 0 {
+		return strings.ToUpper(fields[0])
+	}
+	return "SQL"
+}
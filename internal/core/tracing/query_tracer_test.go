@@ -0,0 +1,25 @@
+package tracing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatementName(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		expected string
+	}{
+		{"sqlc query", "-- name: ListProjects :many\nSELECT project_id FROM projects", "ListProjects"},
+		{"leading whitespace", "\n  -- name: GetWallet :one\nSELECT 1", "GetWallet"},
+		{"plain statement", "begin", "BEGIN"},
+		{"empty", "", "SQL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, StatementName(tt.sql))
+		})
+	}
+}
@@ -0,0 +1,66 @@
+// Package tracing records OpenTelemetry spans of requests, the service and repository
+// calls they make and the SQL those run. Everything here is a no-op while tracing is
+// disabled: the provider and tracer are nil and the decorators return what they wrap.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Abdelrahman-habib/expense-tracker/config"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName names the tracer of the application's spans
+const instrumentationName = "github.com/Abdelrahman-habib/expense-tracker"
+
+// Setup returns a provider batching spans to the configured OTLP/HTTP collector, or nil
+// while tracing is disabled. The provider has to be shut down to flush the last spans.
+func Setup(ctx context.Context, cfg config.TracingConfig) (*sdktrace.TracerProvider, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("error creating trace exporter: %w", err)
+	}
+	return NewProvider(cfg, sdktrace.NewBatchSpanProcessor(exporter)), nil
+}
+
+// NewProvider returns a provider handing the spans to the processors, sampling new
+// traces at cfg.SampleRatio, or nil while tracing is disabled
+func NewProvider(cfg config.TracingConfig, processors ...sdktrace.SpanProcessor) *sdktrace.TracerProvider {
+	if !cfg.Enabled {
+		return nil
+	}
+	options := []sdktrace.TracerProviderOption{
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", cfg.ServiceName))),
+	}
+	for _, processor := range processors {
+		options = append(options, sdktrace.WithSpanProcessor(processor))
+	}
+	return sdktrace.NewTracerProvider(options...)
+}
+
+// Tracer returns the application's tracer of the provider, nil for a nil provider
+func Tracer(provider *sdktrace.TracerProvider) trace.Tracer {
+	if provider == nil {
+		return nil
+	}
+	return provider.Tracer(instrumentationName)
+}
+
+// End records err on the span, if any, and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,91 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DateLayout is the format of calendar dates in requests and responses
+const DateLayout = time.DateOnly
+
+// Date is a calendar day, held as midnight UTC so dates compare and store the same
+// wherever they were sent from. It marshals in DateLayout. Unmarshaling accepts a bare
+// date or an RFC 3339 time, whose day is taken in its own offset: "2024-03-01T00:00:00-05:00"
+// is March 1st, not the February 29th it would be in UTC.
+type Date struct {
+	time.Time
+}
+
+// NewDate returns the day t falls on in its own location
+func NewDate(t time.Time) Date {
+	year, month, day := t.Date()
+	return Date{Time: time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+// DatePtr returns the day the time t points to falls on, nil staying nil
+func DatePtr(t *time.Time) *Date {
+	if t == nil {
+		return nil
+	}
+	date := NewDate(*t)
+	return &date
+}
+
+// ParseDate parses a date in DateLayout or an RFC 3339 time
+func ParseDate(value string) (Date, error) {
+	if t, err := time.Parse(DateLayout, value); err == nil {
+		return Date{Time: t}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return Date{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD or an RFC 3339 time", value)
+	}
+	return NewDate(t), nil
+}
+
+// TimePtr returns midnight UTC of the date, nil staying nil
+func (d *Date) TimePtr() *time.Time {
+	if d == nil {
+		return nil
+	}
+	t := d.Time
+	return &t
+}
+
+// String formats the date in DateLayout
+func (d Date) String() string {
+	return d.Format(DateLayout)
+}
+
+// MarshalText formats the date in DateLayout
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// MarshalJSON formats the date as a JSON string in DateLayout
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON parses a date in DateLayout or an RFC 3339 time, null leaves it as is
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("invalid date %s, expected a string", data)
+	}
+	return d.UnmarshalText([]byte(value))
+}
+
+// UnmarshalText parses a date in DateLayout or an RFC 3339 time
+func (d *Date) UnmarshalText(data []byte) error {
+	date, err := ParseDate(string(data))
+	if err != nil {
+		return err
+	}
+	*d = date
+	return nil
+}
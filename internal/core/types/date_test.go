@@ -0,0 +1,72 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDate_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"bare date", `"2024-03-01"`, "2024-03-01"},
+		{"UTC midnight", `"2024-03-01T00:00:00Z"`, "2024-03-01"},
+		{"fractional seconds", `"2024-03-01T12:30:45.123456Z"`, "2024-03-01"},
+		{"evening west of UTC", `"2024-03-01T20:00:00-05:00"`, "2024-03-01"},
+		{"midnight west of UTC", `"2024-03-01T00:00:00-08:00"`, "2024-03-01"},
+		{"early morning east of UTC", `"2024-03-01T01:00:00+03:00"`, "2024-03-01"},
+		{"leap day", `"2024-02-29"`, "2024-02-29"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var date Date
+			require.NoError(t, json.Unmarshal([]byte(tt.value), &date))
+			assert.Equal(t, tt.want, date.String())
+			assert.Equal(t, time.UTC, date.Location())
+			assert.True(t, date.Equal(date.Truncate(24*time.Hour)), "dates are held as midnight UTC")
+		})
+	}
+
+	for _, value := range []string{`"03/01/2024"`, `"2024-02-30"`, `"2024-03-01T25:00:00Z"`, `""`, `20240301`} {
+		var date Date
+		assert.Error(t, json.Unmarshal([]byte(value), &date), value)
+	}
+}
+
+func TestDate_InStructs(t *testing.T) {
+	evening := time.Date(2024, 3, 1, 22, 0, 0, 0, time.FixedZone("UTC-5", -5*60*60))
+	payload := struct {
+		Date    Date  `json:"date"`
+		Pointer *Date `json:"pointer"`
+		Missing *Date `json:"missing,omitempty"`
+	}{Date: NewDate(evening), Pointer: DatePtr(&evening)}
+
+	data, err := json.Marshal(payload)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"date":"2024-03-01","pointer":"2024-03-01"}`, string(data))
+	assert.Nil(t, DatePtr(nil))
+
+	var decoded struct {
+		Pointer *Date `json:"pointer"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"pointer":null}`), &decoded))
+	assert.Nil(t, decoded.Pointer)
+}
+
+func TestDate_Compare(t *testing.T) {
+	start, err := ParseDate("2024-03-01T23:30:00-05:00")
+	require.NoError(t, err)
+	end, err := ParseDate("2024-03-01")
+	require.NoError(t, err)
+
+	// the instants are over a day apart, the dates are the same
+	assert.True(t, start.Equal(end.Time))
+	assert.False(t, end.Before(start.Time))
+}
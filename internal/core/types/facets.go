@@ -0,0 +1,32 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// FacetFieldParam is the query parameter naming the field a facets endpoint counts the values of
+const FacetFieldParam = "field"
+
+// Facet is a distinct value of a field and how many entities hold it
+// @Description Distinct value of a field with the number of entities holding it
+type Facet struct {
+	Value string `json:"value" example:"US"`
+	Count int64  `json:"count" example:"12"`
+}
+
+// ParseFacetField returns the field of the field query parameter, which has to be one
+// of the facetable fields
+func ParseFacetField(query url.Values, facetable ...string) (string, error) {
+	field := strings.TrimSpace(query.Get(FacetFieldParam))
+	if field == "" {
+		return "", fmt.Errorf("%s: cannot be blank (allowed: %s)", FacetFieldParam, strings.Join(facetable, ", "))
+	}
+	for _, allowed := range facetable {
+		if field == allowed {
+			return field, nil
+		}
+	}
+	return "", fmt.Errorf("%s: %q cannot be faceted (allowed: %s)", FacetFieldParam, field, strings.Join(facetable, ", "))
+}
@@ -0,0 +1,70 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IDsQueryParam is the query parameter list endpoints take to fetch entities by ID
+const IDsQueryParam = "ids"
+
+// StreamParam is the query parameter paginated lists take to stream their page as NDJSON
+const StreamParam = "stream"
+
+// OnlyIDsParam is the query parameter paginated lists take to return the IDs of their
+// page instead of the entities
+const OnlyIDsParam = "only_ids"
+
+// KeyedID is an entity of a paginated list reduced to its ID and the keys the list is
+// paged by, enough to issue the next_token without reading the rest of the row. PinnedAt
+// is set on the pinned entities listed first, they are paged by it instead of CreatedAt.
+type KeyedID struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	PinnedAt  *time.Time
+}
+
+// KeyedIDs returns the IDs of keys in order
+func KeyedIDs(keys []KeyedID) []uuid.UUID {
+	ids := make([]uuid.UUID, len(keys))
+	for i, key := range keys {
+		ids[i] = key.ID
+	}
+	return ids
+}
+
+// MaxBatchIDs caps the number of entities fetched by ID in one request
+const MaxBatchIDs = 100
+
+// ParseIDs parses the comma separated IDs of the ids query parameter, keeping their order
+// and dropping repeats
+func ParseIDs(value string) ([]uuid.UUID, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) > MaxBatchIDs {
+		return nil, fmt.Errorf("%s: at most %d IDs can be fetched at once", IDsQueryParam, MaxBatchIDs)
+	}
+
+	ids := make([]uuid.UUID, 0, len(parts))
+	seen := make(map[uuid.UUID]bool, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := uuid.Parse(part)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %q is not a valid UUID", IDsQueryParam, part)
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%s: cannot be blank", IDsQueryParam)
+	}
+	return ids, nil
+}
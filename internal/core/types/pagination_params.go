@@ -1,9 +1,15 @@
 package types
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -12,25 +18,161 @@ import (
 	"github.com/google/uuid"
 )
 
+// The limits used when no policy is configured
 const (
 	DefaultLimit = 10
 	MaxLimit     = 100
 )
 
+// DefaultCursorTTL is how long a next_token stays valid when no TTL is configured
+const DefaultCursorTTL = 24 * time.Hour
+
+// ErrExpiredCursor is returned for a next_token older than the cursor TTL, the client
+// has to restart the pagination from the first page
+var ErrExpiredCursor = errors.New("next_token has expired")
+
+// ErrCursorMismatch is returned for a next_token issued for another sort order or other
+// filters than the request's, resuming it would page through a different list
+var ErrCursorMismatch = errors.New("token does not match current query parameters")
+
+// generatedCursorKeyBytes is the size of the key signing next_tokens generated when none
+// is configured
+const generatedCursorKeyBytes = 32
+
+// generatedCursorKey signs the next_tokens of policies without a key, the tokens then stop
+// being accepted when the process restarts and by other instances
+var generatedCursorKey = func() []byte {
+	key := make([]byte, generatedCursorKeyBytes)
+	if _, err := rand.Read(key); err != nil {
+		panic(fmt.Sprintf("generate cursor key: %v", err))
+	}
+	return key
+}()
+
+// LimitPolicy bounds the page sizes of an entity's list and search endpoints, handlers
+// get one at construction so the limits can be tuned per entity from the config
+type LimitPolicy struct {
+	DefaultLimit       int32
+	MaxLimit           int32
+	DefaultSearchLimit int32
+	MaxSearchLimit     int32
+	// MinTrimmedResults is the fewest results trim=auto leaves in a search
+	MinTrimmedResults int32
+	// CursorTTL is how long a next_token is accepted after it was issued, zero means
+	// DefaultCursorTTL
+	CursorTTL time.Duration
+	// CursorKey is the HMAC key signing the next_tokens, empty uses a key generated at
+	// startup
+	CursorKey []byte
+	// AllowLegacyCursors accepts unsigned tokens and tokens issued before they carried
+	// their issue time and user, only meant for the migration period so clients paging
+	// during the upgrade aren't cut off
+	AllowLegacyCursors bool
+}
+
+// DefaultLimitPolicy returns the limits used when none are configured
+func DefaultLimitPolicy() LimitPolicy {
+	return LimitPolicy{
+		DefaultLimit:       DefaultLimit,
+		MaxLimit:           MaxLimit,
+		DefaultSearchLimit: DefaultSearchLimit,
+		MaxSearchLimit:     MaxSearchLimit,
+		MinTrimmedResults:  DefaultMinTrimmedResults,
+		CursorTTL:          DefaultCursorTTL,
+	}
+}
+
+// cursorTTL returns the configured cursor TTL or the default
+func (p LimitPolicy) cursorTTL() time.Duration {
+	if p.CursorTTL > 0 {
+		return p.CursorTTL
+	}
+	return DefaultCursorTTL
+}
+
+// cursorKey returns the configured cursor key or the generated one
+func (p LimitPolicy) cursorKey() []byte {
+	if len(p.CursorKey) > 0 {
+		return p.CursorKey
+	}
+	return generatedCursorKey
+}
+
+// SortOrder is the direction paginated lists are ordered by created_at
+type SortOrder string
+
+const (
+	SortOrderDesc SortOrder = "desc"
+	SortOrderAsc  SortOrder = "asc"
+)
+
+// Cursor is the position a next_token resumes at. IssuedAt and UserID bind the token
+// to when and for whom it was issued, both are zero on legacy tokens. Pinned cursors are
+// still in the pinned items listed first, Timestamp is then the pin time. Signature is
+// the QuerySignature of the list the token pages through, empty on tokens issued before
+// they carried it.
 type Cursor struct {
 	Timestamp time.Time
 	ID        uuid.UUID
+	Order     SortOrder
+	IssuedAt  time.Time
+	UserID    uuid.UUID
+	Pinned    bool
+	Signature string
 }
 
+// pinnedPart marks the token of a pinned cursor, signaturePart prefixes its signature and
+// macPart the HMAC of the rest of the token
+const (
+	pinnedPart    = "pinned"
+	signaturePart = "sig="
+	macPart       = "mac="
+)
+
 type PaginationParams struct {
 	Cursor *Cursor
 	Limit  int32
+	Order  SortOrder
+	// Signature is the QuerySignature of the request, carried by the next_tokens issued for it
+	Signature string
+	// LimitCapped reports whether the limit asked for was above the policy's and lowered to it
+	LimitCapped bool
+	// key signs the next_tokens issued for the request, the policy's cursor key
+	key []byte
 }
 
-// ParsePaginationParams parses and validates pagination parameters from URL query
-func ParsePaginationParams(query url.Values) (PaginationParams, error) {
+// QuerySignature sums up the filters of a list request, the query parameters named in
+// filters. The order is carried by the cursor itself, the limit and next_token are left
+// out since clients may change the page size while paging, and so are the parameters
+// the list doesn't read.
+func QuerySignature(query url.Values, filters ...string) string {
+	hash := sha256.New()
+	names := slices.Clone(filters)
+	slices.Sort(names)
+	for _, name := range slices.Compact(names) {
+		if name == "limit" || name == "order" || name == "next_token" {
+			continue
+		}
+		values := slices.Clone(query[name])
+		slices.Sort(values)
+		for _, value := range values {
+			fmt.Fprintf(hash, "%s=%s&", url.QueryEscape(name), url.QueryEscape(value))
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil)[:8])
+}
+
+// ParsePaginationParams parses and validates pagination parameters from URL query,
+// the limit defaults to and is capped by the policy. A next_token must be signed with the
+// policy's key and have been issued to userID within its cursor TTL, expired tokens return
+// ErrExpiredCursor, and for the same filters, the query parameters named in filters, other
+// tokens return ErrCursorMismatch.
+func ParsePaginationParams(query url.Values, policy LimitPolicy, userID uuid.UUID, filters ...string) (PaginationParams, error) {
 	params := PaginationParams{
-		Limit: DefaultLimit,
+		Limit:     policy.DefaultLimit,
+		Order:     SortOrderDesc,
+		Signature: QuerySignature(query, filters...),
+		key:       policy.cursorKey(),
 	}
 
 	// Parse limit
@@ -40,32 +182,68 @@ func ParsePaginationParams(query url.Values) (PaginationParams, error) {
 			return params, fmt.Errorf("invalid limit format")
 		}
 		// cap the limit
-		if l > MaxLimit {
-			l = MaxLimit
+		if l > int64(policy.MaxLimit) {
+			l = int64(policy.MaxLimit)
+			params.LimitCapped = true
 		}
 		params.Limit = int32(l)
 	}
 
-	// Parse cursor if provided
+	// Parse order
+	order := SortOrder(strings.ToLower(query.Get("order")))
+	if order != "" {
+		params.Order = order
+	}
+
+	// Parse cursor if provided, the cursor carries the order it was issued for
 	if nextToken := query.Get("next_token"); nextToken != "" {
-		cursor, err := DecodeCursor(nextToken)
+		cursor, err := DecodeCursor(nextToken, params.Signature, policy)
 		if err != nil {
 			return params, err
 		}
+		if err := cursor.CheckIssuer(userID, policy, time.Now()); err != nil {
+			return params, err
+		}
+		if order != "" && order != cursor.Order {
+			return params, fmt.Errorf("order does not match next_token: %w", ErrCursorMismatch)
+		}
 		params.Cursor = cursor
+		params.Order = cursor.Order
+	}
+
+	return params, params.Validate(policy)
+}
+
+// endOfTime sorts after every stored timestamp, times past the year 9999 can't be encoded as JSON so
+// the API never stores one
+var endOfTime = time.Date(9999, time.December, 31, 23, 59, 59, 0, time.UTC)
+
+// StartCursor returns the cursor values to use for the given order when no next_token was provided.
+// A descending list starts past every row rather than at the current time, so rows stamped at
+// or after this instant (clock skew between the app and the database, or a non-UTC session
+// timezone) still show on the first page instead of being skipped for good.
+func StartCursor(order SortOrder) (time.Time, uuid.UUID) {
+	if order == SortOrderAsc {
+		return time.Time{}, uuid.Nil
 	}
+	return endOfTime, uuid.Nil
+}
 
-	return params, params.Validate()
+// StartPinnedCursor returns the cursor values to use when no next_token was provided on a
+// list starting with pinned items, they come first in either order, most recently pinned on top
+func StartPinnedCursor() (time.Time, uuid.UUID) {
+	return StartCursor(SortOrderDesc)
 }
 
-// Validate implements validation for pagination parameters
-func (p *PaginationParams) Validate() error {
+// Validate implements validation for pagination parameters against the policy
+func (p *PaginationParams) Validate(policy LimitPolicy) error {
 	return validation.Errors{
 		"limit": validation.Validate(p.Limit,
 			validation.Required.Error("must be no less than 1"), // we have this because validation package treats 0 as nil so the min and max won't work for limit = 0
 			validation.Min(1),
-			validation.Max(MaxLimit),
+			validation.Max(policy.MaxLimit),
 		),
+		"order": validation.Validate(p.Order, validation.In(SortOrderAsc, SortOrderDesc)),
 		"cursor": validation.Validate(p.Cursor,
 			validation.When(p.Cursor != nil, validation.By(func(value interface{}) error {
 				return value.(*Cursor).Validate()
@@ -101,27 +279,155 @@ func (c *Cursor) Validate() error {
 				return nil
 			}),
 		),
+		"order": validation.Validate(c.Order, validation.Required, validation.In(SortOrderAsc, SortOrderDesc)),
 	}.Filter()
 }
 
-// EncodeCursor creates a cursor token from timestamp and ID
-func EncodeCursor(timestamp time.Time, id uuid.UUID) string {
+// CheckIssuer rejects a cursor issued to another user or longer than the policy's TTL
+// ago. Legacy cursors carry neither and pass only while the policy allows them.
+func (c *Cursor) CheckIssuer(userID uuid.UUID, policy LimitPolicy, now time.Time) error {
+	if c.IssuedAt.IsZero() {
+		if !policy.AllowLegacyCursors {
+			return ErrExpiredCursor
+		}
+		return nil
+	}
+	if c.UserID != userID {
+		return fmt.Errorf("next_token was issued to another user")
+	}
+	if now.Sub(c.IssuedAt) > policy.cursorTTL() {
+		return ErrExpiredCursor
+	}
+	return nil
+}
+
+// CheckSignature rejects a cursor issued for another query than the one signature sums
+// up. Tokens without a signature were issued before they carried one and pass.
+func (c *Cursor) CheckSignature(signature string) error {
+	if c.Signature != "" && signature != "" && c.Signature != signature {
+		return ErrCursorMismatch
+	}
+	return nil
+}
+
+// NextToken creates the token of the page after the item at timestamp and id, issued now
+// to the given user for the request's order and filters
+func (p PaginationParams) NextToken(timestamp time.Time, id uuid.UUID, userID uuid.UUID) string {
+	cursor := &Cursor{
+		Timestamp: timestamp.UTC(),
+		ID:        id,
+		Order:     p.Order,
+		IssuedAt:  time.Now().UTC(),
+		UserID:    userID,
+		Signature: p.Signature,
+	}
+	return cursor.Encode(p.signingKey())
+}
+
+// NextPinnedToken creates the token of the page after the pinned item pinned at pinnedAt,
+// issued now to the given user for the request's order and filters
+func (p PaginationParams) NextPinnedToken(pinnedAt time.Time, id uuid.UUID, userID uuid.UUID) string {
+	cursor := &Cursor{
+		Timestamp: pinnedAt.UTC(),
+		ID:        id,
+		Order:     p.Order,
+		IssuedAt:  time.Now().UTC(),
+		UserID:    userID,
+		Pinned:    true,
+		Signature: p.Signature,
+	}
+	return cursor.Encode(p.signingKey())
+}
+
+// NextKeyedToken creates the token of the page after the one ending on last, a page
+// ending on a pinned entity continues among the pinned ones
+func (p PaginationParams) NextKeyedToken(last KeyedID, userID uuid.UUID) string {
+	if last.PinnedAt != nil {
+		return p.NextPinnedToken(*last.PinnedAt, last.ID, userID)
+	}
+	return p.NextToken(last.CreatedAt, last.ID, userID)
+}
+
+// signingKey returns the key signing the request's next_tokens, params not parsed from a
+// request use the generated key
+func (p PaginationParams) signingKey() []byte {
+	if len(p.key) > 0 {
+		return p.key
+	}
+	return generatedCursorKey
+}
+
+// EncodeCursor creates a cursor token from timestamp and ID for the default descending
+// order, issued now to the given user and signed with the generated key
+func EncodeCursor(timestamp time.Time, id uuid.UUID, userID uuid.UUID) string {
+	return EncodeOrderedCursor(timestamp, id, SortOrderDesc, userID)
+}
+
+// EncodeOrderedCursor creates a cursor token from timestamp, ID and the order of the list,
+// issued now to the given user and signed with the generated key
+func EncodeOrderedCursor(timestamp time.Time, id uuid.UUID, order SortOrder, userID uuid.UUID) string {
 	cursor := &Cursor{
 		Timestamp: timestamp.UTC(), // Ensure UTC
 		ID:        id,
+		Order:     order,
+		IssuedAt:  time.Now().UTC(),
+		UserID:    userID,
+	}
+	return cursor.Encode(generatedCursorKey)
+}
+
+// EncodePinnedCursor creates a cursor token positioned among the pinned items, which are
+// ordered by pin time whatever the order of the list, issued now to the given user and
+// signed with the generated key
+func EncodePinnedCursor(pinnedAt time.Time, id uuid.UUID, order SortOrder, userID uuid.UUID) string {
+	cursor := &Cursor{
+		Timestamp: pinnedAt.UTC(),
+		ID:        id,
+		Order:     order,
+		IssuedAt:  time.Now().UTC(),
+		UserID:    userID,
+		Pinned:    true,
 	}
+	return cursor.Encode(generatedCursorKey)
+}
 
+// Encode creates the token of the cursor signed with key, a cursor without an issue time
+// is encoded in the legacy format
+func (c *Cursor) Encode(key []byte) string {
 	// Validate cursor before encoding
-	if err := cursor.Validate(); err != nil {
+	if err := c.Validate(); err != nil {
 		return ""
 	}
 
-	raw := fmt.Sprintf("%d:%s", timestamp.UTC().UnixNano(), id.String())
+	raw := fmt.Sprintf("%d:%s:%s", c.Timestamp.UTC().UnixNano(), c.ID.String(), c.Order)
+	if !c.IssuedAt.IsZero() {
+		raw += fmt.Sprintf(":%d:%s", c.IssuedAt.Unix(), c.UserID.String())
+		if c.Pinned {
+			raw += ":" + pinnedPart
+		}
+		if c.Signature != "" {
+			raw += ":" + signaturePart + c.Signature
+		}
+	}
+	raw += ":" + macPart + cursorMAC(key, raw)
 	return base64.StdEncoding.EncodeToString([]byte(raw))
 }
 
-// DecodeCursor parses a cursor token into timestamp and ID
-func DecodeCursor(token string) (*Cursor, error) {
+// cursorMAC is the hex encoded HMAC-SHA256 of the token payload raw
+func cursorMAC(key []byte, raw string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(raw))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// DecodeCursor parses a cursor token into timestamp and ID. Tokens issued before ordering
+// was supported have no order part, legacy tokens have no issue time and user parts, only
+// pinned cursors carry the pinned part and tokens issued for a query its signature part.
+// The token must end with the HMAC of the rest signed with the policy's key, a bad one is
+// rejected and a missing one returns ErrExpiredCursor unless the policy allows legacy
+// cursors. A token whose signature differs from signature returns ErrCursorMismatch, an
+// empty signature skips the check for callers only reading the token.
+func DecodeCursor(token string, signature string, policy LimitPolicy) (*Cursor, error) {
 	if token == "" {
 		return nil, nil
 	}
@@ -132,11 +438,19 @@ func DecodeCursor(token string) (*Cursor, error) {
 		return nil, fmt.Errorf("invalid token format")
 	}
 
-	// Split into parts
-	parts := strings.Split(string(raw), ":")
-	if len(parts) != 2 {
+	payload, err := verifyCursorMAC(string(raw), policy)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(payload, ":")
+	if len(parts) != 2 && len(parts) != 3 && (len(parts) < 5 || len(parts) > 7) {
 		return nil, fmt.Errorf("invalid token format")
 	}
+	order := SortOrderDesc
+	if len(parts) >= 3 {
+		order = SortOrder(parts[2])
+	}
 
 	// Parse timestamp
 	var nanos int64
@@ -154,12 +468,56 @@ func DecodeCursor(token string) (*Cursor, error) {
 	cursor := &Cursor{
 		Timestamp: timestamp,
 		ID:        id,
+		Order:     order,
+	}
+
+	// Parse the issue time, user and segment
+	if len(parts) >= 5 {
+		issuedAt, err := strconv.ParseInt(parts[3], 10, 64)
+		if err != nil || issuedAt <= 0 {
+			return nil, fmt.Errorf("invalid token value")
+		}
+		cursor.IssuedAt = time.Unix(issuedAt, 0).UTC()
+		if cursor.UserID, err = uuid.Parse(parts[4]); err != nil {
+			return nil, fmt.Errorf("invalid token value")
+		}
+		for _, part := range parts[5:] {
+			switch {
+			case part == pinnedPart && !cursor.Pinned && cursor.Signature == "":
+				cursor.Pinned = true
+			case strings.HasPrefix(part, signaturePart) && len(part) > len(signaturePart) && cursor.Signature == "":
+				cursor.Signature = strings.TrimPrefix(part, signaturePart)
+			default:
+				return nil, fmt.Errorf("invalid token value")
+			}
+		}
 	}
 
 	// Validate the cursor after decoding
 	if err := cursor.Validate(); err != nil {
 		return nil, err
 	}
+	if err := cursor.CheckSignature(signature); err != nil {
+		return nil, err
+	}
 
 	return cursor, nil
 }
+
+// verifyCursorMAC checks the HMAC ending the decoded token raw and returns the payload it
+// signs. Unsigned tokens were issued before tokens were signed and pass only while the
+// policy allows legacy cursors.
+func verifyCursorMAC(raw string, policy LimitPolicy) (string, error) {
+	i := strings.LastIndex(raw, ":"+macPart)
+	if i < 0 {
+		if !policy.AllowLegacyCursors {
+			return "", ErrExpiredCursor
+		}
+		return raw, nil
+	}
+	payload, mac := raw[:i], raw[i+len(macPart)+1:]
+	if !hmac.Equal([]byte(mac), []byte(cursorMAC(policy.cursorKey(), payload))) {
+		return "", fmt.Errorf("invalid token signature")
+	}
+	return payload, nil
+}
@@ -0,0 +1,62 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// QueryParamsMode decides what list and search endpoints do with query parameters they don't recognize
+type QueryParamsMode string
+
+const (
+	// QueryParamsWarn serves the request and names the unknown parameters in the response meta
+	QueryParamsWarn QueryParamsMode = "warn"
+	// QueryParamsStrict rejects the request with a 400
+	QueryParamsStrict QueryParamsMode = "strict"
+)
+
+// Valid reports whether the mode is one of the known modes
+func (m QueryParamsMode) Valid() bool {
+	return m == QueryParamsWarn || m == QueryParamsStrict
+}
+
+// Query parameters accepted by the shared list and search endpoints
+var (
+	PaginationQueryParams = []string{"limit", "order", "next_token"}
+	SearchQueryParams     = []string{"q", "limit", "next_token"}
+)
+
+// ValidateQueryParams returns an error naming every query parameter not in the allowed list
+// along with the allowed ones, repeated parameters are named once
+func ValidateQueryParams(query url.Values, allowed ...string) error {
+	var unknown []string
+	for name := range query {
+		if !containsParam(allowed, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	sort.Strings(unknown)
+	accepted := "none"
+	if len(allowed) > 0 {
+		accepted = strings.Join(allowed, ", ")
+	}
+	if len(unknown) == 1 {
+		return fmt.Errorf("unknown query parameter: %s (allowed: %s)", unknown[0], accepted)
+	}
+	return fmt.Errorf("unknown query parameters: %s (allowed: %s)", strings.Join(unknown, ", "), accepted)
+}
+
+func containsParam(allowed []string, name string) bool {
+	for _, param := range allowed {
+		if param == name {
+			return true
+		}
+	}
+	return false
+}
@@ -1,45 +1,188 @@
 package types
 
 import (
+	"encoding/base64"
 	"errors"
+	"fmt"
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
+	"unicode"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 const (
-	MinQueryLength     = 1
-	MaxQueryLength     = 100
-	MaxSearchLimit     = 50
-	DefaultSearchLimit = 10
+	MinQueryLength         = 1
+	MaxQueryLength         = 100
+	MaxSearchLimit         = 50
+	DefaultSearchLimit     = 10
+	DefaultMaxSearchWindow = 500
+	// DefaultMinTrimmedResults is the fewest results trim=auto leaves unless configured
+	DefaultMinTrimmedResults = 3
+	// MaxRepeatedRun is the longest run of one character kept in a trigram search query,
+	// longer runs only repeat the same trigrams and are collapsed to it
+	MaxRepeatedRun = 3
+	// MaxQueryTrigrams is the most distinct trigrams a trigram search query may hold, each
+	// one is another index lookup and similarity the database has to work through
+	MaxQueryTrigrams = 64
+	// DefaultSearchTimeout bounds a search unless configured, it is shorter than the
+	// request timeout so a query too slow to match fails on its own
+	DefaultSearchTimeout = 3 * time.Second
 )
 
+const searchTokenPrefix = "search:"
+
+// ErrSearchWindowExceeded is returned when a search cursor pages past the search window
+var ErrSearchWindowExceeded = errors.New("search window exceeded, refine your query")
+
+// ErrQueryTooComplex is returned for a search query too costly to match, holding more
+// than MaxQueryTrigrams distinct trigrams or running past the search timeout
+var ErrQueryTooComplex = errors.New("query too complex, search with fewer or shorter words")
+
 type SearchParams struct {
-	Query string
-	Limit int32
+	Query  string
+	Limit  int32
+	Offset int32
+	// Window is how many results deep the search may page, zero means unbounded
+	Window int32
 }
 
-func ParseAndValidateSearchParams(query url.Values) (SearchParams, error) {
+// ParseAndValidateSearchParams parses and validates search parameters from URL query,
+// the limit defaults to and is capped by the policy's search limits
+func ParseAndValidateSearchParams(query url.Values, policy LimitPolicy) (SearchParams, error) {
 	searchQuery := strings.TrimSpace(query.Get("q"))
 
 	// Parse and validate limit
-	limit := int32(DefaultSearchLimit)
+	limit := policy.DefaultSearchLimit
 	if limitStr := query.Get("limit"); limitStr != "" {
 		l, err := strconv.ParseInt(limitStr, 10, 32)
 		if err != nil {
 			return SearchParams{}, errors.New("limit: invalid format")
 		}
 		// Cap the limit
-		if l > MaxSearchLimit {
-			l = MaxSearchLimit
+		if l > int64(policy.MaxSearchLimit) {
+			l = int64(policy.MaxSearchLimit)
 		}
 		limit = int32(l)
 	}
 
-	return SearchParams{Query: searchQuery, Limit: limit}, validation.Errors{
+	// Parse the position to resume from
+	offset, err := DecodeSearchToken(query.Get("next_token"))
+	if err != nil {
+		return SearchParams{}, err
+	}
+
+	return SearchParams{Query: searchQuery, Limit: limit, Offset: offset}, validation.Errors{
 		"query": validation.Validate(searchQuery, validation.Length(MinQueryLength, MaxQueryLength)),
 		"limit": validation.Validate(limit, validation.Min(1)),
 	}.Filter()
 }
+
+// ParseTrigramSearchParams is ParseAndValidateSearchParams for the searches matching by
+// trigram similarity, the query is normalized by NormalizeTrigramQuery
+func ParseTrigramSearchParams(query url.Values, policy LimitPolicy) (SearchParams, error) {
+	params, err := ParseAndValidateSearchParams(query, policy)
+	if err != nil {
+		return SearchParams{}, err
+	}
+	if params.Query, err = NormalizeTrigramQuery(params.Query); err != nil {
+		return SearchParams{}, err
+	}
+	return params, nil
+}
+
+// NormalizeTrigramQuery collapses the runs of one character longer than MaxRepeatedRun
+// and returns ErrQueryTooComplex when the query still holds more than MaxQueryTrigrams
+// distinct trigrams
+func NormalizeTrigramQuery(query string) (string, error) {
+	var b strings.Builder
+	var last rune
+	run := 0
+	for _, r := range query {
+		if r == last {
+			run++
+		} else {
+			last, run = r, 1
+		}
+		if run <= MaxRepeatedRun {
+			b.WriteRune(r)
+		}
+	}
+	normalized := b.String()
+	if countTrigrams(normalized) > MaxQueryTrigrams {
+		return "", ErrQueryTooComplex
+	}
+	return normalized, nil
+}
+
+// countTrigrams counts the distinct trigrams pg_trgm extracts from s, it lowercases the
+// words made of letters and digits and pads each with two spaces before and one after
+func countTrigrams(s string) int {
+	trigrams := make(map[string]struct{})
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			trigrams[string(padded[i:i+3])] = struct{}{}
+		}
+	}
+	return len(trigrams)
+}
+
+// ApplyWindow bounds the search to the first window results, the page is shortened
+// when it would run past the end and a cursor starting beyond it is rejected
+func (p *SearchParams) ApplyWindow(window int32) error {
+	if p.Offset >= window {
+		return ErrSearchWindowExceeded
+	}
+	if p.Offset+p.Limit > window {
+		p.Limit = window - p.Offset
+	}
+	p.Window = window
+	return nil
+}
+
+// NextToken returns the token for the page after one that returned count results,
+// empty when the results ran out or the window ends with this page
+func (p SearchParams) NextToken(count int) string {
+	if count < int(p.Limit) {
+		return ""
+	}
+	next := p.Offset + p.Limit
+	if p.Window > 0 && next >= p.Window {
+		return ""
+	}
+	return EncodeSearchToken(next)
+}
+
+// EncodeSearchToken creates a search token resuming at the given offset
+func EncodeSearchToken(offset int32) string {
+	raw := fmt.Sprintf("%s%d", searchTokenPrefix, offset)
+	return base64.StdEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeSearchToken parses a search token into the offset it resumes at
+func DecodeSearchToken(token string) (int32, error) {
+	if token == "" {
+		return 0, nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(token)
+	if err != nil {
+		return 0, fmt.Errorf("invalid token format")
+	}
+
+	value, ok := strings.CutPrefix(string(raw), searchTokenPrefix)
+	if !ok {
+		return 0, fmt.Errorf("invalid token format")
+	}
+	offset, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid token value")
+	}
+	return int32(offset), nil
+}
@@ -0,0 +1,74 @@
+package types
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeTrigramQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"plain words", "Acme Construction", "Acme Construction"},
+		{"long company name", "International Business Machines Corporation", "International Business Machines Corporation"},
+		{"short runs kept", "Mississippi 1000", "Mississippi 1000"},
+		{"long run collapsed", "aaaaaaaaaaaaaaaaaaaa", "aaa"},
+		{"runs inside words", "hellooooo worlddddd", "hellooo worlddd"},
+		{"runs of non letters", "a!!!!!!!!b      c", "a!!!b   c"},
+		{"multibyte runs", "ééééééé", "ééé"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeTrigramQuery(tt.query)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNormalizeTrigramQuery_TooComplex(t *testing.T) {
+	for _, query := range []string{
+		"the quick brown fox jumps over the lazy dog, pack my box with five dozen liquor jugs",
+		"zq xj vk wb pf ym gh lt nr cd sa eo iu ba ce di fo gu hy jk lm np qr st vw xz",
+	} {
+		_, err := NormalizeTrigramQuery(query)
+		assert.ErrorIs(t, err, ErrQueryTooComplex, query)
+	}
+
+	// Repeating the same words adds no trigram
+	_, err := NormalizeTrigramQuery(strings.Repeat("acme ", 20))
+	assert.NoError(t, err)
+}
+
+func TestCountTrigrams(t *testing.T) {
+	// pg_trgm: "  c", " ca", "cat", "at "
+	assert.Equal(t, 4, countTrigrams("cat"))
+	assert.Equal(t, 4, countTrigrams("CAT, cat!"))
+	assert.Equal(t, 0, countTrigrams("--- !!!"))
+	// "  a", " a ", "  b", " b "
+	assert.Equal(t, 4, countTrigrams("a b"))
+}
+
+func TestParseTrigramSearchParams(t *testing.T) {
+	policy := DefaultLimitPolicy()
+
+	params, err := ParseTrigramSearchParams(url.Values{"q": {"  zzzzzzzzzzzz top  "}}, policy)
+	require.NoError(t, err)
+	assert.Equal(t, "zzz top", params.Query)
+	assert.Equal(t, int32(DefaultSearchLimit), params.Limit)
+
+	_, err = ParseTrigramSearchParams(url.Values{"q": {"zq xj vk wb pf ym gh lt nr cd sa eo iu ba ce di fo gu hy jk lm np qr st vw xz"}}, policy)
+	assert.ErrorIs(t, err, ErrQueryTooComplex)
+
+	// Too long is rejected before the trigrams are counted
+	_, err = ParseTrigramSearchParams(url.Values{"q": {strings.Repeat("a", MaxQueryLength+1)}}, policy)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrQueryTooComplex)
+}
@@ -0,0 +1,70 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// TimestampLayout is the one format responses write timestamps in: RFC 3339 in UTC with
+// exactly three fractional digits, so every timestamp parses the same way and sorts as
+// text
+const TimestampLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// Timestamp is a time.Time that marshals in TimestampLayout. It keeps the full precision
+// of the time it holds, only its JSON is truncated to the millisecond, so cursors and
+// comparisons built from it stay exact. Unmarshaling accepts any RFC 3339 time.
+type Timestamp struct {
+	time.Time
+}
+
+// NewTimestamp wraps t
+func NewTimestamp(t time.Time) Timestamp {
+	return Timestamp{Time: t}
+}
+
+// TimestampPtr wraps the time t points to, nil staying nil
+func TimestampPtr(t *time.Time) *Timestamp {
+	if t == nil {
+		return nil
+	}
+	return &Timestamp{Time: *t}
+}
+
+// FormatTimestamp formats t in TimestampLayout, truncated to the millisecond in UTC
+func FormatTimestamp(t time.Time) string {
+	return t.UTC().Truncate(time.Millisecond).Format(TimestampLayout)
+}
+
+// TimePtr returns the time ts holds, nil staying nil
+func (ts *Timestamp) TimePtr() *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.Time
+	return &t
+}
+
+// String formats the timestamp in TimestampLayout
+func (ts Timestamp) String() string {
+	return FormatTimestamp(ts.Time)
+}
+
+// MarshalText formats the timestamp in TimestampLayout
+func (ts Timestamp) MarshalText() ([]byte, error) {
+	return []byte(FormatTimestamp(ts.Time)), nil
+}
+
+// MarshalJSON formats the timestamp as a JSON string in TimestampLayout
+func (ts Timestamp) MarshalJSON() ([]byte, error) {
+	return json.Marshal(FormatTimestamp(ts.Time))
+}
+
+// UnmarshalJSON parses an RFC 3339 time of any precision
+func (ts *Timestamp) UnmarshalJSON(data []byte) error {
+	return ts.Time.UnmarshalJSON(data)
+}
+
+// UnmarshalText parses an RFC 3339 time of any precision
+func (ts *Timestamp) UnmarshalText(data []byte) error {
+	return ts.Time.UnmarshalText(data)
+}
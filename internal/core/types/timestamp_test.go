@@ -0,0 +1,70 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimestamp_MarshalJSON(t *testing.T) {
+	local := time.FixedZone("UTC-5", -5*60*60)
+
+	tests := []struct {
+		name string
+		time time.Time
+		want string
+	}{
+		{"whole seconds keep three digits", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), `"2024-01-01T00:00:00.000Z"`},
+		{"microseconds are truncated", time.Date(2024, 1, 1, 12, 30, 45, 123456000, time.UTC), `"2024-01-01T12:30:45.123Z"`},
+		{"nanoseconds are truncated not rounded", time.Date(2024, 1, 1, 12, 30, 45, 999999999, time.UTC), `"2024-01-01T12:30:45.999Z"`},
+		{"trailing zeros are kept", time.Date(2024, 1, 1, 12, 30, 45, 100000000, time.UTC), `"2024-01-01T12:30:45.100Z"`},
+		{"other zones are converted to UTC", time.Date(2024, 1, 1, 22, 0, 0, 0, local), `"2024-01-02T03:00:00.000Z"`},
+		{"zero time", time.Time{}, `"0001-01-01T00:00:00.000Z"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(NewTimestamp(tt.time))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(data))
+		})
+	}
+}
+
+func TestTimestamp_InStructs(t *testing.T) {
+	at := time.Date(2024, 3, 4, 5, 6, 7, 891011, time.UTC)
+	payload := struct {
+		At      Timestamp  `json:"at"`
+		Pointer *Timestamp `json:"pointer"`
+		Missing *Timestamp `json:"missing,omitempty"`
+	}{At: NewTimestamp(at), Pointer: TimestampPtr(&at)}
+
+	data, err := json.Marshal(payload)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"at":"2024-03-04T05:06:07.000Z","pointer":"2024-03-04T05:06:07.000Z"}`, string(data))
+	assert.Nil(t, TimestampPtr(nil))
+}
+
+func TestTimestamp_UnmarshalJSON(t *testing.T) {
+	for _, value := range []string{`"2024-01-01T12:30:45Z"`, `"2024-01-01T12:30:45.123Z"`, `"2024-01-01T14:30:45.123456789+02:00"`} {
+		var ts Timestamp
+		require.NoError(t, json.Unmarshal([]byte(value), &ts), value)
+		assert.Equal(t, time.Date(2024, 1, 1, 12, 30, 45, 0, time.UTC), ts.UTC().Truncate(time.Second), value)
+	}
+
+	var ts Timestamp
+	assert.Error(t, json.Unmarshal([]byte(`"2024-01-01"`), &ts))
+}
+
+func TestTimestamp_KeepsFullPrecision(t *testing.T) {
+	at := time.Date(2024, 1, 1, 12, 30, 45, 123456789, time.UTC)
+	ts := NewTimestamp(at)
+
+	assert.True(t, ts.Equal(at))
+	assert.Equal(t, &at, TimestampPtr(&at).TimePtr())
+	assert.Nil(t, (*Timestamp)(nil).TimePtr())
+	assert.Equal(t, "2024-01-01T12:30:45.123Z", ts.String())
+}
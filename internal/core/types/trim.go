@@ -0,0 +1,32 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// TrimParam is the query parameter cutting a search at the cliff of its scores
+const TrimParam = "trim"
+
+const (
+	// TrimOff returns every result up to the limit, the default
+	TrimOff = "off"
+	// TrimAuto drops the results after the largest fall in score
+	TrimAuto = "auto"
+)
+
+// ParseTrim reports whether the trim query parameter asks for trim=auto. Results in the
+// picker view carry no score, asking to trim them is an error.
+func ParseTrim(query url.Values, view string) (bool, error) {
+	switch strings.TrimSpace(query.Get(TrimParam)) {
+	case "", TrimOff:
+		return false, nil
+	case TrimAuto:
+		if view == ViewPicker {
+			return false, fmt.Errorf("%s: %s is not available with %s=%s", TrimParam, TrimAuto, ViewParam, ViewPicker)
+		}
+		return true, nil
+	}
+	return false, fmt.Errorf("%s: must be one of %s, %s", TrimParam, TrimOff, TrimAuto)
+}
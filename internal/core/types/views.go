@@ -0,0 +1,30 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ViewParam is the query parameter choosing how much of each entity a search returns
+const ViewParam = "view"
+
+const (
+	// ViewFull returns the entities whole
+	ViewFull = "full"
+	// ViewPicker returns the ID, name and the few fields a picker shows of each entity,
+	// read by queries selecting only those columns
+	ViewPicker = "picker"
+)
+
+// ParseView returns the view of the view query parameter, ViewFull when it is left out
+func ParseView(query url.Values) (string, error) {
+	view := strings.TrimSpace(query.Get(ViewParam))
+	switch view {
+	case "":
+		return ViewFull, nil
+	case ViewFull, ViewPicker:
+		return view, nil
+	}
+	return "", fmt.Errorf("%s: must be one of %s, %s", ViewParam, ViewFull, ViewPicker)
+}
@@ -12,6 +12,61 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const anonymizeContact = `-- name: AnonymizeContact :exec
+UPDATE contacts
+SET
+    name = $1,
+    phone = $2,
+    email = $3,
+    address_line1 = $4,
+    address_line2 = $5,
+    zip_postal_code = $6,
+    company = $7,
+    notes = $8,
+    links = '[]'
+WHERE contact_id = $9
+`
+
+type AnonymizeContactParams struct {
+	Name          string      `json:"name"`
+	Phone         pgtype.Text `json:"phone"`
+	Email         pgtype.Text `json:"email"`
+	AddressLine1  pgtype.Text `json:"addressLine1"`
+	AddressLine2  pgtype.Text `json:"addressLine2"`
+	ZipPostalCode pgtype.Text `json:"zipPostalCode"`
+	Company       pgtype.Text `json:"company"`
+	Notes         pgtype.Text `json:"notes"`
+	ContactID     uuid.UUID   `json:"contactId"`
+}
+
+// timestamps are left alone so reporting on them still works
+func (q *Queries) AnonymizeContact(ctx context.Context, arg AnonymizeContactParams) error {
+	_, err := q.db.Exec(ctx, anonymizeContact,
+		arg.Name,
+		arg.Phone,
+		arg.Email,
+		arg.AddressLine1,
+		arg.AddressLine2,
+		arg.ZipPostalCode,
+		arg.Company,
+		arg.Notes,
+		arg.ContactID,
+	)
+	return err
+}
+
+const countContacts = `-- name: CountContacts :one
+SELECT COUNT(*) FROM contacts
+WHERE user_id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) CountContacts(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countContacts, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createContact = `-- name: CreateContact :one
 INSERT INTO contacts (
     user_id,
@@ -24,11 +79,31 @@ INSERT INTO contacts (
     city,
     state_province,
     zip_postal_code,
-    tags
+    tags,
+    company,
+    notes,
+    links,
+    created_by,
+    updated_by
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
+    $10,
+    owned_tags($1, $11::uuid[]),
+    $12,
+    $13,
+    $14,
+    $15::uuid,
+    $15::uuid
 )
-RETURNING contact_id, user_id, name, phone, email, address_line1, address_line2, country, city, state_province, zip_postal_code, tags, created_at, updated_at
+RETURNING contact_id, user_id, name, phone, email, address_line1, address_line2, country, city, state_province, zip_postal_code, tags, created_at, updated_at, company, deleted_at, notes, notes_search, created_by, updated_by, email_key, external_source, external_id, links
 `
 
 type CreateContactParams struct {
@@ -43,6 +118,10 @@ type CreateContactParams struct {
 	StateProvince pgtype.Text `json:"stateProvince"`
 	ZipPostalCode pgtype.Text `json:"zipPostalCode"`
 	Tags          []uuid.UUID `json:"tags"`
+	Company       pgtype.Text `json:"company"`
+	Notes         pgtype.Text `json:"notes"`
+	Links         []byte      `json:"links"`
+	ActorID       uuid.UUID   `json:"actorId"`
 }
 
 func (q *Queries) CreateContact(ctx context.Context, arg CreateContactParams) (Contact, error) {
@@ -58,6 +137,10 @@ func (q *Queries) CreateContact(ctx context.Context, arg CreateContactParams) (C
 		arg.StateProvince,
 		arg.ZipPostalCode,
 		arg.Tags,
+		arg.Company,
+		arg.Notes,
+		arg.Links,
+		arg.ActorID,
 	)
 	var i Contact
 	err := row.Scan(
@@ -75,28 +158,45 @@ func (q *Queries) CreateContact(ctx context.Context, arg CreateContactParams) (C
 		&i.Tags,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Company,
+		&i.DeletedAt,
+		&i.Notes,
+		&i.NotesSearch,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.EmailKey,
+		&i.ExternalSource,
+		&i.ExternalID,
+		&i.Links,
 	)
 	return i, err
 }
 
-const deleteContact = `-- name: DeleteContact :exec
-DELETE FROM contacts
-WHERE contact_id = $1 AND user_id = $2
+const deleteContact = `-- name: DeleteContact :execrows
+UPDATE contacts
+SET deleted_at = CURRENT_TIMESTAMP
+WHERE contact_id = $1 AND user_id = $2 AND deleted_at IS NULL
+  AND ($3::timestamp IS NULL OR updated_at = $3)
 `
 
 type DeleteContactParams struct {
-	ContactID uuid.UUID `json:"contactId"`
-	UserID    uuid.UUID `json:"userId"`
+	ContactID uuid.UUID        `json:"contactId"`
+	UserID    uuid.UUID        `json:"userId"`
+	Version   pgtype.Timestamp `json:"version"`
 }
 
-func (q *Queries) DeleteContact(ctx context.Context, arg DeleteContactParams) error {
-	_, err := q.db.Exec(ctx, deleteContact, arg.ContactID, arg.UserID)
-	return err
+// a version set only trashes the contact while it is still at that updated_at
+func (q *Queries) DeleteContact(ctx context.Context, arg DeleteContactParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteContact, arg.ContactID, arg.UserID, arg.Version)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const getContact = `-- name: GetContact :one
-SELECT contact_id, user_id, name, phone, email, address_line1, address_line2, country, city, state_province, zip_postal_code, tags, created_at, updated_at FROM contacts
-WHERE contact_id = $1 AND user_id = $2 LIMIT 1
+SELECT contact_id, user_id, name, phone, email, address_line1, address_line2, country, city, state_province, zip_postal_code, tags, created_at, updated_at, company, deleted_at, notes, notes_search, created_by, updated_by, email_key, external_source, external_id, links FROM contacts
+WHERE contact_id = $1 AND user_id = $2 AND deleted_at IS NULL LIMIT 1
 `
 
 type GetContactParams struct {
@@ -122,13 +222,273 @@ func (q *Queries) GetContact(ctx context.Context, arg GetContactParams) (Contact
 		&i.Tags,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Company,
+		&i.DeletedAt,
+		&i.Notes,
+		&i.NotesSearch,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.EmailKey,
+		&i.ExternalSource,
+		&i.ExternalID,
+		&i.Links,
 	)
 	return i, err
 }
 
-const listContacts = `-- name: ListContacts :many
-SELECT contact_id, user_id, name, phone, email, address_line1, address_line2, country, city, state_province, zip_postal_code, tags, created_at, updated_at FROM contacts
+const getContactsByIDs = `-- name: GetContactsByIDs :many
+SELECT contact_id, user_id, name, phone, email, address_line1, address_line2, country, city, state_province, zip_postal_code, tags, created_at, updated_at, company, deleted_at, notes, notes_search, created_by, updated_by, email_key, external_source, external_id, links FROM contacts
+WHERE contact_id = ANY($1::uuid[]) AND user_id = $2 AND deleted_at IS NULL
+`
+
+type GetContactsByIDsParams struct {
+	ContactIds []uuid.UUID `json:"contactIds"`
+	UserID     uuid.UUID   `json:"userId"`
+}
+
+// in no particular order, the repository puts them in the order asked for
+func (q *Queries) GetContactsByIDs(ctx context.Context, arg GetContactsByIDsParams) ([]Contact, error) {
+	rows, err := q.db.Query(ctx, getContactsByIDs, arg.ContactIds, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Contact
+	for rows.Next() {
+		var i Contact
+		if err := rows.Scan(
+			&i.ContactID,
+			&i.UserID,
+			&i.Name,
+			&i.Phone,
+			&i.Email,
+			&i.AddressLine1,
+			&i.AddressLine2,
+			&i.Country,
+			&i.City,
+			&i.StateProvince,
+			&i.ZipPostalCode,
+			&i.Tags,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Company,
+			&i.DeletedAt,
+			&i.Notes,
+			&i.NotesSearch,
+			&i.CreatedBy,
+			&i.UpdatedBy,
+			&i.EmailKey,
+			&i.ExternalSource,
+			&i.ExternalID,
+			&i.Links,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listContactCompanies = `-- name: ListContactCompanies :many
+SELECT
+    g.company::text AS company,
+    g.contact_count,
+    c.contact_id,
+    c.name,
+    c.email,
+    c.phone
+FROM (
+    SELECT grouped.company, COUNT(*) AS contact_count
+    FROM contacts grouped
+    WHERE grouped.user_id = $1 AND grouped.company IS NOT NULL AND grouped.deleted_at IS NULL
+    GROUP BY grouped.company
+    ORDER BY
+        CASE WHEN $2::text = 'name' THEN grouped.company END ASC,
+        COUNT(*) DESC,
+        grouped.company ASC
+    LIMIT $3
+) g
+CROSS JOIN LATERAL (
+    SELECT member.contact_id, member.name, member.email, member.phone
+    FROM contacts member
+    WHERE member.user_id = $1 AND member.company = g.company AND member.deleted_at IS NULL
+    ORDER BY member.name ASC, member.contact_id ASC
+    LIMIT $4::int
+) c
+ORDER BY
+    CASE WHEN $2::text = 'name' THEN g.company END ASC,
+    g.contact_count DESC,
+    g.company ASC,
+    c.name ASC,
+    c.contact_id ASC
+`
+
+type ListContactCompaniesParams struct {
+	UserID        uuid.UUID `json:"userId"`
+	SortBy        string    `json:"sortBy"`
+	Limit         int32     `json:"limit"`
+	ContactsLimit int32     `json:"contactsLimit"`
+}
+
+type ListContactCompaniesRow struct {
+	Company      string      `json:"company"`
+	ContactCount int64       `json:"contactCount"`
+	ContactID    uuid.UUID   `json:"contactId"`
+	Name         string      `json:"name"`
+	Email        pgtype.Text `json:"email"`
+	Phone        pgtype.Text `json:"phone"`
+}
+
+func (q *Queries) ListContactCompanies(ctx context.Context, arg ListContactCompaniesParams) ([]ListContactCompaniesRow, error) {
+	rows, err := q.db.Query(ctx, listContactCompanies,
+		arg.UserID,
+		arg.SortBy,
+		arg.Limit,
+		arg.ContactsLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListContactCompaniesRow
+	for rows.Next() {
+		var i ListContactCompaniesRow
+		if err := rows.Scan(
+			&i.Company,
+			&i.ContactCount,
+			&i.ContactID,
+			&i.Name,
+			&i.Email,
+			&i.Phone,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listContactFacets = `-- name: ListContactFacets :many
+SELECT facet.value::text AS value, COUNT(*) AS count
+FROM (
+    SELECT CASE $1::text
+        WHEN 'country' THEN country
+        WHEN 'city' THEN city
+        WHEN 'company' THEN company
+    END AS value
+    FROM contacts
+    WHERE user_id = $2 AND deleted_at IS NULL
+) facet
+WHERE facet.value IS NOT NULL
+GROUP BY facet.value
+ORDER BY count DESC, facet.value ASC
+`
+
+type ListContactFacetsParams struct {
+	Field  string    `json:"field"`
+	UserID uuid.UUID `json:"userId"`
+}
+
+type ListContactFacetsRow struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// counts the distinct values of the facetable field of the user's contacts, most common first
+func (q *Queries) ListContactFacets(ctx context.Context, arg ListContactFacetsParams) ([]ListContactFacetsRow, error) {
+	rows, err := q.db.Query(ctx, listContactFacets, arg.Field, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListContactFacetsRow
+	for rows.Next() {
+		var i ListContactFacetsRow
+		if err := rows.Scan(&i.Value, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listContactIDsPaginated = `-- name: ListContactIDsPaginated :many
+SELECT contact_id, created_at
+FROM contacts
 WHERE user_id = $1
+  AND deleted_at IS NULL
+  AND ($2::text IS NULL OR lower(city) = lower($2::text))
+  AND ($3::text IS NULL OR lower(state_province) = lower($3::text))
+  AND (
+      ($4::text = 'asc'
+          AND (created_at > $5 OR (created_at = $5 AND contact_id > $6)))
+      OR ($4::text <> 'asc'
+          AND (created_at < $5 OR (created_at = $5 AND contact_id < $6)))
+  )
+ORDER BY
+    CASE WHEN $4::text = 'asc' THEN created_at END ASC,
+    CASE WHEN $4::text = 'asc' THEN contact_id END ASC,
+    CASE WHEN $4::text <> 'asc' THEN created_at END DESC,
+    CASE WHEN $4::text <> 'asc' THEN contact_id END DESC
+LIMIT $7
+`
+
+type ListContactIDsPaginatedParams struct {
+	UserID        uuid.UUID        `json:"userId"`
+	City          pgtype.Text      `json:"city"`
+	StateProvince pgtype.Text      `json:"stateProvince"`
+	SortOrder     string           `json:"sortOrder"`
+	CreatedAt     pgtype.Timestamp `json:"createdAt"`
+	ContactID     uuid.UUID        `json:"contactId"`
+	Limit         int32            `json:"limit"`
+}
+
+type ListContactIDsPaginatedRow struct {
+	ContactID uuid.UUID        `json:"contactId"`
+	CreatedAt pgtype.Timestamp `json:"createdAt"`
+}
+
+// ListContactsPaginated selecting only the keys the list is paged by
+func (q *Queries) ListContactIDsPaginated(ctx context.Context, arg ListContactIDsPaginatedParams) ([]ListContactIDsPaginatedRow, error) {
+	rows, err := q.db.Query(ctx, listContactIDsPaginated,
+		arg.UserID,
+		arg.City,
+		arg.StateProvince,
+		arg.SortOrder,
+		arg.CreatedAt,
+		arg.ContactID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListContactIDsPaginatedRow
+	for rows.Next() {
+		var i ListContactIDsPaginatedRow
+		if err := rows.Scan(&i.ContactID, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listContacts = `-- name: ListContacts :many
+SELECT contact_id, user_id, name, phone, email, address_line1, address_line2, country, city, state_province, zip_postal_code, tags, created_at, updated_at, company, deleted_at, notes, notes_search, created_by, updated_by, email_key, external_source, external_id, links FROM contacts
+WHERE user_id = $1 AND deleted_at IS NULL
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3
 `
@@ -163,6 +523,16 @@ func (q *Queries) ListContacts(ctx context.Context, arg ListContactsParams) ([]C
 			&i.Tags,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Company,
+			&i.DeletedAt,
+			&i.Notes,
+			&i.NotesSearch,
+			&i.CreatedBy,
+			&i.UpdatedBy,
+			&i.EmailKey,
+			&i.ExternalSource,
+			&i.ExternalID,
+			&i.Links,
 		); err != nil {
 			return nil, err
 		}
@@ -174,29 +544,22 @@ func (q *Queries) ListContacts(ctx context.Context, arg ListContactsParams) ([]C
 	return items, nil
 }
 
-const listContactsPaginated = `-- name: ListContactsPaginated :many
-SELECT contact_id, user_id, name, phone, email, address_line1, address_line2, country, city, state_province, zip_postal_code, tags, created_at, updated_at 
-FROM contacts
-WHERE user_id = $1 
-  AND (created_at < $2 OR (created_at = $2 AND contact_id < $3))
-ORDER BY created_at DESC, contact_id DESC
-LIMIT $4
+const listContactsForAnonymization = `-- name: ListContactsForAnonymization :many
+SELECT contact_id, user_id, name, phone, email, address_line1, address_line2, country, city, state_province, zip_postal_code, tags, created_at, updated_at, company, deleted_at, notes, notes_search, created_by, updated_by, email_key, external_source, external_id, links FROM contacts
+WHERE user_id = $1 AND contact_id > $2
+ORDER BY contact_id
+LIMIT $3
 `
 
-type ListContactsPaginatedParams struct {
-	UserID    uuid.UUID        `json:"userId"`
-	CreatedAt pgtype.Timestamp `json:"createdAt"`
-	ContactID uuid.UUID        `json:"contactId"`
-	Limit     int32            `json:"limit"`
+type ListContactsForAnonymizationParams struct {
+	UserID    uuid.UUID `json:"userId"`
+	ContactID uuid.UUID `json:"contactId"`
+	Limit     int32     `json:"limit"`
 }
 
-func (q *Queries) ListContactsPaginated(ctx context.Context, arg ListContactsPaginatedParams) ([]Contact, error) {
-	rows, err := q.db.Query(ctx, listContactsPaginated,
-		arg.UserID,
-		arg.CreatedAt,
-		arg.ContactID,
-		arg.Limit,
-	)
+// trashed contacts included, ordered by ID so batches resume after the last one
+func (q *Queries) ListContactsForAnonymization(ctx context.Context, arg ListContactsForAnonymizationParams) ([]Contact, error) {
+	rows, err := q.db.Query(ctx, listContactsForAnonymization, arg.UserID, arg.ContactID, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -219,6 +582,16 @@ func (q *Queries) ListContactsPaginated(ctx context.Context, arg ListContactsPag
 			&i.Tags,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Company,
+			&i.DeletedAt,
+			&i.Notes,
+			&i.NotesSearch,
+			&i.CreatedBy,
+			&i.UpdatedBy,
+			&i.EmailKey,
+			&i.ExternalSource,
+			&i.ExternalID,
+			&i.Links,
 		); err != nil {
 			return nil, err
 		}
@@ -230,30 +603,48 @@ func (q *Queries) ListContactsPaginated(ctx context.Context, arg ListContactsPag
 	return items, nil
 }
 
-const searchContacts = `-- name: SearchContacts :many
-SELECT contact_id, user_id, name, phone, email, address_line1, address_line2, country, city, state_province, zip_postal_code, tags, created_at, updated_at
+const listContactsPaginated = `-- name: ListContactsPaginated :many
+SELECT contact_id, user_id, name, phone, email, address_line1, address_line2, country, city, state_province, zip_postal_code, tags, created_at, updated_at, company, deleted_at, notes, notes_search, created_by, updated_by, email_key, external_source, external_id, links
 FROM contacts
 WHERE user_id = $1
+  AND deleted_at IS NULL
+  AND ($2::text IS NULL OR lower(city) = lower($2::text))
+  AND ($3::text IS NULL OR lower(state_province) = lower($3::text))
   AND (
-      $2::text = ''  -- No filter applied if sqlc.arg('name') is empty
-      OR name ILIKE '%' || $2 || '%'  -- Substring match
-      OR name <-> $2 < 0.9  -- Trigram similarity with threshold high for low sim to be included
+      ($4::text = 'asc'
+          AND (created_at > $5 OR (created_at = $5 AND contact_id > $6)))
+      OR ($4::text <> 'asc'
+          AND (created_at < $5 OR (created_at = $5 AND contact_id < $6)))
   )
-ORDER BY 
-    CASE WHEN $2 = '' THEN created_at END DESC,  -- If sqlc.arg('name') is empty, sort by created_at
-    CASE WHEN $2 <> '' THEN name <-> $2 END,  -- If sqlc.arg('name') is provided, sort by trigram similarity
-    length(name) ASC  -- Shorter names are preferred as tiebreaker
-LIMIT $3
+ORDER BY
+    CASE WHEN $4::text = 'asc' THEN created_at END ASC,
+    CASE WHEN $4::text = 'asc' THEN contact_id END ASC,
+    CASE WHEN $4::text <> 'asc' THEN created_at END DESC,
+    CASE WHEN $4::text <> 'asc' THEN contact_id END DESC
+LIMIT $7
 `
 
-type SearchContactsParams struct {
-	UserID uuid.UUID `json:"userId"`
-	Name   string    `json:"name"`
-	Limit  int32     `json:"limit"`
+type ListContactsPaginatedParams struct {
+	UserID        uuid.UUID        `json:"userId"`
+	City          pgtype.Text      `json:"city"`
+	StateProvince pgtype.Text      `json:"stateProvince"`
+	SortOrder     string           `json:"sortOrder"`
+	CreatedAt     pgtype.Timestamp `json:"createdAt"`
+	ContactID     uuid.UUID        `json:"contactId"`
+	Limit         int32            `json:"limit"`
 }
 
-func (q *Queries) SearchContacts(ctx context.Context, arg SearchContactsParams) ([]Contact, error) {
-	rows, err := q.db.Query(ctx, searchContacts, arg.UserID, arg.Name, arg.Limit)
+// city and state_province, when set, match the normalized values stored ignoring case.
+func (q *Queries) ListContactsPaginated(ctx context.Context, arg ListContactsPaginatedParams) ([]Contact, error) {
+	rows, err := q.db.Query(ctx, listContactsPaginated,
+		arg.UserID,
+		arg.City,
+		arg.StateProvince,
+		arg.SortOrder,
+		arg.CreatedAt,
+		arg.ContactID,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -276,6 +667,16 @@ func (q *Queries) SearchContacts(ctx context.Context, arg SearchContactsParams)
 			&i.Tags,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Company,
+			&i.DeletedAt,
+			&i.Notes,
+			&i.NotesSearch,
+			&i.CreatedBy,
+			&i.UpdatedBy,
+			&i.EmailKey,
+			&i.ExternalSource,
+			&i.ExternalID,
+			&i.Links,
 		); err != nil {
 			return nil, err
 		}
@@ -287,33 +688,41 @@ func (q *Queries) SearchContacts(ctx context.Context, arg SearchContactsParams)
 	return items, nil
 }
 
-const searchContactsByPhone = `-- name: SearchContactsByPhone :many
-SELECT contact_id, user_id, name, phone, email, address_line1, address_line2, country, city, state_province, zip_postal_code, tags, created_at, updated_at
+const listDeletedContactsPaginated = `-- name: ListDeletedContactsPaginated :many
+SELECT contact_id, user_id, name, phone, email, address_line1, address_line2, country, city, state_province, zip_postal_code, tags, created_at, updated_at, company, deleted_at, notes, notes_search, created_by, updated_by, email_key, external_source, external_id, links
 FROM contacts
 WHERE user_id = $1
+  AND deleted_at IS NOT NULL
   AND (
-      $2::text = ''  -- No filter applied if sqlc.arg('phone') is empty
-      OR phone LIKE $2 || '%'
+      ($2::text = 'asc'
+          AND (deleted_at > $3 OR (deleted_at = $3 AND contact_id > $4)))
+      OR ($2::text <> 'asc'
+          AND (deleted_at < $3 OR (deleted_at = $3 AND contact_id < $4)))
   )
-ORDER BY 
-    CASE WHEN $2 = '' THEN created_at END DESC,
-    CASE 
-        WHEN phone = $2 THEN 1  -- Exact match
-        WHEN phone LIKE $2 || '%' THEN 2  -- Starts with
-        ELSE 3  -- Contains
-    END,
-    created_at DESC
-LIMIT $3
+ORDER BY
+    CASE WHEN $2::text = 'asc' THEN deleted_at END ASC,
+    CASE WHEN $2::text = 'asc' THEN contact_id END ASC,
+    CASE WHEN $2::text <> 'asc' THEN deleted_at END DESC,
+    CASE WHEN $2::text <> 'asc' THEN contact_id END DESC
+LIMIT $5
 `
 
-type SearchContactsByPhoneParams struct {
-	UserID uuid.UUID `json:"userId"`
-	Phone  string    `json:"phone"`
-	Limit  int32     `json:"limit"`
+type ListDeletedContactsPaginatedParams struct {
+	UserID    uuid.UUID        `json:"userId"`
+	SortOrder string           `json:"sortOrder"`
+	DeletedAt pgtype.Timestamp `json:"deletedAt"`
+	ContactID uuid.UUID        `json:"contactId"`
+	Limit     int32            `json:"limit"`
 }
 
-func (q *Queries) SearchContactsByPhone(ctx context.Context, arg SearchContactsByPhoneParams) ([]Contact, error) {
-	rows, err := q.db.Query(ctx, searchContactsByPhone, arg.UserID, arg.Phone, arg.Limit)
+func (q *Queries) ListDeletedContactsPaginated(ctx context.Context, arg ListDeletedContactsPaginatedParams) ([]Contact, error) {
+	rows, err := q.db.Query(ctx, listDeletedContactsPaginated,
+		arg.UserID,
+		arg.SortOrder,
+		arg.DeletedAt,
+		arg.ContactID,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -336,6 +745,16 @@ func (q *Queries) SearchContactsByPhone(ctx context.Context, arg SearchContactsB
 			&i.Tags,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Company,
+			&i.DeletedAt,
+			&i.Notes,
+			&i.NotesSearch,
+			&i.CreatedBy,
+			&i.UpdatedBy,
+			&i.EmailKey,
+			&i.ExternalSource,
+			&i.ExternalID,
+			&i.Links,
 		); err != nil {
 			return nil, err
 		}
@@ -347,42 +766,561 @@ func (q *Queries) SearchContactsByPhone(ctx context.Context, arg SearchContactsB
 	return items, nil
 }
 
-const updateContact = `-- name: UpdateContact :one
-UPDATE contacts
-SET 
-    name = COALESCE($1, name),
-    phone = $2,
-    email = $3,
-    address_line1 = $4,
-    address_line2 = $5,
-    country = $6,
-    city = $7,
-    state_province = $8,
-    zip_postal_code = $9,
-    tags = $10,
-    updated_at = CURRENT_TIMESTAMP
-WHERE contact_id = $11 AND user_id = $12
-RETURNING contact_id, user_id, name, phone, email, address_line1, address_line2, country, city, state_province, zip_postal_code, tags, created_at, updated_at
+const purgeDeletedContacts = `-- name: PurgeDeletedContacts :execrows
+DELETE FROM contacts
+WHERE contact_id IN (
+    SELECT c.contact_id FROM contacts c
+    WHERE c.deleted_at IS NOT NULL AND c.deleted_at < $1
+    ORDER BY c.deleted_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
 `
 
-type UpdateContactParams struct {
-	Name          pgtype.Text `json:"name"`
-	Phone         pgtype.Text `json:"phone"`
-	Email         pgtype.Text `json:"email"`
-	AddressLine1  pgtype.Text `json:"addressLine1"`
-	AddressLine2  pgtype.Text `json:"addressLine2"`
-	Country       pgtype.Text `json:"country"`
-	City          pgtype.Text `json:"city"`
-	StateProvince pgtype.Text `json:"stateProvince"`
-	ZipPostalCode pgtype.Text `json:"zipPostalCode"`
-	Tags          []uuid.UUID `json:"tags"`
-	ContactID     uuid.UUID   `json:"contactId"`
-	UserID        uuid.UUID   `json:"userId"`
+type PurgeDeletedContactsParams struct {
+	DeletedBefore pgtype.Timestamp `json:"deletedBefore"`
+	BatchSize     int32            `json:"batchSize"`
 }
 
-func (q *Queries) UpdateContact(ctx context.Context, arg UpdateContactParams) (Contact, error) {
-	row := q.db.QueryRow(ctx, updateContact,
-		arg.Name,
+// at most batch_size rows per call, oldest first, skipping rows locked by a restore
+func (q *Queries) PurgeDeletedContacts(ctx context.Context, arg PurgeDeletedContactsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeDeletedContacts, arg.DeletedBefore, arg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const restoreContact = `-- name: RestoreContact :one
+UPDATE contacts
+SET deleted_at = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE contact_id = $1 AND user_id = $2 AND deleted_at IS NOT NULL
+RETURNING contact_id, user_id, name, phone, email, address_line1, address_line2, country, city, state_province, zip_postal_code, tags, created_at, updated_at, company, deleted_at, notes, notes_search, created_by, updated_by, email_key, external_source, external_id, links
+`
+
+type RestoreContactParams struct {
+	ContactID uuid.UUID `json:"contactId"`
+	UserID    uuid.UUID `json:"userId"`
+}
+
+func (q *Queries) RestoreContact(ctx context.Context, arg RestoreContactParams) (Contact, error) {
+	row := q.db.QueryRow(ctx, restoreContact, arg.ContactID, arg.UserID)
+	var i Contact
+	err := row.Scan(
+		&i.ContactID,
+		&i.UserID,
+		&i.Name,
+		&i.Phone,
+		&i.Email,
+		&i.AddressLine1,
+		&i.AddressLine2,
+		&i.Country,
+		&i.City,
+		&i.StateProvince,
+		&i.ZipPostalCode,
+		&i.Tags,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Company,
+		&i.DeletedAt,
+		&i.Notes,
+		&i.NotesSearch,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.EmailKey,
+		&i.ExternalSource,
+		&i.ExternalID,
+		&i.Links,
+	)
+	return i, err
+}
+
+const searchContacts = `-- name: SearchContacts :many
+SELECT contacts.contact_id, contacts.user_id, contacts.name, contacts.phone, contacts.email, contacts.address_line1, contacts.address_line2, contacts.country, contacts.city, contacts.state_province, contacts.zip_postal_code, contacts.tags, contacts.created_at, contacts.updated_at, contacts.company, contacts.deleted_at, contacts.notes, contacts.notes_search, contacts.created_by, contacts.updated_by, contacts.email_key, contacts.external_source, contacts.external_id, contacts.links,
+    (1 - LEAST(f_unaccent(name) <-> f_unaccent($1), f_unaccent(company) <-> f_unaccent($1)))::real AS score
+FROM contacts
+WHERE user_id = $2
+  AND deleted_at IS NULL
+  AND (
+      $1::text = ''  -- No filter applied if sqlc.arg('name') is empty
+      OR f_unaccent(name) ILIKE '%' || f_unaccent($1) || '%'  -- Substring match, accents ignored
+      OR f_unaccent(name) <-> f_unaccent($1) < 0.9  -- Trigram similarity with threshold high for low sim to be included
+      OR f_unaccent(company) ILIKE '%' || f_unaccent($1) || '%'  -- Company is searchable as well
+  )
+ORDER BY 
+    CASE WHEN $1 = '' THEN created_at END DESC,  -- If sqlc.arg('name') is empty, sort by created_at
+    CASE WHEN $1 <> '' THEN LEAST(f_unaccent(name) <-> f_unaccent($1), f_unaccent(company) <-> f_unaccent($1)) END,  -- If sqlc.arg('name') is provided, sort by the closest of name and company
+    length(name) ASC,  -- Shorter names are preferred as tiebreaker
+    created_at DESC, contact_id DESC  -- then the newest, so ties always resolve the same way
+LIMIT $4
+OFFSET $3
+`
+
+type SearchContactsParams struct {
+	Name   string    `json:"name"`
+	UserID uuid.UUID `json:"userId"`
+	Offset int32     `json:"offset"`
+	Limit  int32     `json:"limit"`
+}
+
+type SearchContactsRow struct {
+	Contact Contact `json:"contact"`
+	Score   float32 `json:"score"`
+}
+
+// score is how similar the closest of name and company is to the query from 0 to 1,
+// meaningless without a query
+func (q *Queries) SearchContacts(ctx context.Context, arg SearchContactsParams) ([]SearchContactsRow, error) {
+	rows, err := q.db.Query(ctx, searchContacts,
+		arg.Name,
+		arg.UserID,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchContactsRow
+	for rows.Next() {
+		var i SearchContactsRow
+		if err := rows.Scan(
+			&i.Contact.ContactID,
+			&i.Contact.UserID,
+			&i.Contact.Name,
+			&i.Contact.Phone,
+			&i.Contact.Email,
+			&i.Contact.AddressLine1,
+			&i.Contact.AddressLine2,
+			&i.Contact.Country,
+			&i.Contact.City,
+			&i.Contact.StateProvince,
+			&i.Contact.ZipPostalCode,
+			&i.Contact.Tags,
+			&i.Contact.CreatedAt,
+			&i.Contact.UpdatedAt,
+			&i.Contact.Company,
+			&i.Contact.DeletedAt,
+			&i.Contact.Notes,
+			&i.Contact.NotesSearch,
+			&i.Contact.CreatedBy,
+			&i.Contact.UpdatedBy,
+			&i.Contact.EmailKey,
+			&i.Contact.ExternalSource,
+			&i.Contact.ExternalID,
+			&i.Contact.Links,
+			&i.Score,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchContactsByCompany = `-- name: SearchContactsByCompany :many
+SELECT contacts.contact_id, contacts.user_id, contacts.name, contacts.phone, contacts.email, contacts.address_line1, contacts.address_line2, contacts.country, contacts.city, contacts.state_province, contacts.zip_postal_code, contacts.tags, contacts.created_at, contacts.updated_at, contacts.company, contacts.deleted_at, contacts.notes, contacts.notes_search, contacts.created_by, contacts.updated_by, contacts.email_key, contacts.external_source, contacts.external_id, contacts.links,
+    (1 - (f_unaccent(company) <-> f_unaccent($1::text)))::real AS score
+FROM contacts
+WHERE user_id = $2
+  AND deleted_at IS NULL
+  AND company IS NOT NULL
+  AND (
+      f_unaccent(company) ILIKE '%' || f_unaccent($1::text) || '%'
+      OR f_unaccent(company) <-> f_unaccent($1::text) < 0.9
+  )
+ORDER BY 
+    f_unaccent(company) <-> f_unaccent($1::text),
+    name ASC,
+    created_at DESC, contact_id DESC  -- ties always resolve the same way
+LIMIT $4
+OFFSET $3
+`
+
+type SearchContactsByCompanyParams struct {
+	Company string    `json:"company"`
+	UserID  uuid.UUID `json:"userId"`
+	Offset  int32     `json:"offset"`
+	Limit   int32     `json:"limit"`
+}
+
+type SearchContactsByCompanyRow struct {
+	Contact Contact `json:"contact"`
+	Score   float32 `json:"score"`
+}
+
+// score is how similar the company is to the query from 0 to 1
+func (q *Queries) SearchContactsByCompany(ctx context.Context, arg SearchContactsByCompanyParams) ([]SearchContactsByCompanyRow, error) {
+	rows, err := q.db.Query(ctx, searchContactsByCompany,
+		arg.Company,
+		arg.UserID,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchContactsByCompanyRow
+	for rows.Next() {
+		var i SearchContactsByCompanyRow
+		if err := rows.Scan(
+			&i.Contact.ContactID,
+			&i.Contact.UserID,
+			&i.Contact.Name,
+			&i.Contact.Phone,
+			&i.Contact.Email,
+			&i.Contact.AddressLine1,
+			&i.Contact.AddressLine2,
+			&i.Contact.Country,
+			&i.Contact.City,
+			&i.Contact.StateProvince,
+			&i.Contact.ZipPostalCode,
+			&i.Contact.Tags,
+			&i.Contact.CreatedAt,
+			&i.Contact.UpdatedAt,
+			&i.Contact.Company,
+			&i.Contact.DeletedAt,
+			&i.Contact.Notes,
+			&i.Contact.NotesSearch,
+			&i.Contact.CreatedBy,
+			&i.Contact.UpdatedBy,
+			&i.Contact.EmailKey,
+			&i.Contact.ExternalSource,
+			&i.Contact.ExternalID,
+			&i.Contact.Links,
+			&i.Score,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchContactsByCompanyPicker = `-- name: SearchContactsByCompanyPicker :many
+SELECT contact_id, name, phone, email
+FROM contacts
+WHERE user_id = $1
+  AND deleted_at IS NULL
+  AND company IS NOT NULL
+  AND (
+      f_unaccent(company) ILIKE '%' || f_unaccent($2::text) || '%'
+      OR f_unaccent(company) <-> f_unaccent($2::text) < 0.9
+  )
+ORDER BY 
+    f_unaccent(company) <-> f_unaccent($2::text),
+    name ASC,
+    created_at DESC, contact_id DESC  -- ties always resolve the same way
+LIMIT $4
+OFFSET $3
+`
+
+type SearchContactsByCompanyPickerParams struct {
+	UserID  uuid.UUID `json:"userId"`
+	Company string    `json:"company"`
+	Offset  int32     `json:"offset"`
+	Limit   int32     `json:"limit"`
+}
+
+type SearchContactsByCompanyPickerRow struct {
+	ContactID uuid.UUID   `json:"contactId"`
+	Name      string      `json:"name"`
+	Phone     pgtype.Text `json:"phone"`
+	Email     pgtype.Text `json:"email"`
+}
+
+// SearchContactsByCompany selecting only the columns of the picker view
+func (q *Queries) SearchContactsByCompanyPicker(ctx context.Context, arg SearchContactsByCompanyPickerParams) ([]SearchContactsByCompanyPickerRow, error) {
+	rows, err := q.db.Query(ctx, searchContactsByCompanyPicker,
+		arg.UserID,
+		arg.Company,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchContactsByCompanyPickerRow
+	for rows.Next() {
+		var i SearchContactsByCompanyPickerRow
+		if err := rows.Scan(
+			&i.ContactID,
+			&i.Name,
+			&i.Phone,
+			&i.Email,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchContactsByPhone = `-- name: SearchContactsByPhone :many
+SELECT contact_id, user_id, name, phone, email, address_line1, address_line2, country, city, state_province, zip_postal_code, tags, created_at, updated_at, company, deleted_at, notes, notes_search, created_by, updated_by, email_key, external_source, external_id, links
+FROM contacts
+WHERE user_id = $1
+  AND deleted_at IS NULL
+  AND (
+      $2::text = ''  -- No filter applied if sqlc.arg('phone') is empty
+      OR phone LIKE $2 || '%'
+  )
+ORDER BY 
+    CASE WHEN $2 = '' THEN created_at END DESC,
+    CASE 
+        WHEN phone = $2 THEN 1  -- Exact match
+        WHEN phone LIKE $2 || '%' THEN 2  -- Starts with
+        ELSE 3  -- Contains
+    END,
+    created_at DESC, contact_id DESC
+LIMIT $4
+OFFSET $3
+`
+
+type SearchContactsByPhoneParams struct {
+	UserID uuid.UUID `json:"userId"`
+	Phone  string    `json:"phone"`
+	Offset int32     `json:"offset"`
+	Limit  int32     `json:"limit"`
+}
+
+func (q *Queries) SearchContactsByPhone(ctx context.Context, arg SearchContactsByPhoneParams) ([]Contact, error) {
+	rows, err := q.db.Query(ctx, searchContactsByPhone,
+		arg.UserID,
+		arg.Phone,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Contact
+	for rows.Next() {
+		var i Contact
+		if err := rows.Scan(
+			&i.ContactID,
+			&i.UserID,
+			&i.Name,
+			&i.Phone,
+			&i.Email,
+			&i.AddressLine1,
+			&i.AddressLine2,
+			&i.Country,
+			&i.City,
+			&i.StateProvince,
+			&i.ZipPostalCode,
+			&i.Tags,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Company,
+			&i.DeletedAt,
+			&i.Notes,
+			&i.NotesSearch,
+			&i.CreatedBy,
+			&i.UpdatedBy,
+			&i.EmailKey,
+			&i.ExternalSource,
+			&i.ExternalID,
+			&i.Links,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchContactsByPhonePicker = `-- name: SearchContactsByPhonePicker :many
+SELECT contact_id, name, phone, email
+FROM contacts
+WHERE user_id = $1
+  AND deleted_at IS NULL
+  AND (
+      $2::text = ''  -- No filter applied if sqlc.arg('phone') is empty
+      OR phone LIKE $2 || '%'
+  )
+ORDER BY 
+    CASE WHEN $2 = '' THEN created_at END DESC,
+    CASE 
+        WHEN phone = $2 THEN 1  -- Exact match
+        WHEN phone LIKE $2 || '%' THEN 2  -- Starts with
+        ELSE 3  -- Contains
+    END,
+    created_at DESC, contact_id DESC
+LIMIT $4
+OFFSET $3
+`
+
+type SearchContactsByPhonePickerParams struct {
+	UserID uuid.UUID `json:"userId"`
+	Phone  string    `json:"phone"`
+	Offset int32     `json:"offset"`
+	Limit  int32     `json:"limit"`
+}
+
+type SearchContactsByPhonePickerRow struct {
+	ContactID uuid.UUID   `json:"contactId"`
+	Name      string      `json:"name"`
+	Phone     pgtype.Text `json:"phone"`
+	Email     pgtype.Text `json:"email"`
+}
+
+// SearchContactsByPhone selecting only the columns of the picker view
+func (q *Queries) SearchContactsByPhonePicker(ctx context.Context, arg SearchContactsByPhonePickerParams) ([]SearchContactsByPhonePickerRow, error) {
+	rows, err := q.db.Query(ctx, searchContactsByPhonePicker,
+		arg.UserID,
+		arg.Phone,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchContactsByPhonePickerRow
+	for rows.Next() {
+		var i SearchContactsByPhonePickerRow
+		if err := rows.Scan(
+			&i.ContactID,
+			&i.Name,
+			&i.Phone,
+			&i.Email,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchContactsPicker = `-- name: SearchContactsPicker :many
+SELECT contact_id, name, phone, email
+FROM contacts
+WHERE user_id = $1
+  AND deleted_at IS NULL
+  AND (
+      $2::text = ''  -- No filter applied if sqlc.arg('name') is empty
+      OR f_unaccent(name) ILIKE '%' || f_unaccent($2) || '%'  -- Substring match, accents ignored
+      OR f_unaccent(name) <-> f_unaccent($2) < 0.9  -- Trigram similarity with threshold high for low sim to be included
+      OR f_unaccent(company) ILIKE '%' || f_unaccent($2) || '%'  -- Company is searchable as well
+  )
+ORDER BY 
+    CASE WHEN $2 = '' THEN created_at END DESC,  -- If sqlc.arg('name') is empty, sort by created_at
+    CASE WHEN $2 <> '' THEN LEAST(f_unaccent(name) <-> f_unaccent($2), f_unaccent(company) <-> f_unaccent($2)) END,  -- If sqlc.arg('name') is provided, sort by the closest of name and company
+    length(name) ASC,  -- Shorter names are preferred as tiebreaker
+    created_at DESC, contact_id DESC  -- then the newest, so ties always resolve the same way
+LIMIT $4
+OFFSET $3
+`
+
+type SearchContactsPickerParams struct {
+	UserID uuid.UUID `json:"userId"`
+	Name   string    `json:"name"`
+	Offset int32     `json:"offset"`
+	Limit  int32     `json:"limit"`
+}
+
+type SearchContactsPickerRow struct {
+	ContactID uuid.UUID   `json:"contactId"`
+	Name      string      `json:"name"`
+	Phone     pgtype.Text `json:"phone"`
+	Email     pgtype.Text `json:"email"`
+}
+
+// SearchContacts selecting only the columns of the picker view
+func (q *Queries) SearchContactsPicker(ctx context.Context, arg SearchContactsPickerParams) ([]SearchContactsPickerRow, error) {
+	rows, err := q.db.Query(ctx, searchContactsPicker,
+		arg.UserID,
+		arg.Name,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchContactsPickerRow
+	for rows.Next() {
+		var i SearchContactsPickerRow
+		if err := rows.Scan(
+			&i.ContactID,
+			&i.Name,
+			&i.Phone,
+			&i.Email,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateContact = `-- name: UpdateContact :one
+UPDATE contacts
+SET 
+    name = COALESCE($1, name),
+    phone = $2,
+    email = $3,
+    address_line1 = $4,
+    address_line2 = $5,
+    country = $6,
+    city = $7,
+    state_province = $8,
+    zip_postal_code = $9,
+    tags = owned_tags($10, $11::uuid[]),
+    company = $12,
+    notes = $13,
+    links = $14,
+    updated_at = CURRENT_TIMESTAMP,
+    updated_by = $15::uuid
+WHERE contact_id = $16 AND user_id = $10 AND deleted_at IS NULL
+RETURNING contact_id, user_id, name, phone, email, address_line1, address_line2, country, city, state_province, zip_postal_code, tags, created_at, updated_at, company, deleted_at, notes, notes_search, created_by, updated_by, email_key, external_source, external_id, links
+`
+
+type UpdateContactParams struct {
+	Name          pgtype.Text `json:"name"`
+	Phone         pgtype.Text `json:"phone"`
+	Email         pgtype.Text `json:"email"`
+	AddressLine1  pgtype.Text `json:"addressLine1"`
+	AddressLine2  pgtype.Text `json:"addressLine2"`
+	Country       pgtype.Text `json:"country"`
+	City          pgtype.Text `json:"city"`
+	StateProvince pgtype.Text `json:"stateProvince"`
+	ZipPostalCode pgtype.Text `json:"zipPostalCode"`
+	UserID        uuid.UUID   `json:"userId"`
+	Tags          []uuid.UUID `json:"tags"`
+	Company       pgtype.Text `json:"company"`
+	Notes         pgtype.Text `json:"notes"`
+	Links         []byte      `json:"links"`
+	ActorID       uuid.UUID   `json:"actorId"`
+	ContactID     uuid.UUID   `json:"contactId"`
+}
+
+func (q *Queries) UpdateContact(ctx context.Context, arg UpdateContactParams) (Contact, error) {
+	row := q.db.QueryRow(ctx, updateContact,
+		arg.Name,
 		arg.Phone,
 		arg.Email,
 		arg.AddressLine1,
@@ -391,9 +1329,103 @@ func (q *Queries) UpdateContact(ctx context.Context, arg UpdateContactParams) (C
 		arg.City,
 		arg.StateProvince,
 		arg.ZipPostalCode,
+		arg.UserID,
 		arg.Tags,
+		arg.Company,
+		arg.Notes,
+		arg.Links,
+		arg.ActorID,
 		arg.ContactID,
+	)
+	var i Contact
+	err := row.Scan(
+		&i.ContactID,
+		&i.UserID,
+		&i.Name,
+		&i.Phone,
+		&i.Email,
+		&i.AddressLine1,
+		&i.AddressLine2,
+		&i.Country,
+		&i.City,
+		&i.StateProvince,
+		&i.ZipPostalCode,
+		&i.Tags,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Company,
+		&i.DeletedAt,
+		&i.Notes,
+		&i.NotesSearch,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.EmailKey,
+		&i.ExternalSource,
+		&i.ExternalID,
+		&i.Links,
+	)
+	return i, err
+}
+
+const updateContactByExternalRef = `-- name: UpdateContactByExternalRef :one
+UPDATE contacts AS c
+SET
+    phone = COALESCE($1, c.phone),
+    email = COALESCE($2, c.email),
+    address_line1 = COALESCE($3, c.address_line1),
+    address_line2 = COALESCE($4, c.address_line2),
+    country = COALESCE($5, c.country),
+    city = COALESCE($6, c.city),
+    state_province = COALESCE($7, c.state_province),
+    zip_postal_code = COALESCE($8, c.zip_postal_code),
+    tags = CASE WHEN $9::uuid[] IS NULL THEN c.tags ELSE owned_tags($10, $9::uuid[]) END,
+    company = COALESCE($11, c.company),
+    notes = COALESCE($12, c.notes),
+    deleted_at = NULL,
+    updated_at = CURRENT_TIMESTAMP,
+    updated_by = $13::uuid
+WHERE c.user_id = $10
+  AND c.external_source = $14
+  AND c.external_id = $15
+RETURNING c.contact_id, c.user_id, c.name, c.phone, c.email, c.address_line1, c.address_line2, c.country, c.city, c.state_province, c.zip_postal_code, c.tags, c.created_at, c.updated_at, c.company, c.deleted_at, c.notes, c.notes_search, c.created_by, c.updated_by, c.email_key, c.external_source, c.external_id, c.links
+`
+
+type UpdateContactByExternalRefParams struct {
+	Phone          pgtype.Text `json:"phone"`
+	Email          pgtype.Text `json:"email"`
+	AddressLine1   pgtype.Text `json:"addressLine1"`
+	AddressLine2   pgtype.Text `json:"addressLine2"`
+	Country        pgtype.Text `json:"country"`
+	City           pgtype.Text `json:"city"`
+	StateProvince  pgtype.Text `json:"stateProvince"`
+	ZipPostalCode  pgtype.Text `json:"zipPostalCode"`
+	Tags           []uuid.UUID `json:"tags"`
+	UserID         uuid.UUID   `json:"userId"`
+	Company        pgtype.Text `json:"company"`
+	Notes          pgtype.Text `json:"notes"`
+	ActorID        uuid.UUID   `json:"actorId"`
+	ExternalSource pgtype.Text `json:"externalSource"`
+	ExternalID     pgtype.Text `json:"externalId"`
+}
+
+// UpsertContactByExternalRef without a name, which can only update
+func (q *Queries) UpdateContactByExternalRef(ctx context.Context, arg UpdateContactByExternalRefParams) (Contact, error) {
+	row := q.db.QueryRow(ctx, updateContactByExternalRef,
+		arg.Phone,
+		arg.Email,
+		arg.AddressLine1,
+		arg.AddressLine2,
+		arg.Country,
+		arg.City,
+		arg.StateProvince,
+		arg.ZipPostalCode,
+		arg.Tags,
 		arg.UserID,
+		arg.Company,
+		arg.Notes,
+		arg.ActorID,
+		arg.ExternalSource,
+		arg.ExternalID,
 	)
 	var i Contact
 	err := row.Scan(
@@ -411,6 +1443,153 @@ func (q *Queries) UpdateContact(ctx context.Context, arg UpdateContactParams) (C
 		&i.Tags,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Company,
+		&i.DeletedAt,
+		&i.Notes,
+		&i.NotesSearch,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.EmailKey,
+		&i.ExternalSource,
+		&i.ExternalID,
+		&i.Links,
+	)
+	return i, err
+}
+
+const upsertContactByExternalRef = `-- name: UpsertContactByExternalRef :one
+INSERT INTO contacts AS c (
+    user_id,
+    external_source,
+    external_id,
+    name,
+    phone,
+    email,
+    address_line1,
+    address_line2,
+    country,
+    city,
+    state_province,
+    zip_postal_code,
+    tags,
+    company,
+    notes,
+    created_by,
+    updated_by
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
+    $10,
+    $11,
+    $12,
+    owned_tags($1, $13::uuid[]),
+    $14,
+    $15,
+    $16::uuid,
+    $16::uuid
+)
+ON CONFLICT (user_id, external_source, external_id) DO UPDATE
+SET
+    name = EXCLUDED.name,
+    phone = COALESCE(EXCLUDED.phone, c.phone),
+    email = COALESCE(EXCLUDED.email, c.email),
+    address_line1 = COALESCE(EXCLUDED.address_line1, c.address_line1),
+    address_line2 = COALESCE(EXCLUDED.address_line2, c.address_line2),
+    country = COALESCE(EXCLUDED.country, c.country),
+    city = COALESCE(EXCLUDED.city, c.city),
+    state_province = COALESCE(EXCLUDED.state_province, c.state_province),
+    zip_postal_code = COALESCE(EXCLUDED.zip_postal_code, c.zip_postal_code),
+    tags = CASE WHEN $13::uuid[] IS NULL THEN c.tags ELSE EXCLUDED.tags END,
+    company = COALESCE(EXCLUDED.company, c.company),
+    notes = COALESCE(EXCLUDED.notes, c.notes),
+    deleted_at = NULL,
+    updated_at = CURRENT_TIMESTAMP,
+    updated_by = EXCLUDED.updated_by
+RETURNING c.contact_id, c.user_id, c.name, c.phone, c.email, c.address_line1, c.address_line2, c.country, c.city, c.state_province, c.zip_postal_code, c.tags, c.created_at, c.updated_at, c.company, c.deleted_at, c.notes, c.notes_search, c.created_by, c.updated_by, c.email_key, c.external_source, c.external_id, c.links, (c.xmax = 0)::boolean AS inserted
+`
+
+type UpsertContactByExternalRefParams struct {
+	UserID         uuid.UUID   `json:"userId"`
+	ExternalSource pgtype.Text `json:"externalSource"`
+	ExternalID     pgtype.Text `json:"externalId"`
+	Name           string      `json:"name"`
+	Phone          pgtype.Text `json:"phone"`
+	Email          pgtype.Text `json:"email"`
+	AddressLine1   pgtype.Text `json:"addressLine1"`
+	AddressLine2   pgtype.Text `json:"addressLine2"`
+	Country        pgtype.Text `json:"country"`
+	City           pgtype.Text `json:"city"`
+	StateProvince  pgtype.Text `json:"stateProvince"`
+	ZipPostalCode  pgtype.Text `json:"zipPostalCode"`
+	Tags           []uuid.UUID `json:"tags"`
+	Company        pgtype.Text `json:"company"`
+	Notes          pgtype.Text `json:"notes"`
+	ActorID        uuid.UUID   `json:"actorId"`
+}
+
+type UpsertContactByExternalRefRow struct {
+	Contact  Contact `json:"contact"`
+	Inserted bool    `json:"inserted"`
+}
+
+// creates the contact of the external reference or updates the fields the sync sent,
+// NULL args leave a field alone. A trashed contact is restored since its source still
+// has it. inserted tells a create from an update: xmax is 0 on freshly inserted rows.
+// Postgres checks NOT NULL before looking for a conflict, so name is always required
+// here and UpdateContactByExternalRef covers syncs without one.
+func (q *Queries) UpsertContactByExternalRef(ctx context.Context, arg UpsertContactByExternalRefParams) (UpsertContactByExternalRefRow, error) {
+	row := q.db.QueryRow(ctx, upsertContactByExternalRef,
+		arg.UserID,
+		arg.ExternalSource,
+		arg.ExternalID,
+		arg.Name,
+		arg.Phone,
+		arg.Email,
+		arg.AddressLine1,
+		arg.AddressLine2,
+		arg.Country,
+		arg.City,
+		arg.StateProvince,
+		arg.ZipPostalCode,
+		arg.Tags,
+		arg.Company,
+		arg.Notes,
+		arg.ActorID,
+	)
+	var i UpsertContactByExternalRefRow
+	err := row.Scan(
+		&i.Contact.ContactID,
+		&i.Contact.UserID,
+		&i.Contact.Name,
+		&i.Contact.Phone,
+		&i.Contact.Email,
+		&i.Contact.AddressLine1,
+		&i.Contact.AddressLine2,
+		&i.Contact.Country,
+		&i.Contact.City,
+		&i.Contact.StateProvince,
+		&i.Contact.ZipPostalCode,
+		&i.Contact.Tags,
+		&i.Contact.CreatedAt,
+		&i.Contact.UpdatedAt,
+		&i.Contact.Company,
+		&i.Contact.DeletedAt,
+		&i.Contact.Notes,
+		&i.Contact.NotesSearch,
+		&i.Contact.CreatedBy,
+		&i.Contact.UpdatedBy,
+		&i.Contact.EmailKey,
+		&i.Contact.ExternalSource,
+		&i.Contact.ExternalID,
+		&i.Contact.Links,
+		&i.Inserted,
 	)
 	return i, err
 }
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/Abdelrahman-habib/expense-tracker/config"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -14,6 +15,8 @@ type Service interface {
 	Health() map[string]string
 	Close() error
 	Queries() *Queries
+	Begin(ctx context.Context) (pgx.Tx, error)
+	MigrationsStatus(ctx context.Context) (int64, []MigrationState, error)
 }
 
 type service struct {
@@ -22,7 +25,19 @@ type service struct {
 	queries *Queries
 }
 
-func NewService(cfg config.DatabaseConfig) Service {
+// Option customizes the pool of a Service
+type Option func(*pgxpool.Config)
+
+// WithQueryTracer hooks tracer into every connection of the pool, a nil tracer is ignored
+func WithQueryTracer(tracer pgx.QueryTracer) Option {
+	return func(config *pgxpool.Config) {
+		if tracer != nil {
+			config.ConnConfig.Tracer = tracer
+		}
+	}
+}
+
+func NewService(cfg config.DatabaseConfig, opts ...Option) Service {
 	config, err := pgxpool.ParseConfig(cfg.GetDSN())
 	if err != nil {
 		log.Fatal(err)
@@ -35,6 +50,19 @@ func NewService(cfg config.DatabaseConfig) Service {
 	config.MaxConnIdleTime = cfg.MaxIdleTime
 	config.HealthCheckPeriod = cfg.HealthCheck
 
+	// owned_tags reads this setting to decide between dropping and rejecting unowned tags
+	if cfg.StrictTagOwnership {
+		config.ConnConfig.RuntimeParams["app.strict_tag_ownership"] = "on"
+	}
+	// contacts_email_key reads this setting to decide whether contact emails are unique
+	if cfg.UniqueContactEmails {
+		config.ConnConfig.RuntimeParams["app.unique_contact_emails"] = "on"
+	}
+
+	for _, opt := range opts {
+		opt(config)
+	}
+
 	pool, err := pgxpool.NewWithConfig(context.Background(), config)
 	if err != nil {
 		log.Fatal(err)
@@ -82,3 +110,8 @@ func (s *service) Close() error {
 func (s *service) Queries() *Queries {
 	return s.queries
 }
+
+// Begin starts a transaction on the pool. Use New(tx) to run queries inside it
+func (s *service) Begin(ctx context.Context) (pgx.Tx, error) {
+	return s.db.Begin(ctx)
+}
@@ -0,0 +1,27 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIDQueriesSelectOnlyTheirKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		columns []string
+	}{
+		{"ListContactIDsPaginated", listContactIDsPaginated, []string{"contact_id", "created_at"}},
+		{"ListProjectIDsPaginated", listProjectIDsPaginated, []string{"project_id", "created_at"}},
+		{"ListPinnedProjectIDsPaginated", listPinnedProjectIDsPaginated, []string{"project_id", "pinned_at"}},
+		{"ListWalletIDsPaginated", listWalletIDsPaginated, []string{"wallet_id", "created_at"}},
+		{"ListPinnedWalletIDsPaginated", listPinnedWalletIDsPaginated, []string{"wallet_id", "pinned_at"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.columns, selectedColumns(t, tt.query))
+		})
+	}
+}
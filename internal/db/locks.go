@@ -0,0 +1,59 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// EntityLockTimeout is how long a write waits for the advisory lock of its entity, past
+// it the wait fails with SQLSTATE 55P03 (lock_not_available)
+const EntityLockTimeout = 2 * time.Second
+
+// EntityLockKey hashes id to the bigint key of its advisory lock
+func EntityLockKey(id uuid.UUID) int64 {
+	hash := fnv.New64a()
+	_, _ = hash.Write(id[:])
+	return int64(hash.Sum64())
+}
+
+// txBeginner is implemented by the pool and by transactions, which begin a savepoint
+type txBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// WithEntityLock runs fn in a transaction holding the advisory lock of the entity id,
+// waiting at most EntityLockTimeout for it, so the writes of the entity reading its
+// current values run one after the other. fn queries through the Queries it's given,
+// the transaction is committed when it returns nil and its error is returned as it is.
+func (q *Queries) WithEntityLock(ctx context.Context, id uuid.UUID, fn func(q *Queries) error) (err error) {
+	beginner, ok := q.db.(txBeginner)
+	if !ok {
+		return fmt.Errorf("%T can't begin a transaction", q.db)
+	}
+	tx, err := beginner.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	locked := q.WithTx(tx)
+	if err = locked.SetLockTimeout(ctx, fmt.Sprintf("%dms", EntityLockTimeout.Milliseconds())); err != nil {
+		return err
+	}
+	if err = locked.LockEntity(ctx, EntityLockKey(id)); err != nil {
+		return err
+	}
+	if err = fn(locked); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+)
+
+//go:embed sql/migrations/*.sql
+var embeddedMigrations embed.FS
+
+// Migrations returns the goose migrations embedded into the binary
+func Migrations() fs.FS {
+	migrations, err := fs.Sub(embeddedMigrations, "sql/migrations")
+	if err != nil {
+		// the directory is embedded at compile time, so this can't happen
+		panic(err)
+	}
+	return migrations
+}
+
+// MigrationState describes a single embedded migration and whether it was applied
+type MigrationState struct {
+	Version   int64
+	Source    string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// MigrationsStatus reads the current version from goose's version table and
+// reports the state of every embedded migration
+func (s *service) MigrationsStatus(ctx context.Context) (int64, []MigrationState, error) {
+	sqlDB := stdlib.OpenDBFromPool(s.db)
+	defer sqlDB.Close()
+
+	provider, err := goose.NewProvider(goose.DialectPostgres, sqlDB, Migrations())
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to create migration provider: %w", err)
+	}
+
+	version, err := provider.GetDBVersion(ctx)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to get database version: %w", err)
+	}
+
+	statuses, err := provider.Status(ctx)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to get migrations status: %w", err)
+	}
+
+	states := make([]MigrationState, len(statuses))
+	for i, status := range statuses {
+		states[i] = MigrationState{
+			Version:   status.Source.Version,
+			Source:    status.Source.Path,
+			Applied:   status.State == goose.StateApplied,
+			AppliedAt: status.AppliedAt,
+		}
+	}
+
+	return version, states, nil
+}
@@ -1,5 +1,12 @@
 package db
 
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
 type MockService struct{}
 
 func (m *MockService) Health() map[string]string {
@@ -16,3 +23,11 @@ func (m *MockService) Close() error {
 func (m *MockService) Queries() *Queries {
 	return &Queries{} // Return empty Queries struct for documentation purposes
 }
+
+func (m *MockService) Begin(ctx context.Context) (pgx.Tx, error) {
+	return nil, fmt.Errorf("transactions are not supported by the mock service")
+}
+
+func (m *MockService) MigrationsStatus(ctx context.Context) (int64, []MigrationState, error) {
+	return 0, nil, nil
+}
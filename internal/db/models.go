@@ -12,6 +12,51 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type ContactRelationshipType string
+
+const (
+	ContactRelationshipTypeWorksFor   ContactRelationshipType = "works_for"
+	ContactRelationshipTypeReportsTo  ContactRelationshipType = "reports_to"
+	ContactRelationshipTypeSpouseOf   ContactRelationshipType = "spouse_of"
+	ContactRelationshipTypeRelativeOf ContactRelationshipType = "relative_of"
+	ContactRelationshipTypeReferredBy ContactRelationshipType = "referred_by"
+)
+
+func (e *ContactRelationshipType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ContactRelationshipType(s)
+	case string:
+		*e = ContactRelationshipType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ContactRelationshipType: %T", src)
+	}
+	return nil
+}
+
+type NullContactRelationshipType struct {
+	ContactRelationshipType ContactRelationshipType `json:"contactRelationshipType"`
+	Valid                   bool                    `json:"valid"` // Valid is true if ContactRelationshipType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullContactRelationshipType) Scan(value interface{}) error {
+	if value == nil {
+		ns.ContactRelationshipType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ContactRelationshipType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullContactRelationshipType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ContactRelationshipType), nil
+}
+
 type ProjectsStatus string
 
 const (
@@ -55,43 +100,179 @@ func (ns NullProjectsStatus) Value() (driver.Value, error) {
 	return string(ns.ProjectsStatus), nil
 }
 
+type Budget struct {
+	BudgetID  uuid.UUID        `json:"budgetId"`
+	UserID    uuid.UUID        `json:"userId"`
+	ProjectID pgtype.UUID      `json:"projectId"`
+	TagID     pgtype.UUID      `json:"tagId"`
+	Amount    pgtype.Numeric   `json:"amount"`
+	Currency  string           `json:"currency"`
+	Period    string           `json:"period"`
+	StartDate pgtype.Date      `json:"startDate"`
+	CreatedAt pgtype.Timestamp `json:"createdAt"`
+	UpdatedAt pgtype.Timestamp `json:"updatedAt"`
+	CreatedBy pgtype.UUID      `json:"createdBy"`
+	UpdatedBy pgtype.UUID      `json:"updatedBy"`
+}
+
 type Contact struct {
-	ContactID     uuid.UUID        `json:"contactId"`
-	UserID        uuid.UUID        `json:"userId"`
-	Name          string           `json:"name"`
-	Phone         pgtype.Text      `json:"phone"`
-	Email         pgtype.Text      `json:"email"`
-	AddressLine1  pgtype.Text      `json:"addressLine1"`
-	AddressLine2  pgtype.Text      `json:"addressLine2"`
-	Country       pgtype.Text      `json:"country"`
-	City          pgtype.Text      `json:"city"`
-	StateProvince pgtype.Text      `json:"stateProvince"`
-	ZipPostalCode pgtype.Text      `json:"zipPostalCode"`
-	Tags          []uuid.UUID      `json:"tags"`
-	CreatedAt     pgtype.Timestamp `json:"createdAt"`
-	UpdatedAt     pgtype.Timestamp `json:"updatedAt"`
+	ContactID      uuid.UUID        `json:"contactId"`
+	UserID         uuid.UUID        `json:"userId"`
+	Name           string           `json:"name"`
+	Phone          pgtype.Text      `json:"phone"`
+	Email          pgtype.Text      `json:"email"`
+	AddressLine1   pgtype.Text      `json:"addressLine1"`
+	AddressLine2   pgtype.Text      `json:"addressLine2"`
+	Country        pgtype.Text      `json:"country"`
+	City           pgtype.Text      `json:"city"`
+	StateProvince  pgtype.Text      `json:"stateProvince"`
+	ZipPostalCode  pgtype.Text      `json:"zipPostalCode"`
+	Tags           []uuid.UUID      `json:"tags"`
+	CreatedAt      pgtype.Timestamp `json:"createdAt"`
+	UpdatedAt      pgtype.Timestamp `json:"updatedAt"`
+	Company        pgtype.Text      `json:"company"`
+	DeletedAt      pgtype.Timestamp `json:"deletedAt"`
+	Notes          pgtype.Text      `json:"notes"`
+	NotesSearch    interface{}      `json:"notesSearch"`
+	CreatedBy      pgtype.UUID      `json:"createdBy"`
+	UpdatedBy      pgtype.UUID      `json:"updatedBy"`
+	EmailKey       pgtype.Text      `json:"emailKey"`
+	ExternalSource pgtype.Text      `json:"externalSource"`
+	ExternalID     pgtype.Text      `json:"externalId"`
+	Links          []byte           `json:"links"`
+}
+
+type ContactRelationship struct {
+	RelationshipID uuid.UUID               `json:"relationshipId"`
+	UserID         uuid.UUID               `json:"userId"`
+	FromContactID  uuid.UUID               `json:"fromContactId"`
+	ToContactID    uuid.UUID               `json:"toContactId"`
+	Type           ContactRelationshipType `json:"type"`
+	Note           pgtype.Text             `json:"note"`
+	CreatedAt      pgtype.Timestamp        `json:"createdAt"`
+}
+
+type ExportRun struct {
+	RunID        uuid.UUID        `json:"runId"`
+	ScheduleID   uuid.UUID        `json:"scheduleId"`
+	Status       string           `json:"status"`
+	Rows         int32            `json:"rows"`
+	Bytes        int64            `json:"bytes"`
+	Error        pgtype.Text      `json:"error"`
+	ScheduledFor pgtype.Timestamp `json:"scheduledFor"`
+	StartedAt    pgtype.Timestamp `json:"startedAt"`
+	FinishedAt   pgtype.Timestamp `json:"finishedAt"`
+}
+
+type ExportSchedule struct {
+	ScheduleID     uuid.UUID        `json:"scheduleId"`
+	UserID         uuid.UUID        `json:"userId"`
+	EntityType     string           `json:"entityType"`
+	Format         string           `json:"format"`
+	Cadence        string           `json:"cadence"`
+	DeliveryType   string           `json:"deliveryType"`
+	DeliveryTarget string           `json:"deliveryTarget"`
+	SigningSecret  string           `json:"signingSecret"`
+	NextRunAt      pgtype.Timestamp `json:"nextRunAt"`
+	ClaimedUntil   pgtype.Timestamp `json:"claimedUntil"`
+	CreatedAt      pgtype.Timestamp `json:"createdAt"`
+	UpdatedAt      pgtype.Timestamp `json:"updatedAt"`
+	DisabledAt     pgtype.Timestamp `json:"disabledAt"`
+}
+
+type Job struct {
+	JobID       uuid.UUID        `json:"jobId"`
+	UserID      uuid.UUID        `json:"userId"`
+	Type        string           `json:"type"`
+	Status      string           `json:"status"`
+	Total       int32            `json:"total"`
+	Processed   int32            `json:"processed"`
+	Succeeded   int32            `json:"succeeded"`
+	Failed      int32            `json:"failed"`
+	Payload     []byte           `json:"payload"`
+	Errors      []byte           `json:"errors"`
+	Error       pgtype.Text      `json:"error"`
+	CreatedAt   pgtype.Timestamp `json:"createdAt"`
+	UpdatedAt   pgtype.Timestamp `json:"updatedAt"`
+	CompletedAt pgtype.Timestamp `json:"completedAt"`
+	ResultKey   pgtype.Text      `json:"resultKey"`
+}
+
+type MergeAudit struct {
+	AuditID         uuid.UUID        `json:"auditId"`
+	UserID          uuid.UUID        `json:"userId"`
+	EntityType      string           `json:"entityType"`
+	KeptID          uuid.UUID        `json:"keptId"`
+	DroppedID       uuid.UUID        `json:"droppedId"`
+	KeptSnapshot    []byte           `json:"keptSnapshot"`
+	DroppedSnapshot []byte           `json:"droppedSnapshot"`
+	MergedBy        pgtype.UUID      `json:"mergedBy"`
+	MergedAt        pgtype.Timestamp `json:"mergedAt"`
+}
+
+type Milestone struct {
+	MilestoneID uuid.UUID        `json:"milestoneId"`
+	ProjectID   uuid.UUID        `json:"projectId"`
+	Name        string           `json:"name"`
+	DueDate     pgtype.Timestamp `json:"dueDate"`
+	CompletedAt pgtype.Timestamp `json:"completedAt"`
+	SortOrder   int32            `json:"sortOrder"`
+	CreatedAt   pgtype.Timestamp `json:"createdAt"`
+	UpdatedAt   pgtype.Timestamp `json:"updatedAt"`
+}
+
+type PendingEntry struct {
+	EntryID    uuid.UUID        `json:"entryId"`
+	UserID     uuid.UUID        `json:"userId"`
+	Sender     string           `json:"sender"`
+	Subject    string           `json:"subject"`
+	Body       string           `json:"body"`
+	Amount     pgtype.Numeric   `json:"amount"`
+	Currency   pgtype.Text      `json:"currency"`
+	Status     string           `json:"status"`
+	Raw        []byte           `json:"raw"`
+	ReceivedAt pgtype.Timestamp `json:"receivedAt"`
+	DeliveryID pgtype.Text      `json:"deliveryId"`
+}
+
+type PendingEntryAttachment struct {
+	AttachmentID uuid.UUID `json:"attachmentId"`
+	EntryID      uuid.UUID `json:"entryId"`
+	Filename     string    `json:"filename"`
+	ContentType  string    `json:"contentType"`
+	SizeBytes    int64     `json:"sizeBytes"`
+	Content      []byte    `json:"content"`
 }
 
 type Project struct {
-	ProjectID     uuid.UUID        `json:"projectId"`
-	UserID        uuid.UUID        `json:"userId"`
-	Name          string           `json:"name"`
-	Description   pgtype.Text      `json:"description"`
-	Status        ProjectsStatus   `json:"status"`
-	StartDate     pgtype.Timestamp `json:"startDate"`
-	EndDate       pgtype.Timestamp `json:"endDate"`
-	Budget        pgtype.Numeric   `json:"budget"`
-	ActualCost    pgtype.Numeric   `json:"actualCost"`
-	AddressLine1  pgtype.Text      `json:"addressLine1"`
-	AddressLine2  pgtype.Text      `json:"addressLine2"`
-	Country       pgtype.Text      `json:"country"`
-	City          pgtype.Text      `json:"city"`
-	StateProvince pgtype.Text      `json:"stateProvince"`
-	ZipPostalCode pgtype.Text      `json:"zipPostalCode"`
-	Website       pgtype.Text      `json:"website"`
-	Tags          []uuid.UUID      `json:"tags"`
-	CreatedAt     pgtype.Timestamp `json:"createdAt"`
-	UpdatedAt     pgtype.Timestamp `json:"updatedAt"`
+	ProjectID         uuid.UUID        `json:"projectId"`
+	UserID            uuid.UUID        `json:"userId"`
+	Name              string           `json:"name"`
+	Description       pgtype.Text      `json:"description"`
+	Status            ProjectsStatus   `json:"status"`
+	StartDate         pgtype.Date      `json:"startDate"`
+	EndDate           pgtype.Date      `json:"endDate"`
+	Budget            pgtype.Numeric   `json:"budget"`
+	ActualCost        pgtype.Numeric   `json:"actualCost"`
+	AddressLine1      pgtype.Text      `json:"addressLine1"`
+	AddressLine2      pgtype.Text      `json:"addressLine2"`
+	Country           pgtype.Text      `json:"country"`
+	City              pgtype.Text      `json:"city"`
+	StateProvince     pgtype.Text      `json:"stateProvince"`
+	ZipPostalCode     pgtype.Text      `json:"zipPostalCode"`
+	Website           pgtype.Text      `json:"website"`
+	Tags              []uuid.UUID      `json:"tags"`
+	CreatedAt         pgtype.Timestamp `json:"createdAt"`
+	UpdatedAt         pgtype.Timestamp `json:"updatedAt"`
+	DeletedAt         pgtype.Timestamp `json:"deletedAt"`
+	DescriptionSearch interface{}      `json:"descriptionSearch"`
+	CreatedBy         pgtype.UUID      `json:"createdBy"`
+	UpdatedBy         pgtype.UUID      `json:"updatedBy"`
+	PinnedAt          pgtype.Timestamp `json:"pinnedAt"`
+	ParentProjectID   pgtype.UUID      `json:"parentProjectId"`
+	ExternalSource    pgtype.Text      `json:"externalSource"`
+	ExternalID        pgtype.Text      `json:"externalId"`
+	IsDraft           bool             `json:"isDraft"`
 }
 
 type Session struct {
@@ -128,6 +309,13 @@ type User struct {
 	Provider         string           `json:"provider"`
 	RefreshTokenHash pgtype.Text      `json:"refreshTokenHash"`
 	LastLoginAt      pgtype.Timestamp `json:"lastLoginAt"`
+	AnonymizedAt     pgtype.Timestamp `json:"anonymizedAt"`
+	InboundToken     pgtype.Text      `json:"inboundToken"`
+	DefaultWalletID  pgtype.UUID      `json:"defaultWalletId"`
+	DefaultProjectID pgtype.UUID      `json:"defaultProjectId"`
+	ContactCount     int32            `json:"contactCount"`
+	ProjectCount     int32            `json:"projectCount"`
+	WalletCount      int32            `json:"walletCount"`
 }
 
 type UsersSetting struct {
@@ -143,13 +331,39 @@ type UsersSetting struct {
 }
 
 type Wallet struct {
-	WalletID  uuid.UUID        `json:"walletId"`
+	WalletID            uuid.UUID        `json:"walletId"`
+	UserID              uuid.UUID        `json:"userId"`
+	ProjectID           pgtype.UUID      `json:"projectId"`
+	Name                string           `json:"name"`
+	Balance             pgtype.Numeric   `json:"balance"`
+	Currency            string           `json:"currency"`
+	Tags                []uuid.UUID      `json:"tags"`
+	CreatedAt           pgtype.Timestamp `json:"createdAt"`
+	UpdatedAt           pgtype.Timestamp `json:"updatedAt"`
+	DeletedAt           pgtype.Timestamp `json:"deletedAt"`
+	LowBalanceThreshold pgtype.Numeric   `json:"lowBalanceThreshold"`
+	GroupID             pgtype.UUID      `json:"groupId"`
+	CreatedBy           pgtype.UUID      `json:"createdBy"`
+	UpdatedBy           pgtype.UUID      `json:"updatedBy"`
+	PinnedAt            pgtype.Timestamp `json:"pinnedAt"`
+}
+
+type WalletGroup struct {
+	GroupID   uuid.UUID        `json:"groupId"`
 	UserID    uuid.UUID        `json:"userId"`
-	ProjectID pgtype.UUID      `json:"projectId"`
 	Name      string           `json:"name"`
-	Balance   pgtype.Numeric   `json:"balance"`
-	Currency  string           `json:"currency"`
-	Tags      []uuid.UUID      `json:"tags"`
+	SortOrder int32            `json:"sortOrder"`
 	CreatedAt pgtype.Timestamp `json:"createdAt"`
 	UpdatedAt pgtype.Timestamp `json:"updatedAt"`
+	CreatedBy pgtype.UUID      `json:"createdBy"`
+	UpdatedBy pgtype.UUID      `json:"updatedBy"`
+}
+
+type WalletLedgerEntry struct {
+	EntryID     uuid.UUID        `json:"entryId"`
+	WalletID    uuid.UUID        `json:"walletId"`
+	Seq         int64            `json:"seq"`
+	Amount      pgtype.Numeric   `json:"amount"`
+	Description string           `json:"description"`
+	OccurredAt  pgtype.Timestamp `json:"occurredAt"`
 }
@@ -0,0 +1,46 @@
+package db
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// selectList matches the columns a query selects
+var selectList = regexp.MustCompile(`(?s)SELECT (.*?)\s+FROM`)
+
+// selectedColumns returns the columns query selects
+func selectedColumns(t *testing.T, query string) []string {
+	t.Helper()
+	match := selectList.FindStringSubmatch(query)
+	if !assert.NotNil(t, match, "no select list") {
+		return nil
+	}
+	var columns []string
+	for _, column := range strings.Split(match[1], ",") {
+		columns = append(columns, strings.TrimSpace(column))
+	}
+	return columns
+}
+
+func TestPickerQueriesSelectOnlyTheirColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		columns []string
+	}{
+		{"SearchWalletsPicker", searchWalletsPicker, []string{"wallet_id", "name", "balance", "currency"}},
+		{"SearchProjectsPicker", searchProjectsPicker, []string{"project_id", "name", "status", "end_date"}},
+		{"SearchContactsPicker", searchContactsPicker, []string{"contact_id", "name", "phone", "email"}},
+		{"SearchContactsByPhonePicker", searchContactsByPhonePicker, []string{"contact_id", "name", "phone", "email"}},
+		{"SearchContactsByCompanyPicker", searchContactsByCompanyPicker, []string{"contact_id", "name", "phone", "email"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.columns, selectedColumns(t, tt.query))
+		})
+	}
+}
@@ -12,6 +12,233 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const anonymizeProject = `-- name: AnonymizeProject :exec
+UPDATE projects
+SET
+    description = $1,
+    address_line1 = $2,
+    address_line2 = $3,
+    zip_postal_code = $4,
+    website = $5
+WHERE project_id = $6
+`
+
+type AnonymizeProjectParams struct {
+	Description   pgtype.Text `json:"description"`
+	AddressLine1  pgtype.Text `json:"addressLine1"`
+	AddressLine2  pgtype.Text `json:"addressLine2"`
+	ZipPostalCode pgtype.Text `json:"zipPostalCode"`
+	Website       pgtype.Text `json:"website"`
+	ProjectID     uuid.UUID   `json:"projectId"`
+}
+
+// timestamps and amounts are left alone so reporting on them still works
+func (q *Queries) AnonymizeProject(ctx context.Context, arg AnonymizeProjectParams) error {
+	_, err := q.db.Exec(ctx, anonymizeProject,
+		arg.Description,
+		arg.AddressLine1,
+		arg.AddressLine2,
+		arg.ZipPostalCode,
+		arg.Website,
+		arg.ProjectID,
+	)
+	return err
+}
+
+const cloneProject = `-- name: CloneProject :one
+WITH source AS (
+    SELECT project_id, user_id, name, description, status, start_date, end_date, budget, actual_cost, address_line1, address_line2, country, city, state_province, zip_postal_code, website, tags, created_at, updated_at, deleted_at, description_search, created_by, updated_by, pinned_at, parent_project_id, external_source, external_id, is_draft FROM projects p
+    WHERE p.project_id = $1 AND p.user_id = $2 AND p.deleted_at IS NULL
+), clone AS (
+    INSERT INTO projects (
+        user_id,
+        name,
+        description,
+        status,
+        start_date,
+        end_date,
+        budget,
+        address_line1,
+        address_line2,
+        country,
+        city,
+        state_province,
+        zip_postal_code,
+        website,
+        tags,
+        parent_project_id,
+        is_draft,
+        created_by,
+        updated_by
+    )
+    SELECT
+        s.user_id,
+        left(s.name, 93) || ' (copy)',
+        s.description,
+        s.status,
+        s.start_date,
+        s.end_date,
+        s.budget,
+        s.address_line1,
+        s.address_line2,
+        s.country,
+        s.city,
+        s.state_province,
+        s.zip_postal_code,
+        s.website,
+        s.tags,
+        s.parent_project_id,
+        s.is_draft,
+        $3::uuid,
+        $3::uuid
+    FROM source s
+    RETURNING project_id, user_id, name, description, status, start_date, end_date, budget, actual_cost, address_line1, address_line2, country, city, state_province, zip_postal_code, website, tags, created_at, updated_at, deleted_at, description_search, created_by, updated_by, pinned_at, parent_project_id, external_source, external_id, is_draft
+), cloned_wallets AS (
+    INSERT INTO wallets (
+        user_id,
+        project_id,
+        name,
+        balance,
+        currency,
+        tags,
+        low_balance_threshold,
+        group_id,
+        created_by,
+        updated_by
+    )
+    SELECT
+        w.user_id,
+        c.project_id,
+        left(w.name, 93) || ' (copy)',
+        0,
+        w.currency,
+        w.tags,
+        w.low_balance_threshold,
+        w.group_id,
+        $3::uuid,
+        $3::uuid
+    FROM wallets w
+    CROSS JOIN clone c
+    WHERE $4::bool
+        AND w.project_id = $1
+        AND w.user_id = $2
+        AND w.deleted_at IS NULL
+)
+SELECT project_id, user_id, name, description, status, start_date, end_date, budget, actual_cost, address_line1, address_line2, country, city, state_province, zip_postal_code, website, tags, created_at, updated_at, deleted_at, description_search, created_by, updated_by, pinned_at, parent_project_id, external_source, external_id, is_draft FROM clone
+`
+
+type CloneProjectParams struct {
+	ProjectID      uuid.UUID `json:"projectId"`
+	UserID         uuid.UUID `json:"userId"`
+	ActorID        uuid.UUID `json:"actorId"`
+	IncludeWallets bool      `json:"includeWallets"`
+}
+
+type CloneProjectRow struct {
+	ProjectID         uuid.UUID        `json:"projectId"`
+	UserID            uuid.UUID        `json:"userId"`
+	Name              string           `json:"name"`
+	Description       pgtype.Text      `json:"description"`
+	Status            ProjectsStatus   `json:"status"`
+	StartDate         pgtype.Date      `json:"startDate"`
+	EndDate           pgtype.Date      `json:"endDate"`
+	Budget            pgtype.Numeric   `json:"budget"`
+	ActualCost        pgtype.Numeric   `json:"actualCost"`
+	AddressLine1      pgtype.Text      `json:"addressLine1"`
+	AddressLine2      pgtype.Text      `json:"addressLine2"`
+	Country           pgtype.Text      `json:"country"`
+	City              pgtype.Text      `json:"city"`
+	StateProvince     pgtype.Text      `json:"stateProvince"`
+	ZipPostalCode     pgtype.Text      `json:"zipPostalCode"`
+	Website           pgtype.Text      `json:"website"`
+	Tags              []uuid.UUID      `json:"tags"`
+	CreatedAt         pgtype.Timestamp `json:"createdAt"`
+	UpdatedAt         pgtype.Timestamp `json:"updatedAt"`
+	DeletedAt         pgtype.Timestamp `json:"deletedAt"`
+	DescriptionSearch interface{}      `json:"descriptionSearch"`
+	CreatedBy         pgtype.UUID      `json:"createdBy"`
+	UpdatedBy         pgtype.UUID      `json:"updatedBy"`
+	PinnedAt          pgtype.Timestamp `json:"pinnedAt"`
+	ParentProjectID   pgtype.UUID      `json:"parentProjectId"`
+	ExternalSource    pgtype.Text      `json:"externalSource"`
+	ExternalID        pgtype.Text      `json:"externalId"`
+	IsDraft           bool             `json:"isDraft"`
+}
+
+// copies a live project as "<name> (copy)" and, with include_wallets, its live wallets
+// with zeroed balances. Wallet names are unique per user so the copies get the suffix
+// too, names are cut to leave room for it. Pins, milestones and external refs stay
+// with the source.
+func (q *Queries) CloneProject(ctx context.Context, arg CloneProjectParams) (CloneProjectRow, error) {
+	row := q.db.QueryRow(ctx, cloneProject,
+		arg.ProjectID,
+		arg.UserID,
+		arg.ActorID,
+		arg.IncludeWallets,
+	)
+	var i CloneProjectRow
+	err := row.Scan(
+		&i.ProjectID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.Status,
+		&i.StartDate,
+		&i.EndDate,
+		&i.Budget,
+		&i.ActualCost,
+		&i.AddressLine1,
+		&i.AddressLine2,
+		&i.Country,
+		&i.City,
+		&i.StateProvince,
+		&i.ZipPostalCode,
+		&i.Website,
+		&i.Tags,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.DescriptionSearch,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.PinnedAt,
+		&i.ParentProjectID,
+		&i.ExternalSource,
+		&i.ExternalID,
+		&i.IsDraft,
+	)
+	return i, err
+}
+
+const countChildProjects = `-- name: CountChildProjects :one
+SELECT COUNT(*) FROM projects
+WHERE parent_project_id = $1 AND user_id = $2 AND deleted_at IS NULL
+`
+
+type CountChildProjectsParams struct {
+	ParentProjectID pgtype.UUID `json:"parentProjectId"`
+	UserID          uuid.UUID   `json:"userId"`
+}
+
+func (q *Queries) CountChildProjects(ctx context.Context, arg CountChildProjectsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countChildProjects, arg.ParentProjectID, arg.UserID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countPinnedProjects = `-- name: CountPinnedProjects :one
+SELECT COUNT(*) FROM projects
+WHERE user_id = $1 AND pinned_at IS NOT NULL AND deleted_at IS NULL
+`
+
+func (q *Queries) CountPinnedProjects(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countPinnedProjects, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createProject = `-- name: CreateProject :one
 INSERT INTO projects (
     user_id,
@@ -29,30 +256,56 @@ INSERT INTO projects (
     state_province,
     zip_postal_code,
     website,
-    tags
+    tags,
+    parent_project_id,
+    is_draft,
+    created_by,
+    updated_by
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
+    $10,
+    $11,
+    $12,
+    $13,
+    $14,
+    $15,
+    owned_tags($1, $16::uuid[]),
+    $17,
+    $18,
+    $19::uuid,
+    $19::uuid
 )
-RETURNING project_id, user_id, name, description, status, start_date, end_date, budget, actual_cost, address_line1, address_line2, country, city, state_province, zip_postal_code, website, tags, created_at, updated_at
+RETURNING project_id, user_id, name, description, status, start_date, end_date, budget, actual_cost, address_line1, address_line2, country, city, state_province, zip_postal_code, website, tags, created_at, updated_at, deleted_at, description_search, created_by, updated_by, pinned_at, parent_project_id, external_source, external_id, is_draft
 `
 
 type CreateProjectParams struct {
-	UserID        uuid.UUID        `json:"userId"`
-	Name          string           `json:"name"`
-	Description   pgtype.Text      `json:"description"`
-	Status        ProjectsStatus   `json:"status"`
-	StartDate     pgtype.Timestamp `json:"startDate"`
-	EndDate       pgtype.Timestamp `json:"endDate"`
-	Budget        pgtype.Numeric   `json:"budget"`
-	ActualCost    pgtype.Numeric   `json:"actualCost"`
-	AddressLine1  pgtype.Text      `json:"addressLine1"`
-	AddressLine2  pgtype.Text      `json:"addressLine2"`
-	Country       pgtype.Text      `json:"country"`
-	City          pgtype.Text      `json:"city"`
-	StateProvince pgtype.Text      `json:"stateProvince"`
-	ZipPostalCode pgtype.Text      `json:"zipPostalCode"`
-	Website       pgtype.Text      `json:"website"`
-	Tags          []uuid.UUID      `json:"tags"`
+	UserID          uuid.UUID      `json:"userId"`
+	Name            string         `json:"name"`
+	Description     pgtype.Text    `json:"description"`
+	Status          ProjectsStatus `json:"status"`
+	StartDate       pgtype.Date    `json:"startDate"`
+	EndDate         pgtype.Date    `json:"endDate"`
+	Budget          pgtype.Numeric `json:"budget"`
+	ActualCost      pgtype.Numeric `json:"actualCost"`
+	AddressLine1    pgtype.Text    `json:"addressLine1"`
+	AddressLine2    pgtype.Text    `json:"addressLine2"`
+	Country         pgtype.Text    `json:"country"`
+	City            pgtype.Text    `json:"city"`
+	StateProvince   pgtype.Text    `json:"stateProvince"`
+	ZipPostalCode   pgtype.Text    `json:"zipPostalCode"`
+	Website         pgtype.Text    `json:"website"`
+	Tags            []uuid.UUID    `json:"tags"`
+	ParentProjectID pgtype.UUID    `json:"parentProjectId"`
+	IsDraft         bool           `json:"isDraft"`
+	ActorID         uuid.UUID      `json:"actorId"`
 }
 
 func (q *Queries) CreateProject(ctx context.Context, arg CreateProjectParams) (Project, error) {
@@ -73,6 +326,9 @@ func (q *Queries) CreateProject(ctx context.Context, arg CreateProjectParams) (P
 		arg.ZipPostalCode,
 		arg.Website,
 		arg.Tags,
+		arg.ParentProjectID,
+		arg.IsDraft,
+		arg.ActorID,
 	)
 	var i Project
 	err := row.Scan(
@@ -95,28 +351,112 @@ func (q *Queries) CreateProject(ctx context.Context, arg CreateProjectParams) (P
 		&i.Tags,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.DescriptionSearch,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.PinnedAt,
+		&i.ParentProjectID,
+		&i.ExternalSource,
+		&i.ExternalID,
+		&i.IsDraft,
 	)
 	return i, err
 }
 
-const deleteProject = `-- name: DeleteProject :exec
-DELETE FROM projects
-WHERE project_id = $1 AND user_id = $2
+const deleteProject = `-- name: DeleteProject :execrows
+UPDATE projects
+SET deleted_at = CURRENT_TIMESTAMP,
+    pinned_at = NULL
+WHERE project_id = $1 AND user_id = $2 AND deleted_at IS NULL
+  AND ($3::timestamp IS NULL OR updated_at = $3)
 `
 
 type DeleteProjectParams struct {
-	ProjectID uuid.UUID `json:"projectId"`
-	UserID    uuid.UUID `json:"userId"`
+	ProjectID uuid.UUID        `json:"projectId"`
+	UserID    uuid.UUID        `json:"userId"`
+	Version   pgtype.Timestamp `json:"version"`
 }
 
-func (q *Queries) DeleteProject(ctx context.Context, arg DeleteProjectParams) error {
-	_, err := q.db.Exec(ctx, deleteProject, arg.ProjectID, arg.UserID)
-	return err
+// a version set only trashes the project while it is still at that updated_at
+func (q *Queries) DeleteProject(ctx context.Context, arg DeleteProjectParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteProject, arg.ProjectID, arg.UserID, arg.Version)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteProjectDetachingChildren = `-- name: DeleteProjectDetachingChildren :execrows
+WITH target AS (
+    SELECT p.project_id FROM projects p
+    WHERE p.project_id = $1 AND p.user_id = $2 AND p.deleted_at IS NULL
+      AND ($3::timestamp IS NULL OR p.updated_at = $3)
+    FOR UPDATE
+), detached AS (
+    UPDATE projects
+    SET parent_project_id = NULL
+    WHERE projects.parent_project_id IN (SELECT target.project_id FROM target) AND projects.user_id = $2
+)
+UPDATE projects
+SET deleted_at = CURRENT_TIMESTAMP,
+    pinned_at = NULL
+WHERE projects.project_id IN (SELECT target.project_id FROM target)
+`
+
+type DeleteProjectDetachingChildrenParams struct {
+	ProjectID uuid.UUID        `json:"projectId"`
+	UserID    uuid.UUID        `json:"userId"`
+	Version   pgtype.Timestamp `json:"version"`
+}
+
+// moves the children, trashed ones included, to the top level and trashes the project, a version set only does
+// either while the project is still at that updated_at
+func (q *Queries) DeleteProjectDetachingChildren(ctx context.Context, arg DeleteProjectDetachingChildrenParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteProjectDetachingChildren, arg.ProjectID, arg.UserID, arg.Version)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteProjectTree = `-- name: DeleteProjectTree :execrows
+WITH RECURSIVE target AS (
+    SELECT p.project_id FROM projects p
+    WHERE p.project_id = $1 AND p.user_id = $2 AND p.deleted_at IS NULL
+      AND ($3::timestamp IS NULL OR p.updated_at = $3)
+    FOR UPDATE
+), tree AS (
+    SELECT target.project_id FROM target
+    UNION
+    SELECT child.project_id FROM projects child
+    JOIN tree ON child.parent_project_id = tree.project_id
+    WHERE child.deleted_at IS NULL
+)
+UPDATE projects
+SET deleted_at = CURRENT_TIMESTAMP,
+    pinned_at = NULL
+WHERE projects.project_id IN (SELECT tree.project_id FROM tree)
+`
+
+type DeleteProjectTreeParams struct {
+	ProjectID uuid.UUID        `json:"projectId"`
+	UserID    uuid.UUID        `json:"userId"`
+	Version   pgtype.Timestamp `json:"version"`
+}
+
+// trashes the project with all its live descendants, a version set only trashes them while the project is still at that updated_at
+func (q *Queries) DeleteProjectTree(ctx context.Context, arg DeleteProjectTreeParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteProjectTree, arg.ProjectID, arg.UserID, arg.Version)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const getProject = `-- name: GetProject :one
-SELECT project_id, user_id, name, description, status, start_date, end_date, budget, actual_cost, address_line1, address_line2, country, city, state_province, zip_postal_code, website, tags, created_at, updated_at FROM projects
-WHERE project_id = $1 AND user_id = $2 LIMIT 1
+SELECT project_id, user_id, name, description, status, start_date, end_date, budget, actual_cost, address_line1, address_line2, country, city, state_province, zip_postal_code, website, tags, created_at, updated_at, deleted_at, description_search, created_by, updated_by, pinned_at, parent_project_id, external_source, external_id, is_draft FROM projects
+WHERE project_id = $1 AND user_id = $2 AND deleted_at IS NULL LIMIT 1
 `
 
 type GetProjectParams struct {
@@ -147,18 +487,138 @@ func (q *Queries) GetProject(ctx context.Context, arg GetProjectParams) (Project
 		&i.Tags,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.DescriptionSearch,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.PinnedAt,
+		&i.ParentProjectID,
+		&i.ExternalSource,
+		&i.ExternalID,
+		&i.IsDraft,
 	)
 	return i, err
 }
 
-const listProjects = `-- name: ListProjects :many
-SELECT project_id, user_id, name, description, status, start_date, end_date, budget, actual_cost, address_line1, address_line2, country, city, state_province, zip_postal_code, website, tags, created_at, updated_at FROM projects
-WHERE user_id = $1
-ORDER BY created_at DESC
+const getProjectBudgetRollup = `-- name: GetProjectBudgetRollup :one
+WITH RECURSIVE tree AS (
+    SELECT p.project_id, p.budget FROM projects p
+    WHERE p.project_id = $1 AND p.user_id = $2 AND p.deleted_at IS NULL
+    UNION
+    SELECT child.project_id, child.budget FROM projects child
+    JOIN tree ON child.parent_project_id = tree.project_id
+    WHERE $3::bool AND child.deleted_at IS NULL AND NOT child.is_draft
+)
+SELECT COUNT(*) AS projects, COALESCE(SUM(budget), 0)::float8 AS budget FROM tree
 `
 
-func (q *Queries) ListProjects(ctx context.Context, userID uuid.UUID) ([]Project, error) {
-	rows, err := q.db.Query(ctx, listProjects, userID)
+type GetProjectBudgetRollupParams struct {
+	ProjectID uuid.UUID `json:"projectId"`
+	UserID    uuid.UUID `json:"userId"`
+	Rollup    bool      `json:"rollup"`
+}
+
+type GetProjectBudgetRollupRow struct {
+	Projects int64   `json:"projects"`
+	Budget   float64 `json:"budget"`
+}
+
+// the budget of the project and, with rollup, of its live descendants, drafts left out
+func (q *Queries) GetProjectBudgetRollup(ctx context.Context, arg GetProjectBudgetRollupParams) (GetProjectBudgetRollupRow, error) {
+	row := q.db.QueryRow(ctx, getProjectBudgetRollup, arg.ProjectID, arg.UserID, arg.Rollup)
+	var i GetProjectBudgetRollupRow
+	err := row.Scan(&i.Projects, &i.Budget)
+	return i, err
+}
+
+const getProjectByName = `-- name: GetProjectByName :one
+SELECT project_id, user_id, name, description, status, start_date, end_date, budget, actual_cost, address_line1, address_line2, country, city, state_province, zip_postal_code, website, tags, created_at, updated_at, deleted_at, description_search, created_by, updated_by, pinned_at, parent_project_id, external_source, external_id, is_draft FROM projects
+WHERE user_id = $1 AND lower(name) = lower($2) AND deleted_at IS NULL
+ORDER BY created_at, project_id
+LIMIT 1
+`
+
+type GetProjectByNameParams struct {
+	UserID uuid.UUID `json:"userId"`
+	Name   string    `json:"name"`
+}
+
+// names are compared case-insensitively, the oldest match wins
+func (q *Queries) GetProjectByName(ctx context.Context, arg GetProjectByNameParams) (Project, error) {
+	row := q.db.QueryRow(ctx, getProjectByName, arg.UserID, arg.Name)
+	var i Project
+	err := row.Scan(
+		&i.ProjectID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.Status,
+		&i.StartDate,
+		&i.EndDate,
+		&i.Budget,
+		&i.ActualCost,
+		&i.AddressLine1,
+		&i.AddressLine2,
+		&i.Country,
+		&i.City,
+		&i.StateProvince,
+		&i.ZipPostalCode,
+		&i.Website,
+		&i.Tags,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.DescriptionSearch,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.PinnedAt,
+		&i.ParentProjectID,
+		&i.ExternalSource,
+		&i.ExternalID,
+		&i.IsDraft,
+	)
+	return i, err
+}
+
+const getProjectSubtreeDepth = `-- name: GetProjectSubtreeDepth :one
+WITH RECURSIVE tree AS (
+    SELECT p.project_id, 1 AS depth FROM projects p
+    WHERE p.project_id = $1 AND p.user_id = $2 AND p.deleted_at IS NULL
+    UNION
+    SELECT child.project_id, tree.depth + 1 FROM projects child
+    JOIN tree ON child.parent_project_id = tree.project_id
+    WHERE child.deleted_at IS NULL AND tree.depth < 16
+)
+SELECT COALESCE(MAX(depth), 0)::int FROM tree
+`
+
+type GetProjectSubtreeDepthParams struct {
+	ProjectID uuid.UUID `json:"projectId"`
+	UserID    uuid.UUID `json:"userId"`
+}
+
+// levels of the project's live subtree, 1 for a project without children and 0 for
+// a missing one, guarded like ListProjectAncestors
+func (q *Queries) GetProjectSubtreeDepth(ctx context.Context, arg GetProjectSubtreeDepthParams) (int32, error) {
+	row := q.db.QueryRow(ctx, getProjectSubtreeDepth, arg.ProjectID, arg.UserID)
+	var column_1 int32
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const getProjectsByIDs = `-- name: GetProjectsByIDs :many
+SELECT project_id, user_id, name, description, status, start_date, end_date, budget, actual_cost, address_line1, address_line2, country, city, state_province, zip_postal_code, website, tags, created_at, updated_at, deleted_at, description_search, created_by, updated_by, pinned_at, parent_project_id, external_source, external_id, is_draft FROM projects
+WHERE project_id = ANY($1::uuid[]) AND user_id = $2 AND deleted_at IS NULL
+`
+
+type GetProjectsByIDsParams struct {
+	ProjectIds []uuid.UUID `json:"projectIds"`
+	UserID     uuid.UUID   `json:"userId"`
+}
+
+// in no particular order, the repository puts them in the order asked for
+func (q *Queries) GetProjectsByIDs(ctx context.Context, arg GetProjectsByIDsParams) ([]Project, error) {
+	rows, err := q.db.Query(ctx, getProjectsByIDs, arg.ProjectIds, arg.UserID)
 	if err != nil {
 		return nil, err
 	}
@@ -186,6 +646,15 @@ func (q *Queries) ListProjects(ctx context.Context, userID uuid.UUID) ([]Project
 			&i.Tags,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.DescriptionSearch,
+			&i.CreatedBy,
+			&i.UpdatedBy,
+			&i.PinnedAt,
+			&i.ParentProjectID,
+			&i.ExternalSource,
+			&i.ExternalID,
+			&i.IsDraft,
 		); err != nil {
 			return nil, err
 		}
@@ -197,26 +666,98 @@ func (q *Queries) ListProjects(ctx context.Context, userID uuid.UUID) ([]Project
 	return items, nil
 }
 
-const listProjectsPaginated = `-- name: ListProjectsPaginated :many
-SELECT project_id, user_id, name, description, status, start_date, end_date, budget, actual_cost, address_line1, address_line2, country, city, state_province, zip_postal_code, website, tags, created_at, updated_at
+const listChildProjects = `-- name: ListChildProjects :many
+SELECT project_id, user_id, name, description, status, start_date, end_date, budget, actual_cost, address_line1, address_line2, country, city, state_province, zip_postal_code, website, tags, created_at, updated_at, deleted_at, description_search, created_by, updated_by, pinned_at, parent_project_id, external_source, external_id, is_draft FROM projects
+WHERE parent_project_id = $1 AND user_id = $2 AND deleted_at IS NULL
+ORDER BY created_at, project_id
+`
+
+type ListChildProjectsParams struct {
+	ParentProjectID pgtype.UUID `json:"parentProjectId"`
+	UserID          uuid.UUID   `json:"userId"`
+}
+
+func (q *Queries) ListChildProjects(ctx context.Context, arg ListChildProjectsParams) ([]Project, error) {
+	rows, err := q.db.Query(ctx, listChildProjects, arg.ParentProjectID, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Project
+	for rows.Next() {
+		var i Project
+		if err := rows.Scan(
+			&i.ProjectID,
+			&i.UserID,
+			&i.Name,
+			&i.Description,
+			&i.Status,
+			&i.StartDate,
+			&i.EndDate,
+			&i.Budget,
+			&i.ActualCost,
+			&i.AddressLine1,
+			&i.AddressLine2,
+			&i.Country,
+			&i.City,
+			&i.StateProvince,
+			&i.ZipPostalCode,
+			&i.Website,
+			&i.Tags,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.DescriptionSearch,
+			&i.CreatedBy,
+			&i.UpdatedBy,
+			&i.PinnedAt,
+			&i.ParentProjectID,
+			&i.ExternalSource,
+			&i.ExternalID,
+			&i.IsDraft,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDeletedProjectsPaginated = `-- name: ListDeletedProjectsPaginated :many
+SELECT project_id, user_id, name, description, status, start_date, end_date, budget, actual_cost, address_line1, address_line2, country, city, state_province, zip_postal_code, website, tags, created_at, updated_at, deleted_at, description_search, created_by, updated_by, pinned_at, parent_project_id, external_source, external_id, is_draft
 FROM projects
-WHERE user_id = $1 
-  AND (created_at < $2 OR (created_at = $2 AND project_id < $3))
-ORDER BY created_at DESC, project_id DESC
-LIMIT $4
+WHERE user_id = $1
+  AND deleted_at IS NOT NULL
+  AND (
+      ($2::text = 'asc'
+          AND (deleted_at > $3 OR (deleted_at = $3 AND project_id > $4)))
+      OR ($2::text <> 'asc'
+          AND (deleted_at < $3 OR (deleted_at = $3 AND project_id < $4)))
+  )
+ORDER BY
+    CASE WHEN $2::text = 'asc' THEN deleted_at END ASC,
+    CASE WHEN $2::text = 'asc' THEN project_id END ASC,
+    CASE WHEN $2::text <> 'asc' THEN deleted_at END DESC,
+    CASE WHEN $2::text <> 'asc' THEN project_id END DESC
+LIMIT $5
 `
 
-type ListProjectsPaginatedParams struct {
+type ListDeletedProjectsPaginatedParams struct {
 	UserID    uuid.UUID        `json:"userId"`
-	CreatedAt pgtype.Timestamp `json:"createdAt"`
+	SortOrder string           `json:"sortOrder"`
+	DeletedAt pgtype.Timestamp `json:"deletedAt"`
 	ProjectID uuid.UUID        `json:"projectId"`
 	Limit     int32            `json:"limit"`
 }
 
-func (q *Queries) ListProjectsPaginated(ctx context.Context, arg ListProjectsPaginatedParams) ([]Project, error) {
-	rows, err := q.db.Query(ctx, listProjectsPaginated,
+func (q *Queries) ListDeletedProjectsPaginated(ctx context.Context, arg ListDeletedProjectsPaginatedParams) ([]Project, error) {
+	rows, err := q.db.Query(ctx, listDeletedProjectsPaginated,
 		arg.UserID,
-		arg.CreatedAt,
+		arg.SortOrder,
+		arg.DeletedAt,
 		arg.ProjectID,
 		arg.Limit,
 	)
@@ -247,6 +788,15 @@ func (q *Queries) ListProjectsPaginated(ctx context.Context, arg ListProjectsPag
 			&i.Tags,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.DescriptionSearch,
+			&i.CreatedBy,
+			&i.UpdatedBy,
+			&i.PinnedAt,
+			&i.ParentProjectID,
+			&i.ExternalSource,
+			&i.ExternalID,
+			&i.IsDraft,
 		); err != nil {
 			return nil, err
 		}
@@ -258,28 +808,86 @@ func (q *Queries) ListProjectsPaginated(ctx context.Context, arg ListProjectsPag
 	return items, nil
 }
 
-const searchProjects = `-- name: SearchProjects :many
-SELECT project_id, user_id, name, description, status, start_date, end_date, budget, actual_cost, address_line1, address_line2, country, city, state_province, zip_postal_code, website, tags, created_at, updated_at FROM projects
-WHERE user_id = $1 
-  AND ($2::text = '' OR (
-    name <-> $2 < 0.8 OR  
-    name ILIKE '%' || $2 || '%'  
-  ))
-ORDER BY 
-    CASE WHEN $2 = '' THEN created_at END DESC,  -- If sqlc.arg('name') is empty, sort by created_at
-    CASE WHEN $2 <> '' THEN name <-> $2 END,  -- If sqlc.arg('name') is provided, sort by trigram similarity
-    length(name) ASC  -- Shorter names are preferred as tiebreaker
-LIMIT $3
+const listPinnedProjectIDsPaginated = `-- name: ListPinnedProjectIDsPaginated :many
+SELECT project_id, pinned_at
+FROM projects
+WHERE user_id = $1
+  AND deleted_at IS NULL
+  AND pinned_at IS NOT NULL
+  AND ($2::bool OR NOT is_draft)
+  AND (pinned_at < $3 OR (pinned_at = $3 AND project_id < $4))
+ORDER BY pinned_at DESC, project_id DESC
+LIMIT $5
 `
 
-type SearchProjectsParams struct {
-	UserID uuid.UUID `json:"userId"`
-	Name   string    `json:"name"`
-	Limit  int32     `json:"limit"`
+type ListPinnedProjectIDsPaginatedParams struct {
+	UserID        uuid.UUID        `json:"userId"`
+	IncludeDrafts bool             `json:"includeDrafts"`
+	PinnedAt      pgtype.Timestamp `json:"pinnedAt"`
+	ProjectID     uuid.UUID        `json:"projectId"`
+	Limit         int32            `json:"limit"`
+}
+
+type ListPinnedProjectIDsPaginatedRow struct {
+	ProjectID uuid.UUID        `json:"projectId"`
+	PinnedAt  pgtype.Timestamp `json:"pinnedAt"`
+}
+
+// ListPinnedProjectsPaginated selecting only the keys the list is paged by
+func (q *Queries) ListPinnedProjectIDsPaginated(ctx context.Context, arg ListPinnedProjectIDsPaginatedParams) ([]ListPinnedProjectIDsPaginatedRow, error) {
+	rows, err := q.db.Query(ctx, listPinnedProjectIDsPaginated,
+		arg.UserID,
+		arg.IncludeDrafts,
+		arg.PinnedAt,
+		arg.ProjectID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPinnedProjectIDsPaginatedRow
+	for rows.Next() {
+		var i ListPinnedProjectIDsPaginatedRow
+		if err := rows.Scan(&i.ProjectID, &i.PinnedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPinnedProjectsPaginated = `-- name: ListPinnedProjectsPaginated :many
+SELECT project_id, user_id, name, description, status, start_date, end_date, budget, actual_cost, address_line1, address_line2, country, city, state_province, zip_postal_code, website, tags, created_at, updated_at, deleted_at, description_search, created_by, updated_by, pinned_at, parent_project_id, external_source, external_id, is_draft
+FROM projects
+WHERE user_id = $1
+  AND deleted_at IS NULL
+  AND pinned_at IS NOT NULL
+  AND ($2::bool OR NOT is_draft)
+  AND (pinned_at < $3 OR (pinned_at = $3 AND project_id < $4))
+ORDER BY pinned_at DESC, project_id DESC
+LIMIT $5
+`
+
+type ListPinnedProjectsPaginatedParams struct {
+	UserID        uuid.UUID        `json:"userId"`
+	IncludeDrafts bool             `json:"includeDrafts"`
+	PinnedAt      pgtype.Timestamp `json:"pinnedAt"`
+	ProjectID     uuid.UUID        `json:"projectId"`
+	Limit         int32            `json:"limit"`
 }
 
-func (q *Queries) SearchProjects(ctx context.Context, arg SearchProjectsParams) ([]Project, error) {
-	rows, err := q.db.Query(ctx, searchProjects, arg.UserID, arg.Name, arg.Limit)
+func (q *Queries) ListPinnedProjectsPaginated(ctx context.Context, arg ListPinnedProjectsPaginatedParams) ([]Project, error) {
+	rows, err := q.db.Query(ctx, listPinnedProjectsPaginated,
+		arg.UserID,
+		arg.IncludeDrafts,
+		arg.PinnedAt,
+		arg.ProjectID,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -307,6 +915,15 @@ func (q *Queries) SearchProjects(ctx context.Context, arg SearchProjectsParams)
 			&i.Tags,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.DescriptionSearch,
+			&i.CreatedBy,
+			&i.UpdatedBy,
+			&i.PinnedAt,
+			&i.ParentProjectID,
+			&i.ExternalSource,
+			&i.ExternalID,
+			&i.IsDraft,
 		); err != nil {
 			return nil, err
 		}
@@ -318,15 +935,787 @@ func (q *Queries) SearchProjects(ctx context.Context, arg SearchProjectsParams)
 	return items, nil
 }
 
-const updateProject = `-- name: UpdateProject :one
-UPDATE projects
-SET
-    name = COALESCE($1, name),
-    description = $2,
-    status = COALESCE($3, status),
-    start_date = $4,
-    end_date = $5,
-    budget = $6,
+const listProjectAncestors = `-- name: ListProjectAncestors :many
+WITH RECURSIVE chain AS (
+    SELECT p.project_id, p.parent_project_id, 1 AS depth FROM projects p
+    WHERE p.project_id = $1 AND p.user_id = $2 AND p.deleted_at IS NULL
+    UNION
+    SELECT parent.project_id, parent.parent_project_id, chain.depth + 1 FROM projects parent
+    JOIN chain ON parent.project_id = chain.parent_project_id
+    WHERE parent.user_id = $2 AND parent.deleted_at IS NULL AND chain.depth < 16
+)
+SELECT chain.project_id FROM chain
+ORDER BY chain.depth
+`
+
+type ListProjectAncestorsParams struct {
+	ProjectID uuid.UUID `json:"projectId"`
+	UserID    uuid.UUID `json:"userId"`
+}
+
+// the project followed by its ancestors, nearest first. The depth guard ends the walk
+// should racing updates ever write a cycle.
+func (q *Queries) ListProjectAncestors(ctx context.Context, arg ListProjectAncestorsParams) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, listProjectAncestors, arg.ProjectID, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var project_id uuid.UUID
+		if err := rows.Scan(&project_id); err != nil {
+			return nil, err
+		}
+		items = append(items, project_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectIDsPaginated = `-- name: ListProjectIDsPaginated :many
+SELECT project_id, created_at
+FROM projects
+WHERE user_id = $1
+  AND deleted_at IS NULL
+  AND pinned_at IS NULL
+  AND ($2::bool OR NOT is_draft)
+  AND (
+      ($3::text = 'asc'
+          AND (created_at > $4 OR (created_at = $4 AND project_id > $5)))
+      OR ($3::text <> 'asc'
+          AND (created_at < $4 OR (created_at = $4 AND project_id < $5)))
+  )
+ORDER BY
+    CASE WHEN $3::text = 'asc' THEN created_at END ASC,
+    CASE WHEN $3::text = 'asc' THEN project_id END ASC,
+    CASE WHEN $3::text <> 'asc' THEN created_at END DESC,
+    CASE WHEN $3::text <> 'asc' THEN project_id END DESC
+LIMIT $6
+`
+
+type ListProjectIDsPaginatedParams struct {
+	UserID        uuid.UUID        `json:"userId"`
+	IncludeDrafts bool             `json:"includeDrafts"`
+	SortOrder     string           `json:"sortOrder"`
+	CreatedAt     pgtype.Timestamp `json:"createdAt"`
+	ProjectID     uuid.UUID        `json:"projectId"`
+	Limit         int32            `json:"limit"`
+}
+
+type ListProjectIDsPaginatedRow struct {
+	ProjectID uuid.UUID        `json:"projectId"`
+	CreatedAt pgtype.Timestamp `json:"createdAt"`
+}
+
+// ListProjectsPaginated selecting only the keys the list is paged by
+func (q *Queries) ListProjectIDsPaginated(ctx context.Context, arg ListProjectIDsPaginatedParams) ([]ListProjectIDsPaginatedRow, error) {
+	rows, err := q.db.Query(ctx, listProjectIDsPaginated,
+		arg.UserID,
+		arg.IncludeDrafts,
+		arg.SortOrder,
+		arg.CreatedAt,
+		arg.ProjectID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListProjectIDsPaginatedRow
+	for rows.Next() {
+		var i ListProjectIDsPaginatedRow
+		if err := rows.Scan(&i.ProjectID, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectWalletBalances = `-- name: ListProjectWalletBalances :many
+WITH RECURSIVE tree AS (
+    SELECT p.project_id FROM projects p
+    WHERE p.project_id = $2 AND p.user_id = $1 AND p.deleted_at IS NULL
+    UNION
+    SELECT child.project_id FROM projects child
+    JOIN tree ON child.parent_project_id = tree.project_id
+    WHERE $3::bool AND child.deleted_at IS NULL AND NOT child.is_draft
+)
+SELECT w.currency, COUNT(*) AS wallets, COALESCE(SUM(w.balance), 0)::float8 AS balance
+FROM wallets w
+JOIN tree ON w.project_id = tree.project_id
+WHERE w.user_id = $1 AND w.deleted_at IS NULL
+GROUP BY w.currency
+ORDER BY w.currency
+`
+
+type ListProjectWalletBalancesParams struct {
+	UserID    uuid.UUID `json:"userId"`
+	ProjectID uuid.UUID `json:"projectId"`
+	Rollup    bool      `json:"rollup"`
+}
+
+type ListProjectWalletBalancesRow struct {
+	Currency string  `json:"currency"`
+	Wallets  int64   `json:"wallets"`
+	Balance  float64 `json:"balance"`
+}
+
+// the balances of the wallets in the project and, with rollup, in its live descendants
+// that aren't drafts, per currency
+func (q *Queries) ListProjectWalletBalances(ctx context.Context, arg ListProjectWalletBalancesParams) ([]ListProjectWalletBalancesRow, error) {
+	rows, err := q.db.Query(ctx, listProjectWalletBalances, arg.UserID, arg.ProjectID, arg.Rollup)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListProjectWalletBalancesRow
+	for rows.Next() {
+		var i ListProjectWalletBalancesRow
+		if err := rows.Scan(&i.Currency, &i.Wallets, &i.Balance); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjects = `-- name: ListProjects :many
+SELECT project_id, user_id, name, description, status, start_date, end_date, budget, actual_cost, address_line1, address_line2, country, city, state_province, zip_postal_code, website, tags, created_at, updated_at, deleted_at, description_search, created_by, updated_by, pinned_at, parent_project_id, external_source, external_id, is_draft FROM projects
+WHERE user_id = $1 AND deleted_at IS NULL
+ORDER BY created_at DESC
+`
+
+func (q *Queries) ListProjects(ctx context.Context, userID uuid.UUID) ([]Project, error) {
+	rows, err := q.db.Query(ctx, listProjects, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Project
+	for rows.Next() {
+		var i Project
+		if err := rows.Scan(
+			&i.ProjectID,
+			&i.UserID,
+			&i.Name,
+			&i.Description,
+			&i.Status,
+			&i.StartDate,
+			&i.EndDate,
+			&i.Budget,
+			&i.ActualCost,
+			&i.AddressLine1,
+			&i.AddressLine2,
+			&i.Country,
+			&i.City,
+			&i.StateProvince,
+			&i.ZipPostalCode,
+			&i.Website,
+			&i.Tags,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.DescriptionSearch,
+			&i.CreatedBy,
+			&i.UpdatedBy,
+			&i.PinnedAt,
+			&i.ParentProjectID,
+			&i.ExternalSource,
+			&i.ExternalID,
+			&i.IsDraft,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectsForAnonymization = `-- name: ListProjectsForAnonymization :many
+SELECT project_id, user_id, name, description, status, start_date, end_date, budget, actual_cost, address_line1, address_line2, country, city, state_province, zip_postal_code, website, tags, created_at, updated_at, deleted_at, description_search, created_by, updated_by, pinned_at, parent_project_id, external_source, external_id, is_draft FROM projects
+WHERE user_id = $1 AND project_id > $2
+ORDER BY project_id
+LIMIT $3
+`
+
+type ListProjectsForAnonymizationParams struct {
+	UserID    uuid.UUID `json:"userId"`
+	ProjectID uuid.UUID `json:"projectId"`
+	Limit     int32     `json:"limit"`
+}
+
+// trashed projects included, ordered by ID so batches resume after the last one
+func (q *Queries) ListProjectsForAnonymization(ctx context.Context, arg ListProjectsForAnonymizationParams) ([]Project, error) {
+	rows, err := q.db.Query(ctx, listProjectsForAnonymization, arg.UserID, arg.ProjectID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Project
+	for rows.Next() {
+		var i Project
+		if err := rows.Scan(
+			&i.ProjectID,
+			&i.UserID,
+			&i.Name,
+			&i.Description,
+			&i.Status,
+			&i.StartDate,
+			&i.EndDate,
+			&i.Budget,
+			&i.ActualCost,
+			&i.AddressLine1,
+			&i.AddressLine2,
+			&i.Country,
+			&i.City,
+			&i.StateProvince,
+			&i.ZipPostalCode,
+			&i.Website,
+			&i.Tags,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.DescriptionSearch,
+			&i.CreatedBy,
+			&i.UpdatedBy,
+			&i.PinnedAt,
+			&i.ParentProjectID,
+			&i.ExternalSource,
+			&i.ExternalID,
+			&i.IsDraft,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectsPaginated = `-- name: ListProjectsPaginated :many
+SELECT project_id, user_id, name, description, status, start_date, end_date, budget, actual_cost, address_line1, address_line2, country, city, state_province, zip_postal_code, website, tags, created_at, updated_at, deleted_at, description_search, created_by, updated_by, pinned_at, parent_project_id, external_source, external_id, is_draft
+FROM projects
+WHERE user_id = $1
+  AND deleted_at IS NULL
+  AND pinned_at IS NULL
+  AND ($2::bool OR NOT is_draft)
+  AND (
+      ($3::text = 'asc'
+          AND (created_at > $4 OR (created_at = $4 AND project_id > $5)))
+      OR ($3::text <> 'asc'
+          AND (created_at < $4 OR (created_at = $4 AND project_id < $5)))
+  )
+ORDER BY
+    CASE WHEN $3::text = 'asc' THEN created_at END ASC,
+    CASE WHEN $3::text = 'asc' THEN project_id END ASC,
+    CASE WHEN $3::text <> 'asc' THEN created_at END DESC,
+    CASE WHEN $3::text <> 'asc' THEN project_id END DESC
+LIMIT $6
+`
+
+type ListProjectsPaginatedParams struct {
+	UserID        uuid.UUID        `json:"userId"`
+	IncludeDrafts bool             `json:"includeDrafts"`
+	SortOrder     string           `json:"sortOrder"`
+	CreatedAt     pgtype.Timestamp `json:"createdAt"`
+	ProjectID     uuid.UUID        `json:"projectId"`
+	Limit         int32            `json:"limit"`
+}
+
+// the unpinned projects, the pinned ones are listed before them by ListPinnedProjectsPaginated.
+// Drafts are left out unless include_drafts is set.
+func (q *Queries) ListProjectsPaginated(ctx context.Context, arg ListProjectsPaginatedParams) ([]Project, error) {
+	rows, err := q.db.Query(ctx, listProjectsPaginated,
+		arg.UserID,
+		arg.IncludeDrafts,
+		arg.SortOrder,
+		arg.CreatedAt,
+		arg.ProjectID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Project
+	for rows.Next() {
+		var i Project
+		if err := rows.Scan(
+			&i.ProjectID,
+			&i.UserID,
+			&i.Name,
+			&i.Description,
+			&i.Status,
+			&i.StartDate,
+			&i.EndDate,
+			&i.Budget,
+			&i.ActualCost,
+			&i.AddressLine1,
+			&i.AddressLine2,
+			&i.Country,
+			&i.City,
+			&i.StateProvince,
+			&i.ZipPostalCode,
+			&i.Website,
+			&i.Tags,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.DescriptionSearch,
+			&i.CreatedBy,
+			&i.UpdatedBy,
+			&i.PinnedAt,
+			&i.ParentProjectID,
+			&i.ExternalSource,
+			&i.ExternalID,
+			&i.IsDraft,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const projectExists = `-- name: ProjectExists :one
+SELECT EXISTS (
+    SELECT 1 FROM projects
+    WHERE project_id = $1 AND user_id = $2 AND deleted_at IS NULL
+)
+`
+
+type ProjectExistsParams struct {
+	ProjectID uuid.UUID `json:"projectId"`
+	UserID    uuid.UUID `json:"userId"`
+}
+
+func (q *Queries) ProjectExists(ctx context.Context, arg ProjectExistsParams) (bool, error) {
+	row := q.db.QueryRow(ctx, projectExists, arg.ProjectID, arg.UserID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const publishProject = `-- name: PublishProject :one
+WITH published AS (
+    UPDATE projects
+    SET is_draft = FALSE,
+        updated_at = CURRENT_TIMESTAMP,
+        updated_by = $1::uuid
+    WHERE projects.project_id = $2
+      AND projects.user_id = $3
+      AND projects.deleted_at IS NULL
+      AND projects.is_draft
+      AND (NOT $4::bool OR projects.start_date IS NOT NULL)
+      AND (NOT $5::bool OR projects.budget IS NOT NULL)
+    RETURNING project_id, user_id, name, description, status, start_date, end_date, budget, actual_cost, address_line1, address_line2, country, city, state_province, zip_postal_code, website, tags, created_at, updated_at, deleted_at, description_search, created_by, updated_by, pinned_at, parent_project_id, external_source, external_id, is_draft
+)
+SELECT project_id, user_id, name, description, status, start_date, end_date, budget, actual_cost, address_line1, address_line2, country, city, state_province, zip_postal_code, website, tags, created_at, updated_at, deleted_at, description_search, created_by, updated_by, pinned_at, parent_project_id, external_source, external_id, is_draft FROM published
+UNION ALL
+SELECT live.project_id, live.user_id, live.name, live.description, live.status, live.start_date, live.end_date, live.budget, live.actual_cost, live.address_line1, live.address_line2, live.country, live.city, live.state_province, live.zip_postal_code, live.website, live.tags, live.created_at, live.updated_at, live.deleted_at, live.description_search, live.created_by, live.updated_by, live.pinned_at, live.parent_project_id, live.external_source, live.external_id, live.is_draft FROM projects live
+WHERE live.project_id = $2
+  AND live.user_id = $3
+  AND live.deleted_at IS NULL
+  AND NOT live.is_draft
+  AND (NOT $4::bool OR live.start_date IS NOT NULL)
+  AND (NOT $5::bool OR live.budget IS NOT NULL)
+`
+
+type PublishProjectParams struct {
+	ActorID          uuid.UUID `json:"actorId"`
+	ProjectID        uuid.UUID `json:"projectId"`
+	UserID           uuid.UUID `json:"userId"`
+	RequireStartDate bool      `json:"requireStartDate"`
+	RequireBudget    bool      `json:"requireBudget"`
+}
+
+type PublishProjectRow struct {
+	ProjectID         uuid.UUID        `json:"projectId"`
+	UserID            uuid.UUID        `json:"userId"`
+	Name              string           `json:"name"`
+	Description       pgtype.Text      `json:"description"`
+	Status            ProjectsStatus   `json:"status"`
+	StartDate         pgtype.Date      `json:"startDate"`
+	EndDate           pgtype.Date      `json:"endDate"`
+	Budget            pgtype.Numeric   `json:"budget"`
+	ActualCost        pgtype.Numeric   `json:"actualCost"`
+	AddressLine1      pgtype.Text      `json:"addressLine1"`
+	AddressLine2      pgtype.Text      `json:"addressLine2"`
+	Country           pgtype.Text      `json:"country"`
+	City              pgtype.Text      `json:"city"`
+	StateProvince     pgtype.Text      `json:"stateProvince"`
+	ZipPostalCode     pgtype.Text      `json:"zipPostalCode"`
+	Website           pgtype.Text      `json:"website"`
+	Tags              []uuid.UUID      `json:"tags"`
+	CreatedAt         pgtype.Timestamp `json:"createdAt"`
+	UpdatedAt         pgtype.Timestamp `json:"updatedAt"`
+	DeletedAt         pgtype.Timestamp `json:"deletedAt"`
+	DescriptionSearch interface{}      `json:"descriptionSearch"`
+	CreatedBy         pgtype.UUID      `json:"createdBy"`
+	UpdatedBy         pgtype.UUID      `json:"updatedBy"`
+	PinnedAt          pgtype.Timestamp `json:"pinnedAt"`
+	ParentProjectID   pgtype.UUID      `json:"parentProjectId"`
+	ExternalSource    pgtype.Text      `json:"externalSource"`
+	ExternalID        pgtype.Text      `json:"externalId"`
+	IsDraft           bool             `json:"isDraft"`
+}
+
+// turns a draft into a live project when it has the fields a live project needs,
+// publishing a live project changes nothing, it is read rather than updated so its
+// updated_at stays where it is
+func (q *Queries) PublishProject(ctx context.Context, arg PublishProjectParams) (PublishProjectRow, error) {
+	row := q.db.QueryRow(ctx, publishProject,
+		arg.ActorID,
+		arg.ProjectID,
+		arg.UserID,
+		arg.RequireStartDate,
+		arg.RequireBudget,
+	)
+	var i PublishProjectRow
+	err := row.Scan(
+		&i.ProjectID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.Status,
+		&i.StartDate,
+		&i.EndDate,
+		&i.Budget,
+		&i.ActualCost,
+		&i.AddressLine1,
+		&i.AddressLine2,
+		&i.Country,
+		&i.City,
+		&i.StateProvince,
+		&i.ZipPostalCode,
+		&i.Website,
+		&i.Tags,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.DescriptionSearch,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.PinnedAt,
+		&i.ParentProjectID,
+		&i.ExternalSource,
+		&i.ExternalID,
+		&i.IsDraft,
+	)
+	return i, err
+}
+
+const purgeDeletedProjects = `-- name: PurgeDeletedProjects :execrows
+DELETE FROM projects
+WHERE project_id IN (
+    SELECT p.project_id FROM projects p
+    WHERE p.deleted_at IS NOT NULL AND p.deleted_at < $1
+    ORDER BY p.deleted_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+`
+
+type PurgeDeletedProjectsParams struct {
+	DeletedBefore pgtype.Timestamp `json:"deletedBefore"`
+	BatchSize     int32            `json:"batchSize"`
+}
+
+// at most batch_size rows per call, oldest first, skipping rows locked by a restore
+func (q *Queries) PurgeDeletedProjects(ctx context.Context, arg PurgeDeletedProjectsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeDeletedProjects, arg.DeletedBefore, arg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const restoreProject = `-- name: RestoreProject :one
+UPDATE projects
+SET deleted_at = NULL,
+    parent_project_id = (
+        SELECT parent.project_id FROM projects parent
+        WHERE parent.project_id = projects.parent_project_id AND parent.deleted_at IS NULL
+    ),
+    updated_at = CURRENT_TIMESTAMP
+WHERE projects.project_id = $1 AND projects.user_id = $2 AND projects.deleted_at IS NOT NULL
+RETURNING project_id, user_id, name, description, status, start_date, end_date, budget, actual_cost, address_line1, address_line2, country, city, state_province, zip_postal_code, website, tags, created_at, updated_at, deleted_at, description_search, created_by, updated_by, pinned_at, parent_project_id, external_source, external_id, is_draft
+`
+
+type RestoreProjectParams struct {
+	ProjectID uuid.UUID `json:"projectId"`
+	UserID    uuid.UUID `json:"userId"`
+}
+
+// a project whose parent is still in the trash comes back at the top level
+func (q *Queries) RestoreProject(ctx context.Context, arg RestoreProjectParams) (Project, error) {
+	row := q.db.QueryRow(ctx, restoreProject, arg.ProjectID, arg.UserID)
+	var i Project
+	err := row.Scan(
+		&i.ProjectID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.Status,
+		&i.StartDate,
+		&i.EndDate,
+		&i.Budget,
+		&i.ActualCost,
+		&i.AddressLine1,
+		&i.AddressLine2,
+		&i.Country,
+		&i.City,
+		&i.StateProvince,
+		&i.ZipPostalCode,
+		&i.Website,
+		&i.Tags,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.DescriptionSearch,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.PinnedAt,
+		&i.ParentProjectID,
+		&i.ExternalSource,
+		&i.ExternalID,
+		&i.IsDraft,
+	)
+	return i, err
+}
+
+const searchProjects = `-- name: SearchProjects :many
+SELECT projects.project_id, projects.user_id, projects.name, projects.description, projects.status, projects.start_date, projects.end_date, projects.budget, projects.actual_cost, projects.address_line1, projects.address_line2, projects.country, projects.city, projects.state_province, projects.zip_postal_code, projects.website, projects.tags, projects.created_at, projects.updated_at, projects.deleted_at, projects.description_search, projects.created_by, projects.updated_by, projects.pinned_at, projects.parent_project_id, projects.external_source, projects.external_id, projects.is_draft,
+    (1 - (f_unaccent(name) <-> f_unaccent($1)))::real AS score
+FROM projects
+WHERE user_id = $2 
+  AND deleted_at IS NULL
+  AND ($3::bool OR NOT is_draft)
+  AND ($1::text = '' OR (
+    f_unaccent(name) <-> f_unaccent($1) < 0.8 OR
+    f_unaccent(name) ILIKE '%' || f_unaccent($1) || '%'  -- accents ignored
+  ))
+ORDER BY 
+    CASE WHEN $1 = '' THEN created_at END DESC,  -- If sqlc.arg('name') is empty, sort by created_at
+    CASE WHEN $1 <> '' THEN f_unaccent(name) <-> f_unaccent($1) END,  -- If sqlc.arg('name') is provided, sort by trigram similarity
+    length(name) ASC,  -- Shorter names are preferred as tiebreaker
+    created_at DESC, project_id DESC  -- then the newest, so ties always resolve the same way
+LIMIT $5
+OFFSET $4
+`
+
+type SearchProjectsParams struct {
+	Name          string    `json:"name"`
+	UserID        uuid.UUID `json:"userId"`
+	IncludeDrafts bool      `json:"includeDrafts"`
+	Offset        int32     `json:"offset"`
+	Limit         int32     `json:"limit"`
+}
+
+type SearchProjectsRow struct {
+	Project Project `json:"project"`
+	Score   float32 `json:"score"`
+}
+
+// score is how similar the name is to the query from 0 to 1, meaningless without a query
+func (q *Queries) SearchProjects(ctx context.Context, arg SearchProjectsParams) ([]SearchProjectsRow, error) {
+	rows, err := q.db.Query(ctx, searchProjects,
+		arg.Name,
+		arg.UserID,
+		arg.IncludeDrafts,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchProjectsRow
+	for rows.Next() {
+		var i SearchProjectsRow
+		if err := rows.Scan(
+			&i.Project.ProjectID,
+			&i.Project.UserID,
+			&i.Project.Name,
+			&i.Project.Description,
+			&i.Project.Status,
+			&i.Project.StartDate,
+			&i.Project.EndDate,
+			&i.Project.Budget,
+			&i.Project.ActualCost,
+			&i.Project.AddressLine1,
+			&i.Project.AddressLine2,
+			&i.Project.Country,
+			&i.Project.City,
+			&i.Project.StateProvince,
+			&i.Project.ZipPostalCode,
+			&i.Project.Website,
+			&i.Project.Tags,
+			&i.Project.CreatedAt,
+			&i.Project.UpdatedAt,
+			&i.Project.DeletedAt,
+			&i.Project.DescriptionSearch,
+			&i.Project.CreatedBy,
+			&i.Project.UpdatedBy,
+			&i.Project.PinnedAt,
+			&i.Project.ParentProjectID,
+			&i.Project.ExternalSource,
+			&i.Project.ExternalID,
+			&i.Project.IsDraft,
+			&i.Score,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchProjectsPicker = `-- name: SearchProjectsPicker :many
+SELECT project_id, name, status, end_date FROM projects
+WHERE user_id = $1 
+  AND deleted_at IS NULL
+  AND ($2::bool OR NOT is_draft)
+  AND ($3::text = '' OR (
+    f_unaccent(name) <-> f_unaccent($3) < 0.8 OR
+    f_unaccent(name) ILIKE '%' || f_unaccent($3) || '%'  -- accents ignored
+  ))
+ORDER BY 
+    CASE WHEN $3 = '' THEN created_at END DESC,  -- If sqlc.arg('name') is empty, sort by created_at
+    CASE WHEN $3 <> '' THEN f_unaccent(name) <-> f_unaccent($3) END,  -- If sqlc.arg('name') is provided, sort by trigram similarity
+    length(name) ASC,  -- Shorter names are preferred as tiebreaker
+    created_at DESC, project_id DESC  -- then the newest, so ties always resolve the same way
+LIMIT $5
+OFFSET $4
+`
+
+type SearchProjectsPickerParams struct {
+	UserID        uuid.UUID `json:"userId"`
+	IncludeDrafts bool      `json:"includeDrafts"`
+	Name          string    `json:"name"`
+	Offset        int32     `json:"offset"`
+	Limit         int32     `json:"limit"`
+}
+
+type SearchProjectsPickerRow struct {
+	ProjectID uuid.UUID      `json:"projectId"`
+	Name      string         `json:"name"`
+	Status    ProjectsStatus `json:"status"`
+	EndDate   pgtype.Date    `json:"endDate"`
+}
+
+// SearchProjects selecting only the columns of the picker view
+func (q *Queries) SearchProjectsPicker(ctx context.Context, arg SearchProjectsPickerParams) ([]SearchProjectsPickerRow, error) {
+	rows, err := q.db.Query(ctx, searchProjectsPicker,
+		arg.UserID,
+		arg.IncludeDrafts,
+		arg.Name,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchProjectsPickerRow
+	for rows.Next() {
+		var i SearchProjectsPickerRow
+		if err := rows.Scan(
+			&i.ProjectID,
+			&i.Name,
+			&i.Status,
+			&i.EndDate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setProjectPinned = `-- name: SetProjectPinned :one
+UPDATE projects
+SET pinned_at = CASE WHEN $1::bool THEN COALESCE(pinned_at, CURRENT_TIMESTAMP) END
+WHERE project_id = $2 AND user_id = $3 AND deleted_at IS NULL
+RETURNING project_id, user_id, name, description, status, start_date, end_date, budget, actual_cost, address_line1, address_line2, country, city, state_province, zip_postal_code, website, tags, created_at, updated_at, deleted_at, description_search, created_by, updated_by, pinned_at, parent_project_id, external_source, external_id, is_draft
+`
+
+type SetProjectPinnedParams struct {
+	Pinned    bool      `json:"pinned"`
+	ProjectID uuid.UUID `json:"projectId"`
+	UserID    uuid.UUID `json:"userId"`
+}
+
+// pinning a pinned project keeps its place, pins aren't edits so updated_at is left alone
+func (q *Queries) SetProjectPinned(ctx context.Context, arg SetProjectPinnedParams) (Project, error) {
+	row := q.db.QueryRow(ctx, setProjectPinned, arg.Pinned, arg.ProjectID, arg.UserID)
+	var i Project
+	err := row.Scan(
+		&i.ProjectID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.Status,
+		&i.StartDate,
+		&i.EndDate,
+		&i.Budget,
+		&i.ActualCost,
+		&i.AddressLine1,
+		&i.AddressLine2,
+		&i.Country,
+		&i.City,
+		&i.StateProvince,
+		&i.ZipPostalCode,
+		&i.Website,
+		&i.Tags,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.DescriptionSearch,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.PinnedAt,
+		&i.ParentProjectID,
+		&i.ExternalSource,
+		&i.ExternalID,
+		&i.IsDraft,
+	)
+	return i, err
+}
+
+const updateProject = `-- name: UpdateProject :one
+UPDATE projects
+SET
+    name = COALESCE($1, name),
+    description = $2,
+    status = COALESCE($3, status),
+    start_date = $4,
+    end_date = $5,
+    budget = $6,
     address_line1 = $7,
     address_line2 = $8,
     country = $9,
@@ -334,31 +1723,36 @@ SET
     state_province = $11,
     zip_postal_code = $12,
     website = $13,
-    tags = $14,
-    updated_at = CURRENT_TIMESTAMP
+    tags = owned_tags($14, $15::uuid[]),
+    parent_project_id = $16,
+    updated_at = CURRENT_TIMESTAMP,
+    updated_by = $17::uuid
 WHERE 
-    project_id = $15
-    AND user_id = $16
-RETURNING project_id, user_id, name, description, status, start_date, end_date, budget, actual_cost, address_line1, address_line2, country, city, state_province, zip_postal_code, website, tags, created_at, updated_at
+    project_id = $18
+    AND user_id = $14
+    AND deleted_at IS NULL
+RETURNING project_id, user_id, name, description, status, start_date, end_date, budget, actual_cost, address_line1, address_line2, country, city, state_province, zip_postal_code, website, tags, created_at, updated_at, deleted_at, description_search, created_by, updated_by, pinned_at, parent_project_id, external_source, external_id, is_draft
 `
 
 type UpdateProjectParams struct {
-	Name          pgtype.Text        `json:"name"`
-	Description   pgtype.Text        `json:"description"`
-	Status        NullProjectsStatus `json:"status"`
-	StartDate     pgtype.Timestamp   `json:"startDate"`
-	EndDate       pgtype.Timestamp   `json:"endDate"`
-	Budget        pgtype.Numeric     `json:"budget"`
-	AddressLine1  pgtype.Text        `json:"addressLine1"`
-	AddressLine2  pgtype.Text        `json:"addressLine2"`
-	Country       pgtype.Text        `json:"country"`
-	City          pgtype.Text        `json:"city"`
-	StateProvince pgtype.Text        `json:"stateProvince"`
-	ZipPostalCode pgtype.Text        `json:"zipPostalCode"`
-	Website       pgtype.Text        `json:"website"`
-	Tags          []uuid.UUID        `json:"tags"`
-	ProjectID     uuid.UUID          `json:"projectId"`
-	UserID        uuid.UUID          `json:"userId"`
+	Name            pgtype.Text        `json:"name"`
+	Description     pgtype.Text        `json:"description"`
+	Status          NullProjectsStatus `json:"status"`
+	StartDate       pgtype.Date        `json:"startDate"`
+	EndDate         pgtype.Date        `json:"endDate"`
+	Budget          pgtype.Numeric     `json:"budget"`
+	AddressLine1    pgtype.Text        `json:"addressLine1"`
+	AddressLine2    pgtype.Text        `json:"addressLine2"`
+	Country         pgtype.Text        `json:"country"`
+	City            pgtype.Text        `json:"city"`
+	StateProvince   pgtype.Text        `json:"stateProvince"`
+	ZipPostalCode   pgtype.Text        `json:"zipPostalCode"`
+	Website         pgtype.Text        `json:"website"`
+	UserID          uuid.UUID          `json:"userId"`
+	Tags            []uuid.UUID        `json:"tags"`
+	ParentProjectID pgtype.UUID        `json:"parentProjectId"`
+	ActorID         uuid.UUID          `json:"actorId"`
+	ProjectID       uuid.UUID          `json:"projectId"`
 }
 
 func (q *Queries) UpdateProject(ctx context.Context, arg UpdateProjectParams) (Project, error) {
@@ -376,9 +1770,11 @@ func (q *Queries) UpdateProject(ctx context.Context, arg UpdateProjectParams) (P
 		arg.StateProvince,
 		arg.ZipPostalCode,
 		arg.Website,
+		arg.UserID,
 		arg.Tags,
+		arg.ParentProjectID,
+		arg.ActorID,
 		arg.ProjectID,
-		arg.UserID,
 	)
 	var i Project
 	err := row.Scan(
@@ -401,6 +1797,15 @@ func (q *Queries) UpdateProject(ctx context.Context, arg UpdateProjectParams) (P
 		&i.Tags,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.DescriptionSearch,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.PinnedAt,
+		&i.ParentProjectID,
+		&i.ExternalSource,
+		&i.ExternalID,
+		&i.IsDraft,
 	)
 	return i, err
 }
@@ -8,50 +8,326 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
+	// lets the rest of the transaction write PII columns of anonymized users
+	AllowAnonymizedWrites(ctx context.Context) error
+	// timestamps are left alone so reporting on them still works
+	AnonymizeContact(ctx context.Context, arg AnonymizeContactParams) error
+	// timestamps and amounts are left alone so reporting on them still works
+	AnonymizeProject(ctx context.Context, arg AnonymizeProjectParams) error
+	AnonymizeUser(ctx context.Context, arg AnonymizeUserParams) (pgtype.Timestamp, error)
+	ApplyContactMerge(ctx context.Context, arg ApplyContactMergeParams) (Contact, error)
+	// adds the dropped wallet's balance, a wallet without a balance keeps none if the other has none either
+	ApplyWalletMerge(ctx context.Context, arg ApplyWalletMergeParams) (Wallet, error)
+	// wallets already in the project are left alone so only the moved ones are counted
+	AttachWalletsToProject(ctx context.Context, arg AttachWalletsToProjectParams) (int64, error)
+	// the generated columns are left out of the snapshots
+	AuditContactMerge(ctx context.Context, arg AuditContactMergeParams) error
+	AuditWalletMerge(ctx context.Context, arg AuditWalletMergeParams) error
+	// whether the project, or the tag, a budget is scoped to belongs to the user
+	BudgetScopeExists(ctx context.Context, arg BudgetScopeExistsParams) (bool, error)
+	// claims the earliest schedule due at now until claimed_until, skipping those another
+	// scheduler holds
+	ClaimDueExportSchedule(ctx context.Context, arg ClaimDueExportScheduleParams) (ExportSchedule, error)
+	// the notes are free text about the contacts
+	ClearRelationshipNotes(ctx context.Context, userID uuid.UUID) (int64, error)
+	ClaimNextJob(ctx context.Context) (Job, error)
+	// copies a live project as "<name> (copy)" and, with include_wallets, its live wallets
+	// with zeroed balances. Wallet names are unique per user so the copies get the suffix
+	// too, names are cut to leave room for it. Pins, milestones and external refs stay
+	// with the source.
+	CloneProject(ctx context.Context, arg CloneProjectParams) (CloneProjectRow, error)
+	CompleteJob(ctx context.Context, jobID uuid.UUID) error
+	// completes a job that ran in one go, recording the rows it covered and the key of the
+	// file it produced
+	CompleteJobWithResult(ctx context.Context, arg CompleteJobWithResultParams) error
+	// the rows a full account backup archives, the same ones its sections stream
+	CountBackupRows(ctx context.Context, userID uuid.UUID) (CountBackupRowsRow, error)
+	CountChildProjects(ctx context.Context, arg CountChildProjectsParams) (int64, error)
+	CountContacts(ctx context.Context, userID uuid.UUID) (int64, error)
+	CountMilestones(ctx context.Context, projectID uuid.UUID) (int64, error)
+	CountOwnedWallets(ctx context.Context, arg CountOwnedWalletsParams) (int64, error)
+	CountPinnedProjects(ctx context.Context, userID uuid.UUID) (int64, error)
+	CountPinnedWallets(ctx context.Context, userID uuid.UUID) (int64, error)
+	// the milestones of the project and, with rollup, of its live descendants
+	CountProjectTreeMilestones(ctx context.Context, arg CountProjectTreeMilestonesParams) (int64, error)
+	CountWalletLedgerEntries(ctx context.Context, arg CountWalletLedgerEntriesParams) (int64, error)
+	CreateBudget(ctx context.Context, arg CreateBudgetParams) (Budget, error)
 	CreateContact(ctx context.Context, arg CreateContactParams) (Contact, error)
+	// both contacts have to belong to the user and be out of the trash, no row is inserted otherwise
+	CreateContactRelationship(ctx context.Context, arg CreateContactRelationshipParams) (CreateContactRelationshipRow, error)
+	CreateExportRun(ctx context.Context, arg CreateExportRunParams) (ExportRun, error)
+	CreateExportSchedule(ctx context.Context, arg CreateExportScheduleParams) (ExportSchedule, error)
+	CreateJob(ctx context.Context, arg CreateJobParams) (Job, error)
+	CreateMilestone(ctx context.Context, arg CreateMilestoneParams) (Milestone, error)
+	CreatePendingEntry(ctx context.Context, arg CreatePendingEntryParams) (PendingEntry, error)
+	CreatePendingEntryAttachment(ctx context.Context, arg CreatePendingEntryAttachmentParams) (CreatePendingEntryAttachmentRow, error)
 	CreateProject(ctx context.Context, arg CreateProjectParams) (Project, error)
 	CreateTag(ctx context.Context, arg CreateTagParams) (Tag, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
 	CreateUserSettings(ctx context.Context, arg CreateUserSettingsParams) (UsersSetting, error)
 	CreateWallet(ctx context.Context, arg CreateWalletParams) (Wallet, error)
-	DeleteContact(ctx context.Context, arg DeleteContactParams) error
+	// without a sort order the group goes after the user's existing ones
+	CreateWalletGroup(ctx context.Context, arg CreateWalletGroupParams) (WalletGroup, error)
+	DeleteBudget(ctx context.Context, arg DeleteBudgetParams) (int64, error)
+	// a version set only trashes the contact while it is still at that updated_at
+	DeleteContact(ctx context.Context, arg DeleteContactParams) (int64, error)
+	// the relationship can be deleted through either of its contacts
+	DeleteContactRelationship(ctx context.Context, arg DeleteContactRelationshipParams) (int64, error)
 	DeleteExpiredSessions(ctx context.Context) error
-	DeleteProject(ctx context.Context, arg DeleteProjectParams) error
+	DeleteExportSchedule(ctx context.Context, arg DeleteExportScheduleParams) (int64, error)
+	DeleteJobs(ctx context.Context, jobIds []uuid.UUID) (int64, error)
+	// the snapshots of merged contacts hold their PII
+	DeleteMergeAudit(ctx context.Context, userID uuid.UUID) (int64, error)
+	DeleteMilestone(ctx context.Context, arg DeleteMilestoneParams) (int64, error)
+	DeletePendingEntries(ctx context.Context, userID uuid.UUID) (int64, error)
+	// a version set only trashes the project while it is still at that updated_at
+	DeleteProject(ctx context.Context, arg DeleteProjectParams) (int64, error)
+	// moves the children, trashed ones included, to the top level and trashes the project, a version set only does
+	// either while the project is still at that updated_at
+	DeleteProjectDetachingChildren(ctx context.Context, arg DeleteProjectDetachingChildrenParams) (int64, error)
+	// trashes the project with all its live descendants, a version set only trashes them while the project is still at that updated_at
+	DeleteProjectTree(ctx context.Context, arg DeleteProjectTreeParams) (int64, error)
 	DeleteSession(ctx context.Context, key string) error
 	DeleteTag(ctx context.Context, arg DeleteTagParams) error
 	DeleteUser(ctx context.Context, userID uuid.UUID) error
 	DeleteUserSettings(ctx context.Context, userID uuid.UUID) error
 	DeleteUserTags(ctx context.Context, userID uuid.UUID) error
-	DeleteWallet(ctx context.Context, arg DeleteWalletParams) error
+	// a version set only trashes the wallet while it is still at that updated_at
+	DeleteWallet(ctx context.Context, arg DeleteWalletParams) (int64, error)
+	DeleteWalletGroup(ctx context.Context, arg DeleteWalletGroupParams) (int64, error)
+	// switches the user's schedules off for good and clears their targets, which can be
+	// email addresses
+	DisableExportSchedules(ctx context.Context, userID uuid.UUID) (int64, error)
+	// the relationships between the two contacts, and those of the dropped contact the kept
+	// one already has, which moving them over would duplicate
+	DropMergedRelationships(ctx context.Context, arg DropMergedRelationshipsParams) (int64, error)
+	FailJob(ctx context.Context, arg FailJobParams) error
+	// another budget of the user with the same scope and period, periods recur without end
+	// so it overlaps whatever the start dates
+	FindOverlappingBudget(ctx context.Context, arg FindOverlappingBudgetParams) (uuid.UUID, error)
+	GetBudget(ctx context.Context, arg GetBudgetParams) (Budget, error)
+	// the money that left the budget's wallets between window_start and window_end, the
+	// end excluded. Its wallets are the user's wallets in the budget's currency that belong
+	// to its project or carry its tag, trashed wallets don't count.
+	GetBudgetSpend(ctx context.Context, arg GetBudgetSpendParams) (GetBudgetSpendRow, error)
 	GetContact(ctx context.Context, arg GetContactParams) (Contact, error)
+	// in no particular order, the repository puts them in the order asked for
+	GetContactsByIDs(ctx context.Context, arg GetContactsByIDsParams) ([]Contact, error)
+	GetExportSchedule(ctx context.Context, arg GetExportScheduleParams) (ExportSchedule, error)
+	// a wallet without a balance counts as empty
+	GetGroupBalances(ctx context.Context, arg GetGroupBalancesParams) ([]GetGroupBalancesRow, error)
+	GetJob(ctx context.Context, arg GetJobParams) (Job, error)
+	GetMilestone(ctx context.Context, arg GetMilestoneParams) (Milestone, error)
+	GetMilestoneProgress(ctx context.Context, projectIds []uuid.UUID) ([]GetMilestoneProgressRow, error)
 	GetProject(ctx context.Context, arg GetProjectParams) (Project, error)
+	// the budget of the project and, with rollup, of its live descendants, drafts left out
+	GetProjectBudgetRollup(ctx context.Context, arg GetProjectBudgetRollupParams) (GetProjectBudgetRollupRow, error)
+	// names are compared case-insensitively, the oldest match wins
+	GetProjectByName(ctx context.Context, arg GetProjectByNameParams) (Project, error)
+	// levels of the project's live subtree, 1 for a project without children and 0 for
+	// a missing one, guarded like ListProjectAncestors
+	GetProjectSubtreeDepth(ctx context.Context, arg GetProjectSubtreeDepthParams) (int32, error)
 	GetProjectWallets(ctx context.Context, arg GetProjectWalletsParams) ([]Wallet, error)
+	// in no particular order, the repository puts them in the order asked for
+	GetProjectsByIDs(ctx context.Context, arg GetProjectsByIDsParams) ([]Project, error)
 	GetSession(ctx context.Context, key string) (Session, error)
 	GetTag(ctx context.Context, arg GetTagParams) (Tag, error)
 	GetUser(ctx context.Context, userID uuid.UUID) (User, error)
 	GetUserByExternalID(ctx context.Context, arg GetUserByExternalIDParams) (User, error)
+	GetUserIDByInboundToken(ctx context.Context, token pgtype.Text) (uuid.UUID, error)
+	// the counts of the user's contacts, projects and wallets outside the trash, kept by triggers
+	GetUserResourceCounts(ctx context.Context, userID uuid.UUID) (GetUserResourceCountsRow, error)
 	GetUserSettings(ctx context.Context, userID uuid.UUID) (UsersSetting, error)
 	GetWallet(ctx context.Context, arg GetWalletParams) (Wallet, error)
+	GetWalletGroup(ctx context.Context, arg GetWalletGroupParams) (WalletGroup, error)
+	// the balance of the wallet just before the given time
+	GetWalletLedgerBalance(ctx context.Context, arg GetWalletLedgerBalanceParams) (pgtype.Numeric, error)
+	// money that left (outflow) and entered (inflow) the wallet over the trailing 7, 30 and
+	// 90 UTC days ending with today, an entry's day is the date of its occurred_at
+	GetWalletLedgerStats(ctx context.Context, arg GetWalletLedgerStatsParams) (GetWalletLedgerStatsRow, error)
+	// in no particular order, the repository puts them in the order asked for
+	GetWalletsByIDs(ctx context.Context, arg GetWalletsByIDsParams) ([]Wallet, error)
+	// the runner of a job touches it while it runs, so other instances leave it alone
+	HeartbeatJob(ctx context.Context, jobID uuid.UUID) error
+	// the attachments of the user's pending entries after the after_id cursor, without their
+	// contents
+	ListBackupAttachments(ctx context.Context, arg ListBackupAttachmentsParams) ([]ListBackupAttachmentsRow, error)
+	// the ledger entries of the user's active wallets after the after_seq cursor, in the order
+	// they were recorded. seq starts at 1 so a zero after_seq starts at the first entry.
+	ListBackupLedgerEntries(ctx context.Context, arg ListBackupLedgerEntriesParams) ([]WalletLedgerEntry, error)
+	ListBudgets(ctx context.Context, userID uuid.UUID) ([]Budget, error)
+	ListChildProjects(ctx context.Context, arg ListChildProjectsParams) ([]Project, error)
+	ListContactCompanies(ctx context.Context, arg ListContactCompaniesParams) ([]ListContactCompaniesRow, error)
+	// counts the distinct values of the facetable field of the user's contacts, most common first
+	ListContactFacets(ctx context.Context, arg ListContactFacetsParams) ([]ListContactFacetsRow, error)
+	// ListContactsPaginated selecting only the keys the list is paged by
+	ListContactIDsPaginated(ctx context.Context, arg ListContactIDsPaginatedParams) ([]ListContactIDsPaginatedRow, error)
+	// the relationships of the contact both ways, outgoing is false for those naming it as their to_contact.
+	// Each half is resolved through its own index.
+	ListContactRelationships(ctx context.Context, arg ListContactRelationshipsParams) ([]ListContactRelationshipsRow, error)
 	ListContacts(ctx context.Context, arg ListContactsParams) ([]Contact, error)
+	// trashed contacts included, ordered by ID so batches resume after the last one
+	ListContactsForAnonymization(ctx context.Context, arg ListContactsForAnonymizationParams) ([]Contact, error)
+	// city and state_province, when set, match the normalized values stored ignoring case.
 	ListContactsPaginated(ctx context.Context, arg ListContactsPaginatedParams) ([]Contact, error)
+	ListDeletedContactsPaginated(ctx context.Context, arg ListDeletedContactsPaginatedParams) ([]Contact, error)
+	ListDeletedProjectsPaginated(ctx context.Context, arg ListDeletedProjectsPaginatedParams) ([]Project, error)
+	ListDeletedWalletsPaginated(ctx context.Context, arg ListDeletedWalletsPaginatedParams) ([]Wallet, error)
+	// at most batch_size completed backups past their retention, oldest first, with the key
+	// of their archive
+	ListExpiredBackups(ctx context.Context, arg ListExpiredBackupsParams) ([]ListExpiredBackupsRow, error)
+	// the runs of a schedule of the user, newest first
+	ListExportRuns(ctx context.Context, arg ListExportRunsParams) ([]ExportRun, error)
+	ListExportSchedules(ctx context.Context, userID uuid.UUID) ([]ExportSchedule, error)
+	ListGroupWallets(ctx context.Context, arg ListGroupWalletsParams) ([]Wallet, error)
+	// a wallet without a balance counts as empty
+	ListLowBalanceWallets(ctx context.Context, userID uuid.UUID) ([]Wallet, error)
+	ListMilestones(ctx context.Context, arg ListMilestonesParams) ([]Milestone, error)
+	ListPendingEntries(ctx context.Context, arg ListPendingEntriesParams) ([]PendingEntry, error)
+	// the contents are left out, listings only describe the attachments
+	ListPendingEntryAttachments(ctx context.Context, entryIds []uuid.UUID) ([]ListPendingEntryAttachmentsRow, error)
+	// ListPinnedProjectsPaginated selecting only the keys the list is paged by
+	ListPinnedProjectIDsPaginated(ctx context.Context, arg ListPinnedProjectIDsPaginatedParams) ([]ListPinnedProjectIDsPaginatedRow, error)
+	ListPinnedProjectsPaginated(ctx context.Context, arg ListPinnedProjectsPaginatedParams) ([]Project, error)
+	// ListPinnedWalletsPaginated selecting only the keys the list is paged by
+	ListPinnedWalletIDsPaginated(ctx context.Context, arg ListPinnedWalletIDsPaginatedParams) ([]ListPinnedWalletIDsPaginatedRow, error)
+	// filtered the way ListWalletsPaginated is
+	ListPinnedWalletsPaginated(ctx context.Context, arg ListPinnedWalletsPaginatedParams) ([]Wallet, error)
+	// the project followed by its ancestors, nearest first. The depth guard ends the walk
+	// should racing updates ever write a cycle.
+	ListProjectAncestors(ctx context.Context, arg ListProjectAncestorsParams) ([]uuid.UUID, error)
+	// ListProjectsPaginated selecting only the keys the list is paged by
+	ListProjectIDsPaginated(ctx context.Context, arg ListProjectIDsPaginatedParams) ([]ListProjectIDsPaginatedRow, error)
+	// the balances of the wallets in the project and, with rollup, in its live descendants
+	// that aren't drafts, per currency
+	ListProjectWalletBalances(ctx context.Context, arg ListProjectWalletBalancesParams) ([]ListProjectWalletBalancesRow, error)
 	ListProjects(ctx context.Context, userID uuid.UUID) ([]Project, error)
+	// trashed projects included, ordered by ID so batches resume after the last one
+	ListProjectsForAnonymization(ctx context.Context, arg ListProjectsForAnonymizationParams) ([]Project, error)
+	// the unpinned projects, the pinned ones are listed before them by ListPinnedProjectsPaginated.
+	// Drafts are left out unless include_drafts is set.
 	ListProjectsPaginated(ctx context.Context, arg ListProjectsPaginatedParams) ([]Project, error)
 	ListTags(ctx context.Context, userID uuid.UUID) ([]Tag, error)
+	// Only the caller's tags resolve; IDs of other users' tags are ignored
+	ListTagsByIDs(ctx context.Context, arg ListTagsByIDsParams) ([]Tag, error)
+	// at most batch_size of the user's jobs with a stored export or backup archive, with
+	// the key of their archive
+	ListUserArchives(ctx context.Context, arg ListUserArchivesParams) ([]ListUserArchivesRow, error)
 	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
 	// Add efficient pagination using keyset pagination
 	ListUsersPaginated(ctx context.Context, arg ListUsersPaginatedParams) ([]User, error)
+	// counts the distinct values of the facetable field of the user's wallets, most common first
+	ListWalletFacets(ctx context.Context, arg ListWalletFacetsParams) ([]ListWalletFacetsRow, error)
+	ListWalletGroups(ctx context.Context, userID uuid.UUID) ([]WalletGroup, error)
+	// ListWalletsPaginated selecting only the keys the list is paged by
+	ListWalletIDsPaginated(ctx context.Context, arg ListWalletIDsPaginatedParams) ([]ListWalletIDsPaginatedRow, error)
+	// entries before the end of the range following the (after_occurred_at, after_seq) cursor,
+	// oldest first. seq starts at 1 so a zero after_seq includes entries at after_occurred_at.
+	ListWalletLedgerEntries(ctx context.Context, arg ListWalletLedgerEntriesParams) ([]ListWalletLedgerEntriesRow, error)
+	// the project of each wallet among wallet_ids, null for the wallets outside any project
+	ListWalletProjects(ctx context.Context, arg ListWalletProjectsParams) ([]ListWalletProjectsRow, error)
 	ListWallets(ctx context.Context, arg ListWalletsParams) ([]Wallet, error)
+	// with filter_group set only the wallets of group_id are listed, or the ungrouped ones when it is null.
+	// Wallets carrying any of tags are listed, all of them with match_all_tags, no tags doesn't filter.
+	// A null currency or has_project doesn't filter either, the filters all have to match.
+	// The pinned wallets are left out, ListPinnedWalletsPaginated lists them before the rest.
 	ListWalletsPaginated(ctx context.Context, arg ListWalletsPaginatedParams) ([]Wallet, error)
-	SearchContacts(ctx context.Context, arg SearchContactsParams) ([]Contact, error)
+	// the user's live contacts among contact_ids, locked until the merge commits
+	LockContactsForMerge(ctx context.Context, arg LockContactsForMergeParams) ([]Contact, error)
+	// takes the advisory lock of an entity until the transaction ends, writes reading and
+	// merging its current values take it so concurrent ones run one after the other
+	LockEntity(ctx context.Context, lockKey int64) error
+	// the user's live wallets among wallet_ids, locked until the merge commits
+	LockWalletsForMerge(ctx context.Context, arg LockWalletsForMergeParams) ([]Wallet, error)
+	MoveContactRelationships(ctx context.Context, arg MoveContactRelationshipsParams) (int64, error)
+	MoveDefaultWallet(ctx context.Context, arg MoveDefaultWalletParams) error
+	MoveLedgerEntries(ctx context.Context, arg MoveLedgerEntriesParams) (int64, error)
+	ProjectExists(ctx context.Context, arg ProjectExistsParams) (bool, error)
+	// creates the user signing in for the first time, nothing is inserted and no row
+	// returned when a concurrent sign in of the same user created it first
+	ProvisionUser(ctx context.Context, arg ProvisionUserParams) (User, error)
+	// turns a draft into a live project when it has the fields a live project needs,
+	// publishing a live project changes nothing, it is read rather than updated so its
+	// updated_at stays where it is
+	PublishProject(ctx context.Context, arg PublishProjectParams) (PublishProjectRow, error)
+	// at most batch_size rows per call, oldest first, skipping rows locked by a restore
+	PurgeDeletedContacts(ctx context.Context, arg PurgeDeletedContactsParams) (int64, error)
+	// at most batch_size rows per call, oldest first, skipping rows locked by a restore
+	PurgeDeletedProjects(ctx context.Context, arg PurgeDeletedProjectsParams) (int64, error)
+	// at most batch_size rows per call, oldest first, skipping rows locked by a restore
+	PurgeDeletedWallets(ctx context.Context, arg PurgeDeletedWalletsParams) (int64, error)
+	// at most batch_size rows per call, oldest first
+	PurgeExpiredSessions(ctx context.Context, arg PurgeExpiredSessionsParams) (int64, error)
+	// at most batch_size completed or failed jobs per call, oldest first
+	PurgeFinishedJobs(ctx context.Context, arg PurgeFinishedJobsParams) (int64, error)
+	PurgeMergedContact(ctx context.Context, arg PurgeMergedContactParams) error
+	// deletes the wallet for good whether it is in the trash or not, its ledger entries go with it.
+	// deleted_at tells whether it was in the trash, a version set only deletes it while it is still at that updated_at.
+	PurgeWallet(ctx context.Context, arg PurgeWalletParams) (pgtype.Timestamp, error)
+	// Positions follow the order of milestone_ids, nothing is updated unless the
+	// list covers every milestone of the project
+	ReorderMilestones(ctx context.Context, arg ReorderMilestonesParams) (int64, error)
+	RequeueJob(ctx context.Context, jobID uuid.UUID) error
+	// running jobs without a heartbeat since stale_before were left by a stopped instance
+	RequeueStaleJobs(ctx context.Context, staleBefore pgtype.Timestamp) (int64, error)
+	// releases the claim of a schedule once its run is recorded
+	RescheduleExportSchedule(ctx context.Context, arg RescheduleExportScheduleParams) error
+	RestoreContact(ctx context.Context, arg RestoreContactParams) (Contact, error)
+	// a project whose parent is still in the trash comes back at the top level
+	RestoreProject(ctx context.Context, arg RestoreProjectParams) (Project, error)
+	RestoreWallet(ctx context.Context, arg RestoreWalletParams) (Wallet, error)
+	SearchContactNotes(ctx context.Context, arg SearchContactNotesParams) ([]SearchContactNotesRow, error)
+	// score is how similar the closest of name and company is to the query from 0 to 1,
+	// meaningless without a query
+	SearchContacts(ctx context.Context, arg SearchContactsParams) ([]SearchContactsRow, error)
+	// score is how similar the company is to the query from 0 to 1
+	SearchContactsByCompany(ctx context.Context, arg SearchContactsByCompanyParams) ([]SearchContactsByCompanyRow, error)
+	// SearchContactsByCompany selecting only the columns of the picker view
+	SearchContactsByCompanyPicker(ctx context.Context, arg SearchContactsByCompanyPickerParams) ([]SearchContactsByCompanyPickerRow, error)
 	SearchContactsByPhone(ctx context.Context, arg SearchContactsByPhoneParams) ([]Contact, error)
-	SearchProjects(ctx context.Context, arg SearchProjectsParams) ([]Project, error)
+	// SearchContactsByPhone selecting only the columns of the picker view
+	SearchContactsByPhonePicker(ctx context.Context, arg SearchContactsByPhonePickerParams) ([]SearchContactsByPhonePickerRow, error)
+	// SearchContacts selecting only the columns of the picker view
+	SearchContactsPicker(ctx context.Context, arg SearchContactsPickerParams) ([]SearchContactsPickerRow, error)
+	SearchProjectDescriptions(ctx context.Context, arg SearchProjectDescriptionsParams) ([]SearchProjectDescriptionsRow, error)
+	// score is how similar the name is to the query from 0 to 1, meaningless without a query
+	SearchProjects(ctx context.Context, arg SearchProjectsParams) ([]SearchProjectsRow, error)
+	// SearchProjects selecting only the columns of the picker view
+	SearchProjectsPicker(ctx context.Context, arg SearchProjectsPickerParams) ([]SearchProjectsPickerRow, error)
 	// Add efficient search
 	SearchUsers(ctx context.Context, arg SearchUsersParams) ([]User, error)
-	SearchWallets(ctx context.Context, arg SearchWalletsParams) ([]Wallet, error)
+	// score is how similar the name is to the query from 0 to 1, meaningless without a query
+	SearchWallets(ctx context.Context, arg SearchWalletsParams) ([]SearchWalletsRow, error)
+	// SearchWallets selecting only the columns of the picker view
+	SearchWalletsPicker(ctx context.Context, arg SearchWalletsPickerParams) ([]SearchWalletsPickerRow, error)
+	// bounds how long the rest of the transaction waits for locks, lock_timeout being a
+	// duration such as '2s'
+	SetLockTimeout(ctx context.Context, lockTimeout string) error
+	// pinning a pinned project keeps its place, pins aren't edits so updated_at is left alone
+	SetProjectPinned(ctx context.Context, arg SetProjectPinnedParams) (Project, error)
+	// a default that isn't a live wallet or project of the user leaves the row unchanged
+	SetUserDefaults(ctx context.Context, arg SetUserDefaultsParams) (User, error)
+	SetUserInboundToken(ctx context.Context, arg SetUserInboundTokenParams) (User, error)
+	// pinning a pinned wallet keeps its place, pins aren't edits so updated_at is left alone
+	SetWalletPinned(ctx context.Context, arg SetWalletPinnedParams) (Wallet, error)
+	// the ledger trigger doesn't record the balance updates of the wallet until the transaction ends
+	SkipWalletLedger(ctx context.Context, walletID uuid.UUID) error
+	// wallets without a balance count as empty, include_deleted adds the wallets in the trash
+	SumWalletBalancesByCurrency(ctx context.Context, arg SumWalletBalancesByCurrencyParams) ([]SumWalletBalancesByCurrencyRow, error)
+	UnassignTagFromContacts(ctx context.Context, arg UnassignTagFromContactsParams) (int64, error)
+	UnassignTagFromProjects(ctx context.Context, arg UnassignTagFromProjectsParams) (int64, error)
+	UnassignTagFromWallets(ctx context.Context, arg UnassignTagFromWalletsParams) (int64, error)
+	UpdateBudget(ctx context.Context, arg UpdateBudgetParams) (Budget, error)
 	UpdateContact(ctx context.Context, arg UpdateContactParams) (Contact, error)
+	// UpsertContactByExternalRef without a name, which can only update
+	UpdateContactByExternalRef(ctx context.Context, arg UpdateContactByExternalRefParams) (Contact, error)
+	UpdateJobProgress(ctx context.Context, arg UpdateJobProgressParams) error
+	// completed_at keeps its original time while the milestone stays completed
+	UpdateMilestone(ctx context.Context, arg UpdateMilestoneParams) (Milestone, error)
 	UpdateProject(ctx context.Context, arg UpdateProjectParams) (Project, error)
 	UpdateTag(ctx context.Context, arg UpdateTagParams) (Tag, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
@@ -59,7 +335,17 @@ type Querier interface {
 	UpdateUserRefreshToken(ctx context.Context, arg UpdateUserRefreshTokenParams) error
 	UpdateUserSettings(ctx context.Context, arg UpdateUserSettingsParams) (UsersSetting, error)
 	UpdateWallet(ctx context.Context, arg UpdateWalletParams) (Wallet, error)
+	UpdateWalletGroup(ctx context.Context, arg UpdateWalletGroupParams) (WalletGroup, error)
+	// creates the contact of the external reference or updates the fields the sync sent,
+	// NULL args leave a field alone. A trashed contact is restored since its source still
+	// has it. inserted tells a create from an update: xmax is 0 on freshly inserted rows.
+	// Postgres checks NOT NULL before looking for a conflict, so name is always required
+	// here and UpdateContactByExternalRef covers syncs without one.
+	UpsertContactByExternalRef(ctx context.Context, arg UpsertContactByExternalRefParams) (UpsertContactByExternalRefRow, error)
 	UpsertSession(ctx context.Context, arg UpsertSessionParams) (Session, error)
+	UserOwnsWallet(ctx context.Context, arg UserOwnsWalletParams) (bool, error)
+	WalletGroupExists(ctx context.Context, arg WalletGroupExistsParams) (bool, error)
+	WalletNameExists(ctx context.Context, arg WalletNameExistsParams) (bool, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -51,6 +51,31 @@ func (q *Queries) GetSession(ctx context.Context, key string) (Session, error) {
 	return i, err
 }
 
+const purgeExpiredSessions = `-- name: PurgeExpiredSessions :execrows
+DELETE FROM "sessions"
+WHERE session_id IN (
+    SELECT s.session_id FROM "sessions" s
+    WHERE s.expires_at < $1
+    ORDER BY s.expires_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+`
+
+type PurgeExpiredSessionsParams struct {
+	ExpiredBefore pgtype.Timestamp `json:"expiredBefore"`
+	BatchSize     int32            `json:"batchSize"`
+}
+
+// at most batch_size rows per call, oldest first
+func (q *Queries) PurgeExpiredSessions(ctx context.Context, arg PurgeExpiredSessionsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeExpiredSessions, arg.ExpiredBefore, arg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const upsertSession = `-- name: UpsertSession :one
 INSERT INTO "sessions" (
     key,
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE contacts ADD COLUMN company VARCHAR(255);
+CREATE INDEX idx_contacts_user_company ON contacts (user_id, company);
+CREATE INDEX contact_company_trgm ON contacts USING gin (company gin_trgm_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS contact_company_trgm;
+DROP INDEX IF EXISTS idx_contacts_user_company;
+ALTER TABLE contacts DROP COLUMN IF EXISTS company;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+CREATE TABLE "jobs" (
+    job_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    total INTEGER NOT NULL DEFAULT 0,
+    processed INTEGER NOT NULL DEFAULT 0,
+    succeeded INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    payload JSONB NOT NULL,
+    errors JSONB NOT NULL DEFAULT '[]'::jsonb,
+    error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP,
+    CONSTRAINT jobs_status_check CHECK (status IN ('pending', 'running', 'completed', 'failed'))
+);
+
+-- Workers pick up pending jobs in creation order
+CREATE INDEX idx_jobs_status_created_at ON jobs(status, created_at);
+CREATE INDEX idx_jobs_user_id ON jobs(user_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_jobs_user_id;
+DROP INDEX IF EXISTS idx_jobs_status_created_at;
+DROP TABLE IF EXISTS "jobs";
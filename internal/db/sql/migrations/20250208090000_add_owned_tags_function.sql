@@ -0,0 +1,43 @@
+-- +goose Up
+-- owned_tags keeps only the tags that belong to the given user, in their
+-- original order. With app.strict_tag_ownership = 'on' it rejects the write
+-- instead, raising SQLSTATE ET001.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION owned_tags(p_user_id UUID, p_tags UUID[])
+RETURNS UUID[]
+LANGUAGE plpgsql
+STABLE
+AS $$
+DECLARE
+    owned UUID[];
+BEGIN
+    IF p_tags IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    SELECT COALESCE(array_agg(requested.tag_id ORDER BY requested.position), '{}')
+    INTO owned
+    FROM unnest(p_tags) WITH ORDINALITY AS requested(tag_id, position)
+    WHERE EXISTS (
+        SELECT 1 FROM tags t
+        WHERE t.tag_id = requested.tag_id AND t.user_id = p_user_id
+    );
+
+    IF cardinality(owned) < cardinality(p_tags)
+        AND COALESCE(current_setting('app.strict_tag_ownership', true), 'off') = 'on' THEN
+        RAISE EXCEPTION 'tags not owned by user'
+            USING ERRCODE = 'ET001';
+    END IF;
+
+    RETURN owned;
+END;
+$$;
+-- +goose StatementEnd
+
+-- Drop tags that were attached across users before ownership was enforced
+UPDATE contacts SET tags = owned_tags(user_id, tags) WHERE tags IS NOT NULL;
+UPDATE projects SET tags = owned_tags(user_id, tags) WHERE tags IS NOT NULL;
+UPDATE wallets SET tags = owned_tags(user_id, tags) WHERE tags IS NOT NULL;
+
+-- +goose Down
+DROP FUNCTION IF EXISTS owned_tags(UUID, UUID[]);
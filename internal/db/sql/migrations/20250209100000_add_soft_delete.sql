@@ -0,0 +1,34 @@
+-- +goose Up
+ALTER TABLE contacts ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE projects ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE wallets ADD COLUMN deleted_at TIMESTAMP;
+
+-- Names only need to be unique among live rows so a trashed item doesn't block reusing its name
+DROP INDEX IF EXISTS projects_user_id_name_idx;
+CREATE UNIQUE INDEX projects_user_id_name_idx ON projects(user_id, name) WHERE deleted_at IS NULL;
+DROP INDEX IF EXISTS wallets_user_id_name_idx;
+CREATE UNIQUE INDEX wallets_user_id_name_idx ON wallets(user_id, name) WHERE deleted_at IS NULL;
+
+-- Trash listing and purging
+CREATE INDEX contacts_user_id_deleted_at_idx ON contacts(user_id, deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX projects_user_id_deleted_at_idx ON projects(user_id, deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX wallets_user_id_deleted_at_idx ON wallets(user_id, deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS wallets_user_id_deleted_at_idx;
+DROP INDEX IF EXISTS projects_user_id_deleted_at_idx;
+DROP INDEX IF EXISTS contacts_user_id_deleted_at_idx;
+
+-- Trashed rows can't be told apart once the column is gone
+DELETE FROM wallets WHERE deleted_at IS NOT NULL;
+DELETE FROM projects WHERE deleted_at IS NOT NULL;
+DELETE FROM contacts WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS wallets_user_id_name_idx;
+CREATE UNIQUE INDEX wallets_user_id_name_idx ON wallets(user_id, name);
+DROP INDEX IF EXISTS projects_user_id_name_idx;
+CREATE UNIQUE INDEX projects_user_id_name_idx ON projects(user_id, name);
+
+ALTER TABLE wallets DROP COLUMN deleted_at;
+ALTER TABLE projects DROP COLUMN deleted_at;
+ALTER TABLE contacts DROP COLUMN deleted_at;
@@ -0,0 +1,18 @@
+-- +goose Up
+ALTER TABLE contacts ADD COLUMN notes TEXT;
+
+-- Kept in sync by Postgres so full-text search never reads a stale vector
+ALTER TABLE contacts ADD COLUMN notes_search tsvector
+    GENERATED ALWAYS AS (to_tsvector('english', coalesce(notes, ''))) STORED;
+ALTER TABLE projects ADD COLUMN description_search tsvector
+    GENERATED ALWAYS AS (to_tsvector('english', coalesce(description, ''))) STORED;
+
+CREATE INDEX contacts_notes_search_idx ON contacts USING gin (notes_search);
+CREATE INDEX projects_description_search_idx ON projects USING gin (description_search);
+
+-- +goose Down
+DROP INDEX IF EXISTS projects_description_search_idx;
+DROP INDEX IF EXISTS contacts_notes_search_idx;
+ALTER TABLE projects DROP COLUMN IF EXISTS description_search;
+ALTER TABLE contacts DROP COLUMN IF EXISTS notes_search;
+ALTER TABLE contacts DROP COLUMN IF EXISTS notes;
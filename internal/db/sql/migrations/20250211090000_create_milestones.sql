@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE "milestones" (
+    milestone_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    due_date TIMESTAMP,
+    completed_at TIMESTAMP,
+    sort_order INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    -- purging a project takes its milestones with it in the same statement
+    FOREIGN KEY (project_id) REFERENCES projects(project_id) ON DELETE CASCADE
+);
+CREATE INDEX milestones_project_id_sort_order_idx ON milestones(project_id, sort_order);
+
+-- +goose Down
+DROP TABLE IF EXISTS milestones;
@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE wallets ADD COLUMN low_balance_threshold DECIMAL(10,2);
+ALTER TABLE wallets
+ADD CONSTRAINT wallets_low_balance_threshold_check
+CHECK (low_balance_threshold IS NULL OR low_balance_threshold >= 0);
+-- only wallets with a threshold can raise an alert
+CREATE INDEX wallets_low_balance_idx ON wallets(user_id)
+WHERE low_balance_threshold IS NOT NULL AND deleted_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS wallets_low_balance_idx;
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS wallets_low_balance_threshold_check;
+ALTER TABLE wallets DROP COLUMN IF EXISTS low_balance_threshold;
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE "wallet_groups" (
+    group_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    sort_order INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+-- group names are unique per user regardless of case
+CREATE UNIQUE INDEX wallet_groups_user_id_name_idx ON wallet_groups(user_id, lower(name));
+
+-- deleting a group leaves its wallets ungrouped
+ALTER TABLE wallets ADD COLUMN group_id UUID REFERENCES wallet_groups(group_id) ON DELETE SET NULL;
+CREATE INDEX wallets_group_id_idx ON wallets(group_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS wallets_group_id_idx;
+ALTER TABLE wallets DROP COLUMN IF EXISTS group_id;
+DROP INDEX IF EXISTS wallet_groups_user_id_name_idx;
+DROP TABLE IF EXISTS wallet_groups;
@@ -0,0 +1,51 @@
+-- +goose Up
+-- Set once an admin has irreversibly pseudonymized the user's PII
+ALTER TABLE users ADD COLUMN anonymized_at TIMESTAMP;
+
+-- block_anonymized_pii rejects PII writes for anonymized users, raising SQLSTATE
+-- ET002. The anonymization itself runs with app.anonymizing = 'on' to get through.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION block_anonymized_pii()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    IF COALESCE(current_setting('app.anonymizing', true), 'off') = 'on' THEN
+        RETURN NEW;
+    END IF;
+
+    IF EXISTS (
+        SELECT 1 FROM users u
+        WHERE u.user_id = NEW.user_id AND u.anonymized_at IS NOT NULL
+    ) THEN
+        RAISE EXCEPTION 'user data is anonymized'
+            USING ERRCODE = 'ET002';
+    END IF;
+
+    RETURN NEW;
+END;
+$$;
+-- +goose StatementEnd
+
+-- Only writes touching PII columns are blocked, trashing and restoring keep working
+CREATE TRIGGER contacts_block_anonymized_pii
+    BEFORE INSERT OR UPDATE OF name, phone, email, address_line1, address_line2, zip_postal_code, company, notes
+    ON contacts
+    FOR EACH ROW EXECUTE FUNCTION block_anonymized_pii();
+
+CREATE TRIGGER projects_block_anonymized_pii
+    BEFORE INSERT OR UPDATE OF description, address_line1, address_line2, zip_postal_code, website
+    ON projects
+    FOR EACH ROW EXECUTE FUNCTION block_anonymized_pii();
+
+CREATE TRIGGER users_block_anonymized_pii
+    BEFORE UPDATE OF name, email, address_line1, address_line2, zip_postal_code
+    ON users
+    FOR EACH ROW EXECUTE FUNCTION block_anonymized_pii();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS users_block_anonymized_pii ON users;
+DROP TRIGGER IF EXISTS projects_block_anonymized_pii ON projects;
+DROP TRIGGER IF EXISTS contacts_block_anonymized_pii ON contacts;
+DROP FUNCTION IF EXISTS block_anonymized_pii();
+ALTER TABLE users DROP COLUMN IF EXISTS anonymized_at;
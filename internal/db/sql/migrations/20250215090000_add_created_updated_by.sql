@@ -0,0 +1,27 @@
+-- +goose Up
+-- created_by and updated_by record the user who made the change, today always the
+-- owner. They're kept when that user goes away so shared rows keep their history.
+ALTER TABLE contacts
+    ADD COLUMN created_by UUID REFERENCES users(user_id) ON DELETE SET NULL,
+    ADD COLUMN updated_by UUID REFERENCES users(user_id) ON DELETE SET NULL;
+ALTER TABLE projects
+    ADD COLUMN created_by UUID REFERENCES users(user_id) ON DELETE SET NULL,
+    ADD COLUMN updated_by UUID REFERENCES users(user_id) ON DELETE SET NULL;
+ALTER TABLE wallets
+    ADD COLUMN created_by UUID REFERENCES users(user_id) ON DELETE SET NULL,
+    ADD COLUMN updated_by UUID REFERENCES users(user_id) ON DELETE SET NULL;
+ALTER TABLE wallet_groups
+    ADD COLUMN created_by UUID REFERENCES users(user_id) ON DELETE SET NULL,
+    ADD COLUMN updated_by UUID REFERENCES users(user_id) ON DELETE SET NULL;
+
+-- existing rows were all written by their owner
+UPDATE contacts SET created_by = user_id, updated_by = user_id;
+UPDATE projects SET created_by = user_id, updated_by = user_id;
+UPDATE wallets SET created_by = user_id, updated_by = user_id;
+UPDATE wallet_groups SET created_by = user_id, updated_by = user_id;
+
+-- +goose Down
+ALTER TABLE wallet_groups DROP COLUMN IF EXISTS updated_by, DROP COLUMN IF EXISTS created_by;
+ALTER TABLE wallets DROP COLUMN IF EXISTS updated_by, DROP COLUMN IF EXISTS created_by;
+ALTER TABLE projects DROP COLUMN IF EXISTS updated_by, DROP COLUMN IF EXISTS created_by;
+ALTER TABLE contacts DROP COLUMN IF EXISTS updated_by, DROP COLUMN IF EXISTS created_by;
@@ -0,0 +1,52 @@
+-- +goose Up
+-- forwarding_address is the address the user forwards receipts from, inbound emails
+-- are matched to users by their sender
+ALTER TABLE users ADD COLUMN forwarding_address VARCHAR(255);
+CREATE UNIQUE INDEX users_forwarding_address_idx ON users (lower(forwarding_address))
+WHERE forwarding_address IS NOT NULL;
+
+-- pending_entries hold forwarded receipts until transactions land, entries whose
+-- amount couldn't be parsed wait for manual review
+CREATE TABLE pending_entries (
+    entry_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    sender VARCHAR(255) NOT NULL,
+    subject TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL DEFAULT '',
+    amount DECIMAL(12,2),
+    currency CHAR(3),
+    status VARCHAR(20) NOT NULL CHECK (status IN ('parsed', 'needs_review')),
+    -- the text fields as the provider posted them
+    raw JSONB NOT NULL DEFAULT '{}',
+    received_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((amount IS NULL) = (currency IS NULL))
+);
+CREATE INDEX pending_entries_user_idx ON pending_entries(user_id, received_at DESC);
+
+CREATE TABLE pending_entry_attachments (
+    attachment_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    entry_id UUID NOT NULL REFERENCES pending_entries(entry_id) ON DELETE CASCADE,
+    filename VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    content BYTEA NOT NULL
+);
+CREATE INDEX pending_entry_attachments_entry_idx ON pending_entry_attachments(entry_id);
+
+-- the forwarding address is PII like the rest of the profile
+DROP TRIGGER IF EXISTS users_block_anonymized_pii ON users;
+CREATE TRIGGER users_block_anonymized_pii
+    BEFORE UPDATE OF name, email, address_line1, address_line2, zip_postal_code, forwarding_address
+    ON users
+    FOR EACH ROW EXECUTE FUNCTION block_anonymized_pii();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS users_block_anonymized_pii ON users;
+CREATE TRIGGER users_block_anonymized_pii
+    BEFORE UPDATE OF name, email, address_line1, address_line2, zip_postal_code
+    ON users
+    FOR EACH ROW EXECUTE FUNCTION block_anonymized_pii();
+DROP TABLE IF EXISTS pending_entry_attachments;
+DROP TABLE IF EXISTS pending_entries;
+DROP INDEX IF EXISTS users_forwarding_address_idx;
+ALTER TABLE users DROP COLUMN IF EXISTS forwarding_address;
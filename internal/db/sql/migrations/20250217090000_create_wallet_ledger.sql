@@ -0,0 +1,52 @@
+-- +goose Up
+-- The ledger records every change of a wallet's balance, summing a wallet's entries
+-- gives its balance. seq orders entries sharing a timestamp by insertion.
+CREATE TABLE wallet_ledger_entries (
+    entry_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    wallet_id UUID NOT NULL REFERENCES wallets(wallet_id) ON DELETE CASCADE,
+    seq BIGINT NOT NULL GENERATED ALWAYS AS IDENTITY,
+    amount DECIMAL(12,2) NOT NULL,
+    description VARCHAR(255) NOT NULL,
+    occurred_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX wallet_ledger_entries_wallet_idx ON wallet_ledger_entries(wallet_id, occurred_at, seq);
+
+-- record_wallet_ledger adds the opening balance of new wallets and the difference of
+-- every balance update, a null balance counts as zero
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION record_wallet_ledger()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        IF COALESCE(NEW.balance, 0) <> 0 THEN
+            INSERT INTO wallet_ledger_entries (wallet_id, amount, description, occurred_at)
+            VALUES (NEW.wallet_id, NEW.balance, 'Opening balance', COALESCE(NEW.created_at, CURRENT_TIMESTAMP));
+        END IF;
+    ELSIF COALESCE(NEW.balance, 0) <> COALESCE(OLD.balance, 0) THEN
+        INSERT INTO wallet_ledger_entries (wallet_id, amount, description, occurred_at)
+        VALUES (NEW.wallet_id, COALESCE(NEW.balance, 0) - COALESCE(OLD.balance, 0), 'Balance adjustment', COALESCE(NEW.updated_at, CURRENT_TIMESTAMP));
+    END IF;
+
+    RETURN NULL;
+END;
+$$;
+-- +goose StatementEnd
+
+CREATE TRIGGER wallets_record_ledger
+    AFTER INSERT OR UPDATE OF balance
+    ON wallets
+    FOR EACH ROW EXECUTE FUNCTION record_wallet_ledger();
+
+-- Existing wallets open with their current balance
+INSERT INTO wallet_ledger_entries (wallet_id, amount, description, occurred_at)
+SELECT wallet_id, balance, 'Opening balance', COALESCE(created_at, CURRENT_TIMESTAMP)
+FROM wallets
+WHERE COALESCE(balance, 0) <> 0
+ORDER BY created_at, wallet_id;
+
+-- +goose Down
+DROP TRIGGER IF EXISTS wallets_record_ledger ON wallets;
+DROP FUNCTION IF EXISTS record_wallet_ledger();
+DROP TABLE IF EXISTS wallet_ledger_entries;
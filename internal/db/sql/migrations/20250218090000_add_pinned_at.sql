@@ -0,0 +1,16 @@
+-- +goose Up
+-- pinned_at puts a wallet or project at the top of the paginated listings, the most
+-- recently pinned first. Trashing a row drops its pin.
+ALTER TABLE projects ADD COLUMN pinned_at TIMESTAMP;
+ALTER TABLE wallets ADD COLUMN pinned_at TIMESTAMP;
+
+CREATE INDEX idx_projects_pinned ON projects (user_id, pinned_at DESC, project_id DESC)
+    WHERE pinned_at IS NOT NULL AND deleted_at IS NULL;
+CREATE INDEX idx_wallets_pinned ON wallets (user_id, pinned_at DESC, wallet_id DESC)
+    WHERE pinned_at IS NOT NULL AND deleted_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_wallets_pinned;
+DROP INDEX IF EXISTS idx_projects_pinned;
+ALTER TABLE wallets DROP COLUMN IF EXISTS pinned_at;
+ALTER TABLE projects DROP COLUMN IF EXISTS pinned_at;
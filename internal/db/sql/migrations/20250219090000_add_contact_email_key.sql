@@ -0,0 +1,43 @@
+-- +goose Up
+-- email_key is the lowercased email of a live contact while app.unique_contact_emails
+-- = 'on', NULL otherwise. The partial unique index on it keeps at most one contact per
+-- email and user. It's only recomputed when a write changes the email or trashes or restores
+-- the contact, so duplicates from before the setting was on stay editable.
+ALTER TABLE contacts ADD COLUMN email_key VARCHAR(100);
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION contacts_email_key()
+RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+        AND NEW.email IS NOT DISTINCT FROM OLD.email
+        AND NEW.deleted_at IS NOT DISTINCT FROM OLD.deleted_at THEN
+        NEW.email_key := OLD.email_key;
+        RETURN NEW;
+    END IF;
+
+    IF NEW.deleted_at IS NULL
+        AND COALESCE(current_setting('app.unique_contact_emails', true), 'off') = 'on' THEN
+        NEW.email_key := NULLIF(lower(btrim(NEW.email)), '');
+    ELSE
+        NEW.email_key := NULL;
+    END IF;
+    RETURN NEW;
+END;
+$$;
+-- +goose StatementEnd
+
+CREATE TRIGGER contacts_email_key
+    BEFORE INSERT OR UPDATE ON contacts
+    FOR EACH ROW EXECUTE FUNCTION contacts_email_key();
+
+CREATE UNIQUE INDEX contacts_user_id_email_key_idx ON contacts (user_id, email_key)
+    WHERE email_key IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS contacts_user_id_email_key_idx;
+DROP TRIGGER IF EXISTS contacts_email_key ON contacts;
+DROP FUNCTION IF EXISTS contacts_email_key();
+ALTER TABLE contacts DROP COLUMN IF EXISTS email_key;
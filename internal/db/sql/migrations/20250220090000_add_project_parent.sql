@@ -0,0 +1,12 @@
+-- +goose Up
+-- parent_project_id nests a project under another one of the same user, trees are at
+-- most three levels deep and free of cycles, which the service checks on every write.
+-- Purging a trashed parent leaves its children at the top level.
+ALTER TABLE projects ADD COLUMN parent_project_id UUID REFERENCES projects(project_id) ON DELETE SET NULL;
+
+CREATE INDEX idx_projects_parent ON projects (parent_project_id, created_at)
+    WHERE parent_project_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_projects_parent;
+ALTER TABLE projects DROP COLUMN IF EXISTS parent_project_id;
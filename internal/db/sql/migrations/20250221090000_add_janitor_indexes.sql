@@ -0,0 +1,15 @@
+-- +goose Up
+-- The janitor deletes expired and trashed rows of every user in small batches, oldest
+-- first. These indexes find each batch without scanning the tables.
+CREATE INDEX contacts_deleted_at_idx ON contacts (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX projects_deleted_at_idx ON projects (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX wallets_deleted_at_idx ON wallets (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX sessions_expires_at_idx ON sessions (expires_at);
+CREATE INDEX jobs_completed_at_idx ON jobs (completed_at) WHERE completed_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS jobs_completed_at_idx;
+DROP INDEX IF EXISTS sessions_expires_at_idx;
+DROP INDEX IF EXISTS wallets_deleted_at_idx;
+DROP INDEX IF EXISTS projects_deleted_at_idx;
+DROP INDEX IF EXISTS contacts_deleted_at_idx;
@@ -0,0 +1,31 @@
+-- +goose Up
+-- external_source and external_id identify a contact or project in the system it is
+-- synced from, e.g. a CRM. Both are set or neither, and a reference belongs to at most
+-- one row per user: the unique index includes user_id so users syncing the same
+-- system never collide, and rows without a reference never conflict as NULLs are distinct.
+ALTER TABLE contacts
+    ADD COLUMN external_source VARCHAR(50),
+    ADD COLUMN external_id VARCHAR(255),
+    ADD CONSTRAINT contacts_external_ref_check CHECK ((external_source IS NULL) = (external_id IS NULL));
+
+CREATE UNIQUE INDEX contacts_user_id_external_ref_idx ON contacts (user_id, external_source, external_id);
+
+ALTER TABLE projects
+    ADD COLUMN external_source VARCHAR(50),
+    ADD COLUMN external_id VARCHAR(255),
+    ADD CONSTRAINT projects_external_ref_check CHECK ((external_source IS NULL) = (external_id IS NULL));
+
+CREATE UNIQUE INDEX projects_user_id_external_ref_idx ON projects (user_id, external_source, external_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS projects_user_id_external_ref_idx;
+ALTER TABLE projects
+    DROP CONSTRAINT IF EXISTS projects_external_ref_check,
+    DROP COLUMN IF EXISTS external_id,
+    DROP COLUMN IF EXISTS external_source;
+
+DROP INDEX IF EXISTS contacts_user_id_external_ref_idx;
+ALTER TABLE contacts
+    DROP CONSTRAINT IF EXISTS contacts_external_ref_check,
+    DROP COLUMN IF EXISTS external_id,
+    DROP COLUMN IF EXISTS external_source;
@@ -0,0 +1,27 @@
+-- +goose Up
+-- Wallet amounts keep three decimals, the minor unit of currencies like KWD or BHD.
+-- The ledger trigger lists the balance column and has to go while its type changes.
+DROP TRIGGER IF EXISTS wallets_record_ledger ON wallets;
+
+ALTER TABLE wallets
+    ALTER COLUMN balance TYPE DECIMAL(11,3),
+    ALTER COLUMN low_balance_threshold TYPE DECIMAL(11,3);
+ALTER TABLE wallet_ledger_entries ALTER COLUMN amount TYPE DECIMAL(13,3);
+
+CREATE TRIGGER wallets_record_ledger
+    AFTER INSERT OR UPDATE OF balance
+    ON wallets
+    FOR EACH ROW EXECUTE FUNCTION record_wallet_ledger();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS wallets_record_ledger ON wallets;
+
+ALTER TABLE wallet_ledger_entries ALTER COLUMN amount TYPE DECIMAL(12,2);
+ALTER TABLE wallets
+    ALTER COLUMN balance TYPE DECIMAL(10,2),
+    ALTER COLUMN low_balance_threshold TYPE DECIMAL(10,2);
+
+CREATE TRIGGER wallets_record_ledger
+    AFTER INSERT OR UPDATE OF balance
+    ON wallets
+    FOR EACH ROW EXECUTE FUNCTION record_wallet_ledger();
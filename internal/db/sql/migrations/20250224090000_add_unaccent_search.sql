@@ -0,0 +1,37 @@
+-- +goose Up
+-- Name searches match regardless of accents, "emile" finds "Émile"
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+-- unaccent() is only STABLE since its dictionary can be swapped, an index needs an
+-- IMMUTABLE function. Naming the dictionary pins it down.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION f_unaccent(value text)
+RETURNS text
+LANGUAGE sql
+IMMUTABLE PARALLEL SAFE STRICT
+AS $$
+    SELECT public.unaccent('public.unaccent'::regdictionary, value)
+$$;
+-- +goose StatementEnd
+
+-- The trigram indexes move to the unaccented names the searches compare
+DROP INDEX IF EXISTS project_name_trgm;
+DROP INDEX IF EXISTS wallet_name_trgm;
+DROP INDEX IF EXISTS contact_name_trgm;
+DROP INDEX IF EXISTS contact_company_trgm;
+CREATE INDEX project_name_unaccent_trgm ON projects USING gin (f_unaccent(name) gin_trgm_ops);
+CREATE INDEX wallet_name_unaccent_trgm ON wallets USING gin (f_unaccent(name) gin_trgm_ops);
+CREATE INDEX contact_name_unaccent_trgm ON contacts USING gin (f_unaccent(name) gin_trgm_ops);
+CREATE INDEX contact_company_unaccent_trgm ON contacts USING gin (f_unaccent(company) gin_trgm_ops);
+
+-- +goose Down
+DROP INDEX IF EXISTS contact_company_unaccent_trgm;
+DROP INDEX IF EXISTS contact_name_unaccent_trgm;
+DROP INDEX IF EXISTS wallet_name_unaccent_trgm;
+DROP INDEX IF EXISTS project_name_unaccent_trgm;
+CREATE INDEX project_name_trgm ON projects USING gin (name gin_trgm_ops);
+CREATE INDEX wallet_name_trgm ON wallets USING gin (name gin_trgm_ops);
+CREATE INDEX contact_name_trgm ON contacts USING gin (name gin_trgm_ops);
+CREATE INDEX contact_company_trgm ON contacts USING gin (company gin_trgm_ops);
+DROP FUNCTION IF EXISTS f_unaccent(text);
+DROP EXTENSION IF EXISTS unaccent;
@@ -0,0 +1,49 @@
+-- +goose Up
+-- The wallet and project quick entries land in when the user doesn't pick one
+ALTER TABLE users
+    ADD COLUMN default_wallet_id UUID REFERENCES wallets(wallet_id) ON DELETE SET NULL,
+    ADD COLUMN default_project_id UUID REFERENCES projects(project_id) ON DELETE SET NULL;
+
+-- clear_trashed_user_default clears the default a wallet or project was when it goes to
+-- the trash, purging it clears it through the foreign key
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION clear_trashed_user_default()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    IF TG_TABLE_NAME = 'wallets' THEN
+        UPDATE users SET default_wallet_id = NULL
+        WHERE user_id = NEW.user_id AND default_wallet_id = NEW.wallet_id;
+    ELSE
+        UPDATE users SET default_project_id = NULL
+        WHERE user_id = NEW.user_id AND default_project_id = NEW.project_id;
+    END IF;
+
+    RETURN NULL;
+END;
+$$;
+-- +goose StatementEnd
+
+CREATE TRIGGER wallets_clear_user_default
+    AFTER UPDATE OF deleted_at
+    ON wallets
+    FOR EACH ROW
+    WHEN (OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL)
+    EXECUTE FUNCTION clear_trashed_user_default();
+
+CREATE TRIGGER projects_clear_user_default
+    AFTER UPDATE OF deleted_at
+    ON projects
+    FOR EACH ROW
+    WHEN (OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL)
+    EXECUTE FUNCTION clear_trashed_user_default();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS projects_clear_user_default ON projects;
+DROP TRIGGER IF EXISTS wallets_clear_user_default ON wallets;
+DROP FUNCTION IF EXISTS clear_trashed_user_default();
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS default_project_id,
+    DROP COLUMN IF EXISTS default_wallet_id;
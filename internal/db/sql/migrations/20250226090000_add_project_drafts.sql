@@ -0,0 +1,9 @@
+-- +goose Up
+-- Drafts are projects still being negotiated, they stay out of listings and budget
+-- rollups until they are published
+ALTER TABLE projects
+    ADD COLUMN is_draft BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE projects
+    DROP COLUMN IF EXISTS is_draft;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Jobs producing a file, such as contact exports, keep the blob store key of the file
+-- once they complete
+ALTER TABLE jobs
+    ADD COLUMN result_key TEXT;
+
+-- +goose Down
+ALTER TABLE jobs
+    DROP COLUMN IF EXISTS result_key;
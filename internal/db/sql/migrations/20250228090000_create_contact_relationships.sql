@@ -0,0 +1,60 @@
+-- +goose Up
+CREATE TYPE "contact_relationship_type" AS ENUM (
+    'works_for',
+    'reports_to',
+    'spouse_of',
+    'relative_of',
+    'referred_by'
+);
+
+-- A relationship reads from_contact <type> to_contact, the accountant works_for the supplier
+CREATE TABLE "contact_relationships" (
+    relationship_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    from_contact_id UUID NOT NULL,
+    to_contact_id UUID NOT NULL,
+    type contact_relationship_type NOT NULL,
+    note VARCHAR(1000),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT contact_relationships_not_self CHECK (from_contact_id <> to_contact_id),
+    -- purging a contact takes its relationships with it in the same statement
+    FOREIGN KEY (from_contact_id) REFERENCES contacts(contact_id) ON DELETE CASCADE,
+    FOREIGN KEY (to_contact_id) REFERENCES contacts(contact_id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+-- the unique index resolves the outgoing relationships, the second one the incoming ones
+CREATE UNIQUE INDEX contact_relationships_pair_type_idx ON contact_relationships(from_contact_id, to_contact_id, type);
+CREATE INDEX contact_relationships_to_contact_id_idx ON contact_relationships(to_contact_id);
+-- spouse_of and relative_of read the same both ways, B spouse_of A duplicates A spouse_of B
+CREATE UNIQUE INDEX contact_relationships_symmetric_pair_idx
+    ON contact_relationships(LEAST(from_contact_id, to_contact_id), GREATEST(from_contact_id, to_contact_id), type)
+    WHERE type IN ('spouse_of', 'relative_of');
+
+-- drop_trashed_contact_relationships removes the relationships of a contact when it goes
+-- to the trash, in the transaction trashing it. Restoring the contact doesn't bring them back.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION drop_trashed_contact_relationships()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    DELETE FROM contact_relationships
+    WHERE from_contact_id = NEW.contact_id OR to_contact_id = NEW.contact_id;
+
+    RETURN NULL;
+END;
+$$;
+-- +goose StatementEnd
+
+CREATE TRIGGER contacts_drop_relationships
+    AFTER UPDATE OF deleted_at
+    ON contacts
+    FOR EACH ROW
+    WHEN (OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL)
+    EXECUTE FUNCTION drop_trashed_contact_relationships();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS contacts_drop_relationships ON contacts;
+DROP FUNCTION IF EXISTS drop_trashed_contact_relationships();
+DROP TABLE IF EXISTS contact_relationships;
+DROP TYPE IF EXISTS contact_relationship_type;
@@ -0,0 +1,49 @@
+-- +goose Up
+-- Scheduled exports deliver the user's contacts or wallets on a cadence to a webhook or
+-- an email address, every delivery attempt is kept as a run
+CREATE TABLE "export_schedules" (
+    schedule_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    entity_type VARCHAR(50) NOT NULL,
+    format VARCHAR(50) NOT NULL,
+    cadence VARCHAR(20) NOT NULL,
+    delivery_type VARCHAR(20) NOT NULL,
+    delivery_target TEXT NOT NULL,
+    -- signs the webhook deliveries, it is only shown when the schedule is created
+    signing_secret TEXT NOT NULL,
+    next_run_at TIMESTAMP NOT NULL,
+    -- set while a scheduler runs the export, a run that never finished is claimed again
+    -- once it passes
+    claimed_until TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT export_schedules_cadence_check CHECK (cadence IN ('daily', 'weekly', 'monthly')),
+    CONSTRAINT export_schedules_delivery_type_check CHECK (delivery_type IN ('webhook', 'email'))
+);
+
+-- Schedulers claim the schedules that are due, earliest first
+CREATE INDEX idx_export_schedules_next_run_at ON export_schedules(next_run_at);
+CREATE INDEX idx_export_schedules_user_id ON export_schedules(user_id);
+
+CREATE TABLE "export_runs" (
+    run_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    schedule_id UUID NOT NULL REFERENCES export_schedules(schedule_id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL,
+    rows INTEGER NOT NULL DEFAULT 0,
+    bytes BIGINT NOT NULL DEFAULT 0,
+    error TEXT,
+    -- the due time the run was for, a scheduler that was down runs a late schedule once
+    scheduled_for TIMESTAMP NOT NULL,
+    started_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP NOT NULL,
+    CONSTRAINT export_runs_status_check CHECK (status IN ('succeeded', 'failed'))
+);
+
+CREATE INDEX idx_export_runs_schedule_id_started_at ON export_runs(schedule_id, started_at DESC, run_id DESC);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_export_runs_schedule_id_started_at;
+DROP TABLE IF EXISTS "export_runs";
+DROP INDEX IF EXISTS idx_export_schedules_user_id;
+DROP INDEX IF EXISTS idx_export_schedules_next_run_at;
+DROP TABLE IF EXISTS "export_schedules";
@@ -0,0 +1,8 @@
+-- +goose Up
+-- links are the websites and social profiles of a contact, an array of {label, url}
+-- objects
+ALTER TABLE contacts ADD COLUMN links JSONB NOT NULL DEFAULT '[]'
+    CHECK (jsonb_typeof(links) = 'array');
+
+-- +goose Down
+ALTER TABLE contacts DROP COLUMN IF EXISTS links;
@@ -0,0 +1,78 @@
+-- +goose Up
+-- The counts of a user's contacts, projects and wallets outside the trash, kept by
+-- triggers so the quotas don't count the rows on every create
+ALTER TABLE users
+    ADD COLUMN contact_count INT NOT NULL DEFAULT 0,
+    ADD COLUMN project_count INT NOT NULL DEFAULT 0,
+    ADD COLUMN wallet_count INT NOT NULL DEFAULT 0;
+
+UPDATE users u SET
+    contact_count = (SELECT COUNT(*) FROM contacts c WHERE c.user_id = u.user_id AND c.deleted_at IS NULL),
+    project_count = (SELECT COUNT(*) FROM projects p WHERE p.user_id = u.user_id AND p.deleted_at IS NULL),
+    wallet_count = (SELECT COUNT(*) FROM wallets w WHERE w.user_id = u.user_id AND w.deleted_at IS NULL);
+
+-- count_user_resources keeps the users column named by its argument in step with the
+-- rows of the table it is on: inserts and restores add one, deletes and moves to the
+-- trash take one away. Rows in the trash aren't counted.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION count_user_resources()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+DECLARE
+    delta INT := 0;
+    owner UUID;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        IF NEW.deleted_at IS NULL THEN
+            delta := 1;
+        END IF;
+        owner := NEW.user_id;
+    ELSIF TG_OP = 'DELETE' THEN
+        IF OLD.deleted_at IS NULL THEN
+            delta := -1;
+        END IF;
+        owner := OLD.user_id;
+    ELSE
+        IF OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN
+            delta := -1;
+        ELSIF OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN
+            delta := 1;
+        END IF;
+        owner := NEW.user_id;
+    END IF;
+
+    IF delta <> 0 THEN
+        EXECUTE format('UPDATE users SET %1$I = GREATEST(%1$I + $1, 0) WHERE user_id = $2', TG_ARGV[0])
+        USING delta, owner;
+    END IF;
+
+    RETURN NULL;
+END;
+$$;
+-- +goose StatementEnd
+
+CREATE TRIGGER contacts_count_user_resources
+    AFTER INSERT OR DELETE OR UPDATE OF deleted_at
+    ON contacts
+    FOR EACH ROW EXECUTE FUNCTION count_user_resources('contact_count');
+
+CREATE TRIGGER projects_count_user_resources
+    AFTER INSERT OR DELETE OR UPDATE OF deleted_at
+    ON projects
+    FOR EACH ROW EXECUTE FUNCTION count_user_resources('project_count');
+
+CREATE TRIGGER wallets_count_user_resources
+    AFTER INSERT OR DELETE OR UPDATE OF deleted_at
+    ON wallets
+    FOR EACH ROW EXECUTE FUNCTION count_user_resources('wallet_count');
+
+-- +goose Down
+DROP TRIGGER IF EXISTS wallets_count_user_resources ON wallets;
+DROP TRIGGER IF EXISTS projects_count_user_resources ON projects;
+DROP TRIGGER IF EXISTS contacts_count_user_resources ON contacts;
+DROP FUNCTION IF EXISTS count_user_resources();
+ALTER TABLE users
+    DROP COLUMN IF EXISTS wallet_count,
+    DROP COLUMN IF EXISTS project_count,
+    DROP COLUMN IF EXISTS contact_count;
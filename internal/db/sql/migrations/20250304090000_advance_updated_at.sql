@@ -0,0 +1,39 @@
+-- +goose Up
+-- advance_updated_at makes every update setting updated_at move it strictly forward.
+-- CURRENT_TIMESTAMP is the start of the transaction, so an update in the transaction
+-- that created or last updated the row, or two updates within a microsecond, would
+-- otherwise leave updated_at where it was and with it the ETag clients compare.
+-- Updates that leave updated_at out of their SET list, like pins and the move to the
+-- trash, aren't edits and don't fire it.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION advance_updated_at()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    NEW.updated_at := GREATEST(clock_timestamp()::timestamp, OLD.updated_at + INTERVAL '1 microsecond');
+    RETURN NEW;
+END;
+$$;
+-- +goose StatementEnd
+
+CREATE TRIGGER contacts_advance_updated_at
+    BEFORE UPDATE OF updated_at
+    ON contacts
+    FOR EACH ROW EXECUTE FUNCTION advance_updated_at();
+
+CREATE TRIGGER projects_advance_updated_at
+    BEFORE UPDATE OF updated_at
+    ON projects
+    FOR EACH ROW EXECUTE FUNCTION advance_updated_at();
+
+CREATE TRIGGER wallets_advance_updated_at
+    BEFORE UPDATE OF updated_at
+    ON wallets
+    FOR EACH ROW EXECUTE FUNCTION advance_updated_at();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS wallets_advance_updated_at ON wallets;
+DROP TRIGGER IF EXISTS projects_advance_updated_at ON projects;
+DROP TRIGGER IF EXISTS contacts_advance_updated_at ON contacts;
+DROP FUNCTION IF EXISTS advance_updated_at();
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Cities and states are stored trimmed with their whitespace collapsed, so the list
+-- filters match them exactly ignoring case. Blank ones become NULL like blank companies.
+UPDATE contacts
+SET city = NULLIF(regexp_replace(btrim(city), '\s+', ' ', 'g'), ''),
+    state_province = NULLIF(regexp_replace(btrim(state_province), '\s+', ' ', 'g'), '')
+WHERE city IS NOT NULL OR state_province IS NOT NULL;
+
+CREATE INDEX idx_contacts_user_city ON contacts (user_id, lower(city)) WHERE deleted_at IS NULL;
+CREATE INDEX idx_contacts_user_state_province ON contacts (user_id, lower(state_province)) WHERE deleted_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_contacts_user_state_province;
+DROP INDEX IF EXISTS idx_contacts_user_city;
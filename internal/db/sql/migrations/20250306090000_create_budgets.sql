@@ -0,0 +1,38 @@
+-- +goose Up
+-- A budget caps what the user spends on a project or a tag every month, quarter or year.
+-- Periods recur from start_date without end, so two budgets of a scope with the same
+-- period would always overlap and only one is allowed.
+CREATE TABLE "budgets" (
+    budget_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    -- the scope, exactly one of project_id and tag_id is set
+    project_id UUID REFERENCES projects(project_id) ON DELETE CASCADE,
+    tag_id UUID REFERENCES tags(tag_id) ON DELETE CASCADE,
+    amount DECIMAL(11,3) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    period VARCHAR(20) NOT NULL,
+    start_date DATE NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID REFERENCES users(user_id) ON DELETE SET NULL,
+    updated_by UUID REFERENCES users(user_id) ON DELETE SET NULL,
+    CONSTRAINT budgets_scope_check CHECK ((project_id IS NULL) <> (tag_id IS NULL)),
+    CONSTRAINT budgets_amount_check CHECK (amount > 0),
+    CONSTRAINT budgets_period_check CHECK (period IN ('monthly', 'quarterly', 'yearly'))
+);
+
+CREATE INDEX idx_budgets_user_id ON budgets(user_id);
+CREATE UNIQUE INDEX budgets_user_project_period_idx ON budgets(user_id, project_id, period) WHERE project_id IS NOT NULL;
+CREATE UNIQUE INDEX budgets_user_tag_period_idx ON budgets(user_id, tag_id, period) WHERE tag_id IS NOT NULL;
+
+CREATE TRIGGER budgets_advance_updated_at
+    BEFORE UPDATE OF updated_at
+    ON budgets
+    FOR EACH ROW EXECUTE FUNCTION advance_updated_at();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS budgets_advance_updated_at ON budgets;
+DROP INDEX IF EXISTS budgets_user_tag_period_idx;
+DROP INDEX IF EXISTS budgets_user_project_period_idx;
+DROP INDEX IF EXISTS idx_budgets_user_id;
+DROP TABLE IF EXISTS "budgets";
@@ -0,0 +1,65 @@
+-- +goose Up
+-- merge_audit keeps a row per merge of two contacts or wallets with both rows as they
+-- were before it, the dropped one is deleted by the merge and only lives on here
+CREATE TABLE merge_audit (
+    audit_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    entity_type VARCHAR(20) NOT NULL CHECK (entity_type IN ('contact', 'wallet')),
+    kept_id UUID NOT NULL,
+    dropped_id UUID NOT NULL,
+    kept_snapshot JSONB NOT NULL,
+    dropped_snapshot JSONB NOT NULL,
+    merged_by UUID,
+    merged_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX merge_audit_user_idx ON merge_audit(user_id, merged_at DESC);
+
+-- record_wallet_ledger skips the balance update of the wallet named by app.merging_wallet,
+-- a merge moves the ledger entries of the dropped wallet over before adding its balance
+-- so the entries already account for it
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION record_wallet_ledger()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        IF COALESCE(NEW.balance, 0) <> 0 THEN
+            INSERT INTO wallet_ledger_entries (wallet_id, amount, description, occurred_at)
+            VALUES (NEW.wallet_id, NEW.balance, 'Opening balance', COALESCE(NEW.created_at, CURRENT_TIMESTAMP));
+        END IF;
+    ELSIF NEW.wallet_id::text = COALESCE(current_setting('app.merging_wallet', true), '') THEN
+        RETURN NULL;
+    ELSIF COALESCE(NEW.balance, 0) <> COALESCE(OLD.balance, 0) THEN
+        INSERT INTO wallet_ledger_entries (wallet_id, amount, description, occurred_at)
+        VALUES (NEW.wallet_id, COALESCE(NEW.balance, 0) - COALESCE(OLD.balance, 0), 'Balance adjustment', COALESCE(NEW.updated_at, CURRENT_TIMESTAMP));
+    END IF;
+
+    RETURN NULL;
+END;
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION record_wallet_ledger()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        IF COALESCE(NEW.balance, 0) <> 0 THEN
+            INSERT INTO wallet_ledger_entries (wallet_id, amount, description, occurred_at)
+            VALUES (NEW.wallet_id, NEW.balance, 'Opening balance', COALESCE(NEW.created_at, CURRENT_TIMESTAMP));
+        END IF;
+    ELSIF COALESCE(NEW.balance, 0) <> COALESCE(OLD.balance, 0) THEN
+        INSERT INTO wallet_ledger_entries (wallet_id, amount, description, occurred_at)
+        VALUES (NEW.wallet_id, COALESCE(NEW.balance, 0) - COALESCE(OLD.balance, 0), 'Balance adjustment', COALESCE(NEW.updated_at, CURRENT_TIMESTAMP));
+    END IF;
+
+    RETURN NULL;
+END;
+$$;
+-- +goose StatementEnd
+
+DROP TABLE IF EXISTS merge_audit;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- Project start and end dates are calendar days. As timestamps, a date sent without a
+-- time became midnight UTC and showed as the day before west of UTC. The stored times
+-- are all UTC, so their UTC day is the one the client meant.
+ALTER TABLE projects
+    ALTER COLUMN start_date DROP DEFAULT,
+    ALTER COLUMN start_date TYPE DATE USING start_date::date,
+    ALTER COLUMN end_date TYPE DATE USING end_date::date;
+ALTER TABLE projects ALTER COLUMN start_date SET DEFAULT CURRENT_DATE;
+
+-- +goose Down
+ALTER TABLE projects
+    ALTER COLUMN start_date DROP DEFAULT,
+    ALTER COLUMN start_date TYPE TIMESTAMP USING start_date::timestamp,
+    ALTER COLUMN end_date TYPE TIMESTAMP USING end_date::timestamp;
+ALTER TABLE projects ALTER COLUMN start_date SET DEFAULT CURRENT_TIMESTAMP;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- A wallet stays live while its project is in the trash, so purging the project detaches
+-- the wallet instead of deleting it with its ledger.
+ALTER TABLE wallets
+    DROP CONSTRAINT wallets_project_id_fkey,
+    ADD CONSTRAINT wallets_project_id_fkey
+        FOREIGN KEY (project_id) REFERENCES projects(project_id) ON DELETE SET NULL;
+
+-- +goose Down
+ALTER TABLE wallets
+    DROP CONSTRAINT wallets_project_id_fkey,
+    ADD CONSTRAINT wallets_project_id_fkey
+        FOREIGN KEY (project_id) REFERENCES projects(project_id) ON DELETE CASCADE;
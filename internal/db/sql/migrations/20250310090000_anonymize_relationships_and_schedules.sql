@@ -0,0 +1,20 @@
+-- +goose Up
+-- Set when a schedule is switched off for good, disabled schedules are never claimed
+ALTER TABLE export_schedules ADD COLUMN disabled_at TIMESTAMP;
+
+-- Relationship notes are free text and schedule targets hold email addresses, both are
+-- PII the anonymization clears
+CREATE TRIGGER contact_relationships_block_anonymized_pii
+    BEFORE INSERT OR UPDATE OF note
+    ON contact_relationships
+    FOR EACH ROW EXECUTE FUNCTION block_anonymized_pii();
+
+CREATE TRIGGER export_schedules_block_anonymized_pii
+    BEFORE INSERT OR UPDATE OF delivery_target
+    ON export_schedules
+    FOR EACH ROW EXECUTE FUNCTION block_anonymized_pii();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS export_schedules_block_anonymized_pii ON export_schedules;
+DROP TRIGGER IF EXISTS contact_relationships_block_anonymized_pii ON contact_relationships;
+ALTER TABLE export_schedules DROP COLUMN IF EXISTS disabled_at;
@@ -0,0 +1,25 @@
+-- +goose Up
+-- forwarding addresses were claimed by users without proving they own them and matched
+-- against the From header, which anyone can forge. Inbound emails are matched on their
+-- envelope recipient instead, an address on the inbound domain whose local part is a
+-- random token the server hands out. The claimed addresses are dropped.
+DROP INDEX IF EXISTS users_forwarding_address_idx;
+ALTER TABLE users RENAME COLUMN forwarding_address TO inbound_token;
+UPDATE users SET inbound_token = NULL WHERE inbound_token IS NOT NULL;
+CREATE UNIQUE INDEX users_inbound_token_idx ON users (inbound_token)
+WHERE inbound_token IS NOT NULL;
+
+-- delivery_id is the provider's id of the delivery an entry was stored from, a replayed
+-- delivery collides with it instead of storing the receipt twice
+ALTER TABLE pending_entries ADD COLUMN delivery_id TEXT;
+CREATE UNIQUE INDEX pending_entries_delivery_idx ON pending_entries (user_id, delivery_id)
+WHERE delivery_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS pending_entries_delivery_idx;
+ALTER TABLE pending_entries DROP COLUMN IF EXISTS delivery_id;
+DROP INDEX IF EXISTS users_inbound_token_idx;
+UPDATE users SET inbound_token = NULL WHERE inbound_token IS NOT NULL;
+ALTER TABLE users RENAME COLUMN inbound_token TO forwarding_address;
+CREATE UNIQUE INDEX users_forwarding_address_idx ON users (lower(forwarding_address))
+WHERE forwarding_address IS NOT NULL;
@@ -0,0 +1,63 @@
+-- name: CountBackupRows :one
+-- the rows a full account backup archives, the same ones its sections stream
+SELECT
+    (SELECT COUNT(*) FROM contacts c WHERE c.user_id = sqlc.arg('user_id') AND c.deleted_at IS NULL) AS contacts,
+    (SELECT COUNT(*) FROM projects p WHERE p.user_id = sqlc.arg('user_id') AND p.deleted_at IS NULL) AS projects,
+    (SELECT COUNT(*) FROM wallets w WHERE w.user_id = sqlc.arg('user_id') AND w.deleted_at IS NULL) AS wallets,
+    (SELECT COUNT(*) FROM tags t WHERE t.user_id = sqlc.arg('user_id')) AS tags,
+    (SELECT COUNT(*)
+     FROM pending_entry_attachments a
+     JOIN pending_entries e ON e.entry_id = a.entry_id
+     WHERE e.user_id = sqlc.arg('user_id')) AS attachments,
+    (SELECT COUNT(*)
+     FROM wallet_ledger_entries l
+     JOIN wallets w ON w.wallet_id = l.wallet_id
+     WHERE w.user_id = sqlc.arg('user_id') AND w.deleted_at IS NULL) AS ledger_entries;
+
+-- name: ListBackupAttachments :many
+-- the attachments of the user's pending entries after the after_id cursor, without their
+-- contents
+SELECT a.attachment_id, a.entry_id, a.filename, a.content_type, a.size_bytes
+FROM pending_entry_attachments a
+JOIN pending_entries e ON e.entry_id = a.entry_id
+WHERE e.user_id = sqlc.arg('user_id')
+  AND a.attachment_id > sqlc.arg('after_id')
+ORDER BY a.attachment_id
+LIMIT sqlc.arg('limit');
+
+-- name: ListBackupLedgerEntries :many
+-- the ledger entries of the user's active wallets after the after_seq cursor, in the order
+-- they were recorded. seq starts at 1 so a zero after_seq starts at the first entry.
+SELECT l.entry_id, l.wallet_id, l.seq, l.amount, l.description, l.occurred_at
+FROM wallet_ledger_entries l
+JOIN wallets w ON w.wallet_id = l.wallet_id
+WHERE w.user_id = sqlc.arg('user_id')
+  AND w.deleted_at IS NULL
+  AND l.seq > sqlc.arg('after_seq')
+ORDER BY l.seq
+LIMIT sqlc.arg('limit');
+
+-- name: ListExpiredBackups :many
+-- at most batch_size completed backups past their retention, oldest first, with the key
+-- of their archive
+SELECT job_id, result_key
+FROM "jobs"
+WHERE type = 'account_backup'
+  AND status = 'completed'
+  AND completed_at < sqlc.arg('completed_before')
+ORDER BY completed_at
+LIMIT sqlc.arg('batch_size');
+
+-- name: DeleteJobs :execrows
+DELETE FROM "jobs"
+WHERE job_id = ANY(sqlc.arg('job_ids')::uuid[]);
+
+-- name: ListUserArchives :many
+-- at most batch_size of the user's jobs with a stored export or backup archive, with
+-- the key of their archive
+SELECT job_id, result_key
+FROM "jobs"
+WHERE user_id = sqlc.arg('user_id')
+  AND result_key IS NOT NULL
+ORDER BY job_id
+LIMIT sqlc.arg('batch_size');
@@ -0,0 +1,96 @@
+-- name: ListBudgets :many
+SELECT * FROM budgets
+WHERE user_id = $1
+ORDER BY created_at, budget_id;
+
+-- name: GetBudget :one
+SELECT * FROM budgets
+WHERE budget_id = $1 AND user_id = $2 LIMIT 1;
+
+-- name: BudgetScopeExists :one
+-- whether the project, or the tag, a budget is scoped to belongs to the user
+SELECT (EXISTS (
+    SELECT 1 FROM projects p
+    WHERE p.project_id = sqlc.narg('project_id') AND p.user_id = sqlc.arg('user_id') AND p.deleted_at IS NULL
+) OR EXISTS (
+    SELECT 1 FROM tags t
+    WHERE t.tag_id = sqlc.narg('tag_id') AND t.user_id = sqlc.arg('user_id')
+))::boolean AS owned;
+
+-- name: FindOverlappingBudget :one
+-- another budget of the user with the same scope and period, periods recur without end
+-- so it overlaps whatever the start dates
+SELECT budget_id FROM budgets
+WHERE user_id = sqlc.arg('user_id')
+  AND period = sqlc.arg('period')
+  AND project_id IS NOT DISTINCT FROM sqlc.narg('project_id')
+  AND tag_id IS NOT DISTINCT FROM sqlc.narg('tag_id')
+  AND budget_id <> sqlc.arg('exclude_id')
+LIMIT 1;
+
+-- name: CreateBudget :one
+INSERT INTO budgets (
+    user_id,
+    project_id,
+    tag_id,
+    amount,
+    currency,
+    period,
+    start_date,
+    created_by,
+    updated_by
+) VALUES (
+    sqlc.arg('user_id'),
+    sqlc.narg('project_id'),
+    sqlc.narg('tag_id'),
+    sqlc.arg('amount'),
+    sqlc.arg('currency'),
+    sqlc.arg('period'),
+    sqlc.arg('start_date'),
+    sqlc.arg('actor_id')::uuid,
+    sqlc.arg('actor_id')::uuid
+)
+RETURNING *;
+
+-- name: UpdateBudget :one
+UPDATE budgets
+SET
+    project_id = sqlc.narg('project_id'),
+    tag_id = sqlc.narg('tag_id'),
+    amount = sqlc.arg('amount'),
+    currency = sqlc.arg('currency'),
+    period = sqlc.arg('period'),
+    start_date = sqlc.arg('start_date'),
+    updated_at = CURRENT_TIMESTAMP,
+    updated_by = sqlc.arg('actor_id')::uuid
+WHERE budget_id = sqlc.arg('budget_id') AND user_id = sqlc.arg('user_id')
+RETURNING *;
+
+-- name: DeleteBudget :execrows
+DELETE FROM budgets
+WHERE budget_id = $1 AND user_id = $2;
+
+-- name: GetBudgetSpend :one
+-- the money that left the budget's wallets between window_start and window_end, the
+-- end excluded. Its wallets are the user's wallets in the budget's currency that belong
+-- to its project or carry its tag, trashed wallets don't count.
+WITH spend AS (
+    SELECT COALESCE(SUM(-e.amount), 0)::numeric AS spent
+    FROM budgets b
+    JOIN wallets w ON w.user_id = b.user_id
+        AND w.currency = b.currency
+        AND w.deleted_at IS NULL
+        AND (w.project_id = b.project_id OR b.tag_id = ANY(w.tags))
+    JOIN wallet_ledger_entries e ON e.wallet_id = w.wallet_id
+    WHERE b.budget_id = sqlc.arg('budget_id')
+      AND b.user_id = sqlc.arg('user_id')
+      AND e.amount < 0
+      AND e.occurred_at >= sqlc.arg('window_start')::date
+      AND e.occurred_at < sqlc.arg('window_end')::date
+)
+SELECT
+    spend.spent,
+    (b.amount - spend.spent)::numeric AS remaining,
+    ROUND(spend.spent * 100 / b.amount, 2)::numeric AS percentage
+FROM budgets b, spend
+WHERE b.budget_id = sqlc.arg('budget_id') AND b.user_id = sqlc.arg('user_id');
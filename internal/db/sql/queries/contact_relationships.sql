@@ -0,0 +1,44 @@
+-- name: CreateContactRelationship :one
+-- both contacts have to belong to the user and be out of the trash, no row is inserted otherwise
+WITH inserted AS (
+    INSERT INTO contact_relationships (user_id, from_contact_id, to_contact_id, type, note)
+    SELECT f.user_id, f.contact_id, t.contact_id, sqlc.arg('type'), sqlc.narg('note')
+    FROM contacts f
+    JOIN contacts t ON t.user_id = f.user_id
+    WHERE f.contact_id = sqlc.arg('from_contact_id')
+      AND f.user_id = sqlc.arg('user_id')
+      AND f.deleted_at IS NULL
+      AND t.contact_id = sqlc.arg('to_contact_id')
+      AND t.deleted_at IS NULL
+    RETURNING *
+)
+SELECT i.relationship_id, i.type, i.note, i.created_at, i.to_contact_id AS contact_id, t.name AS contact_name
+FROM inserted i
+JOIN contacts t ON t.contact_id = i.to_contact_id;
+
+-- name: ListContactRelationships :many
+-- the relationships of the contact both ways, outgoing is false for those naming it as their to_contact.
+-- Each half is resolved through its own index.
+SELECT r.relationship_id, r.type, r.note, r.created_at, TRUE AS outgoing, c.contact_id, c.name AS contact_name
+FROM contact_relationships r
+JOIN contacts c ON c.contact_id = r.to_contact_id
+WHERE r.from_contact_id = sqlc.arg('contact_id') AND r.user_id = sqlc.arg('user_id') AND c.deleted_at IS NULL
+UNION ALL
+SELECT r.relationship_id, r.type, r.note, r.created_at, FALSE AS outgoing, c.contact_id, c.name AS contact_name
+FROM contact_relationships r
+JOIN contacts c ON c.contact_id = r.from_contact_id
+WHERE r.to_contact_id = sqlc.arg('contact_id') AND r.user_id = sqlc.arg('user_id') AND c.deleted_at IS NULL
+ORDER BY created_at, relationship_id;
+
+-- name: DeleteContactRelationship :execrows
+-- the relationship can be deleted through either of its contacts
+DELETE FROM contact_relationships
+WHERE relationship_id = sqlc.arg('relationship_id')
+  AND user_id = sqlc.arg('user_id')
+  AND (from_contact_id = sqlc.arg('contact_id') OR to_contact_id = sqlc.arg('contact_id'));
+
+-- name: ClearRelationshipNotes :execrows
+-- the notes are free text about the contacts
+UPDATE contact_relationships
+SET note = NULL
+WHERE user_id = $1 AND note IS NOT NULL;
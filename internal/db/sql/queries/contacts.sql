@@ -1,10 +1,10 @@
 -- name: GetContact :one
 SELECT * FROM contacts
-WHERE contact_id = $1 AND user_id = $2 LIMIT 1;
+WHERE contact_id = $1 AND user_id = $2 AND deleted_at IS NULL LIMIT 1;
 
 -- name: ListContacts :many
 SELECT * FROM contacts
-WHERE user_id = $1
+WHERE user_id = $1 AND deleted_at IS NULL
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3;
 
@@ -20,12 +20,117 @@ INSERT INTO contacts (
     city,
     state_province,
     zip_postal_code,
-    tags
+    tags,
+    company,
+    notes,
+    links,
+    created_by,
+    updated_by
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+    sqlc.arg('user_id'),
+    sqlc.arg('name'),
+    sqlc.arg('phone'),
+    sqlc.arg('email'),
+    sqlc.arg('address_line1'),
+    sqlc.arg('address_line2'),
+    sqlc.arg('country'),
+    sqlc.arg('city'),
+    sqlc.arg('state_province'),
+    sqlc.arg('zip_postal_code'),
+    owned_tags(sqlc.arg('user_id'), sqlc.narg('tags')::uuid[]),
+    sqlc.arg('company'),
+    sqlc.arg('notes'),
+    sqlc.arg('links'),
+    sqlc.arg('actor_id')::uuid,
+    sqlc.arg('actor_id')::uuid
 )
 RETURNING *;
 
+-- name: UpsertContactByExternalRef :one
+-- creates the contact of the external reference or updates the fields the sync sent,
+-- NULL args leave a field alone. A trashed contact is restored since its source still
+-- has it. inserted tells a create from an update: xmax is 0 on freshly inserted rows.
+-- Postgres checks NOT NULL before looking for a conflict, so name is always required
+-- here and UpdateContactByExternalRef covers syncs without one.
+INSERT INTO contacts AS c (
+    user_id,
+    external_source,
+    external_id,
+    name,
+    phone,
+    email,
+    address_line1,
+    address_line2,
+    country,
+    city,
+    state_province,
+    zip_postal_code,
+    tags,
+    company,
+    notes,
+    created_by,
+    updated_by
+) VALUES (
+    sqlc.arg('user_id'),
+    sqlc.arg('external_source'),
+    sqlc.arg('external_id'),
+    sqlc.arg('name'),
+    sqlc.narg('phone'),
+    sqlc.narg('email'),
+    sqlc.narg('address_line1'),
+    sqlc.narg('address_line2'),
+    sqlc.narg('country'),
+    sqlc.narg('city'),
+    sqlc.narg('state_province'),
+    sqlc.narg('zip_postal_code'),
+    owned_tags(sqlc.arg('user_id'), sqlc.narg('tags')::uuid[]),
+    sqlc.narg('company'),
+    sqlc.narg('notes'),
+    sqlc.arg('actor_id')::uuid,
+    sqlc.arg('actor_id')::uuid
+)
+ON CONFLICT (user_id, external_source, external_id) DO UPDATE
+SET
+    name = EXCLUDED.name,
+    phone = COALESCE(EXCLUDED.phone, c.phone),
+    email = COALESCE(EXCLUDED.email, c.email),
+    address_line1 = COALESCE(EXCLUDED.address_line1, c.address_line1),
+    address_line2 = COALESCE(EXCLUDED.address_line2, c.address_line2),
+    country = COALESCE(EXCLUDED.country, c.country),
+    city = COALESCE(EXCLUDED.city, c.city),
+    state_province = COALESCE(EXCLUDED.state_province, c.state_province),
+    zip_postal_code = COALESCE(EXCLUDED.zip_postal_code, c.zip_postal_code),
+    tags = CASE WHEN sqlc.narg('tags')::uuid[] IS NULL THEN c.tags ELSE EXCLUDED.tags END,
+    company = COALESCE(EXCLUDED.company, c.company),
+    notes = COALESCE(EXCLUDED.notes, c.notes),
+    deleted_at = NULL,
+    updated_at = CURRENT_TIMESTAMP,
+    updated_by = EXCLUDED.updated_by
+RETURNING sqlc.embed(c), (c.xmax = 0)::boolean AS inserted;
+
+-- name: UpdateContactByExternalRef :one
+-- UpsertContactByExternalRef without a name, which can only update
+UPDATE contacts AS c
+SET
+    phone = COALESCE(sqlc.narg('phone'), c.phone),
+    email = COALESCE(sqlc.narg('email'), c.email),
+    address_line1 = COALESCE(sqlc.narg('address_line1'), c.address_line1),
+    address_line2 = COALESCE(sqlc.narg('address_line2'), c.address_line2),
+    country = COALESCE(sqlc.narg('country'), c.country),
+    city = COALESCE(sqlc.narg('city'), c.city),
+    state_province = COALESCE(sqlc.narg('state_province'), c.state_province),
+    zip_postal_code = COALESCE(sqlc.narg('zip_postal_code'), c.zip_postal_code),
+    tags = CASE WHEN sqlc.narg('tags')::uuid[] IS NULL THEN c.tags ELSE owned_tags(sqlc.arg('user_id'), sqlc.narg('tags')::uuid[]) END,
+    company = COALESCE(sqlc.narg('company'), c.company),
+    notes = COALESCE(sqlc.narg('notes'), c.notes),
+    deleted_at = NULL,
+    updated_at = CURRENT_TIMESTAMP,
+    updated_by = sqlc.arg('actor_id')::uuid
+WHERE c.user_id = sqlc.arg('user_id')
+  AND c.external_source = sqlc.arg('external_source')
+  AND c.external_id = sqlc.arg('external_id')
+RETURNING c.*;
+
 -- name: UpdateContact :one
 UPDATE contacts
 SET 
@@ -38,42 +143,132 @@ SET
     city = sqlc.narg('city'),
     state_province = sqlc.narg('state_province'),
     zip_postal_code = sqlc.narg('zip_postal_code'),
-    tags = sqlc.narg('tags'),
-    updated_at = CURRENT_TIMESTAMP
-WHERE contact_id = sqlc.arg('contact_id') AND user_id = sqlc.arg('user_id')
+    tags = owned_tags(sqlc.arg('user_id'), sqlc.narg('tags')::uuid[]),
+    company = sqlc.narg('company'),
+    notes = sqlc.narg('notes'),
+    links = sqlc.arg('links'),
+    updated_at = CURRENT_TIMESTAMP,
+    updated_by = sqlc.arg('actor_id')::uuid
+WHERE contact_id = sqlc.arg('contact_id') AND user_id = sqlc.arg('user_id') AND deleted_at IS NULL
 RETURNING *;
 
--- name: DeleteContact :exec
-DELETE FROM contacts
-WHERE contact_id = $1 AND user_id = $2;
+-- name: DeleteContact :execrows
+-- a version set only trashes the contact while it is still at that updated_at
+UPDATE contacts
+SET deleted_at = CURRENT_TIMESTAMP
+WHERE contact_id = sqlc.arg('contact_id') AND user_id = sqlc.arg('user_id') AND deleted_at IS NULL
+  AND (sqlc.narg('version')::timestamp IS NULL OR updated_at = sqlc.narg('version'));
 
 -- name: ListContactsPaginated :many
-SELECT * 
+-- city and state_province, when set, match the normalized values stored ignoring case.
+SELECT *
 FROM contacts
-WHERE user_id = $1 
-  AND (created_at < $2 OR (created_at = $2 AND contact_id < $3))
-ORDER BY created_at DESC, contact_id DESC
-LIMIT $4;
+WHERE user_id = sqlc.arg('user_id')
+  AND deleted_at IS NULL
+  AND (sqlc.narg('city')::text IS NULL OR lower(city) = lower(sqlc.narg('city')::text))
+  AND (sqlc.narg('state_province')::text IS NULL OR lower(state_province) = lower(sqlc.narg('state_province')::text))
+  AND (
+      (sqlc.arg('sort_order')::text = 'asc'
+          AND (created_at > sqlc.arg('created_at') OR (created_at = sqlc.arg('created_at') AND contact_id > sqlc.arg('contact_id'))))
+      OR (sqlc.arg('sort_order')::text <> 'asc'
+          AND (created_at < sqlc.arg('created_at') OR (created_at = sqlc.arg('created_at') AND contact_id < sqlc.arg('contact_id'))))
+  )
+ORDER BY
+    CASE WHEN sqlc.arg('sort_order')::text = 'asc' THEN created_at END ASC,
+    CASE WHEN sqlc.arg('sort_order')::text = 'asc' THEN contact_id END ASC,
+    CASE WHEN sqlc.arg('sort_order')::text <> 'asc' THEN created_at END DESC,
+    CASE WHEN sqlc.arg('sort_order')::text <> 'asc' THEN contact_id END DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListContactIDsPaginated :many
+-- ListContactsPaginated selecting only the keys the list is paged by
+SELECT contact_id, created_at
+FROM contacts
+WHERE user_id = sqlc.arg('user_id')
+  AND deleted_at IS NULL
+  AND (sqlc.narg('city')::text IS NULL OR lower(city) = lower(sqlc.narg('city')::text))
+  AND (sqlc.narg('state_province')::text IS NULL OR lower(state_province) = lower(sqlc.narg('state_province')::text))
+  AND (
+      (sqlc.arg('sort_order')::text = 'asc'
+          AND (created_at > sqlc.arg('created_at') OR (created_at = sqlc.arg('created_at') AND contact_id > sqlc.arg('contact_id'))))
+      OR (sqlc.arg('sort_order')::text <> 'asc'
+          AND (created_at < sqlc.arg('created_at') OR (created_at = sqlc.arg('created_at') AND contact_id < sqlc.arg('contact_id'))))
+  )
+ORDER BY
+    CASE WHEN sqlc.arg('sort_order')::text = 'asc' THEN created_at END ASC,
+    CASE WHEN sqlc.arg('sort_order')::text = 'asc' THEN contact_id END ASC,
+    CASE WHEN sqlc.arg('sort_order')::text <> 'asc' THEN created_at END DESC,
+    CASE WHEN sqlc.arg('sort_order')::text <> 'asc' THEN contact_id END DESC
+LIMIT sqlc.arg('limit');
 
 -- name: SearchContacts :many
-SELECT *
+-- score is how similar the closest of name and company is to the query from 0 to 1,
+-- meaningless without a query
+SELECT sqlc.embed(contacts),
+    (1 - LEAST(f_unaccent(name) <-> f_unaccent(sqlc.arg('name')), f_unaccent(company) <-> f_unaccent(sqlc.arg('name'))))::real AS score
 FROM contacts
 WHERE user_id = sqlc.arg('user_id')
+  AND deleted_at IS NULL
   AND (
       sqlc.arg('name')::text = ''  -- No filter applied if sqlc.arg('name') is empty
-      OR name ILIKE '%' || sqlc.arg('name') || '%'  -- Substring match
-      OR name <-> sqlc.arg('name') < 0.9  -- Trigram similarity with threshold high for low sim to be included
+      OR f_unaccent(name) ILIKE '%' || f_unaccent(sqlc.arg('name')) || '%'  -- Substring match, accents ignored
+      OR f_unaccent(name) <-> f_unaccent(sqlc.arg('name')) < 0.9  -- Trigram similarity with threshold high for low sim to be included
+      OR f_unaccent(company) ILIKE '%' || f_unaccent(sqlc.arg('name')) || '%'  -- Company is searchable as well
   )
 ORDER BY 
     CASE WHEN sqlc.arg('name') = '' THEN created_at END DESC,  -- If sqlc.arg('name') is empty, sort by created_at
-    CASE WHEN sqlc.arg('name') <> '' THEN name <-> sqlc.arg('name') END,  -- If sqlc.arg('name') is provided, sort by trigram similarity
-    length(name) ASC  -- Shorter names are preferred as tiebreaker
-LIMIT sqlc.arg('limit');
+    CASE WHEN sqlc.arg('name') <> '' THEN LEAST(f_unaccent(name) <-> f_unaccent(sqlc.arg('name')), f_unaccent(company) <-> f_unaccent(sqlc.arg('name'))) END,  -- If sqlc.arg('name') is provided, sort by the closest of name and company
+    length(name) ASC,  -- Shorter names are preferred as tiebreaker
+    created_at DESC, contact_id DESC  -- then the newest, so ties always resolve the same way
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: SearchContactsPicker :many
+-- SearchContacts selecting only the columns of the picker view
+SELECT contact_id, name, phone, email
+FROM contacts
+WHERE user_id = sqlc.arg('user_id')
+  AND deleted_at IS NULL
+  AND (
+      sqlc.arg('name')::text = ''  -- No filter applied if sqlc.arg('name') is empty
+      OR f_unaccent(name) ILIKE '%' || f_unaccent(sqlc.arg('name')) || '%'  -- Substring match, accents ignored
+      OR f_unaccent(name) <-> f_unaccent(sqlc.arg('name')) < 0.9  -- Trigram similarity with threshold high for low sim to be included
+      OR f_unaccent(company) ILIKE '%' || f_unaccent(sqlc.arg('name')) || '%'  -- Company is searchable as well
+  )
+ORDER BY 
+    CASE WHEN sqlc.arg('name') = '' THEN created_at END DESC,  -- If sqlc.arg('name') is empty, sort by created_at
+    CASE WHEN sqlc.arg('name') <> '' THEN LEAST(f_unaccent(name) <-> f_unaccent(sqlc.arg('name')), f_unaccent(company) <-> f_unaccent(sqlc.arg('name'))) END,  -- If sqlc.arg('name') is provided, sort by the closest of name and company
+    length(name) ASC,  -- Shorter names are preferred as tiebreaker
+    created_at DESC, contact_id DESC  -- then the newest, so ties always resolve the same way
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
 
 -- name: SearchContactsByPhone :many
 SELECT *
 FROM contacts
 WHERE user_id = sqlc.arg('user_id')
+  AND deleted_at IS NULL
+  AND (
+      sqlc.arg('phone')::text = ''  -- No filter applied if sqlc.arg('phone') is empty
+      OR phone LIKE sqlc.arg('phone') || '%'
+  )
+ORDER BY 
+    CASE WHEN sqlc.arg('phone') = '' THEN created_at END DESC,
+    CASE 
+        WHEN phone = sqlc.arg('phone') THEN 1  -- Exact match
+        WHEN phone LIKE sqlc.arg('phone') || '%' THEN 2  -- Starts with
+        ELSE 3  -- Contains
+    END,
+    created_at DESC, contact_id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: SearchContactsByPhonePicker :many
+-- SearchContactsByPhone selecting only the columns of the picker view
+SELECT contact_id, name, phone, email
+FROM contacts
+WHERE user_id = sqlc.arg('user_id')
+  AND deleted_at IS NULL
   AND (
       sqlc.arg('phone')::text = ''  -- No filter applied if sqlc.arg('phone') is empty
       OR phone LIKE sqlc.arg('phone') || '%'
@@ -85,5 +280,158 @@ ORDER BY
         WHEN phone LIKE sqlc.arg('phone') || '%' THEN 2  -- Starts with
         ELSE 3  -- Contains
     END,
-    created_at DESC
-LIMIT sqlc.arg('limit');
\ No newline at end of file
+    created_at DESC, contact_id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+-- name: SearchContactsByCompany :many
+-- score is how similar the company is to the query from 0 to 1
+SELECT sqlc.embed(contacts),
+    (1 - (f_unaccent(company) <-> f_unaccent(sqlc.arg('company')::text)))::real AS score
+FROM contacts
+WHERE user_id = sqlc.arg('user_id')
+  AND deleted_at IS NULL
+  AND company IS NOT NULL
+  AND (
+      f_unaccent(company) ILIKE '%' || f_unaccent(sqlc.arg('company')::text) || '%'
+      OR f_unaccent(company) <-> f_unaccent(sqlc.arg('company')::text) < 0.9
+  )
+ORDER BY 
+    f_unaccent(company) <-> f_unaccent(sqlc.arg('company')::text),
+    name ASC,
+    created_at DESC, contact_id DESC  -- ties always resolve the same way
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: SearchContactsByCompanyPicker :many
+-- SearchContactsByCompany selecting only the columns of the picker view
+SELECT contact_id, name, phone, email
+FROM contacts
+WHERE user_id = sqlc.arg('user_id')
+  AND deleted_at IS NULL
+  AND company IS NOT NULL
+  AND (
+      f_unaccent(company) ILIKE '%' || f_unaccent(sqlc.arg('company')::text) || '%'
+      OR f_unaccent(company) <-> f_unaccent(sqlc.arg('company')::text) < 0.9
+  )
+ORDER BY 
+    f_unaccent(company) <-> f_unaccent(sqlc.arg('company')::text),
+    name ASC,
+    created_at DESC, contact_id DESC  -- ties always resolve the same way
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: ListContactCompanies :many
+SELECT
+    g.company::text AS company,
+    g.contact_count,
+    c.contact_id,
+    c.name,
+    c.email,
+    c.phone
+FROM (
+    SELECT grouped.company, COUNT(*) AS contact_count
+    FROM contacts grouped
+    WHERE grouped.user_id = sqlc.arg('user_id') AND grouped.company IS NOT NULL AND grouped.deleted_at IS NULL
+    GROUP BY grouped.company
+    ORDER BY
+        CASE WHEN sqlc.arg('sort_by')::text = 'name' THEN grouped.company END ASC,
+        COUNT(*) DESC,
+        grouped.company ASC
+    LIMIT sqlc.arg('limit')
+) g
+CROSS JOIN LATERAL (
+    SELECT member.contact_id, member.name, member.email, member.phone
+    FROM contacts member
+    WHERE member.user_id = sqlc.arg('user_id') AND member.company = g.company AND member.deleted_at IS NULL
+    ORDER BY member.name ASC, member.contact_id ASC
+    LIMIT sqlc.arg('contacts_limit')::int
+) c
+ORDER BY
+    CASE WHEN sqlc.arg('sort_by')::text = 'name' THEN g.company END ASC,
+    g.contact_count DESC,
+    g.company ASC,
+    c.name ASC,
+    c.contact_id ASC;
+
+-- name: CountContacts :one
+SELECT COUNT(*) FROM contacts
+WHERE user_id = sqlc.arg('user_id') AND deleted_at IS NULL;
+
+-- name: ListContactFacets :many
+-- counts the distinct values of the facetable field of the user's contacts, most common first
+SELECT facet.value::text AS value, COUNT(*) AS count
+FROM (
+    SELECT CASE sqlc.arg('field')::text
+        WHEN 'country' THEN country
+        WHEN 'city' THEN city
+        WHEN 'company' THEN company
+    END AS value
+    FROM contacts
+    WHERE user_id = sqlc.arg('user_id') AND deleted_at IS NULL
+) facet
+WHERE facet.value IS NOT NULL
+GROUP BY facet.value
+ORDER BY count DESC, facet.value ASC;
+
+-- name: ListDeletedContactsPaginated :many
+SELECT *
+FROM contacts
+WHERE user_id = sqlc.arg('user_id')
+  AND deleted_at IS NOT NULL
+  AND (
+      (sqlc.arg('sort_order')::text = 'asc'
+          AND (deleted_at > sqlc.arg('deleted_at') OR (deleted_at = sqlc.arg('deleted_at') AND contact_id > sqlc.arg('contact_id'))))
+      OR (sqlc.arg('sort_order')::text <> 'asc'
+          AND (deleted_at < sqlc.arg('deleted_at') OR (deleted_at = sqlc.arg('deleted_at') AND contact_id < sqlc.arg('contact_id'))))
+  )
+ORDER BY
+    CASE WHEN sqlc.arg('sort_order')::text = 'asc' THEN deleted_at END ASC,
+    CASE WHEN sqlc.arg('sort_order')::text = 'asc' THEN contact_id END ASC,
+    CASE WHEN sqlc.arg('sort_order')::text <> 'asc' THEN deleted_at END DESC,
+    CASE WHEN sqlc.arg('sort_order')::text <> 'asc' THEN contact_id END DESC
+LIMIT sqlc.arg('limit');
+
+-- name: RestoreContact :one
+UPDATE contacts
+SET deleted_at = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE contact_id = sqlc.arg('contact_id') AND user_id = sqlc.arg('user_id') AND deleted_at IS NOT NULL
+RETURNING *;
+
+-- name: PurgeDeletedContacts :execrows
+-- at most batch_size rows per call, oldest first, skipping rows locked by a restore
+DELETE FROM contacts
+WHERE contact_id IN (
+    SELECT c.contact_id FROM contacts c
+    WHERE c.deleted_at IS NOT NULL AND c.deleted_at < sqlc.arg('deleted_before')
+    ORDER BY c.deleted_at
+    LIMIT sqlc.arg('batch_size')
+    FOR UPDATE SKIP LOCKED
+);
+
+-- name: ListContactsForAnonymization :many
+-- trashed contacts included, ordered by ID so batches resume after the last one
+SELECT * FROM contacts
+WHERE user_id = $1 AND contact_id > $2
+ORDER BY contact_id
+LIMIT $3;
+
+-- name: AnonymizeContact :exec
+-- timestamps are left alone so reporting on them still works
+UPDATE contacts
+SET
+    name = sqlc.arg('name'),
+    phone = sqlc.narg('phone'),
+    email = sqlc.narg('email'),
+    address_line1 = sqlc.narg('address_line1'),
+    address_line2 = sqlc.narg('address_line2'),
+    zip_postal_code = sqlc.narg('zip_postal_code'),
+    company = sqlc.narg('company'),
+    notes = sqlc.narg('notes'),
+    links = '[]'
+WHERE contact_id = sqlc.arg('contact_id');
+
+-- name: GetContactsByIDs :many
+-- in no particular order, the repository puts them in the order asked for
+SELECT * FROM contacts
+WHERE contact_id = ANY(sqlc.arg('contact_ids')::uuid[]) AND user_id = sqlc.arg('user_id') AND deleted_at IS NULL;
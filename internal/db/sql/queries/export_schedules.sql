@@ -0,0 +1,87 @@
+-- name: CreateExportSchedule :one
+INSERT INTO "export_schedules" (
+    user_id,
+    entity_type,
+    format,
+    cadence,
+    delivery_type,
+    delivery_target,
+    signing_secret,
+    next_run_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+)
+RETURNING *;
+
+-- name: GetExportSchedule :one
+SELECT * FROM "export_schedules"
+WHERE schedule_id = $1 AND user_id = $2
+LIMIT 1;
+
+-- name: ListExportSchedules :many
+SELECT * FROM "export_schedules"
+WHERE user_id = $1
+ORDER BY created_at, schedule_id;
+
+-- name: DeleteExportSchedule :execrows
+DELETE FROM "export_schedules"
+WHERE schedule_id = $1 AND user_id = $2;
+
+-- name: ClaimDueExportSchedule :one
+-- claims the earliest schedule due at now until claimed_until, skipping those another
+-- scheduler holds
+UPDATE "export_schedules"
+SET claimed_until = sqlc.arg('claimed_until')
+WHERE schedule_id = (
+    SELECT s.schedule_id FROM "export_schedules" s
+    WHERE s.next_run_at <= sqlc.arg('now')
+        AND s.disabled_at IS NULL
+        AND (s.claimed_until IS NULL OR s.claimed_until <= sqlc.arg('now'))
+    ORDER BY s.next_run_at, s.schedule_id
+    FOR UPDATE SKIP LOCKED
+    LIMIT 1
+)
+RETURNING *;
+
+-- name: RescheduleExportSchedule :exec
+-- releases the claim of a schedule once its run is recorded
+UPDATE "export_schedules"
+SET
+    next_run_at = $2,
+    claimed_until = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE schedule_id = $1;
+
+-- name: CreateExportRun :one
+INSERT INTO "export_runs" (
+    schedule_id,
+    status,
+    rows,
+    bytes,
+    error,
+    scheduled_for,
+    started_at,
+    finished_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+)
+RETURNING *;
+
+-- name: ListExportRuns :many
+-- the runs of a schedule of the user, newest first
+SELECT r.* FROM "export_runs" r
+JOIN "export_schedules" s ON s.schedule_id = r.schedule_id
+WHERE r.schedule_id = $1 AND s.user_id = $2
+ORDER BY r.started_at DESC, r.run_id DESC
+LIMIT $3;
+
+-- name: DisableExportSchedules :execrows
+-- switches the user's schedules off for good and clears their targets, which can be
+-- email addresses
+UPDATE "export_schedules"
+SET
+    disabled_at = CURRENT_TIMESTAMP,
+    delivery_target = '',
+    claimed_until = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1 AND disabled_at IS NULL;
@@ -0,0 +1,101 @@
+-- name: CreateJob :one
+INSERT INTO "jobs" (
+    user_id,
+    type,
+    total,
+    payload
+) VALUES (
+    $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetJob :one
+SELECT * FROM "jobs"
+WHERE job_id = $1 AND user_id = $2
+LIMIT 1;
+
+-- name: ClaimNextJob :one
+UPDATE "jobs"
+SET
+    status = 'running',
+    updated_at = CURRENT_TIMESTAMP
+WHERE job_id = (
+    SELECT job_id FROM "jobs"
+    WHERE status = 'pending'
+    ORDER BY created_at, job_id
+    FOR UPDATE SKIP LOCKED
+    LIMIT 1
+)
+RETURNING *;
+
+-- name: UpdateJobProgress :exec
+UPDATE "jobs"
+SET
+    processed = $2,
+    succeeded = $3,
+    failed = $4,
+    errors = $5,
+    updated_at = CURRENT_TIMESTAMP
+WHERE job_id = $1;
+
+-- name: CompleteJob :exec
+UPDATE "jobs"
+SET
+    status = 'completed',
+    updated_at = CURRENT_TIMESTAMP,
+    completed_at = CURRENT_TIMESTAMP
+WHERE job_id = $1;
+
+-- name: CompleteJobWithResult :exec
+-- completes a job that ran in one go, recording the rows it covered and the key of the
+-- file it produced
+UPDATE "jobs"
+SET
+    status = 'completed',
+    processed = sqlc.arg('rows'),
+    succeeded = sqlc.arg('rows'),
+    result_key = sqlc.arg('result_key'),
+    updated_at = CURRENT_TIMESTAMP,
+    completed_at = CURRENT_TIMESTAMP
+WHERE job_id = sqlc.arg('job_id');
+
+-- name: FailJob :exec
+UPDATE "jobs"
+SET
+    status = 'failed',
+    error = $2,
+    updated_at = CURRENT_TIMESTAMP,
+    completed_at = CURRENT_TIMESTAMP
+WHERE job_id = $1;
+
+-- name: RequeueJob :exec
+UPDATE "jobs"
+SET
+    status = 'pending',
+    updated_at = CURRENT_TIMESTAMP
+WHERE job_id = $1 AND status = 'running';
+
+-- name: HeartbeatJob :exec
+-- the runner of a job touches it while it runs, so other instances leave it alone
+UPDATE "jobs"
+SET updated_at = CURRENT_TIMESTAMP
+WHERE job_id = $1 AND status = 'running';
+
+-- name: RequeueStaleJobs :execrows
+-- running jobs without a heartbeat since stale_before were left by a stopped instance
+UPDATE "jobs"
+SET
+    status = 'pending',
+    updated_at = CURRENT_TIMESTAMP
+WHERE status = 'running' AND updated_at < sqlc.arg('stale_before');
+
+-- name: PurgeFinishedJobs :execrows
+-- at most batch_size completed or failed jobs per call, oldest first
+DELETE FROM "jobs"
+WHERE job_id IN (
+    SELECT j.job_id FROM "jobs" j
+    WHERE j.status IN ('completed', 'failed') AND j.completed_at < sqlc.arg('completed_before')
+    ORDER BY j.completed_at
+    LIMIT sqlc.arg('batch_size')
+    FOR UPDATE SKIP LOCKED
+);
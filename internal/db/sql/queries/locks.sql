@@ -0,0 +1,9 @@
+-- name: SetLockTimeout :exec
+-- bounds how long the rest of the transaction waits for locks, lock_timeout being a
+-- duration such as '2s'
+SELECT set_config('lock_timeout', @lock_timeout::text, true);
+
+-- name: LockEntity :exec
+-- takes the advisory lock of an entity until the transaction ends, writes reading and
+-- merging its current values take it so concurrent ones run one after the other
+SELECT pg_advisory_xact_lock(@lock_key::bigint);
@@ -0,0 +1,122 @@
+-- name: LockWalletsForMerge :many
+-- the user's live wallets among wallet_ids, locked until the merge commits
+SELECT * FROM wallets
+WHERE user_id = sqlc.arg('user_id')
+  AND wallet_id = ANY(sqlc.arg('wallet_ids')::uuid[])
+  AND deleted_at IS NULL
+ORDER BY wallet_id
+FOR UPDATE;
+
+-- name: AuditWalletMerge :exec
+INSERT INTO merge_audit (user_id, entity_type, kept_id, dropped_id, kept_snapshot, dropped_snapshot, merged_by)
+SELECT k.user_id, 'wallet', k.wallet_id, d.wallet_id, to_jsonb(k), to_jsonb(d), sqlc.arg('actor_id')::uuid
+FROM wallets k, wallets d
+WHERE k.wallet_id = sqlc.arg('kept_id') AND d.wallet_id = sqlc.arg('dropped_id');
+
+-- name: MoveLedgerEntries :execrows
+UPDATE wallet_ledger_entries
+SET wallet_id = sqlc.arg('kept_id')
+WHERE wallet_id = sqlc.arg('dropped_id');
+
+-- name: MoveDefaultWallet :exec
+UPDATE users
+SET default_wallet_id = sqlc.arg('kept_id')::uuid
+WHERE user_id = sqlc.arg('user_id') AND default_wallet_id = sqlc.arg('dropped_id')::uuid;
+
+-- name: SkipWalletLedger :exec
+-- the ledger trigger doesn't record the balance updates of the wallet until the transaction ends
+SELECT set_config('app.merging_wallet', sqlc.arg('wallet_id')::uuid::text, true);
+
+-- name: ApplyWalletMerge :one
+-- adds the dropped wallet's balance, a wallet without a balance keeps none if the other has none either
+UPDATE wallets
+SET
+    balance = CASE
+        WHEN balance IS NULL AND sqlc.narg('dropped_balance')::numeric IS NULL THEN NULL
+        ELSE COALESCE(balance, 0) + COALESCE(sqlc.narg('dropped_balance')::numeric, 0)
+    END,
+    project_id = sqlc.narg('project_id'),
+    group_id = sqlc.narg('group_id'),
+    low_balance_threshold = sqlc.narg('low_balance_threshold'),
+    tags = sqlc.arg('tags')::uuid[],
+    updated_at = CURRENT_TIMESTAMP,
+    updated_by = sqlc.arg('actor_id')::uuid
+WHERE wallet_id = sqlc.arg('wallet_id')
+RETURNING *;
+
+-- name: LockContactsForMerge :many
+-- the user's live contacts among contact_ids, locked until the merge commits
+SELECT * FROM contacts
+WHERE user_id = sqlc.arg('user_id')
+  AND contact_id = ANY(sqlc.arg('contact_ids')::uuid[])
+  AND deleted_at IS NULL
+ORDER BY contact_id
+FOR UPDATE;
+
+-- name: AuditContactMerge :exec
+-- the generated columns are left out of the snapshots
+INSERT INTO merge_audit (user_id, entity_type, kept_id, dropped_id, kept_snapshot, dropped_snapshot, merged_by)
+SELECT k.user_id, 'contact', k.contact_id, d.contact_id,
+    to_jsonb(k) - 'notes_search' - 'email_key',
+    to_jsonb(d) - 'notes_search' - 'email_key',
+    sqlc.arg('actor_id')::uuid
+FROM contacts k, contacts d
+WHERE k.contact_id = sqlc.arg('kept_id') AND d.contact_id = sqlc.arg('dropped_id');
+
+-- name: DropMergedRelationships :execrows
+-- the relationships between the two contacts, and those of the dropped contact the kept
+-- one already has, which moving them over would duplicate
+DELETE FROM contact_relationships r
+WHERE (r.from_contact_id = sqlc.arg('dropped_id') AND r.to_contact_id = sqlc.arg('kept_id'))
+   OR (r.from_contact_id = sqlc.arg('kept_id') AND r.to_contact_id = sqlc.arg('dropped_id'))
+   OR EXISTS (
+        SELECT 1 FROM contact_relationships k
+        WHERE k.type = r.type
+          AND k.relationship_id <> r.relationship_id
+          AND (
+              (r.from_contact_id = sqlc.arg('dropped_id') AND k.from_contact_id = sqlc.arg('kept_id') AND k.to_contact_id = r.to_contact_id)
+              OR (r.to_contact_id = sqlc.arg('dropped_id') AND k.to_contact_id = sqlc.arg('kept_id') AND k.from_contact_id = r.from_contact_id)
+              -- spouse_of and relative_of read the same both ways
+              OR (r.type IN ('spouse_of', 'relative_of') AND (
+                  (r.from_contact_id = sqlc.arg('dropped_id') AND k.to_contact_id = sqlc.arg('kept_id') AND k.from_contact_id = r.to_contact_id)
+                  OR (r.to_contact_id = sqlc.arg('dropped_id') AND k.from_contact_id = sqlc.arg('kept_id') AND k.to_contact_id = r.from_contact_id)
+              ))
+          )
+   );
+
+-- name: MoveContactRelationships :execrows
+UPDATE contact_relationships
+SET
+    from_contact_id = CASE WHEN from_contact_id = sqlc.arg('dropped_id') THEN sqlc.arg('kept_id') ELSE from_contact_id END,
+    to_contact_id = CASE WHEN to_contact_id = sqlc.arg('dropped_id') THEN sqlc.arg('kept_id') ELSE to_contact_id END
+WHERE from_contact_id = sqlc.arg('dropped_id') OR to_contact_id = sqlc.arg('dropped_id');
+
+-- name: PurgeMergedContact :exec
+DELETE FROM contacts
+WHERE contact_id = sqlc.arg('contact_id') AND user_id = sqlc.arg('user_id');
+
+-- name: ApplyContactMerge :one
+UPDATE contacts
+SET
+    phone = sqlc.narg('phone'),
+    email = sqlc.narg('email'),
+    address_line1 = sqlc.narg('address_line1'),
+    address_line2 = sqlc.narg('address_line2'),
+    country = sqlc.narg('country'),
+    city = sqlc.narg('city'),
+    state_province = sqlc.narg('state_province'),
+    zip_postal_code = sqlc.narg('zip_postal_code'),
+    company = sqlc.narg('company'),
+    notes = sqlc.narg('notes'),
+    links = sqlc.arg('links')::jsonb,
+    external_source = sqlc.narg('external_source'),
+    external_id = sqlc.narg('external_id'),
+    tags = sqlc.arg('tags')::uuid[],
+    updated_at = CURRENT_TIMESTAMP,
+    updated_by = sqlc.arg('actor_id')::uuid
+WHERE contact_id = sqlc.arg('contact_id')
+RETURNING *;
+
+-- name: DeleteMergeAudit :execrows
+-- the snapshots of merged contacts hold their PII
+DELETE FROM merge_audit WHERE user_id = $1;
@@ -0,0 +1,104 @@
+-- name: ListMilestones :many
+SELECT m.*
+FROM milestones m
+JOIN projects p ON p.project_id = m.project_id
+WHERE m.project_id = sqlc.arg('project_id')
+  AND p.user_id = sqlc.arg('user_id')
+  AND p.deleted_at IS NULL
+ORDER BY m.sort_order, m.created_at, m.milestone_id;
+
+-- name: GetMilestone :one
+SELECT m.*
+FROM milestones m
+JOIN projects p ON p.project_id = m.project_id
+WHERE m.milestone_id = sqlc.arg('milestone_id')
+  AND m.project_id = sqlc.arg('project_id')
+  AND p.user_id = sqlc.arg('user_id')
+  AND p.deleted_at IS NULL
+LIMIT 1;
+
+-- name: CountMilestones :one
+SELECT count(*) FROM milestones
+WHERE project_id = $1;
+
+-- name: CountProjectTreeMilestones :one
+-- the milestones of the project and, with rollup, of its live descendants
+WITH RECURSIVE tree AS (
+    SELECT p.project_id FROM projects p
+    WHERE p.project_id = sqlc.arg('project_id') AND p.user_id = sqlc.arg('user_id') AND p.deleted_at IS NULL
+    UNION
+    SELECT child.project_id FROM projects child
+    JOIN tree ON child.parent_project_id = tree.project_id
+    WHERE sqlc.arg('rollup')::bool AND child.deleted_at IS NULL
+)
+SELECT COUNT(*) FROM milestones m
+JOIN tree ON m.project_id = tree.project_id;
+
+-- name: CreateMilestone :one
+INSERT INTO milestones (
+    project_id,
+    name,
+    due_date,
+    completed_at,
+    sort_order
+)
+SELECT
+    p.project_id,
+    sqlc.arg('name')::text,
+    sqlc.narg('due_date')::timestamp,
+    CASE WHEN sqlc.arg('completed')::boolean THEN CURRENT_TIMESTAMP END,
+    COALESCE((SELECT MAX(sort_order) + 1 FROM milestones WHERE project_id = p.project_id), 0)
+FROM projects p
+WHERE p.project_id = sqlc.arg('project_id')
+  AND p.user_id = sqlc.arg('user_id')
+  AND p.deleted_at IS NULL
+RETURNING *;
+
+-- name: UpdateMilestone :one
+-- completed_at keeps its original time while the milestone stays completed
+UPDATE milestones m
+SET
+    name = sqlc.arg('name')::text,
+    due_date = sqlc.narg('due_date')::timestamp,
+    completed_at = CASE WHEN sqlc.arg('completed')::boolean THEN COALESCE(m.completed_at, CURRENT_TIMESTAMP) END,
+    updated_at = CURRENT_TIMESTAMP
+FROM projects p
+WHERE m.milestone_id = sqlc.arg('milestone_id')
+  AND m.project_id = sqlc.arg('project_id')
+  AND p.project_id = m.project_id
+  AND p.user_id = sqlc.arg('user_id')
+  AND p.deleted_at IS NULL
+RETURNING m.*;
+
+-- name: DeleteMilestone :execrows
+DELETE FROM milestones m
+USING projects p
+WHERE m.milestone_id = sqlc.arg('milestone_id')
+  AND m.project_id = sqlc.arg('project_id')
+  AND p.project_id = m.project_id
+  AND p.user_id = sqlc.arg('user_id')
+  AND p.deleted_at IS NULL;
+
+-- name: ReorderMilestones :execrows
+-- Positions follow the order of milestone_ids, nothing is updated unless the
+-- list covers every milestone of the project
+UPDATE milestones m
+SET sort_order = (o.position - 1)::int,
+    updated_at = CURRENT_TIMESTAMP
+FROM unnest(sqlc.arg('milestone_ids')::uuid[]) WITH ORDINALITY AS o(milestone_id, position),
+     projects p
+WHERE m.milestone_id = o.milestone_id
+  AND m.project_id = sqlc.arg('project_id')
+  AND p.project_id = m.project_id
+  AND p.user_id = sqlc.arg('user_id')
+  AND p.deleted_at IS NULL
+  AND (SELECT count(*) FROM milestones WHERE project_id = sqlc.arg('project_id')) = cardinality(sqlc.arg('milestone_ids')::uuid[]);
+
+-- name: GetMilestoneProgress :many
+SELECT
+    project_id,
+    count(*)::int AS total,
+    count(completed_at)::int AS completed
+FROM milestones
+WHERE project_id = ANY(sqlc.arg('project_ids')::uuid[])
+GROUP BY project_id;
@@ -0,0 +1,61 @@
+-- name: GetUserIDByInboundToken :one
+SELECT user_id FROM "users"
+WHERE inbound_token = sqlc.arg('token');
+
+-- name: CreatePendingEntry :one
+INSERT INTO pending_entries (
+    user_id,
+    sender,
+    subject,
+    body,
+    amount,
+    currency,
+    status,
+    raw,
+    delivery_id
+) VALUES (
+    sqlc.arg('user_id'),
+    sqlc.arg('sender'),
+    sqlc.arg('subject'),
+    sqlc.arg('body'),
+    sqlc.narg('amount'),
+    sqlc.narg('currency'),
+    sqlc.arg('status'),
+    sqlc.arg('raw'),
+    sqlc.narg('delivery_id')
+)
+RETURNING *;
+
+-- name: CreatePendingEntryAttachment :one
+INSERT INTO pending_entry_attachments (
+    entry_id,
+    filename,
+    content_type,
+    size_bytes,
+    content
+) VALUES (
+    sqlc.arg('entry_id'),
+    sqlc.arg('filename'),
+    sqlc.arg('content_type'),
+    sqlc.arg('size_bytes'),
+    sqlc.arg('content')
+)
+RETURNING attachment_id, entry_id, filename, content_type, size_bytes;
+
+-- name: ListPendingEntries :many
+SELECT * FROM pending_entries
+WHERE user_id = sqlc.arg('user_id')
+  AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status')::text)
+ORDER BY received_at DESC, entry_id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListPendingEntryAttachments :many
+-- the contents are left out, listings only describe the attachments
+SELECT attachment_id, entry_id, filename, content_type, size_bytes
+FROM pending_entry_attachments
+WHERE entry_id = ANY(sqlc.arg('entry_ids')::uuid[])
+ORDER BY entry_id, filename, attachment_id;
+
+-- name: DeletePendingEntries :execrows
+DELETE FROM pending_entries
+WHERE user_id = $1;
@@ -1,10 +1,16 @@
 -- name: GetProject :one
 SELECT * FROM projects
-WHERE project_id = $1 AND user_id = $2 LIMIT 1;
+WHERE project_id = $1 AND user_id = $2 AND deleted_at IS NULL LIMIT 1;
+
+-- name: ProjectExists :one
+SELECT EXISTS (
+    SELECT 1 FROM projects
+    WHERE project_id = $1 AND user_id = $2 AND deleted_at IS NULL
+);
 
 -- name: ListProjects :many
 SELECT * FROM projects
-WHERE user_id = $1
+WHERE user_id = $1 AND deleted_at IS NULL
 ORDER BY created_at DESC;
 
 -- name: CreateProject :one
@@ -24,9 +30,32 @@ INSERT INTO projects (
     state_province,
     zip_postal_code,
     website,
-    tags
+    tags,
+    parent_project_id,
+    is_draft,
+    created_by,
+    updated_by
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
+    sqlc.arg('user_id'),
+    sqlc.arg('name'),
+    sqlc.arg('description'),
+    sqlc.arg('status'),
+    sqlc.arg('start_date'),
+    sqlc.arg('end_date'),
+    sqlc.arg('budget'),
+    sqlc.arg('actual_cost'),
+    sqlc.arg('address_line1'),
+    sqlc.arg('address_line2'),
+    sqlc.arg('country'),
+    sqlc.arg('city'),
+    sqlc.arg('state_province'),
+    sqlc.arg('zip_postal_code'),
+    sqlc.arg('website'),
+    owned_tags(sqlc.arg('user_id'), sqlc.narg('tags')::uuid[]),
+    sqlc.narg('parent_project_id'),
+    sqlc.arg('is_draft'),
+    sqlc.arg('actor_id')::uuid,
+    sqlc.arg('actor_id')::uuid
 )
 RETURNING *;
 
@@ -46,34 +75,421 @@ SET
     state_province = sqlc.narg('state_province'),
     zip_postal_code = sqlc.narg('zip_postal_code'),
     website = sqlc.narg('website'),
-    tags = sqlc.narg('tags'),
-    updated_at = CURRENT_TIMESTAMP
+    tags = owned_tags(sqlc.arg('user_id'), sqlc.narg('tags')::uuid[]),
+    parent_project_id = sqlc.narg('parent_project_id'),
+    updated_at = CURRENT_TIMESTAMP,
+    updated_by = sqlc.arg('actor_id')::uuid
 WHERE 
     project_id = sqlc.arg('project_id')
     AND user_id = sqlc.arg('user_id')
+    AND deleted_at IS NULL
 RETURNING *;
 
--- name: DeleteProject :exec
-DELETE FROM projects
-WHERE project_id = $1 AND user_id = $2;
+-- name: DeleteProject :execrows
+-- a version set only trashes the project while it is still at that updated_at
+UPDATE projects
+SET deleted_at = CURRENT_TIMESTAMP,
+    pinned_at = NULL
+WHERE project_id = sqlc.arg('project_id') AND user_id = sqlc.arg('user_id') AND deleted_at IS NULL
+  AND (sqlc.narg('version')::timestamp IS NULL OR updated_at = sqlc.narg('version'));
+
+-- name: DeleteProjectTree :execrows
+-- trashes the project with all its live descendants, a version set only trashes them while the project is still at that updated_at
+WITH RECURSIVE target AS (
+    SELECT p.project_id FROM projects p
+    WHERE p.project_id = sqlc.arg('project_id') AND p.user_id = sqlc.arg('user_id') AND p.deleted_at IS NULL
+      AND (sqlc.narg('version')::timestamp IS NULL OR p.updated_at = sqlc.narg('version'))
+    FOR UPDATE
+), tree AS (
+    SELECT target.project_id FROM target
+    UNION
+    SELECT child.project_id FROM projects child
+    JOIN tree ON child.parent_project_id = tree.project_id
+    WHERE child.deleted_at IS NULL
+)
+UPDATE projects
+SET deleted_at = CURRENT_TIMESTAMP,
+    pinned_at = NULL
+WHERE projects.project_id IN (SELECT tree.project_id FROM tree);
+
+-- name: DeleteProjectDetachingChildren :execrows
+-- moves the children, trashed ones included, to the top level and trashes the project, a version set only does
+-- either while the project is still at that updated_at
+WITH target AS (
+    SELECT p.project_id FROM projects p
+    WHERE p.project_id = sqlc.arg('project_id') AND p.user_id = sqlc.arg('user_id') AND p.deleted_at IS NULL
+      AND (sqlc.narg('version')::timestamp IS NULL OR p.updated_at = sqlc.narg('version'))
+    FOR UPDATE
+), detached AS (
+    UPDATE projects
+    SET parent_project_id = NULL
+    WHERE projects.parent_project_id IN (SELECT target.project_id FROM target) AND projects.user_id = sqlc.arg('user_id')
+)
+UPDATE projects
+SET deleted_at = CURRENT_TIMESTAMP,
+    pinned_at = NULL
+WHERE projects.project_id IN (SELECT target.project_id FROM target);
+
+-- name: ListChildProjects :many
+SELECT * FROM projects
+WHERE parent_project_id = $1 AND user_id = $2 AND deleted_at IS NULL
+ORDER BY created_at, project_id;
+
+-- name: CountChildProjects :one
+SELECT COUNT(*) FROM projects
+WHERE parent_project_id = $1 AND user_id = $2 AND deleted_at IS NULL;
+
+-- name: ListProjectAncestors :many
+-- the project followed by its ancestors, nearest first. The depth guard ends the walk
+-- should racing updates ever write a cycle.
+WITH RECURSIVE chain AS (
+    SELECT p.project_id, p.parent_project_id, 1 AS depth FROM projects p
+    WHERE p.project_id = sqlc.arg('project_id') AND p.user_id = sqlc.arg('user_id') AND p.deleted_at IS NULL
+    UNION
+    SELECT parent.project_id, parent.parent_project_id, chain.depth + 1 FROM projects parent
+    JOIN chain ON parent.project_id = chain.parent_project_id
+    WHERE parent.user_id = sqlc.arg('user_id') AND parent.deleted_at IS NULL AND chain.depth < 16
+)
+SELECT chain.project_id FROM chain
+ORDER BY chain.depth;
+
+-- name: GetProjectSubtreeDepth :one
+-- levels of the project's live subtree, 1 for a project without children and 0 for
+-- a missing one, guarded like ListProjectAncestors
+WITH RECURSIVE tree AS (
+    SELECT p.project_id, 1 AS depth FROM projects p
+    WHERE p.project_id = sqlc.arg('project_id') AND p.user_id = sqlc.arg('user_id') AND p.deleted_at IS NULL
+    UNION
+    SELECT child.project_id, tree.depth + 1 FROM projects child
+    JOIN tree ON child.parent_project_id = tree.project_id
+    WHERE child.deleted_at IS NULL AND tree.depth < 16
+)
+SELECT COALESCE(MAX(depth), 0)::int FROM tree;
+
+-- name: GetProjectBudgetRollup :one
+-- the budget of the project and, with rollup, of its live descendants, drafts left out
+WITH RECURSIVE tree AS (
+    SELECT p.project_id, p.budget FROM projects p
+    WHERE p.project_id = sqlc.arg('project_id') AND p.user_id = sqlc.arg('user_id') AND p.deleted_at IS NULL
+    UNION
+    SELECT child.project_id, child.budget FROM projects child
+    JOIN tree ON child.parent_project_id = tree.project_id
+    WHERE sqlc.arg('rollup')::bool AND child.deleted_at IS NULL AND NOT child.is_draft
+)
+SELECT COUNT(*) AS projects, COALESCE(SUM(budget), 0)::float8 AS budget FROM tree;
+
+-- name: ListProjectWalletBalances :many
+-- the balances of the wallets in the project and, with rollup, in its live descendants
+-- that aren't drafts, per currency
+WITH RECURSIVE tree AS (
+    SELECT p.project_id FROM projects p
+    WHERE p.project_id = sqlc.arg('project_id') AND p.user_id = sqlc.arg('user_id') AND p.deleted_at IS NULL
+    UNION
+    SELECT child.project_id FROM projects child
+    JOIN tree ON child.parent_project_id = tree.project_id
+    WHERE sqlc.arg('rollup')::bool AND child.deleted_at IS NULL AND NOT child.is_draft
+)
+SELECT w.currency, COUNT(*) AS wallets, COALESCE(SUM(w.balance), 0)::float8 AS balance
+FROM wallets w
+JOIN tree ON w.project_id = tree.project_id
+WHERE w.user_id = sqlc.arg('user_id') AND w.deleted_at IS NULL
+GROUP BY w.currency
+ORDER BY w.currency;
 
 -- name: ListProjectsPaginated :many
+-- the unpinned projects, the pinned ones are listed before them by ListPinnedProjectsPaginated.
+-- Drafts are left out unless include_drafts is set.
+SELECT *
+FROM projects
+WHERE user_id = sqlc.arg('user_id')
+  AND deleted_at IS NULL
+  AND pinned_at IS NULL
+  AND (sqlc.arg('include_drafts')::bool OR NOT is_draft)
+  AND (
+      (sqlc.arg('sort_order')::text = 'asc'
+          AND (created_at > sqlc.arg('created_at') OR (created_at = sqlc.arg('created_at') AND project_id > sqlc.arg('project_id'))))
+      OR (sqlc.arg('sort_order')::text <> 'asc'
+          AND (created_at < sqlc.arg('created_at') OR (created_at = sqlc.arg('created_at') AND project_id < sqlc.arg('project_id'))))
+  )
+ORDER BY
+    CASE WHEN sqlc.arg('sort_order')::text = 'asc' THEN created_at END ASC,
+    CASE WHEN sqlc.arg('sort_order')::text = 'asc' THEN project_id END ASC,
+    CASE WHEN sqlc.arg('sort_order')::text <> 'asc' THEN created_at END DESC,
+    CASE WHEN sqlc.arg('sort_order')::text <> 'asc' THEN project_id END DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListProjectIDsPaginated :many
+-- ListProjectsPaginated selecting only the keys the list is paged by
+SELECT project_id, created_at
+FROM projects
+WHERE user_id = sqlc.arg('user_id')
+  AND deleted_at IS NULL
+  AND pinned_at IS NULL
+  AND (sqlc.arg('include_drafts')::bool OR NOT is_draft)
+  AND (
+      (sqlc.arg('sort_order')::text = 'asc'
+          AND (created_at > sqlc.arg('created_at') OR (created_at = sqlc.arg('created_at') AND project_id > sqlc.arg('project_id'))))
+      OR (sqlc.arg('sort_order')::text <> 'asc'
+          AND (created_at < sqlc.arg('created_at') OR (created_at = sqlc.arg('created_at') AND project_id < sqlc.arg('project_id'))))
+  )
+ORDER BY
+    CASE WHEN sqlc.arg('sort_order')::text = 'asc' THEN created_at END ASC,
+    CASE WHEN sqlc.arg('sort_order')::text = 'asc' THEN project_id END ASC,
+    CASE WHEN sqlc.arg('sort_order')::text <> 'asc' THEN created_at END DESC,
+    CASE WHEN sqlc.arg('sort_order')::text <> 'asc' THEN project_id END DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListPinnedProjectsPaginated :many
 SELECT *
 FROM projects
-WHERE user_id = $1 
-  AND (created_at < $2 OR (created_at = $2 AND project_id < $3))
-ORDER BY created_at DESC, project_id DESC
-LIMIT $4;
+WHERE user_id = sqlc.arg('user_id')
+  AND deleted_at IS NULL
+  AND pinned_at IS NOT NULL
+  AND (sqlc.arg('include_drafts')::bool OR NOT is_draft)
+  AND (pinned_at < sqlc.arg('pinned_at') OR (pinned_at = sqlc.arg('pinned_at') AND project_id < sqlc.arg('project_id')))
+ORDER BY pinned_at DESC, project_id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListPinnedProjectIDsPaginated :many
+-- ListPinnedProjectsPaginated selecting only the keys the list is paged by
+SELECT project_id, pinned_at
+FROM projects
+WHERE user_id = sqlc.arg('user_id')
+  AND deleted_at IS NULL
+  AND pinned_at IS NOT NULL
+  AND (sqlc.arg('include_drafts')::bool OR NOT is_draft)
+  AND (pinned_at < sqlc.arg('pinned_at') OR (pinned_at = sqlc.arg('pinned_at') AND project_id < sqlc.arg('project_id')))
+ORDER BY pinned_at DESC, project_id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: CountPinnedProjects :one
+SELECT COUNT(*) FROM projects
+WHERE user_id = $1 AND pinned_at IS NOT NULL AND deleted_at IS NULL;
+
+-- name: SetProjectPinned :one
+-- pinning a pinned project keeps its place, pins aren't edits so updated_at is left alone
+UPDATE projects
+SET pinned_at = CASE WHEN sqlc.arg('pinned')::bool THEN COALESCE(pinned_at, CURRENT_TIMESTAMP) END
+WHERE project_id = sqlc.arg('project_id') AND user_id = sqlc.arg('user_id') AND deleted_at IS NULL
+RETURNING *;
 
 -- name: SearchProjects :many
-SELECT * FROM projects
+-- score is how similar the name is to the query from 0 to 1, meaningless without a query
+SELECT sqlc.embed(projects),
+    (1 - (f_unaccent(name) <-> f_unaccent(sqlc.arg('name'))))::real AS score
+FROM projects
 WHERE user_id = sqlc.arg('user_id') 
+  AND deleted_at IS NULL
+  AND (sqlc.arg('include_drafts')::bool OR NOT is_draft)
   AND (sqlc.arg('name')::text = '' OR (
-    name <-> sqlc.arg('name') < 0.8 OR  
-    name ILIKE '%' || sqlc.arg('name') || '%'  
+    f_unaccent(name) <-> f_unaccent(sqlc.arg('name')) < 0.8 OR
+    f_unaccent(name) ILIKE '%' || f_unaccent(sqlc.arg('name')) || '%'  -- accents ignored
   ))
 ORDER BY 
     CASE WHEN sqlc.arg('name') = '' THEN created_at END DESC,  -- If sqlc.arg('name') is empty, sort by created_at
-    CASE WHEN sqlc.arg('name') <> '' THEN name <-> sqlc.arg('name') END,  -- If sqlc.arg('name') is provided, sort by trigram similarity
-    length(name) ASC  -- Shorter names are preferred as tiebreaker
-LIMIT sqlc.arg('limit');
\ No newline at end of file
+    CASE WHEN sqlc.arg('name') <> '' THEN f_unaccent(name) <-> f_unaccent(sqlc.arg('name')) END,  -- If sqlc.arg('name') is provided, sort by trigram similarity
+    length(name) ASC,  -- Shorter names are preferred as tiebreaker
+    created_at DESC, project_id DESC  -- then the newest, so ties always resolve the same way
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: SearchProjectsPicker :many
+-- SearchProjects selecting only the columns of the picker view
+SELECT project_id, name, status, end_date FROM projects
+WHERE user_id = sqlc.arg('user_id') 
+  AND deleted_at IS NULL
+  AND (sqlc.arg('include_drafts')::bool OR NOT is_draft)
+  AND (sqlc.arg('name')::text = '' OR (
+    f_unaccent(name) <-> f_unaccent(sqlc.arg('name')) < 0.8 OR
+    f_unaccent(name) ILIKE '%' || f_unaccent(sqlc.arg('name')) || '%'  -- accents ignored
+  ))
+ORDER BY 
+    CASE WHEN sqlc.arg('name') = '' THEN created_at END DESC,  -- If sqlc.arg('name') is empty, sort by created_at
+    CASE WHEN sqlc.arg('name') <> '' THEN f_unaccent(name) <-> f_unaccent(sqlc.arg('name')) END,  -- If sqlc.arg('name') is provided, sort by trigram similarity
+    length(name) ASC,  -- Shorter names are preferred as tiebreaker
+    created_at DESC, project_id DESC  -- then the newest, so ties always resolve the same way
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: ListDeletedProjectsPaginated :many
+SELECT *
+FROM projects
+WHERE user_id = sqlc.arg('user_id')
+  AND deleted_at IS NOT NULL
+  AND (
+      (sqlc.arg('sort_order')::text = 'asc'
+          AND (deleted_at > sqlc.arg('deleted_at') OR (deleted_at = sqlc.arg('deleted_at') AND project_id > sqlc.arg('project_id'))))
+      OR (sqlc.arg('sort_order')::text <> 'asc'
+          AND (deleted_at < sqlc.arg('deleted_at') OR (deleted_at = sqlc.arg('deleted_at') AND project_id < sqlc.arg('project_id'))))
+  )
+ORDER BY
+    CASE WHEN sqlc.arg('sort_order')::text = 'asc' THEN deleted_at END ASC,
+    CASE WHEN sqlc.arg('sort_order')::text = 'asc' THEN project_id END ASC,
+    CASE WHEN sqlc.arg('sort_order')::text <> 'asc' THEN deleted_at END DESC,
+    CASE WHEN sqlc.arg('sort_order')::text <> 'asc' THEN project_id END DESC
+LIMIT sqlc.arg('limit');
+
+-- name: RestoreProject :one
+-- a project whose parent is still in the trash comes back at the top level
+UPDATE projects
+SET deleted_at = NULL,
+    parent_project_id = (
+        SELECT parent.project_id FROM projects parent
+        WHERE parent.project_id = projects.parent_project_id AND parent.deleted_at IS NULL
+    ),
+    updated_at = CURRENT_TIMESTAMP
+WHERE projects.project_id = sqlc.arg('project_id') AND projects.user_id = sqlc.arg('user_id') AND projects.deleted_at IS NOT NULL
+RETURNING *;
+
+-- name: PurgeDeletedProjects :execrows
+-- at most batch_size rows per call, oldest first, skipping rows locked by a restore
+DELETE FROM projects
+WHERE project_id IN (
+    SELECT p.project_id FROM projects p
+    WHERE p.deleted_at IS NOT NULL AND p.deleted_at < sqlc.arg('deleted_before')
+    ORDER BY p.deleted_at
+    LIMIT sqlc.arg('batch_size')
+    FOR UPDATE SKIP LOCKED
+);
+
+-- name: ListProjectsForAnonymization :many
+-- trashed projects included, ordered by ID so batches resume after the last one
+SELECT * FROM projects
+WHERE user_id = $1 AND project_id > $2
+ORDER BY project_id
+LIMIT $3;
+
+-- name: AnonymizeProject :exec
+-- timestamps and amounts are left alone so reporting on them still works
+UPDATE projects
+SET
+    description = sqlc.narg('description'),
+    address_line1 = sqlc.narg('address_line1'),
+    address_line2 = sqlc.narg('address_line2'),
+    zip_postal_code = sqlc.narg('zip_postal_code'),
+    website = sqlc.narg('website')
+WHERE project_id = sqlc.arg('project_id');
+
+-- name: PublishProject :one
+-- turns a draft into a live project when it has the fields a live project needs,
+-- publishing a live project changes nothing, it is read rather than updated so its
+-- updated_at stays where it is
+WITH published AS (
+    UPDATE projects
+    SET is_draft = FALSE,
+        updated_at = CURRENT_TIMESTAMP,
+        updated_by = sqlc.arg('actor_id')::uuid
+    WHERE projects.project_id = sqlc.arg('project_id')
+      AND projects.user_id = sqlc.arg('user_id')
+      AND projects.deleted_at IS NULL
+      AND projects.is_draft
+      AND (NOT sqlc.arg('require_start_date')::bool OR projects.start_date IS NOT NULL)
+      AND (NOT sqlc.arg('require_budget')::bool OR projects.budget IS NOT NULL)
+    RETURNING *
+)
+SELECT * FROM published
+UNION ALL
+SELECT live.* FROM projects live
+WHERE live.project_id = sqlc.arg('project_id')
+  AND live.user_id = sqlc.arg('user_id')
+  AND live.deleted_at IS NULL
+  AND NOT live.is_draft
+  AND (NOT sqlc.arg('require_start_date')::bool OR live.start_date IS NOT NULL)
+  AND (NOT sqlc.arg('require_budget')::bool OR live.budget IS NOT NULL);
+
+-- name: GetProjectByName :one
+-- names are compared case-insensitively, the oldest match wins
+SELECT * FROM projects
+WHERE user_id = sqlc.arg('user_id') AND lower(name) = lower(sqlc.arg('name')) AND deleted_at IS NULL
+ORDER BY created_at, project_id
+LIMIT 1;
+
+-- name: CloneProject :one
+-- copies a live project as "<name> (copy)" and, with include_wallets, its live wallets
+-- with zeroed balances. Wallet names are unique per user so the copies get the suffix
+-- too, names are cut to leave room for it. Pins, milestones and external refs stay
+-- with the source.
+WITH source AS (
+    SELECT * FROM projects p
+    WHERE p.project_id = sqlc.arg('project_id') AND p.user_id = sqlc.arg('user_id') AND p.deleted_at IS NULL
+), clone AS (
+    INSERT INTO projects (
+        user_id,
+        name,
+        description,
+        status,
+        start_date,
+        end_date,
+        budget,
+        address_line1,
+        address_line2,
+        country,
+        city,
+        state_province,
+        zip_postal_code,
+        website,
+        tags,
+        parent_project_id,
+        is_draft,
+        created_by,
+        updated_by
+    )
+    SELECT
+        s.user_id,
+        left(s.name, 93) || ' (copy)',
+        s.description,
+        s.status,
+        s.start_date,
+        s.end_date,
+        s.budget,
+        s.address_line1,
+        s.address_line2,
+        s.country,
+        s.city,
+        s.state_province,
+        s.zip_postal_code,
+        s.website,
+        s.tags,
+        s.parent_project_id,
+        s.is_draft,
+        sqlc.arg('actor_id')::uuid,
+        sqlc.arg('actor_id')::uuid
+    FROM source s
+    RETURNING *
+), cloned_wallets AS (
+    INSERT INTO wallets (
+        user_id,
+        project_id,
+        name,
+        balance,
+        currency,
+        tags,
+        low_balance_threshold,
+        group_id,
+        created_by,
+        updated_by
+    )
+    SELECT
+        w.user_id,
+        c.project_id,
+        left(w.name, 93) || ' (copy)',
+        0,
+        w.currency,
+        w.tags,
+        w.low_balance_threshold,
+        w.group_id,
+        sqlc.arg('actor_id')::uuid,
+        sqlc.arg('actor_id')::uuid
+    FROM wallets w
+    CROSS JOIN clone c
+    WHERE sqlc.arg('include_wallets')::bool
+        AND w.project_id = sqlc.arg('project_id')
+        AND w.user_id = sqlc.arg('user_id')
+        AND w.deleted_at IS NULL
+)
+SELECT * FROM clone;
+
+-- name: GetProjectsByIDs :many
+-- in no particular order, the repository puts them in the order asked for
+SELECT * FROM projects
+WHERE project_id = ANY(sqlc.arg('project_ids')::uuid[]) AND user_id = sqlc.arg('user_id') AND deleted_at IS NULL;
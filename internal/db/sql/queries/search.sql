@@ -0,0 +1,29 @@
+-- name: SearchContactNotes :many
+SELECT
+    contact_id,
+    name,
+    ts_headline('english', notes, websearch_to_tsquery('english', sqlc.arg('query')::text),
+        'StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2')::text AS snippet,
+    ts_rank(notes_search, websearch_to_tsquery('english', sqlc.arg('query')::text))::real AS rank,
+    updated_at
+FROM contacts
+WHERE user_id = sqlc.arg('user_id')
+  AND deleted_at IS NULL
+  AND notes_search @@ websearch_to_tsquery('english', sqlc.arg('query')::text)
+ORDER BY rank DESC, updated_at DESC, contact_id
+LIMIT sqlc.arg('limit');
+
+-- name: SearchProjectDescriptions :many
+SELECT
+    project_id,
+    name,
+    ts_headline('english', description, websearch_to_tsquery('english', sqlc.arg('query')::text),
+        'StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2')::text AS snippet,
+    ts_rank(description_search, websearch_to_tsquery('english', sqlc.arg('query')::text))::real AS rank,
+    updated_at
+FROM projects
+WHERE user_id = sqlc.arg('user_id')
+  AND deleted_at IS NULL
+  AND description_search @@ websearch_to_tsquery('english', sqlc.arg('query')::text)
+ORDER BY rank DESC, updated_at DESC, project_id
+LIMIT sqlc.arg('limit');
@@ -24,4 +24,15 @@ WHERE key = $1;
 
 -- name: DeleteExpiredSessions :exec
 DELETE FROM "sessions"
-WHERE expires_at <= CURRENT_TIMESTAMP; 
\ No newline at end of file
+WHERE expires_at <= CURRENT_TIMESTAMP; 
+
+-- name: PurgeExpiredSessions :execrows
+-- at most batch_size rows per call, oldest first
+DELETE FROM "sessions"
+WHERE session_id IN (
+    SELECT s.session_id FROM "sessions" s
+    WHERE s.expires_at < sqlc.arg('expired_before')
+    ORDER BY s.expires_at
+    LIMIT sqlc.arg('batch_size')
+    FOR UPDATE SKIP LOCKED
+);
@@ -31,3 +31,34 @@ WHERE tag_id = $1 AND user_id = $2;
 -- name: DeleteUserTags :exec
 DELETE FROM tags
 WHERE user_id = $1;
+
+-- name: UnassignTagFromContacts :execrows
+UPDATE contacts
+SET tags = array_remove(tags, sqlc.arg('tag_id')::uuid),
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = sqlc.arg('user_id')
+  AND contact_id = ANY(sqlc.arg('ids')::uuid[])
+  AND sqlc.arg('tag_id')::uuid = ANY(tags);
+
+-- name: UnassignTagFromProjects :execrows
+UPDATE projects
+SET tags = array_remove(tags, sqlc.arg('tag_id')::uuid),
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = sqlc.arg('user_id')
+  AND project_id = ANY(sqlc.arg('ids')::uuid[])
+  AND sqlc.arg('tag_id')::uuid = ANY(tags);
+
+-- name: UnassignTagFromWallets :execrows
+UPDATE wallets
+SET tags = array_remove(tags, sqlc.arg('tag_id')::uuid),
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = sqlc.arg('user_id')
+  AND wallet_id = ANY(sqlc.arg('ids')::uuid[])
+  AND sqlc.arg('tag_id')::uuid = ANY(tags);
+
+-- name: ListTagsByIDs :many
+-- Only the caller's tags resolve; IDs of other users' tags are ignored
+SELECT * FROM tags
+WHERE user_id = sqlc.arg('user_id')
+  AND tag_id = ANY(sqlc.arg('tag_ids')::uuid[])
+ORDER BY name, tag_id;
@@ -28,6 +28,26 @@ INSERT INTO "users" (
 )
 RETURNING *;
 
+-- name: ProvisionUser :one
+-- creates the user signing in for the first time, with the ID of its token when it has
+-- one, nothing is inserted and no row returned when a concurrent sign in or the webhook
+-- created the user of the external ID first
+INSERT INTO "users" (
+  user_id,
+  name,
+  email,
+  external_id,
+  provider
+) VALUES (
+  COALESCE(sqlc.narg('user_id')::uuid, gen_random_uuid()),
+  sqlc.arg('name'),
+  sqlc.arg('email'),
+  sqlc.arg('external_id'),
+  sqlc.arg('provider')
+)
+ON CONFLICT (external_id, provider) DO NOTHING
+RETURNING *;
+
 -- name: UpdateUser :one
 UPDATE "users"
 SET 
@@ -43,6 +63,38 @@ SET
 WHERE user_id = $1
 RETURNING *;
 
+-- name: SetUserInboundToken :one
+UPDATE "users"
+SET
+  inbound_token = sqlc.narg('inbound_token'),
+  updated_at = CURRENT_TIMESTAMP
+WHERE user_id = sqlc.arg('user_id')
+RETURNING *;
+
+-- name: SetUserDefaults :one
+-- a default that isn't a live wallet or project of the user leaves the row unchanged
+UPDATE "users" u
+SET
+  default_wallet_id = sqlc.narg('default_wallet_id'),
+  default_project_id = sqlc.narg('default_project_id'),
+  updated_at = CURRENT_TIMESTAMP
+WHERE u.user_id = sqlc.arg('user_id')
+  AND (sqlc.narg('default_wallet_id')::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM wallets w
+    WHERE w.wallet_id = sqlc.narg('default_wallet_id') AND w.user_id = u.user_id AND w.deleted_at IS NULL
+  ))
+  AND (sqlc.narg('default_project_id')::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM projects p
+    WHERE p.project_id = sqlc.narg('default_project_id') AND p.user_id = u.user_id AND p.deleted_at IS NULL
+  ))
+RETURNING u.*;
+
+-- name: UserOwnsWallet :one
+SELECT EXISTS (
+    SELECT 1 FROM wallets
+    WHERE wallet_id = sqlc.arg('wallet_id') AND user_id = sqlc.arg('user_id') AND deleted_at IS NULL
+);
+
 -- name: UpdateUserRefreshToken :exec
 UPDATE "users"
 SET 
@@ -77,5 +129,27 @@ ORDER BY
          WHEN name ILIKE ($1 || '%') THEN 1
          ELSE 2
     END,
-    created_at DESC
-LIMIT $2;
\ No newline at end of file
+    created_at DESC, user_id DESC
+LIMIT $2;
+
+-- name: AllowAnonymizedWrites :exec
+-- lets the rest of the transaction write PII columns of anonymized users
+SELECT set_config('app.anonymizing', 'on', true);
+
+-- name: AnonymizeUser :one
+UPDATE "users"
+SET
+  name = sqlc.arg('name'),
+  email = sqlc.arg('email'),
+  address_line1 = NULL,
+  address_line2 = NULL,
+  zip_postal_code = NULL,
+  inbound_token = NULL,
+  anonymized_at = COALESCE(anonymized_at, CURRENT_TIMESTAMP)
+WHERE user_id = sqlc.arg('user_id')
+RETURNING anonymized_at;
+
+-- name: GetUserResourceCounts :one
+-- the counts of the user's contacts, projects and wallets outside the trash, kept by triggers
+SELECT contact_count, project_count, wallet_count FROM "users"
+WHERE user_id = $1;
@@ -0,0 +1,68 @@
+-- name: ListWalletGroups :many
+SELECT * FROM wallet_groups
+WHERE user_id = $1
+ORDER BY sort_order, lower(name);
+
+-- name: GetWalletGroup :one
+SELECT * FROM wallet_groups
+WHERE group_id = $1 AND user_id = $2 LIMIT 1;
+
+-- name: WalletGroupExists :one
+SELECT EXISTS (
+    SELECT 1 FROM wallet_groups
+    WHERE group_id = $1 AND user_id = $2
+);
+
+-- name: CreateWalletGroup :one
+-- without a sort order the group goes after the user's existing ones
+INSERT INTO wallet_groups (
+    user_id,
+    name,
+    sort_order,
+    created_by,
+    updated_by
+) VALUES (
+    sqlc.arg('user_id'),
+    sqlc.arg('name'),
+    COALESCE(
+        sqlc.narg('sort_order')::int,
+        (SELECT COALESCE(MAX(sort_order) + 1, 0) FROM wallet_groups WHERE user_id = sqlc.arg('user_id'))
+    ),
+    sqlc.arg('actor_id')::uuid,
+    sqlc.arg('actor_id')::uuid
+)
+RETURNING *;
+
+-- name: UpdateWalletGroup :one
+UPDATE wallet_groups
+SET
+    name = sqlc.arg('name'),
+    sort_order = COALESCE(sqlc.narg('sort_order')::int, sort_order),
+    updated_at = CURRENT_TIMESTAMP,
+    updated_by = sqlc.arg('actor_id')::uuid
+WHERE group_id = sqlc.arg('group_id') AND user_id = sqlc.arg('user_id')
+RETURNING *;
+
+-- name: DeleteWalletGroup :execrows
+DELETE FROM wallet_groups
+WHERE group_id = $1 AND user_id = $2;
+
+-- name: ListGroupWallets :many
+SELECT w.* FROM wallets w
+WHERE w.group_id = sqlc.arg('group_id')
+  AND w.user_id = sqlc.arg('user_id')
+  AND w.deleted_at IS NULL
+ORDER BY lower(w.name);
+
+-- name: GetGroupBalances :many
+-- a wallet without a balance counts as empty
+SELECT
+    currency::text AS currency,
+    SUM(COALESCE(balance, 0))::numeric AS balance,
+    COUNT(*) AS wallet_count
+FROM wallets
+WHERE group_id = sqlc.arg('group_id')
+  AND user_id = sqlc.arg('user_id')
+  AND deleted_at IS NULL
+GROUP BY currency
+ORDER BY currency;
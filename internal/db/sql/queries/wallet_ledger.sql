@@ -0,0 +1,45 @@
+-- name: GetWalletLedgerBalance :one
+-- the balance of the wallet just before the given time
+SELECT COALESCE(SUM(e.amount), 0)::numeric AS balance
+FROM wallet_ledger_entries e
+JOIN wallets w ON w.wallet_id = e.wallet_id
+WHERE e.wallet_id = sqlc.arg('wallet_id')
+  AND w.user_id = sqlc.arg('user_id')
+  AND e.occurred_at < sqlc.arg('before');
+
+-- name: CountWalletLedgerEntries :one
+SELECT COUNT(*)
+FROM wallet_ledger_entries e
+JOIN wallets w ON w.wallet_id = e.wallet_id
+WHERE e.wallet_id = sqlc.arg('wallet_id')
+  AND w.user_id = sqlc.arg('user_id');
+
+-- name: ListWalletLedgerEntries :many
+-- entries before the end of the range following the (after_occurred_at, after_seq) cursor,
+-- oldest first. seq starts at 1 so a zero after_seq includes entries at after_occurred_at.
+SELECT e.entry_id, e.seq, e.amount, e.description, e.occurred_at
+FROM wallet_ledger_entries e
+JOIN wallets w ON w.wallet_id = e.wallet_id
+WHERE e.wallet_id = sqlc.arg('wallet_id')
+  AND w.user_id = sqlc.arg('user_id')
+  AND e.occurred_at < sqlc.arg('before')
+  AND (e.occurred_at, e.seq) > (sqlc.arg('after_occurred_at')::timestamp, sqlc.arg('after_seq')::bigint)
+ORDER BY e.occurred_at, e.seq
+LIMIT sqlc.arg('limit');
+
+-- name: GetWalletLedgerStats :one
+-- money that left (outflow) and entered (inflow) the wallet over the trailing 7, 30 and
+-- 90 UTC days ending with today, an entry's day is the date of its occurred_at
+SELECT
+    COALESCE(SUM(CASE WHEN e.amount < 0 AND e.occurred_at >= sqlc.arg('today')::date - 6 THEN -e.amount END), 0)::numeric AS outflow_7d,
+    COALESCE(SUM(CASE WHEN e.amount > 0 AND e.occurred_at >= sqlc.arg('today')::date - 6 THEN e.amount END), 0)::numeric AS inflow_7d,
+    COALESCE(SUM(CASE WHEN e.amount < 0 AND e.occurred_at >= sqlc.arg('today')::date - 29 THEN -e.amount END), 0)::numeric AS outflow_30d,
+    COALESCE(SUM(CASE WHEN e.amount > 0 AND e.occurred_at >= sqlc.arg('today')::date - 29 THEN e.amount END), 0)::numeric AS inflow_30d,
+    COALESCE(SUM(CASE WHEN e.amount < 0 THEN -e.amount END), 0)::numeric AS outflow_90d,
+    COALESCE(SUM(CASE WHEN e.amount > 0 THEN e.amount END), 0)::numeric AS inflow_90d
+FROM wallet_ledger_entries e
+JOIN wallets w ON w.wallet_id = e.wallet_id
+WHERE e.wallet_id = sqlc.arg('wallet_id')
+  AND w.user_id = sqlc.arg('user_id')
+  AND e.occurred_at >= sqlc.arg('today')::date - 89
+  AND e.occurred_at < sqlc.arg('today')::date + 1;
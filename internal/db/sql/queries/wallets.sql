@@ -1,11 +1,11 @@
 -- name: GetWallet :one
 SELECT * FROM wallets
-WHERE wallet_id = $1 AND user_id = $2 LIMIT 1;
+WHERE wallet_id = $1 AND user_id = $2 AND deleted_at IS NULL LIMIT 1;
 
 -- name: ListWallets :many
 SELECT * FROM wallets
-WHERE user_id = $1
-ORDER BY created_at DESC
+WHERE user_id = $1 AND deleted_at IS NULL
+ORDER BY created_at DESC, wallet_id DESC
 LIMIT $2 OFFSET $3;
 
 -- name: CreateWallet :one
@@ -15,9 +15,23 @@ INSERT INTO wallets (
     name,
     balance,
     currency,
-    tags
+    tags,
+    low_balance_threshold,
+    group_id,
+    created_by,
+    updated_by
 ) VALUES (
-    $1, $2, $3, $4, $5, $6
+    sqlc.arg('user_id'),
+    sqlc.arg('project_id'),
+    sqlc.arg('name'),
+    sqlc.arg('balance'),
+    sqlc.arg('currency'),
+    owned_tags(sqlc.arg('user_id'), sqlc.narg('tags')::uuid[]),
+    sqlc.narg('low_balance_threshold'),
+    -- groups of other users are dropped
+    (SELECT g.group_id FROM wallet_groups g WHERE g.group_id = sqlc.narg('group_id') AND g.user_id = sqlc.arg('user_id')),
+    sqlc.arg('actor_id')::uuid,
+    sqlc.arg('actor_id')::uuid
 )
 RETURNING *;
 
@@ -27,41 +41,281 @@ SET
     name = COALESCE(sqlc.narg('name'), name),
     balance = sqlc.narg('balance'),
     currency = COALESCE(sqlc.narg('currency'), currency),
-    tags = sqlc.narg('tags'),
-    updated_at = CURRENT_TIMESTAMP
+    tags = owned_tags(sqlc.arg('user_id'), sqlc.narg('tags')::uuid[]),
+    low_balance_threshold = sqlc.narg('low_balance_threshold'),
+    group_id = (SELECT g.group_id FROM wallet_groups g WHERE g.group_id = sqlc.narg('group_id') AND g.user_id = sqlc.arg('user_id')),
+    updated_at = CURRENT_TIMESTAMP,
+    updated_by = sqlc.arg('actor_id')::uuid
 
-WHERE wallet_id = sqlc.arg('wallet_id') AND user_id = sqlc.arg('user_id')
+WHERE wallet_id = sqlc.arg('wallet_id') AND user_id = sqlc.arg('user_id') AND deleted_at IS NULL
 RETURNING *;
 
 
--- name: DeleteWallet :exec
+-- name: DeleteWallet :execrows
+-- a version set only trashes the wallet while it is still at that updated_at
+UPDATE wallets
+SET deleted_at = CURRENT_TIMESTAMP,
+    pinned_at = NULL
+WHERE wallet_id = sqlc.arg('wallet_id') AND user_id = sqlc.arg('user_id') AND deleted_at IS NULL
+  AND (sqlc.narg('version')::timestamp IS NULL OR updated_at = sqlc.narg('version'));
+
+-- name: PurgeWallet :one
+-- deletes the wallet for good whether it is in the trash or not, its ledger entries go with it.
+-- deleted_at tells whether it was in the trash, a version set only deletes it while it is still at that updated_at.
 DELETE FROM wallets
-WHERE wallet_id = $1 AND user_id = $2;
+WHERE wallet_id = sqlc.arg('wallet_id') AND user_id = sqlc.arg('user_id')
+  AND (sqlc.narg('version')::timestamp IS NULL OR updated_at = sqlc.narg('version'))
+RETURNING deleted_at;
 
 -- name: ListWalletsPaginated :many
-SELECT * 
+-- with filter_group set only the wallets of group_id are listed, or the ungrouped ones when it is null.
+-- Wallets carrying any of tags are listed, all of them with match_all_tags, no tags doesn't filter.
+-- A null currency or has_project doesn't filter either, the filters all have to match.
+-- The pinned wallets are left out, ListPinnedWalletsPaginated lists them before the rest.
+SELECT *
 FROM wallets
-WHERE user_id = $1 
-  AND (created_at < $2 OR (created_at = $2 AND wallet_id < $3))
-ORDER BY created_at DESC, wallet_id DESC
-LIMIT $4;
+WHERE user_id = sqlc.arg('user_id')
+  AND deleted_at IS NULL
+  AND pinned_at IS NULL
+  AND (NOT sqlc.arg('filter_group')::bool OR group_id IS NOT DISTINCT FROM sqlc.narg('group_id')::uuid)
+  AND (COALESCE(cardinality(sqlc.arg('tags')::uuid[]), 0) = 0
+      OR (sqlc.arg('match_all_tags')::bool AND tags @> sqlc.arg('tags')::uuid[])
+      OR (NOT sqlc.arg('match_all_tags')::bool AND tags && sqlc.arg('tags')::uuid[]))
+  AND (sqlc.narg('currency')::text IS NULL OR currency = sqlc.narg('currency')::text)
+  AND (sqlc.narg('has_project')::bool IS NULL OR (project_id IS NOT NULL) = sqlc.narg('has_project')::bool)
+  AND (
+      (sqlc.arg('sort_order')::text = 'asc'
+          AND (created_at > sqlc.arg('created_at') OR (created_at = sqlc.arg('created_at') AND wallet_id > sqlc.arg('wallet_id'))))
+      OR (sqlc.arg('sort_order')::text <> 'asc'
+          AND (created_at < sqlc.arg('created_at') OR (created_at = sqlc.arg('created_at') AND wallet_id < sqlc.arg('wallet_id'))))
+  )
+ORDER BY
+    CASE WHEN sqlc.arg('sort_order')::text = 'asc' THEN created_at END ASC,
+    CASE WHEN sqlc.arg('sort_order')::text = 'asc' THEN wallet_id END ASC,
+    CASE WHEN sqlc.arg('sort_order')::text <> 'asc' THEN created_at END DESC,
+    CASE WHEN sqlc.arg('sort_order')::text <> 'asc' THEN wallet_id END DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListWalletIDsPaginated :many
+-- ListWalletsPaginated selecting only the keys the list is paged by
+SELECT wallet_id, created_at
+FROM wallets
+WHERE user_id = sqlc.arg('user_id')
+  AND deleted_at IS NULL
+  AND pinned_at IS NULL
+  AND (NOT sqlc.arg('filter_group')::bool OR group_id IS NOT DISTINCT FROM sqlc.narg('group_id')::uuid)
+  AND (COALESCE(cardinality(sqlc.arg('tags')::uuid[]), 0) = 0
+      OR (sqlc.arg('match_all_tags')::bool AND tags @> sqlc.arg('tags')::uuid[])
+      OR (NOT sqlc.arg('match_all_tags')::bool AND tags && sqlc.arg('tags')::uuid[]))
+  AND (sqlc.narg('currency')::text IS NULL OR currency = sqlc.narg('currency')::text)
+  AND (sqlc.narg('has_project')::bool IS NULL OR (project_id IS NOT NULL) = sqlc.narg('has_project')::bool)
+  AND (
+      (sqlc.arg('sort_order')::text = 'asc'
+          AND (created_at > sqlc.arg('created_at') OR (created_at = sqlc.arg('created_at') AND wallet_id > sqlc.arg('wallet_id'))))
+      OR (sqlc.arg('sort_order')::text <> 'asc'
+          AND (created_at < sqlc.arg('created_at') OR (created_at = sqlc.arg('created_at') AND wallet_id < sqlc.arg('wallet_id'))))
+  )
+ORDER BY
+    CASE WHEN sqlc.arg('sort_order')::text = 'asc' THEN created_at END ASC,
+    CASE WHEN sqlc.arg('sort_order')::text = 'asc' THEN wallet_id END ASC,
+    CASE WHEN sqlc.arg('sort_order')::text <> 'asc' THEN created_at END DESC,
+    CASE WHEN sqlc.arg('sort_order')::text <> 'asc' THEN wallet_id END DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListPinnedWalletsPaginated :many
+-- filtered the way ListWalletsPaginated is
+SELECT *
+FROM wallets
+WHERE user_id = sqlc.arg('user_id')
+  AND deleted_at IS NULL
+  AND pinned_at IS NOT NULL
+  AND (NOT sqlc.arg('filter_group')::bool OR group_id IS NOT DISTINCT FROM sqlc.narg('group_id')::uuid)
+  AND (COALESCE(cardinality(sqlc.arg('tags')::uuid[]), 0) = 0
+      OR (sqlc.arg('match_all_tags')::bool AND tags @> sqlc.arg('tags')::uuid[])
+      OR (NOT sqlc.arg('match_all_tags')::bool AND tags && sqlc.arg('tags')::uuid[]))
+  AND (sqlc.narg('currency')::text IS NULL OR currency = sqlc.narg('currency')::text)
+  AND (sqlc.narg('has_project')::bool IS NULL OR (project_id IS NOT NULL) = sqlc.narg('has_project')::bool)
+  AND (pinned_at < sqlc.arg('pinned_at') OR (pinned_at = sqlc.arg('pinned_at') AND wallet_id < sqlc.arg('wallet_id')))
+ORDER BY pinned_at DESC, wallet_id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListPinnedWalletIDsPaginated :many
+-- ListPinnedWalletsPaginated selecting only the keys the list is paged by
+SELECT wallet_id, pinned_at
+FROM wallets
+WHERE user_id = sqlc.arg('user_id')
+  AND deleted_at IS NULL
+  AND pinned_at IS NOT NULL
+  AND (NOT sqlc.arg('filter_group')::bool OR group_id IS NOT DISTINCT FROM sqlc.narg('group_id')::uuid)
+  AND (COALESCE(cardinality(sqlc.arg('tags')::uuid[]), 0) = 0
+      OR (sqlc.arg('match_all_tags')::bool AND tags @> sqlc.arg('tags')::uuid[])
+      OR (NOT sqlc.arg('match_all_tags')::bool AND tags && sqlc.arg('tags')::uuid[]))
+  AND (sqlc.narg('currency')::text IS NULL OR currency = sqlc.narg('currency')::text)
+  AND (sqlc.narg('has_project')::bool IS NULL OR (project_id IS NOT NULL) = sqlc.narg('has_project')::bool)
+  AND (pinned_at < sqlc.arg('pinned_at') OR (pinned_at = sqlc.arg('pinned_at') AND wallet_id < sqlc.arg('wallet_id')))
+ORDER BY pinned_at DESC, wallet_id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: CountPinnedWallets :one
+SELECT COUNT(*) FROM wallets
+WHERE user_id = $1 AND pinned_at IS NOT NULL AND deleted_at IS NULL;
+
+-- name: SetWalletPinned :one
+-- pinning a pinned wallet keeps its place, pins aren't edits so updated_at is left alone
+UPDATE wallets
+SET pinned_at = CASE WHEN sqlc.arg('pinned')::bool THEN COALESCE(pinned_at, CURRENT_TIMESTAMP) END
+WHERE wallet_id = sqlc.arg('wallet_id') AND user_id = sqlc.arg('user_id') AND deleted_at IS NULL
+RETURNING *;
 
 -- name: GetProjectWallets :many
 SELECT * FROM wallets
-WHERE project_id = $1 AND user_id = $2
+WHERE project_id = $1 AND user_id = $2 AND deleted_at IS NULL
 ORDER BY created_at DESC;
 
--- name: SearchWallets :many
+-- name: CountOwnedWallets :one
+SELECT COUNT(*) FROM wallets
+WHERE user_id = sqlc.arg('user_id') AND wallet_id = ANY(sqlc.arg('wallet_ids')::uuid[]) AND deleted_at IS NULL;
+
+-- name: WalletNameExists :one
+SELECT EXISTS (
+    SELECT 1 FROM wallets
+    WHERE user_id = $1 AND lower(name) = lower(sqlc.arg('name')) AND deleted_at IS NULL
+);
+
+-- name: AttachWalletsToProject :execrows
+-- wallets already in the project are left alone so only the moved ones are counted
+UPDATE wallets
+SET
+    project_id = sqlc.arg('project_id'),
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = sqlc.arg('user_id')
+    AND wallet_id = ANY(sqlc.arg('wallet_ids')::uuid[])
+    AND deleted_at IS NULL
+    AND project_id IS DISTINCT FROM sqlc.arg('project_id');
+
+-- name: ListLowBalanceWallets :many
+-- a wallet without a balance counts as empty
 SELECT *
 FROM wallets
 WHERE user_id = sqlc.arg('user_id')
+  AND deleted_at IS NULL
+  AND low_balance_threshold IS NOT NULL
+  AND COALESCE(balance, 0) < low_balance_threshold
+ORDER BY low_balance_threshold - COALESCE(balance, 0) DESC, wallet_id;
+
+-- name: SumWalletBalancesByCurrency :many
+-- wallets without a balance count as empty, include_deleted adds the wallets in the trash
+SELECT
+    currency,
+    COALESCE(SUM(balance), 0)::numeric AS total,
+    COUNT(*) AS wallet_count
+FROM wallets
+WHERE user_id = sqlc.arg('user_id')
+  AND (sqlc.arg('include_deleted')::bool OR deleted_at IS NULL)
+GROUP BY currency
+ORDER BY currency;
+
+-- name: ListWalletFacets :many
+-- counts the distinct values of the facetable field of the user's wallets, most common first
+SELECT facet.value::text AS value, COUNT(*) AS count
+FROM (
+    SELECT CASE sqlc.arg('field')::text
+        WHEN 'currency' THEN currency
+    END AS value
+    FROM wallets
+    WHERE user_id = sqlc.arg('user_id') AND deleted_at IS NULL
+) facet
+WHERE facet.value IS NOT NULL
+GROUP BY facet.value
+ORDER BY count DESC, facet.value ASC;
+
+-- name: ListWalletProjects :many
+-- the project of each wallet among wallet_ids, null for the wallets outside any project
+SELECT
+    w.wallet_id,
+    p.project_id,
+    p.name AS project_name
+FROM wallets w
+LEFT JOIN projects p ON p.project_id = w.project_id AND p.user_id = w.user_id
+WHERE w.user_id = sqlc.arg('user_id')
+  AND w.wallet_id = ANY(sqlc.arg('wallet_ids')::uuid[]);
+
+-- name: SearchWallets :many
+-- score is how similar the name is to the query from 0 to 1, meaningless without a query
+SELECT sqlc.embed(wallets),
+    (1 - (f_unaccent(name) <-> f_unaccent(sqlc.arg('name'))))::real AS score
+FROM wallets
+WHERE user_id = sqlc.arg('user_id')
+  AND deleted_at IS NULL
   AND (
       sqlc.arg('name')::text = ''  -- No filter applied if sqlc.arg('name') is empty
-      OR name ILIKE '%' || sqlc.arg('name') || '%'  -- Substring match
-      OR name <-> sqlc.arg('name') < 0.8  -- Trigram similarity with threshold
+      OR f_unaccent(name) ILIKE '%' || f_unaccent(sqlc.arg('name')) || '%'  -- Substring match, accents ignored
+      OR f_unaccent(name) <-> f_unaccent(sqlc.arg('name')) < 0.8  -- Trigram similarity with threshold
   )
 ORDER BY 
     CASE WHEN sqlc.arg('name') = '' THEN created_at END DESC,  -- If sqlc.arg('name') is empty, sort by created_at
-    CASE WHEN sqlc.arg('name') <> '' THEN name <-> sqlc.arg('name') END,  -- If sqlc.arg('name') is provided, sort by trigram similarity
-    length(name) ASC  -- Shorter names are preferred as tiebreaker
+    CASE WHEN sqlc.arg('name') <> '' THEN f_unaccent(name) <-> f_unaccent(sqlc.arg('name')) END,  -- If sqlc.arg('name') is provided, sort by trigram similarity
+    length(name) ASC,  -- Shorter names are preferred as tiebreaker
+    created_at DESC, wallet_id DESC  -- then the newest, so ties always resolve the same way
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: SearchWalletsPicker :many
+-- SearchWallets selecting only the columns of the picker view
+SELECT wallet_id, name, balance, currency
+FROM wallets
+WHERE user_id = sqlc.arg('user_id')
+  AND deleted_at IS NULL
+  AND (
+      sqlc.arg('name')::text = ''  -- No filter applied if sqlc.arg('name') is empty
+      OR f_unaccent(name) ILIKE '%' || f_unaccent(sqlc.arg('name')) || '%'  -- Substring match, accents ignored
+      OR f_unaccent(name) <-> f_unaccent(sqlc.arg('name')) < 0.8  -- Trigram similarity with threshold
+  )
+ORDER BY 
+    CASE WHEN sqlc.arg('name') = '' THEN created_at END DESC,  -- If sqlc.arg('name') is empty, sort by created_at
+    CASE WHEN sqlc.arg('name') <> '' THEN f_unaccent(name) <-> f_unaccent(sqlc.arg('name')) END,  -- If sqlc.arg('name') is provided, sort by trigram similarity
+    length(name) ASC,  -- Shorter names are preferred as tiebreaker
+    created_at DESC, wallet_id DESC  -- then the newest, so ties always resolve the same way
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: ListDeletedWalletsPaginated :many
+SELECT *
+FROM wallets
+WHERE user_id = sqlc.arg('user_id')
+  AND deleted_at IS NOT NULL
+  AND (
+      (sqlc.arg('sort_order')::text = 'asc'
+          AND (deleted_at > sqlc.arg('deleted_at') OR (deleted_at = sqlc.arg('deleted_at') AND wallet_id > sqlc.arg('wallet_id'))))
+      OR (sqlc.arg('sort_order')::text <> 'asc'
+          AND (deleted_at < sqlc.arg('deleted_at') OR (deleted_at = sqlc.arg('deleted_at') AND wallet_id < sqlc.arg('wallet_id'))))
+  )
+ORDER BY
+    CASE WHEN sqlc.arg('sort_order')::text = 'asc' THEN deleted_at END ASC,
+    CASE WHEN sqlc.arg('sort_order')::text = 'asc' THEN wallet_id END ASC,
+    CASE WHEN sqlc.arg('sort_order')::text <> 'asc' THEN deleted_at END DESC,
+    CASE WHEN sqlc.arg('sort_order')::text <> 'asc' THEN wallet_id END DESC
 LIMIT sqlc.arg('limit');
+
+-- name: RestoreWallet :one
+UPDATE wallets
+SET deleted_at = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE wallet_id = sqlc.arg('wallet_id') AND user_id = sqlc.arg('user_id') AND deleted_at IS NOT NULL
+RETURNING *;
+
+-- name: PurgeDeletedWallets :execrows
+-- at most batch_size rows per call, oldest first, skipping rows locked by a restore
+DELETE FROM wallets
+WHERE wallet_id IN (
+    SELECT w.wallet_id FROM wallets w
+    WHERE w.deleted_at IS NOT NULL AND w.deleted_at < sqlc.arg('deleted_before')
+    ORDER BY w.deleted_at
+    LIMIT sqlc.arg('batch_size')
+    FOR UPDATE SKIP LOCKED
+);
+
+-- name: GetWalletsByIDs :many
+-- in no particular order, the repository puts them in the order asked for
+SELECT * FROM wallets
+WHERE wallet_id = ANY(sqlc.arg('wallet_ids')::uuid[]) AND user_id = sqlc.arg('user_id') AND deleted_at IS NULL;
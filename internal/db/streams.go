@@ -0,0 +1,228 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// The Stream variants run the same SQL as their generated :many counterparts but
+// hand each row to fn as soon as it is scanned instead of collecting a slice, so
+// callers never hold more than one row. Returning an error from fn stops the scan.
+
+// SearchContactsStream runs SearchContacts calling fn for each row in order
+func (q *Queries) SearchContactsStream(ctx context.Context, arg SearchContactsParams, fn func(Contact) error) error {
+	rows, err := q.db.Query(ctx, searchContacts, arg.Name, arg.UserID, arg.Offset, arg.Limit)
+	if err != nil {
+		return err
+	}
+	// the score following the contact columns isn't streamed
+	var score float32
+	return streamContacts(rows, fn, &score)
+}
+
+// ListContactsPaginatedStream runs ListContactsPaginated calling fn for each row in order
+func (q *Queries) ListContactsPaginatedStream(ctx context.Context, arg ListContactsPaginatedParams, fn func(Contact) error) error {
+	rows, err := q.db.Query(ctx, listContactsPaginated,
+		arg.UserID,
+		arg.City,
+		arg.StateProvince,
+		arg.SortOrder,
+		arg.CreatedAt,
+		arg.ContactID,
+		arg.Limit,
+	)
+	if err != nil {
+		return err
+	}
+	return streamContacts(rows, fn)
+}
+
+// streamContacts scans contacts rows in the column order of SELECT * FROM contacts, into
+// extra for the columns following them
+func streamContacts(rows pgx.Rows, fn func(Contact) error, extra ...any) error {
+	defer rows.Close()
+	for rows.Next() {
+		var i Contact
+		dest := append([]any{
+			&i.ContactID,
+			&i.UserID,
+			&i.Name,
+			&i.Phone,
+			&i.Email,
+			&i.AddressLine1,
+			&i.AddressLine2,
+			&i.Country,
+			&i.City,
+			&i.StateProvince,
+			&i.ZipPostalCode,
+			&i.Tags,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Company,
+			&i.DeletedAt,
+			&i.Notes,
+			&i.NotesSearch,
+			&i.CreatedBy,
+			&i.UpdatedBy,
+			&i.EmailKey,
+			&i.ExternalSource,
+			&i.ExternalID,
+			&i.Links,
+		}, extra...)
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		if err := fn(i); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// ListProjectsPaginatedStream runs ListProjectsPaginated calling fn for each row in order
+func (q *Queries) ListProjectsPaginatedStream(ctx context.Context, arg ListProjectsPaginatedParams, fn func(Project) error) error {
+	rows, err := q.db.Query(ctx, listProjectsPaginated,
+		arg.UserID,
+		arg.IncludeDrafts,
+		arg.SortOrder,
+		arg.CreatedAt,
+		arg.ProjectID,
+		arg.Limit,
+	)
+	if err != nil {
+		return err
+	}
+	return streamProjects(rows, fn)
+}
+
+// ListPinnedProjectsPaginatedStream runs ListPinnedProjectsPaginated calling fn for each row in order
+func (q *Queries) ListPinnedProjectsPaginatedStream(ctx context.Context, arg ListPinnedProjectsPaginatedParams, fn func(Project) error) error {
+	rows, err := q.db.Query(ctx, listPinnedProjectsPaginated,
+		arg.UserID,
+		arg.IncludeDrafts,
+		arg.PinnedAt,
+		arg.ProjectID,
+		arg.Limit,
+	)
+	if err != nil {
+		return err
+	}
+	return streamProjects(rows, fn)
+}
+
+// streamProjects scans projects rows in the column order of SELECT * FROM projects
+func streamProjects(rows pgx.Rows, fn func(Project) error) error {
+	defer rows.Close()
+	for rows.Next() {
+		var i Project
+		if err := rows.Scan(
+			&i.ProjectID,
+			&i.UserID,
+			&i.Name,
+			&i.Description,
+			&i.Status,
+			&i.StartDate,
+			&i.EndDate,
+			&i.Budget,
+			&i.ActualCost,
+			&i.AddressLine1,
+			&i.AddressLine2,
+			&i.Country,
+			&i.City,
+			&i.StateProvince,
+			&i.ZipPostalCode,
+			&i.Website,
+			&i.Tags,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.DescriptionSearch,
+			&i.CreatedBy,
+			&i.UpdatedBy,
+			&i.PinnedAt,
+			&i.ParentProjectID,
+			&i.ExternalSource,
+			&i.ExternalID,
+			&i.IsDraft,
+		); err != nil {
+			return err
+		}
+		if err := fn(i); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// ListWalletsPaginatedStream runs ListWalletsPaginated calling fn for each row in order
+func (q *Queries) ListWalletsPaginatedStream(ctx context.Context, arg ListWalletsPaginatedParams, fn func(Wallet) error) error {
+	rows, err := q.db.Query(ctx, listWalletsPaginated,
+		arg.UserID,
+		arg.FilterGroup,
+		arg.GroupID,
+		arg.Tags,
+		arg.MatchAllTags,
+		arg.Currency,
+		arg.HasProject,
+		arg.SortOrder,
+		arg.CreatedAt,
+		arg.WalletID,
+		arg.Limit,
+	)
+	if err != nil {
+		return err
+	}
+	return streamWallets(rows, fn)
+}
+
+// ListPinnedWalletsPaginatedStream runs ListPinnedWalletsPaginated calling fn for each row in order
+func (q *Queries) ListPinnedWalletsPaginatedStream(ctx context.Context, arg ListPinnedWalletsPaginatedParams, fn func(Wallet) error) error {
+	rows, err := q.db.Query(ctx, listPinnedWalletsPaginated,
+		arg.UserID,
+		arg.FilterGroup,
+		arg.GroupID,
+		arg.Tags,
+		arg.MatchAllTags,
+		arg.Currency,
+		arg.HasProject,
+		arg.PinnedAt,
+		arg.WalletID,
+		arg.Limit,
+	)
+	if err != nil {
+		return err
+	}
+	return streamWallets(rows, fn)
+}
+
+// streamWallets scans wallets rows in the column order of SELECT * FROM wallets
+func streamWallets(rows pgx.Rows, fn func(Wallet) error) error {
+	defer rows.Close()
+	for rows.Next() {
+		var i Wallet
+		if err := rows.Scan(
+			&i.WalletID,
+			&i.UserID,
+			&i.ProjectID,
+			&i.Name,
+			&i.Balance,
+			&i.Currency,
+			&i.Tags,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.LowBalanceThreshold,
+			&i.GroupID,
+			&i.CreatedBy,
+			&i.UpdatedBy,
+			&i.PinnedAt,
+		); err != nil {
+			return err
+		}
+		if err := fn(i); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -124,6 +124,115 @@ func (q *Queries) ListTags(ctx context.Context, userID uuid.UUID) ([]Tag, error)
 	return items, nil
 }
 
+const listTagsByIDs = `-- name: ListTagsByIDs :many
+SELECT tag_id, user_id, name, color, created_at, updated_at FROM tags
+WHERE user_id = $1
+  AND tag_id = ANY($2::uuid[])
+ORDER BY name, tag_id
+`
+
+type ListTagsByIDsParams struct {
+	UserID uuid.UUID   `json:"userId"`
+	TagIds []uuid.UUID `json:"tagIds"`
+}
+
+// Only the caller's tags resolve; IDs of other users' tags are ignored
+func (q *Queries) ListTagsByIDs(ctx context.Context, arg ListTagsByIDsParams) ([]Tag, error) {
+	rows, err := q.db.Query(ctx, listTagsByIDs, arg.UserID, arg.TagIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Tag
+	for rows.Next() {
+		var i Tag
+		if err := rows.Scan(
+			&i.TagID,
+			&i.UserID,
+			&i.Name,
+			&i.Color,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const unassignTagFromContacts = `-- name: UnassignTagFromContacts :execrows
+UPDATE contacts
+SET tags = array_remove(tags, $1::uuid),
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $2
+  AND contact_id = ANY($3::uuid[])
+  AND $1::uuid = ANY(tags)
+`
+
+type UnassignTagFromContactsParams struct {
+	TagID  uuid.UUID   `json:"tagId"`
+	UserID uuid.UUID   `json:"userId"`
+	Ids    []uuid.UUID `json:"ids"`
+}
+
+func (q *Queries) UnassignTagFromContacts(ctx context.Context, arg UnassignTagFromContactsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, unassignTagFromContacts, arg.TagID, arg.UserID, arg.Ids)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const unassignTagFromProjects = `-- name: UnassignTagFromProjects :execrows
+UPDATE projects
+SET tags = array_remove(tags, $1::uuid),
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $2
+  AND project_id = ANY($3::uuid[])
+  AND $1::uuid = ANY(tags)
+`
+
+type UnassignTagFromProjectsParams struct {
+	TagID  uuid.UUID   `json:"tagId"`
+	UserID uuid.UUID   `json:"userId"`
+	Ids    []uuid.UUID `json:"ids"`
+}
+
+func (q *Queries) UnassignTagFromProjects(ctx context.Context, arg UnassignTagFromProjectsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, unassignTagFromProjects, arg.TagID, arg.UserID, arg.Ids)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const unassignTagFromWallets = `-- name: UnassignTagFromWallets :execrows
+UPDATE wallets
+SET tags = array_remove(tags, $1::uuid),
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $2
+  AND wallet_id = ANY($3::uuid[])
+  AND $1::uuid = ANY(tags)
+`
+
+type UnassignTagFromWalletsParams struct {
+	TagID  uuid.UUID   `json:"tagId"`
+	UserID uuid.UUID   `json:"userId"`
+	Ids    []uuid.UUID `json:"ids"`
+}
+
+func (q *Queries) UnassignTagFromWallets(ctx context.Context, arg UnassignTagFromWalletsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, unassignTagFromWallets, arg.TagID, arg.UserID, arg.Ids)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const updateTag = `-- name: UpdateTag :one
 UPDATE tags
 SET name = $2,
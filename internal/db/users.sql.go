@@ -12,6 +12,43 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const allowAnonymizedWrites = `-- name: AllowAnonymizedWrites :exec
+SELECT set_config('app.anonymizing', 'on', true)
+`
+
+// lets the rest of the transaction write PII columns of anonymized users
+func (q *Queries) AllowAnonymizedWrites(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, allowAnonymizedWrites)
+	return err
+}
+
+const anonymizeUser = `-- name: AnonymizeUser :one
+UPDATE "users"
+SET
+  name = $1,
+  email = $2,
+  address_line1 = NULL,
+  address_line2 = NULL,
+  zip_postal_code = NULL,
+  inbound_token = NULL,
+  anonymized_at = COALESCE(anonymized_at, CURRENT_TIMESTAMP)
+WHERE user_id = $3
+RETURNING anonymized_at
+`
+
+type AnonymizeUserParams struct {
+	Name   string    `json:"name"`
+	Email  string    `json:"email"`
+	UserID uuid.UUID `json:"userId"`
+}
+
+func (q *Queries) AnonymizeUser(ctx context.Context, arg AnonymizeUserParams) (pgtype.Timestamp, error) {
+	row := q.db.QueryRow(ctx, anonymizeUser, arg.Name, arg.Email, arg.UserID)
+	var anonymized_at pgtype.Timestamp
+	err := row.Scan(&anonymized_at)
+	return anonymized_at, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO "users" (
   name,
@@ -27,7 +64,7 @@ INSERT INTO "users" (
 ) VALUES (
   $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
 )
-RETURNING user_id, external_id, name, email, address_line1, address_line2, country, city, state_province, zip_postal_code, created_at, updated_at, provider, refresh_token_hash, last_login_at
+RETURNING user_id, external_id, name, email, address_line1, address_line2, country, city, state_province, zip_postal_code, created_at, updated_at, provider, refresh_token_hash, last_login_at, anonymized_at, inbound_token, default_wallet_id, default_project_id, contact_count, project_count, wallet_count
 `
 
 type CreateUserParams struct {
@@ -73,6 +110,13 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Provider,
 		&i.RefreshTokenHash,
 		&i.LastLoginAt,
+		&i.AnonymizedAt,
+		&i.InboundToken,
+		&i.DefaultWalletID,
+		&i.DefaultProjectID,
+		&i.ContactCount,
+		&i.ProjectCount,
+		&i.WalletCount,
 	)
 	return i, err
 }
@@ -88,7 +132,7 @@ func (q *Queries) DeleteUser(ctx context.Context, userID uuid.UUID) error {
 }
 
 const getUser = `-- name: GetUser :one
-SELECT user_id, external_id, name, email, address_line1, address_line2, country, city, state_province, zip_postal_code, created_at, updated_at, provider, refresh_token_hash, last_login_at FROM "users"
+SELECT user_id, external_id, name, email, address_line1, address_line2, country, city, state_province, zip_postal_code, created_at, updated_at, provider, refresh_token_hash, last_login_at, anonymized_at, inbound_token, default_wallet_id, default_project_id, contact_count, project_count, wallet_count FROM "users"
 WHERE user_id = $1 LIMIT 1
 `
 
@@ -111,12 +155,19 @@ func (q *Queries) GetUser(ctx context.Context, userID uuid.UUID) (User, error) {
 		&i.Provider,
 		&i.RefreshTokenHash,
 		&i.LastLoginAt,
+		&i.AnonymizedAt,
+		&i.InboundToken,
+		&i.DefaultWalletID,
+		&i.DefaultProjectID,
+		&i.ContactCount,
+		&i.ProjectCount,
+		&i.WalletCount,
 	)
 	return i, err
 }
 
 const getUserByExternalID = `-- name: GetUserByExternalID :one
-SELECT user_id, external_id, name, email, address_line1, address_line2, country, city, state_province, zip_postal_code, created_at, updated_at, provider, refresh_token_hash, last_login_at FROM "users"
+SELECT user_id, external_id, name, email, address_line1, address_line2, country, city, state_province, zip_postal_code, created_at, updated_at, provider, refresh_token_hash, last_login_at, anonymized_at, inbound_token, default_wallet_id, default_project_id, contact_count, project_count, wallet_count FROM "users"
 WHERE external_id = $1 AND provider = $2 LIMIT 1
 `
 
@@ -144,12 +195,38 @@ func (q *Queries) GetUserByExternalID(ctx context.Context, arg GetUserByExternal
 		&i.Provider,
 		&i.RefreshTokenHash,
 		&i.LastLoginAt,
+		&i.AnonymizedAt,
+		&i.InboundToken,
+		&i.DefaultWalletID,
+		&i.DefaultProjectID,
+		&i.ContactCount,
+		&i.ProjectCount,
+		&i.WalletCount,
 	)
 	return i, err
 }
 
+const getUserResourceCounts = `-- name: GetUserResourceCounts :one
+SELECT contact_count, project_count, wallet_count FROM "users"
+WHERE user_id = $1
+`
+
+type GetUserResourceCountsRow struct {
+	ContactCount int32 `json:"contactCount"`
+	ProjectCount int32 `json:"projectCount"`
+	WalletCount  int32 `json:"walletCount"`
+}
+
+// the counts of the user's contacts, projects and wallets outside the trash, kept by triggers
+func (q *Queries) GetUserResourceCounts(ctx context.Context, userID uuid.UUID) (GetUserResourceCountsRow, error) {
+	row := q.db.QueryRow(ctx, getUserResourceCounts, userID)
+	var i GetUserResourceCountsRow
+	err := row.Scan(&i.ContactCount, &i.ProjectCount, &i.WalletCount)
+	return i, err
+}
+
 const listUsers = `-- name: ListUsers :many
-SELECT user_id, external_id, name, email, address_line1, address_line2, country, city, state_province, zip_postal_code, created_at, updated_at, provider, refresh_token_hash, last_login_at FROM "users"
+SELECT user_id, external_id, name, email, address_line1, address_line2, country, city, state_province, zip_postal_code, created_at, updated_at, provider, refresh_token_hash, last_login_at, anonymized_at, inbound_token, default_wallet_id, default_project_id, contact_count, project_count, wallet_count FROM "users"
 ORDER BY created_at DESC
 LIMIT $1 OFFSET $2
 `
@@ -184,6 +261,13 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 			&i.Provider,
 			&i.RefreshTokenHash,
 			&i.LastLoginAt,
+			&i.AnonymizedAt,
+			&i.InboundToken,
+			&i.DefaultWalletID,
+			&i.DefaultProjectID,
+			&i.ContactCount,
+			&i.ProjectCount,
+			&i.WalletCount,
 		); err != nil {
 			return nil, err
 		}
@@ -196,7 +280,7 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 }
 
 const listUsersPaginated = `-- name: ListUsersPaginated :many
-SELECT user_id, external_id, name, email, address_line1, address_line2, country, city, state_province, zip_postal_code, created_at, updated_at, provider, refresh_token_hash, last_login_at FROM "users"
+SELECT user_id, external_id, name, email, address_line1, address_line2, country, city, state_province, zip_postal_code, created_at, updated_at, provider, refresh_token_hash, last_login_at, anonymized_at, inbound_token, default_wallet_id, default_project_id, contact_count, project_count, wallet_count FROM "users"
 WHERE (created_at, user_id) < ($1, $2)
 ORDER BY created_at DESC, user_id DESC
 LIMIT $3
@@ -234,6 +318,13 @@ func (q *Queries) ListUsersPaginated(ctx context.Context, arg ListUsersPaginated
 			&i.Provider,
 			&i.RefreshTokenHash,
 			&i.LastLoginAt,
+			&i.AnonymizedAt,
+			&i.InboundToken,
+			&i.DefaultWalletID,
+			&i.DefaultProjectID,
+			&i.ContactCount,
+			&i.ProjectCount,
+			&i.WalletCount,
 		); err != nil {
 			return nil, err
 		}
@@ -245,15 +336,80 @@ func (q *Queries) ListUsersPaginated(ctx context.Context, arg ListUsersPaginated
 	return items, nil
 }
 
+const provisionUser = `-- name: ProvisionUser :one
+INSERT INTO "users" (
+  user_id,
+  name,
+  email,
+  external_id,
+  provider
+) VALUES (
+  COALESCE($1::uuid, gen_random_uuid()),
+  $2,
+  $3,
+  $4,
+  $5
+)
+ON CONFLICT (external_id, provider) DO NOTHING
+RETURNING user_id, external_id, name, email, address_line1, address_line2, country, city, state_province, zip_postal_code, created_at, updated_at, provider, refresh_token_hash, last_login_at, anonymized_at, inbound_token, default_wallet_id, default_project_id, contact_count, project_count, wallet_count
+`
+
+type ProvisionUserParams struct {
+	UserID     pgtype.UUID `json:"userId"`
+	Name       string      `json:"name"`
+	Email      string      `json:"email"`
+	ExternalID string      `json:"externalId"`
+	Provider   string      `json:"provider"`
+}
+
+// creates the user signing in for the first time, with the ID of its token when it has
+// one, nothing is inserted and no row returned when a concurrent sign in or the webhook
+// created the user of the external ID first
+func (q *Queries) ProvisionUser(ctx context.Context, arg ProvisionUserParams) (User, error) {
+	row := q.db.QueryRow(ctx, provisionUser,
+		arg.UserID,
+		arg.Name,
+		arg.Email,
+		arg.ExternalID,
+		arg.Provider,
+	)
+	var i User
+	err := row.Scan(
+		&i.UserID,
+		&i.ExternalID,
+		&i.Name,
+		&i.Email,
+		&i.AddressLine1,
+		&i.AddressLine2,
+		&i.Country,
+		&i.City,
+		&i.StateProvince,
+		&i.ZipPostalCode,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Provider,
+		&i.RefreshTokenHash,
+		&i.LastLoginAt,
+		&i.AnonymizedAt,
+		&i.InboundToken,
+		&i.DefaultWalletID,
+		&i.DefaultProjectID,
+		&i.ContactCount,
+		&i.ProjectCount,
+		&i.WalletCount,
+	)
+	return i, err
+}
+
 const searchUsers = `-- name: SearchUsers :many
-SELECT user_id, external_id, name, email, address_line1, address_line2, country, city, state_province, zip_postal_code, created_at, updated_at, provider, refresh_token_hash, last_login_at FROM users
+SELECT user_id, external_id, name, email, address_line1, address_line2, country, city, state_province, zip_postal_code, created_at, updated_at, provider, refresh_token_hash, last_login_at, anonymized_at, inbound_token, default_wallet_id, default_project_id, contact_count, project_count, wallet_count FROM users
 WHERE name ILIKE $1
 ORDER BY 
     CASE WHEN name ILIKE $1 THEN 0
          WHEN name ILIKE ($1 || '%') THEN 1
          ELSE 2
     END,
-    created_at DESC
+    created_at DESC, user_id DESC
 LIMIT $2
 `
 
@@ -288,6 +444,13 @@ func (q *Queries) SearchUsers(ctx context.Context, arg SearchUsersParams) ([]Use
 			&i.Provider,
 			&i.RefreshTokenHash,
 			&i.LastLoginAt,
+			&i.AnonymizedAt,
+			&i.InboundToken,
+			&i.DefaultWalletID,
+			&i.DefaultProjectID,
+			&i.ContactCount,
+			&i.ProjectCount,
+			&i.WalletCount,
 		); err != nil {
 			return nil, err
 		}
@@ -299,6 +462,105 @@ func (q *Queries) SearchUsers(ctx context.Context, arg SearchUsersParams) ([]Use
 	return items, nil
 }
 
+const setUserDefaults = `-- name: SetUserDefaults :one
+UPDATE "users" u
+SET
+  default_wallet_id = $1,
+  default_project_id = $2,
+  updated_at = CURRENT_TIMESTAMP
+WHERE u.user_id = $3
+  AND ($1::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM wallets w
+    WHERE w.wallet_id = $1 AND w.user_id = u.user_id AND w.deleted_at IS NULL
+  ))
+  AND ($2::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM projects p
+    WHERE p.project_id = $2 AND p.user_id = u.user_id AND p.deleted_at IS NULL
+  ))
+RETURNING u.user_id, u.external_id, u.name, u.email, u.address_line1, u.address_line2, u.country, u.city, u.state_province, u.zip_postal_code, u.created_at, u.updated_at, u.provider, u.refresh_token_hash, u.last_login_at, u.anonymized_at, u.inbound_token, u.default_wallet_id, u.default_project_id, u.contact_count, u.project_count, u.wallet_count
+`
+
+type SetUserDefaultsParams struct {
+	DefaultWalletID  pgtype.UUID `json:"defaultWalletId"`
+	DefaultProjectID pgtype.UUID `json:"defaultProjectId"`
+	UserID           uuid.UUID   `json:"userId"`
+}
+
+// a default that isn't a live wallet or project of the user leaves the row unchanged
+func (q *Queries) SetUserDefaults(ctx context.Context, arg SetUserDefaultsParams) (User, error) {
+	row := q.db.QueryRow(ctx, setUserDefaults, arg.DefaultWalletID, arg.DefaultProjectID, arg.UserID)
+	var i User
+	err := row.Scan(
+		&i.UserID,
+		&i.ExternalID,
+		&i.Name,
+		&i.Email,
+		&i.AddressLine1,
+		&i.AddressLine2,
+		&i.Country,
+		&i.City,
+		&i.StateProvince,
+		&i.ZipPostalCode,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Provider,
+		&i.RefreshTokenHash,
+		&i.LastLoginAt,
+		&i.AnonymizedAt,
+		&i.InboundToken,
+		&i.DefaultWalletID,
+		&i.DefaultProjectID,
+		&i.ContactCount,
+		&i.ProjectCount,
+		&i.WalletCount,
+	)
+	return i, err
+}
+
+const setUserInboundToken = `-- name: SetUserInboundToken :one
+UPDATE "users"
+SET
+  inbound_token = $1,
+  updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $2
+RETURNING user_id, external_id, name, email, address_line1, address_line2, country, city, state_province, zip_postal_code, created_at, updated_at, provider, refresh_token_hash, last_login_at, anonymized_at, inbound_token, default_wallet_id, default_project_id, contact_count, project_count, wallet_count
+`
+
+type SetUserInboundTokenParams struct {
+	InboundToken pgtype.Text `json:"inboundToken"`
+	UserID       uuid.UUID   `json:"userId"`
+}
+
+func (q *Queries) SetUserInboundToken(ctx context.Context, arg SetUserInboundTokenParams) (User, error) {
+	row := q.db.QueryRow(ctx, setUserInboundToken, arg.InboundToken, arg.UserID)
+	var i User
+	err := row.Scan(
+		&i.UserID,
+		&i.ExternalID,
+		&i.Name,
+		&i.Email,
+		&i.AddressLine1,
+		&i.AddressLine2,
+		&i.Country,
+		&i.City,
+		&i.StateProvince,
+		&i.ZipPostalCode,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Provider,
+		&i.RefreshTokenHash,
+		&i.LastLoginAt,
+		&i.AnonymizedAt,
+		&i.InboundToken,
+		&i.DefaultWalletID,
+		&i.DefaultProjectID,
+		&i.ContactCount,
+		&i.ProjectCount,
+		&i.WalletCount,
+	)
+	return i, err
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE "users"
 SET 
@@ -312,7 +574,7 @@ SET
   zip_postal_code = COALESCE($9, zip_postal_code),
   updated_at = CURRENT_TIMESTAMP
 WHERE user_id = $1
-RETURNING user_id, external_id, name, email, address_line1, address_line2, country, city, state_province, zip_postal_code, created_at, updated_at, provider, refresh_token_hash, last_login_at
+RETURNING user_id, external_id, name, email, address_line1, address_line2, country, city, state_province, zip_postal_code, created_at, updated_at, provider, refresh_token_hash, last_login_at, anonymized_at, inbound_token, default_wallet_id, default_project_id, contact_count, project_count, wallet_count
 `
 
 type UpdateUserParams struct {
@@ -356,6 +618,13 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.Provider,
 		&i.RefreshTokenHash,
 		&i.LastLoginAt,
+		&i.AnonymizedAt,
+		&i.InboundToken,
+		&i.DefaultWalletID,
+		&i.DefaultProjectID,
+		&i.ContactCount,
+		&i.ProjectCount,
+		&i.WalletCount,
 	)
 	return i, err
 }
@@ -390,3 +659,22 @@ func (q *Queries) UpdateUserRefreshToken(ctx context.Context, arg UpdateUserRefr
 	_, err := q.db.Exec(ctx, updateUserRefreshToken, arg.UserID, arg.RefreshTokenHash)
 	return err
 }
+
+const userOwnsWallet = `-- name: UserOwnsWallet :one
+SELECT EXISTS (
+    SELECT 1 FROM wallets
+    WHERE wallet_id = $1 AND user_id = $2 AND deleted_at IS NULL
+)
+`
+
+type UserOwnsWalletParams struct {
+	WalletID uuid.UUID `json:"walletId"`
+	UserID   uuid.UUID `json:"userId"`
+}
+
+func (q *Queries) UserOwnsWallet(ctx context.Context, arg UserOwnsWalletParams) (bool, error) {
+	row := q.db.QueryRow(ctx, userOwnsWallet, arg.WalletID, arg.UserID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
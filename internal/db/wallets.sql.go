@@ -12,6 +12,61 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const attachWalletsToProject = `-- name: AttachWalletsToProject :execrows
+UPDATE wallets
+SET
+    project_id = $1,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $2
+    AND wallet_id = ANY($3::uuid[])
+    AND deleted_at IS NULL
+    AND project_id IS DISTINCT FROM $1
+`
+
+type AttachWalletsToProjectParams struct {
+	ProjectID pgtype.UUID `json:"projectId"`
+	UserID    uuid.UUID   `json:"userId"`
+	WalletIds []uuid.UUID `json:"walletIds"`
+}
+
+// wallets already in the project are left alone so only the moved ones are counted
+func (q *Queries) AttachWalletsToProject(ctx context.Context, arg AttachWalletsToProjectParams) (int64, error) {
+	result, err := q.db.Exec(ctx, attachWalletsToProject, arg.ProjectID, arg.UserID, arg.WalletIds)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const countOwnedWallets = `-- name: CountOwnedWallets :one
+SELECT COUNT(*) FROM wallets
+WHERE user_id = $1 AND wallet_id = ANY($2::uuid[]) AND deleted_at IS NULL
+`
+
+type CountOwnedWalletsParams struct {
+	UserID    uuid.UUID   `json:"userId"`
+	WalletIds []uuid.UUID `json:"walletIds"`
+}
+
+func (q *Queries) CountOwnedWallets(ctx context.Context, arg CountOwnedWalletsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countOwnedWallets, arg.UserID, arg.WalletIds)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countPinnedWallets = `-- name: CountPinnedWallets :one
+SELECT COUNT(*) FROM wallets
+WHERE user_id = $1 AND pinned_at IS NOT NULL AND deleted_at IS NULL
+`
+
+func (q *Queries) CountPinnedWallets(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countPinnedWallets, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createWallet = `-- name: CreateWallet :one
 INSERT INTO wallets (
     user_id,
@@ -19,20 +74,37 @@ INSERT INTO wallets (
     name,
     balance,
     currency,
-    tags
+    tags,
+    low_balance_threshold,
+    group_id,
+    created_by,
+    updated_by
 ) VALUES (
-    $1, $2, $3, $4, $5, $6
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    owned_tags($1, $6::uuid[]),
+    $7,
+    -- groups of other users are dropped
+    (SELECT g.group_id FROM wallet_groups g WHERE g.group_id = $8 AND g.user_id = $1),
+    $9::uuid,
+    $9::uuid
 )
-RETURNING wallet_id, user_id, project_id, name, balance, currency, tags, created_at, updated_at
+RETURNING wallet_id, user_id, project_id, name, balance, currency, tags, created_at, updated_at, deleted_at, low_balance_threshold, group_id, created_by, updated_by, pinned_at
 `
 
 type CreateWalletParams struct {
-	UserID    uuid.UUID      `json:"userId"`
-	ProjectID pgtype.UUID    `json:"projectId"`
-	Name      string         `json:"name"`
-	Balance   pgtype.Numeric `json:"balance"`
-	Currency  string         `json:"currency"`
-	Tags      []uuid.UUID    `json:"tags"`
+	UserID              uuid.UUID      `json:"userId"`
+	ProjectID           pgtype.UUID    `json:"projectId"`
+	Name                string         `json:"name"`
+	Balance             pgtype.Numeric `json:"balance"`
+	Currency            string         `json:"currency"`
+	Tags                []uuid.UUID    `json:"tags"`
+	LowBalanceThreshold pgtype.Numeric `json:"lowBalanceThreshold"`
+	GroupID             pgtype.UUID    `json:"groupId"`
+	ActorID             uuid.UUID      `json:"actorId"`
 }
 
 func (q *Queries) CreateWallet(ctx context.Context, arg CreateWalletParams) (Wallet, error) {
@@ -43,6 +115,9 @@ func (q *Queries) CreateWallet(ctx context.Context, arg CreateWalletParams) (Wal
 		arg.Balance,
 		arg.Currency,
 		arg.Tags,
+		arg.LowBalanceThreshold,
+		arg.GroupID,
+		arg.ActorID,
 	)
 	var i Wallet
 	err := row.Scan(
@@ -55,28 +130,42 @@ func (q *Queries) CreateWallet(ctx context.Context, arg CreateWalletParams) (Wal
 		&i.Tags,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.LowBalanceThreshold,
+		&i.GroupID,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.PinnedAt,
 	)
 	return i, err
 }
 
-const deleteWallet = `-- name: DeleteWallet :exec
-DELETE FROM wallets
-WHERE wallet_id = $1 AND user_id = $2
+const deleteWallet = `-- name: DeleteWallet :execrows
+UPDATE wallets
+SET deleted_at = CURRENT_TIMESTAMP,
+    pinned_at = NULL
+WHERE wallet_id = $1 AND user_id = $2 AND deleted_at IS NULL
+  AND ($3::timestamp IS NULL OR updated_at = $3)
 `
 
 type DeleteWalletParams struct {
-	WalletID uuid.UUID `json:"walletId"`
-	UserID   uuid.UUID `json:"userId"`
+	WalletID uuid.UUID        `json:"walletId"`
+	UserID   uuid.UUID        `json:"userId"`
+	Version  pgtype.Timestamp `json:"version"`
 }
 
-func (q *Queries) DeleteWallet(ctx context.Context, arg DeleteWalletParams) error {
-	_, err := q.db.Exec(ctx, deleteWallet, arg.WalletID, arg.UserID)
-	return err
+// a version set only trashes the wallet while it is still at that updated_at
+func (q *Queries) DeleteWallet(ctx context.Context, arg DeleteWalletParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteWallet, arg.WalletID, arg.UserID, arg.Version)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const getProjectWallets = `-- name: GetProjectWallets :many
-SELECT wallet_id, user_id, project_id, name, balance, currency, tags, created_at, updated_at FROM wallets
-WHERE project_id = $1 AND user_id = $2
+SELECT wallet_id, user_id, project_id, name, balance, currency, tags, created_at, updated_at, deleted_at, low_balance_threshold, group_id, created_by, updated_by, pinned_at FROM wallets
+WHERE project_id = $1 AND user_id = $2 AND deleted_at IS NULL
 ORDER BY created_at DESC
 `
 
@@ -104,6 +193,12 @@ func (q *Queries) GetProjectWallets(ctx context.Context, arg GetProjectWalletsPa
 			&i.Tags,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.LowBalanceThreshold,
+			&i.GroupID,
+			&i.CreatedBy,
+			&i.UpdatedBy,
+			&i.PinnedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -116,8 +211,8 @@ func (q *Queries) GetProjectWallets(ctx context.Context, arg GetProjectWalletsPa
 }
 
 const getWallet = `-- name: GetWallet :one
-SELECT wallet_id, user_id, project_id, name, balance, currency, tags, created_at, updated_at FROM wallets
-WHERE wallet_id = $1 AND user_id = $2 LIMIT 1
+SELECT wallet_id, user_id, project_id, name, balance, currency, tags, created_at, updated_at, deleted_at, low_balance_threshold, group_id, created_by, updated_by, pinned_at FROM wallets
+WHERE wallet_id = $1 AND user_id = $2 AND deleted_at IS NULL LIMIT 1
 `
 
 type GetWalletParams struct {
@@ -138,14 +233,494 @@ func (q *Queries) GetWallet(ctx context.Context, arg GetWalletParams) (Wallet, e
 		&i.Tags,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.LowBalanceThreshold,
+		&i.GroupID,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.PinnedAt,
 	)
 	return i, err
 }
 
-const listWallets = `-- name: ListWallets :many
-SELECT wallet_id, user_id, project_id, name, balance, currency, tags, created_at, updated_at FROM wallets
+const getWalletsByIDs = `-- name: GetWalletsByIDs :many
+SELECT wallet_id, user_id, project_id, name, balance, currency, tags, created_at, updated_at, deleted_at, low_balance_threshold, group_id, created_by, updated_by, pinned_at FROM wallets
+WHERE wallet_id = ANY($1::uuid[]) AND user_id = $2 AND deleted_at IS NULL
+`
+
+type GetWalletsByIDsParams struct {
+	WalletIds []uuid.UUID `json:"walletIds"`
+	UserID    uuid.UUID   `json:"userId"`
+}
+
+// in no particular order, the repository puts them in the order asked for
+func (q *Queries) GetWalletsByIDs(ctx context.Context, arg GetWalletsByIDsParams) ([]Wallet, error) {
+	rows, err := q.db.Query(ctx, getWalletsByIDs, arg.WalletIds, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Wallet
+	for rows.Next() {
+		var i Wallet
+		if err := rows.Scan(
+			&i.WalletID,
+			&i.UserID,
+			&i.ProjectID,
+			&i.Name,
+			&i.Balance,
+			&i.Currency,
+			&i.Tags,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.LowBalanceThreshold,
+			&i.GroupID,
+			&i.CreatedBy,
+			&i.UpdatedBy,
+			&i.PinnedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDeletedWalletsPaginated = `-- name: ListDeletedWalletsPaginated :many
+SELECT wallet_id, user_id, project_id, name, balance, currency, tags, created_at, updated_at, deleted_at, low_balance_threshold, group_id, created_by, updated_by, pinned_at
+FROM wallets
 WHERE user_id = $1
-ORDER BY created_at DESC
+  AND deleted_at IS NOT NULL
+  AND (
+      ($2::text = 'asc'
+          AND (deleted_at > $3 OR (deleted_at = $3 AND wallet_id > $4)))
+      OR ($2::text <> 'asc'
+          AND (deleted_at < $3 OR (deleted_at = $3 AND wallet_id < $4)))
+  )
+ORDER BY
+    CASE WHEN $2::text = 'asc' THEN deleted_at END ASC,
+    CASE WHEN $2::text = 'asc' THEN wallet_id END ASC,
+    CASE WHEN $2::text <> 'asc' THEN deleted_at END DESC,
+    CASE WHEN $2::text <> 'asc' THEN wallet_id END DESC
+LIMIT $5
+`
+
+type ListDeletedWalletsPaginatedParams struct {
+	UserID    uuid.UUID        `json:"userId"`
+	SortOrder string           `json:"sortOrder"`
+	DeletedAt pgtype.Timestamp `json:"deletedAt"`
+	WalletID  uuid.UUID        `json:"walletId"`
+	Limit     int32            `json:"limit"`
+}
+
+func (q *Queries) ListDeletedWalletsPaginated(ctx context.Context, arg ListDeletedWalletsPaginatedParams) ([]Wallet, error) {
+	rows, err := q.db.Query(ctx, listDeletedWalletsPaginated,
+		arg.UserID,
+		arg.SortOrder,
+		arg.DeletedAt,
+		arg.WalletID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Wallet
+	for rows.Next() {
+		var i Wallet
+		if err := rows.Scan(
+			&i.WalletID,
+			&i.UserID,
+			&i.ProjectID,
+			&i.Name,
+			&i.Balance,
+			&i.Currency,
+			&i.Tags,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.LowBalanceThreshold,
+			&i.GroupID,
+			&i.CreatedBy,
+			&i.UpdatedBy,
+			&i.PinnedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLowBalanceWallets = `-- name: ListLowBalanceWallets :many
+SELECT wallet_id, user_id, project_id, name, balance, currency, tags, created_at, updated_at, deleted_at, low_balance_threshold, group_id, created_by, updated_by, pinned_at
+FROM wallets
+WHERE user_id = $1
+  AND deleted_at IS NULL
+  AND low_balance_threshold IS NOT NULL
+  AND COALESCE(balance, 0) < low_balance_threshold
+ORDER BY low_balance_threshold - COALESCE(balance, 0) DESC, wallet_id
+`
+
+// a wallet without a balance counts as empty
+func (q *Queries) ListLowBalanceWallets(ctx context.Context, userID uuid.UUID) ([]Wallet, error) {
+	rows, err := q.db.Query(ctx, listLowBalanceWallets, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Wallet
+	for rows.Next() {
+		var i Wallet
+		if err := rows.Scan(
+			&i.WalletID,
+			&i.UserID,
+			&i.ProjectID,
+			&i.Name,
+			&i.Balance,
+			&i.Currency,
+			&i.Tags,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.LowBalanceThreshold,
+			&i.GroupID,
+			&i.CreatedBy,
+			&i.UpdatedBy,
+			&i.PinnedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPinnedWalletIDsPaginated = `-- name: ListPinnedWalletIDsPaginated :many
+SELECT wallet_id, pinned_at
+FROM wallets
+WHERE user_id = $1
+  AND deleted_at IS NULL
+  AND pinned_at IS NOT NULL
+  AND (NOT $2::bool OR group_id IS NOT DISTINCT FROM $3::uuid)
+  AND (COALESCE(cardinality($4::uuid[]), 0) = 0
+      OR ($5::bool AND tags @> $4::uuid[])
+      OR (NOT $5::bool AND tags && $4::uuid[]))
+  AND ($6::text IS NULL OR currency = $6::text)
+  AND ($7::bool IS NULL OR (project_id IS NOT NULL) = $7::bool)
+  AND (pinned_at < $8 OR (pinned_at = $8 AND wallet_id < $9))
+ORDER BY pinned_at DESC, wallet_id DESC
+LIMIT $10
+`
+
+type ListPinnedWalletIDsPaginatedParams struct {
+	UserID       uuid.UUID        `json:"userId"`
+	FilterGroup  bool             `json:"filterGroup"`
+	GroupID      pgtype.UUID      `json:"groupId"`
+	Tags         []uuid.UUID      `json:"tags"`
+	MatchAllTags bool             `json:"matchAllTags"`
+	Currency     pgtype.Text      `json:"currency"`
+	HasProject   pgtype.Bool      `json:"hasProject"`
+	PinnedAt     pgtype.Timestamp `json:"pinnedAt"`
+	WalletID     uuid.UUID        `json:"walletId"`
+	Limit        int32            `json:"limit"`
+}
+
+type ListPinnedWalletIDsPaginatedRow struct {
+	WalletID uuid.UUID        `json:"walletId"`
+	PinnedAt pgtype.Timestamp `json:"pinnedAt"`
+}
+
+// ListPinnedWalletsPaginated selecting only the keys the list is paged by
+func (q *Queries) ListPinnedWalletIDsPaginated(ctx context.Context, arg ListPinnedWalletIDsPaginatedParams) ([]ListPinnedWalletIDsPaginatedRow, error) {
+	rows, err := q.db.Query(ctx, listPinnedWalletIDsPaginated,
+		arg.UserID,
+		arg.FilterGroup,
+		arg.GroupID,
+		arg.Tags,
+		arg.MatchAllTags,
+		arg.Currency,
+		arg.HasProject,
+		arg.PinnedAt,
+		arg.WalletID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPinnedWalletIDsPaginatedRow
+	for rows.Next() {
+		var i ListPinnedWalletIDsPaginatedRow
+		if err := rows.Scan(&i.WalletID, &i.PinnedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPinnedWalletsPaginated = `-- name: ListPinnedWalletsPaginated :many
+SELECT wallet_id, user_id, project_id, name, balance, currency, tags, created_at, updated_at, deleted_at, low_balance_threshold, group_id, created_by, updated_by, pinned_at
+FROM wallets
+WHERE user_id = $1
+  AND deleted_at IS NULL
+  AND pinned_at IS NOT NULL
+  AND (NOT $2::bool OR group_id IS NOT DISTINCT FROM $3::uuid)
+  AND (COALESCE(cardinality($4::uuid[]), 0) = 0
+      OR ($5::bool AND tags @> $4::uuid[])
+      OR (NOT $5::bool AND tags && $4::uuid[]))
+  AND ($6::text IS NULL OR currency = $6::text)
+  AND ($7::bool IS NULL OR (project_id IS NOT NULL) = $7::bool)
+  AND (pinned_at < $8 OR (pinned_at = $8 AND wallet_id < $9))
+ORDER BY pinned_at DESC, wallet_id DESC
+LIMIT $10
+`
+
+type ListPinnedWalletsPaginatedParams struct {
+	UserID       uuid.UUID        `json:"userId"`
+	FilterGroup  bool             `json:"filterGroup"`
+	GroupID      pgtype.UUID      `json:"groupId"`
+	Tags         []uuid.UUID      `json:"tags"`
+	MatchAllTags bool             `json:"matchAllTags"`
+	Currency     pgtype.Text      `json:"currency"`
+	HasProject   pgtype.Bool      `json:"hasProject"`
+	PinnedAt     pgtype.Timestamp `json:"pinnedAt"`
+	WalletID     uuid.UUID        `json:"walletId"`
+	Limit        int32            `json:"limit"`
+}
+
+// filtered the way ListWalletsPaginated is
+func (q *Queries) ListPinnedWalletsPaginated(ctx context.Context, arg ListPinnedWalletsPaginatedParams) ([]Wallet, error) {
+	rows, err := q.db.Query(ctx, listPinnedWalletsPaginated,
+		arg.UserID,
+		arg.FilterGroup,
+		arg.GroupID,
+		arg.Tags,
+		arg.MatchAllTags,
+		arg.Currency,
+		arg.HasProject,
+		arg.PinnedAt,
+		arg.WalletID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Wallet
+	for rows.Next() {
+		var i Wallet
+		if err := rows.Scan(
+			&i.WalletID,
+			&i.UserID,
+			&i.ProjectID,
+			&i.Name,
+			&i.Balance,
+			&i.Currency,
+			&i.Tags,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.LowBalanceThreshold,
+			&i.GroupID,
+			&i.CreatedBy,
+			&i.UpdatedBy,
+			&i.PinnedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWalletFacets = `-- name: ListWalletFacets :many
+SELECT facet.value::text AS value, COUNT(*) AS count
+FROM (
+    SELECT CASE $1::text
+        WHEN 'currency' THEN currency
+    END AS value
+    FROM wallets
+    WHERE user_id = $2 AND deleted_at IS NULL
+) facet
+WHERE facet.value IS NOT NULL
+GROUP BY facet.value
+ORDER BY count DESC, facet.value ASC
+`
+
+type ListWalletFacetsParams struct {
+	Field  string    `json:"field"`
+	UserID uuid.UUID `json:"userId"`
+}
+
+type ListWalletFacetsRow struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// counts the distinct values of the facetable field of the user's wallets, most common first
+func (q *Queries) ListWalletFacets(ctx context.Context, arg ListWalletFacetsParams) ([]ListWalletFacetsRow, error) {
+	rows, err := q.db.Query(ctx, listWalletFacets, arg.Field, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListWalletFacetsRow
+	for rows.Next() {
+		var i ListWalletFacetsRow
+		if err := rows.Scan(&i.Value, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWalletIDsPaginated = `-- name: ListWalletIDsPaginated :many
+SELECT wallet_id, created_at
+FROM wallets
+WHERE user_id = $1
+  AND deleted_at IS NULL
+  AND pinned_at IS NULL
+  AND (NOT $2::bool OR group_id IS NOT DISTINCT FROM $3::uuid)
+  AND (COALESCE(cardinality($4::uuid[]), 0) = 0
+      OR ($5::bool AND tags @> $4::uuid[])
+      OR (NOT $5::bool AND tags && $4::uuid[]))
+  AND ($6::text IS NULL OR currency = $6::text)
+  AND ($7::bool IS NULL OR (project_id IS NOT NULL) = $7::bool)
+  AND (
+      ($8::text = 'asc'
+          AND (created_at > $9 OR (created_at = $9 AND wallet_id > $10)))
+      OR ($8::text <> 'asc'
+          AND (created_at < $9 OR (created_at = $9 AND wallet_id < $10)))
+  )
+ORDER BY
+    CASE WHEN $8::text = 'asc' THEN created_at END ASC,
+    CASE WHEN $8::text = 'asc' THEN wallet_id END ASC,
+    CASE WHEN $8::text <> 'asc' THEN created_at END DESC,
+    CASE WHEN $8::text <> 'asc' THEN wallet_id END DESC
+LIMIT $11
+`
+
+type ListWalletIDsPaginatedParams struct {
+	UserID       uuid.UUID        `json:"userId"`
+	FilterGroup  bool             `json:"filterGroup"`
+	GroupID      pgtype.UUID      `json:"groupId"`
+	Tags         []uuid.UUID      `json:"tags"`
+	MatchAllTags bool             `json:"matchAllTags"`
+	Currency     pgtype.Text      `json:"currency"`
+	HasProject   pgtype.Bool      `json:"hasProject"`
+	SortOrder    string           `json:"sortOrder"`
+	CreatedAt    pgtype.Timestamp `json:"createdAt"`
+	WalletID     uuid.UUID        `json:"walletId"`
+	Limit        int32            `json:"limit"`
+}
+
+type ListWalletIDsPaginatedRow struct {
+	WalletID  uuid.UUID        `json:"walletId"`
+	CreatedAt pgtype.Timestamp `json:"createdAt"`
+}
+
+// ListWalletsPaginated selecting only the keys the list is paged by
+func (q *Queries) ListWalletIDsPaginated(ctx context.Context, arg ListWalletIDsPaginatedParams) ([]ListWalletIDsPaginatedRow, error) {
+	rows, err := q.db.Query(ctx, listWalletIDsPaginated,
+		arg.UserID,
+		arg.FilterGroup,
+		arg.GroupID,
+		arg.Tags,
+		arg.MatchAllTags,
+		arg.Currency,
+		arg.HasProject,
+		arg.SortOrder,
+		arg.CreatedAt,
+		arg.WalletID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListWalletIDsPaginatedRow
+	for rows.Next() {
+		var i ListWalletIDsPaginatedRow
+		if err := rows.Scan(&i.WalletID, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWalletProjects = `-- name: ListWalletProjects :many
+SELECT
+    w.wallet_id,
+    p.project_id,
+    p.name AS project_name
+FROM wallets w
+LEFT JOIN projects p ON p.project_id = w.project_id AND p.user_id = w.user_id
+WHERE w.user_id = $1
+  AND w.wallet_id = ANY($2::uuid[])
+`
+
+type ListWalletProjectsParams struct {
+	UserID    uuid.UUID   `json:"userId"`
+	WalletIds []uuid.UUID `json:"walletIds"`
+}
+
+type ListWalletProjectsRow struct {
+	WalletID    uuid.UUID   `json:"walletId"`
+	ProjectID   pgtype.UUID `json:"projectId"`
+	ProjectName pgtype.Text `json:"projectName"`
+}
+
+// the project of each wallet among wallet_ids, null for the wallets outside any project
+func (q *Queries) ListWalletProjects(ctx context.Context, arg ListWalletProjectsParams) ([]ListWalletProjectsRow, error) {
+	rows, err := q.db.Query(ctx, listWalletProjects, arg.UserID, arg.WalletIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListWalletProjectsRow
+	for rows.Next() {
+		var i ListWalletProjectsRow
+		if err := rows.Scan(&i.WalletID, &i.ProjectID, &i.ProjectName); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWallets = `-- name: ListWallets :many
+SELECT wallet_id, user_id, project_id, name, balance, currency, tags, created_at, updated_at, deleted_at, low_balance_threshold, group_id, created_by, updated_by, pinned_at FROM wallets
+WHERE user_id = $1 AND deleted_at IS NULL
+ORDER BY created_at DESC, wallet_id DESC
 LIMIT $2 OFFSET $3
 `
 
@@ -174,6 +749,12 @@ func (q *Queries) ListWallets(ctx context.Context, arg ListWalletsParams) ([]Wal
 			&i.Tags,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.LowBalanceThreshold,
+			&i.GroupID,
+			&i.CreatedBy,
+			&i.UpdatedBy,
+			&i.PinnedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -186,24 +767,59 @@ func (q *Queries) ListWallets(ctx context.Context, arg ListWalletsParams) ([]Wal
 }
 
 const listWalletsPaginated = `-- name: ListWalletsPaginated :many
-SELECT wallet_id, user_id, project_id, name, balance, currency, tags, created_at, updated_at 
+SELECT wallet_id, user_id, project_id, name, balance, currency, tags, created_at, updated_at, deleted_at, low_balance_threshold, group_id, created_by, updated_by, pinned_at
 FROM wallets
-WHERE user_id = $1 
-  AND (created_at < $2 OR (created_at = $2 AND wallet_id < $3))
-ORDER BY created_at DESC, wallet_id DESC
-LIMIT $4
+WHERE user_id = $1
+  AND deleted_at IS NULL
+  AND pinned_at IS NULL
+  AND (NOT $2::bool OR group_id IS NOT DISTINCT FROM $3::uuid)
+  AND (COALESCE(cardinality($4::uuid[]), 0) = 0
+      OR ($5::bool AND tags @> $4::uuid[])
+      OR (NOT $5::bool AND tags && $4::uuid[]))
+  AND ($6::text IS NULL OR currency = $6::text)
+  AND ($7::bool IS NULL OR (project_id IS NOT NULL) = $7::bool)
+  AND (
+      ($8::text = 'asc'
+          AND (created_at > $9 OR (created_at = $9 AND wallet_id > $10)))
+      OR ($8::text <> 'asc'
+          AND (created_at < $9 OR (created_at = $9 AND wallet_id < $10)))
+  )
+ORDER BY
+    CASE WHEN $8::text = 'asc' THEN created_at END ASC,
+    CASE WHEN $8::text = 'asc' THEN wallet_id END ASC,
+    CASE WHEN $8::text <> 'asc' THEN created_at END DESC,
+    CASE WHEN $8::text <> 'asc' THEN wallet_id END DESC
+LIMIT $11
 `
 
 type ListWalletsPaginatedParams struct {
-	UserID    uuid.UUID        `json:"userId"`
-	CreatedAt pgtype.Timestamp `json:"createdAt"`
-	WalletID  uuid.UUID        `json:"walletId"`
-	Limit     int32            `json:"limit"`
+	UserID       uuid.UUID        `json:"userId"`
+	FilterGroup  bool             `json:"filterGroup"`
+	GroupID      pgtype.UUID      `json:"groupId"`
+	Tags         []uuid.UUID      `json:"tags"`
+	MatchAllTags bool             `json:"matchAllTags"`
+	Currency     pgtype.Text      `json:"currency"`
+	HasProject   pgtype.Bool      `json:"hasProject"`
+	SortOrder    string           `json:"sortOrder"`
+	CreatedAt    pgtype.Timestamp `json:"createdAt"`
+	WalletID     uuid.UUID        `json:"walletId"`
+	Limit        int32            `json:"limit"`
 }
 
+// with filter_group set only the wallets of group_id are listed, or the ungrouped ones when it is null.
+// Wallets carrying any of tags are listed, all of them with match_all_tags, no tags doesn't filter.
+// A null currency or has_project doesn't filter either, the filters all have to match.
+// The pinned wallets are left out, ListPinnedWalletsPaginated lists them before the rest.
 func (q *Queries) ListWalletsPaginated(ctx context.Context, arg ListWalletsPaginatedParams) ([]Wallet, error) {
 	rows, err := q.db.Query(ctx, listWalletsPaginated,
 		arg.UserID,
+		arg.FilterGroup,
+		arg.GroupID,
+		arg.Tags,
+		arg.MatchAllTags,
+		arg.Currency,
+		arg.HasProject,
+		arg.SortOrder,
 		arg.CreatedAt,
 		arg.WalletID,
 		arg.Limit,
@@ -225,6 +841,12 @@ func (q *Queries) ListWalletsPaginated(ctx context.Context, arg ListWalletsPagin
 			&i.Tags,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.LowBalanceThreshold,
+			&i.GroupID,
+			&i.CreatedBy,
+			&i.UpdatedBy,
+			&i.PinnedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -236,47 +858,217 @@ func (q *Queries) ListWalletsPaginated(ctx context.Context, arg ListWalletsPagin
 	return items, nil
 }
 
+const purgeDeletedWallets = `-- name: PurgeDeletedWallets :execrows
+DELETE FROM wallets
+WHERE wallet_id IN (
+    SELECT w.wallet_id FROM wallets w
+    WHERE w.deleted_at IS NOT NULL AND w.deleted_at < $1
+    ORDER BY w.deleted_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+`
+
+type PurgeDeletedWalletsParams struct {
+	DeletedBefore pgtype.Timestamp `json:"deletedBefore"`
+	BatchSize     int32            `json:"batchSize"`
+}
+
+// at most batch_size rows per call, oldest first, skipping rows locked by a restore
+func (q *Queries) PurgeDeletedWallets(ctx context.Context, arg PurgeDeletedWalletsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeDeletedWallets, arg.DeletedBefore, arg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const purgeWallet = `-- name: PurgeWallet :one
+DELETE FROM wallets
+WHERE wallet_id = $1 AND user_id = $2
+  AND ($3::timestamp IS NULL OR updated_at = $3)
+RETURNING deleted_at
+`
+
+type PurgeWalletParams struct {
+	WalletID uuid.UUID        `json:"walletId"`
+	UserID   uuid.UUID        `json:"userId"`
+	Version  pgtype.Timestamp `json:"version"`
+}
+
+// deletes the wallet for good whether it is in the trash or not, its ledger entries go with it.
+// deleted_at tells whether it was in the trash, a version set only deletes it while it is still at that updated_at.
+func (q *Queries) PurgeWallet(ctx context.Context, arg PurgeWalletParams) (pgtype.Timestamp, error) {
+	row := q.db.QueryRow(ctx, purgeWallet, arg.WalletID, arg.UserID, arg.Version)
+	var deleted_at pgtype.Timestamp
+	err := row.Scan(&deleted_at)
+	return deleted_at, err
+}
+
+const restoreWallet = `-- name: RestoreWallet :one
+UPDATE wallets
+SET deleted_at = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE wallet_id = $1 AND user_id = $2 AND deleted_at IS NOT NULL
+RETURNING wallet_id, user_id, project_id, name, balance, currency, tags, created_at, updated_at, deleted_at, low_balance_threshold, group_id, created_by, updated_by, pinned_at
+`
+
+type RestoreWalletParams struct {
+	WalletID uuid.UUID `json:"walletId"`
+	UserID   uuid.UUID `json:"userId"`
+}
+
+func (q *Queries) RestoreWallet(ctx context.Context, arg RestoreWalletParams) (Wallet, error) {
+	row := q.db.QueryRow(ctx, restoreWallet, arg.WalletID, arg.UserID)
+	var i Wallet
+	err := row.Scan(
+		&i.WalletID,
+		&i.UserID,
+		&i.ProjectID,
+		&i.Name,
+		&i.Balance,
+		&i.Currency,
+		&i.Tags,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.LowBalanceThreshold,
+		&i.GroupID,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.PinnedAt,
+	)
+	return i, err
+}
+
 const searchWallets = `-- name: SearchWallets :many
-SELECT wallet_id, user_id, project_id, name, balance, currency, tags, created_at, updated_at
+SELECT wallets.wallet_id, wallets.user_id, wallets.project_id, wallets.name, wallets.balance, wallets.currency, wallets.tags, wallets.created_at, wallets.updated_at, wallets.deleted_at, wallets.low_balance_threshold, wallets.group_id, wallets.created_by, wallets.updated_by, wallets.pinned_at,
+    (1 - (f_unaccent(name) <-> f_unaccent($1)))::real AS score
+FROM wallets
+WHERE user_id = $2
+  AND deleted_at IS NULL
+  AND (
+      $1::text = ''  -- No filter applied if sqlc.arg('name') is empty
+      OR f_unaccent(name) ILIKE '%' || f_unaccent($1) || '%'  -- Substring match, accents ignored
+      OR f_unaccent(name) <-> f_unaccent($1) < 0.8  -- Trigram similarity with threshold
+  )
+ORDER BY 
+    CASE WHEN $1 = '' THEN created_at END DESC,  -- If sqlc.arg('name') is empty, sort by created_at
+    CASE WHEN $1 <> '' THEN f_unaccent(name) <-> f_unaccent($1) END,  -- If sqlc.arg('name') is provided, sort by trigram similarity
+    length(name) ASC,  -- Shorter names are preferred as tiebreaker
+    created_at DESC, wallet_id DESC  -- then the newest, so ties always resolve the same way
+LIMIT $4
+OFFSET $3
+`
+
+type SearchWalletsParams struct {
+	Name   string    `json:"name"`
+	UserID uuid.UUID `json:"userId"`
+	Offset int32     `json:"offset"`
+	Limit  int32     `json:"limit"`
+}
+
+type SearchWalletsRow struct {
+	Wallet Wallet  `json:"wallet"`
+	Score  float32 `json:"score"`
+}
+
+// score is how similar the name is to the query from 0 to 1, meaningless without a query
+func (q *Queries) SearchWallets(ctx context.Context, arg SearchWalletsParams) ([]SearchWalletsRow, error) {
+	rows, err := q.db.Query(ctx, searchWallets,
+		arg.Name,
+		arg.UserID,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchWalletsRow
+	for rows.Next() {
+		var i SearchWalletsRow
+		if err := rows.Scan(
+			&i.Wallet.WalletID,
+			&i.Wallet.UserID,
+			&i.Wallet.ProjectID,
+			&i.Wallet.Name,
+			&i.Wallet.Balance,
+			&i.Wallet.Currency,
+			&i.Wallet.Tags,
+			&i.Wallet.CreatedAt,
+			&i.Wallet.UpdatedAt,
+			&i.Wallet.DeletedAt,
+			&i.Wallet.LowBalanceThreshold,
+			&i.Wallet.GroupID,
+			&i.Wallet.CreatedBy,
+			&i.Wallet.UpdatedBy,
+			&i.Wallet.PinnedAt,
+			&i.Score,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchWalletsPicker = `-- name: SearchWalletsPicker :many
+SELECT wallet_id, name, balance, currency
 FROM wallets
 WHERE user_id = $1
+  AND deleted_at IS NULL
   AND (
       $2::text = ''  -- No filter applied if sqlc.arg('name') is empty
-      OR name ILIKE '%' || $2 || '%'  -- Substring match
-      OR name <-> $2 < 0.8  -- Trigram similarity with threshold
+      OR f_unaccent(name) ILIKE '%' || f_unaccent($2) || '%'  -- Substring match, accents ignored
+      OR f_unaccent(name) <-> f_unaccent($2) < 0.8  -- Trigram similarity with threshold
   )
 ORDER BY 
     CASE WHEN $2 = '' THEN created_at END DESC,  -- If sqlc.arg('name') is empty, sort by created_at
-    CASE WHEN $2 <> '' THEN name <-> $2 END,  -- If sqlc.arg('name') is provided, sort by trigram similarity
-    length(name) ASC  -- Shorter names are preferred as tiebreaker
-LIMIT $3
+    CASE WHEN $2 <> '' THEN f_unaccent(name) <-> f_unaccent($2) END,  -- If sqlc.arg('name') is provided, sort by trigram similarity
+    length(name) ASC,  -- Shorter names are preferred as tiebreaker
+    created_at DESC, wallet_id DESC  -- then the newest, so ties always resolve the same way
+LIMIT $4
+OFFSET $3
 `
 
-type SearchWalletsParams struct {
+type SearchWalletsPickerParams struct {
 	UserID uuid.UUID `json:"userId"`
 	Name   string    `json:"name"`
+	Offset int32     `json:"offset"`
 	Limit  int32     `json:"limit"`
 }
 
-func (q *Queries) SearchWallets(ctx context.Context, arg SearchWalletsParams) ([]Wallet, error) {
-	rows, err := q.db.Query(ctx, searchWallets, arg.UserID, arg.Name, arg.Limit)
+type SearchWalletsPickerRow struct {
+	WalletID uuid.UUID      `json:"walletId"`
+	Name     string         `json:"name"`
+	Balance  pgtype.Numeric `json:"balance"`
+	Currency string         `json:"currency"`
+}
+
+// SearchWallets selecting only the columns of the picker view
+func (q *Queries) SearchWalletsPicker(ctx context.Context, arg SearchWalletsPickerParams) ([]SearchWalletsPickerRow, error) {
+	rows, err := q.db.Query(ctx, searchWalletsPicker,
+		arg.UserID,
+		arg.Name,
+		arg.Offset,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Wallet
+	var items []SearchWalletsPickerRow
 	for rows.Next() {
-		var i Wallet
+		var i SearchWalletsPickerRow
 		if err := rows.Scan(
 			&i.WalletID,
-			&i.UserID,
-			&i.ProjectID,
 			&i.Name,
 			&i.Balance,
 			&i.Currency,
-			&i.Tags,
-			&i.CreatedAt,
-			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -288,26 +1080,113 @@ func (q *Queries) SearchWallets(ctx context.Context, arg SearchWalletsParams) ([
 	return items, nil
 }
 
+const setWalletPinned = `-- name: SetWalletPinned :one
+UPDATE wallets
+SET pinned_at = CASE WHEN $1::bool THEN COALESCE(pinned_at, CURRENT_TIMESTAMP) END
+WHERE wallet_id = $2 AND user_id = $3 AND deleted_at IS NULL
+RETURNING wallet_id, user_id, project_id, name, balance, currency, tags, created_at, updated_at, deleted_at, low_balance_threshold, group_id, created_by, updated_by, pinned_at
+`
+
+type SetWalletPinnedParams struct {
+	Pinned   bool      `json:"pinned"`
+	WalletID uuid.UUID `json:"walletId"`
+	UserID   uuid.UUID `json:"userId"`
+}
+
+// pinning a pinned wallet keeps its place, pins aren't edits so updated_at is left alone
+func (q *Queries) SetWalletPinned(ctx context.Context, arg SetWalletPinnedParams) (Wallet, error) {
+	row := q.db.QueryRow(ctx, setWalletPinned, arg.Pinned, arg.WalletID, arg.UserID)
+	var i Wallet
+	err := row.Scan(
+		&i.WalletID,
+		&i.UserID,
+		&i.ProjectID,
+		&i.Name,
+		&i.Balance,
+		&i.Currency,
+		&i.Tags,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.LowBalanceThreshold,
+		&i.GroupID,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.PinnedAt,
+	)
+	return i, err
+}
+
+const sumWalletBalancesByCurrency = `-- name: SumWalletBalancesByCurrency :many
+SELECT
+    currency,
+    COALESCE(SUM(balance), 0)::numeric AS total,
+    COUNT(*) AS wallet_count
+FROM wallets
+WHERE user_id = $1
+  AND ($2::bool OR deleted_at IS NULL)
+GROUP BY currency
+ORDER BY currency
+`
+
+type SumWalletBalancesByCurrencyParams struct {
+	UserID         uuid.UUID `json:"userId"`
+	IncludeDeleted bool      `json:"includeDeleted"`
+}
+
+type SumWalletBalancesByCurrencyRow struct {
+	Currency    string         `json:"currency"`
+	Total       pgtype.Numeric `json:"total"`
+	WalletCount int64          `json:"walletCount"`
+}
+
+// wallets without a balance count as empty, include_deleted adds the wallets in the trash
+func (q *Queries) SumWalletBalancesByCurrency(ctx context.Context, arg SumWalletBalancesByCurrencyParams) ([]SumWalletBalancesByCurrencyRow, error) {
+	rows, err := q.db.Query(ctx, sumWalletBalancesByCurrency, arg.UserID, arg.IncludeDeleted)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SumWalletBalancesByCurrencyRow
+	for rows.Next() {
+		var i SumWalletBalancesByCurrencyRow
+		if err := rows.Scan(&i.Currency, &i.Total, &i.WalletCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateWallet = `-- name: UpdateWallet :one
 UPDATE wallets
 SET 
     name = COALESCE($1, name),
     balance = $2,
     currency = COALESCE($3, currency),
-    tags = $4,
-    updated_at = CURRENT_TIMESTAMP
+    tags = owned_tags($4, $5::uuid[]),
+    low_balance_threshold = $6,
+    group_id = (SELECT g.group_id FROM wallet_groups g WHERE g.group_id = $7 AND g.user_id = $4),
+    updated_at = CURRENT_TIMESTAMP,
+    updated_by = $8::uuid
 
-WHERE wallet_id = $5 AND user_id = $6
-RETURNING wallet_id, user_id, project_id, name, balance, currency, tags, created_at, updated_at
+WHERE wallet_id = $9 AND user_id = $4 AND deleted_at IS NULL
+RETURNING wallet_id, user_id, project_id, name, balance, currency, tags, created_at, updated_at, deleted_at, low_balance_threshold, group_id, created_by, updated_by, pinned_at
 `
 
 type UpdateWalletParams struct {
-	Name     pgtype.Text    `json:"name"`
-	Balance  pgtype.Numeric `json:"balance"`
-	Currency pgtype.Text    `json:"currency"`
-	Tags     []uuid.UUID    `json:"tags"`
-	WalletID uuid.UUID      `json:"walletId"`
-	UserID   uuid.UUID      `json:"userId"`
+	Name                pgtype.Text    `json:"name"`
+	Balance             pgtype.Numeric `json:"balance"`
+	Currency            pgtype.Text    `json:"currency"`
+	UserID              uuid.UUID      `json:"userId"`
+	Tags                []uuid.UUID    `json:"tags"`
+	LowBalanceThreshold pgtype.Numeric `json:"lowBalanceThreshold"`
+	GroupID             pgtype.UUID    `json:"groupId"`
+	ActorID             uuid.UUID      `json:"actorId"`
+	WalletID            uuid.UUID      `json:"walletId"`
 }
 
 func (q *Queries) UpdateWallet(ctx context.Context, arg UpdateWalletParams) (Wallet, error) {
@@ -315,9 +1194,12 @@ func (q *Queries) UpdateWallet(ctx context.Context, arg UpdateWalletParams) (Wal
 		arg.Name,
 		arg.Balance,
 		arg.Currency,
+		arg.UserID,
 		arg.Tags,
+		arg.LowBalanceThreshold,
+		arg.GroupID,
+		arg.ActorID,
 		arg.WalletID,
-		arg.UserID,
 	)
 	var i Wallet
 	err := row.Scan(
@@ -330,6 +1212,31 @@ func (q *Queries) UpdateWallet(ctx context.Context, arg UpdateWalletParams) (Wal
 		&i.Tags,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.LowBalanceThreshold,
+		&i.GroupID,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.PinnedAt,
 	)
 	return i, err
 }
+
+const walletNameExists = `-- name: WalletNameExists :one
+SELECT EXISTS (
+    SELECT 1 FROM wallets
+    WHERE user_id = $1 AND lower(name) = lower($2) AND deleted_at IS NULL
+)
+`
+
+type WalletNameExistsParams struct {
+	UserID uuid.UUID `json:"userId"`
+	Name   string    `json:"name"`
+}
+
+func (q *Queries) WalletNameExists(ctx context.Context, arg WalletNameExistsParams) (bool, error) {
+	row := q.db.QueryRow(ctx, walletNameExists, arg.UserID, arg.Name)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
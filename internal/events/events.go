@@ -0,0 +1,199 @@
+// Package events publishes the changes made to a user's contacts, projects and wallets
+// to the clients of that user listening for them. The bus is in-process, each instance
+// of the API only streams the changes made through it.
+package events
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultBufferSize is how many events a subscriber can fall behind by when none is configured
+	DefaultBufferSize = 64
+	// DefaultReplaySize is how many of a user's latest events are replayed when none is configured
+	DefaultReplaySize = 100
+)
+
+// Entity types of the events
+const (
+	TypeContact = "contact"
+	TypeProject = "project"
+	TypeWallet  = "wallet"
+)
+
+// Actions of the events
+const (
+	ActionCreated  = "created"
+	ActionUpdated  = "updated"
+	ActionDeleted  = "deleted"
+	ActionRestored = "restored"
+)
+
+// Event is a change to one of a user's entities
+// @Description Change to one of the user's contacts, projects or wallets, sent as the data of a server-sent event
+type Event struct {
+	// ID orders the events of the bus, clients send the last one they saw as Last-Event-ID
+	ID        uint64              `json:"-"`
+	Type      string              `json:"type" example:"wallet" enums:"contact,project,wallet"`
+	EntityID  uuid.UUID           `json:"id" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
+	Action    string              `json:"action" example:"updated" enums:"created,updated,deleted,restored"`
+	UpdatedAt coreTypes.Timestamp `json:"updatedAt" example:"2024-01-01T00:00:00.000Z" swaggertype:"string" format:"date-time"`
+}
+
+// EventID is the id of the event as sent in the stream
+func (e Event) EventID() string {
+	return strconv.FormatUint(e.ID, 10)
+}
+
+// topic holds a user's subscribers and latest events
+type topic struct {
+	subscribers map[*Subscription]struct{}
+	// history is a ring of the latest events, oldest first from next once full
+	history []Event
+	next    int
+}
+
+// Bus fans the events of each user out to that user's subscribers. Each subscriber has
+// a bounded buffer, one that falls behind is closed rather than slowing the publishers
+// down, and reconnects to replay what it missed. A nil bus drops every event.
+type Bus struct {
+	mu         sync.Mutex
+	topics     map[uuid.UUID]*topic
+	lastID     uint64
+	bufferSize int
+	replaySize int
+}
+
+// NewBus creates a bus buffering up to bufferSize events per subscriber and keeping the
+// latest replaySize events of each user for reconnecting clients. Sizes of 0 or less
+// fall back to the defaults.
+func NewBus(bufferSize, replaySize int) *Bus {
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+	if replaySize <= 0 {
+		replaySize = DefaultReplaySize
+	}
+	return &Bus{
+		topics: make(map[uuid.UUID]*topic),
+		// ids keep increasing across restarts, so an id from before one isn't mistaken
+		// for a recent one
+		lastID:     uint64(time.Now().UnixMicro()),
+		bufferSize: bufferSize,
+		replaySize: replaySize,
+	}
+}
+
+// Publish sends the event to the user's subscribers and keeps it for replays. The
+// event's ID is assigned here, and UpdatedAt defaults to now.
+func (b *Bus) Publish(userID uuid.UUID, event Event) {
+	if b == nil {
+		return
+	}
+	if event.UpdatedAt.IsZero() {
+		event.UpdatedAt = coreTypes.NewTimestamp(time.Now().UTC())
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	event.ID = b.lastID
+
+	t := b.topic(userID)
+	if len(t.history) < b.replaySize {
+		t.history = append(t.history, event)
+	} else {
+		t.history[t.next] = event
+		t.next = (t.next + 1) % b.replaySize
+	}
+
+	for sub := range t.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			b.remove(userID, sub)
+		}
+	}
+}
+
+// Subscribe starts listening to the user's events. With the id of the last event the
+// client saw, the events the bus still holds after it are returned to replay first, all
+// of them when that event is no longer held. An empty or malformed lastEventID replays
+// nothing. The subscription must be closed once done.
+func (b *Bus) Subscribe(userID uuid.UUID, lastEventID string) (*Subscription, []Event) {
+	lastID, err := strconv.ParseUint(lastEventID, 10, 64)
+	replay := err == nil
+
+	sub := &Subscription{
+		bus:    b,
+		userID: userID,
+		events: make(chan Event, b.bufferSize),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	t := b.topic(userID)
+	t.subscribers[sub] = struct{}{}
+	if !replay {
+		return sub, nil
+	}
+
+	history := make([]Event, 0, len(t.history))
+	history = append(history, t.history[t.next:]...)
+	history = append(history, t.history[:t.next]...)
+	for i, event := range history {
+		if event.ID > lastID {
+			return sub, history[i:]
+		}
+	}
+	return sub, nil
+}
+
+// topic returns the user's topic, creating it. The caller holds the lock.
+func (b *Bus) topic(userID uuid.UUID) *topic {
+	t, ok := b.topics[userID]
+	if !ok {
+		t = &topic{subscribers: make(map[*Subscription]struct{})}
+		b.topics[userID] = t
+	}
+	return t
+}
+
+// remove closes the subscription. The caller holds the lock.
+func (b *Bus) remove(userID uuid.UUID, sub *Subscription) {
+	t, ok := b.topics[userID]
+	if !ok {
+		return
+	}
+	if _, ok := t.subscribers[sub]; !ok {
+		return
+	}
+	delete(t.subscribers, sub)
+	close(sub.events)
+}
+
+// Subscription receives the events of a user published after it started
+type Subscription struct {
+	bus    *Bus
+	userID uuid.UUID
+	events chan Event
+}
+
+// Events is closed when the subscription is, or when it fell too far behind
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Close stops the subscription, closing it twice is a no-op
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	s.bus.remove(s.userID, s)
+}
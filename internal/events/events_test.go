@@ -0,0 +1,108 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBus_PublishSubscribe(t *testing.T) {
+	bus := NewBus(4, 10)
+	userID, otherID := uuid.New(), uuid.New()
+
+	subscription, replay := bus.Subscribe(userID, "")
+	defer subscription.Close()
+	assert.Empty(t, replay)
+
+	walletID := uuid.New()
+	updatedAt := time.Date(2025, 3, 4, 10, 0, 0, 0, time.UTC)
+	bus.Publish(otherID, Event{Type: TypeWallet, EntityID: uuid.New(), Action: ActionCreated})
+	bus.Publish(userID, Event{Type: TypeWallet, EntityID: walletID, Action: ActionUpdated, UpdatedAt: coreTypes.NewTimestamp(updatedAt)})
+
+	select {
+	case event := <-subscription.Events():
+		assert.Equal(t, TypeWallet, event.Type)
+		assert.Equal(t, walletID, event.EntityID)
+		assert.Equal(t, ActionUpdated, event.Action)
+		assert.Equal(t, updatedAt, event.UpdatedAt.Time)
+		assert.NotZero(t, event.ID)
+	case <-time.After(time.Second):
+		t.Fatal("the event wasn't received")
+	}
+	// the other user's event isn't received
+	assert.Empty(t, subscription.Events())
+
+	t.Run("deletes default to now", func(t *testing.T) {
+		bus.Publish(userID, Event{Type: TypeWallet, EntityID: walletID, Action: ActionDeleted})
+		event := <-subscription.Events()
+		assert.WithinDuration(t, time.Now(), event.UpdatedAt.Time, time.Minute)
+	})
+}
+
+func TestBus_Replay(t *testing.T) {
+	bus := NewBus(4, 3)
+	userID := uuid.New()
+
+	var ids []string
+	for i := 0; i < 5; i++ {
+		bus.Publish(userID, Event{Type: TypeContact, EntityID: uuid.New(), Action: ActionCreated})
+		subscription, replay := bus.Subscribe(userID, "0")
+		subscription.Close()
+		ids = append(ids, replay[len(replay)-1].EventID())
+	}
+
+	subscription, replay := bus.Subscribe(userID, ids[2])
+	subscription.Close()
+	require.Len(t, replay, 2)
+	assert.Equal(t, ids[3], replay[0].EventID())
+	assert.Equal(t, ids[4], replay[1].EventID())
+
+	// an id older than the ones kept replays all of them, oldest first
+	subscription, replay = bus.Subscribe(userID, ids[0])
+	subscription.Close()
+	require.Len(t, replay, 3)
+	assert.Equal(t, ids[2], replay[0].EventID())
+
+	// the latest id replays nothing
+	subscription, replay = bus.Subscribe(userID, ids[4])
+	subscription.Close()
+	assert.Empty(t, replay)
+
+	// a malformed id replays nothing either
+	subscription, replay = bus.Subscribe(userID, "yesterday")
+	subscription.Close()
+	assert.Empty(t, replay)
+}
+
+func TestBus_SlowSubscriber(t *testing.T) {
+	bus := NewBus(2, 10)
+	userID := uuid.New()
+
+	slow, _ := bus.Subscribe(userID, "")
+	defer slow.Close()
+	for i := 0; i < 3; i++ {
+		bus.Publish(userID, Event{Type: TypeProject, EntityID: uuid.New(), Action: ActionUpdated})
+	}
+
+	// the buffered events drain, then the channel is closed
+	received := 0
+	for range slow.Events() {
+		received++
+	}
+	assert.Equal(t, 2, received)
+
+	// closing a dropped subscription is a no-op, later publishes go on
+	slow.Close()
+	bus.Publish(userID, Event{Type: TypeProject, EntityID: uuid.New(), Action: ActionUpdated})
+}
+
+func TestBus_Nil(t *testing.T) {
+	var bus *Bus
+	assert.NotPanics(t, func() {
+		bus.Publish(uuid.New(), Event{Type: TypeWallet, EntityID: uuid.New(), Action: ActionCreated})
+	})
+}
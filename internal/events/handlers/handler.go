@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/events"
+	"go.uber.org/zap"
+)
+
+// DefaultHeartbeat is how often an idle stream is sent a comment when none is configured
+const DefaultHeartbeat = 15 * time.Second
+
+type EventHandler struct {
+	handlers.BaseHandler
+	bus       *events.Bus
+	heartbeat time.Duration
+}
+
+// NewEventHandler creates the handler streaming the events of bus, idle streams are sent
+// a comment every heartbeat, DefaultHeartbeat when it is 0
+func NewEventHandler(bus *events.Bus, heartbeat time.Duration, logger *zap.Logger) *EventHandler {
+	if heartbeat <= 0 {
+		heartbeat = DefaultHeartbeat
+	}
+	return &EventHandler{
+		BaseHandler: handlers.NewBaseHandler(logger),
+		bus:         bus,
+		heartbeat:   heartbeat,
+	}
+}
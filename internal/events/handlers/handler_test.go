@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/events"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// streamServer serves the event stream to userID
+func streamServer(t *testing.T, handler *EventHandler, userID uuid.UUID) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.StreamEvents(w, r.WithContext(context.WithValue(r.Context(), requestcontext.UserIDKey, userID)))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// openStream connects to the stream and returns its lines
+func openStream(t *testing.T, url, lastEventID string) (*http.Response, <-chan string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+
+	lines := make(chan string, 64)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+	return resp, lines
+}
+
+// nextLine returns the next line starting with prefix
+func nextLine(t *testing.T, lines <-chan string, prefix string) string {
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case line, ok := <-lines:
+			require.True(t, ok, "the stream closed before a %q line", prefix)
+			if strings.HasPrefix(line, prefix) {
+				return strings.TrimPrefix(line, prefix)
+			}
+		case <-timeout:
+			t.Fatalf("no %q line received", prefix)
+		}
+	}
+}
+
+func TestEventHandler_StreamEvents(t *testing.T) {
+	userID := uuid.New()
+	bus := events.NewBus(8, 10)
+	server := streamServer(t, NewEventHandler(bus, time.Minute, zap.NewNop()), userID)
+
+	resp, lines := openStream(t, server.URL, "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+	assert.Equal(t, "3000", nextLine(t, lines, "retry: "))
+
+	contactID := uuid.New()
+	updatedAt := time.Date(2025, 3, 4, 10, 0, 0, 0, time.UTC)
+	bus.Publish(uuid.New(), events.Event{Type: events.TypeContact, EntityID: uuid.New(), Action: events.ActionCreated})
+	bus.Publish(userID, events.Event{Type: events.TypeContact, EntityID: contactID, Action: events.ActionUpdated, UpdatedAt: coreTypes.NewTimestamp(updatedAt)})
+
+	id := nextLine(t, lines, "id: ")
+	var data map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(nextLine(t, lines, "data: ")), &data))
+	assert.Equal(t, map[string]interface{}{
+		"type":      "contact",
+		"id":        contactID.String(),
+		"action":    "updated",
+		"updatedAt": "2025-03-04T10:00:00.000Z",
+	}, data)
+
+	t.Run("replays the events after Last-Event-ID", func(t *testing.T) {
+		walletID := uuid.New()
+		bus.Publish(userID, events.Event{Type: events.TypeWallet, EntityID: walletID, Action: events.ActionDeleted})
+
+		_, lines := openStream(t, server.URL, id)
+		nextLine(t, lines, "id: ")
+		require.NoError(t, json.Unmarshal([]byte(nextLine(t, lines, "data: ")), &data))
+		assert.Equal(t, walletID.String(), data["id"])
+		assert.Equal(t, "deleted", data["action"])
+	})
+
+	t.Run("sends heartbeats", func(t *testing.T) {
+		server := streamServer(t, NewEventHandler(bus, 10*time.Millisecond, zap.NewNop()), uuid.New())
+		_, lines := openStream(t, server.URL, "")
+		assert.Equal(t, "heartbeat", nextLine(t, lines, ": "))
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		NewEventHandler(bus, 0, zap.NewNop()).StreamEvents(w, httptest.NewRequest(http.MethodGet, "/events/stream", nil))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/events"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+)
+
+// reconnectDelay is how long clients wait before reconnecting a dropped stream
+const reconnectDelay = 3 * time.Second
+
+// StreamEvents godoc
+// @Summary Stream entity changes
+// @Description Streams the changes to the user's contacts, projects and wallets as server-sent events, each with the event id and the change as JSON data. Clients reconnecting with Last-Event-ID first get the changes since that event, out of the latest ones kept for the user. Idle streams are sent a comment every 15 seconds, and a client falling too far behind is disconnected to reconnect.
+// @Tags Events
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param Last-Event-ID header string false "ID of the last event received, to replay the ones after it"
+// @Success 200 {object} events.Event "text/event-stream of events"
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 429 {object} errors.ErrorResponse
+// @Router /events/stream [get]
+// @ID StreamEvents
+func (h *EventHandler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	// the stream outlives the server's write timeout
+	controller := http.NewResponseController(w)
+	controller.SetWriteDeadline(time.Time{})
+
+	// subscribed before the status goes out, a change made once the client sees it
+	// connected is streamed
+	subscription, replay := h.bus.Subscribe(userID, r.Header.Get("Last-Event-ID"))
+	defer subscription.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", reconnectDelay.Milliseconds()); err != nil {
+		return
+	}
+	for _, event := range replay {
+		if err := writeEvent(w, event); err != nil {
+			return
+		}
+	}
+	if err := controller.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-subscription.Events():
+			if !ok {
+				// fell too far behind, the client reconnects and replays what it missed
+				return
+			}
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+		if err := controller.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeEvent writes the event as a server-sent event with its id and JSON data
+func writeEvent(w io.Writer, event events.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\ndata: %s\n\n", event.EventID(), data)
+	return err
+}
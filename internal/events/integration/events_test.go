@@ -0,0 +1,309 @@
+package integration
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/config"
+	contactHandlers "github.com/Abdelrahman-habib/expense-tracker/internal/contacts/handlers"
+	contactRepository "github.com/Abdelrahman-habib/expense-tracker/internal/contacts/repository"
+	contactService "github.com/Abdelrahman-habib/expense-tracker/internal/contacts/service"
+	contactTypes "github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/events"
+	eventRoutes "github.com/Abdelrahman-habib/expense-tracker/internal/events/routes"
+	projectHandlers "github.com/Abdelrahman-habib/expense-tracker/internal/projects/handlers"
+	projectRepository "github.com/Abdelrahman-habib/expense-tracker/internal/projects/repository"
+	projectService "github.com/Abdelrahman-habib/expense-tracker/internal/projects/service"
+	projectTypes "github.com/Abdelrahman-habib/expense-tracker/internal/projects/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/validate"
+	walletHandlers "github.com/Abdelrahman-habib/expense-tracker/internal/wallets/handlers"
+	walletRepository "github.com/Abdelrahman-habib/expense-tracker/internal/wallets/repository"
+	walletService "github.com/Abdelrahman-habib/expense-tracker/internal/wallets/service"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"go.uber.org/zap"
+)
+
+type EventsIntegrationTestSuite struct {
+	suite.Suite
+	container testcontainers.Container
+	service   db.Service
+	pool      *pgxpool.Pool
+	server    *httptest.Server
+	userID    uuid.UUID
+	ctx       context.Context
+}
+
+func TestEventsIntegrationSuite(t *testing.T) {
+	suite.Run(t, new(EventsIntegrationTestSuite))
+}
+
+func (s *EventsIntegrationTestSuite) SetupSuite() {
+	s.ctx = context.Background()
+	s.userID = uuid.New()
+
+	var host, port string
+
+	if os.Getenv("CI") == "true" {
+		// Running in GitHub Actions, use service-based PostgreSQL
+		host = "localhost"
+		port = "5432"
+	} else {
+		// Running locally, use TestContainers
+		req := testcontainers.ContainerRequest{
+			Image:        "postgres:15-alpine",
+			ExposedPorts: []string{"5432/tcp"},
+			WaitingFor:   wait.ForListeningPort("5432/tcp"),
+			Env: map[string]string{
+				"POSTGRES_DB":       "testdb",
+				"POSTGRES_USER":     "test",
+				"POSTGRES_PASSWORD": "test",
+			},
+			NetworkMode: "bridge",
+		}
+
+		container, err := testcontainers.GenericContainer(s.ctx, testcontainers.GenericContainerRequest{
+			ContainerRequest: req,
+			Started:          true,
+		})
+		require.NoError(s.T(), err)
+		s.container = container
+
+		host, err = container.Host(s.ctx)
+		require.NoError(s.T(), err)
+		mappedPort, err := container.MappedPort(s.ctx, "5432")
+		require.NoError(s.T(), err)
+		port = mappedPort.Port()
+	}
+
+	cfg := config.DatabaseConfig{
+		Host:        host,
+		Port:        port,
+		Username:    "test",
+		Password:    "test",
+		Database:    "testdb",
+		Schema:      "public",
+		MaxConns:    5,
+		MinConns:    1,
+		MaxLifetime: time.Hour,
+		MaxIdleTime: time.Minute * 30,
+		HealthCheck: time.Minute,
+		SSLMode:     "disable",
+		SearchPath:  "public",
+	}
+
+	dbService := db.NewService(cfg)
+	s.service = dbService
+
+	pool, err := pgxpool.New(s.ctx, cfg.GetDSN())
+	require.NoError(s.T(), err)
+	s.pool = pool
+
+	require.NoError(s.T(), s.runMigrations())
+
+	_, err = s.pool.Exec(s.ctx, `
+		INSERT INTO users (user_id, clerk_ex_user_id, name, email)
+		VALUES ($1, 'evit_test_clerk_id', 'evit_Test User', 'evit_test@example.com')
+	`, s.userID)
+	require.NoError(s.T(), err)
+
+	logger := zap.NewNop()
+	queries := dbService.Queries()
+	bus := events.NewBus(0, 0)
+	limits := coreTypes.DefaultLimitPolicy()
+
+	wallets := walletHandlers.NewWalletHandler(walletService.NewWalletService(
+		walletRepository.NewWalletRepository(queries), validate.RoundHalfUp, nil, nil, bus, logger), limits, logger)
+	projects := projectHandlers.NewProjectHandler(projectService.NewProjectService(
+		projectRepository.NewProjectRepository(queries), projectTypes.PublishRules{}, nil, "", nil, bus, logger), limits, logger)
+	contacts := contactHandlers.NewContactHandler(contactService.NewContactService(
+		contactRepository.New(queries), nil, nil, contactTypes.ExportPolicy{}, nil, nil, bus, logger), limits, logger)
+
+	router := chi.NewRouter()
+	// every request is the test user's
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestcontext.UserIDKey, s.userID)))
+		})
+	})
+	router.Post("/wallets", wallets.CreateWallet)
+	router.Delete("/wallets/{id}", wallets.DeleteWallet)
+	router.Post("/projects", projects.CreateProject)
+	router.Put("/contacts/{id}", contacts.UpdateContact)
+	router.Post("/contacts", contacts.CreateContact)
+	eventRoutes.New(bus, time.Second, logger).RegisterRoutes(router)
+	s.server = httptest.NewServer(router)
+}
+
+func (s *EventsIntegrationTestSuite) TearDownSuite() {
+	if s.server != nil {
+		s.server.Close()
+	}
+	if s.pool != nil {
+		_, _ = s.pool.Exec(s.ctx, "DELETE FROM users WHERE user_id = $1", s.userID)
+		s.pool.Close()
+	}
+	if s.service != nil {
+		s.service.Close()
+	}
+	if s.container != nil && os.Getenv("CI") != "true" {
+		err := s.container.Terminate(s.ctx)
+		require.NoError(s.T(), err)
+	}
+}
+
+func (s *EventsIntegrationTestSuite) runMigrations() error {
+	migrationsDir := "../../db/sql/migrations"
+
+	sqlDB := stdlib.OpenDBFromPool(s.pool)
+	defer sqlDB.Close()
+
+	if err := goose.SetDialect("postgres"); err != nil {
+		return err
+	}
+	return goose.Up(sqlDB, migrationsDir)
+}
+
+// streamedEvent is an event as read off the stream
+type streamedEvent struct {
+	id   string
+	data map[string]interface{}
+}
+
+// openStream connects to the event stream, returning its events once it is connected
+func (s *EventsIntegrationTestSuite) openStream(lastEventID string) <-chan streamedEvent {
+	ctx, cancel := context.WithCancel(s.ctx)
+	s.T().Cleanup(cancel)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.server.URL+eventRoutes.StreamPath, nil)
+	s.Require().NoError(err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	s.Require().Equal("text/event-stream", resp.Header.Get("Content-Type"))
+
+	streamed := make(chan streamedEvent, 16)
+	go func() {
+		defer close(streamed)
+		defer resp.Body.Close()
+		var event streamedEvent
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "id: "):
+				event.id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "data: "):
+				json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event.data)
+			case line == "" && event.data != nil:
+				streamed <- event
+				event = streamedEvent{}
+			}
+		}
+	}()
+	return streamed
+}
+
+// next returns the next event of the stream
+func (s *EventsIntegrationTestSuite) next(streamed <-chan streamedEvent) streamedEvent {
+	select {
+	case event, ok := <-streamed:
+		s.Require().True(ok, "the stream closed")
+		return event
+	case <-time.After(5 * time.Second):
+		s.FailNow("no event received")
+		return streamedEvent{}
+	}
+}
+
+// do sends a request and decodes the data of the response
+func (s *EventsIntegrationTestSuite) do(method, path string, body interface{}) (int, map[string]interface{}) {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		s.Require().NoError(err)
+	}
+	req, err := http.NewRequest(method, s.server.URL+path, bytes.NewReader(payload))
+	s.Require().NoError(err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+
+	var response map[string]interface{}
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&response))
+	data, _ := response["data"].(map[string]interface{})
+	return resp.StatusCode, data
+}
+
+func (s *EventsIntegrationTestSuite) TestMutationsAreStreamed() {
+	streamed := s.openStream("")
+
+	code, wallet := s.do(http.MethodPost, "/wallets", map[string]interface{}{"name": "Checking", "currency": "USD"})
+	s.Require().Equal(http.StatusCreated, code)
+	created := s.next(streamed)
+	s.NotEmpty(created.id)
+	s.Equal("wallet", created.data["type"])
+	s.Equal(wallet["walletId"], created.data["id"])
+	s.Equal("created", created.data["action"])
+	createdAt, err := time.Parse(coreTypes.TimestampLayout, created.data["updatedAt"].(string))
+	s.Require().NoError(err)
+	walletUpdatedAt, err := time.Parse(coreTypes.TimestampLayout, wallet["updatedAt"].(string))
+	s.Require().NoError(err)
+	s.True(createdAt.Equal(walletUpdatedAt))
+
+	code, _ = s.do(http.MethodDelete, "/wallets/"+wallet["walletId"].(string), nil)
+	s.Require().Equal(http.StatusOK, code)
+	deleted := s.next(streamed)
+	s.Equal(wallet["walletId"], deleted.data["id"])
+	s.Equal("deleted", deleted.data["action"])
+
+	code, project := s.do(http.MethodPost, "/projects", map[string]interface{}{"name": "Renovation"})
+	s.Require().Equal(http.StatusCreated, code)
+	event := s.next(streamed)
+	s.Equal("project", event.data["type"])
+	s.Equal(project["projectId"], event.data["id"])
+	s.Equal("created", event.data["action"])
+
+	code, contact := s.do(http.MethodPost, "/contacts", map[string]interface{}{"name": "Jane"})
+	s.Require().Equal(http.StatusCreated, code)
+	s.Equal("created", s.next(streamed).data["action"])
+	code, _ = s.do(http.MethodPut, "/contacts/"+contact["contactId"].(string), map[string]interface{}{"name": "Jane Doe"})
+	s.Require().Equal(http.StatusOK, code)
+	event = s.next(streamed)
+	s.Equal("contact", event.data["type"])
+	s.Equal(contact["contactId"], event.data["id"])
+	s.Equal("updated", event.data["action"])
+
+	// a failed mutation isn't streamed, a client reconnecting after the wallet was
+	// deleted gets the changes since
+	code, _ = s.do(http.MethodPost, "/wallets", map[string]interface{}{"name": "", "currency": "USD"})
+	s.Require().Equal(http.StatusBadRequest, code)
+
+	replayed := s.openStream(deleted.id)
+	for _, action := range []string{"created", "created", "updated"} {
+		s.Equal(action, s.next(replayed).data["action"])
+	}
+}
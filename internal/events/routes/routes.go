@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/events"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/events/handlers"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// StreamPath is the path of the event stream below the API prefix
+const StreamPath = "/events/stream"
+
+// Router encapsulates the event routes setup
+type Router struct {
+	handler *handlers.EventHandler
+}
+
+// New creates a new event router streaming the events of bus
+func New(bus *events.Bus, heartbeat time.Duration, logger *zap.Logger) *Router {
+	return &Router{
+		handler: handlers.NewEventHandler(bus, heartbeat, logger),
+	}
+}
+
+// RegisterRoutes registers all event routes
+func (r *Router) RegisterRoutes(router chi.Router) {
+	router.Get(StreamPath, r.handler.StreamEvents)
+}
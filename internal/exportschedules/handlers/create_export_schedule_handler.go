@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/exportschedules/types"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+)
+
+// CreateExportSchedule godoc
+// @Summary Schedule an export
+// @Description Schedules a daily, weekly or monthly export of the user's contacts or wallets, delivered as a multipart POST to a webhook or as an email attachment. The first run is one cadence from now.
+// @Description Webhook deliveries carry an X-Export-Signature header, t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">, keyed with the signing secret only returned here.
+// @Tags Export Schedules
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body types.ExportSchedulePayload true "Export schedule request"
+// @Success 201 {object} payloads.Response{data=types.ExportSchedule}
+// @Failure 400 {object} errors.ErrorResponse "Invalid payload, a format the entity isn't exported in or email delivery isn't configured"
+// @Failure 401  {object} errors.ErrorResponse
+// @Failure 429  {object} errors.ErrorResponse
+// @Failure 500  {object} errors.ErrorResponse
+// @Router /export-schedules [post]
+// @ID CreateExportSchedule
+func (h *ExportScheduleHandler) CreateExportSchedule(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	var req types.ExportSchedulePayload
+	if !h.Bind(w, r, &req) {
+		return
+	}
+
+	schedule, err := h.service.CreateExportSchedule(r.Context(), userID, req)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.Created(schedule))
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// DeleteExportSchedule godoc
+// @Summary Delete an export schedule
+// @Description Deletes an export schedule with its run history, no further exports are delivered
+// @Tags Export Schedules
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Export schedule ID" format(uuid)
+// @Success 200 {object} payloads.Response
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401  {object} errors.ErrorResponse
+// @Failure 404  {object} errors.ErrorResponse
+// @Failure 429  {object} errors.ErrorResponse
+// @Failure 500  {object} errors.ErrorResponse
+// @Router /export-schedules/{id} [delete]
+// @ID DeleteExportSchedule
+func (h *ExportScheduleHandler) DeleteExportSchedule(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	scheduleID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	if err := h.service.DeleteExportSchedule(r.Context(), userID, scheduleID); err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.Deleted())
+}
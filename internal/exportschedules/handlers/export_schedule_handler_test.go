@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	coreErrors "github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/exportschedules/types"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+type mockExportScheduleService struct {
+	mock.Mock
+}
+
+func (m *mockExportScheduleService) CreateExportSchedule(ctx context.Context, userID uuid.UUID, payload types.ExportSchedulePayload) (types.ExportSchedule, error) {
+	args := m.Called(ctx, userID, payload)
+	return args.Get(0).(types.ExportSchedule), args.Error(1)
+}
+
+func (m *mockExportScheduleService) GetExportSchedule(ctx context.Context, userID, scheduleID uuid.UUID) (types.ExportSchedule, error) {
+	args := m.Called(ctx, userID, scheduleID)
+	return args.Get(0).(types.ExportSchedule), args.Error(1)
+}
+
+func (m *mockExportScheduleService) ListExportSchedules(ctx context.Context, userID uuid.UUID) ([]types.ExportSchedule, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]types.ExportSchedule), args.Error(1)
+}
+
+func (m *mockExportScheduleService) DeleteExportSchedule(ctx context.Context, userID, scheduleID uuid.UUID) error {
+	return m.Called(ctx, userID, scheduleID).Error(0)
+}
+
+func (m *mockExportScheduleService) ListExportRuns(ctx context.Context, userID, scheduleID uuid.UUID, limit int32) ([]types.ExportRun, error) {
+	args := m.Called(ctx, userID, scheduleID, limit)
+	return args.Get(0).([]types.ExportRun), args.Error(1)
+}
+
+func setupTest(t *testing.T) (*mockExportScheduleService, *ExportScheduleHandler) {
+	mockService := new(mockExportScheduleService)
+	handler := NewExportScheduleHandler(mockService, zap.NewNop())
+	return mockService, handler
+}
+
+// newRequest builds a request carrying the user and the schedule ID route parameter
+func newRequest(method, target, body string, userID uuid.UUID, scheduleID string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
+	if scheduleID != "" {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", scheduleID)
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+	}
+	return req.WithContext(ctx)
+}
+
+func TestExportScheduleHandler_CreateExportSchedule(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name           string
+		payload        string
+		setupMock      func(*mockExportScheduleService)
+		expectedStatus int
+	}{
+		{
+			name:    "schedules a webhook export",
+			payload: `{"entityType": "wallets", "format": "text/csv", "cadence": "weekly", "delivery": {"type": "webhook", "target": "https://example.com/exports"}}`,
+			setupMock: func(m *mockExportScheduleService) {
+				m.On("CreateExportSchedule", mock.Anything, userID, types.ExportSchedulePayload{
+					EntityType: types.EntityWallets,
+					Format:     "text/csv",
+					Cadence:    types.CadenceWeekly,
+					Delivery:   types.Delivery{Type: types.DeliveryWebhook, Target: "https://example.com/exports"},
+				}).Return(types.ExportSchedule{ScheduleID: uuid.New(), SigningSecret: "secret"}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "invalid cadence",
+			payload:        `{"entityType": "wallets", "format": "text/csv", "cadence": "hourly", "delivery": {"type": "email", "target": "finance@example.com"}}`,
+			setupMock:      func(m *mockExportScheduleService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "webhook target isn't https",
+			payload:        `{"entityType": "wallets", "format": "text/csv", "cadence": "daily", "delivery": {"type": "webhook", "target": "http://example.com/exports"}}`,
+			setupMock:      func(m *mockExportScheduleService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "webhook target isn't a URL",
+			payload:        `{"entityType": "wallets", "format": "text/csv", "cadence": "daily", "delivery": {"type": "webhook", "target": "example.com"}}`,
+			setupMock:      func(m *mockExportScheduleService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "email delivery not configured",
+			payload: `{"entityType": "wallets", "format": "text/csv", "cadence": "daily", "delivery": {"type": "email", "target": "finance@example.com"}}`,
+			setupMock: func(m *mockExportScheduleService) {
+				m.On("CreateExportSchedule", mock.Anything, userID, mock.Anything).
+					Return(types.ExportSchedule{}, coreErrors.NewValidationError("delivery: email delivery is not configured on this server"))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService, handler := setupTest(t)
+			tt.setupMock(mockService)
+
+			w := httptest.NewRecorder()
+			handler.CreateExportSchedule(w, newRequest(http.MethodPost, "/export-schedules", tt.payload, userID, ""))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestExportScheduleHandler_DeleteExportSchedule(t *testing.T) {
+	userID, scheduleID := uuid.New(), uuid.New()
+
+	t.Run("deletes the schedule", func(t *testing.T) {
+		mockService, handler := setupTest(t)
+		mockService.On("DeleteExportSchedule", mock.Anything, userID, scheduleID).Return(nil)
+
+		w := httptest.NewRecorder()
+		handler.DeleteExportSchedule(w, newRequest(http.MethodDelete, "/export-schedules/"+scheduleID.String(), "", userID, scheduleID.String()))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("missing schedule", func(t *testing.T) {
+		mockService, handler := setupTest(t)
+		mockService.On("DeleteExportSchedule", mock.Anything, userID, scheduleID).Return(repository.ErrNotFound)
+
+		w := httptest.NewRecorder()
+		handler.DeleteExportSchedule(w, newRequest(http.MethodDelete, "/export-schedules/"+scheduleID.String(), "", userID, scheduleID.String()))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestExportScheduleHandler_ListExportRuns(t *testing.T) {
+	userID, scheduleID := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name           string
+		query          string
+		limit          int32
+		expectedStatus int
+	}{
+		{name: "default limit", limit: types.DefaultRunsLimit, expectedStatus: http.StatusOK},
+		{name: "custom limit", query: "?limit=5", limit: 5, expectedStatus: http.StatusOK},
+		{name: "capped limit", query: "?limit=1000", limit: types.MaxRunsLimit, expectedStatus: http.StatusOK},
+		{name: "invalid limit", query: "?limit=0", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService, handler := setupTest(t)
+			if tt.expectedStatus == http.StatusOK {
+				mockService.On("ListExportRuns", mock.Anything, userID, scheduleID, tt.limit).
+					Return([]types.ExportRun{{ScheduleID: scheduleID, Status: types.RunSucceeded}}, nil)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ListExportRuns(w, newRequest(http.MethodGet, "/export-schedules/"+scheduleID.String()+"/runs"+tt.query, "", userID, scheduleID.String()))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response map[string]interface{}
+				assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				assert.Len(t, response["data"], 1)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// GetExportSchedule godoc
+// @Summary Get an export schedule
+// @Description Gets an export schedule with its next due time
+// @Tags Export Schedules
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Export schedule ID" format(uuid)
+// @Success 200 {object} payloads.Response{data=types.ExportSchedule}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401  {object} errors.ErrorResponse
+// @Failure 404  {object} errors.ErrorResponse
+// @Failure 429  {object} errors.ErrorResponse
+// @Failure 500  {object} errors.ErrorResponse
+// @Router /export-schedules/{id} [get]
+// @ID GetExportSchedule
+func (h *ExportScheduleHandler) GetExportSchedule(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	scheduleID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	schedule, err := h.service.GetExportSchedule(r.Context(), userID, scheduleID)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.OK(schedule))
+}
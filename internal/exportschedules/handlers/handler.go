@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/exportschedules/service"
+	"go.uber.org/zap"
+)
+
+type ExportScheduleHandler struct {
+	handlers.BaseHandler
+	service service.ExportScheduleService
+}
+
+func NewExportScheduleHandler(service service.ExportScheduleService, logger *zap.Logger) *ExportScheduleHandler {
+	return &ExportScheduleHandler{
+		BaseHandler: handlers.NewBaseHandler(logger),
+		service:     service,
+	}
+}
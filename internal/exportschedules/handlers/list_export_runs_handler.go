@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/exportschedules/types"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// ListExportRuns godoc
+// @Summary List the runs of an export schedule
+// @Description Lists the deliveries of an export schedule newest first, failed ones with the reason
+// @Tags Export Schedules
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Export schedule ID" format(uuid)
+// @Param limit query integer false "Maximum number of runs, 20 by default and up to 100"
+// @Success 200 {object} payloads.Response{data=[]types.ExportRun}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401  {object} errors.ErrorResponse
+// @Failure 404  {object} errors.ErrorResponse
+// @Failure 429  {object} errors.ErrorResponse
+// @Failure 500  {object} errors.ErrorResponse
+// @Router /export-schedules/{id}/runs [get]
+// @ID ListExportRuns
+func (h *ExportScheduleHandler) ListExportRuns(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	scheduleID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	if !h.CheckQueryParams(w, r, "limit") {
+		return
+	}
+
+	limit, err := types.ParseRunsLimit(r.URL.Query())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	runs, err := h.service.ListExportRuns(r.Context(), userID, scheduleID, limit)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.List(runs, len(runs)))
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+)
+
+// ListExportSchedules godoc
+// @Summary List export schedules
+// @Description Lists the user's export schedules, oldest first
+// @Tags Export Schedules
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} payloads.Response{data=[]types.ExportSchedule}
+// @Failure 401  {object} errors.ErrorResponse
+// @Failure 429  {object} errors.ErrorResponse
+// @Failure 500  {object} errors.ErrorResponse
+// @Router /export-schedules [get]
+// @ID ListExportSchedules
+func (h *ExportScheduleHandler) ListExportSchedules(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	schedules, err := h.service.ListExportSchedules(r.Context(), userID)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.List(schedules, len(schedules)))
+}
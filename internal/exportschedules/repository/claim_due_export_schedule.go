@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/exportschedules/types"
+)
+
+func (r *exportScheduleRepository) ClaimDueExportSchedule(ctx context.Context, now, claimedUntil time.Time) (types.ExportSchedule, bool, error) {
+	schedule, err := r.q.ClaimDueExportSchedule(ctx, db.ClaimDueExportScheduleParams{
+		Now:          toTimestamp(now),
+		ClaimedUntil: toTimestamp(claimedUntil),
+	})
+	if err == pgx.ErrNoRows {
+		return types.ExportSchedule{}, false, nil
+	}
+	if err != nil {
+		return types.ExportSchedule{}, false, errors.HandleRepositoryError(err, "claim", "export schedule")
+	}
+
+	return toExportSchedule(schedule), true, nil
+}
+
+func (r *exportScheduleRepository) RescheduleExportSchedule(ctx context.Context, scheduleID uuid.UUID, nextRunAt time.Time) error {
+	err := r.q.RescheduleExportSchedule(ctx, db.RescheduleExportScheduleParams{
+		ScheduleID: scheduleID,
+		NextRunAt:  toTimestamp(nextRunAt),
+	})
+	if err != nil {
+		return errors.HandleRepositoryError(err, "reschedule", "export schedule")
+	}
+	return nil
+}
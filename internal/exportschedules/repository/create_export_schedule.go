@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/exportschedules/types"
+)
+
+func (r *exportScheduleRepository) CreateExportSchedule(ctx context.Context, userID uuid.UUID, payload types.ExportSchedulePayload, signingSecret string, nextRunAt time.Time) (types.ExportSchedule, error) {
+	schedule, err := r.q.CreateExportSchedule(ctx, db.CreateExportScheduleParams{
+		UserID:         userID,
+		EntityType:     payload.EntityType,
+		Format:         payload.Format,
+		Cadence:        payload.Cadence,
+		DeliveryType:   payload.Delivery.Type,
+		DeliveryTarget: payload.Delivery.Target,
+		SigningSecret:  signingSecret,
+		NextRunAt:      toTimestamp(nextRunAt),
+	})
+	if err != nil {
+		return types.ExportSchedule{}, errors.HandleRepositoryError(err, "create", "export schedule")
+	}
+
+	return toExportSchedule(schedule), nil
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+)
+
+func (r *exportScheduleRepository) DeleteExportSchedule(ctx context.Context, userID, scheduleID uuid.UUID) error {
+	deleted, err := r.q.DeleteExportSchedule(ctx, db.DeleteExportScheduleParams{
+		ScheduleID: scheduleID,
+		UserID:     userID,
+	})
+	if err != nil {
+		return errors.HandleRepositoryError(err, "delete", "export schedule")
+	}
+	if deleted == 0 {
+		return fmt.Errorf("delete export schedule %s: %w", scheduleID, repository.ErrNotFound)
+	}
+	return nil
+}
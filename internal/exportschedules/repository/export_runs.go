@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/exportschedules/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
+)
+
+func (r *exportScheduleRepository) RecordExportRun(ctx context.Context, run types.ExportRun) (types.ExportRun, error) {
+	recorded, err := r.q.CreateExportRun(ctx, db.CreateExportRunParams{
+		ScheduleID:   run.ScheduleID,
+		Status:       run.Status,
+		Rows:         int32(run.Rows),
+		Bytes:        run.Bytes,
+		Error:        utils.ToNullableText(run.Error),
+		ScheduledFor: toTimestamp(run.ScheduledFor.Time),
+		StartedAt:    toTimestamp(run.StartedAt.Time),
+		FinishedAt:   toTimestamp(run.FinishedAt.Time),
+	})
+	if err != nil {
+		return types.ExportRun{}, errors.HandleRepositoryError(err, "record", "export run")
+	}
+
+	return toExportRun(recorded), nil
+}
+
+func (r *exportScheduleRepository) ListExportRuns(ctx context.Context, userID, scheduleID uuid.UUID, limit int32) ([]types.ExportRun, error) {
+	runs, err := r.q.ListExportRuns(ctx, db.ListExportRunsParams{
+		ScheduleID: scheduleID,
+		UserID:     userID,
+		Limit:      limit,
+	})
+	if err != nil {
+		return nil, errors.HandleRepositoryError(err, "list", "export runs")
+	}
+
+	result := make([]types.ExportRun, len(runs))
+	for i, run := range runs {
+		result[i] = toExportRun(run)
+	}
+	return result, nil
+}
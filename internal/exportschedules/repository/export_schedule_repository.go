@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+)
+
+type exportScheduleRepository struct {
+	q *db.Queries
+}
+
+// New creates a new export schedule repository
+func New(q *db.Queries) Repository {
+	return &exportScheduleRepository{q: q}
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/exportschedules/types"
+)
+
+func (r *exportScheduleRepository) GetExportSchedule(ctx context.Context, userID, scheduleID uuid.UUID) (types.ExportSchedule, error) {
+	schedule, err := r.q.GetExportSchedule(ctx, db.GetExportScheduleParams{
+		ScheduleID: scheduleID,
+		UserID:     userID,
+	})
+	if err != nil {
+		return types.ExportSchedule{}, errors.HandleRepositoryError(err, "get", "export schedule")
+	}
+
+	return toExportSchedule(schedule), nil
+}
+
+func (r *exportScheduleRepository) ListExportSchedules(ctx context.Context, userID uuid.UUID) ([]types.ExportSchedule, error) {
+	schedules, err := r.q.ListExportSchedules(ctx, userID)
+	if err != nil {
+		return nil, errors.HandleRepositoryError(err, "list", "export schedules")
+	}
+
+	result := make([]types.ExportSchedule, len(schedules))
+	for i, schedule := range schedules {
+		result[i] = toExportSchedule(schedule)
+	}
+	return result, nil
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/exportschedules/types"
+)
+
+// Repository defines the interface for export schedule operations
+type Repository interface {
+	// CreateExportSchedule stores a schedule first due at nextRunAt
+	CreateExportSchedule(ctx context.Context, userID uuid.UUID, payload types.ExportSchedulePayload, signingSecret string, nextRunAt time.Time) (types.ExportSchedule, error)
+
+	// GetExportSchedule retrieves a schedule by ID and user ID
+	GetExportSchedule(ctx context.Context, userID, scheduleID uuid.UUID) (types.ExportSchedule, error)
+
+	// ListExportSchedules retrieves the user's schedules, oldest first
+	ListExportSchedules(ctx context.Context, userID uuid.UUID) ([]types.ExportSchedule, error)
+
+	// DeleteExportSchedule deletes a schedule with its runs
+	DeleteExportSchedule(ctx context.Context, userID, scheduleID uuid.UUID) error
+
+	// ClaimDueExportSchedule holds the earliest schedule due at now until claimedUntil and
+	// returns it, reporting false when no schedule is due
+	ClaimDueExportSchedule(ctx context.Context, now, claimedUntil time.Time) (types.ExportSchedule, bool, error)
+
+	// RecordExportRun stores the run of a claimed schedule
+	RecordExportRun(ctx context.Context, run types.ExportRun) (types.ExportRun, error)
+
+	// RescheduleExportSchedule releases the claim of a schedule, it is due again at nextRunAt
+	RescheduleExportSchedule(ctx context.Context, scheduleID uuid.UUID, nextRunAt time.Time) error
+
+	// ListExportRuns retrieves up to limit runs of a schedule of the user, newest first
+	ListExportRuns(ctx context.Context, userID, scheduleID uuid.UUID, limit int32) ([]types.ExportRun, error)
+}
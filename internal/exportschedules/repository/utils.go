@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"time"
+
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/exportschedules/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
+)
+
+// toExportSchedule converts a db.ExportSchedule to domain types.ExportSchedule
+func toExportSchedule(s db.ExportSchedule) types.ExportSchedule {
+	return types.ExportSchedule{
+		ScheduleID: s.ScheduleID,
+		UserID:     s.UserID,
+		EntityType: s.EntityType,
+		Format:     s.Format,
+		Cadence:    s.Cadence,
+		Delivery: types.Delivery{
+			Type:   s.DeliveryType,
+			Target: s.DeliveryTarget,
+		},
+		SigningSecret: s.SigningSecret,
+		NextRunAt:     coreTypes.NewTimestamp(s.NextRunAt.Time),
+		CreatedAt:     coreTypes.NewTimestamp(s.CreatedAt.Time),
+		UpdatedAt:     coreTypes.NewTimestamp(s.UpdatedAt.Time),
+	}
+}
+
+// toExportRun converts a db.ExportRun to domain types.ExportRun
+func toExportRun(r db.ExportRun) types.ExportRun {
+	return types.ExportRun{
+		RunID:        r.RunID,
+		ScheduleID:   r.ScheduleID,
+		Status:       r.Status,
+		Rows:         int(r.Rows),
+		Bytes:        r.Bytes,
+		Error:        utils.PgtextToStringPtr(r.Error),
+		ScheduledFor: coreTypes.NewTimestamp(r.ScheduledFor.Time),
+		StartedAt:    coreTypes.NewTimestamp(r.StartedAt.Time),
+		FinishedAt:   coreTypes.NewTimestamp(r.FinishedAt.Time),
+	}
+}
+
+// toTimestamp converts a time to a timestamp in UTC, the columns have no time zone
+func toTimestamp(t time.Time) pgtype.Timestamp {
+	return pgtype.Timestamp{Time: t.UTC(), Valid: true}
+}
@@ -0,0 +1,40 @@
+package routes
+
+import (
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/mail"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/exportschedules/handlers"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/exportschedules/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/exportschedules/service"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// Router encapsulates the export schedule routes setup
+type Router struct {
+	handler *handlers.ExportScheduleHandler
+}
+
+// New creates a new export schedule router, mailer is nil when email delivery isn't configured
+func New(dbService db.Service, mailer mail.Mailer, logger *zap.Logger) *Router {
+	repo := repository.New(dbService.Queries())
+	scheduleService := service.NewExportScheduleService(repo, service.NewExporters(dbService.Queries()), mailer, logger)
+	handler := handlers.NewExportScheduleHandler(scheduleService, logger)
+
+	return &Router{
+		handler: handler,
+	}
+}
+
+// RegisterRoutes registers all export schedule routes
+func (r *Router) RegisterRoutes(router chi.Router) {
+	router.Route("/export-schedules", func(router chi.Router) {
+		router.Get("/", r.handler.ListExportSchedules)
+		router.Post("/", r.handler.CreateExportSchedule)
+		router.Route("/{id}", func(router chi.Router) {
+			router.Get("/", r.handler.GetExportSchedule)
+			router.Delete("/", r.handler.DeleteExportSchedule)
+			router.Get("/runs", r.handler.ListExportRuns)
+		})
+	})
+}
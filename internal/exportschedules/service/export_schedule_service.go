@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/logging"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/mail"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/exportschedules/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/exportschedules/types"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// signingSecretBytes is the number of random bytes of a webhook signing secret
+const signingSecretBytes = 32
+
+type ExportScheduleService interface {
+	CreateExportSchedule(ctx context.Context, userID uuid.UUID, payload types.ExportSchedulePayload) (types.ExportSchedule, error)
+	GetExportSchedule(ctx context.Context, userID, scheduleID uuid.UUID) (types.ExportSchedule, error)
+	ListExportSchedules(ctx context.Context, userID uuid.UUID) ([]types.ExportSchedule, error)
+	DeleteExportSchedule(ctx context.Context, userID, scheduleID uuid.UUID) error
+	ListExportRuns(ctx context.Context, userID, scheduleID uuid.UUID, limit int32) ([]types.ExportRun, error)
+}
+
+type exportScheduleService struct {
+	repo      repository.Repository
+	exporters map[string]Exporter
+	// email is whether a mailer is configured, schedules can't be emailed without one
+	email  bool
+	now    func() time.Time
+	logger *zap.Logger
+}
+
+// NewExportScheduleService returns the service managing export schedules, exporters are
+// the entities schedules can export and mailer, nil when none is configured, sends the
+// emailed ones
+func NewExportScheduleService(repo repository.Repository, exporters map[string]Exporter, mailer mail.Mailer, logger *zap.Logger) ExportScheduleService {
+	return &exportScheduleService{
+		repo:      repo,
+		exporters: exporters,
+		email:     mailer != nil,
+		now:       time.Now,
+		logger:    logger.With(zap.String("component", "export_schedule_service")),
+	}
+}
+
+// operation starts the log of an export schedule service method, scheduleID is uuid.Nil
+// when the method doesn't work on one schedule
+func (s *exportScheduleService) operation(name string, userID, scheduleID uuid.UUID, fields ...zap.Field) *logging.Operation {
+	logger := logging.WithEntity(logging.WithUser(s.logger, userID), "export_schedule", scheduleID)
+	return logging.Start(logger, "ExportScheduleService."+name, fields...)
+}
+
+// CreateExportSchedule schedules the export, first due one cadence from now. The signing
+// secret of its webhook deliveries is only returned here.
+func (s *exportScheduleService) CreateExportSchedule(ctx context.Context, userID uuid.UUID, payload types.ExportSchedulePayload) (_ types.ExportSchedule, err error) {
+	op := s.operation("CreateExportSchedule", userID, uuid.Nil,
+		zap.String("entity_type", payload.EntityType),
+		zap.String("cadence", payload.Cadence),
+		zap.String("delivery", payload.Delivery.Type))
+	defer op.End(&err)
+
+	exporter, ok := s.exporters[payload.EntityType]
+	if !ok {
+		return types.ExportSchedule{}, errors.NewValidationError("entity_type: %q can't be exported", payload.EntityType)
+	}
+	if _, ok := exporter.Formats[payload.Format]; !ok {
+		return types.ExportSchedule{}, errors.NewValidationError("format: %s can't be exported as %q", payload.EntityType, payload.Format)
+	}
+	switch payload.Cadence {
+	case types.CadenceDaily, types.CadenceWeekly, types.CadenceMonthly:
+	default:
+		return types.ExportSchedule{}, errors.NewValidationError("cadence: must be daily, weekly or monthly")
+	}
+	if payload.Delivery.Type == types.DeliveryEmail && !s.email {
+		return types.ExportSchedule{}, errors.NewValidationError("delivery: email delivery is not configured on this server")
+	}
+
+	secret, err := newSigningSecret()
+	if err != nil {
+		return types.ExportSchedule{}, err
+	}
+
+	schedule, err := s.repo.CreateExportSchedule(ctx, userID, payload, secret, types.Next(payload.Cadence, s.now().UTC()))
+	if err != nil {
+		return types.ExportSchedule{}, err
+	}
+	op.With(zap.Stringer(logging.FieldEntityID, schedule.ScheduleID))
+	return schedule, nil
+}
+
+// newSigningSecret returns a random hex encoded secret to sign webhook deliveries with
+func newSigningSecret() (string, error) {
+	secret := make([]byte, signingSecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("generate signing secret: %w", err)
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+func (s *exportScheduleService) GetExportSchedule(ctx context.Context, userID, scheduleID uuid.UUID) (_ types.ExportSchedule, err error) {
+	defer s.operation("GetExportSchedule", userID, scheduleID).End(&err)
+
+	schedule, err := s.repo.GetExportSchedule(ctx, userID, scheduleID)
+	if err != nil {
+		return types.ExportSchedule{}, err
+	}
+	schedule.SigningSecret = ""
+	return schedule, nil
+}
+
+func (s *exportScheduleService) ListExportSchedules(ctx context.Context, userID uuid.UUID) (_ []types.ExportSchedule, err error) {
+	defer s.operation("ListExportSchedules", userID, uuid.Nil).End(&err)
+
+	schedules, err := s.repo.ListExportSchedules(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range schedules {
+		schedules[i].SigningSecret = ""
+	}
+	return schedules, nil
+}
+
+func (s *exportScheduleService) DeleteExportSchedule(ctx context.Context, userID, scheduleID uuid.UUID) (err error) {
+	defer s.operation("DeleteExportSchedule", userID, scheduleID).End(&err)
+	return s.repo.DeleteExportSchedule(ctx, userID, scheduleID)
+}
+
+// ListExportRuns returns up to limit runs of the schedule, newest first
+func (s *exportScheduleService) ListExportRuns(ctx context.Context, userID, scheduleID uuid.UUID, limit int32) (_ []types.ExportRun, err error) {
+	defer s.operation("ListExportRuns", userID, scheduleID, zap.Int32("limit", limit)).End(&err)
+
+	// an empty history can't tell a schedule that hasn't run from a missing one
+	if _, err := s.repo.GetExportSchedule(ctx, userID, scheduleID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListExportRuns(ctx, userID, scheduleID, limit)
+}
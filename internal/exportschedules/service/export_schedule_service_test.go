@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/mail"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/exportschedules/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type mockExportScheduleRepository struct {
+	mock.Mock
+}
+
+func (m *mockExportScheduleRepository) CreateExportSchedule(ctx context.Context, userID uuid.UUID, payload types.ExportSchedulePayload, signingSecret string, nextRunAt time.Time) (types.ExportSchedule, error) {
+	args := m.Called(ctx, userID, payload, signingSecret, nextRunAt)
+	return args.Get(0).(types.ExportSchedule), args.Error(1)
+}
+
+func (m *mockExportScheduleRepository) GetExportSchedule(ctx context.Context, userID, scheduleID uuid.UUID) (types.ExportSchedule, error) {
+	args := m.Called(ctx, userID, scheduleID)
+	return args.Get(0).(types.ExportSchedule), args.Error(1)
+}
+
+func (m *mockExportScheduleRepository) ListExportSchedules(ctx context.Context, userID uuid.UUID) ([]types.ExportSchedule, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]types.ExportSchedule), args.Error(1)
+}
+
+func (m *mockExportScheduleRepository) DeleteExportSchedule(ctx context.Context, userID, scheduleID uuid.UUID) error {
+	return m.Called(ctx, userID, scheduleID).Error(0)
+}
+
+func (m *mockExportScheduleRepository) ClaimDueExportSchedule(ctx context.Context, now, claimedUntil time.Time) (types.ExportSchedule, bool, error) {
+	args := m.Called(ctx, now, claimedUntil)
+	return args.Get(0).(types.ExportSchedule), args.Bool(1), args.Error(2)
+}
+
+func (m *mockExportScheduleRepository) RecordExportRun(ctx context.Context, run types.ExportRun) (types.ExportRun, error) {
+	args := m.Called(ctx, run)
+	return args.Get(0).(types.ExportRun), args.Error(1)
+}
+
+func (m *mockExportScheduleRepository) RescheduleExportSchedule(ctx context.Context, scheduleID uuid.UUID, nextRunAt time.Time) error {
+	return m.Called(ctx, scheduleID, nextRunAt).Error(0)
+}
+
+func (m *mockExportScheduleRepository) ListExportRuns(ctx context.Context, userID, scheduleID uuid.UUID, limit int32) ([]types.ExportRun, error) {
+	args := m.Called(ctx, userID, scheduleID, limit)
+	return args.Get(0).([]types.ExportRun), args.Error(1)
+}
+
+// mockMailer keeps the messages it is asked to send with their attachments read
+type mockMailer struct {
+	messages    []mail.Message
+	attachments [][]byte
+	err         error
+}
+
+func (m *mockMailer) Send(ctx context.Context, msg mail.Message) error {
+	m.messages = append(m.messages, msg)
+	for _, attachment := range msg.Attachments {
+		content, err := io.ReadAll(attachment.Content)
+		if err != nil {
+			return err
+		}
+		m.attachments = append(m.attachments, content)
+	}
+	return m.err
+}
+
+// testExporters exports rows lines of text, as text/csv only
+func testExporters(rows int) map[string]Exporter {
+	return map[string]Exporter{
+		types.EntityWallets: {
+			Formats: map[string]string{"text/csv": "csv"},
+			Write: func(ctx context.Context, userID uuid.UUID, format string, w io.Writer) (int, error) {
+				for i := 0; i < rows; i++ {
+					if _, err := io.WriteString(w, "wallet\n"); err != nil {
+						return i, err
+					}
+				}
+				return rows, nil
+			},
+		},
+	}
+}
+
+func TestExportScheduleService_CreateExportSchedule(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	now := time.Date(2024, 1, 31, 9, 0, 0, 0, time.UTC)
+
+	webhook := types.Delivery{Type: types.DeliveryWebhook, Target: "https://example.com/exports"}
+	email := types.Delivery{Type: types.DeliveryEmail, Target: "finance@example.com"}
+
+	tests := []struct {
+		name      string
+		payload   types.ExportSchedulePayload
+		mailer    mail.Mailer
+		nextRunAt time.Time
+		errMsg    string
+	}{
+		{
+			name:      "schedules a monthly webhook export",
+			payload:   types.ExportSchedulePayload{EntityType: types.EntityWallets, Format: "text/csv", Cadence: types.CadenceMonthly, Delivery: webhook},
+			nextRunAt: time.Date(2024, 3, 2, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "schedules an emailed export with a mailer",
+			payload:   types.ExportSchedulePayload{EntityType: types.EntityWallets, Format: "text/csv", Cadence: types.CadenceDaily, Delivery: email},
+			mailer:    &mockMailer{},
+			nextRunAt: now.AddDate(0, 0, 1),
+		},
+		{
+			name:    "refuses an unknown entity",
+			payload: types.ExportSchedulePayload{EntityType: "projects", Format: "text/csv", Cadence: types.CadenceDaily, Delivery: webhook},
+			errMsg:  `entity_type: "projects" can't be exported`,
+		},
+		{
+			name:    "refuses a format the entity isn't exported in",
+			payload: types.ExportSchedulePayload{EntityType: types.EntityWallets, Format: "text/vcard", Cadence: types.CadenceDaily, Delivery: webhook},
+			errMsg:  `format: wallets can't be exported as "text/vcard"`,
+		},
+		{
+			name:    "refuses email delivery without a mailer",
+			payload: types.ExportSchedulePayload{EntityType: types.EntityWallets, Format: "text/csv", Cadence: types.CadenceDaily, Delivery: email},
+			errMsg:  "delivery: email delivery is not configured on this server",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mockExportScheduleRepository)
+			service := NewExportScheduleService(mockRepo, testExporters(1), tt.mailer, zap.NewNop()).(*exportScheduleService)
+			service.now = func() time.Time { return now }
+
+			if tt.errMsg == "" {
+				mockRepo.On("CreateExportSchedule", ctx, userID, tt.payload, mock.MatchedBy(func(secret string) bool {
+					return len(secret) == 2*signingSecretBytes
+				}), tt.nextRunAt).Return(types.ExportSchedule{ScheduleID: uuid.New(), SigningSecret: "secret"}, nil)
+			}
+
+			schedule, err := service.CreateExportSchedule(ctx, userID, tt.payload)
+			if tt.errMsg != "" {
+				require.Error(t, err)
+				assert.True(t, errors.IsErrorType(err, errors.ErrorTypeValidation))
+				assert.Contains(t, err.Error(), tt.errMsg)
+				mockRepo.AssertNotCalled(t, "CreateExportSchedule")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "secret", schedule.SigningSecret)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestExportScheduleService_HidesSigningSecret(t *testing.T) {
+	ctx := context.Background()
+	userID, scheduleID := uuid.New(), uuid.New()
+	mockRepo := new(mockExportScheduleRepository)
+	service := NewExportScheduleService(mockRepo, testExporters(1), nil, zap.NewNop())
+
+	mockRepo.On("GetExportSchedule", ctx, userID, scheduleID).Return(types.ExportSchedule{ScheduleID: scheduleID, SigningSecret: "secret"}, nil)
+	mockRepo.On("ListExportSchedules", ctx, userID).Return([]types.ExportSchedule{{ScheduleID: scheduleID, SigningSecret: "secret"}}, nil)
+
+	schedule, err := service.GetExportSchedule(ctx, userID, scheduleID)
+	require.NoError(t, err)
+	assert.Empty(t, schedule.SigningSecret)
+
+	schedules, err := service.ListExportSchedules(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, schedules, 1)
+	assert.Empty(t, schedules[0].SigningSecret)
+}
+
+func TestExportScheduleService_ListExportRuns(t *testing.T) {
+	ctx := context.Background()
+	userID, scheduleID := uuid.New(), uuid.New()
+
+	t.Run("lists the runs of the schedule", func(t *testing.T) {
+		mockRepo := new(mockExportScheduleRepository)
+		service := NewExportScheduleService(mockRepo, testExporters(1), nil, zap.NewNop())
+		runs := []types.ExportRun{{ScheduleID: scheduleID, Status: types.RunSucceeded}}
+		mockRepo.On("GetExportSchedule", ctx, userID, scheduleID).Return(types.ExportSchedule{ScheduleID: scheduleID}, nil)
+		mockRepo.On("ListExportRuns", ctx, userID, scheduleID, int32(5)).Return(runs, nil)
+
+		result, err := service.ListExportRuns(ctx, userID, scheduleID, 5)
+		require.NoError(t, err)
+		assert.Equal(t, runs, result)
+	})
+
+	t.Run("missing schedule", func(t *testing.T) {
+		mockRepo := new(mockExportScheduleRepository)
+		service := NewExportScheduleService(mockRepo, testExporters(1), nil, zap.NewNop())
+		mockRepo.On("GetExportSchedule", ctx, userID, scheduleID).Return(types.ExportSchedule{}, repository.ErrNotFound)
+
+		_, err := service.ListExportRuns(ctx, userID, scheduleID, 5)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+		mockRepo.AssertNotCalled(t, "ListExportRuns")
+	})
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"io"
+
+	contactRepository "github.com/Abdelrahman-habib/expense-tracker/internal/contacts/repository"
+	contactService "github.com/Abdelrahman-habib/expense-tracker/internal/contacts/service"
+	contactTypes "github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/exportschedules/types"
+	walletRepository "github.com/Abdelrahman-habib/expense-tracker/internal/wallets/repository"
+	walletService "github.com/Abdelrahman-habib/expense-tracker/internal/wallets/service"
+	walletTypes "github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+	"github.com/google/uuid"
+)
+
+// Exporter writes every entity of one type the user has, the way the entity's own
+// export endpoints do
+type Exporter struct {
+	// Formats maps the media types the entity is exported in to the extension of their files
+	Formats map[string]string
+	// Write writes the user's entities to w in format, returning how many it wrote
+	Write func(ctx context.Context, userID uuid.UUID, format string, w io.Writer) (int, error)
+}
+
+// NewExporters returns the exporters of the entities a schedule can export, by entity type
+func NewExporters(q *db.Queries) map[string]Exporter {
+	contacts := contactRepository.New(q)
+	wallets := walletRepository.NewWalletRepository(q)
+
+	return map[string]Exporter{
+		types.EntityContacts: {
+			Formats: contactTypes.ExportExtensions,
+			Write: func(ctx context.Context, userID uuid.UUID, format string, w io.Writer) (int, error) {
+				return contactService.WriteContacts(ctx, contacts, userID, format, w)
+			},
+		},
+		types.EntityWallets: {
+			Formats: walletTypes.ExportExtensions,
+			Write: func(ctx context.Context, userID uuid.UUID, format string, w io.Writer) (int, error) {
+				return walletService.WriteWallets(ctx, wallets, userID, format, w)
+			},
+		},
+	}
+}
@@ -0,0 +1,352 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/config"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/mail"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/exportschedules/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/exportschedules/types"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultSchedulerInterval = time.Minute
+	DefaultSchedulerLease    = 15 * time.Minute
+	DefaultWebhookTimeout    = 30 * time.Second
+)
+
+// The reasons a failed run keeps, runs are shown to the user so they tell which step
+// failed and leave the details of the error to the logs
+const (
+	reasonExportFailed    = "the export could not be written"
+	reasonWebhookFailed   = "the webhook could not be delivered"
+	reasonEmailFailed     = "the email could not be sent"
+	reasonEmailDisabled   = "email delivery is not configured"
+	reasonUnknownDelivery = "the delivery type is not supported"
+)
+
+// runError is the error of a failed run with the reason the run keeps
+type runError struct {
+	reason string
+	err    error
+}
+
+func (e *runError) Error() string { return e.err.Error() }
+func (e *runError) Unwrap() error { return e.err }
+
+// failed tags err with the reason the run keeps, nil stays nil
+func failed(reason string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &runError{reason: reason, err: err}
+}
+
+// Scheduler runs the export schedules once they are due: it writes the export to a
+// temporary file, delivers it, records the run and schedules the next one. Schedules are
+// claimed for a lease with SKIP LOCKED so several instances share them, a run that
+// doesn't finish within its lease is run again.
+type Scheduler struct {
+	repo      repository.Repository
+	exporters map[string]Exporter
+	mailer    mail.Mailer
+	client    *http.Client
+	interval  time.Duration
+	lease     time.Duration
+	now       func() time.Time
+	wg        sync.WaitGroup
+	logger    *zap.Logger
+}
+
+// NewScheduler returns the scheduler of the export schedules, mailer is nil when no
+// mail server is configured and fails the emailed schedules
+func NewScheduler(repo repository.Repository, exporters map[string]Exporter, mailer mail.Mailer, cfg config.ExportSchedulesConfig, logger *zap.Logger) *Scheduler {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultSchedulerInterval
+	}
+	if cfg.Lease <= 0 {
+		cfg.Lease = DefaultSchedulerLease
+	}
+	if cfg.WebhookTimeout <= 0 {
+		cfg.WebhookTimeout = DefaultWebhookTimeout
+	}
+
+	return &Scheduler{
+		repo:      repo,
+		exporters: exporters,
+		mailer:    mailer,
+		client:    newWebhookClient(cfg.WebhookTimeout),
+		interval:  cfg.Interval,
+		lease:     cfg.Lease,
+		now:       time.Now,
+		logger:    logger.With(zap.String("component", "export_scheduler")),
+	}
+}
+
+// Start runs the due schedules right away and then on every interval until ctx is
+// cancelled; use Wait to block until the scheduler has stopped
+func (s *Scheduler) Start(ctx context.Context) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := s.Tick(ctx); err != nil && ctx.Err() == nil {
+				s.logger.Error("failed to run export schedules", zap.Error(err))
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Wait blocks until the scheduler has stopped
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+// Tick runs the schedules due now one after the other until none is left and returns
+// how many ran. A failed export or delivery is recorded as a failed run, the schedule
+// still moves on to its next due time. Tick stops at the first schedule it can't record,
+// its claim lapses and it is run again.
+func (s *Scheduler) Tick(ctx context.Context) (int, error) {
+	ran := 0
+	for ctx.Err() == nil {
+		now := s.now().UTC()
+		schedule, ok, err := s.repo.ClaimDueExportSchedule(ctx, now, now.Add(s.lease))
+		if err != nil {
+			return ran, err
+		}
+		if !ok {
+			break
+		}
+
+		run := s.run(ctx, schedule)
+		if ctx.Err() != nil {
+			// shutting down, the claim lapses and the run starts over on the next start
+			return ran, ctx.Err()
+		}
+		if _, err := s.repo.RecordExportRun(ctx, run); err != nil {
+			return ran, err
+		}
+
+		next := types.Next(schedule.Cadence, schedule.NextRunAt.Time)
+		for !next.After(now) {
+			// runs missed while the scheduler was down aren't caught up on
+			next = types.Next(schedule.Cadence, next)
+		}
+		if err := s.repo.RescheduleExportSchedule(ctx, schedule.ScheduleID, next); err != nil {
+			return ran, err
+		}
+		ran++
+	}
+	return ran, nil
+}
+
+// run exports and delivers a schedule, returning the run to record
+func (s *Scheduler) run(ctx context.Context, schedule types.ExportSchedule) types.ExportRun {
+	logger := s.logger.With(
+		zap.String("schedule_id", schedule.ScheduleID.String()),
+		zap.String("user_id", schedule.UserID.String()),
+		zap.String("entity_type", schedule.EntityType),
+		zap.String("delivery", schedule.Delivery.Type))
+
+	run := types.ExportRun{
+		ScheduleID:   schedule.ScheduleID,
+		Status:       types.RunSucceeded,
+		ScheduledFor: schedule.NextRunAt,
+		StartedAt:    coreTypes.NewTimestamp(s.now().UTC()),
+	}
+
+	rows, size, err := s.exportAndDeliver(ctx, schedule)
+	run.Rows, run.Bytes = rows, size
+	run.FinishedAt = coreTypes.NewTimestamp(s.now().UTC())
+	if err != nil {
+		reason := reasonExportFailed
+		var failure *runError
+		if errors.As(err, &failure) {
+			reason = failure.reason
+		}
+		run.Status, run.Error = types.RunFailed, &reason
+		logger.Warn("scheduled export failed", zap.Error(err))
+		return run
+	}
+
+	logger.Info("scheduled export delivered",
+		zap.Int("rows", rows),
+		zap.Int64("bytes", size),
+		zap.Duration("duration", run.FinishedAt.Sub(run.StartedAt.Time)))
+	return run
+}
+
+// exportAndDeliver writes the export of the schedule to a temporary file and delivers
+// it, returning the rows and the size of the file
+func (s *Scheduler) exportAndDeliver(ctx context.Context, schedule types.ExportSchedule) (int, int64, error) {
+	exporter, ok := s.exporters[schedule.EntityType]
+	if !ok {
+		return 0, 0, fmt.Errorf("%q can't be exported", schedule.EntityType)
+	}
+	extension, ok := exporter.Formats[schedule.Format]
+	if !ok {
+		return 0, 0, fmt.Errorf("%s can't be exported as %q", schedule.EntityType, schedule.Format)
+	}
+
+	file, err := os.CreateTemp("", "export-*."+extension)
+	if err != nil {
+		return 0, 0, fmt.Errorf("create export file: %w", err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	rows, err := exporter.Write(ctx, schedule.UserID, schedule.Format, file)
+	if err != nil {
+		return rows, 0, fmt.Errorf("export %s: %w", schedule.EntityType, err)
+	}
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return rows, 0, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return rows, size, err
+	}
+
+	export := exportFile{
+		file:     file,
+		filename: fmt.Sprintf("%s-%s.%s", schedule.EntityType, schedule.NextRunAt.Format("2006-01-02"), extension),
+		rows:     rows,
+	}
+	switch schedule.Delivery.Type {
+	case types.DeliveryWebhook:
+		err = failed(reasonWebhookFailed, s.deliverWebhook(ctx, schedule, export))
+	case types.DeliveryEmail:
+		err = s.deliverEmail(ctx, schedule, export)
+	default:
+		err = failed(reasonUnknownDelivery, fmt.Errorf("unknown delivery %q", schedule.Delivery.Type))
+	}
+	return rows, size, err
+}
+
+// exportFile is the written export of a run, read from the start
+type exportFile struct {
+	file     io.Reader
+	filename string
+	rows     int
+}
+
+// deliverWebhook posts the export as the file field of a multipart form, the form is
+// written to a temporary file so it can be signed before it is sent. Only https targets
+// are delivered to, schedules created before they were required fail.
+func (s *Scheduler) deliverWebhook(ctx context.Context, schedule types.ExportSchedule, export exportFile) error {
+	target, err := url.Parse(schedule.Delivery.Target)
+	if err != nil || target.Scheme != "https" {
+		return fmt.Errorf("webhook target %q is not an https URL", schedule.Delivery.Target)
+	}
+
+	body, err := os.CreateTemp("", "export-webhook-*")
+	if err != nil {
+		return fmt.Errorf("create webhook body: %w", err)
+	}
+	defer os.Remove(body.Name())
+	defer body.Close()
+
+	timestamp := strconv.FormatInt(s.now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(schedule.SigningSecret))
+	mac.Write([]byte(timestamp + "."))
+
+	form := multipart.NewWriter(io.MultiWriter(body, mac))
+	fields := [][2]string{
+		{"schedule_id", schedule.ScheduleID.String()},
+		{"entity_type", schedule.EntityType},
+		{"format", schedule.Format},
+		{"rows", strconv.Itoa(export.rows)},
+		{"scheduled_for", schedule.NextRunAt.UTC().Format(time.RFC3339)},
+	}
+	for _, field := range fields {
+		if err := form.WriteField(field[0], field[1]); err != nil {
+			return err
+		}
+	}
+	part, err := form.CreatePart(textproto.MIMEHeader{
+		"Content-Disposition": {mime.FormatMediaType("form-data", map[string]string{"name": "file", "filename": export.filename})},
+		"Content-Type":        {schedule.Format},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, export.file); err != nil {
+		return err
+	}
+	if err := form.Close(); err != nil {
+		return err
+	}
+
+	size, err := body.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, schedule.Delivery.Target, body)
+	if err != nil {
+		return fmt.Errorf("webhook request: %w", err)
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set(types.SignatureHeader, "t="+timestamp+",v1="+hex.EncodeToString(mac.Sum(nil)))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("deliver webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return nil
+}
+
+// deliverEmail mails the export as an attachment to the schedule's address
+func (s *Scheduler) deliverEmail(ctx context.Context, schedule types.ExportSchedule, export exportFile) error {
+	if s.mailer == nil {
+		return failed(reasonEmailDisabled, fmt.Errorf("email delivery is not configured"))
+	}
+
+	err := s.mailer.Send(ctx, mail.Message{
+		To:      []string{schedule.Delivery.Target},
+		Subject: fmt.Sprintf("Your %s %s export", schedule.Cadence, schedule.EntityType),
+		Body: fmt.Sprintf("Attached is the %s export of your %d %s due %s.\r\n",
+			schedule.Cadence, export.rows, schedule.EntityType, schedule.NextRunAt.UTC().Format("2006-01-02 15:04 MST")),
+		Attachments: []mail.Attachment{
+			{Filename: export.filename, ContentType: schedule.Format, Content: export.file},
+		},
+	})
+	return failed(reasonEmailFailed, err)
+}
@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/config"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/exportschedules/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// webhookRequest is what the test webhook received
+type webhookRequest struct {
+	signature string
+	body      []byte
+	fields    map[string]string
+	filename  string
+	file      string
+}
+
+func newTestScheduler(repo *mockExportScheduleRepository, mailer *mockMailer, now time.Time) *Scheduler {
+	scheduler := NewScheduler(repo, testExporters(2), nil, config.ExportSchedulesConfig{Lease: time.Minute}, zap.NewNop())
+	if mailer != nil {
+		scheduler.mailer = mailer
+	}
+	scheduler.now = func() time.Time { return now }
+	return scheduler
+}
+
+// trustServer lets the scheduler deliver to the test server, which listens on the
+// loopback with a certificate of its own, redirects are still refused
+func trustServer(scheduler *Scheduler, server *httptest.Server) *Scheduler {
+	scheduler.client.Transport = server.Client().Transport
+	return scheduler
+}
+
+// expectClaim makes the repository hand out schedule once, and no schedule afterwards
+func expectClaim(repo *mockExportScheduleRepository, schedule types.ExportSchedule, now time.Time) {
+	repo.On("ClaimDueExportSchedule", mock.Anything, now, now.Add(time.Minute)).Return(schedule, true, nil).Once()
+	repo.On("ClaimDueExportSchedule", mock.Anything, now, now.Add(time.Minute)).Return(types.ExportSchedule{}, false, nil)
+}
+
+func TestScheduler_TickDeliversWebhook(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 8, 9, 0, 30, 0, time.UTC)
+
+	received := make(chan webhookRequest, 1)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		r.Body = io.NopCloser(strings.NewReader(string(body)))
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+
+		file, header, err := r.FormFile("file")
+		require.NoError(t, err)
+		content, err := io.ReadAll(file)
+		require.NoError(t, err)
+
+		fields := map[string]string{}
+		for name, values := range r.MultipartForm.Value {
+			fields[name] = values[0]
+		}
+		received <- webhookRequest{
+			signature: r.Header.Get(types.SignatureHeader),
+			body:      body,
+			fields:    fields,
+			filename:  header.Filename,
+			file:      string(content),
+		}
+	}))
+	defer server.Close()
+
+	schedule := types.ExportSchedule{
+		ScheduleID:    uuid.New(),
+		UserID:        uuid.New(),
+		EntityType:    types.EntityWallets,
+		Format:        "text/csv",
+		Cadence:       types.CadenceWeekly,
+		Delivery:      types.Delivery{Type: types.DeliveryWebhook, Target: server.URL},
+		SigningSecret: "secret",
+		NextRunAt:     coreTypes.NewTimestamp(time.Date(2024, 1, 8, 9, 0, 0, 0, time.UTC)),
+	}
+
+	repo := new(mockExportScheduleRepository)
+	expectClaim(repo, schedule, now)
+	repo.On("RecordExportRun", mock.Anything, types.ExportRun{
+		ScheduleID:   schedule.ScheduleID,
+		Status:       types.RunSucceeded,
+		Rows:         2,
+		Bytes:        int64(len("wallet\nwallet\n")),
+		ScheduledFor: schedule.NextRunAt,
+		StartedAt:    coreTypes.NewTimestamp(now),
+		FinishedAt:   coreTypes.NewTimestamp(now),
+	}).Return(types.ExportRun{}, nil)
+	repo.On("RescheduleExportSchedule", mock.Anything, schedule.ScheduleID, time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)).Return(nil)
+
+	ran, err := trustServer(newTestScheduler(repo, nil, now), server).Tick(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, ran)
+	repo.AssertExpectations(t)
+
+	request := <-received
+	assert.Equal(t, "wallet\nwallet\n", request.file)
+	assert.Equal(t, "wallets-2024-01-08.csv", request.filename)
+	assert.Equal(t, map[string]string{
+		"schedule_id":   schedule.ScheduleID.String(),
+		"entity_type":   types.EntityWallets,
+		"format":        "text/csv",
+		"rows":          "2",
+		"scheduled_for": "2024-01-08T09:00:00Z",
+	}, request.fields)
+
+	timestamp := fmt.Sprint(now.Unix())
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(request.body)
+	assert.Equal(t, "t="+timestamp+",v1="+hex.EncodeToString(mac.Sum(nil)), request.signature)
+}
+
+func TestScheduler_TickRecordsFailedDelivery(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 3, 20, 12, 0, 0, 0, time.UTC)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	schedule := types.ExportSchedule{
+		ScheduleID: uuid.New(),
+		EntityType: types.EntityWallets,
+		Format:     "text/csv",
+		Cadence:    types.CadenceDaily,
+		Delivery:   types.Delivery{Type: types.DeliveryWebhook, Target: server.URL},
+		// due days ago, the runs missed since aren't caught up on
+		NextRunAt: coreTypes.NewTimestamp(time.Date(2024, 3, 17, 9, 0, 0, 0, time.UTC)),
+	}
+
+	repo := new(mockExportScheduleRepository)
+	expectClaim(repo, schedule, now)
+	repo.On("RecordExportRun", mock.Anything, mock.MatchedBy(func(run types.ExportRun) bool {
+		return run.Status == types.RunFailed && run.Rows == 2 &&
+			run.Error != nil && *run.Error == "the webhook could not be delivered"
+	})).Return(types.ExportRun{}, nil)
+	repo.On("RescheduleExportSchedule", mock.Anything, schedule.ScheduleID, time.Date(2024, 3, 21, 9, 0, 0, 0, time.UTC)).Return(nil)
+
+	ran, err := trustServer(newTestScheduler(repo, nil, now), server).Tick(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, ran)
+	repo.AssertExpectations(t)
+}
+
+func TestScheduler_TickRefusesUnsafeWebhooks(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 3, 20, 12, 0, 0, 0, time.UTC)
+
+	var requests atomic.Int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "https://169.254.169.254/latest/meta-data/", http.StatusFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		target  string
+		trusted bool
+		reached int32
+	}{
+		{name: "loopback host", target: server.URL},
+		{name: "metadata address", target: "https://169.254.169.254/latest/meta-data/"},
+		{name: "plain http", target: strings.Replace(server.URL, "https://", "http://", 1), trusted: true},
+		{name: "redirect", target: server.URL + "/redirect", trusted: true, reached: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests.Store(0)
+			schedule := types.ExportSchedule{
+				ScheduleID: uuid.New(),
+				EntityType: types.EntityWallets,
+				Format:     "text/csv",
+				Cadence:    types.CadenceDaily,
+				Delivery:   types.Delivery{Type: types.DeliveryWebhook, Target: tt.target},
+				NextRunAt:  coreTypes.NewTimestamp(now),
+			}
+
+			repo := new(mockExportScheduleRepository)
+			expectClaim(repo, schedule, now)
+			repo.On("RecordExportRun", mock.Anything, mock.MatchedBy(func(run types.ExportRun) bool {
+				return run.Status == types.RunFailed && *run.Error == "the webhook could not be delivered"
+			})).Return(types.ExportRun{}, nil)
+			repo.On("RescheduleExportSchedule", mock.Anything, schedule.ScheduleID, mock.Anything).Return(nil)
+
+			scheduler := newTestScheduler(repo, nil, now)
+			if tt.trusted {
+				trustServer(scheduler, server)
+			}
+			_, err := scheduler.Tick(ctx)
+			require.NoError(t, err)
+			repo.AssertExpectations(t)
+			assert.Equal(t, tt.reached, requests.Load())
+		})
+	}
+}
+
+func TestPublicIP(t *testing.T) {
+	tests := []struct {
+		ip     string
+		public bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.100.100.200", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"fd00:ec2::254", false},
+		{"fe80::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"224.0.0.1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			assert.Equal(t, tt.public, publicIP(net.ParseIP(tt.ip)))
+		})
+	}
+}
+
+func TestScheduler_TickDeliversEmail(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC)
+
+	schedule := types.ExportSchedule{
+		ScheduleID: uuid.New(),
+		EntityType: types.EntityWallets,
+		Format:     "text/csv",
+		Cadence:    types.CadenceMonthly,
+		Delivery:   types.Delivery{Type: types.DeliveryEmail, Target: "finance@example.com"},
+		NextRunAt:  coreTypes.NewTimestamp(now),
+	}
+
+	t.Run("mails the export as an attachment", func(t *testing.T) {
+		repo := new(mockExportScheduleRepository)
+		expectClaim(repo, schedule, now)
+		repo.On("RecordExportRun", mock.Anything, mock.MatchedBy(func(run types.ExportRun) bool {
+			return run.Status == types.RunSucceeded && run.Error == nil
+		})).Return(types.ExportRun{}, nil)
+		repo.On("RescheduleExportSchedule", mock.Anything, schedule.ScheduleID, time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)).Return(nil)
+
+		mailer := &mockMailer{}
+		_, err := newTestScheduler(repo, mailer, now).Tick(ctx)
+		require.NoError(t, err)
+		repo.AssertExpectations(t)
+
+		require.Len(t, mailer.messages, 1)
+		assert.Equal(t, []string{"finance@example.com"}, mailer.messages[0].To)
+		assert.Equal(t, "Your monthly wallets export", mailer.messages[0].Subject)
+		require.Len(t, mailer.messages[0].Attachments, 1)
+		assert.Equal(t, "wallets-2024-02-01.csv", mailer.messages[0].Attachments[0].Filename)
+		assert.Equal(t, "wallet\nwallet\n", string(mailer.attachments[0]))
+	})
+
+	t.Run("fails without a mailer", func(t *testing.T) {
+		repo := new(mockExportScheduleRepository)
+		expectClaim(repo, schedule, now)
+		repo.On("RecordExportRun", mock.Anything, mock.MatchedBy(func(run types.ExportRun) bool {
+			return run.Status == types.RunFailed && *run.Error == "email delivery is not configured"
+		})).Return(types.ExportRun{}, nil)
+		repo.On("RescheduleExportSchedule", mock.Anything, schedule.ScheduleID, mock.Anything).Return(nil)
+
+		_, err := newTestScheduler(repo, nil, now).Tick(ctx)
+		require.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// blockedNetworks are the ranges webhooks can't be delivered to on top of the loopback,
+// private, link-local and multicast ones net.IP tells apart: this network, shared
+// address space, which holds some cloud metadata services, and the benchmarking range
+var blockedNetworks = mustParseCIDRs("0.0.0.0/8", "100.64.0.0/10", "198.18.0.0/15")
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// errRedirect refuses the redirects of a webhook, they could point it anywhere
+var errRedirect = errors.New("webhook redirects are not followed")
+
+// publicIP tells whether the address is one webhooks can be delivered to, which keeps
+// them away from the server's own host, its network and the cloud metadata services
+func publicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// newWebhookClient returns the client delivering webhooks. It resolves the host itself
+// and only dials public addresses, the address checked is the one dialed so the host
+// can't resolve to another one in between. Proxies and redirects are not followed.
+func newWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			if !publicIP(addr.IP) {
+				return nil, fmt.Errorf("webhook host %s resolves to the non-public address %s", host, addr.IP)
+			}
+		}
+		if len(addrs) == 0 {
+			return nil, fmt.Errorf("webhook host %s has no address", host)
+		}
+		return dialer.DialContext(ctx, network, net.JoinHostPort(addrs[0].IP.String(), port))
+	}
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return errRedirect
+		},
+	}
+}
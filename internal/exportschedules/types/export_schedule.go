@@ -0,0 +1,165 @@
+package types
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/jsoncase"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/google/uuid"
+)
+
+// Entities a schedule exports
+const (
+	EntityContacts = "contacts"
+	EntityWallets  = "wallets"
+)
+
+// Cadences a schedule runs at
+const (
+	CadenceDaily   = "daily"
+	CadenceWeekly  = "weekly"
+	CadenceMonthly = "monthly"
+)
+
+// Ways an export is delivered
+const (
+	DeliveryWebhook = "webhook"
+	DeliveryEmail   = "email"
+)
+
+// Outcomes of a run
+const (
+	RunSucceeded = "succeeded"
+	RunFailed    = "failed"
+)
+
+const (
+	// MaxTargetLength caps the webhook URL or email address of a delivery
+	MaxTargetLength = 2048
+
+	DefaultRunsLimit = 20
+	MaxRunsLimit     = 100
+)
+
+// SignatureHeader carries the signature of a webhook delivery, t=<unix seconds>,v1=<hex>
+// where v1 is the HMAC-SHA256 of "<t>.<body>" keyed with the schedule's signing secret
+const SignatureHeader = "X-Export-Signature"
+
+// Next returns when a schedule running at cadence and last due at due is due again, the
+// time of day is kept and monthly schedules land on the same day of the month,
+// normalized the way time.AddDate does
+func Next(cadence string, due time.Time) time.Time {
+	switch cadence {
+	case CadenceDaily:
+		return due.AddDate(0, 0, 1)
+	case CadenceWeekly:
+		return due.AddDate(0, 0, 7)
+	default:
+		return due.AddDate(0, 1, 0)
+	}
+}
+
+// Delivery is where the exports of a schedule go
+type Delivery struct {
+	Type   string `json:"type" example:"email" enums:"webhook,email" validate:"required"`
+	Target string `json:"target" example:"finance@example.com" maxLength:"2048" validate:"required"` // webhook URL or email address
+}
+
+// Validate checks the target fits the type of delivery, webhooks take absolute https URLs
+func (d Delivery) Validate() error {
+	return validation.ValidateStruct(&d,
+		validation.Field(&d.Type, validation.Required, validation.In(DeliveryWebhook, DeliveryEmail)),
+		validation.Field(&d.Target, validation.Required, validation.Length(1, MaxTargetLength),
+			validation.When(d.Type == DeliveryEmail, is.EmailFormat),
+			validation.When(d.Type == DeliveryWebhook, validation.By(webhookURL))),
+	)
+}
+
+func webhookURL(value any) error {
+	target, _ := value.(string)
+	parsed, err := url.Parse(target)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return validation.NewError("validation_webhook_url", "must be an absolute https URL")
+	}
+	return nil
+}
+
+// ExportSchedule exports the user's contacts or wallets on a cadence and delivers the file
+// @Description Scheduled export, the signing secret of webhook deliveries is only returned when the schedule is created
+type ExportSchedule struct {
+	ScheduleID uuid.UUID `json:"scheduleId" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
+	UserID     uuid.UUID `json:"userId" example:"123e4567-e89b-12d3-a456-426614174001" format:"uuid"`
+	EntityType string    `json:"entityType" example:"wallets" enums:"contacts,wallets"`
+	Format     string    `json:"format" example:"text/csv" enums:"text/csv,application/json,text/vcard"`
+	Cadence    string    `json:"cadence" example:"weekly" enums:"daily,weekly,monthly"`
+	Delivery   Delivery  `json:"delivery"`
+	// SigningSecret keys the signature of webhook deliveries
+	SigningSecret string              `json:"signingSecret,omitempty" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	NextRunAt     coreTypes.Timestamp `json:"nextRunAt" example:"2024-01-08T09:00:00.000Z" swaggertype:"string" format:"date-time"`
+	CreatedAt     coreTypes.Timestamp `json:"createdAt" example:"2024-01-01T09:00:00.000Z" swaggertype:"string" format:"date-time"`
+	UpdatedAt     coreTypes.Timestamp `json:"updatedAt" example:"2024-01-01T09:00:00.000Z" swaggertype:"string" format:"date-time"`
+}
+
+// ExportSchedulePayload represents the payload for scheduling an export
+// @Description Payload for scheduling an export, the first run is one cadence after the schedule is created
+type ExportSchedulePayload struct {
+	EntityType string   `json:"entityType" example:"wallets" enums:"contacts,wallets" validate:"required"`
+	Format     string   `json:"format" example:"text/csv" enums:"text/csv,application/json,text/vcard" validate:"required"` // vCards are for contacts only
+	Cadence    string   `json:"cadence" example:"weekly" enums:"daily,weekly,monthly" validate:"required"`
+	Delivery   Delivery `json:"delivery"`
+}
+
+// Bind implements render.Binder interface and validates the schedule payload, the
+// formats an entity is exported in are checked by the service
+func (p *ExportSchedulePayload) Bind(r *http.Request) error {
+	return validation.Errors{
+		"entity_type": validation.Validate(p.EntityType, validation.Required, validation.In(EntityContacts, EntityWallets)),
+		"format":      validation.Validate(p.Format, validation.Required),
+		"cadence":     validation.Validate(p.Cadence, validation.Required, validation.In(CadenceDaily, CadenceWeekly, CadenceMonthly)),
+		"delivery":    p.Delivery.Validate(),
+	}.Filter()
+}
+
+// exportScheduleAliases maps the snake_case field names of ExportSchedulePayload to their camelCase name
+var exportScheduleAliases = jsoncase.AliasesOf(ExportSchedulePayload{})
+
+// UnmarshalJSON accepts the snake_case field names older clients send too
+func (p *ExportSchedulePayload) UnmarshalJSON(data []byte) error {
+	type payload ExportSchedulePayload
+	return exportScheduleAliases.Unmarshal(data, (*payload)(p))
+}
+
+// ExportRun is a delivery of a scheduled export
+// @Description Outcome of a scheduled export run, failed runs carry the reason
+type ExportRun struct {
+	RunID      uuid.UUID `json:"runId" example:"123e4567-e89b-12d3-a456-426614174002" format:"uuid"`
+	ScheduleID uuid.UUID `json:"scheduleId" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
+	Status     string    `json:"status" example:"succeeded" enums:"succeeded,failed"`
+	Rows       int       `json:"rows" example:"42"`
+	Bytes      int64     `json:"bytes" example:"5120"`
+	Error      *string   `json:"error,omitempty" example:"the webhook could not be delivered"`
+	// ScheduledFor is when the run was due, a scheduler that was down runs a late schedule once
+	ScheduledFor coreTypes.Timestamp `json:"scheduledFor" example:"2024-01-08T09:00:00.000Z" swaggertype:"string" format:"date-time"`
+	StartedAt    coreTypes.Timestamp `json:"startedAt" example:"2024-01-08T09:00:05.000Z" swaggertype:"string" format:"date-time"`
+	FinishedAt   coreTypes.Timestamp `json:"finishedAt" example:"2024-01-08T09:00:07.000Z" swaggertype:"string" format:"date-time"`
+}
+
+// ParseRunsLimit parses the limit of a run history, it defaults to DefaultRunsLimit and
+// is capped at MaxRunsLimit
+func ParseRunsLimit(query url.Values) (int32, error) {
+	limitStr := query.Get("limit")
+	if limitStr == "" {
+		return DefaultRunsLimit, nil
+	}
+	l, err := strconv.ParseInt(limitStr, 10, 32)
+	if err != nil || l < 1 {
+		return 0, fmt.Errorf("invalid limit format")
+	}
+	return int32(min(l, MaxRunsLimit)), nil
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/config"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/inbound/service"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/inbound/types"
+	"go.uber.org/zap"
+)
+
+type InboundHandler struct {
+	handlers.BaseHandler
+	service service.InboundService
+	webhook config.InboundConfig
+	limits  coreTypes.LimitPolicy
+	now     func() time.Time
+}
+
+func NewInboundHandler(service service.InboundService, webhook config.InboundConfig, limits coreTypes.LimitPolicy, logger *zap.Logger) *InboundHandler {
+	if webhook.MaxBytes <= 0 {
+		webhook.MaxBytes = types.DefaultMaxEmailBytes
+	}
+	return &InboundHandler{
+		BaseHandler: handlers.NewBaseHandler(logger),
+		service:     service,
+		webhook:     webhook,
+		limits:      limits,
+		now:         time.Now,
+	}
+}
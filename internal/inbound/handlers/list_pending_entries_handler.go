@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/inbound/types"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+)
+
+// ListPendingEntries godoc
+// @Summary List pending entries
+// @Description Lists the receipts forwarded by email newest first, filter on needs_review to find the ones whose amount couldn't be parsed
+// @Tags Inbound Email
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Only entries with this status" Enums(parsed, needs_review)
+// @Param limit query integer false "Maximum number of entries"
+// @Success 200 {object} payloads.Response{data=[]types.PendingEntry}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401  {object} errors.ErrorResponse
+// @Failure 429  {object} errors.ErrorResponse
+// @Failure 500  {object} errors.ErrorResponse
+// @Router /pending-entries [get]
+// @ID ListPendingEntries
+func (h *InboundHandler) ListPendingEntries(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	if !h.CheckQueryParams(w, r, "status", "limit") {
+		return
+	}
+
+	params, err := types.ParsePendingEntriesParams(r.URL.Query(), h.limits)
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	entries, err := h.service.ListPendingEntries(r.Context(), userID, params)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.List(entries, len(entries)))
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/inbound/parser"
+)
+
+// ReceiveEmail godoc
+// @Summary Receive a forwarded email
+// @Description Inbound parse webhook of the email provider (SendGrid or Mailgun). The email has to be delivered to a user's inbound address, matched on the envelope recipient rather than the forgeable sender.
+// @Description It is stored as a pending entry with the amount found in its subject or body, or for review when none is found. A delivery received already is rejected with a 409.
+// @Tags Inbound Email
+// @Accept mpfd
+// @Produce json
+// @Param from formData string true "Sender, from on SendGrid and sender on Mailgun"
+// @Param envelope formData string false "SendGrid's envelope JSON, its to list holds the inbound address"
+// @Param recipient formData string false "Mailgun's envelope recipient, the inbound address"
+// @Param subject formData string false "Subject"
+// @Param text formData string false "Plain text body, text on SendGrid and body-plain on Mailgun"
+// @Success 201 {object} payloads.Response{data=types.PendingEntry}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 409 {object} errors.ErrorResponse
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /inbound-email [post]
+// @ID ReceiveEmail
+func (h *InboundHandler) ReceiveEmail(w http.ResponseWriter, r *http.Request) {
+	email, err := parser.ParseEmail(r.MultipartForm)
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	entry, err := h.service.ReceiveEmail(r.Context(), email)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.Created(entry))
+}
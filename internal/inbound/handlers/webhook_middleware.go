@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/inbound/parser"
+)
+
+// VerifyWebhook caps the size of the posted email, parses the form and only lets
+// through requests verified with the configured secret
+func (h *InboundHandler) VerifyWebhook(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, h.webhook.MaxBytes)
+		if err := r.ParseMultipartForm(h.webhook.MaxBytes); err != nil {
+			h.RespondError(w, r, errors.ErrInvalidRequest(err))
+			return
+		}
+
+		if err := parser.Verify(r, h.webhook.Provider, h.webhook.Secret, h.now()); err != nil {
+			h.RespondError(w, r, errors.ErrAuthorization(err))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
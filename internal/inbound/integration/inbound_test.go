@@ -0,0 +1,314 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/config"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	inboundRoutes "github.com/Abdelrahman-habib/expense-tracker/internal/inbound/routes"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"go.uber.org/zap"
+)
+
+const webhookSecret = "inbound-secret"
+
+type InboundIntegrationTestSuite struct {
+	suite.Suite
+	container      testcontainers.Container
+	service        db.Service
+	pool           *pgxpool.Pool
+	router         *chi.Mux
+	userID         uuid.UUID
+	inboundAddress string
+	ctx            context.Context
+}
+
+func TestInboundIntegrationSuite(t *testing.T) {
+	suite.Run(t, new(InboundIntegrationTestSuite))
+}
+
+func (s *InboundIntegrationTestSuite) SetupSuite() {
+	s.ctx = context.Background()
+
+	var host, port string
+
+	if os.Getenv("CI") == "true" {
+		// Running in GitHub Actions, use service-based PostgreSQL
+		host = "localhost"
+		port = "5432"
+	} else {
+		// Running locally, use TestContainers
+		req := testcontainers.ContainerRequest{
+			Image:        "postgres:15-alpine",
+			ExposedPorts: []string{"5432/tcp"},
+			WaitingFor:   wait.ForListeningPort("5432/tcp"),
+			Env: map[string]string{
+				"POSTGRES_DB":       "testdb",
+				"POSTGRES_USER":     "test",
+				"POSTGRES_PASSWORD": "test",
+			},
+			NetworkMode: "bridge",
+		}
+
+		container, err := testcontainers.GenericContainer(s.ctx, testcontainers.GenericContainerRequest{
+			ContainerRequest: req,
+			Started:          true,
+		})
+		require.NoError(s.T(), err)
+		s.container = container
+
+		host, err = container.Host(s.ctx)
+		require.NoError(s.T(), err)
+		mappedPort, err := container.MappedPort(s.ctx, "5432")
+		require.NoError(s.T(), err)
+		port = mappedPort.Port()
+	}
+
+	cfg := config.DatabaseConfig{
+		Host:        host,
+		Port:        port,
+		Username:    "test",
+		Password:    "test",
+		Database:    "testdb",
+		Schema:      "public",
+		MaxConns:    5,
+		MinConns:    1,
+		MaxLifetime: time.Hour,
+		MaxIdleTime: time.Minute * 30,
+		HealthCheck: time.Minute,
+		SSLMode:     "disable",
+		SearchPath:  "public",
+	}
+
+	dbService := db.NewService(cfg)
+	s.service = dbService
+
+	pool, err := pgxpool.New(s.ctx, cfg.GetDSN())
+	require.NoError(s.T(), err)
+	s.pool = pool
+
+	require.NoError(s.T(), s.runMigrations())
+
+	routes := inboundRoutes.New(dbService, config.InboundConfig{
+		Provider: "sendgrid",
+		Secret:   webhookSecret,
+		MaxBytes: 1 << 20,
+		Domain:   "inbound.example.com",
+	}, coreTypes.DefaultLimitPolicy(), zap.NewNop())
+
+	router := chi.NewRouter()
+	routes.RegisterWebhookRoutes(router)
+	routes.RegisterRoutes(router)
+	s.router = router
+}
+
+func (s *InboundIntegrationTestSuite) TearDownSuite() {
+	if s.pool != nil {
+		s.pool.Close()
+	}
+	if s.service != nil {
+		s.service.Close()
+	}
+	if s.container != nil && os.Getenv("CI") != "true" {
+		err := s.container.Terminate(s.ctx)
+		require.NoError(s.T(), err)
+	}
+}
+
+// SetupTest creates a user with its own inbound address for each test
+func (s *InboundIntegrationTestSuite) SetupTest() {
+	s.userID = uuid.New()
+	token := strings.ReplaceAll(s.userID.String(), "-", "")
+	s.inboundAddress = token + "@inbound.example.com"
+	_, err := s.pool.Exec(s.ctx, `
+		INSERT INTO users (user_id, external_id, name, email, inbound_token)
+		VALUES ($1, $2, 'John Doe', 'john.doe@example.com', $3)
+	`, s.userID, s.userID.String(), token)
+	s.Require().NoError(err)
+}
+
+// envelope is SendGrid's envelope of an email delivered to the addresses
+func envelope(to ...string) string {
+	encoded, _ := json.Marshal(map[string]interface{}{"to": to, "from": "john.doe@example.com"})
+	return string(encoded)
+}
+
+func (s *InboundIntegrationTestSuite) runMigrations() error {
+	migrationsDir := "../../db/sql/migrations"
+
+	sqlDB := stdlib.OpenDBFromPool(s.pool)
+	defer sqlDB.Close()
+
+	if err := goose.SetDialect("postgres"); err != nil {
+		return err
+	}
+	return goose.Up(sqlDB, migrationsDir)
+}
+
+// post sends an email the way SendGrid's inbound parse posts it
+func (s *InboundIntegrationTestSuite) post(password string, fields map[string]string, attachments map[string]string) (int, map[string]interface{}) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for name, value := range fields {
+		s.Require().NoError(w.WriteField(name, value))
+	}
+	i := 1
+	for filename, content := range attachments {
+		part, err := w.CreateFormFile("attachment"+strconv.Itoa(i), filename)
+		s.Require().NoError(err)
+		_, err = part.Write([]byte(content))
+		s.Require().NoError(err)
+		i++
+	}
+	s.Require().NoError(w.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/inbound-email", &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	req.SetBasicAuth("inbound", password)
+
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+
+	var response map[string]interface{}
+	s.Require().NoError(json.NewDecoder(rec.Body).Decode(&response))
+	return rec.Code, response
+}
+
+// list fetches the pending entries of the user
+func (s *InboundIntegrationTestSuite) list(query string) []interface{} {
+	req := httptest.NewRequest(http.MethodGet, "/pending-entries"+query, nil)
+	req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, s.userID))
+
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+	s.Require().Equal(http.StatusOK, rec.Code, rec.Body.String())
+
+	var response map[string]interface{}
+	s.Require().NoError(json.NewDecoder(rec.Body).Decode(&response))
+	entries, _ := response["data"].([]interface{})
+	return entries
+}
+
+func (s *InboundIntegrationTestSuite) TestStoresParsedReceipt() {
+	code, response := s.post(webhookSecret, map[string]string{
+		"from":     "John Doe <john.doe@example.com>",
+		"to":       "Receipts <" + s.inboundAddress + ">",
+		"envelope": envelope(s.inboundAddress),
+		"subject":  "Fwd: Your Tuesday evening trip",
+		"text":     "Trip fare €18.20\nBooking fee €2.50\nTotal €20.70\n",
+	}, map[string]string{"receipt.pdf": "%PDF-1.4"})
+	s.Require().Equal(http.StatusCreated, code, response)
+
+	entry := response["data"].(map[string]interface{})
+	s.Equal("parsed", entry["status"])
+	s.Equal("john.doe@example.com", entry["sender"])
+	s.Equal(20.70, entry["amount"])
+	s.Equal("EUR", entry["currency"])
+	s.Len(entry["attachments"], 1)
+
+	entries := s.list("")
+	s.Require().Len(entries, 1)
+	listed := entries[0].(map[string]interface{})
+	s.Equal(entry["entryId"], listed["entryId"])
+	attachment := listed["attachments"].([]interface{})[0].(map[string]interface{})
+	s.Equal("receipt.pdf", attachment["filename"])
+	s.Equal(8.0, attachment["size"])
+}
+
+func (s *InboundIntegrationTestSuite) TestStoresUnparseableEmailForReview() {
+	code, response := s.post(webhookSecret, map[string]string{
+		"from":     "john.doe@example.com",
+		"envelope": envelope(s.inboundAddress),
+		"subject":  "Your order is on its way",
+		"text":     "Track your package with the link below.",
+	}, nil)
+	s.Require().Equal(http.StatusCreated, code, response)
+
+	entry := response["data"].(map[string]interface{})
+	s.Equal("needs_review", entry["status"])
+	s.Nil(entry["amount"])
+
+	s.Len(s.list("?status=needs_review"), 1)
+	s.Empty(s.list("?status=parsed"))
+}
+
+func (s *InboundIntegrationTestSuite) TestMatchesRecipientCaseInsensitively() {
+	code, response := s.post(webhookSecret, map[string]string{
+		"from":     "john.doe@example.com",
+		"envelope": envelope("jane@example.com", strings.ToUpper(s.inboundAddress)),
+		"subject":  "Your receipt: $4.75",
+	}, nil)
+	s.Require().Equal(http.StatusCreated, code, response)
+	s.Len(s.list(""), 1)
+}
+
+func (s *InboundIntegrationTestSuite) TestRejectsUnknownRecipient() {
+	code, _ := s.post(webhookSecret, map[string]string{
+		"from":     "john.doe@example.com",
+		"envelope": envelope("guessed@inbound.example.com"),
+		"subject":  "Receipt to a guessed address: $4.75",
+	}, nil)
+	s.Equal(http.StatusForbidden, code)
+
+	var count int
+	s.Require().NoError(s.pool.QueryRow(s.ctx, "SELECT count(*) FROM pending_entries WHERE subject = 'Receipt to a guessed address: $4.75'").Scan(&count))
+	s.Zero(count)
+}
+
+// TestIgnoresForgedHeaders checks only the envelope picks the user, the From and To
+// headers are written by whoever sent the email
+func (s *InboundIntegrationTestSuite) TestIgnoresForgedHeaders() {
+	code, _ := s.post(webhookSecret, map[string]string{
+		"from":     "john.doe@example.com",
+		"to":       s.inboundAddress,
+		"envelope": envelope("attacker@inbound.example.com"),
+		"subject":  "Your receipt: $4,750.00",
+	}, nil)
+	s.Equal(http.StatusForbidden, code)
+	s.Empty(s.list(""))
+}
+
+func (s *InboundIntegrationTestSuite) TestRejectsReplayedDelivery() {
+	fields := map[string]string{
+		"from":     "john.doe@example.com",
+		"envelope": envelope(s.inboundAddress),
+		"headers":  "Message-ID: <CAF1234@mail.example.com>\nSubject: Your receipt\n",
+		"subject":  "Your receipt: $4.75",
+	}
+	code, response := s.post(webhookSecret, fields, nil)
+	s.Require().Equal(http.StatusCreated, code, response)
+
+	code, response = s.post(webhookSecret, fields, nil)
+	s.Equal(http.StatusConflict, code, response)
+	s.Len(s.list(""), 1)
+}
+
+func (s *InboundIntegrationTestSuite) TestRejectsUnverifiedRequests() {
+	code, _ := s.post("wrong-secret", map[string]string{
+		"from":     "john.doe@example.com",
+		"envelope": envelope(s.inboundAddress),
+		"subject":  "Your receipt: $4.75",
+	}, nil)
+	s.Equal(http.StatusUnauthorized, code)
+	s.Empty(s.list(""))
+}
@@ -0,0 +1,173 @@
+package parser
+
+import (
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+// Amount is a sum of money found in an email
+type Amount struct {
+	Value    float64
+	Currency string
+}
+
+// symbols maps currency symbols to their ISO code, a bare $ is read as dollars
+var symbols = map[string]string{
+	"$":   "USD",
+	"US$": "USD",
+	"€":   "EUR",
+	"£":   "GBP",
+	"¥":   "JPY",
+	"₹":   "INR",
+}
+
+const number = `\d{1,3}(?:[,.]\d{3})+(?:[.,]\d{1,2})?|\d+(?:[.,]\d{1,2})?`
+
+var (
+	// prefixed matches "$12.50", "€ 8,90" and "USD 1,200.00"
+	prefixed = regexp.MustCompile(`(US\$|[$€£¥₹]|\b[A-Z]{3})\s?(` + number + `)\b`)
+	// suffixed matches "12,50 €" and "1.200,00 EUR"
+	suffixed = regexp.MustCompile(`\b(` + number + `)\s?([€£¥₹]|[A-Z]{3}\b)`)
+
+	// totals names the receipt lines carrying what was paid, the strong ones win over a
+	// plain total. \b keeps subtotal out.
+	strongTotal = regexp.MustCompile(`(?i)\b(grand total|total (paid|charged)|amount (paid|charged)|order total)\b`)
+	plainTotal  = regexp.MustCompile(`(?i)\b(total|amount due|balance due)\b`)
+)
+
+// ParseAmount finds what a receipt email is for. It reads, in order, the line of the
+// body naming the total, the first amount of the subject, and the body's amount when
+// it only mentions one. ok is false when none of them gives an answer.
+func ParseAmount(subject, body string) (Amount, bool) {
+	var (
+		best      Amount
+		bestScore int
+	)
+	for _, line := range strings.Split(body, "\n") {
+		score := 0
+		switch {
+		case strongTotal.MatchString(line):
+			score = 2
+		case plainTotal.MatchString(line):
+			score = 1
+		default:
+			continue
+		}
+		amounts := findAmounts(line)
+		// the last amount of the line, "Total (2 items) $12.00" is about the $12
+		if len(amounts) > 0 && score >= bestScore {
+			best, bestScore = amounts[len(amounts)-1], score
+		}
+	}
+	if bestScore > 0 {
+		return best, true
+	}
+
+	if amounts := findAmounts(subject); len(amounts) > 0 {
+		return amounts[0], true
+	}
+
+	amounts := findAmounts(body)
+	if len(amounts) == 0 {
+		return Amount{}, false
+	}
+	for _, amount := range amounts[1:] {
+		if amount != amounts[0] {
+			return Amount{}, false
+		}
+	}
+	return amounts[0], true
+}
+
+// findAmounts returns the positive amounts of text in the order they appear
+func findAmounts(text string) []Amount {
+	type found struct {
+		start, end int
+		amount     Amount
+	}
+	var all []found
+	for _, m := range prefixed.FindAllStringSubmatchIndex(text, -1) {
+		if amount, ok := toAmount(text[m[2]:m[3]], text[m[4]:m[5]]); ok {
+			all = append(all, found{m[0], m[1], amount})
+		}
+	}
+	prefixedCount := len(all)
+	for _, m := range suffixed.FindAllStringSubmatchIndex(text, -1) {
+		// "USD 10 EUR" already gave its number to the prefixed match
+		taken := slices.ContainsFunc(all[:prefixedCount], func(f found) bool {
+			return m[0] < f.end && f.start < m[1]
+		})
+		if taken {
+			continue
+		}
+		if amount, ok := toAmount(text[m[4]:m[5]], text[m[2]:m[3]]); ok {
+			all = append(all, found{m[0], m[1], amount})
+		}
+	}
+
+	sort.SliceStable(all, func(i, j int) bool { return all[i].start < all[j].start })
+	amounts := make([]Amount, len(all))
+	for i, f := range all {
+		amounts[i] = f.amount
+	}
+	return amounts
+}
+
+// toAmount pairs a currency with a number, rejecting unknown codes and zero
+func toAmount(currency, digits string) (Amount, bool) {
+	code, ok := symbols[currency]
+	if !ok {
+		if is.CurrencyCode.Validate(currency) != nil {
+			return Amount{}, false
+		}
+		code = currency
+	}
+	value, ok := parseNumber(digits)
+	if !ok || value <= 0 {
+		return Amount{}, false
+	}
+	return Amount{Value: value, Currency: code}, true
+}
+
+// parseNumber reads both 1,234.56 and 1.234,56. With a single kind of separator it is
+// the decimal one when one or two digits follow it once, otherwise it groups thousands.
+func parseNumber(digits string) (float64, bool) {
+	lastDot, lastComma := strings.LastIndex(digits, "."), strings.LastIndex(digits, ",")
+	var decimal string
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		decimal = "."
+		if lastComma > lastDot {
+			decimal = ","
+		}
+	case lastDot >= 0 || lastComma >= 0:
+		sep := "."
+		if lastComma >= 0 {
+			sep = ","
+		}
+		if strings.Count(digits, sep) == 1 && len(digits)-strings.LastIndex(digits, sep)-1 <= 2 {
+			decimal = sep
+		}
+	}
+
+	var b strings.Builder
+	for i, r := range digits {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case decimal != "" && i == strings.LastIndex(digits, decimal):
+			b.WriteByte('.')
+		}
+	}
+	value, err := strconv.ParseFloat(b.String(), 64)
+	if err != nil {
+		return 0, false
+	}
+	return math.Round(value*100) / 100, true
+}
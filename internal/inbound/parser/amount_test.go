@@ -0,0 +1,104 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const rideReceipt = `Thanks for riding, John
+
+Trip fare                 €18.20
+Booking fee                €2.50
+Tip                        €2.70
+
+Total                     €23.40
+
+Charged to Visa ••••4242
+Trip on 12 March 2024, 21:14 from Alexanderplatz`
+
+const orderConfirmation = `Hello John,
+
+Your order #112-4587123-9911023 has shipped.
+
+Items:
+  1 x USB-C cable              $12.99
+  2 x Notebook (A5)            $17.98
+
+Item(s) Subtotal:              $30.97
+Shipping & Handling:            $5.99
+Estimated tax to be collected:  $2.48
+Order Total:                   $39.44
+
+Questions? Visit our help pages.`
+
+const hotelInvoice = `INVOICE 2024-0193
+
+Room, 3 nights                  1.050,00 EUR
+City tax                           15,00 EUR
+Total                           1.065,00 EUR
+Amount paid                     1.065,00 EUR
+Balance due                         0,00 EUR`
+
+const utilityBill = `Your February statement is ready.
+
+Previous balance: GBP 84.10
+Payments received: GBP 84.10
+Amount due: GBP 91.35 by 15 March`
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		name     string
+		subject  string
+		body     string
+		expected Amount
+		ok       bool
+	}{
+		{name: "ride receipt total", subject: "Your Tuesday evening trip", body: rideReceipt, expected: Amount{Value: 23.40, Currency: "EUR"}, ok: true},
+		{name: "order total over subtotal and tax", subject: "Your order has shipped", body: orderConfirmation, expected: Amount{Value: 39.44, Currency: "USD"}, ok: true},
+		{name: "amount paid over a zero balance", subject: "Invoice 2024-0193", body: hotelInvoice, expected: Amount{Value: 1065, Currency: "EUR"}, ok: true},
+		{name: "amount due", subject: "Statement", body: utilityBill, expected: Amount{Value: 91.35, Currency: "GBP"}, ok: true},
+		{name: "amount in the subject", subject: "Your receipt from Coffee House: $4.75", body: "Thanks for stopping by!\nPaid with Apple Pay", expected: Amount{Value: 4.75, Currency: "USD"}, ok: true},
+		{name: "single amount of the body", subject: "Receipt", body: "You paid 12,50 € at Bäckerei Schmidt.\nSee you soon.", expected: Amount{Value: 12.5, Currency: "EUR"}, ok: true},
+		{name: "repeated amount of the body", subject: "Payment confirmation", body: "We received USD 250.00.\nThe USD 250.00 were applied to your account.", expected: Amount{Value: 250, Currency: "USD"}, ok: true},
+		{name: "currency code after the number", subject: "Lunch 18.90 CHF", body: "", expected: Amount{Value: 18.9, Currency: "CHF"}, ok: true},
+		{name: "thousands without decimals", subject: "Rent: $1,200", body: "", expected: Amount{Value: 1200, Currency: "USD"}, ok: true},
+		{name: "no amount", subject: "Your order is on its way", body: "Track your package with the link below.\nOrder #5521", ok: false},
+		{name: "ambiguous amounts without a total", subject: "Price alert", body: "The item dropped from $59.99 to $44.99.", ok: false},
+		{name: "words that look like codes", subject: "VAT 20 applies", body: "Call ABC 123 for help", ok: false},
+		{name: "zero amount", subject: "Refund of $0.00", body: "", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, ok := ParseAmount(tt.subject, tt.body)
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.Equal(t, tt.expected.Currency, amount.Currency)
+				assert.InDelta(t, tt.expected.Value, amount.Value, 0.001)
+			}
+		})
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := map[string]float64{
+		"12":        12,
+		"12.5":      12.5,
+		"12,50":     12.5,
+		"1,234":     1234,
+		"1.234":     1234,
+		"1,234.56":  1234.56,
+		"1.234,56":  1234.56,
+		"1234.56":   1234.56,
+		"1,000,000": 1000000,
+	}
+
+	for digits, expected := range tests {
+		t.Run(digits, func(t *testing.T) {
+			value, ok := parseNumber(digits)
+			assert.True(t, ok)
+			assert.InDelta(t, expected, value, 0.001)
+		})
+	}
+}
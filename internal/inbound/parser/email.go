@@ -0,0 +1,157 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/mail"
+	"sort"
+	"strings"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/inbound/types"
+)
+
+// Providers whose inbound parse webhooks are understood
+const (
+	ProviderSendGrid = "sendgrid"
+	ProviderMailgun  = "mailgun"
+)
+
+// fields lists the form fields each part of the email is read from, SendGrid's names
+// first and Mailgun's after
+var fields = struct {
+	from, to, subject, text []string
+}{
+	from:    []string{"from", "sender"},
+	to:      []string{"to", "recipient"},
+	subject: []string{"subject"},
+	text:    []string{"text", "body-plain"},
+}
+
+// ParseEmail reads the email out of an inbound parse webhook form. Both providers post
+// multipart forms with the attachments as files, so the same reading serves both.
+func ParseEmail(form *multipart.Form) (types.InboundEmail, error) {
+	if form == nil {
+		return types.InboundEmail{}, fmt.Errorf("missing form")
+	}
+
+	email := types.InboundEmail{
+		From:    first(form.Value, fields.from),
+		To:      first(form.Value, fields.to),
+		Subject: first(form.Value, fields.subject),
+		Text:    strings.ReplaceAll(first(form.Value, fields.text), "\r\n", "\n"),
+		Raw:     make(map[string]string, len(form.Value)),
+	}
+	for name, values := range form.Value {
+		if len(values) > 0 {
+			email.Raw[name] = values[0]
+		}
+	}
+	if email.From == "" {
+		return email, fmt.Errorf("missing sender")
+	}
+
+	recipients, err := envelopeRecipients(form.Value)
+	if err != nil {
+		return email, err
+	}
+	if len(recipients) == 0 {
+		return email, fmt.Errorf("missing envelope recipient")
+	}
+	email.Recipients = recipients
+	email.DeliveryID = deliveryID(form.Value)
+
+	// attachment1, attachment2... for SendGrid and attachment-1... for Mailgun, sorted
+	// so they're stored in the order they were attached
+	names := make([]string, 0, len(form.File))
+	for name := range form.File {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, header := range form.File[name] {
+			attachment, err := readAttachment(header)
+			if err != nil {
+				return email, fmt.Errorf("reading attachment %s: %w", header.Filename, err)
+			}
+			email.Attachments = append(email.Attachments, attachment)
+		}
+	}
+
+	return email, nil
+}
+
+// envelopeRecipients returns the addresses the email was delivered to, SendGrid posts them
+// in the to list of the envelope JSON and Mailgun comma separated in recipient
+func envelopeRecipients(values map[string][]string) ([]string, error) {
+	if envelope := first(values, []string{"envelope"}); envelope != "" {
+		var parsed struct {
+			To []string `json:"to"`
+		}
+		if err := json.Unmarshal([]byte(envelope), &parsed); err != nil {
+			return nil, fmt.Errorf("invalid envelope: %w", err)
+		}
+		return parsed.To, nil
+	}
+
+	var recipients []string
+	for _, recipient := range strings.Split(first(values, []string{"recipient"}), ",") {
+		if recipient = strings.TrimSpace(recipient); recipient != "" {
+			recipients = append(recipients, recipient)
+		}
+	}
+	return recipients, nil
+}
+
+// deliveryID identifies the delivery, Mailgun's signed token or else the Message-ID of
+// the email, posted on its own by Mailgun and within the raw headers by SendGrid. It's
+// empty when the email has none.
+func deliveryID(values map[string][]string) string {
+	if id := first(values, []string{"token", "Message-Id"}); id != "" {
+		return id
+	}
+
+	headers := first(values, []string{"headers"})
+	if headers == "" {
+		return ""
+	}
+	message, err := mail.ReadMessage(strings.NewReader(headers + "\r\n\r\n"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(message.Header.Get("Message-Id"))
+}
+
+// first returns the first non-empty value of the named fields
+func first(values map[string][]string, names []string) string {
+	for _, name := range names {
+		if v := values[name]; len(v) > 0 && strings.TrimSpace(v[0]) != "" {
+			return strings.TrimSpace(v[0])
+		}
+	}
+	return ""
+}
+
+func readAttachment(header *multipart.FileHeader) (types.Attachment, error) {
+	file, err := header.Open()
+	if err != nil {
+		return types.Attachment{}, err
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return types.Attachment{}, err
+	}
+
+	contentType := header.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return types.Attachment{
+		Filename:    header.Filename,
+		ContentType: contentType,
+		Content:     content,
+	}, nil
+}
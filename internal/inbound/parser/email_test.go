@@ -0,0 +1,125 @@
+package parser
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type file struct {
+	field, name, contentType, content string
+}
+
+// newForm builds a multipart form the way the providers post it
+func newForm(t *testing.T, values map[string]string, files ...file) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for name, value := range values {
+		require.NoError(t, w.WriteField(name, value))
+	}
+	for _, f := range files {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", `form-data; name="`+f.field+`"; filename="`+f.name+`"`)
+		if f.contentType != "" {
+			header.Set("Content-Type", f.contentType)
+		}
+		part, err := w.CreatePart(header)
+		require.NoError(t, err)
+		_, err = part.Write([]byte(f.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	r := httptest.NewRequest(http.MethodPost, "/inbound-email", &body)
+	r.Header.Set("Content-Type", w.FormDataContentType())
+	require.NoError(t, r.ParseMultipartForm(1<<20))
+	return r
+}
+
+func TestParseEmail_SendGrid(t *testing.T) {
+	r := newForm(t, map[string]string{
+		"from":        " John Doe <john.doe@example.com> ",
+		"to":          "Receipts <receipts@example.com>",
+		"envelope":    `{"to":["k3v9x2m7@inbound.example.com"],"from":"john.doe@example.com"}`,
+		"headers":     "Received: by mx.example.com\nMessage-ID: <CAF1234@mail.example.com>\nSubject: Fwd: Your Tuesday evening trip\n",
+		"subject":     "Fwd: Your Tuesday evening trip",
+		"text":        rideReceipt,
+		"attachments": "2",
+	},
+		file{field: "attachment2", name: "map.png", contentType: "image/png", content: "png"},
+		file{field: "attachment1", name: "receipt.pdf", contentType: "application/pdf", content: "%PDF-1.4"},
+	)
+
+	email, err := ParseEmail(r.MultipartForm)
+	require.NoError(t, err)
+
+	assert.Equal(t, "John Doe <john.doe@example.com>", email.From)
+	assert.Equal(t, "Receipts <receipts@example.com>", email.To)
+	assert.Equal(t, []string{"k3v9x2m7@inbound.example.com"}, email.Recipients)
+	assert.Equal(t, "<CAF1234@mail.example.com>", email.DeliveryID)
+	assert.Equal(t, "Fwd: Your Tuesday evening trip", email.Subject)
+	assert.Equal(t, rideReceipt, email.Text)
+	assert.Equal(t, "2", email.Raw["attachments"])
+
+	require.Len(t, email.Attachments, 2)
+	assert.Equal(t, "receipt.pdf", email.Attachments[0].Filename)
+	assert.Equal(t, "application/pdf", email.Attachments[0].ContentType)
+	assert.Equal(t, []byte("%PDF-1.4"), email.Attachments[0].Content)
+	assert.Equal(t, "map.png", email.Attachments[1].Filename)
+}
+
+func TestParseEmail_Mailgun(t *testing.T) {
+	r := newForm(t, map[string]string{
+		"sender":     "john.doe@example.com",
+		"recipient":  "k3v9x2m7@inbound.example.com, jane@example.com",
+		"subject":    "Your order has shipped",
+		"body-plain": "Order Total:\r\n$39.44\r\n",
+		"timestamp":  "1700000000",
+		"token":      "c5a4b1e2d3f4",
+		"Message-Id": "<20231114@mail.example.com>",
+	},
+		file{field: "attachment-1", name: "invoice.txt", content: "invoice"},
+	)
+
+	email, err := ParseEmail(r.MultipartForm)
+	require.NoError(t, err)
+
+	assert.Equal(t, "john.doe@example.com", email.From)
+	assert.Equal(t, "k3v9x2m7@inbound.example.com, jane@example.com", email.To)
+	assert.Equal(t, []string{"k3v9x2m7@inbound.example.com", "jane@example.com"}, email.Recipients)
+	assert.Equal(t, "c5a4b1e2d3f4", email.DeliveryID, "the signed token identifies the delivery")
+	assert.Equal(t, "Order Total:\n$39.44", email.Text)
+	assert.Equal(t, "1700000000", email.Raw["timestamp"])
+
+	require.Len(t, email.Attachments, 1)
+	assert.Equal(t, "invoice.txt", email.Attachments[0].Filename)
+	assert.Equal(t, "application/octet-stream", email.Attachments[0].ContentType)
+}
+
+func TestParseEmail_MissingSender(t *testing.T) {
+	r := newForm(t, map[string]string{"subject": "Receipt", "text": "Total $5.00"})
+
+	_, err := ParseEmail(r.MultipartForm)
+	assert.Error(t, err)
+
+	_, err = ParseEmail(nil)
+	assert.Error(t, err)
+}
+
+func TestParseEmail_MissingRecipient(t *testing.T) {
+	r := newForm(t, map[string]string{"from": "john.doe@example.com", "to": "k3v9x2m7@inbound.example.com"})
+	_, err := ParseEmail(r.MultipartForm)
+	assert.ErrorContains(t, err, "missing envelope recipient", "the To header isn't trusted")
+
+	r = newForm(t, map[string]string{"from": "john.doe@example.com", "envelope": "{"})
+	_, err = ParseEmail(r.MultipartForm)
+	assert.ErrorContains(t, err, "invalid envelope")
+}
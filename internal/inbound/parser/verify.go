@@ -0,0 +1,59 @@
+package parser
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// MaxSignatureAge is how old a Mailgun signature may be, older ones are replays
+const MaxSignatureAge = 5 * time.Minute
+
+// Verify checks the webhook request was sent by the provider holding secret. Mailgun
+// signs the timestamp and token form fields with the signing key, SendGrid doesn't sign
+// so its destination URL carries the secret as the basic auth password. The form has to
+// be parsed already. An empty secret rejects every request.
+func Verify(r *http.Request, provider, secret string, now time.Time) error {
+	if secret == "" {
+		return fmt.Errorf("inbound email secret is not configured")
+	}
+
+	switch provider {
+	case ProviderSendGrid:
+		_, password, ok := r.BasicAuth()
+		if !ok || subtle.ConstantTimeCompare([]byte(password), []byte(secret)) != 1 {
+			return fmt.Errorf("invalid inbound email credentials")
+		}
+		return nil
+
+	case ProviderMailgun:
+		timestamp, token := r.FormValue("timestamp"), r.FormValue("token")
+		signature, err := hex.DecodeString(r.FormValue("signature"))
+		if err != nil || timestamp == "" || token == "" {
+			return fmt.Errorf("missing inbound email signature")
+		}
+
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(timestamp + token))
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return fmt.Errorf("invalid inbound email signature")
+		}
+
+		seconds, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid inbound email timestamp")
+		}
+		if age := now.Sub(time.Unix(seconds, 0)); age > MaxSignatureAge || age < -MaxSignatureAge {
+			return fmt.Errorf("inbound email signature expired")
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown inbound email provider %q", provider)
+	}
+}
@@ -0,0 +1,101 @@
+package parser
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const secret = "webhook-secret"
+
+func mailgunFields(key string, timestamp time.Time, token string) map[string]string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(ts + token))
+	return map[string]string{
+		"sender":    "john.doe@example.com",
+		"timestamp": ts,
+		"token":     token,
+		"signature": hex.EncodeToString(mac.Sum(nil)),
+	}
+}
+
+func TestVerify_SendGrid(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		auth     bool
+		wantErr  bool
+	}{
+		{name: "valid credentials", password: secret, auth: true},
+		{name: "wrong password", password: "guess", auth: true, wantErr: true},
+		{name: "no credentials", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newForm(t, map[string]string{"from": "john.doe@example.com"})
+			if tt.auth {
+				r.SetBasicAuth("inbound", tt.password)
+			}
+
+			err := Verify(r, ProviderSendGrid, secret, time.Now())
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestVerify_Mailgun(t *testing.T) {
+	now := time.Date(2024, 3, 12, 21, 15, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		fields  map[string]string
+		wantErr bool
+	}{
+		{name: "valid signature", fields: mailgunFields(secret, now.Add(-time.Minute), "token-1")},
+		{name: "slightly ahead clock", fields: mailgunFields(secret, now.Add(time.Minute), "token-2")},
+		{name: "other signing key", fields: mailgunFields("other-key", now, "token-3"), wantErr: true},
+		{name: "expired signature", fields: mailgunFields(secret, now.Add(-MaxSignatureAge-time.Second), "token-4"), wantErr: true},
+		{name: "missing signature", fields: map[string]string{"sender": "john.doe@example.com"}, wantErr: true},
+		{
+			name: "tampered token",
+			fields: func() map[string]string {
+				f := mailgunFields(secret, now, "token-5")
+				f["token"] = "token-6"
+				return f
+			}(),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newForm(t, tt.fields)
+
+			err := Verify(r, ProviderMailgun, secret, now)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestVerify_Misconfigured(t *testing.T) {
+	r := newForm(t, map[string]string{"from": "john.doe@example.com"})
+	r.SetBasicAuth("inbound", "")
+
+	assert.Error(t, Verify(r, ProviderSendGrid, "", time.Now()))
+	assert.Error(t, Verify(r, "postmark", secret, time.Now()))
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/inbound/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// deliveryIndex keeps the deliveries of a user unique, so a replayed one isn't stored twice
+const deliveryIndex = "pending_entries_delivery_idx"
+
+// CreatePendingEntry stores a received email and its attachments in one transaction
+func (r *inboundRepository) CreatePendingEntry(ctx context.Context, userID uuid.UUID, entry types.PendingEntryCreate) (types.PendingEntry, error) {
+	raw, err := json.Marshal(entry.Raw)
+	if err != nil {
+		return types.PendingEntry{}, errors.HandleRepositoryError(err, "create", "pending entry")
+	}
+
+	var result types.PendingEntry
+	err = r.inTx(ctx, func(q *db.Queries) error {
+		created, err := q.CreatePendingEntry(ctx, db.CreatePendingEntryParams{
+			UserID:   userID,
+			Sender:   entry.Sender,
+			Subject:  entry.Subject,
+			Body:     entry.Body,
+			Amount:   utils.ToNullableNumeric(entry.Amount),
+			Currency: utils.ToNullableText(entry.Currency),
+			Status:   entry.Status,
+			Raw:      raw,
+			// emails without a delivery id aren't deduplicated
+			DeliveryID: pgtype.Text{String: entry.DeliveryID, Valid: entry.DeliveryID != ""},
+		})
+		if err != nil {
+			return err
+		}
+
+		attachments := make([]db.ListPendingEntryAttachmentsRow, 0, len(entry.Attachments))
+		for _, attachment := range entry.Attachments {
+			row, err := q.CreatePendingEntryAttachment(ctx, db.CreatePendingEntryAttachmentParams{
+				EntryID:     created.EntryID,
+				Filename:    attachment.Filename,
+				ContentType: attachment.ContentType,
+				SizeBytes:   int64(len(attachment.Content)),
+				Content:     attachment.Content,
+			})
+			if err != nil {
+				return err
+			}
+			attachments = append(attachments, db.ListPendingEntryAttachmentsRow(row))
+		}
+
+		result = toPendingEntry(created, attachments)
+		return nil
+	})
+	if errors.IsUniqueViolation(err, deliveryIndex) {
+		return types.PendingEntry{}, errors.NewConflictError("email already received")
+	}
+	if err != nil {
+		return types.PendingEntry{}, errors.HandleRepositoryError(err, "create", "pending entry")
+	}
+
+	return result, nil
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// GetUserIDByInboundToken finds the user whose inbound address has token as its local part
+func (r *inboundRepository) GetUserIDByInboundToken(ctx context.Context, token string) (uuid.UUID, error) {
+	userID, err := r.q.GetUserIDByInboundToken(ctx, pgtype.Text{String: token, Valid: true})
+	if err != nil {
+		return uuid.Nil, errors.HandleRepositoryError(err, "get", "inbound address")
+	}
+	return userID, nil
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/bulk"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/inbound/types"
+	"github.com/google/uuid"
+)
+
+// InboundRepository defines the interface for inbound email data access
+type InboundRepository interface {
+	// GetUserIDByInboundToken finds the user whose inbound address has token as its local part
+	GetUserIDByInboundToken(ctx context.Context, token string) (uuid.UUID, error)
+
+	// CreatePendingEntry stores a received email with its attachments, a delivery stored
+	// already is a conflict
+	CreatePendingEntry(ctx context.Context, userID uuid.UUID, entry types.PendingEntryCreate) (types.PendingEntry, error)
+
+	// ListPendingEntries retrieves the user's pending entries, newest first
+	ListPendingEntries(ctx context.Context, userID uuid.UUID, params types.PendingEntriesParams) ([]types.PendingEntry, error)
+}
+
+type inboundRepository struct {
+	db bulk.TxBeginner
+	q  *db.Queries
+}
+
+// NewInboundRepository creates a new instance of InboundRepository
+func NewInboundRepository(dbService db.Service) InboundRepository {
+	return &inboundRepository{
+		db: dbService,
+		q:  dbService.Queries(),
+	}
+}
+
+// inTx runs fn in a transaction
+func (r *inboundRepository) inTx(ctx context.Context, fn func(q *db.Queries) error) (err error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	if err = fn(db.New(tx)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/inbound/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ListPendingEntries retrieves the user's pending entries newest first, with the
+// attachments of the whole page read in one query
+func (r *inboundRepository) ListPendingEntries(ctx context.Context, userID uuid.UUID, params types.PendingEntriesParams) ([]types.PendingEntry, error) {
+	entries, err := r.q.ListPendingEntries(ctx, db.ListPendingEntriesParams{
+		UserID: userID,
+		// without a status every entry is listed
+		Status: pgtype.Text{String: params.Status, Valid: params.Status != ""},
+		Limit:  params.Limit,
+	})
+	if err != nil {
+		return nil, errors.HandleRepositoryError(err, "list", "pending entries")
+	}
+	entryIDs := make([]uuid.UUID, len(entries))
+	for i, entry := range entries {
+		entryIDs[i] = entry.EntryID
+	}
+	attachments, err := r.q.ListPendingEntryAttachments(ctx, entryIDs)
+	if err != nil {
+		return nil, errors.HandleRepositoryError(err, "list", "pending entry attachments")
+	}
+
+	byEntry := make(map[uuid.UUID][]db.ListPendingEntryAttachmentsRow, len(entries))
+	for _, attachment := range attachments {
+		byEntry[attachment.EntryID] = append(byEntry[attachment.EntryID], attachment)
+	}
+
+	result := make([]types.PendingEntry, len(entries))
+	for i, entry := range entries {
+		result[i] = toPendingEntry(entry, byEntry[entry.EntryID])
+	}
+	return result, nil
+}
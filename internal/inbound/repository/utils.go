@@ -0,0 +1,32 @@
+package repository
+
+import (
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/inbound/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
+)
+
+// toPendingEntry converts a db.PendingEntry and its attachments to domain types.PendingEntry
+func toPendingEntry(e db.PendingEntry, attachments []db.ListPendingEntryAttachmentsRow) types.PendingEntry {
+	entry := types.PendingEntry{
+		EntryID:     e.EntryID,
+		Sender:      e.Sender,
+		Subject:     e.Subject,
+		Body:        e.Body,
+		Amount:      utils.GetFloat64Ptr(e.Amount),
+		Currency:    utils.PgtextToStringPtr(e.Currency),
+		Status:      e.Status,
+		Attachments: make([]types.AttachmentInfo, len(attachments)),
+		ReceivedAt:  coreTypes.NewTimestamp(e.ReceivedAt.Time),
+	}
+	for i, a := range attachments {
+		entry.Attachments[i] = types.AttachmentInfo{
+			AttachmentID: a.AttachmentID,
+			Filename:     a.Filename,
+			ContentType:  a.ContentType,
+			Size:         a.SizeBytes,
+		}
+	}
+	return entry
+}
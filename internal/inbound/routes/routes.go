@@ -0,0 +1,39 @@
+package routes
+
+import (
+	"github.com/Abdelrahman-habib/expense-tracker/config"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/inbound/handlers"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/inbound/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/inbound/service"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// Router encapsulates the inbound email routes setup
+type Router struct {
+	handler *handlers.InboundHandler
+}
+
+// New creates a new inbound email router with proper dependency injection
+func New(dbService db.Service, cfg config.InboundConfig, limits coreTypes.LimitPolicy, logger *zap.Logger) *Router {
+	repo := repository.NewInboundRepository(dbService)
+	inboundService := service.NewInboundService(repo, cfg.Domain, logger)
+	handler := handlers.NewInboundHandler(inboundService, cfg, limits, logger)
+
+	return &Router{
+		handler: handler,
+	}
+}
+
+// RegisterWebhookRoutes registers the provider's webhook, it authenticates with the
+// shared secret instead of a user token and goes with the public routes
+func (r *Router) RegisterWebhookRoutes(router chi.Router) {
+	router.With(r.handler.VerifyWebhook).Post("/inbound-email", r.handler.ReceiveEmail)
+}
+
+// RegisterRoutes registers the routes of the authenticated user
+func (r *Router) RegisterRoutes(router chi.Router) {
+	router.Get("/pending-entries", r.handler.ListPendingEntries)
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"net/mail"
+	"strings"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/inbound/parser"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/inbound/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/inbound/types"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type InboundService interface {
+	ReceiveEmail(ctx context.Context, email types.InboundEmail) (types.PendingEntry, error)
+	ListPendingEntries(ctx context.Context, userID uuid.UUID, params types.PendingEntriesParams) ([]types.PendingEntry, error)
+}
+
+type inboundService struct {
+	repo repository.InboundRepository
+	// domain is the domain of the inbound addresses, every email is rejected without it
+	domain string
+	logger *zap.Logger
+}
+
+func NewInboundService(repo repository.InboundRepository, domain string, logger *zap.Logger) InboundService {
+	return &inboundService{
+		repo:   repo,
+		domain: strings.ToLower(domain),
+		logger: logger.With(zap.String("component", "inbound_service")),
+	}
+}
+
+// ReceiveEmail stores a forwarded email as a pending entry of the user whose inbound
+// address it was delivered to. The user is found from the envelope recipients the
+// provider sets, the sender is only recorded since anyone can forge it. Emails whose
+// amount can't be found are kept for review rather than dropped, a delivery received
+// already is a conflict.
+func (s *inboundService) ReceiveEmail(ctx context.Context, email types.InboundEmail) (types.PendingEntry, error) {
+	sender, err := mail.ParseAddress(email.From)
+	if err != nil {
+		return types.PendingEntry{}, errors.NewValidationError("invalid sender %q", email.From)
+	}
+
+	userID, err := s.recipientUser(ctx, email.Recipients)
+	if err != nil {
+		return types.PendingEntry{}, err
+	}
+
+	entry := types.PendingEntryCreate{
+		Sender:      sender.Address,
+		Subject:     email.Subject,
+		Body:        email.Text,
+		Status:      types.StatusNeedsReview,
+		Raw:         email.Raw,
+		Attachments: email.Attachments,
+		DeliveryID:  email.DeliveryID,
+	}
+	if amount, ok := parser.ParseAmount(email.Subject, email.Text); ok {
+		entry.Amount = &amount.Value
+		entry.Currency = &amount.Currency
+		entry.Status = types.StatusParsed
+	}
+
+	s.logger.Info("storing inbound email",
+		zap.String("user_id", userID.String()),
+		zap.String("status", entry.Status),
+		zap.Int("attachments", len(entry.Attachments)))
+	created, err := s.repo.CreatePendingEntry(ctx, userID, entry)
+	if errors.IsErrorType(err, errors.ErrorTypeConflict) {
+		s.logger.Warn("rejecting inbound email delivered already",
+			zap.String("user_id", userID.String()))
+	}
+	return created, err
+}
+
+// recipientUser finds the user of the first recipient on the inbound domain whose token
+// is handed out, recipients elsewhere are other addresses the email was sent to
+func (s *inboundService) recipientUser(ctx context.Context, recipients []string) (uuid.UUID, error) {
+	for _, recipient := range recipients {
+		address, err := mail.ParseAddress(recipient)
+		if err != nil {
+			continue
+		}
+		at := strings.LastIndex(address.Address, "@")
+		if at <= 0 || s.domain == "" || !strings.EqualFold(address.Address[at+1:], s.domain) {
+			continue
+		}
+
+		userID, err := s.repo.GetUserIDByInboundToken(ctx, strings.ToLower(address.Address[:at]))
+		if errors.IsErrorType(err, errors.ErrorTypeNotFound) {
+			continue
+		}
+		return userID, err
+	}
+
+	s.logger.Warn("rejecting inbound email to unknown recipients", zap.Int("recipients", len(recipients)))
+	return uuid.Nil, errors.NewForbiddenError("recipient is not an inbound address")
+}
+
+func (s *inboundService) ListPendingEntries(ctx context.Context, userID uuid.UUID, params types.PendingEntriesParams) ([]types.PendingEntry, error) {
+	return s.repo.ListPendingEntries(ctx, userID, params)
+}
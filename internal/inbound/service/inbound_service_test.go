@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	coreErrors "github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/inbound/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+type mockInboundRepository struct {
+	mock.Mock
+}
+
+func (m *mockInboundRepository) GetUserIDByInboundToken(ctx context.Context, token string) (uuid.UUID, error) {
+	args := m.Called(ctx, token)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *mockInboundRepository) CreatePendingEntry(ctx context.Context, userID uuid.UUID, entry types.PendingEntryCreate) (types.PendingEntry, error) {
+	args := m.Called(ctx, userID, entry)
+	return args.Get(0).(types.PendingEntry), args.Error(1)
+}
+
+func (m *mockInboundRepository) ListPendingEntries(ctx context.Context, userID uuid.UUID, params types.PendingEntriesParams) ([]types.PendingEntry, error) {
+	args := m.Called(ctx, userID, params)
+	return args.Get(0).([]types.PendingEntry), args.Error(1)
+}
+
+func setupTest(t *testing.T) (*mockInboundRepository, InboundService) {
+	mockRepo := new(mockInboundRepository)
+	service := NewInboundService(mockRepo, "Inbound.Example.com", zap.NewNop())
+	return mockRepo, service
+}
+
+func TestInboundService_ReceiveEmail(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	amount, currency := 39.44, "USD"
+
+	tests := []struct {
+		name    string
+		email   types.InboundEmail
+		mock    func(*mockInboundRepository)
+		errType coreErrors.ErrorType
+	}{
+		{
+			name: "stores the parsed amount",
+			email: types.InboundEmail{
+				From:       "John <john@example.com>",
+				Recipients: []string{"k3v9x2m7@inbound.example.com"},
+				DeliveryID: "delivery-1",
+				Subject:    "Your order",
+				Text:       "Order Total: $39.44",
+			},
+			mock: func(m *mockInboundRepository) {
+				m.On("GetUserIDByInboundToken", ctx, "k3v9x2m7").Return(userID, nil)
+				m.On("CreatePendingEntry", ctx, userID, types.PendingEntryCreate{
+					Sender:     "john@example.com",
+					Subject:    "Your order",
+					Body:       "Order Total: $39.44",
+					Amount:     &amount,
+					Currency:   &currency,
+					Status:     types.StatusParsed,
+					DeliveryID: "delivery-1",
+				}).Return(types.PendingEntry{Status: types.StatusParsed}, nil)
+			},
+		},
+		{
+			name: "stores emails without an amount for review",
+			email: types.InboundEmail{
+				From:       "john@example.com",
+				Recipients: []string{"k3v9x2m7@inbound.example.com"},
+				Subject:    "Your order is on its way",
+			},
+			mock: func(m *mockInboundRepository) {
+				m.On("GetUserIDByInboundToken", ctx, "k3v9x2m7").Return(userID, nil)
+				m.On("CreatePendingEntry", ctx, userID, types.PendingEntryCreate{
+					Sender:  "john@example.com",
+					Subject: "Your order is on its way",
+					Status:  types.StatusNeedsReview,
+				}).Return(types.PendingEntry{Status: types.StatusNeedsReview}, nil)
+			},
+		},
+		{
+			name: "matches the inbound recipient among others regardless of case",
+			email: types.InboundEmail{
+				From:       "john@example.com",
+				Recipients: []string{"jane@example.com", "K3V9X2M7@INBOUND.EXAMPLE.COM"},
+				Subject:    "Your order is on its way",
+			},
+			mock: func(m *mockInboundRepository) {
+				m.On("GetUserIDByInboundToken", ctx, "k3v9x2m7").Return(userID, nil)
+				m.On("CreatePendingEntry", ctx, userID, mock.Anything).
+					Return(types.PendingEntry{Status: types.StatusNeedsReview}, nil)
+			},
+		},
+		{
+			name: "rejects unknown inbound addresses",
+			email: types.InboundEmail{
+				From:       "john@example.com",
+				Recipients: []string{"guessed@inbound.example.com"},
+				Subject:    "Total $5.00",
+			},
+			mock: func(m *mockInboundRepository) {
+				m.On("GetUserIDByInboundToken", ctx, "guessed").
+					Return(uuid.Nil, coreErrors.NewNotFoundError("user not found"))
+			},
+			errType: coreErrors.ErrorTypeForbidden,
+		},
+		{
+			name: "ignores the sender",
+			email: types.InboundEmail{
+				From:       "john@example.com",
+				Recipients: []string{"k3v9x2m7@elsewhere.example.com"},
+				Subject:    "Total $5.00",
+			},
+			mock:    func(m *mockInboundRepository) {},
+			errType: coreErrors.ErrorTypeForbidden,
+		},
+		{
+			name: "rejects replayed deliveries",
+			email: types.InboundEmail{
+				From:       "john@example.com",
+				Recipients: []string{"k3v9x2m7@inbound.example.com"},
+				DeliveryID: "delivery-1",
+				Subject:    "Total $5.00",
+			},
+			mock: func(m *mockInboundRepository) {
+				m.On("GetUserIDByInboundToken", ctx, "k3v9x2m7").Return(userID, nil)
+				m.On("CreatePendingEntry", ctx, userID, mock.Anything).
+					Return(types.PendingEntry{}, coreErrors.NewConflictError("email already received"))
+			},
+			errType: coreErrors.ErrorTypeConflict,
+		},
+		{
+			name:    "rejects invalid senders",
+			email:   types.InboundEmail{From: "not an address", Recipients: []string{"k3v9x2m7@inbound.example.com"}},
+			mock:    func(m *mockInboundRepository) {},
+			errType: coreErrors.ErrorTypeValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo, service := setupTest(t)
+			tt.mock(mockRepo)
+
+			_, err := service.ReceiveEmail(ctx, tt.email)
+			if tt.errType != "" {
+				assert.True(t, coreErrors.IsErrorType(err, tt.errType), "got %v", err)
+				if tt.errType != coreErrors.ErrorTypeConflict {
+					mockRepo.AssertNotCalled(t, "CreatePendingEntry", mock.Anything, mock.Anything, mock.Anything)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/google/uuid"
+)
+
+// Statuses of a pending entry
+const (
+	// StatusParsed entries carry the amount found in the email
+	StatusParsed = "parsed"
+	// StatusNeedsReview entries had no recognizable amount and wait for the user
+	StatusNeedsReview = "needs_review"
+)
+
+// DefaultMaxEmailBytes caps the size of a posted email when no limit is configured
+const DefaultMaxEmailBytes = 10 << 20
+
+// InboundEmail is an email handed over by the provider's inbound parse webhook
+type InboundEmail struct {
+	From string
+	To   string
+	// Recipients are the addresses of the envelope the email was delivered to, unlike the
+	// headers they are set by the provider
+	Recipients []string
+	// DeliveryID identifies the delivery, a replay of it carries the same one
+	DeliveryID  string
+	Subject     string
+	Text        string
+	Attachments []Attachment
+	// Raw holds the text fields as the provider posted them
+	Raw map[string]string
+}
+
+// Attachment is a file attached to an inbound email
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+// PendingEntryCreate is what is stored for a received email
+type PendingEntryCreate struct {
+	Sender      string
+	Subject     string
+	Body        string
+	Amount      *float64
+	Currency    *string
+	Status      string
+	Raw         map[string]string
+	Attachments []Attachment
+	DeliveryID  string
+}
+
+// PendingEntry is a forwarded receipt waiting to become an expense
+// @Description Forwarded receipt with the amount parsed from it, if any
+type PendingEntry struct {
+	EntryID     uuid.UUID           `json:"entryId" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
+	Sender      string              `json:"sender" example:"john.doe@example.com" format:"email"`
+	Subject     string              `json:"subject" example:"Your receipt from Coffee House"`
+	Body        string              `json:"body" example:"Total: $4.75"`
+	Amount      *float64            `json:"amount,omitempty" example:"4.75"`
+	Currency    *string             `json:"currency,omitempty" example:"USD"`
+	Status      string              `json:"status" example:"parsed" enums:"parsed,needs_review"`
+	Attachments []AttachmentInfo    `json:"attachments"`
+	ReceivedAt  coreTypes.Timestamp `json:"receivedAt" example:"2024-01-01T00:00:00.000Z" swaggertype:"string" format:"date-time"`
+}
+
+// AttachmentInfo describes an attachment of a pending entry without its content
+// @Description Attachment of a pending entry
+type AttachmentInfo struct {
+	AttachmentID uuid.UUID `json:"attachmentId" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
+	Filename     string    `json:"filename" example:"receipt.pdf"`
+	ContentType  string    `json:"contentType" example:"application/pdf"`
+	Size         int64     `json:"size" example:"48213"`
+}
+
+// PendingEntriesParams filters the pending entries listing
+type PendingEntriesParams struct {
+	Status string
+	Limit  int32
+}
+
+// ParsePendingEntriesParams parses the status filter and limit of the listing, the
+// limit defaults to and is capped by the policy
+func ParsePendingEntriesParams(query url.Values, policy coreTypes.LimitPolicy) (PendingEntriesParams, error) {
+	params := PendingEntriesParams{
+		Status: query.Get("status"),
+		Limit:  policy.DefaultLimit,
+	}
+
+	switch params.Status {
+	case "", StatusParsed, StatusNeedsReview:
+	default:
+		return params, fmt.Errorf("invalid status %q, expected %s or %s", params.Status, StatusParsed, StatusNeedsReview)
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		l, err := strconv.ParseInt(limitStr, 10, 32)
+		if err != nil || l < 1 {
+			return params, fmt.Errorf("invalid limit format")
+		}
+		params.Limit = int32(min(l, int64(policy.MaxLimit)))
+	}
+
+	return params, nil
+}
@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/jobs/service"
+	"go.uber.org/zap"
+)
+
+type JobHandler struct {
+	handlers.BaseHandler
+	service service.JobService
+}
+
+func NewJobHandler(service service.JobService, logger *zap.Logger) *JobHandler {
+	return &JobHandler{
+		BaseHandler: handlers.NewBaseHandler(logger),
+		service:     service,
+	}
+}
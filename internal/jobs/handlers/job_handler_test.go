@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/bulk"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/jobs/types"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// Mock service
+type mockJobService struct {
+	mock.Mock
+}
+
+func (m *mockJobService) GetJob(ctx context.Context, jobID, userID uuid.UUID) (types.Job, error) {
+	args := m.Called(ctx, jobID, userID)
+	return args.Get(0).(types.Job), args.Error(1)
+}
+
+func TestJobHandler_GetJob(t *testing.T) {
+	userID := uuid.New()
+	jobID := uuid.New()
+
+	tests := []struct {
+		name           string
+		setupAuth      bool
+		jobID          string
+		setupMock      func(*mockJobService)
+		expectedStatus int
+		checkResponse  func(t *testing.T, data map[string]interface{})
+	}{
+		{
+			name:      "reports progress",
+			setupAuth: true,
+			jobID:     jobID.String(),
+			setupMock: func(m *mockJobService) {
+				m.On("GetJob", mock.Anything, jobID, userID).Return(types.Job{
+					JobID:     jobID,
+					Type:      types.JobTypeContactImport,
+					Status:    types.JobStatusRunning,
+					Total:     500,
+					Processed: 200,
+					Succeeded: 199,
+					Failed:    1,
+					Errors:    []bulk.RowError{{Index: 42, Error: "name: cannot be blank."}},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, data map[string]interface{}) {
+				assert.Equal(t, "running", data["status"])
+				assert.Equal(t, float64(200), data["processed"])
+				assert.Equal(t, float64(199), data["succeeded"])
+				assert.Equal(t, float64(1), data["failed"])
+				rowErrors := data["errors"].([]interface{})
+				assert.Len(t, rowErrors, 1)
+				assert.Equal(t, float64(42), rowErrors[0].(map[string]interface{})["index"])
+				assert.NotContains(t, data, "payload")
+			},
+		},
+		{
+			name:      "not found",
+			setupAuth: true,
+			jobID:     jobID.String(),
+			setupMock: func(m *mockJobService) {
+				m.On("GetJob", mock.Anything, jobID, userID).
+					Return(types.Job{}, errors.HandleRepositoryError(pgx.ErrNoRows, "get", "job"))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "invalid id",
+			setupAuth:      true,
+			jobID:          "not-a-uuid",
+			setupMock:      func(m *mockJobService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing auth",
+			jobID:          jobID.String(),
+			setupMock:      func(m *mockJobService) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockJobService)
+			handler := NewJobHandler(mockService, zap.NewNop())
+			tt.setupMock(mockService)
+
+			req := httptest.NewRequest(http.MethodGet, "/jobs/"+tt.jobID, nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.jobID)
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			if tt.setupAuth {
+				ctx = context.WithValue(ctx, requestcontext.UserIDKey, userID)
+			}
+			req = req.WithContext(ctx)
+
+			w := httptest.NewRecorder()
+			handler.GetJob(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.checkResponse != nil {
+				var response map[string]interface{}
+				assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				tt.checkResponse(t, response["data"].(map[string]interface{}))
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
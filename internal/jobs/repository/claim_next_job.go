@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/jobs/types"
+)
+
+func (r *jobRepository) ClaimNextJob(ctx context.Context) (types.Job, bool, error) {
+	job, err := r.q.ClaimNextJob(ctx)
+	if err == pgx.ErrNoRows {
+		return types.Job{}, false, nil
+	}
+	if err != nil {
+		return types.Job{}, false, errors.HandleRepositoryError(err, "claim", "job")
+	}
+
+	claimed, err := toJob(job)
+	if err != nil {
+		return types.Job{}, false, err
+	}
+	return claimed, true, nil
+}
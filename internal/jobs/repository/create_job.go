@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/jobs/types"
+)
+
+func (r *jobRepository) CreateJob(ctx context.Context, userID uuid.UUID, jobType types.JobType, total int, payload []byte) (types.Job, error) {
+	if userID == uuid.Nil {
+		return types.Job{}, fmt.Errorf("invalid user id")
+	}
+
+	job, err := r.q.CreateJob(ctx, db.CreateJobParams{
+		UserID:  userID,
+		Type:    string(jobType),
+		Total:   int32(total),
+		Payload: payload,
+	})
+	if err != nil {
+		return types.Job{}, errors.HandleRepositoryError(err, "create", "job")
+	}
+
+	return toJob(job)
+}
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/jobs/types"
+)
+
+func (r *jobRepository) GetJob(ctx context.Context, jobID, userID uuid.UUID) (types.Job, error) {
+	if userID == uuid.Nil {
+		return types.Job{}, fmt.Errorf("invalid user id")
+	}
+
+	job, err := r.q.GetJob(ctx, db.GetJobParams{
+		JobID:  jobID,
+		UserID: userID,
+	})
+	if err != nil {
+		return types.Job{}, errors.HandleRepositoryError(err, "get", "job")
+	}
+
+	return toJob(job)
+}
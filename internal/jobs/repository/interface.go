@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/bulk"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/jobs/types"
+)
+
+// Repository defines the interface for job operations
+type Repository interface {
+	// CreateJob stores a new pending job
+	CreateJob(ctx context.Context, userID uuid.UUID, jobType types.JobType, total int, payload []byte) (types.Job, error)
+
+	// GetJob retrieves a job by ID and user ID
+	GetJob(ctx context.Context, jobID, userID uuid.UUID) (types.Job, error)
+
+	// ClaimNextJob marks the oldest pending job as running and returns it,
+	// reporting false when there is nothing to claim
+	ClaimNextJob(ctx context.Context) (types.Job, bool, error)
+
+	// UpdateJobProgress saves the progress of a running job
+	UpdateJobProgress(ctx context.Context, jobID uuid.UUID, progress bulk.Progress) error
+
+	// CompleteJob marks a job as completed
+	CompleteJob(ctx context.Context, jobID uuid.UUID) error
+
+	// CompleteJobWithResult marks a job that ran in one go as completed, with the rows
+	// it covered and the blob store key of the file it produced
+	CompleteJobWithResult(ctx context.Context, jobID uuid.UUID, rows int, resultKey string) error
+
+	// FailJob marks a job as failed with the given reason
+	FailJob(ctx context.Context, jobID uuid.UUID, reason string) error
+
+	// RequeueJob puts a running job back to pending
+	RequeueJob(ctx context.Context, jobID uuid.UUID) error
+
+	// HeartbeatJob marks a running job as still being worked on
+	HeartbeatJob(ctx context.Context, jobID uuid.UUID) error
+
+	// RequeueStaleJobs puts the running jobs without a heartbeat since staleBefore back to
+	// pending, returning how many were requeued
+	RequeueStaleJobs(ctx context.Context, staleBefore time.Time) (int64, error)
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+)
+
+type jobRepository struct {
+	q *db.Queries
+}
+
+// New creates a new job repository
+func New(q *db.Queries) Repository {
+	return &jobRepository{q: q}
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/bulk"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+)
+
+func (r *jobRepository) UpdateJobProgress(ctx context.Context, jobID uuid.UUID, progress bulk.Progress) error {
+	rowErrors := progress.Errors
+	if rowErrors == nil {
+		rowErrors = []bulk.RowError{}
+	}
+	encoded, err := json.Marshal(rowErrors)
+	if err != nil {
+		return err
+	}
+
+	err = r.q.UpdateJobProgress(ctx, db.UpdateJobProgressParams{
+		JobID:     jobID,
+		Processed: int32(progress.Processed),
+		Succeeded: int32(progress.Succeeded),
+		Failed:    int32(progress.Failed),
+		Errors:    encoded,
+	})
+	if err != nil {
+		return errors.HandleRepositoryError(err, "update", "job")
+	}
+	return nil
+}
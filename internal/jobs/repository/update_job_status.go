@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
+)
+
+func (r *jobRepository) CompleteJob(ctx context.Context, jobID uuid.UUID) error {
+	if err := r.q.CompleteJob(ctx, jobID); err != nil {
+		return errors.HandleRepositoryError(err, "complete", "job")
+	}
+	return nil
+}
+
+func (r *jobRepository) CompleteJobWithResult(ctx context.Context, jobID uuid.UUID, rows int, resultKey string) error {
+	err := r.q.CompleteJobWithResult(ctx, db.CompleteJobWithResultParams{
+		JobID:     jobID,
+		Rows:      int32(rows),
+		ResultKey: utils.ToNullableText(&resultKey),
+	})
+	if err != nil {
+		return errors.HandleRepositoryError(err, "complete", "job")
+	}
+	return nil
+}
+
+func (r *jobRepository) FailJob(ctx context.Context, jobID uuid.UUID, reason string) error {
+	err := r.q.FailJob(ctx, db.FailJobParams{
+		JobID: jobID,
+		Error: utils.ToNullableText(&reason),
+	})
+	if err != nil {
+		return errors.HandleRepositoryError(err, "fail", "job")
+	}
+	return nil
+}
+
+func (r *jobRepository) RequeueJob(ctx context.Context, jobID uuid.UUID) error {
+	if err := r.q.RequeueJob(ctx, jobID); err != nil {
+		return errors.HandleRepositoryError(err, "requeue", "job")
+	}
+	return nil
+}
+
+func (r *jobRepository) HeartbeatJob(ctx context.Context, jobID uuid.UUID) error {
+	if err := r.q.HeartbeatJob(ctx, jobID); err != nil {
+		return errors.HandleRepositoryError(err, "heartbeat", "job")
+	}
+	return nil
+}
+
+func (r *jobRepository) RequeueStaleJobs(ctx context.Context, staleBefore time.Time) (int64, error) {
+	// the column has no time zone and is written in UTC
+	count, err := r.q.RequeueStaleJobs(ctx, pgtype.Timestamp{Time: staleBefore.UTC(), Valid: true})
+	if err != nil {
+		return 0, errors.HandleRepositoryError(err, "requeue", "jobs")
+	}
+	return count, nil
+}
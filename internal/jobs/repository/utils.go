@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"encoding/json"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/bulk"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/jobs/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
+)
+
+// toJob converts a db.Job to domain types.Job
+func toJob(j db.Job) (types.Job, error) {
+	rowErrors := []bulk.RowError{}
+	if len(j.Errors) > 0 {
+		if err := json.Unmarshal(j.Errors, &rowErrors); err != nil {
+			return types.Job{}, err
+		}
+	}
+
+	return types.Job{
+		JobID:       j.JobID,
+		UserID:      j.UserID,
+		Type:        types.JobType(j.Type),
+		Status:      types.JobStatus(j.Status),
+		Total:       int(j.Total),
+		Processed:   int(j.Processed),
+		Succeeded:   int(j.Succeeded),
+		Failed:      int(j.Failed),
+		Errors:      rowErrors,
+		Error:       utils.PgtextToStringPtr(j.Error),
+		Payload:     j.Payload,
+		ResultKey:   utils.PgtextToStringPtr(j.ResultKey),
+		CreatedAt:   coreTypes.NewTimestamp(j.CreatedAt.Time),
+		UpdatedAt:   coreTypes.NewTimestamp(j.UpdatedAt.Time),
+		CompletedAt: utils.GetTimestampPtr(j.CompletedAt),
+	}, nil
+}
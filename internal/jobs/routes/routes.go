@@ -0,0 +1,41 @@
+package routes
+
+import (
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/jobs/handlers"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/jobs/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/jobs/service"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// Router encapsulates the job routes setup
+type Router struct {
+	handler *handlers.JobHandler
+}
+
+// New creates a new job router with proper dependency injection
+func New(dbService db.Service, logger *zap.Logger) *Router {
+	// Get queries from db service
+	queries := dbService.Queries()
+
+	// Initialize repository
+	repo := repository.New(queries)
+
+	// Initialize service with repository
+	jobService := service.NewJobService(repo, logger)
+
+	// Initialize handler with service
+	handler := handlers.NewJobHandler(jobService, logger)
+
+	return &Router{
+		handler: handler,
+	}
+}
+
+// RegisterRoutes registers all job routes
+func (r *Router) RegisterRoutes(router chi.Router) {
+	router.Route("/jobs", func(router chi.Router) {
+		router.Get("/{id}", r.handler.GetJob)
+	})
+}
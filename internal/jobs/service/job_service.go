@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/jobs/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/jobs/types"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type JobService interface {
+	GetJob(ctx context.Context, jobID, userID uuid.UUID) (types.Job, error)
+}
+
+type jobService struct {
+	repo   repository.Repository
+	logger *zap.Logger
+}
+
+func NewJobService(repo repository.Repository, logger *zap.Logger) JobService {
+	return &jobService{
+		repo:   repo,
+		logger: logger.With(zap.String("component", "job_service")),
+	}
+}
+
+func (s *jobService) GetJob(ctx context.Context, jobID, userID uuid.UUID) (types.Job, error) {
+	s.logger.Info("getting job",
+		zap.String("job_id", jobID.String()),
+		zap.String("user_id", userID.String()))
+
+	return s.repo.GetJob(ctx, jobID, userID)
+}
@@ -0,0 +1,57 @@
+package types
+
+import (
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/bulk"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/google/uuid"
+)
+
+// JobType identifies the processor that handles a job
+type JobType string
+
+// JobStatus is the lifecycle state of a job
+type JobStatus string
+
+const (
+	JobStatusPending   JobStatus = "pending"
+	JobStatusRunning   JobStatus = "running"
+	JobStatusCompleted JobStatus = "completed"
+	JobStatusFailed    JobStatus = "failed"
+)
+
+const (
+	JobTypeContactImport JobType = "contact_import"
+	JobTypeContactExport JobType = "contact_export"
+	JobTypeAccountBackup JobType = "account_backup"
+)
+
+// Job represents a background bulk write and its progress
+// @Description Background job progress, including per-row errors capped at 100
+type Job struct {
+	JobID     uuid.UUID       `json:"jobId" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
+	UserID    uuid.UUID       `json:"userId" example:"123e4567-e89b-12d3-a456-426614174001" format:"uuid"`
+	Type      JobType         `json:"type" example:"contact_import"`
+	Status    JobStatus       `json:"status" example:"running" enums:"pending,running,completed,failed"`
+	Total     int             `json:"total" example:"1000"`
+	Processed int             `json:"processed" example:"400"`
+	Succeeded int             `json:"succeeded" example:"398"`
+	Failed    int             `json:"failed" example:"2"`
+	Errors    []bulk.RowError `json:"errors"`
+	Error     *string         `json:"error,omitempty" example:"chunk 0-200 failed after 4 attempts"`
+	Payload   []byte          `json:"-" swaggerignore:"true"`
+	// ResultKey is the blob store key of the file a completed job produced
+	ResultKey   *string              `json:"-" swaggerignore:"true"`
+	CreatedAt   coreTypes.Timestamp  `json:"createdAt" example:"2024-01-01T00:00:00.000Z" swaggertype:"string" format:"date-time"`
+	UpdatedAt   coreTypes.Timestamp  `json:"updatedAt" example:"2024-01-01T00:00:00.000Z" swaggertype:"string" format:"date-time"`
+	CompletedAt *coreTypes.Timestamp `json:"completedAt,omitempty" example:"2024-01-01T00:00:00.000Z" swaggertype:"string" format:"date-time"`
+}
+
+// Progress returns the job's progress as the bulk engine tracks it
+func (j Job) Progress() bulk.Progress {
+	return bulk.Progress{
+		Processed: j.Processed,
+		Succeeded: j.Succeeded,
+		Failed:    j.Failed,
+		Errors:    j.Errors,
+	}
+}
@@ -0,0 +1,369 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/config"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/bulk"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/jobs/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/jobs/types"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultPollInterval = 5 * time.Second
+	DefaultStaleAfter   = 2 * time.Minute
+)
+
+// Processor prepares a claimed job for the bulk engine, returning the number
+// of rows in the job and the function that writes one of them
+type Processor func(ctx context.Context, job types.Job) (int, bulk.RowFunc, error)
+
+// Task runs a claimed job in one go, for jobs producing a file rather than writing
+// rows. It returns the number of rows the file holds and its blob store key.
+type Task func(ctx context.Context, job types.Job) (rows int, resultKey string, err error)
+
+// Enqueuer stores a new job for the runner to process
+type Enqueuer interface {
+	Enqueue(ctx context.Context, userID uuid.UUID, jobType types.JobType, total int, payload any) (types.Job, error)
+}
+
+// Queue enqueues jobs and reads them back to follow their progress
+type Queue interface {
+	Enqueuer
+	GetJob(ctx context.Context, jobID, userID uuid.UUID) (types.Job, error)
+}
+
+// Runner claims pending jobs and processes them in chunks with a fixed number
+// of workers, so a burst of submissions queues up in the jobs table rather
+// than all running at once
+type Runner struct {
+	repo         repository.Repository
+	txRepo       func(q *db.Queries) repository.Repository
+	engine       *bulk.Engine
+	processors   map[types.JobType]Processor
+	tasks        map[types.JobType]Task
+	workers      int
+	pollInterval time.Duration
+	staleAfter   time.Duration
+	now          func() time.Time
+	wake         chan struct{}
+	wg           sync.WaitGroup
+	logger       *zap.Logger
+}
+
+func NewRunner(dbService db.Service, cfg config.JobsConfig, logger *zap.Logger) *Runner {
+	engine := bulk.NewEngine(dbService, bulk.Config{
+		ChunkSize:  cfg.ChunkSize,
+		MaxRetries: cfg.MaxRetries,
+		RetryDelay: cfg.RetryDelay,
+	}, logger)
+
+	return newRunner(repository.New(dbService.Queries()), repository.New, engine, cfg, logger)
+}
+
+func newRunner(repo repository.Repository, txRepo func(q *db.Queries) repository.Repository, engine *bulk.Engine, cfg config.JobsConfig, logger *zap.Logger) *Runner {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultPollInterval
+	}
+	if cfg.StaleAfter <= 0 {
+		cfg.StaleAfter = DefaultStaleAfter
+	}
+
+	return &Runner{
+		repo:         repo,
+		txRepo:       txRepo,
+		engine:       engine,
+		processors:   make(map[types.JobType]Processor),
+		tasks:        make(map[types.JobType]Task),
+		workers:      cfg.Workers,
+		pollInterval: cfg.PollInterval,
+		staleAfter:   cfg.StaleAfter,
+		now:          time.Now,
+		wake:         make(chan struct{}, 1),
+		logger:       logger.With(zap.String("component", "job_runner")),
+	}
+}
+
+// Register sets the processor for a job type. It must be called before Start.
+func (r *Runner) Register(jobType types.JobType, processor Processor) {
+	r.processors[jobType] = processor
+}
+
+// RegisterTask sets the task running a job type. It must be called before Start.
+func (r *Runner) RegisterTask(jobType types.JobType, task Task) {
+	r.tasks[jobType] = task
+}
+
+// Enqueue stores a pending job and wakes a worker to pick it up
+func (r *Runner) Enqueue(ctx context.Context, userID uuid.UUID, jobType types.JobType, total int, payload any) (types.Job, error) {
+	_, isProcessor := r.processors[jobType]
+	_, isTask := r.tasks[jobType]
+	if !isProcessor && !isTask {
+		return types.Job{}, fmt.Errorf("unknown job type %q", jobType)
+	}
+
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return types.Job{}, fmt.Errorf("encode job payload: %w", err)
+	}
+
+	job, err := r.repo.CreateJob(ctx, userID, jobType, total, encoded)
+	if err != nil {
+		return types.Job{}, err
+	}
+
+	r.logger.Info("job enqueued",
+		zap.String("job_id", job.JobID.String()),
+		zap.String("type", string(jobType)),
+		zap.Int("total", total))
+
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+
+	return job, nil
+}
+
+// GetJob returns a job of the user
+func (r *Runner) GetJob(ctx context.Context, jobID, userID uuid.UUID) (types.Job, error) {
+	return r.repo.GetJob(ctx, jobID, userID)
+}
+
+// Start requeues the jobs left running by stopped instances and starts the workers.
+// Jobs count as left once their heartbeat is older than the stale timeout, so the jobs
+// other instances are running stay with them. The workers stop when ctx is cancelled;
+// use Wait to block until they have finished.
+func (r *Runner) Start(ctx context.Context) error {
+	if err := r.requeueStale(ctx); err != nil {
+		return fmt.Errorf("requeue interrupted jobs: %w", err)
+	}
+
+	for i := 0; i < r.workers; i++ {
+		r.wg.Add(1)
+		go r.work(ctx)
+	}
+
+	r.wg.Add(1)
+	go r.reap(ctx)
+
+	return nil
+}
+
+// requeueStale puts the jobs of stopped instances back to pending and wakes a worker
+func (r *Runner) requeueStale(ctx context.Context) error {
+	requeued, err := r.repo.RequeueStaleJobs(ctx, r.now().Add(-r.staleAfter))
+	if err != nil {
+		return err
+	}
+	if requeued > 0 {
+		r.logger.Info("resuming interrupted jobs", zap.Int64("count", requeued))
+		select {
+		case r.wake <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// reap requeues the jobs of instances that stop while this one runs, once per stale timeout
+func (r *Runner) reap(ctx context.Context) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.staleAfter)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := r.requeueStale(ctx); err != nil && ctx.Err() == nil {
+			r.logger.Error("failed to requeue interrupted jobs", zap.Error(err))
+		}
+	}
+}
+
+// heartbeat touches the job four times per stale timeout until the returned function is
+// called, so other instances don't take it for abandoned while it runs
+func (r *Runner) heartbeat(ctx context.Context, jobID uuid.UUID, logger *zap.Logger) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(r.staleAfter / 4)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if err := r.repo.HeartbeatJob(ctx, jobID); err != nil && ctx.Err() == nil {
+				logger.Warn("failed to record job heartbeat", zap.Error(err))
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// RunPending processes the pending jobs one after the other on the calling goroutine until
+// none is left and returns how many it processed. It's for tests and tools running jobs
+// without starting the workers.
+func (r *Runner) RunPending(ctx context.Context) int {
+	processed := 0
+	for ctx.Err() == nil && r.processNext(ctx) {
+		processed++
+	}
+	return processed
+}
+
+// Wait blocks until all workers have stopped
+func (r *Runner) Wait() {
+	r.wg.Wait()
+}
+
+func (r *Runner) work(ctx context.Context) {
+	defer r.wg.Done()
+
+	for {
+		// drain the queue before waiting for new work
+		for ctx.Err() == nil {
+			if !r.processNext(ctx) {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.wake:
+		case <-time.After(r.pollInterval):
+		}
+	}
+}
+
+// processNext claims and processes one job, reporting whether there was one
+func (r *Runner) processNext(ctx context.Context) bool {
+	job, ok, err := r.repo.ClaimNextJob(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			r.logger.Error("failed to claim job", zap.Error(err))
+		}
+		return false
+	}
+	if !ok {
+		return false
+	}
+
+	r.process(ctx, job)
+	return true
+}
+
+func (r *Runner) process(ctx context.Context, job types.Job) {
+	logger := r.logger.With(
+		zap.String("job_id", job.JobID.String()),
+		zap.String("type", string(job.Type)))
+
+	stop := r.heartbeat(ctx, job.JobID, logger)
+	defer stop()
+
+	if task, ok := r.tasks[job.Type]; ok {
+		r.runTask(ctx, job, task, logger)
+		return
+	}
+
+	processor, ok := r.processors[job.Type]
+	if !ok {
+		r.fail(job, logger, fmt.Errorf("no processor registered for job type %q", job.Type))
+		return
+	}
+
+	total, row, err := processor(ctx, job)
+	if err != nil {
+		r.fail(job, logger, err)
+		return
+	}
+
+	logger.Info("processing job",
+		zap.Int("total", total),
+		zap.Int("processed", job.Processed))
+
+	progress, err := r.engine.Run(ctx, total, job.Progress(), row,
+		func(ctx context.Context, q *db.Queries, progress bulk.Progress) error {
+			return r.txRepo(q).UpdateJobProgress(ctx, job.JobID, progress)
+		})
+	if err != nil {
+		if ctx.Err() != nil {
+			// shutting down; the committed chunks are kept and the job resumes on the next start
+			if err := r.repo.RequeueJob(context.Background(), job.JobID); err != nil {
+				logger.Error("failed to requeue job", zap.Error(err))
+			}
+			logger.Info("job interrupted", zap.Int("processed", progress.Processed))
+			return
+		}
+		r.fail(job, logger, err)
+		return
+	}
+
+	if err := r.repo.CompleteJob(ctx, job.JobID); err != nil {
+		logger.Error("failed to complete job", zap.Error(err))
+		return
+	}
+
+	logger.Info("job completed",
+		zap.Int("succeeded", progress.Succeeded),
+		zap.Int("failed", progress.Failed))
+}
+
+// runTask runs a job that produces a file, an interrupted task starts over on the next start
+func (r *Runner) runTask(ctx context.Context, job types.Job, task Task, logger *zap.Logger) {
+	logger.Info("running job task", zap.Int("total", job.Total))
+
+	rows, resultKey, err := task(ctx, job)
+	if err != nil {
+		if ctx.Err() != nil {
+			if err := r.repo.RequeueJob(context.Background(), job.JobID); err != nil {
+				logger.Error("failed to requeue job", zap.Error(err))
+			}
+			logger.Info("job interrupted")
+			return
+		}
+		r.fail(job, logger, err)
+		return
+	}
+
+	if err := r.repo.CompleteJobWithResult(ctx, job.JobID, rows, resultKey); err != nil {
+		logger.Error("failed to complete job", zap.Error(err))
+		return
+	}
+
+	logger.Info("job completed", zap.Int("rows", rows))
+}
+
+func (r *Runner) fail(job types.Job, logger *zap.Logger, reason error) {
+	logger.Error("job failed", zap.Error(reason))
+
+	if err := r.repo.FailJob(context.Background(), job.JobID, reason.Error()); err != nil {
+		logger.Error("failed to mark job as failed", zap.Error(err))
+	}
+}
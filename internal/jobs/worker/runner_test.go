@@ -0,0 +1,360 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/config"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/bulk"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/jobs/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/jobs/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// Mock repository
+type mockJobRepository struct {
+	mock.Mock
+}
+
+func (m *mockJobRepository) CreateJob(ctx context.Context, userID uuid.UUID, jobType types.JobType, total int, payload []byte) (types.Job, error) {
+	args := m.Called(ctx, userID, jobType, total, payload)
+	return args.Get(0).(types.Job), args.Error(1)
+}
+
+func (m *mockJobRepository) GetJob(ctx context.Context, jobID, userID uuid.UUID) (types.Job, error) {
+	args := m.Called(ctx, jobID, userID)
+	return args.Get(0).(types.Job), args.Error(1)
+}
+
+func (m *mockJobRepository) ClaimNextJob(ctx context.Context) (types.Job, bool, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(types.Job), args.Bool(1), args.Error(2)
+}
+
+func (m *mockJobRepository) UpdateJobProgress(ctx context.Context, jobID uuid.UUID, progress bulk.Progress) error {
+	args := m.Called(ctx, jobID, progress)
+	return args.Error(0)
+}
+
+func (m *mockJobRepository) CompleteJob(ctx context.Context, jobID uuid.UUID) error {
+	args := m.Called(ctx, jobID)
+	return args.Error(0)
+}
+
+func (m *mockJobRepository) CompleteJobWithResult(ctx context.Context, jobID uuid.UUID, rows int, resultKey string) error {
+	args := m.Called(ctx, jobID, rows, resultKey)
+	return args.Error(0)
+}
+
+func (m *mockJobRepository) FailJob(ctx context.Context, jobID uuid.UUID, reason string) error {
+	args := m.Called(ctx, jobID, reason)
+	return args.Error(0)
+}
+
+func (m *mockJobRepository) RequeueJob(ctx context.Context, jobID uuid.UUID) error {
+	args := m.Called(ctx, jobID)
+	return args.Error(0)
+}
+
+func (m *mockJobRepository) HeartbeatJob(ctx context.Context, jobID uuid.UUID) error {
+	args := m.Called(ctx, jobID)
+	return args.Error(0)
+}
+
+func (m *mockJobRepository) RequeueStaleJobs(ctx context.Context, staleBefore time.Time) (int64, error) {
+	args := m.Called(ctx, staleBefore)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// fakeTx lets the bulk engine run without a database; rows under test must not run queries
+type fakeTx struct {
+	pgx.Tx
+}
+
+func (t *fakeTx) Begin(ctx context.Context) (pgx.Tx, error) { return &fakeTx{}, nil }
+func (t *fakeTx) Commit(ctx context.Context) error          { return nil }
+func (t *fakeTx) Rollback(ctx context.Context) error        { return nil }
+
+type fakeDB struct{}
+
+func (fakeDB) Begin(ctx context.Context) (pgx.Tx, error) { return &fakeTx{}, nil }
+
+func setupTest(t *testing.T) (*mockJobRepository, *Runner) {
+	mockRepo := new(mockJobRepository)
+	logger := zap.NewNop()
+	engine := bulk.NewEngine(fakeDB{}, bulk.Config{ChunkSize: 2, RetryDelay: time.Millisecond}, logger)
+	runner := newRunner(mockRepo, func(q *db.Queries) repository.Repository { return mockRepo }, engine,
+		config.JobsConfig{Workers: 1, PollInterval: 10 * time.Millisecond}, logger)
+	return mockRepo, runner
+}
+
+func TestRunner_ProcessResumesFromProgress(t *testing.T) {
+	mockRepo, runner := setupTest(t)
+	job := types.Job{JobID: uuid.New(), Type: types.JobTypeContactImport, Total: 5, Processed: 2, Succeeded: 2}
+
+	var written []int
+	runner.Register(types.JobTypeContactImport, func(ctx context.Context, job types.Job) (int, bulk.RowFunc, error) {
+		return job.Total, func(ctx context.Context, q *db.Queries, index int) error {
+			written = append(written, index)
+			if index == 3 {
+				return errors.New("bad row")
+			}
+			return nil
+		}, nil
+	})
+
+	mockRepo.On("UpdateJobProgress", mock.Anything, job.JobID, bulk.Progress{
+		Processed: 4, Succeeded: 3, Failed: 1, Errors: []bulk.RowError{{Index: 3, Error: "bad row"}},
+	}).Return(nil).Once()
+	mockRepo.On("UpdateJobProgress", mock.Anything, job.JobID, bulk.Progress{
+		Processed: 5, Succeeded: 4, Failed: 1, Errors: []bulk.RowError{{Index: 3, Error: "bad row"}},
+	}).Return(nil).Once()
+	mockRepo.On("CompleteJob", mock.Anything, job.JobID).Return(nil)
+
+	runner.process(context.Background(), job)
+
+	assert.Equal(t, []int{2, 3, 4}, written)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRunner_ProcessFailures(t *testing.T) {
+	tests := []struct {
+		name      string
+		processor Processor
+		reason    string
+	}{
+		{
+			name:   "unknown job type",
+			reason: `no processor registered for job type "contact_import"`,
+		},
+		{
+			name: "processor error",
+			processor: func(ctx context.Context, job types.Job) (int, bulk.RowFunc, error) {
+				return 0, nil, errors.New("decode contacts: unexpected end of JSON input")
+			},
+			reason: "decode contacts: unexpected end of JSON input",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo, runner := setupTest(t)
+			if tt.processor != nil {
+				runner.Register(types.JobTypeContactImport, tt.processor)
+			}
+			job := types.Job{JobID: uuid.New(), Type: types.JobTypeContactImport}
+			mockRepo.On("FailJob", mock.Anything, job.JobID, tt.reason).Return(nil)
+
+			runner.process(context.Background(), job)
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRunner_ProcessTask(t *testing.T) {
+	t.Run("completes with the result", func(t *testing.T) {
+		mockRepo, runner := setupTest(t)
+		job := types.Job{JobID: uuid.New(), Type: types.JobTypeContactExport, Total: 3}
+		runner.RegisterTask(types.JobTypeContactExport, func(ctx context.Context, job types.Job) (int, string, error) {
+			return 3, "exports/" + job.JobID.String() + ".csv", nil
+		})
+		mockRepo.On("CompleteJobWithResult", mock.Anything, job.JobID, 3, "exports/"+job.JobID.String()+".csv").Return(nil)
+
+		runner.process(context.Background(), job)
+
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "UpdateJobProgress", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("fails with the task's error", func(t *testing.T) {
+		mockRepo, runner := setupTest(t)
+		job := types.Job{JobID: uuid.New(), Type: types.JobTypeContactExport}
+		runner.RegisterTask(types.JobTypeContactExport, func(ctx context.Context, job types.Job) (int, string, error) {
+			return 0, "", errors.New("write blob: no space left on device")
+		})
+		mockRepo.On("FailJob", mock.Anything, job.JobID, "write blob: no space left on device").Return(nil)
+
+		runner.process(context.Background(), job)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("requeued when interrupted", func(t *testing.T) {
+		mockRepo, runner := setupTest(t)
+		job := types.Job{JobID: uuid.New(), Type: types.JobTypeContactExport}
+		ctx, cancel := context.WithCancel(context.Background())
+		runner.RegisterTask(types.JobTypeContactExport, func(ctx context.Context, job types.Job) (int, string, error) {
+			cancel()
+			return 0, "", ctx.Err()
+		})
+		mockRepo.On("RequeueJob", mock.Anything, job.JobID).Return(nil)
+
+		runner.process(ctx, job)
+
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "FailJob", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestRunner_RunPending(t *testing.T) {
+	mockRepo, runner := setupTest(t)
+	first := types.Job{JobID: uuid.New(), Type: types.JobTypeAccountBackup}
+	second := types.Job{JobID: uuid.New(), Type: types.JobTypeAccountBackup}
+	runner.RegisterTask(types.JobTypeAccountBackup, func(ctx context.Context, job types.Job) (int, string, error) {
+		return 1, "backups/" + job.JobID.String() + ".zip", nil
+	})
+
+	mockRepo.On("ClaimNextJob", mock.Anything).Return(first, true, nil).Once()
+	mockRepo.On("ClaimNextJob", mock.Anything).Return(second, true, nil).Once()
+	mockRepo.On("ClaimNextJob", mock.Anything).Return(types.Job{}, false, nil).Once()
+	mockRepo.On("CompleteJobWithResult", mock.Anything, first.JobID, 1, "backups/"+first.JobID.String()+".zip").Return(nil)
+	mockRepo.On("CompleteJobWithResult", mock.Anything, second.JobID, 1, "backups/"+second.JobID.String()+".zip").Return(nil)
+
+	assert.Equal(t, 2, runner.RunPending(context.Background()))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRunner_StartRequeuesAndDrainsQueue(t *testing.T) {
+	mockRepo, runner := setupTest(t)
+	job := types.Job{JobID: uuid.New(), Type: types.JobTypeContactImport, Total: 1}
+
+	done := make(chan struct{})
+	runner.Register(types.JobTypeContactImport, func(ctx context.Context, job types.Job) (int, bulk.RowFunc, error) {
+		return job.Total, func(ctx context.Context, q *db.Queries, index int) error { return nil }, nil
+	})
+
+	mockRepo.On("RequeueStaleJobs", mock.Anything, mock.Anything).Return(int64(1), nil)
+	mockRepo.On("ClaimNextJob", mock.Anything).Return(job, true, nil).Once()
+	mockRepo.On("ClaimNextJob", mock.Anything).Return(types.Job{}, false, nil)
+	mockRepo.On("UpdateJobProgress", mock.Anything, job.JobID, bulk.Progress{Processed: 1, Succeeded: 1}).Return(nil)
+	mockRepo.On("CompleteJob", mock.Anything, job.JobID).Return(nil).Run(func(args mock.Arguments) { close(done) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, runner.Start(ctx))
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("job was not processed")
+	}
+	cancel()
+	runner.Wait()
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRunner_StartRequeuesOnlyStaleJobs(t *testing.T) {
+	mockRepo, runner := setupTest(t)
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	runner.now = func() time.Time { return now }
+
+	// jobs other instances heartbeat within the timeout stay with them
+	mockRepo.On("RequeueStaleJobs", mock.Anything, now.Add(-DefaultStaleAfter)).Return(int64(0), nil).Once()
+	mockRepo.On("ClaimNextJob", mock.Anything).Return(types.Job{}, false, nil).Maybe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, runner.Start(ctx))
+	cancel()
+	runner.Wait()
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRunner_RequeuesJobsOfStoppedInstances(t *testing.T) {
+	mockRepo := new(mockJobRepository)
+	logger := zap.NewNop()
+	runner := newRunner(mockRepo, func(q *db.Queries) repository.Repository { return mockRepo }, nil,
+		config.JobsConfig{Workers: 1, PollInterval: time.Hour, StaleAfter: 20 * time.Millisecond}, logger)
+
+	job := types.Job{JobID: uuid.New(), Type: types.JobTypeAccountBackup}
+	done := make(chan struct{})
+	runner.RegisterTask(types.JobTypeAccountBackup, func(ctx context.Context, job types.Job) (int, string, error) {
+		return 1, "backups/" + job.JobID.String() + ".zip", nil
+	})
+
+	// nothing is stale at start, another instance stops later and its job turns stale
+	mockRepo.On("RequeueStaleJobs", mock.Anything, mock.Anything).Return(int64(0), nil).Once()
+	mockRepo.On("RequeueStaleJobs", mock.Anything, mock.Anything).Return(int64(1), nil).Once()
+	mockRepo.On("RequeueStaleJobs", mock.Anything, mock.Anything).Return(int64(0), nil)
+	mockRepo.On("ClaimNextJob", mock.Anything).Return(types.Job{}, false, nil).Once()
+	mockRepo.On("ClaimNextJob", mock.Anything).Return(job, true, nil).Once()
+	mockRepo.On("ClaimNextJob", mock.Anything).Return(types.Job{}, false, nil)
+	mockRepo.On("HeartbeatJob", mock.Anything, job.JobID).Return(nil).Maybe()
+	mockRepo.On("CompleteJobWithResult", mock.Anything, job.JobID, 1, mock.Anything).Return(nil).
+		Run(func(args mock.Arguments) { close(done) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, runner.Start(ctx))
+
+	// the poll interval is an hour, the requeue wakes the worker
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("requeued job was not processed")
+	}
+	cancel()
+	runner.Wait()
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRunner_HeartbeatsWhileRunning(t *testing.T) {
+	mockRepo := new(mockJobRepository)
+	logger := zap.NewNop()
+	runner := newRunner(mockRepo, func(q *db.Queries) repository.Repository { return mockRepo }, nil,
+		config.JobsConfig{Workers: 1, StaleAfter: 40 * time.Millisecond}, logger)
+
+	job := types.Job{JobID: uuid.New(), Type: types.JobTypeAccountBackup}
+	runner.RegisterTask(types.JobTypeAccountBackup, func(ctx context.Context, job types.Job) (int, string, error) {
+		time.Sleep(100 * time.Millisecond)
+		return 1, "backups/" + job.JobID.String() + ".zip", nil
+	})
+	mockRepo.On("HeartbeatJob", mock.Anything, job.JobID).Return(nil)
+	mockRepo.On("CompleteJobWithResult", mock.Anything, job.JobID, 1, mock.Anything).Return(nil)
+
+	runner.process(context.Background(), job)
+	beats := len(mockRepo.Calls) - 1
+	assert.GreaterOrEqual(t, beats, 2, "the job is touched while it runs")
+
+	// and left alone once it's done
+	time.Sleep(30 * time.Millisecond)
+	assert.Len(t, mockRepo.Calls, beats+1)
+}
+
+func TestRunner_Enqueue(t *testing.T) {
+	mockRepo, runner := setupTest(t)
+	userID := uuid.New()
+
+	_, err := runner.Enqueue(context.Background(), userID, types.JobTypeContactImport, 1, []string{"a"})
+	assert.Error(t, err, "unregistered job types are rejected")
+
+	runner.Register(types.JobTypeContactImport, func(ctx context.Context, job types.Job) (int, bulk.RowFunc, error) {
+		return 0, nil, nil
+	})
+	mockRepo.On("CreateJob", mock.Anything, userID, types.JobTypeContactImport, 1, []byte(`["a"]`)).
+		Return(types.Job{JobID: uuid.New(), Status: types.JobStatusPending, Total: 1}, nil)
+
+	job, err := runner.Enqueue(context.Background(), userID, types.JobTypeContactImport, 1, []string{"a"})
+	require.NoError(t, err)
+	assert.Equal(t, types.JobStatusPending, job.Status)
+	assert.Len(t, runner.wake, 1, "enqueueing wakes a worker")
+
+	// tasks are job types too
+	runner.RegisterTask(types.JobTypeContactExport, func(ctx context.Context, job types.Job) (int, string, error) {
+		return 0, "", nil
+	})
+	mockRepo.On("CreateJob", mock.Anything, userID, types.JobTypeContactExport, 0, []byte(`{}`)).
+		Return(types.Job{JobID: uuid.New(), Status: types.JobStatusPending}, nil)
+	_, err = runner.Enqueue(context.Background(), userID, types.JobTypeContactExport, 0, struct{}{})
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/merges/service"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type MergeHandler struct {
+	handlers.BaseHandler
+	service service.MergeService
+}
+
+func NewMergeHandler(service service.MergeService, logger *zap.Logger) *MergeHandler {
+	return &MergeHandler{
+		BaseHandler: handlers.NewBaseHandler(logger),
+		service:     service,
+	}
+}
+
+// mergeParams reads the user and the keepId and dropId path parameters, responding with
+// the error when one is missing or invalid
+func (h *MergeHandler) mergeParams(w http.ResponseWriter, r *http.Request) (userID, keptID, droppedID uuid.UUID, ok bool) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return userID, keptID, droppedID, false
+	}
+
+	if keptID, err = uuid.Parse(chi.URLParam(r, "keepId")); err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return userID, keptID, droppedID, false
+	}
+	if droppedID, err = uuid.Parse(chi.URLParam(r, "dropId")); err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return userID, keptID, droppedID, false
+	}
+	return userID, keptID, droppedID, true
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+)
+
+// MergeContacts godoc
+// @Summary Merge a duplicate Contact into another
+// @Description Merges the dropped contact into the kept one in a single transaction. The relationships of the dropped contact move to the kept one, except those between the two and those the kept contact already has, which are deleted.
+// @Description The empty fields of the kept contact are filled from the dropped one, fields holding a value are never overwritten, and the tags of both are combined up to 10.
+// @Description The dropped contact is then deleted for good, both contacts as they were before the merge are kept in the merge audit.
+// @Tags Contacts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param keepId path string true "ID of the contact to keep" format(uuid)
+// @Param dropId path string true "ID of the contact to merge into it and delete" format(uuid)
+// @Success 200 {object} payloads.Response{data=types.ContactMergeResult}
+// @Failure 400 {object} errors.ErrorResponse "Invalid IDs or the same contact twice"
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse "Either contact doesn't exist, is in the trash or belongs to another user"
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /contacts/{keepId}/merge/{dropId} [post]
+// @ID MergeContacts
+func (h *MergeHandler) MergeContacts(w http.ResponseWriter, r *http.Request) {
+	userID, keptID, droppedID, ok := h.mergeParams(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.MergeContacts(r.Context(), userID, keptID, droppedID)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.OK(result))
+}
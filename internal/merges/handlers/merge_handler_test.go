@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	contactTypes "github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	coreErrors "github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/merges/types"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// Mock service
+type mockMergeService struct {
+	mock.Mock
+}
+
+func (m *mockMergeService) MergeContacts(ctx context.Context, userID, keptID, droppedID uuid.UUID) (types.ContactMergeResult, error) {
+	args := m.Called(ctx, userID, keptID, droppedID)
+	return args.Get(0).(types.ContactMergeResult), args.Error(1)
+}
+
+func (m *mockMergeService) MergeWallets(ctx context.Context, userID, keptID, droppedID uuid.UUID) (types.WalletMergeResult, error) {
+	args := m.Called(ctx, userID, keptID, droppedID)
+	return args.Get(0).(types.WalletMergeResult), args.Error(1)
+}
+
+// newRequest builds a merge request carrying the user and the keepId and dropId route parameters
+func newRequest(target string, userID uuid.UUID, keepID, dropID string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, target, nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("keepId", keepID)
+	rctx.URLParams.Add("dropId", dropID)
+	ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
+	ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+	return req.WithContext(ctx)
+}
+
+func TestMergeHandler_MergeContacts(t *testing.T) {
+	userID, keptID, droppedID := uuid.New(), uuid.New(), uuid.New()
+
+	tests := []struct {
+		name           string
+		keepID, dropID string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{name: "successful merge", keepID: keptID.String(), dropID: droppedID.String(), expectedStatus: http.StatusOK},
+		{name: "invalid keep ID", keepID: "nope", dropID: droppedID.String(), expectedStatus: http.StatusBadRequest},
+		{name: "invalid drop ID", keepID: keptID.String(), dropID: "nope", expectedStatus: http.StatusBadRequest},
+		{name: "same contact twice", keepID: keptID.String(), dropID: droppedID.String(), serviceErr: coreErrors.NewValidationError("dropId: a contact can't be merged into itself"), expectedStatus: http.StatusBadRequest},
+		{name: "other user's contact", keepID: keptID.String(), dropID: droppedID.String(), serviceErr: coreErrors.NewNotFoundError("contact not found"), expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockMergeService)
+			handler := NewMergeHandler(mockService, zap.NewNop())
+
+			_, keepErr := uuid.Parse(tt.keepID)
+			_, dropErr := uuid.Parse(tt.dropID)
+			parsed := keepErr == nil && dropErr == nil
+			if parsed {
+				mockService.On("MergeContacts", mock.Anything, userID, keptID, droppedID).Return(types.ContactMergeResult{
+					Contact:          contactTypes.Contact{ContactID: keptID},
+					DroppedContactID: droppedID,
+					FilledFields:     []string{"email"},
+				}, tt.serviceErr)
+			}
+
+			rr := httptest.NewRecorder()
+			handler.MergeContacts(rr, newRequest("/contacts/"+tt.keepID+"/merge/"+tt.dropID, userID, tt.keepID, tt.dropID))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if !parsed {
+				mockService.AssertNotCalled(t, "MergeContacts", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+			if tt.expectedStatus == http.StatusOK {
+				var body struct {
+					Data types.ContactMergeResult `json:"data"`
+				}
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&body))
+				assert.Equal(t, keptID, body.Data.Contact.ContactID)
+				assert.Equal(t, droppedID, body.Data.DroppedContactID)
+				assert.Equal(t, []string{"email"}, body.Data.FilledFields)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMergeHandler_MergeWallets(t *testing.T) {
+	userID, keptID, droppedID := uuid.New(), uuid.New(), uuid.New()
+
+	tests := []struct {
+		name           string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{name: "successful merge", expectedStatus: http.StatusOK},
+		{name: "currency mismatch", serviceErr: coreErrors.NewValidationError("dropId: wallet holds EUR and can't be merged into a USD wallet"), expectedStatus: http.StatusBadRequest},
+		{name: "wallet not found", serviceErr: coreErrors.NewNotFoundError("wallet not found"), expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockMergeService)
+			handler := NewMergeHandler(mockService, zap.NewNop())
+			mockService.On("MergeWallets", mock.Anything, userID, keptID, droppedID).Return(types.WalletMergeResult{DroppedWalletID: droppedID}, tt.serviceErr)
+
+			rr := httptest.NewRecorder()
+			handler.MergeWallets(rr, newRequest("/wallets/x/merge/y", userID, keptID.String(), droppedID.String()))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+)
+
+// MergeWallets godoc
+// @Summary Merge a duplicate Wallet into another
+// @Description Merges the dropped wallet into the kept one in a single transaction, both have to hold the same currency. The ledger entries of the dropped wallet move to the kept one, which takes on its balance, and it replaces the dropped wallet as the default wallet.
+// @Description The empty fields of the kept wallet are filled from the dropped one, fields holding a value are never overwritten, and the tags of both are combined up to 10.
+// @Description The dropped wallet is then deleted for good, both wallets as they were before the merge are kept in the merge audit.
+// @Tags Wallets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param keepId path string true "ID of the wallet to keep" format(uuid)
+// @Param dropId path string true "ID of the wallet to merge into it and delete" format(uuid)
+// @Success 200 {object} payloads.Response{data=types.WalletMergeResult}
+// @Failure 400 {object} errors.ErrorResponse "Invalid IDs, the same wallet twice or wallets of different currencies"
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse "Either wallet doesn't exist, is in the trash or belongs to another user"
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /wallets/{keepId}/merge/{dropId} [post]
+// @ID MergeWallets
+func (h *MergeHandler) MergeWallets(w http.ResponseWriter, r *http.Request) {
+	userID, keptID, droppedID, ok := h.mergeParams(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.MergeWallets(r.Context(), userID, keptID, droppedID)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.OK(result))
+}
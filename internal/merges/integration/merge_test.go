@@ -0,0 +1,319 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/config"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/merges/routes"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"go.uber.org/zap"
+)
+
+type MergeIntegrationTestSuite struct {
+	suite.Suite
+	container   testcontainers.Container
+	service     db.Service
+	pool        *pgxpool.Pool
+	router      *chi.Mux
+	userID      uuid.UUID
+	otherUserID uuid.UUID
+	ctx         context.Context
+}
+
+func TestMergeIntegrationSuite(t *testing.T) {
+	suite.Run(t, new(MergeIntegrationTestSuite))
+}
+
+func (s *MergeIntegrationTestSuite) SetupSuite() {
+	s.ctx = context.Background()
+	s.userID = uuid.New()
+	s.otherUserID = uuid.New()
+
+	var host, port string
+
+	if os.Getenv("CI") == "true" {
+		// Running in GitHub Actions, use service-based PostgreSQL
+		host = "localhost"
+		port = "5432"
+	} else {
+		// Running locally, use TestContainers
+		req := testcontainers.ContainerRequest{
+			Image:        "postgres:15-alpine",
+			ExposedPorts: []string{"5432/tcp"},
+			WaitingFor:   wait.ForListeningPort("5432/tcp"),
+			Env: map[string]string{
+				"POSTGRES_DB":       "testdb",
+				"POSTGRES_USER":     "test",
+				"POSTGRES_PASSWORD": "test",
+			},
+			NetworkMode: "bridge",
+		}
+
+		container, err := testcontainers.GenericContainer(s.ctx, testcontainers.GenericContainerRequest{
+			ContainerRequest: req,
+			Started:          true,
+		})
+		require.NoError(s.T(), err)
+		s.container = container
+
+		host, err = container.Host(s.ctx)
+		require.NoError(s.T(), err)
+		mappedPort, err := container.MappedPort(s.ctx, "5432")
+		require.NoError(s.T(), err)
+		port = mappedPort.Port()
+	}
+
+	cfg := config.DatabaseConfig{
+		Host:        host,
+		Port:        port,
+		Username:    "test",
+		Password:    "test",
+		Database:    "testdb",
+		Schema:      "public",
+		MaxConns:    5,
+		MinConns:    1,
+		MaxLifetime: time.Hour,
+		MaxIdleTime: time.Minute * 30,
+		HealthCheck: time.Minute,
+		SSLMode:     "disable",
+		SearchPath:  "public",
+	}
+
+	dbService := db.NewService(cfg)
+	s.service = dbService
+
+	pool, err := pgxpool.New(s.ctx, cfg.GetDSN())
+	require.NoError(s.T(), err)
+	s.pool = pool
+
+	require.NoError(s.T(), s.runMigrations())
+
+	// Create test users
+	_, err = s.pool.Exec(s.ctx, `
+		INSERT INTO users (user_id, external_id, name, email)
+		VALUES ($1, 'mgit_test_clerk_id', 'mgit_Test User', 'mgit_test@example.com'),
+		       ($2, 'mgit_other_clerk_id', 'mgit_Other User', 'mgit_other@example.com')
+	`, s.userID, s.otherUserID)
+	require.NoError(s.T(), err)
+
+	router := chi.NewRouter()
+	routes.New(dbService, nil, zap.NewNop()).RegisterRoutes(router)
+	s.router = router
+}
+
+func (s *MergeIntegrationTestSuite) TearDownSuite() {
+	if s.pool != nil {
+		_, _ = s.pool.Exec(s.ctx, "DELETE FROM users WHERE user_id IN ($1, $2)", s.userID, s.otherUserID)
+		s.pool.Close()
+	}
+	if s.service != nil {
+		s.service.Close()
+	}
+	if s.container != nil && os.Getenv("CI") != "true" {
+		err := s.container.Terminate(s.ctx)
+		require.NoError(s.T(), err)
+	}
+}
+
+func (s *MergeIntegrationTestSuite) SetupTest() {
+	_, err := s.pool.Exec(s.ctx, "UPDATE users SET default_wallet_id = NULL WHERE user_id IN ($1, $2)", s.userID, s.otherUserID)
+	s.Require().NoError(err)
+	for _, table := range []string{"merge_audit", "contact_relationships", "contacts", "wallets", "tags"} {
+		_, err := s.pool.Exec(s.ctx, "DELETE FROM "+table+" WHERE user_id IN ($1, $2)", s.userID, s.otherUserID)
+		s.Require().NoError(err)
+	}
+}
+
+func (s *MergeIntegrationTestSuite) runMigrations() error {
+	migrationsDir := "../../db/sql/migrations"
+
+	sqlDB := stdlib.OpenDBFromPool(s.pool)
+	defer sqlDB.Close()
+
+	if err := goose.SetDialect("postgres"); err != nil {
+		return err
+	}
+	return goose.Up(sqlDB, migrationsDir)
+}
+
+// merge posts the merge of dropID into keepID as the user and decodes the response
+func (s *MergeIntegrationTestSuite) merge(userID uuid.UUID, entity string, keepID, dropID uuid.UUID) (int, map[string]interface{}) {
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/%s/%s/merge/%s", entity, keepID, dropID), nil)
+	req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+
+	var response map[string]interface{}
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+	return w.Code, response
+}
+
+func (s *MergeIntegrationTestSuite) createTags(n int) []uuid.UUID {
+	tags := make([]uuid.UUID, n)
+	for i := range tags {
+		err := s.pool.QueryRow(s.ctx, `
+			INSERT INTO tags (user_id, name) VALUES ($1, $2) RETURNING tag_id
+		`, s.userID, fmt.Sprintf("tag %d", i)).Scan(&tags[i])
+		s.Require().NoError(err)
+	}
+	return tags
+}
+
+// createWallet adds a wallet, a balance other than zero records its opening ledger entry
+func (s *MergeIntegrationTestSuite) createWallet(userID uuid.UUID, name, currency string, balance *float64, tags []uuid.UUID) uuid.UUID {
+	var walletID uuid.UUID
+	err := s.pool.QueryRow(s.ctx, `
+		INSERT INTO wallets (user_id, name, currency, balance, tags) VALUES ($1, $2, $3, $4, $5) RETURNING wallet_id
+	`, userID, name, currency, balance, tags).Scan(&walletID)
+	s.Require().NoError(err)
+	return walletID
+}
+
+func (s *MergeIntegrationTestSuite) createContact(userID uuid.UUID, name string, email, city *string, tags []uuid.UUID) uuid.UUID {
+	var contactID uuid.UUID
+	err := s.pool.QueryRow(s.ctx, `
+		INSERT INTO contacts (user_id, name, email, city, tags) VALUES ($1, $2, $3, $4, $5) RETURNING contact_id
+	`, userID, name, email, city, tags).Scan(&contactID)
+	s.Require().NoError(err)
+	return contactID
+}
+
+func (s *MergeIntegrationTestSuite) relate(from, to uuid.UUID, relationship string) {
+	_, err := s.pool.Exec(s.ctx, `
+		INSERT INTO contact_relationships (user_id, from_contact_id, to_contact_id, type) VALUES ($1, $2, $3, $4)
+	`, s.userID, from, to, relationship)
+	s.Require().NoError(err)
+}
+
+func (s *MergeIntegrationTestSuite) count(query string, args ...interface{}) int {
+	var n int
+	s.Require().NoError(s.pool.QueryRow(s.ctx, query, args...).Scan(&n))
+	return n
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func (s *MergeIntegrationTestSuite) TestMergeWallets() {
+	tags := s.createTags(2)
+	kept := s.createWallet(s.userID, "Cash", "USD", ptr(100.0), tags[:1])
+	dropped := s.createWallet(s.userID, "Cash (old)", "USD", ptr(40.0), tags)
+	// records a -15 adjustment next to the opening 40
+	_, err := s.pool.Exec(s.ctx, "UPDATE wallets SET balance = 25 WHERE wallet_id = $1", dropped)
+	s.Require().NoError(err)
+	_, err = s.pool.Exec(s.ctx, "UPDATE users SET default_wallet_id = $1 WHERE user_id = $2", dropped, s.userID)
+	s.Require().NoError(err)
+	ledgerBefore := s.count("SELECT count(*) FROM wallet_ledger_entries WHERE wallet_id IN ($1, $2)", kept, dropped)
+
+	status, response := s.merge(s.userID, "wallets", kept, dropped)
+	s.Require().Equal(http.StatusOK, status, response)
+
+	data := response["data"].(map[string]interface{})
+	wallet := data["wallet"].(map[string]interface{})
+	s.Equal(kept.String(), wallet["walletId"])
+	s.Equal(125.0, wallet["balance"])
+	s.Len(wallet["tags"], 2)
+	s.Equal(dropped.String(), data["droppedWalletId"])
+
+	// the entries moved over and the added balance didn't record an adjustment on top
+	s.Equal(ledgerBefore, s.count("SELECT count(*) FROM wallet_ledger_entries WHERE wallet_id = $1", kept))
+	var ledgerSum float64
+	s.Require().NoError(s.pool.QueryRow(s.ctx, "SELECT SUM(amount)::float8 FROM wallet_ledger_entries WHERE wallet_id = $1", kept).Scan(&ledgerSum))
+	s.Equal(125.0, ledgerSum)
+
+	var defaultWallet uuid.UUID
+	s.Require().NoError(s.pool.QueryRow(s.ctx, "SELECT default_wallet_id FROM users WHERE user_id = $1", s.userID).Scan(&defaultWallet))
+	s.Equal(kept, defaultWallet)
+
+	s.Zero(s.count("SELECT count(*) FROM wallets WHERE wallet_id = $1", dropped))
+	s.Equal(1, s.count("SELECT count(*) FROM merge_audit WHERE entity_type = 'wallet' AND kept_id = $1 AND dropped_id = $2 AND dropped_snapshot->>'name' = 'Cash (old)'", kept, dropped))
+
+	// the balance updates of the kept wallet are recorded again once the merge committed
+	_, err = s.pool.Exec(s.ctx, "UPDATE wallets SET balance = 130 WHERE wallet_id = $1", kept)
+	s.Require().NoError(err)
+	s.Equal(1, s.count("SELECT count(*) FROM wallet_ledger_entries WHERE wallet_id = $1 AND description = 'Balance adjustment' AND amount = 5", kept))
+}
+
+func (s *MergeIntegrationTestSuite) TestMergeWallets_Rejected() {
+	usd := s.createWallet(s.userID, "Cash", "USD", nil, nil)
+	eur := s.createWallet(s.userID, "Euros", "EUR", nil, nil)
+	other := s.createWallet(s.otherUserID, "Theirs", "USD", nil, nil)
+
+	status, _ := s.merge(s.userID, "wallets", usd, eur)
+	s.Equal(http.StatusBadRequest, status)
+
+	status, _ = s.merge(s.userID, "wallets", usd, other)
+	s.Equal(http.StatusNotFound, status)
+
+	status, _ = s.merge(s.userID, "wallets", usd, usd)
+	s.Equal(http.StatusBadRequest, status)
+
+	s.Equal(3, s.count("SELECT count(*) FROM wallets WHERE wallet_id IN ($1, $2, $3)", usd, eur, other))
+	s.Zero(s.count("SELECT count(*) FROM merge_audit WHERE user_id IN ($1, $2)", s.userID, s.otherUserID))
+}
+
+func (s *MergeIntegrationTestSuite) TestMergeContacts() {
+	tags := s.createTags(12)
+	kept := s.createContact(s.userID, "Jane Doe", ptr("jane@example.com"), nil, tags[:6])
+	dropped := s.createContact(s.userID, "J. Doe", ptr("jdoe@example.com"), ptr("Cairo"), tags[4:])
+	employer := s.createContact(s.userID, "Acme", nil, nil, nil)
+	spouse := s.createContact(s.userID, "John Doe", nil, nil, nil)
+	referrer := s.createContact(s.userID, "Sam", nil, nil, nil)
+
+	s.relate(kept, employer, "works_for")
+	s.relate(dropped, employer, "works_for") // duplicates the kept contact's
+	s.relate(spouse, dropped, "spouse_of")
+	s.relate(dropped, referrer, "referred_by")
+	s.relate(dropped, kept, "relative_of") // between the two
+
+	status, response := s.merge(s.userID, "contacts", kept, dropped)
+	s.Require().Equal(http.StatusOK, status, response)
+
+	data := response["data"].(map[string]interface{})
+	contact := data["contact"].(map[string]interface{})
+	s.Equal("Jane Doe", contact["name"])
+	s.Equal("jane@example.com", contact["email"], "fields holding a value are never overwritten")
+	s.Equal("Cairo", contact["city"])
+	s.Len(contact["tags"], 10)
+	s.Equal([]interface{}{"city"}, data["filledFields"])
+	s.Equal(2.0, data["movedRelationships"])
+	s.Equal(2.0, data["droppedRelationships"])
+
+	s.Zero(s.count("SELECT count(*) FROM contacts WHERE contact_id = $1", dropped))
+	s.Zero(s.count("SELECT count(*) FROM contact_relationships WHERE $1 IN (from_contact_id, to_contact_id)", dropped))
+	s.Equal(3, s.count("SELECT count(*) FROM contact_relationships WHERE $1 IN (from_contact_id, to_contact_id)", kept))
+	s.Equal(1, s.count("SELECT count(*) FROM contact_relationships WHERE from_contact_id = $1 AND to_contact_id = $2 AND type = 'spouse_of'", spouse, kept))
+	s.Equal(1, s.count("SELECT count(*) FROM merge_audit WHERE entity_type = 'contact' AND dropped_snapshot->>'email' = 'jdoe@example.com'"))
+}
+
+func (s *MergeIntegrationTestSuite) TestMergeContacts_OtherUser() {
+	kept := s.createContact(s.userID, "Jane Doe", nil, nil, nil)
+	other := s.createContact(s.otherUserID, "Jane Doe", nil, nil, nil)
+
+	status, _ := s.merge(s.userID, "contacts", kept, other)
+	s.Equal(http.StatusNotFound, status)
+
+	status, _ = s.merge(s.otherUserID, "contacts", kept, other)
+	s.Equal(http.StatusNotFound, status)
+
+	s.Equal(2, s.count("SELECT count(*) FROM contacts WHERE contact_id IN ($1, $2)", kept, other))
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/merges/types"
+	"github.com/google/uuid"
+)
+
+// MergeRepository defines the interface for merging duplicate contacts and wallets
+type MergeRepository interface {
+	// MergeContacts moves the relationships of the dropped contact to the kept one, fills the
+	// kept contact's empty fields from it and deletes it, in one transaction. The result
+	// carries the kept contact's ID only.
+	MergeContacts(ctx context.Context, userID, keptID, droppedID uuid.UUID) (types.ContactMergeResult, error)
+
+	// MergeWallets moves the ledger entries and the balance of the dropped wallet to the kept
+	// one, fills the kept wallet's empty fields from it and deletes it, in one transaction.
+	// The result carries the kept wallet's ID only.
+	MergeWallets(ctx context.Context, userID, keptID, droppedID uuid.UUID) (types.WalletMergeResult, error)
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+
+	contactTypes "github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/merges/types"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/google/uuid"
+)
+
+// MergeContacts locks both contacts, records them in the merge audit and moves the dropped
+// contact's relationships to the kept one, dropping those that would relate the kept contact
+// to itself or duplicate one it has. The dropped contact is deleted before the kept one
+// fills its empty fields from it, so its email and external reference are free to move.
+func (r *mergeRepository) MergeContacts(ctx context.Context, userID, keptID, droppedID uuid.UUID) (types.ContactMergeResult, error) {
+	actorID := requestcontext.GetActorIDFromContext(ctx, userID)
+	result := types.ContactMergeResult{DroppedContactID: droppedID}
+
+	err := r.inTx(ctx, "contacts", func(q *db.Queries) error {
+		rows, err := q.LockContactsForMerge(ctx, db.LockContactsForMergeParams{
+			UserID:     userID,
+			ContactIds: []uuid.UUID{keptID, droppedID},
+		})
+		if err != nil {
+			return err
+		}
+		kept, dropped, err := pickPair(rows, func(c db.Contact) uuid.UUID { return c.ContactID }, keptID, droppedID, types.EntityContact)
+		if err != nil {
+			return err
+		}
+
+		if err := q.AuditContactMerge(ctx, db.AuditContactMergeParams{KeptID: keptID, DroppedID: droppedID, ActorID: actorID}); err != nil {
+			return err
+		}
+		if result.DroppedRelationships, err = q.DropMergedRelationships(ctx, db.DropMergedRelationshipsParams{KeptID: keptID, DroppedID: droppedID}); err != nil {
+			return err
+		}
+		if result.MovedRelationships, err = q.MoveContactRelationships(ctx, db.MoveContactRelationshipsParams{KeptID: keptID, DroppedID: droppedID}); err != nil {
+			return err
+		}
+		if err := q.PurgeMergedContact(ctx, db.PurgeMergedContactParams{ContactID: droppedID, UserID: userID}); err != nil {
+			return err
+		}
+
+		fill := &filler{}
+		params := db.ApplyContactMergeParams{
+			Phone:          fill.text("phone", kept.Phone, dropped.Phone),
+			Email:          fill.text("email", kept.Email, dropped.Email),
+			AddressLine1:   fill.text("addressLine1", kept.AddressLine1, dropped.AddressLine1),
+			AddressLine2:   fill.text("addressLine2", kept.AddressLine2, dropped.AddressLine2),
+			Country:        fill.text("country", kept.Country, dropped.Country),
+			City:           fill.text("city", kept.City, dropped.City),
+			StateProvince:  fill.text("stateProvince", kept.StateProvince, dropped.StateProvince),
+			ZipPostalCode:  fill.text("zipPostalCode", kept.ZipPostalCode, dropped.ZipPostalCode),
+			Company:        fill.text("company", kept.Company, dropped.Company),
+			Notes:          fill.text("notes", kept.Notes, dropped.Notes),
+			Links:          fill.links("links", kept.Links, dropped.Links),
+			ExternalSource: fill.text("externalRef", kept.ExternalSource, dropped.ExternalSource),
+			ExternalID:     kept.ExternalID,
+			Tags:           unionTags(kept.Tags, dropped.Tags, contactTypes.MaxTagsCount),
+			ActorID:        actorID,
+			ContactID:      keptID,
+		}
+		// the source and the ID of an external reference only make sense together
+		if params.ExternalSource != kept.ExternalSource {
+			params.ExternalID = dropped.ExternalID
+		}
+		if params.Links == nil {
+			params.Links = []byte("[]")
+		}
+		_, err = q.ApplyContactMerge(ctx, params)
+		result.FilledFields = fill.fields()
+		return err
+	})
+	if err != nil {
+		return types.ContactMergeResult{}, err
+	}
+
+	result.Contact.ContactID = keptID
+	return result, nil
+}
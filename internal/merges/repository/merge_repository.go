@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/bulk"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+)
+
+type mergeRepository struct {
+	db bulk.TxBeginner
+}
+
+// NewMergeRepository creates a new instance of MergeRepository
+func NewMergeRepository(conn bulk.TxBeginner) MergeRepository {
+	return &mergeRepository{db: conn}
+}
+
+// inTx runs fn in a transaction, the errors fn returns for the client are passed on as
+// they are and the others are handled as repository errors
+func (r *mergeRepository) inTx(ctx context.Context, entity string, fn func(q *db.Queries) error) (err error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return errors.HandleRepositoryError(err, "merge", entity)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	if err = fn(db.New(tx)); err != nil {
+		if _, ok := err.(*errors.ErrorResponse); ok {
+			return err
+		}
+		return errors.HandleRepositoryError(err, "merge", entity)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return errors.HandleRepositoryError(err, "merge", entity)
+	}
+	return nil
+}
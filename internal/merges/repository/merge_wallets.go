@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/merges/types"
+	walletTypes "github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/google/uuid"
+)
+
+// MergeWallets locks both wallets, records them in the merge audit, moves the dropped
+// wallet's ledger entries and its place as the user's default wallet to the kept one and
+// deletes it. The kept wallet takes on its balance and tags and fills its empty fields from it.
+// The ledger entries moved over account for the added balance, so the update records none.
+func (r *mergeRepository) MergeWallets(ctx context.Context, userID, keptID, droppedID uuid.UUID) (types.WalletMergeResult, error) {
+	actorID := requestcontext.GetActorIDFromContext(ctx, userID)
+	result := types.WalletMergeResult{DroppedWalletID: droppedID}
+
+	err := r.inTx(ctx, "wallets", func(q *db.Queries) error {
+		rows, err := q.LockWalletsForMerge(ctx, db.LockWalletsForMergeParams{
+			UserID:    userID,
+			WalletIds: []uuid.UUID{keptID, droppedID},
+		})
+		if err != nil {
+			return err
+		}
+		kept, dropped, err := pickPair(rows, func(w db.Wallet) uuid.UUID { return w.WalletID }, keptID, droppedID, types.EntityWallet)
+		if err != nil {
+			return err
+		}
+		if kept.Currency != dropped.Currency {
+			return errors.NewValidationError("dropId: wallet %s holds %s and can't be merged into a %s wallet", droppedID, dropped.Currency, kept.Currency)
+		}
+
+		if err := q.AuditWalletMerge(ctx, db.AuditWalletMergeParams{KeptID: keptID, DroppedID: droppedID, ActorID: actorID}); err != nil {
+			return err
+		}
+		if result.MovedLedgerEntries, err = q.MoveLedgerEntries(ctx, db.MoveLedgerEntriesParams{KeptID: keptID, DroppedID: droppedID}); err != nil {
+			return err
+		}
+		if err := q.MoveDefaultWallet(ctx, db.MoveDefaultWalletParams{UserID: userID, KeptID: keptID, DroppedID: droppedID}); err != nil {
+			return err
+		}
+		if _, err := q.PurgeWallet(ctx, db.PurgeWalletParams{WalletID: droppedID, UserID: userID}); err != nil {
+			return err
+		}
+
+		if err := q.SkipWalletLedger(ctx, keptID); err != nil {
+			return err
+		}
+		fill := &filler{}
+		_, err = q.ApplyWalletMerge(ctx, db.ApplyWalletMergeParams{
+			DroppedBalance:      dropped.Balance,
+			ProjectID:           fill.uuid("projectId", kept.ProjectID, dropped.ProjectID),
+			GroupID:             fill.uuid("groupId", kept.GroupID, dropped.GroupID),
+			LowBalanceThreshold: fill.numeric("lowBalanceThreshold", kept.LowBalanceThreshold, dropped.LowBalanceThreshold),
+			Tags:                unionTags(kept.Tags, dropped.Tags, walletTypes.MaxTagsCount),
+			ActorID:             actorID,
+			WalletID:            keptID,
+		})
+		result.FilledFields = fill.fields()
+		return err
+	})
+	if err != nil {
+		return types.WalletMergeResult{}, err
+	}
+
+	result.Wallet.WalletID = keptID
+	return result, nil
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// pickPair finds the kept and the dropped row among the locked rows, either missing is
+// reported as not found
+func pickPair[T any](rows []T, id func(T) uuid.UUID, keptID, droppedID uuid.UUID, entity string) (kept, dropped T, err error) {
+	var foundKept, foundDropped bool
+	for _, row := range rows {
+		switch id(row) {
+		case keptID:
+			kept, foundKept = row, true
+		case droppedID:
+			dropped, foundDropped = row, true
+		}
+	}
+	if !foundKept {
+		return kept, dropped, errors.NewNotFoundError("%s %s not found", entity, keptID)
+	}
+	if !foundDropped {
+		return kept, dropped, errors.NewNotFoundError("%s %s not found", entity, droppedID)
+	}
+	return kept, dropped, nil
+}
+
+// filler fills the empty fields of the kept row from the dropped one, a field holding a
+// value is never overwritten. It names the fields it filled.
+type filler struct {
+	filled []string
+}
+
+func (f *filler) text(name string, kept, dropped pgtype.Text) pgtype.Text {
+	if !isBlank(kept) || isBlank(dropped) {
+		return kept
+	}
+	f.filled = append(f.filled, name)
+	return dropped
+}
+
+func (f *filler) uuid(name string, kept, dropped pgtype.UUID) pgtype.UUID {
+	if kept.Valid || !dropped.Valid {
+		return kept
+	}
+	f.filled = append(f.filled, name)
+	return dropped
+}
+
+func (f *filler) numeric(name string, kept, dropped pgtype.Numeric) pgtype.Numeric {
+	if kept.Valid || !dropped.Valid {
+		return kept
+	}
+	f.filled = append(f.filled, name)
+	return dropped
+}
+
+// links fills an empty JSON array of links
+func (f *filler) links(name string, kept, dropped []byte) []byte {
+	if !isEmptyArray(kept) || isEmptyArray(dropped) {
+		return kept
+	}
+	f.filled = append(f.filled, name)
+	return dropped
+}
+
+// fields returns the names of the filled fields, never nil so an empty list is rendered as []
+func (f *filler) fields() []string {
+	if f.filled == nil {
+		return []string{}
+	}
+	return f.filled
+}
+
+func isBlank(t pgtype.Text) bool {
+	return !t.Valid || strings.TrimSpace(t.String) == ""
+}
+
+func isEmptyArray(data []byte) bool {
+	var items []json.RawMessage
+	if len(data) == 0 || json.Unmarshal(data, &items) != nil {
+		return true
+	}
+	return len(items) == 0
+}
+
+// unionTags appends the dropped row's tags the kept row lacks to the kept row's, up to max
+func unionTags(kept, dropped []uuid.UUID, max int) []uuid.UUID {
+	tags := slices.Clone(kept)
+	for _, tag := range dropped {
+		if len(tags) >= max {
+			break
+		}
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
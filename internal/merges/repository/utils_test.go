@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func text(s string) pgtype.Text {
+	return pgtype.Text{String: s, Valid: true}
+}
+
+func TestFiller(t *testing.T) {
+	fill := &filler{}
+	assert.Equal(t, []string{}, fill.fields())
+
+	assert.Equal(t, text("kept"), fill.text("phone", text("kept"), text("dropped")), "values are never overwritten")
+	assert.Equal(t, text("dropped"), fill.text("email", pgtype.Text{}, text("dropped")))
+	assert.Equal(t, text("dropped"), fill.text("city", text("  "), text("dropped")), "blank counts as empty")
+	assert.Equal(t, pgtype.Text{}, fill.text("notes", pgtype.Text{}, text(" ")), "blank doesn't fill")
+
+	projectID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	assert.Equal(t, projectID, fill.uuid("projectId", pgtype.UUID{}, projectID))
+	assert.Equal(t, projectID, fill.uuid("groupId", projectID, pgtype.UUID{Bytes: uuid.New(), Valid: true}))
+
+	threshold := pgtype.Numeric{Int: nil, Valid: false}
+	assert.Equal(t, threshold, fill.numeric("lowBalanceThreshold", threshold, pgtype.Numeric{}))
+
+	links := []byte(`[{"label":"site","url":"https://example.com"}]`)
+	assert.Equal(t, links, fill.links("links", []byte(`[]`), links))
+	assert.Equal(t, links, fill.links("website", links, []byte(`[{"label":"other","url":"https://example.org"}]`)))
+
+	assert.Equal(t, []string{"email", "city", "projectId", "links"}, fill.fields())
+}
+
+func TestUnionTags(t *testing.T) {
+	a, b, c, d := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	assert.Equal(t, []uuid.UUID{a, b, c}, unionTags([]uuid.UUID{a, b}, []uuid.UUID{b, c}, 10))
+	assert.Equal(t, []uuid.UUID{c}, unionTags(nil, []uuid.UUID{c}, 10))
+	assert.Equal(t, []uuid.UUID{a, b, c}, unionTags([]uuid.UUID{a, b}, []uuid.UUID{c, d}, 3), "the kept tags come first and the union stops at the cap")
+	assert.Nil(t, unionTags(nil, nil, 10))
+}
+
+func TestPickPair(t *testing.T) {
+	keptID, droppedID := uuid.New(), uuid.New()
+	id := func(u uuid.UUID) uuid.UUID { return u }
+
+	kept, dropped, err := pickPair([]uuid.UUID{droppedID, keptID}, id, keptID, droppedID, "wallet")
+	require.NoError(t, err)
+	assert.Equal(t, keptID, kept)
+	assert.Equal(t, droppedID, dropped)
+
+	_, _, err = pickPair([]uuid.UUID{keptID}, id, keptID, droppedID, "wallet")
+	assert.True(t, errors.IsErrorType(err, errors.ErrorTypeNotFound))
+	assert.Contains(t, err.Error(), droppedID.String())
+}
@@ -0,0 +1,37 @@
+package routes
+
+import (
+	contactRepository "github.com/Abdelrahman-habib/expense-tracker/internal/contacts/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/events"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/merges/handlers"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/merges/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/merges/service"
+	walletRepository "github.com/Abdelrahman-habib/expense-tracker/internal/wallets/repository"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// Router encapsulates the merge routes setup
+type Router struct {
+	handler *handlers.MergeHandler
+}
+
+// New creates a new merge router, the merges run in transactions of dbService and are
+// published to bus
+func New(dbService db.Service, bus *events.Bus, logger *zap.Logger) *Router {
+	queries := dbService.Queries()
+	repo := repository.NewMergeRepository(dbService)
+	mergeService := service.NewMergeService(repo, contactRepository.New(queries), walletRepository.NewWalletRepository(queries), bus, logger)
+	handler := handlers.NewMergeHandler(mergeService, logger)
+
+	return &Router{
+		handler: handler,
+	}
+}
+
+// RegisterRoutes registers the merge routes under the contacts and wallets they merge
+func (r *Router) RegisterRoutes(router chi.Router) {
+	router.Post("/contacts/{keepId}/merge/{dropId}", r.handler.MergeContacts)
+	router.Post("/wallets/{keepId}/merge/{dropId}", r.handler.MergeWallets)
+}
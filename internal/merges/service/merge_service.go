@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	contactTypes "github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/logging"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/events"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/merges/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/merges/types"
+	walletTypes "github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type MergeService interface {
+	MergeContacts(ctx context.Context, userID, keptID, droppedID uuid.UUID) (types.ContactMergeResult, error)
+	MergeWallets(ctx context.Context, userID, keptID, droppedID uuid.UUID) (types.WalletMergeResult, error)
+}
+
+// ContactReader reads the contact a merge kept, the contact repository is one
+type ContactReader interface {
+	GetContact(ctx context.Context, contactID, userID uuid.UUID) (contactTypes.Contact, error)
+}
+
+// WalletReader reads the wallet a merge kept, the wallet repository is one
+type WalletReader interface {
+	GetWallet(ctx context.Context, walletID, userID uuid.UUID) (walletTypes.Wallet, error)
+}
+
+type mergeService struct {
+	repo     repository.MergeRepository
+	contacts ContactReader
+	wallets  WalletReader
+	events   *events.Bus
+	logger   *zap.Logger
+}
+
+// NewMergeService creates the merge service, the merges are published to bus as an update
+// of the kept entity and a deletion of the dropped one, nil publishes nothing
+func NewMergeService(repo repository.MergeRepository, contacts ContactReader, wallets WalletReader, bus *events.Bus, logger *zap.Logger) MergeService {
+	return &mergeService{
+		repo:     repo,
+		contacts: contacts,
+		wallets:  wallets,
+		events:   bus,
+		logger:   logger.With(zap.String("component", "merge_service")),
+	}
+}
+
+// operation starts the log of a merge service method on the kept entity
+func (s *mergeService) operation(name, entity string, userID, keptID, droppedID uuid.UUID) *logging.Operation {
+	logger := logging.WithEntity(logging.WithUser(s.logger, userID), entity, keptID)
+	return logging.Start(logger, "MergeService."+name, zap.Stringer("dropped_id", droppedID))
+}
+
+// publish tells the user's clients the kept entity changed and the dropped one is gone
+func (s *mergeService) publish(userID uuid.UUID, entityType string, keptID, droppedID uuid.UUID, updatedAt time.Time) {
+	s.events.Publish(userID, events.Event{Type: entityType, EntityID: keptID, Action: events.ActionUpdated, UpdatedAt: coreTypes.NewTimestamp(updatedAt)})
+	s.events.Publish(userID, events.Event{Type: entityType, EntityID: droppedID, Action: events.ActionDeleted})
+}
+
+// MergeContacts merges the dropped contact into the kept one, both have to be the user's
+// live contacts
+func (s *mergeService) MergeContacts(ctx context.Context, userID, keptID, droppedID uuid.UUID) (_ types.ContactMergeResult, err error) {
+	defer s.operation("MergeContacts", types.EntityContact, userID, keptID, droppedID).End(&err)
+
+	if keptID == droppedID {
+		return types.ContactMergeResult{}, errors.NewValidationError("dropId: a contact can't be merged into itself")
+	}
+
+	result, err := s.repo.MergeContacts(ctx, userID, keptID, droppedID)
+	if err != nil {
+		return types.ContactMergeResult{}, err
+	}
+	if result.Contact, err = s.contacts.GetContact(ctx, keptID, userID); err != nil {
+		return types.ContactMergeResult{}, err
+	}
+	s.publish(userID, events.TypeContact, keptID, droppedID, result.Contact.UpdatedAt.Time)
+	return result, nil
+}
+
+// MergeWallets merges the dropped wallet into the kept one, both have to be the user's
+// live wallets of the same currency
+func (s *mergeService) MergeWallets(ctx context.Context, userID, keptID, droppedID uuid.UUID) (_ types.WalletMergeResult, err error) {
+	defer s.operation("MergeWallets", types.EntityWallet, userID, keptID, droppedID).End(&err)
+
+	if keptID == droppedID {
+		return types.WalletMergeResult{}, errors.NewValidationError("dropId: a wallet can't be merged into itself")
+	}
+
+	result, err := s.repo.MergeWallets(ctx, userID, keptID, droppedID)
+	if err != nil {
+		return types.WalletMergeResult{}, err
+	}
+	if result.Wallet, err = s.wallets.GetWallet(ctx, keptID, userID); err != nil {
+		return types.WalletMergeResult{}, err
+	}
+	s.publish(userID, events.TypeWallet, keptID, droppedID, result.Wallet.UpdatedAt.Time)
+	return result, nil
+}
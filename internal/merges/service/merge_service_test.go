@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	contactTypes "github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	coreErrors "github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/events"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/merges/types"
+	walletTypes "github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type mockMergeRepository struct {
+	mock.Mock
+}
+
+func (m *mockMergeRepository) MergeContacts(ctx context.Context, userID, keptID, droppedID uuid.UUID) (types.ContactMergeResult, error) {
+	args := m.Called(ctx, userID, keptID, droppedID)
+	return args.Get(0).(types.ContactMergeResult), args.Error(1)
+}
+
+func (m *mockMergeRepository) MergeWallets(ctx context.Context, userID, keptID, droppedID uuid.UUID) (types.WalletMergeResult, error) {
+	args := m.Called(ctx, userID, keptID, droppedID)
+	return args.Get(0).(types.WalletMergeResult), args.Error(1)
+}
+
+type mockReader struct {
+	mock.Mock
+}
+
+func (m *mockReader) GetContact(ctx context.Context, contactID, userID uuid.UUID) (contactTypes.Contact, error) {
+	args := m.Called(ctx, contactID, userID)
+	return args.Get(0).(contactTypes.Contact), args.Error(1)
+}
+
+func (m *mockReader) GetWallet(ctx context.Context, walletID, userID uuid.UUID) (walletTypes.Wallet, error) {
+	args := m.Called(ctx, walletID, userID)
+	return args.Get(0).(walletTypes.Wallet), args.Error(1)
+}
+
+func setupTest() (*mockMergeRepository, *mockReader, *events.Bus, MergeService) {
+	repo := new(mockMergeRepository)
+	reader := new(mockReader)
+	bus := events.NewBus(8, 8)
+	return repo, reader, bus, NewMergeService(repo, reader, reader, bus, zap.NewNop())
+}
+
+// received drains the events published to the subscription so far
+func received(sub *events.Subscription) []events.Event {
+	var published []events.Event
+	for {
+		select {
+		case event := <-sub.Events():
+			published = append(published, event)
+		default:
+			return published
+		}
+	}
+}
+
+func TestMergeService_MergeContacts(t *testing.T) {
+	ctx := context.Background()
+	userID, keptID, droppedID := uuid.New(), uuid.New(), uuid.New()
+	updatedAt := time.Date(2025, 3, 7, 9, 0, 0, 0, time.UTC)
+
+	t.Run("merges, re-reads the kept contact and publishes both sides", func(t *testing.T) {
+		repo, reader, bus, service := setupTest()
+		sub, _ := bus.Subscribe(userID, "")
+		defer sub.Close()
+
+		repo.On("MergeContacts", ctx, userID, keptID, droppedID).Return(types.ContactMergeResult{
+			DroppedContactID:   droppedID,
+			FilledFields:       []string{"email"},
+			MovedRelationships: 2,
+		}, nil)
+		contact := contactTypes.Contact{ContactID: keptID, Name: "Kept", UpdatedAt: coreTypes.NewTimestamp(updatedAt)}
+		reader.On("GetContact", ctx, keptID, userID).Return(contact, nil)
+
+		result, err := service.MergeContacts(ctx, userID, keptID, droppedID)
+		require.NoError(t, err)
+		assert.Equal(t, contact, result.Contact)
+		assert.Equal(t, []string{"email"}, result.FilledFields)
+		assert.Equal(t, int64(2), result.MovedRelationships)
+
+		published := received(sub)
+		require.Len(t, published, 2)
+		assert.Equal(t, events.TypeContact, published[0].Type)
+		assert.Equal(t, events.ActionUpdated, published[0].Action)
+		assert.Equal(t, keptID, published[0].EntityID)
+		assert.True(t, published[0].UpdatedAt.Equal(updatedAt))
+		assert.Equal(t, events.TypeContact, published[1].Type)
+		assert.Equal(t, events.ActionDeleted, published[1].Action)
+		assert.Equal(t, droppedID, published[1].EntityID)
+	})
+
+	t.Run("a contact can't be merged into itself", func(t *testing.T) {
+		repo, _, _, service := setupTest()
+
+		_, err := service.MergeContacts(ctx, userID, keptID, keptID)
+		assert.True(t, coreErrors.IsErrorType(err, coreErrors.ErrorTypeValidation))
+		repo.AssertNotCalled(t, "MergeContacts", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("repository errors are returned and nothing is published", func(t *testing.T) {
+		repo, reader, bus, service := setupTest()
+		sub, _ := bus.Subscribe(userID, "")
+		defer sub.Close()
+
+		repo.On("MergeContacts", ctx, userID, keptID, droppedID).Return(types.ContactMergeResult{}, coreErrors.NewNotFoundError("contact not found"))
+
+		_, err := service.MergeContacts(ctx, userID, keptID, droppedID)
+		assert.True(t, coreErrors.IsErrorType(err, coreErrors.ErrorTypeNotFound))
+		reader.AssertNotCalled(t, "GetContact", mock.Anything, mock.Anything, mock.Anything)
+		assert.Empty(t, received(sub))
+	})
+}
+
+func TestMergeService_MergeWallets(t *testing.T) {
+	ctx := context.Background()
+	userID, keptID, droppedID := uuid.New(), uuid.New(), uuid.New()
+
+	t.Run("merges, re-reads the kept wallet and publishes both sides", func(t *testing.T) {
+		repo, reader, bus, service := setupTest()
+		sub, _ := bus.Subscribe(userID, "")
+		defer sub.Close()
+
+		repo.On("MergeWallets", ctx, userID, keptID, droppedID).Return(types.WalletMergeResult{
+			DroppedWalletID:    droppedID,
+			FilledFields:       []string{},
+			MovedLedgerEntries: 3,
+		}, nil)
+		wallet := walletTypes.Wallet{WalletID: keptID, Name: "Kept", UpdatedAt: coreTypes.NewTimestamp(time.Now())}
+		reader.On("GetWallet", ctx, keptID, userID).Return(wallet, nil)
+
+		result, err := service.MergeWallets(ctx, userID, keptID, droppedID)
+		require.NoError(t, err)
+		assert.Equal(t, wallet, result.Wallet)
+		assert.Equal(t, int64(3), result.MovedLedgerEntries)
+
+		published := received(sub)
+		require.Len(t, published, 2)
+		assert.Equal(t, events.ActionUpdated, published[0].Action)
+		assert.Equal(t, keptID, published[0].EntityID)
+		assert.Equal(t, events.ActionDeleted, published[1].Action)
+		assert.Equal(t, droppedID, published[1].EntityID)
+	})
+
+	t.Run("a wallet can't be merged into itself", func(t *testing.T) {
+		repo, _, _, service := setupTest()
+
+		_, err := service.MergeWallets(ctx, userID, keptID, keptID)
+		assert.True(t, coreErrors.IsErrorType(err, coreErrors.ErrorTypeValidation))
+		repo.AssertNotCalled(t, "MergeWallets", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("a currency mismatch is returned as is", func(t *testing.T) {
+		repo, _, _, service := setupTest()
+		mismatch := coreErrors.NewValidationError("dropId: wallet holds EUR and can't be merged into a USD wallet")
+		repo.On("MergeWallets", ctx, userID, keptID, droppedID).Return(types.WalletMergeResult{}, mismatch)
+
+		_, err := service.MergeWallets(ctx, userID, keptID, droppedID)
+		assert.Equal(t, mismatch, err)
+	})
+}
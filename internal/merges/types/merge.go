@@ -0,0 +1,37 @@
+package types
+
+import (
+	contactTypes "github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	walletTypes "github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+	"github.com/google/uuid"
+)
+
+const (
+	EntityContact = "contact"
+	EntityWallet  = "wallet"
+)
+
+// ContactMergeResult is the contact a merge kept, as the merge left it
+// @Description The kept contact after the merge, with what the dropped one brought into it
+type ContactMergeResult struct {
+	Contact          contactTypes.Contact `json:"contact"`
+	DroppedContactID uuid.UUID            `json:"droppedContactId" example:"123e4567-e89b-12d3-a456-426614174001" format:"uuid"`
+	// FilledFields are the empty fields of the kept contact filled from the dropped one
+	FilledFields []string `json:"filledFields" example:"phone,company"`
+	// MovedRelationships is how many relationships of the dropped contact now belong to the kept one
+	MovedRelationships int64 `json:"movedRelationships" example:"2"`
+	// DroppedRelationships is how many were deleted instead, those between the two contacts
+	// and those the kept contact already had
+	DroppedRelationships int64 `json:"droppedRelationships" example:"1"`
+}
+
+// WalletMergeResult is the wallet a merge kept, as the merge left it
+// @Description The kept wallet after the merge, with what the dropped one brought into it
+type WalletMergeResult struct {
+	Wallet          walletTypes.Wallet `json:"wallet"`
+	DroppedWalletID uuid.UUID          `json:"droppedWalletId" example:"123e4567-e89b-12d3-a456-426614174001" format:"uuid"`
+	// FilledFields are the empty fields of the kept wallet filled from the dropped one
+	FilledFields []string `json:"filledFields" example:"projectId"`
+	// MovedLedgerEntries is how many ledger entries of the dropped wallet now belong to the kept one
+	MovedLedgerEntries int64 `json:"movedLedgerEntries" example:"12"`
+}
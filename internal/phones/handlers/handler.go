@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
+	"go.uber.org/zap"
+)
+
+type PhoneHandler struct {
+	handlers.BaseHandler
+}
+
+func NewPhoneHandler(logger *zap.Logger) *PhoneHandler {
+	return &PhoneHandler{
+		BaseHandler: handlers.NewBaseHandler(logger),
+	}
+}
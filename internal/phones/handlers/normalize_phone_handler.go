@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	stdErrors "errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/phone"
+)
+
+// NormalizePhone godoc
+// @Summary Preview how a phone number is stored
+// @Description Normalizes a phone number to E.164 without storing anything, reporting the region it belongs to and its type as far as its prefix tells. A number starting with + or 00 carries its country calling code, any other needs the region it is dialed in. Only the regions listed in the error for an unsupported region are known.
+// @Tags Utilities
+// @Produce json
+// @Security BearerAuth
+// @Param number query string true "phone number, formatted with spaces, dashes, dots, slashes or parentheses or not" example(+44 20 7946 0958)
+// @Param region query string false "ISO 3166 alpha-2 code of the region a number without a calling code is dialed in" example(GB)
+// @Success 200 {object} payloads.Response{data=phone.Number}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 429 {object} errors.ErrorResponse
+// @Router /util/normalize-phone [get]
+// @ID NormalizePhone
+func (h *PhoneHandler) NormalizePhone(w http.ResponseWriter, r *http.Request) {
+	if !h.CheckQueryParams(w, r, "number", "region") {
+		return
+	}
+
+	query := r.URL.Query()
+	number := query.Get("number")
+	if strings.TrimSpace(number) == "" {
+		h.RespondError(w, r, errors.ErrInvalidRequest(fmt.Errorf("number: cannot be blank")))
+		return
+	}
+
+	normalized, err := phone.Normalize(number, query.Get("region"))
+	if stdErrors.Is(err, phone.ErrUnsupportedRegion) {
+		h.RespondError(w, r, errors.ErrInvalidRequest(fmt.Errorf("region: %w", err)))
+		return
+	}
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(fmt.Errorf("number: %w", err)))
+		return
+	}
+
+	h.Respond(w, r, payloads.OK(normalized))
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestPhoneHandler_NormalizePhone(t *testing.T) {
+	handler := NewPhoneHandler(zap.NewNop())
+
+	tests := []struct {
+		name           string
+		query          url.Values
+		expectedStatus int
+		expectedData   map[string]interface{}
+		expectedError  string
+	}{
+		{
+			name:           "international number",
+			query:          url.Values{"number": {"+44 20 7946 0958"}},
+			expectedStatus: http.StatusOK,
+			expectedData:   map[string]interface{}{"e164": "+442079460958", "region": "GB", "type": "fixed_line"},
+		},
+		{
+			name:           "national number in its region",
+			query:          url.Values{"number": {"010 1234 5678"}, "region": {"eg"}},
+			expectedStatus: http.StatusOK,
+			expectedData:   map[string]interface{}{"e164": "+201012345678", "region": "EG", "type": "mobile"},
+		},
+		{
+			name:           "missing number",
+			query:          url.Values{"region": {"US"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "number: cannot be blank",
+		},
+		{
+			name:           "national number without a region",
+			query:          url.Values{"number": {"(415) 555-2671"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "number: invalid phone number: a number without a country calling code needs a region",
+		},
+		{
+			name:           "invalid number",
+			query:          url.Values{"number": {"+44 20 CALL NOW"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  `number: invalid phone number: unexpected character 'C'`,
+		},
+		{
+			name:           "unsupported region",
+			query:          url.Values{"number": {"495 123 4567"}, "region": {"RU"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "region: unsupported region RU (supported: AE, CA, DE, EG, FR, GB, SA, US)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/util/normalize-phone?"+tt.query.Encode(), nil)
+			w := httptest.NewRecorder()
+			handler.NormalizePhone(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var response map[string]interface{}
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+			if tt.expectedError != "" {
+				assert.Equal(t, tt.expectedError, response["error"])
+				return
+			}
+			assert.Equal(t, tt.expectedData, response["data"])
+		})
+	}
+}
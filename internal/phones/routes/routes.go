@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"github.com/Abdelrahman-habib/expense-tracker/internal/phones/handlers"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// Router encapsulates the phone utility routes setup
+type Router struct {
+	handler *handlers.PhoneHandler
+}
+
+// New creates a new router for the phone utilities, they read no user data
+func New(logger *zap.Logger) *Router {
+	return &Router{
+		handler: handlers.NewPhoneHandler(logger),
+	}
+}
+
+// RegisterRoutes registers all phone utility routes
+func (r *Router) RegisterRoutes(router chi.Router) {
+	router.Get("/util/normalize-phone", r.handler.NormalizePhone)
+}
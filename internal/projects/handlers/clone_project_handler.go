@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// CloneProject godoc
+// @Summary Clone a Project
+// @Description Creates a copy of a Project named "<name> (copy)" with its fields and tags. With include_wallets=true its wallets are copied too, with zeroed balances and the same name suffix. Everything is created in one transaction.
+// @Tags Projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID" format(uuid)
+// @Param include_wallets query bool false "copy the project's wallets"
+// @Success 201 {object} payloads.Response{data=types.Project}
+// @Header 201 {string} ETag "version of the project, for If-Match"
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Failure 409 {object} errors.ErrorResponse "A project or wallet already uses the copy's name"
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /projects/{id}/clone [post]
+// @ID CloneProject
+func (h *ProjectHandler) CloneProject(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	projectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	project, err := h.service.CloneProject(r.Context(), userID, projectID, r.URL.Query().Get("include_wallets") == "true")
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	handlers.SetETag(w, project.UpdatedAt.Time)
+	h.Respond(w, r, payloads.Created(project))
+}
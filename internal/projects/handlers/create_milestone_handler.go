@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/projects/types"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// CreateMilestone godoc
+// @Summary Add a project milestone
+// @Description Adds a milestone after the project's existing ones, a project holds at most 100 milestones and due dates must fall within its start and end dates
+// @Tags Projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "project ID" format(uuid)
+// @Param request body types.MilestoneCreatePayload true "milestone creation request"
+// @Success 201 {object} payloads.Response{data=types.Milestone}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /projects/{id}/milestones [post]
+// @ID CreateMilestone
+func (h *ProjectHandler) CreateMilestone(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	projectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	var req types.MilestoneCreatePayload
+	if !h.Bind(w, r, &req) {
+		return
+	}
+
+	milestone, err := h.service.CreateMilestone(r.Context(), userID, projectID, req)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.Created(milestone))
+}
@@ -4,21 +4,25 @@ import (
 	"net/http"
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/projects/types"
 	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
-	"github.com/go-chi/render"
 )
 
 // CreateProject godoc
 // @Summary Create a new project
-// @Description Creates a new project for the authenticated user
+// @Description Creates a new project for the authenticated user. With if_not_exists=true a project of the user with the same name, compared case-insensitively, is returned with 200 instead of creating another.
 // @Tags Projects
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param request body types.ProjectCreatePayload true "project creation request"
+// @Param if_not_exists query bool false "return the existing project with the same name instead of creating one"
+// @Success 200 {object} payloads.Response{data=types.Project} "existing project with the same name"
+// @Header 200 {string} ETag "version of the project, for If-Match"
 // @Success 201 {object} payloads.Response{data=types.Project}
+// @Header 201 {string} ETag "version of the project, for If-Match"
 // @Failure 400 {object} errors.ErrorResponse
 // @Failure 401 {object} errors.ErrorResponse
 // @Failure 404 {object} errors.ErrorResponse
@@ -33,9 +37,27 @@ func (h *ProjectHandler) CreateProject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.CheckQueryParams(w, r, "if_not_exists") {
+		return
+	}
+
 	var req types.ProjectCreatePayload
-	if err := render.Bind(r, &req); err != nil {
-		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+	if !h.Bind(w, r, &req) {
+		return
+	}
+
+	if r.URL.Query().Get("if_not_exists") == "true" {
+		project, created, err := h.service.CreateProjectIfNotExists(r.Context(), userID, req)
+		if err != nil {
+			h.HandleServiceError(w, r, err)
+			return
+		}
+		handlers.SetETag(w, project.UpdatedAt.Time)
+		if !created {
+			h.Respond(w, r, payloads.OK(project))
+			return
+		}
+		h.Respond(w, r, payloads.Created(project))
 		return
 	}
 
@@ -45,5 +67,6 @@ func (h *ProjectHandler) CreateProject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	handlers.SetETag(w, project.UpdatedAt.Time)
 	h.Respond(w, r, payloads.Created(project))
 }
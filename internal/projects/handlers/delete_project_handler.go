@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/projects/types"
 	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -12,16 +14,22 @@ import (
 
 // DeleteProject godoc
 // @Summary Delete a project
-// @Description Deletes a project by ID
+// @Description Moves a project to the trash, it can be restored until the retention period passes.
+// @Description A project with sub-projects is only deleted with cascade=true, trashing them too, or detach=true, moving them to the top level.
 // @Tags Projects
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "project ID" format(uuid)
+// @Param cascade query bool false "trash the sub-projects too"
+// @Param detach query bool false "move the sub-projects to the top level"
+// @Param If-Match header string false "ETag of the project as last seen, the delete fails with a 412 if it changed since"
 // @Success 200 {object} payloads.Response
 // @Failure 400 {object} errors.ErrorResponse
 // @Failure 401 {object} errors.ErrorResponse
 // @Failure 404 {object} errors.ErrorResponse
+// @Failure 412 {object} errors.ErrorResponse
+// @Failure 409 {object} errors.ErrorResponse
 // @Failure 429 {object} errors.ErrorResponse
 // @Failure 500 {object} errors.ErrorResponse
 // @Router /projects/{id} [delete]
@@ -39,7 +47,21 @@ func (h *ProjectHandler) DeleteProject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.service.DeleteProject(r.Context(), userID, projectID)
+	children, err := types.ParseChildrenMode(r.URL.Query())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	version, ok := h.CheckIfMatch(w, r, func() (time.Time, error) {
+		project, err := h.service.GetProject(r.Context(), userID, projectID, types.ProjectExpand{})
+		return project.UpdatedAt.Time, err
+	})
+	if !ok {
+		return
+	}
+
+	err = h.service.DeleteProject(r.Context(), userID, projectID, children, version)
 	if err != nil {
 		h.HandleServiceError(w, r, err)
 		return
@@ -4,7 +4,9 @@ import (
 	"net/http"
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/projects/types"
 	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -12,13 +14,15 @@ import (
 
 // GetProject godoc
 // @Summary Get a project
-// @Description Retrieves a project by ID
+// @Description Retrieves a project by ID, with expand=parent along with the name and status of its parent
 // @Tags Projects
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "project ID" format(uuid)
+// @Param expand query string false "comma separated related resources to include" Enums(parent)
 // @Success 200 {object} payloads.Response{data=types.Project}
+// @Header 200 {string} ETag "version of the project, for If-Match"
 // @Failure 400 {object} errors.ErrorResponse
 // @Failure 401 {object} errors.ErrorResponse
 // @Failure 404 {object} errors.ErrorResponse
@@ -39,11 +43,18 @@ func (h *ProjectHandler) GetProject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	project, err := h.service.GetProject(r.Context(), userID, projectID)
+	expand, err := types.ParseProjectExpand(r.URL.Query())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	project, err := h.service.GetProject(r.Context(), userID, projectID, expand)
 	if err != nil {
 		h.HandleServiceError(w, r, err)
 		return
 	}
 
+	handlers.SetETag(w, project.UpdatedAt.Time)
 	h.Respond(w, r, payloads.OK(project))
 }
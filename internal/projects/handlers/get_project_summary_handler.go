@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/projects/types"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// GetProjectSummary godoc
+// @Summary Summarize a project
+// @Description Totals the budget and the wallet balances per currency of a project, with rollup=true those of its sub-projects at any depth too. With convert_to the balances are also converted and added up in that currency, along with the rate each currency was converted at; when a currency has no exchange rate, or conversion isn't configured, the summary comes without the converted total and with a warning.
+// @Tags Projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "project ID" format(uuid)
+// @Param rollup query bool false "include the sub-projects"
+// @Param convert_to query string false "currency to convert the balances to and add them up in" example(USD)
+// @Success 200 {object} payloads.Response{data=types.ProjectSummary}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /projects/{id}/summary [get]
+// @ID GetProjectSummary
+func (h *ProjectHandler) GetProjectSummary(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	projectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	if !h.CheckQueryParams(w, r, types.ProjectSummaryQueryParams...) {
+		return
+	}
+
+	params, err := types.ParseProjectSummaryParams(r.URL.Query())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	summary, err := h.service.GetProjectSummary(r.Context(), userID, projectID, params)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.OK(summary))
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/projects/service"
 	"go.uber.org/zap"
 )
@@ -9,11 +10,13 @@ import (
 type ProjectHandler struct {
 	handlers.BaseHandler
 	service service.ProjectService
+	limits  coreTypes.LimitPolicy
 }
 
-func NewProjectHandler(service service.ProjectService, logger *zap.Logger) *ProjectHandler {
+func NewProjectHandler(service service.ProjectService, limits coreTypes.LimitPolicy, logger *zap.Logger) *ProjectHandler {
 	return &ProjectHandler{
 		BaseHandler: handlers.NewBaseHandler(logger),
 		service:     service,
+		limits:      limits,
 	}
 }
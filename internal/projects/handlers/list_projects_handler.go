@@ -5,12 +5,15 @@ import (
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/google/uuid"
 )
 
 // ListProjects godoc
 // @Summary List projects
-// @Description Returns all project for a user (since projects are limited to 10 we usually won't want to paginate the date)
+// @Description Returns all project for a user (since projects are limited to 10 we usually won't want to paginate the date). With ids it returns the projects with those IDs instead, in the order asked for, and names the IDs not found in meta.missing.
+// @Param ids query string false "Comma separated IDs of the projects to fetch, at most 100"
 // @Tags Projects
 // @Accept json
 // @Produce json
@@ -29,6 +32,15 @@ func (h *ProjectHandler) ListProjects(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if r.URL.Query().Has(types.IDsQueryParam) {
+		h.getProjectsByIDs(w, r, userID)
+		return
+	}
+
+	if !h.CheckQueryParams(w, r) {
+		return
+	}
+
 	projects, err := h.service.ListProjects(r.Context(), userID)
 	if err != nil {
 		h.HandleServiceError(w, r, err)
@@ -37,3 +49,22 @@ func (h *ProjectHandler) ListProjects(w http.ResponseWriter, r *http.Request) {
 
 	h.Respond(w, r, payloads.OK(projects))
 }
+
+// getProjectsByIDs responds with the user's projects of the ids query parameter
+func (h *ProjectHandler) getProjectsByIDs(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+	if !h.CheckQueryParams(w, r, types.IDsQueryParam) {
+		return
+	}
+	ids, ok := h.ParseIDs(w, r)
+	if !ok {
+		return
+	}
+
+	projects, missing, err := h.service.GetProjectsByIDs(r.Context(), userID, ids)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.Batch(projects, len(projects), missing))
+}
@@ -2,25 +2,35 @@ package handlers
 
 import (
 	"net/http"
+	"slices"
 	"time"
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	projectTypes "github.com/Abdelrahman-habib/expense-tracker/internal/projects/types"
 	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
 	"github.com/google/uuid"
 )
 
 // ListProjectsPaginated godoc
 // @Summary List projects with pagination
-// @Description Returns a paginated list of projects
+// @Description Returns a paginated list of projects, the pinned ones first. Drafts are left out unless include_drafts=true.
+// @Description With only_ids=true the data is only the IDs of the page and the next_token, {"ids":[...],"next_token":...}, read without the rest of the projects.
+// @Description With stream=true or Accept: application/x-ndjson the page is streamed as newline-delimited JSON instead, one project per line followed by a {"meta":{"count","next_token"}} line, or an {"error":...} line when the stream fails midway.
 // @Tags Projects
 // @Accept json
 // @Produce json
+// @Produce application/x-ndjson
 // @Security BearerAuth
 // @Param limit query integer false "Number of projects to return" minimum(1) maximum(100) default(10)
+// @Param order query string false "Sort order by creation time" Enums(asc, desc) default(desc)
 // @Param next_token query string false "Token for the next page"
+// @Param include_drafts query bool false "list the draft projects too"
+// @Param stream query boolean false "Stream the page as newline-delimited JSON"
+// @Param only_ids query boolean false "Return only the IDs of the page, not with stream"
 // @Success 200 {object} payloads.Response{data=[]types.Project}
+// @Success 200 {object} payloads.Response{data=payloads.IDPage} "only_ids=true"
 // @Failure 400 {object} errors.ErrorResponse
 // @Failure 401  {object} errors.ErrorResponse
 // @Failure 429  {object} errors.ErrorResponse
@@ -34,25 +44,53 @@ func (h *ProjectHandler) ListProjectsPaginated(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	if !h.CheckQueryParams(w, r, append(slices.Clone(projectTypes.ListQueryParams), types.StreamParam, types.OnlyIDsParam)...) {
+		return
+	}
+
 	// Parse and validate pagination parameters
-	params, err := types.ParsePaginationParams(r.URL.Query())
-	if err != nil {
-		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+	params, ok := h.ParsePagination(w, r, h.limits, userID, projectTypes.ListQueryParams...)
+	if !ok {
+		return
+	}
+	stream, ok := h.ParseStream(w, r)
+	if !ok {
+		return
+	}
+	onlyIDs, ok := h.ParseOnlyIDs(w, r, stream)
+	if !ok {
 		return
 	}
 
-	// Set cursor values based on parsed parameters
+	// Set cursor values based on parsed parameters, the first page starts with the pinned projects
 	var cursor time.Time
 	var cursorID uuid.UUID
+	pinned := true
 	if params.Cursor != nil {
 		cursor = params.Cursor.Timestamp
 		cursorID = params.Cursor.ID
+		pinned = params.Cursor.Pinned
 	} else {
-		cursor = time.Now()
-		cursorID = uuid.Nil
+		cursor, cursorID = types.StartPinnedCursor()
 	}
 
-	projects, err := h.service.ListProjectsPaginated(r.Context(), userID, cursor, cursorID, params.Limit)
+	includeDrafts := r.URL.Query().Get("include_drafts") == "true"
+	if stream {
+		h.streamProjects(w, r, userID, params, cursor, cursorID, pinned, includeDrafts)
+		return
+	}
+
+	if onlyIDs {
+		keys, err := h.service.ListProjectIDsPaginated(r.Context(), userID, cursor, cursorID, pinned, includeDrafts, params.Limit, params.Order)
+		if err != nil {
+			h.HandleServiceError(w, r, err)
+			return
+		}
+		h.RespondIDs(w, r, params, keys, userID)
+		return
+	}
+
+	projects, err := h.service.ListProjectsPaginated(r.Context(), userID, cursor, cursorID, pinned, includeDrafts, params.Limit, params.Order)
 	if err != nil {
 		h.HandleServiceError(w, r, err)
 		return
@@ -60,8 +98,7 @@ func (h *ProjectHandler) ListProjectsPaginated(w http.ResponseWriter, r *http.Re
 
 	var nextToken string
 	if len(projects) > 0 && len(projects) == int(params.Limit) {
-		lastProject := projects[len(projects)-1]
-		nextToken = types.EncodeCursor(lastProject.CreatedAt, lastProject.ProjectID)
+		nextToken = projectNextToken(params, projects[len(projects)-1], userID)
 	}
 
 	h.Respond(w, r, payloads.Paginated(
@@ -70,3 +107,29 @@ func (h *ProjectHandler) ListProjectsPaginated(w http.ResponseWriter, r *http.Re
 		params.Limit,
 	))
 }
+
+// streamProjects streams the page of projects as NDJSON
+func (h *ProjectHandler) streamProjects(w http.ResponseWriter, r *http.Request, userID uuid.UUID, params types.PaginationParams, cursor time.Time, cursorID uuid.UUID, pinned, includeDrafts bool) {
+	h.StreamNDJSON(w, r, func(write func(item any) error) (string, error) {
+		var last projectTypes.Project
+		count := 0
+		err := h.service.StreamProjectsPaginated(r.Context(), userID, cursor, cursorID, pinned, includeDrafts, params.Limit, params.Order, func(project projectTypes.Project) error {
+			last = project
+			count++
+			return write(project)
+		})
+		if err != nil || count < int(params.Limit) {
+			return "", err
+		}
+		return projectNextToken(params, last, userID), nil
+	})
+}
+
+// projectNextToken returns the token of the page after the one ending on last, a page
+// ending on a pinned project continues among the pinned ones
+func projectNextToken(params types.PaginationParams, last projectTypes.Project, userID uuid.UUID) string {
+	if last.PinnedAt != nil {
+		return params.NextPinnedToken(last.PinnedAt.Time, last.ProjectID, userID)
+	}
+	return params.NextToken(last.CreatedAt.Time, last.ProjectID, userID)
+}
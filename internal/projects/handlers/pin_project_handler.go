@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// PinProject godoc
+// @Summary Pin a Project
+// @Description Puts the Project at the top of the paginated list, at most 10 projects can be pinned
+// @Tags Projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID" format(uuid)
+// @Success 200 {object} payloads.Response{data=types.Project}
+// @Header 200 {string} ETag "version of the project, for If-Match"
+// @Failure 400 {object} errors.ErrorResponse "Invalid ID or too many pinned projects"
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /projects/{id}/pin [post]
+// @ID PinProject
+func (h *ProjectHandler) PinProject(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	projectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	project, err := h.service.PinProject(r.Context(), userID, projectID)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	handlers.SetETag(w, project.UpdatedAt.Time)
+	h.Respond(w, r, payloads.Updated(project))
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -11,6 +12,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/deletion"
+	coreErrors "github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	coreRepository "github.com/Abdelrahman-habib/expense-tracker/internal/core/repository"
 	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/projects/types"
@@ -19,6 +23,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
@@ -32,48 +37,184 @@ func (m *mockProjectService) ListProjects(ctx context.Context, userID uuid.UUID)
 	return args.Get(0).([]types.Project), args.Error(1)
 }
 
-func (m *mockProjectService) GetProject(ctx context.Context, userID, projectID uuid.UUID) (types.Project, error) {
-	args := m.Called(ctx, userID, projectID)
+func (m *mockProjectService) GetProjectsByIDs(ctx context.Context, userID uuid.UUID, projectIDs []uuid.UUID) ([]types.Project, []uuid.UUID, error) {
+	args := m.Called(ctx, userID, projectIDs)
+	return args.Get(0).([]types.Project), args.Get(1).([]uuid.UUID), args.Error(2)
+}
+
+func (m *mockProjectService) GetProject(ctx context.Context, userID, projectID uuid.UUID, expand types.ProjectExpand) (types.Project, error) {
+	args := m.Called(ctx, userID, projectID, expand)
 	return args.Get(0).(types.Project), args.Error(1)
 }
 
+func (m *mockProjectService) ListChildProjects(ctx context.Context, userID, projectID uuid.UUID) ([]types.Project, error) {
+	args := m.Called(ctx, userID, projectID)
+	return args.Get(0).([]types.Project), args.Error(1)
+}
+
+func (m *mockProjectService) GetProjectSummary(ctx context.Context, userID, projectID uuid.UUID, params types.ProjectSummaryParams) (types.ProjectSummary, error) {
+	args := m.Called(ctx, userID, projectID, params)
+	return args.Get(0).(types.ProjectSummary), args.Error(1)
+}
+
 func (m *mockProjectService) CreateProject(ctx context.Context, userID uuid.UUID, projectData types.ProjectCreatePayload) (types.Project, error) {
 	args := m.Called(ctx, userID, projectData)
 	return args.Get(0).(types.Project), args.Error(1)
 }
 
-func (m *mockProjectService) UpdateProject(ctx context.Context, userID uuid.UUID, projectData types.ProjectUpdatePayload) (types.Project, error) {
+func (m *mockProjectService) CreateProjectIfNotExists(ctx context.Context, userID uuid.UUID, projectData types.ProjectCreatePayload) (types.Project, bool, error) {
+	args := m.Called(ctx, userID, projectData)
+	return args.Get(0).(types.Project), args.Bool(1), args.Error(2)
+}
+
+// UpdateProject reads the project with GetProject and merges the update onto it like the
+// service, the update is then matched on the merged payload
+func (m *mockProjectService) UpdateProject(ctx context.Context, userID, projectID uuid.UUID, merge func(types.Project) (types.ProjectUpdatePayload, error)) (types.Project, error) {
+	existing, err := m.GetProject(ctx, userID, projectID, types.ProjectExpand{})
+	if err != nil {
+		return types.Project{}, err
+	}
+	projectData, err := merge(existing)
+	if err != nil {
+		return types.Project{}, err
+	}
 	args := m.Called(ctx, userID, projectData)
 	return args.Get(0).(types.Project), args.Error(1)
 }
 
-func (m *mockProjectService) DeleteProject(ctx context.Context, userID, projectID uuid.UUID) error {
-	args := m.Called(ctx, userID, projectID)
+func (m *mockProjectService) DeleteProject(ctx context.Context, userID, projectID uuid.UUID, children types.ChildrenMode, version *time.Time) error {
+	args := m.Called(ctx, userID, projectID, children, version)
 	return args.Error(0)
 }
 
+func (m *mockProjectService) DeletionImpact(ctx context.Context, userID, projectID uuid.UUID, children types.ChildrenMode) (deletion.Impact, error) {
+	args := m.Called(ctx, userID, projectID, children)
+	return args.Get(0).(deletion.Impact), args.Error(1)
+}
+
+func (m *mockProjectService) ListDeletedProjectsPaginated(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, limit int32, order coreTypes.SortOrder) ([]types.Project, error) {
+	args := m.Called(ctx, userID, cursor, cursorID, limit, order)
+	return args.Get(0).([]types.Project), args.Error(1)
+}
+
+func (m *mockProjectService) RestoreProject(ctx context.Context, userID, projectID uuid.UUID) (types.Project, error) {
+	args := m.Called(ctx, userID, projectID)
+	return args.Get(0).(types.Project), args.Error(1)
+}
+
+func (m *mockProjectService) CloneProject(ctx context.Context, userID, projectID uuid.UUID, includeWallets bool) (types.Project, error) {
+	args := m.Called(ctx, userID, projectID, includeWallets)
+	return args.Get(0).(types.Project), args.Error(1)
+}
+
+func (m *mockProjectService) PublishProject(ctx context.Context, userID, projectID uuid.UUID) (types.Project, error) {
+	args := m.Called(ctx, userID, projectID)
+	return args.Get(0).(types.Project), args.Error(1)
+}
+
 func (m *mockProjectService) GetProjectWallets(ctx context.Context, userID, projectID uuid.UUID) ([]db.Wallet, error) {
 	args := m.Called(ctx, userID, projectID)
 	return args.Get(0).([]db.Wallet), args.Error(1)
 }
 
-func (m *mockProjectService) ListProjectsPaginated(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, limit int32) ([]types.Project, error) {
-	args := m.Called(ctx, userID, cursor, cursorID, limit)
+func (m *mockProjectService) ListProjectsPaginated(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, pinned, includeDrafts bool, limit int32, order coreTypes.SortOrder) ([]types.Project, error) {
+	args := m.Called(ctx, userID, cursor, cursorID, pinned, includeDrafts, limit, order)
 	return args.Get(0).([]types.Project), args.Error(1)
 }
 
-func (m *mockProjectService) SearchProjects(ctx context.Context, userID uuid.UUID, query string, limit int32) ([]types.Project, error) {
-	args := m.Called(ctx, userID, query, limit)
+func (m *mockProjectService) ListProjectIDsPaginated(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, pinned, includeDrafts bool, limit int32, order coreTypes.SortOrder) ([]coreTypes.KeyedID, error) {
+	args := m.Called(ctx, userID, cursor, cursorID, pinned, includeDrafts, limit, order)
+	return args.Get(0).([]coreTypes.KeyedID), args.Error(1)
+}
+
+// StreamProjectsPaginated hands the projects to fn and then returns the error, so a
+// test fails a stream midway by returning both
+func (m *mockProjectService) StreamProjectsPaginated(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, pinned, includeDrafts bool, limit int32, order coreTypes.SortOrder, fn func(types.Project) error) error {
+	args := m.Called(ctx, userID, cursor, cursorID, pinned, includeDrafts, limit, order)
+	for _, project := range args.Get(0).([]types.Project) {
+		if err := fn(project); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *mockProjectService) PinProject(ctx context.Context, userID, projectID uuid.UUID) (types.Project, error) {
+	args := m.Called(ctx, userID, projectID)
+	return args.Get(0).(types.Project), args.Error(1)
+}
+
+func (m *mockProjectService) UnpinProject(ctx context.Context, userID, projectID uuid.UUID) (types.Project, error) {
+	args := m.Called(ctx, userID, projectID)
+	return args.Get(0).(types.Project), args.Error(1)
+}
+
+func (m *mockProjectService) SearchProjects(ctx context.Context, userID uuid.UUID, query string, includeDrafts bool, limit, offset int32) ([]types.Project, error) {
+	args := m.Called(ctx, userID, query, includeDrafts, limit, offset)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]types.Project), args.Error(1)
 }
 
+func (m *mockProjectService) SearchProjectsPicker(ctx context.Context, userID uuid.UUID, query string, includeDrafts bool, limit, offset int32) ([]types.ProjectPickerItem, error) {
+	args := m.Called(ctx, userID, query, includeDrafts, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.ProjectPickerItem), args.Error(1)
+}
+
+func (m *mockProjectService) ListMilestones(ctx context.Context, userID, projectID uuid.UUID) ([]types.Milestone, error) {
+	args := m.Called(ctx, userID, projectID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.Milestone), args.Error(1)
+}
+
+func (m *mockProjectService) GetMilestone(ctx context.Context, userID, projectID, milestoneID uuid.UUID) (types.Milestone, error) {
+	args := m.Called(ctx, userID, projectID, milestoneID)
+	return args.Get(0).(types.Milestone), args.Error(1)
+}
+
+func (m *mockProjectService) CreateMilestone(ctx context.Context, userID, projectID uuid.UUID, milestoneData types.MilestoneCreatePayload) (types.Milestone, error) {
+	args := m.Called(ctx, userID, projectID, milestoneData)
+	return args.Get(0).(types.Milestone), args.Error(1)
+}
+
+func (m *mockProjectService) UpdateMilestone(ctx context.Context, userID uuid.UUID, milestoneData types.MilestoneUpdatePayload) (types.Milestone, error) {
+	args := m.Called(ctx, userID, milestoneData)
+	return args.Get(0).(types.Milestone), args.Error(1)
+}
+
+func (m *mockProjectService) DeleteMilestone(ctx context.Context, userID, projectID, milestoneID uuid.UUID) error {
+	args := m.Called(ctx, userID, projectID, milestoneID)
+	return args.Error(0)
+}
+
+func (m *mockProjectService) ReorderMilestones(ctx context.Context, userID, projectID uuid.UUID, milestoneIDs []uuid.UUID) ([]types.Milestone, error) {
+	args := m.Called(ctx, userID, projectID, milestoneIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.Milestone), args.Error(1)
+}
+
+// testLimits is injected into the handler, its maximums differ from the package
+// defaults (projects keep smaller pages than the default) so the tests catch limits read from anywhere else
+var testLimits = coreTypes.LimitPolicy{
+	DefaultLimit:       10,
+	MaxLimit:           50,
+	DefaultSearchLimit: 10,
+	MaxSearchLimit:     50,
+	MinTrimmedResults:  2,
+}
+
 func setupTest(t *testing.T) (*mockProjectService, *ProjectHandler) {
 	mockService := new(mockProjectService)
 	logger := zap.NewNop()
-	handler := NewProjectHandler(mockService, logger)
+	handler := NewProjectHandler(mockService, testLimits, logger)
 	return mockService, handler
 }
 
@@ -83,6 +224,7 @@ func TestProjectHandler_CreateProject(t *testing.T) {
 
 	tests := []struct {
 		name           string
+		query          string
 		payload        string
 		setupAuth      bool
 		setupMock      func()
@@ -106,6 +248,48 @@ func TestProjectHandler_CreateProject(t *testing.T) {
 			},
 			expectedStatus: http.StatusCreated,
 		},
+		{
+			name:  "if_not_exists returns the existing project",
+			query: "?if_not_exists=true",
+			payload: `{
+				"name": "test project",
+				"status": "ongoing"
+			}`,
+			setupAuth: true,
+			setupMock: func() {
+				mockService.On("CreateProjectIfNotExists", mock.Anything, userID, mock.AnythingOfType("types.ProjectCreatePayload")).
+					Return(types.Project{ProjectID: uuid.New(), Name: "Test Project"}, false, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "if_not_exists creates a missing project",
+			query: "?if_not_exists=true",
+			payload: `{
+				"name": "Test Project",
+				"status": "ongoing"
+			}`,
+			setupAuth: true,
+			setupMock: func() {
+				mockService.On("CreateProjectIfNotExists", mock.Anything, userID, mock.AnythingOfType("types.ProjectCreatePayload")).
+					Return(types.Project{ProjectID: uuid.New(), Name: "Test Project"}, true, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:  "if_not_exists=false creates as usual",
+			query: "?if_not_exists=false",
+			payload: `{
+				"name": "Test Project",
+				"status": "ongoing"
+			}`,
+			setupAuth: true,
+			setupMock: func() {
+				mockService.On("CreateProject", mock.Anything, userID, mock.AnythingOfType("types.ProjectCreatePayload")).
+					Return(types.Project{ProjectID: uuid.New(), Name: "Test Project"}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
 		{
 			name: "invalid payload",
 			payload: `{
@@ -130,7 +314,7 @@ func TestProjectHandler_CreateProject(t *testing.T) {
 			// Clear previous mock expectations
 			mockService.ExpectedCalls = nil
 
-			req := httptest.NewRequest(http.MethodPost, "/projects", strings.NewReader(tt.payload))
+			req := httptest.NewRequest(http.MethodPost, "/projects"+tt.query, strings.NewReader(tt.payload))
 			req.Header.Set("Content-Type", "application/json")
 
 			if tt.setupAuth {
@@ -143,11 +327,11 @@ func TestProjectHandler_CreateProject(t *testing.T) {
 			handler.CreateProject(w, req)
 
 			assert.Equal(t, tt.expectedStatus, w.Code)
-			if tt.expectedStatus == http.StatusCreated {
+			if tt.expectedStatus == http.StatusCreated || tt.expectedStatus == http.StatusOK {
 				var response map[string]interface{}
 				err := json.NewDecoder(w.Body).Decode(&response)
 				assert.NoError(t, err)
-				assert.Equal(t, float64(http.StatusCreated), response["status"])
+				assert.Equal(t, float64(tt.expectedStatus), response["status"])
 				assert.NotNil(t, response["data"])
 			}
 			mockService.AssertExpectations(t)
@@ -155,6 +339,169 @@ func TestProjectHandler_CreateProject(t *testing.T) {
 	}
 }
 
+func TestProjectHandler_CreateProject_SnakeCase(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	parentID := uuid.New()
+
+	tests := []struct {
+		name           string
+		payload        string
+		expected       func(types.ProjectCreatePayload) bool
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "snake_case fields",
+			payload: fmt.Sprintf(`{
+				"name": "Test Project",
+				"status": "ongoing",
+				"start_date": "2024-01-01T00:00:00Z",
+				"zip_postal_code": "10001",
+				"parent_project_id": %q
+			}`, parentID),
+			expected: func(p types.ProjectCreatePayload) bool {
+				return p.StartDate != nil && p.StartDate.Year() == 2024 && *p.ZipPostalCode == "10001" && *p.ParentProjectID == parentID
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "mixed fields",
+			payload: `{
+				"name": "Test Project",
+				"status": "ongoing",
+				"end_date": "2024-12-31T00:00:00Z",
+				"addressLine1": "123 Main St",
+				"address_line2": "Suite 100"
+			}`,
+			expected: func(p types.ProjectCreatePayload) bool {
+				return p.EndDate != nil && p.EndDate.Month() == 12 && *p.AddressLine1 == "123 Main St" && *p.AddressLine2 == "Suite 100"
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "conflicting fields",
+			payload: `{
+				"name": "Test Project",
+				"status": "ongoing",
+				"endDate": "2024-12-31T00:00:00Z",
+				"end_date": "2025-12-31T00:00:00Z"
+			}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "end_date: conflicts with endDate",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			if tt.expected != nil {
+				mockService.On("CreateProject", mock.Anything, userID, mock.MatchedBy(tt.expected)).
+					Return(types.Project{ProjectID: uuid.New(), Name: "Test Project"}, nil)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/projects", strings.NewReader(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+			req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+
+			w := httptest.NewRecorder()
+			handler.CreateProject(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProjectHandler_CreateProject_DateFormats(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+
+	tests := []struct {
+		name          string
+		dates         string
+		expectedStart string
+		expectedEnd   string
+		expectedError string
+	}{
+		{
+			name:          "bare dates",
+			dates:         `"startDate": "2024-03-01", "endDate": "2024-12-31"`,
+			expectedStart: "2024-03-01",
+			expectedEnd:   "2024-12-31",
+		},
+		{
+			name:          "RFC 3339 times",
+			dates:         `"startDate": "2024-03-01T00:00:00Z", "endDate": "2024-12-31T09:30:00.000Z"`,
+			expectedStart: "2024-03-01",
+			expectedEnd:   "2024-12-31",
+		},
+		{
+			name:          "times west of UTC keep their own date",
+			dates:         `"startDate": "2024-03-01T20:00:00-05:00"`,
+			expectedStart: "2024-03-01",
+		},
+		{
+			name:          "times east of UTC keep their own date",
+			dates:         `"startDate": "2024-03-01T01:00:00+03:00"`,
+			expectedStart: "2024-03-01",
+		},
+		{
+			name:          "same start and end date",
+			dates:         `"startDate": "2024-03-01", "endDate": "2024-03-01T23:00:00Z"`,
+			expectedStart: "2024-03-01",
+			expectedEnd:   "2024-03-01",
+		},
+		{
+			name:          "end before start",
+			dates:         `"startDate": "2024-03-02T00:00:00Z", "endDate": "2024-03-01"`,
+			expectedError: "end date must be after start date",
+		},
+		{
+			name:          "other date format",
+			dates:         `"startDate": "03/01/2024"`,
+			expectedError: `invalid date \"03/01/2024\", expected YYYY-MM-DD or an RFC 3339 time`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			var created types.ProjectCreatePayload
+			if tt.expectedError == "" {
+				mockService.On("CreateProject", mock.Anything, userID, mock.AnythingOfType("types.ProjectCreatePayload")).
+					Run(func(args mock.Arguments) { created = args.Get(2).(types.ProjectCreatePayload) }).
+					Return(types.Project{ProjectID: uuid.New(), Name: "Test Project"}, nil)
+			}
+
+			payload := `{"name": "Test Project", "status": "ongoing", ` + tt.dates + `}`
+			req := httptest.NewRequest(http.MethodPost, "/projects", strings.NewReader(payload))
+			req.Header.Set("Content-Type", "application/json")
+			req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+
+			w := httptest.NewRecorder()
+			handler.CreateProject(w, req)
+
+			if tt.expectedError != "" {
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+				return
+			}
+			require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+			if assert.NotNil(t, created.StartDate) {
+				assert.Equal(t, tt.expectedStart, created.StartDate.String())
+			}
+			if tt.expectedEnd != "" && assert.NotNil(t, created.EndDate) {
+				assert.Equal(t, tt.expectedEnd, created.EndDate.String())
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
 func TestProjectHandler_GetProject(t *testing.T) {
 	mockService, handler := setupTest(t)
 	userID := uuid.New()
@@ -177,7 +524,7 @@ func TestProjectHandler_GetProject(t *testing.T) {
 					Name:      "Test Project",
 					Status:    "ongoing",
 				}
-				mockService.On("GetProject", mock.Anything, userID, projectID).
+				mockService.On("GetProject", mock.Anything, userID, projectID, types.ProjectExpand{}).
 					Return(expectedProject, nil)
 			},
 			expectedStatus: http.StatusOK,
@@ -232,6 +579,56 @@ func TestProjectHandler_GetProject(t *testing.T) {
 	}
 }
 
+func TestProjectHandler_GetProject_TimestampFormat(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	projectID := uuid.New()
+
+	// the database hands out microseconds and a non UTC zone, whole seconds must not lose
+	// their fraction either
+	local := time.FixedZone("UTC+2", 2*60*60)
+	startDate := coreTypes.NewDate(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	pinnedAt := time.Date(2024, 1, 3, 10, 30, 15, 123456789, local)
+	project := types.Project{
+		ProjectID: projectID,
+		Name:      "Test Project",
+		Status:    "ongoing",
+		StartDate: &startDate,
+		Pinned:    true,
+		PinnedAt:  coreTypes.TimestampPtr(&pinnedAt),
+		CreatedAt: coreTypes.NewTimestamp(time.Date(2024, 1, 2, 8, 0, 0, 120000000, time.UTC)),
+		UpdatedAt: coreTypes.NewTimestamp(time.Date(2024, 1, 2, 9, 0, 0, 999999, local)),
+	}
+	mockService.On("GetProject", mock.Anything, userID, projectID, types.ProjectExpand{}).Return(project, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+projectID.String(), nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", projectID.String())
+	ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
+	req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+
+	w := httptest.NewRecorder()
+	handler.GetProject(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, map[string]string{
+		"startDate": "2024-01-01",
+		"pinnedAt":  "2024-01-03T08:30:15.123Z",
+		"createdAt": "2024-01-02T08:00:00.120Z",
+		"updatedAt": "2024-01-02T07:00:00.000Z",
+	}, map[string]string{
+		"startDate": response.Data["startDate"].(string),
+		"pinnedAt":  response.Data["pinnedAt"].(string),
+		"createdAt": response.Data["createdAt"].(string),
+		"updatedAt": response.Data["updatedAt"].(string),
+	})
+	mockService.AssertExpectations(t)
+}
+
 func TestProjectHandler_ListProjects(t *testing.T) {
 	mockService, handler := setupTest(t)
 	userID := uuid.New()
@@ -303,6 +700,35 @@ func TestProjectHandler_ListProjects(t *testing.T) {
 	}
 }
 
+func TestProjectHandler_ListProjects_ByIDs(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	first, second, foreign := uuid.New(), uuid.New(), uuid.New()
+	mockService.On("GetProjectsByIDs", mock.Anything, userID, []uuid.UUID{first, foreign, second}).Return([]types.Project{
+		{ProjectID: first, Name: "Kitchen", Status: "ongoing"},
+		{ProjectID: second, Name: "Garden", Status: "ongoing"},
+	}, []uuid.UUID{foreign}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/projects?ids="+first.String()+","+foreign.String()+","+second.String(), nil)
+	req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+	w := httptest.NewRecorder()
+	handler.ListProjects(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data []types.Project `json:"data"`
+		Meta struct {
+			Missing []uuid.UUID `json:"missing"`
+		} `json:"meta"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	require.Len(t, response.Data, 2)
+	assert.Equal(t, first, response.Data[0].ProjectID)
+	assert.Equal(t, second, response.Data[1].ProjectID)
+	assert.Equal(t, []uuid.UUID{foreign}, response.Meta.Missing)
+	mockService.AssertNotCalled(t, "ListProjects")
+}
+
 func TestProjectHandler_ListProjectsPaginated(t *testing.T) {
 	mockService, handler := setupTest(t)
 	userID := uuid.New()
@@ -330,13 +756,13 @@ func TestProjectHandler_ListProjectsPaginated(t *testing.T) {
 						ProjectID: uuid.New(),
 						Name:      "Project 1",
 						Status:    "ongoing",
-						CreatedAt: now.Add(-1 * time.Hour),
+						CreatedAt: coreTypes.NewTimestamp(now.Add(-1 * time.Hour)),
 					},
 					{
 						ProjectID: uuid.New(),
 						Name:      "Project 2",
 						Status:    "completed",
-						CreatedAt: now.Add(-2 * time.Hour),
+						CreatedAt: coreTypes.NewTimestamp(now.Add(-2 * time.Hour)),
 					},
 				}
 				mockService.On("ListProjectsPaginated",
@@ -348,12 +774,15 @@ func TestProjectHandler_ListProjectsPaginated(t *testing.T) {
 					mock.MatchedBy(func(id uuid.UUID) bool {
 						return id == uuid.Nil
 					}),
-					int32(coreTypes.DefaultLimit),
+					true,
+					false,
+					testLimits.DefaultLimit,
+					coreTypes.SortOrderDesc,
 				).Return(projects, nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedLen:    2,
-			expectedLimit:  fmt.Sprint(coreTypes.DefaultLimit),
+			expectedLimit:  fmt.Sprint(testLimits.DefaultLimit),
 		},
 		{
 			name:      "first page with custom limit",
@@ -367,7 +796,7 @@ func TestProjectHandler_ListProjectsPaginated(t *testing.T) {
 						ProjectID: uuid.New(),
 						Name:      "Project 1",
 						Status:    "ongoing",
-						CreatedAt: now.Add(-1 * time.Hour),
+						CreatedAt: coreTypes.NewTimestamp(now.Add(-1 * time.Hour)),
 					},
 				}
 				mockService.On("ListProjectsPaginated",
@@ -379,7 +808,10 @@ func TestProjectHandler_ListProjectsPaginated(t *testing.T) {
 					mock.MatchedBy(func(id uuid.UUID) bool {
 						return id == uuid.Nil
 					}),
+					true,
+					false,
 					int32(5),
+					coreTypes.SortOrderDesc,
 				).Return(projects, nil)
 			},
 			expectedStatus: http.StatusOK,
@@ -390,7 +822,7 @@ func TestProjectHandler_ListProjectsPaginated(t *testing.T) {
 			name:      "successful pagination with next_token",
 			setupAuth: true,
 			queryParams: map[string]string{
-				"next_token": coreTypes.EncodeCursor(now, cursorID),
+				"next_token": coreTypes.EncodeCursor(now, cursorID, userID),
 				"limit":      "2",
 			},
 			setupMock: func() {
@@ -399,13 +831,13 @@ func TestProjectHandler_ListProjectsPaginated(t *testing.T) {
 						ProjectID: uuid.New(),
 						Name:      "Project 3",
 						Status:    "ongoing",
-						CreatedAt: now.Add(-3 * time.Hour),
+						CreatedAt: coreTypes.NewTimestamp(now.Add(-3 * time.Hour)),
 					},
 					{
 						ProjectID: uuid.New(),
 						Name:      "Project 4",
 						Status:    "completed",
-						CreatedAt: now.Add(-4 * time.Hour),
+						CreatedAt: coreTypes.NewTimestamp(now.Add(-4 * time.Hour)),
 					},
 				}
 				mockService.On("ListProjectsPaginated",
@@ -417,7 +849,10 @@ func TestProjectHandler_ListProjectsPaginated(t *testing.T) {
 					mock.MatchedBy(func(id uuid.UUID) bool {
 						return id == cursorID
 					}),
+					false,
+					false,
 					int32(2),
+					coreTypes.SortOrderDesc,
 				).Return(projects, nil)
 			},
 			expectedStatus:  http.StatusOK,
@@ -425,41 +860,122 @@ func TestProjectHandler_ListProjectsPaginated(t *testing.T) {
 			expectNextToken: true,
 		},
 		{
-			name:           "missing auth",
-			setupAuth:      false,
-			queryParams:    map[string]string{},
-			setupMock:      func() {},
-			expectedStatus: http.StatusUnauthorized,
-			expectedError:  "missing user ID",
+			name:      "first page in ascending order",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"limit": "1",
+				"order": "asc",
+			},
+			setupMock: func() {
+				projects := []types.Project{
+					{
+						ProjectID: uuid.New(),
+						Name:      "Oldest Project",
+						Status:    "ongoing",
+						CreatedAt: coreTypes.NewTimestamp(now.Add(-4 * time.Hour)),
+					},
+				}
+				mockService.On("ListProjectsPaginated",
+					mock.Anything,
+					userID,
+					// the pinned projects come first and are listed most recently pinned first in either order
+					mock.MatchedBy(func(t time.Time) bool {
+						return t.After(now)
+					}),
+					uuid.Nil,
+					true,
+					false,
+					int32(1),
+					coreTypes.SortOrderAsc,
+				).Return(projects, nil)
+			},
+			expectedStatus:  http.StatusOK,
+			expectedLen:     1,
+			expectNextToken: true,
 		},
 		{
-			name:      "service error",
+			name:      "next page keeps cursor order",
 			setupAuth: true,
 			queryParams: map[string]string{
-				"limit": "10",
+				"limit":      "1",
+				"next_token": coreTypes.EncodeOrderedCursor(now, cursorID, coreTypes.SortOrderAsc, userID),
 			},
 			setupMock: func() {
 				mockService.On("ListProjectsPaginated",
 					mock.Anything,
 					userID,
-					mock.Anything,
-					mock.Anything,
-					int32(10),
-				).Return([]types.Project{}, fmt.Errorf("database error"))
+					mock.MatchedBy(func(t time.Time) bool {
+						return t.Equal(now)
+					}),
+					cursorID,
+					false,
+					false,
+					int32(1),
+					coreTypes.SortOrderAsc,
+				).Return([]types.Project{}, nil)
 			},
-			expectedStatus: http.StatusInternalServerError,
-			expectedError:  "database error",
+			expectedStatus: http.StatusOK,
+			expectedLen:    0,
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			mockService.ExpectedCalls = nil
-
-			reqURL := "/projects/paginated"
-			if len(tt.queryParams) > 0 {
-				values := url.Values{}
-				for k, v := range tt.queryParams {
+		{
+			name:      "order conflicts with cursor",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"order":      "desc",
+				"next_token": coreTypes.EncodeOrderedCursor(now, cursorID, coreTypes.SortOrderAsc, userID),
+			},
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "order does not match next_token",
+		},
+		{
+			name:      "invalid order",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"order": "sideways",
+			},
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing auth",
+			setupAuth:      false,
+			queryParams:    map[string]string{},
+			setupMock:      func() {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "missing user ID",
+		},
+		{
+			name:      "service error",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"limit": "10",
+			},
+			setupMock: func() {
+				mockService.On("ListProjectsPaginated",
+					mock.Anything,
+					userID,
+					mock.Anything,
+					mock.Anything,
+					mock.Anything,
+					false,
+					int32(10),
+					coreTypes.SortOrderDesc,
+				).Return([]types.Project{}, fmt.Errorf("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "database error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+
+			reqURL := "/projects/paginated"
+			if len(tt.queryParams) > 0 {
+				values := url.Values{}
+				for k, v := range tt.queryParams {
 					values.Add(k, v)
 				}
 				reqURL += "?" + values.Encode()
@@ -512,6 +1028,129 @@ func TestProjectHandler_ListProjectsPaginated(t *testing.T) {
 	}
 }
 
+func TestProjectHandler_ListProjectsPaginated_CursorIssuer(t *testing.T) {
+	userID := uuid.New()
+	now := time.Now().UTC()
+	cursor := coreTypes.Cursor{
+		Timestamp: now.Add(-time.Hour),
+		ID:        uuid.New(),
+		Order:     coreTypes.SortOrderDesc,
+		IssuedAt:  now.Add(-time.Minute),
+		UserID:    userID,
+	}
+	key := []byte("cursor key")
+	issued := func(issuedAt time.Time, user uuid.UUID) string {
+		c := cursor
+		c.IssuedAt, c.UserID = issuedAt, user
+		return c.Encode(key)
+	}
+	legacy := issued(time.Time{}, uuid.Nil)
+	unsigned := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%s:desc:%d:%s", cursor.Timestamp.UnixNano(), cursor.ID, cursor.IssuedAt.Unix(), userID)))
+	forged := cursor
+	forged.UserID = uuid.New()
+	forgedToken := forged.Encode([]byte("another key"))
+
+	tests := []struct {
+		name           string
+		token          string
+		allowLegacy    bool
+		expectedStatus int
+		expectedType   string
+	}{
+		{name: "fresh token", token: issued(now.Add(-time.Minute), userID), expectedStatus: http.StatusOK},
+		{name: "token at the end of its ttl", token: issued(now.Add(-coreTypes.DefaultCursorTTL+time.Minute), userID), expectedStatus: http.StatusOK},
+		{name: "expired token", token: issued(now.Add(-coreTypes.DefaultCursorTTL-time.Minute), userID), expectedStatus: http.StatusBadRequest, expectedType: string(coreErrors.ErrorTypeExpiredCursor)},
+		{name: "token of another user", token: issued(now.Add(-time.Minute), uuid.New()), expectedStatus: http.StatusBadRequest, expectedType: string(coreErrors.ErrorTypeValidation)},
+		{name: "legacy token while allowed", token: legacy, allowLegacy: true, expectedStatus: http.StatusOK},
+		{name: "legacy token once disallowed", token: legacy, expectedStatus: http.StatusBadRequest, expectedType: string(coreErrors.ErrorTypeExpiredCursor)},
+		{name: "unsigned token while legacy allowed", token: unsigned, allowLegacy: true, expectedStatus: http.StatusOK},
+		{name: "unsigned token once disallowed", token: unsigned, expectedStatus: http.StatusBadRequest, expectedType: string(coreErrors.ErrorTypeExpiredCursor)},
+		{name: "token signed with another key", token: forgedToken, allowLegacy: true, expectedStatus: http.StatusBadRequest, expectedType: string(coreErrors.ErrorTypeValidation)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockProjectService)
+			limits := testLimits
+			limits.CursorKey = key
+			limits.AllowLegacyCursors = tt.allowLegacy
+			handler := NewProjectHandler(mockService, limits, zap.NewNop())
+			if tt.expectedStatus == http.StatusOK {
+				mockService.On("ListProjectsPaginated", mock.Anything, userID, cursor.Timestamp, cursor.ID, false, false, testLimits.DefaultLimit, coreTypes.SortOrderDesc).
+					Return([]types.Project{}, nil)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/projects?next_token="+url.QueryEscape(tt.token), nil)
+			req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+			w := httptest.NewRecorder()
+			handler.ListProjectsPaginated(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedType != "" {
+				var response map[string]interface{}
+				assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				assert.Equal(t, tt.expectedType, response["type"])
+				if tt.expectedType == string(coreErrors.ErrorTypeExpiredCursor) {
+					assert.NotEmpty(t, response["hint"])
+				}
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProjectHandler_ListProjectsPaginated_Pinned(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	now := time.Now().UTC()
+	pinnedAt := now.Add(-time.Minute)
+	pinned := types.Project{ProjectID: uuid.New(), Name: "Pinned", CreatedAt: coreTypes.NewTimestamp(now.Add(-time.Hour)), Pinned: true, PinnedAt: coreTypes.TimestampPtr(&pinnedAt)}
+	unpinned := types.Project{ProjectID: uuid.New(), Name: "Unpinned", CreatedAt: coreTypes.NewTimestamp(now.Add(-2 * time.Hour))}
+
+	list := func(query string) (*coreTypes.Cursor, int) {
+		req := httptest.NewRequest(http.MethodGet, "/projects?"+query, nil)
+		req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+		w := httptest.NewRecorder()
+		handler.ListProjectsPaginated(w, req)
+
+		var response struct {
+			Meta struct {
+				NextToken string `json:"next_token"`
+			} `json:"meta"`
+		}
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		cursor, err := coreTypes.DecodeCursor(response.Meta.NextToken, "", coreTypes.DefaultLimitPolicy())
+		assert.NoError(t, err)
+		return cursor, w.Code
+	}
+
+	// a page ending on a pinned project resumes among the pinned ones
+	mockService.On("ListProjectsPaginated", mock.Anything, userID, mock.Anything, uuid.Nil, true, false, int32(1), coreTypes.SortOrderAsc).
+		Return([]types.Project{pinned}, nil).Once()
+	cursor, code := list("limit=1&order=asc")
+	assert.Equal(t, http.StatusOK, code)
+	if assert.NotNil(t, cursor) {
+		assert.True(t, cursor.Pinned)
+		assert.True(t, cursor.Timestamp.Equal(pinnedAt))
+		assert.Equal(t, pinned.ProjectID, cursor.ID)
+		assert.Equal(t, coreTypes.SortOrderAsc, cursor.Order)
+	}
+
+	// the next page is still in the pinned segment, ending on an unpinned project moves past it
+	token := coreTypes.EncodePinnedCursor(pinnedAt, pinned.ProjectID, coreTypes.SortOrderAsc, userID)
+	mockService.On("ListProjectsPaginated", mock.Anything, userID, mock.MatchedBy(pinnedAt.Equal), pinned.ProjectID, true, false, int32(1), coreTypes.SortOrderAsc).
+		Return([]types.Project{unpinned}, nil).Once()
+	cursor, code = list("limit=1&next_token=" + url.QueryEscape(token))
+	assert.Equal(t, http.StatusOK, code)
+	if assert.NotNil(t, cursor) {
+		assert.False(t, cursor.Pinned)
+		assert.True(t, cursor.Timestamp.Equal(unpinned.CreatedAt.Time))
+		assert.Equal(t, unpinned.ProjectID, cursor.ID)
+	}
+
+	mockService.AssertExpectations(t)
+}
+
 func TestProjectHandler_SearchProjects(t *testing.T) {
 	mockService, handler := setupTest(t)
 	userID := uuid.New()
@@ -539,7 +1178,7 @@ func TestProjectHandler_SearchProjects(t *testing.T) {
 						Status:    "ongoing",
 					},
 				}
-				mockService.On("SearchProjects", mock.Anything, userID, "test", int32(coreTypes.DefaultSearchLimit)).
+				mockService.On("SearchProjects", mock.Anything, userID, "test", false, testLimits.DefaultSearchLimit, int32(0)).
 					Return(projects, nil)
 			},
 			expectedStatus: http.StatusOK,
@@ -548,9 +1187,83 @@ func TestProjectHandler_SearchProjects(t *testing.T) {
 				assert.Len(t, data, 1)
 				meta := response["meta"].(map[string]interface{})
 				assert.Equal(t, "test", meta["query"])
-				assert.Equal(t, float64(coreTypes.DefaultSearchLimit), meta["limit"])
+				assert.Equal(t, float64(testLimits.DefaultSearchLimit), meta["limit"])
 				assert.Equal(t, float64(1), meta["count"])
+				assert.Equal(t, "full", meta["view"])
+			},
+		},
+		{
+			name:      "picker view returns only what a picker shows",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"q":              "test",
+				"include_drafts": "true",
+				"view":           "picker",
+			},
+			setupMock: func() {
+				endDate := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+				items := []types.ProjectPickerItem{
+					{ProjectID: uuid.New(), Name: "Test Project", Status: "ongoing", EndDate: coreTypes.DatePtr(&endDate)},
+				}
+				mockService.On("SearchProjectsPicker", mock.Anything, userID, "test", true, testLimits.DefaultSearchLimit, int32(0)).
+					Return(items, nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, response map[string]interface{}) {
+				assert.Equal(t, "picker", response["meta"].(map[string]interface{})["view"])
+				data := response["data"].([]interface{})
+				require.Len(t, data, 1)
+				item := data[0].(map[string]interface{})
+				assert.Len(t, item, 4)
+				for _, key := range []string{"projectId", "name", "status", "endDate"} {
+					assert.Contains(t, item, key)
+				}
+				assert.Equal(t, "2024-12-31", item["endDate"])
+			},
+		},
+		{
+			name:      "unknown view",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"q":    "test",
+				"view": "compact",
+			},
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "trim=auto cuts the results at the score cliff",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"q":    "test",
+				"trim": "auto",
+			},
+			setupMock: func() {
+				scores := []float32{0.95, 0.9, 0.4}
+				projects := make([]types.Project, len(scores))
+				for i := range scores {
+					projects[i] = types.Project{ProjectID: uuid.New(), Name: "Test Project", Status: "ongoing", Score: &scores[i]}
+				}
+				mockService.On("SearchProjects", mock.Anything, userID, "test", false, testLimits.DefaultSearchLimit, int32(0)).
+					Return(projects, nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, response map[string]interface{}) {
+				assert.Len(t, response["data"], 2)
+				meta := response["meta"].(map[string]interface{})
+				assert.Equal(t, float64(1), meta["trimmed"])
+				assert.InDelta(t, 0.9, meta["cutoff"], 0.001)
+			},
+		},
+		{
+			name:      "unknown trim mode",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"q":    "test",
+				"trim": "always",
 			},
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
 		},
 		{
 			name:      "empty query parameter returns all projects",
@@ -563,15 +1276,15 @@ func TestProjectHandler_SearchProjects(t *testing.T) {
 					{
 						ProjectID: uuid.New(),
 						Name:      "Recent Project",
-						CreatedAt: time.Now().Add(-1 * time.Hour),
+						CreatedAt: coreTypes.NewTimestamp(time.Now().Add(-1 * time.Hour)),
 					},
 					{
 						ProjectID: uuid.New(),
 						Name:      "Older Project",
-						CreatedAt: time.Now().Add(-2 * time.Hour),
+						CreatedAt: coreTypes.NewTimestamp(time.Now().Add(-2 * time.Hour)),
 					},
 				}
-				mockService.On("SearchProjects", mock.Anything, userID, "", int32(coreTypes.DefaultSearchLimit)).
+				mockService.On("SearchProjects", mock.Anything, userID, "", false, testLimits.DefaultSearchLimit, int32(0)).
 					Return(projects, nil)
 			},
 			expectedStatus: http.StatusOK,
@@ -580,7 +1293,7 @@ func TestProjectHandler_SearchProjects(t *testing.T) {
 				assert.Len(t, data, 2)
 				meta := response["meta"].(map[string]interface{})
 				assert.Equal(t, nil, meta["query"])
-				assert.Equal(t, float64(coreTypes.DefaultSearchLimit), meta["limit"])
+				assert.Equal(t, float64(testLimits.DefaultSearchLimit), meta["limit"])
 				assert.Equal(t, float64(2), meta["count"])
 			},
 		},
@@ -594,6 +1307,28 @@ func TestProjectHandler_SearchProjects(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedError:  "query: the length must be between 1 and 100.",
 		},
+		{
+			name:      "long runs of a character collapsed",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"q": "roooooooof",
+			},
+			setupMock: func() {
+				mockService.On("SearchProjects", mock.Anything, userID, "rooof", false, testLimits.DefaultSearchLimit, int32(0)).
+					Return([]types.Project{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:      "query too complex",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"q": "zq xj vk wb pf ym gh lt nr cd sa eo iu ba ce di fo gu hy jk lm np qr st vw xz",
+			},
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "query too complex",
+		},
 		{
 			name:      "negative limit",
 			setupAuth: true,
@@ -612,7 +1347,7 @@ func TestProjectHandler_SearchProjects(t *testing.T) {
 				"q": "test",
 			},
 			setupMock: func() {
-				mockService.On("SearchProjects", mock.Anything, userID, "test", int32(coreTypes.DefaultSearchLimit)).
+				mockService.On("SearchProjects", mock.Anything, userID, "test", false, testLimits.DefaultSearchLimit, int32(0)).
 					Return([]types.Project(nil), fmt.Errorf("database error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
@@ -672,3 +1407,1084 @@ func TestProjectHandler_SearchProjects(t *testing.T) {
 		})
 	}
 }
+
+func TestProjectHandler_QueryParamsModes(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+
+	tests := []struct {
+		name             string
+		mode             coreTypes.QueryParamsMode
+		path             string
+		handle           http.HandlerFunc
+		setupMock        func()
+		expectedStatus   int
+		expectedError    string
+		expectedWarnings []interface{}
+	}{
+		{
+			name:   "unknown param warned about in warn mode",
+			mode:   coreTypes.QueryParamsWarn,
+			path:   "/projects/search?serach=foo",
+			handle: handler.SearchProjects,
+			setupMock: func() {
+				mockService.On("SearchProjects", mock.Anything, userID, "", false, testLimits.DefaultSearchLimit, int32(0)).Return([]types.Project{}, nil)
+			},
+			expectedStatus:   http.StatusOK,
+			expectedWarnings: []interface{}{"unknown query parameter: serach (allowed: include_drafts, view, trim, q, limit, next_token)"},
+		},
+		{
+			name:           "unknown search params rejected when strict",
+			mode:           coreTypes.QueryParamsStrict,
+			path:           "/projects/search?q=foo&nexttoken=abc&page=2&page=3",
+			handle:         handler.SearchProjects,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "unknown query parameters: nexttoken, page (allowed: include_drafts, view, trim, q, limit, next_token)",
+		},
+		{
+			name:           "unknown list param rejected when strict",
+			mode:           coreTypes.QueryParamsStrict,
+			path:           "/projects?limt=5",
+			handle:         handler.ListProjectsPaginated,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "unknown query parameter: limt (allowed: include_drafts, limit, order, next_token, stream, only_ids)",
+		},
+		{
+			name:   "known list params accepted when strict",
+			mode:   coreTypes.QueryParamsStrict,
+			path:   "/projects?limit=5&order=asc",
+			handle: handler.ListProjectsPaginated,
+			setupMock: func() {
+				mockService.On("ListProjectsPaginated", mock.Anything, userID, mock.Anything, mock.Anything, true, false,
+					int32(5), coreTypes.SortOrderAsc).Return([]types.Project{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
+			ctx = context.WithValue(ctx, requestcontext.QueryParamsModeKey, string(tt.mode))
+			req = req.WithContext(requestcontext.WithWarnings(ctx))
+
+			tt.setupMock()
+			w := httptest.NewRecorder()
+			tt.handle(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var response map[string]interface{}
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+			if tt.expectedError != "" {
+				assert.Equal(t, tt.expectedError, response["error"])
+			} else {
+				meta := response["meta"].(map[string]interface{})
+				if tt.expectedWarnings == nil {
+					assert.NotContains(t, meta, "warnings")
+				} else {
+					assert.Equal(t, tt.expectedWarnings, meta["warnings"])
+				}
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProjectHandler_ListDeletedProjects(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	deletedAt := time.Now().Add(-time.Hour).UTC()
+
+	tests := []struct {
+		name            string
+		setupAuth       bool
+		query           string
+		setupMock       func()
+		expectedStatus  int
+		expectNextToken bool
+	}{
+		{
+			name:      "full page returns a token from the deletion time",
+			setupAuth: true,
+			query:     "?limit=1",
+			setupMock: func() {
+				mockService.On("ListDeletedProjectsPaginated", mock.Anything, userID,
+					mock.AnythingOfType("time.Time"), uuid.Nil, int32(1), coreTypes.SortOrderDesc).
+					Return([]types.Project{{ProjectID: uuid.New(), Name: "Old Project", DeletedAt: coreTypes.TimestampPtr(&deletedAt)}}, nil)
+			},
+			expectedStatus:  http.StatusOK,
+			expectNextToken: true,
+		},
+		{
+			name:      "partial page has no token",
+			setupAuth: true,
+			setupMock: func() {
+				mockService.On("ListDeletedProjectsPaginated", mock.Anything, userID,
+					mock.AnythingOfType("time.Time"), uuid.Nil, testLimits.DefaultLimit, coreTypes.SortOrderDesc).
+					Return([]types.Project{{ProjectID: uuid.New(), Name: "Old Project", DeletedAt: coreTypes.TimestampPtr(&deletedAt)}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid token",
+			setupAuth:      true,
+			query:          "?next_token=invalid",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing auth",
+			setupAuth:      false,
+			setupMock:      func() {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+
+			req := httptest.NewRequest(http.MethodGet, "/projects/trash"+tt.query, nil)
+			if tt.setupAuth {
+				ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
+				req = req.WithContext(ctx)
+			}
+
+			tt.setupMock()
+			w := httptest.NewRecorder()
+			handler.ListDeletedProjects(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response map[string]interface{}
+				err := json.NewDecoder(w.Body).Decode(&response)
+				assert.NoError(t, err)
+
+				data := response["data"].([]interface{})
+				assert.NotEmpty(t, data[0].(map[string]interface{})["deletedAt"])
+
+				meta := response["meta"].(map[string]interface{})
+				if tt.expectNextToken {
+					cursor, err := coreTypes.DecodeCursor(meta["next_token"].(string), "", coreTypes.DefaultLimitPolicy())
+					assert.NoError(t, err)
+					assert.True(t, cursor.Timestamp.Equal(deletedAt))
+				} else {
+					assert.Empty(t, meta["next_token"])
+				}
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProjectHandler_RestoreProject(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	projectID := uuid.New()
+
+	tests := []struct {
+		name           string
+		setupAuth      bool
+		projectID      string
+		setupMock      func()
+		expectedStatus int
+	}{
+		{
+			name:      "successful restore",
+			setupAuth: true,
+			projectID: projectID.String(),
+			setupMock: func() {
+				mockService.On("RestoreProject", mock.Anything, userID, projectID).
+					Return(types.Project{ProjectID: projectID, Name: "Test Project", Status: "ongoing"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:      "not in trash",
+			setupAuth: true,
+			projectID: projectID.String(),
+			setupMock: func() {
+				mockService.On("RestoreProject", mock.Anything, userID, projectID).
+					Return(types.Project{}, &coreErrors.ErrorResponse{Type: coreErrors.ErrorTypeNotFound, Message: "project(s) not found"})
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:      "name taken by an active project",
+			setupAuth: true,
+			projectID: projectID.String(),
+			setupMock: func() {
+				mockService.On("RestoreProject", mock.Anything, userID, projectID).
+					Return(types.Project{}, &coreErrors.ErrorResponse{Type: coreErrors.ErrorTypeConflict, Message: "Failed to restore project(s): already exists"})
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "invalid project ID",
+			setupAuth:      true,
+			projectID:      "invalid-uuid",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing auth",
+			setupAuth:      false,
+			projectID:      projectID.String(),
+			setupMock:      func() {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+
+			req := httptest.NewRequest(http.MethodPost, "/projects/"+tt.projectID+"/restore", nil)
+			if tt.setupAuth {
+				ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
+				req = req.WithContext(ctx)
+			}
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.projectID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			tt.setupMock()
+			w := httptest.NewRecorder()
+			handler.RestoreProject(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response map[string]interface{}
+				err := json.NewDecoder(w.Body).Decode(&response)
+				assert.NoError(t, err)
+				assert.Equal(t, "Resource restored successfully", response["message"])
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProjectHandler_PublishProject(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	projectID := uuid.New()
+
+	tests := []struct {
+		name           string
+		projectID      string
+		setupMock      func()
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:      "successful publish",
+			projectID: projectID.String(),
+			setupMock: func() {
+				mockService.On("PublishProject", mock.Anything, userID, projectID).
+					Return(types.Project{ProjectID: projectID, Name: "Test Project", Status: "ongoing"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:      "missing required fields",
+			projectID: projectID.String(),
+			setupMock: func() {
+				mockService.On("PublishProject", mock.Anything, userID, projectID).
+					Return(types.Project{}, coreErrors.NewValidationError("the project needs start_date before it can be published"))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "the project needs start_date before it can be published",
+		},
+		{
+			name:      "project not found",
+			projectID: projectID.String(),
+			setupMock: func() {
+				mockService.On("PublishProject", mock.Anything, userID, projectID).
+					Return(types.Project{}, coreErrors.NewNotFoundError("project not found"))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "invalid project ID",
+			projectID:      "invalid-uuid",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+
+			req := httptest.NewRequest(http.MethodPost, "/projects/"+tt.projectID+"/publish", nil)
+			ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.projectID)
+			req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+
+			tt.setupMock()
+			w := httptest.NewRecorder()
+			handler.PublishProject(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.NotEmpty(t, w.Header().Get("ETag"))
+			}
+			if tt.expectedError != "" {
+				var response map[string]interface{}
+				assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				assert.Contains(t, response["error"], tt.expectedError)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProjectHandler_CloneProject(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	projectID := uuid.New()
+	cloneID := uuid.New()
+
+	tests := []struct {
+		name           string
+		setupAuth      bool
+		projectID      string
+		query          string
+		setupMock      func()
+		expectedStatus int
+	}{
+		{
+			name:      "clones the project alone",
+			setupAuth: true,
+			projectID: projectID.String(),
+			setupMock: func() {
+				mockService.On("CloneProject", mock.Anything, userID, projectID, false).
+					Return(types.Project{ProjectID: cloneID, Name: "Test Project (copy)", Status: "ongoing"}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:      "clones the wallets too",
+			setupAuth: true,
+			projectID: projectID.String(),
+			query:     "?include_wallets=true",
+			setupMock: func() {
+				mockService.On("CloneProject", mock.Anything, userID, projectID, true).
+					Return(types.Project{ProjectID: cloneID, Name: "Test Project (copy)", Status: "ongoing"}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:      "another user's project",
+			setupAuth: true,
+			projectID: projectID.String(),
+			setupMock: func() {
+				mockService.On("CloneProject", mock.Anything, userID, projectID, false).
+					Return(types.Project{}, &coreErrors.ErrorResponse{Type: coreErrors.ErrorTypeNotFound, Message: "project(s) not found"})
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:      "copy already exists",
+			setupAuth: true,
+			projectID: projectID.String(),
+			setupMock: func() {
+				mockService.On("CloneProject", mock.Anything, userID, projectID, false).
+					Return(types.Project{}, &coreErrors.ErrorResponse{Type: coreErrors.ErrorTypeConflict, Message: "Failed to clone project(s): already exists"})
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "invalid project ID",
+			setupAuth:      true,
+			projectID:      "invalid-uuid",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing auth",
+			setupAuth:      false,
+			projectID:      projectID.String(),
+			setupMock:      func() {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+
+			req := httptest.NewRequest(http.MethodPost, "/projects/"+tt.projectID+"/clone"+tt.query, nil)
+			if tt.setupAuth {
+				ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
+				req = req.WithContext(ctx)
+			}
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.projectID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			tt.setupMock()
+			w := httptest.NewRecorder()
+			handler.CloneProject(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusCreated {
+				var response struct {
+					Data types.Project `json:"data"`
+				}
+				err := json.NewDecoder(w.Body).Decode(&response)
+				assert.NoError(t, err)
+				assert.Equal(t, cloneID, response.Data.ProjectID)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProjectHandler_DeleteProject_IfMatch(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	projectID := uuid.New()
+	updatedAt := time.Date(2025, 3, 4, 10, 0, 0, 123456000, time.UTC)
+	current := types.Project{ProjectID: projectID, Name: "Test Project", UpdatedAt: coreTypes.NewTimestamp(updatedAt)}
+
+	tests := []struct {
+		name           string
+		query          string
+		ifMatch        string
+		setupMock      func()
+		expectedStatus int
+	}{
+		{
+			name: "without If-Match any version is deleted",
+			setupMock: func() {
+				mockService.On("DeleteProject", mock.Anything, userID, projectID, types.ChildrenRestrict, (*time.Time)(nil)).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:    "matching If-Match deletes only that version",
+			query:   "?cascade=true",
+			ifMatch: `"1741082400123456"`,
+			setupMock: func() {
+				mockService.On("GetProject", mock.Anything, userID, projectID, types.ProjectExpand{}).Return(current, nil)
+				mockService.On("DeleteProject", mock.Anything, userID, projectID, types.ChildrenCascade, &updatedAt).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:    "stale If-Match",
+			ifMatch: `"1741082400000000"`,
+			setupMock: func() {
+				mockService.On("GetProject", mock.Anything, userID, projectID, types.ProjectExpand{}).Return(current, nil)
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:    "project updated between the read and the delete",
+			ifMatch: `"1741082400123456"`,
+			setupMock: func() {
+				mockService.On("GetProject", mock.Anything, userID, projectID, types.ProjectExpand{}).Return(current, nil)
+				mockService.On("DeleteProject", mock.Anything, userID, projectID, types.ChildrenRestrict, &updatedAt).
+					Return(fmt.Errorf("delete project %s: %w", projectID, coreRepository.ErrPreconditionFailed))
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+
+			req := httptest.NewRequest(http.MethodDelete, "/projects/"+projectID.String()+tt.query, nil)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", projectID.String())
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			tt.setupMock()
+			w := httptest.NewRecorder()
+			handler.DeleteProject(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusPreconditionFailed {
+				var response coreErrors.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				assert.Equal(t, coreErrors.ErrorTypePreconditionFailed, response.Type)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProjectHandler_PreviewProjectDeletion(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	projectID := uuid.New()
+
+	tests := []struct {
+		name           string
+		setupAuth      bool
+		projectID      string
+		query          string
+		setupMock      func()
+		expectedStatus int
+		expectedBlock  bool
+	}{
+		{
+			name:      "blocked by sub-projects",
+			setupAuth: true,
+			projectID: projectID.String(),
+			setupMock: func() {
+				mockService.On("DeletionImpact", mock.Anything, userID, projectID, types.ChildrenRestrict).
+					Return(deletion.Impact{Resource: "project", ID: projectID, Blocked: true, Reasons: []string{"project has 1 sub-project(s)"},
+						Affected: []deletion.Affected{{Kind: "subProjects", Count: 1, Effect: deletion.EffectBlocked}}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBlock:  true,
+		},
+		{
+			name:      "cascade",
+			setupAuth: true,
+			projectID: projectID.String(),
+			query:     "?cascade=true",
+			setupMock: func() {
+				mockService.On("DeletionImpact", mock.Anything, userID, projectID, types.ChildrenCascade).
+					Return(deletion.Impact{Resource: "project", ID: projectID,
+						Affected: []deletion.Affected{{Kind: "projects", Count: 2, Effect: deletion.EffectTrashed}}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "cascade and detach",
+			setupAuth:      true,
+			projectID:      projectID.String(),
+			query:          "?cascade=true&detach=true",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "not found",
+			setupAuth: true,
+			projectID: projectID.String(),
+			setupMock: func() {
+				mockService.On("DeletionImpact", mock.Anything, userID, projectID, types.ChildrenRestrict).
+					Return(deletion.Impact{}, &coreErrors.ErrorResponse{Type: coreErrors.ErrorTypeNotFound, Message: "project(s) not found"})
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "invalid project ID",
+			setupAuth:      true,
+			projectID:      "invalid-uuid",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing auth",
+			setupAuth:      false,
+			projectID:      projectID.String(),
+			setupMock:      func() {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+
+			req := httptest.NewRequest(http.MethodGet, "/projects/"+tt.projectID+"/delete-preview"+tt.query, nil)
+			if tt.setupAuth {
+				ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
+				req = req.WithContext(ctx)
+			}
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.projectID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			tt.setupMock()
+			w := httptest.NewRecorder()
+			handler.PreviewProjectDeletion(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response struct {
+					Data deletion.Impact `json:"data"`
+				}
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				assert.Equal(t, projectID, response.Data.ID)
+				assert.Equal(t, tt.expectedBlock, response.Data.Blocked)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProjectHandler_GetProjectSummary(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	projectID := uuid.New()
+	balances := []types.CurrencyBalance{
+		{Currency: "EUR", Wallets: 1, Balance: 80},
+		{Currency: "JPY", Wallets: 1, Balance: 12000},
+		{Currency: "USD", Wallets: 2, Balance: 150.25},
+	}
+	summary := types.ProjectSummary{ProjectID: projectID, Projects: 1, Wallets: 4, Balances: balances}
+
+	tests := []struct {
+		name             string
+		query            string
+		setupMock        func()
+		expectedStatus   int
+		expectedWarnings []interface{}
+		checkData        func(t *testing.T, data map[string]interface{})
+	}{
+		{
+			name: "balances per currency",
+			setupMock: func() {
+				mockService.On("GetProjectSummary", mock.Anything, userID, projectID, types.ProjectSummaryParams{}).
+					Return(summary, nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkData: func(t *testing.T, data map[string]interface{}) {
+				assert.Equal(t, []interface{}{
+					map[string]interface{}{"currency": "EUR", "wallets": float64(1), "balance": float64(80)},
+					map[string]interface{}{"currency": "JPY", "wallets": float64(1), "balance": float64(12000)},
+					map[string]interface{}{"currency": "USD", "wallets": float64(2), "balance": 150.25},
+				}, data["balances"])
+				assert.NotContains(t, data, "converted")
+			},
+		},
+		{
+			name:  "converted",
+			query: "?rollup=true&convert_to=usd",
+			setupMock: func() {
+				converted := summary
+				converted.Rollup = true
+				converted.Converted = &types.ConvertedBalance{
+					Currency: "USD",
+					Balance:  316.65,
+					Rates:    map[string]float64{"EUR": 1.1, "JPY": 0.0067, "USD": 1},
+				}
+				mockService.On("GetProjectSummary", mock.Anything, userID, projectID, types.ProjectSummaryParams{Rollup: true, ConvertTo: "USD"}).
+					Return(converted, nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkData: func(t *testing.T, data map[string]interface{}) {
+				assert.Len(t, data["balances"], 3)
+				assert.Equal(t, map[string]interface{}{
+					"currency": "USD",
+					"balance":  316.65,
+					"rates":    map[string]interface{}{"EUR": 1.1, "JPY": 0.0067, "USD": float64(1)},
+				}, data["converted"])
+			},
+		},
+		{
+			name:  "rate unavailable",
+			query: "?convert_to=GBP",
+			setupMock: func() {
+				mockService.On("GetProjectSummary", mock.Anything, userID, projectID, types.ProjectSummaryParams{ConvertTo: "GBP"}).
+					Run(func(args mock.Arguments) {
+						requestcontext.AddWarning(args.Get(0).(context.Context), "convert_to: no exchange rate from JPY to GBP, the balances are not converted")
+					}).
+					Return(summary, nil)
+			},
+			expectedStatus:   http.StatusOK,
+			expectedWarnings: []interface{}{"convert_to: no exchange rate from JPY to GBP, the balances are not converted"},
+			checkData: func(t *testing.T, data map[string]interface{}) {
+				assert.Len(t, data["balances"], 3)
+				assert.NotContains(t, data, "converted")
+			},
+		},
+		{
+			name:           "invalid currency",
+			query:          "?convert_to=XYZ",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+
+			req := httptest.NewRequest(http.MethodGet, "/projects/"+projectID.String()+"/summary"+tt.query, nil)
+			ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", projectID.String())
+			ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+			req = req.WithContext(requestcontext.WithWarnings(ctx))
+
+			tt.setupMock()
+			w := httptest.NewRecorder()
+			handler.GetProjectSummary(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response map[string]interface{}
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				meta, _ := response["meta"].(map[string]interface{})
+				if tt.expectedWarnings == nil {
+					assert.NotContains(t, meta, "warnings")
+				} else {
+					assert.Equal(t, tt.expectedWarnings, meta["warnings"])
+				}
+				tt.checkData(t, response["data"].(map[string]interface{}))
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProjectHandler_PinProject(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	projectID := uuid.New()
+	pinnedAt := time.Now().UTC()
+
+	tests := []struct {
+		name           string
+		handle         http.HandlerFunc
+		setupMock      func()
+		expectedStatus int
+		expectedPinned bool
+	}{
+		{
+			name:   "pin",
+			handle: handler.PinProject,
+			setupMock: func() {
+				mockService.On("PinProject", mock.Anything, userID, projectID).
+					Return(types.Project{ProjectID: projectID, Name: "Test Project", Pinned: true, PinnedAt: coreTypes.TimestampPtr(&pinnedAt)}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedPinned: true,
+		},
+		{
+			name:   "too many pinned projects",
+			handle: handler.PinProject,
+			setupMock: func() {
+				mockService.On("PinProject", mock.Anything, userID, projectID).
+					Return(types.Project{}, coreErrors.NewValidationError("at most %d projects can be pinned, unpin one first", types.MaxPinnedProjects))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "unpin",
+			handle: handler.UnpinProject,
+			setupMock: func() {
+				mockService.On("UnpinProject", mock.Anything, userID, projectID).
+					Return(types.Project{ProjectID: projectID, Name: "Test Project"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "unpin a trashed project",
+			handle: handler.UnpinProject,
+			setupMock: func() {
+				mockService.On("UnpinProject", mock.Anything, userID, projectID).
+					Return(types.Project{}, &coreErrors.ErrorResponse{Type: coreErrors.ErrorTypeNotFound, Message: "project(s) not found"})
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+
+			req := httptest.NewRequest(http.MethodPost, "/projects/"+projectID.String()+"/pin", nil)
+			ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", projectID.String())
+			req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+
+			tt.setupMock()
+			w := httptest.NewRecorder()
+			tt.handle(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response struct {
+					Data types.Project `json:"data"`
+				}
+				assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				assert.Equal(t, tt.expectedPinned, response.Data.Pinned)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProjectHandler_CreateMilestone(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	projectID := uuid.New()
+
+	tests := []struct {
+		name           string
+		body           string
+		setupMock      func()
+		expectedStatus int
+	}{
+		{
+			name: "successful creation",
+			body: `{"name":"Foundations poured","dueDate":"2024-03-01T00:00:00Z"}`,
+			setupMock: func() {
+				mockService.On("CreateMilestone", mock.Anything, userID, projectID, mock.AnythingOfType("types.MilestoneCreatePayload")).
+					Return(types.Milestone{MilestoneID: uuid.New(), ProjectID: projectID, Name: "Foundations poured"}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "missing name",
+			body:           `{"dueDate":"2024-03-01T00:00:00Z"}`,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "too many milestones",
+			body: `{"name":"One too many"}`,
+			setupMock: func() {
+				mockService.On("CreateMilestone", mock.Anything, userID, projectID, mock.AnythingOfType("types.MilestoneCreatePayload")).
+					Return(types.Milestone{}, coreErrors.NewValidationError("a project can have at most %d milestones", types.MaxMilestones))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "project not found",
+			body: `{"name":"Foundations poured"}`,
+			setupMock: func() {
+				mockService.On("CreateMilestone", mock.Anything, userID, projectID, mock.AnythingOfType("types.MilestoneCreatePayload")).
+					Return(types.Milestone{}, &coreErrors.ErrorResponse{Type: coreErrors.ErrorTypeNotFound, Message: "project(s) not found"})
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+
+			req := httptest.NewRequest(http.MethodPost, "/projects/"+projectID.String()+"/milestones", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", projectID.String())
+			req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+
+			tt.setupMock()
+			w := httptest.NewRecorder()
+			handler.CreateMilestone(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProjectHandler_UpdateMilestone(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	projectID := uuid.New()
+	milestoneID := uuid.New()
+	existing := types.Milestone{MilestoneID: milestoneID, ProjectID: projectID, Name: "Foundations poured"}
+
+	mockService.On("GetMilestone", mock.Anything, userID, projectID, milestoneID).Return(existing, nil)
+	// only completed is sent, the name carries over from the existing milestone
+	mockService.On("UpdateMilestone", mock.Anything, userID, types.MilestoneUpdatePayload{
+		MilestoneID: milestoneID,
+		ProjectID:   projectID,
+		Name:        "Foundations poured",
+		Completed:   true,
+	}).Return(existing, nil)
+
+	req := httptest.NewRequest(http.MethodPut, "/projects/"+projectID.String()+"/milestones/"+milestoneID.String(), strings.NewReader(`{"completed":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", projectID.String())
+	rctx.URLParams.Add("milestoneId", milestoneID.String())
+	req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+
+	w := httptest.NewRecorder()
+	handler.UpdateMilestone(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestProjectHandler_ReorderMilestones(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	projectID := uuid.New()
+	first, second := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name           string
+		body           string
+		setupMock      func()
+		expectedStatus int
+	}{
+		{
+			name: "successful reorder",
+			body: fmt.Sprintf(`{"milestoneIds":["%s","%s"]}`, second, first),
+			setupMock: func() {
+				mockService.On("ReorderMilestones", mock.Anything, userID, projectID, []uuid.UUID{second, first}).
+					Return([]types.Milestone{
+						{MilestoneID: second, ProjectID: projectID, SortOrder: 0},
+						{MilestoneID: first, ProjectID: projectID, SortOrder: 1},
+					}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "empty order",
+			body:           `{"milestoneIds":[]}`,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "incomplete order",
+			body: fmt.Sprintf(`{"milestoneIds":["%s"]}`, first),
+			setupMock: func() {
+				mockService.On("ReorderMilestones", mock.Anything, userID, projectID, []uuid.UUID{first}).
+					Return(nil, coreErrors.NewValidationError("order must list all 2 milestones of the project"))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+
+			req := httptest.NewRequest(http.MethodPut, "/projects/"+projectID.String()+"/milestones/order", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", projectID.String())
+			req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+
+			tt.setupMock()
+			w := httptest.NewRecorder()
+			handler.ReorderMilestones(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProjectHandler_UpdateProject_BodyID(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	projectID := uuid.New()
+	existing := types.Project{ProjectID: projectID, Name: "Website", Status: "ongoing"}
+
+	tests := []struct {
+		name         string
+		payload      string
+		expectedType coreErrors.ErrorType
+	}{
+		{name: "matching ID", payload: `{"projectId": "` + projectID.String() + `", "name": "Webshop"}`},
+		{name: "nil ID", payload: `{"projectId": "00000000-0000-0000-0000-000000000000", "name": "Webshop"}`},
+		{name: "no ID", payload: `{"name": "Webshop"}`},
+		{name: "conflicting ID", payload: `{"projectId": "` + uuid.New().String() + `", "name": "Webshop"}`, expectedType: coreErrors.ErrorTypeIDMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			mockService.On("GetProject", mock.Anything, userID, projectID, types.ProjectExpand{}).Return(existing, nil)
+			if tt.expectedType == "" {
+				mockService.On("UpdateProject", mock.Anything, userID, mock.MatchedBy(func(payload types.ProjectUpdatePayload) bool {
+					return payload.ProjectID == projectID && payload.Name == "Webshop"
+				})).Return(existing, nil)
+			}
+
+			req := httptest.NewRequest(http.MethodPut, "/projects/"+projectID.String(), strings.NewReader(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", projectID.String())
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+
+			w := httptest.NewRecorder()
+			handler.UpdateProject(w, req)
+
+			if tt.expectedType == "" {
+				assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			} else {
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+				var response coreErrors.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				assert.Equal(t, tt.expectedType, response.Type)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProjectHandler_PayloadShape(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	projectID := uuid.New()
+
+	bodies := []struct {
+		name    string
+		payload string
+		create  bool
+	}{
+		{name: "array on create", payload: `[{"name": "Mass"}]`, create: true},
+		{name: "scalar on create", payload: `"Mass"`, create: true},
+		{name: "array on update", payload: `[{"name": "Mass"}, {"name": "Update"}]`},
+		{name: "empty array on update", payload: `[]`},
+		{name: "scalar on update", payload: `42`},
+		{name: "null on update", payload: `null`},
+		{name: "only unknown keys on update", payload: `{"fullName": "Mass", "colour": "red"}`},
+	}
+
+	for _, tt := range bodies {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			method, handle := http.MethodPost, handler.CreateProject
+			req := httptest.NewRequest(method, "/projects", strings.NewReader(tt.payload))
+			if !tt.create {
+				mockService.On("GetProject", mock.Anything, userID, projectID, types.ProjectExpand{}).Return(types.Project{ProjectID: projectID, Name: "Website", Status: "ongoing"}, nil)
+				method, handle = http.MethodPut, handler.UpdateProject
+				req = httptest.NewRequest(method, "/projects/"+projectID.String(), strings.NewReader(tt.payload))
+				rctx := chi.NewRouteContext()
+				rctx.URLParams.Add("id", projectID.String())
+				req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+
+			w := httptest.NewRecorder()
+			handle(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			var response coreErrors.ErrorResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+			assert.Equal(t, coreErrors.ErrorTypePayloadShape, response.Type)
+			mockService.AssertNotCalled(t, "CreateProject", mock.Anything, mock.Anything, mock.Anything)
+			mockService.AssertNotCalled(t, "UpdateProject", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
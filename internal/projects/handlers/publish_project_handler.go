@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// PublishProject godoc
+// @Summary Publish a draft Project
+// @Description Turns a draft Project into a live one, listed and counted in the budget rollups. The Project must have the fields live projects are configured to require, such as a start date or a budget. Publishing a live Project returns it unchanged.
+// @Tags Projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID" format(uuid)
+// @Success 200 {object} payloads.Response{data=types.Project}
+// @Header 200 {string} ETag "version of the project, for If-Match"
+// @Failure 400 {object} errors.ErrorResponse "The Project misses a field live projects require"
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /projects/{id}/publish [post]
+// @ID PublishProject
+func (h *ProjectHandler) PublishProject(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	projectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	project, err := h.service.PublishProject(r.Context(), userID, projectID)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	handlers.SetETag(w, project.UpdatedAt.Time)
+	h.Respond(w, r, payloads.Updated(project))
+}
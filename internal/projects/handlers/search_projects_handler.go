@@ -7,20 +7,28 @@ import (
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/ranking"
+	projectTypes "github.com/Abdelrahman-habib/expense-tracker/internal/projects/types"
 	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
 )
 
 // SearchProject godoc
 // @Summary Search project
-// @Description Searches for project based on a query string
+// @Description Searches for project based on a query string, drafts are left out unless include_drafts=true. With view=picker each project only carries its ID, name, status and end date. With a query each project is scored, and trim=auto drops the projects after the largest fall in score.
+// @Description The closest matches come first, projects scoring the same are ordered by the shorter name, then the newest first, so the order of a search never changes between requests.
 // @Tags Projects
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param q query string true "Search query" minLength(1) maxLength(100)
 // @Param limit query integer false "Maximum number of results" minimum(1) maximum(50) default(10)
-// @Success 200 {object} payloads.Response{data=[]types.Project}
-// @Failure 400 {object} errors.ErrorResponse
+// @Param next_token query string false "Token for the next page of results, paging stops at the configured search window"
+// @Param include_drafts query bool false "search the draft projects too"
+// @Param view query string false "Shape of the projects, picker returns only what a picker shows" Enums(full, picker) default(full)
+// @Param trim query string false "auto cuts the results after the largest fall in score, keeping at least the configured minimum" Enums(off, auto) default(off)
+// @Success 200 {object} payloads.Response{data=[]types.Project} "view=full"
+// @Success 200 {object} payloads.Response{data=[]projectTypes.ProjectPickerItem} "view=picker"
+// @Failure 400 {object} errors.ErrorResponse "Invalid parameters, or QUERY_TOO_COMPLEX for a query with too many distinct words or one that timed out"
 // @Failure 401  {object} errors.ErrorResponse
 // @Failure 429  {object} errors.ErrorResponse
 // @Failure 500  {object} errors.ErrorResponse
@@ -33,24 +41,75 @@ func (h *ProjectHandler) SearchProjects(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if !h.CheckQueryParams(w, r, projectTypes.SearchQueryParams...) {
+		return
+	}
+
 	// Parse query parameters
 	query := r.URL.Query()
-	params, err := types.ParseAndValidateSearchParams(query)
+	params, err := types.ParseTrigramSearchParams(query, h.limits)
+	if err != nil {
+		h.RespondInvalidSearch(w, r, err)
+		return
+	}
+	view, err := types.ParseView(query)
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+	trim, err := types.ParseTrim(query, view)
 	if err != nil {
 		h.RespondError(w, r, errors.ErrInvalidRequest(err))
 		return
 	}
+	if !h.CheckSearchWindow(w, r, &params) {
+		return
+	}
+	ctx, cancel := h.SearchContext(r)
+	defer cancel()
 
-	projects, err := h.service.SearchProjects(r.Context(), userID, params.Query, params.Limit)
+	includeDrafts := query.Get("include_drafts") == "true"
+	if view == types.ViewPicker {
+		items, err := h.service.SearchProjectsPicker(ctx, userID, params.Query, includeDrafts, params.Limit, params.Offset)
+		if err != nil {
+			h.HandleSearchError(w, r, ctx, err)
+			return
+		}
+		h.Respond(w, r, payloads.PaginatedSearchView(
+			items,
+			params.Query,
+			params.Limit,
+			len(items),
+			params.NextToken(len(items)),
+			view,
+		))
+		return
+	}
+
+	projects, err := h.service.SearchProjects(ctx, userID, params.Query, includeDrafts, params.Limit, params.Offset)
 	if err != nil {
-		h.HandleServiceError(w, r, err)
+		h.HandleSearchError(w, r, ctx, err)
 		return
 	}
 
-	h.Respond(w, r, payloads.Search(
+	nextToken := params.NextToken(len(projects))
+	var cut *ranking.Trim
+	if trim {
+		projects, cut = ranking.TrimAtCliff(projects, int(h.limits.MinTrimmedResults), func(project projectTypes.Project) *float32 {
+			return project.Score
+		})
+	}
+
+	resp := payloads.PaginatedSearchView(
 		projects,
 		params.Query,
 		params.Limit,
 		len(projects),
-	))
+		nextToken,
+		view,
+	)
+	if cut != nil {
+		resp = payloads.WithTrim(resp, cut.Trimmed, cut.Cutoff)
+	}
+	h.Respond(w, r, resp)
 }
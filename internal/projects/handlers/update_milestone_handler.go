@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// UpdateMilestone godoc
+// @Summary Update a project milestone
+// @Description Updates a milestone, setting completed marks it done or reopens it
+// @Tags Projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "project ID" format(uuid)
+// @Param milestoneId path string true "milestone ID" format(uuid)
+// @Param request body types.MilestoneUpdatePayload true "milestone update request"
+// @Success 200 {object} payloads.Response{data=types.Milestone}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /projects/{id}/milestones/{milestoneId} [put]
+// @ID UpdateMilestone
+func (h *ProjectHandler) UpdateMilestone(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	projectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	milestoneID, err := uuid.Parse(chi.URLParam(r, "milestoneId"))
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	// Get existing milestone first
+	existingMilestone, err := h.service.GetMilestone(r.Context(), userID, projectID, milestoneID)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	// Create update payload from existing milestone
+	updatePayload := existingMilestone.ToUpdatePayload()
+
+	if !h.BindUpdate(w, r, &updatePayload) {
+		return
+	}
+
+	milestone, err := h.service.UpdateMilestone(r.Context(), userID, updatePayload)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.Updated(milestone))
+}
@@ -1,19 +1,21 @@
 package handlers
 
 import (
+	stdErrors "errors"
 	"net/http"
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/projects/types"
 	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/render"
 	"github.com/google/uuid"
 )
 
 // UpdateProject godoc
 // @Summary Update a project
-// @Description Updates an existing project
+// @Description Updates an existing project. The URL names the project, a projectId in the body naming another one is refused with ID_MISMATCH. Concurrent updates of a project apply one after the other.
 // @Tags Projects
 // @Accept json
 // @Produce json
@@ -21,9 +23,11 @@ import (
 // @Param id path string true "project ID" format(uuid)
 // @Param request body types.ProjectUpdatePayload true "project update request"
 // @Success 200 {object} payloads.Response{data=types.Project}
+// @Header 200 {string} ETag "version of the project, for If-Match"
 // @Failure 400 {object} errors.ErrorResponse
 // @Failure 401 {object} errors.ErrorResponse
 // @Failure 404 {object} errors.ErrorResponse
+// @Failure 409 {object} errors.ErrorResponse "CONCURRENT_MODIFICATION when another update of the project didn't finish in time"
 // @Failure 429 {object} errors.ErrorResponse
 // @Failure 500 {object} errors.ErrorResponse
 // @Router /projects/{id} [put]
@@ -41,27 +45,24 @@ func (h *ProjectHandler) UpdateProject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get existing project first
-	existingProject, err := h.service.GetProject(r.Context(), userID, projectID)
-	if err != nil {
-		h.HandleServiceError(w, r, err)
-		return
-	}
-
-	// Create update payload from existing project
-	updatePayload := existingProject.ToUpdatePayload()
-
-	// Use render.Bind to decode and validate
-	if err := render.Bind(r, &updatePayload); err != nil {
-		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+	// Decode and validate onto the current values, read under the project's lock so a
+	// concurrent update can't change them before this one is written
+	project, err := h.service.UpdateProject(r.Context(), userID, projectID, func(existing types.Project) (types.ProjectUpdatePayload, error) {
+		updatePayload := existing.ToUpdatePayload()
+		// the URL names the project
+		if !h.BindEntityUpdate(w, r, &updatePayload, "projectId", projectID, &updatePayload.ProjectID) {
+			return types.ProjectUpdatePayload{}, handlers.ErrResponded
+		}
+		return updatePayload, nil
+	})
+	if stdErrors.Is(err, handlers.ErrResponded) {
 		return
 	}
-
-	project, err := h.service.UpdateProject(r.Context(), userID, updatePayload)
 	if err != nil {
 		h.HandleServiceError(w, r, err)
 		return
 	}
 
+	handlers.SetETag(w, project.UpdatedAt.Time)
 	h.Respond(w, r, payloads.Updated(project))
 }
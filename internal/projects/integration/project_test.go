@@ -10,6 +10,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -17,6 +18,7 @@ import (
 	"time"
 
 	"github.com/Abdelrahman-habib/expense-tracker/config"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/deletion"
 	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/projects/handlers"
@@ -26,6 +28,7 @@ import (
 	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/pressly/goose/v3"
@@ -145,8 +148,8 @@ func (s *ProjectIntegrationTestSuite) SetupSuite() {
 	// Initialize components
 	logger := zap.NewNop()
 	repo := repository.NewProjectRepository(dbService.Queries())
-	projectService := service.NewProjectService(repo, logger)
-	s.handler = handlers.NewProjectHandler(projectService, logger)
+	projectService := service.NewProjectService(repo, types.PublishRules{}, nil, "", nil, nil, logger)
+	s.handler = handlers.NewProjectHandler(projectService, coreTypes.DefaultLimitPolicy(), logger)
 
 	// Setup router
 	router := chi.NewRouter()
@@ -159,6 +162,16 @@ func (s *ProjectIntegrationTestSuite) SetupSuite() {
 			r.Get("/", s.handler.GetProject)
 			r.Put("/", s.handler.UpdateProject)
 			r.Delete("/", s.handler.DeleteProject)
+			r.Get("/delete-preview", s.handler.PreviewProjectDeletion)
+			r.Post("/clone", s.handler.CloneProject)
+			r.Post("/publish", s.handler.PublishProject)
+			r.Route("/milestones", func(r chi.Router) {
+				r.Get("/", s.handler.ListMilestones)
+				r.Post("/", s.handler.CreateMilestone)
+				r.Put("/order", s.handler.ReorderMilestones)
+				r.Put("/{milestoneId}", s.handler.UpdateMilestone)
+				r.Delete("/{milestoneId}", s.handler.DeleteMilestone)
+			})
 		})
 	})
 	s.router = router
@@ -206,7 +219,7 @@ func (s *ProjectIntegrationTestSuite) createTestProject() types.Project {
 		Name:        "Integration Test Project",
 		Description: stringPtr("Test Description"),
 		Status:      "ongoing",
-		StartDate:   timePtr(time.Now()),
+		StartDate:   datePtr(time.Now()),
 		Budget:      float64Ptr(1000.50),
 	}
 
@@ -372,8 +385,8 @@ func stringPtr(s string) *string {
 	return &s
 }
 
-func timePtr(t time.Time) *time.Time {
-	return &t
+func datePtr(t time.Time) *coreTypes.Date {
+	return coreTypes.DatePtr(&t)
 }
 
 func float64Ptr(f float64) *float64 {
@@ -413,19 +426,20 @@ func (s *ProjectIntegrationTestSuite) createTestProjects(count int) []types.Proj
 
 		projectData := response["data"].(map[string]interface{})
 
-		// Parse time in UTC
-		createdAtStr := projectData["createdAt"].(string)
-		createdAt, err := time.Parse(time.RFC3339Nano, createdAtStr)
+		projectID := uuid.MustParse(projectData["projectId"].(string))
+		_, err = time.Parse(coreTypes.TimestampLayout, projectData["createdAt"].(string))
 		s.Require().NoError(err)
 
-		// Ensure it's in UTC
-		createdAt = createdAt.UTC()
+		// responses carry createdAt to the millisecond, cursors are built from the exact one
+		var createdAt time.Time
+		err = s.pool.QueryRow(s.ctx, "SELECT created_at FROM projects WHERE project_id = $1", projectID).Scan(&createdAt)
+		s.Require().NoError(err)
 
 		projects[count-1-i] = types.Project{
-			ProjectID: uuid.MustParse(projectData["projectId"].(string)),
+			ProjectID: projectID,
 			Name:      projectData["name"].(string),
 			Status:    projectData["status"].(string),
-			CreatedAt: createdAt,
+			CreatedAt: coreTypes.NewTimestamp(createdAt),
 		}
 		time.Sleep(time.Millisecond * 10)
 	}
@@ -462,7 +476,7 @@ func (s *ProjectIntegrationTestSuite) TestListProjectsPaginated() {
 			name: "with next_token", // Using Project 6's cursor: Gets next newer records (5,4,3)
 			queryParams: map[string]string{
 				"limit":      "3",
-				"next_token": coreTypes.EncodeCursor(projects[4].CreatedAt, projects[4].ProjectID), // Project 6
+				"next_token": coreTypes.EncodeCursor(projects[4].CreatedAt.Time, projects[4].ProjectID, s.userID), // Project 6
 			},
 			expectedStatus:  http.StatusOK,
 			expectedLen:     3,
@@ -473,13 +487,35 @@ func (s *ProjectIntegrationTestSuite) TestListProjectsPaginated() {
 			name: "last page", // Using Project 3's cursor: Gets final records (2,1)
 			queryParams: map[string]string{
 				"limit":      "5",
-				"next_token": coreTypes.EncodeCursor(projects[7].CreatedAt, projects[7].ProjectID), // Project 3
+				"next_token": coreTypes.EncodeCursor(projects[7].CreatedAt.Time, projects[7].ProjectID, s.userID), // Project 3
 			},
 			expectedStatus:  http.StatusOK,
 			expectedLen:     2,
 			expectedLimit:   "5",
 			expectNextToken: false,
 		},
+		{
+			name: "ascending first page", // Oldest first: Gets (1,2,3,4)
+			queryParams: map[string]string{
+				"limit": "4",
+				"order": "asc",
+			},
+			expectedStatus:  http.StatusOK,
+			expectedLen:     4,
+			expectedLimit:   "4",
+			expectNextToken: true,
+		},
+		{
+			name: "ascending with next_token", // Using Project 4's cursor: Gets newer records (5..10)
+			queryParams: map[string]string{
+				"limit":      "10",
+				"next_token": coreTypes.EncodeOrderedCursor(projects[6].CreatedAt.Time, projects[6].ProjectID, coreTypes.SortOrderAsc, s.userID), // Project 4
+			},
+			expectedStatus:  http.StatusOK,
+			expectedLen:     6,
+			expectedLimit:   "10",
+			expectNextToken: false,
+		},
 		{
 			name: "invalid next_token",
 			queryParams: map[string]string{
@@ -837,7 +873,7 @@ func (s *ProjectIntegrationTestSuite) TestComplexProjectLifecycle() {
 			Name:      "Lifecycle Project",
 			Status:    "ongoing",
 			Budget:    float64Ptr(1000),
-			StartDate: timePtr(time.Now()),
+			StartDate: datePtr(time.Now()),
 		}
 
 		payloadBytes, err := json.Marshal(createPayload)
@@ -870,7 +906,7 @@ func (s *ProjectIntegrationTestSuite) TestComplexProjectLifecycle() {
 				ProjectID: uuid.MustParse(projectID),
 				Name:      "Updated Name",
 				Status:    "completed",
-				EndDate:   timePtr(time.Now().Add(24 * time.Hour)),
+				EndDate:   datePtr(time.Now().Add(24 * time.Hour)),
 			},
 		}
 
@@ -915,6 +951,91 @@ func (s *ProjectIntegrationTestSuite) TestComplexProjectLifecycle() {
 	})
 }
 
+func (s *ProjectIntegrationTestSuite) TestPinnedProjectsPagination() {
+	s.clearProjects()
+	projects := s.createTestProjects(7) // newest first
+
+	// pin three projects from the middle and the ends, the last pinned is listed first
+	var pinned []string
+	for _, i := range []int{3, 0, 6} {
+		code, response := s.serveJSON(http.MethodPost, "/projects/"+projects[i].ProjectID.String()+"/pin", nil)
+		s.Require().Equal(http.StatusOK, code)
+		s.True(response["data"].(map[string]interface{})["pinned"].(bool))
+		pinned = append([]string{projects[i].ProjectID.String()}, pinned...)
+		time.Sleep(10 * time.Millisecond) // distinct pin times
+	}
+
+	var newestFirst, oldestFirst []string
+	for _, p := range projects {
+		if !slices.Contains(pinned, p.ProjectID.String()) {
+			newestFirst = append(newestFirst, p.ProjectID.String())
+			oldestFirst = append([]string{p.ProjectID.String()}, oldestFirst...)
+		}
+	}
+
+	pageAll := func(order string, limit int) []string {
+		var ids []string
+		token := ""
+		for pages := 0; pages < 10; pages++ {
+			values := url.Values{"limit": {fmt.Sprint(limit)}}
+			if token != "" {
+				values.Set("next_token", token)
+			} else {
+				values.Set("order", order)
+			}
+			code, response := s.serveJSON(http.MethodGet, "/projects/paginated?"+values.Encode(), nil)
+			s.Require().Equal(http.StatusOK, code)
+			for _, item := range response["data"].([]interface{}) {
+				ids = append(ids, item.(map[string]interface{})["projectId"].(string))
+			}
+			token, _ = response["meta"].(map[string]interface{})["next_token"].(string)
+			if token == "" {
+				return ids
+			}
+		}
+		s.FailNow("pagination didn't end")
+		return nil
+	}
+
+	// page sizes ending right on, before and after the last pinned project
+	for _, limit := range []int{1, 2, 3, 4} {
+		s.Equal(append(slices.Clone(pinned), newestFirst...), pageAll("desc", limit), "desc with limit %d", limit)
+		s.Equal(append(slices.Clone(pinned), oldestFirst...), pageAll("asc", limit), "asc with limit %d", limit)
+	}
+
+	// unpinning moves the project back to its place
+	code, _ := s.serveJSON(http.MethodPost, "/projects/"+projects[0].ProjectID.String()+"/unpin", nil)
+	s.Require().Equal(http.StatusOK, code)
+	ids := pageAll("desc", 3)
+	s.Equal([]string{projects[6].ProjectID.String(), projects[3].ProjectID.String(), projects[0].ProjectID.String()}, ids[:3])
+	s.Len(ids, 7)
+}
+
+func (s *ProjectIntegrationTestSuite) TestPinnedProjectsCap() {
+	s.clearProjects()
+	projects := s.createTestProjects(types.MaxPinnedProjects + 1)
+
+	for _, p := range projects[:types.MaxPinnedProjects] {
+		code, _ := s.serveJSON(http.MethodPost, "/projects/"+p.ProjectID.String()+"/pin", nil)
+		s.Require().Equal(http.StatusOK, code)
+	}
+
+	last := projects[types.MaxPinnedProjects].ProjectID.String()
+	code, response := s.serveJSON(http.MethodPost, "/projects/"+last+"/pin", nil)
+	s.Equal(http.StatusBadRequest, code)
+	s.Contains(response["message"], "at most 10 projects can be pinned")
+
+	// pinning a pinned project doesn't count against the cap
+	code, _ = s.serveJSON(http.MethodPost, "/projects/"+projects[0].ProjectID.String()+"/pin", nil)
+	s.Equal(http.StatusOK, code)
+
+	// trashing a pinned project frees its slot
+	code, _ = s.serveJSON(http.MethodDelete, "/projects/"+projects[0].ProjectID.String(), nil)
+	s.Require().Equal(http.StatusOK, code)
+	code, _ = s.serveJSON(http.MethodPost, "/projects/"+last+"/pin", nil)
+	s.Equal(http.StatusOK, code)
+}
+
 func (s *ProjectIntegrationTestSuite) TestPaginationEdgeCases() {
 	// Test extreme pagination cases
 	s.Run("pagination edge cases", func() {
@@ -1076,10 +1197,10 @@ func (s *ProjectIntegrationTestSuite) TestResponsePayloadStructure() {
 			Name:        "Response Test Project",
 			Description: stringPtr("Test Description"),
 			Status:      "ongoing",
-			StartDate:   timePtr(time.Now().UTC()),
+			StartDate:   datePtr(time.Now().UTC()),
 			Budget:      float64Ptr(1000.50),
 			Website:     stringPtr("https://example.com"),
-			Tags:        []uuid.UUID{uuid.New(), uuid.New()},
+			Tags:        s.createTestTags(2),
 		}
 
 		payloadBytes, err := json.Marshal(createPayload)
@@ -1122,9 +1243,9 @@ func (s *ProjectIntegrationTestSuite) TestResponsePayloadStructure() {
 		s.Equal(*createPayload.Budget, data["budget"])
 
 		// Verify timestamps are in correct format
-		_, err = time.Parse(time.RFC3339, data["createdAt"].(string))
+		_, err = time.Parse(coreTypes.TimestampLayout, data["createdAt"].(string))
 		s.NoError(err)
-		_, err = time.Parse(time.RFC3339, data["updatedAt"].(string))
+		_, err = time.Parse(coreTypes.TimestampLayout, data["updatedAt"].(string))
 		s.NoError(err)
 
 		// Verify tags array
@@ -1136,3 +1257,534 @@ func (s *ProjectIntegrationTestSuite) TestResponsePayloadStructure() {
 		}
 	})
 }
+
+// createTestTags creates tags owned by the test user; tags the user doesn't own are dropped on write
+func (s *ProjectIntegrationTestSuite) createTestTags(count int) []uuid.UUID {
+	tags := make([]uuid.UUID, count)
+	for i := range tags {
+		err := s.pool.QueryRow(s.ctx, `
+			INSERT INTO tags (user_id, name) VALUES ($1, $2) RETURNING tag_id
+		`, s.userID, fmt.Sprintf("test tag %d", i)).Scan(&tags[i])
+		s.Require().NoError(err)
+	}
+	return tags
+}
+
+// serveJSON sends an authenticated JSON request through the router and decodes the response
+func (s *ProjectIntegrationTestSuite) serveJSON(method, path string, payload interface{}) (int, map[string]interface{}) {
+	var body io.Reader
+	if payload != nil {
+		payloadBytes, err := json.Marshal(payload)
+		s.Require().NoError(err)
+		body = bytes.NewReader(payloadBytes)
+	}
+
+	req := s.newAuthenticatedRequest(method, path, body)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+
+	var response map[string]interface{}
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+	return w.Code, response
+}
+
+func (s *ProjectIntegrationTestSuite) createTestMilestones(projectID uuid.UUID, names ...string) []uuid.UUID {
+	ids := make([]uuid.UUID, len(names))
+	for i, name := range names {
+		code, response := s.serveJSON(http.MethodPost, "/projects/"+projectID.String()+"/milestones",
+			types.MilestoneCreatePayload{Name: name})
+		s.Require().Equal(http.StatusCreated, code)
+		data := response["data"].(map[string]interface{})
+		s.Equal(float64(i), data["sortOrder"])
+		ids[i] = uuid.MustParse(data["milestoneId"].(string))
+	}
+	return ids
+}
+
+func (s *ProjectIntegrationTestSuite) TestReorderMilestones() {
+	project := s.createTestProject()
+	milestonesPath := "/projects/" + project.ProjectID.String() + "/milestones"
+	ids := s.createTestMilestones(project.ProjectID, "Design", "Build", "Ship")
+
+	s.Run("applies the new order", func() {
+		order := []uuid.UUID{ids[2], ids[0], ids[1]}
+		code, response := s.serveJSON(http.MethodPut, milestonesPath+"/order", types.MilestoneOrderPayload{MilestoneIDs: order})
+		s.Require().Equal(http.StatusOK, code)
+		s.Len(response["data"], 3)
+
+		code, response = s.serveJSON(http.MethodGet, milestonesPath, nil)
+		s.Require().Equal(http.StatusOK, code)
+		listed := response["data"].([]interface{})
+		s.Require().Len(listed, 3)
+		for i, item := range listed {
+			milestone := item.(map[string]interface{})
+			s.Equal(order[i].String(), milestone["milestoneId"])
+			s.Equal(float64(i), milestone["sortOrder"])
+		}
+	})
+
+	s.Run("rejects incomplete and foreign orders", func() {
+		for _, order := range [][]uuid.UUID{
+			{ids[0], ids[1]},
+			{ids[0], ids[1], uuid.New()},
+			{ids[0], ids[1], ids[1]},
+		} {
+			code, _ := s.serveJSON(http.MethodPut, milestonesPath+"/order", types.MilestoneOrderPayload{MilestoneIDs: order})
+			s.Equal(http.StatusBadRequest, code)
+		}
+
+		// the last successful order is untouched
+		_, response := s.serveJSON(http.MethodGet, milestonesPath, nil)
+		first := response["data"].([]interface{})[0].(map[string]interface{})
+		s.Equal(ids[2].String(), first["milestoneId"])
+	})
+
+	s.Run("new milestones go last", func() {
+		added := s.createTestMilestones(project.ProjectID, "Retro")
+		_, response := s.serveJSON(http.MethodGet, milestonesPath, nil)
+		listed := response["data"].([]interface{})
+		s.Require().Len(listed, 4)
+		s.Equal(added[0].String(), listed[3].(map[string]interface{})["milestoneId"])
+	})
+}
+
+func (s *ProjectIntegrationTestSuite) TestMilestoneProgress() {
+	project := s.createTestProject()
+	projectPath := "/projects/" + project.ProjectID.String()
+
+	progress := func() interface{} {
+		code, response := s.serveJSON(http.MethodGet, projectPath, nil)
+		s.Require().Equal(http.StatusOK, code)
+		data := response["data"].(map[string]interface{})
+		s.Require().Contains(data, "progress")
+		return data["progress"]
+	}
+	setCompleted := func(milestoneID uuid.UUID, completed bool) map[string]interface{} {
+		code, response := s.serveJSON(http.MethodPut, projectPath+"/milestones/"+milestoneID.String(),
+			map[string]interface{}{"completed": completed})
+		s.Require().Equal(http.StatusOK, code)
+		return response["data"].(map[string]interface{})
+	}
+
+	s.Nil(progress(), "null without milestones")
+
+	ids := s.createTestMilestones(project.ProjectID, "Design", "Build", "Ship", "Retro")
+	s.Equal(0.0, progress())
+
+	milestone := setCompleted(ids[0], true)
+	s.NotEmpty(milestone["completedAt"])
+	s.Equal("Design", milestone["name"], "unsent fields keep their value")
+	setCompleted(ids[1], true)
+	s.Equal(0.5, progress())
+
+	setCompleted(ids[1], false)
+	s.Equal(0.25, progress())
+
+	// the rollup shows on listings too
+	_, response := s.serveJSON(http.MethodGet, "/projects", nil)
+	listed := response["data"].([]interface{})
+	s.Require().Len(listed, 1)
+	s.Equal(0.25, listed[0].(map[string]interface{})["progress"])
+
+	code, _ := s.serveJSON(http.MethodDelete, projectPath+"/milestones/"+ids[0].String(), nil)
+	s.Require().Equal(http.StatusOK, code)
+	s.Equal(0.0, progress())
+}
+
+func (s *ProjectIntegrationTestSuite) TestMilestoneValidation() {
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2030, 6, 30, 0, 0, 0, 0, time.UTC)
+	code, response := s.serveJSON(http.MethodPost, "/projects", types.ProjectCreatePayload{
+		Name:      "Windowed Project",
+		Status:    "ongoing",
+		StartDate: datePtr(start),
+		EndDate:   datePtr(end),
+	})
+	s.Require().Equal(http.StatusCreated, code)
+	milestonesPath := "/projects/" + response["data"].(map[string]interface{})["projectId"].(string) + "/milestones"
+
+	s.Run("due date within the project window", func() {
+		for _, tt := range []struct {
+			dueDate time.Time
+			code    int
+		}{
+			{dueDate: start, code: http.StatusCreated},
+			{dueDate: end, code: http.StatusCreated},
+			{dueDate: start.Add(-time.Hour), code: http.StatusBadRequest},
+			// project dates are days, a milestone due later on the end date is within them
+			{dueDate: end.Add(time.Hour), code: http.StatusCreated},
+			{dueDate: end.AddDate(0, 0, 1), code: http.StatusBadRequest},
+		} {
+			code, _ := s.serveJSON(http.MethodPost, milestonesPath, types.MilestoneCreatePayload{Name: "Due", DueDate: &tt.dueDate})
+			s.Equal(tt.code, code, tt.dueDate)
+		}
+	})
+
+	s.Run("at most 100 milestones", func() {
+		_, response := s.serveJSON(http.MethodGet, milestonesPath, nil)
+		for i := len(response["data"].([]interface{})); i < types.MaxMilestones; i++ {
+			code, _ := s.serveJSON(http.MethodPost, milestonesPath, types.MilestoneCreatePayload{Name: fmt.Sprintf("Milestone %d", i)})
+			s.Require().Equal(http.StatusCreated, code)
+		}
+		code, _ := s.serveJSON(http.MethodPost, milestonesPath, types.MilestoneCreatePayload{Name: "One too many"})
+		s.Equal(http.StatusBadRequest, code)
+	})
+
+	s.Run("trashed project hides its milestones", func() {
+		projectPath := strings.TrimSuffix(milestonesPath, "/milestones")
+		code, _ := s.serveJSON(http.MethodDelete, projectPath, nil)
+		s.Require().Equal(http.StatusOK, code)
+
+		code, _ = s.serveJSON(http.MethodGet, milestonesPath, nil)
+		s.Equal(http.StatusNotFound, code)
+	})
+}
+
+func (s *ProjectIntegrationTestSuite) createSubProject(name string, parentID *uuid.UUID) uuid.UUID {
+	code, response := s.serveJSON(http.MethodPost, "/projects",
+		types.ProjectCreatePayload{Name: name, Status: "ongoing", ParentProjectID: parentID})
+	s.Require().Equal(http.StatusCreated, code, response)
+	return uuid.MustParse(response["data"].(map[string]interface{})["projectId"].(string))
+}
+
+func (s *ProjectIntegrationTestSuite) previewDeletion(projectID uuid.UUID, query string) (int, deletion.Impact) {
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, s.newAuthenticatedRequest(http.MethodGet, "/projects/"+projectID.String()+"/delete-preview"+query, nil))
+	var response struct {
+		Data deletion.Impact `json:"data"`
+	}
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+	return w.Code, response.Data
+}
+
+// affected indexes the counts of an impact by kind and effect
+func affected(impact deletion.Impact) map[string]int64 {
+	counts := make(map[string]int64, len(impact.Affected))
+	for _, a := range impact.Affected {
+		counts[a.Kind+":"+string(a.Effect)] = a.Count
+	}
+	return counts
+}
+
+func (s *ProjectIntegrationTestSuite) TestDeletionPreviewMatchesTheDelete() {
+	root := s.createSubProject("Preview Root", nil)
+	child := s.createSubProject("Preview Child", &root)
+	grandchild := s.createSubProject("Preview Grandchild", &child)
+	other := s.createSubProject("Preview Other", nil)
+
+	s.createTestMilestones(root, "Design", "Build")
+	s.createTestMilestones(child, "Ship")
+	s.createTestMilestones(grandchild, "Launch")
+	s.createTestMilestones(other, "Unrelated")
+	for _, wallet := range []struct {
+		projectID uuid.UUID
+		balance   float64
+		currency  string
+	}{
+		{root, 100, "USD"},
+		{child, 50, "USD"},
+		{grandchild, 10, "EUR"},
+		{other, 1, "USD"},
+	} {
+		_, err := s.pool.Exec(s.ctx, `
+			INSERT INTO wallets (user_id, project_id, name, balance, currency)
+			VALUES ($1, $2, 'Preview Wallet', $3, $4)
+		`, s.userID, wallet.projectID, wallet.balance, wallet.currency)
+		s.Require().NoError(err)
+	}
+
+	s.Run("sub-projects block a plain delete", func() {
+		code, impact := s.previewDeletion(root, "")
+		s.Require().Equal(http.StatusOK, code)
+		s.True(impact.Blocked)
+		s.Equal([]string{"project has 1 sub-project(s), delete with cascade=true or detach=true"}, impact.Reasons)
+		s.Equal(int64(1), affected(impact)["subProjects:blocked"])
+
+		code, response := s.serveJSON(http.MethodDelete, "/projects/"+root.String(), nil)
+		s.Equal(http.StatusConflict, code)
+		s.Contains(response["error"], impact.Reasons[0])
+	})
+
+	s.Run("detach only trashes the project", func() {
+		code, impact := s.previewDeletion(child, "?detach=true")
+		s.Require().Equal(http.StatusOK, code)
+		s.False(impact.Blocked)
+		s.Equal(map[string]int64{
+			"projects:trashed":     1,
+			"wallets:detached":     1,
+			"subProjects:detached": 1,
+			"milestones:kept":      1,
+		}, affected(impact))
+	})
+
+	s.Run("cascade counts match the rows it trashes", func() {
+		code, impact := s.previewDeletion(root, "?cascade=true")
+		s.Require().Equal(http.StatusOK, code)
+		s.False(impact.Blocked)
+		counts := affected(impact)
+		s.Equal(map[string]int64{"projects:trashed": 3, "wallets:detached": 3, "milestones:kept": 4}, counts)
+
+		code, _ = s.serveJSON(http.MethodDelete, "/projects/"+root.String()+"?cascade=true", nil)
+		s.Require().Equal(http.StatusOK, code)
+
+		var projects, wallets, milestones int64
+		err := s.pool.QueryRow(s.ctx, `
+			SELECT
+				(SELECT COUNT(*) FROM projects WHERE user_id = $1 AND deleted_at IS NOT NULL),
+				(SELECT COUNT(*) FROM wallets w JOIN projects p ON p.project_id = w.project_id
+				 WHERE p.user_id = $1 AND p.deleted_at IS NOT NULL),
+				(SELECT COUNT(*) FROM milestones m JOIN projects p ON p.project_id = m.project_id
+				 WHERE p.user_id = $1 AND p.deleted_at IS NOT NULL)
+		`, s.userID).Scan(&projects, &wallets, &milestones)
+		s.Require().NoError(err)
+		s.Equal(counts["projects:trashed"], projects)
+		s.Equal(counts["wallets:detached"], wallets)
+		s.Equal(counts["milestones:kept"], milestones)
+	})
+
+	s.Run("a trashed project has nothing left to preview", func() {
+		code, _ := s.previewDeletion(root, "")
+		s.Equal(http.StatusNotFound, code)
+	})
+
+	s.Run("purging the projects detaches their wallets", func() {
+		_, err := s.service.Queries().PurgeDeletedProjects(s.ctx, db.PurgeDeletedProjectsParams{
+			DeletedBefore: pgtype.Timestamp{Time: time.Now().Add(time.Hour), Valid: true},
+			BatchSize:     100,
+		})
+		s.Require().NoError(err)
+
+		var detached, attached int64
+		err = s.pool.QueryRow(s.ctx, `
+			SELECT
+				COUNT(*) FILTER (WHERE project_id IS NULL),
+				COUNT(*) FILTER (WHERE project_id = $2)
+			FROM wallets WHERE user_id = $1 AND deleted_at IS NULL
+		`, s.userID, other).Scan(&detached, &attached)
+		s.Require().NoError(err)
+		s.Equal(int64(3), detached)
+		s.Equal(int64(1), attached)
+	})
+}
+
+func (s *ProjectIntegrationTestSuite) TestTrashingTheDefaultProjectClearsIt() {
+	root := s.createSubProject("Default Root", nil)
+	child := s.createSubProject("Default Child", &root)
+
+	_, err := s.service.Queries().SetUserDefaults(s.ctx, db.SetUserDefaultsParams{
+		UserID:           s.userID,
+		DefaultProjectID: pgtype.UUID{Bytes: child, Valid: true},
+	})
+	s.Require().NoError(err)
+
+	// trashing the tree clears the default, sub-projects included
+	code, _ := s.serveJSON(http.MethodDelete, "/projects/"+root.String()+"?cascade=true", nil)
+	s.Require().Equal(http.StatusOK, code)
+
+	var defaultProject pgtype.UUID
+	s.Require().NoError(s.pool.QueryRow(s.ctx, "SELECT default_project_id FROM users WHERE user_id = $1", s.userID).Scan(&defaultProject))
+	s.False(defaultProject.Valid)
+}
+
+func (s *ProjectIntegrationTestSuite) TestCloneProject() {
+	tags := s.createTestTags(2)
+	code, response := s.serveJSON(http.MethodPost, "/projects", types.ProjectCreatePayload{
+		Name:        "Template",
+		Description: stringPtr("Monthly close"),
+		Status:      "ongoing",
+		Budget:      float64Ptr(500),
+		Tags:        tags,
+	})
+	s.Require().Equal(http.StatusCreated, code, response)
+	source := uuid.MustParse(response["data"].(map[string]interface{})["projectId"].(string))
+
+	for _, wallet := range []struct {
+		name    string
+		balance float64
+		trashed bool
+	}{
+		{"Template Cash", 120, false},
+		{"Template Card", 0, false},
+		{"Template Old", 30, true},
+	} {
+		_, err := s.pool.Exec(s.ctx, `
+			INSERT INTO wallets (user_id, project_id, name, balance, currency, tags, deleted_at)
+			VALUES ($1, $2, $3, $4, 'USD', $5, CASE WHEN $6::bool THEN CURRENT_TIMESTAMP END)
+		`, s.userID, source, wallet.name, wallet.balance, tags[:1], wallet.trashed)
+		s.Require().NoError(err)
+	}
+
+	cloneWallets := func(projectID string) map[string]float64 {
+		rows, err := s.pool.Query(s.ctx, `
+			SELECT name, COALESCE(balance, 0)::float8 FROM wallets
+			WHERE project_id = $1 AND deleted_at IS NULL
+		`, projectID)
+		s.Require().NoError(err)
+		defer rows.Close()
+
+		wallets := map[string]float64{}
+		for rows.Next() {
+			var name string
+			var balance float64
+			s.Require().NoError(rows.Scan(&name, &balance))
+			wallets[name] = balance
+		}
+		s.Require().NoError(rows.Err())
+		return wallets
+	}
+
+	s.Run("a wallet name in use rolls back the whole clone", func() {
+		_, err := s.pool.Exec(s.ctx, `
+			INSERT INTO wallets (user_id, name, balance, currency) VALUES ($1, 'Template Card (copy)', 0, 'USD')
+		`, s.userID)
+		s.Require().NoError(err)
+
+		code, _ := s.serveJSON(http.MethodPost, "/projects/"+source.String()+"/clone?include_wallets=true", nil)
+		s.Equal(http.StatusConflict, code)
+
+		var copies int64
+		s.Require().NoError(s.pool.QueryRow(s.ctx, "SELECT COUNT(*) FROM projects WHERE user_id = $1 AND name = 'Template (copy)'", s.userID).Scan(&copies))
+		s.Zero(copies)
+
+		_, err = s.pool.Exec(s.ctx, "DELETE FROM wallets WHERE user_id = $1 AND name = 'Template Card (copy)'", s.userID)
+		s.Require().NoError(err)
+	})
+
+	s.Run("copies the fields, tags and live wallets with zeroed balances", func() {
+		code, response := s.serveJSON(http.MethodPost, "/projects/"+source.String()+"/clone?include_wallets=true", nil)
+		s.Require().Equal(http.StatusCreated, code, response)
+
+		clone := response["data"].(map[string]interface{})
+		s.NotEqual(source.String(), clone["projectId"])
+		s.Equal("Template (copy)", clone["name"])
+		s.Equal("Monthly close", clone["description"])
+		s.Equal(float64(500), clone["budget"])
+		s.ElementsMatch([]interface{}{tags[0].String(), tags[1].String()}, clone["tags"])
+
+		s.Equal(map[string]float64{"Template Cash (copy)": 0, "Template Card (copy)": 0}, cloneWallets(clone["projectId"].(string)))
+		s.Equal(map[string]float64{"Template Cash": 120, "Template Card": 0}, cloneWallets(source.String()))
+
+		var ledgerEntries int64
+		s.Require().NoError(s.pool.QueryRow(s.ctx, `
+			SELECT COUNT(*) FROM wallet_ledger_entries e JOIN wallets w ON w.wallet_id = e.wallet_id
+			WHERE w.project_id = $1
+		`, clone["projectId"]).Scan(&ledgerEntries))
+		s.Zero(ledgerEntries)
+	})
+
+	s.Run("leaves the wallets out by default", func() {
+		code, response := s.serveJSON(http.MethodPost, "/projects/"+source.String()+"/clone", nil)
+		// the first clone holds the name
+		s.Equal(http.StatusConflict, code, response)
+
+		other := s.createSubProject("Solo", nil)
+		code, response = s.serveJSON(http.MethodPost, "/projects/"+other.String()+"/clone", nil)
+		s.Require().Equal(http.StatusCreated, code, response)
+		clone := response["data"].(map[string]interface{})
+		s.Equal("Solo (copy)", clone["name"])
+		s.Empty(cloneWallets(clone["projectId"].(string)))
+	})
+
+	s.Run("another user's project isn't found", func() {
+		strangerProject := uuid.New()
+		_, err := s.pool.Exec(s.ctx, `
+			WITH stranger AS (
+				INSERT INTO users (user_id, clerk_ex_user_id, name, email)
+				VALUES (gen_random_uuid(), 'clone_stranger', 'Clone Stranger', 'clone_stranger@example.com')
+				RETURNING user_id
+			)
+			INSERT INTO projects (project_id, user_id, name, status)
+			SELECT $1, user_id, 'Stranger Template', 'ongoing' FROM stranger
+		`, strangerProject)
+		s.Require().NoError(err)
+
+		code, _ := s.serveJSON(http.MethodPost, "/projects/"+strangerProject.String()+"/clone", nil)
+		s.Equal(http.StatusNotFound, code)
+	})
+}
+
+func (s *ProjectIntegrationTestSuite) TestProjectDrafts() {
+	listed := func(path string) []string {
+		code, response := s.serveJSON(http.MethodGet, path, nil)
+		s.Require().Equal(http.StatusOK, code)
+		var names []string
+		for _, item := range response["data"].([]interface{}) {
+			names = append(names, item.(map[string]interface{})["name"].(string))
+		}
+		return names
+	}
+
+	code, response := s.serveJSON(http.MethodPost, "/projects", types.ProjectCreatePayload{Name: "Live Budget", Status: "ongoing"})
+	s.Require().Equal(http.StatusCreated, code)
+	s.False(response["data"].(map[string]interface{})["draft"].(bool))
+
+	code, response = s.serveJSON(http.MethodPost, "/projects", types.ProjectCreatePayload{Name: "Draft Budget", Status: "ongoing", Draft: true})
+	s.Require().Equal(http.StatusCreated, code)
+	draft := response["data"].(map[string]interface{})
+	s.True(draft["draft"].(bool))
+	draftID := draft["projectId"].(string)
+
+	s.Run("listings leave drafts out unless asked for", func() {
+		s.Equal([]string{"Live Budget"}, listed("/projects/paginated"))
+		s.ElementsMatch([]string{"Live Budget", "Draft Budget"}, listed("/projects/paginated?include_drafts=true"))
+		s.Equal([]string{"Live Budget"}, listed("/projects/search?q=Budget"))
+		s.ElementsMatch([]string{"Live Budget", "Draft Budget"}, listed("/projects/search?q=Budget&include_drafts=true"))
+	})
+
+	s.Run("publishing twice leaves the project as the first publish left it", func() {
+		code, response := s.serveJSON(http.MethodPost, "/projects/"+draftID+"/publish", nil)
+		s.Require().Equal(http.StatusOK, code)
+		published := response["data"].(map[string]interface{})
+		s.False(published["draft"].(bool))
+
+		code, response = s.serveJSON(http.MethodPost, "/projects/"+draftID+"/publish", nil)
+		s.Require().Equal(http.StatusOK, code)
+		s.Equal(published["updatedAt"], response["data"].(map[string]interface{})["updatedAt"])
+
+		s.ElementsMatch([]string{"Live Budget", "Draft Budget"}, listed("/projects/paginated"))
+	})
+
+	s.Run("unknown project", func() {
+		code, _ := s.serveJSON(http.MethodPost, "/projects/"+uuid.New().String()+"/publish", nil)
+		s.Equal(http.StatusNotFound, code)
+	})
+}
+
+func (s *ProjectIntegrationTestSuite) TestPublishProjectRules() {
+	strict := handlers.NewProjectHandler(
+		service.NewProjectService(repository.NewProjectRepository(s.service.Queries()),
+			types.PublishRules{RequireStartDate: true, RequireBudget: true}, nil, "", nil, nil, zap.NewNop()),
+		coreTypes.DefaultLimitPolicy(), zap.NewNop())
+	router := chi.NewRouter()
+	router.Post("/projects", strict.CreateProject)
+	router.Post("/projects/{id}/publish", strict.PublishProject)
+
+	serve := func(path string, payload interface{}) (int, map[string]interface{}) {
+		prev := s.router
+		s.router = router
+		defer func() { s.router = prev }()
+		return s.serveJSON(http.MethodPost, path, payload)
+	}
+
+	code, response := serve("/projects", types.ProjectCreatePayload{Name: "Incomplete", Status: "ongoing"})
+	s.Equal(http.StatusBadRequest, code)
+	s.Contains(response["error"], "start_date, budget")
+
+	code, response = serve("/projects", types.ProjectCreatePayload{Name: "Incomplete", Status: "ongoing", Draft: true})
+	s.Require().Equal(http.StatusCreated, code)
+	draftID := response["data"].(map[string]interface{})["projectId"].(string)
+
+	code, response = serve("/projects/"+draftID+"/publish", nil)
+	s.Equal(http.StatusBadRequest, code)
+	s.Contains(response["error"], "start_date, budget")
+
+	code, _ = s.serveJSON(http.MethodPut, "/projects/"+draftID, types.ProjectUpdatePayload{
+		Name: "Incomplete", Status: "ongoing", StartDate: datePtr(time.Now()), Budget: float64Ptr(1000),
+	})
+	s.Require().Equal(http.StatusOK, code)
+
+	code, response = serve("/projects/"+draftID+"/publish", nil)
+	s.Require().Equal(http.StatusOK, code)
+	s.False(response["data"].(map[string]interface{})["draft"].(bool))
+}
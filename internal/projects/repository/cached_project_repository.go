@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/cache"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/projects/types"
+	"github.com/google/uuid"
+)
+
+// cachedProjectRepository runs each read at most once per request, so summaries calling
+// GetProjectWallets and friends repeatedly only query once, and shares the heavy
+// aggregate reads between requests through the optional micro-cache. Writes clear
+// the request's reads and the user's aggregates.
+type cachedProjectRepository struct {
+	ProjectRepository
+	aggregates *cache.MicroCache
+}
+
+// NewCachedProjectRepository decorates repo with the request cache and, when aggregates
+// isn't nil, the cross-request micro-cache
+func NewCachedProjectRepository(repo ProjectRepository, aggregates *cache.MicroCache) ProjectRepository {
+	return &cachedProjectRepository{
+		ProjectRepository: repo,
+		aggregates:        aggregates,
+	}
+}
+
+// userPrefix starts the micro-cache keys of the user's aggregates
+func userPrefix(userID uuid.UUID) string {
+	return fmt.Sprintf("projects:%s:", userID)
+}
+
+func (c *cachedProjectRepository) ListProjects(ctx context.Context, userID uuid.UUID) ([]types.Project, error) {
+	projects, err := cache.Memoize(ctx, fmt.Sprintf("projects.ListProjects:%s", userID), func() ([]types.Project, error) {
+		return c.ProjectRepository.ListProjects(ctx, userID)
+	})
+	return slices.Clone(projects), err
+}
+
+func (c *cachedProjectRepository) GetProject(ctx context.Context, userID, projectID uuid.UUID) (types.Project, error) {
+	return cache.Memoize(ctx, fmt.Sprintf("projects.GetProject:%s:%s", userID, projectID), func() (types.Project, error) {
+		return c.ProjectRepository.GetProject(ctx, userID, projectID)
+	})
+}
+
+func (c *cachedProjectRepository) GetProjectWallets(ctx context.Context, userID, projectID uuid.UUID) ([]db.Wallet, error) {
+	wallets, err := cache.Memoize(ctx, fmt.Sprintf("projects.GetProjectWallets:%s:%s", userID, projectID), func() ([]db.Wallet, error) {
+		return cache.Remember(c.aggregates, userPrefix(userID)+"wallets:"+projectID.String(), func() ([]db.Wallet, error) {
+			return c.ProjectRepository.GetProjectWallets(ctx, userID, projectID)
+		})
+	})
+	return slices.Clone(wallets), err
+}
+
+func (c *cachedProjectRepository) GetProjectSummary(ctx context.Context, userID, projectID uuid.UUID, rollup bool) (types.ProjectSummary, error) {
+	return cache.Memoize(ctx, fmt.Sprintf("projects.GetProjectSummary:%s:%s:%t", userID, projectID, rollup), func() (types.ProjectSummary, error) {
+		return cache.Remember(c.aggregates, fmt.Sprintf("%ssummary:%s:%t", userPrefix(userID), projectID, rollup), func() (types.ProjectSummary, error) {
+			return c.ProjectRepository.GetProjectSummary(ctx, userID, projectID, rollup)
+		})
+	})
+}
+
+func (c *cachedProjectRepository) ListMilestones(ctx context.Context, userID, projectID uuid.UUID) ([]types.Milestone, error) {
+	milestones, err := cache.Memoize(ctx, fmt.Sprintf("projects.ListMilestones:%s:%s", userID, projectID), func() ([]types.Milestone, error) {
+		return c.ProjectRepository.ListMilestones(ctx, userID, projectID)
+	})
+	return slices.Clone(milestones), err
+}
+
+func (c *cachedProjectRepository) GetMilestone(ctx context.Context, userID, projectID, milestoneID uuid.UUID) (types.Milestone, error) {
+	return cache.Memoize(ctx, fmt.Sprintf("projects.GetMilestone:%s:%s:%s", userID, projectID, milestoneID), func() (types.Milestone, error) {
+		return c.ProjectRepository.GetMilestone(ctx, userID, projectID, milestoneID)
+	})
+}
+
+func (c *cachedProjectRepository) invalidate(ctx context.Context, userID uuid.UUID) {
+	cache.Invalidate(ctx)
+	c.aggregates.Forget(userPrefix(userID))
+}
+
+func (c *cachedProjectRepository) CreateProject(ctx context.Context, userID uuid.UUID, projectData types.ProjectCreatePayload) (types.Project, error) {
+	defer c.invalidate(ctx, userID)
+	return c.ProjectRepository.CreateProject(ctx, userID, projectData)
+}
+
+func (c *cachedProjectRepository) UpdateProject(ctx context.Context, userID uuid.UUID, projectData types.ProjectUpdatePayload) (types.Project, error) {
+	defer c.invalidate(ctx, userID)
+	return c.ProjectRepository.UpdateProject(ctx, userID, projectData)
+}
+
+func (c *cachedProjectRepository) UpdateProjectLocked(ctx context.Context, userID, projectID uuid.UUID, merge func(types.Project) (types.ProjectUpdatePayload, error)) (types.Project, error) {
+	defer c.invalidate(ctx, userID)
+	return c.ProjectRepository.UpdateProjectLocked(ctx, userID, projectID, merge)
+}
+
+func (c *cachedProjectRepository) DeleteProject(ctx context.Context, userID, projectID uuid.UUID, version *time.Time) error {
+	defer c.invalidate(ctx, userID)
+	return c.ProjectRepository.DeleteProject(ctx, userID, projectID, version)
+}
+
+func (c *cachedProjectRepository) DeleteProjectTree(ctx context.Context, userID, projectID uuid.UUID, version *time.Time) error {
+	defer c.invalidate(ctx, userID)
+	return c.ProjectRepository.DeleteProjectTree(ctx, userID, projectID, version)
+}
+
+func (c *cachedProjectRepository) DeleteProjectDetachingChildren(ctx context.Context, userID, projectID uuid.UUID, version *time.Time) error {
+	defer c.invalidate(ctx, userID)
+	return c.ProjectRepository.DeleteProjectDetachingChildren(ctx, userID, projectID, version)
+}
+
+func (c *cachedProjectRepository) RestoreProject(ctx context.Context, userID, projectID uuid.UUID) (types.Project, error) {
+	defer c.invalidate(ctx, userID)
+	return c.ProjectRepository.RestoreProject(ctx, userID, projectID)
+}
+
+func (c *cachedProjectRepository) CloneProject(ctx context.Context, userID, projectID uuid.UUID, includeWallets bool) (types.Project, error) {
+	defer c.invalidate(ctx, userID)
+	return c.ProjectRepository.CloneProject(ctx, userID, projectID, includeWallets)
+}
+
+func (c *cachedProjectRepository) PublishProject(ctx context.Context, userID, projectID uuid.UUID, rules types.PublishRules) (types.Project, error) {
+	defer c.invalidate(ctx, userID)
+	return c.ProjectRepository.PublishProject(ctx, userID, projectID, rules)
+}
+
+func (c *cachedProjectRepository) SetProjectPinned(ctx context.Context, userID, projectID uuid.UUID, pinned bool) (types.Project, error) {
+	defer c.invalidate(ctx, userID)
+	return c.ProjectRepository.SetProjectPinned(ctx, userID, projectID, pinned)
+}
+
+func (c *cachedProjectRepository) CreateMilestone(ctx context.Context, userID, projectID uuid.UUID, milestoneData types.MilestoneCreatePayload) (types.Milestone, error) {
+	defer c.invalidate(ctx, userID)
+	return c.ProjectRepository.CreateMilestone(ctx, userID, projectID, milestoneData)
+}
+
+func (c *cachedProjectRepository) UpdateMilestone(ctx context.Context, userID uuid.UUID, milestoneData types.MilestoneUpdatePayload) (types.Milestone, error) {
+	defer c.invalidate(ctx, userID)
+	return c.ProjectRepository.UpdateMilestone(ctx, userID, milestoneData)
+}
+
+func (c *cachedProjectRepository) DeleteMilestone(ctx context.Context, userID, projectID, milestoneID uuid.UUID) error {
+	defer c.invalidate(ctx, userID)
+	return c.ProjectRepository.DeleteMilestone(ctx, userID, projectID, milestoneID)
+}
+
+func (c *cachedProjectRepository) ReorderMilestones(ctx context.Context, userID, projectID uuid.UUID, milestoneIDs []uuid.UUID) error {
+	defer c.invalidate(ctx, userID)
+	return c.ProjectRepository.ReorderMilestones(ctx, userID, projectID, milestoneIDs)
+}
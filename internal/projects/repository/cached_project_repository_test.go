@@ -0,0 +1,161 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/cache"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/projects/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/projects/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingRepository counts the calls reaching the database, the methods it doesn't
+// override panic through the nil embedded interface
+type countingRepository struct {
+	repository.ProjectRepository
+	calls   map[string]int
+	wallets []db.Wallet
+}
+
+func newCountingRepository() *countingRepository {
+	return &countingRepository{calls: map[string]int{}}
+}
+
+func (c *countingRepository) GetProject(ctx context.Context, userID, projectID uuid.UUID) (types.Project, error) {
+	c.calls["GetProject"]++
+	return types.Project{ProjectID: projectID, Name: "House"}, nil
+}
+
+func (c *countingRepository) GetProjectWallets(ctx context.Context, userID, projectID uuid.UUID) ([]db.Wallet, error) {
+	c.calls["GetProjectWallets"]++
+	return c.wallets, nil
+}
+
+func (c *countingRepository) ListMilestones(ctx context.Context, userID, projectID uuid.UUID) ([]types.Milestone, error) {
+	c.calls["ListMilestones"]++
+	return []types.Milestone{{ProjectID: projectID, Name: "Foundations poured"}}, nil
+}
+
+func (c *countingRepository) UpdateProject(ctx context.Context, userID uuid.UUID, projectData types.ProjectUpdatePayload) (types.Project, error) {
+	c.calls["UpdateProject"]++
+	return types.Project{ProjectID: projectData.ProjectID, Name: projectData.Name}, nil
+}
+
+func TestCachedProjectRepository_RequestCache(t *testing.T) {
+	userID, projectID, otherProjectID := uuid.New(), uuid.New(), uuid.New()
+
+	t.Run("same reads query once per request", func(t *testing.T) {
+		counting := newCountingRepository()
+		repo := repository.NewCachedProjectRepository(counting, nil)
+		ctx := cache.WithRequestCache(context.Background())
+
+		for i := 0; i < 3; i++ {
+			_, err := repo.GetProjectWallets(ctx, userID, projectID)
+			require.NoError(t, err)
+			_, err = repo.GetProject(ctx, userID, projectID)
+			require.NoError(t, err)
+		}
+		_, err := repo.GetProjectWallets(ctx, userID, otherProjectID)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, counting.calls["GetProjectWallets"])
+		assert.Equal(t, 1, counting.calls["GetProject"])
+	})
+
+	t.Run("requests don't share reads", func(t *testing.T) {
+		counting := newCountingRepository()
+		repo := repository.NewCachedProjectRepository(counting, nil)
+
+		for i := 0; i < 2; i++ {
+			ctx := cache.WithRequestCache(context.Background())
+			_, err := repo.GetProjectWallets(ctx, userID, projectID)
+			require.NoError(t, err)
+		}
+		_, err := repo.GetProjectWallets(context.Background(), userID, projectID)
+		require.NoError(t, err)
+
+		assert.Equal(t, 3, counting.calls["GetProjectWallets"])
+	})
+
+	t.Run("writes bypass and clear the request cache", func(t *testing.T) {
+		counting := newCountingRepository()
+		repo := repository.NewCachedProjectRepository(counting, nil)
+		ctx := cache.WithRequestCache(context.Background())
+
+		_, err := repo.GetProject(ctx, userID, projectID)
+		require.NoError(t, err)
+		_, err = repo.ListMilestones(ctx, userID, projectID)
+		require.NoError(t, err)
+
+		for i := 0; i < 2; i++ {
+			_, err = repo.UpdateProject(ctx, userID, types.ProjectUpdatePayload{ProjectID: projectID, Name: "Renamed"})
+			require.NoError(t, err)
+		}
+		_, err = repo.GetProject(ctx, userID, projectID)
+		require.NoError(t, err)
+		_, err = repo.ListMilestones(ctx, userID, projectID)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, counting.calls["UpdateProject"])
+		assert.Equal(t, 2, counting.calls["GetProject"])
+		assert.Equal(t, 2, counting.calls["ListMilestones"])
+	})
+
+	t.Run("callers can't change memoized results", func(t *testing.T) {
+		counting := newCountingRepository()
+		counting.wallets = []db.Wallet{{Name: "Cash"}}
+		repo := repository.NewCachedProjectRepository(counting, nil)
+		ctx := cache.WithRequestCache(context.Background())
+
+		wallets, err := repo.GetProjectWallets(ctx, userID, projectID)
+		require.NoError(t, err)
+		wallets[0].Name = "Changed"
+
+		wallets, err = repo.GetProjectWallets(ctx, userID, projectID)
+		require.NoError(t, err)
+		assert.Equal(t, "Cash", wallets[0].Name)
+	})
+}
+
+func TestCachedProjectRepository_MicroCache(t *testing.T) {
+	userID, otherUserID, projectID := uuid.New(), uuid.New(), uuid.New()
+
+	t.Run("aggregates are shared between requests of the user", func(t *testing.T) {
+		counting := newCountingRepository()
+		repo := repository.NewCachedProjectRepository(counting, cache.NewMicroCache(time.Minute))
+
+		for i := 0; i < 3; i++ {
+			_, err := repo.GetProjectWallets(cache.WithRequestCache(context.Background()), userID, projectID)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 1, counting.calls["GetProjectWallets"])
+
+		_, err := repo.GetProjectWallets(cache.WithRequestCache(context.Background()), otherUserID, projectID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, counting.calls["GetProjectWallets"])
+	})
+
+	t.Run("writes forget the user's aggregates", func(t *testing.T) {
+		counting := newCountingRepository()
+		repo := repository.NewCachedProjectRepository(counting, cache.NewMicroCache(time.Minute))
+
+		_, err := repo.GetProjectWallets(context.Background(), userID, projectID)
+		require.NoError(t, err)
+		_, err = repo.GetProjectWallets(context.Background(), otherUserID, projectID)
+		require.NoError(t, err)
+
+		_, err = repo.UpdateProject(context.Background(), userID, types.ProjectUpdatePayload{ProjectID: projectID, Name: "Renamed"})
+		require.NoError(t, err)
+
+		_, err = repo.GetProjectWallets(context.Background(), userID, projectID)
+		require.NoError(t, err)
+		_, err = repo.GetProjectWallets(context.Background(), otherUserID, projectID)
+		require.NoError(t, err)
+		assert.Equal(t, 3, counting.calls["GetProjectWallets"])
+	})
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/repository"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/projects/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrMilestoneLimit is returned when a milestone is added to a project that already has
+// types.MaxMilestones of them
+var ErrMilestoneLimit = stdErrors.New("milestone limit reached")
+
+func (p *projectRepository) ListMilestones(ctx context.Context, userID, projectID uuid.UUID) ([]types.Milestone, error) {
+	milestones, err := p.queries.ListMilestones(ctx, db.ListMilestonesParams{
+		UserID:    userID,
+		ProjectID: projectID,
+	})
+	if err != nil {
+		return nil, errors.HandleRepositoryError(err, "list", "milestone(s)")
+	}
+
+	return toMilestones(milestones), nil
+}
+
+func (p *projectRepository) GetMilestone(ctx context.Context, userID, projectID, milestoneID uuid.UUID) (types.Milestone, error) {
+	milestone, err := p.queries.GetMilestone(ctx, db.GetMilestoneParams{
+		UserID:      userID,
+		ProjectID:   projectID,
+		MilestoneID: milestoneID,
+	})
+	if err != nil {
+		return types.Milestone{}, errors.HandleRepositoryError(err, "get", "milestone(s)")
+	}
+
+	return toMilestone(milestone), nil
+}
+
+// CountProjectTreeMilestones counts the project's milestones, with rollup those of its live
+// sub-projects at any depth too
+func (p *projectRepository) CountProjectTreeMilestones(ctx context.Context, userID, projectID uuid.UUID, rollup bool) (int64, error) {
+	count, err := p.queries.CountProjectTreeMilestones(ctx, db.CountProjectTreeMilestonesParams{
+		ProjectID: projectID,
+		UserID:    userID,
+		Rollup:    rollup,
+	})
+	if err != nil {
+		return 0, errors.HandleRepositoryError(err, "count", "milestone(s)")
+	}
+	return count, nil
+}
+
+// CreateMilestone adds a milestone to the project unless it already has types.MaxMilestones
+// of them, returning ErrMilestoneLimit then. The count and the insert run in a transaction
+// holding the project's advisory lock, so concurrent creates can't go past the limit.
+func (p *projectRepository) CreateMilestone(ctx context.Context, userID, projectID uuid.UUID, milestoneData types.MilestoneCreatePayload) (types.Milestone, error) {
+	var milestone db.Milestone
+	err := p.queries.WithEntityLock(ctx, projectID, func(q *db.Queries) error {
+		count, err := q.CountMilestones(ctx, projectID)
+		if err != nil {
+			return err
+		}
+		if count >= types.MaxMilestones {
+			return ErrMilestoneLimit
+		}
+		milestone, err = q.CreateMilestone(ctx, db.CreateMilestoneParams{
+			UserID:    userID,
+			ProjectID: projectID,
+			Name:      milestoneData.Name,
+			DueDate:   utils.ToNullableTimestamp(milestoneData.DueDate),
+			Completed: milestoneData.Completed,
+		})
+		return err
+	})
+	if stdErrors.Is(err, ErrMilestoneLimit) {
+		return types.Milestone{}, err
+	}
+	if err != nil {
+		return types.Milestone{}, errors.HandleRepositoryError(err, "create", "milestone(s)")
+	}
+
+	return toMilestone(milestone), nil
+}
+
+func (p *projectRepository) UpdateMilestone(ctx context.Context, userID uuid.UUID, milestoneData types.MilestoneUpdatePayload) (types.Milestone, error) {
+	if milestoneData.MilestoneID == uuid.Nil || milestoneData.ProjectID == uuid.Nil || userID == uuid.Nil {
+		return types.Milestone{}, fmt.Errorf("invalid milestone id, project id or user id")
+	}
+
+	milestone, err := p.queries.UpdateMilestone(ctx, db.UpdateMilestoneParams{
+		UserID:      userID,
+		ProjectID:   milestoneData.ProjectID,
+		MilestoneID: milestoneData.MilestoneID,
+		Name:        milestoneData.Name,
+		DueDate:     utils.ToNullableTimestamp(milestoneData.DueDate),
+		Completed:   milestoneData.Completed,
+	})
+	if err != nil {
+		return types.Milestone{}, errors.HandleRepositoryError(err, "update", "milestone(s)")
+	}
+
+	return toMilestone(milestone), nil
+}
+
+func (p *projectRepository) DeleteMilestone(ctx context.Context, userID, projectID, milestoneID uuid.UUID) error {
+	deleted, err := p.queries.DeleteMilestone(ctx, db.DeleteMilestoneParams{
+		UserID:      userID,
+		ProjectID:   projectID,
+		MilestoneID: milestoneID,
+	})
+	if err != nil {
+		return errors.HandleRepositoryError(err, "delete", "milestone(s)")
+	}
+	if deleted == 0 {
+		return fmt.Errorf("delete milestone %s: %w", milestoneID, repository.ErrNotFound)
+	}
+	return nil
+}
+
+// ReorderMilestones moves the project's milestones into the order of milestoneIDs,
+// which must list every one of them
+func (p *projectRepository) ReorderMilestones(ctx context.Context, userID, projectID uuid.UUID, milestoneIDs []uuid.UUID) error {
+	updated, err := p.queries.ReorderMilestones(ctx, db.ReorderMilestonesParams{
+		UserID:       userID,
+		ProjectID:    projectID,
+		MilestoneIds: milestoneIDs,
+	})
+	if err != nil {
+		return errors.HandleRepositoryError(err, "reorder", "milestone(s)")
+	}
+	// the query refuses partial orders, so a short count means the project or
+	// one of its milestones is gone
+	if updated != int64(len(milestoneIDs)) {
+		return errors.HandleRepositoryError(pgx.ErrNoRows, "reorder", "milestone(s)")
+	}
+	return nil
+}
+
+// withProgress fills in the milestone progress of a single project
+func (p *projectRepository) withProgress(ctx context.Context, project types.Project) (types.Project, error) {
+	projects, err := p.withProgresses(ctx, []types.Project{project})
+	if err != nil {
+		return types.Project{}, err
+	}
+	return projects[0], nil
+}
+
+// withProgresses fills in the milestone progress of the projects with one query,
+// projects without milestones keep a nil progress
+func (p *projectRepository) withProgresses(ctx context.Context, projects []types.Project) ([]types.Project, error) {
+	if len(projects) == 0 {
+		return projects, nil
+	}
+
+	projectIDs := make([]uuid.UUID, len(projects))
+	for i, project := range projects {
+		projectIDs[i] = project.ProjectID
+	}
+
+	rows, err := p.queries.GetMilestoneProgress(ctx, projectIDs)
+	if err != nil {
+		return nil, errors.HandleRepositoryError(err, "get progress for", "project(s)")
+	}
+
+	progress := make(map[uuid.UUID]float64, len(rows))
+	for _, row := range rows {
+		if row.Total > 0 {
+			progress[row.ProjectID] = float64(row.Completed) / float64(row.Total)
+		}
+	}
+	for i := range projects {
+		if value, ok := progress[projects[i].ProjectID]; ok {
+			projects[i].Progress = &value
+		}
+	}
+	return projects, nil
+}
+
+func toMilestone(m db.Milestone) types.Milestone {
+	return types.Milestone{
+		MilestoneID: m.MilestoneID,
+		ProjectID:   m.ProjectID,
+		Name:        m.Name,
+		DueDate:     utils.GetTimestampPtr(m.DueDate),
+		CompletedAt: utils.GetTimestampPtr(m.CompletedAt),
+		SortOrder:   m.SortOrder,
+		CreatedAt:   coreTypes.NewTimestamp(m.CreatedAt.Time),
+		UpdatedAt:   coreTypes.NewTimestamp(m.UpdatedAt.Time),
+	}
+}
+
+func toMilestones(milestones []db.Milestone) []types.Milestone {
+	result := make([]types.Milestone, len(milestones))
+	for i, m := range milestones {
+		result[i] = toMilestone(m)
+	}
+	return result
+}
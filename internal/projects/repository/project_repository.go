@@ -2,27 +2,71 @@ package repository
 
 import (
 	"context"
+	stdErrors "errors"
 	"fmt"
 	"time"
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/repository"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/projects/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
 	"github.com/google/uuid"
 )
 
 type ProjectRepository interface {
 	ListProjects(ctx context.Context, userID uuid.UUID) ([]types.Project, error)
 	GetProject(ctx context.Context, userID, projectID uuid.UUID) (types.Project, error)
+	GetProjectsByIDs(ctx context.Context, userID uuid.UUID, projectIDs []uuid.UUID) ([]types.Project, []uuid.UUID, error)
+	GetProjectByName(ctx context.Context, userID uuid.UUID, name string) (types.Project, error)
 	CreateProject(ctx context.Context, userID uuid.UUID, projectData types.ProjectCreatePayload) (types.Project, error)
 	UpdateProject(ctx context.Context, userID uuid.UUID, projectData types.ProjectUpdatePayload) (types.Project, error)
-	DeleteProject(ctx context.Context, userID, projectID uuid.UUID) error
+	// UpdateProjectLocked updates the project with the payload merge makes of its current
+	// values, holding the project's advisory lock from the read to the write
+	UpdateProjectLocked(ctx context.Context, userID, projectID uuid.UUID, merge func(types.Project) (types.ProjectUpdatePayload, error)) (types.Project, error)
+	// the deletes take a version to only trash the project while it is still at that
+	// updatedAt, a live project at another one returns ErrPreconditionFailed
+	DeleteProject(ctx context.Context, userID, projectID uuid.UUID, version *time.Time) error
+	DeleteProjectTree(ctx context.Context, userID, projectID uuid.UUID, version *time.Time) error
+	DeleteProjectDetachingChildren(ctx context.Context, userID, projectID uuid.UUID, version *time.Time) error
+	ListChildProjects(ctx context.Context, userID, projectID uuid.UUID) ([]types.Project, error)
+	CountChildProjects(ctx context.Context, userID, projectID uuid.UUID) (int64, error)
+	ListProjectAncestors(ctx context.Context, userID, projectID uuid.UUID) ([]uuid.UUID, error)
+	GetProjectSubtreeDepth(ctx context.Context, userID, projectID uuid.UUID) (int32, error)
+	GetProjectSummary(ctx context.Context, userID, projectID uuid.UUID, rollup bool) (types.ProjectSummary, error)
+	ListDeletedProjectsPaginated(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, limit int32, order coreTypes.SortOrder) ([]types.Project, error)
+	RestoreProject(ctx context.Context, userID, projectID uuid.UUID) (types.Project, error)
+	CloneProject(ctx context.Context, userID, projectID uuid.UUID, includeWallets bool) (types.Project, error)
+	PublishProject(ctx context.Context, userID, projectID uuid.UUID, rules types.PublishRules) (types.Project, error)
 	GetProjectWallets(ctx context.Context, userID, projectID uuid.UUID) ([]db.Wallet, error)
-	ListProjectsPaginated(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, limit int32) ([]types.Project, error)
-	SearchProjects(ctx context.Context, userID uuid.UUID, query string, limit int32) ([]types.Project, error)
+	ListProjectsPaginated(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, includeDrafts bool, limit int32, order coreTypes.SortOrder) ([]types.Project, error)
+	ListPinnedProjectsPaginated(ctx context.Context, userID uuid.UUID, pinnedAt time.Time, cursorID uuid.UUID, includeDrafts bool, limit int32) ([]types.Project, error)
+	// ListProjectIDsPaginated and ListPinnedProjectIDsPaginated read only the IDs and the
+	// keys the lists are paged by
+	ListProjectIDsPaginated(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, includeDrafts bool, limit int32, order coreTypes.SortOrder) ([]coreTypes.KeyedID, error)
+	ListPinnedProjectIDsPaginated(ctx context.Context, userID uuid.UUID, pinnedAt time.Time, cursorID uuid.UUID, includeDrafts bool, limit int32) ([]coreTypes.KeyedID, error)
+	// ListProjectsPaginatedStream and ListPinnedProjectsPaginatedStream hand each project to fn as it
+	// is read, an error from fn stops the stream and is returned as is
+	ListProjectsPaginatedStream(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, includeDrafts bool, limit int32, order coreTypes.SortOrder, fn func(types.Project) error) error
+	ListPinnedProjectsPaginatedStream(ctx context.Context, userID uuid.UUID, pinnedAt time.Time, cursorID uuid.UUID, includeDrafts bool, limit int32, fn func(types.Project) error) error
+	SetProjectPinned(ctx context.Context, userID, projectID uuid.UUID, pinned bool) (types.Project, error)
+	SearchProjects(ctx context.Context, userID uuid.UUID, query string, includeDrafts bool, limit, offset int32) ([]types.Project, error)
+	SearchProjectsPicker(ctx context.Context, userID uuid.UUID, query string, includeDrafts bool, limit, offset int32) ([]types.ProjectPickerItem, error)
+	ListMilestones(ctx context.Context, userID, projectID uuid.UUID) ([]types.Milestone, error)
+	GetMilestone(ctx context.Context, userID, projectID, milestoneID uuid.UUID) (types.Milestone, error)
+	CountProjectTreeMilestones(ctx context.Context, userID, projectID uuid.UUID, rollup bool) (int64, error)
+	CreateMilestone(ctx context.Context, userID, projectID uuid.UUID, milestoneData types.MilestoneCreatePayload) (types.Milestone, error)
+	UpdateMilestone(ctx context.Context, userID uuid.UUID, milestoneData types.MilestoneUpdatePayload) (types.Milestone, error)
+	DeleteMilestone(ctx context.Context, userID, projectID, milestoneID uuid.UUID) error
+	ReorderMilestones(ctx context.Context, userID, projectID uuid.UUID, milestoneIDs []uuid.UUID) error
 }
 
+// ErrPinnedProjectLimit is returned when a project is pinned by a user who already has
+// types.MaxPinnedProjects pinned
+var ErrPinnedProjectLimit = stdErrors.New("pinned project limit reached")
+
 type projectRepository struct {
 	queries *db.Queries
 }
@@ -33,21 +77,24 @@ func NewProjectRepository(queries *db.Queries) ProjectRepository {
 
 func (p *projectRepository) CreateProject(ctx context.Context, userID uuid.UUID, projectData types.ProjectCreatePayload) (types.Project, error) {
 	params := db.CreateProjectParams{
-		UserID:        userID,
-		Name:          projectData.Name,
-		Description:   utils.ToNullableText(projectData.Description),
-		Status:        db.ProjectsStatus(projectData.Status),
-		StartDate:     utils.ToNullableTimestamp(projectData.StartDate),
-		EndDate:       utils.ToNullableTimestamp(projectData.EndDate),
-		Budget:        utils.ToNullableNumeric(projectData.Budget),
-		AddressLine1:  utils.ToNullableText(projectData.AddressLine1),
-		AddressLine2:  utils.ToNullableText(projectData.AddressLine2),
-		Country:       utils.ToNullableText(projectData.Country),
-		City:          utils.ToNullableText(projectData.City),
-		StateProvince: utils.ToNullableText(projectData.StateProvince),
-		ZipPostalCode: utils.ToNullableText(projectData.ZipPostalCode),
-		Website:       utils.ToNullableText(projectData.Website),
-		Tags:          projectData.Tags,
+		UserID:          userID,
+		Name:            projectData.Name,
+		Description:     utils.ToNullableText(projectData.Description),
+		Status:          db.ProjectsStatus(projectData.Status),
+		StartDate:       utils.ToNullableDate(projectData.StartDate),
+		EndDate:         utils.ToNullableDate(projectData.EndDate),
+		Budget:          utils.ToNullableNumeric(projectData.Budget),
+		AddressLine1:    utils.ToNullableText(projectData.AddressLine1),
+		AddressLine2:    utils.ToNullableText(projectData.AddressLine2),
+		Country:         utils.ToNullableText(projectData.Country),
+		City:            utils.ToNullableText(projectData.City),
+		StateProvince:   utils.ToNullableText(projectData.StateProvince),
+		ZipPostalCode:   utils.ToNullableText(projectData.ZipPostalCode),
+		Website:         utils.ToNullableText(projectData.Website),
+		Tags:            projectData.Tags,
+		ParentProjectID: utils.UUIDToNullableUUID(projectData.ParentProjectID),
+		IsDraft:         projectData.Draft,
+		ActorID:         requestcontext.GetActorIDFromContext(ctx, userID),
 	}
 
 	project, err := p.queries.CreateProject(ctx, params)
@@ -64,7 +111,7 @@ func (p *projectRepository) ListProjects(ctx context.Context, userID uuid.UUID)
 		return nil, errors.HandleRepositoryError(err, "list", "project(s)")
 	}
 
-	return toProjects(projects), nil
+	return p.withProgresses(ctx, toProjects(projects))
 }
 
 func (p *projectRepository) GetProject(ctx context.Context, userID, projectID uuid.UUID) (types.Project, error) {
@@ -76,7 +123,41 @@ func (p *projectRepository) GetProject(ctx context.Context, userID, projectID uu
 		return types.Project{}, errors.HandleRepositoryError(err, "get", "project(s)")
 	}
 
-	return toProject(project), nil
+	return p.withProgress(ctx, toProject(project))
+}
+
+// GetProjectsByIDs retrieves the user's projects with the IDs in the order asked for, along
+// with the IDs of those that don't exist, are trashed or belong to another user
+func (p *projectRepository) GetProjectsByIDs(ctx context.Context, userID uuid.UUID, projectIDs []uuid.UUID) ([]types.Project, []uuid.UUID, error) {
+	rows, err := p.queries.GetProjectsByIDs(ctx, db.GetProjectsByIDsParams{
+		ProjectIds: projectIDs,
+		UserID:     userID,
+	})
+	if err != nil {
+		return nil, nil, errors.HandleRepositoryError(err, "get", "project(s)")
+	}
+
+	found, missing := repository.OrderByIDs(projectIDs, toProjects(rows), func(project types.Project) uuid.UUID {
+		return project.ProjectID
+	})
+	projects, err := p.withProgresses(ctx, found)
+	if err != nil {
+		return nil, nil, err
+	}
+	return projects, missing, nil
+}
+
+// GetProjectByName retrieves the user's project with the name, compared case-insensitively
+func (p *projectRepository) GetProjectByName(ctx context.Context, userID uuid.UUID, name string) (types.Project, error) {
+	project, err := p.queries.GetProjectByName(ctx, db.GetProjectByNameParams{
+		UserID: userID,
+		Name:   name,
+	})
+	if err != nil {
+		return types.Project{}, errors.HandleRepositoryError(err, "get", "project(s)")
+	}
+
+	return p.withProgress(ctx, toProject(project))
 }
 
 // toNullableProjectStatus converts a string to NullProjectsStatus, setting Valid to true
@@ -104,22 +185,24 @@ func (p *projectRepository) UpdateProject(ctx context.Context, userID uuid.UUID,
 	}
 
 	params := db.UpdateProjectParams{
-		ProjectID:     projectData.ProjectID,
-		UserID:        userID,
-		Name:          utils.ToNullableText(&projectData.Name),
-		Description:   utils.ToNullableText(projectData.Description),
-		Status:        toNullableProjectStatus(projectData.Status),
-		StartDate:     utils.ToNullableTimestamp(projectData.StartDate),
-		EndDate:       utils.ToNullableTimestamp(projectData.EndDate),
-		Budget:        utils.ToNullableNumeric(projectData.Budget),
-		AddressLine1:  utils.ToNullableText(projectData.AddressLine1),
-		AddressLine2:  utils.ToNullableText(projectData.AddressLine2),
-		Country:       utils.ToNullableText(projectData.Country),
-		City:          utils.ToNullableText(projectData.City),
-		StateProvince: utils.ToNullableText(projectData.StateProvince),
-		ZipPostalCode: utils.ToNullableText(projectData.ZipPostalCode),
-		Website:       utils.ToNullableText(projectData.Website),
-		Tags:          projectData.Tags,
+		ProjectID:       projectData.ProjectID,
+		UserID:          userID,
+		Name:            utils.ToNullableText(&projectData.Name),
+		Description:     utils.ToNullableText(projectData.Description),
+		Status:          toNullableProjectStatus(projectData.Status),
+		StartDate:       utils.ToNullableDate(projectData.StartDate),
+		EndDate:         utils.ToNullableDate(projectData.EndDate),
+		Budget:          utils.ToNullableNumeric(projectData.Budget),
+		AddressLine1:    utils.ToNullableText(projectData.AddressLine1),
+		AddressLine2:    utils.ToNullableText(projectData.AddressLine2),
+		Country:         utils.ToNullableText(projectData.Country),
+		City:            utils.ToNullableText(projectData.City),
+		StateProvince:   utils.ToNullableText(projectData.StateProvince),
+		ZipPostalCode:   utils.ToNullableText(projectData.ZipPostalCode),
+		Website:         utils.ToNullableText(projectData.Website),
+		Tags:            projectData.Tags,
+		ParentProjectID: utils.UUIDToNullableUUID(projectData.ParentProjectID),
+		ActorID:         requestcontext.GetActorIDFromContext(ctx, userID),
 	}
 
 	project, err := p.queries.UpdateProject(ctx, params)
@@ -127,20 +210,244 @@ func (p *projectRepository) UpdateProject(ctx context.Context, userID uuid.UUID,
 		return types.Project{}, errors.HandleRepositoryError(err, "update", "project(s)")
 	}
 
-	return toProject(project), nil
+	return p.withProgress(ctx, toProject(project))
+}
+
+// UpdateProjectLocked updates the project with the payload merge makes of its current
+// values. The read and the write run in a transaction holding the project's advisory
+// lock, so concurrent updates apply one after the other instead of interleaving.
+func (p *projectRepository) UpdateProjectLocked(ctx context.Context, userID, projectID uuid.UUID, merge func(types.Project) (types.ProjectUpdatePayload, error)) (types.Project, error) {
+	var project types.Project
+	// the errors of the read, merge and write are returned as they are, the others are
+	// those of the transaction and the lock
+	var updateErr error
+	err := p.queries.WithEntityLock(ctx, projectID, func(q *db.Queries) error {
+		locked := &projectRepository{queries: q}
+		existing, err := locked.GetProject(ctx, userID, projectID)
+		if err == nil {
+			var projectData types.ProjectUpdatePayload
+			if projectData, err = merge(existing); err == nil {
+				project, err = locked.UpdateProject(ctx, userID, projectData)
+			}
+		}
+		updateErr = err
+		return err
+	})
+	if updateErr != nil {
+		return types.Project{}, updateErr
+	}
+	if err != nil {
+		return types.Project{}, errors.HandleRepositoryError(err, "update", "project(s)")
+	}
+	return project, nil
+}
+
+func (p *projectRepository) DeleteProject(ctx context.Context, userID, projectID uuid.UUID, version *time.Time) error {
+	deleted, err := p.queries.DeleteProject(ctx, db.DeleteProjectParams{
+		UserID:    userID,
+		ProjectID: projectID,
+		Version:   utils.ToNullableTimestamp(version),
+	})
+	if err != nil {
+		return errors.HandleRepositoryError(err, "delete", "project(s)")
+	}
+	if deleted == 0 {
+		return p.missedDelete(ctx, userID, projectID, version)
+	}
+	return nil
+}
+
+// DeleteProjectTree trashes the project along with its sub-projects at any depth
+func (p *projectRepository) DeleteProjectTree(ctx context.Context, userID, projectID uuid.UUID, version *time.Time) error {
+	deleted, err := p.queries.DeleteProjectTree(ctx, db.DeleteProjectTreeParams{
+		UserID:    userID,
+		ProjectID: projectID,
+		Version:   utils.ToNullableTimestamp(version),
+	})
+	if err != nil {
+		return errors.HandleRepositoryError(err, "delete", "project(s)")
+	}
+	if deleted == 0 {
+		return p.missedDelete(ctx, userID, projectID, version)
+	}
+	return nil
 }
 
-func (p *projectRepository) DeleteProject(ctx context.Context, userID, projectID uuid.UUID) error {
-	err := p.queries.DeleteProject(ctx, db.DeleteProjectParams{
+// DeleteProjectDetachingChildren moves the project's children to the top level and trashes it
+func (p *projectRepository) DeleteProjectDetachingChildren(ctx context.Context, userID, projectID uuid.UUID, version *time.Time) error {
+	deleted, err := p.queries.DeleteProjectDetachingChildren(ctx, db.DeleteProjectDetachingChildrenParams{
 		UserID:    userID,
 		ProjectID: projectID,
+		Version:   utils.ToNullableTimestamp(version),
 	})
 	if err != nil {
 		return errors.HandleRepositoryError(err, "delete", "project(s)")
 	}
+	if deleted == 0 {
+		return p.missedDelete(ctx, userID, projectID, version)
+	}
 	return nil
 }
 
+// missedDelete returns the error of a delete that found no project to trash, a live project
+// was at another version than the one the delete was conditioned on
+func (p *projectRepository) missedDelete(ctx context.Context, userID, projectID uuid.UUID, version *time.Time) error {
+	if version != nil {
+		if _, err := p.GetProject(ctx, userID, projectID); err == nil {
+			return fmt.Errorf("delete project %s: %w", projectID, repository.ErrPreconditionFailed)
+		}
+	}
+	return fmt.Errorf("delete project %s: %w", projectID, repository.ErrNotFound)
+}
+
+// ListChildProjects lists the live direct sub-projects of the project, oldest first
+func (p *projectRepository) ListChildProjects(ctx context.Context, userID, projectID uuid.UUID) ([]types.Project, error) {
+	projects, err := p.queries.ListChildProjects(ctx, db.ListChildProjectsParams{
+		ParentProjectID: utils.ToNullableUUID(projectID),
+		UserID:          userID,
+	})
+	if err != nil {
+		return nil, errors.HandleRepositoryError(err, "list children of", "project(s)")
+	}
+
+	return p.withProgresses(ctx, toProjects(projects))
+}
+
+func (p *projectRepository) CountChildProjects(ctx context.Context, userID, projectID uuid.UUID) (int64, error) {
+	count, err := p.queries.CountChildProjects(ctx, db.CountChildProjectsParams{
+		ParentProjectID: utils.ToNullableUUID(projectID),
+		UserID:          userID,
+	})
+	if err != nil {
+		return 0, errors.HandleRepositoryError(err, "count children of", "project(s)")
+	}
+	return count, nil
+}
+
+// ListProjectAncestors returns the project's ID followed by those of its ancestors,
+// nearest first, and nothing for a missing project
+func (p *projectRepository) ListProjectAncestors(ctx context.Context, userID, projectID uuid.UUID) ([]uuid.UUID, error) {
+	ids, err := p.queries.ListProjectAncestors(ctx, db.ListProjectAncestorsParams{
+		ProjectID: projectID,
+		UserID:    userID,
+	})
+	if err != nil {
+		return nil, errors.HandleRepositoryError(err, "list ancestors of", "project(s)")
+	}
+	return ids, nil
+}
+
+// GetProjectSubtreeDepth returns how many levels the project's subtree has, 1 without
+// sub-projects and 0 for a missing project
+func (p *projectRepository) GetProjectSubtreeDepth(ctx context.Context, userID, projectID uuid.UUID) (int32, error) {
+	depth, err := p.queries.GetProjectSubtreeDepth(ctx, db.GetProjectSubtreeDepthParams{
+		ProjectID: projectID,
+		UserID:    userID,
+	})
+	if err != nil {
+		return 0, errors.HandleRepositoryError(err, "get depth of", "project(s)")
+	}
+	return depth, nil
+}
+
+// GetProjectSummary totals the budget and wallet balances of the project and, with
+// rollup, of its sub-projects at any depth
+func (p *projectRepository) GetProjectSummary(ctx context.Context, userID, projectID uuid.UUID, rollup bool) (types.ProjectSummary, error) {
+	budget, err := p.queries.GetProjectBudgetRollup(ctx, db.GetProjectBudgetRollupParams{
+		ProjectID: projectID,
+		UserID:    userID,
+		Rollup:    rollup,
+	})
+	if err != nil {
+		return types.ProjectSummary{}, errors.HandleRepositoryError(err, "summarize", "project(s)")
+	}
+	if budget.Projects == 0 {
+		return types.ProjectSummary{}, errors.NewNotFoundError("project(s) not found")
+	}
+
+	balances, err := p.queries.ListProjectWalletBalances(ctx, db.ListProjectWalletBalancesParams{
+		ProjectID: projectID,
+		UserID:    userID,
+		Rollup:    rollup,
+	})
+	if err != nil {
+		return types.ProjectSummary{}, errors.HandleRepositoryError(err, "summarize", "project(s)")
+	}
+
+	summary := types.ProjectSummary{
+		ProjectID: projectID,
+		Rollup:    rollup,
+		Projects:  budget.Projects,
+		Budget:    budget.Budget,
+		Balances:  make([]types.CurrencyBalance, len(balances)),
+	}
+	for i, b := range balances {
+		summary.Wallets += b.Wallets
+		summary.Balances[i] = types.CurrencyBalance{Currency: b.Currency, Wallets: b.Wallets, Balance: b.Balance}
+	}
+	return summary, nil
+}
+
+func (p *projectRepository) ListDeletedProjectsPaginated(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, limit int32, order coreTypes.SortOrder) ([]types.Project, error) {
+	projects, err := p.queries.ListDeletedProjectsPaginated(ctx, db.ListDeletedProjectsPaginatedParams{
+		UserID:    userID,
+		SortOrder: string(order),
+		DeletedAt: utils.ToNullableTimestamp(&cursor),
+		ProjectID: cursorID,
+		Limit:     limit,
+	})
+	if err != nil {
+		return nil, errors.HandleRepositoryError(err, "list deleted", "project(s)")
+	}
+
+	return p.withProgresses(ctx, toProjects(projects))
+}
+
+func (p *projectRepository) RestoreProject(ctx context.Context, userID, projectID uuid.UUID) (types.Project, error) {
+	project, err := p.queries.RestoreProject(ctx, db.RestoreProjectParams{
+		UserID:    userID,
+		ProjectID: projectID,
+	})
+	if err != nil {
+		return types.Project{}, errors.HandleRepositoryError(err, "restore", "project(s)")
+	}
+
+	return p.withProgress(ctx, toProject(project))
+}
+
+// CloneProject copies the project, and with includeWallets its wallets with zeroed
+// balances, in a single statement so a failing wallet leaves no clone behind
+func (p *projectRepository) CloneProject(ctx context.Context, userID, projectID uuid.UUID, includeWallets bool) (types.Project, error) {
+	clone, err := p.queries.CloneProject(ctx, db.CloneProjectParams{
+		ProjectID:      projectID,
+		UserID:         userID,
+		ActorID:        requestcontext.GetActorIDFromContext(ctx, userID),
+		IncludeWallets: includeWallets,
+	})
+	if err != nil {
+		return types.Project{}, errors.HandleRepositoryError(err, "clone", "project(s)")
+	}
+
+	return toProject(db.Project(clone)), nil
+}
+
+// PublishProject turns the draft live when it has the fields the rules require, a project
+// missing one of them is reported as not found like a project that doesn't exist
+func (p *projectRepository) PublishProject(ctx context.Context, userID, projectID uuid.UUID, rules types.PublishRules) (types.Project, error) {
+	project, err := p.queries.PublishProject(ctx, db.PublishProjectParams{
+		ProjectID:        projectID,
+		UserID:           userID,
+		ActorID:          requestcontext.GetActorIDFromContext(ctx, userID),
+		RequireStartDate: rules.RequireStartDate,
+		RequireBudget:    rules.RequireBudget,
+	})
+	if err != nil {
+		return types.Project{}, errors.HandleRepositoryError(err, "publish", "project(s)")
+	}
+
+	return p.withProgress(ctx, toProject(db.Project(project)))
+}
+
 func (p *projectRepository) GetProjectWallets(ctx context.Context, userID, projectID uuid.UUID) ([]db.Wallet, error) {
 	wallets, err := p.queries.GetProjectWallets(ctx, db.GetProjectWalletsParams{
 		ProjectID: utils.ToNullableUUID(projectID),
@@ -152,53 +459,264 @@ func (p *projectRepository) GetProjectWallets(ctx context.Context, userID, proje
 	return wallets, nil
 }
 
-func (p *projectRepository) ListProjectsPaginated(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, limit int32) ([]types.Project, error) {
+func (p *projectRepository) ListProjectsPaginated(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, includeDrafts bool, limit int32, order coreTypes.SortOrder) ([]types.Project, error) {
 	projects, err := p.queries.ListProjectsPaginated(ctx, db.ListProjectsPaginatedParams{
-		UserID:    userID,
-		CreatedAt: utils.ToNullableTimestamp(&cursor),
-		ProjectID: cursorID,
-		Limit:     limit,
+		UserID:        userID,
+		IncludeDrafts: includeDrafts,
+		SortOrder:     string(order),
+		CreatedAt:     utils.ToNullableTimestamp(&cursor),
+		ProjectID:     cursorID,
+		Limit:         limit,
 	})
 	if err != nil {
 		return nil, errors.HandleRepositoryError(err, "list paginated", "project(s)")
 	}
 
-	return toProjects(projects), nil
+	return p.withProgresses(ctx, toProjects(projects))
 }
 
-func (p *projectRepository) SearchProjects(ctx context.Context, userID uuid.UUID, query string, limit int32) ([]types.Project, error) {
-	projects, err := p.queries.SearchProjects(ctx, db.SearchProjectsParams{
-		UserID: userID,
-		Name:   query,
-		Limit:  limit,
+// ListPinnedProjectsPaginated lists the pinned projects after the cursor, most recently pinned first
+func (p *projectRepository) ListPinnedProjectsPaginated(ctx context.Context, userID uuid.UUID, pinnedAt time.Time, cursorID uuid.UUID, includeDrafts bool, limit int32) ([]types.Project, error) {
+	projects, err := p.queries.ListPinnedProjectsPaginated(ctx, db.ListPinnedProjectsPaginatedParams{
+		UserID:        userID,
+		IncludeDrafts: includeDrafts,
+		PinnedAt:      utils.ToNullableTimestamp(&pinnedAt),
+		ProjectID:     cursorID,
+		Limit:         limit,
+	})
+	if err != nil {
+		return nil, errors.HandleRepositoryError(err, "list pinned", "project(s)")
+	}
+
+	return p.withProgresses(ctx, toProjects(projects))
+}
+
+// ListProjectIDsPaginated is ListProjectsPaginated reading only the IDs and creation times
+func (p *projectRepository) ListProjectIDsPaginated(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, includeDrafts bool, limit int32, order coreTypes.SortOrder) ([]coreTypes.KeyedID, error) {
+	rows, err := p.queries.ListProjectIDsPaginated(ctx, db.ListProjectIDsPaginatedParams{
+		UserID:        userID,
+		IncludeDrafts: includeDrafts,
+		SortOrder:     string(order),
+		CreatedAt:     utils.ToNullableTimestamp(&cursor),
+		ProjectID:     cursorID,
+		Limit:         limit,
+	})
+	if err != nil {
+		return nil, errors.HandleRepositoryError(err, "list paginated", "project(s)")
+	}
+
+	keys := make([]coreTypes.KeyedID, len(rows))
+	for i, row := range rows {
+		keys[i] = coreTypes.KeyedID{ID: row.ProjectID, CreatedAt: row.CreatedAt.Time}
+	}
+	return keys, nil
+}
+
+// ListPinnedProjectIDsPaginated is ListPinnedProjectsPaginated reading only the IDs and pin times
+func (p *projectRepository) ListPinnedProjectIDsPaginated(ctx context.Context, userID uuid.UUID, pinnedAt time.Time, cursorID uuid.UUID, includeDrafts bool, limit int32) ([]coreTypes.KeyedID, error) {
+	rows, err := p.queries.ListPinnedProjectIDsPaginated(ctx, db.ListPinnedProjectIDsPaginatedParams{
+		UserID:        userID,
+		IncludeDrafts: includeDrafts,
+		PinnedAt:      utils.ToNullableTimestamp(&pinnedAt),
+		ProjectID:     cursorID,
+		Limit:         limit,
+	})
+	if err != nil {
+		return nil, errors.HandleRepositoryError(err, "list pinned", "project(s)")
+	}
+
+	keys := make([]coreTypes.KeyedID, len(rows))
+	for i, row := range rows {
+		keys[i] = coreTypes.KeyedID{ID: row.ProjectID, PinnedAt: &row.PinnedAt.Time}
+	}
+	return keys, nil
+}
+
+func (p *projectRepository) ListProjectsPaginatedStream(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, includeDrafts bool, limit int32, order coreTypes.SortOrder, fn func(types.Project) error) error {
+	return p.streamProjects(ctx, func(scan func(db.Project) error) error {
+		return p.queries.ListProjectsPaginatedStream(ctx, db.ListProjectsPaginatedParams{
+			UserID:        userID,
+			IncludeDrafts: includeDrafts,
+			SortOrder:     string(order),
+			CreatedAt:     utils.ToNullableTimestamp(&cursor),
+			ProjectID:     cursorID,
+			Limit:         limit,
+		}, scan)
+	}, fn, "list paginated")
+}
+
+func (p *projectRepository) ListPinnedProjectsPaginatedStream(ctx context.Context, userID uuid.UUID, pinnedAt time.Time, cursorID uuid.UUID, includeDrafts bool, limit int32, fn func(types.Project) error) error {
+	return p.streamProjects(ctx, func(scan func(db.Project) error) error {
+		return p.queries.ListPinnedProjectsPaginatedStream(ctx, db.ListPinnedProjectsPaginatedParams{
+			UserID:        userID,
+			IncludeDrafts: includeDrafts,
+			PinnedAt:      utils.ToNullableTimestamp(&pinnedAt),
+			ProjectID:     cursorID,
+			Limit:         limit,
+		}, scan)
+	}, fn, "list pinned")
+}
+
+// progressBatchSize is the number of streamed projects whose milestone progress is looked
+// up together
+const progressBatchSize = 20
+
+// streamProjects hands the projects stream reads to fn, filling in their milestone progress
+// progressBatchSize projects at a time so a stream costs one progress query per batch
+func (p *projectRepository) streamProjects(ctx context.Context, stream func(scan func(db.Project) error) error, fn func(types.Project) error, operation string) error {
+	batch := make([]types.Project, 0, progressBatchSize)
+	// errors from fn and the progress lookup are returned as they are
+	var stopErr error
+	flush := func() error {
+		projects, err := p.withProgresses(ctx, batch)
+		if err != nil {
+			stopErr = err
+			return err
+		}
+		batch = batch[:0]
+		for _, project := range projects {
+			if stopErr = fn(project); stopErr != nil {
+				return stopErr
+			}
+		}
+		return nil
+	}
+
+	err := stream(func(project db.Project) error {
+		batch = append(batch, toProject(project))
+		if len(batch) < progressBatchSize {
+			return nil
+		}
+		return flush()
+	})
+	if stopErr != nil {
+		return stopErr
+	}
+	if err != nil {
+		return errors.HandleRepositoryError(err, operation, "project(s)")
+	}
+	flush()
+	return stopErr
+}
+
+// SetProjectPinned pins or unpins the project, a project pinned again keeps its pin time.
+// A pin past types.MaxPinnedProjects returns ErrPinnedProjectLimit, the count and the pin
+// run in a transaction holding the user's advisory lock so concurrent pins can't both take
+// the last slot.
+func (p *projectRepository) SetProjectPinned(ctx context.Context, userID, projectID uuid.UUID, pinned bool) (types.Project, error) {
+	params := db.SetProjectPinnedParams{
+		Pinned:    pinned,
+		ProjectID: projectID,
+		UserID:    userID,
+	}
+	var project db.Project
+	var err error
+	if pinned {
+		err = p.queries.WithEntityLock(ctx, userID, func(q *db.Queries) error {
+			current, err := q.GetProject(ctx, db.GetProjectParams{ProjectID: projectID, UserID: userID})
+			if err != nil {
+				return err
+			}
+			if !current.PinnedAt.Valid {
+				count, err := q.CountPinnedProjects(ctx, userID)
+				if err != nil {
+					return err
+				}
+				if count >= types.MaxPinnedProjects {
+					return ErrPinnedProjectLimit
+				}
+			}
+			project, err = q.SetProjectPinned(ctx, params)
+			return err
+		})
+	} else {
+		project, err = p.queries.SetProjectPinned(ctx, params)
+	}
+	if stdErrors.Is(err, ErrPinnedProjectLimit) {
+		return types.Project{}, err
+	}
+	if err != nil {
+		return types.Project{}, errors.HandleRepositoryError(err, "pin", "project(s)")
+	}
+
+	return p.withProgress(ctx, toProject(project))
+}
+
+func (p *projectRepository) SearchProjects(ctx context.Context, userID uuid.UUID, query string, includeDrafts bool, limit, offset int32) ([]types.Project, error) {
+	rows, err := p.queries.SearchProjects(ctx, db.SearchProjectsParams{
+		UserID:        userID,
+		IncludeDrafts: includeDrafts,
+		Name:          query,
+		Limit:         limit,
+		Offset:        offset,
 	})
 	if err != nil {
 		return nil, errors.HandleRepositoryError(err, "search", "project(s)")
 	}
 
-	return toProjects(projects), nil
+	projects := make([]types.Project, len(rows))
+	for i, row := range rows {
+		projects[i] = toProject(row.Project)
+		if query != "" {
+			projects[i].Score = &row.Score
+		}
+	}
+	return p.withProgresses(ctx, projects)
+}
+
+// SearchProjectsPicker searches projects like SearchProjects, reading only the columns of
+// the picker view
+func (p *projectRepository) SearchProjectsPicker(ctx context.Context, userID uuid.UUID, query string, includeDrafts bool, limit, offset int32) ([]types.ProjectPickerItem, error) {
+	rows, err := p.queries.SearchProjectsPicker(ctx, db.SearchProjectsPickerParams{
+		UserID:        userID,
+		IncludeDrafts: includeDrafts,
+		Name:          query,
+		Limit:         limit,
+		Offset:        offset,
+	})
+	if err != nil {
+		return nil, errors.HandleRepositoryError(err, "search", "project(s)")
+	}
+
+	items := make([]types.ProjectPickerItem, len(rows))
+	for i, row := range rows {
+		items[i] = types.ProjectPickerItem{
+			ProjectID: row.ProjectID,
+			Name:      row.Name,
+			Status:    string(row.Status),
+			EndDate:   utils.GetDatePtr(row.EndDate),
+		}
+	}
+	return items, nil
 }
 
 // Helper functions to convert between domain and database types
 func toProject(p db.Project) types.Project {
 	return types.Project{
-		ProjectID:     p.ProjectID,
-		Name:          p.Name,
-		Description:   utils.PgtextToStringPtr(p.Description),
-		Status:        string(p.Status),
-		StartDate:     utils.GetTimePtr(p.StartDate),
-		EndDate:       utils.GetTimePtr(p.EndDate),
-		Budget:        utils.GetFloat64Ptr(p.Budget),
-		AddressLine1:  utils.PgtextToStringPtr(p.AddressLine1),
-		AddressLine2:  utils.PgtextToStringPtr(p.AddressLine2),
-		Country:       utils.PgtextToStringPtr(p.Country),
-		City:          utils.PgtextToStringPtr(p.City),
-		StateProvince: utils.PgtextToStringPtr(p.StateProvince),
-		ZipPostalCode: utils.PgtextToStringPtr(p.ZipPostalCode),
-		Website:       utils.PgtextToStringPtr(p.Website),
-		Tags:          p.Tags,
-		CreatedAt:     p.CreatedAt.Time,
-		UpdatedAt:     p.UpdatedAt.Time,
+		ProjectID:       p.ProjectID,
+		Name:            p.Name,
+		Description:     utils.PgtextToStringPtr(p.Description),
+		Status:          string(p.Status),
+		StartDate:       utils.GetDatePtr(p.StartDate),
+		EndDate:         utils.GetDatePtr(p.EndDate),
+		Budget:          utils.GetFloat64Ptr(p.Budget),
+		AddressLine1:    utils.PgtextToStringPtr(p.AddressLine1),
+		AddressLine2:    utils.PgtextToStringPtr(p.AddressLine2),
+		Country:         utils.PgtextToStringPtr(p.Country),
+		City:            utils.PgtextToStringPtr(p.City),
+		StateProvince:   utils.PgtextToStringPtr(p.StateProvince),
+		ZipPostalCode:   utils.PgtextToStringPtr(p.ZipPostalCode),
+		Website:         utils.PgtextToStringPtr(p.Website),
+		Tags:            p.Tags,
+		ParentProjectID: utils.GetUUIDPtr(p.ParentProjectID),
+		CreatedAt:       coreTypes.NewTimestamp(p.CreatedAt.Time),
+		UpdatedAt:       coreTypes.NewTimestamp(p.UpdatedAt.Time),
+		DeletedAt:       utils.GetTimestampPtr(p.DeletedAt),
+		Pinned:          p.PinnedAt.Valid,
+		PinnedAt:        utils.GetTimestampPtr(p.PinnedAt),
+		Draft:           p.IsDraft,
+		CreatedBy:       utils.GetUUIDPtr(p.CreatedBy),
+		UpdatedBy:       utils.GetUUIDPtr(p.UpdatedBy),
 	}
 }
 
@@ -4,9 +4,13 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	coreRepository "github.com/Abdelrahman-habib/expense-tracker/internal/core/repository"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/projects/repository"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/projects/types"
@@ -186,8 +190,8 @@ func (s *ProjectRepositoryTestSuite) TestCreateProject() {
 				Name:          "Full Project",
 				Description:   utils.StringPtr("Test Description"),
 				Status:        "ongoing",
-				StartDate:     utils.TimePtr(now),
-				EndDate:       utils.TimePtr(now.Add(24 * time.Hour)),
+				StartDate:     datePtr(now),
+				EndDate:       datePtr(now.Add(24 * time.Hour)),
 				Budget:        utils.Float64Ptr(1000.50),
 				Website:       utils.StringPtr("https://test.com"),
 				Country:       utils.StringPtr("US"),
@@ -221,15 +225,11 @@ func (s *ProjectRepositoryTestSuite) TestCreateProject() {
 			}
 			if tt.payload.StartDate != nil {
 				s.NotNil(project.StartDate)
-				s.WithinDuration(*tt.payload.StartDate, *project.StartDate, time.Second,
-					"StartDate not within expected duration: got %v, want %v",
-					project.StartDate, tt.payload.StartDate)
+				s.Equal(*tt.payload.StartDate, *project.StartDate)
 			}
 			if tt.payload.EndDate != nil {
 				s.NotNil(project.EndDate)
-				s.WithinDuration(*tt.payload.EndDate, *project.EndDate, time.Second,
-					"EndDate not within expected duration: got %v, want %v",
-					project.EndDate, tt.payload.EndDate)
+				s.Equal(*tt.payload.EndDate, *project.EndDate)
 			}
 			if tt.payload.Budget != nil {
 				s.NotNil(project.Budget)
@@ -323,6 +323,91 @@ func (s *ProjectRepositoryTestSuite) TestGetProject() {
 	}
 }
 
+func (s *ProjectRepositoryTestSuite) TestGetProjectByName() {
+	created, err := s.repo.CreateProject(s.ctx, s.testUser, types.ProjectCreatePayload{
+		Name:   "Garden Shed",
+		Status: "ongoing",
+	})
+	require.NoError(s.T(), err)
+
+	trashed, err := s.repo.CreateProject(s.ctx, s.testUser, types.ProjectCreatePayload{
+		Name:   "Old Garage",
+		Status: "ongoing",
+	})
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), s.repo.DeleteProject(s.ctx, s.testUser, trashed.ProjectID, nil))
+
+	tests := []struct {
+		name    string
+		userID  uuid.UUID
+		lookup  string
+		wantErr bool
+	}{
+		{name: "same case", userID: s.testUser, lookup: "Garden Shed"},
+		{name: "different case", userID: s.testUser, lookup: "gARDEN sHED"},
+		{name: "other name", userID: s.testUser, lookup: "Garden", wantErr: true},
+		{name: "wrong user", userID: uuid.New(), lookup: "Garden Shed", wantErr: true},
+		{name: "trashed project", userID: s.testUser, lookup: "old garage", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			project, err := s.repo.GetProjectByName(s.ctx, tt.userID, tt.lookup)
+			if tt.wantErr {
+				s.True(errors.IsErrorType(err, errors.ErrorTypeNotFound), "got %v", err)
+				return
+			}
+
+			s.NoError(err)
+			s.Equal(created.ProjectID, project.ProjectID)
+			s.Equal("Garden Shed", project.Name)
+		})
+	}
+}
+
+func (s *ProjectRepositoryTestSuite) TestDeleteProject_Version() {
+	deletes := map[string]func(projectID uuid.UUID, version *time.Time) error{
+		"restrict": func(projectID uuid.UUID, version *time.Time) error {
+			return s.repo.DeleteProject(s.ctx, s.testUser, projectID, version)
+		},
+		"cascade": func(projectID uuid.UUID, version *time.Time) error {
+			return s.repo.DeleteProjectTree(s.ctx, s.testUser, projectID, version)
+		},
+		"detach": func(projectID uuid.UUID, version *time.Time) error {
+			return s.repo.DeleteProjectDetachingChildren(s.ctx, s.testUser, projectID, version)
+		},
+	}
+
+	for name, deleteProject := range deletes {
+		s.Run(name, func() {
+			parent, err := s.repo.CreateProject(s.ctx, s.testUser, types.ProjectCreatePayload{Name: "Versioned " + name, Status: "ongoing"})
+			s.Require().NoError(err)
+			child, err := s.repo.CreateProject(s.ctx, s.testUser, types.ProjectCreatePayload{Name: "Versioned child " + name, Status: "ongoing", ParentProjectID: &parent.ProjectID})
+			s.Require().NoError(err)
+			read, err := s.repo.GetProject(s.ctx, s.testUser, parent.ProjectID)
+			s.Require().NoError(err)
+
+			// an update committed between the read and the delete fails it, leaving the sub-project as it was
+			payload := read.ToUpdatePayload()
+			payload.Name = "Renamed " + name
+			updated, err := s.repo.UpdateProject(s.ctx, s.testUser, payload)
+			s.Require().NoError(err)
+
+			err = deleteProject(parent.ProjectID, &read.UpdatedAt.Time)
+			s.ErrorIs(err, coreRepository.ErrPreconditionFailed)
+			_, err = s.repo.GetProject(s.ctx, s.testUser, parent.ProjectID)
+			s.Require().NoError(err, "the project is still live")
+			kept, err := s.repo.GetProject(s.ctx, s.testUser, child.ProjectID)
+			s.Require().NoError(err, "the sub-project is still live")
+			s.Equal(&parent.ProjectID, kept.ParentProjectID, "the sub-project is still attached")
+
+			s.Require().NoError(deleteProject(parent.ProjectID, &updated.UpdatedAt.Time))
+			err = deleteProject(parent.ProjectID, &updated.UpdatedAt.Time)
+			s.ErrorIs(err, coreRepository.ErrNotFound, "a trashed project is missing whatever the version")
+		})
+	}
+}
+
 func (s *ProjectRepositoryTestSuite) TestUpdateProject() {
 	// Helper function to create a fresh project for each test case
 	createInitialProject := func() types.Project {
@@ -331,8 +416,8 @@ func (s *ProjectRepositoryTestSuite) TestUpdateProject() {
 			Name:          "Test Project",
 			Description:   stringPtr("Initial description"),
 			Status:        "ongoing",
-			StartDate:     &now,
-			EndDate:       timePtr(now.Add(24 * time.Hour)),
+			StartDate:     datePtr(now),
+			EndDate:       datePtr(now.Add(24 * time.Hour)),
 			Budget:        float64Ptr(1000.50),
 			AddressLine1:  stringPtr("123 Main St"),
 			AddressLine2:  stringPtr("Suite 100"),
@@ -341,7 +426,7 @@ func (s *ProjectRepositoryTestSuite) TestUpdateProject() {
 			StateProvince: stringPtr("CA"),
 			ZipPostalCode: stringPtr("12345"),
 			Website:       stringPtr("https://example.com"),
-			Tags:          []uuid.UUID{uuid.New(), uuid.New()},
+			Tags:          s.createTestTags(2),
 		}
 
 		project, err := s.repo.CreateProject(s.ctx, s.testUser, createPayload)
@@ -449,7 +534,7 @@ func (s *ProjectRepositoryTestSuite) TestUpdateProject() {
 			name:  "update_tags_only",
 			setup: createInitialProject,
 			payload: func(p types.Project) types.ProjectUpdatePayload {
-				newTags := []uuid.UUID{uuid.New(), uuid.New()}
+				newTags := s.createTestTags(2)
 				return types.ProjectUpdatePayload{
 					ProjectID: p.ProjectID,
 					Name:      p.Name,
@@ -549,8 +634,8 @@ func (s *ProjectRepositoryTestSuite) TestListProjectsPaginated() {
 		},
 		{
 			name:      "get second page",
-			cursor:    createdProjects[2].CreatedAt, // Use Project 3's timestamp
-			cursorID:  createdProjects[2].ProjectID, // Use Project 3's ID
+			cursor:    createdProjects[2].CreatedAt.Time, // Use Project 3's timestamp
+			cursorID:  createdProjects[2].ProjectID,      // Use Project 3's ID
 			limit:     2,
 			wantLen:   2,
 			wantNames: []string{"Project 2", "Project 1"}, // Next oldest pair
@@ -558,8 +643,8 @@ func (s *ProjectRepositoryTestSuite) TestListProjectsPaginated() {
 		},
 		{
 			name:      "get empty page",
-			cursor:    createdProjects[0].CreatedAt, // Use oldest project's timestamp
-			cursorID:  createdProjects[0].ProjectID, // Use oldest project's ID
+			cursor:    createdProjects[0].CreatedAt.Time, // Use oldest project's timestamp
+			cursorID:  createdProjects[0].ProjectID,      // Use oldest project's ID
 			limit:     2,
 			wantLen:   0,
 			wantNames: []string{},
@@ -576,7 +661,7 @@ func (s *ProjectRepositoryTestSuite) TestListProjectsPaginated() {
 
 	for _, tt := range tests {
 		s.Run(tt.name, func() {
-			projects, err := s.repo.ListProjectsPaginated(s.ctx, s.testUser, tt.cursor, tt.cursorID, tt.limit)
+			projects, err := s.repo.ListProjectsPaginated(s.ctx, s.testUser, tt.cursor, tt.cursorID, false, tt.limit, coreTypes.SortOrderDesc)
 			if tt.wantErr {
 				s.Error(err)
 				return
@@ -596,8 +681,8 @@ func (s *ProjectRepositoryTestSuite) TestListProjectsPaginated() {
 			// Verify ordering for non-empty results
 			if len(projects) > 1 {
 				for i := 1; i < len(projects); i++ {
-					isCorrectOrder := projects[i-1].CreatedAt.After(projects[i].CreatedAt) ||
-						(projects[i-1].CreatedAt.Equal(projects[i].CreatedAt) &&
+					isCorrectOrder := projects[i-1].CreatedAt.After(projects[i].CreatedAt.Time) ||
+						(projects[i-1].CreatedAt.Equal(projects[i].CreatedAt.Time) &&
 							projects[i-1].ProjectID.String() > projects[i].ProjectID.String())
 					s.True(isCorrectOrder, "Projects should be ordered by created_at DESC and then by project_id DESC")
 				}
@@ -606,6 +691,280 @@ func (s *ProjectRepositoryTestSuite) TestListProjectsPaginated() {
 	}
 }
 
+func (s *ProjectRepositoryTestSuite) TestPublishProject() {
+	draft, err := s.repo.CreateProject(s.ctx, s.testUser, types.ProjectCreatePayload{Name: "Draft Project", Status: "ongoing", Draft: true})
+	s.Require().NoError(err)
+
+	published, err := s.repo.PublishProject(s.ctx, s.testUser, draft.ProjectID, types.PublishRules{})
+	s.Require().NoError(err)
+	s.False(published.Draft)
+	s.True(published.UpdatedAt.After(draft.UpdatedAt.Time))
+
+	// publishing a live project changes nothing, its version included
+	again, err := s.repo.PublishProject(s.ctx, s.testUser, draft.ProjectID, types.PublishRules{})
+	s.Require().NoError(err)
+	s.Equal(published.UpdatedAt, again.UpdatedAt)
+}
+
+func (s *ProjectRepositoryTestSuite) TestCreateMilestone_Limit() {
+	project, err := s.repo.CreateProject(s.ctx, s.testUser, types.ProjectCreatePayload{Name: "Busy Project", Status: "ongoing"})
+	s.Require().NoError(err)
+	for i := 0; i < types.MaxMilestones-5; i++ {
+		_, err := s.repo.CreateMilestone(s.ctx, s.testUser, project.ProjectID, types.MilestoneCreatePayload{Name: fmt.Sprintf("Milestone %d", i)})
+		s.Require().NoError(err)
+	}
+
+	// concurrent creates race for the last five slots
+	var wg sync.WaitGroup
+	errs := make([]error, 20)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = s.repo.CreateMilestone(s.ctx, s.testUser, project.ProjectID, types.MilestoneCreatePayload{Name: fmt.Sprintf("Late %d", i)})
+		}(i)
+	}
+	wg.Wait()
+
+	created := 0
+	for _, err := range errs {
+		if err == nil {
+			created++
+			continue
+		}
+		s.ErrorIs(err, repository.ErrMilestoneLimit)
+	}
+	s.Equal(5, created)
+
+	milestones, err := s.repo.ListMilestones(s.ctx, s.testUser, project.ProjectID)
+	s.Require().NoError(err)
+	s.Len(milestones, types.MaxMilestones)
+}
+
+func (s *ProjectRepositoryTestSuite) TestPinnedProjects() {
+	var created []types.Project
+	for _, name := range []string{"Project 1", "Project 2", "Project 3"} {
+		project, err := s.repo.CreateProject(s.ctx, s.testUser, types.ProjectCreatePayload{Name: name, Status: "ongoing"})
+		s.Require().NoError(err)
+		created = append(created, project)
+	}
+
+	first, err := s.repo.SetProjectPinned(s.ctx, s.testUser, created[0].ProjectID, true)
+	s.Require().NoError(err)
+	s.True(first.Pinned)
+	s.Require().NotNil(first.PinnedAt)
+	s.Equal(created[0].UpdatedAt, first.UpdatedAt, "pinning isn't an edit")
+
+	time.Sleep(10 * time.Millisecond) // distinct pin times
+	_, err = s.repo.SetProjectPinned(s.ctx, s.testUser, created[2].ProjectID, true)
+	s.Require().NoError(err)
+
+	// pinning again keeps the pin time
+	again, err := s.repo.SetProjectPinned(s.ctx, s.testUser, created[0].ProjectID, true)
+	s.Require().NoError(err)
+	s.Equal(first.PinnedAt, again.PinnedAt)
+
+	count, err := s.queries.CountPinnedProjects(s.ctx, s.testUser)
+	s.Require().NoError(err)
+	s.Equal(int64(2), count)
+
+	start, startID := coreTypes.StartPinnedCursor()
+	pinned, err := s.repo.ListPinnedProjectsPaginated(s.ctx, s.testUser, start, startID, false, 10)
+	s.Require().NoError(err)
+	s.Require().Len(pinned, 2)
+	s.Equal("Project 3", pinned[0].Name, "most recently pinned first")
+	s.Equal("Project 1", pinned[1].Name)
+
+	rest, err := s.repo.ListPinnedProjectsPaginated(s.ctx, s.testUser, pinned[0].PinnedAt.Time, pinned[0].ProjectID, false, 10)
+	s.Require().NoError(err)
+	s.Require().Len(rest, 1)
+	s.Equal("Project 1", rest[0].Name)
+
+	unpinnedStart, unpinnedStartID := coreTypes.StartCursor(coreTypes.SortOrderDesc)
+	unpinned, err := s.repo.ListProjectsPaginated(s.ctx, s.testUser, unpinnedStart, unpinnedStartID, false, 10, coreTypes.SortOrderDesc)
+	s.Require().NoError(err)
+	s.Require().Len(unpinned, 1)
+	s.Equal("Project 2", unpinned[0].Name)
+
+	// unpinning and trashing both drop the pin
+	unpinnedProject, err := s.repo.SetProjectPinned(s.ctx, s.testUser, created[2].ProjectID, false)
+	s.Require().NoError(err)
+	s.False(unpinnedProject.Pinned)
+	s.Nil(unpinnedProject.PinnedAt)
+	s.Require().NoError(s.repo.DeleteProject(s.ctx, s.testUser, created[0].ProjectID, nil))
+	restored, err := s.repo.RestoreProject(s.ctx, s.testUser, created[0].ProjectID)
+	s.Require().NoError(err)
+	s.False(restored.Pinned)
+
+	count, err = s.queries.CountPinnedProjects(s.ctx, s.testUser)
+	s.Require().NoError(err)
+	s.Zero(count)
+}
+
+func (s *ProjectRepositoryTestSuite) TestPinnedProjects_Limit() {
+	var created []types.Project
+	for i := 0; i < types.MaxPinnedProjects+15; i++ {
+		project, err := s.repo.CreateProject(s.ctx, s.testUser, types.ProjectCreatePayload{Name: fmt.Sprintf("Pinnable %d", i), Status: "ongoing"})
+		s.Require().NoError(err)
+		created = append(created, project)
+	}
+	for _, project := range created[:types.MaxPinnedProjects-5] {
+		_, err := s.repo.SetProjectPinned(s.ctx, s.testUser, project.ProjectID, true)
+		s.Require().NoError(err)
+	}
+
+	// concurrent pins race for the last five slots
+	rest := created[types.MaxPinnedProjects-5:]
+	var wg sync.WaitGroup
+	errs := make([]error, len(rest))
+	for i, project := range rest {
+		wg.Add(1)
+		go func(i int, projectID uuid.UUID) {
+			defer wg.Done()
+			_, errs[i] = s.repo.SetProjectPinned(s.ctx, s.testUser, projectID, true)
+		}(i, project.ProjectID)
+	}
+	wg.Wait()
+
+	pinned := 0
+	for _, err := range errs {
+		if err == nil {
+			pinned++
+			continue
+		}
+		s.ErrorIs(err, repository.ErrPinnedProjectLimit)
+	}
+	s.Equal(5, pinned)
+
+	count, err := s.queries.CountPinnedProjects(s.ctx, s.testUser)
+	s.Require().NoError(err)
+	s.Equal(int64(types.MaxPinnedProjects), count)
+
+	// a pinned project pinned again at the limit keeps its pin
+	again, err := s.repo.SetProjectPinned(s.ctx, s.testUser, created[0].ProjectID, true)
+	s.Require().NoError(err)
+	s.True(again.Pinned)
+}
+
+func (s *ProjectRepositoryTestSuite) TestProjectTree() {
+	create := func(name string, budget float64, parentID *uuid.UUID) types.Project {
+		project, err := s.repo.CreateProject(s.ctx, s.testUser, types.ProjectCreatePayload{
+			Name:            name,
+			Status:          "ongoing",
+			Budget:          float64Ptr(budget),
+			ParentProjectID: parentID,
+		})
+		s.Require().NoError(err)
+		return project
+	}
+	addWallet := func(projectID uuid.UUID, balance float64, currency string) {
+		_, err := s.pool.Exec(s.ctx, `
+			INSERT INTO wallets (user_id, project_id, name, balance, currency)
+			VALUES ($1, $2, $3, $4, $5)`, s.testUser, projectID, "Wallet "+uuid.NewString(), balance, currency)
+		s.Require().NoError(err)
+	}
+
+	root := create("Root", 1000, nil)
+	child := create("Child", 300, &root.ProjectID)
+	grandchild := create("Grandchild", 50, &child.ProjectID)
+	s.Equal(&root.ProjectID, child.ParentProjectID)
+
+	addWallet(root.ProjectID, 100, "USD")
+	addWallet(child.ProjectID, 25.5, "USD")
+	addWallet(grandchild.ProjectID, 10, "EUR")
+
+	ancestors, err := s.repo.ListProjectAncestors(s.ctx, s.testUser, grandchild.ProjectID)
+	s.Require().NoError(err)
+	s.Equal([]uuid.UUID{grandchild.ProjectID, child.ProjectID, root.ProjectID}, ancestors)
+
+	depth, err := s.repo.GetProjectSubtreeDepth(s.ctx, s.testUser, root.ProjectID)
+	s.Require().NoError(err)
+	s.Equal(int32(3), depth)
+
+	children, err := s.repo.ListChildProjects(s.ctx, s.testUser, root.ProjectID)
+	s.Require().NoError(err)
+	s.Require().Len(children, 1)
+	s.Equal(child.ProjectID, children[0].ProjectID)
+
+	own, err := s.repo.GetProjectSummary(s.ctx, s.testUser, root.ProjectID, false)
+	s.Require().NoError(err)
+	s.Equal(int64(1), own.Projects)
+	s.Equal(1000.0, own.Budget)
+	s.Equal(int64(1), own.Wallets)
+	s.Equal([]types.CurrencyBalance{{Currency: "USD", Wallets: 1, Balance: 100}}, own.Balances)
+
+	rolled, err := s.repo.GetProjectSummary(s.ctx, s.testUser, root.ProjectID, true)
+	s.Require().NoError(err)
+	s.Equal(int64(3), rolled.Projects)
+	s.Equal(1350.0, rolled.Budget)
+	s.Equal(int64(3), rolled.Wallets)
+	s.Equal([]types.CurrencyBalance{
+		{Currency: "EUR", Wallets: 1, Balance: 10},
+		{Currency: "USD", Wallets: 2, Balance: 125.5},
+	}, rolled.Balances)
+
+	// a trashed sub-project drops out of the rollup along with its descendants
+	s.Require().NoError(s.repo.DeleteProject(s.ctx, s.testUser, child.ProjectID, nil))
+	rolled, err = s.repo.GetProjectSummary(s.ctx, s.testUser, root.ProjectID, true)
+	s.Require().NoError(err)
+	s.Equal(int64(1), rolled.Projects)
+	s.Equal(1000.0, rolled.Budget)
+
+	_, err = s.repo.RestoreProject(s.ctx, s.testUser, child.ProjectID)
+	s.Require().NoError(err)
+
+	// detaching moves the children to the top level
+	s.Require().NoError(s.repo.DeleteProjectDetachingChildren(s.ctx, s.testUser, child.ProjectID, nil))
+	detached, err := s.repo.GetProject(s.ctx, s.testUser, grandchild.ProjectID)
+	s.Require().NoError(err)
+	s.Nil(detached.ParentProjectID)
+
+	// cascading trashes the whole tree
+	_, err = s.repo.RestoreProject(s.ctx, s.testUser, child.ProjectID)
+	s.Require().NoError(err)
+	s.Require().NoError(s.repo.DeleteProjectTree(s.ctx, s.testUser, root.ProjectID, nil))
+	for _, id := range []uuid.UUID{root.ProjectID, child.ProjectID} {
+		_, err = s.repo.GetProject(s.ctx, s.testUser, id)
+		s.Error(err)
+	}
+	_, err = s.repo.GetProject(s.ctx, s.testUser, grandchild.ProjectID)
+	s.NoError(err, "the detached project is no longer in the tree")
+
+	_, err = s.repo.GetProjectSummary(s.ctx, s.testUser, root.ProjectID, true)
+	s.True(errors.IsErrorType(err, errors.ErrorTypeNotFound))
+}
+
+func (s *ProjectRepositoryTestSuite) TestGetProjectSummary_Currencies() {
+	project, err := s.repo.CreateProject(s.ctx, s.testUser, types.ProjectCreatePayload{Name: "Trip", Status: "ongoing"})
+	s.Require().NoError(err)
+	addWallet := func(balance float64, currency string) uuid.UUID {
+		var walletID uuid.UUID
+		err := s.pool.QueryRow(s.ctx, `
+			INSERT INTO wallets (user_id, project_id, name, balance, currency)
+			VALUES ($1, $2, $3, $4, $5) RETURNING wallet_id`, s.testUser, project.ProjectID, "Wallet "+uuid.NewString(), balance, currency).Scan(&walletID)
+		s.Require().NoError(err)
+		return walletID
+	}
+
+	addWallet(100, "USD")
+	addWallet(50.25, "USD")
+	addWallet(80, "EUR")
+	addWallet(12000, "JPY")
+	trashed := addWallet(999, "EUR")
+	_, err = s.pool.Exec(s.ctx, `UPDATE wallets SET deleted_at = NOW() WHERE wallet_id = $1`, trashed)
+	s.Require().NoError(err)
+
+	summary, err := s.repo.GetProjectSummary(s.ctx, s.testUser, project.ProjectID, false)
+	s.Require().NoError(err)
+	s.Equal(int64(4), summary.Wallets)
+	s.Equal([]types.CurrencyBalance{
+		{Currency: "EUR", Wallets: 1, Balance: 80},
+		{Currency: "JPY", Wallets: 1, Balance: 12000},
+		{Currency: "USD", Wallets: 2, Balance: 150.25},
+	}, summary.Balances, "one balance per currency, in currency order, without the trashed wallet")
+	s.Nil(summary.Converted)
+}
+
 func (s *ProjectRepositoryTestSuite) TestSearchProjects() {
 	// Create test projects with various names to test different search scenarios
 	projects := []types.ProjectCreatePayload{
@@ -757,7 +1116,7 @@ func (s *ProjectRepositoryTestSuite) TestSearchProjects() {
 
 	for _, tt := range tests {
 		s.Run(tt.name, func() {
-			projects, err := s.repo.SearchProjects(s.ctx, s.testUser, tt.query, tt.limit)
+			projects, err := s.repo.SearchProjects(s.ctx, s.testUser, tt.query, false, tt.limit, 0)
 			if tt.wantErr {
 				s.Error(err)
 				return
@@ -799,6 +1158,22 @@ func timePtr(t time.Time) *time.Time {
 	return &t
 }
 
+func datePtr(t time.Time) *coreTypes.Date {
+	return coreTypes.DatePtr(&t)
+}
+
 func float64Ptr(f float64) *float64 {
 	return &f
 }
+
+// createTestTags creates tags owned by the test user; tags the user doesn't own are dropped on write
+func (s *ProjectRepositoryTestSuite) createTestTags(count int) []uuid.UUID {
+	tags := make([]uuid.UUID, count)
+	for i := range tags {
+		err := s.pool.QueryRow(s.ctx, `
+			INSERT INTO tags (user_id, name) VALUES ($1, $2) RETURNING tag_id
+		`, s.testUser, fmt.Sprintf("test tag %d", i)).Scan(&tags[i])
+		s.Require().NoError(err)
+	}
+	return tags
+}
@@ -0,0 +1,280 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/tracing"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/projects/types"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedProjectRepository records a span around each call of the wrapped repository
+type tracedProjectRepository struct {
+	next   ProjectRepository
+	tracer trace.Tracer
+}
+
+// NewTracedProjectRepository wraps next so each call records a span named after the method, a nil
+// tracer returns next as is
+func NewTracedProjectRepository(next ProjectRepository, tracer trace.Tracer) ProjectRepository {
+	if tracer == nil {
+		return next
+	}
+	return &tracedProjectRepository{next: next, tracer: tracer}
+}
+
+func (t *tracedProjectRepository) ListProjects(ctx context.Context, userID uuid.UUID) ([]types.Project, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.ListProjects")
+	projects, err := t.next.ListProjects(ctx, userID)
+	tracing.End(span, err)
+	return projects, err
+}
+
+func (t *tracedProjectRepository) GetProject(ctx context.Context, userID, projectID uuid.UUID) (types.Project, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.GetProject")
+	project, err := t.next.GetProject(ctx, userID, projectID)
+	tracing.End(span, err)
+	return project, err
+}
+
+func (t *tracedProjectRepository) GetProjectsByIDs(ctx context.Context, userID uuid.UUID, projectIDs []uuid.UUID) ([]types.Project, []uuid.UUID, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.GetProjectsByIDs")
+	projects, missing, err := t.next.GetProjectsByIDs(ctx, userID, projectIDs)
+	tracing.End(span, err)
+	return projects, missing, err
+}
+
+func (t *tracedProjectRepository) GetProjectByName(ctx context.Context, userID uuid.UUID, name string) (types.Project, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.GetProjectByName")
+	project, err := t.next.GetProjectByName(ctx, userID, name)
+	tracing.End(span, err)
+	return project, err
+}
+
+func (t *tracedProjectRepository) CreateProject(ctx context.Context, userID uuid.UUID, projectData types.ProjectCreatePayload) (types.Project, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.CreateProject")
+	project, err := t.next.CreateProject(ctx, userID, projectData)
+	tracing.End(span, err)
+	return project, err
+}
+
+func (t *tracedProjectRepository) UpdateProject(ctx context.Context, userID uuid.UUID, projectData types.ProjectUpdatePayload) (types.Project, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.UpdateProject")
+	project, err := t.next.UpdateProject(ctx, userID, projectData)
+	tracing.End(span, err)
+	return project, err
+}
+
+func (t *tracedProjectRepository) UpdateProjectLocked(ctx context.Context, userID, projectID uuid.UUID, merge func(types.Project) (types.ProjectUpdatePayload, error)) (types.Project, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.UpdateProjectLocked")
+	project, err := t.next.UpdateProjectLocked(ctx, userID, projectID, merge)
+	tracing.End(span, err)
+	return project, err
+}
+
+func (t *tracedProjectRepository) DeleteProject(ctx context.Context, userID, projectID uuid.UUID, version *time.Time) error {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.DeleteProject")
+	err := t.next.DeleteProject(ctx, userID, projectID, version)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *tracedProjectRepository) DeleteProjectTree(ctx context.Context, userID, projectID uuid.UUID, version *time.Time) error {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.DeleteProjectTree")
+	err := t.next.DeleteProjectTree(ctx, userID, projectID, version)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *tracedProjectRepository) DeleteProjectDetachingChildren(ctx context.Context, userID, projectID uuid.UUID, version *time.Time) error {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.DeleteProjectDetachingChildren")
+	err := t.next.DeleteProjectDetachingChildren(ctx, userID, projectID, version)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *tracedProjectRepository) ListChildProjects(ctx context.Context, userID, projectID uuid.UUID) ([]types.Project, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.ListChildProjects")
+	projects, err := t.next.ListChildProjects(ctx, userID, projectID)
+	tracing.End(span, err)
+	return projects, err
+}
+
+func (t *tracedProjectRepository) CountChildProjects(ctx context.Context, userID, projectID uuid.UUID) (int64, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.CountChildProjects")
+	count, err := t.next.CountChildProjects(ctx, userID, projectID)
+	tracing.End(span, err)
+	return count, err
+}
+
+func (t *tracedProjectRepository) ListProjectAncestors(ctx context.Context, userID, projectID uuid.UUID) ([]uuid.UUID, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.ListProjectAncestors")
+	ids, err := t.next.ListProjectAncestors(ctx, userID, projectID)
+	tracing.End(span, err)
+	return ids, err
+}
+
+func (t *tracedProjectRepository) GetProjectSubtreeDepth(ctx context.Context, userID, projectID uuid.UUID) (int32, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.GetProjectSubtreeDepth")
+	depth, err := t.next.GetProjectSubtreeDepth(ctx, userID, projectID)
+	tracing.End(span, err)
+	return depth, err
+}
+
+func (t *tracedProjectRepository) GetProjectSummary(ctx context.Context, userID, projectID uuid.UUID, rollup bool) (types.ProjectSummary, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.GetProjectSummary")
+	summary, err := t.next.GetProjectSummary(ctx, userID, projectID, rollup)
+	tracing.End(span, err)
+	return summary, err
+}
+
+func (t *tracedProjectRepository) ListDeletedProjectsPaginated(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, limit int32, order coreTypes.SortOrder) ([]types.Project, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.ListDeletedProjectsPaginated")
+	projects, err := t.next.ListDeletedProjectsPaginated(ctx, userID, cursor, cursorID, limit, order)
+	tracing.End(span, err)
+	return projects, err
+}
+
+func (t *tracedProjectRepository) RestoreProject(ctx context.Context, userID, projectID uuid.UUID) (types.Project, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.RestoreProject")
+	project, err := t.next.RestoreProject(ctx, userID, projectID)
+	tracing.End(span, err)
+	return project, err
+}
+
+func (t *tracedProjectRepository) CloneProject(ctx context.Context, userID, projectID uuid.UUID, includeWallets bool) (types.Project, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.CloneProject")
+	project, err := t.next.CloneProject(ctx, userID, projectID, includeWallets)
+	tracing.End(span, err)
+	return project, err
+}
+
+func (t *tracedProjectRepository) PublishProject(ctx context.Context, userID, projectID uuid.UUID, rules types.PublishRules) (types.Project, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.PublishProject")
+	project, err := t.next.PublishProject(ctx, userID, projectID, rules)
+	tracing.End(span, err)
+	return project, err
+}
+
+func (t *tracedProjectRepository) GetProjectWallets(ctx context.Context, userID, projectID uuid.UUID) ([]db.Wallet, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.GetProjectWallets")
+	wallets, err := t.next.GetProjectWallets(ctx, userID, projectID)
+	tracing.End(span, err)
+	return wallets, err
+}
+
+func (t *tracedProjectRepository) ListProjectsPaginated(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, includeDrafts bool, limit int32, order coreTypes.SortOrder) ([]types.Project, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.ListProjectsPaginated")
+	projects, err := t.next.ListProjectsPaginated(ctx, userID, cursor, cursorID, includeDrafts, limit, order)
+	tracing.End(span, err)
+	return projects, err
+}
+
+func (t *tracedProjectRepository) ListProjectsPaginatedStream(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, includeDrafts bool, limit int32, order coreTypes.SortOrder, fn func(types.Project) error) error {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.ListProjectsPaginatedStream")
+	err := t.next.ListProjectsPaginatedStream(ctx, userID, cursor, cursorID, includeDrafts, limit, order, fn)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *tracedProjectRepository) ListPinnedProjectsPaginatedStream(ctx context.Context, userID uuid.UUID, pinnedAt time.Time, cursorID uuid.UUID, includeDrafts bool, limit int32, fn func(types.Project) error) error {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.ListPinnedProjectsPaginatedStream")
+	err := t.next.ListPinnedProjectsPaginatedStream(ctx, userID, pinnedAt, cursorID, includeDrafts, limit, fn)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *tracedProjectRepository) ListPinnedProjectsPaginated(ctx context.Context, userID uuid.UUID, pinnedAt time.Time, cursorID uuid.UUID, includeDrafts bool, limit int32) ([]types.Project, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.ListPinnedProjectsPaginated")
+	projects, err := t.next.ListPinnedProjectsPaginated(ctx, userID, pinnedAt, cursorID, includeDrafts, limit)
+	tracing.End(span, err)
+	return projects, err
+}
+
+func (t *tracedProjectRepository) ListProjectIDsPaginated(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, includeDrafts bool, limit int32, order coreTypes.SortOrder) ([]coreTypes.KeyedID, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.ListProjectIDsPaginated")
+	keys, err := t.next.ListProjectIDsPaginated(ctx, userID, cursor, cursorID, includeDrafts, limit, order)
+	tracing.End(span, err)
+	return keys, err
+}
+
+func (t *tracedProjectRepository) ListPinnedProjectIDsPaginated(ctx context.Context, userID uuid.UUID, pinnedAt time.Time, cursorID uuid.UUID, includeDrafts bool, limit int32) ([]coreTypes.KeyedID, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.ListPinnedProjectIDsPaginated")
+	keys, err := t.next.ListPinnedProjectIDsPaginated(ctx, userID, pinnedAt, cursorID, includeDrafts, limit)
+	tracing.End(span, err)
+	return keys, err
+}
+
+func (t *tracedProjectRepository) SetProjectPinned(ctx context.Context, userID, projectID uuid.UUID, pinned bool) (types.Project, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.SetProjectPinned")
+	project, err := t.next.SetProjectPinned(ctx, userID, projectID, pinned)
+	tracing.End(span, err)
+	return project, err
+}
+
+func (t *tracedProjectRepository) SearchProjects(ctx context.Context, userID uuid.UUID, query string, includeDrafts bool, limit, offset int32) ([]types.Project, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.SearchProjects")
+	projects, err := t.next.SearchProjects(ctx, userID, query, includeDrafts, limit, offset)
+	tracing.End(span, err)
+	return projects, err
+}
+
+func (t *tracedProjectRepository) SearchProjectsPicker(ctx context.Context, userID uuid.UUID, query string, includeDrafts bool, limit, offset int32) ([]types.ProjectPickerItem, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.SearchProjectsPicker")
+	items, err := t.next.SearchProjectsPicker(ctx, userID, query, includeDrafts, limit, offset)
+	tracing.End(span, err)
+	return items, err
+}
+
+func (t *tracedProjectRepository) ListMilestones(ctx context.Context, userID, projectID uuid.UUID) ([]types.Milestone, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.ListMilestones")
+	milestones, err := t.next.ListMilestones(ctx, userID, projectID)
+	tracing.End(span, err)
+	return milestones, err
+}
+
+func (t *tracedProjectRepository) GetMilestone(ctx context.Context, userID, projectID, milestoneID uuid.UUID) (types.Milestone, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.GetMilestone")
+	milestone, err := t.next.GetMilestone(ctx, userID, projectID, milestoneID)
+	tracing.End(span, err)
+	return milestone, err
+}
+
+func (t *tracedProjectRepository) CountProjectTreeMilestones(ctx context.Context, userID, projectID uuid.UUID, rollup bool) (int64, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.CountProjectTreeMilestones")
+	count, err := t.next.CountProjectTreeMilestones(ctx, userID, projectID, rollup)
+	tracing.End(span, err)
+	return count, err
+}
+
+func (t *tracedProjectRepository) CreateMilestone(ctx context.Context, userID, projectID uuid.UUID, milestoneData types.MilestoneCreatePayload) (types.Milestone, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.CreateMilestone")
+	milestone, err := t.next.CreateMilestone(ctx, userID, projectID, milestoneData)
+	tracing.End(span, err)
+	return milestone, err
+}
+
+func (t *tracedProjectRepository) UpdateMilestone(ctx context.Context, userID uuid.UUID, milestoneData types.MilestoneUpdatePayload) (types.Milestone, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.UpdateMilestone")
+	milestone, err := t.next.UpdateMilestone(ctx, userID, milestoneData)
+	tracing.End(span, err)
+	return milestone, err
+}
+
+func (t *tracedProjectRepository) DeleteMilestone(ctx context.Context, userID, projectID, milestoneID uuid.UUID) error {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.DeleteMilestone")
+	err := t.next.DeleteMilestone(ctx, userID, projectID, milestoneID)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *tracedProjectRepository) ReorderMilestones(ctx context.Context, userID, projectID uuid.UUID, milestoneIDs []uuid.UUID) error {
+	ctx, span := t.tracer.Start(ctx, "ProjectRepository.ReorderMilestones")
+	err := t.next.ReorderMilestones(ctx, userID, projectID, milestoneIDs)
+	tracing.End(span, err)
+	return err
+}
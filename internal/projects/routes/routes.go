@@ -1,11 +1,20 @@
 package routes
 
 import (
+	"github.com/Abdelrahman-habib/expense-tracker/config"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/cache"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/currency"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/quota"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/events"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/projects/handlers"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/projects/repository"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/projects/service"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/projects/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/validate"
 	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -15,18 +24,26 @@ type Router struct {
 }
 
 // New creates a new project router with proper dependency injection
-func New(dbService db.Service, logger *zap.Logger) *Router {
+func New(dbService db.Service, logger *zap.Logger, cacheConfig config.CacheConfig, projectsConfig config.ProjectsConfig, rates currency.Converter, rounding validate.RoundingMode, quotas *quota.Checker, bus *events.Bus, limits coreTypes.LimitPolicy, tracer trace.Tracer) *Router {
 	// Get queries from db service
 	queries := dbService.Queries()
 
-	// Initialize repository
-	repo := repository.NewProjectRepository(queries)
+	// Initialize repository, memoizing reads per request in front of the traced queries
+	repo := repository.NewCachedProjectRepository(
+		repository.NewTracedProjectRepository(repository.NewProjectRepository(queries), tracer),
+		cache.NewMicroCache(cacheConfig.AggregateTTL),
+	)
 
-	// Initialize service with repository
-	projectService := service.NewProjectService(repo, logger)
+	// Initialize service with repository, capping the projects of each user at their quota,
+	// converting summaries with rates and publishing their changes to bus
+	rules := types.PublishRules{
+		RequireStartDate: projectsConfig.RequireStartDate,
+		RequireBudget:    projectsConfig.RequireBudget,
+	}
+	projectService := service.NewTracedProjectService(service.NewProjectService(repo, rules, rates, rounding, quotas, bus, logger), tracer)
 
 	// Initialize handler with service
-	handler := handlers.NewProjectHandler(projectService, logger)
+	handler := handlers.NewProjectHandler(projectService, limits, logger)
 
 	return &Router{
 		handler: handler,
@@ -38,12 +55,29 @@ func (r *Router) RegisterRoutes(router chi.Router) {
 	router.Route("/projects", func(router chi.Router) {
 		router.Get("/", r.handler.ListProjects)
 		router.Get("/search", r.handler.SearchProjects)
+		router.Get("/trash", r.handler.ListDeletedProjects)
 		router.Get("/paginated", r.handler.ListProjectsPaginated)
 		router.Post("/", r.handler.CreateProject)
 		router.Route("/{id}", func(router chi.Router) {
 			router.Get("/", r.handler.GetProject)
 			router.Put("/", r.handler.UpdateProject)
 			router.Delete("/", r.handler.DeleteProject)
+			router.Get("/delete-preview", r.handler.PreviewProjectDeletion)
+			router.Post("/restore", r.handler.RestoreProject)
+			router.Post("/clone", r.handler.CloneProject)
+			router.Post("/publish", r.handler.PublishProject)
+			router.Post("/pin", r.handler.PinProject)
+			router.Post("/unpin", r.handler.UnpinProject)
+			router.Get("/children", r.handler.ListChildProjects)
+			router.Get("/summary", r.handler.GetProjectSummary)
+			router.Route("/milestones", func(router chi.Router) {
+				router.Get("/", r.handler.ListMilestones)
+				router.Post("/", r.handler.CreateMilestone)
+				router.Put("/order", r.handler.ReorderMilestones)
+				router.Get("/{milestoneId}", r.handler.GetMilestone)
+				router.Put("/{milestoneId}", r.handler.UpdateMilestone)
+				router.Delete("/{milestoneId}", r.handler.DeleteMilestone)
+			})
 			// router.Get("/wallets", r.handler.GetProjectWallets) // handled by wallets feature
 		})
 	})
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/paging"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/projects/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/projects/types"
+	"github.com/google/uuid"
+)
+
+// exportBatchSize is the number of projects read per query while exporting
+var exportBatchSize int32 = 500
+
+// WriteProjects writes every active project of the user, drafts included, to w in format,
+// one of the ExportExtensions media types. The pinned projects come first like in the
+// listings, then the others newest first, read in keyset batches. It returns how many
+// projects it wrote.
+func WriteProjects(ctx context.Context, repo repository.ProjectRepository, userID uuid.UUID, format string, w io.Writer) (int, error) {
+	if _, ok := types.ExportExtensions[format]; !ok {
+		return 0, fmt.Errorf("unknown project export format %q", format)
+	}
+	buffered := bufio.NewWriter(w)
+
+	var write func(types.Project) error
+	var finish func() error
+	switch format {
+	case types.ExportFormatJSON:
+		if err := buffered.WriteByte('['); err != nil {
+			return 0, err
+		}
+		first := true
+		write = func(project types.Project) error {
+			encoded, err := json.Marshal(project)
+			if err != nil {
+				return err
+			}
+			if !first {
+				if err := buffered.WriteByte(','); err != nil {
+					return err
+				}
+			}
+			first = false
+			_, err = buffered.Write(encoded)
+			return err
+		}
+		finish = func() error {
+			return buffered.WriteByte(']')
+		}
+	default:
+		writer := csv.NewWriter(buffered)
+		if err := writer.Write(types.CSVHeader); err != nil {
+			return 0, err
+		}
+		write = func(project types.Project) error {
+			return writer.Write(project.CSVRecord())
+		}
+		finish = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+	}
+
+	exported, err := StreamProjects(ctx, repo, userID, write)
+	if err != nil {
+		return exported, err
+	}
+
+	if err := finish(); err != nil {
+		return exported, err
+	}
+	return exported, buffered.Flush()
+}
+
+// StreamProjects hands every active project of the user, drafts included, to fn and
+// returns how many it handed over. The pinned projects come first like in the listings,
+// then the others newest first, read in keyset batches.
+func StreamProjects(ctx context.Context, repo repository.ProjectRepository, userID uuid.UUID, fn func(types.Project) error) (int, error) {
+	// cursor is where a batch starts, after the last project handed over
+	type cursor struct {
+		at time.Time
+		id uuid.UUID
+	}
+	var exported int
+	// streamAll hands over what list streams from start on, batch after batch
+	streamAll := func(start cursor, list func(after cursor, fn func(types.Project) error) error) error {
+		return paging.ForEachPage(ctx, func(ctx context.Context, after cursor) ([]types.Project, cursor, error) {
+			if after == (cursor{}) {
+				after = start
+			}
+			var batch []types.Project
+			err := list(after, func(project types.Project) error {
+				batch = append(batch, project)
+				return nil
+			})
+			return batch, paging.Next(batch, int(exportBatchSize), func(project types.Project) cursor {
+				if project.PinnedAt != nil {
+					return cursor{project.PinnedAt.Time, project.ProjectID}
+				}
+				return cursor{project.CreatedAt.Time, project.ProjectID}
+			}), err
+		}, func(project types.Project) error {
+			if err := fn(project); err != nil {
+				return err
+			}
+			exported++
+			return nil
+		})
+	}
+
+	start, startID := coreTypes.StartPinnedCursor()
+	err := streamAll(cursor{start, startID}, func(after cursor, yield func(types.Project) error) error {
+		return repo.ListPinnedProjectsPaginatedStream(ctx, userID, after.at, after.id, true, exportBatchSize, yield)
+	})
+	if err != nil {
+		return exported, err
+	}
+	start, startID = coreTypes.StartCursor(coreTypes.SortOrderDesc)
+	err = streamAll(cursor{start, startID}, func(after cursor, yield func(types.Project) error) error {
+		return repo.ListProjectsPaginatedStream(ctx, userID, after.at, after.id, true, exportBatchSize, coreTypes.SortOrderDesc, yield)
+	})
+	return exported, err
+}
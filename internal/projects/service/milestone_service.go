@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	stdErrors "errors"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/logging"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/projects/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/projects/types"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func (s *projectService) ListMilestones(ctx context.Context, userID, projectID uuid.UUID) (_ []types.Milestone, err error) {
+	defer s.milestoneOperation("ListMilestones", userID, uuid.Nil,
+		zap.String("project_id", projectID.String())).End(&err)
+
+	// an empty list can't tell a project without milestones from a missing one
+	if _, err := s.repo.GetProject(ctx, userID, projectID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListMilestones(ctx, userID, projectID)
+}
+
+func (s *projectService) GetMilestone(ctx context.Context, userID, projectID, milestoneID uuid.UUID) (_ types.Milestone, err error) {
+	defer s.milestoneOperation("GetMilestone", userID, milestoneID,
+		zap.String("project_id", projectID.String())).End(&err)
+	return s.repo.GetMilestone(ctx, userID, projectID, milestoneID)
+}
+
+func (s *projectService) CreateMilestone(ctx context.Context, userID, projectID uuid.UUID, milestoneData types.MilestoneCreatePayload) (_ types.Milestone, err error) {
+	op := s.milestoneOperation("CreateMilestone", userID, uuid.Nil,
+		zap.String("project_id", projectID.String()),
+		zap.String("name", milestoneData.Name))
+	defer op.End(&err)
+
+	project, err := s.repo.GetProject(ctx, userID, projectID)
+	if err != nil {
+		return types.Milestone{}, err
+	}
+	if err := validateDueDate(project, milestoneData.DueDate); err != nil {
+		return types.Milestone{}, err
+	}
+
+	milestone, err := s.repo.CreateMilestone(ctx, userID, projectID, milestoneData)
+	if stdErrors.Is(err, repository.ErrMilestoneLimit) {
+		return types.Milestone{}, errors.NewValidationError("a project can have at most %d milestones", types.MaxMilestones)
+	}
+	if err != nil {
+		return types.Milestone{}, err
+	}
+	op.With(zap.Stringer(logging.FieldEntityID, milestone.MilestoneID))
+	return milestone, nil
+}
+
+func (s *projectService) UpdateMilestone(ctx context.Context, userID uuid.UUID, milestoneData types.MilestoneUpdatePayload) (_ types.Milestone, err error) {
+	defer s.milestoneOperation("UpdateMilestone", userID, milestoneData.MilestoneID,
+		zap.String("project_id", milestoneData.ProjectID.String()),
+		zap.Bool("completed", milestoneData.Completed)).End(&err)
+
+	project, err := s.repo.GetProject(ctx, userID, milestoneData.ProjectID)
+	if err != nil {
+		return types.Milestone{}, err
+	}
+	if err := validateDueDate(project, milestoneData.DueDate); err != nil {
+		return types.Milestone{}, err
+	}
+
+	return s.repo.UpdateMilestone(ctx, userID, milestoneData)
+}
+
+func (s *projectService) DeleteMilestone(ctx context.Context, userID, projectID, milestoneID uuid.UUID) (err error) {
+	defer s.milestoneOperation("DeleteMilestone", userID, milestoneID,
+		zap.String("project_id", projectID.String())).End(&err)
+	return s.repo.DeleteMilestone(ctx, userID, projectID, milestoneID)
+}
+
+// ReorderMilestones applies a new order to the project's milestones and returns them
+// in that order, the IDs must cover every milestone of the project exactly once
+func (s *projectService) ReorderMilestones(ctx context.Context, userID, projectID uuid.UUID, milestoneIDs []uuid.UUID) (_ []types.Milestone, err error) {
+	defer s.milestoneOperation("ReorderMilestones", userID, uuid.Nil,
+		zap.String("project_id", projectID.String()),
+		zap.Int("count", len(milestoneIDs))).End(&err)
+
+	milestones, err := s.ListMilestones(ctx, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateMilestoneOrder(milestones, milestoneIDs); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ReorderMilestones(ctx, userID, projectID, milestoneIDs); err != nil {
+		return nil, err
+	}
+	return s.repo.ListMilestones(ctx, userID, projectID)
+}
+
+// validateDueDate checks a milestone due date falls within the project's start and end
+// dates, comparing days so a milestone due any time on the end date is within it
+func validateDueDate(project types.Project, dueDate *time.Time) error {
+	if dueDate == nil {
+		return nil
+	}
+	due := coreTypes.NewDate(*dueDate)
+	if project.StartDate != nil && due.Before(project.StartDate.Time) {
+		return errors.NewValidationError("due date cannot be before the project start date")
+	}
+	if project.EndDate != nil && due.After(project.EndDate.Time) {
+		return errors.NewValidationError("due date cannot be after the project end date")
+	}
+	return nil
+}
+
+// validateMilestoneOrder checks the order lists each of the milestones exactly once
+func validateMilestoneOrder(milestones []types.Milestone, milestoneIDs []uuid.UUID) error {
+	if len(milestoneIDs) != len(milestones) {
+		return errors.NewValidationError("order must list all %d milestones of the project", len(milestones))
+	}
+
+	existing := make(map[uuid.UUID]bool, len(milestones))
+	for _, m := range milestones {
+		existing[m.MilestoneID] = true
+	}
+	seen := make(map[uuid.UUID]bool, len(milestoneIDs))
+	for _, id := range milestoneIDs {
+		if !existing[id] {
+			return errors.NewValidationError("milestone %s does not belong to the project", id)
+		}
+		if seen[id] {
+			return errors.NewValidationError("milestone %s is listed more than once", id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
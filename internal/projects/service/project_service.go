@@ -2,53 +2,234 @@ package service
 
 import (
 	"context"
+	stdErrors "errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/cache"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/currency"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/deletion"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/logging"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/quota"
+	coreRepository "github.com/Abdelrahman-habib/expense-tracker/internal/core/repository"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/events"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/projects/repository"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/projects/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/validate"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 type ProjectService interface {
 	ListProjects(ctx context.Context, userID uuid.UUID) ([]types.Project, error)
-	GetProject(ctx context.Context, userID, projectID uuid.UUID) (types.Project, error)
+	GetProject(ctx context.Context, userID, projectID uuid.UUID, expand types.ProjectExpand) (types.Project, error)
+	GetProjectsByIDs(ctx context.Context, userID uuid.UUID, projectIDs []uuid.UUID) ([]types.Project, []uuid.UUID, error)
+	ListChildProjects(ctx context.Context, userID, projectID uuid.UUID) ([]types.Project, error)
+	GetProjectSummary(ctx context.Context, userID, projectID uuid.UUID, params types.ProjectSummaryParams) (types.ProjectSummary, error)
 	CreateProject(ctx context.Context, userID uuid.UUID, projectData types.ProjectCreatePayload) (types.Project, error)
-	UpdateProject(ctx context.Context, userID uuid.UUID, projectData types.ProjectUpdatePayload) (types.Project, error)
-	DeleteProject(ctx context.Context, userID, projectID uuid.UUID) error
+	CreateProjectIfNotExists(ctx context.Context, userID uuid.UUID, projectData types.ProjectCreatePayload) (types.Project, bool, error)
+	UpdateProject(ctx context.Context, userID, projectID uuid.UUID, merge func(types.Project) (types.ProjectUpdatePayload, error)) (types.Project, error)
+	DeleteProject(ctx context.Context, userID, projectID uuid.UUID, children types.ChildrenMode, version *time.Time) error
+	DeletionImpact(ctx context.Context, userID, projectID uuid.UUID, children types.ChildrenMode) (deletion.Impact, error)
+	ListDeletedProjectsPaginated(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, limit int32, order coreTypes.SortOrder) ([]types.Project, error)
+	RestoreProject(ctx context.Context, userID, projectID uuid.UUID) (types.Project, error)
+	CloneProject(ctx context.Context, userID, projectID uuid.UUID, includeWallets bool) (types.Project, error)
+	PublishProject(ctx context.Context, userID, projectID uuid.UUID) (types.Project, error)
 	GetProjectWallets(ctx context.Context, userID, projectID uuid.UUID) ([]db.Wallet, error)
-	ListProjectsPaginated(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, limit int32) ([]types.Project, error)
-	SearchProjects(ctx context.Context, userID uuid.UUID, query string, limit int32) ([]types.Project, error)
+	ListProjectsPaginated(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, pinned, includeDrafts bool, limit int32, order coreTypes.SortOrder) ([]types.Project, error)
+	ListProjectIDsPaginated(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, pinned, includeDrafts bool, limit int32, order coreTypes.SortOrder) ([]coreTypes.KeyedID, error)
+	StreamProjectsPaginated(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, pinned, includeDrafts bool, limit int32, order coreTypes.SortOrder, fn func(types.Project) error) error
+	PinProject(ctx context.Context, userID, projectID uuid.UUID) (types.Project, error)
+	UnpinProject(ctx context.Context, userID, projectID uuid.UUID) (types.Project, error)
+	SearchProjects(ctx context.Context, userID uuid.UUID, query string, includeDrafts bool, limit, offset int32) ([]types.Project, error)
+	SearchProjectsPicker(ctx context.Context, userID uuid.UUID, query string, includeDrafts bool, limit, offset int32) ([]types.ProjectPickerItem, error)
+	ListMilestones(ctx context.Context, userID, projectID uuid.UUID) ([]types.Milestone, error)
+	GetMilestone(ctx context.Context, userID, projectID, milestoneID uuid.UUID) (types.Milestone, error)
+	CreateMilestone(ctx context.Context, userID, projectID uuid.UUID, milestoneData types.MilestoneCreatePayload) (types.Milestone, error)
+	UpdateMilestone(ctx context.Context, userID uuid.UUID, milestoneData types.MilestoneUpdatePayload) (types.Milestone, error)
+	DeleteMilestone(ctx context.Context, userID, projectID, milestoneID uuid.UUID) error
+	ReorderMilestones(ctx context.Context, userID, projectID uuid.UUID, milestoneIDs []uuid.UUID) ([]types.Milestone, error)
 }
 
 type projectService struct {
-	repo   repository.ProjectRepository
-	logger *zap.Logger
+	repo     repository.ProjectRepository
+	rules    types.PublishRules
+	rates    currency.Converter
+	rounding validate.RoundingMode
+	quotas   *quota.Checker
+	events   *events.Bus
+	deletes  *deletion.Registry[types.ChildrenMode]
+	searches cache.Coalescer
+	logger   *zap.Logger
 }
 
-func NewProjectService(repo repository.ProjectRepository, logger *zap.Logger) ProjectService {
-	return &projectService{
-		repo:   repo,
-		logger: logger.With(zap.String("component", "project_service")),
+// NewProjectService creates the project service, rules are the fields live projects need
+// and quotas caps the projects and wallets of each user, nil leaves them unlimited. The
+// summaries convert wallet balances with rates, nil refuses conversions, and round the
+// converted totals with rounding. The changes to projects are published to bus, nil
+// publishes nothing.
+func NewProjectService(repo repository.ProjectRepository, rules types.PublishRules, rates currency.Converter, rounding validate.RoundingMode, quotas *quota.Checker, bus *events.Bus, logger *zap.Logger) ProjectService {
+	s := &projectService{
+		repo:     repo,
+		rules:    rules,
+		rates:    rates,
+		rounding: rounding,
+		quotas:   quotas,
+		events:   bus,
+		logger:   logger.With(zap.String("component", "project_service")),
 	}
+	s.deletes = deletion.NewRegistry[types.ChildrenMode]("project").
+		Register(s.trashedProjects, s.childProjects, s.keptMilestones)
+	return s
 }
 
-func (s *projectService) ListProjects(ctx context.Context, userID uuid.UUID) ([]types.Project, error) {
-	s.logger.Info("listing projects for user", zap.String("user_id", userID.String()))
+// operation starts the log of a project service method, projectID is uuid.Nil when the
+// method doesn't work on one project
+func (s *projectService) operation(name string, userID, projectID uuid.UUID, fields ...zap.Field) *logging.Operation {
+	logger := logging.WithEntity(logging.WithUser(s.logger, userID), "project", projectID)
+	return logging.Start(logger, "ProjectService."+name, fields...)
+}
+
+// publish tells the user's clients listening for changes that the project changed
+func (s *projectService) publish(userID, projectID uuid.UUID, action string, updatedAt time.Time) {
+	s.events.Publish(userID, events.Event{Type: events.TypeProject, EntityID: projectID, Action: action, UpdatedAt: coreTypes.NewTimestamp(updatedAt)})
+}
+
+// published publishes the change of a project a method returns along with its error
+func (s *projectService) published(userID uuid.UUID, action string, project types.Project, err error) (types.Project, error) {
+	if err != nil {
+		return types.Project{}, err
+	}
+	s.publish(userID, project.ProjectID, action, project.UpdatedAt.Time)
+	return project, nil
+}
+
+// milestoneOperation starts the log of a milestone method, milestoneID is uuid.Nil when
+// the method doesn't work on one milestone
+func (s *projectService) milestoneOperation(name string, userID, milestoneID uuid.UUID, fields ...zap.Field) *logging.Operation {
+	logger := logging.WithEntity(logging.WithUser(s.logger, userID), "milestone", milestoneID)
+	return logging.Start(logger, "ProjectService."+name, fields...)
+}
+
+func (s *projectService) ListProjects(ctx context.Context, userID uuid.UUID) (_ []types.Project, err error) {
+	defer s.operation("ListProjects", userID, uuid.Nil).End(&err)
 	return s.repo.ListProjects(ctx, userID)
 }
 
-func (s *projectService) GetProject(ctx context.Context, userID, projectID uuid.UUID) (types.Project, error) {
-	s.logger.Info("getting project",
-		zap.String("user_id", userID.String()),
-		zap.String("project_id", projectID.String()))
-	return s.repo.GetProject(ctx, userID, projectID)
+// GetProject gets the project, with expand.Parent along with the name and status of its parent
+func (s *projectService) GetProject(ctx context.Context, userID, projectID uuid.UUID, expand types.ProjectExpand) (_ types.Project, err error) {
+	defer s.operation("GetProject", userID, projectID).End(&err)
+
+	project, err := s.repo.GetProject(ctx, userID, projectID)
+	if err != nil || !expand.Parent || project.ParentProjectID == nil {
+		return project, err
+	}
+
+	parent, err := s.repo.GetProject(ctx, userID, *project.ParentProjectID)
+	if err != nil {
+		return types.Project{}, err
+	}
+	project.Parent = &types.ProjectParent{ProjectID: parent.ProjectID, Name: parent.Name, Status: parent.Status}
+	return project, nil
+}
+
+// GetProjectsByIDs gets the user's projects with the IDs in the order asked for, the IDs
+// of the projects not found are returned apart
+func (s *projectService) GetProjectsByIDs(ctx context.Context, userID uuid.UUID, projectIDs []uuid.UUID) (_ []types.Project, _ []uuid.UUID, err error) {
+	defer s.operation("GetProjectsByIDs", userID, uuid.Nil, zap.Int("ids", len(projectIDs))).End(&err)
+	return s.repo.GetProjectsByIDs(ctx, userID, projectIDs)
+}
+
+// ListChildProjects lists the direct sub-projects of the project
+func (s *projectService) ListChildProjects(ctx context.Context, userID, projectID uuid.UUID) (_ []types.Project, err error) {
+	defer s.operation("ListChildProjects", userID, projectID).End(&err)
+
+	if _, err := s.repo.GetProject(ctx, userID, projectID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListChildProjects(ctx, userID, projectID)
+}
+
+// GetProjectSummary totals the project's budget and wallet balances, with rollup those of
+// its sub-projects at any depth too. With a convert_to currency the balances are also
+// converted and added up, when that isn't possible the summary is returned without the
+// converted total and a warning says why.
+func (s *projectService) GetProjectSummary(ctx context.Context, userID, projectID uuid.UUID, params types.ProjectSummaryParams) (_ types.ProjectSummary, err error) {
+	defer s.operation("GetProjectSummary", userID, projectID,
+		zap.Bool("rollup", params.Rollup),
+		zap.String("convert_to", params.ConvertTo)).End(&err)
+
+	summary, err := s.repo.GetProjectSummary(ctx, userID, projectID, params.Rollup)
+	if err != nil || params.ConvertTo == "" {
+		return summary, err
+	}
+	if s.rates == nil {
+		requestcontext.AddWarning(ctx, "convert_to: currency conversion is not available")
+		return summary, nil
+	}
+
+	converted := &types.ConvertedBalance{Currency: params.ConvertTo, Rates: make(map[string]float64, len(summary.Balances))}
+	var sum float64
+	for _, balance := range summary.Balances {
+		rate, err := s.rates.Rate(ctx, balance.Currency, params.ConvertTo)
+		if stdErrors.Is(err, currency.ErrRateUnavailable) {
+			// a total leaving a currency out would be wrong, the breakdown is still right
+			requestcontext.AddWarning(ctx, fmt.Sprintf("convert_to: no exchange rate from %s to %s, the balances are not converted", balance.Currency, params.ConvertTo))
+			return summary, nil
+		}
+		if err != nil {
+			return types.ProjectSummary{}, err
+		}
+		converted.Rates[balance.Currency] = rate
+		sum += balance.Balance * rate
+	}
+	converted.Balance = validate.RoundAmount(sum, params.ConvertTo, s.rounding)
+	summary.Converted = converted
+	return summary, nil
+}
+
+// validateParent checks that nesting the project under parentID keeps the tree free of
+// cycles and within MaxProjectDepth levels. projectID is uuid.Nil for a new project.
+func (s *projectService) validateParent(ctx context.Context, userID, projectID uuid.UUID, parentID *uuid.UUID) error {
+	if parentID == nil {
+		return nil
+	}
+	if *parentID == projectID {
+		return errors.NewValidationError("parent_project_id: a project can't be its own parent")
+	}
+
+	ancestors, err := s.repo.ListProjectAncestors(ctx, userID, *parentID)
+	if err != nil {
+		return err
+	}
+	if len(ancestors) == 0 {
+		return errors.NewValidationError("parent_project_id: project not found")
+	}
+
+	depth := int32(1)
+	if projectID != uuid.Nil {
+		if slices.Contains(ancestors, projectID) {
+			return errors.NewValidationError("parent_project_id: a project can't be nested under its own sub-project")
+		}
+		if depth, err = s.repo.GetProjectSubtreeDepth(ctx, userID, projectID); err != nil {
+			return err
+		}
+	}
+
+	if len(ancestors)+int(depth) > types.MaxProjectDepth {
+		return errors.NewValidationError("parent_project_id: projects can be nested at most %d levels deep", types.MaxProjectDepth)
+	}
+	return nil
 }
 
 // Common validation function
-func validateProject(name, status string, startDate, endDate *time.Time, budget *float64, description *string) error {
+func validateProject(name, status string, startDate, endDate *coreTypes.Date, budget *float64, description *string) error {
 	// Validate required fields
 	if name == "" {
 		return fmt.Errorf("project name is required")
@@ -61,7 +242,7 @@ func validateProject(name, status string, startDate, endDate *time.Time, budget
 
 	// Validate dates
 	if startDate != nil && endDate != nil {
-		if endDate.Before(*startDate) {
+		if endDate.Before(startDate.Time) {
 			return fmt.Errorf("end date cannot be before start date")
 		}
 	}
@@ -82,7 +263,12 @@ func validateProject(name, status string, startDate, endDate *time.Time, budget
 	return nil
 }
 
-func (s *projectService) CreateProject(ctx context.Context, userID uuid.UUID, projectData types.ProjectCreatePayload) (types.Project, error) {
+func (s *projectService) CreateProject(ctx context.Context, userID uuid.UUID, projectData types.ProjectCreatePayload) (_ types.Project, err error) {
+	op := s.operation("CreateProject", userID, uuid.Nil,
+		zap.String("name", projectData.Name),
+		zap.Bool("draft", projectData.Draft))
+	defer op.End(&err)
+
 	// Validate project data
 	if err := validateProject(
 		projectData.Name,
@@ -95,14 +281,89 @@ func (s *projectService) CreateProject(ctx context.Context, userID uuid.UUID, pr
 		return types.Project{}, err
 	}
 
-	s.logger.Info("creating project",
-		zap.String("user_id", userID.String()),
-		zap.String("name", projectData.Name))
-	return s.repo.CreateProject(ctx, userID, projectData)
+	// drafts fill in the fields of a live project before they are published
+	if !projectData.Draft {
+		if missing := s.rules.Missing(projectData.Status, projectData.StartDate, projectData.Budget); len(missing) > 0 {
+			return types.Project{}, errors.NewValidationError("a live project needs %s, create it as a draft to fill them in later", strings.Join(missing, ", "))
+		}
+	}
+
+	if err := s.validateParent(ctx, userID, uuid.Nil, projectData.ParentProjectID); err != nil {
+		return types.Project{}, err
+	}
+	if err := s.quotas.Check(ctx, userID, quota.Projects, 1); err != nil {
+		return types.Project{}, err
+	}
+
+	project, err := s.repo.CreateProject(ctx, userID, projectData)
+	if err != nil {
+		return types.Project{}, err
+	}
+	op.With(zap.Stringer(logging.FieldEntityID, project.ProjectID))
+	return s.published(userID, events.ActionCreated, project, nil)
 }
 
-func (s *projectService) UpdateProject(ctx context.Context, userID uuid.UUID, projectData types.ProjectUpdatePayload) (types.Project, error) {
-	// Validate project data
+// CreateProjectIfNotExists returns the user's project with the same name, compared
+// case-insensitively, and only creates the project when there is none. created reports
+// which of the two happened.
+func (s *projectService) CreateProjectIfNotExists(ctx context.Context, userID uuid.UUID, projectData types.ProjectCreatePayload) (_ types.Project, _ bool, err error) {
+	op := s.operation("CreateProjectIfNotExists", userID, uuid.Nil, zap.String("name", projectData.Name))
+	defer op.End(&err)
+
+	// an invalid payload is rejected even when a project with its name exists
+	if err := validateProject(
+		projectData.Name,
+		projectData.Status,
+		projectData.StartDate,
+		projectData.EndDate,
+		projectData.Budget,
+		projectData.Description,
+	); err != nil {
+		return types.Project{}, false, err
+	}
+
+	existing, err := s.repo.GetProjectByName(ctx, userID, projectData.Name)
+	if err == nil {
+		op.With(zap.Stringer(logging.FieldEntityID, existing.ProjectID), zap.Bool("created", false))
+		return existing, false, nil
+	}
+	if !errors.IsErrorType(err, errors.ErrorTypeNotFound) {
+		return types.Project{}, false, err
+	}
+
+	project, err := s.CreateProject(ctx, userID, projectData)
+	if errors.IsErrorType(err, errors.ErrorTypeConflict) {
+		// a concurrent request created it between the lookup and the insert
+		if existing, lookupErr := s.repo.GetProjectByName(ctx, userID, projectData.Name); lookupErr == nil {
+			op.With(zap.Stringer(logging.FieldEntityID, existing.ProjectID), zap.Bool("created", false))
+			return existing, false, nil
+		}
+	}
+	if err != nil {
+		return types.Project{}, false, err
+	}
+	op.With(zap.Stringer(logging.FieldEntityID, project.ProjectID), zap.Bool("created", true))
+	return project, true, nil
+}
+
+// UpdateProject updates the project with the payload merge makes of its current values.
+// The project's advisory lock is held from the read to the write, so concurrent updates
+// apply one after the other instead of one losing the changes of the other.
+func (s *projectService) UpdateProject(ctx context.Context, userID, projectID uuid.UUID, merge func(types.Project) (types.ProjectUpdatePayload, error)) (_ types.Project, err error) {
+	defer s.operation("UpdateProject", userID, projectID).End(&err)
+
+	project, err := s.repo.UpdateProjectLocked(ctx, userID, projectID, func(existing types.Project) (types.ProjectUpdatePayload, error) {
+		projectData, err := merge(existing)
+		if err != nil {
+			return types.ProjectUpdatePayload{}, err
+		}
+		return projectData, s.validateUpdate(ctx, userID, projectData)
+	})
+	return s.published(userID, events.ActionUpdated, project, err)
+}
+
+// validateUpdate validates the payload of a project update
+func (s *projectService) validateUpdate(ctx context.Context, userID uuid.UUID, projectData types.ProjectUpdatePayload) error {
 	if err := validateProject(
 		projectData.Name,
 		projectData.Status,
@@ -111,50 +372,329 @@ func (s *projectService) UpdateProject(ctx context.Context, userID uuid.UUID, pr
 		projectData.Budget,
 		projectData.Description,
 	); err != nil {
+		return err
+	}
+	return s.validateParent(ctx, userID, projectData.ProjectID, projectData.ParentProjectID)
+}
+
+// DeleteProject trashes the project. A project with sub-projects is only deleted with
+// children set to cascade, trashing them too, or detach, moving them to the top level.
+// With a version the project is only trashed while it is still at that updatedAt.
+func (s *projectService) DeleteProject(ctx context.Context, userID, projectID uuid.UUID, children types.ChildrenMode, version *time.Time) (err error) {
+	defer s.operation("DeleteProject", userID, projectID,
+		zap.String("children", string(children))).End(&err)
+
+	impact, err := s.DeletionImpact(ctx, userID, projectID, children)
+	if err != nil {
+		return err
+	}
+	if err := impact.Err(); err != nil {
+		return err
+	}
+
+	switch children {
+	case types.ChildrenCascade:
+		err = s.repo.DeleteProjectTree(ctx, userID, projectID, version)
+	case types.ChildrenDetach:
+		err = s.repo.DeleteProjectDetachingChildren(ctx, userID, projectID, version)
+	default:
+		err = s.repo.DeleteProject(ctx, userID, projectID, version)
+	}
+	if err != nil {
+		return err
+	}
+	s.publish(userID, projectID, events.ActionDeleted, time.Time{})
+	return nil
+}
+
+// DeletionImpact tells what deleting the project with the children mode affects and
+// whether anything blocks it, DeleteProject runs the same checks
+func (s *projectService) DeletionImpact(ctx context.Context, userID, projectID uuid.UUID, children types.ChildrenMode) (_ deletion.Impact, err error) {
+	defer s.operation("DeletionImpact", userID, projectID,
+		zap.String("children", string(children))).End(&err)
+	return s.deletes.Assess(ctx, userID, projectID, children)
+}
+
+// trashedProjects adds the projects going to the trash, with cascade the whole tree, and
+// the wallets attached to them, which stay live and attached until the projects are
+// purged and are detached then
+func (s *projectService) trashedProjects(ctx context.Context, userID, projectID uuid.UUID, children types.ChildrenMode, impact *deletion.Impact) error {
+	summary, err := s.repo.GetProjectSummary(ctx, userID, projectID, children == types.ChildrenCascade)
+	if err != nil {
+		return err
+	}
+
+	balances := make([]deletion.Balance, 0, len(summary.Balances))
+	for _, balance := range summary.Balances {
+		balances = append(balances, deletion.Balance{Currency: balance.Currency, Balance: balance.Balance})
+	}
+	impact.Add(deletion.Affected{Kind: "projects", Count: summary.Projects, Effect: deletion.EffectTrashed})
+	impact.Add(deletion.Affected{Kind: "wallets", Count: summary.Wallets, Effect: deletion.EffectDetached, Balances: balances})
+	return nil
+}
+
+// childProjects adds the direct sub-projects, they block the delete unless it cascades or
+// detaches them
+func (s *projectService) childProjects(ctx context.Context, userID, projectID uuid.UUID, children types.ChildrenMode, impact *deletion.Impact) error {
+	count, err := s.repo.CountChildProjects(ctx, userID, projectID)
+	if err != nil || count == 0 {
+		return err
+	}
+
+	switch children {
+	case types.ChildrenCascade:
+		// trashedProjects already counts them
+	case types.ChildrenDetach:
+		impact.Add(deletion.Affected{Kind: "subProjects", Count: count, Effect: deletion.EffectDetached})
+	default:
+		impact.Add(deletion.Affected{Kind: "subProjects", Count: count, Effect: deletion.EffectBlocked})
+		impact.Block(fmt.Sprintf("project has %d sub-project(s), delete with cascade=true or detach=true", count))
+	}
+	return nil
+}
+
+// keptMilestones adds the milestones of the trashed projects, they stay with their project
+func (s *projectService) keptMilestones(ctx context.Context, userID, projectID uuid.UUID, children types.ChildrenMode, impact *deletion.Impact) error {
+	count, err := s.repo.CountProjectTreeMilestones(ctx, userID, projectID, children == types.ChildrenCascade)
+	if err != nil {
+		return err
+	}
+	impact.Add(deletion.Affected{Kind: "milestones", Count: count, Effect: deletion.EffectKept})
+	return nil
+}
+
+func (s *projectService) ListDeletedProjectsPaginated(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, limit int32, order coreTypes.SortOrder) (_ []types.Project, err error) {
+	defer s.operation("ListDeletedProjectsPaginated", userID, uuid.Nil,
+		zap.Time("cursor", cursor),
+		zap.String("cursor_id", cursorID.String()),
+		zap.Int32("limit", limit),
+		zap.String("order", string(order))).End(&err)
+
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+
+	return s.repo.ListDeletedProjectsPaginated(ctx, userID, cursor, cursorID, limit, order)
+}
+
+func (s *projectService) RestoreProject(ctx context.Context, userID, projectID uuid.UUID) (_ types.Project, err error) {
+	defer s.operation("RestoreProject", userID, projectID).End(&err)
+	if err := s.quotas.Check(ctx, userID, quota.Projects, 1); err != nil {
 		return types.Project{}, err
 	}
+	project, err := s.repo.RestoreProject(ctx, userID, projectID)
+	return s.published(userID, events.ActionRestored, project, err)
+}
 
-	s.logger.Info("updating project",
-		zap.String("user_id", userID.String()),
-		zap.String("project_id", projectData.ProjectID.String()))
+// CloneProject copies one of the user's projects as "<name> (copy)", with includeWallets
+// along with its wallets, their balances zeroed. The clone keeps the source's parent.
+func (s *projectService) CloneProject(ctx context.Context, userID, projectID uuid.UUID, includeWallets bool) (_ types.Project, err error) {
+	defer s.operation("CloneProject", userID, projectID,
+		zap.Bool("include_wallets", includeWallets)).End(&err)
 
-	return s.repo.UpdateProject(ctx, userID, projectData)
+	if err := s.quotas.Check(ctx, userID, quota.Projects, 1); err != nil {
+		return types.Project{}, err
+	}
+	if includeWallets {
+		wallets, err := s.repo.GetProjectWallets(ctx, userID, projectID)
+		if err != nil {
+			return types.Project{}, err
+		}
+		if err := s.quotas.Check(ctx, userID, quota.Wallets, len(wallets)); err != nil {
+			return types.Project{}, err
+		}
+	}
+	project, err := s.repo.CloneProject(ctx, userID, projectID, includeWallets)
+	return s.published(userID, events.ActionCreated, project, err)
 }
 
-func (s *projectService) DeleteProject(ctx context.Context, userID, projectID uuid.UUID) error {
-	s.logger.Info("deleting project",
-		zap.String("user_id", userID.String()),
-		zap.String("project_id", projectID.String()))
-	return s.repo.DeleteProject(ctx, userID, projectID)
+// PublishProject turns a draft into a live project once it has the fields live projects
+// need, publishing a live project returns it unchanged
+func (s *projectService) PublishProject(ctx context.Context, userID, projectID uuid.UUID) (_ types.Project, err error) {
+	defer s.operation("PublishProject", userID, projectID).End(&err)
+
+	project, err := s.repo.PublishProject(ctx, userID, projectID, s.rules)
+	if !stdErrors.Is(err, coreRepository.ErrNotFound) {
+		return s.published(userID, events.ActionUpdated, project, err)
+	}
+
+	// the repository doesn't tell a missing project from one missing fields
+	draft, getErr := s.repo.GetProject(ctx, userID, projectID)
+	if getErr != nil {
+		return types.Project{}, getErr
+	}
+	if missing := s.rules.Missing(draft.Status, draft.StartDate, draft.Budget); len(missing) > 0 {
+		return types.Project{}, errors.NewValidationError("the project needs %s before it can be published", strings.Join(missing, ", "))
+	}
+	return types.Project{}, err
 }
 
-func (s *projectService) GetProjectWallets(ctx context.Context, userID, projectID uuid.UUID) ([]db.Wallet, error) {
-	s.logger.Info("getting project wallets",
-		zap.String("user_id", userID.String()),
-		zap.String("project_id", projectID.String()))
+func (s *projectService) GetProjectWallets(ctx context.Context, userID, projectID uuid.UUID) (_ []db.Wallet, err error) {
+	defer s.operation("GetProjectWallets", userID, projectID).End(&err)
 	return s.repo.GetProjectWallets(ctx, userID, projectID)
 }
 
-func (s *projectService) ListProjectsPaginated(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, limit int32) ([]types.Project, error) {
-	s.logger.Info("listing paginated projects",
-		zap.String("user_id", userID.String()),
+// ListProjectsPaginated lists the pinned projects first, most recently pinned on top, then
+// the others in the requested order. With pinned set the cursor is a position among the
+// pinned projects (pinned_at, project_id), the page continues with the others once they run out.
+func (s *projectService) ListProjectsPaginated(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, pinned, includeDrafts bool, limit int32, order coreTypes.SortOrder) (_ []types.Project, err error) {
+	defer s.operation("ListProjectsPaginated", userID, uuid.Nil,
 		zap.Time("cursor", cursor),
 		zap.String("cursor_id", cursorID.String()),
-		zap.Int32("limit", limit))
+		zap.Bool("pinned", pinned),
+		zap.Bool("include_drafts", includeDrafts),
+		zap.Int32("limit", limit),
+		zap.String("order", string(order))).End(&err)
 
 	if limit <= 0 {
 		return nil, fmt.Errorf("limit must be positive")
 	}
 
-	return s.repo.ListProjectsPaginated(ctx, userID, cursor, cursorID, limit)
+	var projects []types.Project
+	if pinned {
+		pinnedProjects, err := s.repo.ListPinnedProjectsPaginated(ctx, userID, cursor, cursorID, includeDrafts, limit)
+		if err != nil {
+			return nil, err
+		}
+		if len(pinnedProjects) == int(limit) {
+			return pinnedProjects, nil
+		}
+		projects = pinnedProjects
+		limit -= int32(len(pinnedProjects))
+		cursor, cursorID = coreTypes.StartCursor(order)
+	}
+
+	unpinned, err := s.repo.ListProjectsPaginated(ctx, userID, cursor, cursorID, includeDrafts, limit, order)
+	if err != nil {
+		return nil, err
+	}
+	return append(projects, unpinned...), nil
+}
+
+// ListProjectIDsPaginated is ListProjectsPaginated returning only the IDs of the projects
+// and the keys to page on from them
+func (s *projectService) ListProjectIDsPaginated(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, pinned, includeDrafts bool, limit int32, order coreTypes.SortOrder) (_ []coreTypes.KeyedID, err error) {
+	defer s.operation("ListProjectIDsPaginated", userID, uuid.Nil,
+		zap.Time("cursor", cursor),
+		zap.String("cursor_id", cursorID.String()),
+		zap.Bool("pinned", pinned),
+		zap.Bool("include_drafts", includeDrafts),
+		zap.Int32("limit", limit),
+		zap.String("order", string(order))).End(&err)
+
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+
+	var keys []coreTypes.KeyedID
+	if pinned {
+		pinnedKeys, err := s.repo.ListPinnedProjectIDsPaginated(ctx, userID, cursor, cursorID, includeDrafts, limit)
+		if err != nil {
+			return nil, err
+		}
+		if len(pinnedKeys) == int(limit) {
+			return pinnedKeys, nil
+		}
+		keys = pinnedKeys
+		limit -= int32(len(pinnedKeys))
+		cursor, cursorID = coreTypes.StartCursor(order)
+	}
+
+	unpinned, err := s.repo.ListProjectIDsPaginated(ctx, userID, cursor, cursorID, includeDrafts, limit, order)
+	if err != nil {
+		return nil, err
+	}
+	return append(keys, unpinned...), nil
 }
 
-func (s *projectService) SearchProjects(ctx context.Context, userID uuid.UUID, query string, limit int32) ([]types.Project, error) {
-	s.logger.Info("searching projects",
-		zap.String("user_id", userID.String()),
+// StreamProjectsPaginated is ListProjectsPaginated handing each project to fn as it is read
+func (s *projectService) StreamProjectsPaginated(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, pinned, includeDrafts bool, limit int32, order coreTypes.SortOrder, fn func(types.Project) error) (err error) {
+	defer s.operation("StreamProjectsPaginated", userID, uuid.Nil,
+		zap.Time("cursor", cursor),
+		zap.String("cursor_id", cursorID.String()),
+		zap.Bool("pinned", pinned),
+		zap.Bool("include_drafts", includeDrafts),
+		zap.Int32("limit", limit),
+		zap.String("order", string(order))).End(&err)
+
+	if limit <= 0 {
+		return fmt.Errorf("limit must be positive")
+	}
+
+	if pinned {
+		var streamed int32
+		err := s.repo.ListPinnedProjectsPaginatedStream(ctx, userID, cursor, cursorID, includeDrafts, limit, func(project types.Project) error {
+			streamed++
+			return fn(project)
+		})
+		if err != nil || streamed == limit {
+			return err
+		}
+		limit -= streamed
+		cursor, cursorID = coreTypes.StartCursor(order)
+	}
+
+	return s.repo.ListProjectsPaginatedStream(ctx, userID, cursor, cursorID, includeDrafts, limit, order, fn)
+}
+
+// PinProject puts the project at the top of the listings, up to MaxPinnedProjects per
+// user. Pinning a pinned project leaves it as it is.
+func (s *projectService) PinProject(ctx context.Context, userID, projectID uuid.UUID) (_ types.Project, err error) {
+	defer s.operation("PinProject", userID, projectID).End(&err)
+
+	project, err := s.repo.GetProject(ctx, userID, projectID)
+	if err != nil {
+		return types.Project{}, err
+	}
+	if project.Pinned {
+		return project, nil
+	}
+
+	project, err = s.repo.SetProjectPinned(ctx, userID, projectID, true)
+	if stdErrors.Is(err, repository.ErrPinnedProjectLimit) {
+		return types.Project{}, errors.NewValidationError("at most %d projects can be pinned, unpin one first", types.MaxPinnedProjects)
+	}
+	return s.published(userID, events.ActionUpdated, project, err)
+}
+
+// UnpinProject moves the project back among the unpinned ones
+func (s *projectService) UnpinProject(ctx context.Context, userID, projectID uuid.UUID) (_ types.Project, err error) {
+	defer s.operation("UnpinProject", userID, projectID).End(&err)
+	project, err := s.repo.SetProjectPinned(ctx, userID, projectID, false)
+	return s.published(userID, events.ActionUpdated, project, err)
+}
+
+func (s *projectService) SearchProjects(ctx context.Context, userID uuid.UUID, query string, includeDrafts bool, limit, offset int32) (_ []types.Project, err error) {
+	defer s.operation("SearchProjects", userID, uuid.Nil,
+		zap.String("query", query),
+		zap.Bool("include_drafts", includeDrafts),
+		zap.Int32("limit", limit),
+		zap.Int32("offset", offset)).End(&err)
+	key := fmt.Sprintf("%s:projects:name:%q:%t:%d:%d", userID, query, includeDrafts, limit, offset)
+	return cache.Coalesce(ctx, &s.searches, key, func(ctx context.Context) ([]types.Project, error) {
+		return s.repo.SearchProjects(ctx, userID, query, includeDrafts, limit, offset)
+	}, cloneProjects)
+}
+
+func (s *projectService) SearchProjectsPicker(ctx context.Context, userID uuid.UUID, query string, includeDrafts bool, limit, offset int32) (_ []types.ProjectPickerItem, err error) {
+	defer s.operation("SearchProjectsPicker", userID, uuid.Nil,
 		zap.String("query", query),
-		zap.Int32("limit", limit))
-	return s.repo.SearchProjects(ctx, userID, query, limit)
+		zap.Bool("include_drafts", includeDrafts),
+		zap.Int32("limit", limit),
+		zap.Int32("offset", offset)).End(&err)
+	key := fmt.Sprintf("%s:projects:picker:name:%q:%t:%d:%d", userID, query, includeDrafts, limit, offset)
+	return cache.Coalesce(ctx, &s.searches, key, func(ctx context.Context) ([]types.ProjectPickerItem, error) {
+		return s.repo.SearchProjectsPicker(ctx, userID, query, includeDrafts, limit, offset)
+	}, slices.Clone)
+}
+
+// cloneProjects copies the projects of a coalesced search for one of its callers
+func cloneProjects(projects []types.Project) []types.Project {
+	cloned := slices.Clone(projects)
+	for i := range cloned {
+		cloned[i].Tags = slices.Clone(cloned[i].Tags)
+	}
+	return cloned
 }
 
 func isValidProjectStatus(status string) bool {
@@ -3,17 +3,30 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/currency"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/deletion"
+	coreErrors "github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/logging"
+	coreRepository "github.com/Abdelrahman-habib/expense-tracker/internal/core/repository"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/projects/repository"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/projects/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/validate"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 // Mock repository
@@ -26,45 +39,204 @@ func (m *mockProjectRepository) ListProjects(ctx context.Context, userID uuid.UU
 	return args.Get(0).([]types.Project), args.Error(1)
 }
 
+func (m *mockProjectRepository) GetProjectsByIDs(ctx context.Context, userID uuid.UUID, projectIDs []uuid.UUID) ([]types.Project, []uuid.UUID, error) {
+	args := m.Called(ctx, userID, projectIDs)
+	return args.Get(0).([]types.Project), args.Get(1).([]uuid.UUID), args.Error(2)
+}
+
 func (m *mockProjectRepository) GetProject(ctx context.Context, userID, projectID uuid.UUID) (types.Project, error) {
 	args := m.Called(ctx, userID, projectID)
 	return args.Get(0).(types.Project), args.Error(1)
 }
 
+func (m *mockProjectRepository) GetProjectByName(ctx context.Context, userID uuid.UUID, name string) (types.Project, error) {
+	args := m.Called(ctx, userID, name)
+	return args.Get(0).(types.Project), args.Error(1)
+}
+
 func (m *mockProjectRepository) CreateProject(ctx context.Context, userID uuid.UUID, projectData types.ProjectCreatePayload) (types.Project, error) {
 	args := m.Called(ctx, userID, projectData)
 	return args.Get(0).(types.Project), args.Error(1)
 }
 
+// UpdateProjectLocked merges onto a project holding only its ID, the update is then
+// matched on the merged payload
+func (m *mockProjectRepository) UpdateProjectLocked(ctx context.Context, userID, projectID uuid.UUID, merge func(types.Project) (types.ProjectUpdatePayload, error)) (types.Project, error) {
+	projectData, err := merge(types.Project{ProjectID: projectID})
+	if err != nil {
+		return types.Project{}, err
+	}
+	return m.UpdateProject(ctx, userID, projectData)
+}
+
 func (m *mockProjectRepository) UpdateProject(ctx context.Context, userID uuid.UUID, projectData types.ProjectUpdatePayload) (types.Project, error) {
 	args := m.Called(ctx, userID, projectData)
 	return args.Get(0).(types.Project), args.Error(1)
 }
 
-func (m *mockProjectRepository) DeleteProject(ctx context.Context, userID, projectID uuid.UUID) error {
-	args := m.Called(ctx, userID, projectID)
+func (m *mockProjectRepository) DeleteProject(ctx context.Context, userID, projectID uuid.UUID, version *time.Time) error {
+	args := m.Called(ctx, userID, projectID, version)
 	return args.Error(0)
 }
 
+func (m *mockProjectRepository) ListDeletedProjectsPaginated(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, limit int32, order coreTypes.SortOrder) ([]types.Project, error) {
+	args := m.Called(ctx, userID, cursor, cursorID, limit, order)
+	return args.Get(0).([]types.Project), args.Error(1)
+}
+
+func (m *mockProjectRepository) RestoreProject(ctx context.Context, userID, projectID uuid.UUID) (types.Project, error) {
+	args := m.Called(ctx, userID, projectID)
+	return args.Get(0).(types.Project), args.Error(1)
+}
+
+func (m *mockProjectRepository) CloneProject(ctx context.Context, userID, projectID uuid.UUID, includeWallets bool) (types.Project, error) {
+	args := m.Called(ctx, userID, projectID, includeWallets)
+	return args.Get(0).(types.Project), args.Error(1)
+}
+
+func (m *mockProjectRepository) PublishProject(ctx context.Context, userID, projectID uuid.UUID, rules types.PublishRules) (types.Project, error) {
+	args := m.Called(ctx, userID, projectID, rules)
+	return args.Get(0).(types.Project), args.Error(1)
+}
+
 func (m *mockProjectRepository) GetProjectWallets(ctx context.Context, userID, projectID uuid.UUID) ([]db.Wallet, error) {
 	args := m.Called(ctx, userID, projectID)
 	return args.Get(0).([]db.Wallet), args.Error(1)
 }
 
-func (m *mockProjectRepository) ListProjectsPaginated(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, limit int32) ([]types.Project, error) {
-	args := m.Called(ctx, userID, cursor, cursorID, limit)
+func (m *mockProjectRepository) DeleteProjectTree(ctx context.Context, userID, projectID uuid.UUID, version *time.Time) error {
+	args := m.Called(ctx, userID, projectID, version)
+	return args.Error(0)
+}
+
+func (m *mockProjectRepository) DeleteProjectDetachingChildren(ctx context.Context, userID, projectID uuid.UUID, version *time.Time) error {
+	args := m.Called(ctx, userID, projectID, version)
+	return args.Error(0)
+}
+
+func (m *mockProjectRepository) ListChildProjects(ctx context.Context, userID, projectID uuid.UUID) ([]types.Project, error) {
+	args := m.Called(ctx, userID, projectID)
+	return args.Get(0).([]types.Project), args.Error(1)
+}
+
+func (m *mockProjectRepository) CountChildProjects(ctx context.Context, userID, projectID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, userID, projectID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockProjectRepository) CountProjectTreeMilestones(ctx context.Context, userID, projectID uuid.UUID, rollup bool) (int64, error) {
+	args := m.Called(ctx, userID, projectID, rollup)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockProjectRepository) ListProjectAncestors(ctx context.Context, userID, projectID uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(ctx, userID, projectID)
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *mockProjectRepository) GetProjectSubtreeDepth(ctx context.Context, userID, projectID uuid.UUID) (int32, error) {
+	args := m.Called(ctx, userID, projectID)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (m *mockProjectRepository) GetProjectSummary(ctx context.Context, userID, projectID uuid.UUID, rollup bool) (types.ProjectSummary, error) {
+	args := m.Called(ctx, userID, projectID, rollup)
+	return args.Get(0).(types.ProjectSummary), args.Error(1)
+}
+
+func (m *mockProjectRepository) ListProjectsPaginated(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, includeDrafts bool, limit int32, order coreTypes.SortOrder) ([]types.Project, error) {
+	args := m.Called(ctx, userID, cursor, cursorID, includeDrafts, limit, order)
+	return args.Get(0).([]types.Project), args.Error(1)
+}
+
+func (m *mockProjectRepository) ListPinnedProjectsPaginated(ctx context.Context, userID uuid.UUID, pinnedAt time.Time, cursorID uuid.UUID, includeDrafts bool, limit int32) ([]types.Project, error) {
+	args := m.Called(ctx, userID, pinnedAt, cursorID, includeDrafts, limit)
 	return args.Get(0).([]types.Project), args.Error(1)
 }
 
-func (m *mockProjectRepository) SearchProjects(ctx context.Context, userID uuid.UUID, query string, limit int32) ([]types.Project, error) {
-	args := m.Called(ctx, userID, query, limit)
+func (m *mockProjectRepository) ListProjectIDsPaginated(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, includeDrafts bool, limit int32, order coreTypes.SortOrder) ([]coreTypes.KeyedID, error) {
+	args := m.Called(ctx, userID, cursor, cursorID, includeDrafts, limit, order)
+	return args.Get(0).([]coreTypes.KeyedID), args.Error(1)
+}
+
+func (m *mockProjectRepository) ListPinnedProjectIDsPaginated(ctx context.Context, userID uuid.UUID, pinnedAt time.Time, cursorID uuid.UUID, includeDrafts bool, limit int32) ([]coreTypes.KeyedID, error) {
+	args := m.Called(ctx, userID, pinnedAt, cursorID, includeDrafts, limit)
+	return args.Get(0).([]coreTypes.KeyedID), args.Error(1)
+}
+
+func (m *mockProjectRepository) ListProjectsPaginatedStream(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, includeDrafts bool, limit int32, order coreTypes.SortOrder, fn func(types.Project) error) error {
+	args := m.Called(ctx, userID, cursor, cursorID, includeDrafts, limit, order)
+	return streamProjects(args.Get(0).([]types.Project), args.Error(1), fn)
+}
+
+func (m *mockProjectRepository) ListPinnedProjectsPaginatedStream(ctx context.Context, userID uuid.UUID, pinnedAt time.Time, cursorID uuid.UUID, includeDrafts bool, limit int32, fn func(types.Project) error) error {
+	args := m.Called(ctx, userID, pinnedAt, cursorID, includeDrafts, limit)
+	return streamProjects(args.Get(0).([]types.Project), args.Error(1), fn)
+}
+
+// streamProjects hands projects to fn and then returns err, the way a stream failing
+// after its rows does
+func streamProjects(projects []types.Project, err error, fn func(types.Project) error) error {
+	for _, project := range projects {
+		if err := fn(project); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+func (m *mockProjectRepository) SetProjectPinned(ctx context.Context, userID, projectID uuid.UUID, pinned bool) (types.Project, error) {
+	args := m.Called(ctx, userID, projectID, pinned)
+	return args.Get(0).(types.Project), args.Error(1)
+}
+
+func (m *mockProjectRepository) SearchProjects(ctx context.Context, userID uuid.UUID, query string, includeDrafts bool, limit, offset int32) ([]types.Project, error) {
+	args := m.Called(ctx, userID, query, includeDrafts, limit, offset)
 	return args.Get(0).([]types.Project), args.Error(1)
 }
 
+func (m *mockProjectRepository) SearchProjectsPicker(ctx context.Context, userID uuid.UUID, query string, includeDrafts bool, limit, offset int32) ([]types.ProjectPickerItem, error) {
+	args := m.Called(ctx, userID, query, includeDrafts, limit, offset)
+	return args.Get(0).([]types.ProjectPickerItem), args.Error(1)
+}
+
+func (m *mockProjectRepository) ListMilestones(ctx context.Context, userID, projectID uuid.UUID) ([]types.Milestone, error) {
+	args := m.Called(ctx, userID, projectID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.Milestone), args.Error(1)
+}
+
+func (m *mockProjectRepository) GetMilestone(ctx context.Context, userID, projectID, milestoneID uuid.UUID) (types.Milestone, error) {
+	args := m.Called(ctx, userID, projectID, milestoneID)
+	return args.Get(0).(types.Milestone), args.Error(1)
+}
+
+func (m *mockProjectRepository) CreateMilestone(ctx context.Context, userID, projectID uuid.UUID, milestoneData types.MilestoneCreatePayload) (types.Milestone, error) {
+	args := m.Called(ctx, userID, projectID, milestoneData)
+	return args.Get(0).(types.Milestone), args.Error(1)
+}
+
+func (m *mockProjectRepository) UpdateMilestone(ctx context.Context, userID uuid.UUID, milestoneData types.MilestoneUpdatePayload) (types.Milestone, error) {
+	args := m.Called(ctx, userID, milestoneData)
+	return args.Get(0).(types.Milestone), args.Error(1)
+}
+
+func (m *mockProjectRepository) DeleteMilestone(ctx context.Context, userID, projectID, milestoneID uuid.UUID) error {
+	args := m.Called(ctx, userID, projectID, milestoneID)
+	return args.Error(0)
+}
+
+func (m *mockProjectRepository) ReorderMilestones(ctx context.Context, userID, projectID uuid.UUID, milestoneIDs []uuid.UUID) error {
+	args := m.Called(ctx, userID, projectID, milestoneIDs)
+	return args.Error(0)
+}
+
 func setupTest(t *testing.T) (*mockProjectRepository, ProjectService) {
 	mockRepo := new(mockProjectRepository)
 	logger := zap.NewNop()
-	service := NewProjectService(mockRepo, logger)
+	service := NewProjectService(mockRepo, types.PublishRules{}, nil, "", nil, nil, logger)
 	return mockRepo, service
 }
 
@@ -117,8 +289,8 @@ func TestProjectService_CreateProject(t *testing.T) {
 			payload: types.ProjectCreatePayload{
 				Name:      "Test Project",
 				Status:    "ongoing",
-				StartDate: utils.TimePtr(time.Now()),
-				EndDate:   utils.TimePtr(time.Now().Add(-24 * time.Hour)),
+				StartDate: coreTypes.DatePtr(utils.TimePtr(time.Now())),
+				EndDate:   coreTypes.DatePtr(utils.TimePtr(time.Now().Add(-24 * time.Hour))),
 			},
 			mock:    func() {},
 			wantErr: true,
@@ -166,6 +338,85 @@ func TestProjectService_CreateProject(t *testing.T) {
 	}
 }
 
+func TestProjectService_CreateProjectIfNotExists(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	payload := types.ProjectCreatePayload{Name: "Kitchen Renovation", Status: "ongoing"}
+	existing := types.Project{ProjectID: uuid.New(), Name: "kitchen renovation"}
+	notFound := coreErrors.NewNotFoundError("project not found")
+
+	tests := []struct {
+		name        string
+		payload     types.ProjectCreatePayload
+		mock        func(*mockProjectRepository)
+		wantProject types.Project
+		wantCreated bool
+		wantErr     bool
+	}{
+		{
+			name:    "returns the existing project",
+			payload: payload,
+			mock: func(m *mockProjectRepository) {
+				m.On("GetProjectByName", ctx, userID, "Kitchen Renovation").Return(existing, nil)
+			},
+			wantProject: existing,
+		},
+		{
+			name:    "creates a missing project",
+			payload: payload,
+			mock: func(m *mockProjectRepository) {
+				m.On("GetProjectByName", ctx, userID, "Kitchen Renovation").Return(types.Project{}, notFound)
+				m.On("CreateProject", ctx, userID, payload).Return(types.Project{Name: "Kitchen Renovation"}, nil)
+			},
+			wantProject: types.Project{Name: "Kitchen Renovation"},
+			wantCreated: true,
+		},
+		{
+			name:    "returns the project created concurrently",
+			payload: payload,
+			mock: func(m *mockProjectRepository) {
+				m.On("GetProjectByName", ctx, userID, "Kitchen Renovation").Return(types.Project{}, notFound).Once()
+				m.On("CreateProject", ctx, userID, payload).Return(types.Project{}, &coreErrors.ErrorResponse{Type: coreErrors.ErrorTypeConflict, Message: "project already exists"})
+				m.On("GetProjectByName", ctx, userID, "Kitchen Renovation").Return(existing, nil).Once()
+			},
+			wantProject: existing,
+		},
+		{
+			name:    "validates before looking up",
+			payload: types.ProjectCreatePayload{Name: "Kitchen Renovation", Status: "invalid_status"},
+			mock:    func(m *mockProjectRepository) {},
+			wantErr: true,
+		},
+		{
+			name:    "lookup failure",
+			payload: payload,
+			mock: func(m *mockProjectRepository) {
+				m.On("GetProjectByName", ctx, userID, "Kitchen Renovation").Return(types.Project{}, errors.New("database error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo, service := setupTest(t)
+			tt.mock(mockRepo)
+
+			project, created, err := service.CreateProjectIfNotExists(ctx, userID, tt.payload)
+			if tt.wantErr {
+				assert.Error(t, err)
+				mockRepo.AssertNotCalled(t, "CreateProject", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantProject, project)
+			assert.Equal(t, tt.wantCreated, created)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
 func TestProjectService_GetProject(t *testing.T) {
 	mockRepo, service := setupTest(t)
 	ctx := context.Background()
@@ -204,7 +455,7 @@ func TestProjectService_GetProject(t *testing.T) {
 			mockRepo.ExpectedCalls = nil
 
 			tt.mock()
-			project, err := service.GetProject(ctx, userID, projectID)
+			project, err := service.GetProject(ctx, userID, projectID, types.ProjectExpand{})
 			if tt.wantErr {
 				assert.Error(t, err)
 				return
@@ -326,8 +577,8 @@ func TestProjectService_UpdateProject(t *testing.T) {
 				ProjectID: projectID,
 				Name:      "Test Project",
 				Status:    "ongoing",
-				StartDate: utils.TimePtr(time.Now()),
-				EndDate:   utils.TimePtr(time.Now().Add(-24 * time.Hour)),
+				StartDate: coreTypes.DatePtr(utils.TimePtr(time.Now())),
+				EndDate:   coreTypes.DatePtr(utils.TimePtr(time.Now().Add(-24 * time.Hour))),
 			},
 			mock:    func() {},
 			wantErr: true,
@@ -363,7 +614,9 @@ func TestProjectService_UpdateProject(t *testing.T) {
 			mockRepo.ExpectedCalls = nil
 			tt.mock()
 
-			project, err := service.UpdateProject(ctx, userID, tt.payload)
+			project, err := service.UpdateProject(ctx, userID, tt.payload.ProjectID, func(types.Project) (types.ProjectUpdatePayload, error) {
+				return tt.payload, nil
+			})
 			if tt.wantErr {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), tt.errMsg)
@@ -377,6 +630,262 @@ func TestProjectService_UpdateProject(t *testing.T) {
 	}
 }
 
+func TestProjectService_UpdateProject_Parent(t *testing.T) {
+	mockRepo, service := setupTest(t)
+	ctx := context.Background()
+	userID := uuid.New()
+	projectID := uuid.New()
+	parentID := uuid.New()
+	rootID := uuid.New()
+
+	payload := types.ProjectUpdatePayload{
+		ProjectID:       projectID,
+		Name:            "Sub Project",
+		Status:          "ongoing",
+		ParentProjectID: &parentID,
+	}
+
+	tests := []struct {
+		name    string
+		payload types.ProjectUpdatePayload
+		mock    func()
+		errMsg  string
+	}{
+		{
+			name:    "nested under a top-level project",
+			payload: payload,
+			mock: func() {
+				mockRepo.On("ListProjectAncestors", ctx, userID, parentID).Return([]uuid.UUID{parentID}, nil)
+				mockRepo.On("GetProjectSubtreeDepth", ctx, userID, projectID).Return(int32(2), nil)
+				mockRepo.On("UpdateProject", ctx, userID, payload).Return(types.Project{ProjectID: projectID, ParentProjectID: &parentID}, nil)
+			},
+		},
+		{
+			name: "own parent",
+			payload: types.ProjectUpdatePayload{
+				ProjectID:       projectID,
+				Name:            "Sub Project",
+				Status:          "ongoing",
+				ParentProjectID: &projectID,
+			},
+			mock:   func() {},
+			errMsg: "a project can't be its own parent",
+		},
+		{
+			name:    "parent not found",
+			payload: payload,
+			mock: func() {
+				mockRepo.On("ListProjectAncestors", ctx, userID, parentID).Return([]uuid.UUID{}, nil)
+			},
+			errMsg: "parent_project_id: project not found",
+		},
+		{
+			name:    "parent is a descendant",
+			payload: payload,
+			mock: func() {
+				mockRepo.On("ListProjectAncestors", ctx, userID, parentID).Return([]uuid.UUID{parentID, projectID, rootID}, nil)
+			},
+			errMsg: "can't be nested under its own sub-project",
+		},
+		{
+			name:    "tree too deep",
+			payload: payload,
+			mock: func() {
+				mockRepo.On("ListProjectAncestors", ctx, userID, parentID).Return([]uuid.UUID{parentID, rootID}, nil)
+				mockRepo.On("GetProjectSubtreeDepth", ctx, userID, projectID).Return(int32(2), nil)
+			},
+			errMsg: "nested at most 3 levels deep",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.ExpectedCalls = nil
+			mockRepo.Calls = nil
+			tt.mock()
+
+			project, err := service.UpdateProject(ctx, userID, tt.payload.ProjectID, func(types.Project) (types.ProjectUpdatePayload, error) {
+				return tt.payload, nil
+			})
+			if tt.errMsg != "" {
+				assert.True(t, coreErrors.IsErrorType(err, coreErrors.ErrorTypeValidation))
+				assert.Contains(t, err.Error(), tt.errMsg)
+				mockRepo.AssertNotCalled(t, "UpdateProject", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, &parentID, project.ParentProjectID)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProjectService_DeleteProject(t *testing.T) {
+	mockRepo, service := setupTest(t)
+	ctx := context.Background()
+	userID := uuid.New()
+	projectID := uuid.New()
+
+	// impact mocks the checks every delete runs first
+	impact := func(rollup bool, children int64) {
+		mockRepo.On("GetProjectSummary", ctx, userID, projectID, rollup).Return(types.ProjectSummary{ProjectID: projectID, Projects: 1}, nil)
+		mockRepo.On("CountChildProjects", ctx, userID, projectID).Return(children, nil)
+		mockRepo.On("CountProjectTreeMilestones", ctx, userID, projectID, rollup).Return(int64(0), nil)
+	}
+
+	tests := []struct {
+		name     string
+		children types.ChildrenMode
+		mock     func()
+		conflict bool
+		notFound bool
+	}{
+		{
+			name: "without sub-projects",
+			mock: func() {
+				impact(false, 0)
+				mockRepo.On("DeleteProject", ctx, userID, projectID, (*time.Time)(nil)).Return(nil)
+			},
+		},
+		{
+			name: "with sub-projects",
+			mock: func() {
+				impact(false, 2)
+			},
+			conflict: true,
+		},
+		{
+			name:     "cascade",
+			children: types.ChildrenCascade,
+			mock: func() {
+				impact(true, 2)
+				mockRepo.On("DeleteProjectTree", ctx, userID, projectID, (*time.Time)(nil)).Return(nil)
+			},
+		},
+		{
+			name:     "detach",
+			children: types.ChildrenDetach,
+			mock: func() {
+				impact(false, 2)
+				mockRepo.On("DeleteProjectDetachingChildren", ctx, userID, projectID, (*time.Time)(nil)).Return(nil)
+			},
+		},
+		{
+			name: "missing project",
+			mock: func() {
+				mockRepo.On("GetProjectSummary", ctx, userID, projectID, false).
+					Return(types.ProjectSummary{}, coreErrors.NewNotFoundError("project not found"))
+			},
+			notFound: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.ExpectedCalls = nil
+			mockRepo.Calls = nil
+			tt.mock()
+
+			err := service.DeleteProject(ctx, userID, projectID, tt.children, nil)
+			if tt.conflict {
+				assert.True(t, coreErrors.IsErrorType(err, coreErrors.ErrorTypeConflict))
+				assert.Contains(t, err.Error(), "2 sub-project(s)")
+				mockRepo.AssertNotCalled(t, "DeleteProject", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			if tt.notFound {
+				assert.True(t, coreErrors.IsErrorType(err, coreErrors.ErrorTypeNotFound))
+				mockRepo.AssertNotCalled(t, "DeleteProject", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+
+			assert.NoError(t, err)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProjectService_DeletionImpact(t *testing.T) {
+	mockRepo, service := setupTest(t)
+	ctx := context.Background()
+	userID := uuid.New()
+	projectID := uuid.New()
+
+	summary := types.ProjectSummary{
+		ProjectID: projectID,
+		Projects:  3,
+		Wallets:   2,
+		Balances: []types.CurrencyBalance{
+			{Currency: "EUR", Wallets: 1, Balance: 10},
+			{Currency: "USD", Wallets: 1, Balance: 150.5},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		children types.ChildrenMode
+		rollup   bool
+		expected deletion.Impact
+	}{
+		{
+			name: "restrict blocks on sub-projects",
+			expected: deletion.Impact{
+				Blocked: true,
+				Reasons: []string{"project has 2 sub-project(s), delete with cascade=true or detach=true"},
+				Affected: []deletion.Affected{
+					{Kind: "projects", Count: 3, Effect: deletion.EffectTrashed},
+					{Kind: "wallets", Count: 2, Effect: deletion.EffectDetached, Balances: []deletion.Balance{{Currency: "EUR", Balance: 10}, {Currency: "USD", Balance: 150.5}}},
+					{Kind: "subProjects", Count: 2, Effect: deletion.EffectBlocked},
+					{Kind: "milestones", Count: 5, Effect: deletion.EffectKept},
+				},
+			},
+		},
+		{
+			name:     "detach",
+			children: types.ChildrenDetach,
+			expected: deletion.Impact{
+				Affected: []deletion.Affected{
+					{Kind: "projects", Count: 3, Effect: deletion.EffectTrashed},
+					{Kind: "wallets", Count: 2, Effect: deletion.EffectDetached, Balances: []deletion.Balance{{Currency: "EUR", Balance: 10}, {Currency: "USD", Balance: 150.5}}},
+					{Kind: "subProjects", Count: 2, Effect: deletion.EffectDetached},
+					{Kind: "milestones", Count: 5, Effect: deletion.EffectKept},
+				},
+			},
+		},
+		{
+			name:     "cascade counts the whole tree",
+			children: types.ChildrenCascade,
+			rollup:   true,
+			expected: deletion.Impact{
+				Affected: []deletion.Affected{
+					{Kind: "projects", Count: 3, Effect: deletion.EffectTrashed},
+					{Kind: "wallets", Count: 2, Effect: deletion.EffectDetached, Balances: []deletion.Balance{{Currency: "EUR", Balance: 10}, {Currency: "USD", Balance: 150.5}}},
+					{Kind: "milestones", Count: 5, Effect: deletion.EffectKept},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.ExpectedCalls = nil
+			mockRepo.Calls = nil
+			mockRepo.On("GetProjectSummary", ctx, userID, projectID, tt.rollup).Return(summary, nil)
+			mockRepo.On("CountChildProjects", ctx, userID, projectID).Return(int64(2), nil)
+			mockRepo.On("CountProjectTreeMilestones", ctx, userID, projectID, tt.rollup).Return(int64(5), nil)
+
+			impact, err := service.DeletionImpact(ctx, userID, projectID, tt.children)
+			require.NoError(t, err)
+			tt.expected.Resource = "project"
+			tt.expected.ID = projectID
+			assert.Equal(t, tt.expected, impact)
+			assert.Equal(t, tt.expected.Blocked, impact.Err() != nil)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
 func TestProjectService_ListProjectsPaginated(t *testing.T) {
 	mockRepo, service := setupTest(t)
 	ctx := context.Background()
@@ -405,16 +914,16 @@ func TestProjectService_ListProjectsPaginated(t *testing.T) {
 						ProjectID: uuid.New(),
 						Name:      "Project 1",
 						Status:    "ongoing",
-						CreatedAt: now.Add(-1 * time.Hour),
+						CreatedAt: coreTypes.NewTimestamp(now.Add(-1 * time.Hour)),
 					},
 					{
 						ProjectID: uuid.New(),
 						Name:      "Project 2",
 						Status:    "completed",
-						CreatedAt: now.Add(-2 * time.Hour),
+						CreatedAt: coreTypes.NewTimestamp(now.Add(-2 * time.Hour)),
 					},
 				}
-				mockRepo.On("ListProjectsPaginated", ctx, userID, now, cursorID, int32(10)).
+				mockRepo.On("ListProjectsPaginated", ctx, userID, now, cursorID, false, int32(10), coreTypes.SortOrderDesc).
 					Return(projects, nil)
 			},
 			wantErr: false,
@@ -435,7 +944,7 @@ func TestProjectService_ListProjectsPaginated(t *testing.T) {
 			cursorID: cursorID,
 			limit:    10,
 			mock: func() {
-				mockRepo.On("ListProjectsPaginated", ctx, userID, now, cursorID, int32(10)).
+				mockRepo.On("ListProjectsPaginated", ctx, userID, now, cursorID, false, int32(10), coreTypes.SortOrderDesc).
 					Return([]types.Project{}, nil)
 			},
 			wantErr: false,
@@ -447,7 +956,7 @@ func TestProjectService_ListProjectsPaginated(t *testing.T) {
 			cursorID: cursorID,
 			limit:    10,
 			mock: func() {
-				mockRepo.On("ListProjectsPaginated", ctx, userID, now, cursorID, int32(10)).
+				mockRepo.On("ListProjectsPaginated", ctx, userID, now, cursorID, false, int32(10), coreTypes.SortOrderDesc).
 					Return([]types.Project{}, errors.New("database error"))
 			},
 			wantErr: true,
@@ -460,7 +969,7 @@ func TestProjectService_ListProjectsPaginated(t *testing.T) {
 			mockRepo.ExpectedCalls = nil
 			tt.mock()
 
-			projects, err := service.ListProjectsPaginated(ctx, userID, tt.cursor, tt.cursorID, tt.limit)
+			projects, err := service.ListProjectsPaginated(ctx, userID, tt.cursor, tt.cursorID, false, false, tt.limit, coreTypes.SortOrderDesc)
 			if tt.wantErr {
 				assert.Error(t, err)
 				if tt.errMsg != "" {
@@ -477,11 +986,624 @@ func TestProjectService_ListProjectsPaginated(t *testing.T) {
 			if len(projects) > 1 {
 				for i := 1; i < len(projects); i++ {
 					// Check that results are ordered by created_at DESC
-					assert.True(t, projects[i-1].CreatedAt.After(projects[i].CreatedAt) ||
-						(projects[i-1].CreatedAt.Equal(projects[i].CreatedAt) &&
+					assert.True(t, projects[i-1].CreatedAt.After(projects[i].CreatedAt.Time) ||
+						(projects[i-1].CreatedAt.Equal(projects[i].CreatedAt.Time) &&
 							projects[i-1].ProjectID.String() > projects[i].ProjectID.String()))
 				}
 			}
 		})
 	}
 }
+
+func TestProjectService_ListProjectsPaginated_Pinned(t *testing.T) {
+	mockRepo, service := setupTest(t)
+	ctx := context.Background()
+	userID := uuid.New()
+	start, startID := coreTypes.StartPinnedCursor()
+	pinned := []types.Project{{ProjectID: uuid.New(), Name: "Pinned 1", Pinned: true}, {ProjectID: uuid.New(), Name: "Pinned 2", Pinned: true}}
+	unpinned := []types.Project{{ProjectID: uuid.New(), Name: "Unpinned"}}
+
+	t.Run("page within the pinned projects", func(t *testing.T) {
+		mockRepo.ExpectedCalls = nil
+		mockRepo.Calls = nil
+		mockRepo.On("ListPinnedProjectsPaginated", ctx, userID, start, startID, false, int32(2)).Return(pinned, nil)
+
+		projects, err := service.ListProjectsPaginated(ctx, userID, start, startID, true, false, 2, coreTypes.SortOrderAsc)
+		assert.NoError(t, err)
+		assert.Equal(t, pinned, projects)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "ListProjectsPaginated")
+	})
+
+	t.Run("page continuing with the unpinned projects", func(t *testing.T) {
+		mockRepo.ExpectedCalls = nil
+		mockRepo.On("ListPinnedProjectsPaginated", ctx, userID, start, startID, false, int32(5)).Return(pinned, nil)
+		unpinnedStart, unpinnedStartID := coreTypes.StartCursor(coreTypes.SortOrderAsc)
+		mockRepo.On("ListProjectsPaginated", ctx, userID, unpinnedStart, unpinnedStartID, false, int32(3), coreTypes.SortOrderAsc).Return(unpinned, nil)
+
+		projects, err := service.ListProjectsPaginated(ctx, userID, start, startID, true, false, 5, coreTypes.SortOrderAsc)
+		assert.NoError(t, err)
+		assert.Equal(t, append(append([]types.Project{}, pinned...), unpinned...), projects)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestProjectService_StreamProjectsPaginated_Pinned(t *testing.T) {
+	mockRepo, service := setupTest(t)
+	ctx := context.Background()
+	userID := uuid.New()
+	start, startID := coreTypes.StartPinnedCursor()
+	pinned := []types.Project{{ProjectID: uuid.New(), Name: "Pinned 1", Pinned: true}, {ProjectID: uuid.New(), Name: "Pinned 2", Pinned: true}}
+	unpinned := []types.Project{{ProjectID: uuid.New(), Name: "Unpinned"}}
+
+	collect := func(limit int32) ([]types.Project, error) {
+		var streamed []types.Project
+		err := service.StreamProjectsPaginated(ctx, userID, start, startID, true, false, limit, coreTypes.SortOrderAsc, func(project types.Project) error {
+			streamed = append(streamed, project)
+			return nil
+		})
+		return streamed, err
+	}
+
+	t.Run("page within the pinned projects", func(t *testing.T) {
+		mockRepo.ExpectedCalls = nil
+		mockRepo.Calls = nil
+		mockRepo.On("ListPinnedProjectsPaginatedStream", ctx, userID, start, startID, false, int32(2)).Return(pinned, nil)
+
+		projects, err := collect(2)
+		assert.NoError(t, err)
+		assert.Equal(t, pinned, projects)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "ListProjectsPaginatedStream")
+	})
+
+	t.Run("page continuing with the unpinned projects", func(t *testing.T) {
+		mockRepo.ExpectedCalls = nil
+		mockRepo.On("ListPinnedProjectsPaginatedStream", ctx, userID, start, startID, false, int32(5)).Return(pinned, nil)
+		unpinnedStart, unpinnedStartID := coreTypes.StartCursor(coreTypes.SortOrderAsc)
+		mockRepo.On("ListProjectsPaginatedStream", ctx, userID, unpinnedStart, unpinnedStartID, false, int32(3), coreTypes.SortOrderAsc).Return(unpinned, nil)
+
+		projects, err := collect(5)
+		assert.NoError(t, err)
+		assert.Equal(t, append(append([]types.Project{}, pinned...), unpinned...), projects)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("error in the pinned projects ends the stream", func(t *testing.T) {
+		mockRepo.ExpectedCalls = nil
+		mockRepo.Calls = nil
+		mockRepo.On("ListPinnedProjectsPaginatedStream", ctx, userID, start, startID, false, int32(5)).Return(pinned[:1], errors.New("connection reset"))
+
+		projects, err := collect(5)
+		assert.EqualError(t, err, "connection reset")
+		assert.Equal(t, pinned[:1], projects)
+		mockRepo.AssertNotCalled(t, "ListProjectsPaginatedStream")
+	})
+}
+
+func TestWriteProjects(t *testing.T) {
+	defer func(size int32) { exportBatchSize = size }(exportBatchSize)
+	exportBatchSize = 2
+
+	mockRepo := new(mockProjectRepository)
+	ctx := context.Background()
+	userID := uuid.New()
+	now := time.Now().UTC()
+	project := func(name string, age time.Duration, pinned bool) types.Project {
+		p := types.Project{ProjectID: uuid.New(), Name: name, Status: "ongoing", CreatedAt: coreTypes.NewTimestamp(now.Add(-age))}
+		if pinned {
+			pinnedAt := now.Add(-age / 2)
+			p.Pinned, p.PinnedAt = true, coreTypes.TimestampPtr(&pinnedAt)
+		}
+		return p
+	}
+	pinned := []types.Project{project("Pinned 1", time.Hour, true), project("Pinned 2", 2*time.Hour, true)}
+	unpinned := []types.Project{project("Newest", time.Minute, false), project("Older", 3*time.Hour, false), project("Oldest", 4*time.Hour, false)}
+
+	// a full batch is followed by another from its last project, pinned or not
+	start, startID := coreTypes.StartPinnedCursor()
+	mockRepo.On("ListPinnedProjectsPaginatedStream", ctx, userID, start, startID, true, int32(2)).Return(pinned, nil).Once()
+	mockRepo.On("ListPinnedProjectsPaginatedStream", ctx, userID, pinned[1].PinnedAt.Time, pinned[1].ProjectID, true, int32(2)).Return([]types.Project{}, nil).Once()
+	unpinnedStart, unpinnedStartID := coreTypes.StartCursor(coreTypes.SortOrderDesc)
+	mockRepo.On("ListProjectsPaginatedStream", ctx, userID, unpinnedStart, unpinnedStartID, true, int32(2), coreTypes.SortOrderDesc).Return(unpinned[:2], nil).Once()
+	mockRepo.On("ListProjectsPaginatedStream", ctx, userID, unpinned[1].CreatedAt.Time, unpinned[1].ProjectID, true, int32(2), coreTypes.SortOrderDesc).Return(unpinned[2:], nil).Once()
+
+	var out strings.Builder
+	exported, err := WriteProjects(ctx, mockRepo, userID, types.ExportFormatCSV, &out)
+	require.NoError(t, err)
+	assert.Equal(t, 5, exported)
+	mockRepo.AssertExpectations(t)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 6)
+	assert.Equal(t, strings.Join(types.CSVHeader, ","), lines[0])
+	for i, name := range []string{"Pinned 1", "Pinned 2", "Newest", "Older", "Oldest"} {
+		assert.Contains(t, lines[i+1], ","+name+",")
+	}
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := WriteProjects(ctx, mockRepo, userID, "text/vcard", &out)
+		assert.EqualError(t, err, `unknown project export format "text/vcard"`)
+	})
+}
+
+func TestProjectService_PinProject(t *testing.T) {
+	mockRepo, service := setupTest(t)
+	ctx := context.Background()
+	userID := uuid.New()
+	projectID := uuid.New()
+	pinnedAt := time.Now()
+
+	tests := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "pins the project",
+			mock: func() {
+				mockRepo.On("GetProject", ctx, userID, projectID).Return(types.Project{ProjectID: projectID}, nil)
+				mockRepo.On("SetProjectPinned", ctx, userID, projectID, true).Return(types.Project{ProjectID: projectID, Pinned: true, PinnedAt: coreTypes.TimestampPtr(&pinnedAt)}, nil)
+			},
+		},
+		{
+			name: "already pinned",
+			mock: func() {
+				mockRepo.On("GetProject", ctx, userID, projectID).Return(types.Project{ProjectID: projectID, Pinned: true, PinnedAt: coreTypes.TimestampPtr(&pinnedAt)}, nil)
+			},
+		},
+		{
+			name: "cap reached",
+			mock: func() {
+				mockRepo.On("GetProject", ctx, userID, projectID).Return(types.Project{ProjectID: projectID}, nil)
+				mockRepo.On("SetProjectPinned", ctx, userID, projectID, true).Return(types.Project{}, repository.ErrPinnedProjectLimit)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.ExpectedCalls = nil
+			mockRepo.Calls = nil
+			tt.mock()
+
+			project, err := service.PinProject(ctx, userID, projectID)
+			if tt.wantErr {
+				assert.True(t, coreErrors.IsErrorType(err, coreErrors.ErrorTypeValidation))
+				mockRepo.AssertExpectations(t)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, project.Pinned)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProjectService_GetProjectSummary_Convert(t *testing.T) {
+	userID := uuid.New()
+	projectID := uuid.New()
+	summary := types.ProjectSummary{ProjectID: projectID, Projects: 1, Wallets: 3, Balances: []types.CurrencyBalance{
+		{Currency: "EUR", Wallets: 1, Balance: 80},
+		{Currency: "JPY", Wallets: 1, Balance: 12000},
+		{Currency: "USD", Wallets: 1, Balance: 100.5},
+	}}
+	rates, err := currency.NewStaticRates("USD", map[string]float64{"EUR": 1.1, "JPY": 0.0067})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name             string
+		rates            currency.Converter
+		convertTo        string
+		expected         *types.ConvertedBalance
+		expectedWarnings []string
+	}{
+		{
+			name:  "not requested",
+			rates: rates,
+		},
+		{
+			name:      "converted",
+			rates:     rates,
+			convertTo: "USD",
+			expected: &types.ConvertedBalance{
+				Currency: "USD",
+				Balance:  268.9, // 88 + 80.4 + 100.5
+				Rates:    map[string]float64{"EUR": 1.1, "JPY": 0.0067, "USD": 1},
+			},
+		},
+		{
+			name:             "rate unavailable",
+			rates:            rates,
+			convertTo:        "GBP",
+			expectedWarnings: []string{"convert_to: no exchange rate from EUR to GBP, the balances are not converted"},
+		},
+		{
+			name:             "conversion not configured",
+			convertTo:        "USD",
+			expectedWarnings: []string{"convert_to: currency conversion is not available"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mockProjectRepository)
+			service := NewProjectService(mockRepo, types.PublishRules{}, tt.rates, validate.RoundHalfUp, nil, nil, zap.NewNop())
+			ctx := requestcontext.WithWarnings(context.Background())
+			mockRepo.On("GetProjectSummary", ctx, userID, projectID, true).Return(summary, nil)
+
+			got, err := service.GetProjectSummary(ctx, userID, projectID, types.ProjectSummaryParams{Rollup: true, ConvertTo: tt.convertTo})
+			require.NoError(t, err)
+			assert.Equal(t, summary.Balances, got.Balances, "the breakdown is kept whether or not it converts")
+			if tt.expected == nil {
+				assert.Nil(t, got.Converted)
+			} else {
+				require.NotNil(t, got.Converted)
+				assert.Equal(t, tt.expected.Currency, got.Converted.Currency)
+				assert.Equal(t, tt.expected.Balance, got.Converted.Balance)
+				assert.Len(t, got.Converted.Rates, len(tt.expected.Rates))
+				for code, rate := range tt.expected.Rates {
+					assert.InDelta(t, rate, got.Converted.Rates[code], 1e-12, code)
+				}
+			}
+			assert.Equal(t, tt.expectedWarnings, requestcontext.GetWarningsFromContext(ctx))
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProjectService_CreateProject_Draft(t *testing.T) {
+	mockRepo := new(mockProjectRepository)
+	service := NewProjectService(mockRepo, types.PublishRules{RequireStartDate: true, RequireBudget: true}, nil, "", nil, nil, zap.NewNop())
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("a live project needs the required fields", func(t *testing.T) {
+		_, err := service.CreateProject(ctx, userID, types.ProjectCreatePayload{Name: "Office Fit-out", Status: "ongoing"})
+		assert.True(t, coreErrors.IsErrorType(err, coreErrors.ErrorTypeValidation))
+		assert.ErrorContains(t, err, "start_date, budget")
+		mockRepo.AssertNotCalled(t, "CreateProject", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("a draft goes without them", func(t *testing.T) {
+		payload := types.ProjectCreatePayload{Name: "Office Fit-out", Status: "ongoing", Draft: true}
+		mockRepo.On("CreateProject", ctx, userID, payload).Return(types.Project{ProjectID: uuid.New(), Name: payload.Name, Draft: true}, nil)
+
+		project, err := service.CreateProject(ctx, userID, payload)
+		assert.NoError(t, err)
+		assert.True(t, project.Draft)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestProjectService_PublishProject(t *testing.T) {
+	mockRepo := new(mockProjectRepository)
+	rules := types.PublishRules{RequireStartDate: true, RequireBudget: true}
+	service := NewProjectService(mockRepo, rules, nil, "", nil, nil, zap.NewNop())
+	ctx := context.Background()
+	userID := uuid.New()
+	projectID := uuid.New()
+	startDate, budget := time.Now(), 500.0
+	live := types.Project{ProjectID: projectID, Status: "ongoing", StartDate: coreTypes.DatePtr(&startDate), Budget: &budget}
+	missing := fmt.Errorf("publish project %s: %w", projectID, coreRepository.ErrNotFound)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		wantErr func(error) bool
+		errMsg  string
+	}{
+		{
+			name: "publishes the draft",
+			mock: func() {
+				mockRepo.On("PublishProject", ctx, userID, projectID, rules).Return(live, nil)
+			},
+		},
+		{
+			name: "missing required fields",
+			mock: func() {
+				mockRepo.On("PublishProject", ctx, userID, projectID, rules).Return(types.Project{}, missing)
+				mockRepo.On("GetProject", ctx, userID, projectID).Return(types.Project{ProjectID: projectID, Status: "ongoing", Draft: true}, nil)
+			},
+			wantErr: func(err error) bool { return coreErrors.IsErrorType(err, coreErrors.ErrorTypeValidation) },
+			errMsg:  "the project needs start_date, budget before it can be published",
+		},
+		{
+			name: "project not found",
+			mock: func() {
+				mockRepo.On("PublishProject", ctx, userID, projectID, rules).Return(types.Project{}, missing)
+				mockRepo.On("GetProject", ctx, userID, projectID).Return(types.Project{}, coreErrors.NewNotFoundError("project not found"))
+			},
+			wantErr: func(err error) bool { return coreErrors.IsErrorType(err, coreErrors.ErrorTypeNotFound) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.ExpectedCalls = nil
+			mockRepo.Calls = nil
+			tt.mock()
+
+			project, err := service.PublishProject(ctx, userID, projectID)
+			if tt.wantErr != nil {
+				assert.True(t, tt.wantErr(err), "unexpected error %v", err)
+				if tt.errMsg != "" {
+					assert.ErrorContains(t, err, tt.errMsg)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.False(t, project.Draft)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+
+	t.Run("publishing twice returns the same project", func(t *testing.T) {
+		mockRepo.ExpectedCalls = nil
+		mockRepo.Calls = nil
+		mockRepo.On("PublishProject", ctx, userID, projectID, rules).Return(live, nil).Twice()
+
+		first, err := service.PublishProject(ctx, userID, projectID)
+		require.NoError(t, err)
+		second, err := service.PublishProject(ctx, userID, projectID)
+		require.NoError(t, err)
+		assert.Equal(t, first, second)
+		mockRepo.AssertNotCalled(t, "GetProject", ctx, userID, projectID)
+	})
+}
+
+func TestProjectService_CreateMilestone(t *testing.T) {
+	mockRepo, service := setupTest(t)
+	ctx := context.Background()
+	userID := uuid.New()
+	projectID := uuid.New()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	project := types.Project{ProjectID: projectID, StartDate: coreTypes.DatePtr(&start), EndDate: coreTypes.DatePtr(&end)}
+
+	tests := []struct {
+		name    string
+		payload types.MilestoneCreatePayload
+		mock    func()
+		wantErr bool
+		errMsg  string
+	}{
+		{
+			name:    "successful creation",
+			payload: types.MilestoneCreatePayload{Name: "Kickoff", DueDate: utils.TimePtr(start.AddDate(0, 1, 0))},
+			mock: func() {
+				mockRepo.On("GetProject", ctx, userID, projectID).Return(project, nil)
+				mockRepo.On("CreateMilestone", ctx, userID, projectID, mock.AnythingOfType("types.MilestoneCreatePayload")).
+					Return(types.Milestone{ProjectID: projectID, Name: "Kickoff", SortOrder: 3}, nil)
+			},
+		},
+		{
+			name:    "due before project start",
+			payload: types.MilestoneCreatePayload{Name: "Kickoff", DueDate: utils.TimePtr(start.AddDate(0, 0, -1))},
+			mock: func() {
+				mockRepo.On("GetProject", ctx, userID, projectID).Return(project, nil)
+			},
+			wantErr: true,
+			errMsg:  "due date cannot be before the project start date",
+		},
+		{
+			name:    "due later on the project end date",
+			payload: types.MilestoneCreatePayload{Name: "Kickoff", DueDate: utils.TimePtr(end.Add(18 * time.Hour))},
+			mock: func() {
+				mockRepo.On("GetProject", ctx, userID, projectID).Return(project, nil)
+				mockRepo.On("CreateMilestone", ctx, userID, projectID, mock.AnythingOfType("types.MilestoneCreatePayload")).
+					Return(types.Milestone{ProjectID: projectID, Name: "Kickoff", SortOrder: 3}, nil)
+			},
+		},
+		{
+			name:    "due after project end",
+			payload: types.MilestoneCreatePayload{Name: "Kickoff", DueDate: utils.TimePtr(end.AddDate(0, 0, 1))},
+			mock: func() {
+				mockRepo.On("GetProject", ctx, userID, projectID).Return(project, nil)
+			},
+			wantErr: true,
+			errMsg:  "due date cannot be after the project end date",
+		},
+		{
+			name:    "limit reached",
+			payload: types.MilestoneCreatePayload{Name: "Kickoff"},
+			mock: func() {
+				mockRepo.On("GetProject", ctx, userID, projectID).Return(project, nil)
+				mockRepo.On("CreateMilestone", ctx, userID, projectID, mock.AnythingOfType("types.MilestoneCreatePayload")).
+					Return(types.Milestone{}, repository.ErrMilestoneLimit)
+			},
+			wantErr: true,
+			errMsg:  "at most 100 milestones",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.ExpectedCalls = nil
+			tt.mock()
+
+			milestone, err := service.CreateMilestone(ctx, userID, projectID, tt.payload)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+				assert.True(t, coreErrors.IsErrorType(err, coreErrors.ErrorTypeValidation))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "Kickoff", milestone.Name)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProjectService_ReorderMilestones(t *testing.T) {
+	mockRepo, service := setupTest(t)
+	ctx := context.Background()
+	userID := uuid.New()
+	projectID := uuid.New()
+	first, second := uuid.New(), uuid.New()
+	milestones := []types.Milestone{{MilestoneID: first}, {MilestoneID: second}}
+
+	tests := []struct {
+		name    string
+		order   []uuid.UUID
+		mock    func()
+		wantErr bool
+		errMsg  string
+	}{
+		{
+			name:  "successful reorder",
+			order: []uuid.UUID{second, first},
+			mock: func() {
+				mockRepo.On("ReorderMilestones", ctx, userID, projectID, []uuid.UUID{second, first}).Return(nil)
+			},
+		},
+		{
+			name:    "missing milestone",
+			order:   []uuid.UUID{second},
+			mock:    func() {},
+			wantErr: true,
+			errMsg:  "order must list all 2 milestones",
+		},
+		{
+			name:    "foreign milestone",
+			order:   []uuid.UUID{second, uuid.New()},
+			mock:    func() {},
+			wantErr: true,
+			errMsg:  "does not belong to the project",
+		},
+		{
+			name:    "duplicate milestone",
+			order:   []uuid.UUID{second, second},
+			mock:    func() {},
+			wantErr: true,
+			errMsg:  "listed more than once",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.ExpectedCalls = nil
+			mockRepo.Calls = nil
+			mockRepo.On("GetProject", ctx, userID, projectID).Return(types.Project{ProjectID: projectID}, nil)
+			mockRepo.On("ListMilestones", ctx, userID, projectID).Return(milestones, nil)
+			tt.mock()
+
+			_, err := service.ReorderMilestones(ctx, userID, projectID, tt.order)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+				mockRepo.AssertNotCalled(t, "ReorderMilestones", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProjectService_Logging(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	projectID := uuid.New()
+	milestoneID := uuid.New()
+
+	tests := []struct {
+		name       string
+		err        error
+		level      zapcore.Level
+		call       func(ProjectService) error
+		mock       func(*mockProjectRepository, error)
+		entityType string
+		entityID   uuid.UUID
+		operation  string
+	}{
+		{
+			name:  "project success",
+			level: zapcore.DebugLevel,
+			call: func(s ProjectService) error {
+				_, err := s.GetProject(ctx, userID, projectID, types.ProjectExpand{})
+				return err
+			},
+			mock: func(m *mockProjectRepository, err error) {
+				m.On("GetProject", ctx, userID, projectID).Return(types.Project{ProjectID: projectID}, err)
+			},
+			entityType: "project",
+			entityID:   projectID,
+			operation:  "ProjectService.GetProject",
+		},
+		{
+			name:  "project failure",
+			err:   errors.New("database error"),
+			level: zapcore.ErrorLevel,
+			call: func(s ProjectService) error {
+				_, err := s.GetProject(ctx, userID, projectID, types.ProjectExpand{})
+				return err
+			},
+			mock: func(m *mockProjectRepository, err error) {
+				m.On("GetProject", ctx, userID, projectID).Return(types.Project{}, err)
+			},
+			entityType: "project",
+			entityID:   projectID,
+			operation:  "ProjectService.GetProject",
+		},
+		{
+			name:  "milestone success",
+			level: zapcore.DebugLevel,
+			call: func(s ProjectService) error {
+				_, err := s.GetMilestone(ctx, userID, projectID, milestoneID)
+				return err
+			},
+			mock: func(m *mockProjectRepository, err error) {
+				m.On("GetMilestone", ctx, userID, projectID, milestoneID).Return(types.Milestone{MilestoneID: milestoneID}, err)
+			},
+			entityType: "milestone",
+			entityID:   milestoneID,
+			operation:  "ProjectService.GetMilestone",
+		},
+		{
+			name:  "milestone failure",
+			err:   errors.New("database error"),
+			level: zapcore.ErrorLevel,
+			call: func(s ProjectService) error {
+				_, err := s.GetMilestone(ctx, userID, projectID, milestoneID)
+				return err
+			},
+			mock: func(m *mockProjectRepository, err error) {
+				m.On("GetMilestone", ctx, userID, projectID, milestoneID).Return(types.Milestone{}, err)
+			},
+			entityType: "milestone",
+			entityID:   milestoneID,
+			operation:  "ProjectService.GetMilestone",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.DebugLevel)
+			mockRepo := new(mockProjectRepository)
+			service := NewProjectService(mockRepo, types.PublishRules{}, nil, "", nil, nil, zap.New(core))
+			tt.mock(mockRepo, tt.err)
+
+			assert.Equal(t, tt.err, tt.call(service))
+
+			require.Equal(t, 1, logs.Len())
+			entry := logs.All()[0]
+			assert.Equal(t, tt.level, entry.Level)
+
+			fields := entry.ContextMap()
+			assert.Equal(t, userID.String(), fields[logging.FieldUserID])
+			assert.Equal(t, tt.entityType, fields[logging.FieldEntityType])
+			assert.Equal(t, tt.entityID.String(), fields[logging.FieldEntityID])
+			assert.Equal(t, tt.operation, fields[logging.FieldOperation])
+			assert.Contains(t, fields, logging.FieldDuration)
+			if tt.err != nil {
+				assert.Equal(t, logging.EventFailed, entry.Message)
+				assert.Equal(t, tt.err.Error(), fields["error"])
+			} else {
+				assert.Equal(t, logging.EventSucceeded, entry.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/deletion"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/tracing"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/projects/types"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedProjectService records a span around each call of the wrapped service
+type tracedProjectService struct {
+	next   ProjectService
+	tracer trace.Tracer
+}
+
+// NewTracedProjectService wraps next so each call records a span named after the method, a nil
+// tracer returns next as is
+func NewTracedProjectService(next ProjectService, tracer trace.Tracer) ProjectService {
+	if tracer == nil {
+		return next
+	}
+	return &tracedProjectService{next: next, tracer: tracer}
+}
+
+func (t *tracedProjectService) ListProjects(ctx context.Context, userID uuid.UUID) ([]types.Project, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectService.ListProjects")
+	projects, err := t.next.ListProjects(ctx, userID)
+	tracing.End(span, err)
+	return projects, err
+}
+
+func (t *tracedProjectService) GetProject(ctx context.Context, userID, projectID uuid.UUID, expand types.ProjectExpand) (types.Project, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectService.GetProject")
+	project, err := t.next.GetProject(ctx, userID, projectID, expand)
+	tracing.End(span, err)
+	return project, err
+}
+
+func (t *tracedProjectService) GetProjectsByIDs(ctx context.Context, userID uuid.UUID, projectIDs []uuid.UUID) ([]types.Project, []uuid.UUID, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectService.GetProjectsByIDs")
+	projects, missing, err := t.next.GetProjectsByIDs(ctx, userID, projectIDs)
+	tracing.End(span, err)
+	return projects, missing, err
+}
+
+func (t *tracedProjectService) ListChildProjects(ctx context.Context, userID, projectID uuid.UUID) ([]types.Project, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectService.ListChildProjects")
+	projects, err := t.next.ListChildProjects(ctx, userID, projectID)
+	tracing.End(span, err)
+	return projects, err
+}
+
+func (t *tracedProjectService) GetProjectSummary(ctx context.Context, userID, projectID uuid.UUID, params types.ProjectSummaryParams) (types.ProjectSummary, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectService.GetProjectSummary")
+	summary, err := t.next.GetProjectSummary(ctx, userID, projectID, params)
+	tracing.End(span, err)
+	return summary, err
+}
+
+func (t *tracedProjectService) CreateProject(ctx context.Context, userID uuid.UUID, projectData types.ProjectCreatePayload) (types.Project, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectService.CreateProject")
+	project, err := t.next.CreateProject(ctx, userID, projectData)
+	tracing.End(span, err)
+	return project, err
+}
+
+func (t *tracedProjectService) CreateProjectIfNotExists(ctx context.Context, userID uuid.UUID, projectData types.ProjectCreatePayload) (types.Project, bool, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectService.CreateProjectIfNotExists")
+	project, created, err := t.next.CreateProjectIfNotExists(ctx, userID, projectData)
+	tracing.End(span, err)
+	return project, created, err
+}
+
+func (t *tracedProjectService) UpdateProject(ctx context.Context, userID, projectID uuid.UUID, merge func(types.Project) (types.ProjectUpdatePayload, error)) (types.Project, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectService.UpdateProject")
+	project, err := t.next.UpdateProject(ctx, userID, projectID, merge)
+	tracing.End(span, err)
+	return project, err
+}
+
+func (t *tracedProjectService) DeleteProject(ctx context.Context, userID, projectID uuid.UUID, children types.ChildrenMode, version *time.Time) error {
+	ctx, span := t.tracer.Start(ctx, "ProjectService.DeleteProject")
+	err := t.next.DeleteProject(ctx, userID, projectID, children, version)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *tracedProjectService) DeletionImpact(ctx context.Context, userID, projectID uuid.UUID, children types.ChildrenMode) (deletion.Impact, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectService.DeletionImpact")
+	impact, err := t.next.DeletionImpact(ctx, userID, projectID, children)
+	tracing.End(span, err)
+	return impact, err
+}
+
+func (t *tracedProjectService) ListDeletedProjectsPaginated(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, limit int32, order coreTypes.SortOrder) ([]types.Project, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectService.ListDeletedProjectsPaginated")
+	projects, err := t.next.ListDeletedProjectsPaginated(ctx, userID, cursor, cursorID, limit, order)
+	tracing.End(span, err)
+	return projects, err
+}
+
+func (t *tracedProjectService) RestoreProject(ctx context.Context, userID, projectID uuid.UUID) (types.Project, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectService.RestoreProject")
+	project, err := t.next.RestoreProject(ctx, userID, projectID)
+	tracing.End(span, err)
+	return project, err
+}
+
+func (t *tracedProjectService) CloneProject(ctx context.Context, userID, projectID uuid.UUID, includeWallets bool) (types.Project, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectService.CloneProject")
+	project, err := t.next.CloneProject(ctx, userID, projectID, includeWallets)
+	tracing.End(span, err)
+	return project, err
+}
+
+func (t *tracedProjectService) PublishProject(ctx context.Context, userID, projectID uuid.UUID) (types.Project, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectService.PublishProject")
+	project, err := t.next.PublishProject(ctx, userID, projectID)
+	tracing.End(span, err)
+	return project, err
+}
+
+func (t *tracedProjectService) GetProjectWallets(ctx context.Context, userID, projectID uuid.UUID) ([]db.Wallet, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectService.GetProjectWallets")
+	wallets, err := t.next.GetProjectWallets(ctx, userID, projectID)
+	tracing.End(span, err)
+	return wallets, err
+}
+
+func (t *tracedProjectService) StreamProjectsPaginated(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, pinned, includeDrafts bool, limit int32, order coreTypes.SortOrder, fn func(types.Project) error) error {
+	ctx, span := t.tracer.Start(ctx, "ProjectService.StreamProjectsPaginated")
+	err := t.next.StreamProjectsPaginated(ctx, userID, cursor, cursorID, pinned, includeDrafts, limit, order, fn)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *tracedProjectService) ListProjectsPaginated(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, pinned, includeDrafts bool, limit int32, order coreTypes.SortOrder) ([]types.Project, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectService.ListProjectsPaginated")
+	projects, err := t.next.ListProjectsPaginated(ctx, userID, cursor, cursorID, pinned, includeDrafts, limit, order)
+	tracing.End(span, err)
+	return projects, err
+}
+
+func (t *tracedProjectService) ListProjectIDsPaginated(ctx context.Context, userID uuid.UUID, cursor time.Time, cursorID uuid.UUID, pinned, includeDrafts bool, limit int32, order coreTypes.SortOrder) ([]coreTypes.KeyedID, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectService.ListProjectIDsPaginated")
+	keys, err := t.next.ListProjectIDsPaginated(ctx, userID, cursor, cursorID, pinned, includeDrafts, limit, order)
+	tracing.End(span, err)
+	return keys, err
+}
+
+func (t *tracedProjectService) PinProject(ctx context.Context, userID, projectID uuid.UUID) (types.Project, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectService.PinProject")
+	project, err := t.next.PinProject(ctx, userID, projectID)
+	tracing.End(span, err)
+	return project, err
+}
+
+func (t *tracedProjectService) UnpinProject(ctx context.Context, userID, projectID uuid.UUID) (types.Project, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectService.UnpinProject")
+	project, err := t.next.UnpinProject(ctx, userID, projectID)
+	tracing.End(span, err)
+	return project, err
+}
+
+func (t *tracedProjectService) SearchProjects(ctx context.Context, userID uuid.UUID, query string, includeDrafts bool, limit, offset int32) ([]types.Project, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectService.SearchProjects")
+	projects, err := t.next.SearchProjects(ctx, userID, query, includeDrafts, limit, offset)
+	tracing.End(span, err)
+	return projects, err
+}
+
+func (t *tracedProjectService) SearchProjectsPicker(ctx context.Context, userID uuid.UUID, query string, includeDrafts bool, limit, offset int32) ([]types.ProjectPickerItem, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectService.SearchProjectsPicker")
+	items, err := t.next.SearchProjectsPicker(ctx, userID, query, includeDrafts, limit, offset)
+	tracing.End(span, err)
+	return items, err
+}
+
+func (t *tracedProjectService) ListMilestones(ctx context.Context, userID, projectID uuid.UUID) ([]types.Milestone, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectService.ListMilestones")
+	milestones, err := t.next.ListMilestones(ctx, userID, projectID)
+	tracing.End(span, err)
+	return milestones, err
+}
+
+func (t *tracedProjectService) GetMilestone(ctx context.Context, userID, projectID, milestoneID uuid.UUID) (types.Milestone, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectService.GetMilestone")
+	milestone, err := t.next.GetMilestone(ctx, userID, projectID, milestoneID)
+	tracing.End(span, err)
+	return milestone, err
+}
+
+func (t *tracedProjectService) CreateMilestone(ctx context.Context, userID, projectID uuid.UUID, milestoneData types.MilestoneCreatePayload) (types.Milestone, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectService.CreateMilestone")
+	milestone, err := t.next.CreateMilestone(ctx, userID, projectID, milestoneData)
+	tracing.End(span, err)
+	return milestone, err
+}
+
+func (t *tracedProjectService) UpdateMilestone(ctx context.Context, userID uuid.UUID, milestoneData types.MilestoneUpdatePayload) (types.Milestone, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectService.UpdateMilestone")
+	milestone, err := t.next.UpdateMilestone(ctx, userID, milestoneData)
+	tracing.End(span, err)
+	return milestone, err
+}
+
+func (t *tracedProjectService) DeleteMilestone(ctx context.Context, userID, projectID, milestoneID uuid.UUID) error {
+	ctx, span := t.tracer.Start(ctx, "ProjectService.DeleteMilestone")
+	err := t.next.DeleteMilestone(ctx, userID, projectID, milestoneID)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *tracedProjectService) ReorderMilestones(ctx context.Context, userID, projectID uuid.UUID, milestoneIDs []uuid.UUID) ([]types.Milestone, error) {
+	ctx, span := t.tracer.Start(ctx, "ProjectService.ReorderMilestones")
+	milestones, err := t.next.ReorderMilestones(ctx, userID, projectID, milestoneIDs)
+	tracing.End(span, err)
+	return milestones, err
+}
@@ -0,0 +1,90 @@
+package types
+
+import (
+	"strconv"
+	"strings"
+
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
+)
+
+// Media types projects are exported in
+const (
+	ExportFormatCSV  = "text/csv"
+	ExportFormatJSON = "application/json"
+)
+
+// ExportExtensions maps the media types of project exports to the extension of their files
+var ExportExtensions = map[string]string{
+	ExportFormatCSV:  "csv",
+	ExportFormatJSON: "json",
+}
+
+// CSVHeader is the header row of a projects CSV export, in the column order of CSVRecord
+var CSVHeader = []string{
+	"project_id",
+	"name",
+	"description",
+	"status",
+	"draft",
+	"start_date",
+	"end_date",
+	"budget",
+	"parent_project_id",
+	"address_line1",
+	"address_line2",
+	"city",
+	"state_province",
+	"zip_postal_code",
+	"country",
+	"website",
+	"tags",
+	"created_at",
+	"updated_at",
+}
+
+// CSVRecord returns the project as a CSV row, the budget has two decimals, empty optional
+// fields become empty cells and tag IDs are joined with semicolons
+func (p Project) CSVRecord() []string {
+	date := func(value *coreTypes.Date) string {
+		if value == nil {
+			return ""
+		}
+		return value.String()
+	}
+	budget := ""
+	if p.Budget != nil {
+		budget = strconv.FormatFloat(*p.Budget, 'f', 2, 64)
+	}
+	parent := ""
+	if p.ParentProjectID != nil {
+		parent = p.ParentProjectID.String()
+	}
+
+	tags := make([]string, len(p.Tags))
+	for i, tag := range p.Tags {
+		tags[i] = tag.String()
+	}
+
+	return []string{
+		p.ProjectID.String(),
+		p.Name,
+		utils.StringPtrToString(p.Description),
+		p.Status,
+		strconv.FormatBool(p.Draft),
+		date(p.StartDate),
+		date(p.EndDate),
+		budget,
+		parent,
+		utils.StringPtrToString(p.AddressLine1),
+		utils.StringPtrToString(p.AddressLine2),
+		utils.StringPtrToString(p.City),
+		utils.StringPtrToString(p.StateProvince),
+		utils.StringPtrToString(p.ZipPostalCode),
+		utils.StringPtrToString(p.Country),
+		utils.StringPtrToString(p.Website),
+		strings.Join(tags, ";"),
+		p.CreatedAt.String(),
+		p.UpdatedAt.String(),
+	}
+}
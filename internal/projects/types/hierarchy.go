@@ -0,0 +1,126 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/validate"
+	"github.com/google/uuid"
+)
+
+// MaxProjectDepth caps how many levels a project tree has, a top-level project counting as one
+const MaxProjectDepth = 3
+
+// ProjectParent is what a project shows of its parent with expand=parent
+// @Description Parent of a sub-project
+type ProjectParent struct {
+	ProjectID uuid.UUID `json:"projectId" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
+	Name      string    `json:"name" example:"Website Redesign"`
+	Status    string    `json:"status" example:"ongoing" enums:"ongoing,completed,canceled"`
+}
+
+// ProjectExpand lists the related resources to include in a project response
+type ProjectExpand struct {
+	Parent bool
+}
+
+// ParseProjectExpand parses the comma separated expand query parameter, parent is the
+// only resource that expands
+func ParseProjectExpand(query url.Values) (ProjectExpand, error) {
+	var expand ProjectExpand
+	for _, name := range strings.Split(query.Get("expand"), ",") {
+		switch strings.TrimSpace(name) {
+		case "":
+		case "parent":
+			expand.Parent = true
+		default:
+			return expand, fmt.Errorf("expand: %q can't be expanded, expected parent", name)
+		}
+	}
+	return expand, nil
+}
+
+// ChildrenMode is what deleting a project does with its sub-projects
+type ChildrenMode string
+
+const (
+	// ChildrenRestrict refuses to delete a project with sub-projects
+	ChildrenRestrict ChildrenMode = ""
+	// ChildrenCascade trashes the sub-projects along with the project
+	ChildrenCascade ChildrenMode = "cascade"
+	// ChildrenDetach moves the sub-projects to the top level
+	ChildrenDetach ChildrenMode = "detach"
+)
+
+// ParseChildrenMode reads the cascade and detach query parameters of a delete
+func ParseChildrenMode(query url.Values) (ChildrenMode, error) {
+	cascade, detach := query.Get("cascade") == "true", query.Get("detach") == "true"
+	switch {
+	case cascade && detach:
+		return ChildrenRestrict, fmt.Errorf("cascade and detach can't be combined")
+	case cascade:
+		return ChildrenCascade, nil
+	case detach:
+		return ChildrenDetach, nil
+	}
+	return ChildrenRestrict, nil
+}
+
+// ProjectSummary totals the budget and wallet balances of a project
+// @Description Budget and wallet balances of a project, with rollup those of its sub-projects at any depth too
+type ProjectSummary struct {
+	ProjectID uuid.UUID `json:"projectId" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
+	Rollup    bool      `json:"rollup" example:"true"`
+	// Projects is how many projects the totals cover, the project and with rollup its sub-projects
+	Projects int64             `json:"projects" example:"3"`
+	Budget   float64           `json:"budget" example:"25000"`
+	Wallets  int64             `json:"wallets" example:"4"`
+	Balances []CurrencyBalance `json:"balances"`
+	// Converted is set with convert_to, unless a currency has no exchange rate
+	Converted *ConvertedBalance `json:"converted,omitempty"`
+}
+
+// CurrencyBalance is the balance of the wallets in one currency
+// @Description Total balance of the wallets in a currency
+type CurrencyBalance struct {
+	Currency string  `json:"currency" example:"USD" format:"iso-4217"`
+	Wallets  int64   `json:"wallets" example:"2"`
+	Balance  float64 `json:"balance" example:"1250.75"`
+}
+
+// ConvertedBalance is the balances of a project converted to a single currency, with
+// the rate each currency was converted at
+// @Description Wallet balances added up in a single currency
+type ConvertedBalance struct {
+	Currency string             `json:"currency" example:"USD" format:"iso-4217"`
+	Balance  float64            `json:"balance" example:"1450.58"`
+	Rates    map[string]float64 `json:"rates"`
+}
+
+// ProjectSummaryQueryParams are the query parameters of the project summary
+var ProjectSummaryQueryParams = []string{"rollup", "convert_to"}
+
+// ProjectSummaryParams choose which projects the summary covers and the currency its
+// balances are converted to
+type ProjectSummaryParams struct {
+	// Rollup adds the sub-projects at any depth
+	Rollup bool
+	// ConvertTo is the currency the balances are converted to and added up in, empty leaves them apart
+	ConvertTo string
+}
+
+// ParseProjectSummaryParams parses the rollup flag and the convert_to currency of the project summary
+func ParseProjectSummaryParams(query url.Values) (ProjectSummaryParams, error) {
+	params := ProjectSummaryParams{Rollup: query.Get("rollup") == "true"}
+
+	if value := strings.ToUpper(strings.TrimSpace(query.Get("convert_to"))); value != "" {
+		if !slices.Contains(validate.CurrencyCodes(), value) {
+			return params, fmt.Errorf("convert_to: must be a valid currency code")
+		}
+		params.ConvertTo = value
+	}
+
+	return params, nil
+}
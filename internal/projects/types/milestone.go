@@ -0,0 +1,83 @@
+package types
+
+import (
+	"net/http"
+	"time"
+
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+)
+
+const (
+	MaxMilestones          = 100
+	MaxMilestoneNameLength = 255
+)
+
+// Milestone represents a checkpoint within a project
+// @Description Project milestone with its due date, completion time and position
+type Milestone struct {
+	MilestoneID uuid.UUID            `json:"milestoneId" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
+	ProjectID   uuid.UUID            `json:"projectId" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
+	Name        string               `json:"name" example:"Foundations poured" minLength:"1" maxLength:"255"`
+	DueDate     *coreTypes.Timestamp `json:"dueDate,omitempty" example:"2024-03-01T00:00:00.000Z" swaggertype:"string" format:"date-time"`
+	CompletedAt *coreTypes.Timestamp `json:"completedAt,omitempty" example:"2024-02-27T00:00:00.000Z" swaggertype:"string" format:"date-time"`
+	SortOrder   int32                `json:"sortOrder" example:"0" minimum:"0"`
+	CreatedAt   coreTypes.Timestamp  `json:"createdAt" example:"2024-01-01T00:00:00.000Z" swaggertype:"string" format:"date-time"`
+	UpdatedAt   coreTypes.Timestamp  `json:"updatedAt" example:"2024-01-01T00:00:00.000Z" swaggertype:"string" format:"date-time"`
+}
+
+// MilestoneCreatePayload represents the payload for adding a milestone to a project
+// @Description Payload for adding a milestone to a project, it is placed after the existing ones
+type MilestoneCreatePayload struct {
+	Name      string     `json:"name" example:"Foundations poured" minLength:"1" maxLength:"255" validate:"required"`
+	DueDate   *time.Time `json:"dueDate" extensions:"x-nullable" example:"2024-03-01T00:00:00Z" format:"date-time"`
+	Completed bool       `json:"completed" example:"false"`
+}
+
+// Bind implements render.Binder interface
+func (c *MilestoneCreatePayload) Bind(r *http.Request) error {
+	return validation.Errors{
+		"name": validation.Validate(c.Name, validation.Required, validation.Length(1, MaxMilestoneNameLength)),
+	}.Filter()
+}
+
+// MilestoneUpdatePayload represents the payload for updating a milestone
+// @Description Payload for updating a milestone, completed toggles its completion
+type MilestoneUpdatePayload struct {
+	MilestoneID uuid.UUID  `json:"-" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
+	ProjectID   uuid.UUID  `json:"-" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
+	Name        string     `json:"name" example:"Foundations poured" minLength:"1" maxLength:"255"`
+	DueDate     *time.Time `json:"dueDate" extensions:"x-nullable" example:"2024-03-01T00:00:00Z" format:"date-time"`
+	Completed   bool       `json:"completed" example:"true"`
+}
+
+// Bind implements render.Binder interface
+func (u *MilestoneUpdatePayload) Bind(r *http.Request) error {
+	return validation.Errors{
+		"name": validation.Validate(u.Name, validation.Required, validation.Length(1, MaxMilestoneNameLength)),
+	}.Filter()
+}
+
+func (m *Milestone) ToUpdatePayload() MilestoneUpdatePayload {
+	return MilestoneUpdatePayload{
+		MilestoneID: m.MilestoneID,
+		ProjectID:   m.ProjectID,
+		Name:        m.Name,               // Non-optional
+		DueDate:     m.DueDate.TimePtr(),  // Optional
+		Completed:   m.CompletedAt != nil, // Non-optional
+	}
+}
+
+// MilestoneOrderPayload represents the payload for reordering a project's milestones
+// @Description Every milestone ID of the project, in the desired order
+type MilestoneOrderPayload struct {
+	MilestoneIDs []uuid.UUID `json:"milestoneIds" example:"123e4567-e89b-12d3-a456-426614174001,123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
+}
+
+// Bind implements render.Binder interface
+func (o *MilestoneOrderPayload) Bind(r *http.Request) error {
+	return validation.Errors{
+		"milestoneIds": validation.Validate(o.MilestoneIDs, validation.Required, validation.Length(1, MaxMilestones)),
+	}.Filter()
+}
@@ -2,8 +2,9 @@ package types
 
 import (
 	"net/http"
-	"time"
 
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/jsoncase"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/validate"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
@@ -11,52 +12,103 @@ import (
 	"github.com/google/uuid"
 )
 
+// ListQueryParams lists the query parameters accepted when listing projects
+var ListQueryParams = append([]string{"include_drafts"}, coreTypes.PaginationQueryParams...)
+
+// SearchQueryParams lists the query parameters accepted when searching projects
+var SearchQueryParams = append([]string{"include_drafts", coreTypes.ViewParam, coreTypes.TrimParam}, coreTypes.SearchQueryParams...)
+
+// PublishRules are the fields a live project needs on top of the payload rules, drafts
+// only need them once they are published
+type PublishRules struct {
+	RequireStartDate bool
+	RequireBudget    bool
+}
+
+// Missing lists the fields the rules require that the project leaves empty
+func (r PublishRules) Missing(status string, startDate *coreTypes.Date, budget *float64) []string {
+	var missing []string
+	if status == "" {
+		missing = append(missing, "status")
+	}
+	if r.RequireStartDate && startDate == nil {
+		missing = append(missing, "start_date")
+	}
+	if r.RequireBudget && budget == nil {
+		missing = append(missing, "budget")
+	}
+	return missing
+}
+
 const (
 	MaxDescriptionLength = 1000
 	MaxNameLength        = 255
 	MaxAddressLength     = 255
 	MaxTagsCount         = 10
+	// MaxPinnedProjects caps the projects a user can pin
+	MaxPinnedProjects = 10
 )
 
 // Project represents a project entity
 // @Description Project information including details, status, dates, location and tags
 type Project struct {
-	ProjectID     uuid.UUID   `json:"projectId" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
-	Name          string      `json:"name" example:"My Project" minLength:"1" maxLength:"255"`
-	Description   *string     `json:"description,omitempty" example:"Detailed project description" maxLength:"1000"`
-	Status        string      `json:"status" example:"ongoing" enums:"ongoing,completed,canceled"`
-	StartDate     *time.Time  `json:"startDate,omitempty" example:"2024-01-01T00:00:00Z" format:"date-time"`
-	EndDate       *time.Time  `json:"endDate,omitempty" example:"2024-12-31T00:00:00Z" format:"date-time"`
-	Budget        *float64    `json:"budget,omitempty" example:"10000.50" minimum:"0"`
-	AddressLine1  *string     `json:"addressLine1,omitempty" example:"123 Main St" maxLength:"255"`
-	AddressLine2  *string     `json:"addressLine2,omitempty" example:"Suite 100" maxLength:"255"`
-	Country       *string     `json:"country,omitempty" example:"US" format:"iso-3166-1-alpha-2" pattern:"^[A-Z]{2}$"`
-	City          *string     `json:"city,omitempty" example:"New York" maxLength:"255"`
-	StateProvince *string     `json:"stateProvince,omitempty" example:"NY" maxLength:"255"`
-	ZipPostalCode *string     `json:"zipPostalCode,omitempty" example:"10001" format:"zip-code" pattern:"^\\d{5}(?:[-\\s]\\d{4})?$"`
-	Website       *string     `json:"website,omitempty" example:"https://example.com" format:"uri"`
-	Tags          []uuid.UUID `json:"tags,omitempty" example:"123e4567-e89b-12d3-a456-426614174000,123e4567-e89b-12d3-a456-426614174001" format:"uuid" validate:"unique,max=10"`
-	CreatedAt     time.Time   `json:"createdAt" example:"2024-01-01T00:00:00Z" format:"date-time"`
-	UpdatedAt     time.Time   `json:"updatedAt" example:"2024-01-01T00:00:00Z" format:"date-time"`
+	ProjectID       uuid.UUID            `json:"projectId" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
+	Name            string               `json:"name" example:"My Project" minLength:"1" maxLength:"255"`
+	Description     *string              `json:"description,omitempty" example:"Detailed project description" maxLength:"1000"`
+	Status          string               `json:"status" example:"ongoing" enums:"ongoing,completed,canceled"`
+	StartDate       *coreTypes.Date      `json:"startDate,omitempty" example:"2024-01-01" swaggertype:"string" format:"date"`
+	EndDate         *coreTypes.Date      `json:"endDate,omitempty" example:"2024-12-31" swaggertype:"string" format:"date"`
+	Budget          *float64             `json:"budget,omitempty" example:"10000.50" minimum:"0"`
+	AddressLine1    *string              `json:"addressLine1,omitempty" example:"123 Main St" maxLength:"255"`
+	AddressLine2    *string              `json:"addressLine2,omitempty" example:"Suite 100" maxLength:"255"`
+	Country         *string              `json:"country,omitempty" example:"US" format:"iso-3166-1-alpha-2" pattern:"^[A-Z]{2}$"`
+	City            *string              `json:"city,omitempty" example:"New York" maxLength:"255"`
+	StateProvince   *string              `json:"stateProvince,omitempty" example:"NY" maxLength:"255"`
+	ZipPostalCode   *string              `json:"zipPostalCode,omitempty" example:"10001" format:"zip-code" pattern:"^\\d{5}(?:[-\\s]\\d{4})?$"`
+	Website         *string              `json:"website,omitempty" example:"https://example.com" format:"uri"`
+	Tags            []uuid.UUID          `json:"tags,omitempty" example:"123e4567-e89b-12d3-a456-426614174000,123e4567-e89b-12d3-a456-426614174001" format:"uuid" validate:"unique,max=10"`
+	ParentProjectID *uuid.UUID           `json:"parentProjectId,omitempty" example:"123e4567-e89b-12d3-a456-426614174002" format:"uuid"` // the project this one is a sub-project of
+	Parent          *ProjectParent       `json:"parent,omitempty"`                                                                       // set with expand=parent
+	Progress        *float64             `json:"progress" extensions:"x-nullable" example:"0.5" minimum:"0" maximum:"1"`                 // completed/total milestones, null without milestones
+	Pinned          bool                 `json:"pinned" example:"false"`                                                                 // pinned projects are listed first
+	Draft           bool                 `json:"draft" example:"false"`                                                                  // drafts stay out of listings and rollups until published
+	PinnedAt        *coreTypes.Timestamp `json:"pinnedAt,omitempty" example:"2024-01-03T00:00:00.000Z" swaggertype:"string" format:"date-time"`
+	CreatedAt       coreTypes.Timestamp  `json:"createdAt" example:"2024-01-01T00:00:00.000Z" swaggertype:"string" format:"date-time"`
+	UpdatedAt       coreTypes.Timestamp  `json:"updatedAt" example:"2024-01-01T00:00:00.000Z" swaggertype:"string" format:"date-time"`
+	DeletedAt       *coreTypes.Timestamp `json:"deletedAt,omitempty" example:"2024-01-02T00:00:00.000Z" swaggertype:"string" format:"date-time"`
+	CreatedBy       *uuid.UUID           `json:"createdBy,omitempty" example:"123e4567-e89b-12d3-a456-426614174001" format:"uuid"` // user who created the project
+	UpdatedBy       *uuid.UUID           `json:"updatedBy,omitempty" example:"123e4567-e89b-12d3-a456-426614174001" format:"uuid"` // user behind the last update
+	Score           *float32             `json:"score,omitempty" example:"0.83" minimum:"0" maximum:"1"`                           // how well the name matches the query, set by searches with one
+}
+
+// ProjectPickerItem is a project in the picker view of a search, only what a picker shows
+// @Description A project as listed by a picker
+type ProjectPickerItem struct {
+	ProjectID uuid.UUID       `json:"projectId" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
+	Name      string          `json:"name" example:"My Project"`
+	Status    string          `json:"status" example:"ongoing" enums:"ongoing,completed,canceled"`
+	EndDate   *coreTypes.Date `json:"endDate,omitempty" example:"2024-12-31" swaggertype:"string" format:"date"`
 }
 
 // ProjectCreatePayload represents the payload for creating a new project
 // @Description Payload for creating a new project
 type ProjectCreatePayload struct {
-	Name          string      `json:"name" example:"My Project" minLength:"1" maxLength:"255" validate:"required"`
-	Description   *string     `json:"description" extensions:"x-nullable" example:"Detailed project description" maxLength:"1000"`
-	Status        string      `json:"status" example:"ongoing" enums:"ongoing,completed,canceled" validate:"required" default:"ongoing"`
-	StartDate     *time.Time  `json:"startDate" extensions:"x-nullable" example:"2024-01-01T00:00:00Z" format:"date-time"`
-	EndDate       *time.Time  `json:"endDate" extensions:"x-nullable" example:"2024-12-31T00:00:00Z" format:"date-time"`
-	Budget        *float64    `json:"budget" extensions:"x-nullable" example:"10000.50" minimum:"0"`
-	AddressLine1  *string     `json:"addressLine1" extensions:"x-nullable" example:"123 Main St" maxLength:"255"`
-	AddressLine2  *string     `json:"addressLine2" extensions:"x-nullable" example:"Suite 100" maxLength:"255"`
-	Country       *string     `json:"country" extensions:"x-nullable" example:"US" format:"iso-3166-1-alpha-2" pattern:"^[A-Z]{2}$"`
-	City          *string     `json:"city" extensions:"x-nullable" example:"New York" maxLength:"255"`
-	StateProvince *string     `json:"stateProvince" extensions:"x-nullable" example:"NY" maxLength:"255"`
-	ZipPostalCode *string     `json:"zipPostalCode" extensions:"x-nullable" example:"10001" format:"zip-code" pattern:"^\\d{5}(?:[-\\s]\\d{4})?$"`
-	Website       *string     `json:"website" extensions:"x-nullable" example:"https://example.com" format:"uri"`
-	Tags          []uuid.UUID `json:"tags" items:"uuid"  example:"123e4567-e89b-12d3-a456-426614174000,123e4567-e89b-12d3-a456-426614174001" format:"uuid" validate:"unique,max=10"`
+	Name            string          `json:"name" example:"My Project" minLength:"1" maxLength:"255" validate:"required"`
+	Description     *string         `json:"description" extensions:"x-nullable" example:"Detailed project description" maxLength:"1000"`
+	Status          string          `json:"status" example:"ongoing" enums:"ongoing,completed,canceled" validate:"required" default:"ongoing"`
+	StartDate       *coreTypes.Date `json:"startDate" extensions:"x-nullable" example:"2024-01-01" swaggertype:"string" format:"date"` // a date, or an RFC 3339 time whose date in its own offset is kept
+	EndDate         *coreTypes.Date `json:"endDate" extensions:"x-nullable" example:"2024-12-31" swaggertype:"string" format:"date"`
+	Budget          *float64        `json:"budget" extensions:"x-nullable" example:"10000.50" minimum:"0"`
+	AddressLine1    *string         `json:"addressLine1" extensions:"x-nullable" example:"123 Main St" maxLength:"255"`
+	AddressLine2    *string         `json:"addressLine2" extensions:"x-nullable" example:"Suite 100" maxLength:"255"`
+	Country         *string         `json:"country" extensions:"x-nullable" example:"US" format:"iso-3166-1-alpha-2" pattern:"^[A-Z]{2}$"`
+	City            *string         `json:"city" extensions:"x-nullable" example:"New York" maxLength:"255"`
+	StateProvince   *string         `json:"stateProvince" extensions:"x-nullable" example:"NY" maxLength:"255"`
+	ZipPostalCode   *string         `json:"zipPostalCode" extensions:"x-nullable" example:"10001" format:"zip-code" pattern:"^\\d{5}(?:[-\\s]\\d{4})?$"`
+	Website         *string         `json:"website" extensions:"x-nullable" example:"https://example.com" format:"uri"`
+	Tags            []uuid.UUID     `json:"tags" items:"uuid"  example:"123e4567-e89b-12d3-a456-426614174000,123e4567-e89b-12d3-a456-426614174001" format:"uuid" validate:"unique,max=10"`
+	ParentProjectID *uuid.UUID      `json:"parentProjectId" extensions:"x-nullable" example:"123e4567-e89b-12d3-a456-426614174002" format:"uuid"`
+	Draft           bool            `json:"draft" example:"false"` // drafts skip the required fields of live projects until they are published
 }
 
 // Bind implements render.Binder interface
@@ -65,7 +117,7 @@ func (c *ProjectCreatePayload) Bind(r *http.Request) error {
 		"name":          validation.Validate(c.Name, validation.Required, validation.Length(1, MaxNameLength)),
 		"description":   validation.Validate(c.Description, validation.When(c.Description != nil, validation.Length(0, MaxDescriptionLength))),
 		"status":        validation.Validate(c.Status, validation.Required, validation.In(string(db.ProjectsStatusOngoing), string(db.ProjectsStatusCompleted), string(db.ProjectsStatusCanceled))),
-		"end_date":      validation.Validate(c.EndDate, validation.When(c.StartDate != nil && c.EndDate != nil, validation.Min(c.StartDate).Error("end date must be after start date"))),
+		"end_date":      validation.Validate(c.EndDate, validation.By(endDateRule(c.StartDate))),
 		"country":       validation.Validate(c.Country, validation.When(c.Country != nil, is.CountryCode2)),
 		"zip_code":      validation.Validate(c.ZipPostalCode, validation.When(c.ZipPostalCode != nil, validate.Zipcode)),
 		"website":       validation.Validate(c.Website, validation.When(c.Website != nil, is.URL)),
@@ -77,24 +129,46 @@ func (c *ProjectCreatePayload) Bind(r *http.Request) error {
 	}.Filter()
 }
 
+// endDateRule checks an end date doesn't fall before startDate, both are days so a project
+// can start and end on the same one
+func endDateRule(startDate *coreTypes.Date) validation.RuleFunc {
+	return func(value interface{}) error {
+		endDate, _ := value.(*coreTypes.Date)
+		if startDate != nil && endDate != nil && endDate.Before(startDate.Time) {
+			return validation.NewError("validation_end_date_before_start", "end date must be after start date")
+		}
+		return nil
+	}
+}
+
+// projectCreateAliases maps the snake_case field names of ProjectCreatePayload to their camelCase name
+var projectCreateAliases = jsoncase.AliasesOf(ProjectCreatePayload{})
+
+// UnmarshalJSON accepts the snake_case field names older clients send too
+func (c *ProjectCreatePayload) UnmarshalJSON(data []byte) error {
+	type payload ProjectCreatePayload
+	return projectCreateAliases.Unmarshal(data, (*payload)(c))
+}
+
 // ProjectUpdatePayload represents the payload for updating an existing project
 // @Description Payload for updating an existing project
 type ProjectUpdatePayload struct {
-	ProjectID     uuid.UUID   `json:"-" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
-	Name          string      `json:"name" example:"My Project" minLength:"1" maxLength:"255"`
-	Description   *string     `json:"description" extensions:"x-nullable" example:"Detailed project description" maxLength:"1000"`
-	Status        string      `json:"status" example:"ongoing" enums:"ongoing,completed,canceled"`
-	StartDate     *time.Time  `json:"startDate" extensions:"x-nullable" example:"2024-01-01T00:00:00Z" format:"date-time"`
-	EndDate       *time.Time  `json:"endDate" extensions:"x-nullable" example:"2024-12-31T00:00:00Z" format:"date-time"`
-	Budget        *float64    `json:"budget" extensions:"x-nullable" example:"10000.50" minimum:"0"`
-	AddressLine1  *string     `json:"addressLine1" extensions:"x-nullable" example:"123 Main St" maxLength:"255"`
-	AddressLine2  *string     `json:"addressLine2" extensions:"x-nullable" example:"Suite 100" maxLength:"255"`
-	Country       *string     `json:"country" extensions:"x-nullable" example:"US" format:"iso-3166-1-alpha-2" pattern:"^[A-Z]{2}$"`
-	City          *string     `json:"city" extensions:"x-nullable" example:"New York" maxLength:"255"`
-	StateProvince *string     `json:"stateProvince" extensions:"x-nullable" example:"NY" maxLength:"255"`
-	ZipPostalCode *string     `json:"zipPostalCode" extensions:"x-nullable" example:"10001" format:"zip-code" pattern:"^\\d{5}(?:[-\\s]\\d{4})?$"`
-	Website       *string     `json:"website" extensions:"x-nullable" example:"https://example.com" format:"uri"`
-	Tags          []uuid.UUID `json:"tags,omitempty" example:"123e4567-e89b-12d3-a456-426614174000,123e4567-e89b-12d3-a456-426614174001" format:"uuid" validate:"unique,max=10"`
+	ProjectID       uuid.UUID       `json:"-" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
+	Name            string          `json:"name" example:"My Project" minLength:"1" maxLength:"255"`
+	Description     *string         `json:"description" extensions:"x-nullable" example:"Detailed project description" maxLength:"1000"`
+	Status          string          `json:"status" example:"ongoing" enums:"ongoing,completed,canceled"`
+	StartDate       *coreTypes.Date `json:"startDate" extensions:"x-nullable" example:"2024-01-01" swaggertype:"string" format:"date"` // a date, or an RFC 3339 time whose date in its own offset is kept
+	EndDate         *coreTypes.Date `json:"endDate" extensions:"x-nullable" example:"2024-12-31" swaggertype:"string" format:"date"`
+	Budget          *float64        `json:"budget" extensions:"x-nullable" example:"10000.50" minimum:"0"`
+	AddressLine1    *string         `json:"addressLine1" extensions:"x-nullable" example:"123 Main St" maxLength:"255"`
+	AddressLine2    *string         `json:"addressLine2" extensions:"x-nullable" example:"Suite 100" maxLength:"255"`
+	Country         *string         `json:"country" extensions:"x-nullable" example:"US" format:"iso-3166-1-alpha-2" pattern:"^[A-Z]{2}$"`
+	City            *string         `json:"city" extensions:"x-nullable" example:"New York" maxLength:"255"`
+	StateProvince   *string         `json:"stateProvince" extensions:"x-nullable" example:"NY" maxLength:"255"`
+	ZipPostalCode   *string         `json:"zipPostalCode" extensions:"x-nullable" example:"10001" format:"zip-code" pattern:"^\\d{5}(?:[-\\s]\\d{4})?$"`
+	Website         *string         `json:"website" extensions:"x-nullable" example:"https://example.com" format:"uri"`
+	Tags            []uuid.UUID     `json:"tags,omitempty" example:"123e4567-e89b-12d3-a456-426614174000,123e4567-e89b-12d3-a456-426614174001" format:"uuid" validate:"unique,max=10"`
+	ParentProjectID *uuid.UUID      `json:"parentProjectId" extensions:"x-nullable" example:"123e4567-e89b-12d3-a456-426614174002" format:"uuid"`
 }
 
 // Bind implements render.Binder interface
@@ -103,7 +177,7 @@ func (u *ProjectUpdatePayload) Bind(r *http.Request) error {
 		"name":          validation.Validate(u.Name, validation.Required, validation.Length(1, MaxNameLength)),
 		"description":   validation.Validate(u.Description, validation.When(u.Description != nil, validation.Length(0, MaxDescriptionLength))),
 		"status":        validation.Validate(u.Status, validation.Required, validation.In(string(db.ProjectsStatusOngoing), string(db.ProjectsStatusCompleted), string(db.ProjectsStatusCanceled))),
-		"end_date":      validation.Validate(u.EndDate, validation.When(u.StartDate != nil && u.EndDate != nil, validation.Min(u.StartDate).Error("end date must be after start date"))),
+		"end_date":      validation.Validate(u.EndDate, validation.By(endDateRule(u.StartDate))),
 		"country":       validation.Validate(u.Country, validation.When(u.Country != nil, is.CountryCode2)),
 		"zip_code":      validation.Validate(u.ZipPostalCode, validation.When(u.ZipPostalCode != nil, validate.Zipcode)),
 		"website":       validation.Validate(u.Website, validation.When(u.Website != nil, is.URL)),
@@ -115,22 +189,32 @@ func (u *ProjectUpdatePayload) Bind(r *http.Request) error {
 	}.Filter()
 }
 
+// projectUpdateAliases maps the snake_case field names of ProjectUpdatePayload to their camelCase name
+var projectUpdateAliases = jsoncase.AliasesOf(ProjectUpdatePayload{})
+
+// UnmarshalJSON accepts the snake_case field names older clients send too
+func (u *ProjectUpdatePayload) UnmarshalJSON(data []byte) error {
+	type payload ProjectUpdatePayload
+	return projectUpdateAliases.Unmarshal(data, (*payload)(u))
+}
+
 func (p *Project) ToUpdatePayload() ProjectUpdatePayload {
 	return ProjectUpdatePayload{
-		ProjectID:     p.ProjectID,
-		Name:          p.Name,          // Non-optional
-		Description:   p.Description,   // Optional
-		Status:        p.Status,        // Non-optional
-		StartDate:     p.StartDate,     // Optional
-		EndDate:       p.EndDate,       // Optional
-		Budget:        p.Budget,        // Optional
-		AddressLine1:  p.AddressLine1,  // Optional
-		AddressLine2:  p.AddressLine2,  // Optional
-		Country:       p.Country,       // Optional
-		City:          p.City,          // Optional
-		StateProvince: p.StateProvince, // Optional
-		ZipPostalCode: p.ZipPostalCode, // Optional
-		Website:       p.Website,       // Optional
-		Tags:          p.Tags,          // Optional
+		ProjectID:       p.ProjectID,
+		Name:            p.Name,            // Non-optional
+		Description:     p.Description,     // Optional
+		Status:          p.Status,          // Non-optional
+		StartDate:       p.StartDate,       // Optional
+		EndDate:         p.EndDate,         // Optional
+		Budget:          p.Budget,          // Optional
+		AddressLine1:    p.AddressLine1,    // Optional
+		AddressLine2:    p.AddressLine2,    // Optional
+		Country:         p.Country,         // Optional
+		City:            p.City,            // Optional
+		StateProvince:   p.StateProvince,   // Optional
+		ZipPostalCode:   p.ZipPostalCode,   // Optional
+		Website:         p.Website,         // Optional
+		Tags:            p.Tags,            // Optional
+		ParentProjectID: p.ParentProjectID, // Optional
 	}
 }
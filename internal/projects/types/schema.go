@@ -0,0 +1,48 @@
+package types
+
+import (
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/schema"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/validate"
+)
+
+// SchemaVersion is bumped whenever a project payload rule changes
+const SchemaVersion = 2
+
+// Schema describes the project create and update payloads, mirroring their Bind rules
+func Schema() schema.Entity {
+	return schema.Entity{
+		Name:    "projects",
+		Title:   "Project payloads",
+		Version: SchemaVersion,
+		Create:  schema.Object(createProperties(), "name", "status"),
+		// the update is applied over the stored project so every field is optional
+		Update: schema.Object(projectProperties()),
+	}
+}
+
+// createProperties adds the fields only a new project takes
+func createProperties() map[string]*schema.Schema {
+	properties := projectProperties()
+	properties["draft"] = schema.Boolean().Describe("drafts may leave the fields live projects require empty until they are published")
+	return properties
+}
+
+func projectProperties() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"name":          schema.String().Length(1, MaxNameLength),
+		"description":   schema.String().Length(0, MaxDescriptionLength).Nullable(),
+		"status":        schema.In(schema.String(), db.ProjectsStatusOngoing, db.ProjectsStatusCompleted, db.ProjectsStatusCanceled),
+		"startDate":     schema.String().As("date-time").Nullable(),
+		"endDate":       schema.String().As("date-time").Describe("must not be before startDate").Nullable(),
+		"budget":        schema.Number().Min(0).Nullable(),
+		"addressLine1":  schema.String().Length(0, MaxAddressLength).Nullable(),
+		"addressLine2":  schema.String().Length(0, MaxAddressLength).Nullable(),
+		"country":       schema.In(schema.String(), validate.CountryCodes()...).Nullable(),
+		"city":          schema.String().Length(0, MaxAddressLength).Nullable(),
+		"stateProvince": schema.String().Nullable(),
+		"zipPostalCode": schema.String().Match(validate.ZipcodePattern).Nullable(),
+		"website":       schema.String().As("uri").Nullable(),
+		"tags":          schema.Array(schema.UUID()).Count(0, MaxTagsCount).Nullable(),
+	}
+}
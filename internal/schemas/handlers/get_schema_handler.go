@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/go-chi/chi/v5"
+)
+
+// GetSchema godoc
+// @Summary Get a payload schema
+// @Description Returns a JSON Schema (draft 2020-12) document of the entity's create and update payloads under $defs, built from the same rules the API validates with. The ETag changes only when the rules do.
+// @Tags Schemas
+// @Produce application/schema+json
+// @Security BearerAuth
+// @Param entity path string true "entity name" example(contacts)
+// @Param If-None-Match header string false "ETag of a cached copy"
+// @Success 200 {object} object
+// @Success 304 "cached copy is current"
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /schemas/{entity} [get]
+// @ID GetSchema
+func (h *SchemaHandler) GetSchema(w http.ResponseWriter, r *http.Request) {
+	entity, ok := h.registry.Get(chi.URLParam(r, "entity"))
+	if !ok {
+		h.RespondError(w, r, errors.ErrNotFound())
+		return
+	}
+
+	etag := entity.ETag()
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", schemaMaxAge))
+	if matchesETag(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	body, err := json.Marshal(entity.Document())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInternal(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/schema+json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// matchesETag reports whether an If-None-Match header lists the etag
+func matchesETag(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/schema"
+	"go.uber.org/zap"
+)
+
+// schemaMaxAge is how long clients may reuse a schema document before revalidating it
+const schemaMaxAge = 24 * 60 * 60
+
+type SchemaHandler struct {
+	handlers.BaseHandler
+	registry *schema.Registry
+}
+
+func NewSchemaHandler(registry *schema.Registry, logger *zap.Logger) *SchemaHandler {
+	return &SchemaHandler{
+		BaseHandler: handlers.NewBaseHandler(logger),
+		registry:    registry,
+	}
+}
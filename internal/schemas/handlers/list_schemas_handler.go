@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+)
+
+// ListSchemas godoc
+// @Summary List payload schemas
+// @Description Lists the entities a JSON Schema of the create and update payloads is served for
+// @Tags Schemas
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} payloads.Response{data=[]string}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 429 {object} errors.ErrorResponse
+// @Router /schemas [get]
+// @ID ListSchemas
+func (h *SchemaHandler) ListSchemas(w http.ResponseWriter, r *http.Request) {
+	if !h.CheckQueryParams(w, r) {
+		return
+	}
+
+	names := h.registry.Names()
+	h.Respond(w, r, payloads.List(names, len(names)))
+}
@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	contactTypes "github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/schema"
+	projectTypes "github.com/Abdelrahman-habib/expense-tracker/internal/projects/types"
+	walletGroupTypes "github.com/Abdelrahman-habib/expense-tracker/internal/walletgroups/types"
+	walletTypes "github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+	"github.com/go-chi/chi/v5"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func setupTest() *SchemaHandler {
+	registry := schema.NewRegistry(
+		contactTypes.Schema(),
+		projectTypes.Schema(),
+		walletTypes.Schema(),
+		walletGroupTypes.Schema(),
+	)
+	return NewSchemaHandler(registry, zap.NewNop())
+}
+
+func newRequest(entity string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/schemas/"+entity, nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("entity", entity)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+// getSchema fetches the entity's document through the handler
+func getSchema(t *testing.T, handler *SchemaHandler, entity string) []byte {
+	w := httptest.NewRecorder()
+	handler.GetSchema(w, newRequest(entity))
+	require.Equal(t, http.StatusOK, w.Code)
+	return w.Body.Bytes()
+}
+
+// compilePayload compiles one of the payload definitions of the document
+func compilePayload(t *testing.T, document []byte, payload string) *jsonschema.Schema {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(document))
+	require.NoError(t, err)
+	id := doc.(map[string]any)["$id"].(string)
+
+	compiler := jsonschema.NewCompiler()
+	require.NoError(t, compiler.AddResource(id, doc))
+	compiled, err := compiler.Compile(id + "#/$defs/" + payload)
+	require.NoError(t, err)
+	return compiled
+}
+
+func TestSchemaHandler_ListSchemas(t *testing.T) {
+	handler := setupTest()
+
+	w := httptest.NewRecorder()
+	handler.ListSchemas(w, httptest.NewRequest(http.MethodGet, "/schemas", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data []string `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []string{"contacts", "projects", "wallet-groups", "wallets"}, response.Data)
+}
+
+func TestSchemaHandler_GetSchema(t *testing.T) {
+	handler := setupTest()
+
+	compiler := jsonschema.NewCompiler()
+	metaSchema, err := compiler.Compile(schema.Draft)
+	require.NoError(t, err)
+
+	for _, entity := range handler.registry.Names() {
+		t.Run(entity, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.GetSchema(w, newRequest(entity))
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "application/schema+json", w.Header().Get("Content-Type"))
+			assert.Equal(t, "public, max-age=86400", w.Header().Get("Cache-Control"))
+			assert.NotEmpty(t, w.Header().Get("ETag"))
+
+			doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(w.Body.Bytes()))
+			require.NoError(t, err)
+			assert.NoError(t, metaSchema.Validate(doc))
+			assert.Equal(t, schema.Draft, doc.(map[string]any)["$schema"])
+		})
+	}
+
+	t.Run("unknown entity", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.GetSchema(w, newRequest("invoices"))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestSchemaHandler_GetSchema_ETag(t *testing.T) {
+	handler := setupTest()
+
+	w := httptest.NewRecorder()
+	handler.GetSchema(w, newRequest("wallets"))
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	assert.Equal(t, `"wallets-v1"`, etag)
+
+	tests := []struct {
+		name           string
+		ifNoneMatch    string
+		expectedStatus int
+	}{
+		{name: "current copy", ifNoneMatch: etag, expectedStatus: http.StatusNotModified},
+		{name: "weak comparison", ifNoneMatch: `"contacts-v1", W/` + etag, expectedStatus: http.StatusNotModified},
+		{name: "previous version", ifNoneMatch: `"wallets-v0"`, expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newRequest("wallets")
+			req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			w := httptest.NewRecorder()
+			handler.GetSchema(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, etag, w.Header().Get("ETag"))
+			if tt.expectedStatus == http.StatusNotModified {
+				assert.Empty(t, w.Body.String())
+			}
+		})
+	}
+}
+
+// binder decodes a payload and runs its ozzo rules
+type binder func(payload string) error
+
+func bindWith[T any, P interface {
+	*T
+	Bind(*http.Request) error
+}]() binder {
+	return func(payload string) error {
+		var value T
+		if err := json.Unmarshal([]byte(payload), &value); err != nil {
+			return err
+		}
+		return P(&value).Bind(nil)
+	}
+}
+
+func TestSchemaHandler_GetSchema_MatchesBindRules(t *testing.T) {
+	handler := setupTest()
+	tagID := "123e4567-e89b-12d3-a456-426614174000"
+	tags := func(count int) string {
+		ids := make([]string, count)
+		for i := range ids {
+			ids[i] = `"123e4567-e89b-12d3-a456-42661417400` + string(rune('0'+i%10)) + `"`
+		}
+		return "[" + strings.Join(ids, ",") + "]"
+	}
+
+	tests := []struct {
+		name    string
+		entity  string
+		bind    binder
+		payload string
+		valid   bool
+	}{
+		{name: "contact with a name", entity: "contacts", bind: bindWith[contactTypes.ContactCreatePayload](), payload: `{"name":"John Doe","country":"US","tags":["` + tagID + `"]}`, valid: true},
+		{name: "contact without a name", entity: "contacts", bind: bindWith[contactTypes.ContactCreatePayload](), payload: `{"email":"john@example.com"}`},
+		{name: "contact name too long", entity: "contacts", bind: bindWith[contactTypes.ContactCreatePayload](), payload: `{"name":"` + strings.Repeat("a", contactTypes.MaxNameLength+1) + `"}`},
+		{name: "contact with too many tags", entity: "contacts", bind: bindWith[contactTypes.ContactCreatePayload](), payload: `{"name":"John","tags":` + tags(contactTypes.MaxTagsCount+1) + `}`},
+		{name: "contact with duplicate tags", entity: "contacts", bind: bindWith[contactTypes.ContactCreatePayload](), payload: `{"name":"John","tags":["` + tagID + `","` + tagID + `"]}`},
+		{name: "contact with unknown country", entity: "contacts", bind: bindWith[contactTypes.ContactCreatePayload](), payload: `{"name":"John","country":"XX"}`},
+		{name: "contact phone too long", entity: "contacts", bind: bindWith[contactTypes.ContactCreatePayload](), payload: `{"name":"John","phone":"` + strings.Repeat("1", contactTypes.MaxPhoneLength+1) + `"}`},
+		{name: "ongoing project", entity: "projects", bind: bindWith[projectTypes.ProjectCreatePayload](), payload: `{"name":"House","status":"ongoing","budget":0}`, valid: true},
+		{name: "project with unknown status", entity: "projects", bind: bindWith[projectTypes.ProjectCreatePayload](), payload: `{"name":"House","status":"paused"}`},
+		{name: "project without a status", entity: "projects", bind: bindWith[projectTypes.ProjectCreatePayload](), payload: `{"name":"House"}`},
+		{name: "project with negative budget", entity: "projects", bind: bindWith[projectTypes.ProjectCreatePayload](), payload: `{"name":"House","status":"ongoing","budget":-1}`},
+		{name: "project description too long", entity: "projects", bind: bindWith[projectTypes.ProjectCreatePayload](), payload: `{"name":"House","status":"ongoing","description":"` + strings.Repeat("a", projectTypes.MaxDescriptionLength+1) + `"}`},
+		{name: "wallet in dollars", entity: "wallets", bind: bindWith[walletTypes.WalletCreatePayload](), payload: `{"name":"Cash","currency":"USD","balance":10.5}`, valid: true},
+		{name: "wallet with unknown currency", entity: "wallets", bind: bindWith[walletTypes.WalletCreatePayload](), payload: `{"name":"Cash","currency":"ABC"}`},
+		{name: "wallet without a currency", entity: "wallets", bind: bindWith[walletTypes.WalletCreatePayload](), payload: `{"name":"Cash"}`},
+		{name: "wallet with negative balance", entity: "wallets", bind: bindWith[walletTypes.WalletCreatePayload](), payload: `{"name":"Cash","currency":"USD","balance":-1}`},
+		{name: "wallet with too many tags", entity: "wallets", bind: bindWith[walletTypes.WalletCreatePayload](), payload: `{"name":"Cash","currency":"USD","tags":` + tags(walletTypes.MaxTagsCount+1) + `}`},
+		{name: "wallet group", entity: "wallet-groups", bind: bindWith[walletGroupTypes.WalletGroupCreatePayload](), payload: `{"name":"Savings","sortOrder":2}`, valid: true},
+		{name: "wallet group name too long", entity: "wallet-groups", bind: bindWith[walletGroupTypes.WalletGroupCreatePayload](), payload: `{"name":"` + strings.Repeat("a", walletGroupTypes.MaxNameLength+1) + `"}`},
+		{name: "wallet group with negative sort order", entity: "wallet-groups", bind: bindWith[walletGroupTypes.WalletGroupCreatePayload](), payload: `{"name":"Savings","sortOrder":-1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			create := compilePayload(t, getSchema(t, handler, tt.entity), "create")
+			instance, err := jsonschema.UnmarshalJSON(strings.NewReader(tt.payload))
+			require.NoError(t, err)
+
+			schemaErr := create.Validate(instance)
+			bindErr := tt.bind(tt.payload)
+			if tt.valid {
+				assert.NoError(t, schemaErr)
+				assert.NoError(t, bindErr)
+			} else {
+				assert.Error(t, schemaErr)
+				assert.Error(t, bindErr)
+			}
+		})
+	}
+}
+
+func TestSchemaHandler_GetSchema_UpdateIsPartial(t *testing.T) {
+	handler := setupTest()
+
+	for _, entity := range handler.registry.Names() {
+		t.Run(entity, func(t *testing.T) {
+			update := compilePayload(t, getSchema(t, handler, entity), "update")
+			assert.NoError(t, update.Validate(map[string]any{}))
+			assert.Error(t, update.Validate(map[string]any{"name": ""}))
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package routes
+
+import (
+	budgetTypes "github.com/Abdelrahman-habib/expense-tracker/internal/budgets/types"
+	contactTypes "github.com/Abdelrahman-habib/expense-tracker/internal/contacts/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/schema"
+	projectTypes "github.com/Abdelrahman-habib/expense-tracker/internal/projects/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/schemas/handlers"
+	walletGroupTypes "github.com/Abdelrahman-habib/expense-tracker/internal/walletgroups/types"
+	walletTypes "github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// Router encapsulates the schema routes setup
+type Router struct {
+	handler *handlers.SchemaHandler
+}
+
+// New creates a new schema router serving the payload schemas of every module,
+// new modules add their Schema here
+func New(logger *zap.Logger) *Router {
+	registry := schema.NewRegistry(
+		contactTypes.Schema(),
+		projectTypes.Schema(),
+		walletTypes.Schema(),
+		walletGroupTypes.Schema(),
+		budgetTypes.Schema(),
+	)
+
+	return &Router{
+		handler: handlers.NewSchemaHandler(registry, logger),
+	}
+}
+
+// RegisterRoutes registers all schema routes
+func (r *Router) RegisterRoutes(router chi.Router) {
+	router.Route("/schemas", func(router chi.Router) {
+		router.Get("/", r.handler.ListSchemas)
+		router.Get("/{entity}", r.handler.GetSchema)
+	})
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/search/types"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+)
+
+// FullTextSearch godoc
+// @Summary Full-text search
+// @Description Searches contact notes and project descriptions with Postgres full-text search. The query accepts web search syntax ("quoted phrases", or, -excluded), matches word stems so "running" finds "run", and each result carries a snippet with the matches wrapped in <mark> tags
+// @Tags Search
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "Search query" minLength(1) maxLength(100)
+// @Param types query string false "Comma separated result types to search, defaults to all" example(notes,projects)
+// @Param limit query integer false "Maximum number of results" minimum(1) maximum(50) default(10)
+// @Success 200 {object} payloads.Response{data=[]types.SearchResult}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /search/fulltext [get]
+// @ID FullTextSearch
+func (h *SearchHandler) FullTextSearch(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	if !h.CheckQueryParams(w, r, types.FullTextQueryParams...) {
+		return
+	}
+
+	params, err := types.ParseFullTextSearchParams(r.URL.Query(), h.limits)
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	results, err := h.service.FullTextSearch(r.Context(), userID, params)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.Search(
+		results,
+		params.Query,
+		params.Limit,
+		len(results),
+	))
+}
@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/search/service"
+	"go.uber.org/zap"
+)
+
+type SearchHandler struct {
+	handlers.BaseHandler
+	service service.SearchService
+	limits  coreTypes.LimitPolicy
+}
+
+func NewSearchHandler(service service.SearchService, limits coreTypes.LimitPolicy, logger *zap.Logger) *SearchHandler {
+	return &SearchHandler{
+		BaseHandler: handlers.NewBaseHandler(logger),
+		service:     service,
+		limits:      limits,
+	}
+}
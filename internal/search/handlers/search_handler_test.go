@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/search/types"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// Mock service
+type mockSearchService struct {
+	mock.Mock
+}
+
+func (m *mockSearchService) FullTextSearch(ctx context.Context, userID uuid.UUID, params types.FullTextSearchParams) ([]types.SearchResult, error) {
+	args := m.Called(ctx, userID, params)
+	return args.Get(0).([]types.SearchResult), args.Error(1)
+}
+
+func TestSearchHandler_FullTextSearch(t *testing.T) {
+	userID := uuid.New()
+	contactID := uuid.New()
+
+	tests := []struct {
+		name           string
+		setupAuth      bool
+		query          string
+		setupMock      func(*mockSearchService)
+		expectedStatus int
+		checkResponse  func(t *testing.T, response map[string]interface{})
+	}{
+		{
+			name:      "searches every type by default",
+			setupAuth: true,
+			query:     "?q=run",
+			setupMock: func(m *mockSearchService) {
+				m.On("FullTextSearch", mock.Anything, userID, types.FullTextSearchParams{
+					Query: "run",
+					Types: []types.ResultType{types.ResultTypeNotes, types.ResultTypeProjects},
+					Limit: 10,
+				}).Return([]types.SearchResult{{
+					Type:    types.ResultTypeNotes,
+					ID:      contactID,
+					Title:   "John Doe",
+					Snippet: "Likes <mark>running</mark> before work",
+					Rank:    0.06,
+				}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, response map[string]interface{}) {
+				data := response["data"].([]interface{})
+				assert.Len(t, data, 1)
+				result := data[0].(map[string]interface{})
+				assert.Equal(t, "notes", result["type"])
+				assert.Equal(t, contactID.String(), result["id"])
+				assert.Equal(t, "Likes <mark>running</mark> before work", result["snippet"])
+			},
+		},
+		{
+			name:      "selected types",
+			setupAuth: true,
+			query:     "?q=%22kitchen+remodel%22&types=projects,+Projects&limit=5",
+			setupMock: func(m *mockSearchService) {
+				m.On("FullTextSearch", mock.Anything, userID, types.FullTextSearchParams{
+					Query: `"kitchen remodel"`,
+					Types: []types.ResultType{types.ResultTypeProjects},
+					Limit: 5,
+				}).Return([]types.SearchResult{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, response map[string]interface{}) {
+				assert.Empty(t, response["data"])
+			},
+		},
+		{
+			name:           "unsupported type",
+			setupAuth:      true,
+			query:          "?q=run&types=notes,wallets",
+			setupMock:      func(m *mockSearchService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing query",
+			setupAuth:      true,
+			query:          "?types=notes",
+			setupMock:      func(m *mockSearchService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing auth",
+			query:          "?q=run",
+			setupMock:      func(m *mockSearchService) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockSearchService)
+			handler := NewSearchHandler(mockService, coreTypes.DefaultLimitPolicy(), zap.NewNop())
+			tt.setupMock(mockService)
+
+			req := httptest.NewRequest(http.MethodGet, "/search/fulltext"+tt.query, nil)
+			if tt.setupAuth {
+				req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+			}
+
+			w := httptest.NewRecorder()
+			handler.FullTextSearch(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.checkResponse != nil {
+				var response map[string]interface{}
+				assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				tt.checkResponse(t, response)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
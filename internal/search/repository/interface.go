@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/search/types"
+)
+
+// Repository defines the interface for full-text search operations
+type Repository interface {
+	// SearchContactNotes finds the user's contacts whose notes match the query, best match first
+	SearchContactNotes(ctx context.Context, userID uuid.UUID, query string, limit int32) ([]types.SearchResult, error)
+
+	// SearchProjectDescriptions finds the user's projects whose description matches the query, best match first
+	SearchProjectDescriptions(ctx context.Context, userID uuid.UUID, query string, limit int32) ([]types.SearchResult, error)
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/search/types"
+)
+
+type searchRepository struct {
+	q *db.Queries
+}
+
+// New creates a new search repository
+func New(q *db.Queries) Repository {
+	return &searchRepository{q: q}
+}
+
+func (r *searchRepository) SearchContactNotes(ctx context.Context, userID uuid.UUID, query string, limit int32) ([]types.SearchResult, error) {
+	if userID == uuid.Nil {
+		return nil, fmt.Errorf("invalid user id")
+	}
+
+	rows, err := r.q.SearchContactNotes(ctx, db.SearchContactNotesParams{
+		UserID: userID,
+		Query:  query,
+		Limit:  limit,
+	})
+	if err != nil {
+		return nil, errors.HandleRepositoryError(err, "search", "contact notes")
+	}
+
+	results := make([]types.SearchResult, len(rows))
+	for i, row := range rows {
+		results[i] = types.SearchResult{
+			Type:      types.ResultTypeNotes,
+			ID:        row.ContactID,
+			Title:     row.Name,
+			Snippet:   row.Snippet,
+			Rank:      row.Rank,
+			UpdatedAt: coreTypes.NewTimestamp(row.UpdatedAt.Time),
+		}
+	}
+	return results, nil
+}
+
+func (r *searchRepository) SearchProjectDescriptions(ctx context.Context, userID uuid.UUID, query string, limit int32) ([]types.SearchResult, error) {
+	if userID == uuid.Nil {
+		return nil, fmt.Errorf("invalid user id")
+	}
+
+	rows, err := r.q.SearchProjectDescriptions(ctx, db.SearchProjectDescriptionsParams{
+		UserID: userID,
+		Query:  query,
+		Limit:  limit,
+	})
+	if err != nil {
+		return nil, errors.HandleRepositoryError(err, "search", "project descriptions")
+	}
+
+	results := make([]types.SearchResult, len(rows))
+	for i, row := range rows {
+		results[i] = types.SearchResult{
+			Type:      types.ResultTypeProjects,
+			ID:        row.ProjectID,
+			Title:     row.Name,
+			Snippet:   row.Snippet,
+			Rank:      row.Rank,
+			UpdatedAt: coreTypes.NewTimestamp(row.UpdatedAt.Time),
+		}
+	}
+	return results, nil
+}
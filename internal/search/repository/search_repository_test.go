@@ -0,0 +1,206 @@
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/search/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/search/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/suite"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// SearchRepositoryTestSuite defines the test suite
+type SearchRepositoryTestSuite struct {
+	suite.Suite
+	container testcontainers.Container
+	pool      *pgxpool.Pool
+	repo      repository.Repository
+	ctx       context.Context
+	testUser  uuid.UUID
+	otherUser uuid.UUID
+}
+
+// TestSearchRepository is the single entry point for the test suite
+func TestSearchRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+	suite.Run(t, new(SearchRepositoryTestSuite))
+}
+
+func (s *SearchRepositoryTestSuite) SetupSuite() {
+	s.ctx = context.Background()
+
+	var host, port string
+	var err error
+
+	if os.Getenv("CI") == "true" {
+		// Running in GitHub Actions, use service-based PostgreSQL
+		host = "localhost"
+		port = "5432"
+	} else {
+		// Running locally, use TestContainers
+		req := testcontainers.ContainerRequest{
+			Image:        "postgres:15-alpine",
+			ExposedPorts: []string{"5432/tcp"},
+			WaitingFor:   wait.ForListeningPort("5432/tcp"),
+			Env: map[string]string{
+				"POSTGRES_DB":       "testdb",
+				"POSTGRES_USER":     "test",
+				"POSTGRES_PASSWORD": "test",
+			},
+			NetworkMode: "bridge",
+		}
+
+		container, err := testcontainers.GenericContainer(s.ctx, testcontainers.GenericContainerRequest{
+			ContainerRequest: req,
+			Started:          true,
+		})
+		s.Require().NoError(err)
+		s.container = container
+
+		host, err = container.Host(s.ctx)
+		s.Require().NoError(err)
+		portMapped, err := container.MappedPort(s.ctx, "5432")
+		s.Require().NoError(err)
+		port = portMapped.Port()
+	}
+
+	connString := fmt.Sprintf("postgres://test:test@%s:%s/testdb?sslmode=disable", host, port)
+	s.pool, err = pgxpool.New(s.ctx, connString)
+	s.Require().NoError(err)
+	s.Require().NoError(s.runMigrations())
+
+	s.repo = repository.New(db.New(s.pool))
+
+	s.testUser = s.createUser("srt_search_user")
+	s.otherUser = s.createUser("srt_other_user")
+	s.seed()
+}
+
+func (s *SearchRepositoryTestSuite) TearDownSuite() {
+	if s.pool != nil {
+		_, err := s.pool.Exec(s.ctx, `DELETE FROM users WHERE user_id = ANY($1)`, []uuid.UUID{s.testUser, s.otherUser})
+		s.Require().NoError(err)
+		s.pool.Close()
+	}
+	if s.container != nil && os.Getenv("CI") != "true" {
+		s.Require().NoError(s.container.Terminate(s.ctx))
+	}
+}
+
+func (s *SearchRepositoryTestSuite) runMigrations() error {
+	migrationsDir := "../../db/sql/migrations"
+
+	// Convert pool to *sql.DB for goose
+	sqlDB := stdlib.OpenDBFromPool(s.pool)
+	defer sqlDB.Close()
+
+	if err := goose.SetDialect("postgres"); err != nil {
+		return fmt.Errorf("failed to set dialect: %w", err)
+	}
+	if err := goose.Up(sqlDB, migrationsDir); err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+	return nil
+}
+
+func (s *SearchRepositoryTestSuite) createUser(name string) uuid.UUID {
+	userID := uuid.New()
+	_, err := s.pool.Exec(s.ctx, `
+		INSERT INTO users (user_id, clerk_ex_user_id, name, email)
+		VALUES ($1, $2, $3, $4)
+	`, userID, userID.String(), name, name+"@example.com")
+	s.Require().NoError(err)
+	return userID
+}
+
+func (s *SearchRepositoryTestSuite) seed() {
+	contacts := []struct {
+		userID uuid.UUID
+		name   string
+		notes  string
+	}{
+		{s.testUser, "Runner", "Goes for a run every morning and prefers calls after nine"},
+		{s.testUser, "Landlord", "Owns the warehouse next to the old train station, rent is due monthly"},
+		{s.testUser, "Trashed", "Also runs the local marathon club"},
+		{s.otherUser, "Someone Else", "Running late to every meeting"},
+	}
+	for _, c := range contacts {
+		_, err := s.pool.Exec(s.ctx, `INSERT INTO contacts (user_id, name, notes) VALUES ($1, $2, $3)`, c.userID, c.name, c.notes)
+		s.Require().NoError(err)
+	}
+	_, err := s.pool.Exec(s.ctx, `UPDATE contacts SET deleted_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND name = 'Trashed'`, s.testUser)
+	s.Require().NoError(err)
+
+	projects := []struct {
+		name        string
+		description string
+	}{
+		{"Kitchen", "Full kitchen remodel with new cabinets and a marble countertop"},
+		{"Garden", "Remodel the garden shed and plant a new kitchen garden"},
+	}
+	for _, p := range projects {
+		_, err := s.pool.Exec(s.ctx, `INSERT INTO projects (user_id, name, description) VALUES ($1, $2, $3)`, s.testUser, p.name, p.description)
+		s.Require().NoError(err)
+	}
+}
+
+func titles(results []types.SearchResult) []string {
+	names := make([]string, len(results))
+	for i, r := range results {
+		names[i] = r.Title
+	}
+	return names
+}
+
+func (s *SearchRepositoryTestSuite) TestStemming() {
+	results, err := s.repo.SearchContactNotes(s.ctx, s.testUser, "running", 10)
+	s.Require().NoError(err)
+	s.Equal([]string{"Runner"}, titles(results), "trashed contacts and other users' notes are never returned")
+	s.Equal(types.ResultTypeNotes, results[0].Type)
+	s.Positive(results[0].Rank)
+}
+
+func (s *SearchRepositoryTestSuite) TestPhraseQuery() {
+	results, err := s.repo.SearchProjectDescriptions(s.ctx, s.testUser, "kitchen remodel", 10)
+	s.Require().NoError(err)
+	s.ElementsMatch([]string{"Kitchen", "Garden"}, titles(results))
+
+	results, err = s.repo.SearchProjectDescriptions(s.ctx, s.testUser, `"kitchen remodel"`, 10)
+	s.Require().NoError(err)
+	s.Equal([]string{"Kitchen"}, titles(results), "a quoted phrase needs the words next to each other")
+
+	results, err = s.repo.SearchProjectDescriptions(s.ctx, s.testUser, "remodel -marble", 10)
+	s.Require().NoError(err)
+	s.Equal([]string{"Garden"}, titles(results))
+}
+
+func (s *SearchRepositoryTestSuite) TestSnippet() {
+	results, err := s.repo.SearchContactNotes(s.ctx, s.testUser, "train station", 10)
+	s.Require().NoError(err)
+	s.Require().Len(results, 1)
+	s.Equal("Landlord", results[0].Title)
+	s.Contains(results[0].Snippet, "<mark>train</mark> <mark>station</mark>")
+	s.Contains(results[0].Snippet, "warehouse")
+}
+
+func (s *SearchRepositoryTestSuite) TestNoMatches() {
+	results, err := s.repo.SearchContactNotes(s.ctx, s.testUser, "invoice", 10)
+	s.Require().NoError(err)
+	s.Empty(results)
+
+	// stop words alone match nothing rather than failing
+	results, err = s.repo.SearchProjectDescriptions(s.ctx, s.testUser, "the and", 10)
+	s.Require().NoError(err)
+	s.Empty(results)
+}
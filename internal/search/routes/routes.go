@@ -0,0 +1,47 @@
+package routes
+
+import (
+	"github.com/Abdelrahman-habib/expense-tracker/config"
+	coreHandlers "github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/search/handlers"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/search/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/search/service"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// Router encapsulates the search routes setup
+type Router struct {
+	handler  *handlers.SearchHandler
+	features config.FeaturesConfig
+}
+
+// New creates a new search router with proper dependency injection
+func New(dbService db.Service, logger *zap.Logger, features config.FeaturesConfig, limits coreTypes.LimitPolicy) *Router {
+	// Get queries from db service
+	queries := dbService.Queries()
+
+	// Initialize repository
+	repo := repository.New(queries)
+
+	// Initialize service with repository
+	searchService := service.NewSearchService(repo, logger)
+
+	// Initialize handler with service
+	handler := handlers.NewSearchHandler(searchService, limits, logger)
+
+	return &Router{
+		handler:  handler,
+		features: features,
+	}
+}
+
+// RegisterRoutes registers all search routes
+func (r *Router) RegisterRoutes(router chi.Router) {
+	router.Route("/search", func(router chi.Router) {
+		router.With(coreHandlers.RequireFeature(r.features, config.FeatureFullTextSearch)).
+			Get("/fulltext", r.handler.FullTextSearch)
+	})
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/search/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/search/types"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type SearchService interface {
+	FullTextSearch(ctx context.Context, userID uuid.UUID, params types.FullTextSearchParams) ([]types.SearchResult, error)
+}
+
+type searchService struct {
+	repo   repository.Repository
+	logger *zap.Logger
+}
+
+func NewSearchService(repo repository.Repository, logger *zap.Logger) SearchService {
+	return &searchService{
+		repo:   repo,
+		logger: logger.With(zap.String("component", "search_service")),
+	}
+}
+
+// FullTextSearch searches every requested result type and merges the matches by rank
+func (s *searchService) FullTextSearch(ctx context.Context, userID uuid.UUID, params types.FullTextSearchParams) ([]types.SearchResult, error) {
+	s.logger.Info("full-text searching",
+		zap.String("user_id", userID.String()),
+		zap.String("query", params.Query),
+		zap.Int("types", len(params.Types)),
+		zap.Int32("limit", params.Limit))
+
+	results := make([]types.SearchResult, 0)
+	if params.Includes(types.ResultTypeNotes) {
+		notes, err := s.repo.SearchContactNotes(ctx, userID, params.Query, params.Limit)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, notes...)
+	}
+	if params.Includes(types.ResultTypeProjects) {
+		projects, err := s.repo.SearchProjectDescriptions(ctx, userID, params.Query, params.Limit)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, projects...)
+	}
+
+	// each type is already ranked, a stable sort keeps their own tie order
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Rank > results[j].Rank
+	})
+	if len(results) > int(params.Limit) {
+		results = results[:params.Limit]
+	}
+	return results, nil
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/search/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// Mock repository
+type mockSearchRepository struct {
+	mock.Mock
+}
+
+func (m *mockSearchRepository) SearchContactNotes(ctx context.Context, userID uuid.UUID, query string, limit int32) ([]types.SearchResult, error) {
+	args := m.Called(ctx, userID, query, limit)
+	return args.Get(0).([]types.SearchResult), args.Error(1)
+}
+
+func (m *mockSearchRepository) SearchProjectDescriptions(ctx context.Context, userID uuid.UUID, query string, limit int32) ([]types.SearchResult, error) {
+	args := m.Called(ctx, userID, query, limit)
+	return args.Get(0).([]types.SearchResult), args.Error(1)
+}
+
+func TestSearchService_FullTextSearch(t *testing.T) {
+	userID := uuid.New()
+	note := func(rank float32) types.SearchResult {
+		return types.SearchResult{Type: types.ResultTypeNotes, ID: uuid.New(), Rank: rank}
+	}
+	project := func(rank float32) types.SearchResult {
+		return types.SearchResult{Type: types.ResultTypeProjects, ID: uuid.New(), Rank: rank}
+	}
+	notes := []types.SearchResult{note(0.9), note(0.3), note(0.1)}
+	projects := []types.SearchResult{project(0.5), project(0.3)}
+
+	tests := []struct {
+		name          string
+		params        types.FullTextSearchParams
+		setupMock     func(*mockSearchRepository)
+		expected      []types.SearchResult
+		expectedError bool
+	}{
+		{
+			name: "merges types by rank",
+			params: types.FullTextSearchParams{
+				Query: "run",
+				Types: types.ResultTypes,
+				Limit: 4,
+			},
+			setupMock: func(m *mockSearchRepository) {
+				m.On("SearchContactNotes", mock.Anything, userID, "run", int32(4)).Return(notes, nil)
+				m.On("SearchProjectDescriptions", mock.Anything, userID, "run", int32(4)).Return(projects, nil)
+			},
+			expected: []types.SearchResult{notes[0], projects[0], notes[1], projects[1]},
+		},
+		{
+			name: "only requested types",
+			params: types.FullTextSearchParams{
+				Query: "run",
+				Types: []types.ResultType{types.ResultTypeProjects},
+				Limit: 10,
+			},
+			setupMock: func(m *mockSearchRepository) {
+				m.On("SearchProjectDescriptions", mock.Anything, userID, "run", int32(10)).Return(projects, nil)
+			},
+			expected: projects,
+		},
+		{
+			name: "repository error",
+			params: types.FullTextSearchParams{
+				Query: "run",
+				Types: types.ResultTypes,
+				Limit: 10,
+			},
+			setupMock: func(m *mockSearchRepository) {
+				m.On("SearchContactNotes", mock.Anything, userID, "run", int32(10)).
+					Return([]types.SearchResult(nil), errors.New("database error"))
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mockSearchRepository)
+			tt.setupMock(mockRepo)
+			service := NewSearchService(mockRepo, zap.NewNop())
+
+			results, err := service.FullTextSearch(context.Background(), userID, tt.params)
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, results)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+)
+
+// ResultType identifies the kind of text a full-text search result was found in
+type ResultType string
+
+const (
+	ResultTypeNotes    ResultType = "notes"
+	ResultTypeProjects ResultType = "projects"
+)
+
+// ResultTypes lists every searchable result type in the order results are gathered
+var ResultTypes = []ResultType{ResultTypeNotes, ResultTypeProjects}
+
+// FullTextQueryParams lists the query parameters accepted by full-text search
+var FullTextQueryParams = []string{"q", "types", "limit"}
+
+// SearchResult is a single full-text search match
+// @Description Full-text search match with a highlighted snippet of the matching text
+type SearchResult struct {
+	Type      ResultType      `json:"type" example:"notes" enums:"notes,projects"`
+	ID        uuid.UUID       `json:"id" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
+	Title     string          `json:"title" example:"John Doe"`
+	Snippet   string          `json:"snippet" example:"Prefers to <mark>run</mark> the kickoff meetings himself"`
+	Rank      float32         `json:"rank" example:"0.0607927"`
+	UpdatedAt types.Timestamp `json:"updatedAt" example:"2024-01-01T00:00:00.000Z" swaggertype:"string" format:"date-time"`
+}
+
+// FullTextSearchParams represents the parameters of a full-text search
+type FullTextSearchParams struct {
+	Query string
+	Types []ResultType
+	Limit int32
+}
+
+// Includes reports whether results of the given type were requested
+func (p FullTextSearchParams) Includes(resultType ResultType) bool {
+	for _, t := range p.Types {
+		if t == resultType {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseFullTextSearchParams parses the q, types and limit query parameters,
+// searching every result type when types is omitted
+func ParseFullTextSearchParams(query url.Values, policy types.LimitPolicy) (FullTextSearchParams, error) {
+	searchParams, err := types.ParseAndValidateSearchParams(query, policy)
+	if err != nil {
+		return FullTextSearchParams{}, err
+	}
+
+	params := FullTextSearchParams{
+		Query: searchParams.Query,
+		Limit: searchParams.Limit,
+	}
+
+	resultTypes, err := parseResultTypes(query.Get("types"))
+	if err != nil {
+		return FullTextSearchParams{}, err
+	}
+	params.Types = resultTypes
+
+	return params, validation.Errors{
+		"q": validation.Validate(params.Query, validation.Required),
+	}.Filter()
+}
+
+// parseResultTypes parses a comma separated list of result types, ignoring repeats
+func parseResultTypes(value string) ([]ResultType, error) {
+	if strings.TrimSpace(value) == "" {
+		return ResultTypes, nil
+	}
+
+	var result []ResultType
+	seen := make(map[ResultType]bool)
+	for _, part := range strings.Split(value, ",") {
+		resultType := ResultType(strings.ToLower(strings.TrimSpace(part)))
+		if !isResultType(resultType) {
+			return nil, fmt.Errorf("types: unsupported type %q, must be one of notes, projects", part)
+		}
+		if !seen[resultType] {
+			seen[resultType] = true
+			result = append(result, resultType)
+		}
+	}
+	return result, nil
+}
+
+func isResultType(resultType ResultType) bool {
+	for _, t := range ResultTypes {
+		if t == resultType {
+			return true
+		}
+	}
+	return false
+}
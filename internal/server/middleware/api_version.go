@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/version"
+)
+
+// APIVersion names the version of the server in the response headers, set before
+// anything else runs so errors and panics carry it too
+func (m *Middleware) APIVersion(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(version.Header, version.Version)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/Abdelrahman-habib/expense-tracker/config"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/version"
+	versionRoutes "github.com/Abdelrahman-habib/expense-tracker/internal/version/routes"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAPIVersion(t *testing.T) {
+	defer func(v, commit, buildTime string) {
+		version.Version, version.Commit, version.BuildTime = v, commit, buildTime
+	}(version.Version, version.Commit, version.BuildTime)
+	version.Version, version.Commit, version.BuildTime = "v1.2.0", "707a497", "2024-01-01T00:00:00Z"
+
+	m := NewMiddleware(zap.NewNop(), nil, nil, config.ServerConfig{}, nil)
+	base := handlers.NewBaseHandler(zap.NewNop())
+	r := chi.NewRouter()
+	r.Use(m.APIVersion)
+	r.NotFound(handlers.NotFound)
+	versionRoutes.New(zap.NewNop()).RegisterRoutes(r)
+	r.Get("/fails", func(w http.ResponseWriter, r *http.Request) {
+		base.HandleServiceError(w, r, fmt.Errorf("connection reset"))
+	})
+
+	serve := func(target string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+		return w, body
+	}
+
+	t.Run("version endpoint", func(t *testing.T) {
+		w, body := serve("/version")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "v1.2.0", w.Header().Get(version.Header))
+		assert.Equal(t, map[string]interface{}{
+			"version":   "v1.2.0",
+			"commit":    "707a497",
+			"buildTime": "2024-01-01T00:00:00Z",
+			"goVersion": runtime.Version(),
+		}, body["data"])
+	})
+
+	t.Run("client errors carry the header but no meta", func(t *testing.T) {
+		w, body := serve("/missing")
+		require.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "v1.2.0", w.Header().Get(version.Header))
+		assert.NotContains(t, body, "meta")
+	})
+
+	t.Run("server errors name the version in meta", func(t *testing.T) {
+		w, body := serve("/fails")
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, "v1.2.0", w.Header().Get(version.Header))
+		assert.Equal(t, map[string]interface{}{"version": "v1.2.0"}, body["meta"])
+	})
+}
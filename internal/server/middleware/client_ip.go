@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"go.uber.org/zap"
+)
+
+// parseTrustedProxies parses the configured proxy CIDRs, a bare address is trusted on its own.
+// Invalid entries are skipped so a typo never widens what is trusted
+func parseTrustedProxies(entries []string, logger *zap.Logger) []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		if addr, err := netip.ParseAddr(entry); err == nil {
+			addr = addr.Unmap()
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		logger.Warn("ignoring invalid trusted proxy", zap.String("entry", entry))
+	}
+	return prefixes
+}
+
+// ClientIP resolves the address of the client behind any trusted proxies and stores it in the request context.
+// X-Forwarded-For is only read when the direct peer is a trusted proxy, and is walked from the right
+// so hops prepended by the client can't be used to spoof the address. Proxies that only send
+// X-Real-IP are honoured the same way when X-Forwarded-For is missing
+func (m *Middleware) ClientIP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := resolveClientIP(r.RemoteAddr, r.Header.Values("X-Forwarded-For"), r.Header.Get("X-Real-IP"), m.trustedProxies)
+		ctx := context.WithValue(r.Context(), requestcontext.ClientIPKey, ip)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func resolveClientIP(remoteAddr string, forwardedFor []string, realIP string, trusted []netip.Prefix) string {
+	peer, ok := parseHop(remoteAddr)
+	if !ok {
+		host, _, err := net.SplitHostPort(remoteAddr)
+		if err != nil {
+			return remoteAddr
+		}
+		return host
+	}
+	if !isTrusted(peer, trusted) {
+		return peer.String()
+	}
+
+	var hops []string
+	for _, value := range forwardedFor {
+		hops = append(hops, strings.Split(value, ",")...)
+	}
+	if len(hops) == 0 {
+		// X-Real-IP carries the single address the trusted proxy saw
+		if addr, ok := parseHop(realIP); ok {
+			return addr.String()
+		}
+		return peer.String()
+	}
+
+	client := peer
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, ok := parseHop(hops[i])
+		if !ok {
+			// anything left of a malformed hop can't be attributed, the last trusted proxy is the best we know
+			break
+		}
+		client = hop
+		if !isTrusted(hop, trusted) {
+			break
+		}
+	}
+	return client.String()
+}
+
+// parseHop parses an address as found in RemoteAddr or X-Forwarded-For, with or without a port
+func parseHop(value string) (netip.Addr, bool) {
+	value = strings.TrimSpace(value)
+	if addr, err := netip.ParseAddr(value); err == nil {
+		return addr.Unmap().WithZone(""), true
+	}
+	if addrPort, err := netip.ParseAddrPort(value); err == nil {
+		return addrPort.Addr().Unmap().WithZone(""), true
+	}
+	return netip.Addr{}, false
+}
+
+func isTrusted(addr netip.Addr, trusted []netip.Prefix) bool {
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP returns the resolved client address, falling back to the direct peer
+func clientIP(r *http.Request) string {
+	if ip, err := requestcontext.GetClientIPFromContext(r.Context()); err == nil {
+		return ip
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// rateLimitKey buckets requests by client address, IPv6 clients are grouped by their /64
+// since a single host usually controls the whole subnet
+func rateLimitKey(r *http.Request) (string, error) {
+	ip := clientIP(r)
+	addr, err := netip.ParseAddr(ip)
+	if err != nil || !addr.Is6() {
+		return ip, nil
+	}
+	return netip.PrefixFrom(addr, 64).Masked().String(), nil
+}
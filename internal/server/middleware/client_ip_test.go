@@ -0,0 +1,198 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Abdelrahman-habib/expense-tracker/config"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestResolveClientIP(t *testing.T) {
+	trusted := parseTrustedProxies([]string{"10.0.0.0/8", "fd00::/8", "192.168.1.5"}, zap.NewNop())
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		realIP       string
+		expected     string
+	}{
+		{
+			name:       "direct client",
+			remoteAddr: "203.0.113.7:51234",
+			expected:   "203.0.113.7",
+		},
+		{
+			name:         "spoofed header from untrusted peer",
+			remoteAddr:   "203.0.113.7:51234",
+			forwardedFor: []string{"1.2.3.4"},
+			expected:     "203.0.113.7",
+		},
+		{
+			name:         "single trusted proxy",
+			remoteAddr:   "10.0.0.4:443",
+			forwardedFor: []string{"198.51.100.20"},
+			expected:     "198.51.100.20",
+		},
+		{
+			name:         "client prepended hops are ignored",
+			remoteAddr:   "10.0.0.4:443",
+			forwardedFor: []string{"1.2.3.4, 198.51.100.20"},
+			expected:     "198.51.100.20",
+		},
+		{
+			name:         "multiple proxy hops",
+			remoteAddr:   "10.0.0.4:443",
+			forwardedFor: []string{"1.2.3.4, 198.51.100.20, 192.168.1.5, 10.1.2.3"},
+			expected:     "198.51.100.20",
+		},
+		{
+			name:         "hops split across headers",
+			remoteAddr:   "10.0.0.4:443",
+			forwardedFor: []string{"198.51.100.20", "10.1.2.3"},
+			expected:     "198.51.100.20",
+		},
+		{
+			name:         "every hop trusted",
+			remoteAddr:   "10.0.0.4:443",
+			forwardedFor: []string{"10.9.9.9, 10.1.2.3"},
+			expected:     "10.9.9.9",
+		},
+		{
+			name:       "no header from trusted proxy",
+			remoteAddr: "10.0.0.4:443",
+			expected:   "10.0.0.4",
+		},
+		{
+			name:         "ipv6 peer and client",
+			remoteAddr:   "[fd00::1]:443",
+			forwardedFor: []string{"2001:db8::42"},
+			expected:     "2001:db8::42",
+		},
+		{
+			name:         "ipv6 hop with port",
+			remoteAddr:   "[fd00::1]:443",
+			forwardedFor: []string{"[2001:db8::42]:8080"},
+			expected:     "2001:db8::42",
+		},
+		{
+			name:         "ipv4 mapped ipv6 peer",
+			remoteAddr:   "[::ffff:10.0.0.4]:443",
+			forwardedFor: []string{"198.51.100.20"},
+			expected:     "198.51.100.20",
+		},
+		{
+			name:         "untrusted ipv6 peer",
+			remoteAddr:   "[2001:db8::1]:443",
+			forwardedFor: []string{"198.51.100.20"},
+			expected:     "2001:db8::1",
+		},
+		{
+			name:         "malformed hop stops the walk",
+			remoteAddr:   "10.0.0.4:443",
+			forwardedFor: []string{"198.51.100.20, not-an-ip, 10.1.2.3"},
+			expected:     "10.1.2.3",
+		},
+		{
+			name:         "malformed header",
+			remoteAddr:   "10.0.0.4:443",
+			forwardedFor: []string{"<script>"},
+			expected:     "10.0.0.4",
+		},
+		{
+			name:         "empty hops",
+			remoteAddr:   "10.0.0.4:443",
+			forwardedFor: []string{",,"},
+			expected:     "10.0.0.4",
+		},
+		{
+			name:       "real ip from trusted proxy",
+			remoteAddr: "10.0.0.4:443",
+			realIP:     "198.51.100.20",
+			expected:   "198.51.100.20",
+		},
+		{
+			name:       "spoofed real ip from untrusted peer",
+			remoteAddr: "203.0.113.7:51234",
+			realIP:     "1.2.3.4",
+			expected:   "203.0.113.7",
+		},
+		{
+			name:         "forwarded for wins over real ip",
+			remoteAddr:   "10.0.0.4:443",
+			forwardedFor: []string{"198.51.100.20"},
+			realIP:       "1.2.3.4",
+			expected:     "198.51.100.20",
+		},
+		{
+			name:       "malformed real ip",
+			remoteAddr: "10.0.0.4:443",
+			realIP:     "<script>",
+			expected:   "10.0.0.4",
+		},
+		{
+			name:       "unparsable remote address",
+			remoteAddr: "pipe",
+			expected:   "pipe",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, resolveClientIP(tt.remoteAddr, tt.forwardedFor, tt.realIP, trusted))
+		})
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	prefixes := parseTrustedProxies([]string{"10.0.0.0/8", " 172.16.5.4 ", "2001:db8::/32", "bogus", "10.0.0.0/99"}, zap.NewNop())
+
+	require.Len(t, prefixes, 3)
+	assert.Equal(t, "10.0.0.0/8", prefixes[0].String())
+	assert.Equal(t, "172.16.5.4/32", prefixes[1].String())
+	assert.Equal(t, "2001:db8::/32", prefixes[2].String())
+}
+
+func TestMiddleware_ClientIP(t *testing.T) {
+	cfg := config.ServerConfig{}
+	cfg.Middleware.TrustedProxies = []string{"10.0.0.0/8"}
+	m := NewMiddleware(zap.NewNop(), nil, nil, cfg, nil)
+
+	var resolved string
+	handler := m.ClientIP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip, err := requestcontext.GetClientIPFromContext(r.Context())
+		require.NoError(t, err)
+		resolved = ip
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.4:443"
+	req.Header.Set("X-Forwarded-For", "198.51.100.20")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "198.51.100.20", resolved)
+}
+
+func TestRateLimitKey(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.4:443"
+
+	key, err := rateLimitKey(req)
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.4", key, "falls back to the peer without the middleware")
+
+	req = req.WithContext(context.WithValue(req.Context(), requestcontext.ClientIPKey, "198.51.100.20"))
+	key, err = rateLimitKey(req)
+	require.NoError(t, err)
+	assert.Equal(t, "198.51.100.20", key)
+
+	req = req.WithContext(context.WithValue(req.Context(), requestcontext.ClientIPKey, "2001:db8::42"))
+	key, err = rateLimitKey(req)
+	require.NoError(t, err)
+	assert.Equal(t, "2001:db8::/64", key)
+}
@@ -0,0 +1,280 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	encodingGzip    = "gzip"
+	encodingDeflate = "deflate"
+
+	// DefaultCompressionMinSize is used when no threshold is configured
+	DefaultCompressionMinSize = 1024
+)
+
+// incompressibleTypes are content types that are already compressed
+var incompressibleTypes = []string{
+	"image/",
+	"video/",
+	"audio/",
+	"application/zip",
+	"application/gzip",
+	"application/x-gzip",
+	"application/pdf",
+	"application/octet-stream",
+}
+
+// streamingTypes are content types written incrementally, they are only compressed
+// when the underlying writer can be flushed
+var streamingTypes = []string{
+	"text/csv",
+	"text/event-stream",
+	"application/x-ndjson",
+}
+
+var gzipWriterPool = sync.Pool{
+	New: func() interface{} {
+		return gzip.NewWriter(io.Discard)
+	},
+}
+
+var zlibWriterPool = sync.Pool{
+	New: func() interface{} {
+		return zlib.NewWriter(io.Discard)
+	},
+}
+
+// Compress compresses responses with gzip or deflate based on the request's Accept-Encoding
+func (m *Middleware) Compress(next http.Handler) http.Handler {
+	minSize := m.config.Compression.MinSize
+	if minSize <= 0 {
+		minSize = DefaultCompressionMinSize
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{
+			ResponseWriter: w,
+			encoding:       encoding,
+			minSize:        minSize,
+			status:         http.StatusOK,
+		}
+		defer cw.Close()
+
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// negotiateEncoding picks the preferred supported encoding from an Accept-Encoding header
+func negotiateEncoding(header string) string {
+	qualities := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		qualities[name] = q
+	}
+
+	best, bestQ := "", 0.0
+	for _, encoding := range []string{encodingGzip, encodingDeflate} {
+		q, ok := qualities[encoding]
+		if !ok {
+			q, ok = qualities["*"]
+		}
+		if ok && q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+	return best
+}
+
+// compressWriter buffers the start of a response until it knows whether compressing it is worthwhile
+type compressWriter struct {
+	http.ResponseWriter
+	encoding   string
+	minSize    int
+	status     int
+	buf        []byte
+	decided    bool
+	compressor io.WriteCloser
+}
+
+func (cw *compressWriter) WriteHeader(code int) {
+	if cw.decided {
+		return
+	}
+	cw.status = code
+
+	// responses without a body go straight through
+	if code < http.StatusOK || code == http.StatusNoContent || code == http.StatusNotModified {
+		cw.passthrough()
+	}
+}
+
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	if !cw.decided {
+		if !cw.compressible() {
+			cw.passthrough()
+		} else if length := cw.Header().Get("Content-Length"); length != "" {
+			if n, err := strconv.Atoi(length); err == nil && n < cw.minSize {
+				cw.passthrough()
+			}
+		}
+	}
+
+	if cw.decided {
+		if cw.compressor != nil {
+			return cw.compressor.Write(b)
+		}
+		return cw.ResponseWriter.Write(b)
+	}
+
+	cw.buf = append(cw.buf, b...)
+	if len(cw.buf) >= cw.minSize {
+		if err := cw.startCompression(); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// Flush sends buffered data to the client, streamed responses are compressed as they are flushed
+func (cw *compressWriter) Flush() {
+	flusher, canFlush := cw.ResponseWriter.(http.Flusher)
+	if !cw.decided {
+		if canFlush && cw.compressible() {
+			cw.startCompression()
+		} else {
+			cw.passthrough()
+		}
+	}
+
+	if flushable, ok := cw.compressor.(interface{ Flush() error }); ok {
+		flushable.Flush()
+	}
+	if canFlush {
+		flusher.Flush()
+	}
+}
+
+// Close writes out anything still buffered and finishes the compressed stream
+func (cw *compressWriter) Close() error {
+	if !cw.decided {
+		cw.passthrough()
+	}
+	if cw.compressor == nil {
+		return nil
+	}
+
+	err := cw.compressor.Close()
+	switch c := cw.compressor.(type) {
+	case *gzip.Writer:
+		gzipWriterPool.Put(c)
+	case *zlib.Writer:
+		zlibWriterPool.Put(c)
+	}
+	cw.compressor = nil
+	return err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// compressible reports whether the response headers allow compressing the body
+func (cw *compressWriter) compressible() bool {
+	header := cw.Header()
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+	if disposition, _, err := mime.ParseMediaType(header.Get("Content-Disposition")); err == nil && disposition == "attachment" {
+		return false
+	}
+
+	contentType := header.Get("Content-Type")
+	if contentType == "" && len(cw.buf) > 0 {
+		contentType = http.DetectContentType(cw.buf)
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	for _, prefix := range incompressibleTypes {
+		if strings.HasPrefix(mediaType, prefix) {
+			return false
+		}
+	}
+	for _, streaming := range streamingTypes {
+		if mediaType == streaming {
+			_, canFlush := cw.ResponseWriter.(http.Flusher)
+			return canFlush
+		}
+	}
+	return true
+}
+
+func (cw *compressWriter) startCompression() error {
+	cw.decided = true
+
+	header := cw.Header()
+	if header.Get("Content-Type") == "" && len(cw.buf) > 0 {
+		// sniff before the body is compressed, net/http would otherwise sniff the compressed bytes
+		header.Set("Content-Type", http.DetectContentType(cw.buf))
+	}
+	header.Del("Content-Length")
+	header.Set("Content-Encoding", cw.encoding)
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	switch cw.encoding {
+	case encodingGzip:
+		gz := gzipWriterPool.Get().(*gzip.Writer)
+		gz.Reset(cw.ResponseWriter)
+		cw.compressor = gz
+	default:
+		zw := zlibWriterPool.Get().(*zlib.Writer)
+		zw.Reset(cw.ResponseWriter)
+		cw.compressor = zw
+	}
+
+	buf := cw.buf
+	cw.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	_, err := cw.compressor.Write(buf)
+	return err
+}
+
+func (cw *compressWriter) passthrough() {
+	cw.decided = true
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	buf := cw.buf
+	cw.buf = nil
+	if len(buf) > 0 {
+		cw.ResponseWriter.Write(buf)
+	}
+}
@@ -0,0 +1,271 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/Abdelrahman-habib/expense-tracker/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// largeJSON builds a paginated-style payload well above the compression threshold
+func largeJSON(t *testing.T) []byte {
+	contacts := make([]map[string]string, 100)
+	for i := range contacts {
+		contacts[i] = map[string]string{
+			"name":         fmt.Sprintf("Contact %d", i),
+			"addressLine1": "123 Main Street",
+			"city":         "Springfield",
+			"country":      "US",
+		}
+	}
+	body, err := json.Marshal(map[string]interface{}{"data": contacts})
+	require.NoError(t, err)
+	return body
+}
+
+// compress wraps next in the compression middleware configured with cfg
+func compress(cfg config.CompressionConfig, next http.Handler) http.Handler {
+	return NewMiddleware(zap.NewNop(), nil, nil, config.ServerConfig{Compression: cfg}, nil).Compress(next)
+}
+
+func serve(handler http.HandlerFunc, acceptEncoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/contacts", nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	w := httptest.NewRecorder()
+	compress(config.CompressionConfig{}, handler).ServeHTTP(w, req)
+	return w
+}
+
+func writeBody(contentType string, body []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.Write(body)
+	}
+}
+
+func TestCompress_Encodings(t *testing.T) {
+	body := largeJSON(t)
+
+	tests := []struct {
+		name             string
+		acceptEncoding   string
+		expectedEncoding string
+		decode           func(io.Reader) (io.Reader, error)
+	}{
+		{
+			name:             "gzip",
+			acceptEncoding:   "gzip",
+			expectedEncoding: "gzip",
+			decode:           func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+		},
+		{
+			name:             "deflate",
+			acceptEncoding:   "deflate",
+			expectedEncoding: "deflate",
+			decode:           func(r io.Reader) (io.Reader, error) { return zlib.NewReader(r) },
+		},
+		{
+			name:             "gzip preferred",
+			acceptEncoding:   "deflate, gzip",
+			expectedEncoding: "gzip",
+			decode:           func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+		},
+		{
+			name:             "quality values honored",
+			acceptEncoding:   "gzip;q=0.2, deflate;q=0.8",
+			expectedEncoding: "deflate",
+			decode:           func(r io.Reader) (io.Reader, error) { return zlib.NewReader(r) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(writeBody("application/json", body), tt.acceptEncoding)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.expectedEncoding, w.Header().Get("Content-Encoding"))
+			assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+			assert.Empty(t, w.Header().Get("Content-Length"))
+			assert.Less(t, w.Body.Len(), len(body)/4, "compressed body should be much smaller")
+
+			reader, err := tt.decode(w.Body)
+			require.NoError(t, err)
+			decoded, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, body, decoded)
+		})
+	}
+}
+
+func TestCompress_SkipRules(t *testing.T) {
+	body := largeJSON(t)
+
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		handler        http.HandlerFunc
+		expectedBody   []byte
+	}{
+		{
+			name:         "no accept encoding",
+			handler:      writeBody("application/json", body),
+			expectedBody: body,
+		},
+		{
+			name:           "unsupported encoding",
+			acceptEncoding: "br",
+			handler:        writeBody("application/json", body),
+			expectedBody:   body,
+		},
+		{
+			name:           "gzip refused",
+			acceptEncoding: "gzip;q=0, *;q=0",
+			handler:        writeBody("application/json", body),
+			expectedBody:   body,
+		},
+		{
+			name:           "small body",
+			acceptEncoding: "gzip",
+			handler:        writeBody("application/json", []byte(`{"status":200}`)),
+			expectedBody:   []byte(`{"status":200}`),
+		},
+		{
+			name:           "small body written in pieces",
+			acceptEncoding: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"status":`))
+				w.Write([]byte(`200}`))
+			},
+			expectedBody: []byte(`{"status":200}`),
+		},
+		{
+			name:           "image",
+			acceptEncoding: "gzip",
+			handler:        writeBody("image/png", body),
+			expectedBody:   body,
+		},
+		{
+			name:           "attachment",
+			acceptEncoding: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Disposition", `attachment; filename="receipt.json"`)
+				writeBody("application/json", body)(w, r)
+			},
+			expectedBody: body,
+		},
+		{
+			name:           "already encoded",
+			acceptEncoding: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Encoding", "br")
+				writeBody("application/json", body)(w, r)
+			},
+			expectedBody: body,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(tt.handler, tt.acceptEncoding)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.NotEqual(t, "gzip", w.Header().Get("Content-Encoding"))
+			assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+			assert.Equal(t, tt.expectedBody, w.Body.Bytes())
+		})
+	}
+}
+
+func TestCompress_StatusWithoutBody(t *testing.T) {
+	w := serve(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}, "gzip")
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Zero(t, w.Body.Len())
+}
+
+func TestCompress_KeepsStatus(t *testing.T) {
+	body := largeJSON(t)
+	w := serve(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write(body)
+	}, "gzip")
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+}
+
+// nonFlushingWriter hides the recorder's Flush method
+type nonFlushingWriter struct {
+	http.ResponseWriter
+}
+
+func TestCompress_StreamingCSV(t *testing.T) {
+	rows := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/csv")
+		for i := 0; i < 200; i++ {
+			fmt.Fprintf(w, "%d,Contact %d,contact%d@example.com\n", i, i, i)
+			if i%50 == 0 {
+				http.NewResponseController(w).Flush()
+			}
+		}
+	}
+
+	t.Run("compressed when flushable", func(t *testing.T) {
+		w := serve(rows, "gzip")
+
+		assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+		assert.True(t, w.Flushed)
+
+		reader, err := gzip.NewReader(w.Body)
+		require.NoError(t, err)
+		decoded, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(decoded, []byte("0,Contact 0,contact0@example.com\n")))
+		assert.Equal(t, 200, bytes.Count(decoded, []byte("\n")))
+	})
+
+	t.Run("skipped when the writer can't flush", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/contacts/export", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		recorder := httptest.NewRecorder()
+		compress(config.CompressionConfig{}, http.HandlerFunc(rows)).ServeHTTP(nonFlushingWriter{recorder}, req)
+
+		assert.Empty(t, recorder.Header().Get("Content-Encoding"))
+		assert.Equal(t, 200, bytes.Count(recorder.Body.Bytes(), []byte("\n")))
+	})
+}
+
+func TestCompress_Threshold(t *testing.T) {
+	body := bytes.Repeat([]byte("a"), 600)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	w := httptest.NewRecorder()
+	compress(config.CompressionConfig{}, writeBody("text/plain", body)).ServeHTTP(w, req)
+	assert.Empty(t, w.Header().Get("Content-Encoding"), "below the default threshold")
+	assert.Equal(t, "600", w.Header().Get("Content-Length"))
+
+	w = httptest.NewRecorder()
+	compress(config.CompressionConfig{MinSize: 512}, writeBody("text/plain", body)).ServeHTTP(w, req)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"), "above the configured threshold")
+	assert.Empty(t, w.Header().Get("Content-Length"))
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/go-chi/render"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// overloadRetryAfter is the Retry-After in seconds of a shed request
+const overloadRetryAfter = 1
+
+// InFlightLimiter caps how many requests are served at once. Requests past the cap are
+// answered with 503 right away, so under overload the pool isn't exhausted and requests
+// don't pile up waiting for a connection until they time out.
+type InFlightLimiter struct {
+	slots    chan struct{}
+	rejected atomic.Int64
+	logger   *zap.Logger
+}
+
+// NewInFlightLimiter creates a limiter serving up to size requests at once, a size of 0
+// or less lets every request through
+func NewInFlightLimiter(size int, logger *zap.Logger) *InFlightLimiter {
+	limiter := &InFlightLimiter{logger: logger}
+	if size > 0 {
+		limiter.slots = make(chan struct{}, size)
+	}
+	return limiter
+}
+
+// Limit serves the request if a slot is free and sheds it with 503 otherwise
+func (l *InFlightLimiter) Limit(next http.Handler) http.Handler {
+	if l.slots == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case l.slots <- struct{}{}:
+		default:
+			rejected := l.rejected.Add(1)
+			trace.SpanFromContext(r.Context()).AddEvent("request shed")
+			l.logger.Warn("request shed, too many requests in flight",
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
+				zap.Int("limit", cap(l.slots)),
+				zap.Int64("rejected", rejected))
+
+			w.Header().Set("Retry-After", strconv.Itoa(overloadRetryAfter))
+			render.Render(w, r, errors.ErrOverloaded(fmt.Errorf("too many requests in flight, retry later")))
+			return
+		}
+		defer func() { <-l.slots }()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// InFlight returns how many requests are being served
+func (l *InFlightLimiter) InFlight() int {
+	return len(l.slots)
+}
+
+// Rejected returns how many requests were shed since the limiter was created
+func (l *InFlightLimiter) Rejected() int64 {
+	return l.rejected.Load()
+}
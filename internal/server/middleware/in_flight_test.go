@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestInFlightLimiter(t *testing.T) {
+	t.Run("sheds requests past the limit", func(t *testing.T) {
+		limiter := NewInFlightLimiter(2, zap.NewNop())
+		entered, release := make(chan struct{}), make(chan struct{})
+		handler := limiter.Limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			entered <- struct{}{}
+			<-release
+			w.WriteHeader(http.StatusNoContent)
+		}))
+
+		done := make(chan int, 2)
+		for range 2 {
+			go func() {
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/wallets", nil))
+				done <- w.Code
+			}()
+			<-entered
+		}
+		assert.Equal(t, 2, limiter.InFlight())
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/wallets", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "1", w.Header().Get("Retry-After"))
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.Equal(t, "OVERLOADED", response["type"])
+		assert.Equal(t, int64(1), limiter.Rejected())
+
+		close(release)
+		assert.Equal(t, http.StatusNoContent, <-done)
+		assert.Equal(t, http.StatusNoContent, <-done)
+		assert.Equal(t, 0, limiter.InFlight())
+
+		// the freed slots serve new requests
+		go func() { <-entered }()
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/wallets", nil))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, int64(1), limiter.Rejected())
+	})
+
+	t.Run("no limit lets every request through", func(t *testing.T) {
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+		limiter := NewInFlightLimiter(0, zap.NewNop())
+		w := httptest.NewRecorder()
+		limiter.Limit(next).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, int64(0), limiter.Rejected())
+	})
+}
+
+func TestMiddleware_LongLived(t *testing.T) {
+	cfg := config.ServerConfig{}
+	cfg.Middleware.MaxInFlight = 1
+	m := NewMiddleware(zap.NewNop(), nil, nil, cfg, nil)
+	m.LongLived("/api/v1/events/stream")
+
+	entered, release := make(chan struct{}), make(chan struct{})
+	handler := m.Timeout(20 * time.Millisecond)(m.InFlightLimit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/events/stream" {
+			// outlives the timeout with its context intact
+			time.Sleep(50 * time.Millisecond)
+			assert.NoError(t, r.Context().Err())
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		entered <- struct{}{}
+		<-release
+	})))
+
+	// a regular request holds the only slot until it times out
+	done := make(chan int)
+	go func() {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/wallets", nil))
+		done <- w.Code
+	}()
+	<-entered
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/events/stream", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	assert.Equal(t, http.StatusGatewayTimeout, <-done)
+	close(release)
+}
@@ -3,14 +3,21 @@ package middleware
 import (
 	"context"
 	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/Abdelrahman-habib/expense-tracker/config"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/auth/service"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/cache"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
 	userService "github.com/Abdelrahman-habib/expense-tracker/internal/users/service"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/version"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
 	"github.com/go-chi/httprate"
 	"go.uber.org/zap"
@@ -23,6 +30,13 @@ type Middleware struct {
 	config      config.ServerConfig
 	userService userService.UsersService
 	cache       interface{}
+
+	trustedProxies []netip.Prefix
+	inFlight       *InFlightLimiter
+	// longLived are the paths of streams kept open for as long as the client listens
+	longLived map[string]bool
+	// routeTimeouts are the request timeouts of the routes overriding the default, by pattern
+	routeTimeouts map[string]time.Duration
 }
 
 var responseWriterPool = sync.Pool{
@@ -40,13 +54,53 @@ func NewMiddleware(logger *zap.Logger, auth service.Service, db db.Service, conf
 		db:     db,
 		config: config,
 		cache:  cache,
+
+		trustedProxies: parseTrustedProxies(config.Middleware.TrustedProxies, logger),
+		inFlight:       NewInFlightLimiter(config.Middleware.MaxInFlight, logger),
+		longLived:      make(map[string]bool),
+		routeTimeouts:  routeTimeouts(config.RouteTimeouts),
 	}
 }
 
-// Timeout middleware cancels the context after the specified duration
-func (m *Middleware) Timeout(timeout time.Duration) func(next http.Handler) http.Handler {
+// routeTimeouts indexes the route timeout overrides by pattern, without the trailing
+// slash chi leaves out of the patterns it matches
+func routeTimeouts(routes []config.RouteTimeout) map[string]time.Duration {
+	timeouts := make(map[string]time.Duration, len(routes))
+	for _, route := range routes {
+		pattern := route.Pattern
+		if pattern != "/" {
+			pattern = strings.TrimSuffix(pattern, "/")
+		}
+		timeouts[pattern] = route.Timeout
+	}
+	return timeouts
+}
+
+// LongLived exempts the requests to path from the request timeout and the in-flight
+// limit, for streams kept open for as long as the client listens. It is set up along
+// with the routes, before the server starts.
+func (m *Middleware) LongLived(path string) {
+	m.longLived[path] = true
+}
+
+// Timeout middleware cancels the context after the specified duration, or after the
+// timeout configured for the route the request matches. A route given longer than the
+// default also gets its write deadline pushed back, the server's write timeout would
+// cut its response short otherwise.
+func (m *Middleware) Timeout(defaultTimeout time.Duration) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if m.longLived[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			timeout := m.routeTimeout(r, defaultTimeout)
+			if timeout > defaultTimeout {
+				// writers without deadlines, like recorders in tests, have nothing to extend
+				_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(timeout))
+			}
+
 			ctx, cancel := context.WithTimeout(r.Context(), timeout)
 			defer cancel()
 
@@ -81,6 +135,27 @@ func (m *Middleware) Timeout(timeout time.Duration) func(next http.Handler) http
 	}
 }
 
+// routeTimeout returns the timeout configured for the route r matches, defaultTimeout
+// when none is. The middleware runs ahead of routing, so the route is looked up in the
+// router the request came through.
+func (m *Middleware) routeTimeout(r *http.Request, defaultTimeout time.Duration) time.Duration {
+	if len(m.routeTimeouts) == 0 {
+		return defaultTimeout
+	}
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil || rctx.Routes == nil {
+		return defaultTimeout
+	}
+	match := chi.NewRouteContext()
+	if !rctx.Routes.Match(match, r.Method, r.URL.Path) {
+		return defaultTimeout
+	}
+	if timeout, ok := m.routeTimeouts[match.RoutePattern()]; ok {
+		return timeout
+	}
+	return defaultTimeout
+}
+
 // Logger logs request details
 func (m *Middleware) Logger(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -100,19 +175,19 @@ func (m *Middleware) Logger(next http.Handler) http.Handler {
 			zap.String("path", r.URL.Path),
 			zap.Int("status", writer.status),
 			zap.Duration("duration", time.Since(start)),
-			zap.String("ip", r.RemoteAddr),
+			zap.String("ip", clientIP(r)),
 			zap.String("user-agent", r.UserAgent()),
 		)
 	})
 }
 
-// CORS sets up CORS headers
+// CORS sets up CORS headers, the version header is always exposed as every response carries it
 func (m *Middleware) CORS() func(http.Handler) http.Handler {
 	return cors.Handler(cors.Options{
 		AllowedOrigins:   m.config.Middleware.AllowedOrigins,
 		AllowedMethods:   m.config.Middleware.AllowedMethods,
 		AllowedHeaders:   m.config.Middleware.AllowedHeaders,
-		ExposedHeaders:   m.config.Middleware.ExposedHeaders,
+		ExposedHeaders:   append(slices.Clone(m.config.Middleware.ExposedHeaders), version.Header),
 		AllowCredentials: m.config.Middleware.AllowCredentials,
 		MaxAge:           m.config.Middleware.MaxAge,
 	})
@@ -120,12 +195,31 @@ func (m *Middleware) CORS() func(http.Handler) http.Handler {
 
 // RateLimiter implements rate limiting
 func (m *Middleware) RateLimiter(next http.Handler) http.Handler {
-	return httprate.LimitByIP(
+	return httprate.Limit(
 		m.config.Middleware.RateLimit.RequestsPerMinute,
 		m.config.Middleware.RateLimit.WindowLength,
+		httprate.WithKeyFuncs(rateLimitKey),
 	)(next)
 }
 
+// InFlightLimit sheds requests with 503 while MaxInFlight of them are being served, the
+// long lived streams don't take a slot
+func (m *Middleware) InFlightLimit(next http.Handler) http.Handler {
+	limited := m.inFlight.Limit(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if m.longLived[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		limited.ServeHTTP(w, r)
+	})
+}
+
+// InFlight returns the limiter of InFlightLimit, its counts tell how loaded the server is
+func (m *Middleware) InFlight() *InFlightLimiter {
+	return m.inFlight
+}
+
 // Recovery handles panics
 func (m *Middleware) Recovery(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -142,6 +236,54 @@ func (m *Middleware) Recovery(next http.Handler) http.Handler {
 	})
 }
 
+// QueryParams tells handlers whether to reject or warn about query parameters they don't
+// recognize, and collects the warnings for the response meta
+func (m *Middleware) QueryParams(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), requestcontext.QueryParamsModeKey, string(m.config.QueryParamsMode))
+		ctx = requestcontext.WithWarnings(ctx)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// EmptyUpdates tells update handlers whether to refuse bodies naming no field
+func (m *Middleware) EmptyUpdates(next http.Handler) http.Handler {
+	if !m.config.RejectEmptyUpdates {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), requestcontext.RejectEmptyUpdatesKey, true)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// SearchWindow passes the configured search window on to search handlers, a window of 0
+// or less is passed on too so the handlers leave the pagination unbounded
+func (m *Middleware) SearchWindow(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), requestcontext.MaxSearchWindowKey, m.config.MaxSearchWindow)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// SearchTimeout passes the configured search timeout on to search handlers
+func (m *Middleware) SearchTimeout(next http.Handler) http.Handler {
+	if m.config.SearchTimeout <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), requestcontext.SearchTimeoutKey, m.config.SearchTimeout)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequestCache lets repositories memoize reads for the duration of the request
+func (m *Middleware) RequestCache(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(cache.WithRequestCache(r.Context())))
+	})
+}
+
 // clerk auth
 func (m *Middleware) Authenticate(next http.Handler) http.Handler {
 	return m.auth.Middleware(next)
@@ -158,6 +300,11 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap lets http.ResponseController reach the underlying writer
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // timeoutWriter wraps http.ResponseWriter to track if headers were written
 type timeoutWriter struct {
 	w       http.ResponseWriter
@@ -183,3 +330,8 @@ func (tw *timeoutWriter) WriteHeader(code int) {
 	tw.written = true
 	tw.w.WriteHeader(code)
 }
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (tw *timeoutWriter) Unwrap() http.ResponseWriter {
+	return tw.w
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/config"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestMiddleware_RouteTimeouts(t *testing.T) {
+	cfg := config.ServerConfig{RouteTimeouts: []config.RouteTimeout{
+		{Pattern: "/api/v1/contacts/export", Timeout: time.Second},
+		{Pattern: "/api/v1/search/", Timeout: time.Second},
+		{Pattern: "/api/v1/wallets/{id}", Timeout: 5 * time.Millisecond},
+	}}
+	m := NewMiddleware(zap.NewNop(), nil, nil, cfg, nil)
+
+	// every handler takes longer than the default timeout
+	slow := func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(60 * time.Millisecond):
+			w.WriteHeader(http.StatusOK)
+		case <-r.Context().Done():
+		}
+	}
+	router := chi.NewRouter()
+	router.Use(m.Timeout(30 * time.Millisecond))
+	router.Route("/api/v1", func(r chi.Router) {
+		r.Route("/contacts", func(r chi.Router) {
+			r.Get("/export", slow)
+			r.Get("/{id}", slow)
+		})
+		r.Route("/search", func(r chi.Router) {
+			r.Get("/", slow)
+		})
+		r.Get("/wallets/{id}", func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-time.After(15 * time.Millisecond):
+				w.WriteHeader(http.StatusOK)
+			case <-r.Context().Done():
+			}
+		})
+	})
+
+	tests := []struct {
+		name           string
+		target         string
+		expectedStatus int
+	}{
+		{"longer override", "/api/v1/contacts/export", http.StatusOK},
+		{"default for routes without an override", "/api/v1/contacts/123", http.StatusGatewayTimeout},
+		{"override of a subrouter index", "/api/v1/search", http.StatusOK},
+		{"shorter override", "/api/v1/wallets/123", http.StatusGatewayTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
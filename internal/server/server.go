@@ -5,54 +5,122 @@ import (
 	"net/http"
 
 	"github.com/Abdelrahman-habib/expense-tracker/config"
+	adminRoutes "github.com/Abdelrahman-habib/expense-tracker/internal/admin/routes"
 	authRoutes "github.com/Abdelrahman-habib/expense-tracker/internal/auth/routes"
+	backupRoutes "github.com/Abdelrahman-habib/expense-tracker/internal/backups/routes"
+	backupService "github.com/Abdelrahman-habib/expense-tracker/internal/backups/service"
+	budgetRoutes "github.com/Abdelrahman-habib/expense-tracker/internal/budgets/routes"
 	contactRoutes "github.com/Abdelrahman-habib/expense-tracker/internal/contacts/routes"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/blob"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/currency"
+	coreHandlers "github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/mail"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/quota"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/events"
+	eventRoutes "github.com/Abdelrahman-habib/expense-tracker/internal/events/routes"
+	exportScheduleRoutes "github.com/Abdelrahman-habib/expense-tracker/internal/exportschedules/routes"
+	inboundRoutes "github.com/Abdelrahman-habib/expense-tracker/internal/inbound/routes"
+	jobRoutes "github.com/Abdelrahman-habib/expense-tracker/internal/jobs/routes"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/jobs/worker"
+	mergeRoutes "github.com/Abdelrahman-habib/expense-tracker/internal/merges/routes"
+	phoneRoutes "github.com/Abdelrahman-habib/expense-tracker/internal/phones/routes"
 	projectRoutes "github.com/Abdelrahman-habib/expense-tracker/internal/projects/routes"
+	schemaRoutes "github.com/Abdelrahman-habib/expense-tracker/internal/schemas/routes"
+	searchRoutes "github.com/Abdelrahman-habib/expense-tracker/internal/search/routes"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/server/middleware"
 	tagRoutes "github.com/Abdelrahman-habib/expense-tracker/internal/tags/routes"
 	userRoutes "github.com/Abdelrahman-habib/expense-tracker/internal/users/routes"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/validate"
+	versionRoutes "github.com/Abdelrahman-habib/expense-tracker/internal/version/routes"
+	walletGroupRoutes "github.com/Abdelrahman-habib/expense-tracker/internal/walletgroups/routes"
 	walletRoutes "github.com/Abdelrahman-habib/expense-tracker/internal/wallets/routes"
 
 	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
 type APIServer struct {
-	config        *config.Config
-	db            db.Service
-	logger        *zap.Logger
-	middleware    *middleware.Middleware
-	authRoutes    *authRoutes.Router
-	tagRoutes     *tagRoutes.Router
-	userRoutes    *userRoutes.Router
-	projectRoutes *projectRoutes.Router
-	walletRoutes  *walletRoutes.Router
-	contactRoutes *contactRoutes.Router
+	config               *config.Config
+	db                   db.Service
+	logger               *zap.Logger
+	middleware           *middleware.Middleware
+	authRoutes           *authRoutes.Router
+	tagRoutes            *tagRoutes.Router
+	userRoutes           *userRoutes.Router
+	projectRoutes        *projectRoutes.Router
+	walletRoutes         *walletRoutes.Router
+	walletGroupRoutes    *walletGroupRoutes.Router
+	budgetRoutes         *budgetRoutes.Router
+	mergeRoutes          *mergeRoutes.Router
+	contactRoutes        *contactRoutes.Router
+	jobRoutes            *jobRoutes.Router
+	adminRoutes          *adminRoutes.Router
+	searchRoutes         *searchRoutes.Router
+	schemaRoutes         *schemaRoutes.Router
+	phoneRoutes          *phoneRoutes.Router
+	inboundRoutes        *inboundRoutes.Router
+	exportScheduleRoutes *exportScheduleRoutes.Router
+	eventRoutes          *eventRoutes.Router
+	backupRoutes         *backupRoutes.Router
+	versionRoutes        *versionRoutes.Router
 }
 
 type ServerDependencies struct {
 	Config *config.Config
 	DB     db.Service
+	Jobs   *worker.Runner
+	// Blobs keeps the files background jobs produce
+	Blobs blob.Store
+	// BackupLinks signs the links downloading the account backups
+	BackupLinks *backupService.Links
+	// Rates converts between currencies, nil refuses conversions
+	Rates currency.Converter
+	// Emails checks the email addresses of contacts, nil only suggests corrections for the default typo domains
+	Emails *validate.EmailChecker
+	// Quotas caps the contacts, projects and wallets of each user, nil leaves them unlimited
+	Quotas *quota.Checker
+	// Events fans the changes to contacts, projects and wallets out to the event streams
+	Events *events.Bus
+	// Mailer sends the scheduled exports delivered by email, nil refuses email delivery
+	Mailer mail.Mailer
 	Logger *zap.Logger
+	// Tracer records the spans of the services and repositories, nil leaves them untraced
+	Tracer trace.Tracer
 }
 
 func NewAPIServer(deps ServerDependencies) *APIServer {
 	// Create server instance
 	server := &APIServer{
-		config:        deps.Config,
-		db:            deps.DB,
-		logger:        deps.Logger,
-		authRoutes:    authRoutes.New(deps.DB.Queries(), deps.Logger, &deps.Config.Auth),
-		userRoutes:    userRoutes.New(deps.DB, deps.Logger, nil, &deps.Config.Clerk),
-		tagRoutes:     tagRoutes.New(deps.DB, deps.Logger),
-		projectRoutes: projectRoutes.New(deps.DB, deps.Logger),
-		walletRoutes:  walletRoutes.New(deps.DB, deps.Logger),
-		contactRoutes: contactRoutes.New(deps.DB, deps.Logger),
+		config:               deps.Config,
+		db:                   deps.DB,
+		logger:               deps.Logger,
+		authRoutes:           authRoutes.New(deps.DB.Queries(), deps.Logger, &deps.Config.Auth),
+		userRoutes:           userRoutes.New(deps.DB, deps.Logger, nil, &deps.Config.Clerk, deps.Config.Inbound.Domain),
+		tagRoutes:            tagRoutes.New(deps.DB, deps.Logger),
+		projectRoutes:        projectRoutes.New(deps.DB, deps.Logger, deps.Config.Cache, deps.Config.Projects, deps.Rates, deps.Config.Wallets.Rounding, deps.Quotas, deps.Events, deps.Config.Pagination.ProjectsPolicy(), deps.Tracer),
+		walletRoutes:         walletRoutes.New(deps.DB, deps.Config.Pagination.WalletsPolicy(), deps.Config.Wallets.Rounding, deps.Rates, deps.Quotas, deps.Events, deps.Logger, deps.Tracer),
+		walletGroupRoutes:    walletGroupRoutes.New(deps.DB, deps.Logger),
+		budgetRoutes:         budgetRoutes.New(deps.DB, deps.Logger),
+		mergeRoutes:          mergeRoutes.New(deps.DB, deps.Events, deps.Logger),
+		contactRoutes:        contactRoutes.New(deps.DB, deps.Jobs, deps.Blobs, deps.Config.Exports, deps.Emails, deps.Quotas, deps.Events, deps.Config.Pagination.ContactsPolicy(), deps.Logger, deps.Tracer),
+		jobRoutes:            jobRoutes.New(deps.DB, deps.Logger),
+		adminRoutes:          adminRoutes.New(deps.DB, deps.Blobs, deps.Logger, deps.Config.Admin, deps.Config.Features),
+		searchRoutes:         searchRoutes.New(deps.DB, deps.Logger, deps.Config.Features, deps.Config.Pagination.GlobalPolicy()),
+		schemaRoutes:         schemaRoutes.New(deps.Logger),
+		phoneRoutes:          phoneRoutes.New(deps.Logger),
+		inboundRoutes:        inboundRoutes.New(deps.DB, deps.Config.Inbound, deps.Config.Pagination.GlobalPolicy(), deps.Logger),
+		exportScheduleRoutes: exportScheduleRoutes.New(deps.DB, deps.Mailer, deps.Logger),
+		eventRoutes:          eventRoutes.New(deps.Events, deps.Config.Events.Heartbeat, deps.Logger),
+		versionRoutes:        versionRoutes.New(deps.Logger),
+		backupRoutes:         backupRoutes.New(deps.DB, deps.Jobs, deps.Blobs, deps.Config.Backups, deps.BackupLinks, deps.Logger),
 	}
 
 	// Initialize middleware after auth service is created
 	server.middleware = middleware.NewMiddleware(deps.Logger, server.authRoutes.GetService(), deps.DB, deps.Config.Server, nil)
+	// the event stream stays open for as long as the client listens
+	server.middleware.LongLived("/api/v1" + eventRoutes.StreamPath)
 
 	return server
 }
@@ -81,17 +149,37 @@ func (s *APIServer) RegisterRoutes() http.Handler {
 	r := chi.NewRouter()
 
 	// Global middleware
+	r.Use(s.middleware.APIVersion)
+	r.Use(s.middleware.ClientIP)
+	r.Use(s.middleware.Compress)
 	r.Use(s.middleware.Timeout(s.config.Server.RequestTimeout))
 	r.Use(s.middleware.Recovery)
 	r.Use(s.middleware.Logger)
 	r.Use(s.middleware.CORS())
 	r.Use(s.middleware.RateLimiter)
+	r.Use(s.middleware.InFlightLimit)
+	r.Use(s.middleware.QueryParams)
+	r.Use(s.middleware.SearchWindow)
+	r.Use(s.middleware.SearchTimeout)
+	r.Use(s.middleware.EmptyUpdates)
+	r.Use(s.middleware.RequestCache)
+
+	// Unmatched routes answer with the same JSON error body as the handlers, set
+	// before the routes so the mounted sub-routers inherit them
+	r.NotFound(coreHandlers.NotFound)
+	r.MethodNotAllowed(coreHandlers.MethodNotAllowed)
 
 	// Public routes
 	r.Group(func(r chi.Router) {
 		s.logger.Debug("registering public routes")
 		// Register auth routes
 		s.authRoutes.RegisterRoutes(r)
+		// Register the inbound email webhook, verified with its own secret
+		s.inboundRoutes.RegisterWebhookRoutes(r)
+		// Register the build info used to verify deploys
+		s.versionRoutes.RegisterRoutes(r)
+		// Register the backup downloads, authorized by their signed links
+		s.backupRoutes.RegisterPublicRoutes(r)
 	})
 
 	// Protected routes
@@ -107,8 +195,32 @@ func (s *APIServer) RegisterRoutes() http.Handler {
 			s.projectRoutes.RegisterRoutes(r)
 			// Register wallet Routes
 			s.walletRoutes.RegisterRoutes(r)
+			// Register wallet group Routes
+			s.walletGroupRoutes.RegisterRoutes(r)
+			// Register budget Routes
+			s.budgetRoutes.RegisterRoutes(r)
 			// Register contact Routes
 			s.contactRoutes.RegisterRoutes(r)
+			// Register the merges of contacts and wallets
+			s.mergeRoutes.RegisterRoutes(r)
+			// Register job Routes
+			s.jobRoutes.RegisterRoutes(r)
+			// Register admin Routes
+			s.adminRoutes.RegisterRoutes(r)
+			// Register search Routes
+			s.searchRoutes.RegisterRoutes(r)
+			// Register schema Routes
+			s.schemaRoutes.RegisterRoutes(r)
+			// Register the phone utilities
+			s.phoneRoutes.RegisterRoutes(r)
+			// Register pending entry Routes
+			s.inboundRoutes.RegisterRoutes(r)
+			// Register export schedule Routes
+			s.exportScheduleRoutes.RegisterRoutes(r)
+			// Register the event stream
+			s.eventRoutes.RegisterRoutes(r)
+			// Register the data export archive
+			s.backupRoutes.RegisterRoutes(r)
 		})
 	})
 
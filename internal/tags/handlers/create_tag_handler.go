@@ -7,7 +7,6 @@ import (
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/tags/types"
 	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
-	"github.com/go-chi/render"
 )
 
 // CreateTag godoc
@@ -34,8 +33,7 @@ func (h *TagHandler) CreateTag(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req types.TagCreatePayload
-	if err := render.Bind(r, &req); err != nil {
-		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+	if !h.Bind(w, r, &req) {
 		return
 	}
 
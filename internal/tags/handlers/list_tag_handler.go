@@ -1,20 +1,25 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/tags/types"
 	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/google/uuid"
 )
 
 // ListTags godoc
 // @Summary List Tags
-// @Description Returns a list of Tags
+// @Description Returns a list of Tags, optionally limited to the given IDs. IDs of tags the user doesn't own are ignored.
 // @Tags Tags
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param ids query string false "Comma-separated tag IDs to resolve"
 // @Success 200 {object} payloads.Response{data=[]types.Tag}
 // @Failure 400 {object} errors.ErrorResponse
 // @Failure 401 {object} errors.ErrorResponse
@@ -28,7 +33,21 @@ func (h *TagHandler) ListTags(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tags, err := h.service.ListTags(r.Context(), userID)
+	if !h.CheckQueryParams(w, r, "ids") {
+		return
+	}
+
+	var tags []types.Tag
+	if raw := r.URL.Query().Get("ids"); raw != "" {
+		tagIDs, parseErr := parseTagIDs(raw)
+		if parseErr != nil {
+			h.RespondError(w, r, errors.ErrInvalidRequest(parseErr))
+			return
+		}
+		tags, err = h.service.ListTagsByIDs(r.Context(), userID, tagIDs)
+	} else {
+		tags, err = h.service.ListTags(r.Context(), userID)
+	}
 	if err != nil {
 		h.HandleServiceError(w, r, err)
 		return
@@ -36,3 +55,21 @@ func (h *TagHandler) ListTags(w http.ResponseWriter, r *http.Request) {
 
 	h.Respond(w, r, payloads.OK(tags))
 }
+
+// parseTagIDs parses a comma-separated list of tag IDs
+func parseTagIDs(raw string) ([]uuid.UUID, error) {
+	parts := strings.Split(raw, ",")
+	if len(parts) > types.MaxListTagIDs {
+		return nil, fmt.Errorf("ids must contain at most %d tags", types.MaxListTagIDs)
+	}
+
+	tagIDs := make([]uuid.UUID, 0, len(parts))
+	for _, part := range parts {
+		id, err := uuid.Parse(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("invalid tag id %q", part)
+		}
+		tagIDs = append(tagIDs, id)
+	}
+	return tagIDs, nil
+}
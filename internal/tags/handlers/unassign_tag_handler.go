@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/tags/types"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+)
+
+// UnassignTag godoc
+// @Summary Remove a tag from resources
+// @Description Removes a tag from several contacts, projects or wallets at once. Resources that don't belong to the user or don't carry the tag are skipped and not counted.
+// @Tags Tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tag ID" format(uuid)
+// @Param request body types.TagUnassignPayload true "Tag unassign request"
+// @Success 200 {object} payloads.Response{data=types.TagUnassignResult}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /tags/{id}/unassign [post]
+// @ID UnassignTag
+func (h *TagHandler) UnassignTag(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	tagID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	payload := types.TagUnassignPayload{TagID: tagID}
+	if err := render.Bind(r, &payload); err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	result, err := h.service.UnassignTag(r.Context(), userID, payload)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.OK(result))
+}
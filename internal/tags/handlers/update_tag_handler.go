@@ -7,7 +7,6 @@ import (
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
 	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/render"
 	"github.com/google/uuid"
 )
 
@@ -51,9 +50,8 @@ func (h *TagHandler) UpdateTag(w http.ResponseWriter, r *http.Request) {
 	// Create update payload from existing tag
 	updatePayload := existingTag.ToUpdatePayload()
 
-	// Use render.Bind to decode and validate
-	if err := render.Bind(r, &updatePayload); err != nil {
-		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+	// Decode and validate onto the current values
+	if !h.BindUpdate(w, r, &updatePayload) {
 		return
 	}
 
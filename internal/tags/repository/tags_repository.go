@@ -2,8 +2,10 @@ package repository
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/tags/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
@@ -18,6 +20,8 @@ type TagRepository interface {
 	UpdateTag(ctx context.Context, userID uuid.UUID, tagData types.TagUpdatePayload) (types.Tag, error)
 	DeleteTag(ctx context.Context, userID, tagID uuid.UUID) error
 	DeleteUserTags(ctx context.Context, userID uuid.UUID) error
+	UnassignTag(ctx context.Context, userID uuid.UUID, payload types.TagUnassignPayload) (int64, error)
+	ListTagsByIDs(ctx context.Context, userID uuid.UUID, tagIDs []uuid.UUID) ([]types.Tag, error)
 }
 
 type tagRepository struct {
@@ -48,8 +52,8 @@ func (t *tagRepository) CreateTag(ctx context.Context, userID uuid.UUID, tagData
 		TagID:     createdtag.TagID,
 		Name:      createdtag.Name,
 		Color:     &createdtag.Color.String,
-		CreatedAt: createdtag.CreatedAt.Time,
-		UpdatedAt: createdtag.UpdatedAt.Time,
+		CreatedAt: coreTypes.NewTimestamp(createdtag.CreatedAt.Time),
+		UpdatedAt: coreTypes.NewTimestamp(createdtag.UpdatedAt.Time),
 	}, nil
 }
 
@@ -65,8 +69,30 @@ func (t *tagRepository) ListTags(ctx context.Context, userID uuid.UUID) ([]types
 			TagID:     tag.TagID,
 			Name:      tag.Name,
 			Color:     &tag.Color.String,
-			CreatedAt: tag.CreatedAt.Time,
-			UpdatedAt: tag.UpdatedAt.Time,
+			CreatedAt: coreTypes.NewTimestamp(tag.CreatedAt.Time),
+			UpdatedAt: coreTypes.NewTimestamp(tag.UpdatedAt.Time),
+		})
+	}
+	return result, nil
+}
+
+func (t *tagRepository) ListTagsByIDs(ctx context.Context, userID uuid.UUID, tagIDs []uuid.UUID) ([]types.Tag, error) {
+	tags, err := t.queries.ListTagsByIDs(ctx, db.ListTagsByIDsParams{
+		UserID: userID,
+		TagIds: tagIDs,
+	})
+	if err != nil {
+		return nil, errors.HandleRepositoryError(err, "list", "tags")
+	}
+
+	result := make([]types.Tag, 0, len(tags))
+	for _, tag := range tags {
+		result = append(result, types.Tag{
+			TagID:     tag.TagID,
+			Name:      tag.Name,
+			Color:     &tag.Color.String,
+			CreatedAt: coreTypes.NewTimestamp(tag.CreatedAt.Time),
+			UpdatedAt: coreTypes.NewTimestamp(tag.UpdatedAt.Time),
 		})
 	}
 	return result, nil
@@ -85,8 +111,8 @@ func (t *tagRepository) GetTag(ctx context.Context, userID, tagID uuid.UUID) (ty
 		TagID:     tag.TagID,
 		Name:      tag.Name,
 		Color:     &tag.Color.String,
-		CreatedAt: tag.CreatedAt.Time,
-		UpdatedAt: tag.UpdatedAt.Time,
+		CreatedAt: coreTypes.NewTimestamp(tag.CreatedAt.Time),
+		UpdatedAt: coreTypes.NewTimestamp(tag.UpdatedAt.Time),
 	}, nil
 }
 
@@ -110,8 +136,8 @@ func (t *tagRepository) UpdateTag(ctx context.Context, userID uuid.UUID, tagData
 		TagID:     updatedTag.TagID,
 		Name:      updatedTag.Name,
 		Color:     &updatedTag.Color.String,
-		CreatedAt: updatedTag.CreatedAt.Time,
-		UpdatedAt: updatedTag.UpdatedAt.Time,
+		CreatedAt: coreTypes.NewTimestamp(updatedTag.CreatedAt.Time),
+		UpdatedAt: coreTypes.NewTimestamp(updatedTag.UpdatedAt.Time),
 	}, nil
 }
 
@@ -133,3 +159,38 @@ func (t *tagRepository) DeleteUserTags(ctx context.Context, userID uuid.UUID) er
 	}
 	return err
 }
+
+func (t *tagRepository) UnassignTag(ctx context.Context, userID uuid.UUID, payload types.TagUnassignPayload) (int64, error) {
+	var (
+		affected int64
+		err      error
+	)
+
+	switch payload.ResourceType {
+	case types.ResourceTypeContact:
+		affected, err = t.queries.UnassignTagFromContacts(ctx, db.UnassignTagFromContactsParams{
+			TagID:  payload.TagID,
+			UserID: userID,
+			Ids:    payload.IDs,
+		})
+	case types.ResourceTypeProject:
+		affected, err = t.queries.UnassignTagFromProjects(ctx, db.UnassignTagFromProjectsParams{
+			TagID:  payload.TagID,
+			UserID: userID,
+			Ids:    payload.IDs,
+		})
+	case types.ResourceTypeWallet:
+		affected, err = t.queries.UnassignTagFromWallets(ctx, db.UnassignTagFromWalletsParams{
+			TagID:  payload.TagID,
+			UserID: userID,
+			Ids:    payload.IDs,
+		})
+	default:
+		return 0, fmt.Errorf("unsupported resource type %q", payload.ResourceType)
+	}
+	if err != nil {
+		return 0, errors.HandleRepositoryError(err, "unassign", "tag")
+	}
+
+	return affected, nil
+}
@@ -44,6 +44,7 @@ func (r *Router) RegisterRoutes(router chi.Router) {
 			router.Get("/", r.handler.GetTag)
 			router.Put("/", r.handler.UpdateTag)
 			router.Delete("/", r.handler.DeleteTag)
+			router.Post("/unassign", r.handler.UnassignTag)
 		})
 	})
 }
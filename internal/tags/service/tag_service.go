@@ -11,11 +11,13 @@ import (
 
 type TagService interface {
 	ListTags(ctx context.Context, userID uuid.UUID) ([]types.Tag, error)
+	ListTagsByIDs(ctx context.Context, userID uuid.UUID, tagIDs []uuid.UUID) ([]types.Tag, error)
 	GetTag(ctx context.Context, userID, tagID uuid.UUID) (types.Tag, error)
 	CreateTag(ctx context.Context, userID uuid.UUID, tagData types.TagCreatePayload) (types.Tag, error)
 	UpdateTag(ctx context.Context, userID uuid.UUID, tagData types.TagUpdatePayload) (types.Tag, error)
 	DeleteTag(ctx context.Context, userID, tagID uuid.UUID) error
 	DeleteUserTags(ctx context.Context, userID uuid.UUID) error
+	UnassignTag(ctx context.Context, userID uuid.UUID, payload types.TagUnassignPayload) (types.TagUnassignResult, error)
 }
 
 type tagService struct {
@@ -35,6 +37,11 @@ func (s *tagService) ListTags(ctx context.Context, userID uuid.UUID) ([]types.Ta
 	return s.repo.ListTags(ctx, userID)
 }
 
+// ListTagsByIDs resolves tag IDs to the user's tags, ignoring IDs the user doesn't own
+func (s *tagService) ListTagsByIDs(ctx context.Context, userID uuid.UUID, tagIDs []uuid.UUID) ([]types.Tag, error) {
+	return s.repo.ListTagsByIDs(ctx, userID, tagIDs)
+}
+
 // GetTag returns a specific tag by ID
 func (s *tagService) GetTag(ctx context.Context, userID, tagID uuid.UUID) (types.Tag, error) {
 	return s.repo.GetTag(ctx, userID, tagID)
@@ -59,3 +66,26 @@ func (s *tagService) DeleteTag(ctx context.Context, userID, tagID uuid.UUID) err
 func (s *tagService) DeleteUserTags(ctx context.Context, userID uuid.UUID) error {
 	return s.repo.DeleteUserTags(ctx, userID)
 }
+
+// UnassignTag removes a tag from the given resources, skipping resources that
+// don't belong to the user or don't carry the tag
+func (s *tagService) UnassignTag(ctx context.Context, userID uuid.UUID, payload types.TagUnassignPayload) (types.TagUnassignResult, error) {
+	// the tag itself must belong to the user
+	if _, err := s.repo.GetTag(ctx, userID, payload.TagID); err != nil {
+		return types.TagUnassignResult{}, err
+	}
+
+	affected, err := s.repo.UnassignTag(ctx, userID, payload)
+	if err != nil {
+		return types.TagUnassignResult{}, err
+	}
+
+	s.logger.Info("tag unassigned",
+		zap.String("user_id", userID.String()),
+		zap.String("tag_id", payload.TagID.String()),
+		zap.String("resource_type", string(payload.ResourceType)),
+		zap.Int("requested", len(payload.IDs)),
+		zap.Int64("affected", affected))
+
+	return types.TagUnassignResult{Affected: affected}, nil
+}
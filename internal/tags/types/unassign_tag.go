@@ -0,0 +1,42 @@
+package types
+
+import (
+	"net/http"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+)
+
+// ResourceType names a kind of resource that can carry tags
+type ResourceType string
+
+const (
+	ResourceTypeContact ResourceType = "contact"
+	ResourceTypeProject ResourceType = "project"
+	ResourceTypeWallet  ResourceType = "wallet"
+)
+
+// MaxUnassignIDs caps the number of resources a single unassign request can touch
+const MaxUnassignIDs = 1000
+
+// TagUnassignPayload represents the payload for removing a tag from resources in bulk
+// @Description Payload for removing a tag from several contacts, projects or wallets at once
+type TagUnassignPayload struct {
+	TagID        uuid.UUID    `json:"-" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"` // Set from URL parameter
+	ResourceType ResourceType `json:"resourceType" example:"contact" enums:"contact,project,wallet"`
+	IDs          []uuid.UUID  `json:"ids" example:"123e4567-e89b-12d3-a456-426614174000,123e4567-e89b-12d3-a456-426614174001" minItems:"1" maxItems:"1000"`
+}
+
+func (u *TagUnassignPayload) Bind(r *http.Request) error {
+	return validation.Errors{
+		"resourceType": validation.Validate(u.ResourceType, validation.Required,
+			validation.In(ResourceTypeContact, ResourceTypeProject, ResourceTypeWallet)),
+		"ids": validation.Validate(u.IDs, validation.Required, validation.Length(1, MaxUnassignIDs)),
+	}.Filter()
+}
+
+// TagUnassignResult reports how many resources had the tag removed
+// @Description Number of resources the tag was removed from; resources that did not carry the tag are not counted
+type TagUnassignResult struct {
+	Affected int64 `json:"affected" example:"3"`
+}
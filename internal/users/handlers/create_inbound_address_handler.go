@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+)
+
+// CreateInboundAddress godoc
+// @Summary      Create the inbound address
+// @Description  Hands the user a new address to forward receipts to, emails delivered to it become pending entries.
+// @Description  The address is generated by the server and replaces the previous one, emails to that are rejected from then on.
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Success      201  {object}  payloads.Response{data=types.InboundAddress}
+// @Failure      401  {object} errors.ErrorResponse
+// @Failure      429  {object} errors.ErrorResponse
+// @Failure      500  {object} errors.ErrorResponse
+// @Router       /users/me/inbound-address [post]
+// @ID CreateInboundAddress
+func (h *UserHandler) CreateInboundAddress(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	address, err := h.service.CreateInboundAddress(r.Context(), userID)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.Created(address))
+}
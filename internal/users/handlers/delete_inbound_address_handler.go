@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+)
+
+// DeleteInboundAddress godoc
+// @Summary      Delete the inbound address
+// @Description  Stops accepting forwarded receipts, emails to the address are rejected until a new one is created
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  payloads.Response
+// @Failure      401  {object} errors.ErrorResponse
+// @Failure      429  {object} errors.ErrorResponse
+// @Failure      500  {object} errors.ErrorResponse
+// @Router       /users/me/inbound-address [delete]
+// @ID DeleteInboundAddress
+func (h *UserHandler) DeleteInboundAddress(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	if err := h.service.DeleteInboundAddress(r.Context(), userID); err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.Deleted())
+}
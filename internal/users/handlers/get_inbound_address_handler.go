@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+)
+
+// GetInboundAddress godoc
+// @Summary      Get the inbound address
+// @Description  Returns the address the user forwards receipts to, not found until one was created
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  payloads.Response{data=types.InboundAddress}
+// @Failure      401  {object} errors.ErrorResponse
+// @Failure      404  {object} errors.ErrorResponse
+// @Failure      429  {object} errors.ErrorResponse
+// @Failure      500  {object} errors.ErrorResponse
+// @Router       /users/me/inbound-address [get]
+// @ID GetInboundAddress
+func (h *UserHandler) GetInboundAddress(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	address, err := h.service.GetInboundAddress(r.Context(), userID)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.OK(address))
+}
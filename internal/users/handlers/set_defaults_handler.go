@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/users/types"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+)
+
+// SetDefaults godoc
+// @Summary      Set the default wallet and project
+// @Description  Sets the wallet and project quick entries land in when they don't name one, null clears them.
+// @Description  Each has to be a wallet or project of the user outside the trash; trashing it later clears the default.
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body types.SetDefaultsPayload true "Default wallet and project"
+// @Success      200  {object}  payloads.Response{data=types.User}
+// @Failure      400  {object} errors.ErrorResponse
+// @Failure      401  {object} errors.ErrorResponse
+// @Failure      404  {object} errors.ErrorResponse
+// @Failure      429  {object} errors.ErrorResponse
+// @Failure      500  {object} errors.ErrorResponse
+// @Router       /users/me/defaults [put]
+// @ID SetDefaults
+func (h *UserHandler) SetDefaults(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	var req types.SetDefaultsPayload
+	if !h.Bind(w, r, &req) {
+		return
+	}
+
+	user, err := h.service.SetDefaults(r.Context(), userID, req)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.Updated(user))
+}
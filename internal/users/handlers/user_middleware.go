@@ -2,41 +2,52 @@ package handlers
 
 import (
 	"context"
+	stdErrors "errors"
 	"fmt"
 	"net/http"
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/repository"
 	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
 	"github.com/google/uuid"
 )
 
-// TODO: consider another appraoch that doesn't envolve fetching from the db, maybe look into clerk metadata, can we assign the user id into the meta from the webhook?
+// WithUser adds the ID of the authenticated user to the context. The auth middleware
+// resolves it, provisioning the user on its first request, the claims are the fallback
+// when it didn't run. A user without a row is answered with a 403.
 func (u *UserHandler) WithUser(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		claims, ok := u.auth.GetUserClaims(r.Context())
-		if !ok {
-			u.RespondError(w, r, errors.ErrAuthorization(fmt.Errorf("user claims missing")))
-			return
-		}
+		userID, err := requestcontext.GetUserIDFromContext(r.Context())
+		if err != nil {
+			claims, ok := u.auth.GetUserClaims(r.Context())
+			if !ok {
+				u.RespondError(w, r, errors.ErrAuthorization(fmt.Errorf("user claims missing")))
+				return
+			}
 
-		// Get user ID from claims
-		userIDStr, ok := claims["user_id"].(string)
-		if !ok {
-			u.RespondError(w, r, errors.ErrAuthorization(fmt.Errorf("invalid user ID in claims")))
-			return
-		}
+			// Get user ID from claims
+			userIDStr, ok := claims["user_id"].(string)
+			if !ok {
+				u.RespondError(w, r, errors.ErrAuthorization(fmt.Errorf("invalid user ID in claims")))
+				return
+			}
 
-		// Parse UUID
-		userID, err := uuid.Parse(userIDStr)
-		if err != nil {
-			u.RespondError(w, r, errors.ErrAuthorization(fmt.Errorf("invalid user ID format")))
-			return
+			// Parse UUID
+			userID, err = uuid.Parse(userIDStr)
+			if err != nil {
+				u.RespondError(w, r, errors.ErrAuthorization(fmt.Errorf("invalid user ID format")))
+				return
+			}
 		}
 
 		// Get user from database
 		user, err := u.service.GetUser(r.Context(), userID)
+		if stdErrors.Is(err, repository.ErrNotFound) {
+			u.RespondError(w, r, errors.ErrForbidden(fmt.Errorf("user not provisioned")))
+			return
+		}
 		if err != nil {
-			u.RespondError(w, r, errors.ErrInternal(fmt.Errorf("user not found")))
+			u.RespondError(w, r, errors.ErrInternal(fmt.Errorf("failed to get user: %w", err)))
 			return
 		}
 
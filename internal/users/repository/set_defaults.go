@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/users/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
+	"github.com/google/uuid"
+)
+
+// SetDefaults sets or, with nil, clears the user's default wallet and project. Trashing
+// either clears it again in the same transaction.
+func (r *usersRepository) SetDefaults(ctx context.Context, userID uuid.UUID, walletID, projectID *uuid.UUID) (types.User, error) {
+	user, err := r.queries.SetUserDefaults(ctx, db.SetUserDefaultsParams{
+		UserID:           userID,
+		DefaultWalletID:  utils.UUIDToNullableUUID(walletID),
+		DefaultProjectID: utils.UUIDToNullableUUID(projectID),
+	})
+	if err != nil {
+		return types.User{}, errors.HandleRepositoryError(err, "update", "defaults")
+	}
+
+	return mapDBUserToUser(user), nil
+}
+
+// OwnsWallet reports whether the wallet belongs to the user and isn't trashed
+func (r *usersRepository) OwnsWallet(ctx context.Context, userID, walletID uuid.UUID) (bool, error) {
+	exists, err := r.queries.UserOwnsWallet(ctx, db.UserOwnsWalletParams{
+		WalletID: walletID,
+		UserID:   userID,
+	})
+	if err != nil {
+		return false, errors.HandleRepositoryError(err, "check", "wallet(s)")
+	}
+	return exists, nil
+}
+
+// OwnsProject reports whether the project belongs to the user and isn't trashed
+func (r *usersRepository) OwnsProject(ctx context.Context, userID, projectID uuid.UUID) (bool, error) {
+	exists, err := r.queries.ProjectExists(ctx, db.ProjectExistsParams{
+		ProjectID: projectID,
+		UserID:    userID,
+	})
+	if err != nil {
+		return false, errors.HandleRepositoryError(err, "check", "project(s)")
+	}
+	return exists, nil
+}
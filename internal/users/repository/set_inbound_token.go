@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/users/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
+	"github.com/google/uuid"
+)
+
+// SetInboundToken sets or, with nil, clears the token of the user's inbound address
+func (r *usersRepository) SetInboundToken(ctx context.Context, userID uuid.UUID, token *string) (types.User, error) {
+	user, err := r.queries.SetUserInboundToken(ctx, db.SetUserInboundTokenParams{
+		UserID:       userID,
+		InboundToken: utils.ToNullableText(token),
+	})
+	if err != nil {
+		return types.User{}, errors.HandleRepositoryError(err, "update", "inbound address")
+	}
+
+	return mapDBUserToUser(user), nil
+}
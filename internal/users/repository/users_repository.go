@@ -2,12 +2,17 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/auth/service"
+	coreRepository "github.com/Abdelrahman-habib/expense-tracker/internal/core/repository"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/users/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"go.uber.org/zap"
 )
@@ -20,6 +25,10 @@ type UsersRepository interface {
 	ListUsers(ctx context.Context, params types.ListUsersParams) ([]types.User, error)
 	SearchUsers(ctx context.Context, params types.SearchUsersParams) ([]types.User, error)
 	UpdateUser(ctx context.Context, userID uuid.UUID, userData types.UpdateUserPayload) (types.User, error)
+	SetInboundToken(ctx context.Context, userID uuid.UUID, token *string) (types.User, error)
+	SetDefaults(ctx context.Context, userID uuid.UUID, walletID, projectID *uuid.UUID) (types.User, error)
+	OwnsWallet(ctx context.Context, userID, walletID uuid.UUID) (bool, error)
+	OwnsProject(ctx context.Context, userID, projectID uuid.UUID) (bool, error)
 	GetGoogleToken(ctx context.Context) (types.GoogleOauthToken, error)
 	GetGoogleContacts(ctx context.Context, token string, pageToken string) (*types.PaginatedGoogleContacts, error)
 }
@@ -93,6 +102,9 @@ func (r *usersRepository) GetUser(ctx context.Context, userID uuid.UUID) (types.
 	r.logger.Debug("getting user", zap.String("user_id", userID.String()))
 
 	user, err := r.queries.GetUser(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return types.User{}, fmt.Errorf("get user %s: %w", userID, coreRepository.ErrNotFound)
+	}
 	if err != nil {
 		return types.User{}, err
 	}
@@ -188,19 +200,22 @@ func (r *usersRepository) UpdateUser(ctx context.Context, UserID uuid.UUID, user
 // Helper functions for mapping between types
 func mapDBUserToUser(dbUser db.User) types.User {
 	return types.User{
-		UserID:        dbUser.UserID,
-		Name:          dbUser.Name,
-		Email:         dbUser.Email,
-		ExternalID:    dbUser.ExternalID,
-		Provider:      dbUser.Provider,
-		AddressLine1:  utils.PgtextToStringPtr(dbUser.AddressLine1),
-		AddressLine2:  utils.PgtextToStringPtr(dbUser.AddressLine2),
-		Country:       utils.PgtextToStringPtr(dbUser.Country),
-		City:          utils.PgtextToStringPtr(dbUser.City),
-		StateProvince: utils.PgtextToStringPtr(dbUser.StateProvince),
-		ZipPostalCode: utils.PgtextToStringPtr(dbUser.ZipPostalCode),
-		CreatedAt:     dbUser.CreatedAt.Time,
-		UpdatedAt:     dbUser.UpdatedAt.Time,
+		UserID:           dbUser.UserID,
+		Name:             dbUser.Name,
+		Email:            dbUser.Email,
+		ExternalID:       dbUser.ExternalID,
+		Provider:         dbUser.Provider,
+		AddressLine1:     utils.PgtextToStringPtr(dbUser.AddressLine1),
+		AddressLine2:     utils.PgtextToStringPtr(dbUser.AddressLine2),
+		Country:          utils.PgtextToStringPtr(dbUser.Country),
+		City:             utils.PgtextToStringPtr(dbUser.City),
+		StateProvince:    utils.PgtextToStringPtr(dbUser.StateProvince),
+		ZipPostalCode:    utils.PgtextToStringPtr(dbUser.ZipPostalCode),
+		InboundToken:     utils.PgtextToStringPtr(dbUser.InboundToken),
+		DefaultWalletID:  utils.GetUUIDPtr(dbUser.DefaultWalletID),
+		DefaultProjectID: utils.GetUUIDPtr(dbUser.DefaultProjectID),
+		CreatedAt:        coreTypes.NewTimestamp(dbUser.CreatedAt.Time),
+		UpdatedAt:        coreTypes.NewTimestamp(dbUser.UpdatedAt.Time),
 	}
 }
 
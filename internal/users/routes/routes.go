@@ -15,12 +15,12 @@ type Router struct {
 	Handlers *handlers.UserHandler
 }
 
-func New(db db.Service, logger *zap.Logger, auth authService.Service, clerkConfig *config.ClerkConfig) *Router {
+func New(db db.Service, logger *zap.Logger, auth authService.Service, clerkConfig *config.ClerkConfig, inboundDomain string) *Router {
 	// Initialize repository
 	repo := repository.NewUsersRepository(db.Queries(), logger, nil)
 
 	// Initialize service
-	us := userService.NewUsersService(repo, logger)
+	us := userService.NewUsersService(repo, inboundDomain, logger)
 
 	// Initialize handler
 	handler := handlers.NewUserHandler(us, logger, clerkConfig, auth)
@@ -35,5 +35,9 @@ func (r *Router) RegisterRoutes(router chi.Router) {
 		router.Use(r.Handlers.WithUser)
 		router.Get("/{id}", r.Handlers.GetUser)
 		router.Get("/contacts", r.Handlers.GetUserContacts)
+		router.Get("/me/inbound-address", r.Handlers.GetInboundAddress)
+		router.Post("/me/inbound-address", r.Handlers.CreateInboundAddress)
+		router.Delete("/me/inbound-address", r.Handlers.DeleteInboundAddress)
+		router.Put("/me/defaults", r.Handlers.SetDefaults)
 	})
 }
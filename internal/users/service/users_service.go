@@ -2,10 +2,14 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base32"
 	"fmt"
+	"strings"
 
 	"errors"
 
+	coreErrors "github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/users/repository"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/users/types"
 	"github.com/google/uuid"
@@ -20,18 +24,25 @@ type UsersService interface {
 	ListUsers(ctx context.Context, params types.ListUsersParams) ([]types.User, error)
 	SearchUsers(ctx context.Context, params types.SearchUsersParams) ([]types.User, error)
 	UpdateUser(ctx context.Context, userID uuid.UUID, params types.UpdateUserPayload) (types.User, error)
+	GetInboundAddress(ctx context.Context, userID uuid.UUID) (types.InboundAddress, error)
+	CreateInboundAddress(ctx context.Context, userID uuid.UUID) (types.InboundAddress, error)
+	DeleteInboundAddress(ctx context.Context, userID uuid.UUID) error
+	SetDefaults(ctx context.Context, userID uuid.UUID, params types.SetDefaultsPayload) (types.User, error)
 	GetGoogleContacts(ctx context.Context, pageToken string) (*types.PaginatedGoogleContacts, error)
 }
 
 type usersService struct {
-	repo   repository.UsersRepository
-	logger *zap.Logger
+	repo repository.UsersRepository
+	// inboundDomain is the domain of the inbound addresses, none are handed out without it
+	inboundDomain string
+	logger        *zap.Logger
 }
 
-func NewUsersService(repo repository.UsersRepository, logger *zap.Logger) UsersService {
+func NewUsersService(repo repository.UsersRepository, inboundDomain string, logger *zap.Logger) UsersService {
 	return &usersService{
-		repo:   repo,
-		logger: logger,
+		repo:          repo,
+		inboundDomain: strings.ToLower(inboundDomain),
+		logger:        logger,
 	}
 }
 
@@ -79,6 +90,72 @@ func (s *usersService) UpdateUser(ctx context.Context, userID uuid.UUID, params
 	return s.repo.UpdateUser(ctx, userID, params)
 }
 
+// inboundTokenEncoding spells the tokens of inbound addresses with lower case letters and
+// digits only, so they survive the case folding of mail servers
+var inboundTokenEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// GetInboundAddress returns the address the user forwards receipts to, not found until
+// one was created
+func (s *usersService) GetInboundAddress(ctx context.Context, userID uuid.UUID) (types.InboundAddress, error) {
+	user, err := s.repo.GetUser(ctx, userID)
+	if err != nil {
+		return types.InboundAddress{}, err
+	}
+	if user.InboundToken == nil || s.inboundDomain == "" {
+		return types.InboundAddress{}, coreErrors.NewNotFoundError("no inbound address")
+	}
+	return types.InboundAddress{Address: *user.InboundToken + "@" + s.inboundDomain}, nil
+}
+
+// CreateInboundAddress hands the user a new inbound address with a random token, emails
+// to the address it replaces are rejected from then on
+func (s *usersService) CreateInboundAddress(ctx context.Context, userID uuid.UUID) (types.InboundAddress, error) {
+	if s.inboundDomain == "" {
+		return types.InboundAddress{}, fmt.Errorf("inbound email domain is not configured")
+	}
+
+	random := make([]byte, types.InboundTokenBytes)
+	if _, err := rand.Read(random); err != nil {
+		return types.InboundAddress{}, fmt.Errorf("generate inbound token: %w", err)
+	}
+	token := inboundTokenEncoding.EncodeToString(random)
+
+	if _, err := s.repo.SetInboundToken(ctx, userID, &token); err != nil {
+		return types.InboundAddress{}, err
+	}
+	return types.InboundAddress{Address: token + "@" + s.inboundDomain}, nil
+}
+
+// DeleteInboundAddress stops accepting forwarded emails for the user
+func (s *usersService) DeleteInboundAddress(ctx context.Context, userID uuid.UUID) error {
+	_, err := s.repo.SetInboundToken(ctx, userID, nil)
+	return err
+}
+
+// SetDefaults sets the wallet and project quick entries land in, each has to be a live
+// wallet or project of the user. nil clears them.
+func (s *usersService) SetDefaults(ctx context.Context, userID uuid.UUID, params types.SetDefaultsPayload) (types.User, error) {
+	if params.DefaultWalletID != nil {
+		owned, err := s.repo.OwnsWallet(ctx, userID, *params.DefaultWalletID)
+		if err != nil {
+			return types.User{}, err
+		}
+		if !owned {
+			return types.User{}, coreErrors.NewValidationError("default_wallet_id: wallet %s not found", *params.DefaultWalletID)
+		}
+	}
+	if params.DefaultProjectID != nil {
+		owned, err := s.repo.OwnsProject(ctx, userID, *params.DefaultProjectID)
+		if err != nil {
+			return types.User{}, err
+		}
+		if !owned {
+			return types.User{}, coreErrors.NewValidationError("default_project_id: project %s not found", *params.DefaultProjectID)
+		}
+	}
+	return s.repo.SetDefaults(ctx, userID, params.DefaultWalletID, params.DefaultProjectID)
+}
+
 func (s *usersService) GetGoogleContacts(ctx context.Context, pageToken string) (*types.PaginatedGoogleContacts, error) {
 	// First, get the Google OAuth token for the user
 	token, err := s.repo.GetGoogleToken(ctx)
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	coreErrors "github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/users/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// Mock repository
+type mockUsersRepository struct {
+	mock.Mock
+}
+
+func (m *mockUsersRepository) CreateUser(ctx context.Context, userData types.CreateUserPayload) (types.User, error) {
+	args := m.Called(ctx, userData)
+	return args.Get(0).(types.User), args.Error(1)
+}
+
+func (m *mockUsersRepository) DeleteUser(ctx context.Context, userID uuid.UUID) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *mockUsersRepository) GetUser(ctx context.Context, userID uuid.UUID) (types.User, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(types.User), args.Error(1)
+}
+
+func (m *mockUsersRepository) GetUserByExternalID(ctx context.Context, externalID string) (types.User, error) {
+	args := m.Called(ctx, externalID)
+	return args.Get(0).(types.User), args.Error(1)
+}
+
+func (m *mockUsersRepository) ListUsers(ctx context.Context, params types.ListUsersParams) ([]types.User, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).([]types.User), args.Error(1)
+}
+
+func (m *mockUsersRepository) SearchUsers(ctx context.Context, params types.SearchUsersParams) ([]types.User, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).([]types.User), args.Error(1)
+}
+
+func (m *mockUsersRepository) UpdateUser(ctx context.Context, userID uuid.UUID, userData types.UpdateUserPayload) (types.User, error) {
+	args := m.Called(ctx, userID, userData)
+	return args.Get(0).(types.User), args.Error(1)
+}
+
+func (m *mockUsersRepository) SetInboundToken(ctx context.Context, userID uuid.UUID, token *string) (types.User, error) {
+	args := m.Called(ctx, userID, token)
+	return args.Get(0).(types.User), args.Error(1)
+}
+
+func (m *mockUsersRepository) SetDefaults(ctx context.Context, userID uuid.UUID, walletID, projectID *uuid.UUID) (types.User, error) {
+	args := m.Called(ctx, userID, walletID, projectID)
+	return args.Get(0).(types.User), args.Error(1)
+}
+
+func (m *mockUsersRepository) OwnsWallet(ctx context.Context, userID, walletID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, userID, walletID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockUsersRepository) OwnsProject(ctx context.Context, userID, projectID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, userID, projectID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockUsersRepository) GetGoogleToken(ctx context.Context) (types.GoogleOauthToken, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(types.GoogleOauthToken), args.Error(1)
+}
+
+func (m *mockUsersRepository) GetGoogleContacts(ctx context.Context, token string, pageToken string) (*types.PaginatedGoogleContacts, error) {
+	args := m.Called(ctx, token, pageToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.PaginatedGoogleContacts), args.Error(1)
+}
+
+func TestUsersService_SetDefaults(t *testing.T) {
+	mockRepo := new(mockUsersRepository)
+	service := NewUsersService(mockRepo, "", zap.NewNop())
+	ctx := context.Background()
+	userID := uuid.New()
+	walletID := uuid.New()
+	projectID := uuid.New()
+
+	tests := []struct {
+		name      string
+		payload   types.SetDefaultsPayload
+		mock      func()
+		wantField string
+	}{
+		{
+			name:    "sets both",
+			payload: types.SetDefaultsPayload{DefaultWalletID: &walletID, DefaultProjectID: &projectID},
+			mock: func() {
+				mockRepo.On("OwnsWallet", ctx, userID, walletID).Return(true, nil)
+				mockRepo.On("OwnsProject", ctx, userID, projectID).Return(true, nil)
+				mockRepo.On("SetDefaults", ctx, userID, &walletID, &projectID).
+					Return(types.User{UserID: userID, DefaultWalletID: &walletID, DefaultProjectID: &projectID}, nil)
+			},
+		},
+		{
+			name:    "clears both without checks",
+			payload: types.SetDefaultsPayload{},
+			mock: func() {
+				mockRepo.On("SetDefaults", ctx, userID, (*uuid.UUID)(nil), (*uuid.UUID)(nil)).Return(types.User{UserID: userID}, nil)
+			},
+		},
+		{
+			name:    "another user's wallet",
+			payload: types.SetDefaultsPayload{DefaultWalletID: &walletID},
+			mock: func() {
+				mockRepo.On("OwnsWallet", ctx, userID, walletID).Return(false, nil)
+			},
+			wantField: "default_wallet_id",
+		},
+		{
+			name:    "another user's project",
+			payload: types.SetDefaultsPayload{DefaultWalletID: &walletID, DefaultProjectID: &projectID},
+			mock: func() {
+				mockRepo.On("OwnsWallet", ctx, userID, walletID).Return(true, nil)
+				mockRepo.On("OwnsProject", ctx, userID, projectID).Return(false, nil)
+			},
+			wantField: "default_project_id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.ExpectedCalls = nil
+			mockRepo.Calls = nil
+			tt.mock()
+
+			user, err := service.SetDefaults(ctx, userID, tt.payload)
+			if tt.wantField != "" {
+				require.Error(t, err)
+				assert.True(t, coreErrors.IsErrorType(err, coreErrors.ErrorTypeValidation))
+				assert.Contains(t, err.Error(), tt.wantField)
+				mockRepo.AssertNotCalled(t, "SetDefaults", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.payload.DefaultWalletID, user.DefaultWalletID)
+			assert.Equal(t, tt.payload.DefaultProjectID, user.DefaultProjectID)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUsersService_InboundAddress(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("hands out a random address on the inbound domain", func(t *testing.T) {
+		mockRepo := new(mockUsersRepository)
+		service := NewUsersService(mockRepo, "Inbound.Example.com", zap.NewNop())
+		var tokens []string
+		mockRepo.On("SetInboundToken", ctx, userID, mock.AnythingOfType("*string")).
+			Run(func(args mock.Arguments) { tokens = append(tokens, *args.Get(2).(*string)) }).
+			Return(types.User{UserID: userID}, nil)
+
+		first, err := service.CreateInboundAddress(ctx, userID)
+		require.NoError(t, err)
+		second, err := service.CreateInboundAddress(ctx, userID)
+		require.NoError(t, err)
+
+		require.Len(t, tokens, 2)
+		assert.Regexp(t, `^[a-z2-7]{24}$`, tokens[0])
+		assert.Equal(t, tokens[0]+"@inbound.example.com", first.Address)
+		assert.NotEqual(t, first.Address, second.Address, "a new address replaces the old one")
+	})
+
+	t.Run("returns the address handed out", func(t *testing.T) {
+		mockRepo := new(mockUsersRepository)
+		service := NewUsersService(mockRepo, "inbound.example.com", zap.NewNop())
+		token := "mfrggzdfmztwq2lknnwg23tp"
+		mockRepo.On("GetUser", ctx, userID).Return(types.User{UserID: userID, InboundToken: &token}, nil).Once()
+		mockRepo.On("GetUser", ctx, userID).Return(types.User{UserID: userID}, nil).Once()
+
+		address, err := service.GetInboundAddress(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, "mfrggzdfmztwq2lknnwg23tp@inbound.example.com", address.Address)
+
+		_, err = service.GetInboundAddress(ctx, userID)
+		assert.True(t, coreErrors.IsErrorType(err, coreErrors.ErrorTypeNotFound))
+	})
+
+	t.Run("deleting clears the token", func(t *testing.T) {
+		mockRepo := new(mockUsersRepository)
+		service := NewUsersService(mockRepo, "inbound.example.com", zap.NewNop())
+		mockRepo.On("SetInboundToken", ctx, userID, (*string)(nil)).Return(types.User{UserID: userID}, nil)
+
+		require.NoError(t, service.DeleteInboundAddress(ctx, userID))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("no address without an inbound domain", func(t *testing.T) {
+		mockRepo := new(mockUsersRepository)
+		service := NewUsersService(mockRepo, "", zap.NewNop())
+
+		_, err := service.CreateInboundAddress(ctx, userID)
+		assert.Error(t, err)
+		mockRepo.AssertNotCalled(t, "SetInboundToken", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestUser_EntryTarget(t *testing.T) {
+	defaultWallet, defaultProject, picked := uuid.New(), uuid.New(), uuid.New()
+	user := types.User{DefaultWalletID: &defaultWallet, DefaultProjectID: &defaultProject}
+
+	wallet, project := user.EntryTarget(nil, nil)
+	assert.Equal(t, &defaultWallet, wallet)
+	assert.Equal(t, &defaultProject, project)
+
+	wallet, project = user.EntryTarget(&picked, nil)
+	assert.Equal(t, &picked, wallet)
+	assert.Equal(t, &defaultProject, project)
+
+	wallet, project = types.User{}.EntryTarget(nil, nil)
+	assert.Nil(t, wallet)
+	assert.Nil(t, project)
+}
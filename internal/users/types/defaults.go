@@ -0,0 +1,32 @@
+package types
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// SetDefaultsPayload sets where quick entries land when they don't name a wallet or project
+// @Description Default wallet and project of the user, null clears them
+type SetDefaultsPayload struct {
+	DefaultWalletID  *uuid.UUID `json:"default_wallet_id" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid" extensions:"x-nullable"`
+	DefaultProjectID *uuid.UUID `json:"default_project_id" example:"123e4567-e89b-12d3-a456-426614174001" format:"uuid" extensions:"x-nullable"`
+}
+
+// Bind implements render.Binder, the IDs are checked against the user's wallets and
+// projects by the service
+func (p *SetDefaultsPayload) Bind(r *http.Request) error {
+	return nil
+}
+
+// EntryTarget returns the wallet and project an entry lands in, the ones it names or
+// else the user's defaults
+func (u User) EntryTarget(walletID, projectID *uuid.UUID) (*uuid.UUID, *uuid.UUID) {
+	if walletID == nil {
+		walletID = u.DefaultWalletID
+	}
+	if projectID == nil {
+		projectID = u.DefaultProjectID
+	}
+	return walletID, projectID
+}
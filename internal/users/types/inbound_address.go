@@ -0,0 +1,10 @@
+package types
+
+// InboundTokenBytes is how many random bytes the token of an inbound address is made of
+const InboundTokenBytes = 15
+
+// InboundAddress is the address the user forwards receipts to
+// @Description Address receipts are forwarded to, emails delivered to it become pending entries of the user
+type InboundAddress struct {
+	Address string `json:"address" example:"mfrggzdfmztwq2lknnwg23tp@inbound.example.com" format:"email"`
+}
@@ -1,8 +1,7 @@
 package types
 
 import (
-	"time"
-
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 	"github.com/google/uuid"
 )
 
@@ -20,6 +19,12 @@ type User struct {
 	City          *string   `json:"city,omitempty" example:"New York"`
 	StateProvince *string   `json:"state_province,omitempty" example:"NY"`
 	ZipPostalCode *string   `json:"zip_postal_code,omitempty" example:"10001"`
-	CreatedAt     time.Time `json:"created_at" example:"2023-01-01T00:00:00Z"`
-	UpdatedAt     time.Time `json:"updated_at" example:"2023-01-01T00:00:00Z"`
+	// InboundToken is the local part of the user's inbound address, handed out through
+	// its own endpoint rather than with the profile
+	InboundToken *string `json:"-"`
+	// DefaultWalletID and DefaultProjectID are where quick entries land when they don't name one
+	DefaultWalletID  *uuid.UUID          `json:"default_wallet_id,omitempty" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
+	DefaultProjectID *uuid.UUID          `json:"default_project_id,omitempty" example:"123e4567-e89b-12d3-a456-426614174001" format:"uuid"`
+	CreatedAt        coreTypes.Timestamp `json:"created_at" example:"2023-01-01T00:00:00.000Z" swaggertype:"string" format:"date-time"`
+	UpdatedAt        coreTypes.Timestamp `json:"updated_at" example:"2023-01-01T00:00:00.000Z" swaggertype:"string" format:"date-time"`
 }
@@ -5,6 +5,7 @@ import (
 	"math"
 	"time"
 
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 )
@@ -30,6 +31,13 @@ func ToNullableText(s *string) pgtype.Text {
 	return pgtype.Text{String: *s, Valid: true}
 }
 
+func ToNullableBool(b *bool) pgtype.Bool {
+	if b == nil {
+		return pgtype.Bool{Valid: false}
+	}
+	return pgtype.Bool{Bool: *b, Valid: true}
+}
+
 func ToNullableTimestamp(t *time.Time) pgtype.Timestamp {
 	if t == nil {
 		return pgtype.Timestamp{Valid: false}
@@ -37,6 +45,14 @@ func ToNullableTimestamp(t *time.Time) pgtype.Timestamp {
 	return pgtype.Timestamp{Time: *t, Valid: true}
 }
 
+// ToNullableDate stores a date in a DATE column, nil as NULL
+func ToNullableDate(d *coreTypes.Date) pgtype.Date {
+	if d == nil {
+		return pgtype.Date{Valid: false}
+	}
+	return pgtype.Date{Time: d.Time, Valid: true}
+}
+
 func ToNullableNumeric(f *float64) pgtype.Numeric {
 	if f == nil {
 		return pgtype.Numeric{Valid: false}
@@ -57,6 +73,23 @@ func GetTimePtr(t pgtype.Timestamp) *time.Time {
 	return nil
 }
 
+// GetTimestampPtr is GetTimePtr for the timestamps of response structs
+func GetTimestampPtr(t pgtype.Timestamp) *coreTypes.Timestamp {
+	if t.Valid {
+		return &coreTypes.Timestamp{Time: t.Time}
+	}
+	return nil
+}
+
+// GetDatePtr reads a DATE column, NULL as nil
+func GetDatePtr(d pgtype.Date) *coreTypes.Date {
+	if d.Valid {
+		date := coreTypes.NewDate(d.Time)
+		return &date
+	}
+	return nil
+}
+
 func GetFloat64Ptr(n pgtype.Numeric) *float64 {
 	if !n.Valid {
 		return nil
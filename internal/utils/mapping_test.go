@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/assert"
@@ -106,6 +107,13 @@ func TestToNullableText(t *testing.T) {
 	}
 }
 
+func TestToNullableBool(t *testing.T) {
+	yes, no := true, false
+	assert.Equal(t, pgtype.Bool{Valid: false}, ToNullableBool(nil))
+	assert.Equal(t, pgtype.Bool{Bool: true, Valid: true}, ToNullableBool(&yes))
+	assert.Equal(t, pgtype.Bool{Bool: false, Valid: true}, ToNullableBool(&no))
+}
+
 func TestToNullableTimestamp(t *testing.T) {
 	now := time.Now().UTC()
 	tests := []struct {
@@ -205,6 +213,28 @@ func TestGetTimePtr(t *testing.T) {
 	}
 }
 
+func TestGetTimestampPtr(t *testing.T) {
+	now := time.Now().UTC()
+	assert.Nil(t, GetTimestampPtr(pgtype.Timestamp{Valid: false}))
+
+	got := GetTimestampPtr(pgtype.Timestamp{Time: now, Valid: true})
+	if assert.NotNil(t, got) {
+		assert.Equal(t, now, got.Time)
+	}
+}
+
+func TestDateMapping(t *testing.T) {
+	date := coreTypes.NewDate(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, pgtype.Date{Valid: false}, ToNullableDate(nil))
+	assert.Equal(t, pgtype.Date{Time: date.Time, Valid: true}, ToNullableDate(&date))
+
+	assert.Nil(t, GetDatePtr(pgtype.Date{Valid: false}))
+	got := GetDatePtr(pgtype.Date{Time: date.Time, Valid: true})
+	if assert.NotNil(t, got) {
+		assert.Equal(t, "2024-03-01", got.String())
+	}
+}
+
 func TestGetFloat64Ptr(t *testing.T) {
 	tests := []struct {
 		name string
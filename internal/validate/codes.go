@@ -0,0 +1,17 @@
+package validate
+
+import "github.com/asaskevich/govalidator"
+
+// CurrencyCodes returns the ISO 4217 codes accepted by is.CurrencyCode
+func CurrencyCodes() []string {
+	return append([]string(nil), govalidator.ISO4217List...)
+}
+
+// CountryCodes returns the ISO 3166 alpha-2 codes accepted by is.CountryCode2
+func CountryCodes() []string {
+	codes := make([]string, len(govalidator.ISO3166List))
+	for i, entry := range govalidator.ISO3166List {
+		codes[i] = entry.Alpha2Code
+	}
+	return codes
+}
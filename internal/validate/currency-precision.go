@@ -0,0 +1,131 @@
+package validate
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// MaxAmountDecimals is the number of decimal places amounts are stored with
+const MaxAmountDecimals = 3
+
+// MaxAmount bounds the amounts the API takes, the largest balance the DECIMAL(10,2)
+// columns store
+const MaxAmount = 99_999_999.99
+
+// zeroDecimalCurrencies lists the ISO 4217 currencies without a minor unit
+var zeroDecimalCurrencies = map[string]bool{
+	"BIF": true, "CLP": true, "DJF": true, "GNF": true, "ISK": true, "JPY": true,
+	"KMF": true, "KRW": true, "PYG": true, "RWF": true, "UGX": true, "UYI": true,
+	"VND": true, "VUV": true, "XAF": true, "XOF": true, "XPF": true,
+}
+
+// threeDecimalCurrencies lists the ISO 4217 currencies with a minor unit of a thousandth
+var threeDecimalCurrencies = map[string]bool{
+	"BHD": true, "IQD": true, "JOD": true, "KWD": true, "LYD": true, "OMR": true, "TND": true,
+}
+
+// ErrCurrencyPrecision is the error that returns when an amount has more decimals than its currency allows
+var ErrCurrencyPrecision = validation.NewError("validation_currency_precision", "must have at most {{.decimals}} decimal places for the currency")
+
+// CurrencyDecimals returns how many decimal places amounts in the currency may have, the
+// decimals of its minor unit
+func CurrencyDecimals(currency string) int {
+	currency = strings.ToUpper(currency)
+	switch {
+	case zeroDecimalCurrencies[currency]:
+		return 0
+	case threeDecimalCurrencies[currency]:
+		return 3
+	}
+	return 2
+}
+
+// CurrencyPrecision validates that a float64 amount has no more decimal places than the currency allows.
+// An empty value is considered valid.
+func CurrencyPrecision(currency string) validation.Rule {
+	decimals := CurrencyDecimals(currency)
+	return validation.By(func(value interface{}) error {
+		value, isNil := validation.Indirect(value)
+		if isNil {
+			return nil
+		}
+		amount, ok := value.(float64)
+		if !ok {
+			return nil
+		}
+		if countDecimals(amount) > decimals {
+			return ErrCurrencyPrecision.SetParams(map[string]interface{}{"decimals": decimals})
+		}
+		return nil
+	})
+}
+
+// countDecimals counts the decimal places of the shortest representation of the amount
+func countDecimals(amount float64) int {
+	formatted := strconv.FormatFloat(amount, 'f', -1, 64)
+	_, fraction, found := strings.Cut(formatted, ".")
+	if !found {
+		return 0
+	}
+	return len(fraction)
+}
+
+// RoundingMode is how amounts are rounded to the decimal places of their currency
+type RoundingMode string
+
+const (
+	// RoundHalfUp rounds halves away from zero, 100.555 USD becomes 100.56. Postgres
+	// rounds numerics the same way.
+	RoundHalfUp RoundingMode = "half_up"
+	// RoundHalfEven rounds halves to the even neighbour (banker's rounding), 100.555 USD
+	// becomes 100.56 and 100.545 USD 100.54, so rounding doesn't drift sums upwards
+	RoundHalfEven RoundingMode = "half_even"
+)
+
+// Valid reports whether the rounding mode is a known one
+func (m RoundingMode) Valid() bool {
+	return m == RoundHalfUp || m == RoundHalfEven
+}
+
+// RoundAmount rounds an amount to the decimal places of its currency. The amount is read
+// as its shortest decimal representation, the way it was written, so 100.555 is a half
+// even though the closest float64 is slightly below it. Unknown modes round half up.
+func RoundAmount(amount float64, currency string, mode RoundingMode) float64 {
+	decimals := CurrencyDecimals(currency)
+	if countDecimals(amount) <= decimals {
+		return amount
+	}
+
+	formatted := strconv.FormatFloat(amount, 'f', -1, 64)
+	negative := strings.HasPrefix(formatted, "-")
+	whole, fraction, _ := strings.Cut(strings.TrimPrefix(formatted, "-"), ".")
+
+	kept, dropped := fraction[:decimals], fraction[decimals:]
+	units, _ := new(big.Int).SetString(whole+kept, 10)
+
+	roundUp := dropped[0] > '5' || (dropped[0] == '5' && strings.Trim(dropped[1:], "0") != "")
+	if dropped[0] == '5' && !roundUp {
+		// exactly half way
+		roundUp = mode != RoundHalfEven || units.Bit(0) == 1
+	}
+	if roundUp {
+		units.Add(units, big.NewInt(1))
+	}
+
+	digits := units.String()
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	rounded := digits
+	if decimals > 0 {
+		rounded = digits[:len(digits)-decimals] + "." + digits[len(digits)-decimals:]
+	}
+	if negative {
+		rounded = "-" + rounded
+	}
+	result, _ := strconv.ParseFloat(rounded, 64)
+	return result
+}
@@ -0,0 +1,189 @@
+package validate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+// DefaultMXLookupTimeout bounds the MX lookup of a domain when none is configured
+const DefaultMXLookupTimeout = 2 * time.Second
+
+var (
+	// EmailFormat validates the syntax of an email address, ignoring the whitespace
+	// around it that NormalizeEmail trims
+	EmailFormat = validation.NewStringRuleWithError(func(value string) bool {
+		return is.EmailFormat.Validate(strings.TrimSpace(value)) == nil
+	}, is.ErrEmail)
+
+	// ErrEmailDomain is returned for an address whose domain can't be a public one
+	ErrEmailDomain = errors.New("must have a domain like example.com")
+	// ErrEmailNoMX is returned for an address whose domain has no mail server
+	ErrEmailNoMX = errors.New("has a domain that doesn't receive email")
+)
+
+// DefaultEmailTypoDomains maps common misspellings of the popular mail domains to the
+// domain meant
+var DefaultEmailTypoDomains = map[string]string{
+	"gamil.com":   "gmail.com",
+	"gmial.com":   "gmail.com",
+	"gmai.com":    "gmail.com",
+	"gnail.com":   "gmail.com",
+	"gmail.co":    "gmail.com",
+	"gmail.con":   "gmail.com",
+	"hotmial.com": "hotmail.com",
+	"hotmal.com":  "hotmail.com",
+	"hotmail.co":  "hotmail.com",
+	"yaho.com":    "yahoo.com",
+	"yahooo.com":  "yahoo.com",
+	"yahoo.co":    "yahoo.com",
+	"outlok.com":  "outlook.com",
+	"outloo.com":  "outlook.com",
+	"iclod.com":   "icloud.com",
+	"icloud.co":   "icloud.com",
+}
+
+// reservedTLDs are the top level domains that never reach a public mail server
+var reservedTLDs = map[string]bool{
+	"localhost": true,
+	"local":     true,
+	"internal":  true,
+	"invalid":   true,
+	"test":      true,
+}
+
+// EmailTypoError is returned for an address whose domain looks like a typo of a
+// common one
+type EmailTypoError struct {
+	Email      string
+	Suggestion string
+}
+
+func (e *EmailTypoError) Error() string {
+	return fmt.Sprintf("%s looks like a typo, did you mean %s?", e.Email, e.Suggestion)
+}
+
+// MXResolver looks up the mail servers of a domain, *net.Resolver is one
+type MXResolver interface {
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+}
+
+// EmailChecker checks the domain of email addresses: its structure, typos of common
+// domains and, with a resolver, that it has a mail server. MX lookups are cached per
+// domain for the life of the process.
+type EmailChecker struct {
+	typos    map[string]string
+	resolver MXResolver
+	timeout  time.Duration
+	// mx caches whether a domain has a mail server, by domain
+	mx sync.Map
+}
+
+// NewEmailChecker returns a checker suggesting the domains of typos, keyed by the
+// misspelled domain. A nil resolver skips the MX lookups, a zero timeout bounds them to
+// DefaultMXLookupTimeout.
+func NewEmailChecker(typos map[string]string, resolver MXResolver, timeout time.Duration) *EmailChecker {
+	if timeout <= 0 {
+		timeout = DefaultMXLookupTimeout
+	}
+	normalized := make(map[string]string, len(typos))
+	for typo, domain := range typos {
+		normalized[strings.ToLower(strings.TrimSpace(typo))] = strings.ToLower(strings.TrimSpace(domain))
+	}
+	return &EmailChecker{typos: normalized, resolver: resolver, timeout: timeout}
+}
+
+// NormalizeEmail trims and lower cases an email address
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// Check checks the domain of a normalized address. It returns ErrEmailDomain for a
+// domain that can't be public, an *EmailTypoError for a typo of a common domain and
+// ErrEmailNoMX for a domain without a mail server. Lookups that fail for any other
+// reason than the domain missing, timeouts included, accept the address.
+func (c *EmailChecker) Check(ctx context.Context, email string) error {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return ErrEmailDomain
+	}
+	local, domain := email[:at], email[at+1:]
+	if !isPublicDomain(domain) {
+		return ErrEmailDomain
+	}
+	if suggestion, ok := c.typos[domain]; ok {
+		return &EmailTypoError{Email: email, Suggestion: local + "@" + suggestion}
+	}
+	if c.resolver != nil && !c.hasMX(ctx, domain) {
+		return ErrEmailNoMX
+	}
+	return nil
+}
+
+// isPublicDomain reports whether domain is made of at least two valid labels and ends
+// with an alphabetic top level domain that isn't reserved
+func isPublicDomain(domain string) bool {
+	if len(domain) > 253 {
+		return false
+	}
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return false
+	}
+	for _, label := range labels {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, r := range label {
+			if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+				return false
+			}
+		}
+	}
+
+	tld := labels[len(labels)-1]
+	if len(tld) < 2 || reservedTLDs[tld] {
+		return false
+	}
+	if strings.HasPrefix(tld, "xn--") {
+		return true
+	}
+	return strings.Trim(tld, "abcdefghijklmnopqrstuvwxyz") == ""
+}
+
+// hasMX reports whether domain has a mail server, looking it up once per domain. A domain
+// publishing the null MX record refuses email. Failed lookups aren't cached and report true.
+func (c *EmailChecker) hasMX(ctx context.Context, domain string) bool {
+	if cached, ok := c.mx.Load(domain); ok {
+		return cached.(bool)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	records, err := c.resolver.LookupMX(ctx, domain)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+			return true
+		}
+		records = nil
+	}
+
+	found := false
+	for _, record := range records {
+		if record.Host != "." && record.Host != "" {
+			found = true
+			break
+		}
+	}
+	c.mx.Store(domain, found)
+	return found
+}
@@ -0,0 +1,133 @@
+package validate
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeResolver answers MX lookups from records, by domain, and counts them
+type fakeResolver struct {
+	records map[string][]*net.MX
+	err     error
+	lookups map[string]int
+}
+
+func (f *fakeResolver) LookupMX(ctx context.Context, name string) ([]*net.MX, error) {
+	f.lookups[name]++
+	if f.err != nil {
+		return nil, f.err
+	}
+	records, ok := f.records[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+func TestNormalizeEmail(t *testing.T) {
+	assert.Equal(t, "jane.doe@example.com", NormalizeEmail("  Jane.Doe@Example.COM "))
+}
+
+func TestEmailChecker_Domain(t *testing.T) {
+	checker := NewEmailChecker(DefaultEmailTypoDomains, nil, 0)
+
+	tests := []struct {
+		email string
+		err   error
+	}{
+		{"jane@example.com", nil},
+		{"jane@mail.example.co.uk", nil},
+		{"jane@xn--80ak6aa92e.com", nil},
+		{"jane@localhost", ErrEmailDomain},
+		{"jane@printer.local", ErrEmailDomain},
+		{"jane@example", ErrEmailDomain},
+		{"jane@example.c", ErrEmailDomain},
+		{"jane@192.168.0.1", ErrEmailDomain},
+		{"jane@-example.com", ErrEmailDomain},
+		{"jane@example..com", ErrEmailDomain},
+		{"@example.com", ErrEmailDomain},
+	}
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			assert.Equal(t, tt.err, checker.Check(context.Background(), tt.email))
+		})
+	}
+}
+
+func TestEmailChecker_TypoSuggestions(t *testing.T) {
+	checker := NewEmailChecker(map[string]string{"Gamil.com": "gmail.com", "yaho.com": "Yahoo.com"}, nil, 0)
+
+	tests := []struct {
+		email      string
+		suggestion string
+	}{
+		{"jane@gamil.com", "jane@gmail.com"},
+		{"john.doe+bills@yaho.com", "john.doe+bills@yahoo.com"},
+		{"jane@gmail.com", ""},
+		// only whole domains are matched
+		{"jane@notgamil.com", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			err := checker.Check(context.Background(), tt.email)
+			if tt.suggestion == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var typo *EmailTypoError
+			require.True(t, errors.As(err, &typo), "got %v", err)
+			assert.Equal(t, tt.suggestion, typo.Suggestion)
+			assert.Equal(t, tt.email, typo.Email)
+		})
+	}
+}
+
+func TestEmailChecker_MX(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("checks the domain once", func(t *testing.T) {
+		resolver := &fakeResolver{
+			records: map[string][]*net.MX{
+				"example.com": {{Host: "mx.example.com.", Pref: 10}},
+				"nomail.com":  {{Host: ".", Pref: 0}},
+			},
+			lookups: map[string]int{},
+		}
+		checker := NewEmailChecker(nil, resolver, 0)
+
+		assert.NoError(t, checker.Check(ctx, "jane@example.com"))
+		assert.NoError(t, checker.Check(ctx, "john@example.com"))
+		assert.Equal(t, ErrEmailNoMX, checker.Check(ctx, "jane@missing.com"))
+		assert.Equal(t, ErrEmailNoMX, checker.Check(ctx, "john@missing.com"))
+		assert.Equal(t, ErrEmailNoMX, checker.Check(ctx, "jane@nomail.com"))
+		assert.Equal(t, map[string]int{"example.com": 1, "missing.com": 1, "nomail.com": 1}, resolver.lookups)
+	})
+
+	t.Run("fails open on DNS errors", func(t *testing.T) {
+		resolver := &fakeResolver{
+			err:     &net.DNSError{Err: "i/o timeout", Name: "example.com", IsTimeout: true},
+			lookups: map[string]int{},
+		}
+		checker := NewEmailChecker(nil, resolver, 0)
+
+		assert.NoError(t, checker.Check(ctx, "jane@example.com"))
+		assert.NoError(t, checker.Check(ctx, "jane@example.com"))
+		// failures aren't cached, the next address looks the domain up again
+		assert.Equal(t, 2, resolver.lookups["example.com"])
+	})
+
+	t.Run("checks the domain before looking it up", func(t *testing.T) {
+		resolver := &fakeResolver{lookups: map[string]int{}}
+		checker := NewEmailChecker(DefaultEmailTypoDomains, resolver, 0)
+
+		assert.Equal(t, ErrEmailDomain, checker.Check(ctx, "jane@localhost"))
+		var typo *EmailTypoError
+		assert.True(t, errors.As(checker.Check(ctx, "jane@gamil.com"), &typo))
+		assert.Empty(t, resolver.lookups)
+	})
+}
@@ -6,10 +6,14 @@ import (
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
+// PhoneNumberPattern is the expression phone numbers are matched against, written so
+// it reads the same as an ECMA-262 pattern for JSON Schema
+const PhoneNumberPattern = `[+]?[\d\s()-]+$`
+
 var (
 	// ErrPhoneNumber is the error that returns in case of an invalid PhoneNumber.
 	ErrPhoneNumber = validation.NewError("validation_is_PhoneNumber", "invalid phone number format")
-	rePhoneNumber  = regexp.MustCompile(`[+]?[\d\s-()]+$`)
+	rePhoneNumber  = regexp.MustCompile(PhoneNumberPattern)
 	// PhoneNumber validates if a string is a valid PhoneNumber
 	PhoneNumber = validation.NewStringRuleWithError(isPhoneNumber, ErrPhoneNumber)
 )
@@ -0,0 +1,22 @@
+package validate
+
+import (
+	"net/url"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+var (
+	// ErrWebURL is the error that returns in case of an invalid WebURL.
+	ErrWebURL = validation.NewError("validation_is_web_url", "must be an absolute http or https URL")
+	// WebURL validates if a string is an absolute http or https URL with a host
+	WebURL = validation.NewStringRuleWithError(isWebURL, ErrWebURL)
+)
+
+func isWebURL(value string) bool {
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return false
+	}
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Hostname() != ""
+}
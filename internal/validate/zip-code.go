@@ -6,10 +6,13 @@ import (
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
+// ZipcodePattern is the expression zip codes are matched against
+const ZipcodePattern = `^[A-Za-z0-9\s\-]{3,10}$`
+
 var (
 	// ErrZipcode is the error that returns in case of an invalid zipcode.
 	ErrZipCode = validation.NewError("validation_is_zipcode", "invalid zip code format")
-	reZipcode  = regexp.MustCompile(`^[A-Za-z0-9\s\-]{3,10}$`)
+	reZipcode  = regexp.MustCompile(ZipcodePattern)
 	// Zipcode validates if a string is a valid Zipcode
 	Zipcode = validation.NewStringRuleWithError(isZipcode, ErrZipCode)
 )
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+)
+
+// GetVersion godoc
+// @Summary Get the running version
+// @Description Returns the version, git commit and build time the server was built with, and the Go version it runs on
+// @Tags Version
+// @Produce json
+// @Success 200 {object} payloads.Response{data=version.Info}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 429 {object} errors.ErrorResponse
+// @Router /version [get]
+// @ID GetVersion
+func (h *VersionHandler) GetVersion(w http.ResponseWriter, r *http.Request) {
+	if !h.CheckQueryParams(w, r) {
+		return
+	}
+
+	h.Respond(w, r, payloads.OK(h.info))
+}
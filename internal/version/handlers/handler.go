@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/version"
+	"go.uber.org/zap"
+)
+
+type VersionHandler struct {
+	handlers.BaseHandler
+	info version.Info
+}
+
+func NewVersionHandler(info version.Info, logger *zap.Logger) *VersionHandler {
+	return &VersionHandler{
+		BaseHandler: handlers.NewBaseHandler(logger),
+		info:        info,
+	}
+}
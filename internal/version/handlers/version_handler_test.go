@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestVersionHandler_GetVersion(t *testing.T) {
+	info := version.Info{
+		Version:   "v1.2.0",
+		Commit:    "707a497",
+		BuildTime: "2024-01-01T00:00:00Z",
+		GoVersion: runtime.Version(),
+	}
+	handler := NewVersionHandler(info, zap.NewNop())
+
+	w := httptest.NewRecorder()
+	handler.GetVersion(w, httptest.NewRequest(http.MethodGet, "/version", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data version.Info `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, info, response.Data)
+}
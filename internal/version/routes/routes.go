@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"github.com/Abdelrahman-habib/expense-tracker/internal/version"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/version/handlers"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// Router encapsulates the version routes setup
+type Router struct {
+	handler *handlers.VersionHandler
+}
+
+// New creates a new version router reporting the build metadata of this binary
+func New(logger *zap.Logger) *Router {
+	return &Router{
+		handler: handlers.NewVersionHandler(version.Get(), logger),
+	}
+}
+
+// RegisterRoutes registers the version route, it is public so deploys can be
+// verified without a token
+func (r *Router) RegisterRoutes(router chi.Router) {
+	router.Get("/version", r.handler.GetVersion)
+}
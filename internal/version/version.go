@@ -0,0 +1,52 @@
+// Package version holds the build metadata of the binary. The variables are set at
+// build time, e.g.
+//
+//	go build -ldflags "-X github.com/Abdelrahman-habib/expense-tracker/internal/version.Version=v1.2.0"
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Header names the version of the server in every response
+const Header = "X-API-Version"
+
+// Set with -ldflags -X, the defaults mark a development build
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
+
+// Info is the build metadata of the running binary
+// @Description Build metadata of the running server
+type Info struct {
+	Version   string `json:"version" example:"v1.2.0"`
+	Commit    string `json:"commit" example:"707a497"`
+	BuildTime string `json:"buildTime" example:"2024-01-01T00:00:00Z"`
+	GoVersion string `json:"goVersion" example:"go1.23.4"`
+}
+
+// Get returns the build metadata. Without a commit from the ldflags it falls back to
+// the revision go build stamps when building from a git checkout.
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+
+	if info.Commit == "unknown" {
+		if build, ok := debug.ReadBuildInfo(); ok {
+			for _, setting := range build.Settings {
+				if setting.Key == "vcs.revision" && setting.Value != "" {
+					info.Commit = setting.Value
+				}
+			}
+		}
+	}
+
+	return info
+}
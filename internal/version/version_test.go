@@ -0,0 +1,28 @@
+package version
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGet(t *testing.T) {
+	info := Get()
+	assert.Equal(t, "dev", info.Version)
+	assert.Equal(t, "unknown", info.BuildTime)
+	assert.Equal(t, runtime.Version(), info.GoVersion)
+	assert.NotEmpty(t, info.Commit)
+
+	defer func(version, commit, buildTime string) {
+		Version, Commit, BuildTime = version, commit, buildTime
+	}(Version, Commit, BuildTime)
+	Version, Commit, BuildTime = "v1.2.0", "707a497", "2024-01-01T00:00:00Z"
+
+	assert.Equal(t, Info{
+		Version:   "v1.2.0",
+		Commit:    "707a497",
+		BuildTime: "2024-01-01T00:00:00Z",
+		GoVersion: runtime.Version(),
+	}, Get())
+}
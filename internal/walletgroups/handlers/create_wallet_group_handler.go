@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/walletgroups/types"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+)
+
+// CreateWalletGroup godoc
+// @Summary Create a wallet group
+// @Description Creates a wallet group, names are unique per user regardless of case
+// @Tags Wallet Groups
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body types.WalletGroupCreatePayload true "Wallet group creation request"
+// @Success 201 {object} payloads.Response{data=types.WalletGroup}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401  {object} errors.ErrorResponse
+// @Failure 409  {object} errors.ErrorResponse
+// @Failure 429  {object} errors.ErrorResponse
+// @Failure 500  {object} errors.ErrorResponse
+// @Router /wallet-groups [post]
+// @ID CreateWalletGroup
+func (h *WalletGroupHandler) CreateWalletGroup(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	var req types.WalletGroupCreatePayload
+	if !h.Bind(w, r, &req) {
+		return
+	}
+
+	group, err := h.service.CreateWalletGroup(r.Context(), userID, req)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.Created(group))
+}
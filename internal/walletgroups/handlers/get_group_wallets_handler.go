@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// GetGroupWallets godoc
+// @Summary List the wallets of a group
+// @Description Returns a wallet group with its wallets and their combined balance per currency
+// @Tags Wallet Groups
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Wallet group ID" format(uuid)
+// @Success 200 {object} payloads.Response{data=types.GroupWallets}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401  {object} errors.ErrorResponse
+// @Failure 404  {object} errors.ErrorResponse
+// @Failure 429  {object} errors.ErrorResponse
+// @Failure 500  {object} errors.ErrorResponse
+// @Router /wallet-groups/{id}/wallets [get]
+// @ID GetGroupWallets
+func (h *WalletGroupHandler) GetGroupWallets(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	groupID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	if !h.CheckQueryParams(w, r) {
+		return
+	}
+
+	groupWallets, err := h.service.GetGroupWallets(r.Context(), userID, groupID)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.OK(groupWallets))
+}
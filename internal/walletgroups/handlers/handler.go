@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/walletgroups/service"
+	"go.uber.org/zap"
+)
+
+type WalletGroupHandler struct {
+	handlers.BaseHandler
+	service service.WalletGroupService
+}
+
+func NewWalletGroupHandler(service service.WalletGroupService, logger *zap.Logger) *WalletGroupHandler {
+	return &WalletGroupHandler{
+		BaseHandler: handlers.NewBaseHandler(logger),
+		service:     service,
+	}
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+)
+
+// ListWalletGroups godoc
+// @Summary List wallet groups
+// @Description Lists the user's wallet groups by sort order
+// @Tags Wallet Groups
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} payloads.Response{data=[]types.WalletGroup}
+// @Failure 401  {object} errors.ErrorResponse
+// @Failure 429  {object} errors.ErrorResponse
+// @Failure 500  {object} errors.ErrorResponse
+// @Router /wallet-groups [get]
+// @ID ListWalletGroups
+func (h *WalletGroupHandler) ListWalletGroups(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	if !h.CheckQueryParams(w, r) {
+		return
+	}
+
+	groups, err := h.service.ListWalletGroups(r.Context(), userID)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.List(groups, len(groups)))
+}
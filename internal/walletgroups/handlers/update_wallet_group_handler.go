@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// UpdateWalletGroup godoc
+// @Summary Update a wallet group
+// @Description Renames or moves a wallet group
+// @Tags Wallet Groups
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Wallet group ID" format(uuid)
+// @Param request body types.WalletGroupUpdatePayload true "Wallet group update request"
+// @Success 200 {object} payloads.Response{data=types.WalletGroup}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401  {object} errors.ErrorResponse
+// @Failure 404  {object} errors.ErrorResponse
+// @Failure 409  {object} errors.ErrorResponse
+// @Failure 429  {object} errors.ErrorResponse
+// @Failure 500  {object} errors.ErrorResponse
+// @Router /wallet-groups/{id} [put]
+// @ID UpdateWalletGroup
+func (h *WalletGroupHandler) UpdateWalletGroup(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	groupID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	// Get existing group first
+	existingGroup, err := h.service.GetWalletGroup(r.Context(), userID, groupID)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	// Create update payload from existing group
+	updatePayload := existingGroup.ToUpdatePayload()
+
+	// Decode and validate onto the current values
+	if !h.BindUpdate(w, r, &updatePayload) {
+		return
+	}
+
+	group, err := h.service.UpdateWalletGroup(r.Context(), userID, updatePayload)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.Updated(group))
+}
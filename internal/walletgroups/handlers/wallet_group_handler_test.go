@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	coreErrors "github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/walletgroups/types"
+	walletTypes "github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// Mock service
+type mockWalletGroupService struct {
+	mock.Mock
+}
+
+func (m *mockWalletGroupService) ListWalletGroups(ctx context.Context, userID uuid.UUID) ([]types.WalletGroup, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]types.WalletGroup), args.Error(1)
+}
+
+func (m *mockWalletGroupService) GetWalletGroup(ctx context.Context, userID, groupID uuid.UUID) (types.WalletGroup, error) {
+	args := m.Called(ctx, userID, groupID)
+	return args.Get(0).(types.WalletGroup), args.Error(1)
+}
+
+func (m *mockWalletGroupService) CreateWalletGroup(ctx context.Context, userID uuid.UUID, payload types.WalletGroupCreatePayload) (types.WalletGroup, error) {
+	args := m.Called(ctx, userID, payload)
+	return args.Get(0).(types.WalletGroup), args.Error(1)
+}
+
+func (m *mockWalletGroupService) UpdateWalletGroup(ctx context.Context, userID uuid.UUID, payload types.WalletGroupUpdatePayload) (types.WalletGroup, error) {
+	args := m.Called(ctx, userID, payload)
+	return args.Get(0).(types.WalletGroup), args.Error(1)
+}
+
+func (m *mockWalletGroupService) DeleteWalletGroup(ctx context.Context, userID, groupID uuid.UUID) error {
+	args := m.Called(ctx, userID, groupID)
+	return args.Error(0)
+}
+
+func (m *mockWalletGroupService) GetGroupWallets(ctx context.Context, userID, groupID uuid.UUID) (types.GroupWallets, error) {
+	args := m.Called(ctx, userID, groupID)
+	return args.Get(0).(types.GroupWallets), args.Error(1)
+}
+
+func setupTest(t *testing.T) (*mockWalletGroupService, *WalletGroupHandler) {
+	mockService := new(mockWalletGroupService)
+	logger := zap.NewNop()
+	handler := NewWalletGroupHandler(mockService, logger)
+	return mockService, handler
+}
+
+// newRequest builds a request carrying the user and the group ID route parameter
+func newRequest(method, target, body string, userID uuid.UUID, groupID string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
+	if groupID != "" {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", groupID)
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+	}
+	return req.WithContext(ctx)
+}
+
+func int32Ptr(v int32) *int32 {
+	return &v
+}
+
+func TestWalletGroupHandler_ListWalletGroups(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+
+	groups := []types.WalletGroup{
+		{GroupID: uuid.New(), Name: "Savings", SortOrder: 0},
+		{GroupID: uuid.New(), Name: "Travel", SortOrder: 1},
+	}
+	mockService.On("ListWalletGroups", mock.Anything, userID).Return(groups, nil)
+
+	w := httptest.NewRecorder()
+	handler.ListWalletGroups(w, newRequest(http.MethodGet, "/wallet-groups", "", userID, ""))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	data := response["data"].([]interface{})
+	assert.Len(t, data, 2)
+	assert.Equal(t, "Savings", data[0].(map[string]interface{})["name"])
+	mockService.AssertExpectations(t)
+}
+
+func TestWalletGroupHandler_CreateWalletGroup(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+
+	tests := []struct {
+		name           string
+		payload        string
+		setupMock      func()
+		expectedStatus int
+	}{
+		{
+			name:    "successful creation",
+			payload: `{"name": "Savings"}`,
+			setupMock: func() {
+				mockService.On("CreateWalletGroup", mock.Anything, userID, types.WalletGroupCreatePayload{Name: "Savings"}).
+					Return(types.WalletGroup{GroupID: uuid.New(), Name: "Savings"}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:    "with sort order",
+			payload: `{"name": "Travel", "sortOrder": 3}`,
+			setupMock: func() {
+				mockService.On("CreateWalletGroup", mock.Anything, userID, types.WalletGroupCreatePayload{Name: "Travel", SortOrder: int32Ptr(3)}).
+					Return(types.WalletGroup{GroupID: uuid.New(), Name: "Travel", SortOrder: 3}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "missing name",
+			payload:        `{"name": ""}`,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "negative sort order",
+			payload:        `{"name": "Savings", "sortOrder": -1}`,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "duplicate name",
+			payload: `{"name": "savings"}`,
+			setupMock: func() {
+				mockService.On("CreateWalletGroup", mock.Anything, userID, mock.Anything).
+					Return(types.WalletGroup{}, &coreErrors.ErrorResponse{Type: coreErrors.ErrorTypeConflict, Message: "Failed to create wallet group: already exists"})
+			},
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			tt.setupMock()
+
+			w := httptest.NewRecorder()
+			handler.CreateWalletGroup(w, newRequest(http.MethodPost, "/wallet-groups", tt.payload, userID, ""))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWalletGroupHandler_UpdateWalletGroup(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	groupID := uuid.New()
+	existing := types.WalletGroup{GroupID: groupID, Name: "Savings", SortOrder: 2}
+
+	tests := []struct {
+		name           string
+		groupID        string
+		payload        string
+		setupMock      func()
+		expectedStatus int
+	}{
+		{
+			name:    "rename keeps the sort order",
+			groupID: groupID.String(),
+			payload: `{"name": "Rainy day"}`,
+			setupMock: func() {
+				mockService.On("GetWalletGroup", mock.Anything, userID, groupID).Return(existing, nil)
+				mockService.On("UpdateWalletGroup", mock.Anything, userID, types.WalletGroupUpdatePayload{
+					GroupID:   groupID,
+					Name:      "Rainy day",
+					SortOrder: int32Ptr(2),
+				}).Return(types.WalletGroup{GroupID: groupID, Name: "Rainy day", SortOrder: 2}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:    "group not found",
+			groupID: groupID.String(),
+			payload: `{"name": "Rainy day"}`,
+			setupMock: func() {
+				mockService.On("GetWalletGroup", mock.Anything, userID, groupID).
+					Return(types.WalletGroup{}, &coreErrors.ErrorResponse{Type: coreErrors.ErrorTypeNotFound, Message: "wallet group not found"})
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "invalid group ID",
+			groupID:        "invalid",
+			payload:        `{"name": "Rainy day"}`,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			tt.setupMock()
+
+			w := httptest.NewRecorder()
+			handler.UpdateWalletGroup(w, newRequest(http.MethodPut, "/wallet-groups/"+tt.groupID, tt.payload, userID, tt.groupID))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWalletGroupHandler_DeleteWalletGroup(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	groupID := uuid.New()
+
+	tests := []struct {
+		name           string
+		setupMock      func()
+		expectedStatus int
+	}{
+		{
+			name: "successful deletion",
+			setupMock: func() {
+				mockService.On("DeleteWalletGroup", mock.Anything, userID, groupID).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "group not found",
+			setupMock: func() {
+				mockService.On("DeleteWalletGroup", mock.Anything, userID, groupID).
+					Return(&coreErrors.ErrorResponse{Type: coreErrors.ErrorTypeNotFound, Message: "wallet group not found"})
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			tt.setupMock()
+
+			w := httptest.NewRecorder()
+			handler.DeleteWalletGroup(w, newRequest(http.MethodDelete, "/wallet-groups/"+groupID.String(), "", userID, groupID.String()))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWalletGroupHandler_GetGroupWallets(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	groupID := uuid.New()
+
+	tests := []struct {
+		name           string
+		setupMock      func()
+		expectedStatus int
+	}{
+		{
+			name: "group with wallets",
+			setupMock: func() {
+				balance := 150.0
+				mockService.On("GetGroupWallets", mock.Anything, userID, groupID).Return(types.GroupWallets{
+					Group: types.WalletGroup{GroupID: groupID, Name: "Savings"},
+					Wallets: []walletTypes.Wallet{
+						{WalletID: uuid.New(), Name: "Cash", Currency: "USD", Balance: &balance, GroupID: &groupID},
+					},
+					Balances: []types.CurrencyBalance{{Currency: "USD", Balance: 150, WalletCount: 1}},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "service error",
+			setupMock: func() {
+				mockService.On("GetGroupWallets", mock.Anything, userID, groupID).
+					Return(types.GroupWallets{}, fmt.Errorf("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			tt.setupMock()
+
+			w := httptest.NewRecorder()
+			handler.GetGroupWallets(w, newRequest(http.MethodGet, "/wallet-groups/"+groupID.String()+"/wallets", "", userID, groupID.String()))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response map[string]interface{}
+				assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				data := response["data"].(map[string]interface{})
+				assert.Len(t, data["wallets"], 1)
+				balances := data["balances"].([]interface{})
+				assert.Equal(t, "USD", balances[0].(map[string]interface{})["currency"])
+				assert.Equal(t, float64(150), balances[0].(map[string]interface{})["balance"])
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
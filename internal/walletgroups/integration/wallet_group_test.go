@@ -0,0 +1,336 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/config"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/validate"
+	groupHandlers "github.com/Abdelrahman-habib/expense-tracker/internal/walletgroups/handlers"
+	groupRepository "github.com/Abdelrahman-habib/expense-tracker/internal/walletgroups/repository"
+	groupService "github.com/Abdelrahman-habib/expense-tracker/internal/walletgroups/service"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/handlers"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/service"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"go.uber.org/zap"
+)
+
+type WalletGroupIntegrationTestSuite struct {
+	suite.Suite
+	container   testcontainers.Container
+	service     db.Service
+	pool        *pgxpool.Pool
+	router      *chi.Mux
+	userID      uuid.UUID
+	otherUserID uuid.UUID
+	ctx         context.Context
+}
+
+func TestWalletGroupIntegrationSuite(t *testing.T) {
+	suite.Run(t, new(WalletGroupIntegrationTestSuite))
+}
+
+func (s *WalletGroupIntegrationTestSuite) SetupSuite() {
+	s.ctx = context.Background()
+	s.userID = uuid.New()
+	s.otherUserID = uuid.New()
+
+	var host, port string
+
+	if os.Getenv("CI") == "true" {
+		// Running in GitHub Actions, use service-based PostgreSQL
+		host = "localhost"
+		port = "5432"
+	} else {
+		// Running locally, use TestContainers
+		req := testcontainers.ContainerRequest{
+			Image:        "postgres:15-alpine",
+			ExposedPorts: []string{"5432/tcp"},
+			WaitingFor:   wait.ForListeningPort("5432/tcp"),
+			Env: map[string]string{
+				"POSTGRES_DB":       "testdb",
+				"POSTGRES_USER":     "test",
+				"POSTGRES_PASSWORD": "test",
+			},
+			NetworkMode: "bridge",
+		}
+
+		container, err := testcontainers.GenericContainer(s.ctx, testcontainers.GenericContainerRequest{
+			ContainerRequest: req,
+			Started:          true,
+		})
+		require.NoError(s.T(), err)
+		s.container = container
+
+		host, err = container.Host(s.ctx)
+		require.NoError(s.T(), err)
+		mappedPort, err := container.MappedPort(s.ctx, "5432")
+		require.NoError(s.T(), err)
+		port = mappedPort.Port()
+	}
+
+	cfg := config.DatabaseConfig{
+		Host:        host,
+		Port:        port,
+		Username:    "test",
+		Password:    "test",
+		Database:    "testdb",
+		Schema:      "public",
+		MaxConns:    5,
+		MinConns:    1,
+		MaxLifetime: time.Hour,
+		MaxIdleTime: time.Minute * 30,
+		HealthCheck: time.Minute,
+		SSLMode:     "disable",
+		SearchPath:  "public",
+	}
+
+	dbService := db.NewService(cfg)
+	s.service = dbService
+
+	pool, err := pgxpool.New(s.ctx, cfg.GetDSN())
+	require.NoError(s.T(), err)
+	s.pool = pool
+
+	require.NoError(s.T(), s.runMigrations())
+
+	// Create test users
+	_, err = s.pool.Exec(s.ctx, `
+		INSERT INTO users (user_id, clerk_ex_user_id, name, email)
+		VALUES ($1, 'wgit_test_clerk_id', 'wgit_Test User', 'wgit_test@example.com'),
+		       ($2, 'wgit_other_clerk_id', 'wgit_Other User', 'wgit_other@example.com')
+	`, s.userID, s.otherUserID)
+	require.NoError(s.T(), err)
+
+	logger := zap.NewNop()
+	walletHandler := handlers.NewWalletHandler(service.NewWalletService(repository.NewWalletRepository(dbService.Queries()), validate.RoundHalfUp, nil, nil, nil, logger), coreTypes.DefaultLimitPolicy(), logger)
+	groupHandler := groupHandlers.NewWalletGroupHandler(groupService.NewWalletGroupService(groupRepository.NewWalletGroupRepository(dbService.Queries()), logger), logger)
+
+	router := chi.NewRouter()
+	router.Route("/wallets", func(r chi.Router) {
+		r.Get("/paginated", walletHandler.ListWalletsPaginated)
+		r.Post("/", walletHandler.CreateWallet)
+		r.Get("/{id}", walletHandler.GetWallet)
+	})
+	router.Route("/wallet-groups", func(r chi.Router) {
+		r.Get("/", groupHandler.ListWalletGroups)
+		r.Post("/", groupHandler.CreateWalletGroup)
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", groupHandler.GetWalletGroup)
+			r.Put("/", groupHandler.UpdateWalletGroup)
+			r.Delete("/", groupHandler.DeleteWalletGroup)
+			r.Get("/wallets", groupHandler.GetGroupWallets)
+		})
+	})
+	s.router = router
+}
+
+func (s *WalletGroupIntegrationTestSuite) TearDownSuite() {
+	if s.pool != nil {
+		_, _ = s.pool.Exec(s.ctx, "DELETE FROM users WHERE user_id IN ($1, $2)", s.userID, s.otherUserID)
+		s.pool.Close()
+	}
+	if s.service != nil {
+		s.service.Close()
+	}
+	if s.container != nil && os.Getenv("CI") != "true" {
+		err := s.container.Terminate(s.ctx)
+		require.NoError(s.T(), err)
+	}
+}
+
+func (s *WalletGroupIntegrationTestSuite) SetupTest() {
+	for _, table := range []string{"wallets", "wallet_groups"} {
+		_, err := s.pool.Exec(s.ctx, "DELETE FROM "+table+" WHERE user_id IN ($1, $2)", s.userID, s.otherUserID)
+		s.Require().NoError(err)
+	}
+}
+
+func (s *WalletGroupIntegrationTestSuite) runMigrations() error {
+	migrationsDir := "../../db/sql/migrations"
+
+	sqlDB := stdlib.OpenDBFromPool(s.pool)
+	defer sqlDB.Close()
+
+	if err := goose.SetDialect("postgres"); err != nil {
+		return err
+	}
+	return goose.Up(sqlDB, migrationsDir)
+}
+
+// do sends an authenticated request as the user and decodes the response
+func (s *WalletGroupIntegrationTestSuite) do(userID uuid.UUID, method, path string, body interface{}) (int, map[string]interface{}) {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		s.Require().NoError(err)
+		reader = bytes.NewReader(payload)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+
+	var response map[string]interface{}
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+	return w.Code, response
+}
+
+func (s *WalletGroupIntegrationTestSuite) createGroup(name string) string {
+	code, response := s.do(s.userID, http.MethodPost, "/wallet-groups", map[string]interface{}{"name": name})
+	s.Require().Equal(http.StatusCreated, code, response)
+	return response["data"].(map[string]interface{})["groupId"].(string)
+}
+
+func (s *WalletGroupIntegrationTestSuite) createWallet(name, currency string, balance float64, groupID string) string {
+	payload := map[string]interface{}{"name": name, "currency": currency, "balance": balance}
+	if groupID != "" {
+		payload["groupId"] = groupID
+	}
+	code, response := s.do(s.userID, http.MethodPost, "/wallets", payload)
+	s.Require().Equal(http.StatusCreated, code, response)
+	return response["data"].(map[string]interface{})["walletId"].(string)
+}
+
+// listWalletNames lists the names of the user's wallets matching the group_id filter
+func (s *WalletGroupIntegrationTestSuite) listWalletNames(groupFilter string) []string {
+	path := "/wallets/paginated"
+	if groupFilter != "" {
+		path += "?group_id=" + groupFilter
+	}
+	code, response := s.do(s.userID, http.MethodGet, path, nil)
+	s.Require().Equal(http.StatusOK, code, response)
+
+	var names []string
+	for _, wallet := range response["data"].([]interface{}) {
+		names = append(names, wallet.(map[string]interface{})["name"].(string))
+	}
+	return names
+}
+
+func (s *WalletGroupIntegrationTestSuite) TestGroupFilter() {
+	savings := s.createGroup("Savings")
+	travel := s.createGroup("Travel")
+	s.createWallet("Emergency", "USD", 100, savings)
+	s.createWallet("Flights", "EUR", 50, travel)
+	s.createWallet("Cash", "USD", 10, "")
+
+	s.ElementsMatch([]string{"Emergency"}, s.listWalletNames(savings))
+	s.ElementsMatch([]string{"Flights"}, s.listWalletNames(travel))
+	s.ElementsMatch([]string{"Cash"}, s.listWalletNames("none"))
+	s.ElementsMatch([]string{"Emergency", "Flights", "Cash"}, s.listWalletNames(""))
+
+	code, _ := s.do(s.userID, http.MethodGet, "/wallets/paginated?group_id=nope", nil)
+	s.Equal(http.StatusBadRequest, code)
+}
+
+func (s *WalletGroupIntegrationTestSuite) TestDeleteMovesWalletsToUngrouped() {
+	groupID := s.createGroup("Savings")
+	walletID := s.createWallet("Emergency", "USD", 100, groupID)
+
+	code, _ := s.do(s.userID, http.MethodDelete, "/wallet-groups/"+groupID, nil)
+	s.Equal(http.StatusOK, code)
+
+	// the wallet survives without a group
+	code, response := s.do(s.userID, http.MethodGet, "/wallets/"+walletID, nil)
+	s.Require().Equal(http.StatusOK, code)
+	s.NotContains(response["data"], "groupId")
+	s.ElementsMatch([]string{"Emergency"}, s.listWalletNames("none"))
+
+	code, _ = s.do(s.userID, http.MethodDelete, "/wallet-groups/"+groupID, nil)
+	s.Equal(http.StatusNotFound, code)
+	code, _ = s.do(s.userID, http.MethodGet, "/wallet-groups/"+groupID, nil)
+	s.Equal(http.StatusNotFound, code)
+}
+
+func (s *WalletGroupIntegrationTestSuite) TestNamesUniquePerUserIgnoringCase() {
+	s.createGroup("Savings")
+
+	code, _ := s.do(s.userID, http.MethodPost, "/wallet-groups", map[string]interface{}{"name": "SAVINGS"})
+	s.Equal(http.StatusConflict, code)
+
+	// another user can reuse the name
+	code, _ = s.do(s.otherUserID, http.MethodPost, "/wallet-groups", map[string]interface{}{"name": "Savings"})
+	s.Equal(http.StatusCreated, code)
+
+	travel := s.createGroup("Travel")
+	code, _ = s.do(s.userID, http.MethodPut, "/wallet-groups/"+travel, map[string]interface{}{"name": "savings"})
+	s.Equal(http.StatusConflict, code)
+}
+
+func (s *WalletGroupIntegrationTestSuite) TestSortOrder() {
+	s.createGroup("First")
+	s.createGroup("Second")
+	code, _ := s.do(s.userID, http.MethodPost, "/wallet-groups", map[string]interface{}{"name": "Top", "sortOrder": 0})
+	s.Require().Equal(http.StatusCreated, code)
+
+	code, response := s.do(s.userID, http.MethodGet, "/wallet-groups", nil)
+	s.Require().Equal(http.StatusOK, code)
+	var names []string
+	for _, group := range response["data"].([]interface{}) {
+		names = append(names, group.(map[string]interface{})["name"].(string))
+	}
+	// Top shares position 0 with First and sorts before it by name
+	s.Equal([]string{"First", "Top", "Second"}, names)
+}
+
+func (s *WalletGroupIntegrationTestSuite) TestGroupWalletsBalances() {
+	groupID := s.createGroup("Savings")
+	s.createWallet("Emergency", "USD", 100.25, groupID)
+	s.createWallet("Vacation", "USD", 49.75, groupID)
+	s.createWallet("Euro cash", "EUR", 20, groupID)
+	s.createWallet("Outside", "USD", 1000, "")
+
+	code, response := s.do(s.userID, http.MethodGet, "/wallet-groups/"+groupID+"/wallets", nil)
+	s.Require().Equal(http.StatusOK, code, response)
+
+	data := response["data"].(map[string]interface{})
+	s.Equal("Savings", data["group"].(map[string]interface{})["name"])
+	s.Len(data["wallets"], 3)
+
+	balances := data["balances"].([]interface{})
+	s.Require().Len(balances, 2)
+	eur := balances[0].(map[string]interface{})
+	usd := balances[1].(map[string]interface{})
+	s.Equal("EUR", eur["currency"])
+	s.Equal(float64(20), eur["balance"])
+	s.Equal("USD", usd["currency"])
+	s.Equal(float64(150), usd["balance"])
+	s.Equal(float64(2), usd["walletCount"])
+}
+
+func (s *WalletGroupIntegrationTestSuite) TestForeignGroupRejected() {
+	code, response := s.do(s.otherUserID, http.MethodPost, "/wallet-groups", map[string]interface{}{"name": "Theirs"})
+	s.Require().Equal(http.StatusCreated, code)
+	foreignGroup := response["data"].(map[string]interface{})["groupId"].(string)
+
+	code, _ = s.do(s.userID, http.MethodPost, "/wallets", map[string]interface{}{
+		"name": "Sneaky", "currency": "USD", "groupId": foreignGroup,
+	})
+	s.Equal(http.StatusBadRequest, code)
+
+	code, _ = s.do(s.userID, http.MethodGet, "/wallet-groups/"+foreignGroup+"/wallets", nil)
+	s.Equal(http.StatusNotFound, code)
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/walletgroups/types"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+)
+
+// CreateWalletGroup creates a new wallet group, names are unique per user regardless of case
+func (r *WalletGroupRepositoryImpl) CreateWalletGroup(ctx context.Context, userID uuid.UUID, payload types.WalletGroupCreatePayload) (types.WalletGroup, error) {
+	group, err := r.db.CreateWalletGroup(ctx, db.CreateWalletGroupParams{
+		UserID:    userID,
+		Name:      payload.Name,
+		SortOrder: toNullableInt4(payload.SortOrder),
+		ActorID:   requestcontext.GetActorIDFromContext(ctx, userID),
+	})
+	if err != nil {
+		return types.WalletGroup{}, errors.HandleRepositoryError(err, "create", "wallet group")
+	}
+
+	return toWalletGroup(group), nil
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+)
+
+// DeleteWalletGroup deletes a wallet group, the foreign key moves its wallets to ungrouped
+func (r *WalletGroupRepositoryImpl) DeleteWalletGroup(ctx context.Context, userID, groupID uuid.UUID) error {
+	deleted, err := r.db.DeleteWalletGroup(ctx, db.DeleteWalletGroupParams{
+		GroupID: groupID,
+		UserID:  userID,
+	})
+	if err != nil {
+		return errors.HandleRepositoryError(err, "delete", "wallet group")
+	}
+	if deleted == 0 {
+		return fmt.Errorf("delete wallet group %s: %w", groupID, repository.ErrNotFound)
+	}
+	return nil
+}
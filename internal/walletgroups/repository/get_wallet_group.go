@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/walletgroups/types"
+)
+
+// GetWalletGroup retrieves a wallet group by ID
+func (r *WalletGroupRepositoryImpl) GetWalletGroup(ctx context.Context, userID, groupID uuid.UUID) (types.WalletGroup, error) {
+	group, err := r.db.GetWalletGroup(ctx, db.GetWalletGroupParams{
+		GroupID: groupID,
+		UserID:  userID,
+	})
+	if err != nil {
+		return types.WalletGroup{}, errors.HandleRepositoryError(err, "get", "wallet group")
+	}
+
+	return toWalletGroup(group), nil
+}
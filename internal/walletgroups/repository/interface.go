@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/walletgroups/types"
+	walletTypes "github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+	"github.com/google/uuid"
+)
+
+// WalletGroupRepository defines the interface for wallet group data access
+type WalletGroupRepository interface {
+	// ListWalletGroups retrieves the user's wallet groups by sort order
+	ListWalletGroups(ctx context.Context, userID uuid.UUID) ([]types.WalletGroup, error)
+
+	// GetWalletGroup retrieves a wallet group by ID
+	GetWalletGroup(ctx context.Context, userID, groupID uuid.UUID) (types.WalletGroup, error)
+
+	// CreateWalletGroup creates a new wallet group
+	CreateWalletGroup(ctx context.Context, userID uuid.UUID, payload types.WalletGroupCreatePayload) (types.WalletGroup, error)
+
+	// UpdateWalletGroup updates an existing wallet group
+	UpdateWalletGroup(ctx context.Context, userID uuid.UUID, payload types.WalletGroupUpdatePayload) (types.WalletGroup, error)
+
+	// DeleteWalletGroup deletes a wallet group, its wallets become ungrouped
+	DeleteWalletGroup(ctx context.Context, userID, groupID uuid.UUID) error
+
+	// ListGroupWallets retrieves the wallets filed under a group
+	ListGroupWallets(ctx context.Context, userID, groupID uuid.UUID) ([]walletTypes.Wallet, error)
+
+	// GetGroupBalances sums the balances of a group's wallets per currency
+	GetGroupBalances(ctx context.Context, userID, groupID uuid.UUID) ([]types.CurrencyBalance, error)
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/walletgroups/types"
+	walletTypes "github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+)
+
+// ListGroupWallets retrieves the wallets filed under a group, deleted wallets excluded
+func (r *WalletGroupRepositoryImpl) ListGroupWallets(ctx context.Context, userID, groupID uuid.UUID) ([]walletTypes.Wallet, error) {
+	wallets, err := r.db.ListGroupWallets(ctx, db.ListGroupWalletsParams{
+		GroupID: utils.ToNullableUUID(groupID),
+		UserID:  userID,
+	})
+	if err != nil {
+		return []walletTypes.Wallet{}, errors.HandleRepositoryError(err, "list", "wallet(s)")
+	}
+
+	return toWallets(wallets), nil
+}
+
+// GetGroupBalances sums the balances of a group's wallets per currency
+func (r *WalletGroupRepositoryImpl) GetGroupBalances(ctx context.Context, userID, groupID uuid.UUID) ([]types.CurrencyBalance, error) {
+	rows, err := r.db.GetGroupBalances(ctx, db.GetGroupBalancesParams{
+		GroupID: utils.ToNullableUUID(groupID),
+		UserID:  userID,
+	})
+	if err != nil {
+		return []types.CurrencyBalance{}, errors.HandleRepositoryError(err, "get balances for", "wallet group")
+	}
+
+	balances := make([]types.CurrencyBalance, len(rows))
+	for i, row := range rows {
+		balances[i] = types.CurrencyBalance{
+			Currency:    row.Currency,
+			WalletCount: row.WalletCount,
+		}
+		if balance := utils.GetFloat64Ptr(row.Balance); balance != nil {
+			balances[i].Balance = *balance
+		}
+	}
+	return balances, nil
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/walletgroups/types"
+)
+
+// ListWalletGroups retrieves the user's wallet groups by sort order
+func (r *WalletGroupRepositoryImpl) ListWalletGroups(ctx context.Context, userID uuid.UUID) ([]types.WalletGroup, error) {
+	groups, err := r.db.ListWalletGroups(ctx, userID)
+	if err != nil {
+		return []types.WalletGroup{}, errors.HandleRepositoryError(err, "list", "wallet group(s)")
+	}
+
+	return toWalletGroups(groups), nil
+}
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/walletgroups/types"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+)
+
+// UpdateWalletGroup updates an existing wallet group
+func (r *WalletGroupRepositoryImpl) UpdateWalletGroup(ctx context.Context, userID uuid.UUID, payload types.WalletGroupUpdatePayload) (types.WalletGroup, error) {
+	group, err := r.db.UpdateWalletGroup(ctx, db.UpdateWalletGroupParams{
+		GroupID:   payload.GroupID,
+		UserID:    userID,
+		Name:      payload.Name,
+		SortOrder: toNullableInt4(payload.SortOrder),
+		ActorID:   requestcontext.GetActorIDFromContext(ctx, userID),
+	})
+	if err != nil {
+		return types.WalletGroup{}, errors.HandleRepositoryError(err, "update", "wallet group")
+	}
+
+	return toWalletGroup(group), nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"github.com/jackc/pgx/v5/pgtype"
+
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/walletgroups/types"
+	walletTypes "github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+)
+
+// toWalletGroup converts a db.WalletGroup to domain types.WalletGroup
+func toWalletGroup(g db.WalletGroup) types.WalletGroup {
+	return types.WalletGroup{
+		GroupID:   g.GroupID,
+		Name:      g.Name,
+		SortOrder: g.SortOrder,
+		CreatedAt: coreTypes.NewTimestamp(g.CreatedAt.Time),
+		UpdatedAt: coreTypes.NewTimestamp(g.UpdatedAt.Time),
+		CreatedBy: utils.GetUUIDPtr(g.CreatedBy),
+		UpdatedBy: utils.GetUUIDPtr(g.UpdatedBy),
+	}
+}
+
+// toWalletGroups converts a slice of db.WalletGroup to a slice of domain types.WalletGroup
+func toWalletGroups(groups []db.WalletGroup) []types.WalletGroup {
+	result := make([]types.WalletGroup, len(groups))
+	for i, g := range groups {
+		result[i] = toWalletGroup(g)
+	}
+	return result
+}
+
+// toWallets converts a slice of db.Wallet to a slice of domain wallets
+func toWallets(wallets []db.Wallet) []walletTypes.Wallet {
+	result := make([]walletTypes.Wallet, len(wallets))
+	for i, w := range wallets {
+		result[i] = walletTypes.Wallet{
+			WalletID:            w.WalletID,
+			UserID:              w.UserID,
+			ProjectID:           utils.GetUUIDPtr(w.ProjectID),
+			GroupID:             utils.GetUUIDPtr(w.GroupID),
+			Name:                w.Name,
+			Balance:             utils.GetFloat64Ptr(w.Balance),
+			Currency:            w.Currency,
+			Tags:                w.Tags,
+			LowBalanceThreshold: utils.GetFloat64Ptr(w.LowBalanceThreshold),
+			CreatedAt:           coreTypes.NewTimestamp(w.CreatedAt.Time),
+			UpdatedAt:           coreTypes.NewTimestamp(w.UpdatedAt.Time),
+			DeletedAt:           utils.GetTimestampPtr(w.DeletedAt),
+			CreatedBy:           utils.GetUUIDPtr(w.CreatedBy),
+			UpdatedBy:           utils.GetUUIDPtr(w.UpdatedBy),
+		}
+	}
+	return result
+}
+
+// toNullableInt4 converts an optional sort order, nil keeps the database default
+func toNullableInt4(v *int32) pgtype.Int4 {
+	if v == nil {
+		return pgtype.Int4{Valid: false}
+	}
+	return pgtype.Int4{Int32: *v, Valid: true}
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+)
+
+// WalletGroupRepositoryImpl implements WalletGroupRepository interface
+type WalletGroupRepositoryImpl struct {
+	db *db.Queries
+}
+
+// NewWalletGroupRepository creates a new instance of WalletGroupRepository
+func NewWalletGroupRepository(queries *db.Queries) WalletGroupRepository {
+	return &WalletGroupRepositoryImpl{
+		db: queries,
+	}
+}
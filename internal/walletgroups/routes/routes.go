@@ -0,0 +1,40 @@
+package routes
+
+import (
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/walletgroups/handlers"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/walletgroups/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/walletgroups/service"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// Router encapsulates the wallet group routes setup
+type Router struct {
+	handler *handlers.WalletGroupHandler
+}
+
+// New creates a new wallet group router with proper dependency injection
+func New(dbService db.Service, logger *zap.Logger) *Router {
+	repo := repository.NewWalletGroupRepository(dbService.Queries())
+	groupService := service.NewWalletGroupService(repo, logger)
+	handler := handlers.NewWalletGroupHandler(groupService, logger)
+
+	return &Router{
+		handler: handler,
+	}
+}
+
+// RegisterRoutes registers all wallet group routes
+func (r *Router) RegisterRoutes(router chi.Router) {
+	router.Route("/wallet-groups", func(router chi.Router) {
+		router.Get("/", r.handler.ListWalletGroups)
+		router.Post("/", r.handler.CreateWalletGroup)
+		router.Route("/{id}", func(router chi.Router) {
+			router.Get("/", r.handler.GetWalletGroup)
+			router.Put("/", r.handler.UpdateWalletGroup)
+			router.Delete("/", r.handler.DeleteWalletGroup)
+			router.Get("/wallets", r.handler.GetGroupWallets)
+		})
+	})
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/walletgroups/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/walletgroups/types"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type WalletGroupService interface {
+	ListWalletGroups(ctx context.Context, userID uuid.UUID) ([]types.WalletGroup, error)
+	GetWalletGroup(ctx context.Context, userID, groupID uuid.UUID) (types.WalletGroup, error)
+	CreateWalletGroup(ctx context.Context, userID uuid.UUID, payload types.WalletGroupCreatePayload) (types.WalletGroup, error)
+	UpdateWalletGroup(ctx context.Context, userID uuid.UUID, payload types.WalletGroupUpdatePayload) (types.WalletGroup, error)
+	DeleteWalletGroup(ctx context.Context, userID, groupID uuid.UUID) error
+	GetGroupWallets(ctx context.Context, userID, groupID uuid.UUID) (types.GroupWallets, error)
+}
+
+type walletGroupService struct {
+	repo   repository.WalletGroupRepository
+	logger *zap.Logger
+}
+
+func NewWalletGroupService(repo repository.WalletGroupRepository, logger *zap.Logger) WalletGroupService {
+	return &walletGroupService{
+		repo:   repo,
+		logger: logger.With(zap.String("component", "wallet_group_service")),
+	}
+}
+
+// validateWalletGroup checks the fields shared by create and update, trimming the name
+func validateWalletGroup(name *string, sortOrder *int32) error {
+	*name = strings.TrimSpace(*name)
+	if *name == "" {
+		return fmt.Errorf("wallet group name is required")
+	}
+
+	if len(*name) > types.MaxNameLength {
+		return fmt.Errorf("name exceeds maximum length")
+	}
+
+	if sortOrder != nil && *sortOrder < 0 {
+		return fmt.Errorf("sort order cannot be negative")
+	}
+
+	return nil
+}
+
+func (s *walletGroupService) ListWalletGroups(ctx context.Context, userID uuid.UUID) ([]types.WalletGroup, error) {
+	s.logger.Info("listing wallet groups",
+		zap.String("user_id", userID.String()))
+	return s.repo.ListWalletGroups(ctx, userID)
+}
+
+func (s *walletGroupService) GetWalletGroup(ctx context.Context, userID, groupID uuid.UUID) (types.WalletGroup, error) {
+	s.logger.Info("getting wallet group",
+		zap.String("user_id", userID.String()),
+		zap.String("group_id", groupID.String()))
+	return s.repo.GetWalletGroup(ctx, userID, groupID)
+}
+
+func (s *walletGroupService) CreateWalletGroup(ctx context.Context, userID uuid.UUID, payload types.WalletGroupCreatePayload) (types.WalletGroup, error) {
+	s.logger.Info("creating wallet group",
+		zap.String("user_id", userID.String()),
+		zap.String("name", payload.Name))
+
+	if err := validateWalletGroup(&payload.Name, payload.SortOrder); err != nil {
+		return types.WalletGroup{}, err
+	}
+
+	return s.repo.CreateWalletGroup(ctx, userID, payload)
+}
+
+func (s *walletGroupService) UpdateWalletGroup(ctx context.Context, userID uuid.UUID, payload types.WalletGroupUpdatePayload) (types.WalletGroup, error) {
+	s.logger.Info("updating wallet group",
+		zap.String("user_id", userID.String()),
+		zap.String("group_id", payload.GroupID.String()))
+
+	if err := validateWalletGroup(&payload.Name, payload.SortOrder); err != nil {
+		return types.WalletGroup{}, err
+	}
+
+	return s.repo.UpdateWalletGroup(ctx, userID, payload)
+}
+
+func (s *walletGroupService) DeleteWalletGroup(ctx context.Context, userID, groupID uuid.UUID) error {
+	s.logger.Info("deleting wallet group",
+		zap.String("user_id", userID.String()),
+		zap.String("group_id", groupID.String()))
+	return s.repo.DeleteWalletGroup(ctx, userID, groupID)
+}
+
+// GetGroupWallets returns the group with its wallets and their balances per currency
+func (s *walletGroupService) GetGroupWallets(ctx context.Context, userID, groupID uuid.UUID) (types.GroupWallets, error) {
+	s.logger.Info("getting group wallets",
+		zap.String("user_id", userID.String()),
+		zap.String("group_id", groupID.String()))
+
+	// an empty wallet list can't tell an empty group from a missing one
+	group, err := s.repo.GetWalletGroup(ctx, userID, groupID)
+	if err != nil {
+		return types.GroupWallets{}, err
+	}
+
+	wallets, err := s.repo.ListGroupWallets(ctx, userID, groupID)
+	if err != nil {
+		return types.GroupWallets{}, err
+	}
+
+	balances, err := s.repo.GetGroupBalances(ctx, userID, groupID)
+	if err != nil {
+		return types.GroupWallets{}, err
+	}
+
+	return types.GroupWallets{
+		Group:    group,
+		Wallets:  wallets,
+		Balances: balances,
+	}, nil
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	coreErrors "github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/walletgroups/types"
+	walletTypes "github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+type mockWalletGroupRepository struct {
+	mock.Mock
+}
+
+func (m *mockWalletGroupRepository) ListWalletGroups(ctx context.Context, userID uuid.UUID) ([]types.WalletGroup, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]types.WalletGroup), args.Error(1)
+}
+
+func (m *mockWalletGroupRepository) GetWalletGroup(ctx context.Context, userID, groupID uuid.UUID) (types.WalletGroup, error) {
+	args := m.Called(ctx, userID, groupID)
+	return args.Get(0).(types.WalletGroup), args.Error(1)
+}
+
+func (m *mockWalletGroupRepository) CreateWalletGroup(ctx context.Context, userID uuid.UUID, payload types.WalletGroupCreatePayload) (types.WalletGroup, error) {
+	args := m.Called(ctx, userID, payload)
+	return args.Get(0).(types.WalletGroup), args.Error(1)
+}
+
+func (m *mockWalletGroupRepository) UpdateWalletGroup(ctx context.Context, userID uuid.UUID, payload types.WalletGroupUpdatePayload) (types.WalletGroup, error) {
+	args := m.Called(ctx, userID, payload)
+	return args.Get(0).(types.WalletGroup), args.Error(1)
+}
+
+func (m *mockWalletGroupRepository) DeleteWalletGroup(ctx context.Context, userID, groupID uuid.UUID) error {
+	args := m.Called(ctx, userID, groupID)
+	return args.Error(0)
+}
+
+func (m *mockWalletGroupRepository) ListGroupWallets(ctx context.Context, userID, groupID uuid.UUID) ([]walletTypes.Wallet, error) {
+	args := m.Called(ctx, userID, groupID)
+	return args.Get(0).([]walletTypes.Wallet), args.Error(1)
+}
+
+func (m *mockWalletGroupRepository) GetGroupBalances(ctx context.Context, userID, groupID uuid.UUID) ([]types.CurrencyBalance, error) {
+	args := m.Called(ctx, userID, groupID)
+	return args.Get(0).([]types.CurrencyBalance), args.Error(1)
+}
+
+func setupTest(t *testing.T) (*mockWalletGroupRepository, WalletGroupService) {
+	mockRepo := new(mockWalletGroupRepository)
+	logger := zap.NewNop()
+	service := NewWalletGroupService(mockRepo, logger)
+	return mockRepo, service
+}
+
+func TestWalletGroupService_CreateWalletGroup(t *testing.T) {
+	mockRepo, service := setupTest(t)
+	ctx := context.Background()
+	userID := uuid.New()
+	negative := int32(-1)
+
+	tests := []struct {
+		name    string
+		payload types.WalletGroupCreatePayload
+		mock    func()
+		wantErr bool
+		errMsg  string
+	}{
+		{
+			name:    "trims the name",
+			payload: types.WalletGroupCreatePayload{Name: "  Savings  "},
+			mock: func() {
+				mockRepo.On("CreateWalletGroup", ctx, userID, types.WalletGroupCreatePayload{Name: "Savings"}).
+					Return(types.WalletGroup{Name: "Savings"}, nil)
+			},
+		},
+		{
+			name:    "blank name",
+			payload: types.WalletGroupCreatePayload{Name: "   "},
+			mock:    func() {},
+			wantErr: true,
+			errMsg:  "wallet group name is required",
+		},
+		{
+			name:    "negative sort order",
+			payload: types.WalletGroupCreatePayload{Name: "Savings", SortOrder: &negative},
+			mock:    func() {},
+			wantErr: true,
+			errMsg:  "sort order cannot be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.ExpectedCalls = nil
+			tt.mock()
+
+			group, err := service.CreateWalletGroup(ctx, userID, tt.payload)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, "Savings", group.Name)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWalletGroupService_GetGroupWallets(t *testing.T) {
+	mockRepo, service := setupTest(t)
+	ctx := context.Background()
+	userID := uuid.New()
+	groupID := uuid.New()
+
+	t.Run("combines the group, wallets and balances", func(t *testing.T) {
+		mockRepo.ExpectedCalls = nil
+		group := types.WalletGroup{GroupID: groupID, Name: "Savings"}
+		wallets := []walletTypes.Wallet{{WalletID: uuid.New(), Currency: "USD"}, {WalletID: uuid.New(), Currency: "EUR"}}
+		balances := []types.CurrencyBalance{{Currency: "EUR", Balance: 20, WalletCount: 1}, {Currency: "USD", Balance: 10, WalletCount: 1}}
+		mockRepo.On("GetWalletGroup", ctx, userID, groupID).Return(group, nil)
+		mockRepo.On("ListGroupWallets", ctx, userID, groupID).Return(wallets, nil)
+		mockRepo.On("GetGroupBalances", ctx, userID, groupID).Return(balances, nil)
+
+		result, err := service.GetGroupWallets(ctx, userID, groupID)
+		assert.NoError(t, err)
+		assert.Equal(t, group, result.Group)
+		assert.Equal(t, wallets, result.Wallets)
+		assert.Equal(t, balances, result.Balances)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("missing group", func(t *testing.T) {
+		mockRepo.ExpectedCalls = nil
+		mockRepo.Calls = nil
+		notFound := &coreErrors.ErrorResponse{Type: coreErrors.ErrorTypeNotFound, Message: "wallet group not found"}
+		mockRepo.On("GetWalletGroup", ctx, userID, groupID).Return(types.WalletGroup{}, notFound)
+
+		_, err := service.GetGroupWallets(ctx, userID, groupID)
+		assert.True(t, coreErrors.IsErrorType(err, coreErrors.ErrorTypeNotFound))
+		mockRepo.AssertNotCalled(t, "ListGroupWallets", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("wallets error", func(t *testing.T) {
+		mockRepo.ExpectedCalls = nil
+		mockRepo.On("GetWalletGroup", ctx, userID, groupID).Return(types.WalletGroup{GroupID: groupID}, nil)
+		mockRepo.On("ListGroupWallets", ctx, userID, groupID).Return([]walletTypes.Wallet{}, fmt.Errorf("database error"))
+
+		_, err := service.GetGroupWallets(ctx, userID, groupID)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,25 @@
+package types
+
+import "github.com/Abdelrahman-habib/expense-tracker/internal/core/schema"
+
+// SchemaVersion is bumped whenever a wallet group payload rule changes
+const SchemaVersion = 1
+
+// Schema describes the wallet group create and update payloads, mirroring their Bind rules
+func Schema() schema.Entity {
+	return schema.Entity{
+		Name:    "wallet-groups",
+		Title:   "Wallet group payloads",
+		Version: SchemaVersion,
+		Create:  schema.Object(walletGroupProperties(), "name"),
+		// the update is applied over the stored group so every field is optional
+		Update: schema.Object(walletGroupProperties()),
+	}
+}
+
+func walletGroupProperties() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"name":      schema.String().Length(1, MaxNameLength),
+		"sortOrder": schema.Integer().Min(0).Nullable(),
+	}
+}
@@ -0,0 +1,80 @@
+package types
+
+import (
+	"net/http"
+
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	walletTypes "github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+)
+
+const MaxNameLength = 100
+
+// WalletGroup represents a folder the user files wallets under
+// @Description Wallet group with its name and position among the user's groups
+type WalletGroup struct {
+	GroupID   uuid.UUID           `json:"groupId" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
+	Name      string              `json:"name" example:"Savings" minLength:"1" maxLength:"100"`
+	SortOrder int32               `json:"sortOrder" example:"0" minimum:"0"`
+	CreatedAt coreTypes.Timestamp `json:"createdAt" example:"2024-01-01T00:00:00.000Z" swaggertype:"string" format:"date-time"`
+	UpdatedAt coreTypes.Timestamp `json:"updatedAt" example:"2024-01-01T00:00:00.000Z" swaggertype:"string" format:"date-time"`
+	CreatedBy *uuid.UUID          `json:"createdBy,omitempty" example:"123e4567-e89b-12d3-a456-426614174001" format:"uuid"` // user who created the group
+	UpdatedBy *uuid.UUID          `json:"updatedBy,omitempty" example:"123e4567-e89b-12d3-a456-426614174001" format:"uuid"` // user behind the last update
+}
+
+// WalletGroupCreatePayload represents the payload for creating a wallet group
+// @Description Payload for creating a wallet group, without a sort order it is placed after the existing ones
+type WalletGroupCreatePayload struct {
+	Name      string `json:"name" example:"Savings" minLength:"1" maxLength:"100" validate:"required"`
+	SortOrder *int32 `json:"sortOrder" extensions:"x-nullable" example:"0" minimum:"0"`
+}
+
+// Bind implements render.Binder interface
+func (c *WalletGroupCreatePayload) Bind(r *http.Request) error {
+	return validation.Errors{
+		"name":       validation.Validate(c.Name, validation.Required, validation.Length(1, MaxNameLength)),
+		"sort_order": validation.Validate(c.SortOrder, validation.When(c.SortOrder != nil, validation.Min(int32(0)))),
+	}.Filter()
+}
+
+// WalletGroupUpdatePayload represents the payload for updating a wallet group
+// @Description Payload for renaming or moving a wallet group
+type WalletGroupUpdatePayload struct {
+	GroupID   uuid.UUID `json:"-" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
+	Name      string    `json:"name" example:"Savings" minLength:"1" maxLength:"100"`
+	SortOrder *int32    `json:"sortOrder" extensions:"x-nullable" example:"0" minimum:"0"`
+}
+
+// Bind implements render.Binder interface
+func (u *WalletGroupUpdatePayload) Bind(r *http.Request) error {
+	return validation.Errors{
+		"name":       validation.Validate(u.Name, validation.Required, validation.Length(1, MaxNameLength)),
+		"sort_order": validation.Validate(u.SortOrder, validation.When(u.SortOrder != nil, validation.Min(int32(0)))),
+	}.Filter()
+}
+
+func (g *WalletGroup) ToUpdatePayload() WalletGroupUpdatePayload {
+	sortOrder := g.SortOrder
+	return WalletGroupUpdatePayload{
+		GroupID:   g.GroupID,
+		Name:      g.Name,     // Non-optional
+		SortOrder: &sortOrder, // Optional
+	}
+}
+
+// CurrencyBalance is the combined balance of a group's wallets in one currency
+// @Description Sum of the balances of a group's wallets sharing a currency
+type CurrencyBalance struct {
+	Currency    string  `json:"currency" example:"USD" minLength:"3" maxLength:"3"`
+	Balance     float64 `json:"balance" example:"1500.50"`
+	WalletCount int64   `json:"walletCount" example:"2"`
+}
+
+// GroupWallets holds a wallet group with its wallets and their balances per currency
+// @Description Wallet group with its wallets and their combined balance per currency
+type GroupWallets struct {
+	Group    WalletGroup          `json:"group"`
+	Wallets  []walletTypes.Wallet `json:"wallets"`
+	Balances []CurrencyBalance    `json:"balances"`
+}
@@ -4,21 +4,22 @@ import (
 	"net/http"
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
 	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
-	"github.com/go-chi/render"
 )
 
 // CreateWallet godoc
 // @Summary Create a new wallet
-// @Description Creates a new wallet for the authenticated user
+// @Description Creates a new wallet for the authenticated user. Wallet names don't have to be unique, creating one with the name of another of the user's wallets, compared case-insensitively, adds a warning to the response meta.
 // @Tags Wallets
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param request body types.WalletCreatePayload true "Wallet creation request"
 // @Success 201 {object} payloads.Response{data=types.Wallet}
+// @Header 201 {string} ETag "version of the wallet, for If-Match"
 // @Failure 400 {object} errors.ErrorResponse
 // @Failure 401  {object} errors.ErrorResponse
 // @Failure 404  {object} errors.ErrorResponse
@@ -34,8 +35,7 @@ func (h *WalletHandler) CreateWallet(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req types.WalletCreatePayload
-	if err := render.Bind(r, &req); err != nil {
-		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+	if !h.Bind(w, r, &req) {
 		return
 	}
 
@@ -45,5 +45,6 @@ func (h *WalletHandler) CreateWallet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	handlers.SetETag(w, wallet.UpdatedAt.Time)
 	h.Respond(w, r, payloads.Created(wallet))
 }
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
@@ -12,16 +13,20 @@ import (
 
 // DeleteWallet godoc
 // @Summary Delete a wallet
-// @Description Deletes a wallet by ID
+// @Description Moves a wallet to the trash, it can be restored until the retention period passes. The wallet keeps its project and its ledger entries while in the trash.
+// @Description With purge=true the wallet, in the trash or not, is deleted for good along with its ledger entries.
 // @Tags Wallets
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Wallet ID" format(uuid)
+// @Param purge query bool false "delete the wallet for good instead of moving it to the trash"
+// @Param If-Match header string false "ETag of the wallet as last seen, the delete fails with a 412 if it changed since"
 // @Success 200 {object} payloads.Response
 // @Failure 400 {object} errors.ErrorResponse
 // @Failure 401  {object} errors.ErrorResponse
 // @Failure 404  {object} errors.ErrorResponse
+// @Failure 412  {object} errors.ErrorResponse
 // @Failure 429  {object} errors.ErrorResponse
 // @Failure 500  {object} errors.ErrorResponse
 // @Router /wallets/{id} [delete]
@@ -39,7 +44,20 @@ func (h *WalletHandler) DeleteWallet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.DeleteWallet(r.Context(), walletID, userID); err != nil {
+	version, ok := h.CheckIfMatch(w, r, func() (time.Time, error) {
+		wallet, err := h.service.GetWallet(r.Context(), walletID, userID)
+		return wallet.UpdatedAt.Time, err
+	})
+	if !ok {
+		return
+	}
+
+	if r.URL.Query().Get("purge") == "true" {
+		err = h.service.PurgeWallet(r.Context(), walletID, userID, version)
+	} else {
+		err = h.service.DeleteWallet(r.Context(), walletID, userID, version)
+	}
+	if err != nil {
 		h.HandleServiceError(w, r, err)
 		return
 	}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// ExportStatement godoc
+// @Summary Export a wallet statement
+// @Description Streams the wallet's ledger over the range as a bank-style CSV statement, oldest first. Each row splits the amount into debit or credit and carries the running balance starting from the balance at "from", a closing row totals both sides. Ranges over 2 years are rejected.
+// @Description With Accept: application/json the rows come as a JSON array instead, their dates in RFC 3339 whatever the format.
+// @Tags Wallets
+// @Produce text/csv
+// @Produce application/json
+// @Security BearerAuth
+// @Param id path string true "Wallet ID" format(uuid)
+// @Param from query string true "first day of the statement" format(date) example(2024-01-01)
+// @Param to query string true "last day of the statement" format(date) example(2024-12-31)
+// @Param format query string false "date format: iso (2024-01-31), us (01/31/2024) or eu (31/01/2024)" Enums(iso, us, eu) default(iso)
+// @Success 200 {string} string "date,description,debit,credit,balance"
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Failure 406 {object} errors.ErrorResponse
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /wallets/{id}/statement.csv [get]
+// @ID ExportWalletStatement
+func (h *WalletHandler) ExportStatement(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	walletID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	if !h.CheckQueryParams(w, r, "from", "to", "format") {
+		return
+	}
+
+	params, err := types.ParseStatementParams(r.URL.Query())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	// format picks the date format of the rows, only the Accept header picks the media type
+	mediaType, err := handlers.NegotiateFormat(r, "", handlers.MediaTypeCSV, handlers.MediaTypeJSON)
+	if err != nil {
+		h.RespondError(w, r, errors.ErrNotAcceptable(err))
+		return
+	}
+
+	if mediaType == handlers.MediaTypeJSON {
+		h.StreamJSON(w, r, func(write func(item any) error) error {
+			return h.service.ExportStatement(r.Context(), walletID, userID, params, func(row types.StatementRow) error {
+				return write(row)
+			})
+		})
+		return
+	}
+
+	h.StreamCSV(w, r, types.StatementCSVHeader, func(write func(record []string) error) error {
+		return h.service.ExportStatement(r.Context(), walletID, userID, params, func(row types.StatementRow) error {
+			return write(row.CSVRecord(params.Format))
+		})
+	})
+}
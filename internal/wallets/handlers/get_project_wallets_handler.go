@@ -33,6 +33,10 @@ func (h *WalletHandler) GetProjectWallets(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	if !h.CheckQueryParams(w, r) {
+		return
+	}
+
 	projectID, err := uuid.Parse(chi.URLParam(r, "project_id"))
 	if err != nil {
 		h.RespondError(w, r, errors.ErrInvalidRequest(err))
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
 	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
 	"github.com/go-chi/chi/v5"
@@ -12,13 +13,15 @@ import (
 
 // GetWallet godoc
 // @Summary Get a wallet
-// @Description Retrieves a wallet by ID
+// @Description Retrieves a wallet by ID, with include_stats=true along with its outflows and inflows over the trailing 7, 30 and 90 days. Days are UTC calendar days, today included.
 // @Tags Wallets
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Wallet ID" format(uuid)
+// @Param include_stats query bool false "include the spending stats"
 // @Success 200 {object} payloads.Response{data=types.Wallet}
+// @Header 200 {string} ETag "version of the wallet, for If-Match"
 // @Failure 400 {object} errors.ErrorResponse
 // @Failure 401  {object} errors.ErrorResponse
 // @Failure 404  {object} errors.ErrorResponse
@@ -39,11 +42,17 @@ func (h *WalletHandler) GetWallet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	wallet, err := h.service.GetWallet(r.Context(), walletID, userID)
+	get := h.service.GetWallet
+	if r.URL.Query().Get("include_stats") == "true" {
+		get = h.service.GetWalletWithStats
+	}
+
+	wallet, err := get(r.Context(), walletID, userID)
 	if err != nil {
 		h.HandleServiceError(w, r, err)
 		return
 	}
 
+	handlers.SetETag(w, wallet.UpdatedAt.Time)
 	h.Respond(w, r, payloads.OK(wallet))
 }
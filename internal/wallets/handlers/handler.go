@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/service"
 	"go.uber.org/zap"
 )
@@ -9,11 +10,13 @@ import (
 type WalletHandler struct {
 	handlers.BaseHandler
 	service service.WalletService
+	limits  coreTypes.LimitPolicy
 }
 
-func NewWalletHandler(service service.WalletService, logger *zap.Logger) *WalletHandler {
+func NewWalletHandler(service service.WalletService, limits coreTypes.LimitPolicy, logger *zap.Logger) *WalletHandler {
 	return &WalletHandler{
 		BaseHandler: handlers.NewBaseHandler(logger),
 		service:     service,
+		limits:      limits,
 	}
 }
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/google/uuid"
+)
+
+// ListDeletedWallets godoc
+// @Summary List trashed Wallets
+// @Description Returns a paginated list of the Wallets in the trash, most recently deleted first. Trashed Wallets are purged once the retention period passes.
+// @Tags Wallets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param limit query integer false "Number of Wallets to return" minimum(1) maximum(100) default(10)
+// @Param order query string false "Sort order by deletion time" Enums(asc, desc) default(desc)
+// @Param next_token query string false "Token for the next page"
+// @Success 200 {object} payloads.Response{data=[]types.Wallet}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /wallets/trash [get]
+// @ID ListDeletedWallets
+func (h *WalletHandler) ListDeletedWallets(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	if !h.CheckQueryParams(w, r, types.PaginationQueryParams...) {
+		return
+	}
+
+	params, ok := h.ParsePagination(w, r, h.limits, userID)
+	if !ok {
+		return
+	}
+
+	var cursor time.Time
+	var cursorID uuid.UUID
+	if params.Cursor != nil {
+		cursor = params.Cursor.Timestamp
+		cursorID = params.Cursor.ID
+	} else {
+		cursor, cursorID = types.StartCursor(params.Order)
+	}
+
+	wallets, err := h.service.ListDeletedWalletsPaginated(r.Context(), userID, cursor, cursorID, params.Limit, params.Order)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	// Trashed items are paged by when they were deleted
+	var nextToken string
+	if len(wallets) > 0 && len(wallets) == int(params.Limit) {
+		last := wallets[len(wallets)-1]
+		if last.DeletedAt != nil {
+			nextToken = params.NextToken(last.DeletedAt.Time, last.WalletID, userID)
+		}
+	}
+
+	h.Respond(w, r, payloads.Paginated(
+		wallets,
+		nextToken,
+		params.Limit,
+	))
+}
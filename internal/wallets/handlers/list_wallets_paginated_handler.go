@@ -1,26 +1,46 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
+	"slices"
 	"time"
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	walletTypes "github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
 	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
 	"github.com/google/uuid"
 )
 
 // ListWalletsPaginated godoc
 // @Summary List wallets with pagination
-// @Description Returns a paginated list of wallets
+// @Description Returns a paginated list of wallets, the pinned ones first, optionally narrowed by group, tags, currency and whether they belong to a project.
+// @Description The filters given all have to match, and a next_token only continues the list it was issued for.
+// @Description With expand=project each wallet embeds the ID and name of its project, null when it is outside any project.
+// @Description With stream=true or Accept: application/x-ndjson the page is streamed as newline-delimited JSON instead, one wallet per line followed by a {"meta":{"count","next_token"}} line, or an {"error":...} line when the stream fails midway. expand isn't supported when streaming.
+// @Description With only_ids=true the data is only the IDs of the page and the next_token, {"ids":[...],"next_token":...}, read without the rest of the wallets. expand isn't supported with it.
+// @Description With ids it returns the wallets with those IDs instead, in the order asked for, and names the IDs not found in meta.missing.
 // @Tags Wallets
 // @Accept json
 // @Produce json
+// @Produce application/x-ndjson
 // @Security BearerAuth
 // @Param limit query integer false "Number of wallets to return" minimum(1) maximum(100) default(10)
+// @Param order query string false "Sort order by creation time" Enums(asc, desc) default(desc)
 // @Param next_token query string false "Token for the next page"
-// @Success 200 {object} payloads.Response{data=[]types.Wallet}
+// @Param group_id query string false "Only wallets of this group, or none for ungrouped wallets"
+// @Param tags query string false "Comma separated tag IDs, at most 10, only wallets carrying them"
+// @Param tag_match query string false "Whether wallets need any or all of the tags" Enums(any, all) default(any)
+// @Param currency query string false "Only wallets holding this ISO 4217 currency"
+// @Param has_project query boolean false "Only wallets inside a project when true, outside any when false"
+// @Param expand query string false "comma separated related resources to include" Enums(project)
+// @Param stream query boolean false "Stream the page as newline-delimited JSON"
+// @Param only_ids query boolean false "Return only the IDs of the page, not with stream"
+// @Param ids query string false "Comma separated IDs of the wallets to fetch, at most 100, other parameters aren't allowed with it"
+// @Success 200 {object} payloads.Response{data=[]types.WalletWithProject} "wallets, without the project field unless expanded"
+// @Success 200 {object} payloads.Response{data=payloads.IDPage} "only_ids=true"
 // @Failure 400 {object} errors.ErrorResponse
 // @Failure 401  {object} errors.ErrorResponse
 // @Failure 429  {object} errors.ErrorResponse
@@ -34,25 +54,78 @@ func (h *WalletHandler) ListWalletsPaginated(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	if r.URL.Query().Has(types.IDsQueryParam) {
+		h.getWalletsByIDs(w, r, userID)
+		return
+	}
+
+	if !h.CheckQueryParams(w, r, append(slices.Clone(walletTypes.ListQueryParams), walletTypes.ExpandQueryParam, types.StreamParam, types.OnlyIDsParam)...) {
+		return
+	}
+
 	// Parse and validate pagination parameters
-	params, err := types.ParsePaginationParams(r.URL.Query())
+	params, ok := h.ParsePagination(w, r, h.limits, userID, walletTypes.ListQueryParams...)
+	if !ok {
+		return
+	}
+
+	filter, err := walletTypes.ParseWalletFilter(r.URL.Query())
 	if err != nil {
 		h.RespondError(w, r, errors.ErrInvalidRequest(err))
 		return
 	}
 
-	// Set default cursor values if not provided
+	expand, err := walletTypes.ParseWalletExpand(r.URL.Query())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	stream, ok := h.ParseStream(w, r)
+	if !ok {
+		return
+	}
+	if stream && expand.Project {
+		h.RespondError(w, r, errors.ErrInvalidRequest(fmt.Errorf("%s: not supported when streaming", walletTypes.ExpandQueryParam)))
+		return
+	}
+	onlyIDs, ok := h.ParseOnlyIDs(w, r, stream)
+	if !ok {
+		return
+	}
+	if onlyIDs && expand.Project {
+		h.RespondError(w, r, errors.ErrInvalidRequest(fmt.Errorf("%s: not supported with %s", walletTypes.ExpandQueryParam, types.OnlyIDsParam)))
+		return
+	}
+
+	// Set default cursor values if not provided, the first page starts with the pinned wallets
 	var cursor time.Time
 	var cursorID uuid.UUID
+	pinned := true
 	if params.Cursor != nil {
 		cursor = params.Cursor.Timestamp
 		cursorID = params.Cursor.ID
+		pinned = params.Cursor.Pinned
 	} else {
-		cursor = time.Now().UTC()
-		cursorID = uuid.Nil
+		cursor, cursorID = types.StartPinnedCursor()
 	}
 
-	wallets, err := h.service.ListWalletsPaginated(r.Context(), userID, cursor, cursorID, params.Limit)
+	if stream {
+		h.streamWallets(w, r, userID, params, cursor, cursorID, pinned, filter)
+		return
+	}
+
+	if onlyIDs {
+		keys, err := h.service.ListWalletIDsPaginated(r.Context(), userID, cursor, cursorID, pinned, params.Limit, params.Order, filter)
+		if err != nil {
+			h.HandleServiceError(w, r, err)
+			return
+		}
+		h.RespondIDs(w, r, params, keys, userID)
+		return
+	}
+
+	wallets, err := h.service.ListWalletsPaginated(r.Context(), userID, cursor, cursorID, pinned, params.Limit, params.Order, filter)
 	if err != nil {
 		h.HandleServiceError(w, r, err)
 		return
@@ -60,8 +133,17 @@ func (h *WalletHandler) ListWalletsPaginated(w http.ResponseWriter, r *http.Requ
 
 	var nextToken string
 	if len(wallets) > 0 && len(wallets) == int(params.Limit) {
-		lastWallet := wallets[len(wallets)-1]
-		nextToken = types.EncodeCursor(lastWallet.CreatedAt, lastWallet.WalletID)
+		nextToken = walletNextToken(params, wallets[len(wallets)-1], userID)
+	}
+
+	if expand.Project {
+		expanded, err := h.service.ExpandWalletProjects(r.Context(), userID, wallets)
+		if err != nil {
+			h.HandleServiceError(w, r, err)
+			return
+		}
+		h.Respond(w, r, payloads.Paginated(expanded, nextToken, params.Limit))
+		return
 	}
 
 	h.Respond(w, r, payloads.Paginated(
@@ -70,3 +152,48 @@ func (h *WalletHandler) ListWalletsPaginated(w http.ResponseWriter, r *http.Requ
 		params.Limit,
 	))
 }
+
+// streamWallets streams the page of wallets as NDJSON
+func (h *WalletHandler) streamWallets(w http.ResponseWriter, r *http.Request, userID uuid.UUID, params types.PaginationParams, cursor time.Time, cursorID uuid.UUID, pinned bool, filter walletTypes.WalletFilter) {
+	h.StreamNDJSON(w, r, func(write func(item any) error) (string, error) {
+		var last walletTypes.Wallet
+		count := 0
+		err := h.service.StreamWalletsPaginated(r.Context(), userID, cursor, cursorID, pinned, params.Limit, params.Order, filter, func(wallet walletTypes.Wallet) error {
+			last = wallet
+			count++
+			return write(wallet)
+		})
+		if err != nil || count < int(params.Limit) {
+			return "", err
+		}
+		return walletNextToken(params, last, userID), nil
+	})
+}
+
+// walletNextToken returns the token of the page after the one ending on last, a page
+// ending on a pinned wallet continues among the pinned ones
+func walletNextToken(params types.PaginationParams, last walletTypes.Wallet, userID uuid.UUID) string {
+	if last.PinnedAt != nil {
+		return params.NextPinnedToken(last.PinnedAt.Time, last.WalletID, userID)
+	}
+	return params.NextToken(last.CreatedAt.Time, last.WalletID, userID)
+}
+
+// getWalletsByIDs responds with the user's wallets of the ids query parameter
+func (h *WalletHandler) getWalletsByIDs(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+	if !h.CheckQueryParams(w, r, types.IDsQueryParam) {
+		return
+	}
+	ids, ok := h.ParseIDs(w, r)
+	if !ok {
+		return
+	}
+
+	wallets, missing, err := h.service.GetWalletsByIDs(r.Context(), userID, ids)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.Batch(wallets, len(wallets), missing))
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+)
+
+// NetWorth godoc
+// @Summary Get the net worth
+// @Description Sums the balances of the user's wallets by currency, wallets without a balance count as empty. With convert_to the totals are also converted and added up in that currency, along with the rate each currency was converted at; a currency without an exchange rate fails the conversion. A user without wallets gets no totals.
+// @Tags Wallets
+// @Produce json
+// @Security BearerAuth
+// @Param include_archived query bool false "sum the wallets in the trash too"
+// @Param convert_to query string false "currency to convert the totals to and add them up in" example(USD)
+// @Success 200 {object} payloads.Response{data=types.NetWorth}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /me/net-worth [get]
+// @ID GetNetWorth
+func (h *WalletHandler) NetWorth(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	if !h.CheckQueryParams(w, r, types.NetWorthQueryParams...) {
+		return
+	}
+
+	params, err := types.ParseNetWorthParams(r.URL.Query())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	netWorth, err := h.service.NetWorth(r.Context(), userID, params)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.OK(netWorth))
+}
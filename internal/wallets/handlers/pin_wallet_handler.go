@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// PinWallet godoc
+// @Summary Pin a Wallet
+// @Description Puts the Wallet at the top of the paginated list, at most 10 wallets can be pinned
+// @Tags Wallets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Wallet ID" format(uuid)
+// @Success 200 {object} payloads.Response{data=types.Wallet}
+// @Header 200 {string} ETag "version of the wallet, for If-Match"
+// @Failure 400 {object} errors.ErrorResponse "Invalid ID or too many pinned wallets"
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /wallets/{id}/pin [post]
+// @ID PinWallet
+func (h *WalletHandler) PinWallet(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	walletID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	wallet, err := h.service.PinWallet(r.Context(), walletID, userID)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	handlers.SetETag(w, wallet.UpdatedAt.Time)
+	h.Respond(w, r, payloads.Updated(wallet))
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// ProjectBalance godoc
+// @Summary Project a wallet's balance
+// @Description Projects the wallet's balance month by month from its current balance, adding the contribution at the end of each month and compounding the annual rate monthly. Negative rates model fees and negative contributions withdrawals. Nothing is saved.
+// @Tags Wallets
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Wallet ID" format(uuid)
+// @Param monthly_contribution query number false "added at the end of every month" default(0) example(200)
+// @Param annual_rate query number false "nominal annual rate compounded monthly, 0.02 for 2%" minimum(-1) maximum(1) default(0) example(0.02)
+// @Param months query int false "months to project" minimum(1) maximum(600) default(12) example(24)
+// @Success 200 {object} payloads.Response{data=types.Projection}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /wallets/{id}/projection [get]
+// @ID ProjectWalletBalance
+func (h *WalletHandler) ProjectBalance(w http.ResponseWriter, r *http.Request) {
+	userID, err := requestcontext.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrAuthorization(err))
+		return
+	}
+
+	walletID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	if !h.CheckQueryParams(w, r, types.ProjectionQueryParams...) {
+		return
+	}
+
+	params, err := types.ParseProjectionParams(r.URL.Query())
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+
+	projection, err := h.service.ProjectBalance(r.Context(), walletID, userID, params)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.Respond(w, r, payloads.OK(projection))
+}
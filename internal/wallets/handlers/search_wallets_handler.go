@@ -5,21 +5,28 @@ import (
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/ranking"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	walletTypes "github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
 	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
 )
 
 // SearchWallets godoc
 // @Summary Search wallets
-// @Description Searches for wallets based on a query string
+// @Description Searches for wallets based on a query string. With view=picker each wallet only carries its ID, name, balance and currency. With a query each wallet is scored, and trim=auto drops the wallets after the largest fall in score.
+// @Description The closest matches come first, wallets scoring the same are ordered by the shorter name, then the newest first, so the order of a search never changes between requests.
 // @Tags Wallets
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param q query string true "Search query" minLength(1) maxLength(100)
 // @Param limit query integer false "Maximum number of results" minimum(1) maximum(50) default(10)
-// @Success 200 {object} payloads.Response{data=[]types.Wallet}
-// @Failure 400 {object} errors.ErrorResponse
+// @Param next_token query string false "Token for the next page of results, paging stops at the configured search window"
+// @Param view query string false "Shape of the wallets, picker returns only what a picker shows" Enums(full, picker) default(full)
+// @Param trim query string false "auto cuts the results after the largest fall in score, keeping at least the configured minimum" Enums(off, auto) default(off)
+// @Success 200 {object} payloads.Response{data=[]types.Wallet} "view=full"
+// @Success 200 {object} payloads.Response{data=[]walletTypes.WalletPickerItem} "view=picker"
+// @Failure 400 {object} errors.ErrorResponse "Invalid parameters, or QUERY_TOO_COMPLEX for a query with too many distinct words or one that timed out"
 // @Failure 401  {object} errors.ErrorResponse
 // @Failure 429  {object} errors.ErrorResponse
 // @Failure 500  {object} errors.ErrorResponse
@@ -32,24 +39,74 @@ func (h *WalletHandler) SearchWallets(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.CheckQueryParams(w, r, walletTypes.SearchQueryParams...) {
+		return
+	}
+
 	// Parse query parameters
 	query := r.URL.Query()
-	params, err := types.ParseAndValidateSearchParams(query)
+	params, err := types.ParseTrigramSearchParams(query, h.limits)
+	if err != nil {
+		h.RespondInvalidSearch(w, r, err)
+		return
+	}
+	view, err := types.ParseView(query)
+	if err != nil {
+		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+		return
+	}
+	trim, err := types.ParseTrim(query, view)
 	if err != nil {
 		h.RespondError(w, r, errors.ErrInvalidRequest(err))
 		return
 	}
+	if !h.CheckSearchWindow(w, r, &params) {
+		return
+	}
+	ctx, cancel := h.SearchContext(r)
+	defer cancel()
 
-	wallets, err := h.service.SearchWallets(r.Context(), userID, params.Query, params.Limit)
+	if view == types.ViewPicker {
+		items, err := h.service.SearchWalletsPicker(ctx, userID, params.Query, params.Limit, params.Offset)
+		if err != nil {
+			h.HandleSearchError(w, r, ctx, err)
+			return
+		}
+		h.Respond(w, r, payloads.PaginatedSearchView(
+			items,
+			params.Query,
+			params.Limit,
+			len(items),
+			params.NextToken(len(items)),
+			view,
+		))
+		return
+	}
+
+	wallets, err := h.service.SearchWallets(ctx, userID, params.Query, params.Limit, params.Offset)
 	if err != nil {
-		h.HandleServiceError(w, r, err)
+		h.HandleSearchError(w, r, ctx, err)
 		return
 	}
 
-	h.Respond(w, r, payloads.Search(
+	nextToken := params.NextToken(len(wallets))
+	var cut *ranking.Trim
+	if trim {
+		wallets, cut = ranking.TrimAtCliff(wallets, int(h.limits.MinTrimmedResults), func(wallet walletTypes.Wallet) *float32 {
+			return wallet.Score
+		})
+	}
+
+	resp := payloads.PaginatedSearchView(
 		wallets,
 		params.Query,
 		params.Limit,
 		len(wallets),
-	))
+		nextToken,
+		view,
+	)
+	if cut != nil {
+		resp = payloads.WithTrim(resp, cut.Trimmed, cut.Cutoff)
+	}
+	h.Respond(w, r, resp)
 }
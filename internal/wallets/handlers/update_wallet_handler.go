@@ -1,19 +1,21 @@
 package handlers
 
 import (
+	stdErrors "errors"
 	"net/http"
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/payloads"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
 	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/render"
 	"github.com/google/uuid"
 )
 
 // UpdateWallet godoc
 // @Summary Update a wallet
-// @Description Updates an existing wallet
+// @Description Updates an existing wallet. The URL names the wallet, a walletId in the body naming another one is refused with ID_MISMATCH. Concurrent updates of a wallet apply one after the other.
 // @Tags Wallets
 // @Accept json
 // @Produce json
@@ -21,9 +23,11 @@ import (
 // @Param id path string true "Wallet ID" format(uuid)
 // @Param request body types.WalletUpdatePayload true "Wallet update request"
 // @Success 200 {object} payloads.Response{data=types.Wallet}
+// @Header 200 {string} ETag "version of the wallet, for If-Match"
 // @Failure 400 {object} errors.ErrorResponse
 // @Failure 401  {object} errors.ErrorResponse
 // @Failure 404  {object} errors.ErrorResponse
+// @Failure 409  {object} errors.ErrorResponse "CONCURRENT_MODIFICATION when another update of the wallet didn't finish in time"
 // @Failure 429  {object} errors.ErrorResponse
 // @Failure 500  {object} errors.ErrorResponse
 // @Router /wallets/{id} [put]
@@ -41,27 +45,24 @@ func (h *WalletHandler) UpdateWallet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get existing wallet first
-	existingWallet, err := h.service.GetWallet(r.Context(), walletID, userID)
-	if err != nil {
-		h.HandleServiceError(w, r, err)
-		return
-	}
-
-	// Create update payload from existing wallet
-	updatePayload := existingWallet.ToUpdatePayload()
-
-	// Use render.Bind to decode and validate
-	if err := render.Bind(r, &updatePayload); err != nil {
-		h.RespondError(w, r, errors.ErrInvalidRequest(err))
+	// Decode and validate onto the current values, read under the wallet's lock so a
+	// concurrent update can't change them before this one is written
+	wallet, err := h.service.UpdateWallet(r.Context(), walletID, userID, func(existing types.Wallet) (types.WalletUpdatePayload, error) {
+		updatePayload := existing.ToUpdatePayload()
+		// the URL names the wallet
+		if !h.BindEntityUpdate(w, r, &updatePayload, "walletId", walletID, &updatePayload.WalletID) {
+			return types.WalletUpdatePayload{}, handlers.ErrResponded
+		}
+		return updatePayload, nil
+	})
+	if stdErrors.Is(err, handlers.ErrResponded) {
 		return
 	}
-
-	wallet, err := h.service.UpdateWallet(r.Context(), updatePayload, userID)
 	if err != nil {
 		h.HandleServiceError(w, r, err)
 		return
 	}
 
+	handlers.SetETag(w, wallet.UpdatedAt.Time)
 	h.Respond(w, r, payloads.Updated(wallet))
 }
@@ -11,13 +11,20 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/deletion"
+	coreErrors "github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	coreHandlers "github.com/Abdelrahman-habib/expense-tracker/internal/core/handlers"
+	coreRepository "github.com/Abdelrahman-habib/expense-tracker/internal/core/repository"
 	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/service"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
 	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
@@ -26,53 +33,178 @@ type mockWalletService struct {
 	mock.Mock
 }
 
+func (m *mockWalletService) GetWalletsByIDs(ctx context.Context, userID uuid.UUID, walletIDs []uuid.UUID) ([]types.Wallet, []uuid.UUID, error) {
+	args := m.Called(ctx, userID, walletIDs)
+	return args.Get(0).([]types.Wallet), args.Get(1).([]uuid.UUID), args.Error(2)
+}
+
 func (m *mockWalletService) GetWallet(ctx context.Context, walletID, userID uuid.UUID) (types.Wallet, error) {
 	args := m.Called(ctx, walletID, userID)
 	return args.Get(0).(types.Wallet), args.Error(1)
 }
 
+func (m *mockWalletService) ExpandWalletProjects(ctx context.Context, userID uuid.UUID, wallets []types.Wallet) ([]types.WalletWithProject, error) {
+	args := m.Called(ctx, userID, wallets)
+	return args.Get(0).([]types.WalletWithProject), args.Error(1)
+}
+
+func (m *mockWalletService) GetWalletWithStats(ctx context.Context, walletID, userID uuid.UUID) (types.Wallet, error) {
+	args := m.Called(ctx, walletID, userID)
+	return args.Get(0).(types.Wallet), args.Error(1)
+}
+
 func (m *mockWalletService) ListWallets(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]types.Wallet, error) {
 	args := m.Called(ctx, userID, limit, offset)
 	return args.Get(0).([]types.Wallet), args.Error(1)
 }
 
-func (m *mockWalletService) ListWalletsPaginated(ctx context.Context, userID uuid.UUID, createdAt time.Time, walletID uuid.UUID, limit int32) ([]types.Wallet, error) {
-	args := m.Called(ctx, userID, createdAt, walletID, limit)
+func (m *mockWalletService) ListWalletsPaginated(ctx context.Context, userID uuid.UUID, createdAt time.Time, walletID uuid.UUID, pinned bool, limit int32, order coreTypes.SortOrder, filter types.WalletFilter) ([]types.Wallet, error) {
+	args := m.Called(ctx, userID, createdAt, walletID, pinned, limit, order, filter)
 	return args.Get(0).([]types.Wallet), args.Error(1)
 }
 
+func (m *mockWalletService) ListWalletIDsPaginated(ctx context.Context, userID uuid.UUID, createdAt time.Time, walletID uuid.UUID, pinned bool, limit int32, order coreTypes.SortOrder, filter types.WalletFilter) ([]coreTypes.KeyedID, error) {
+	args := m.Called(ctx, userID, createdAt, walletID, pinned, limit, order, filter)
+	return args.Get(0).([]coreTypes.KeyedID), args.Error(1)
+}
+
+// StreamWalletsPaginated hands the wallets to fn and then returns the error, so a
+// test fails a stream midway by returning both
+func (m *mockWalletService) StreamWalletsPaginated(ctx context.Context, userID uuid.UUID, createdAt time.Time, walletID uuid.UUID, pinned bool, limit int32, order coreTypes.SortOrder, filter types.WalletFilter, fn func(types.Wallet) error) error {
+	args := m.Called(ctx, userID, createdAt, walletID, pinned, limit, order, filter)
+	for _, wallet := range args.Get(0).([]types.Wallet) {
+		if err := fn(wallet); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *mockWalletService) PinWallet(ctx context.Context, walletID, userID uuid.UUID) (types.Wallet, error) {
+	args := m.Called(ctx, walletID, userID)
+	return args.Get(0).(types.Wallet), args.Error(1)
+}
+
+func (m *mockWalletService) UnpinWallet(ctx context.Context, walletID, userID uuid.UUID) (types.Wallet, error) {
+	args := m.Called(ctx, walletID, userID)
+	return args.Get(0).(types.Wallet), args.Error(1)
+}
+
 func (m *mockWalletService) CreateWallet(ctx context.Context, payload types.WalletCreatePayload, userID uuid.UUID) (types.Wallet, error) {
 	args := m.Called(ctx, payload, userID)
 	return args.Get(0).(types.Wallet), args.Error(1)
 }
 
-func (m *mockWalletService) UpdateWallet(ctx context.Context, payload types.WalletUpdatePayload, userID uuid.UUID) (types.Wallet, error) {
+// UpdateWallet reads the wallet with GetWallet and merges the update onto it like the
+// service, the update is then matched on the merged payload
+func (m *mockWalletService) UpdateWallet(ctx context.Context, walletID, userID uuid.UUID, merge func(types.Wallet) (types.WalletUpdatePayload, error)) (types.Wallet, error) {
+	existing, err := m.GetWallet(ctx, walletID, userID)
+	if err != nil {
+		return types.Wallet{}, err
+	}
+	payload, err := merge(existing)
+	if err != nil {
+		return types.Wallet{}, err
+	}
 	args := m.Called(ctx, payload, userID)
 	return args.Get(0).(types.Wallet), args.Error(1)
 }
 
-func (m *mockWalletService) DeleteWallet(ctx context.Context, walletID, userID uuid.UUID) error {
-	args := m.Called(ctx, walletID, userID)
+func (m *mockWalletService) DeleteWallet(ctx context.Context, walletID, userID uuid.UUID, version *time.Time) error {
+	args := m.Called(ctx, walletID, userID, version)
 	return args.Error(0)
 }
 
+func (m *mockWalletService) PurgeWallet(ctx context.Context, walletID, userID uuid.UUID, version *time.Time) error {
+	args := m.Called(ctx, walletID, userID, version)
+	return args.Error(0)
+}
+
+func (m *mockWalletService) ListDeletedWalletsPaginated(ctx context.Context, userID uuid.UUID, deletedAt time.Time, walletID uuid.UUID, limit int32, order coreTypes.SortOrder) ([]types.Wallet, error) {
+	args := m.Called(ctx, userID, deletedAt, walletID, limit, order)
+	return args.Get(0).([]types.Wallet), args.Error(1)
+}
+
+func (m *mockWalletService) RestoreWallet(ctx context.Context, walletID, userID uuid.UUID) (types.Wallet, error) {
+	args := m.Called(ctx, walletID, userID)
+	return args.Get(0).(types.Wallet), args.Error(1)
+}
+
 func (m *mockWalletService) GetProjectWallets(ctx context.Context, projectID uuid.UUID, userID uuid.UUID) ([]types.Wallet, error) {
 	args := m.Called(ctx, projectID, userID)
 	return args.Get(0).([]types.Wallet), args.Error(1)
 }
 
-func (m *mockWalletService) SearchWallets(ctx context.Context, userID uuid.UUID, name string, limit int32) ([]types.Wallet, error) {
-	args := m.Called(ctx, userID, name, limit)
+func (m *mockWalletService) SearchWallets(ctx context.Context, userID uuid.UUID, name string, limit, offset int32) ([]types.Wallet, error) {
+	args := m.Called(ctx, userID, name, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.Wallet), args.Error(1)
+}
+
+func (m *mockWalletService) SearchWalletsPicker(ctx context.Context, userID uuid.UUID, name string, limit, offset int32) ([]types.WalletPickerItem, error) {
+	args := m.Called(ctx, userID, name, limit, offset)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
+	return args.Get(0).([]types.WalletPickerItem), args.Error(1)
+}
+
+func (m *mockWalletService) ListLowBalanceWallets(ctx context.Context, userID uuid.UUID) ([]types.Wallet, error) {
+	args := m.Called(ctx, userID)
 	return args.Get(0).([]types.Wallet), args.Error(1)
 }
 
+func (m *mockWalletService) ListFacets(ctx context.Context, userID uuid.UUID, field string) ([]coreTypes.Facet, error) {
+	args := m.Called(ctx, userID, field)
+	return args.Get(0).([]coreTypes.Facet), args.Error(1)
+}
+
+func (m *mockWalletService) AttachWalletsToProject(ctx context.Context, userID uuid.UUID, payload types.WalletAttachPayload) (types.WalletAttachResult, error) {
+	args := m.Called(ctx, userID, payload)
+	return args.Get(0).(types.WalletAttachResult), args.Error(1)
+}
+
+func (m *mockWalletService) ExportStatement(ctx context.Context, walletID, userID uuid.UUID, params types.StatementParams, fn func(types.StatementRow) error) error {
+	args := m.Called(ctx, walletID, userID, params)
+	for _, row := range args.Get(0).([]types.StatementRow) {
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+// testLimits is injected into the handler, its maximums differ from the package
+// defaults (wallets allow bigger pages than the default) so the tests catch limits read from anywhere else
+func (m *mockWalletService) ProjectBalance(ctx context.Context, walletID, userID uuid.UUID, params types.ProjectionParams) (types.Projection, error) {
+	args := m.Called(ctx, walletID, userID, params)
+	return args.Get(0).(types.Projection), args.Error(1)
+}
+
+func (m *mockWalletService) NetWorth(ctx context.Context, userID uuid.UUID, params types.NetWorthParams) (types.NetWorth, error) {
+	args := m.Called(ctx, userID, params)
+	return args.Get(0).(types.NetWorth), args.Error(1)
+}
+
+var testLimits = coreTypes.LimitPolicy{
+	DefaultLimit:       10,
+	MaxLimit:           150,
+	DefaultSearchLimit: 10,
+	MaxSearchLimit:     40,
+	MinTrimmedResults:  2,
+}
+
+func (m *mockWalletService) DeletionImpact(ctx context.Context, walletID, userID uuid.UUID) (deletion.Impact, error) {
+	args := m.Called(ctx, walletID, userID)
+	return args.Get(0).(deletion.Impact), args.Error(1)
+}
+
 func setupTest(t *testing.T) (*mockWalletService, *WalletHandler) {
 	mockService := new(mockWalletService)
 	logger := zap.NewNop()
-	handler := NewWalletHandler(mockService, logger)
+	handler := NewWalletHandler(mockService, testLimits, logger)
 	return mockService, handler
 }
 
@@ -122,6 +254,55 @@ func TestWalletHandler_CreateWallet(t *testing.T) {
 			setupMock:      func() {},
 			expectedStatus: http.StatusBadRequest,
 		},
+		{
+			name: "creation with low balance threshold",
+			payload: `{
+				"name": "Test Wallet",
+				"currency": "JPY",
+				"balance": 1000,
+				"lowBalanceThreshold": 500
+			}`,
+			setupAuth: true,
+			setupMock: func() {
+				mockService.On("CreateWallet", mock.Anything, mock.MatchedBy(func(p types.WalletCreatePayload) bool {
+					return p.LowBalanceThreshold != nil && *p.LowBalanceThreshold == 500
+				}), userID).Return(types.Wallet{WalletID: uuid.New(), LowBalanceThreshold: float64Ptr(500)}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "negative low balance threshold",
+			payload: `{
+				"name": "Test Wallet",
+				"currency": "USD",
+				"lowBalanceThreshold": -1
+			}`,
+			setupAuth:      true,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "low balance threshold finer than the currency",
+			payload: `{
+				"name": "Test Wallet",
+				"currency": "USD",
+				"lowBalanceThreshold": 10.005
+			}`,
+			setupAuth:      true,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "fractional low balance threshold for a zero decimal currency",
+			payload: `{
+				"name": "Test Wallet",
+				"currency": "JPY",
+				"lowBalanceThreshold": 10.5
+			}`,
+			setupAuth:      true,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
 		{
 			name:           "missing auth",
 			payload:        `{}`,
@@ -154,6 +335,116 @@ func TestWalletHandler_CreateWallet(t *testing.T) {
 				assert.NoError(t, err)
 				assert.Equal(t, float64(http.StatusCreated), response["status"])
 				assert.NotNil(t, response["data"])
+				assert.NotEmpty(t, w.Header().Get("ETag"))
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWalletHandler_CreateWallet_Warnings(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	walletID := uuid.New()
+
+	mockService.On("CreateWallet", mock.Anything, mock.AnythingOfType("types.WalletCreatePayload"), userID).
+		Run(func(args mock.Arguments) {
+			requestcontext.AddWarning(args.Get(0).(context.Context), `name: a wallet named "Savings" already exists`)
+		}).
+		Return(types.Wallet{WalletID: walletID, Name: "Savings", Currency: "USD"}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/wallets", strings.NewReader(`{"name": "Savings", "currency": "USD"}`))
+	req.Header.Set("Content-Type", "application/json")
+	ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
+	req = req.WithContext(requestcontext.WithWarnings(ctx))
+
+	w := httptest.NewRecorder()
+	handler.CreateWallet(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	var response struct {
+		Data types.Wallet `json:"data"`
+		Meta struct {
+			Warnings []string `json:"warnings"`
+		} `json:"meta"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, walletID, response.Data.WalletID)
+	assert.Equal(t, "Savings", response.Data.Name)
+	assert.Equal(t, []string{`name: a wallet named "Savings" already exists`}, response.Meta.Warnings)
+	mockService.AssertExpectations(t)
+}
+
+func TestWalletHandler_CreateWallet_SnakeCase(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	projectID := uuid.New()
+
+	tests := []struct {
+		name           string
+		payload        string
+		expected       func(types.WalletCreatePayload) bool
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "snake_case fields",
+			payload: fmt.Sprintf(`{
+				"name": "Test Wallet",
+				"currency": "USD",
+				"project_id": %q,
+				"low_balance_threshold": 20
+			}`, projectID),
+			expected: func(p types.WalletCreatePayload) bool {
+				return *p.ProjectID == projectID && *p.LowBalanceThreshold == 20
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "mixed fields",
+			payload: fmt.Sprintf(`{
+				"name": "Test Wallet",
+				"currency": "USD",
+				"projectId": %q,
+				"low_balance_threshold": 20,
+				"lowBalanceThreshold": 20.0
+			}`, projectID),
+			expected: func(p types.WalletCreatePayload) bool {
+				return *p.ProjectID == projectID && *p.LowBalanceThreshold == 20
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "conflicting fields",
+			payload: `{
+				"name": "Test Wallet",
+				"currency": "USD",
+				"lowBalanceThreshold": 20,
+				"low_balance_threshold": 30
+			}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "low_balance_threshold: conflicts with lowBalanceThreshold",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			if tt.expected != nil {
+				mockService.On("CreateWallet", mock.Anything, mock.MatchedBy(tt.expected), userID).
+					Return(types.Wallet{WalletID: uuid.New(), Name: "Test Wallet"}, nil)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/wallets", strings.NewReader(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+			req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+
+			w := httptest.NewRecorder()
+			handler.CreateWallet(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
 			}
 			mockService.AssertExpectations(t)
 		})
@@ -169,8 +460,10 @@ func TestWalletHandler_GetWallet(t *testing.T) {
 		name           string
 		setupAuth      bool
 		walletID       string
+		query          string
 		setupMock      func()
 		expectedStatus int
+		expectStats    bool
 	}{
 		{
 			name:      "successful retrieval",
@@ -187,6 +480,39 @@ func TestWalletHandler_GetWallet(t *testing.T) {
 			},
 			expectedStatus: http.StatusOK,
 		},
+		{
+			name:      "with stats",
+			setupAuth: true,
+			walletID:  walletID.String(),
+			query:     "?include_stats=true",
+			setupMock: func() {
+				mockService.On("GetWalletWithStats", mock.Anything, walletID, userID).
+					Return(types.Wallet{
+						WalletID: walletID,
+						Name:     "Test Wallet",
+						Currency: "EUR",
+						Stats: &types.WalletStats{
+							Last7Days:         types.WalletFlow{Outflow: 80, Inflow: 0},
+							Last30Days:        types.WalletFlow{Outflow: 340, Inflow: 1200},
+							Last90Days:        types.WalletFlow{Outflow: 910, Inflow: 3600},
+							AverageDailySpend: 11.33,
+						},
+					}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectStats:    true,
+		},
+		{
+			name:      "stats of a missing wallet",
+			setupAuth: true,
+			walletID:  walletID.String(),
+			query:     "?include_stats=true",
+			setupMock: func() {
+				mockService.On("GetWalletWithStats", mock.Anything, walletID, userID).
+					Return(types.Wallet{}, coreErrors.NewNotFoundError("wallet %s not found", walletID))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
 		{
 			name:           "invalid wallet ID",
 			setupAuth:      true,
@@ -207,7 +533,7 @@ func TestWalletHandler_GetWallet(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockService.ExpectedCalls = nil
 
-			req := httptest.NewRequest(http.MethodGet, "/wallets/"+tt.walletID, nil)
+			req := httptest.NewRequest(http.MethodGet, "/wallets/"+tt.walletID+tt.query, nil)
 
 			if tt.setupAuth {
 				ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
@@ -230,6 +556,16 @@ func TestWalletHandler_GetWallet(t *testing.T) {
 				assert.NoError(t, err)
 				assert.Equal(t, float64(http.StatusOK), response["status"])
 				assert.NotNil(t, response["data"])
+				assert.NotEmpty(t, w.Header().Get("ETag"))
+
+				data := response["data"].(map[string]interface{})
+				if tt.expectStats {
+					stats := data["stats"].(map[string]interface{})
+					assert.Equal(t, float64(340), stats["last30Days"].(map[string]interface{})["outflow"])
+					assert.Equal(t, 11.33, stats["averageDailySpend"])
+				} else {
+					assert.NotContains(t, data, "stats")
+				}
 			}
 			mockService.AssertExpectations(t)
 		})
@@ -241,6 +577,9 @@ func TestWalletHandler_ListWalletsPaginated(t *testing.T) {
 	userID := uuid.New()
 	now := time.Now().UTC()
 	cursorID := uuid.New()
+	groupID := uuid.New()
+	tagIDs := []uuid.UUID{uuid.New(), uuid.New()}
+	eur, noProject := "EUR", false
 
 	tests := []struct {
 		name            string
@@ -263,13 +602,13 @@ func TestWalletHandler_ListWalletsPaginated(t *testing.T) {
 						WalletID:  uuid.New(),
 						Name:      "Wallet 1",
 						Currency:  "USD",
-						CreatedAt: now.Add(-1 * time.Hour),
+						CreatedAt: coreTypes.NewTimestamp(now.Add(-1 * time.Hour)),
 					},
 					{
 						WalletID:  uuid.New(),
 						Name:      "Wallet 2",
 						Currency:  "EUR",
-						CreatedAt: now.Add(-2 * time.Hour),
+						CreatedAt: coreTypes.NewTimestamp(now.Add(-2 * time.Hour)),
 					},
 				}
 				mockService.On("ListWalletsPaginated",
@@ -281,12 +620,15 @@ func TestWalletHandler_ListWalletsPaginated(t *testing.T) {
 					mock.MatchedBy(func(id uuid.UUID) bool {
 						return id == uuid.Nil
 					}),
-					int32(coreTypes.DefaultLimit),
+					true,
+					testLimits.DefaultLimit,
+					coreTypes.SortOrderDesc,
+					types.WalletFilter{},
 				).Return(wallets, nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedLen:    2,
-			expectedLimit:  fmt.Sprint(coreTypes.DefaultLimit),
+			expectedLimit:  fmt.Sprint(testLimits.DefaultLimit),
 		},
 		{
 			name:      "first page with custom limit",
@@ -300,7 +642,7 @@ func TestWalletHandler_ListWalletsPaginated(t *testing.T) {
 						WalletID:  uuid.New(),
 						Name:      "Wallet 1",
 						Currency:  "USD",
-						CreatedAt: now.Add(-1 * time.Hour),
+						CreatedAt: coreTypes.NewTimestamp(now.Add(-1 * time.Hour)),
 					},
 				}
 				mockService.On("ListWalletsPaginated",
@@ -312,7 +654,10 @@ func TestWalletHandler_ListWalletsPaginated(t *testing.T) {
 					mock.MatchedBy(func(id uuid.UUID) bool {
 						return id == uuid.Nil
 					}),
+					true,
 					int32(5),
+					coreTypes.SortOrderDesc,
+					types.WalletFilter{},
 				).Return(wallets, nil)
 			},
 			expectedStatus: http.StatusOK,
@@ -323,7 +668,7 @@ func TestWalletHandler_ListWalletsPaginated(t *testing.T) {
 			name:      "second page with next_token",
 			setupAuth: true,
 			queryParams: map[string]string{
-				"next_token": coreTypes.EncodeCursor(now, cursorID),
+				"next_token": coreTypes.EncodeCursor(now, cursorID, userID),
 			},
 			setupMock: func() {
 				wallets := []types.Wallet{
@@ -331,7 +676,7 @@ func TestWalletHandler_ListWalletsPaginated(t *testing.T) {
 						WalletID:  uuid.New(),
 						Name:      "Wallet 3",
 						Currency:  "USD",
-						CreatedAt: now.Add(-3 * time.Hour),
+						CreatedAt: coreTypes.NewTimestamp(now.Add(-3 * time.Hour)),
 					},
 				}
 				mockService.On("ListWalletsPaginated",
@@ -341,12 +686,15 @@ func TestWalletHandler_ListWalletsPaginated(t *testing.T) {
 						return t.Truncate(time.Second).Equal(now.Truncate(time.Second))
 					}),
 					cursorID,
-					int32(coreTypes.DefaultLimit),
+					false,
+					testLimits.DefaultLimit,
+					coreTypes.SortOrderDesc,
+					types.WalletFilter{},
 				).Return(wallets, nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedLen:    1,
-			expectedLimit:  fmt.Sprint(coreTypes.DefaultLimit),
+			expectedLimit:  fmt.Sprint(testLimits.DefaultLimit),
 		},
 		{
 			name:      "invalid next_token format",
@@ -372,7 +720,7 @@ func TestWalletHandler_ListWalletsPaginated(t *testing.T) {
 			name:      "limit above maximum gets capped",
 			setupAuth: true,
 			queryParams: map[string]string{
-				"limit": fmt.Sprintf("%d", coreTypes.MaxLimit+1),
+				"limit": fmt.Sprintf("%d", testLimits.MaxLimit+1),
 			},
 			setupMock: func() {
 				wallets := []types.Wallet{
@@ -380,7 +728,38 @@ func TestWalletHandler_ListWalletsPaginated(t *testing.T) {
 						WalletID:  uuid.New(),
 						Name:      "Wallet 1",
 						Currency:  "USD",
-						CreatedAt: now.Add(-1 * time.Hour),
+						CreatedAt: coreTypes.NewTimestamp(now.Add(-1 * time.Hour)),
+					},
+				}
+				mockService.On("ListWalletsPaginated",
+					mock.Anything,
+					userID,
+					mock.Anything,
+					mock.Anything,
+					true,
+					testLimits.MaxLimit,
+					coreTypes.SortOrderDesc,
+					types.WalletFilter{},
+				).Return(wallets, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedLen:    1,
+			expectedLimit:  fmt.Sprint(testLimits.MaxLimit),
+		},
+		{
+			name:      "filter by group",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"group_id": groupID.String(),
+			},
+			setupMock: func() {
+				wallets := []types.Wallet{
+					{
+						WalletID:  uuid.New(),
+						Name:      "Grouped Wallet",
+						Currency:  "USD",
+						GroupID:   &groupID,
+						CreatedAt: coreTypes.NewTimestamp(now.Add(-1 * time.Hour)),
 					},
 				}
 				mockService.On("ListWalletsPaginated",
@@ -388,12 +767,127 @@ func TestWalletHandler_ListWalletsPaginated(t *testing.T) {
 					userID,
 					mock.Anything,
 					mock.Anything,
-					int32(coreTypes.MaxLimit),
+					true,
+					testLimits.DefaultLimit,
+					coreTypes.SortOrderDesc,
+					types.WalletFilter{GroupID: &groupID},
 				).Return(wallets, nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedLen:    1,
-			expectedLimit:  fmt.Sprint(coreTypes.MaxLimit),
+		},
+		{
+			name:      "filter ungrouped wallets",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"group_id": "none",
+			},
+			setupMock: func() {
+				mockService.On("ListWalletsPaginated",
+					mock.Anything,
+					userID,
+					mock.Anything,
+					mock.Anything,
+					true,
+					testLimits.DefaultLimit,
+					coreTypes.SortOrderDesc,
+					types.WalletFilter{Ungrouped: true},
+				).Return([]types.Wallet{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedLen:    0,
+		},
+		{
+			name:      "invalid group_id",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"group_id": "not-a-group",
+			},
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "group_id: must be a group ID or \"none\"",
+		},
+		{
+			name:      "filters combined",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"group_id":    groupID.String(),
+				"tags":        tagIDs[0].String() + "," + tagIDs[1].String() + "," + tagIDs[0].String(),
+				"tag_match":   "all",
+				"currency":    "eur",
+				"has_project": "false",
+			},
+			setupMock: func() {
+				mockService.On("ListWalletsPaginated",
+					mock.Anything,
+					userID,
+					mock.Anything,
+					mock.Anything,
+					true,
+					testLimits.DefaultLimit,
+					coreTypes.SortOrderDesc,
+					types.WalletFilter{
+						GroupID:      &groupID,
+						Tags:         tagIDs,
+						MatchAllTags: true,
+						Currency:     &eur,
+						HasProject:   &noProject,
+					},
+				).Return([]types.Wallet{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedLen:    0,
+		},
+		{
+			name:      "invalid tag_match",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"tags":      tagIDs[0].String(),
+				"tag_match": "some",
+			},
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "tag_match: must be \"any\" or \"all\"",
+		},
+		{
+			name:      "tag_match without tags",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"tag_match": "all",
+			},
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "tag_match: requires tags",
+		},
+		{
+			name:      "invalid tag ID",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"tags": "not-a-tag",
+			},
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "tags: \"not-a-tag\" is not a valid tag ID",
+		},
+		{
+			name:      "invalid currency",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"currency": "dollars",
+			},
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "currency: must be an ISO 4217 currency code",
+		},
+		{
+			name:      "invalid has_project",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"has_project": "maybe",
+			},
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "has_project: must be true or false",
 		},
 		{
 			name:           "missing auth",
@@ -489,7 +983,7 @@ func TestWalletHandler_SearchWallets(t *testing.T) {
 					{WalletID: uuid.New(), Name: "Test Wallet"},
 					{WalletID: uuid.New(), Name: "Testing Account"},
 				}
-				mockService.On("SearchWallets", mock.Anything, userID, "test", int32(20)).
+				mockService.On("SearchWallets", mock.Anything, userID, "test", int32(20), int32(0)).
 					Return(wallets, nil)
 			},
 			expectedStatus: http.StatusOK,
@@ -498,24 +992,138 @@ func TestWalletHandler_SearchWallets(t *testing.T) {
 				assert.Equal(t, "test", metadata["query"])
 				assert.Equal(t, float64(20), metadata["limit"])
 				assert.Equal(t, float64(2), metadata["count"])
+				assert.Equal(t, "full", metadata["view"])
 			},
 		},
 		{
-			name:      "limit exceeds maximum will be capped to maximum",
+			name:      "picker view returns only what a picker shows",
 			setupAuth: true,
 			queryParams: map[string]string{
-				"q":     "test",
-				"limit": fmt.Sprint(coreTypes.MaxSearchLimit), // > maxSearchLimit
+				"q":    "test",
+				"view": "picker",
+			},
+			setupMock: func() {
+				balance := 12.5
+				items := []types.WalletPickerItem{
+					{WalletID: uuid.New(), Name: "Test Wallet", Balance: &balance, Currency: "USD"},
+				}
+				mockService.On("SearchWalletsPicker", mock.Anything, userID, "test", testLimits.DefaultSearchLimit, int32(0)).
+					Return(items, nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, response map[string]interface{}) {
+				assert.Equal(t, "picker", response["meta"].(map[string]interface{})["view"])
+				data := response["data"].([]interface{})
+				require.Len(t, data, 1)
+				assert.ElementsMatch(t, []string{"walletId", "name", "balance", "currency"}, keysOf(data[0].(map[string]interface{})))
+			},
+		},
+		{
+			name:      "unknown view",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"q":    "test",
+				"view": "compact",
+			},
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "trim=auto cuts the results at the score cliff",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"q":     "test",
+				"limit": "5",
+				"trim":  "auto",
+			},
+			setupMock: func() {
+				mockService.On("SearchWallets", mock.Anything, userID, "test", int32(5), int32(0)).
+					Return(scoredWallets(0.9, 0.88, 0.85, 0.3, 0.2), nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, response map[string]interface{}) {
+				metadata := response["meta"].(map[string]interface{})
+				assert.Len(t, response["data"], 3)
+				assert.Equal(t, float64(3), metadata["count"])
+				assert.Equal(t, float64(2), metadata["trimmed"])
+				assert.InDelta(t, 0.85, metadata["cutoff"], 0.001)
+				assert.NotContains(t, metadata, "next_token")
+			},
+		},
+		{
+			name:      "trim=auto keeps results without a cliff",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"q":     "test",
+				"limit": "4",
+				"trim":  "auto",
+			},
+			setupMock: func() {
+				mockService.On("SearchWallets", mock.Anything, userID, "test", int32(4), int32(0)).
+					Return(scoredWallets(0.9, 0.85, 0.8, 0.75), nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, response map[string]interface{}) {
+				metadata := response["meta"].(map[string]interface{})
+				assert.Len(t, response["data"], 4)
+				assert.NotContains(t, metadata, "trimmed")
+				assert.NotContains(t, metadata, "cutoff")
+			},
+		},
+		{
+			name:      "trim is off by default",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"q":     "test",
+				"limit": "5",
+			},
+			setupMock: func() {
+				mockService.On("SearchWallets", mock.Anything, userID, "test", int32(5), int32(0)).
+					Return(scoredWallets(0.9, 0.88, 0.85, 0.3, 0.2), nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, response map[string]interface{}) {
+				assert.Len(t, response["data"], 5)
+				assert.NotContains(t, response["meta"], "trimmed")
+			},
+		},
+		{
+			name:      "trim=auto with the picker view",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"q":    "test",
+				"view": "picker",
+				"trim": "auto",
+			},
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "unknown trim mode",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"q":    "test",
+				"trim": "always",
+			},
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "limit exceeds maximum will be capped to maximum",
+			setupAuth: true,
+			queryParams: map[string]string{
+				"q":     "test",
+				"limit": fmt.Sprint(testLimits.MaxSearchLimit), // > maxSearchLimit
 			},
 			setupMock: func() {
 				wallets := []types.Wallet{}
-				mockService.On("SearchWallets", mock.Anything, userID, "test", int32(coreTypes.MaxSearchLimit)).
+				mockService.On("SearchWallets", mock.Anything, userID, "test", testLimits.MaxSearchLimit, int32(0)).
 					Return(wallets, nil)
 			},
 			expectedStatus: http.StatusOK,
 			checkResponse: func(t *testing.T, response map[string]interface{}) {
 				metadata := response["meta"].(map[string]interface{})
-				assert.Equal(t, float64(coreTypes.MaxSearchLimit), metadata["limit"])
+				assert.Equal(t, float64(testLimits.MaxSearchLimit), metadata["limit"])
 			},
 		},
 		{
@@ -544,7 +1152,7 @@ func TestWalletHandler_SearchWallets(t *testing.T) {
 				"q": "test",
 			},
 			setupMock: func() {
-				mockService.On("SearchWallets", mock.Anything, userID, "test", int32(coreTypes.DefaultSearchLimit)).
+				mockService.On("SearchWallets", mock.Anything, userID, "test", testLimits.DefaultSearchLimit, int32(0)).
 					Return([]types.Wallet(nil), fmt.Errorf("database error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
@@ -588,6 +1196,214 @@ func TestWalletHandler_SearchWallets(t *testing.T) {
 	}
 }
 
+func TestWalletHandler_SearchWallets_PickerShape(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	balance := 12.5
+	wallet := types.Wallet{WalletID: uuid.New(), UserID: userID, Name: "Test Wallet", Balance: &balance, Currency: "USD", Tags: []uuid.UUID{uuid.New()}}
+
+	mockService.On("SearchWallets", mock.Anything, userID, "test", testLimits.DefaultSearchLimit, int32(0)).
+		Return([]types.Wallet{wallet}, nil)
+	mockService.On("SearchWalletsPicker", mock.Anything, userID, "test", testLimits.DefaultSearchLimit, int32(0)).
+		Return([]types.WalletPickerItem{{WalletID: wallet.WalletID, Name: wallet.Name, Balance: wallet.Balance, Currency: wallet.Currency}}, nil)
+
+	search := func(view string) map[string]interface{} {
+		req := httptest.NewRequest(http.MethodGet, "/wallets/search?q=test&view="+view, nil)
+		req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+		w := httptest.NewRecorder()
+		handler.SearchWallets(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Data []map[string]interface{} `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		require.Len(t, response.Data, 1)
+		return response.Data[0]
+	}
+
+	full, picker := search("full"), search("picker")
+	assert.Subset(t, keysOf(full), keysOf(picker))
+	assert.Less(t, len(picker), len(full))
+	for key, value := range picker {
+		assert.Equal(t, full[key], value, key)
+	}
+	mockService.AssertExpectations(t)
+}
+
+// scoredWallets builds one wallet per score, in the given order
+func scoredWallets(scores ...float32) []types.Wallet {
+	wallets := make([]types.Wallet, len(scores))
+	for i := range scores {
+		wallets[i] = types.Wallet{WalletID: uuid.New(), Name: fmt.Sprintf("Wallet %d", i), Score: &scores[i]}
+	}
+	return wallets
+}
+
+// keysOf lists the keys of a decoded JSON object
+func keysOf(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func TestWalletHandler_ListWalletFacets(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+
+	request := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/wallets/facets"+query, nil)
+		req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+		w := httptest.NewRecorder()
+		handler.ListWalletFacets(w, req)
+		return w
+	}
+
+	t.Run("currency values with counts", func(t *testing.T) {
+		mockService.ExpectedCalls = nil
+		mockService.On("ListFacets", mock.Anything, userID, "currency").Return([]coreTypes.Facet{
+			{Value: "EUR", Count: 3},
+			{Value: "USD", Count: 1},
+		}, nil)
+
+		w := request("?field=currency")
+		require.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"value": "EUR", "count": float64(3)},
+			map[string]interface{}{"value": "USD", "count": float64(1)},
+		}, response["data"])
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("field not facetable", func(t *testing.T) {
+		mockService.ExpectedCalls = nil
+		mockService.Calls = nil
+
+		w := request("?field=name")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `cannot be faceted (allowed: currency)`)
+		mockService.AssertNotCalled(t, "ListFacets", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestWalletHandler_QueryParamsModes(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+
+	tests := []struct {
+		name             string
+		mode             coreTypes.QueryParamsMode
+		path             string
+		handle           http.HandlerFunc
+		setupMock        func()
+		expectedStatus   int
+		expectedError    string
+		expectedWarnings []interface{}
+	}{
+		{
+			name:   "unknown param warned about in warn mode",
+			mode:   coreTypes.QueryParamsWarn,
+			path:   "/wallets/search?serach=foo",
+			handle: handler.SearchWallets,
+			setupMock: func() {
+				mockService.On("SearchWallets", mock.Anything, userID, "", testLimits.DefaultSearchLimit, int32(0)).Return([]types.Wallet{}, nil)
+			},
+			expectedStatus:   http.StatusOK,
+			expectedWarnings: []interface{}{"unknown query parameter: serach (allowed: view, trim, q, limit, next_token)"},
+		},
+		{
+			name:           "unknown search params rejected when strict",
+			mode:           coreTypes.QueryParamsStrict,
+			path:           "/wallets/search?q=foo&nexttoken=abc&page=2&page=3",
+			handle:         handler.SearchWallets,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "unknown query parameters: nexttoken, page (allowed: view, trim, q, limit, next_token)",
+		},
+		{
+			name:           "unknown list param rejected when strict",
+			mode:           coreTypes.QueryParamsStrict,
+			path:           "/wallets?limt=5",
+			handle:         handler.ListWalletsPaginated,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "unknown query parameter: limt (allowed: group_id, tags, tag_match, currency, has_project, limit, order, next_token, expand, stream, only_ids)",
+		},
+		{
+			name:   "capped limit warned about",
+			mode:   coreTypes.QueryParamsWarn,
+			path:   "/wallets?limit=1000",
+			handle: handler.ListWalletsPaginated,
+			setupMock: func() {
+				mockService.On("ListWalletsPaginated", mock.Anything, userID, mock.Anything, mock.Anything, true,
+					testLimits.MaxLimit, coreTypes.SortOrderDesc, types.WalletFilter{}).Return([]types.Wallet{}, nil)
+			},
+			expectedStatus:   http.StatusOK,
+			expectedWarnings: []interface{}{"limit: capped at 150"},
+		},
+		{
+			name:   "warnings kept on error responses",
+			mode:   coreTypes.QueryParamsWarn,
+			path:   "/wallets?limit=1000&page=2",
+			handle: handler.ListWalletsPaginated,
+			setupMock: func() {
+				mockService.On("ListWalletsPaginated", mock.Anything, userID, mock.Anything, mock.Anything, true,
+					testLimits.MaxLimit, coreTypes.SortOrderDesc, types.WalletFilter{}).Return([]types.Wallet(nil), fmt.Errorf("connection reset"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "connection reset",
+			expectedWarnings: []interface{}{
+				"unknown query parameter: page (allowed: group_id, tags, tag_match, currency, has_project, limit, order, next_token, expand, stream, only_ids)",
+				"limit: capped at 150",
+			},
+		},
+		{
+			name:   "known list params accepted when strict",
+			mode:   coreTypes.QueryParamsStrict,
+			path:   "/wallets?limit=5&order=asc",
+			handle: handler.ListWalletsPaginated,
+			setupMock: func() {
+				mockService.On("ListWalletsPaginated", mock.Anything, userID, mock.Anything, mock.Anything, true,
+					int32(5), coreTypes.SortOrderAsc, types.WalletFilter{}).Return([]types.Wallet{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
+			ctx = context.WithValue(ctx, requestcontext.QueryParamsModeKey, string(tt.mode))
+			req = req.WithContext(requestcontext.WithWarnings(ctx))
+
+			tt.setupMock()
+			w := httptest.NewRecorder()
+			tt.handle(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var response map[string]interface{}
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+			if tt.expectedError != "" {
+				assert.Equal(t, tt.expectedError, response["error"])
+			}
+			meta, _ := response["meta"].(map[string]interface{})
+			if tt.expectedWarnings == nil {
+				assert.NotContains(t, meta, "warnings")
+			} else {
+				assert.Equal(t, tt.expectedWarnings, meta["warnings"])
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
 func TestWalletHandler_GetProjectWallets(t *testing.T) {
 	mockService, handler := setupTest(t)
 	userID := uuid.New()
@@ -611,13 +1427,13 @@ func TestWalletHandler_GetProjectWallets(t *testing.T) {
 						WalletID:  uuid.New(),
 						Name:      "Project Wallet 1",
 						Currency:  "USD",
-						CreatedAt: time.Now(),
+						CreatedAt: coreTypes.NewTimestamp(time.Now()),
 					},
 					{
 						WalletID:  uuid.New(),
 						Name:      "Project Wallet 2",
 						Currency:  "EUR",
-						CreatedAt: time.Now(),
+						CreatedAt: coreTypes.NewTimestamp(time.Now()),
 					},
 				}
 				mockService.On("GetProjectWallets", mock.Anything, projectID, userID).
@@ -678,10 +1494,94 @@ func TestWalletHandler_GetProjectWallets(t *testing.T) {
 	}
 }
 
+func TestWalletHandler_ListWalletAlerts(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+
+	tests := []struct {
+		name           string
+		setupAuth      bool
+		setupMock      func()
+		expectedStatus int
+		expectedLen    int
+	}{
+		{
+			name:      "wallets below their threshold",
+			setupAuth: true,
+			setupMock: func() {
+				wallets := []types.Wallet{
+					{WalletID: uuid.New(), Name: "Groceries", Currency: "USD", Balance: float64Ptr(5), LowBalanceThreshold: float64Ptr(50)},
+					{WalletID: uuid.New(), Name: "Fuel", Currency: "USD", Balance: float64Ptr(19.99), LowBalanceThreshold: float64Ptr(20)},
+				}
+				mockService.On("ListLowBalanceWallets", mock.Anything, userID).Return(wallets, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedLen:    2,
+		},
+		{
+			name:      "no alerts",
+			setupAuth: true,
+			setupMock: func() {
+				mockService.On("ListLowBalanceWallets", mock.Anything, userID).Return([]types.Wallet{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:      "service error",
+			setupAuth: true,
+			setupMock: func() {
+				mockService.On("ListLowBalanceWallets", mock.Anything, userID).Return([]types.Wallet{}, fmt.Errorf("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "missing auth",
+			setupAuth:      false,
+			setupMock:      func() {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+
+			req := httptest.NewRequest(http.MethodGet, "/wallets/alerts", nil)
+			if tt.setupAuth {
+				ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
+				req = req.WithContext(ctx)
+			}
+
+			tt.setupMock()
+			w := httptest.NewRecorder()
+			handler.ListWalletAlerts(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response map[string]interface{}
+				err := json.NewDecoder(w.Body).Decode(&response)
+				assert.NoError(t, err)
+
+				wallets := response["data"].([]interface{})
+				assert.Len(t, wallets, tt.expectedLen)
+				for _, wallet := range wallets {
+					assert.Contains(t, wallet, "lowBalanceThreshold")
+				}
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
 func TestWalletHandler_UpdateWallet(t *testing.T) {
 	mockService, handler := setupTest(t)
 	userID := uuid.New()
 	walletID := uuid.New()
+	existingWallet := types.Wallet{WalletID: walletID, Name: "Original Wallet", Currency: "USD"}
+	// the update goes to the wallet of the URL
+	ofURL := mock.MatchedBy(func(payload types.WalletUpdatePayload) bool {
+		return payload.WalletID == walletID
+	})
 
 	tests := []struct {
 		name           string
@@ -690,6 +1590,7 @@ func TestWalletHandler_UpdateWallet(t *testing.T) {
 		setupAuth      bool
 		setupMock      func()
 		expectedStatus int
+		expectedType   coreErrors.ErrorType
 	}{
 		{
 			name:     "successful update",
@@ -720,6 +1621,39 @@ func TestWalletHandler_UpdateWallet(t *testing.T) {
 			},
 			expectedStatus: http.StatusOK,
 		},
+		{
+			name:      "matching body ID",
+			walletID:  walletID.String(),
+			payload:   `{"walletId": "` + walletID.String() + `", "name": "Updated Wallet"}`,
+			setupAuth: true,
+			setupMock: func() {
+				mockService.On("GetWallet", mock.Anything, walletID, userID).Return(existingWallet, nil)
+				mockService.On("UpdateWallet", mock.Anything, ofURL, userID).Return(existingWallet, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:      "nil body ID",
+			walletID:  walletID.String(),
+			payload:   `{"walletId": "00000000-0000-0000-0000-000000000000", "name": "Updated Wallet"}`,
+			setupAuth: true,
+			setupMock: func() {
+				mockService.On("GetWallet", mock.Anything, walletID, userID).Return(existingWallet, nil)
+				mockService.On("UpdateWallet", mock.Anything, ofURL, userID).Return(existingWallet, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:      "conflicting body ID",
+			walletID:  walletID.String(),
+			payload:   `{"walletId": "` + uuid.New().String() + `", "name": "Updated Wallet"}`,
+			setupAuth: true,
+			setupMock: func() {
+				mockService.On("GetWallet", mock.Anything, walletID, userID).Return(existingWallet, nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedType:   coreErrors.ErrorTypeIDMismatch,
+		},
 		{
 			name:           "invalid wallet ID",
 			walletID:       "invalid-uuid",
@@ -778,6 +1712,11 @@ func TestWalletHandler_UpdateWallet(t *testing.T) {
 				assert.Equal(t, float64(http.StatusOK), response["status"])
 				assert.NotNil(t, response["data"])
 			}
+			if tt.expectedType != "" {
+				var response coreErrors.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				assert.Equal(t, tt.expectedType, response.Type)
+			}
 			mockService.AssertExpectations(t)
 		})
 	}
@@ -787,44 +1726,175 @@ func TestWalletHandler_DeleteWallet(t *testing.T) {
 	mockService, handler := setupTest(t)
 	userID := uuid.New()
 	walletID := uuid.New()
+	updatedAt := time.Date(2025, 3, 4, 10, 0, 0, 123456000, time.UTC)
+	current := types.Wallet{WalletID: walletID, Name: "Test Wallet", Currency: "USD", UpdatedAt: coreTypes.NewTimestamp(updatedAt)}
 
 	tests := []struct {
 		name           string
 		walletID       string
 		setupAuth      bool
+		query          string
+		ifMatch        string
 		setupMock      func()
 		expectedStatus int
+		// currentETag is the version a 412 reports
+		currentETag string
 	}{
 		{
 			name:      "successful deletion",
 			walletID:  walletID.String(),
 			setupAuth: true,
 			setupMock: func() {
-				mockService.On("DeleteWallet", mock.Anything, walletID, userID).Return(nil)
+				mockService.On("DeleteWallet", mock.Anything, walletID, userID, (*time.Time)(nil)).Return(nil)
 			},
 			expectedStatus: http.StatusOK,
 		},
 		{
-			name:           "invalid wallet ID",
-			walletID:       "invalid-uuid",
-			setupAuth:      true,
-			setupMock:      func() {},
-			expectedStatus: http.StatusBadRequest,
+			name:      "purge deletes for good",
+			walletID:  walletID.String(),
+			setupAuth: true,
+			query:     "?purge=true",
+			setupMock: func() {
+				mockService.On("PurgeWallet", mock.Anything, walletID, userID, (*time.Time)(nil)).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
 		},
 		{
-			name:           "missing auth",
-			walletID:       walletID.String(),
-			setupAuth:      false,
-			setupMock:      func() {},
-			expectedStatus: http.StatusUnauthorized,
+			name:      "purge=false moves to the trash",
+			walletID:  walletID.String(),
+			setupAuth: true,
+			query:     "?purge=false",
+			setupMock: func() {
+				mockService.On("DeleteWallet", mock.Anything, walletID, userID, (*time.Time)(nil)).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			mockService.ExpectedCalls = nil
-
-			req := httptest.NewRequest(http.MethodDelete, "/wallets/"+tt.walletID, nil)
+		{
+			name:      "purge of a missing wallet",
+			walletID:  walletID.String(),
+			setupAuth: true,
+			query:     "?purge=true",
+			setupMock: func() {
+				mockService.On("PurgeWallet", mock.Anything, walletID, userID, (*time.Time)(nil)).
+					Return(coreErrors.NewNotFoundError("wallet not found"))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:      "matching If-Match",
+			walletID:  walletID.String(),
+			setupAuth: true,
+			ifMatch:   `"1741082400123456"`,
+			setupMock: func() {
+				mockService.On("GetWallet", mock.Anything, walletID, userID).Return(current, nil)
+				mockService.On("DeleteWallet", mock.Anything, walletID, userID, &updatedAt).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:      "If-Match listing the current version",
+			walletID:  walletID.String(),
+			setupAuth: true,
+			ifMatch:   `"1741082400000000", "1741082400123456"`,
+			setupMock: func() {
+				mockService.On("GetWallet", mock.Anything, walletID, userID).Return(current, nil)
+				mockService.On("DeleteWallet", mock.Anything, walletID, userID, &updatedAt).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:      "wildcard If-Match deletes any version",
+			walletID:  walletID.String(),
+			setupAuth: true,
+			ifMatch:   "*",
+			setupMock: func() {
+				mockService.On("GetWallet", mock.Anything, walletID, userID).Return(current, nil)
+				mockService.On("DeleteWallet", mock.Anything, walletID, userID, (*time.Time)(nil)).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:      "matching If-Match on a purge",
+			walletID:  walletID.String(),
+			setupAuth: true,
+			query:     "?purge=true",
+			ifMatch:   `"1741082400123456"`,
+			setupMock: func() {
+				mockService.On("GetWallet", mock.Anything, walletID, userID).Return(current, nil)
+				mockService.On("PurgeWallet", mock.Anything, walletID, userID, &updatedAt).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:      "stale If-Match",
+			walletID:  walletID.String(),
+			setupAuth: true,
+			ifMatch:   `"1741082400000000"`,
+			setupMock: func() {
+				mockService.On("GetWallet", mock.Anything, walletID, userID).Return(current, nil)
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+			currentETag:    `"1741082400123456"`,
+		},
+		{
+			name:      "weak If-Match",
+			walletID:  walletID.String(),
+			setupAuth: true,
+			ifMatch:   `W/"1741082400123456"`,
+			setupMock: func() {
+				mockService.On("GetWallet", mock.Anything, walletID, userID).Return(current, nil)
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+			currentETag:    `"1741082400123456"`,
+		},
+		{
+			name:      "wallet updated between the read and the delete",
+			walletID:  walletID.String(),
+			setupAuth: true,
+			ifMatch:   `"1741082400123456"`,
+			setupMock: func() {
+				mockService.On("GetWallet", mock.Anything, walletID, userID).Return(current, nil)
+				mockService.On("DeleteWallet", mock.Anything, walletID, userID, &updatedAt).
+					Return(fmt.Errorf("delete wallet %s: %w", walletID, coreRepository.ErrPreconditionFailed))
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:      "If-Match on a missing wallet",
+			walletID:  walletID.String(),
+			setupAuth: true,
+			ifMatch:   "*",
+			setupMock: func() {
+				mockService.On("GetWallet", mock.Anything, walletID, userID).
+					Return(types.Wallet{}, coreErrors.NewNotFoundError("wallet %s not found", walletID))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "invalid wallet ID",
+			walletID:       "invalid-uuid",
+			setupAuth:      true,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing auth",
+			walletID:       walletID.String(),
+			setupAuth:      false,
+			setupMock:      func() {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			req := httptest.NewRequest(http.MethodDelete, "/wallets/"+tt.walletID+tt.query, nil)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
 
 			if tt.setupAuth {
 				ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
@@ -847,6 +1917,948 @@ func TestWalletHandler_DeleteWallet(t *testing.T) {
 				assert.NoError(t, err)
 				assert.Equal(t, float64(http.StatusOK), response["status"])
 			}
+			if tt.expectedStatus == http.StatusPreconditionFailed {
+				var response coreErrors.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				assert.Equal(t, coreErrors.ErrorTypePreconditionFailed, response.Type)
+				assert.Contains(t, response.ErrorText, tt.currentETag)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWalletHandler_PreviewWalletDeletion(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	walletID := uuid.New()
+
+	tests := []struct {
+		name           string
+		walletID       string
+		setupAuth      bool
+		setupMock      func()
+		expectedStatus int
+	}{
+		{
+			name:      "successful preview",
+			walletID:  walletID.String(),
+			setupAuth: true,
+			setupMock: func() {
+				mockService.On("DeletionImpact", mock.Anything, walletID, userID).Return(deletion.Impact{
+					Resource: "wallet",
+					ID:       walletID,
+					Affected: []deletion.Affected{
+						{Kind: "wallets", Count: 1, Effect: deletion.EffectTrashed, Balances: []deletion.Balance{{Currency: "USD", Balance: 100}}},
+						{Kind: "ledgerEntries", Count: 3, Effect: deletion.EffectKept},
+					},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:      "not found",
+			walletID:  walletID.String(),
+			setupAuth: true,
+			setupMock: func() {
+				mockService.On("DeletionImpact", mock.Anything, walletID, userID).
+					Return(deletion.Impact{}, &coreErrors.ErrorResponse{Type: coreErrors.ErrorTypeNotFound, Message: "wallet(s) not found"})
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "invalid wallet ID",
+			walletID:       "invalid-uuid",
+			setupAuth:      true,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing auth",
+			walletID:       walletID.String(),
+			setupAuth:      false,
+			setupMock:      func() {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+
+			req := httptest.NewRequest(http.MethodGet, "/wallets/"+tt.walletID+"/delete-preview", nil)
+			if tt.setupAuth {
+				ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
+				req = req.WithContext(ctx)
+			}
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.walletID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			tt.setupMock()
+			w := httptest.NewRecorder()
+			handler.PreviewWalletDeletion(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response struct {
+					Data deletion.Impact `json:"data"`
+				}
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				assert.Equal(t, "wallet", response.Data.Resource)
+				assert.Len(t, response.Data.Affected, 2)
+				assert.Equal(t, int64(3), response.Data.Affected[1].Count)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWalletHandler_PinWallet(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	walletID := uuid.New()
+	pinnedAt := time.Now().UTC()
+	updatedAt := pinnedAt.Add(-time.Hour)
+
+	tests := []struct {
+		name           string
+		handle         http.HandlerFunc
+		setupMock      func()
+		expectedStatus int
+		expectedPinned bool
+	}{
+		{
+			name:   "pin",
+			handle: handler.PinWallet,
+			setupMock: func() {
+				mockService.On("PinWallet", mock.Anything, walletID, userID).
+					Return(types.Wallet{WalletID: walletID, Name: "Savings", Pinned: true, PinnedAt: coreTypes.TimestampPtr(&pinnedAt), UpdatedAt: coreTypes.NewTimestamp(updatedAt)}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedPinned: true,
+		},
+		{
+			name:   "too many pinned wallets",
+			handle: handler.PinWallet,
+			setupMock: func() {
+				mockService.On("PinWallet", mock.Anything, walletID, userID).
+					Return(types.Wallet{}, coreErrors.NewValidationError("at most %d wallets can be pinned, unpin one first", types.MaxPinnedWallets))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "unpin",
+			handle: handler.UnpinWallet,
+			setupMock: func() {
+				mockService.On("UnpinWallet", mock.Anything, walletID, userID).
+					Return(types.Wallet{WalletID: walletID, Name: "Savings", UpdatedAt: coreTypes.NewTimestamp(updatedAt)}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+
+			req := httptest.NewRequest(http.MethodPost, "/wallets/"+walletID.String()+"/pin", nil)
+			ctx := context.WithValue(req.Context(), requestcontext.UserIDKey, userID)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", walletID.String())
+			req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+
+			tt.setupMock()
+			w := httptest.NewRecorder()
+			tt.handle(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response struct {
+					Data types.Wallet `json:"data"`
+				}
+				assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				assert.Equal(t, tt.expectedPinned, response.Data.Pinned)
+				// pins aren't edits, the wallet keeps the version it had
+				assert.Equal(t, coreHandlers.ETag(updatedAt), w.Header().Get("ETag"))
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWalletHandler_ListWalletsPaginated_PinnedCursor(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	pinnedAt := time.Now().UTC().Add(-time.Minute)
+	wallet := types.Wallet{WalletID: uuid.New(), Name: "Savings", Currency: "USD", Pinned: true, PinnedAt: coreTypes.TimestampPtr(&pinnedAt)}
+
+	// a page ending on a pinned wallet resumes among the pinned ones, keeping the filter
+	mockService.On("ListWalletsPaginated", mock.Anything, userID, mock.Anything, uuid.Nil, true, int32(1), coreTypes.SortOrderDesc, types.WalletFilter{Ungrouped: true}).
+		Return([]types.Wallet{wallet}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/wallets?limit=1&group_id=none", nil)
+	req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+	w := httptest.NewRecorder()
+	handler.ListWalletsPaginated(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Meta struct {
+			NextToken string `json:"next_token"`
+		} `json:"meta"`
+	}
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	cursor, err := coreTypes.DecodeCursor(response.Meta.NextToken, "", coreTypes.DefaultLimitPolicy())
+	assert.NoError(t, err)
+	if assert.NotNil(t, cursor) {
+		assert.True(t, cursor.Pinned)
+		assert.True(t, cursor.Timestamp.Equal(pinnedAt))
+		assert.Equal(t, wallet.WalletID, cursor.ID)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestWalletHandler_ListWalletsPaginated_OnlyIDs(t *testing.T) {
+	userID := uuid.New()
+	list := func(handler *WalletHandler, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+		w := httptest.NewRecorder()
+		handler.ListWalletsPaginated(w, req)
+		return w
+	}
+
+	t.Run("page ending on a pinned wallet resumes among the pinned ones", func(t *testing.T) {
+		mockService, handler := setupTest(t)
+		pinnedAt := time.Now().UTC().Add(-time.Minute)
+		key := coreTypes.KeyedID{ID: uuid.New(), CreatedAt: pinnedAt.Add(-time.Hour), PinnedAt: &pinnedAt}
+		mockService.On("ListWalletIDsPaginated", mock.Anything, userID, mock.Anything, uuid.Nil, true, int32(1), coreTypes.SortOrderDesc, types.WalletFilter{}).
+			Return([]coreTypes.KeyedID{key}, nil)
+
+		w := list(handler, "/wallets?limit=1&only_ids=true")
+		require.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Data struct {
+				IDs       []uuid.UUID `json:"ids"`
+				NextToken string      `json:"next_token"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.Equal(t, []uuid.UUID{key.ID}, response.Data.IDs)
+		cursor, err := coreTypes.DecodeCursor(response.Data.NextToken, "", coreTypes.DefaultLimitPolicy())
+		require.NoError(t, err)
+		assert.True(t, cursor.Pinned)
+		assert.True(t, cursor.Timestamp.Equal(pinnedAt))
+		assert.Equal(t, key.ID, cursor.ID)
+		mockService.AssertNotCalled(t, "ListWalletsPaginated")
+	})
+
+	t.Run("expand is rejected", func(t *testing.T) {
+		mockService, handler := setupTest(t)
+		w := list(handler, "/wallets?only_ids=true&expand=project")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "expand: not supported with only_ids")
+		mockService.AssertNotCalled(t, "ListWalletIDsPaginated")
+	})
+}
+
+func TestWalletHandler_ListWalletsPaginated_ExpandProject(t *testing.T) {
+	userID, projectID := uuid.New(), uuid.New()
+	wallets := []types.Wallet{
+		{WalletID: uuid.New(), Name: "Materials", Currency: "USD", ProjectID: &projectID},
+		{WalletID: uuid.New(), Name: "Savings", Currency: "USD"},
+	}
+
+	t.Run("embeds the projects, null outside any project", func(t *testing.T) {
+		mockService, handler := setupTest(t)
+		mockService.On("ListWalletsPaginated", mock.Anything, userID, mock.Anything, mock.Anything, true, int32(10), coreTypes.SortOrderDesc, types.WalletFilter{}).
+			Return(wallets, nil)
+		mockService.On("ExpandWalletProjects", mock.Anything, userID, wallets).Return([]types.WalletWithProject{
+			{Wallet: wallets[0], Project: &types.WalletProject{ProjectID: projectID, ProjectName: "Home renovation"}},
+			{Wallet: wallets[1]},
+		}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/wallets?expand=project", nil)
+		req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+		w := httptest.NewRecorder()
+		handler.ListWalletsPaginated(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Data []map[string]json.RawMessage `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		require.Len(t, response.Data, 2)
+		assert.JSONEq(t, `{"projectId": "`+projectID.String()+`", "projectName": "Home renovation"}`, string(response.Data[0]["project"]))
+		assert.JSONEq(t, `"Materials"`, string(response.Data[0]["name"]))
+		project, ok := response.Data[1]["project"]
+		assert.True(t, ok)
+		assert.Equal(t, "null", string(project))
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("leaves the project out by default", func(t *testing.T) {
+		mockService, handler := setupTest(t)
+		mockService.On("ListWalletsPaginated", mock.Anything, userID, mock.Anything, mock.Anything, true, int32(10), coreTypes.SortOrderDesc, types.WalletFilter{}).
+			Return(wallets, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/wallets", nil)
+		req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+		w := httptest.NewRecorder()
+		handler.ListWalletsPaginated(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Data []map[string]json.RawMessage `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		require.Len(t, response.Data, 2)
+		assert.NotContains(t, response.Data[1], "project")
+		mockService.AssertNotCalled(t, "ExpandWalletProjects")
+	})
+
+	t.Run("unknown expand", func(t *testing.T) {
+		_, handler := setupTest(t)
+		req := httptest.NewRequest(http.MethodGet, "/wallets?expand=group", nil)
+		req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+		w := httptest.NewRecorder()
+		handler.ListWalletsPaginated(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestWalletHandler_ListWalletsPaginated_Stream(t *testing.T) {
+	userID := uuid.New()
+	pinnedAt := time.Now().UTC().Add(-time.Minute)
+	wallets := []types.Wallet{
+		{WalletID: uuid.New(), Name: "Pinned", Currency: "USD", Pinned: true, PinnedAt: coreTypes.TimestampPtr(&pinnedAt)},
+		{WalletID: uuid.New(), Name: "Savings", Currency: "USD", CreatedAt: coreTypes.NewTimestamp(time.Now().UTC().Add(-time.Hour))},
+	}
+	list := func(handler *WalletHandler, target, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+		w := httptest.NewRecorder()
+		handler.ListWalletsPaginated(w, req)
+		return w
+	}
+
+	t.Run("full page ends with the next token", func(t *testing.T) {
+		mockService, handler := setupTest(t)
+		currency := "USD"
+		mockService.On("StreamWalletsPaginated", mock.Anything, userID, mock.Anything, mock.Anything, true, int32(2), coreTypes.SortOrderDesc, types.WalletFilter{Currency: &currency}).
+			Return(wallets, nil)
+
+		w := list(handler, "/wallets?limit=2&currency=USD", "application/x-ndjson")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-ndjson; charset=utf-8", w.Header().Get("Content-Type"))
+
+		lines := strings.Split(strings.TrimSuffix(w.Body.String(), "\n"), "\n")
+		require.Len(t, lines, 3)
+		assert.Contains(t, lines[0], `"name":"Pinned"`)
+		assert.Contains(t, lines[1], `"name":"Savings"`)
+		var trailer struct {
+			Meta struct {
+				Count     int    `json:"count"`
+				NextToken string `json:"next_token"`
+			} `json:"meta"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(lines[2]), &trailer))
+		assert.Equal(t, 2, trailer.Meta.Count)
+		// the token continues the unpinned wallets after the last one streamed
+		cursor, err := coreTypes.DecodeCursor(trailer.Meta.NextToken, "", coreTypes.DefaultLimitPolicy())
+		require.NoError(t, err)
+		assert.False(t, cursor.Pinned)
+		assert.Equal(t, wallets[1].WalletID, cursor.ID)
+		mockService.AssertNotCalled(t, "ListWalletsPaginated")
+	})
+
+	t.Run("error midway ends with the error line", func(t *testing.T) {
+		mockService, handler := setupTest(t)
+		mockService.On("StreamWalletsPaginated", mock.Anything, userID, mock.Anything, mock.Anything, true, int32(10), coreTypes.SortOrderDesc, types.WalletFilter{}).
+			Return(wallets[:1], fmt.Errorf("connection reset"))
+
+		w := list(handler, "/wallets?stream=true", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		lines := strings.Split(strings.TrimSuffix(w.Body.String(), "\n"), "\n")
+		require.Len(t, lines, 2)
+		assert.Contains(t, lines[0], `"name":"Pinned"`)
+		assert.Contains(t, lines[1], `{"error":{"type":"DATABASE_ERROR"`)
+		assert.NotContains(t, w.Body.String(), `"meta":{"count"`)
+	})
+
+	t.Run("expand isn't streamed", func(t *testing.T) {
+		mockService, handler := setupTest(t)
+		w := list(handler, "/wallets?stream=true&expand=project", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "expand: not supported when streaming")
+		mockService.AssertNotCalled(t, "StreamWalletsPaginated")
+	})
+
+	t.Run("invalid stream parameter", func(t *testing.T) {
+		_, handler := setupTest(t)
+		w := list(handler, "/wallets?stream=maybe", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "stream: must be true or false")
+	})
+}
+
+func TestWalletHandler_ListWalletsPaginated_ByIDs(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	first, second, trashed := uuid.New(), uuid.New(), uuid.New()
+	mockService.On("GetWalletsByIDs", mock.Anything, userID, []uuid.UUID{second, trashed, first}).Return([]types.Wallet{
+		{WalletID: second, Name: "Savings", Currency: "USD"},
+		{WalletID: first, Name: "Materials", Currency: "USD"},
+	}, []uuid.UUID{trashed}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/wallets?ids="+second.String()+","+trashed.String()+","+first.String(), nil)
+	req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+	w := httptest.NewRecorder()
+	handler.ListWalletsPaginated(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data []types.Wallet `json:"data"`
+		Meta struct {
+			Missing []uuid.UUID `json:"missing"`
+		} `json:"meta"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	require.Len(t, response.Data, 2)
+	assert.Equal(t, second, response.Data[0].WalletID)
+	assert.Equal(t, first, response.Data[1].WalletID)
+	assert.Equal(t, []uuid.UUID{trashed}, response.Meta.Missing)
+	mockService.AssertNotCalled(t, "ListWalletsPaginated")
+}
+
+func TestWalletHandler_ListWalletsPaginated_CursorMismatch(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	groupID := uuid.New()
+	wallet := types.Wallet{WalletID: uuid.New(), Name: "Savings", Currency: "USD", CreatedAt: coreTypes.NewTimestamp(time.Now().UTC().Add(-time.Minute))}
+
+	list := func(target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+		w := httptest.NewRecorder()
+		handler.ListWalletsPaginated(w, req)
+		return w
+	}
+
+	mockService.On("ListWalletsPaginated", mock.Anything, userID, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return([]types.Wallet{wallet}, nil)
+	w := list("/wallets?limit=1&group_id=" + groupID.String())
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Meta struct {
+			NextToken string `json:"next_token"`
+		} `json:"meta"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	token := url.QueryEscape(response.Meta.NextToken)
+
+	tests := []struct {
+		name           string
+		target         string
+		expectedStatus int
+	}{
+		{name: "same filter", target: "/wallets?limit=1&group_id=" + groupID.String() + "&next_token=" + token, expectedStatus: http.StatusOK},
+		{name: "same filter with another page size", target: "/wallets?limit=5&group_id=" + groupID.String() + "&next_token=" + token, expectedStatus: http.StatusOK},
+		{name: "another group", target: "/wallets?limit=1&group_id=none&next_token=" + token, expectedStatus: http.StatusBadRequest},
+		{name: "filter dropped", target: "/wallets?limit=1&next_token=" + token, expectedStatus: http.StatusBadRequest},
+		{name: "filter added", target: "/wallets?limit=1&currency=USD&group_id=" + groupID.String() + "&next_token=" + token, expectedStatus: http.StatusBadRequest},
+		{name: "another order", target: "/wallets?limit=1&order=asc&group_id=" + groupID.String() + "&next_token=" + token, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := list(tt.target)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusBadRequest {
+				var response coreErrors.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				assert.Equal(t, coreErrors.ErrorTypeCursorMismatch, response.Type)
+				assert.Contains(t, response.ErrorText, "does not match")
+			}
+		})
+	}
+}
+
+func TestWalletHandler_AttachWalletsToProject(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	projectID := uuid.New()
+	walletID := uuid.New()
+
+	tests := []struct {
+		name           string
+		projectID      string
+		body           string
+		setupMock      func()
+		expectedStatus int
+	}{
+		{
+			name:      "successful attach",
+			projectID: projectID.String(),
+			body:      `{"walletIds":["` + walletID.String() + `"]}`,
+			setupMock: func() {
+				mockService.On("AttachWalletsToProject", mock.Anything, userID, types.WalletAttachPayload{
+					ProjectID: projectID,
+					WalletIDs: []uuid.UUID{walletID},
+				}).Return(types.WalletAttachResult{Attached: 1}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid project ID",
+			projectID:      "invalid-uuid",
+			body:           `{"walletIds":["` + walletID.String() + `"]}`,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "no wallets",
+			projectID:      projectID.String(),
+			body:           `{"walletIds":[]}`,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "project not found",
+			projectID: projectID.String(),
+			body:      `{"walletIds":["` + walletID.String() + `"]}`,
+			setupMock: func() {
+				mockService.On("AttachWalletsToProject", mock.Anything, userID, mock.Anything).
+					Return(types.WalletAttachResult{}, coreErrors.NewNotFoundError("project %s not found", projectID))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+
+			req := httptest.NewRequest(http.MethodPost, "/projects/"+tt.projectID+"/wallets/attach", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.projectID)
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			req = req.WithContext(context.WithValue(ctx, requestcontext.UserIDKey, userID))
+
+			tt.setupMock()
+			w := httptest.NewRecorder()
+			handler.AttachWalletsToProject(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response struct {
+					Data types.WalletAttachResult `json:"data"`
+				}
+				assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				assert.Equal(t, int64(1), response.Data.Attached)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWalletHandler_ExportStatement(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	walletID := uuid.New()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	rows := []types.StatementRow{
+		{Date: coreTypes.NewTimestamp(time.Date(2024, 1, 5, 9, 30, 0, 0, time.UTC)), Description: "Groceries", Debit: 20.1, Balance: 79.9, Currency: "EUR"},
+		{Date: coreTypes.NewTimestamp(time.Date(2024, 1, 12, 9, 30, 0, 0, time.UTC)), Description: "Top up, January", Credit: 50, Balance: 129.9, Currency: "EUR"},
+		{Date: coreTypes.NewTimestamp(to), Description: "Closing balance", Debit: 20.1, Credit: 50, Balance: 129.9, Currency: "EUR", Summary: true},
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "iso dates",
+			query: "?from=2024-01-01&to=2024-01-31",
+			setupMock: func() {
+				mockService.On("ExportStatement", mock.Anything, walletID, userID,
+					types.StatementParams{From: from, To: to, Format: types.StatementFormatISO}).Return(rows, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: "date,description,debit,credit,balance\n" +
+				"2024-01-05,Groceries,20.10,,79.90\n" +
+				"2024-01-12,\"Top up, January\",,50.00,129.90\n" +
+				"2024-01-31,Closing balance,20.10,50.00,129.90\n",
+		},
+		{
+			name:  "eu dates",
+			query: "?from=2024-01-01&to=2024-01-31&format=eu",
+			setupMock: func() {
+				mockService.On("ExportStatement", mock.Anything, walletID, userID,
+					types.StatementParams{From: from, To: to, Format: types.StatementFormatEU}).Return(rows[:1], nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "date,description,debit,credit,balance\n05/01/2024,Groceries,20.10,,79.90\n",
+		},
+		{
+			name:  "us dates",
+			query: "?from=2024-01-01&to=2024-01-31&format=us",
+			setupMock: func() {
+				mockService.On("ExportStatement", mock.Anything, walletID, userID,
+					types.StatementParams{From: from, To: to, Format: types.StatementFormatUS}).Return(rows[:1], nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "date,description,debit,credit,balance\n01/05/2024,Groceries,20.10,,79.90\n",
+		},
+		{
+			name:           "range over two years",
+			query:          "?from=2022-01-01&to=2024-01-01",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "to before from",
+			query:          "?from=2024-02-01&to=2024-01-01",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing from",
+			query:          "?to=2024-01-01",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown format",
+			query:          "?from=2024-01-01&to=2024-01-31&format=jp",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "unknown wallet",
+			query: "?from=2024-01-01&to=2024-01-31",
+			setupMock: func() {
+				mockService.On("ExportStatement", mock.Anything, walletID, userID, mock.Anything).
+					Return([]types.StatementRow{}, coreErrors.NewNotFoundError("wallet not found"))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+
+			req := httptest.NewRequest(http.MethodGet, "/wallets/"+walletID.String()+"/statement.csv"+tt.query, nil)
+			req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", walletID.String())
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			tt.setupMock()
+			w := httptest.NewRecorder()
+			handler.ExportStatement(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWalletHandler_ExportStatement_JSON(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	walletID := uuid.New()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	rows := []types.StatementRow{
+		{Date: coreTypes.NewTimestamp(time.Date(2024, 1, 5, 9, 30, 0, 0, time.UTC)), Description: "Groceries", Debit: 20.1, Balance: 79.9, Currency: "EUR"},
+		{Date: coreTypes.NewTimestamp(to), Description: "Closing balance", Debit: 20.1, Balance: 79.9, Currency: "EUR", Summary: true},
+	}
+
+	tests := []struct {
+		name           string
+		accept         string
+		expectedStatus int
+	}{
+		{name: "json", accept: "application/json", expectedStatus: http.StatusOK},
+		{name: "unsupported", accept: "text/vcard", expectedStatus: http.StatusNotAcceptable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			if tt.expectedStatus == http.StatusOK {
+				mockService.On("ExportStatement", mock.Anything, walletID, userID,
+					types.StatementParams{From: from, To: to, Format: types.StatementFormatISO}).Return(rows, nil)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/wallets/"+walletID.String()+"/statement.csv?from=2024-01-01&to=2024-01-31", nil)
+			req.Header.Set("Accept", tt.accept)
+			req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", walletID.String())
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			w := httptest.NewRecorder()
+			handler.ExportStatement(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+			var exported []types.StatementRow
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &exported))
+			assert.Equal(t, rows, exported)
+		})
+	}
+}
+
+func TestWalletHandler_PayloadShape(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+	walletID := uuid.New()
+
+	bodies := []struct {
+		name    string
+		payload string
+		create  bool
+	}{
+		{name: "array on create", payload: `[{"name": "Mass"}]`, create: true},
+		{name: "scalar on create", payload: `"Mass"`, create: true},
+		{name: "array on update", payload: `[{"name": "Mass"}, {"name": "Update"}]`},
+		{name: "empty array on update", payload: `[]`},
+		{name: "scalar on update", payload: `42`},
+		{name: "null on update", payload: `null`},
+		{name: "only unknown keys on update", payload: `{"fullName": "Mass", "colour": "red"}`},
+	}
+
+	for _, tt := range bodies {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			method, handle := http.MethodPost, handler.CreateWallet
+			req := httptest.NewRequest(method, "/wallets", strings.NewReader(tt.payload))
+			if !tt.create {
+				mockService.On("GetWallet", mock.Anything, walletID, userID).Return(types.Wallet{WalletID: walletID, Name: "Main", Currency: "USD"}, nil)
+				method, handle = http.MethodPut, handler.UpdateWallet
+				req = httptest.NewRequest(method, "/wallets/"+walletID.String(), strings.NewReader(tt.payload))
+				rctx := chi.NewRouteContext()
+				rctx.URLParams.Add("id", walletID.String())
+				req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+
+			w := httptest.NewRecorder()
+			handle(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			var response coreErrors.ErrorResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+			assert.Equal(t, coreErrors.ErrorTypePayloadShape, response.Type)
+			mockService.AssertNotCalled(t, "CreateWallet", mock.Anything, mock.Anything, mock.Anything)
+			mockService.AssertNotCalled(t, "UpdateWallet", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+// projectionRepository serves a single wallet, the projection reads nothing else
+type projectionRepository struct {
+	repository.WalletRepository
+	wallet types.Wallet
+}
+
+func (r *projectionRepository) GetWallet(ctx context.Context, walletID, userID uuid.UUID) (types.Wallet, error) {
+	if walletID != r.wallet.WalletID || userID != r.wallet.UserID {
+		return types.Wallet{}, coreRepository.ErrNotFound
+	}
+	return r.wallet, nil
+}
+
+func TestWalletHandler_ProjectBalance(t *testing.T) {
+	userID := uuid.New()
+	wallet := types.Wallet{WalletID: uuid.New(), UserID: userID, Name: "Savings", Currency: "EUR", Balance: float64Ptr(1000)}
+	handler := NewWalletHandler(service.NewWalletService(&projectionRepository{wallet: wallet}, "", nil, nil, nil, zap.NewNop()), testLimits, zap.NewNop())
+
+	tests := []struct {
+		name           string
+		walletID       uuid.UUID
+		query          string
+		expectedStatus int
+		expectedMonths []types.ProjectionMonth
+		expectedTotals [3]float64 // contributed, interest, final balance
+	}{
+		{
+			name:           "contributions with interest",
+			walletID:       wallet.WalletID,
+			query:          "monthly_contribution=200&annual_rate=0.12&months=3",
+			expectedStatus: http.StatusOK,
+			// 1% a month on the balance the month starts with, 14.221 rounds to 14.22
+			expectedMonths: []types.ProjectionMonth{
+				{Month: 1, Contributed: 200, Interest: 10, Balance: 1210},
+				{Month: 2, Contributed: 200, Interest: 12.1, Balance: 1422.1},
+				{Month: 3, Contributed: 200, Interest: 14.22, Balance: 1636.32},
+			},
+			expectedTotals: [3]float64{600, 36.32, 1636.32},
+		},
+		{
+			name:           "zero rate",
+			walletID:       wallet.WalletID,
+			query:          "monthly_contribution=200&months=3",
+			expectedStatus: http.StatusOK,
+			expectedMonths: []types.ProjectionMonth{
+				{Month: 1, Contributed: 200, Balance: 1200},
+				{Month: 2, Contributed: 200, Balance: 1400},
+				{Month: 3, Contributed: 200, Balance: 1600},
+			},
+			expectedTotals: [3]float64{600, 0, 1600},
+		},
+		{
+			name:           "negative rate is a fee",
+			walletID:       wallet.WalletID,
+			query:          "annual_rate=-0.12&months=3",
+			expectedStatus: http.StatusOK,
+			expectedMonths: []types.ProjectionMonth{
+				{Month: 1, Interest: -10, Balance: 990},
+				{Month: 2, Interest: -9.9, Balance: 980.1},
+				{Month: 3, Interest: -9.8, Balance: 970.3},
+			},
+			expectedTotals: [3]float64{0, -29.7, 970.3},
+		},
+		{name: "too many months", walletID: wallet.WalletID, query: "months=601", expectedStatus: http.StatusBadRequest},
+		{name: "no months", walletID: wallet.WalletID, query: "months=0", expectedStatus: http.StatusBadRequest},
+		{name: "rate above 1", walletID: wallet.WalletID, query: "annual_rate=1.5", expectedStatus: http.StatusBadRequest},
+		{name: "rate below -1", walletID: wallet.WalletID, query: "annual_rate=-2", expectedStatus: http.StatusBadRequest},
+		{name: "contribution over the amount bound", walletID: wallet.WalletID, query: "monthly_contribution=100000000", expectedStatus: http.StatusBadRequest},
+		{name: "contribution not a number", walletID: wallet.WalletID, query: "monthly_contribution=lots", expectedStatus: http.StatusBadRequest},
+		{name: "another user's wallet", walletID: uuid.New(), query: "months=3", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/wallets/"+tt.walletID.String()+"/projection?"+tt.query, nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.walletID.String())
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+			w := httptest.NewRecorder()
+			handler.ProjectBalance(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var response struct {
+				Data types.Projection `json:"data"`
+			}
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+			assert.Equal(t, 1000.0, response.Data.StartingBalance)
+			assert.Equal(t, "EUR", response.Data.Currency)
+			assert.Equal(t, tt.expectedMonths, response.Data.Months)
+			assert.Equal(t, tt.expectedTotals, [3]float64{response.Data.TotalContributed, response.Data.TotalInterest, response.Data.FinalBalance})
+		})
+	}
+}
+
+func TestWalletHandler_NetWorth(t *testing.T) {
+	mockService, handler := setupTest(t)
+	userID := uuid.New()
+
+	netWorth := types.NetWorth{
+		Totals: []types.CurrencyTotal{
+			{Currency: "EUR", Total: 1000.55, WalletCount: 2},
+			{Currency: "USD", Total: 20, WalletCount: 1},
+		},
+		Converted: &types.ConvertedTotal{Currency: "USD", Total: 1120.61, Rates: map[string]float64{"EUR": 1.1, "USD": 1}},
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func()
+		expectedStatus int
+	}{
+		{
+			name:  "totals by currency",
+			query: "",
+			setupMock: func() {
+				mockService.On("NetWorth", mock.Anything, userID, types.NetWorthParams{}).Return(types.NetWorth{Totals: netWorth.Totals}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "archived and converted",
+			query: "?include_archived=true&convert_to=usd",
+			setupMock: func() {
+				mockService.On("NetWorth", mock.Anything, userID, types.NetWorthParams{IncludeArchived: true, ConvertTo: "USD"}).Return(netWorth, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "no wallets",
+			query: "",
+			setupMock: func() {
+				mockService.On("NetWorth", mock.Anything, userID, types.NetWorthParams{}).Return(types.NetWorth{Totals: []types.CurrencyTotal{}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid currency",
+			query:          "?convert_to=DOLLARS",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "currency without a rate",
+			query: "?convert_to=GBP",
+			setupMock: func() {
+				mockService.On("NetWorth", mock.Anything, userID, types.NetWorthParams{ConvertTo: "GBP"}).
+					Return(types.NetWorth{}, coreErrors.NewValidationError("convert_to: no exchange rate from EUR to GBP"))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.ExpectedCalls = nil
+			tt.setupMock()
+
+			req := httptest.NewRequest(http.MethodGet, "/me/net-worth"+tt.query, nil)
+			req = req.WithContext(context.WithValue(req.Context(), requestcontext.UserIDKey, userID))
+			w := httptest.NewRecorder()
+			handler.NetWorth(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var response struct {
+				Data types.NetWorth `json:"data"`
+			}
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+			expected := mockService.ExpectedCalls[0].ReturnArguments.Get(0).(types.NetWorth)
+			assert.Equal(t, expected, response.Data)
 			mockService.AssertExpectations(t)
 		})
 	}
@@ -10,6 +10,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -17,8 +18,11 @@ import (
 	"time"
 
 	"github.com/Abdelrahman-habib/expense-tracker/config"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/currency"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/deletion"
 	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/validate"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/handlers"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/repository"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/service"
@@ -26,6 +30,8 @@ import (
 	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/pressly/goose/v3"
@@ -133,8 +139,10 @@ func (s *WalletIntegrationTestSuite) SetupSuite() {
 	// Initialize components
 	logger := zap.NewNop()
 	repo := repository.NewWalletRepository(dbService.Queries())
-	walletService := service.NewWalletService(repo, logger)
-	s.handler = handlers.NewWalletHandler(walletService, logger)
+	rates, err := currency.NewStaticRates("USD", map[string]float64{"EUR": 1.1})
+	require.NoError(s.T(), err)
+	walletService := service.NewWalletService(repo, validate.RoundHalfUp, rates, nil, nil, logger)
+	s.handler = handlers.NewWalletHandler(walletService, coreTypes.DefaultLimitPolicy(), logger)
 
 	// Setup router
 	router := chi.NewRouter()
@@ -146,8 +154,14 @@ func (s *WalletIntegrationTestSuite) SetupSuite() {
 			r.Get("/", s.handler.GetWallet)
 			r.Put("/", s.handler.UpdateWallet)
 			r.Delete("/", s.handler.DeleteWallet)
+			r.Get("/delete-preview", s.handler.PreviewWalletDeletion)
+			r.Get("/statement.csv", s.handler.ExportStatement)
+			r.Post("/pin", s.handler.PinWallet)
+			r.Post("/unpin", s.handler.UnpinWallet)
 		})
 	})
+	router.Post("/projects/{id}/wallets/attach", s.handler.AttachWalletsToProject)
+	router.Get("/me/net-worth", s.handler.NetWorth)
 	s.router = router
 }
 
@@ -392,14 +406,20 @@ func (s *WalletIntegrationTestSuite) createTestWallets(count int) []types.Wallet
 		s.Require().NoError(err)
 
 		walletData := response["data"].(map[string]interface{})
-		createdAt, err := time.Parse(time.RFC3339, walletData["createdAt"].(string))
+		walletID := uuid.MustParse(walletData["walletId"].(string))
+		_, err = time.Parse(coreTypes.TimestampLayout, walletData["createdAt"].(string))
+		s.Require().NoError(err)
+
+		// responses carry createdAt to the millisecond, cursors are built from the exact one
+		var createdAt time.Time
+		err = s.pool.QueryRow(s.ctx, "SELECT created_at FROM wallets WHERE wallet_id = $1", walletID).Scan(&createdAt)
 		s.Require().NoError(err)
 
 		wallets[count-1-i] = types.Wallet{ // Store in reverse order
-			WalletID:  uuid.MustParse(walletData["walletId"].(string)),
+			WalletID:  walletID,
 			Name:      walletData["name"].(string),
 			Currency:  walletData["currency"].(string),
-			CreatedAt: createdAt,
+			CreatedAt: coreTypes.NewTimestamp(createdAt),
 		}
 		time.Sleep(time.Millisecond * 10) // Ensure distinct timestamps
 	}
@@ -444,7 +464,7 @@ func (s *WalletIntegrationTestSuite) TestListWalletsPaginated() {
 			name: "second page with next_token",
 			queryParams: map[string]string{
 				"limit":      "5",
-				"next_token": coreTypes.EncodeCursor(wallets[4].CreatedAt, wallets[4].WalletID),
+				"next_token": coreTypes.EncodeCursor(wallets[4].CreatedAt.Time, wallets[4].WalletID, s.userID),
 			},
 			expectedStatus:  http.StatusOK,
 			expectedLen:     5,
@@ -1039,7 +1059,7 @@ func (s *WalletIntegrationTestSuite) TestResponsePayloadStructure() {
 			Currency:  "USD",
 			Balance:   float64Ptr(1000.50),
 			ProjectID: nil, // Optional
-			Tags:      []uuid.UUID{uuid.New(), uuid.New()},
+			Tags:      s.createTestTags(2),
 		}
 
 		payloadBytes, err := json.Marshal(createPayload)
@@ -1078,9 +1098,9 @@ func (s *WalletIntegrationTestSuite) TestResponsePayloadStructure() {
 		s.NotEmpty(data["updatedAt"])
 
 		// Verify timestamps are in correct format
-		_, err = time.Parse(time.RFC3339, data["createdAt"].(string))
+		_, err = time.Parse(coreTypes.TimestampLayout, data["createdAt"].(string))
 		s.NoError(err)
-		_, err = time.Parse(time.RFC3339, data["updatedAt"].(string))
+		_, err = time.Parse(coreTypes.TimestampLayout, data["updatedAt"].(string))
 		s.NoError(err)
 
 		// Verify tags array
@@ -1095,3 +1115,528 @@ func (s *WalletIntegrationTestSuite) TestResponsePayloadStructure() {
 		s.Nil(data["projectId"]) // Since we didn't set it
 	})
 }
+
+// createTestTags creates tags owned by the test user; tags the user doesn't own are dropped on write
+func (s *WalletIntegrationTestSuite) createTestTags(count int) []uuid.UUID {
+	tags := make([]uuid.UUID, count)
+	for i := range tags {
+		err := s.pool.QueryRow(s.ctx, `
+			INSERT INTO tags (user_id, name) VALUES ($1, $2) RETURNING tag_id
+		`, s.userID, fmt.Sprintf("test tag %d", i)).Scan(&tags[i])
+		s.Require().NoError(err)
+	}
+	return tags
+}
+
+func (s *WalletIntegrationTestSuite) TestAttachWalletsToProject() {
+	var projectID uuid.UUID
+	err := s.pool.QueryRow(s.ctx, `
+		INSERT INTO projects (user_id, name) VALUES ($1, 'Renovation') RETURNING project_id
+	`, s.userID).Scan(&projectID)
+	s.Require().NoError(err)
+
+	first := s.createTestWallet()
+	second := s.createTestWallet()
+
+	attach := func(projectID uuid.UUID, walletIDs ...uuid.UUID) (int, map[string]interface{}) {
+		body, err := json.Marshal(types.WalletAttachPayload{WalletIDs: walletIDs})
+		s.Require().NoError(err)
+		req := s.newAuthenticatedRequest(http.MethodPost, "/projects/"+projectID.String()+"/wallets/attach", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+
+		var response map[string]interface{}
+		s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+		return w.Code, response
+	}
+
+	code, response := attach(projectID, first.WalletID)
+	s.Require().Equal(http.StatusOK, code, response)
+	s.Equal(1.0, response["data"].(map[string]interface{})["attached"])
+
+	// the wallet already in the project is skipped
+	code, response = attach(projectID, first.WalletID, second.WalletID)
+	s.Require().Equal(http.StatusOK, code, response)
+	s.Equal(1.0, response["data"].(map[string]interface{})["attached"])
+
+	var linked int
+	err = s.pool.QueryRow(s.ctx, "SELECT COUNT(*) FROM wallets WHERE project_id = $1", projectID).Scan(&linked)
+	s.Require().NoError(err)
+	s.Equal(2, linked)
+
+	code, _ = attach(uuid.New(), first.WalletID)
+	s.Equal(http.StatusNotFound, code)
+
+	// a wallet the user doesn't own rejects the whole request
+	code, _ = attach(projectID, first.WalletID, uuid.New())
+	s.Equal(http.StatusBadRequest, code)
+}
+
+// serveWallet sends an authenticated request and decodes its JSON response
+func (s *WalletIntegrationTestSuite) serveWallet(method, path string) (int, map[string]interface{}) {
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, s.newAuthenticatedRequest(method, path, nil))
+
+	var response map[string]interface{}
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+	return w.Code, response
+}
+
+func (s *WalletIntegrationTestSuite) TestPinnedWalletsPagination() {
+	s.clearWallets()
+	wallets := s.createTestWallets(7) // newest first
+
+	// the last pinned wallet is listed first
+	var pinned []string
+	for _, i := range []int{3, 0, 6} {
+		code, response := s.serveWallet(http.MethodPost, "/wallets/"+wallets[i].WalletID.String()+"/pin")
+		s.Require().Equal(http.StatusOK, code)
+		s.True(response["data"].(map[string]interface{})["pinned"].(bool))
+		pinned = append([]string{wallets[i].WalletID.String()}, pinned...)
+		time.Sleep(10 * time.Millisecond) // distinct pin times
+	}
+
+	var newestFirst, oldestFirst []string
+	for _, w := range wallets {
+		if !slices.Contains(pinned, w.WalletID.String()) {
+			newestFirst = append(newestFirst, w.WalletID.String())
+			oldestFirst = append([]string{w.WalletID.String()}, oldestFirst...)
+		}
+	}
+
+	pageAll := func(order string, limit int) []string {
+		var ids []string
+		token := ""
+		for pages := 0; pages < 10; pages++ {
+			values := url.Values{"limit": {fmt.Sprint(limit)}}
+			if token != "" {
+				values.Set("next_token", token)
+			} else {
+				values.Set("order", order)
+			}
+			code, response := s.serveWallet(http.MethodGet, "/wallets/paginated?"+values.Encode())
+			s.Require().Equal(http.StatusOK, code)
+			for _, item := range response["data"].([]interface{}) {
+				ids = append(ids, item.(map[string]interface{})["walletId"].(string))
+			}
+			token, _ = response["meta"].(map[string]interface{})["next_token"].(string)
+			if token == "" {
+				return ids
+			}
+		}
+		s.FailNow("pagination didn't end")
+		return nil
+	}
+
+	for _, limit := range []int{1, 2, 3, 4} {
+		s.Equal(append(slices.Clone(pinned), newestFirst...), pageAll("desc", limit), "desc with limit %d", limit)
+		s.Equal(append(slices.Clone(pinned), oldestFirst...), pageAll("asc", limit), "asc with limit %d", limit)
+	}
+
+	// unpinning moves the wallet back to its place
+	code, _ := s.serveWallet(http.MethodPost, "/wallets/"+wallets[0].WalletID.String()+"/unpin")
+	s.Require().Equal(http.StatusOK, code)
+	ids := pageAll("desc", 3)
+	s.Equal([]string{wallets[6].WalletID.String(), wallets[3].WalletID.String(), wallets[0].WalletID.String()}, ids[:3])
+	s.Len(ids, 7)
+}
+
+func (s *WalletIntegrationTestSuite) TestPinnedWalletsCap() {
+	s.clearWallets()
+	wallets := s.createTestWallets(types.MaxPinnedWallets + 1)
+
+	for _, w := range wallets[:types.MaxPinnedWallets] {
+		code, _ := s.serveWallet(http.MethodPost, "/wallets/"+w.WalletID.String()+"/pin")
+		s.Require().Equal(http.StatusOK, code)
+	}
+
+	last := wallets[types.MaxPinnedWallets].WalletID.String()
+	code, response := s.serveWallet(http.MethodPost, "/wallets/"+last+"/pin")
+	s.Equal(http.StatusBadRequest, code)
+	s.Contains(response["message"], "at most 10 wallets can be pinned")
+
+	// trashing a pinned wallet frees its slot
+	code, _ = s.serveWallet(http.MethodDelete, "/wallets/"+wallets[0].WalletID.String())
+	s.Require().Equal(http.StatusOK, code)
+	code, _ = s.serveWallet(http.MethodPost, "/wallets/"+last+"/pin")
+	s.Equal(http.StatusOK, code)
+}
+
+// getStatement fetches the wallet's statement CSV
+func (s *WalletIntegrationTestSuite) getStatement(walletID uuid.UUID, query string) (int, string) {
+	req := s.newAuthenticatedRequest(http.MethodGet, "/wallets/"+walletID.String()+"/statement.csv"+query, nil)
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	return w.Code, w.Body.String()
+}
+
+func (s *WalletIntegrationTestSuite) TestStatementRunningBalance() {
+	var walletID uuid.UUID
+	err := s.pool.QueryRow(s.ctx, `
+		INSERT INTO wallets (user_id, name, balance, currency) VALUES ($1, 'Checking', 0, 'EUR') RETURNING wallet_id
+	`, s.userID).Scan(&walletID)
+	s.Require().NoError(err)
+
+	// entries sharing a timestamp keep the order they were recorded in
+	entries := []struct {
+		amount      float64
+		description string
+		occurredAt  string
+	}{
+		{200, "Opening balance", "2023-12-15 08:00:00"},
+		{-45.10, "Groceries", "2024-03-01 09:15:00"},
+		{1500, "Salary", "2024-03-10 10:00:00"},
+		{-900, "Rent", "2024-03-10 10:00:00"},
+		{-0.10, "Bank fee", "2024-03-10 10:00:00"},
+		{0.30, "Interest", "2024-03-31 23:59:59"},
+		{-60, "After the range", "2024-04-01 00:00:00"},
+	}
+	for _, e := range entries {
+		_, err := s.pool.Exec(s.ctx, `
+			INSERT INTO wallet_ledger_entries (wallet_id, amount, description, occurred_at) VALUES ($1, $2, $3, $4)
+		`, walletID, e.amount, e.description, e.occurredAt)
+		s.Require().NoError(err)
+	}
+
+	code, body := s.getStatement(walletID, "?from=2024-03-01&to=2024-03-31")
+	s.Require().Equal(http.StatusOK, code, body)
+	s.Equal("date,description,debit,credit,balance\n"+
+		"2024-03-01,Groceries,45.10,,154.90\n"+
+		"2024-03-10,Salary,,1500.00,1654.90\n"+
+		"2024-03-10,Rent,900.00,,754.90\n"+
+		"2024-03-10,Bank fee,0.10,,754.80\n"+
+		"2024-03-31,Interest,,0.30,755.10\n"+
+		"2024-03-31,Closing balance,945.20,1500.30,755.10\n", body)
+
+	code, body = s.getStatement(walletID, "?from=2024-03-10&to=2024-03-10&format=eu")
+	s.Require().Equal(http.StatusOK, code, body)
+	s.Equal("date,description,debit,credit,balance\n"+
+		"10/03/2024,Salary,,1500.00,1654.90\n"+
+		"10/03/2024,Rent,900.00,,754.90\n"+
+		"10/03/2024,Bank fee,0.10,,754.80\n"+
+		"10/03/2024,Closing balance,900.10,1500.00,754.80\n", body)
+
+	code, _ = s.getStatement(walletID, "?from=2022-01-01&to=2024-03-31")
+	s.Equal(http.StatusBadRequest, code)
+
+	code, _ = s.getStatement(uuid.New(), "?from=2024-03-01&to=2024-03-31")
+	s.Equal(http.StatusNotFound, code)
+}
+
+func (s *WalletIntegrationTestSuite) TestBalanceChangesAreRecordedInTheLedger() {
+	wallet := s.createTestWallet()
+
+	payload, err := json.Marshal(types.WalletUpdatePayload{Name: wallet.Name, Currency: wallet.Currency, Balance: float64Ptr(900.25)})
+	s.Require().NoError(err)
+	req := s.newAuthenticatedRequest(http.MethodPut, "/wallets/"+wallet.WalletID.String(), bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	s.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+
+	var count int
+	var sum float64
+	err = s.pool.QueryRow(s.ctx, `
+		SELECT COUNT(*), SUM(amount)::float8 FROM wallet_ledger_entries WHERE wallet_id = $1
+	`, wallet.WalletID).Scan(&count, &sum)
+	s.Require().NoError(err)
+	s.Equal(2, count)
+	s.InDelta(900.25, sum, 0.001)
+
+	today := time.Now().UTC().Format(time.DateOnly)
+	code, body := s.getStatement(wallet.WalletID, "?from="+today+"&to="+today)
+	s.Require().Equal(http.StatusOK, code, body)
+	s.Contains(body, ",Opening balance,,1000.50,1000.50\n")
+	s.Contains(body, ",Balance adjustment,100.25,,900.25\n")
+	s.Contains(body, ",Closing balance,100.25,1000.50,900.25\n")
+}
+
+func (s *WalletIntegrationTestSuite) TestBalancesAreRoundedToTheCurrency() {
+	tests := []struct {
+		currency string
+		balance  float64
+		stored   string
+	}{
+		{"USD", 100.555, "100.560"},
+		{"JPY", 1234.5, "1235.000"},
+		{"KWD", 1.2345, "1.235"},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.currency, func() {
+			payload, err := json.Marshal(types.WalletCreatePayload{Name: tt.currency + " Wallet", Currency: tt.currency, Balance: float64Ptr(tt.balance)})
+			s.Require().NoError(err)
+			req := s.newAuthenticatedRequest(http.MethodPost, "/wallets", bytes.NewReader(payload))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			s.router.ServeHTTP(w, req)
+			s.Require().Equal(http.StatusCreated, w.Code, w.Body.String())
+
+			var response struct {
+				Data types.Wallet `json:"data"`
+			}
+			s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+
+			var stored string
+			err = s.pool.QueryRow(s.ctx, `SELECT balance::text FROM wallets WHERE wallet_id = $1`, response.Data.WalletID).Scan(&stored)
+			s.Require().NoError(err)
+			s.Equal(tt.stored, stored)
+		})
+	}
+}
+
+func (s *WalletIntegrationTestSuite) TestWalletStats() {
+	payload, err := json.Marshal(types.WalletCreatePayload{Name: "Stats Wallet", Currency: "EUR"})
+	s.Require().NoError(err)
+	req := s.newAuthenticatedRequest(http.MethodPost, "/wallets", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	s.Require().Equal(http.StatusCreated, w.Code, w.Body.String())
+	var created struct {
+		Data types.Wallet `json:"data"`
+	}
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&created))
+	walletID := created.Data.WalletID
+
+	getStats := func() types.WalletStats {
+		req := s.newAuthenticatedRequest(http.MethodGet, "/wallets/"+walletID.String()+"?include_stats=true", nil)
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+		s.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+		var response struct {
+			Data types.Wallet `json:"data"`
+		}
+		s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+		s.Require().NotNil(response.Data.Stats)
+		return *response.Data.Stats
+	}
+
+	s.Run("a wallet without a ledger has zeros", func() {
+		s.Equal(types.WalletStats{}, getStats())
+	})
+
+	s.Run("entries fall in the windows of their UTC day", func() {
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		day := func(offset int) time.Time { return today.AddDate(0, 0, offset) }
+		entries := []struct {
+			amount     float64
+			occurredAt time.Time
+		}{
+			{-10, day(0)},                                    // every window
+			{-20, day(-6)},                                   // first day of the 7 day window
+			{-40, day(-6).Add(-time.Second)},                 // last second before it
+			{100, day(-29)},                                  // first day of the 30 day window
+			{-80, day(-29).Add(-time.Second)},                // the day before
+			{-160, day(-89)},                                 // first day of the 90 day window
+			{-320, day(-89).Add(-time.Second)},               // the day before
+			{-640, day(1)},                                   // tomorrow
+			{5, day(0).Add(24*time.Hour - time.Millisecond)}, // the end of today
+		}
+		for _, entry := range entries {
+			_, err := s.pool.Exec(s.ctx, `
+				INSERT INTO wallet_ledger_entries (wallet_id, amount, description, occurred_at)
+				VALUES ($1, $2, 'Seeded', $3)
+			`, walletID, entry.amount, entry.occurredAt)
+			s.Require().NoError(err)
+		}
+
+		// a wallet's stats are not mixed up with another user's view of it
+		otherReq := httptest.NewRequest(http.MethodGet, "/wallets/"+walletID.String()+"?include_stats=true", nil)
+		otherReq = otherReq.WithContext(context.WithValue(otherReq.Context(), requestcontext.UserIDKey, uuid.New()))
+		otherW := httptest.NewRecorder()
+		s.router.ServeHTTP(otherW, otherReq)
+		s.Equal(http.StatusNotFound, otherW.Code)
+
+		stats := getStats()
+		s.Equal(types.WalletFlow{Outflow: 30, Inflow: 5}, stats.Last7Days)
+		s.Equal(types.WalletFlow{Outflow: 70, Inflow: 105}, stats.Last30Days)
+		s.Equal(types.WalletFlow{Outflow: 310, Inflow: 105}, stats.Last90Days)
+		s.Equal(2.33, stats.AverageDailySpend)
+	})
+
+	s.Run("stats are only included on request", func() {
+		req := s.newAuthenticatedRequest(http.MethodGet, "/wallets/"+walletID.String(), nil)
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+		s.Require().Equal(http.StatusOK, w.Code)
+		s.NotContains(w.Body.String(), `"stats"`)
+	})
+}
+
+func (s *WalletIntegrationTestSuite) TestDeletionPreviewMatchesTheDelete() {
+	wallet := s.createTestWallet()
+
+	payload, err := json.Marshal(types.WalletUpdatePayload{Name: wallet.Name, Currency: wallet.Currency, Balance: float64Ptr(750)})
+	s.Require().NoError(err)
+	req := s.newAuthenticatedRequest(http.MethodPut, "/wallets/"+wallet.WalletID.String(), bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	s.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, s.newAuthenticatedRequest(http.MethodGet, "/wallets/"+wallet.WalletID.String()+"/delete-preview", nil))
+	s.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	var preview struct {
+		Data deletion.Impact `json:"data"`
+	}
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&preview))
+	s.Equal("wallet", preview.Data.Resource)
+	s.False(preview.Data.Blocked)
+	s.Require().Len(preview.Data.Affected, 2)
+	s.Equal(deletion.Affected{
+		Kind: "wallets", Count: 1, Effect: deletion.EffectTrashed,
+		Balances: []deletion.Balance{{Currency: "USD", Balance: 750}},
+	}, preview.Data.Affected[0])
+	s.Equal(deletion.Affected{Kind: "ledgerEntries", Count: 2, Effect: deletion.EffectKept}, preview.Data.Affected[1])
+
+	code, _ := s.serveWallet(http.MethodDelete, "/wallets/"+wallet.WalletID.String())
+	s.Require().Equal(http.StatusOK, code)
+
+	var trashed, entries int64
+	err = s.pool.QueryRow(s.ctx, `
+		SELECT COUNT(*) FROM wallets WHERE wallet_id = $1 AND deleted_at IS NOT NULL
+	`, wallet.WalletID).Scan(&trashed)
+	s.Require().NoError(err)
+	s.Equal(preview.Data.Affected[0].Count, trashed)
+	err = s.pool.QueryRow(s.ctx, `
+		SELECT COUNT(*) FROM wallet_ledger_entries WHERE wallet_id = $1
+	`, wallet.WalletID).Scan(&entries)
+	s.Require().NoError(err)
+	s.Equal(preview.Data.Affected[1].Count, entries)
+
+	// a trashed wallet has nothing left to preview
+	code, _ = s.serveWallet(http.MethodGet, "/wallets/"+wallet.WalletID.String()+"/delete-preview")
+	s.Equal(http.StatusNotFound, code)
+}
+
+func (s *WalletIntegrationTestSuite) TestTrashingTheDefaultWalletClearsIt() {
+	wallet := s.createTestWallet()
+	other := s.createTestWallet()
+
+	queries := s.service.Queries()
+	user, err := queries.SetUserDefaults(s.ctx, db.SetUserDefaultsParams{
+		UserID:          s.userID,
+		DefaultWalletID: pgtype.UUID{Bytes: wallet.WalletID, Valid: true},
+	})
+	s.Require().NoError(err)
+	s.Equal(wallet.WalletID, uuid.UUID(user.DefaultWalletID.Bytes))
+
+	// another user's wallet can't become the default
+	strangerID := uuid.New()
+	_, err = s.pool.Exec(s.ctx, `
+		INSERT INTO users (user_id, clerk_ex_user_id, name, email)
+		VALUES ($1, 'wit_stranger_clerk_id', 'wit_Stranger', 'wit_stranger@example.com')
+	`, strangerID)
+	s.Require().NoError(err)
+	defer s.pool.Exec(s.ctx, "DELETE FROM users WHERE user_id = $1", strangerID)
+	_, err = queries.SetUserDefaults(s.ctx, db.SetUserDefaultsParams{
+		UserID:          strangerID,
+		DefaultWalletID: pgtype.UUID{Bytes: wallet.WalletID, Valid: true},
+	})
+	s.ErrorIs(err, pgx.ErrNoRows)
+
+	defaultWallet := func() pgtype.UUID {
+		var id pgtype.UUID
+		s.Require().NoError(s.pool.QueryRow(s.ctx, "SELECT default_wallet_id FROM users WHERE user_id = $1", s.userID).Scan(&id))
+		return id
+	}
+
+	// trashing another wallet leaves the default alone
+	code, _ := s.serveWallet(http.MethodDelete, "/wallets/"+other.WalletID.String())
+	s.Require().Equal(http.StatusOK, code)
+	s.True(defaultWallet().Valid)
+
+	code, _ = s.serveWallet(http.MethodDelete, "/wallets/"+wallet.WalletID.String())
+	s.Require().Equal(http.StatusOK, code)
+	s.False(defaultWallet().Valid)
+
+	// a trashed wallet can't become the default either
+	_, err = queries.SetUserDefaults(s.ctx, db.SetUserDefaultsParams{
+		UserID:          s.userID,
+		DefaultWalletID: pgtype.UUID{Bytes: wallet.WalletID, Valid: true},
+	})
+	s.ErrorIs(err, pgx.ErrNoRows)
+}
+
+func (s *WalletIntegrationTestSuite) TestNetWorth() {
+	s.clearWallets()
+
+	netWorth := func(query string) types.NetWorth {
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, s.newAuthenticatedRequest(http.MethodGet, "/me/net-worth"+query, nil))
+		s.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+		var response struct {
+			Data types.NetWorth `json:"data"`
+		}
+		s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+		return response.Data
+	}
+
+	// no wallets, no totals
+	s.Empty(netWorth("").Totals)
+
+	for _, wallet := range []struct {
+		name     string
+		currency string
+		balance  *float64
+		trashed  bool
+	}{
+		{"Checking", "EUR", float64Ptr(1000.55), false},
+		{"Savings", "EUR", float64Ptr(250), false},
+		{"Empty", "EUR", nil, false},
+		{"Cash", "USD", float64Ptr(20), false},
+		{"Old", "USD", float64Ptr(500), true},
+	} {
+		_, err := s.pool.Exec(s.ctx, `INSERT INTO wallets (user_id, name, currency, balance, deleted_at)
+			VALUES ($1, $2, $3, $4, CASE WHEN $5::bool THEN CURRENT_TIMESTAMP END)`,
+			s.userID, wallet.name, wallet.currency, wallet.balance, wallet.trashed)
+		s.Require().NoError(err)
+	}
+
+	s.Equal([]types.CurrencyTotal{
+		{Currency: "EUR", Total: 1250.55, WalletCount: 3},
+		{Currency: "USD", Total: 20, WalletCount: 1},
+	}, netWorth("").Totals)
+
+	archived := netWorth("?include_archived=true&convert_to=USD")
+	s.Equal([]types.CurrencyTotal{
+		{Currency: "EUR", Total: 1250.55, WalletCount: 3},
+		{Currency: "USD", Total: 520, WalletCount: 2},
+	}, archived.Totals)
+	s.Require().NotNil(archived.Converted)
+	s.Equal(1895.61, archived.Converted.Total)
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, s.newAuthenticatedRequest(http.MethodGet, "/me/net-worth?convert_to=GBP", nil))
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *WalletIntegrationTestSuite) TestWalletCountFollowsTheTrash() {
+	s.clearWallets()
+
+	walletCount := func() int32 {
+		counts, err := s.service.Queries().GetUserResourceCounts(s.ctx, s.userID)
+		s.Require().NoError(err)
+		return counts.WalletCount
+	}
+	s.Equal(int32(0), walletCount())
+
+	wallet := s.createTestWallet()
+	s.createTestWallet()
+	s.Equal(int32(2), walletCount())
+
+	code, _ := s.serveWallet(http.MethodDelete, "/wallets/"+wallet.WalletID.String())
+	s.Require().Equal(http.StatusOK, code)
+	s.Equal(int32(1), walletCount())
+
+	code, _ = s.serveWallet(http.MethodPost, "/wallets/"+wallet.WalletID.String()+"/restore")
+	s.Require().Equal(http.StatusOK, code)
+	s.Equal(int32(2), walletCount())
+
+	// purging the wallets left outside the trash takes them off the count
+	s.clearWallets()
+	s.Equal(int32(0), walletCount())
+}
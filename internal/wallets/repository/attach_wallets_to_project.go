@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
+)
+
+// AttachWalletsToProject moves the user's wallets into the project in one statement,
+// returning how many weren't in it already
+func (r *WalletRepositoryImpl) AttachWalletsToProject(ctx context.Context, userID, projectID uuid.UUID, walletIDs []uuid.UUID) (int64, error) {
+	attached, err := r.db.AttachWalletsToProject(ctx, db.AttachWalletsToProjectParams{
+		ProjectID: utils.ToNullableUUID(projectID),
+		UserID:    userID,
+		WalletIds: walletIDs,
+	})
+	if err != nil {
+		return 0, errors.HandleRepositoryError(err, "attach", "wallet(s)")
+	}
+
+	return attached, nil
+}
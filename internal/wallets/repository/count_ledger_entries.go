@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+)
+
+// CountLedgerEntries counts the balance changes recorded for the wallet
+func (r *WalletRepositoryImpl) CountLedgerEntries(ctx context.Context, walletID, userID uuid.UUID) (int64, error) {
+	count, err := r.db.CountWalletLedgerEntries(ctx, db.CountWalletLedgerEntriesParams{
+		WalletID: walletID,
+		UserID:   userID,
+	})
+	if err != nil {
+		return 0, errors.HandleRepositoryError(err, "count", "wallet ledger entries")
+	}
+	return count, nil
+}
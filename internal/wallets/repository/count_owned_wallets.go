@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+)
+
+// CountOwnedWallets counts the wallets among walletIDs that belong to the user and aren't trashed
+func (r *WalletRepositoryImpl) CountOwnedWallets(ctx context.Context, userID uuid.UUID, walletIDs []uuid.UUID) (int64, error) {
+	count, err := r.db.CountOwnedWallets(ctx, db.CountOwnedWalletsParams{
+		UserID:    userID,
+		WalletIds: walletIDs,
+	})
+	if err != nil {
+		return 0, errors.HandleRepositoryError(err, "count", "wallet(s)")
+	}
+
+	return count, nil
+}
@@ -7,11 +7,12 @@ import (
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
 )
 
 // CreateWallet creates a new wallet
 func (r *WalletRepositoryImpl) CreateWallet(ctx context.Context, payload types.WalletCreatePayload, userID uuid.UUID) (types.Wallet, error) {
-	params := createWalletParamsFromPayload(payload, userID)
+	params := createWalletParamsFromPayload(payload, userID, requestcontext.GetActorIDFromContext(ctx, userID))
 	wallet, err := r.db.CreateWallet(ctx, params)
 	if err != nil {
 		return types.Wallet{}, errors.HandleRepositoryError(err, "create", "wallet")
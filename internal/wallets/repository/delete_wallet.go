@@ -2,23 +2,40 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/repository"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
 )
 
-// DeleteWallet deletes a wallet
-func (r *WalletRepositoryImpl) DeleteWallet(ctx context.Context, walletID, userID uuid.UUID) error {
-	err := r.db.DeleteWallet(ctx, db.DeleteWalletParams{
+// DeleteWallet deletes a wallet, with a version only while it is still at that updatedAt
+func (r *WalletRepositoryImpl) DeleteWallet(ctx context.Context, walletID, userID uuid.UUID, version *time.Time) error {
+	deleted, err := r.db.DeleteWallet(ctx, db.DeleteWalletParams{
 		WalletID: walletID,
 		UserID:   userID,
+		Version:  utils.ToNullableTimestamp(version),
 	})
 	if err != nil {
-
 		return errors.HandleRepositoryError(err, "delete", "wallet")
-
+	}
+	if deleted == 0 {
+		return r.missedDelete(ctx, walletID, userID, version)
 	}
 	return nil
 }
+
+// missedDelete returns the error of a delete that found no wallet to delete, a live wallet
+// was at another version than the one the delete was conditioned on
+func (r *WalletRepositoryImpl) missedDelete(ctx context.Context, walletID, userID uuid.UUID, version *time.Time) error {
+	if version != nil {
+		if _, err := r.GetWallet(ctx, walletID, userID); err == nil {
+			return fmt.Errorf("delete wallet %s: %w", walletID, repository.ErrPreconditionFailed)
+		}
+	}
+	return fmt.Errorf("delete wallet %s: %w", walletID, repository.ErrNotFound)
+}
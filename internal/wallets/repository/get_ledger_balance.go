@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
+)
+
+// GetLedgerBalance sums the wallet's ledger entries up to before, its balance at that time
+func (r *WalletRepositoryImpl) GetLedgerBalance(ctx context.Context, walletID, userID uuid.UUID, before time.Time) (float64, error) {
+	balance, err := r.db.GetWalletLedgerBalance(ctx, db.GetWalletLedgerBalanceParams{
+		WalletID: walletID,
+		UserID:   userID,
+		Before:   pgtype.Timestamp{Time: before, Valid: true},
+	})
+	if err != nil {
+		return 0, errors.HandleRepositoryError(err, "get", "wallet ledger balance")
+	}
+
+	if value := utils.GetFloat64Ptr(balance); value != nil {
+		return *value, nil
+	}
+	return 0, nil
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+)
+
+// GetLedgerStats sums the wallet's outflows and inflows over the trailing 7, 30 and 90 UTC
+// days ending with today in a single pass over its ledger, the average is left to the caller
+func (r *WalletRepositoryImpl) GetLedgerStats(ctx context.Context, walletID, userID uuid.UUID, today time.Time) (types.WalletStats, error) {
+	row, err := r.db.GetWalletLedgerStats(ctx, db.GetWalletLedgerStatsParams{
+		Today:    pgtype.Date{Time: today.UTC(), Valid: true},
+		WalletID: walletID,
+		UserID:   userID,
+	})
+	if err != nil {
+		return types.WalletStats{}, errors.HandleRepositoryError(err, "get", "wallet ledger stats")
+	}
+
+	return types.WalletStats{
+		Last7Days:  types.WalletFlow{Outflow: numericValue(row.Outflow7d), Inflow: numericValue(row.Inflow7d)},
+		Last30Days: types.WalletFlow{Outflow: numericValue(row.Outflow30d), Inflow: numericValue(row.Inflow30d)},
+		Last90Days: types.WalletFlow{Outflow: numericValue(row.Outflow90d), Inflow: numericValue(row.Inflow90d)},
+	}, nil
+}
+
+// numericValue converts a sum to a float64, zero when it is null
+func numericValue(n pgtype.Numeric) float64 {
+	if value := utils.GetFloat64Ptr(n); value != nil {
+		return *value
+	}
+	return 0
+}
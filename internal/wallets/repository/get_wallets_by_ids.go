@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+)
+
+// GetWalletsByIDs retrieves the user's wallets with the IDs in the order asked for, along with
+// the IDs of the wallets not found
+func (r *WalletRepositoryImpl) GetWalletsByIDs(ctx context.Context, userID uuid.UUID, walletIDs []uuid.UUID) ([]types.Wallet, []uuid.UUID, error) {
+	wallets, err := r.db.GetWalletsByIDs(ctx, db.GetWalletsByIDsParams{
+		WalletIds: walletIDs,
+		UserID:    userID,
+	})
+	if err != nil {
+		return nil, nil, errors.HandleRepositoryError(err, "get", "wallets")
+	}
+
+	found, missing := repository.OrderByIDs(walletIDs, toWallets(wallets), func(w types.Wallet) uuid.UUID {
+		return w.WalletID
+	})
+	return found, missing, nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/google/uuid"
 
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
 )
 
@@ -14,11 +15,38 @@ type WalletRepository interface {
 	// GetWallet retrieves a wallet by its ID and user ID
 	GetWallet(ctx context.Context, walletID, userID uuid.UUID) (types.Wallet, error)
 
+	// GetWalletsByIDs retrieves the user's wallets with the IDs in the order asked for, along
+	// with the IDs of those that don't exist, are trashed or belong to another user
+	GetWalletsByIDs(ctx context.Context, userID uuid.UUID, walletIDs []uuid.UUID) ([]types.Wallet, []uuid.UUID, error)
+
 	// ListWallets retrieves a paginated list of wallets for a user
 	ListWallets(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]types.Wallet, error)
 
-	// ListWalletsPaginated retrieves a cursor-based paginated list of wallets
-	ListWalletsPaginated(ctx context.Context, userID uuid.UUID, createdAt time.Time, walletID uuid.UUID, limit int32) ([]types.Wallet, error)
+	// ListWalletsPaginated retrieves a cursor-based paginated list of the unpinned wallets narrowed by the filter
+	ListWalletsPaginated(ctx context.Context, userID uuid.UUID, createdAt time.Time, walletID uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.WalletFilter) ([]types.Wallet, error)
+
+	// ListPinnedWalletsPaginated retrieves the pinned wallets after the cursor narrowed by the filter, most recently pinned first
+	ListPinnedWalletsPaginated(ctx context.Context, userID uuid.UUID, pinnedAt time.Time, walletID uuid.UUID, limit int32, filter types.WalletFilter) ([]types.Wallet, error)
+
+	// ListWalletIDsPaginated is ListWalletsPaginated reading only the IDs and the keys the list is paged by
+	ListWalletIDsPaginated(ctx context.Context, userID uuid.UUID, createdAt time.Time, walletID uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.WalletFilter) ([]coreTypes.KeyedID, error)
+
+	// ListPinnedWalletIDsPaginated is ListPinnedWalletsPaginated reading only the IDs and the keys the list is paged by
+	ListPinnedWalletIDsPaginated(ctx context.Context, userID uuid.UUID, pinnedAt time.Time, walletID uuid.UUID, limit int32, filter types.WalletFilter) ([]coreTypes.KeyedID, error)
+
+	// ListWalletsPaginatedStream is ListWalletsPaginated handing each wallet to fn as it is read,
+	// an error from fn stops the stream and is returned as is
+	ListWalletsPaginatedStream(ctx context.Context, userID uuid.UUID, createdAt time.Time, walletID uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.WalletFilter, fn func(types.Wallet) error) error
+
+	// ListPinnedWalletsPaginatedStream is ListPinnedWalletsPaginated handing each wallet to fn as it is read
+	ListPinnedWalletsPaginatedStream(ctx context.Context, userID uuid.UUID, pinnedAt time.Time, walletID uuid.UUID, limit int32, filter types.WalletFilter, fn func(types.Wallet) error) error
+
+	// ListWalletProjects returns the project of each of the user's wallets among walletIDs, by wallet ID, leaving out the wallets outside any project
+	ListWalletProjects(ctx context.Context, userID uuid.UUID, walletIDs []uuid.UUID) (map[uuid.UUID]types.WalletProject, error)
+
+	// SetWalletPinned pins or unpins a wallet, a wallet pinned again keeps its pin time.
+	// Pinning one more than types.MaxPinnedWallets returns ErrPinnedWalletLimit
+	SetWalletPinned(ctx context.Context, walletID, userID uuid.UUID, pinned bool) (types.Wallet, error)
 
 	// CreateWallet creates a new wallet
 	CreateWallet(ctx context.Context, payload types.WalletCreatePayload, userID uuid.UUID) (types.Wallet, error)
@@ -26,12 +54,66 @@ type WalletRepository interface {
 	// UpdateWallet updates an existing wallet
 	UpdateWallet(ctx context.Context, payload types.WalletUpdatePayload, userID uuid.UUID) (types.Wallet, error)
 
-	// DeleteWallet deletes a wallet
-	DeleteWallet(ctx context.Context, walletID, userID uuid.UUID) error
+	// UpdateWalletLocked updates the wallet with the payload merge makes of its current
+	// values, holding the wallet's advisory lock from the read to the write
+	UpdateWalletLocked(ctx context.Context, walletID, userID uuid.UUID, merge func(types.Wallet) (types.WalletUpdatePayload, error)) (types.Wallet, error)
+
+	// DeleteWallet moves a wallet to the trash, with a version only while it is still at
+	// that updatedAt
+	DeleteWallet(ctx context.Context, walletID, userID uuid.UUID, version *time.Time) error
+
+	// PurgeWallet deletes a wallet for good, in the trash or not, along with its ledger
+	// entries, and reports whether it was in the trash. With a version it is only deleted
+	// while it is still at that updatedAt.
+	PurgeWallet(ctx context.Context, walletID, userID uuid.UUID, version *time.Time) (bool, error)
+
+	// ListDeletedWalletsPaginated retrieves a cursor-based paginated list of trashed wallets ordered by deletion time
+	ListDeletedWalletsPaginated(ctx context.Context, userID uuid.UUID, deletedAt time.Time, walletID uuid.UUID, limit int32, order coreTypes.SortOrder) ([]types.Wallet, error)
+
+	// RestoreWallet moves a trashed wallet back to the user's wallets
+	RestoreWallet(ctx context.Context, walletID, userID uuid.UUID) (types.Wallet, error)
 
 	// GetProjectWallets retrieves all wallets associated with a project
 	GetProjectWallets(ctx context.Context, projectID uuid.UUID, userID uuid.UUID) ([]types.Wallet, error)
 
 	// SearchWallets searches for wallets by name
-	SearchWallets(ctx context.Context, userID uuid.UUID, name string, limit int32) ([]types.Wallet, error)
+	SearchWallets(ctx context.Context, userID uuid.UUID, name string, limit, offset int32) ([]types.Wallet, error)
+	// SearchWalletsPicker searches for wallets by name, reading only the columns of the picker view
+	SearchWalletsPicker(ctx context.Context, userID uuid.UUID, name string, limit, offset int32) ([]types.WalletPickerItem, error)
+
+	// ListLowBalanceWallets retrieves the wallets whose balance is below their low balance threshold
+	ListLowBalanceWallets(ctx context.Context, userID uuid.UUID) ([]types.Wallet, error)
+
+	// SumBalancesByCurrency sums the balances of the user's wallets by currency, with includeDeleted those in the trash too
+	SumBalancesByCurrency(ctx context.Context, userID uuid.UUID, includeDeleted bool) ([]types.CurrencyTotal, error)
+
+	// ListFacets counts the distinct values of a facetable field of the user's wallets, most common first
+	ListFacets(ctx context.Context, userID uuid.UUID, field string) ([]coreTypes.Facet, error)
+
+	// AttachWalletsToProject moves the user's wallets into the project, returning how many weren't in it already
+	AttachWalletsToProject(ctx context.Context, userID, projectID uuid.UUID, walletIDs []uuid.UUID) (int64, error)
+
+	// CountOwnedWallets counts the wallets among walletIDs that belong to the user
+	CountOwnedWallets(ctx context.Context, userID uuid.UUID, walletIDs []uuid.UUID) (int64, error)
+
+	// WalletNameExists reports whether the user has a wallet outside the trash with the name, compared case-insensitively
+	WalletNameExists(ctx context.Context, userID uuid.UUID, name string) (bool, error)
+
+	// ProjectExists reports whether the project belongs to the user
+	ProjectExists(ctx context.Context, userID, projectID uuid.UUID) (bool, error)
+
+	// WalletGroupExists reports whether the wallet group belongs to the user
+	WalletGroupExists(ctx context.Context, userID, groupID uuid.UUID) (bool, error)
+
+	// GetLedgerBalance returns the wallet's balance just before the given time
+	GetLedgerBalance(ctx context.Context, walletID, userID uuid.UUID, before time.Time) (float64, error)
+
+	// ListLedgerEntries retrieves a batch of the wallet's ledger entries oldest first, keyset paginated on (occurred_at, seq)
+	ListLedgerEntries(ctx context.Context, walletID, userID uuid.UUID, before, afterOccurredAt time.Time, afterSeq int64, limit int32) ([]types.LedgerEntry, error)
+
+	// GetLedgerStats sums the wallet's outflows and inflows over the trailing 7, 30 and 90 UTC days ending with today
+	GetLedgerStats(ctx context.Context, walletID, userID uuid.UUID, today time.Time) (types.WalletStats, error)
+
+	// CountLedgerEntries counts the wallet's ledger entries
+	CountLedgerEntries(ctx context.Context, walletID, userID uuid.UUID) (int64, error)
 }
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+)
+
+// ListFacets counts the distinct values of the field across the user's wallets outside
+// the trash, most common first
+func (r *WalletRepositoryImpl) ListFacets(ctx context.Context, userID uuid.UUID, field string) ([]coreTypes.Facet, error) {
+	rows, err := r.db.ListWalletFacets(ctx, db.ListWalletFacetsParams{
+		Field:  field,
+		UserID: userID,
+	})
+	if err != nil {
+		return []coreTypes.Facet{}, errors.HandleRepositoryError(err, "list", "wallet facets")
+	}
+
+	facets := make([]coreTypes.Facet, len(rows))
+	for i, row := range rows {
+		facets[i] = coreTypes.Facet{
+			Value: row.Value,
+			Count: row.Count,
+		}
+	}
+	return facets, nil
+}
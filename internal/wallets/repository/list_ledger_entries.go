@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+)
+
+// ListLedgerEntries retrieves up to limit entries of the wallet before the given time
+// following the (afterOccurredAt, afterSeq) cursor, oldest first. A zero afterSeq
+// includes the entries at afterOccurredAt.
+func (r *WalletRepositoryImpl) ListLedgerEntries(ctx context.Context, walletID, userID uuid.UUID, before, afterOccurredAt time.Time, afterSeq int64, limit int32) ([]types.LedgerEntry, error) {
+	rows, err := r.db.ListWalletLedgerEntries(ctx, db.ListWalletLedgerEntriesParams{
+		WalletID:        walletID,
+		UserID:          userID,
+		Before:          pgtype.Timestamp{Time: before, Valid: true},
+		AfterOccurredAt: pgtype.Timestamp{Time: afterOccurredAt, Valid: true},
+		AfterSeq:        afterSeq,
+		Limit:           limit,
+	})
+	if err != nil {
+		return nil, errors.HandleRepositoryError(err, "list", "wallet ledger entries")
+	}
+
+	entries := make([]types.LedgerEntry, len(rows))
+	for i, row := range rows {
+		entries[i] = types.LedgerEntry{
+			EntryID:     row.EntryID,
+			Seq:         row.Seq,
+			Description: row.Description,
+			OccurredAt:  row.OccurredAt.Time,
+		}
+		if amount := utils.GetFloat64Ptr(row.Amount); amount != nil {
+			entries[i].Amount = *amount
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+)
+
+// ListLowBalanceWallets retrieves the wallets whose balance is below their low balance threshold,
+// the furthest below it first
+func (r *WalletRepositoryImpl) ListLowBalanceWallets(ctx context.Context, userID uuid.UUID) ([]types.Wallet, error) {
+	wallets, err := r.db.ListLowBalanceWallets(ctx, userID)
+	if err != nil {
+		return []types.Wallet{}, errors.HandleRepositoryError(err, "list low balance", "wallet(s)")
+	}
+
+	return toWallets(wallets), nil
+}
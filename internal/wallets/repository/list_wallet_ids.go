@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+)
+
+// ListWalletIDsPaginated is ListWalletsPaginated reading only the IDs and creation times
+func (r *WalletRepositoryImpl) ListWalletIDsPaginated(ctx context.Context, userID uuid.UUID, createdAt time.Time, walletID uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.WalletFilter) ([]coreTypes.KeyedID, error) {
+	rows, err := r.db.ListWalletIDsPaginated(ctx, db.ListWalletIDsPaginatedParams{
+		UserID:       userID,
+		FilterGroup:  filter.GroupID != nil || filter.Ungrouped,
+		GroupID:      utils.UUIDToNullableUUID(filter.GroupID),
+		Tags:         filter.Tags,
+		MatchAllTags: filter.MatchAllTags,
+		Currency:     utils.ToNullableText(filter.Currency),
+		HasProject:   utils.ToNullableBool(filter.HasProject),
+		SortOrder:    string(order),
+		CreatedAt:    utils.ToNullableTimestamp(&createdAt),
+		WalletID:     walletID,
+		Limit:        limit,
+	})
+	if err != nil {
+		return nil, errors.HandleRepositoryError(err, "p-list", "wallets")
+	}
+
+	keys := make([]coreTypes.KeyedID, len(rows))
+	for i, row := range rows {
+		keys[i] = coreTypes.KeyedID{ID: row.WalletID, CreatedAt: row.CreatedAt.Time}
+	}
+	return keys, nil
+}
+
+// ListPinnedWalletIDsPaginated is ListPinnedWalletsPaginated reading only the IDs and pin times
+func (r *WalletRepositoryImpl) ListPinnedWalletIDsPaginated(ctx context.Context, userID uuid.UUID, pinnedAt time.Time, walletID uuid.UUID, limit int32, filter types.WalletFilter) ([]coreTypes.KeyedID, error) {
+	rows, err := r.db.ListPinnedWalletIDsPaginated(ctx, db.ListPinnedWalletIDsPaginatedParams{
+		UserID:       userID,
+		FilterGroup:  filter.GroupID != nil || filter.Ungrouped,
+		GroupID:      utils.UUIDToNullableUUID(filter.GroupID),
+		Tags:         filter.Tags,
+		MatchAllTags: filter.MatchAllTags,
+		Currency:     utils.ToNullableText(filter.Currency),
+		HasProject:   utils.ToNullableBool(filter.HasProject),
+		PinnedAt:     utils.ToNullableTimestamp(&pinnedAt),
+		WalletID:     walletID,
+		Limit:        limit,
+	})
+	if err != nil {
+		return nil, errors.HandleRepositoryError(err, "list pinned", "wallets")
+	}
+
+	keys := make([]coreTypes.KeyedID, len(rows))
+	for i, row := range rows {
+		keys[i] = coreTypes.KeyedID{ID: row.WalletID, PinnedAt: &row.PinnedAt.Time}
+	}
+	return keys, nil
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+)
+
+// ListWalletProjects returns the projects of the user's wallets among walletIDs in a single
+// joined query, the wallets outside any project are left out of the map
+func (r *WalletRepositoryImpl) ListWalletProjects(ctx context.Context, userID uuid.UUID, walletIDs []uuid.UUID) (map[uuid.UUID]types.WalletProject, error) {
+	rows, err := r.db.ListWalletProjects(ctx, db.ListWalletProjectsParams{
+		UserID:    userID,
+		WalletIds: walletIDs,
+	})
+	if err != nil {
+		return nil, errors.HandleRepositoryError(err, "list", "wallet projects")
+	}
+
+	projects := make(map[uuid.UUID]types.WalletProject, len(rows))
+	for _, row := range rows {
+		if !row.ProjectID.Valid {
+			continue
+		}
+		projects[row.WalletID] = types.WalletProject{
+			ProjectID:   row.ProjectID.Bytes,
+			ProjectName: row.ProjectName.String,
+		}
+	}
+	return projects, nil
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
@@ -27,15 +28,57 @@ func (r *WalletRepositoryImpl) ListWallets(ctx context.Context, userID uuid.UUID
 }
 
 // ListWalletsPaginated retrieves a cursor-based paginated list of wallets
-func (r *WalletRepositoryImpl) ListWalletsPaginated(ctx context.Context, userID uuid.UUID, createdAt time.Time, walletID uuid.UUID, limit int32) ([]types.Wallet, error) {
+func (r *WalletRepositoryImpl) ListWalletsPaginated(ctx context.Context, userID uuid.UUID, createdAt time.Time, walletID uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.WalletFilter) ([]types.Wallet, error) {
 	wallets, err := r.db.ListWalletsPaginated(ctx, db.ListWalletsPaginatedParams{
+		UserID:       userID,
+		FilterGroup:  filter.GroupID != nil || filter.Ungrouped,
+		GroupID:      utils.UUIDToNullableUUID(filter.GroupID),
+		Tags:         filter.Tags,
+		MatchAllTags: filter.MatchAllTags,
+		Currency:     utils.ToNullableText(filter.Currency),
+		HasProject:   utils.ToNullableBool(filter.HasProject),
+		SortOrder:    string(order),
+		CreatedAt:    utils.ToNullableTimestamp(&createdAt),
+		WalletID:     walletID,
+		Limit:        limit,
+	})
+	if err != nil {
+		return []types.Wallet{}, errors.HandleRepositoryError(err, "p-list", "wallets")
+	}
+
+	return toWallets(wallets), nil
+}
+
+// ListWalletsPaginatedStream streams a cursor-based paginated list of wallets to fn
+func (r *WalletRepositoryImpl) ListWalletsPaginatedStream(ctx context.Context, userID uuid.UUID, createdAt time.Time, walletID uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.WalletFilter, fn func(types.Wallet) error) error {
+	return streamWallets(func(scan func(db.Wallet) error) error {
+		return r.db.ListWalletsPaginatedStream(ctx, db.ListWalletsPaginatedParams{
+			UserID:       userID,
+			FilterGroup:  filter.GroupID != nil || filter.Ungrouped,
+			GroupID:      utils.UUIDToNullableUUID(filter.GroupID),
+			Tags:         filter.Tags,
+			MatchAllTags: filter.MatchAllTags,
+			Currency:     utils.ToNullableText(filter.Currency),
+			HasProject:   utils.ToNullableBool(filter.HasProject),
+			SortOrder:    string(order),
+			CreatedAt:    utils.ToNullableTimestamp(&createdAt),
+			WalletID:     walletID,
+			Limit:        limit,
+		}, scan)
+	}, fn, "p-list")
+}
+
+// ListDeletedWalletsPaginated retrieves a cursor-based paginated list of trashed wallets
+func (r *WalletRepositoryImpl) ListDeletedWalletsPaginated(ctx context.Context, userID uuid.UUID, deletedAt time.Time, walletID uuid.UUID, limit int32, order coreTypes.SortOrder) ([]types.Wallet, error) {
+	wallets, err := r.db.ListDeletedWalletsPaginated(ctx, db.ListDeletedWalletsPaginatedParams{
 		UserID:    userID,
-		CreatedAt: utils.ToNullableTimestamp(&createdAt),
+		SortOrder: string(order),
+		DeletedAt: utils.ToNullableTimestamp(&deletedAt),
 		WalletID:  walletID,
 		Limit:     limit,
 	})
 	if err != nil {
-		return []types.Wallet{}, errors.HandleRepositoryError(err, "p-list", "wallets")
+		return []types.Wallet{}, errors.HandleRepositoryError(err, "list deleted", "wallets")
 	}
 
 	return toWallets(wallets), nil
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	stdErrors "errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+)
+
+// ErrPinnedWalletLimit is returned when a wallet is pinned by a user who already has
+// types.MaxPinnedWallets pinned
+var ErrPinnedWalletLimit = stdErrors.New("pinned wallet limit reached")
+
+// ListPinnedWalletsPaginated retrieves the pinned wallets after the cursor, most recently pinned first
+func (r *WalletRepositoryImpl) ListPinnedWalletsPaginated(ctx context.Context, userID uuid.UUID, pinnedAt time.Time, walletID uuid.UUID, limit int32, filter types.WalletFilter) ([]types.Wallet, error) {
+	wallets, err := r.db.ListPinnedWalletsPaginated(ctx, db.ListPinnedWalletsPaginatedParams{
+		UserID:       userID,
+		FilterGroup:  filter.GroupID != nil || filter.Ungrouped,
+		GroupID:      utils.UUIDToNullableUUID(filter.GroupID),
+		Tags:         filter.Tags,
+		MatchAllTags: filter.MatchAllTags,
+		Currency:     utils.ToNullableText(filter.Currency),
+		HasProject:   utils.ToNullableBool(filter.HasProject),
+		PinnedAt:     utils.ToNullableTimestamp(&pinnedAt),
+		WalletID:     walletID,
+		Limit:        limit,
+	})
+	if err != nil {
+		return []types.Wallet{}, errors.HandleRepositoryError(err, "list pinned", "wallets")
+	}
+
+	return toWallets(wallets), nil
+}
+
+// ListPinnedWalletsPaginatedStream streams the pinned wallets after the cursor to fn, most recently pinned first
+func (r *WalletRepositoryImpl) ListPinnedWalletsPaginatedStream(ctx context.Context, userID uuid.UUID, pinnedAt time.Time, walletID uuid.UUID, limit int32, filter types.WalletFilter, fn func(types.Wallet) error) error {
+	return streamWallets(func(scan func(db.Wallet) error) error {
+		return r.db.ListPinnedWalletsPaginatedStream(ctx, db.ListPinnedWalletsPaginatedParams{
+			UserID:       userID,
+			FilterGroup:  filter.GroupID != nil || filter.Ungrouped,
+			GroupID:      utils.UUIDToNullableUUID(filter.GroupID),
+			Tags:         filter.Tags,
+			MatchAllTags: filter.MatchAllTags,
+			Currency:     utils.ToNullableText(filter.Currency),
+			HasProject:   utils.ToNullableBool(filter.HasProject),
+			PinnedAt:     utils.ToNullableTimestamp(&pinnedAt),
+			WalletID:     walletID,
+			Limit:        limit,
+		}, scan)
+	}, fn, "list pinned")
+}
+
+// SetWalletPinned pins or unpins a wallet. A pin past types.MaxPinnedWallets returns
+// ErrPinnedWalletLimit, the count and the pin run in a transaction holding the user's
+// advisory lock so concurrent pins can't both take the last slot.
+func (r *WalletRepositoryImpl) SetWalletPinned(ctx context.Context, walletID, userID uuid.UUID, pinned bool) (types.Wallet, error) {
+	params := db.SetWalletPinnedParams{
+		Pinned:   pinned,
+		WalletID: walletID,
+		UserID:   userID,
+	}
+	var wallet db.Wallet
+	var err error
+	if pinned {
+		err = r.db.WithEntityLock(ctx, userID, func(q *db.Queries) error {
+			current, err := q.GetWallet(ctx, db.GetWalletParams{WalletID: walletID, UserID: userID})
+			if err != nil {
+				return err
+			}
+			if !current.PinnedAt.Valid {
+				count, err := q.CountPinnedWallets(ctx, userID)
+				if err != nil {
+					return err
+				}
+				if count >= types.MaxPinnedWallets {
+					return ErrPinnedWalletLimit
+				}
+			}
+			wallet, err = q.SetWalletPinned(ctx, params)
+			return err
+		})
+	} else {
+		wallet, err = r.db.SetWalletPinned(ctx, params)
+	}
+	if stdErrors.Is(err, ErrPinnedWalletLimit) {
+		return types.Wallet{}, err
+	}
+	if err != nil {
+		return types.Wallet{}, errors.HandleRepositoryError(err, "pin", "wallet")
+	}
+
+	return toWallet(wallet), nil
+}
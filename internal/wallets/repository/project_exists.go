@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+)
+
+// ProjectExists reports whether the project belongs to the user and isn't trashed
+func (r *WalletRepositoryImpl) ProjectExists(ctx context.Context, userID, projectID uuid.UUID) (bool, error) {
+	exists, err := r.db.ProjectExists(ctx, db.ProjectExistsParams{
+		ProjectID: projectID,
+		UserID:    userID,
+	})
+	if err != nil {
+		return false, errors.HandleRepositoryError(err, "check", "project(s)")
+	}
+
+	return exists, nil
+}
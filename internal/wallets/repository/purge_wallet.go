@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	stdErrors "errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
+)
+
+// PurgeWallet deletes a wallet for good, in the trash or not, and reports whether it was
+// in the trash. With a version it is only deleted while it is still at that updatedAt.
+func (r *WalletRepositoryImpl) PurgeWallet(ctx context.Context, walletID, userID uuid.UUID, version *time.Time) (bool, error) {
+	deletedAt, err := r.db.PurgeWallet(ctx, db.PurgeWalletParams{
+		WalletID: walletID,
+		UserID:   userID,
+		Version:  utils.ToNullableTimestamp(version),
+	})
+	if stdErrors.Is(err, pgx.ErrNoRows) && version != nil {
+		return false, r.missedDelete(ctx, walletID, userID, version)
+	}
+	if err != nil {
+		return false, errors.HandleRepositoryError(err, "purge", "wallet")
+	}
+	return deletedAt.Valid, nil
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+)
+
+// RestoreWallet moves a trashed wallet back to the user's wallets
+func (r *WalletRepositoryImpl) RestoreWallet(ctx context.Context, walletID, userID uuid.UUID) (types.Wallet, error) {
+	wallet, err := r.db.RestoreWallet(ctx, db.RestoreWalletParams{
+		WalletID: walletID,
+		UserID:   userID,
+	})
+	if err != nil {
+		return types.Wallet{}, errors.HandleRepositoryError(err, "restore", "wallet")
+	}
+
+	return toWallet(wallet), nil
+}
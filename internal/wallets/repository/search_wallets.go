@@ -10,16 +10,24 @@ import (
 	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
 )
 
-// SearchWallets searches for wallets by name
-func (r *WalletRepositoryImpl) SearchWallets(ctx context.Context, userID uuid.UUID, name string, limit int32) ([]types.Wallet, error) {
-	wallets, err := r.db.SearchWallets(ctx, db.SearchWalletsParams{
+// SearchWallets searches for wallets by name, scoring them against the name when one is given
+func (r *WalletRepositoryImpl) SearchWallets(ctx context.Context, userID uuid.UUID, name string, limit, offset int32) ([]types.Wallet, error) {
+	rows, err := r.db.SearchWallets(ctx, db.SearchWalletsParams{
 		UserID: userID,
 		Name:   name,
 		Limit:  limit,
+		Offset: offset,
 	})
 	if err != nil {
 		return []types.Wallet{}, errors.HandleRepositoryError(err, "search", "wallet(s)")
 	}
 
-	return toWallets(wallets), nil
+	wallets := make([]types.Wallet, len(rows))
+	for i, row := range rows {
+		wallets[i] = toWallet(row.Wallet)
+		if name != "" {
+			wallets[i].Score = &row.Score
+		}
+	}
+	return wallets, nil
 }
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+)
+
+// SearchWalletsPicker searches for wallets by name like SearchWallets, reading only the
+// columns of the picker view
+func (r *WalletRepositoryImpl) SearchWalletsPicker(ctx context.Context, userID uuid.UUID, name string, limit, offset int32) ([]types.WalletPickerItem, error) {
+	rows, err := r.db.SearchWalletsPicker(ctx, db.SearchWalletsPickerParams{
+		UserID: userID,
+		Name:   name,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return []types.WalletPickerItem{}, errors.HandleRepositoryError(err, "search", "wallet(s)")
+	}
+
+	items := make([]types.WalletPickerItem, len(rows))
+	for i, row := range rows {
+		items[i] = types.WalletPickerItem{
+			WalletID: row.WalletID,
+			Name:     row.Name,
+			Balance:  utils.GetFloat64Ptr(row.Balance),
+			Currency: row.Currency,
+		}
+	}
+	return items, nil
+}
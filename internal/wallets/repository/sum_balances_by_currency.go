@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+)
+
+// SumBalancesByCurrency sums the balances of the user's wallets by currency in a single
+// aggregate query, with includeDeleted the wallets in the trash are summed too
+func (r *WalletRepositoryImpl) SumBalancesByCurrency(ctx context.Context, userID uuid.UUID, includeDeleted bool) ([]types.CurrencyTotal, error) {
+	rows, err := r.db.SumWalletBalancesByCurrency(ctx, db.SumWalletBalancesByCurrencyParams{
+		UserID:         userID,
+		IncludeDeleted: includeDeleted,
+	})
+	if err != nil {
+		return []types.CurrencyTotal{}, errors.HandleRepositoryError(err, "sum", "wallet balances")
+	}
+
+	totals := make([]types.CurrencyTotal, len(rows))
+	for i, row := range rows {
+		totals[i] = types.CurrencyTotal{
+			Currency:    row.Currency,
+			Total:       numericValue(row.Total),
+			WalletCount: row.WalletCount,
+		}
+	}
+	return totals, nil
+}
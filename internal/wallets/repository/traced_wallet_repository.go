@@ -0,0 +1,258 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/tracing"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedWalletRepository records a span around each call of the wrapped repository
+type tracedWalletRepository struct {
+	next   WalletRepository
+	tracer trace.Tracer
+}
+
+// NewTracedWalletRepository wraps next so each call records a span named after the method, a nil
+// tracer returns next as is
+func NewTracedWalletRepository(next WalletRepository, tracer trace.Tracer) WalletRepository {
+	if tracer == nil {
+		return next
+	}
+	return &tracedWalletRepository{next: next, tracer: tracer}
+}
+
+func (t *tracedWalletRepository) GetWallet(ctx context.Context, walletID, userID uuid.UUID) (types.Wallet, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletRepository.GetWallet")
+	wallet, err := t.next.GetWallet(ctx, walletID, userID)
+	tracing.End(span, err)
+	return wallet, err
+}
+
+func (t *tracedWalletRepository) GetWalletsByIDs(ctx context.Context, userID uuid.UUID, walletIDs []uuid.UUID) ([]types.Wallet, []uuid.UUID, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletRepository.GetWalletsByIDs")
+	wallets, missing, err := t.next.GetWalletsByIDs(ctx, userID, walletIDs)
+	tracing.End(span, err)
+	return wallets, missing, err
+}
+
+func (t *tracedWalletRepository) ListWallets(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]types.Wallet, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletRepository.ListWallets")
+	wallets, err := t.next.ListWallets(ctx, userID, limit, offset)
+	tracing.End(span, err)
+	return wallets, err
+}
+
+func (t *tracedWalletRepository) ListWalletsPaginated(ctx context.Context, userID uuid.UUID, createdAt time.Time, walletID uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.WalletFilter) ([]types.Wallet, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletRepository.ListWalletsPaginated")
+	wallets, err := t.next.ListWalletsPaginated(ctx, userID, createdAt, walletID, limit, order, filter)
+	tracing.End(span, err)
+	return wallets, err
+}
+
+func (t *tracedWalletRepository) ListPinnedWalletsPaginated(ctx context.Context, userID uuid.UUID, pinnedAt time.Time, walletID uuid.UUID, limit int32, filter types.WalletFilter) ([]types.Wallet, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletRepository.ListPinnedWalletsPaginated")
+	wallets, err := t.next.ListPinnedWalletsPaginated(ctx, userID, pinnedAt, walletID, limit, filter)
+	tracing.End(span, err)
+	return wallets, err
+}
+
+func (t *tracedWalletRepository) ListWalletIDsPaginated(ctx context.Context, userID uuid.UUID, createdAt time.Time, walletID uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.WalletFilter) ([]coreTypes.KeyedID, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletRepository.ListWalletIDsPaginated")
+	keys, err := t.next.ListWalletIDsPaginated(ctx, userID, createdAt, walletID, limit, order, filter)
+	tracing.End(span, err)
+	return keys, err
+}
+
+func (t *tracedWalletRepository) ListPinnedWalletIDsPaginated(ctx context.Context, userID uuid.UUID, pinnedAt time.Time, walletID uuid.UUID, limit int32, filter types.WalletFilter) ([]coreTypes.KeyedID, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletRepository.ListPinnedWalletIDsPaginated")
+	keys, err := t.next.ListPinnedWalletIDsPaginated(ctx, userID, pinnedAt, walletID, limit, filter)
+	tracing.End(span, err)
+	return keys, err
+}
+
+func (t *tracedWalletRepository) ListWalletsPaginatedStream(ctx context.Context, userID uuid.UUID, createdAt time.Time, walletID uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.WalletFilter, fn func(types.Wallet) error) error {
+	ctx, span := t.tracer.Start(ctx, "WalletRepository.ListWalletsPaginatedStream")
+	err := t.next.ListWalletsPaginatedStream(ctx, userID, createdAt, walletID, limit, order, filter, fn)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *tracedWalletRepository) ListPinnedWalletsPaginatedStream(ctx context.Context, userID uuid.UUID, pinnedAt time.Time, walletID uuid.UUID, limit int32, filter types.WalletFilter, fn func(types.Wallet) error) error {
+	ctx, span := t.tracer.Start(ctx, "WalletRepository.ListPinnedWalletsPaginatedStream")
+	err := t.next.ListPinnedWalletsPaginatedStream(ctx, userID, pinnedAt, walletID, limit, filter, fn)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *tracedWalletRepository) SetWalletPinned(ctx context.Context, walletID, userID uuid.UUID, pinned bool) (types.Wallet, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletRepository.SetWalletPinned")
+	wallet, err := t.next.SetWalletPinned(ctx, walletID, userID, pinned)
+	tracing.End(span, err)
+	return wallet, err
+}
+
+func (t *tracedWalletRepository) CreateWallet(ctx context.Context, payload types.WalletCreatePayload, userID uuid.UUID) (types.Wallet, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletRepository.CreateWallet")
+	wallet, err := t.next.CreateWallet(ctx, payload, userID)
+	tracing.End(span, err)
+	return wallet, err
+}
+
+func (t *tracedWalletRepository) UpdateWallet(ctx context.Context, payload types.WalletUpdatePayload, userID uuid.UUID) (types.Wallet, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletRepository.UpdateWallet")
+	wallet, err := t.next.UpdateWallet(ctx, payload, userID)
+	tracing.End(span, err)
+	return wallet, err
+}
+
+func (t *tracedWalletRepository) UpdateWalletLocked(ctx context.Context, walletID, userID uuid.UUID, merge func(types.Wallet) (types.WalletUpdatePayload, error)) (types.Wallet, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletRepository.UpdateWalletLocked")
+	wallet, err := t.next.UpdateWalletLocked(ctx, walletID, userID, merge)
+	tracing.End(span, err)
+	return wallet, err
+}
+
+func (t *tracedWalletRepository) DeleteWallet(ctx context.Context, walletID, userID uuid.UUID, version *time.Time) error {
+	ctx, span := t.tracer.Start(ctx, "WalletRepository.DeleteWallet")
+	err := t.next.DeleteWallet(ctx, walletID, userID, version)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *tracedWalletRepository) PurgeWallet(ctx context.Context, walletID, userID uuid.UUID, version *time.Time) (bool, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletRepository.PurgeWallet")
+	trashed, err := t.next.PurgeWallet(ctx, walletID, userID, version)
+	tracing.End(span, err)
+	return trashed, err
+}
+
+func (t *tracedWalletRepository) ListDeletedWalletsPaginated(ctx context.Context, userID uuid.UUID, deletedAt time.Time, walletID uuid.UUID, limit int32, order coreTypes.SortOrder) ([]types.Wallet, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletRepository.ListDeletedWalletsPaginated")
+	wallets, err := t.next.ListDeletedWalletsPaginated(ctx, userID, deletedAt, walletID, limit, order)
+	tracing.End(span, err)
+	return wallets, err
+}
+
+func (t *tracedWalletRepository) RestoreWallet(ctx context.Context, walletID, userID uuid.UUID) (types.Wallet, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletRepository.RestoreWallet")
+	wallet, err := t.next.RestoreWallet(ctx, walletID, userID)
+	tracing.End(span, err)
+	return wallet, err
+}
+
+func (t *tracedWalletRepository) GetProjectWallets(ctx context.Context, projectID uuid.UUID, userID uuid.UUID) ([]types.Wallet, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletRepository.GetProjectWallets")
+	wallets, err := t.next.GetProjectWallets(ctx, projectID, userID)
+	tracing.End(span, err)
+	return wallets, err
+}
+
+func (t *tracedWalletRepository) SearchWallets(ctx context.Context, userID uuid.UUID, name string, limit, offset int32) ([]types.Wallet, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletRepository.SearchWallets")
+	wallets, err := t.next.SearchWallets(ctx, userID, name, limit, offset)
+	tracing.End(span, err)
+	return wallets, err
+}
+
+func (t *tracedWalletRepository) SearchWalletsPicker(ctx context.Context, userID uuid.UUID, name string, limit, offset int32) ([]types.WalletPickerItem, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletRepository.SearchWalletsPicker")
+	items, err := t.next.SearchWalletsPicker(ctx, userID, name, limit, offset)
+	tracing.End(span, err)
+	return items, err
+}
+
+func (t *tracedWalletRepository) ListLowBalanceWallets(ctx context.Context, userID uuid.UUID) ([]types.Wallet, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletRepository.ListLowBalanceWallets")
+	wallets, err := t.next.ListLowBalanceWallets(ctx, userID)
+	tracing.End(span, err)
+	return wallets, err
+}
+
+func (t *tracedWalletRepository) ListWalletProjects(ctx context.Context, userID uuid.UUID, walletIDs []uuid.UUID) (map[uuid.UUID]types.WalletProject, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletRepository.ListWalletProjects")
+	projects, err := t.next.ListWalletProjects(ctx, userID, walletIDs)
+	tracing.End(span, err)
+	return projects, err
+}
+
+func (t *tracedWalletRepository) SumBalancesByCurrency(ctx context.Context, userID uuid.UUID, includeDeleted bool) ([]types.CurrencyTotal, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletRepository.SumBalancesByCurrency")
+	totals, err := t.next.SumBalancesByCurrency(ctx, userID, includeDeleted)
+	tracing.End(span, err)
+	return totals, err
+}
+
+func (t *tracedWalletRepository) ListFacets(ctx context.Context, userID uuid.UUID, field string) ([]coreTypes.Facet, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletRepository.ListFacets")
+	facets, err := t.next.ListFacets(ctx, userID, field)
+	tracing.End(span, err)
+	return facets, err
+}
+
+func (t *tracedWalletRepository) AttachWalletsToProject(ctx context.Context, userID, projectID uuid.UUID, walletIDs []uuid.UUID) (int64, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletRepository.AttachWalletsToProject")
+	count, err := t.next.AttachWalletsToProject(ctx, userID, projectID, walletIDs)
+	tracing.End(span, err)
+	return count, err
+}
+
+func (t *tracedWalletRepository) CountOwnedWallets(ctx context.Context, userID uuid.UUID, walletIDs []uuid.UUID) (int64, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletRepository.CountOwnedWallets")
+	count, err := t.next.CountOwnedWallets(ctx, userID, walletIDs)
+	tracing.End(span, err)
+	return count, err
+}
+
+func (t *tracedWalletRepository) ProjectExists(ctx context.Context, userID, projectID uuid.UUID) (bool, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletRepository.ProjectExists")
+	exists, err := t.next.ProjectExists(ctx, userID, projectID)
+	tracing.End(span, err)
+	return exists, err
+}
+
+func (t *tracedWalletRepository) WalletNameExists(ctx context.Context, userID uuid.UUID, name string) (bool, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletRepository.WalletNameExists")
+	exists, err := t.next.WalletNameExists(ctx, userID, name)
+	tracing.End(span, err)
+	return exists, err
+}
+
+func (t *tracedWalletRepository) WalletGroupExists(ctx context.Context, userID, groupID uuid.UUID) (bool, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletRepository.WalletGroupExists")
+	exists, err := t.next.WalletGroupExists(ctx, userID, groupID)
+	tracing.End(span, err)
+	return exists, err
+}
+
+func (t *tracedWalletRepository) GetLedgerBalance(ctx context.Context, walletID, userID uuid.UUID, before time.Time) (float64, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletRepository.GetLedgerBalance")
+	balance, err := t.next.GetLedgerBalance(ctx, walletID, userID, before)
+	tracing.End(span, err)
+	return balance, err
+}
+
+func (t *tracedWalletRepository) ListLedgerEntries(ctx context.Context, walletID, userID uuid.UUID, before, afterOccurredAt time.Time, afterSeq int64, limit int32) ([]types.LedgerEntry, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletRepository.ListLedgerEntries")
+	ledgerEntries, err := t.next.ListLedgerEntries(ctx, walletID, userID, before, afterOccurredAt, afterSeq, limit)
+	tracing.End(span, err)
+	return ledgerEntries, err
+}
+
+func (t *tracedWalletRepository) GetLedgerStats(ctx context.Context, walletID, userID uuid.UUID, today time.Time) (types.WalletStats, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletRepository.GetLedgerStats")
+	stats, err := t.next.GetLedgerStats(ctx, walletID, userID, today)
+	tracing.End(span, err)
+	return stats, err
+}
+
+func (t *tracedWalletRepository) CountLedgerEntries(ctx context.Context, walletID, userID uuid.UUID) (int64, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletRepository.CountLedgerEntries")
+	count, err := t.next.CountLedgerEntries(ctx, walletID, userID)
+	tracing.End(span, err)
+	return count, err
+}
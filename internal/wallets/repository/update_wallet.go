@@ -7,7 +7,9 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
 )
 
 // UpdateWallet updates an existing wallet
@@ -16,7 +18,7 @@ func (r *WalletRepositoryImpl) UpdateWallet(ctx context.Context, payload types.W
 		return types.Wallet{}, fmt.Errorf("invalid wallet id or user id")
 	}
 
-	params := updateWalletParamsFromPayload(payload, userID)
+	params := updateWalletParamsFromPayload(payload, userID, requestcontext.GetActorIDFromContext(ctx, userID))
 	wallet, err := r.db.UpdateWallet(ctx, params)
 	if err != nil {
 		return types.Wallet{}, errors.HandleRepositoryError(err, "update", "wallet")
@@ -24,3 +26,32 @@ func (r *WalletRepositoryImpl) UpdateWallet(ctx context.Context, payload types.W
 
 	return toWallet(wallet), nil
 }
+
+// UpdateWalletLocked updates the wallet with the payload merge makes of its current
+// values. The read and the write run in a transaction holding the wallet's advisory
+// lock, so concurrent updates apply one after the other instead of interleaving.
+func (r *WalletRepositoryImpl) UpdateWalletLocked(ctx context.Context, walletID, userID uuid.UUID, merge func(types.Wallet) (types.WalletUpdatePayload, error)) (types.Wallet, error) {
+	var wallet types.Wallet
+	// the errors of the read, merge and write are returned as they are, the others are
+	// those of the transaction and the lock
+	var updateErr error
+	err := r.db.WithEntityLock(ctx, walletID, func(q *db.Queries) error {
+		locked := &WalletRepositoryImpl{db: q}
+		existing, err := locked.GetWallet(ctx, walletID, userID)
+		if err == nil {
+			var payload types.WalletUpdatePayload
+			if payload, err = merge(existing); err == nil {
+				wallet, err = locked.UpdateWallet(ctx, payload, userID)
+			}
+		}
+		updateErr = err
+		return err
+	})
+	if updateErr != nil {
+		return types.Wallet{}, updateErr
+	}
+	if err != nil {
+		return types.Wallet{}, errors.HandleRepositoryError(err, "update", "wallet")
+	}
+	return wallet, nil
+}
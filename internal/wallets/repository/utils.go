@@ -3,6 +3,8 @@ package repository
 import (
 	"github.com/google/uuid"
 
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
@@ -11,15 +13,22 @@ import (
 // toWallet converts a db.Wallet to domain types.Wallet
 func toWallet(w db.Wallet) types.Wallet {
 	return types.Wallet{
-		WalletID:  w.WalletID,
-		UserID:    w.UserID,
-		ProjectID: utils.GetUUIDPtr(w.ProjectID),
-		Name:      w.Name,
-		Balance:   utils.GetFloat64Ptr(w.Balance),
-		Currency:  w.Currency,
-		Tags:      w.Tags,
-		CreatedAt: w.CreatedAt.Time,
-		UpdatedAt: w.UpdatedAt.Time,
+		WalletID:            w.WalletID,
+		UserID:              w.UserID,
+		ProjectID:           utils.GetUUIDPtr(w.ProjectID),
+		GroupID:             utils.GetUUIDPtr(w.GroupID),
+		Name:                w.Name,
+		Balance:             utils.GetFloat64Ptr(w.Balance),
+		Currency:            w.Currency,
+		Tags:                w.Tags,
+		LowBalanceThreshold: utils.GetFloat64Ptr(w.LowBalanceThreshold),
+		CreatedAt:           coreTypes.NewTimestamp(w.CreatedAt.Time),
+		UpdatedAt:           coreTypes.NewTimestamp(w.UpdatedAt.Time),
+		DeletedAt:           utils.GetTimestampPtr(w.DeletedAt),
+		Pinned:              w.PinnedAt.Valid,
+		PinnedAt:            utils.GetTimestampPtr(w.PinnedAt),
+		CreatedBy:           utils.GetUUIDPtr(w.CreatedBy),
+		UpdatedBy:           utils.GetUUIDPtr(w.UpdatedBy),
 	}
 }
 
@@ -32,26 +41,49 @@ func toWallets(wallets []db.Wallet) []types.Wallet {
 	return result
 }
 
+// streamWallets hands each wallet stream scans to fn, telling the errors of fn, returned
+// as they are, from the query's
+func streamWallets(stream func(scan func(db.Wallet) error) error, fn func(types.Wallet) error, operation string) error {
+	var fnErr error
+	err := stream(func(w db.Wallet) error {
+		fnErr = fn(toWallet(w))
+		return fnErr
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	if err != nil {
+		return errors.HandleRepositoryError(err, operation, "wallets")
+	}
+	return nil
+}
+
 // createWalletParamsFromPayload converts WalletCreatePayload to db.CreateWalletParams
-func createWalletParamsFromPayload(payload types.WalletCreatePayload, userID uuid.UUID) db.CreateWalletParams {
+func createWalletParamsFromPayload(payload types.WalletCreatePayload, userID, actorID uuid.UUID) db.CreateWalletParams {
 	return db.CreateWalletParams{
-		UserID:    userID,
-		ProjectID: utils.UUIDToNullableUUID(payload.ProjectID),
-		Name:      payload.Name,
-		Balance:   utils.ToNullableNumeric(payload.Balance),
-		Currency:  payload.Currency,
-		Tags:      payload.Tags,
+		UserID:              userID,
+		ProjectID:           utils.UUIDToNullableUUID(payload.ProjectID),
+		GroupID:             utils.UUIDToNullableUUID(payload.GroupID),
+		Name:                payload.Name,
+		Balance:             utils.ToNullableNumeric(payload.Balance),
+		Currency:            payload.Currency,
+		Tags:                payload.Tags,
+		LowBalanceThreshold: utils.ToNullableNumeric(payload.LowBalanceThreshold),
+		ActorID:             actorID,
 	}
 }
 
 // updateWalletParamsFromPayload converts WalletUpdatePayload to db.UpdateWalletParams
-func updateWalletParamsFromPayload(payload types.WalletUpdatePayload, userID uuid.UUID) db.UpdateWalletParams {
+func updateWalletParamsFromPayload(payload types.WalletUpdatePayload, userID, actorID uuid.UUID) db.UpdateWalletParams {
 	return db.UpdateWalletParams{
-		WalletID: payload.WalletID,
-		UserID:   userID,
-		Name:     utils.ToNullableText(&payload.Name),
-		Balance:  utils.ToNullableNumeric(payload.Balance),
-		Currency: utils.ToNullableText(&payload.Currency),
-		Tags:     payload.Tags,
+		WalletID:            payload.WalletID,
+		UserID:              userID,
+		GroupID:             utils.UUIDToNullableUUID(payload.GroupID),
+		Name:                utils.ToNullableText(&payload.Name),
+		Balance:             utils.ToNullableNumeric(payload.Balance),
+		Currency:            utils.ToNullableText(&payload.Currency),
+		Tags:                payload.Tags,
+		LowBalanceThreshold: utils.ToNullableNumeric(payload.LowBalanceThreshold),
+		ActorID:             actorID,
 	}
 }
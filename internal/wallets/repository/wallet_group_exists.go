@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+)
+
+// WalletGroupExists reports whether the wallet group belongs to the user
+func (r *WalletRepositoryImpl) WalletGroupExists(ctx context.Context, userID, groupID uuid.UUID) (bool, error) {
+	exists, err := r.db.WalletGroupExists(ctx, db.WalletGroupExistsParams{
+		GroupID: groupID,
+		UserID:  userID,
+	})
+	if err != nil {
+		return false, errors.HandleRepositoryError(err, "check", "wallet group(s)")
+	}
+
+	return exists, nil
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+)
+
+// WalletNameExists reports whether the user has a wallet outside the trash with the name,
+// compared case-insensitively
+func (r *WalletRepositoryImpl) WalletNameExists(ctx context.Context, userID uuid.UUID, name string) (bool, error) {
+	exists, err := r.db.WalletNameExists(ctx, db.WalletNameExistsParams{
+		UserID: userID,
+		Name:   name,
+	})
+	if err != nil {
+		return false, errors.HandleRepositoryError(err, "check", "wallet(s)")
+	}
+
+	return exists, nil
+}
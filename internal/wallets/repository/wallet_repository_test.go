@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
+	coreRepository "github.com/Abdelrahman-habib/expense-tracker/internal/core/repository"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/utils"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/repository"
@@ -186,7 +189,7 @@ func (s *WalletRepositoryTestSuite) TestCreateWallet() {
 				Balance:   utils.Float64Ptr(1000.50),
 				Currency:  "EUR",
 				ProjectID: &projectID,
-				Tags:      []uuid.UUID{uuid.New(), uuid.New()},
+				Tags:      s.createTestTags(2),
 			},
 			wantErr: false,
 		},
@@ -277,13 +280,78 @@ func (s *WalletRepositoryTestSuite) TestGetWallet() {
 	}
 }
 
+func (s *WalletRepositoryTestSuite) TestGetWalletsByIDs() {
+	otherUser := uuid.New()
+	_, err := s.pool.Exec(s.ctx, `
+		INSERT INTO users (user_id, clerk_ex_user_id, name, email)
+		VALUES ($1, $2, 'wrt_Other User', $3)
+	`, otherUser, otherUser.String(), "wrt_"+otherUser.String()+"@example.com")
+	s.Require().NoError(err)
+
+	create := func(name string, userID uuid.UUID) types.Wallet {
+		wallet, err := s.repo.CreateWallet(s.ctx, types.WalletCreatePayload{Name: name, Currency: "USD"}, userID)
+		s.Require().NoError(err)
+		return wallet
+	}
+	first, second, foreign := create("First", s.testUser), create("Second", s.testUser), create("Foreign", otherUser)
+
+	wallets, missing, err := s.repo.GetWalletsByIDs(s.ctx, s.testUser, []uuid.UUID{second.WalletID, foreign.WalletID, first.WalletID})
+	s.Require().NoError(err)
+	s.Require().Len(wallets, 2)
+	s.Equal(second.WalletID, wallets[0].WalletID)
+	s.Equal(first.WalletID, wallets[1].WalletID)
+	s.Equal([]uuid.UUID{foreign.WalletID}, missing)
+}
+
+func (s *WalletRepositoryTestSuite) TestWalletNameExists() {
+	wallet, err := s.repo.CreateWallet(s.ctx, types.WalletCreatePayload{Name: "Travel Fund", Currency: "USD"}, s.testUser)
+	s.Require().NoError(err)
+
+	exists, err := s.repo.WalletNameExists(s.ctx, s.testUser, "travel FUND")
+	s.Require().NoError(err)
+	s.True(exists, "names are compared case-insensitively")
+
+	exists, err = s.repo.WalletNameExists(s.ctx, uuid.New(), "Travel Fund")
+	s.Require().NoError(err)
+	s.False(exists, "another user's wallets don't count")
+
+	s.Require().NoError(s.repo.DeleteWallet(s.ctx, wallet.WalletID, s.testUser, nil))
+	exists, err = s.repo.WalletNameExists(s.ctx, s.testUser, "Travel Fund")
+	s.Require().NoError(err)
+	s.False(exists, "trashed wallets don't count")
+}
+
+func (s *WalletRepositoryTestSuite) TestDeleteWallet_Version() {
+	created, err := s.repo.CreateWallet(s.ctx, types.WalletCreatePayload{Name: "Versioned Wallet", Currency: "USD"}, s.testUser)
+	s.Require().NoError(err)
+	read, err := s.repo.GetWallet(s.ctx, created.WalletID, s.testUser)
+	s.Require().NoError(err)
+
+	// an update committed between the read and the delete fails it
+	payload := read.ToUpdatePayload()
+	payload.Name = "Renamed Wallet"
+	updated, err := s.repo.UpdateWallet(s.ctx, payload, s.testUser)
+	s.Require().NoError(err)
+
+	err = s.repo.DeleteWallet(s.ctx, created.WalletID, s.testUser, &read.UpdatedAt.Time)
+	s.ErrorIs(err, coreRepository.ErrPreconditionFailed)
+	_, err = s.repo.PurgeWallet(s.ctx, created.WalletID, s.testUser, &read.UpdatedAt.Time)
+	s.ErrorIs(err, coreRepository.ErrPreconditionFailed)
+	_, err = s.repo.GetWallet(s.ctx, created.WalletID, s.testUser)
+	s.Require().NoError(err, "the wallet is still live")
+
+	s.Require().NoError(s.repo.DeleteWallet(s.ctx, created.WalletID, s.testUser, &updated.UpdatedAt.Time))
+	err = s.repo.DeleteWallet(s.ctx, created.WalletID, s.testUser, &updated.UpdatedAt.Time)
+	s.ErrorIs(err, coreRepository.ErrNotFound, "a trashed wallet is missing whatever the version")
+}
+
 func (s *WalletRepositoryTestSuite) TestUpdateWallet() {
 	// Create a test wallet first
 	createPayload := types.WalletCreatePayload{
 		Name:     "Test Wallet",
 		Currency: "USD",
 		Balance:  utils.Float64Ptr(100.00),
-		Tags:     []uuid.UUID{uuid.New(), uuid.New()},
+		Tags:     s.createTestTags(2),
 	}
 	created, err := s.repo.CreateWallet(s.ctx, createPayload, s.testUser)
 	require.NoError(s.T(), err)
@@ -346,6 +414,26 @@ func (s *WalletRepositoryTestSuite) TestUpdateWallet() {
 	}
 }
 
+func (s *WalletRepositoryTestSuite) TestUpdateWallet_AdvancesUpdatedAt() {
+	created, err := s.repo.CreateWallet(s.ctx, types.WalletCreatePayload{Name: "Test Wallet", Currency: "USD"}, s.testUser)
+	s.Require().NoError(err)
+
+	// updates writing the same values right after each other still move the version forward
+	payload := types.WalletUpdatePayload{WalletID: created.WalletID, Name: created.Name, Currency: created.Currency}
+	first, err := s.repo.UpdateWallet(s.ctx, payload, s.testUser)
+	s.Require().NoError(err)
+	second, err := s.repo.UpdateWallet(s.ctx, payload, s.testUser)
+	s.Require().NoError(err)
+
+	s.True(first.UpdatedAt.After(created.UpdatedAt.Time))
+	s.True(second.UpdatedAt.After(first.UpdatedAt.Time))
+
+	// pinning isn't an edit
+	pinned, err := s.repo.SetWalletPinned(s.ctx, created.WalletID, s.testUser, true)
+	s.Require().NoError(err)
+	s.Equal(second.UpdatedAt, pinned.UpdatedAt)
+}
+
 func (s *WalletRepositoryTestSuite) TestListWallets() {
 	// Create test wallets
 	wallets := []types.WalletCreatePayload{
@@ -448,7 +536,7 @@ func (s *WalletRepositoryTestSuite) TestListWalletsPaginated() {
 		},
 		{
 			name:      "get second page",
-			cursor:    createdWallets[2].CreatedAt,
+			cursor:    createdWallets[2].CreatedAt.Time,
 			cursorID:  createdWallets[2].WalletID,
 			limit:     2,
 			wantLen:   2,
@@ -457,7 +545,7 @@ func (s *WalletRepositoryTestSuite) TestListWalletsPaginated() {
 		},
 		{
 			name:      "get empty page",
-			cursor:    createdWallets[0].CreatedAt,
+			cursor:    createdWallets[0].CreatedAt.Time,
 			cursorID:  createdWallets[0].WalletID,
 			limit:     2,
 			wantLen:   0,
@@ -468,7 +556,7 @@ func (s *WalletRepositoryTestSuite) TestListWalletsPaginated() {
 
 	for _, tt := range tests {
 		s.Run(tt.name, func() {
-			wallets, err := s.repo.ListWalletsPaginated(s.ctx, s.testUser, tt.cursor, tt.cursorID, tt.limit)
+			wallets, err := s.repo.ListWalletsPaginated(s.ctx, s.testUser, tt.cursor, tt.cursorID, tt.limit, coreTypes.SortOrderDesc, types.WalletFilter{})
 			if tt.wantErr {
 				s.Error(err)
 				return
@@ -488,8 +576,8 @@ func (s *WalletRepositoryTestSuite) TestListWalletsPaginated() {
 			// Verify ordering for non-empty results
 			if len(wallets) > 1 {
 				for i := 1; i < len(wallets); i++ {
-					isCorrectOrder := wallets[i-1].CreatedAt.After(wallets[i].CreatedAt) ||
-						(wallets[i-1].CreatedAt.Equal(wallets[i].CreatedAt) &&
+					isCorrectOrder := wallets[i-1].CreatedAt.After(wallets[i].CreatedAt.Time) ||
+						(wallets[i-1].CreatedAt.Equal(wallets[i].CreatedAt.Time) &&
 							wallets[i-1].WalletID.String() > wallets[i].WalletID.String())
 					s.True(isCorrectOrder, "Wallets should be ordered by created_at DESC and then by wallet_id DESC")
 				}
@@ -498,6 +586,177 @@ func (s *WalletRepositoryTestSuite) TestListWalletsPaginated() {
 	}
 }
 
+func (s *WalletRepositoryTestSuite) TestListWalletsPaginated_CombinedFilter() {
+	tags := s.createTestTags(3)
+	projectID := s.createTestProject("Filtered project")
+	inProject := &projectID
+
+	// Created from oldest to newest
+	wallets := []types.WalletCreatePayload{
+		{Name: "All tags", Currency: "USD", ProjectID: inProject, Tags: []uuid.UUID{tags[0], tags[1]}},
+		{Name: "One tag", Currency: "USD", ProjectID: inProject, Tags: []uuid.UUID{tags[0]}},
+		{Name: "Other currency", Currency: "EUR", ProjectID: inProject, Tags: []uuid.UUID{tags[0], tags[1]}},
+		{Name: "No project", Currency: "USD", Tags: []uuid.UUID{tags[0], tags[1]}},
+		{Name: "More tags", Currency: "USD", ProjectID: inProject, Tags: tags},
+		{Name: "Pinned", Currency: "USD", ProjectID: inProject, Tags: []uuid.UUID{tags[1], tags[0]}},
+		{Name: "Other tag", Currency: "USD", ProjectID: inProject, Tags: []uuid.UUID{tags[1]}},
+		{Name: "Untagged", Currency: "USD", ProjectID: inProject},
+	}
+	created := make(map[string]types.Wallet, len(wallets))
+	for _, w := range wallets {
+		time.Sleep(10 * time.Millisecond) // distinct creation times
+		wallet, err := s.repo.CreateWallet(s.ctx, w, s.testUser)
+		s.Require().NoError(err)
+		created[w.Name] = wallet
+	}
+	_, err := s.repo.SetWalletPinned(s.ctx, created["Pinned"].WalletID, s.testUser, true)
+	s.Require().NoError(err)
+
+	usd, withProject, withoutProject := "USD", true, false
+	names := func(wallets []types.Wallet) []string {
+		result := make([]string, len(wallets))
+		for i, w := range wallets {
+			result[i] = w.Name
+		}
+		return result
+	}
+	// page lists the unpinned wallets matching filter a page of limit at a time, in both orders
+	page := func(filter types.WalletFilter, order coreTypes.SortOrder, limit int32) []string {
+		var listed []string
+		cursor, cursorID := coreTypes.StartCursor(order)
+		for {
+			wallets, err := s.repo.ListWalletsPaginated(s.ctx, s.testUser, cursor, cursorID, limit, order, filter)
+			s.Require().NoError(err)
+			listed = append(listed, names(wallets)...)
+			if len(wallets) < int(limit) {
+				return listed
+			}
+			last := wallets[len(wallets)-1]
+			cursor, cursorID = last.CreatedAt.Time, last.WalletID
+		}
+	}
+
+	allTags := types.WalletFilter{Tags: []uuid.UUID{tags[0], tags[1]}, MatchAllTags: true, Currency: &usd, HasProject: &withProject}
+	s.Equal([]string{"More tags", "All tags"}, page(allTags, coreTypes.SortOrderDesc, 1))
+	s.Equal([]string{"All tags", "More tags"}, page(allTags, coreTypes.SortOrderAsc, 1))
+
+	anyTag := types.WalletFilter{Tags: []uuid.UUID{tags[0], tags[1]}, Currency: &usd, HasProject: &withProject}
+	s.Equal([]string{"Other tag", "More tags", "One tag", "All tags"}, page(anyTag, coreTypes.SortOrderDesc, 2))
+	s.Equal([]string{"All tags", "One tag", "More tags", "Other tag"}, page(anyTag, coreTypes.SortOrderAsc, 3))
+
+	outside := types.WalletFilter{Tags: []uuid.UUID{tags[0], tags[1]}, MatchAllTags: true, HasProject: &withoutProject}
+	s.Equal([]string{"No project"}, page(outside, coreTypes.SortOrderDesc, 10))
+
+	s.Equal([]string{"Untagged", "Other tag", "More tags", "No project", "One tag", "All tags"}, page(types.WalletFilter{Currency: &usd}, coreTypes.SortOrderDesc, 4))
+
+	// the pinned wallets are narrowed by the same filter
+	start, startID := coreTypes.StartPinnedCursor()
+	pinned, err := s.repo.ListPinnedWalletsPaginated(s.ctx, s.testUser, start, startID, 10, allTags)
+	s.Require().NoError(err)
+	s.Equal([]string{"Pinned"}, names(pinned))
+
+	eur := "EUR"
+	pinned, err = s.repo.ListPinnedWalletsPaginated(s.ctx, s.testUser, start, startID, 10, types.WalletFilter{Tags: allTags.Tags, Currency: &eur})
+	s.Require().NoError(err)
+	s.Empty(pinned)
+}
+
+func (s *WalletRepositoryTestSuite) TestPinnedWallets() {
+	var created []types.Wallet
+	for _, name := range []string{"Wallet 1", "Wallet 2", "Wallet 3"} {
+		wallet, err := s.repo.CreateWallet(s.ctx, types.WalletCreatePayload{Name: name, Currency: "USD", Balance: utils.Float64Ptr(100.00)}, s.testUser)
+		s.Require().NoError(err)
+		created = append(created, wallet)
+	}
+
+	first, err := s.repo.SetWalletPinned(s.ctx, created[0].WalletID, s.testUser, true)
+	s.Require().NoError(err)
+	s.True(first.Pinned)
+	s.Require().NotNil(first.PinnedAt)
+
+	time.Sleep(10 * time.Millisecond) // distinct pin times
+	_, err = s.repo.SetWalletPinned(s.ctx, created[2].WalletID, s.testUser, true)
+	s.Require().NoError(err)
+
+	// pinning again keeps the pin time
+	again, err := s.repo.SetWalletPinned(s.ctx, created[0].WalletID, s.testUser, true)
+	s.Require().NoError(err)
+	s.Equal(first.PinnedAt, again.PinnedAt)
+
+	count, err := s.queries.CountPinnedWallets(s.ctx, s.testUser)
+	s.Require().NoError(err)
+	s.Equal(int64(2), count)
+
+	start, startID := coreTypes.StartPinnedCursor()
+	pinned, err := s.repo.ListPinnedWalletsPaginated(s.ctx, s.testUser, start, startID, 10, types.WalletFilter{})
+	s.Require().NoError(err)
+	s.Require().Len(pinned, 2)
+	s.Equal("Wallet 3", pinned[0].Name, "most recently pinned first")
+	s.Equal("Wallet 1", pinned[1].Name)
+
+	unpinnedStart, unpinnedStartID := coreTypes.StartCursor(coreTypes.SortOrderDesc)
+	unpinned, err := s.repo.ListWalletsPaginated(s.ctx, s.testUser, unpinnedStart, unpinnedStartID, 10, coreTypes.SortOrderDesc, types.WalletFilter{})
+	s.Require().NoError(err)
+	s.Require().Len(unpinned, 1)
+	s.Equal("Wallet 2", unpinned[0].Name)
+
+	// unpinning and trashing both drop the pin
+	unpinnedWallet, err := s.repo.SetWalletPinned(s.ctx, created[2].WalletID, s.testUser, false)
+	s.Require().NoError(err)
+	s.False(unpinnedWallet.Pinned)
+	s.Nil(unpinnedWallet.PinnedAt)
+	s.Require().NoError(s.repo.DeleteWallet(s.ctx, created[0].WalletID, s.testUser, nil))
+
+	count, err = s.queries.CountPinnedWallets(s.ctx, s.testUser)
+	s.Require().NoError(err)
+	s.Zero(count)
+}
+
+func (s *WalletRepositoryTestSuite) TestPinnedWallets_Limit() {
+	var created []types.Wallet
+	for i := 0; i < types.MaxPinnedWallets+15; i++ {
+		wallet, err := s.repo.CreateWallet(s.ctx, types.WalletCreatePayload{Name: fmt.Sprintf("Pinnable %d", i), Currency: "USD"}, s.testUser)
+		s.Require().NoError(err)
+		created = append(created, wallet)
+	}
+	for _, wallet := range created[:types.MaxPinnedWallets-5] {
+		_, err := s.repo.SetWalletPinned(s.ctx, wallet.WalletID, s.testUser, true)
+		s.Require().NoError(err)
+	}
+
+	// concurrent pins race for the last five slots
+	rest := created[types.MaxPinnedWallets-5:]
+	var wg sync.WaitGroup
+	errs := make([]error, len(rest))
+	for i, wallet := range rest {
+		wg.Add(1)
+		go func(i int, walletID uuid.UUID) {
+			defer wg.Done()
+			_, errs[i] = s.repo.SetWalletPinned(s.ctx, walletID, s.testUser, true)
+		}(i, wallet.WalletID)
+	}
+	wg.Wait()
+
+	pinned := 0
+	for _, err := range errs {
+		if err == nil {
+			pinned++
+			continue
+		}
+		s.ErrorIs(err, repository.ErrPinnedWalletLimit)
+	}
+	s.Equal(5, pinned)
+
+	count, err := s.queries.CountPinnedWallets(s.ctx, s.testUser)
+	s.Require().NoError(err)
+	s.Equal(int64(types.MaxPinnedWallets), count)
+
+	// a pinned wallet pinned again at the limit keeps its pin
+	again, err := s.repo.SetWalletPinned(s.ctx, created[0].WalletID, s.testUser, true)
+	s.Require().NoError(err)
+	s.True(again.Pinned)
+}
+
 func (s *WalletRepositoryTestSuite) TestSearchWallets() {
 	// Create test wallets with various names
 	wallets := []types.WalletCreatePayload{
@@ -566,7 +825,7 @@ func (s *WalletRepositoryTestSuite) TestSearchWallets() {
 
 	for _, tt := range tests {
 		s.Run(tt.name, func() {
-			wallets, err := s.repo.SearchWallets(s.ctx, s.testUser, tt.query, tt.limit)
+			wallets, err := s.repo.SearchWallets(s.ctx, s.testUser, tt.query, tt.limit, 0)
 			if tt.wantErr {
 				s.Error(err)
 				return
@@ -582,10 +841,101 @@ func (s *WalletRepositoryTestSuite) TestSearchWallets() {
 				}
 				s.Equal(tt.wantNames, actualNames)
 			}
+			for i, w := range wallets {
+				s.Require().NotNil(w.Score)
+				if i > 0 {
+					s.LessOrEqual(*w.Score, *wallets[i-1].Score)
+				}
+			}
 		})
 	}
 }
 
+func (s *WalletRepositoryTestSuite) TestDeleteAndPurgeWallet() {
+	balance := 100.0
+	projectID := s.createTestProject("Purge Project")
+
+	ledgerEntries := func(walletID uuid.UUID) int {
+		var count int
+		s.Require().NoError(s.pool.QueryRow(s.ctx,
+			"SELECT count(*) FROM wallet_ledger_entries WHERE wallet_id = $1", walletID).Scan(&count))
+		return count
+	}
+
+	s.Run("trashing keeps the project and the ledger entries", func() {
+		wallet, err := s.repo.CreateWallet(s.ctx, types.WalletCreatePayload{
+			Name: "Trashed Wallet", Currency: "USD", Balance: &balance, ProjectID: &projectID,
+		}, s.testUser)
+		s.Require().NoError(err)
+		s.Require().Equal(1, ledgerEntries(wallet.WalletID))
+
+		s.Require().NoError(s.repo.DeleteWallet(s.ctx, wallet.WalletID, s.testUser, nil))
+		_, err = s.repo.GetWallet(s.ctx, wallet.WalletID, s.testUser)
+		s.Error(err)
+		s.Equal(1, ledgerEntries(wallet.WalletID))
+
+		restored, err := s.repo.RestoreWallet(s.ctx, wallet.WalletID, s.testUser)
+		s.Require().NoError(err)
+		s.Equal(&projectID, restored.ProjectID)
+		s.Equal(&balance, restored.Balance)
+	})
+
+	s.Run("purging an active wallet deletes its ledger entries", func() {
+		wallet, err := s.repo.CreateWallet(s.ctx, types.WalletCreatePayload{
+			Name: "Purged Wallet", Currency: "USD", Balance: &balance,
+		}, s.testUser)
+		s.Require().NoError(err)
+
+		trashed, err := s.repo.PurgeWallet(s.ctx, wallet.WalletID, s.testUser, nil)
+		s.Require().NoError(err)
+		s.False(trashed)
+		s.Equal(0, ledgerEntries(wallet.WalletID))
+
+		_, err = s.repo.RestoreWallet(s.ctx, wallet.WalletID, s.testUser)
+		s.Error(err)
+	})
+
+	s.Run("purging a trashed wallet", func() {
+		wallet, err := s.repo.CreateWallet(s.ctx, types.WalletCreatePayload{Name: "Trash", Currency: "USD"}, s.testUser)
+		s.Require().NoError(err)
+		s.Require().NoError(s.repo.DeleteWallet(s.ctx, wallet.WalletID, s.testUser, nil))
+
+		trashed, err := s.repo.PurgeWallet(s.ctx, wallet.WalletID, s.testUser, nil)
+		s.Require().NoError(err)
+		s.True(trashed)
+	})
+
+	s.Run("purging someone else's wallet", func() {
+		wallet, err := s.repo.CreateWallet(s.ctx, types.WalletCreatePayload{Name: "Kept", Currency: "USD"}, s.testUser)
+		s.Require().NoError(err)
+
+		_, err = s.repo.PurgeWallet(s.ctx, wallet.WalletID, uuid.New(), nil)
+		s.Error(err)
+		_, err = s.repo.GetWallet(s.ctx, wallet.WalletID, s.testUser)
+		s.NoError(err)
+	})
+}
+
+func (s *WalletRepositoryTestSuite) TestListWalletProjects() {
+	projectID := s.createTestProject("Test Project for ListWalletProjects")
+
+	inProject, err := s.repo.CreateWallet(s.ctx, types.WalletCreatePayload{Name: "Project Wallet", Currency: "USD", ProjectID: &projectID}, s.testUser)
+	s.Require().NoError(err)
+	outside, err := s.repo.CreateWallet(s.ctx, types.WalletCreatePayload{Name: "Personal Wallet", Currency: "USD"}, s.testUser)
+	s.Require().NoError(err)
+
+	projects, err := s.repo.ListWalletProjects(s.ctx, s.testUser, []uuid.UUID{inProject.WalletID, outside.WalletID})
+	s.Require().NoError(err)
+	s.Equal(map[uuid.UUID]types.WalletProject{
+		inProject.WalletID: {ProjectID: projectID, ProjectName: "Test Project for ListWalletProjects"},
+	}, projects)
+
+	// another user's wallets are left out
+	projects, err = s.repo.ListWalletProjects(s.ctx, uuid.New(), []uuid.UUID{inProject.WalletID})
+	s.Require().NoError(err)
+	s.Empty(projects)
+}
+
 func (s *WalletRepositoryTestSuite) TestGetProjectWallets() {
 	// Create test project first
 	projectID := s.createTestProject("Test Project for GetProjectWallets")
@@ -656,6 +1006,51 @@ func (s *WalletRepositoryTestSuite) TestGetProjectWallets() {
 *              Helper Functions                  *
 ************************************************/
 
+func (s *WalletRepositoryTestSuite) TestListLowBalanceWallets() {
+	amount := func(v float64) *float64 { return &v }
+	wallets := []types.WalletCreatePayload{
+		{Name: "Nearly Empty", Currency: "USD", Balance: amount(5), LowBalanceThreshold: amount(50)},
+		{Name: "Just Below", Currency: "USD", Balance: amount(19.99), LowBalanceThreshold: amount(20)},
+		{Name: "At Threshold", Currency: "USD", Balance: amount(20), LowBalanceThreshold: amount(20)},
+		{Name: "No Balance", Currency: "USD", LowBalanceThreshold: amount(10)},
+		{Name: "No Threshold", Currency: "USD", Balance: amount(0)},
+	}
+	ids := make(map[string]uuid.UUID)
+	for _, w := range wallets {
+		created, err := s.repo.CreateWallet(s.ctx, w, s.testUser)
+		s.Require().NoError(err)
+		ids[created.Name] = created.WalletID
+	}
+
+	alerts, err := s.repo.ListLowBalanceWallets(s.ctx, s.testUser)
+	s.Require().NoError(err)
+	names := make([]string, len(alerts))
+	for i, w := range alerts {
+		names[i] = w.Name
+	}
+	// furthest below the threshold first, a missing balance counts as empty
+	s.Equal([]string{"Nearly Empty", "No Balance", "Just Below"}, names)
+	s.Equal(50.0, *alerts[0].LowBalanceThreshold)
+
+	// clearing the threshold or trashing the wallet drops the alert
+	s.Require().NoError(s.repo.DeleteWallet(s.ctx, ids["Nearly Empty"], s.testUser, nil))
+	_, err = s.repo.UpdateWallet(s.ctx, types.WalletUpdatePayload{
+		WalletID: ids["No Balance"],
+		Name:     "No Balance",
+		Currency: "USD",
+	}, s.testUser)
+	s.Require().NoError(err)
+
+	alerts, err = s.repo.ListLowBalanceWallets(s.ctx, s.testUser)
+	s.Require().NoError(err)
+	s.Require().Len(alerts, 1)
+	s.Equal("Just Below", alerts[0].Name)
+
+	alerts, err = s.repo.ListLowBalanceWallets(s.ctx, uuid.New())
+	s.NoError(err)
+	s.Empty(alerts)
+}
+
 func (s *WalletRepositoryTestSuite) runMigrations() error {
 	migrationsDir := "../../db/sql/migrations"
 
@@ -684,3 +1079,15 @@ func (s *WalletRepositoryTestSuite) createTestProject(name string) uuid.UUID {
 	s.Require().NoError(err)
 	return projectID
 }
+
+// createTestTags creates tags owned by the test user; tags the user doesn't own are dropped on write
+func (s *WalletRepositoryTestSuite) createTestTags(count int) []uuid.UUID {
+	tags := make([]uuid.UUID, count)
+	for i := range tags {
+		err := s.pool.QueryRow(s.ctx, `
+			INSERT INTO tags (user_id, name) VALUES ($1, $2) RETURNING tag_id
+		`, s.testUser, fmt.Sprintf("test tag %d", i)).Scan(&tags[i])
+		s.Require().NoError(err)
+	}
+	return tags
+}
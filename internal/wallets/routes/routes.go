@@ -1,11 +1,17 @@
 package routes
 
 import (
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/currency"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/quota"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/events"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/validate"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/handlers"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/repository"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/service"
 	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -15,18 +21,19 @@ type Router struct {
 }
 
 // New creates a new wallet router with proper dependency injection
-func New(dbService db.Service, logger *zap.Logger) *Router {
+func New(dbService db.Service, limits coreTypes.LimitPolicy, rounding validate.RoundingMode, rates currency.Converter, quotas *quota.Checker, bus *events.Bus, logger *zap.Logger, tracer trace.Tracer) *Router {
 	// Get queries from db service
 	queries := dbService.Queries()
 
 	// Initialize repository
-	repo := repository.NewWalletRepository(queries)
+	repo := repository.NewTracedWalletRepository(repository.NewWalletRepository(queries), tracer)
 
-	// Initialize service with repository
-	walletService := service.NewWalletService(repo, logger)
+	// Initialize service with repository, capping the wallets of each user at their quota
+	// and publishing their changes to bus
+	walletService := service.NewTracedWalletService(service.NewWalletService(repo, rounding, rates, quotas, bus, logger), tracer)
 
 	// Initialize handler with service
-	handler := handlers.NewWalletHandler(walletService, logger)
+	handler := handlers.NewWalletHandler(walletService, limits, logger)
 
 	return &Router{
 		handler: handler,
@@ -36,14 +43,26 @@ func New(dbService db.Service, logger *zap.Logger) *Router {
 // RegisterRoutes registers all wallet routes
 func (r *Router) RegisterRoutes(router chi.Router) {
 	router.Route("/wallets", func(router chi.Router) {
+		router.Get("/", r.handler.ListWalletsPaginated)
 		router.Get("/search", r.handler.SearchWallets)
 		router.Get("/paginated", r.handler.ListWalletsPaginated)
+		router.Get("/trash", r.handler.ListDeletedWallets)
+		router.Get("/alerts", r.handler.ListWalletAlerts)
+		router.Get("/facets", r.handler.ListWalletFacets)
 		router.Post("/", r.handler.CreateWallet)
 		router.Route("/{id}", func(router chi.Router) {
 			router.Get("/", r.handler.GetWallet)
 			router.Put("/", r.handler.UpdateWallet)
 			router.Delete("/", r.handler.DeleteWallet)
+			router.Get("/delete-preview", r.handler.PreviewWalletDeletion)
+			router.Post("/restore", r.handler.RestoreWallet)
+			router.Post("/pin", r.handler.PinWallet)
+			router.Post("/unpin", r.handler.UnpinWallet)
+			router.Get("/statement.csv", r.handler.ExportStatement)
+			router.Get("/projection", r.handler.ProjectBalance)
 		})
 	})
+	router.Get("/me/net-worth", r.handler.NetWorth)
 	router.Get("/projects/{id}/wallets", r.handler.GetProjectWallets)
+	router.Post("/projects/{id}/wallets/attach", r.handler.AttachWalletsToProject)
 }
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"math"
+
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// statementBatchSize is the number of ledger entries read per query while writing a
+// statement
+var statementBatchSize int32 = 500
+
+// ExportStatement hands the rows of the wallet's statement over the params' range to fn,
+// oldest first and closed by a summary row with the debit and credit totals. Balances
+// run from the wallet's balance at the start of the range and are added up in minor
+// units so long statements don't drift.
+func (s *walletService) ExportStatement(ctx context.Context, walletID, userID uuid.UUID, params types.StatementParams, fn func(types.StatementRow) error) (err error) {
+	defer s.operation("ExportStatement", userID, walletID,
+		zap.Time("from", params.From),
+		zap.Time("to", params.To)).End(&err)
+
+	wallet, err := s.repo.GetWallet(ctx, walletID, userID)
+	if err != nil {
+		return err
+	}
+
+	opening, err := s.repo.GetLedgerBalance(ctx, walletID, userID, params.From)
+	if err != nil {
+		return err
+	}
+
+	balance, debits, credits := toMinorUnits(opening), int64(0), int64(0)
+	cursor, cursorSeq := params.From, int64(0)
+	for {
+		entries, err := s.repo.ListLedgerEntries(ctx, walletID, userID, params.End(), cursor, cursorSeq, statementBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			amount := toMinorUnits(entry.Amount)
+			balance += amount
+			row := types.StatementRow{
+				Date:        coreTypes.NewTimestamp(entry.OccurredAt),
+				Description: entry.Description,
+				Balance:     fromMinorUnits(balance),
+				Currency:    wallet.Currency,
+			}
+			if amount < 0 {
+				debits -= amount
+				row.Debit = fromMinorUnits(-amount)
+			} else {
+				credits += amount
+				row.Credit = fromMinorUnits(amount)
+			}
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+
+		if int32(len(entries)) < statementBatchSize {
+			break
+		}
+		last := entries[len(entries)-1]
+		cursor, cursorSeq = last.OccurredAt, last.Seq
+	}
+
+	return fn(types.StatementRow{
+		Date:        coreTypes.NewTimestamp(params.To),
+		Description: "Closing balance",
+		Debit:       fromMinorUnits(debits),
+		Credit:      fromMinorUnits(credits),
+		Balance:     fromMinorUnits(balance),
+		Currency:    wallet.Currency,
+		Summary:     true,
+	})
+}
+
+// toMinorUnits converts an amount stored with two decimals to cents
+func toMinorUnits(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+func fromMinorUnits(cents int64) float64 {
+	return float64(cents) / 100
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/paging"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/repository"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+	"github.com/google/uuid"
+)
+
+// exportBatchSize is the number of wallets read per query while exporting
+var exportBatchSize int32 = 500
+
+// WriteWallets writes every active wallet of the user to w in format, one of the
+// ExportExtensions media types, newest first and read in batches. It returns how many
+// wallets it wrote.
+func WriteWallets(ctx context.Context, repo repository.WalletRepository, userID uuid.UUID, format string, w io.Writer) (int, error) {
+	if _, ok := types.ExportExtensions[format]; !ok {
+		return 0, fmt.Errorf("unknown wallet export format %q", format)
+	}
+	buffered := bufio.NewWriter(w)
+
+	var write func(types.Wallet) error
+	var finish func() error
+	switch format {
+	case types.ExportFormatJSON:
+		if err := buffered.WriteByte('['); err != nil {
+			return 0, err
+		}
+		first := true
+		write = func(wallet types.Wallet) error {
+			encoded, err := json.Marshal(wallet)
+			if err != nil {
+				return err
+			}
+			if !first {
+				if err := buffered.WriteByte(','); err != nil {
+					return err
+				}
+			}
+			first = false
+			_, err = buffered.Write(encoded)
+			return err
+		}
+		finish = func() error {
+			return buffered.WriteByte(']')
+		}
+	default:
+		writer := csv.NewWriter(buffered)
+		if err := writer.Write(types.CSVHeader); err != nil {
+			return 0, err
+		}
+		write = func(wallet types.Wallet) error {
+			return writer.Write(wallet.CSVRecord())
+		}
+		finish = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+	}
+
+	exported, err := StreamWallets(ctx, repo, userID, write)
+	if err != nil {
+		return exported, err
+	}
+
+	if err := finish(); err != nil {
+		return exported, err
+	}
+	return exported, buffered.Flush()
+}
+
+// StreamWallets hands every active wallet of the user to fn, newest first and read in
+// batches, and returns how many it handed over
+func StreamWallets(ctx context.Context, repo repository.WalletRepository, userID uuid.UUID, fn func(types.Wallet) error) (int, error) {
+	var exported int
+	err := paging.ForEachPage(ctx, func(ctx context.Context, offset int32) ([]types.Wallet, int32, error) {
+		wallets, err := repo.ListWallets(ctx, userID, exportBatchSize, offset)
+		return wallets, paging.Next(wallets, int(exportBatchSize), func(types.Wallet) int32 { return offset + exportBatchSize }), err
+	}, func(wallet types.Wallet) error {
+		if err := fn(wallet); err != nil {
+			return err
+		}
+		exported++
+		return nil
+	})
+	return exported, err
+}
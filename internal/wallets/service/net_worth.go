@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	stdErrors "errors"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/currency"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/validate"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// NetWorth sums the balances of the user's wallets by currency. With a convert_to currency
+// each total is converted at the current rate and they are added up, a currency without
+// a rate fails the whole conversion rather than being left out of it.
+func (s *walletService) NetWorth(ctx context.Context, userID uuid.UUID, params types.NetWorthParams) (_ types.NetWorth, err error) {
+	defer s.operation("NetWorth", userID, uuid.Nil,
+		zap.Bool("include_archived", params.IncludeArchived),
+		zap.String("convert_to", params.ConvertTo),
+	).End(&err)
+
+	if params.ConvertTo != "" && s.rates == nil {
+		return types.NetWorth{}, errors.NewValidationError("convert_to: currency conversion is not available")
+	}
+
+	totals, err := s.repo.SumBalancesByCurrency(ctx, userID, params.IncludeArchived)
+	if err != nil {
+		return types.NetWorth{}, err
+	}
+	netWorth := types.NetWorth{Totals: totals}
+	if params.ConvertTo == "" {
+		return netWorth, nil
+	}
+
+	converted := &types.ConvertedTotal{Currency: params.ConvertTo, Rates: make(map[string]float64, len(totals))}
+	var sum float64
+	for _, total := range totals {
+		rate, err := s.rates.Rate(ctx, total.Currency, params.ConvertTo)
+		if stdErrors.Is(err, currency.ErrRateUnavailable) {
+			return types.NetWorth{}, errors.NewValidationError("convert_to: no exchange rate from %s to %s", total.Currency, params.ConvertTo)
+		}
+		if err != nil {
+			return types.NetWorth{}, err
+		}
+		converted.Rates[total.Currency] = rate
+		sum += total.Total * rate
+	}
+	converted.Total = validate.RoundAmount(sum, params.ConvertTo, s.rounding)
+	netWorth.Converted = converted
+	return netWorth, nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"math"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/validate"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ProjectBalance projects the wallet's balance month by month from its current balance,
+// nothing is written
+func (s *walletService) ProjectBalance(ctx context.Context, walletID, userID uuid.UUID, params types.ProjectionParams) (_ types.Projection, err error) {
+	defer s.operation("ProjectBalance", userID, walletID, zap.Int("months", params.Months)).End(&err)
+
+	wallet, err := s.repo.GetWallet(ctx, walletID, userID)
+	if err != nil {
+		return types.Projection{}, err
+	}
+
+	var balance float64
+	if wallet.Balance != nil {
+		balance = *wallet.Balance
+	}
+	projection := projectBalance(balance, wallet.Currency, params, s.rounding)
+	projection.WalletID = wallet.WalletID
+	return projection, nil
+}
+
+// projectBalance compounds the balance monthly at the params' annual rate over their
+// months. Each month earns a twelfth of the rate on the balance it starts with, rounded
+// to the currency's minor unit with the rounding mode, and the contribution is added at
+// its end. Amounts are added up in minor units so long projections don't drift.
+func projectBalance(balance float64, currency string, params types.ProjectionParams, rounding validate.RoundingMode) types.Projection {
+	scale := math.Pow10(validate.CurrencyDecimals(currency))
+	round := math.Round
+	if rounding == validate.RoundHalfEven {
+		round = math.RoundToEven
+	}
+	toMinor := func(amount float64) int64 { return int64(round(amount * scale)) }
+	fromMinor := func(units int64) float64 { return float64(units) / scale }
+
+	current := toMinor(balance)
+	contribution := toMinor(params.MonthlyContribution)
+	monthlyRate := params.AnnualRate / 12
+
+	projection := types.Projection{
+		Currency:            currency,
+		StartingBalance:     fromMinor(current),
+		MonthlyContribution: fromMinor(contribution),
+		AnnualRate:          params.AnnualRate,
+		Months:              make([]types.ProjectionMonth, 0, params.Months),
+	}
+
+	var contributed, earned int64
+	for month := 1; month <= params.Months; month++ {
+		interest := int64(round(float64(current) * monthlyRate))
+		current += interest + contribution
+		contributed += contribution
+		earned += interest
+		projection.Months = append(projection.Months, types.ProjectionMonth{
+			Month:       month,
+			Contributed: fromMinor(contribution),
+			Interest:    fromMinor(interest),
+			Balance:     fromMinor(current),
+		})
+	}
+
+	projection.TotalContributed = fromMinor(contributed)
+	projection.TotalInterest = fromMinor(earned)
+	projection.FinalBalance = fromMinor(current)
+	return projection
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/deletion"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/tracing"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedWalletService records a span around each call of the wrapped service
+type tracedWalletService struct {
+	next   WalletService
+	tracer trace.Tracer
+}
+
+// NewTracedWalletService wraps next so each call records a span named after the method, a nil
+// tracer returns next as is
+func NewTracedWalletService(next WalletService, tracer trace.Tracer) WalletService {
+	if tracer == nil {
+		return next
+	}
+	return &tracedWalletService{next: next, tracer: tracer}
+}
+
+func (t *tracedWalletService) GetWallet(ctx context.Context, walletID, userID uuid.UUID) (types.Wallet, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletService.GetWallet")
+	wallet, err := t.next.GetWallet(ctx, walletID, userID)
+	tracing.End(span, err)
+	return wallet, err
+}
+
+func (t *tracedWalletService) GetWalletsByIDs(ctx context.Context, userID uuid.UUID, walletIDs []uuid.UUID) ([]types.Wallet, []uuid.UUID, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletService.GetWalletsByIDs")
+	wallets, missing, err := t.next.GetWalletsByIDs(ctx, userID, walletIDs)
+	tracing.End(span, err)
+	return wallets, missing, err
+}
+
+func (t *tracedWalletService) GetWalletWithStats(ctx context.Context, walletID, userID uuid.UUID) (types.Wallet, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletService.GetWalletWithStats")
+	wallet, err := t.next.GetWalletWithStats(ctx, walletID, userID)
+	tracing.End(span, err)
+	return wallet, err
+}
+
+func (t *tracedWalletService) ListWallets(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]types.Wallet, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletService.ListWallets")
+	wallets, err := t.next.ListWallets(ctx, userID, limit, offset)
+	tracing.End(span, err)
+	return wallets, err
+}
+
+func (t *tracedWalletService) StreamWalletsPaginated(ctx context.Context, userID uuid.UUID, createdAt time.Time, walletID uuid.UUID, pinned bool, limit int32, order coreTypes.SortOrder, filter types.WalletFilter, fn func(types.Wallet) error) error {
+	ctx, span := t.tracer.Start(ctx, "WalletService.StreamWalletsPaginated")
+	err := t.next.StreamWalletsPaginated(ctx, userID, createdAt, walletID, pinned, limit, order, filter, fn)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *tracedWalletService) ListWalletsPaginated(ctx context.Context, userID uuid.UUID, createdAt time.Time, walletID uuid.UUID, pinned bool, limit int32, order coreTypes.SortOrder, filter types.WalletFilter) ([]types.Wallet, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletService.ListWalletsPaginated")
+	wallets, err := t.next.ListWalletsPaginated(ctx, userID, createdAt, walletID, pinned, limit, order, filter)
+	tracing.End(span, err)
+	return wallets, err
+}
+
+func (t *tracedWalletService) ListWalletIDsPaginated(ctx context.Context, userID uuid.UUID, createdAt time.Time, walletID uuid.UUID, pinned bool, limit int32, order coreTypes.SortOrder, filter types.WalletFilter) ([]coreTypes.KeyedID, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletService.ListWalletIDsPaginated")
+	keys, err := t.next.ListWalletIDsPaginated(ctx, userID, createdAt, walletID, pinned, limit, order, filter)
+	tracing.End(span, err)
+	return keys, err
+}
+
+func (t *tracedWalletService) ExpandWalletProjects(ctx context.Context, userID uuid.UUID, wallets []types.Wallet) ([]types.WalletWithProject, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletService.ExpandWalletProjects")
+	expanded, err := t.next.ExpandWalletProjects(ctx, userID, wallets)
+	tracing.End(span, err)
+	return expanded, err
+}
+
+func (t *tracedWalletService) PinWallet(ctx context.Context, walletID, userID uuid.UUID) (types.Wallet, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletService.PinWallet")
+	wallet, err := t.next.PinWallet(ctx, walletID, userID)
+	tracing.End(span, err)
+	return wallet, err
+}
+
+func (t *tracedWalletService) UnpinWallet(ctx context.Context, walletID, userID uuid.UUID) (types.Wallet, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletService.UnpinWallet")
+	wallet, err := t.next.UnpinWallet(ctx, walletID, userID)
+	tracing.End(span, err)
+	return wallet, err
+}
+
+func (t *tracedWalletService) CreateWallet(ctx context.Context, payload types.WalletCreatePayload, userID uuid.UUID) (types.Wallet, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletService.CreateWallet")
+	wallet, err := t.next.CreateWallet(ctx, payload, userID)
+	tracing.End(span, err)
+	return wallet, err
+}
+
+func (t *tracedWalletService) UpdateWallet(ctx context.Context, walletID, userID uuid.UUID, merge func(types.Wallet) (types.WalletUpdatePayload, error)) (types.Wallet, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletService.UpdateWallet")
+	wallet, err := t.next.UpdateWallet(ctx, walletID, userID, merge)
+	tracing.End(span, err)
+	return wallet, err
+}
+
+func (t *tracedWalletService) DeleteWallet(ctx context.Context, walletID, userID uuid.UUID, version *time.Time) error {
+	ctx, span := t.tracer.Start(ctx, "WalletService.DeleteWallet")
+	err := t.next.DeleteWallet(ctx, walletID, userID, version)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *tracedWalletService) PurgeWallet(ctx context.Context, walletID, userID uuid.UUID, version *time.Time) error {
+	ctx, span := t.tracer.Start(ctx, "WalletService.PurgeWallet")
+	err := t.next.PurgeWallet(ctx, walletID, userID, version)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *tracedWalletService) DeletionImpact(ctx context.Context, walletID, userID uuid.UUID) (deletion.Impact, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletService.DeletionImpact")
+	impact, err := t.next.DeletionImpact(ctx, walletID, userID)
+	tracing.End(span, err)
+	return impact, err
+}
+
+func (t *tracedWalletService) ListDeletedWalletsPaginated(ctx context.Context, userID uuid.UUID, deletedAt time.Time, walletID uuid.UUID, limit int32, order coreTypes.SortOrder) ([]types.Wallet, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletService.ListDeletedWalletsPaginated")
+	wallets, err := t.next.ListDeletedWalletsPaginated(ctx, userID, deletedAt, walletID, limit, order)
+	tracing.End(span, err)
+	return wallets, err
+}
+
+func (t *tracedWalletService) RestoreWallet(ctx context.Context, walletID, userID uuid.UUID) (types.Wallet, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletService.RestoreWallet")
+	wallet, err := t.next.RestoreWallet(ctx, walletID, userID)
+	tracing.End(span, err)
+	return wallet, err
+}
+
+func (t *tracedWalletService) GetProjectWallets(ctx context.Context, projectID uuid.UUID, userID uuid.UUID) ([]types.Wallet, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletService.GetProjectWallets")
+	wallets, err := t.next.GetProjectWallets(ctx, projectID, userID)
+	tracing.End(span, err)
+	return wallets, err
+}
+
+func (t *tracedWalletService) AttachWalletsToProject(ctx context.Context, userID uuid.UUID, payload types.WalletAttachPayload) (types.WalletAttachResult, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletService.AttachWalletsToProject")
+	result, err := t.next.AttachWalletsToProject(ctx, userID, payload)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (t *tracedWalletService) SearchWallets(ctx context.Context, userID uuid.UUID, name string, limit, offset int32) ([]types.Wallet, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletService.SearchWallets")
+	wallets, err := t.next.SearchWallets(ctx, userID, name, limit, offset)
+	tracing.End(span, err)
+	return wallets, err
+}
+
+func (t *tracedWalletService) SearchWalletsPicker(ctx context.Context, userID uuid.UUID, name string, limit, offset int32) ([]types.WalletPickerItem, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletService.SearchWalletsPicker")
+	items, err := t.next.SearchWalletsPicker(ctx, userID, name, limit, offset)
+	tracing.End(span, err)
+	return items, err
+}
+
+func (t *tracedWalletService) ListFacets(ctx context.Context, userID uuid.UUID, field string) ([]coreTypes.Facet, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletService.ListFacets")
+	facets, err := t.next.ListFacets(ctx, userID, field)
+	tracing.End(span, err)
+	return facets, err
+}
+
+func (t *tracedWalletService) ListLowBalanceWallets(ctx context.Context, userID uuid.UUID) ([]types.Wallet, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletService.ListLowBalanceWallets")
+	wallets, err := t.next.ListLowBalanceWallets(ctx, userID)
+	tracing.End(span, err)
+	return wallets, err
+}
+
+func (t *tracedWalletService) ExportStatement(ctx context.Context, walletID, userID uuid.UUID, params types.StatementParams, fn func(types.StatementRow) error) error {
+	ctx, span := t.tracer.Start(ctx, "WalletService.ExportStatement")
+	err := t.next.ExportStatement(ctx, walletID, userID, params, fn)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *tracedWalletService) ProjectBalance(ctx context.Context, walletID, userID uuid.UUID, params types.ProjectionParams) (types.Projection, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletService.ProjectBalance")
+	projection, err := t.next.ProjectBalance(ctx, walletID, userID, params)
+	tracing.End(span, err)
+	return projection, err
+}
+
+func (t *tracedWalletService) NetWorth(ctx context.Context, userID uuid.UUID, params types.NetWorthParams) (types.NetWorth, error) {
+	ctx, span := t.tracer.Start(ctx, "WalletService.NetWorth")
+	netWorth, err := t.next.NetWorth(ctx, userID, params)
+	tracing.End(span, err)
+	return netWorth, err
+}
@@ -1,41 +1,123 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	stdErrors "errors"
 	"fmt"
+	"slices"
 	"time"
 
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/cache"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/currency"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/deletion"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/logging"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/quota"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/events"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/validate"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/repository"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 type WalletService interface {
 	GetWallet(ctx context.Context, walletID, userID uuid.UUID) (types.Wallet, error)
+	GetWalletsByIDs(ctx context.Context, userID uuid.UUID, walletIDs []uuid.UUID) ([]types.Wallet, []uuid.UUID, error)
+	GetWalletWithStats(ctx context.Context, walletID, userID uuid.UUID) (types.Wallet, error)
 	ListWallets(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]types.Wallet, error)
-	ListWalletsPaginated(ctx context.Context, userID uuid.UUID, createdAt time.Time, walletID uuid.UUID, limit int32) ([]types.Wallet, error)
+	ListWalletsPaginated(ctx context.Context, userID uuid.UUID, createdAt time.Time, walletID uuid.UUID, pinned bool, limit int32, order coreTypes.SortOrder, filter types.WalletFilter) ([]types.Wallet, error)
+	ListWalletIDsPaginated(ctx context.Context, userID uuid.UUID, createdAt time.Time, walletID uuid.UUID, pinned bool, limit int32, order coreTypes.SortOrder, filter types.WalletFilter) ([]coreTypes.KeyedID, error)
+	StreamWalletsPaginated(ctx context.Context, userID uuid.UUID, createdAt time.Time, walletID uuid.UUID, pinned bool, limit int32, order coreTypes.SortOrder, filter types.WalletFilter, fn func(types.Wallet) error) error
+	ExpandWalletProjects(ctx context.Context, userID uuid.UUID, wallets []types.Wallet) ([]types.WalletWithProject, error)
+	PinWallet(ctx context.Context, walletID, userID uuid.UUID) (types.Wallet, error)
+	UnpinWallet(ctx context.Context, walletID, userID uuid.UUID) (types.Wallet, error)
 	CreateWallet(ctx context.Context, payload types.WalletCreatePayload, userID uuid.UUID) (types.Wallet, error)
-	UpdateWallet(ctx context.Context, payload types.WalletUpdatePayload, userID uuid.UUID) (types.Wallet, error)
-	DeleteWallet(ctx context.Context, walletID, userID uuid.UUID) error
+	UpdateWallet(ctx context.Context, walletID, userID uuid.UUID, merge func(types.Wallet) (types.WalletUpdatePayload, error)) (types.Wallet, error)
+	DeleteWallet(ctx context.Context, walletID, userID uuid.UUID, version *time.Time) error
+	PurgeWallet(ctx context.Context, walletID, userID uuid.UUID, version *time.Time) error
+	DeletionImpact(ctx context.Context, walletID, userID uuid.UUID) (deletion.Impact, error)
+	ListDeletedWalletsPaginated(ctx context.Context, userID uuid.UUID, deletedAt time.Time, walletID uuid.UUID, limit int32, order coreTypes.SortOrder) ([]types.Wallet, error)
+	RestoreWallet(ctx context.Context, walletID, userID uuid.UUID) (types.Wallet, error)
 	GetProjectWallets(ctx context.Context, projectID uuid.UUID, userID uuid.UUID) ([]types.Wallet, error)
-	SearchWallets(ctx context.Context, userID uuid.UUID, name string, limit int32) ([]types.Wallet, error)
+	AttachWalletsToProject(ctx context.Context, userID uuid.UUID, payload types.WalletAttachPayload) (types.WalletAttachResult, error)
+	SearchWallets(ctx context.Context, userID uuid.UUID, name string, limit, offset int32) ([]types.Wallet, error)
+	SearchWalletsPicker(ctx context.Context, userID uuid.UUID, name string, limit, offset int32) ([]types.WalletPickerItem, error)
+	ListLowBalanceWallets(ctx context.Context, userID uuid.UUID) ([]types.Wallet, error)
+	ListFacets(ctx context.Context, userID uuid.UUID, field string) ([]coreTypes.Facet, error)
+	ExportStatement(ctx context.Context, walletID, userID uuid.UUID, params types.StatementParams, fn func(types.StatementRow) error) error
+	ProjectBalance(ctx context.Context, walletID, userID uuid.UUID, params types.ProjectionParams) (types.Projection, error)
+	NetWorth(ctx context.Context, userID uuid.UUID, params types.NetWorthParams) (types.NetWorth, error)
 }
 
 type walletService struct {
-	repo   repository.WalletRepository
-	logger *zap.Logger
+	repo     repository.WalletRepository
+	rounding validate.RoundingMode
+	rates    currency.Converter
+	quotas   *quota.Checker
+	events   *events.Bus
+	deletes  *deletion.Registry[struct{}]
+	searches cache.Coalescer
+	logger   *zap.Logger
+}
+
+// NewWalletService creates the wallet service, balances are rounded to their currency's
+// decimal places with the rounding mode, half up when it is empty. Amounts are converted
+// between currencies with rates, nil refuses conversions. quotas caps the wallets of each
+// user, nil leaves them unlimited. The changes to wallets are published to bus, nil
+// publishes nothing.
+func NewWalletService(repo repository.WalletRepository, rounding validate.RoundingMode, rates currency.Converter, quotas *quota.Checker, bus *events.Bus, logger *zap.Logger) WalletService {
+	if rounding == "" {
+		rounding = validate.RoundHalfUp
+	}
+	s := &walletService{
+		repo:     repo,
+		rounding: rounding,
+		rates:    rates,
+		quotas:   quotas,
+		events:   bus,
+		logger:   logger.With(zap.String("component", "wallet_service")),
+	}
+	s.deletes = deletion.NewRegistry[struct{}]("wallet").Register(s.trashedWallet, s.keptLedgerEntries)
+	return s
+}
+
+// operation starts the log of a wallet service method, walletID is uuid.Nil when the
+// method doesn't work on one wallet
+func (s *walletService) operation(name string, userID, walletID uuid.UUID, fields ...zap.Field) *logging.Operation {
+	logger := logging.WithEntity(logging.WithUser(s.logger, userID), "wallet", walletID)
+	return logging.Start(logger, "WalletService."+name, fields...)
+}
+
+// publish tells the user's clients listening for changes that the wallet changed
+func (s *walletService) publish(userID, walletID uuid.UUID, action string, updatedAt time.Time) {
+	s.events.Publish(userID, events.Event{Type: events.TypeWallet, EntityID: walletID, Action: action, UpdatedAt: coreTypes.NewTimestamp(updatedAt)})
+}
+
+// published publishes the update of a wallet a method returns along with its error
+func (s *walletService) published(userID uuid.UUID, action string, wallet types.Wallet, err error) (types.Wallet, error) {
+	if err != nil {
+		return types.Wallet{}, err
+	}
+	s.publish(userID, wallet.WalletID, action, wallet.UpdatedAt.Time)
+	return wallet, nil
 }
 
-func NewWalletService(repo repository.WalletRepository, logger *zap.Logger) WalletService {
-	return &walletService{
-		repo:   repo,
-		logger: logger.With(zap.String("component", "wallet_service")),
+// roundBalance quantizes a balance to the minor unit of the wallet's currency
+func (s *walletService) roundBalance(balance *float64, currency string) *float64 {
+	if balance == nil {
+		return nil
 	}
+	rounded := validate.RoundAmount(*balance, currency, s.rounding)
+	return &rounded
 }
 
 // Common validation function
-func validateWallet(name, currency string, balance *float64, tags []uuid.UUID) error {
+func validateWallet(name, currency string, balance, lowBalanceThreshold *float64, tags []uuid.UUID) error {
 	if name == "" {
 		return fmt.Errorf("wallet name is required")
 	}
@@ -56,6 +138,10 @@ func validateWallet(name, currency string, balance *float64, tags []uuid.UUID) e
 		return fmt.Errorf("balance cannot be negative")
 	}
 
+	if lowBalanceThreshold != nil && *lowBalanceThreshold < 0 {
+		return fmt.Errorf("low balance threshold cannot be negative")
+	}
+
 	if len(tags) > types.MaxTagsCount {
 		return fmt.Errorf("number of tags exceeds maximum allowed")
 	}
@@ -63,82 +149,467 @@ func validateWallet(name, currency string, balance *float64, tags []uuid.UUID) e
 	return nil
 }
 
-func (s *walletService) GetWallet(ctx context.Context, walletID, userID uuid.UUID) (types.Wallet, error) {
-	s.logger.Info("getting wallet",
-		zap.String("wallet_id", walletID.String()),
-		zap.String("user_id", userID.String()))
+// validateGroup checks an optional wallet group belongs to the user
+func (s *walletService) validateGroup(ctx context.Context, userID uuid.UUID, groupID *uuid.UUID) error {
+	if groupID == nil {
+		return nil
+	}
+
+	exists, err := s.repo.WalletGroupExists(ctx, userID, *groupID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.NewValidationError("wallet group %s not found", *groupID)
+	}
+	return nil
+}
+
+func (s *walletService) GetWallet(ctx context.Context, walletID, userID uuid.UUID) (_ types.Wallet, err error) {
+	defer s.operation("GetWallet", userID, walletID).End(&err)
 	return s.repo.GetWallet(ctx, walletID, userID)
 }
 
-func (s *walletService) ListWallets(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]types.Wallet, error) {
-	s.logger.Info("listing wallets",
-		zap.String("user_id", userID.String()),
+// GetWalletsByIDs gets the user's wallets with the IDs in the order asked for, the IDs of
+// the wallets not found are returned apart
+func (s *walletService) GetWalletsByIDs(ctx context.Context, userID uuid.UUID, walletIDs []uuid.UUID) (_ []types.Wallet, _ []uuid.UUID, err error) {
+	defer s.operation("GetWalletsByIDs", userID, uuid.Nil, zap.Int("ids", len(walletIDs))).End(&err)
+	return s.repo.GetWalletsByIDs(ctx, userID, walletIDs)
+}
+
+// GetWalletWithStats retrieves a wallet along with its outflows and inflows over the
+// trailing 7, 30 and 90 UTC days, today included
+func (s *walletService) GetWalletWithStats(ctx context.Context, walletID, userID uuid.UUID) (_ types.Wallet, err error) {
+	defer s.operation("GetWalletWithStats", userID, walletID).End(&err)
+
+	wallet, err := s.repo.GetWallet(ctx, walletID, userID)
+	if err != nil {
+		return types.Wallet{}, err
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	stats, err := s.repo.GetLedgerStats(ctx, walletID, userID, today)
+	if err != nil {
+		return types.Wallet{}, err
+	}
+	stats.AverageDailySpend = validate.RoundAmount(stats.Last30Days.Outflow/types.StatsAverageDays, wallet.Currency, s.rounding)
+
+	wallet.Stats = &stats
+	return wallet, nil
+}
+
+func (s *walletService) ListWallets(ctx context.Context, userID uuid.UUID, limit, offset int32) (_ []types.Wallet, err error) {
+	defer s.operation("ListWallets", userID, uuid.Nil,
 		zap.Int32("limit", limit),
-		zap.Int32("offset", offset))
+		zap.Int32("offset", offset)).End(&err)
 	return s.repo.ListWallets(ctx, userID, limit, offset)
 }
 
-func (s *walletService) ListWalletsPaginated(ctx context.Context, userID uuid.UUID, createdAt time.Time, walletID uuid.UUID, limit int32) ([]types.Wallet, error) {
-	s.logger.Info("listing paginated wallets",
-		zap.String("user_id", userID.String()),
+// ListWalletsPaginated lists the pinned wallets first, most recently pinned on top, then
+// the others in the requested order. With pinned set the cursor is a position among the
+// pinned wallets (pinned_at, wallet_id), the page continues with the others once they run out.
+func (s *walletService) ListWalletsPaginated(ctx context.Context, userID uuid.UUID, createdAt time.Time, walletID uuid.UUID, pinned bool, limit int32, order coreTypes.SortOrder, filter types.WalletFilter) (_ []types.Wallet, err error) {
+	defer s.operation("ListWalletsPaginated", userID, uuid.Nil,
 		zap.Time("cursor", createdAt),
 		zap.String("cursor_id", walletID.String()),
-		zap.Int32("limit", limit))
+		zap.Bool("pinned", pinned),
+		zap.Int32("limit", limit),
+		zap.String("order", string(order))).End(&err)
 
 	if limit <= 0 {
 		return nil, fmt.Errorf("limit must be positive")
 	}
 
-	return s.repo.ListWalletsPaginated(ctx, userID, createdAt, walletID, limit)
+	var wallets []types.Wallet
+	if pinned {
+		pinnedWallets, err := s.repo.ListPinnedWalletsPaginated(ctx, userID, createdAt, walletID, limit, filter)
+		if err != nil {
+			return nil, err
+		}
+		if len(pinnedWallets) == int(limit) {
+			return pinnedWallets, nil
+		}
+		wallets = pinnedWallets
+		limit -= int32(len(pinnedWallets))
+		createdAt, walletID = coreTypes.StartCursor(order)
+	}
+
+	unpinned, err := s.repo.ListWalletsPaginated(ctx, userID, createdAt, walletID, limit, order, filter)
+	if err != nil {
+		return nil, err
+	}
+	return append(wallets, unpinned...), nil
 }
 
-func (s *walletService) CreateWallet(ctx context.Context, payload types.WalletCreatePayload, userID uuid.UUID) (types.Wallet, error) {
-	s.logger.Info("creating wallet",
-		zap.String("user_id", userID.String()),
-		zap.String("name", payload.Name))
+// ListWalletIDsPaginated is ListWalletsPaginated returning only the IDs of the wallets
+// and the keys to page on from them
+func (s *walletService) ListWalletIDsPaginated(ctx context.Context, userID uuid.UUID, createdAt time.Time, walletID uuid.UUID, pinned bool, limit int32, order coreTypes.SortOrder, filter types.WalletFilter) (_ []coreTypes.KeyedID, err error) {
+	defer s.operation("ListWalletIDsPaginated", userID, uuid.Nil,
+		zap.Time("cursor", createdAt),
+		zap.String("cursor_id", walletID.String()),
+		zap.Bool("pinned", pinned),
+		zap.Int32("limit", limit),
+		zap.String("order", string(order))).End(&err)
 
-	if err := validateWallet(payload.Name, payload.Currency, payload.Balance, payload.Tags); err != nil {
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+
+	var keys []coreTypes.KeyedID
+	if pinned {
+		pinnedKeys, err := s.repo.ListPinnedWalletIDsPaginated(ctx, userID, createdAt, walletID, limit, filter)
+		if err != nil {
+			return nil, err
+		}
+		if len(pinnedKeys) == int(limit) {
+			return pinnedKeys, nil
+		}
+		keys = pinnedKeys
+		limit -= int32(len(pinnedKeys))
+		createdAt, walletID = coreTypes.StartCursor(order)
+	}
+
+	unpinned, err := s.repo.ListWalletIDsPaginated(ctx, userID, createdAt, walletID, limit, order, filter)
+	if err != nil {
+		return nil, err
+	}
+	return append(keys, unpinned...), nil
+}
+
+// StreamWalletsPaginated is ListWalletsPaginated handing each wallet to fn as it is read
+func (s *walletService) StreamWalletsPaginated(ctx context.Context, userID uuid.UUID, createdAt time.Time, walletID uuid.UUID, pinned bool, limit int32, order coreTypes.SortOrder, filter types.WalletFilter, fn func(types.Wallet) error) (err error) {
+	defer s.operation("StreamWalletsPaginated", userID, uuid.Nil,
+		zap.Time("cursor", createdAt),
+		zap.String("cursor_id", walletID.String()),
+		zap.Bool("pinned", pinned),
+		zap.Int32("limit", limit),
+		zap.String("order", string(order))).End(&err)
+
+	if limit <= 0 {
+		return fmt.Errorf("limit must be positive")
+	}
+
+	if pinned {
+		var streamed int32
+		err := s.repo.ListPinnedWalletsPaginatedStream(ctx, userID, createdAt, walletID, limit, filter, func(wallet types.Wallet) error {
+			streamed++
+			return fn(wallet)
+		})
+		if err != nil || streamed == limit {
+			return err
+		}
+		limit -= streamed
+		createdAt, walletID = coreTypes.StartCursor(order)
+	}
+
+	return s.repo.ListWalletsPaginatedStream(ctx, userID, createdAt, walletID, limit, order, filter, fn)
+}
+
+// ExpandWalletProjects embeds the project of each wallet, fetched in a single query for the
+// whole list. The wallets outside any project get a nil project.
+func (s *walletService) ExpandWalletProjects(ctx context.Context, userID uuid.UUID, wallets []types.Wallet) (_ []types.WalletWithProject, err error) {
+	defer s.operation("ExpandWalletProjects", userID, uuid.Nil, zap.Int("wallets", len(wallets))).End(&err)
+
+	expanded := make([]types.WalletWithProject, len(wallets))
+	walletIDs := make([]uuid.UUID, 0, len(wallets))
+	for i, wallet := range wallets {
+		expanded[i].Wallet = wallet
+		if wallet.ProjectID != nil {
+			walletIDs = append(walletIDs, wallet.WalletID)
+		}
+	}
+	if len(walletIDs) == 0 {
+		return expanded, nil
+	}
+
+	projects, err := s.repo.ListWalletProjects(ctx, userID, walletIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range expanded {
+		if project, ok := projects[expanded[i].WalletID]; ok {
+			expanded[i].Project = &project
+		}
+	}
+	return expanded, nil
+}
+
+// PinWallet puts the wallet at the top of the listings, up to MaxPinnedWallets per user.
+// Pinning a pinned wallet leaves it as it is.
+func (s *walletService) PinWallet(ctx context.Context, walletID, userID uuid.UUID) (_ types.Wallet, err error) {
+	defer s.operation("PinWallet", userID, walletID).End(&err)
+
+	wallet, err := s.repo.GetWallet(ctx, walletID, userID)
+	if err != nil {
 		return types.Wallet{}, err
 	}
+	if wallet.Pinned {
+		return wallet, nil
+	}
 
-	return s.repo.CreateWallet(ctx, payload, userID)
+	wallet, err = s.repo.SetWalletPinned(ctx, walletID, userID, true)
+	if stdErrors.Is(err, repository.ErrPinnedWalletLimit) {
+		return types.Wallet{}, errors.NewValidationError("at most %d wallets can be pinned, unpin one first", types.MaxPinnedWallets)
+	}
+	return s.published(userID, events.ActionUpdated, wallet, err)
+}
+
+// UnpinWallet moves the wallet back among the unpinned ones
+func (s *walletService) UnpinWallet(ctx context.Context, walletID, userID uuid.UUID) (_ types.Wallet, err error) {
+	defer s.operation("UnpinWallet", userID, walletID).End(&err)
+	wallet, err := s.repo.SetWalletPinned(ctx, walletID, userID, false)
+	return s.published(userID, events.ActionUpdated, wallet, err)
 }
 
-func (s *walletService) UpdateWallet(ctx context.Context, payload types.WalletUpdatePayload, userID uuid.UUID) (types.Wallet, error) {
-	s.logger.Info("updating wallet",
-		zap.String("wallet_id", payload.WalletID.String()),
-		zap.String("user_id", userID.String()))
+func (s *walletService) CreateWallet(ctx context.Context, payload types.WalletCreatePayload, userID uuid.UUID) (_ types.Wallet, err error) {
+	op := s.operation("CreateWallet", userID, uuid.Nil, zap.String("name", payload.Name))
+	defer op.End(&err)
 
-	if err := validateWallet(payload.Name, payload.Currency, payload.Balance, payload.Tags); err != nil {
+	if err := validateWallet(payload.Name, payload.Currency, payload.Balance, payload.LowBalanceThreshold, payload.Tags); err != nil {
 		return types.Wallet{}, err
 	}
 
-	return s.repo.UpdateWallet(ctx, payload, userID)
+	if err := s.validateGroup(ctx, userID, payload.GroupID); err != nil {
+		return types.Wallet{}, err
+	}
+	if err := s.quotas.Check(ctx, userID, quota.Wallets, 1); err != nil {
+		return types.Wallet{}, err
+	}
+
+	// wallet names don't have to be unique, a second one with the same name is only
+	// pointed out in case it was created by mistake
+	exists, err := s.repo.WalletNameExists(ctx, userID, payload.Name)
+	if err != nil {
+		return types.Wallet{}, err
+	}
+	if exists {
+		requestcontext.AddWarning(ctx, fmt.Sprintf("name: a wallet named %q already exists", payload.Name))
+	}
+
+	payload.Balance = s.roundBalance(payload.Balance, payload.Currency)
+	wallet, err := s.repo.CreateWallet(ctx, payload, userID)
+	if err != nil {
+		return types.Wallet{}, err
+	}
+	op.With(zap.Stringer(logging.FieldEntityID, wallet.WalletID))
+	return s.published(userID, events.ActionCreated, wallet, nil)
+}
+
+// UpdateWallet updates the wallet with the payload merge makes of its current values.
+// The wallet's advisory lock is held from the read to the write, so concurrent updates
+// apply one after the other instead of one losing the changes of the other.
+func (s *walletService) UpdateWallet(ctx context.Context, walletID, userID uuid.UUID, merge func(types.Wallet) (types.WalletUpdatePayload, error)) (_ types.Wallet, err error) {
+	defer s.operation("UpdateWallet", userID, walletID).End(&err)
+
+	wallet, err := s.repo.UpdateWalletLocked(ctx, walletID, userID, func(existing types.Wallet) (types.WalletUpdatePayload, error) {
+		payload, err := merge(existing)
+		if err != nil {
+			return types.WalletUpdatePayload{}, err
+		}
+		return s.prepareUpdate(ctx, userID, payload)
+	})
+	return s.published(userID, events.ActionUpdated, wallet, err)
+}
+
+// prepareUpdate validates the payload of a wallet update and rounds its balance
+func (s *walletService) prepareUpdate(ctx context.Context, userID uuid.UUID, payload types.WalletUpdatePayload) (types.WalletUpdatePayload, error) {
+	if err := validateWallet(payload.Name, payload.Currency, payload.Balance, payload.LowBalanceThreshold, payload.Tags); err != nil {
+		return types.WalletUpdatePayload{}, err
+	}
+
+	if err := s.validateGroup(ctx, userID, payload.GroupID); err != nil {
+		return types.WalletUpdatePayload{}, err
+	}
+
+	payload.Balance = s.roundBalance(payload.Balance, payload.Currency)
+	return payload, nil
+}
+
+// DeleteWallet trashes the wallet, with a version only while it is still at that updatedAt
+// so a change made since the caller read it fails the delete
+func (s *walletService) DeleteWallet(ctx context.Context, walletID, userID uuid.UUID, version *time.Time) (err error) {
+	defer s.operation("DeleteWallet", userID, walletID).End(&err)
+
+	impact, err := s.DeletionImpact(ctx, walletID, userID)
+	if err != nil {
+		return err
+	}
+	if err := impact.Err(); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteWallet(ctx, walletID, userID, version); err != nil {
+		return err
+	}
+	s.publish(userID, walletID, events.ActionDeleted, time.Time{})
+	return nil
+}
+
+// PurgeWallet deletes a wallet for good instead of moving it to the trash, the wallet's
+// ledger entries go with it. Trashed wallets can be purged too, their deletion was
+// already published. With a version the wallet is only deleted while it is still at that
+// updatedAt.
+func (s *walletService) PurgeWallet(ctx context.Context, walletID, userID uuid.UUID, version *time.Time) (err error) {
+	defer s.operation("PurgeWallet", userID, walletID).End(&err)
+
+	trashed, err := s.repo.PurgeWallet(ctx, walletID, userID, version)
+	if err != nil {
+		return err
+	}
+	if !trashed {
+		s.publish(userID, walletID, events.ActionDeleted, time.Time{})
+	}
+	return nil
+}
+
+// DeletionImpact tells what deleting the wallet affects and whether anything blocks it,
+// DeleteWallet runs the same checks
+func (s *walletService) DeletionImpact(ctx context.Context, walletID, userID uuid.UUID) (_ deletion.Impact, err error) {
+	defer s.operation("DeletionImpact", userID, walletID).End(&err)
+	return s.deletes.Assess(ctx, userID, walletID, struct{}{})
+}
+
+// trashedWallet adds the wallet itself with its balance
+func (s *walletService) trashedWallet(ctx context.Context, userID, walletID uuid.UUID, _ struct{}, impact *deletion.Impact) error {
+	wallet, err := s.repo.GetWallet(ctx, walletID, userID)
+	if err != nil {
+		return err
+	}
+	var balance float64
+	if wallet.Balance != nil {
+		balance = *wallet.Balance
+	}
+	impact.Add(deletion.Affected{
+		Kind:     "wallets",
+		Count:    1,
+		Effect:   deletion.EffectTrashed,
+		Balances: []deletion.Balance{{Currency: wallet.Currency, Balance: balance}},
+	})
+	return nil
+}
+
+// keptLedgerEntries adds the wallet's balance history, it stays with the trashed wallet
+func (s *walletService) keptLedgerEntries(ctx context.Context, userID, walletID uuid.UUID, _ struct{}, impact *deletion.Impact) error {
+	count, err := s.repo.CountLedgerEntries(ctx, walletID, userID)
+	if err != nil {
+		return err
+	}
+	impact.Add(deletion.Affected{Kind: "ledgerEntries", Count: count, Effect: deletion.EffectKept})
+	return nil
+}
+
+func (s *walletService) ListDeletedWalletsPaginated(ctx context.Context, userID uuid.UUID, deletedAt time.Time, walletID uuid.UUID, limit int32, order coreTypes.SortOrder) (_ []types.Wallet, err error) {
+	defer s.operation("ListDeletedWalletsPaginated", userID, uuid.Nil,
+		zap.Time("cursor", deletedAt),
+		zap.String("cursor_id", walletID.String()),
+		zap.Int32("limit", limit),
+		zap.String("order", string(order))).End(&err)
+
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+
+	return s.repo.ListDeletedWalletsPaginated(ctx, userID, deletedAt, walletID, limit, order)
 }
 
-func (s *walletService) DeleteWallet(ctx context.Context, walletID, userID uuid.UUID) error {
-	s.logger.Info("deleting wallet",
-		zap.String("wallet_id", walletID.String()),
-		zap.String("user_id", userID.String()))
-	return s.repo.DeleteWallet(ctx, walletID, userID)
+func (s *walletService) RestoreWallet(ctx context.Context, walletID, userID uuid.UUID) (_ types.Wallet, err error) {
+	defer s.operation("RestoreWallet", userID, walletID).End(&err)
+	if err := s.quotas.Check(ctx, userID, quota.Wallets, 1); err != nil {
+		return types.Wallet{}, err
+	}
+	wallet, err := s.repo.RestoreWallet(ctx, walletID, userID)
+	return s.published(userID, events.ActionRestored, wallet, err)
 }
 
-func (s *walletService) GetProjectWallets(ctx context.Context, projectID uuid.UUID, userID uuid.UUID) ([]types.Wallet, error) {
-	s.logger.Info("getting project wallets",
-		zap.String("project_id", projectID.String()),
-		zap.String("user_id", userID.String()))
+func (s *walletService) GetProjectWallets(ctx context.Context, projectID uuid.UUID, userID uuid.UUID) (_ []types.Wallet, err error) {
+	defer s.operation("GetProjectWallets", userID, uuid.Nil,
+		zap.String("project_id", projectID.String())).End(&err)
 	return s.repo.GetProjectWallets(ctx, projectID, userID)
 }
 
-func (s *walletService) SearchWallets(ctx context.Context, userID uuid.UUID, name string, limit int32) ([]types.Wallet, error) {
-	s.logger.Info("searching wallets",
-		zap.String("user_id", userID.String()),
+// AttachWalletsToProject moves the wallets into the project, skipping the ones already
+// in it. Both the project and every wallet must belong to the user. Projects don't
+// carry a currency, so wallets of any currency can share one.
+func (s *walletService) AttachWalletsToProject(ctx context.Context, userID uuid.UUID, payload types.WalletAttachPayload) (_ types.WalletAttachResult, err error) {
+	op := s.operation("AttachWalletsToProject", userID, uuid.Nil,
+		zap.String("project_id", payload.ProjectID.String()))
+	defer op.End(&err)
+
+	exists, err := s.repo.ProjectExists(ctx, userID, payload.ProjectID)
+	if err != nil {
+		return types.WalletAttachResult{}, err
+	}
+	if !exists {
+		return types.WalletAttachResult{}, errors.NewNotFoundError("project %s not found", payload.ProjectID)
+	}
+
+	walletIDs := slices.Clone(payload.WalletIDs)
+	slices.SortFunc(walletIDs, func(a, b uuid.UUID) int { return bytes.Compare(a[:], b[:]) })
+	walletIDs = slices.Compact(walletIDs)
+
+	owned, err := s.repo.CountOwnedWallets(ctx, userID, walletIDs)
+	if err != nil {
+		return types.WalletAttachResult{}, err
+	}
+	if owned != int64(len(walletIDs)) {
+		return types.WalletAttachResult{}, errors.NewValidationError("%d of the wallets were not found", int64(len(walletIDs))-owned)
+	}
+
+	attached, err := s.repo.AttachWalletsToProject(ctx, userID, payload.ProjectID, walletIDs)
+	if err != nil {
+		return types.WalletAttachResult{}, err
+	}
+
+	op.With(zap.Int("requested", len(walletIDs)), zap.Int64("attached", attached))
+	return types.WalletAttachResult{Attached: attached}, nil
+}
+
+func (s *walletService) SearchWallets(ctx context.Context, userID uuid.UUID, name string, limit, offset int32) (_ []types.Wallet, err error) {
+	defer s.operation("SearchWallets", userID, uuid.Nil,
 		zap.String("query", name),
-		zap.Int32("limit", limit))
+		zap.Int32("limit", limit),
+		zap.Int32("offset", offset)).End(&err)
 
 	if limit <= 0 {
 		return nil, fmt.Errorf("limit must be positive")
 	}
 
-	return s.repo.SearchWallets(ctx, userID, name, limit)
+	key := fmt.Sprintf("%s:wallets:name:%q:%d:%d", userID, name, limit, offset)
+	return cache.Coalesce(ctx, &s.searches, key, func(ctx context.Context) ([]types.Wallet, error) {
+		return s.repo.SearchWallets(ctx, userID, name, limit, offset)
+	}, cloneWallets)
+}
+
+// cloneWallets copies the wallets of a coalesced search for one of its callers
+func cloneWallets(wallets []types.Wallet) []types.Wallet {
+	cloned := slices.Clone(wallets)
+	for i := range cloned {
+		cloned[i].Tags = slices.Clone(cloned[i].Tags)
+	}
+	return cloned
+}
+
+func (s *walletService) SearchWalletsPicker(ctx context.Context, userID uuid.UUID, name string, limit, offset int32) (_ []types.WalletPickerItem, err error) {
+	defer s.operation("SearchWalletsPicker", userID, uuid.Nil,
+		zap.String("query", name),
+		zap.Int32("limit", limit),
+		zap.Int32("offset", offset)).End(&err)
+
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+
+	key := fmt.Sprintf("%s:wallets:picker:name:%q:%d:%d", userID, name, limit, offset)
+	return cache.Coalesce(ctx, &s.searches, key, func(ctx context.Context) ([]types.WalletPickerItem, error) {
+		return s.repo.SearchWalletsPicker(ctx, userID, name, limit, offset)
+	}, slices.Clone)
+}
+
+func (s *walletService) ListLowBalanceWallets(ctx context.Context, userID uuid.UUID) (_ []types.Wallet, err error) {
+	defer s.operation("ListLowBalanceWallets", userID, uuid.Nil).End(&err)
+	return s.repo.ListLowBalanceWallets(ctx, userID)
+}
+
+func (s *walletService) ListFacets(ctx context.Context, userID uuid.UUID, field string) (_ []coreTypes.Facet, err error) {
+	defer s.operation("ListFacets", userID, uuid.Nil, zap.String("field", field)).End(&err)
+	return s.repo.ListFacets(ctx, userID, field)
 }
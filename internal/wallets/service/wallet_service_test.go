@@ -3,14 +3,29 @@ package service
 import (
 	"context"
 	"errors"
+	"math"
 	"testing"
 	"time"
 
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/currency"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/deletion"
+	coreErrors "github.com/Abdelrahman-habib/expense-tracker/internal/core/errors"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/logging"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/quota"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/db"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/events"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/validate"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/repository"
 	"github.com/Abdelrahman-habib/expense-tracker/internal/wallets/types"
+	requestcontext "github.com/Abdelrahman-habib/expense-tracker/pkg/context"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 // Mock repository
@@ -18,6 +33,11 @@ type mockWalletRepository struct {
 	mock.Mock
 }
 
+func (m *mockWalletRepository) GetWalletsByIDs(ctx context.Context, userID uuid.UUID, walletIDs []uuid.UUID) ([]types.Wallet, []uuid.UUID, error) {
+	args := m.Called(ctx, userID, walletIDs)
+	return args.Get(0).([]types.Wallet), args.Get(1).([]uuid.UUID), args.Error(2)
+}
+
 func (m *mockWalletRepository) GetWallet(ctx context.Context, walletID, userID uuid.UUID) (types.Wallet, error) {
 	args := m.Called(ctx, walletID, userID)
 	return args.Get(0).(types.Wallet), args.Error(1)
@@ -28,40 +48,176 @@ func (m *mockWalletRepository) ListWallets(ctx context.Context, userID uuid.UUID
 	return args.Get(0).([]types.Wallet), args.Error(1)
 }
 
-func (m *mockWalletRepository) ListWalletsPaginated(ctx context.Context, userID uuid.UUID, createdAt time.Time, walletID uuid.UUID, limit int32) ([]types.Wallet, error) {
-	args := m.Called(ctx, userID, createdAt, walletID, limit)
+func (m *mockWalletRepository) ListWalletsPaginated(ctx context.Context, userID uuid.UUID, createdAt time.Time, walletID uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.WalletFilter) ([]types.Wallet, error) {
+	args := m.Called(ctx, userID, createdAt, walletID, limit, order, filter)
+	return args.Get(0).([]types.Wallet), args.Error(1)
+}
+
+func (m *mockWalletRepository) ListPinnedWalletsPaginated(ctx context.Context, userID uuid.UUID, pinnedAt time.Time, walletID uuid.UUID, limit int32, filter types.WalletFilter) ([]types.Wallet, error) {
+	args := m.Called(ctx, userID, pinnedAt, walletID, limit, filter)
 	return args.Get(0).([]types.Wallet), args.Error(1)
 }
 
+func (m *mockWalletRepository) ListWalletIDsPaginated(ctx context.Context, userID uuid.UUID, createdAt time.Time, walletID uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.WalletFilter) ([]coreTypes.KeyedID, error) {
+	args := m.Called(ctx, userID, createdAt, walletID, limit, order, filter)
+	return args.Get(0).([]coreTypes.KeyedID), args.Error(1)
+}
+
+func (m *mockWalletRepository) ListPinnedWalletIDsPaginated(ctx context.Context, userID uuid.UUID, pinnedAt time.Time, walletID uuid.UUID, limit int32, filter types.WalletFilter) ([]coreTypes.KeyedID, error) {
+	args := m.Called(ctx, userID, pinnedAt, walletID, limit, filter)
+	return args.Get(0).([]coreTypes.KeyedID), args.Error(1)
+}
+
+func (m *mockWalletRepository) ListWalletsPaginatedStream(ctx context.Context, userID uuid.UUID, createdAt time.Time, walletID uuid.UUID, limit int32, order coreTypes.SortOrder, filter types.WalletFilter, fn func(types.Wallet) error) error {
+	args := m.Called(ctx, userID, createdAt, walletID, limit, order, filter)
+	return streamWallets(args.Get(0).([]types.Wallet), args.Error(1), fn)
+}
+
+func (m *mockWalletRepository) ListPinnedWalletsPaginatedStream(ctx context.Context, userID uuid.UUID, pinnedAt time.Time, walletID uuid.UUID, limit int32, filter types.WalletFilter, fn func(types.Wallet) error) error {
+	args := m.Called(ctx, userID, pinnedAt, walletID, limit, filter)
+	return streamWallets(args.Get(0).([]types.Wallet), args.Error(1), fn)
+}
+
+// streamWallets hands wallets to fn and then returns err, the way a stream failing
+// after its rows does
+func streamWallets(wallets []types.Wallet, err error, fn func(types.Wallet) error) error {
+	for _, wallet := range wallets {
+		if err := fn(wallet); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+func (m *mockWalletRepository) SetWalletPinned(ctx context.Context, walletID, userID uuid.UUID, pinned bool) (types.Wallet, error) {
+	args := m.Called(ctx, walletID, userID, pinned)
+	return args.Get(0).(types.Wallet), args.Error(1)
+}
+
 func (m *mockWalletRepository) CreateWallet(ctx context.Context, payload types.WalletCreatePayload, userID uuid.UUID) (types.Wallet, error) {
 	args := m.Called(ctx, payload, userID)
 	return args.Get(0).(types.Wallet), args.Error(1)
 }
 
+// UpdateWalletLocked merges onto a wallet holding only its IDs, the update is then
+// matched on the merged payload
+func (m *mockWalletRepository) UpdateWalletLocked(ctx context.Context, walletID, userID uuid.UUID, merge func(types.Wallet) (types.WalletUpdatePayload, error)) (types.Wallet, error) {
+	payload, err := merge(types.Wallet{WalletID: walletID, UserID: userID})
+	if err != nil {
+		return types.Wallet{}, err
+	}
+	return m.UpdateWallet(ctx, payload, userID)
+}
+
 func (m *mockWalletRepository) UpdateWallet(ctx context.Context, payload types.WalletUpdatePayload, userID uuid.UUID) (types.Wallet, error) {
 	args := m.Called(ctx, payload, userID)
 	return args.Get(0).(types.Wallet), args.Error(1)
 }
 
-func (m *mockWalletRepository) DeleteWallet(ctx context.Context, walletID, userID uuid.UUID) error {
-	args := m.Called(ctx, walletID, userID)
+func (m *mockWalletRepository) DeleteWallet(ctx context.Context, walletID, userID uuid.UUID, version *time.Time) error {
+	args := m.Called(ctx, walletID, userID, version)
 	return args.Error(0)
 }
 
+func (m *mockWalletRepository) PurgeWallet(ctx context.Context, walletID, userID uuid.UUID, version *time.Time) (bool, error) {
+	args := m.Called(ctx, walletID, userID, version)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockWalletRepository) ListDeletedWalletsPaginated(ctx context.Context, userID uuid.UUID, deletedAt time.Time, walletID uuid.UUID, limit int32, order coreTypes.SortOrder) ([]types.Wallet, error) {
+	args := m.Called(ctx, userID, deletedAt, walletID, limit, order)
+	return args.Get(0).([]types.Wallet), args.Error(1)
+}
+
+func (m *mockWalletRepository) RestoreWallet(ctx context.Context, walletID, userID uuid.UUID) (types.Wallet, error) {
+	args := m.Called(ctx, walletID, userID)
+	return args.Get(0).(types.Wallet), args.Error(1)
+}
+
 func (m *mockWalletRepository) GetProjectWallets(ctx context.Context, projectID uuid.UUID, userID uuid.UUID) ([]types.Wallet, error) {
 	args := m.Called(ctx, projectID, userID)
 	return args.Get(0).([]types.Wallet), args.Error(1)
 }
 
-func (m *mockWalletRepository) SearchWallets(ctx context.Context, userID uuid.UUID, name string, limit int32) ([]types.Wallet, error) {
-	args := m.Called(ctx, userID, name, limit)
+func (m *mockWalletRepository) SearchWallets(ctx context.Context, userID uuid.UUID, name string, limit, offset int32) ([]types.Wallet, error) {
+	args := m.Called(ctx, userID, name, limit, offset)
 	return args.Get(0).([]types.Wallet), args.Error(1)
 }
 
+func (m *mockWalletRepository) SearchWalletsPicker(ctx context.Context, userID uuid.UUID, name string, limit, offset int32) ([]types.WalletPickerItem, error) {
+	args := m.Called(ctx, userID, name, limit, offset)
+	return args.Get(0).([]types.WalletPickerItem), args.Error(1)
+}
+
+func (m *mockWalletRepository) ListLowBalanceWallets(ctx context.Context, userID uuid.UUID) ([]types.Wallet, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]types.Wallet), args.Error(1)
+}
+
+func (m *mockWalletRepository) SumBalancesByCurrency(ctx context.Context, userID uuid.UUID, includeDeleted bool) ([]types.CurrencyTotal, error) {
+	args := m.Called(ctx, userID, includeDeleted)
+	return args.Get(0).([]types.CurrencyTotal), args.Error(1)
+}
+
+func (m *mockWalletRepository) ListFacets(ctx context.Context, userID uuid.UUID, field string) ([]coreTypes.Facet, error) {
+	args := m.Called(ctx, userID, field)
+	return args.Get(0).([]coreTypes.Facet), args.Error(1)
+}
+
+func (m *mockWalletRepository) ListWalletProjects(ctx context.Context, userID uuid.UUID, walletIDs []uuid.UUID) (map[uuid.UUID]types.WalletProject, error) {
+	args := m.Called(ctx, userID, walletIDs)
+	return args.Get(0).(map[uuid.UUID]types.WalletProject), args.Error(1)
+}
+
+func (m *mockWalletRepository) WalletGroupExists(ctx context.Context, userID, groupID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, userID, groupID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockWalletRepository) AttachWalletsToProject(ctx context.Context, userID, projectID uuid.UUID, walletIDs []uuid.UUID) (int64, error) {
+	args := m.Called(ctx, userID, projectID, walletIDs)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockWalletRepository) CountOwnedWallets(ctx context.Context, userID uuid.UUID, walletIDs []uuid.UUID) (int64, error) {
+	args := m.Called(ctx, userID, walletIDs)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockWalletRepository) WalletNameExists(ctx context.Context, userID uuid.UUID, name string) (bool, error) {
+	args := m.Called(ctx, userID, name)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockWalletRepository) ProjectExists(ctx context.Context, userID, projectID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, userID, projectID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockWalletRepository) GetLedgerBalance(ctx context.Context, walletID, userID uuid.UUID, before time.Time) (float64, error) {
+	args := m.Called(ctx, walletID, userID, before)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *mockWalletRepository) ListLedgerEntries(ctx context.Context, walletID, userID uuid.UUID, before, afterOccurredAt time.Time, afterSeq int64, limit int32) ([]types.LedgerEntry, error) {
+	args := m.Called(ctx, walletID, userID, before, afterOccurredAt, afterSeq, limit)
+	return args.Get(0).([]types.LedgerEntry), args.Error(1)
+}
+
+func (m *mockWalletRepository) GetLedgerStats(ctx context.Context, walletID, userID uuid.UUID, today time.Time) (types.WalletStats, error) {
+	args := m.Called(ctx, walletID, userID, today)
+	return args.Get(0).(types.WalletStats), args.Error(1)
+}
+
+func (m *mockWalletRepository) CountLedgerEntries(ctx context.Context, walletID, userID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, walletID, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func setupTest(t *testing.T) (*mockWalletRepository, WalletService) {
 	mockRepo := new(mockWalletRepository)
 	logger := zap.NewNop()
-	service := NewWalletService(mockRepo, logger)
+	service := NewWalletService(mockRepo, validate.RoundHalfUp, nil, nil, nil, logger)
 	return mockRepo, service
 }
 
@@ -69,6 +225,7 @@ func TestWalletService_CreateWallet(t *testing.T) {
 	mockRepo, service := setupTest(t)
 	ctx := context.Background()
 	userID := uuid.New()
+	groupID := uuid.New()
 
 	tests := []struct {
 		name    string
@@ -84,6 +241,7 @@ func TestWalletService_CreateWallet(t *testing.T) {
 				Currency: "USD",
 			},
 			mock: func() {
+				mockRepo.On("WalletNameExists", ctx, userID, "New Wallet").Return(false, nil)
 				mockRepo.On("CreateWallet", ctx, mock.AnythingOfType("types.WalletCreatePayload"), userID).
 					Return(types.Wallet{Name: "New Wallet"}, nil)
 			},
@@ -120,6 +278,17 @@ func TestWalletService_CreateWallet(t *testing.T) {
 			wantErr: true,
 			errMsg:  "balance cannot be negative",
 		},
+		{
+			name: "negative low balance threshold",
+			payload: types.WalletCreatePayload{
+				Name:                "Test Wallet",
+				Currency:            "USD",
+				LowBalanceThreshold: float64Ptr(-1),
+			},
+			mock:    func() {},
+			wantErr: true,
+			errMsg:  "low balance threshold cannot be negative",
+		},
 		{
 			name: "too many tags",
 			payload: types.WalletCreatePayload{
@@ -131,6 +300,34 @@ func TestWalletService_CreateWallet(t *testing.T) {
 			wantErr: true,
 			errMsg:  "number of tags exceeds maximum allowed",
 		},
+		{
+			name: "grouped wallet",
+			payload: types.WalletCreatePayload{
+				Name:     "Grouped Wallet",
+				Currency: "USD",
+				GroupID:  &groupID,
+			},
+			mock: func() {
+				mockRepo.On("WalletGroupExists", ctx, userID, groupID).Return(true, nil)
+				mockRepo.On("WalletNameExists", ctx, userID, "Grouped Wallet").Return(false, nil)
+				mockRepo.On("CreateWallet", ctx, mock.AnythingOfType("types.WalletCreatePayload"), userID).
+					Return(types.Wallet{Name: "Grouped Wallet", GroupID: &groupID}, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "group of another user",
+			payload: types.WalletCreatePayload{
+				Name:     "Test Wallet",
+				Currency: "USD",
+				GroupID:  &groupID,
+			},
+			mock: func() {
+				mockRepo.On("WalletGroupExists", ctx, userID, groupID).Return(false, nil)
+			},
+			wantErr: true,
+			errMsg:  "wallet group " + groupID.String() + " not found",
+		},
 	}
 
 	for _, tt := range tests {
@@ -152,6 +349,149 @@ func TestWalletService_CreateWallet(t *testing.T) {
 	}
 }
 
+func TestWalletService_CreateWallet_DuplicateName(t *testing.T) {
+	userID := uuid.New()
+	payload := types.WalletCreatePayload{Name: "Savings", Currency: "USD"}
+
+	t.Run("warned about", func(t *testing.T) {
+		mockRepo, service := setupTest(t)
+		ctx := requestcontext.WithWarnings(context.Background())
+		mockRepo.On("WalletNameExists", ctx, userID, "Savings").Return(true, nil)
+		mockRepo.On("CreateWallet", ctx, payload, userID).Return(types.Wallet{Name: "Savings"}, nil)
+
+		wallet, err := service.CreateWallet(ctx, payload, userID)
+		require.NoError(t, err)
+		assert.Equal(t, "Savings", wallet.Name)
+		assert.Equal(t, []string{`name: a wallet named "Savings" already exists`}, requestcontext.GetWarningsFromContext(ctx))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("unique name", func(t *testing.T) {
+		mockRepo, service := setupTest(t)
+		ctx := requestcontext.WithWarnings(context.Background())
+		mockRepo.On("WalletNameExists", ctx, userID, "Savings").Return(false, nil)
+		mockRepo.On("CreateWallet", ctx, payload, userID).Return(types.Wallet{Name: "Savings"}, nil)
+
+		_, err := service.CreateWallet(ctx, payload, userID)
+		require.NoError(t, err)
+		assert.Empty(t, requestcontext.GetWarningsFromContext(ctx))
+	})
+
+	t.Run("lookup failure", func(t *testing.T) {
+		mockRepo, service := setupTest(t)
+		ctx := context.Background()
+		mockRepo.On("WalletNameExists", ctx, userID, "Savings").Return(false, assert.AnError)
+
+		_, err := service.CreateWallet(ctx, payload, userID)
+		assert.ErrorIs(t, err, assert.AnError)
+		mockRepo.AssertNotCalled(t, "CreateWallet", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestWalletService_RoundsBalance(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	tests := []struct {
+		name     string
+		currency string
+		balance  float64
+		halfUp   float64
+		halfEven float64
+	}{
+		{name: "USD to cents", currency: "USD", balance: 100.555, halfUp: 100.56, halfEven: 100.56},
+		{name: "USD half to even cent", currency: "USD", balance: 100.545, halfUp: 100.55, halfEven: 100.54},
+		{name: "USD above half", currency: "usd", balance: 0.12501, halfUp: 0.13, halfEven: 0.13},
+		{name: "USD already clean", currency: "USD", balance: 42.5, halfUp: 42.5, halfEven: 42.5},
+		{name: "JPY to whole yen", currency: "JPY", balance: 1234.5, halfUp: 1235, halfEven: 1234},
+		{name: "JPY below half", currency: "JPY", balance: 99.49, halfUp: 99, halfEven: 99},
+		{name: "KWD to fils", currency: "KWD", balance: 1.2345, halfUp: 1.235, halfEven: 1.234},
+		{name: "KWD keeps three decimals", currency: "KWD", balance: 12.125, halfUp: 12.125, halfEven: 12.125},
+	}
+
+	for _, mode := range []validate.RoundingMode{validate.RoundHalfUp, validate.RoundHalfEven} {
+		for _, tt := range tests {
+			t.Run(string(mode)+"/"+tt.name, func(t *testing.T) {
+				mockRepo := new(mockWalletRepository)
+				service := NewWalletService(mockRepo, mode, nil, nil, nil, zap.NewNop())
+
+				want := tt.halfUp
+				if mode == validate.RoundHalfEven {
+					want = tt.halfEven
+				}
+
+				mockRepo.On("WalletNameExists", ctx, userID, "Wallet").Return(false, nil)
+				mockRepo.On("CreateWallet", ctx, mock.MatchedBy(func(p types.WalletCreatePayload) bool {
+					return p.Balance != nil && *p.Balance == want
+				}), userID).Return(types.Wallet{}, nil)
+				mockRepo.On("UpdateWallet", ctx, mock.MatchedBy(func(p types.WalletUpdatePayload) bool {
+					return p.Balance != nil && *p.Balance == want
+				}), userID).Return(types.Wallet{}, nil)
+
+				_, err := service.CreateWallet(ctx, types.WalletCreatePayload{Name: "Wallet", Currency: tt.currency, Balance: float64Ptr(tt.balance)}, userID)
+				require.NoError(t, err)
+				_, err = service.UpdateWallet(ctx, uuid.New(), userID, func(existing types.Wallet) (types.WalletUpdatePayload, error) {
+					return types.WalletUpdatePayload{WalletID: existing.WalletID, Name: "Wallet", Currency: tt.currency, Balance: float64Ptr(tt.balance)}, nil
+				})
+				require.NoError(t, err)
+				mockRepo.AssertExpectations(t)
+			})
+		}
+	}
+
+	t.Run("leaves a missing balance alone", func(t *testing.T) {
+		mockRepo, service := setupTest(t)
+		mockRepo.On("WalletNameExists", ctx, userID, "Wallet").Return(false, nil)
+		mockRepo.On("CreateWallet", ctx, types.WalletCreatePayload{Name: "Wallet", Currency: "USD"}, userID).Return(types.Wallet{}, nil)
+
+		_, err := service.CreateWallet(ctx, types.WalletCreatePayload{Name: "Wallet", Currency: "USD"}, userID)
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+// quotaCounter reports a fixed count of wallets
+type quotaCounter int32
+
+func (c quotaCounter) GetUserResourceCounts(ctx context.Context, userID uuid.UUID) (db.GetUserResourceCountsRow, error) {
+	return db.GetUserResourceCountsRow{WalletCount: int32(c)}, nil
+}
+
+func TestWalletService_Quota(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	payload := types.WalletCreatePayload{Name: "Wallet", Currency: "USD"}
+
+	t.Run("create within the quota", func(t *testing.T) {
+		mockRepo := new(mockWalletRepository)
+		service := NewWalletService(mockRepo, validate.RoundHalfUp, nil, quota.NewChecker(quotaCounter(2), quota.Limits{Wallets: 3}), nil, zap.NewNop())
+		mockRepo.On("WalletNameExists", ctx, userID, payload.Name).Return(false, nil)
+		mockRepo.On("CreateWallet", ctx, payload, userID).Return(types.Wallet{}, nil)
+
+		_, err := service.CreateWallet(ctx, payload, userID)
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("create past the quota", func(t *testing.T) {
+		mockRepo := new(mockWalletRepository)
+		service := NewWalletService(mockRepo, validate.RoundHalfUp, nil, quota.NewChecker(quotaCounter(3), quota.Limits{Wallets: 3}), nil, zap.NewNop())
+
+		_, err := service.CreateWallet(ctx, payload, userID)
+		assert.True(t, coreErrors.IsErrorType(err, coreErrors.ErrorTypeQuotaExceeded))
+		mockRepo.AssertNotCalled(t, "CreateWallet", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("restore past the quota", func(t *testing.T) {
+		mockRepo := new(mockWalletRepository)
+		service := NewWalletService(mockRepo, validate.RoundHalfUp, nil, quota.NewChecker(quotaCounter(3), quota.Limits{Wallets: 3}), nil, zap.NewNop())
+
+		_, err := service.RestoreWallet(ctx, uuid.New(), userID)
+		assert.True(t, coreErrors.IsErrorType(err, coreErrors.ErrorTypeQuotaExceeded))
+		mockRepo.AssertNotCalled(t, "RestoreWallet", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
 func TestWalletService_GetWallet(t *testing.T) {
 	mockRepo, service := setupTest(t)
 	ctx := context.Background()
@@ -202,6 +542,61 @@ func TestWalletService_GetWallet(t *testing.T) {
 	}
 }
 
+func TestWalletService_GetWalletWithStats(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	walletID := uuid.New()
+	isToday := mock.MatchedBy(func(today time.Time) bool {
+		return today.Equal(time.Now().UTC().Truncate(24*time.Hour)) && today.Location() == time.UTC
+	})
+
+	t.Run("averages the spending of the last 30 days", func(t *testing.T) {
+		mockRepo, service := setupTest(t)
+		mockRepo.On("GetWallet", ctx, walletID, userID).Return(types.Wallet{WalletID: walletID, Currency: "EUR"}, nil)
+		mockRepo.On("GetLedgerStats", ctx, walletID, userID, isToday).Return(types.WalletStats{
+			Last7Days:  types.WalletFlow{Outflow: 80},
+			Last30Days: types.WalletFlow{Outflow: 340, Inflow: 1200},
+			Last90Days: types.WalletFlow{Outflow: 910, Inflow: 3600},
+		}, nil)
+
+		wallet, err := service.GetWalletWithStats(ctx, walletID, userID)
+		require.NoError(t, err)
+		require.NotNil(t, wallet.Stats)
+		assert.Equal(t, 340.0, wallet.Stats.Last30Days.Outflow)
+		assert.Equal(t, 11.33, wallet.Stats.AverageDailySpend)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rounds the average to the currency", func(t *testing.T) {
+		mockRepo, service := setupTest(t)
+		mockRepo.On("GetWallet", ctx, walletID, userID).Return(types.Wallet{WalletID: walletID, Currency: "JPY"}, nil)
+		mockRepo.On("GetLedgerStats", ctx, walletID, userID, isToday).Return(types.WalletStats{Last30Days: types.WalletFlow{Outflow: 10000}}, nil)
+
+		wallet, err := service.GetWalletWithStats(ctx, walletID, userID)
+		require.NoError(t, err)
+		assert.Equal(t, 333.0, wallet.Stats.AverageDailySpend)
+	})
+
+	t.Run("a wallet without a ledger has zeros", func(t *testing.T) {
+		mockRepo, service := setupTest(t)
+		mockRepo.On("GetWallet", ctx, walletID, userID).Return(types.Wallet{WalletID: walletID, Currency: "USD"}, nil)
+		mockRepo.On("GetLedgerStats", ctx, walletID, userID, isToday).Return(types.WalletStats{}, nil)
+
+		wallet, err := service.GetWalletWithStats(ctx, walletID, userID)
+		require.NoError(t, err)
+		assert.Equal(t, &types.WalletStats{}, wallet.Stats)
+	})
+
+	t.Run("missing wallet", func(t *testing.T) {
+		mockRepo, service := setupTest(t)
+		mockRepo.On("GetWallet", ctx, walletID, userID).Return(types.Wallet{}, coreErrors.NewNotFoundError("wallet not found"))
+
+		_, err := service.GetWalletWithStats(ctx, walletID, userID)
+		assert.True(t, coreErrors.IsErrorType(err, coreErrors.ErrorTypeNotFound))
+		mockRepo.AssertNotCalled(t, "GetLedgerStats", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
 func TestWalletService_ListWallets(t *testing.T) {
 	mockRepo, service := setupTest(t)
 	ctx := context.Background()
@@ -332,7 +727,9 @@ func TestWalletService_UpdateWallet(t *testing.T) {
 			mockRepo.ExpectedCalls = nil
 			tt.mock()
 
-			wallet, err := service.UpdateWallet(ctx, tt.payload, userID)
+			wallet, err := service.UpdateWallet(ctx, tt.payload.WalletID, userID, func(types.Wallet) (types.WalletUpdatePayload, error) {
+				return tt.payload, nil
+			})
 			if tt.wantErr {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), tt.errMsg)
@@ -373,16 +770,16 @@ func TestWalletService_ListWalletsPaginated(t *testing.T) {
 						WalletID:  uuid.New(),
 						Name:      "Wallet 1",
 						Currency:  "USD",
-						CreatedAt: now.Add(-1 * time.Hour),
+						CreatedAt: coreTypes.NewTimestamp(now.Add(-1 * time.Hour)),
 					},
 					{
 						WalletID:  uuid.New(),
 						Name:      "Wallet 2",
 						Currency:  "EUR",
-						CreatedAt: now.Add(-2 * time.Hour),
+						CreatedAt: coreTypes.NewTimestamp(now.Add(-2 * time.Hour)),
 					},
 				}
-				mockRepo.On("ListWalletsPaginated", ctx, userID, now, cursorID, int32(10)).
+				mockRepo.On("ListWalletsPaginated", ctx, userID, now, cursorID, int32(10), coreTypes.SortOrderDesc, types.WalletFilter{}).
 					Return(wallets, nil)
 			},
 			wantErr: false,
@@ -402,7 +799,7 @@ func TestWalletService_ListWalletsPaginated(t *testing.T) {
 			cursorID: cursorID,
 			limit:    10,
 			mock: func() {
-				mockRepo.On("ListWalletsPaginated", ctx, userID, now, cursorID, int32(10)).
+				mockRepo.On("ListWalletsPaginated", ctx, userID, now, cursorID, int32(10), coreTypes.SortOrderDesc, types.WalletFilter{}).
 					Return([]types.Wallet{}, nil)
 			},
 			wantErr: false,
@@ -415,7 +812,7 @@ func TestWalletService_ListWalletsPaginated(t *testing.T) {
 			mockRepo.ExpectedCalls = nil
 			tt.mock()
 
-			wallets, err := service.ListWalletsPaginated(ctx, userID, tt.cursor, tt.cursorID, tt.limit)
+			wallets, err := service.ListWalletsPaginated(ctx, userID, tt.cursor, tt.cursorID, false, tt.limit, coreTypes.SortOrderDesc, types.WalletFilter{})
 			if tt.wantErr {
 				assert.Error(t, err)
 				return
@@ -428,6 +825,162 @@ func TestWalletService_ListWalletsPaginated(t *testing.T) {
 	}
 }
 
+func TestWalletService_ListWalletsPaginated_Pinned(t *testing.T) {
+	mockRepo, service := setupTest(t)
+	ctx := context.Background()
+	userID := uuid.New()
+	filter := types.WalletFilter{Ungrouped: true}
+	start, startID := coreTypes.StartPinnedCursor()
+	pinned := []types.Wallet{{WalletID: uuid.New(), Name: "Pinned 1", Pinned: true}, {WalletID: uuid.New(), Name: "Pinned 2", Pinned: true}}
+	unpinned := []types.Wallet{{WalletID: uuid.New(), Name: "Unpinned"}}
+
+	t.Run("page within the pinned wallets", func(t *testing.T) {
+		mockRepo.ExpectedCalls = nil
+		mockRepo.Calls = nil
+		mockRepo.On("ListPinnedWalletsPaginated", ctx, userID, start, startID, int32(2), filter).Return(pinned, nil)
+
+		wallets, err := service.ListWalletsPaginated(ctx, userID, start, startID, true, 2, coreTypes.SortOrderAsc, filter)
+		assert.NoError(t, err)
+		assert.Equal(t, pinned, wallets)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "ListWalletsPaginated")
+	})
+
+	t.Run("page continuing with the unpinned wallets", func(t *testing.T) {
+		mockRepo.ExpectedCalls = nil
+		mockRepo.On("ListPinnedWalletsPaginated", ctx, userID, start, startID, int32(5), filter).Return(pinned, nil)
+		unpinnedStart, unpinnedStartID := coreTypes.StartCursor(coreTypes.SortOrderAsc)
+		mockRepo.On("ListWalletsPaginated", ctx, userID, unpinnedStart, unpinnedStartID, int32(3), coreTypes.SortOrderAsc, filter).Return(unpinned, nil)
+
+		wallets, err := service.ListWalletsPaginated(ctx, userID, start, startID, true, 5, coreTypes.SortOrderAsc, filter)
+		assert.NoError(t, err)
+		assert.Equal(t, append(append([]types.Wallet{}, pinned...), unpinned...), wallets)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestWalletService_ListWalletIDsPaginated_Pinned(t *testing.T) {
+	mockRepo, service := setupTest(t)
+	ctx := context.Background()
+	userID := uuid.New()
+	filter := types.WalletFilter{}
+	start, startID := coreTypes.StartPinnedCursor()
+	pinnedAt := time.Now().UTC()
+	pinned := []coreTypes.KeyedID{{ID: uuid.New(), PinnedAt: &pinnedAt}, {ID: uuid.New(), PinnedAt: &pinnedAt}}
+	unpinned := []coreTypes.KeyedID{{ID: uuid.New(), CreatedAt: pinnedAt}}
+
+	t.Run("page within the pinned wallets", func(t *testing.T) {
+		mockRepo.ExpectedCalls = nil
+		mockRepo.Calls = nil
+		mockRepo.On("ListPinnedWalletIDsPaginated", ctx, userID, start, startID, int32(2), filter).Return(pinned, nil)
+
+		keys, err := service.ListWalletIDsPaginated(ctx, userID, start, startID, true, 2, coreTypes.SortOrderDesc, filter)
+		assert.NoError(t, err)
+		assert.Equal(t, pinned, keys)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "ListWalletIDsPaginated")
+	})
+
+	t.Run("page continuing with the unpinned wallets", func(t *testing.T) {
+		mockRepo.ExpectedCalls = nil
+		mockRepo.On("ListPinnedWalletIDsPaginated", ctx, userID, start, startID, int32(5), filter).Return(pinned, nil)
+		unpinnedStart, unpinnedStartID := coreTypes.StartCursor(coreTypes.SortOrderDesc)
+		mockRepo.On("ListWalletIDsPaginated", ctx, userID, unpinnedStart, unpinnedStartID, int32(3), coreTypes.SortOrderDesc, filter).Return(unpinned, nil)
+
+		keys, err := service.ListWalletIDsPaginated(ctx, userID, start, startID, true, 5, coreTypes.SortOrderDesc, filter)
+		assert.NoError(t, err)
+		assert.Equal(t, append(append([]coreTypes.KeyedID{}, pinned...), unpinned...), keys)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestWalletService_ExpandWalletProjects(t *testing.T) {
+	mockRepo, service := setupTest(t)
+	ctx := context.Background()
+	userID, projectID := uuid.New(), uuid.New()
+	inProject := types.Wallet{WalletID: uuid.New(), Name: "Materials", ProjectID: &projectID}
+	outside := types.Wallet{WalletID: uuid.New(), Name: "Savings"}
+
+	t.Run("embeds the projects fetched at once", func(t *testing.T) {
+		mockRepo.ExpectedCalls = nil
+		project := types.WalletProject{ProjectID: projectID, ProjectName: "Home renovation"}
+		mockRepo.On("ListWalletProjects", ctx, userID, []uuid.UUID{inProject.WalletID}).
+			Return(map[uuid.UUID]types.WalletProject{inProject.WalletID: project}, nil).Once()
+
+		expanded, err := service.ExpandWalletProjects(ctx, userID, []types.Wallet{inProject, outside})
+		assert.NoError(t, err)
+		assert.Equal(t, []types.WalletWithProject{
+			{Wallet: inProject, Project: &project},
+			{Wallet: outside},
+		}, expanded)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("skips the query without wallets in a project", func(t *testing.T) {
+		mockRepo.ExpectedCalls = nil
+		mockRepo.Calls = nil
+
+		expanded, err := service.ExpandWalletProjects(ctx, userID, []types.Wallet{outside})
+		assert.NoError(t, err)
+		assert.Equal(t, []types.WalletWithProject{{Wallet: outside}}, expanded)
+		mockRepo.AssertNotCalled(t, "ListWalletProjects")
+	})
+}
+
+func TestWalletService_PinWallet(t *testing.T) {
+	mockRepo, service := setupTest(t)
+	ctx := context.Background()
+	userID := uuid.New()
+	walletID := uuid.New()
+	pinnedAt := time.Now()
+
+	tests := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "pins the wallet",
+			mock: func() {
+				mockRepo.On("GetWallet", ctx, walletID, userID).Return(types.Wallet{WalletID: walletID}, nil)
+				mockRepo.On("SetWalletPinned", ctx, walletID, userID, true).Return(types.Wallet{WalletID: walletID, Pinned: true, PinnedAt: coreTypes.TimestampPtr(&pinnedAt)}, nil)
+			},
+		},
+		{
+			name: "already pinned",
+			mock: func() {
+				mockRepo.On("GetWallet", ctx, walletID, userID).Return(types.Wallet{WalletID: walletID, Pinned: true, PinnedAt: coreTypes.TimestampPtr(&pinnedAt)}, nil)
+			},
+		},
+		{
+			name: "cap reached",
+			mock: func() {
+				mockRepo.On("GetWallet", ctx, walletID, userID).Return(types.Wallet{WalletID: walletID}, nil)
+				mockRepo.On("SetWalletPinned", ctx, walletID, userID, true).Return(types.Wallet{}, repository.ErrPinnedWalletLimit)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.ExpectedCalls = nil
+			mockRepo.Calls = nil
+			tt.mock()
+
+			wallet, err := service.PinWallet(ctx, walletID, userID)
+			if tt.wantErr {
+				assert.True(t, coreErrors.IsErrorType(err, coreErrors.ErrorTypeValidation))
+				mockRepo.AssertExpectations(t)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, wallet.Pinned)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
 func TestWalletService_DeleteWallet(t *testing.T) {
 	mockRepo, service := setupTest(t)
 	ctx := context.Background()
@@ -442,14 +995,25 @@ func TestWalletService_DeleteWallet(t *testing.T) {
 		{
 			name: "successful delete",
 			mock: func() {
-				mockRepo.On("DeleteWallet", ctx, walletID, userID).Return(nil)
+				mockRepo.On("GetWallet", ctx, walletID, userID).Return(types.Wallet{WalletID: walletID, Currency: "USD"}, nil)
+				mockRepo.On("CountLedgerEntries", ctx, walletID, userID).Return(int64(3), nil)
+				mockRepo.On("DeleteWallet", ctx, walletID, userID, (*time.Time)(nil)).Return(nil)
 			},
 			wantErr: false,
 		},
 		{
 			name: "not found error",
 			mock: func() {
-				mockRepo.On("DeleteWallet", ctx, walletID, userID).Return(errors.New("not found"))
+				mockRepo.On("GetWallet", ctx, walletID, userID).Return(types.Wallet{}, coreErrors.NewNotFoundError("wallet not found"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "delete error",
+			mock: func() {
+				mockRepo.On("GetWallet", ctx, walletID, userID).Return(types.Wallet{WalletID: walletID, Currency: "USD"}, nil)
+				mockRepo.On("CountLedgerEntries", ctx, walletID, userID).Return(int64(0), nil)
+				mockRepo.On("DeleteWallet", ctx, walletID, userID, (*time.Time)(nil)).Return(errors.New("connection reset"))
 			},
 			wantErr: true,
 		},
@@ -458,11 +1022,13 @@ func TestWalletService_DeleteWallet(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockRepo.ExpectedCalls = nil
+			mockRepo.Calls = nil
 			tt.mock()
 
-			err := service.DeleteWallet(ctx, walletID, userID)
+			err := service.DeleteWallet(ctx, walletID, userID, nil)
 			if tt.wantErr {
 				assert.Error(t, err)
+				mockRepo.AssertExpectations(t)
 				return
 			}
 
@@ -472,6 +1038,80 @@ func TestWalletService_DeleteWallet(t *testing.T) {
 	}
 }
 
+func TestWalletService_PurgeWallet(t *testing.T) {
+	mockRepo := new(mockWalletRepository)
+	bus := events.NewBus(8, 8)
+	service := NewWalletService(mockRepo, validate.RoundHalfUp, nil, nil, bus, zap.NewNop())
+	ctx := context.Background()
+	userID := uuid.New()
+	walletID := uuid.New()
+
+	tests := []struct {
+		name        string
+		trashed     bool
+		repoErr     error
+		wantErr     bool
+		wantPublish bool
+	}{
+		{name: "active wallet is purged and its deletion published", wantPublish: true},
+		{name: "trashed wallet is purged without publishing again", trashed: true},
+		{name: "not found", repoErr: coreErrors.NewNotFoundError("wallet not found"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.ExpectedCalls = nil
+			mockRepo.Calls = nil
+			mockRepo.On("PurgeWallet", ctx, walletID, userID, (*time.Time)(nil)).Return(tt.trashed, tt.repoErr)
+
+			sub, _ := bus.Subscribe(userID, "")
+			defer sub.Close()
+
+			err := service.PurgeWallet(ctx, walletID, userID, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockRepo.AssertNotCalled(t, "DeleteWallet", mock.Anything, mock.Anything, mock.Anything)
+			mockRepo.AssertExpectations(t)
+
+			select {
+			case event := <-sub.Events():
+				require.True(t, tt.wantPublish, "unexpected event %+v", event)
+				assert.Equal(t, events.ActionDeleted, event.Action)
+				assert.Equal(t, walletID, event.EntityID)
+			default:
+				assert.False(t, tt.wantPublish, "deletion not published")
+			}
+		})
+	}
+}
+
+func TestWalletService_DeletionImpact(t *testing.T) {
+	mockRepo, service := setupTest(t)
+	ctx := context.Background()
+	userID := uuid.New()
+	walletID := uuid.New()
+	balance := 250.75
+
+	mockRepo.On("GetWallet", ctx, walletID, userID).Return(types.Wallet{WalletID: walletID, Currency: "EUR", Balance: &balance}, nil)
+	mockRepo.On("CountLedgerEntries", ctx, walletID, userID).Return(int64(4), nil)
+
+	impact, err := service.DeletionImpact(ctx, walletID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, deletion.Impact{
+		Resource: "wallet",
+		ID:       walletID,
+		Affected: []deletion.Affected{
+			{Kind: "wallets", Count: 1, Effect: deletion.EffectTrashed, Balances: []deletion.Balance{{Currency: "EUR", Balance: 250.75}}},
+			{Kind: "ledgerEntries", Count: 4, Effect: deletion.EffectKept},
+		},
+	}, impact)
+	assert.NoError(t, impact.Err())
+	mockRepo.AssertExpectations(t)
+}
+
 func TestWalletService_GetProjectWallets(t *testing.T) {
 	mockRepo, service := setupTest(t)
 	ctx := context.Background()
@@ -492,13 +1132,13 @@ func TestWalletService_GetProjectWallets(t *testing.T) {
 						WalletID:  uuid.New(),
 						Name:      "Project Wallet 1",
 						Currency:  "USD",
-						CreatedAt: time.Now(),
+						CreatedAt: coreTypes.NewTimestamp(time.Now()),
 					},
 					{
 						WalletID:  uuid.New(),
 						Name:      "Project Wallet 2",
 						Currency:  "EUR",
-						CreatedAt: time.Now(),
+						CreatedAt: coreTypes.NewTimestamp(time.Now()),
 					},
 				}
 				mockRepo.On("GetProjectWallets", ctx, projectID, userID).Return(wallets, nil)
@@ -542,6 +1182,96 @@ func TestWalletService_GetProjectWallets(t *testing.T) {
 	}
 }
 
+func TestWalletService_AttachWalletsToProject(t *testing.T) {
+	mockRepo, service := setupTest(t)
+	ctx := context.Background()
+	userID := uuid.New()
+	projectID := uuid.New()
+	walletID := uuid.New()
+	otherWalletID := uuid.New()
+
+	tests := []struct {
+		name      string
+		walletIDs []uuid.UUID
+		mock      func()
+		wantErr   bool
+		errMsg    string
+		want      int64
+	}{
+		{
+			name:      "attaches owned wallets",
+			walletIDs: []uuid.UUID{walletID, otherWalletID},
+			mock: func() {
+				mockRepo.On("ProjectExists", ctx, userID, projectID).Return(true, nil)
+				mockRepo.On("CountOwnedWallets", ctx, userID, mock.Anything).Return(int64(2), nil)
+				mockRepo.On("AttachWalletsToProject", ctx, userID, projectID, mock.Anything).Return(int64(1), nil)
+			},
+			want: 1,
+		},
+		{
+			name:      "duplicate IDs counted once",
+			walletIDs: []uuid.UUID{walletID, walletID},
+			mock: func() {
+				mockRepo.On("ProjectExists", ctx, userID, projectID).Return(true, nil)
+				mockRepo.On("CountOwnedWallets", ctx, userID, []uuid.UUID{walletID}).Return(int64(1), nil)
+				mockRepo.On("AttachWalletsToProject", ctx, userID, projectID, []uuid.UUID{walletID}).Return(int64(1), nil)
+			},
+			want: 1,
+		},
+		{
+			name:      "project not found",
+			walletIDs: []uuid.UUID{walletID},
+			mock: func() {
+				mockRepo.On("ProjectExists", ctx, userID, projectID).Return(false, nil)
+			},
+			wantErr: true,
+			errMsg:  "project " + projectID.String() + " not found",
+		},
+		{
+			name:      "wallet of another user",
+			walletIDs: []uuid.UUID{walletID, otherWalletID},
+			mock: func() {
+				mockRepo.On("ProjectExists", ctx, userID, projectID).Return(true, nil)
+				mockRepo.On("CountOwnedWallets", ctx, userID, mock.Anything).Return(int64(1), nil)
+			},
+			wantErr: true,
+			errMsg:  "1 of the wallets were not found",
+		},
+		{
+			name:      "repository error",
+			walletIDs: []uuid.UUID{walletID},
+			mock: func() {
+				mockRepo.On("ProjectExists", ctx, userID, projectID).Return(true, nil)
+				mockRepo.On("CountOwnedWallets", ctx, userID, mock.Anything).Return(int64(1), nil)
+				mockRepo.On("AttachWalletsToProject", ctx, userID, projectID, mock.Anything).Return(int64(0), errors.New("database error"))
+			},
+			wantErr: true,
+			errMsg:  "database error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.ExpectedCalls = nil
+			tt.mock()
+
+			result, err := service.AttachWalletsToProject(ctx, userID, types.WalletAttachPayload{
+				ProjectID: projectID,
+				WalletIDs: tt.walletIDs,
+			})
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, result.Attached)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
 func TestWalletService_SearchWallets(t *testing.T) {
 	mockRepo, service := setupTest(t)
 	ctx := context.Background()
@@ -566,16 +1296,16 @@ func TestWalletService_SearchWallets(t *testing.T) {
 						WalletID:  uuid.New(),
 						Name:      "Test Wallet 1",
 						Currency:  "USD",
-						CreatedAt: time.Now(),
+						CreatedAt: coreTypes.NewTimestamp(time.Now()),
 					},
 					{
 						WalletID:  uuid.New(),
 						Name:      "Test Wallet 2",
 						Currency:  "EUR",
-						CreatedAt: time.Now(),
+						CreatedAt: coreTypes.NewTimestamp(time.Now()),
 					},
 				}
-				mockRepo.On("SearchWallets", ctx, userID, "test", int32(10)).Return(wallets, nil)
+				mockRepo.On("SearchWallets", ctx, userID, "test", int32(10), int32(0)).Return(wallets, nil)
 			},
 			wantErr: false,
 			wantLen: 2,
@@ -593,7 +1323,7 @@ func TestWalletService_SearchWallets(t *testing.T) {
 			query: "nonexistent",
 			limit: 10,
 			mock: func() {
-				mockRepo.On("SearchWallets", ctx, userID, "nonexistent", int32(10)).Return([]types.Wallet{}, nil)
+				mockRepo.On("SearchWallets", ctx, userID, "nonexistent", int32(10), int32(0)).Return([]types.Wallet{}, nil)
 			},
 			wantErr: false,
 			wantLen: 0,
@@ -603,7 +1333,7 @@ func TestWalletService_SearchWallets(t *testing.T) {
 			query: "test",
 			limit: 10,
 			mock: func() {
-				mockRepo.On("SearchWallets", ctx, userID, "test", int32(10)).Return([]types.Wallet{}, errors.New("database error"))
+				mockRepo.On("SearchWallets", ctx, userID, "test", int32(10), int32(0)).Return([]types.Wallet{}, errors.New("database error"))
 			},
 			wantErr: true,
 			wantLen: 0,
@@ -615,7 +1345,7 @@ func TestWalletService_SearchWallets(t *testing.T) {
 			mockRepo.ExpectedCalls = nil
 			tt.mock()
 
-			wallets, err := service.SearchWallets(ctx, userID, tt.query, tt.limit)
+			wallets, err := service.SearchWallets(ctx, userID, tt.query, tt.limit, 0)
 			if tt.wantErr {
 				assert.Error(t, err)
 				if tt.errMsg != "" {
@@ -635,3 +1365,291 @@ func TestWalletService_SearchWallets(t *testing.T) {
 func float64Ptr(v float64) *float64 {
 	return &v
 }
+
+func TestWalletService_ListLowBalanceWallets(t *testing.T) {
+	mockRepo, service := setupTest(t)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	wallets := []types.Wallet{
+		{WalletID: uuid.New(), Name: "Groceries", Balance: float64Ptr(5), LowBalanceThreshold: float64Ptr(50)},
+	}
+	mockRepo.On("ListLowBalanceWallets", ctx, userID).Return(wallets, nil).Once()
+
+	result, err := service.ListLowBalanceWallets(ctx, userID)
+	assert.NoError(t, err)
+	assert.Equal(t, wallets, result)
+
+	mockRepo.On("ListLowBalanceWallets", ctx, userID).Return([]types.Wallet{}, errors.New("database error")).Once()
+	_, err = service.ListLowBalanceWallets(ctx, userID)
+	assert.Error(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWalletService_NetWorth(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	rates, err := currency.NewStaticRates("USD", map[string]float64{"EUR": 1.1, "JPY": 0.0067})
+	require.NoError(t, err)
+
+	totals := []types.CurrencyTotal{
+		{Currency: "EUR", Total: 1000.55, WalletCount: 2},
+		{Currency: "JPY", Total: 15000, WalletCount: 1},
+		{Currency: "USD", Total: 20, WalletCount: 1},
+	}
+
+	t.Run("totals by currency", func(t *testing.T) {
+		mockRepo, service := setupTest(t)
+		mockRepo.On("SumBalancesByCurrency", ctx, userID, true).Return(totals, nil).Once()
+
+		netWorth, err := service.NetWorth(ctx, userID, types.NetWorthParams{IncludeArchived: true})
+		require.NoError(t, err)
+		assert.Equal(t, totals, netWorth.Totals)
+		assert.Nil(t, netWorth.Converted)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("converted to a single currency", func(t *testing.T) {
+		mockRepo := new(mockWalletRepository)
+		service := NewWalletService(mockRepo, validate.RoundHalfUp, rates, nil, nil, zap.NewNop())
+		mockRepo.On("SumBalancesByCurrency", ctx, userID, false).Return(totals, nil).Once()
+
+		netWorth, err := service.NetWorth(ctx, userID, types.NetWorthParams{ConvertTo: "USD"})
+		require.NoError(t, err)
+		assert.Equal(t, totals, netWorth.Totals)
+		require.NotNil(t, netWorth.Converted)
+		// 1100.605 + 100.5 + 20, rounded to cents
+		assert.Equal(t, 1221.11, netWorth.Converted.Total)
+		assert.Equal(t, "USD", netWorth.Converted.Currency)
+		assert.InDeltaMapValues(t, map[string]float64{"EUR": 1.1, "JPY": 0.0067, "USD": 1}, netWorth.Converted.Rates, 1e-12)
+	})
+
+	t.Run("converted to a currency without decimals", func(t *testing.T) {
+		mockRepo := new(mockWalletRepository)
+		service := NewWalletService(mockRepo, validate.RoundHalfUp, rates, nil, nil, zap.NewNop())
+		mockRepo.On("SumBalancesByCurrency", ctx, userID, false).Return(totals[:1], nil).Once()
+
+		netWorth, err := service.NetWorth(ctx, userID, types.NetWorthParams{ConvertTo: "JPY"})
+		require.NoError(t, err)
+		// 1000.55 EUR is 164269.4 yen
+		assert.Equal(t, 164269.0, netWorth.Converted.Total)
+	})
+
+	t.Run("no wallets", func(t *testing.T) {
+		mockRepo := new(mockWalletRepository)
+		service := NewWalletService(mockRepo, validate.RoundHalfUp, rates, nil, nil, zap.NewNop())
+		mockRepo.On("SumBalancesByCurrency", ctx, userID, false).Return([]types.CurrencyTotal{}, nil).Once()
+
+		netWorth, err := service.NetWorth(ctx, userID, types.NetWorthParams{ConvertTo: "EUR"})
+		require.NoError(t, err)
+		assert.Empty(t, netWorth.Totals)
+		assert.Equal(t, 0.0, netWorth.Converted.Total)
+	})
+
+	t.Run("currency without a rate", func(t *testing.T) {
+		mockRepo := new(mockWalletRepository)
+		service := NewWalletService(mockRepo, validate.RoundHalfUp, rates, nil, nil, zap.NewNop())
+		mockRepo.On("SumBalancesByCurrency", ctx, userID, false).Return(append(totals, types.CurrencyTotal{Currency: "GBP", Total: 5, WalletCount: 1}), nil).Once()
+
+		_, err := service.NetWorth(ctx, userID, types.NetWorthParams{ConvertTo: "USD"})
+		assert.True(t, coreErrors.IsErrorType(err, coreErrors.ErrorTypeValidation))
+		assert.Contains(t, err.Error(), "no exchange rate from GBP to USD")
+	})
+
+	t.Run("conversion without rates", func(t *testing.T) {
+		mockRepo, service := setupTest(t)
+
+		_, err := service.NetWorth(ctx, userID, types.NetWorthParams{ConvertTo: "USD"})
+		assert.True(t, coreErrors.IsErrorType(err, coreErrors.ErrorTypeValidation))
+		mockRepo.AssertNotCalled(t, "SumBalancesByCurrency", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestWalletService_ExportStatement(t *testing.T) {
+	ctx := context.Background()
+	userID, walletID := uuid.New(), uuid.New()
+	params := types.StatementParams{
+		From:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:     time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+		Format: types.StatementFormatISO,
+	}
+	noon := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	defer func(size int32) { statementBatchSize = size }(statementBatchSize)
+	statementBatchSize = 2
+
+	t.Run("runs the balance across batches and same-timestamp entries", func(t *testing.T) {
+		mockRepo, service := setupTest(t)
+
+		// the first batch ends on an entry sharing its timestamp with the next batch
+		first := []types.LedgerEntry{
+			{Seq: 4, Amount: -20.10, Description: "Groceries", OccurredAt: params.From},
+			{Seq: 7, Amount: 0.30, Description: "Refund", OccurredAt: noon},
+		}
+		second := []types.LedgerEntry{
+			{Seq: 8, Amount: -0.10, Description: "Fee", OccurredAt: noon},
+			{Seq: 9, Amount: 50, Description: "Top up", OccurredAt: noon},
+		}
+		third := []types.LedgerEntry{
+			{Seq: 12, Amount: -130.30, Description: "Rent", OccurredAt: noon.AddDate(0, 0, 5)},
+		}
+
+		mockRepo.On("GetWallet", ctx, walletID, userID).Return(types.Wallet{WalletID: walletID, Currency: "EUR"}, nil)
+		mockRepo.On("GetLedgerBalance", ctx, walletID, userID, params.From).Return(100.0, nil)
+		mockRepo.On("ListLedgerEntries", ctx, walletID, userID, params.End(), params.From, int64(0), int32(2)).Return(first, nil)
+		mockRepo.On("ListLedgerEntries", ctx, walletID, userID, params.End(), noon, int64(7), int32(2)).Return(second, nil)
+		mockRepo.On("ListLedgerEntries", ctx, walletID, userID, params.End(), noon, int64(9), int32(2)).Return(third, nil)
+
+		var rows []types.StatementRow
+		err := service.ExportStatement(ctx, walletID, userID, params, func(row types.StatementRow) error {
+			rows = append(rows, row)
+			return nil
+		})
+		assert.NoError(t, err)
+
+		balances := make([]float64, len(rows))
+		for i, row := range rows {
+			balances[i] = row.Balance
+		}
+		// 0.1 + 0.2 style float drift would show up as 80.19999...
+		assert.Equal(t, []float64{79.9, 80.2, 80.1, 130.1, -0.2, -0.2}, balances)
+		assert.Equal(t, types.StatementRow{Date: coreTypes.NewTimestamp(params.From), Description: "Groceries", Debit: 20.1, Balance: 79.9, Currency: "EUR"}, rows[0])
+		assert.Equal(t, 50.0, rows[3].Credit)
+		assert.Equal(t, types.StatementRow{
+			Date: coreTypes.NewTimestamp(params.To), Description: "Closing balance",
+			Debit: 150.5, Credit: 50.3, Balance: -0.2, Currency: "EUR", Summary: true,
+		}, rows[5])
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("empty range only has the summary", func(t *testing.T) {
+		mockRepo, service := setupTest(t)
+		mockRepo.On("GetWallet", ctx, walletID, userID).Return(types.Wallet{WalletID: walletID, Currency: "USD"}, nil)
+		mockRepo.On("GetLedgerBalance", ctx, walletID, userID, params.From).Return(42.5, nil)
+		mockRepo.On("ListLedgerEntries", ctx, walletID, userID, params.End(), params.From, int64(0), int32(2)).Return([]types.LedgerEntry{}, nil)
+
+		var rows []types.StatementRow
+		err := service.ExportStatement(ctx, walletID, userID, params, func(row types.StatementRow) error {
+			rows = append(rows, row)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []types.StatementRow{{Date: coreTypes.NewTimestamp(params.To), Description: "Closing balance", Balance: 42.5, Currency: "USD", Summary: true}}, rows)
+	})
+
+	t.Run("unknown wallet", func(t *testing.T) {
+		mockRepo, service := setupTest(t)
+		mockRepo.On("GetWallet", ctx, walletID, userID).Return(types.Wallet{}, coreErrors.NewNotFoundError("wallet not found"))
+
+		err := service.ExportStatement(ctx, walletID, userID, params, func(types.StatementRow) error {
+			t.Fatal("no rows expected")
+			return nil
+		})
+		assert.True(t, coreErrors.IsErrorType(err, coreErrors.ErrorTypeNotFound))
+		mockRepo.AssertNotCalled(t, "ListLedgerEntries")
+	})
+}
+
+func TestProjectBalance(t *testing.T) {
+	t.Run("matches the closed form of monthly compounding", func(t *testing.T) {
+		params := types.ProjectionParams{MonthlyContribution: 200, AnnualRate: 0.02, Months: 24}
+		projection := projectBalance(0, "EUR", params, validate.RoundHalfUp)
+
+		rate := params.AnnualRate / 12
+		expected := params.MonthlyContribution * (math.Pow(1+rate, float64(params.Months)) - 1) / rate
+		require.Len(t, projection.Months, 24)
+		assert.InDelta(t, expected, projection.FinalBalance, 0.12, "interest is rounded to cents every month")
+		assert.Equal(t, 4800.0, projection.TotalContributed)
+		assert.InDelta(t, projection.FinalBalance, projection.TotalContributed+projection.TotalInterest, 1e-9)
+		assert.Equal(t, projection.FinalBalance, projection.Months[23].Balance)
+	})
+
+	t.Run("rounds to the currency's minor unit", func(t *testing.T) {
+		projection := projectBalance(150, "JPY", types.ProjectionParams{AnnualRate: 0.12, Months: 2}, validate.RoundHalfUp)
+		// 1.5 yen rounds up, then 1% of 152 is 1.52 yen
+		assert.Equal(t, []types.ProjectionMonth{
+			{Month: 1, Interest: 2, Balance: 152},
+			{Month: 2, Interest: 2, Balance: 154},
+		}, projection.Months)
+	})
+
+	t.Run("half even rounding", func(t *testing.T) {
+		up := projectBalance(250, "EUR", types.ProjectionParams{AnnualRate: 0.0012, Months: 1}, validate.RoundHalfUp)
+		even := projectBalance(250, "EUR", types.ProjectionParams{AnnualRate: 0.0012, Months: 1}, validate.RoundHalfEven)
+		// 0.01% of 250.00 is half a cent
+		assert.Equal(t, 0.03, up.Months[0].Interest)
+		assert.Equal(t, 0.02, even.Months[0].Interest)
+	})
+
+	t.Run("withdrawals", func(t *testing.T) {
+		projection := projectBalance(100, "USD", types.ProjectionParams{MonthlyContribution: -60, Months: 2}, validate.RoundHalfUp)
+		assert.Equal(t, -20.0, projection.FinalBalance)
+		assert.Equal(t, -120.0, projection.TotalContributed)
+	})
+}
+
+func TestWalletService_Logging(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	walletID := uuid.New()
+
+	tests := []struct {
+		name  string
+		err   error
+		level zapcore.Level
+	}{
+		{name: "success", level: zapcore.DebugLevel},
+		{name: "failure", err: errors.New("database error"), level: zapcore.ErrorLevel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.DebugLevel)
+			mockRepo := new(mockWalletRepository)
+			service := NewWalletService(mockRepo, validate.RoundHalfUp, nil, nil, nil, zap.New(core))
+			mockRepo.On("GetWallet", ctx, walletID, userID).Return(types.Wallet{WalletID: walletID}, tt.err)
+
+			_, err := service.GetWallet(ctx, walletID, userID)
+			assert.Equal(t, tt.err, err)
+
+			require.Equal(t, 1, logs.Len())
+			entry := logs.All()[0]
+			assert.Equal(t, tt.level, entry.Level)
+
+			fields := entry.ContextMap()
+			assert.Equal(t, userID.String(), fields[logging.FieldUserID])
+			assert.Equal(t, "wallet", fields[logging.FieldEntityType])
+			assert.Equal(t, walletID.String(), fields[logging.FieldEntityID])
+			assert.Equal(t, "WalletService.GetWallet", fields[logging.FieldOperation])
+			assert.Contains(t, fields, logging.FieldDuration)
+			if tt.err != nil {
+				assert.Equal(t, logging.EventFailed, entry.Message)
+				assert.Equal(t, tt.err.Error(), fields["error"])
+			} else {
+				assert.Equal(t, logging.EventSucceeded, entry.Message)
+			}
+		})
+	}
+
+	t.Run("results learned on the way", func(t *testing.T) {
+		core, logs := observer.New(zapcore.DebugLevel)
+		mockRepo := new(mockWalletRepository)
+		service := NewWalletService(mockRepo, validate.RoundHalfUp, nil, nil, nil, zap.New(core))
+		projectID := uuid.New()
+		mockRepo.On("ProjectExists", ctx, userID, projectID).Return(true, nil)
+		mockRepo.On("CountOwnedWallets", ctx, userID, []uuid.UUID{walletID}).Return(int64(1), nil)
+		mockRepo.On("AttachWalletsToProject", ctx, userID, projectID, []uuid.UUID{walletID}).Return(int64(1), nil)
+
+		_, err := service.AttachWalletsToProject(ctx, userID, types.WalletAttachPayload{ProjectID: projectID, WalletIDs: []uuid.UUID{walletID}})
+		require.NoError(t, err)
+
+		require.Equal(t, 1, logs.Len())
+		fields := logs.All()[0].ContextMap()
+		assert.Equal(t, userID.String(), fields[logging.FieldUserID])
+		assert.Equal(t, "wallet", fields[logging.FieldEntityType])
+		assert.Equal(t, "WalletService.AttachWalletsToProject", fields[logging.FieldOperation])
+		assert.Equal(t, projectID.String(), fields["project_id"])
+		assert.EqualValues(t, 1, fields["attached"])
+	})
+}
@@ -0,0 +1,30 @@
+package types
+
+import (
+	"net/http"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+)
+
+// MaxAttachWallets caps the number of wallets a single attach request can move
+const MaxAttachWallets = 100
+
+// WalletAttachPayload represents the payload for moving wallets into a project in bulk
+// @Description Wallets to move into the project, every one of them must belong to the user
+type WalletAttachPayload struct {
+	ProjectID uuid.UUID   `json:"-" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"` // Set from URL parameter
+	WalletIDs []uuid.UUID `json:"walletIds" example:"123e4567-e89b-12d3-a456-426614174000,123e4567-e89b-12d3-a456-426614174001" minItems:"1" maxItems:"100"`
+}
+
+func (a *WalletAttachPayload) Bind(r *http.Request) error {
+	return validation.Errors{
+		"walletIds": validation.Validate(a.WalletIDs, validation.Required, validation.Length(1, MaxAttachWallets)),
+	}.Filter()
+}
+
+// WalletAttachResult reports how many wallets were moved into the project
+// @Description Number of wallets moved into the project; wallets already in it are not counted
+type WalletAttachResult struct {
+	Attached int64 `json:"attached" example:"3"`
+}
@@ -0,0 +1,71 @@
+package types
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/validate"
+	"github.com/google/uuid"
+)
+
+// Media types wallets are exported in
+const (
+	ExportFormatCSV  = "text/csv"
+	ExportFormatJSON = "application/json"
+)
+
+// ExportExtensions maps the media types of wallet exports to the extension of their files
+var ExportExtensions = map[string]string{
+	ExportFormatCSV:  "csv",
+	ExportFormatJSON: "json",
+}
+
+// CSVHeader is the header row of a wallets CSV export, in the column order of CSVRecord
+var CSVHeader = []string{
+	"wallet_id",
+	"name",
+	"currency",
+	"balance",
+	"low_balance_threshold",
+	"project_id",
+	"group_id",
+	"tags",
+	"created_at",
+	"updated_at",
+}
+
+// CSVRecord returns the wallet as a CSV row, amounts have the currency's decimals, empty
+// optional fields become empty cells and tag IDs are joined with semicolons
+func (w Wallet) CSVRecord() []string {
+	decimals := validate.CurrencyDecimals(w.Currency)
+	amount := func(value *float64) string {
+		if value == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*value, 'f', decimals, 64)
+	}
+	id := func(value *uuid.UUID) string {
+		if value == nil {
+			return ""
+		}
+		return value.String()
+	}
+
+	tags := make([]string, len(w.Tags))
+	for i, tag := range w.Tags {
+		tags[i] = tag.String()
+	}
+
+	return []string{
+		w.WalletID.String(),
+		w.Name,
+		w.Currency,
+		amount(w.Balance),
+		amount(w.LowBalanceThreshold),
+		id(w.ProjectID),
+		id(w.GroupID),
+		strings.Join(tags, ";"),
+		w.CreatedAt.String(),
+		w.UpdatedAt.String(),
+	}
+}
@@ -0,0 +1,57 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/validate"
+)
+
+// NetWorthQueryParams are the query parameters of the net worth
+var NetWorthQueryParams = []string{"include_archived", "convert_to"}
+
+// NetWorthParams choose which wallets the net worth sums and the currency it is converted to
+type NetWorthParams struct {
+	// IncludeArchived adds the wallets in the trash
+	IncludeArchived bool
+	// ConvertTo is the currency the totals are converted to and added up in, empty leaves them apart
+	ConvertTo string
+}
+
+// ParseNetWorthParams parses the include_archived flag and the convert_to currency of the net worth
+func ParseNetWorthParams(query url.Values) (NetWorthParams, error) {
+	params := NetWorthParams{IncludeArchived: query.Get("include_archived") == "true"}
+
+	if value := strings.ToUpper(strings.TrimSpace(query.Get("convert_to"))); value != "" {
+		if !slices.Contains(validate.CurrencyCodes(), value) {
+			return params, fmt.Errorf("convert_to: must be a valid currency code")
+		}
+		params.ConvertTo = value
+	}
+
+	return params, nil
+}
+
+// CurrencyTotal is the summed balance of the wallets of a currency
+type CurrencyTotal struct {
+	Currency    string  `json:"currency" example:"EUR"`
+	Total       float64 `json:"total" example:"1250.5"`
+	WalletCount int64   `json:"walletCount" example:"3"`
+}
+
+// ConvertedTotal is the net worth converted to a single currency, with the rate each
+// currency was converted at
+type ConvertedTotal struct {
+	Currency string             `json:"currency" example:"USD"`
+	Total    float64            `json:"total" example:"1450.58"`
+	Rates    map[string]float64 `json:"rates"`
+}
+
+// NetWorth is the summed balance of the user's wallets
+// @Description Summed wallet balances by currency, and their total in a single currency when converted
+type NetWorth struct {
+	Totals    []CurrencyTotal `json:"totals"`
+	Converted *ConvertedTotal `json:"converted,omitempty"` // set with convert_to
+}
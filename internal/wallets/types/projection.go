@@ -0,0 +1,94 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Abdelrahman-habib/expense-tracker/internal/validate"
+	"github.com/google/uuid"
+)
+
+// Bounds of a balance projection
+const (
+	MaxProjectionMonths     = 600
+	DefaultProjectionMonths = 12
+	MaxProjectionRate       = 1.0
+)
+
+// ProjectionQueryParams are the query parameters of a balance projection
+var ProjectionQueryParams = []string{"monthly_contribution", "annual_rate", "months"}
+
+// ProjectionParams describe how a wallet's balance evolves in a projection. AnnualRate is
+// a nominal rate compounded monthly, 0.02 for 2%, negative rates are fees. A negative
+// contribution is a monthly withdrawal.
+type ProjectionParams struct {
+	MonthlyContribution float64
+	AnnualRate          float64
+	Months              int
+}
+
+// ParseProjectionParams parses the monthly_contribution, annual_rate and months of a
+// projection, contributions default to 0, the rate to 0 and months to DefaultProjectionMonths
+func ParseProjectionParams(query url.Values) (ProjectionParams, error) {
+	params := ProjectionParams{Months: DefaultProjectionMonths}
+
+	if value := strings.TrimSpace(query.Get("monthly_contribution")); value != "" {
+		contribution, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return params, fmt.Errorf("monthly_contribution: must be a number")
+		}
+		if contribution < -validate.MaxAmount || contribution > validate.MaxAmount {
+			return params, fmt.Errorf("monthly_contribution: must be between %.2f and %.2f", -validate.MaxAmount, validate.MaxAmount)
+		}
+		params.MonthlyContribution = contribution
+	}
+
+	if value := strings.TrimSpace(query.Get("annual_rate")); value != "" {
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return params, fmt.Errorf("annual_rate: must be a number")
+		}
+		if rate < -MaxProjectionRate || rate > MaxProjectionRate {
+			return params, fmt.Errorf("annual_rate: must be between -1 and 1, 0.02 for 2%%")
+		}
+		params.AnnualRate = rate
+	}
+
+	if value := strings.TrimSpace(query.Get("months")); value != "" {
+		months, err := strconv.Atoi(value)
+		if err != nil {
+			return params, fmt.Errorf("months: must be a whole number")
+		}
+		if months < 1 || months > MaxProjectionMonths {
+			return params, fmt.Errorf("months: must be between 1 and %d", MaxProjectionMonths)
+		}
+		params.Months = months
+	}
+
+	return params, nil
+}
+
+// ProjectionMonth is a month of a balance projection, what was contributed and earned
+// during the month and the balance it ends with
+type ProjectionMonth struct {
+	Month       int     `json:"month" example:"1"`
+	Contributed float64 `json:"contributed" example:"200"`
+	Interest    float64 `json:"interest" example:"1.67"`
+	Balance     float64 `json:"balance" example:"1201.67"`
+}
+
+// Projection is a month by month projection of a wallet's balance
+// @Description Month by month projection of a wallet's balance with monthly contributions and interest
+type Projection struct {
+	WalletID            uuid.UUID         `json:"walletId" format:"uuid"`
+	Currency            string            `json:"currency" example:"EUR"`
+	StartingBalance     float64           `json:"startingBalance" example:"1000"`
+	MonthlyContribution float64           `json:"monthlyContribution" example:"200"`
+	AnnualRate          float64           `json:"annualRate" example:"0.02"`
+	Months              []ProjectionMonth `json:"months"`
+	TotalContributed    float64           `json:"totalContributed" example:"4800"`
+	TotalInterest       float64           `json:"totalInterest" example:"136.98"`
+	FinalBalance        float64           `json:"finalBalance" example:"5936.98"`
+}
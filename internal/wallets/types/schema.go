@@ -0,0 +1,34 @@
+package types
+
+import (
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/schema"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/validate"
+)
+
+// SchemaVersion is bumped whenever a wallet payload rule changes
+const SchemaVersion = 1
+
+// Schema describes the wallet create and update payloads, mirroring their Bind rules
+func Schema() schema.Entity {
+	return schema.Entity{
+		Name:    "wallets",
+		Title:   "Wallet payloads",
+		Version: SchemaVersion,
+		Create:  schema.Object(walletProperties(), "name", "currency"),
+		// the update is applied over the stored wallet so every field is optional
+		Update: schema.Object(walletProperties()),
+	}
+}
+
+func walletProperties() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"projectId": schema.UUID().Nullable(),
+		"groupId":   schema.UUID().Nullable(),
+		"name":      schema.String().Length(1, MaxNameLength),
+		"balance":   schema.Number().Min(0).Nullable(),
+		"currency":  schema.In(schema.String(), validate.CurrencyCodes()...),
+		"tags":      schema.Array(schema.UUID()).Count(0, MaxTagsCount).Nullable(),
+		"lowBalanceThreshold": schema.Number().Min(0).
+			Describe("limited to the decimal places of the currency").Nullable(),
+	}
+}
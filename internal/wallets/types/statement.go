@@ -0,0 +1,133 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/validate"
+	"github.com/google/uuid"
+)
+
+// Date formats of a statement
+const (
+	StatementFormatISO = "iso"
+	StatementFormatUS  = "us"
+	StatementFormatEU  = "eu"
+)
+
+// MaxStatementYears caps the range of a statement
+const MaxStatementYears = 2
+
+// statementDateLayouts maps each format to how its dates are written
+var statementDateLayouts = map[string]string{
+	StatementFormatISO: "2006-01-02",
+	StatementFormatUS:  "01/02/2006",
+	StatementFormatEU:  "02/01/2006",
+}
+
+// StatementCSVHeader is the header row of a statement, the columns bank statement
+// importers expect
+var StatementCSVHeader = []string{"date", "description", "debit", "credit", "balance"}
+
+// LedgerEntry is a change of a wallet's balance
+type LedgerEntry struct {
+	EntryID     uuid.UUID
+	Seq         int64
+	Amount      float64
+	Description string
+	OccurredAt  time.Time
+}
+
+// StatementParams are the range and date format of a statement, From and To are whole
+// days in UTC and both included
+type StatementParams struct {
+	From   time.Time
+	To     time.Time
+	Format string
+}
+
+// End returns the first instant after the statement's range
+func (p StatementParams) End() time.Time {
+	return p.To.AddDate(0, 0, 1)
+}
+
+// ParseStatementParams parses the from and to dates (YYYY-MM-DD) and the date format of
+// a statement, rejecting ranges over MaxStatementYears
+func ParseStatementParams(query url.Values) (StatementParams, error) {
+	params := StatementParams{Format: StatementFormatISO}
+
+	if format := strings.ToLower(strings.TrimSpace(query.Get("format"))); format != "" {
+		if _, ok := statementDateLayouts[format]; !ok {
+			return params, fmt.Errorf("format: must be one of %s, %s or %s", StatementFormatISO, StatementFormatUS, StatementFormatEU)
+		}
+		params.Format = format
+	}
+
+	var err error
+	if params.From, err = parseStatementDate(query, "from"); err != nil {
+		return params, err
+	}
+	if params.To, err = parseStatementDate(query, "to"); err != nil {
+		return params, err
+	}
+
+	if params.To.Before(params.From) {
+		return params, fmt.Errorf("to: must not be before from")
+	}
+	if params.End().After(params.From.AddDate(MaxStatementYears, 0, 0)) {
+		return params, fmt.Errorf("the range must not exceed %d years", MaxStatementYears)
+	}
+
+	return params, nil
+}
+
+func parseStatementDate(query url.Values, name string) (time.Time, error) {
+	value := strings.TrimSpace(query.Get(name))
+	if value == "" {
+		return time.Time{}, fmt.Errorf("%s: is required", name)
+	}
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: must be a date in YYYY-MM-DD format", name)
+	}
+	return date, nil
+}
+
+// StatementRow is a line of a wallet statement. Entries fill either Debit or Credit,
+// the closing Summary row carries the totals of both.
+type StatementRow struct {
+	Date        coreTypes.Timestamp `json:"date" swaggertype:"string" format:"date-time"`
+	Description string              `json:"description" example:"Groceries"`
+	Debit       float64             `json:"debit" example:"42.5"`
+	Credit      float64             `json:"credit" example:"0"`
+	Balance     float64             `json:"balance" example:"957.5"`
+	Currency    string              `json:"currency" example:"USD"`
+	Summary     bool                `json:"summary,omitempty"`
+}
+
+// CSVRecord returns the row in StatementCSVHeader order with dates in the given format
+// and amounts with the currency's decimals. Entries leave the unused side empty.
+func (r StatementRow) CSVRecord(format string) []string {
+	layout, ok := statementDateLayouts[format]
+	if !ok {
+		layout = statementDateLayouts[StatementFormatISO]
+	}
+	decimals := validate.CurrencyDecimals(r.Currency)
+	amount := func(value float64) string {
+		return strconv.FormatFloat(value, 'f', decimals, 64)
+	}
+
+	debit, credit := "", ""
+	if r.Summary || r.Debit != 0 {
+		debit = amount(r.Debit)
+	}
+	if r.Summary || r.Credit != 0 {
+		credit = amount(r.Credit)
+	}
+
+	return []string{r.Date.Format(layout), r.Description, debit, credit, amount(r.Balance)}
+}
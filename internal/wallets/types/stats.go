@@ -0,0 +1,22 @@
+package types
+
+// StatsAverageDays is the window the average daily spend of a wallet is taken over
+const StatsAverageDays = 30
+
+// WalletFlow is the money that left and entered a wallet over a window
+// @Description Outflow and inflow of a wallet over a window of days
+type WalletFlow struct {
+	Outflow float64 `json:"outflow" example:"340"`
+	Inflow  float64 `json:"inflow" example:"1200"`
+}
+
+// WalletStats sums a wallet's ledger entries over the trailing 7, 30 and 90 days. Days are
+// UTC calendar days whatever the user's timezone, each window ending with today.
+// @Description Spending of a wallet over the trailing 7, 30 and 90 UTC days, today included
+type WalletStats struct {
+	Last7Days  WalletFlow `json:"last7Days"`
+	Last30Days WalletFlow `json:"last30Days"`
+	Last90Days WalletFlow `json:"last90Days"`
+	// AverageDailySpend is the outflow of the last 30 days spread over StatsAverageDays
+	AverageDailySpend float64 `json:"averageDailySpend" example:"11.33"`
+}
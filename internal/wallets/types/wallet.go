@@ -1,9 +1,15 @@
 package types
 
 import (
+	"fmt"
 	"net/http"
-	"time"
+	"net/url"
+	"strconv"
+	"strings"
 
+	"github.com/Abdelrahman-habib/expense-tracker/internal/core/jsoncase"
+	coreTypes "github.com/Abdelrahman-habib/expense-tracker/internal/core/types"
+	"github.com/Abdelrahman-habib/expense-tracker/internal/validate"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
 	"github.com/google/uuid"
@@ -12,30 +18,52 @@ import (
 const (
 	MaxNameLength = 255
 	MaxTagsCount  = 10
+	// MaxPinnedWallets caps the wallets a user can pin
+	MaxPinnedWallets = 10
 )
 
 // Wallet represents the domain model for a wallet
 // @Description A wallet entity
 type Wallet struct {
-	WalletID  uuid.UUID   `json:"walletId" example:"123e4567-e89b-12d3-a456-426614174000"`
-	UserID    uuid.UUID   `json:"userId" example:"123e4567-e89b-12d3-a456-426614174000"`
-	ProjectID *uuid.UUID  `json:"projectId,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"`
-	Name      string      `json:"name" example:"My Wallet"`
-	Balance   *float64    `json:"balance,omitempty" example:"100.50"`
-	Currency  string      `json:"currency" example:"USD"`
-	Tags      []uuid.UUID `json:"tags,omitempty"`
-	CreatedAt time.Time   `json:"createdAt" example:"2023-01-01T00:00:00Z"`
-	UpdatedAt time.Time   `json:"updatedAt" example:"2023-01-01T00:00:00Z"`
+	WalletID            uuid.UUID            `json:"walletId" example:"123e4567-e89b-12d3-a456-426614174000"`
+	UserID              uuid.UUID            `json:"userId" example:"123e4567-e89b-12d3-a456-426614174000"`
+	ProjectID           *uuid.UUID           `json:"projectId,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"`
+	GroupID             *uuid.UUID           `json:"groupId,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Name                string               `json:"name" example:"My Wallet"`
+	Balance             *float64             `json:"balance,omitempty" example:"100.50"`
+	Currency            string               `json:"currency" example:"USD"`
+	Tags                []uuid.UUID          `json:"tags,omitempty"`
+	LowBalanceThreshold *float64             `json:"lowBalanceThreshold,omitempty" example:"20.00" minimum:"0"` // listed in alerts once the balance drops below it
+	CreatedAt           coreTypes.Timestamp  `json:"createdAt" example:"2023-01-01T00:00:00.000Z" swaggertype:"string" format:"date-time"`
+	UpdatedAt           coreTypes.Timestamp  `json:"updatedAt" example:"2023-01-01T00:00:00.000Z" swaggertype:"string" format:"date-time"`
+	DeletedAt           *coreTypes.Timestamp `json:"deletedAt,omitempty" example:"2023-01-02T00:00:00.000Z" swaggertype:"string" format:"date-time"`
+	Pinned              bool                 `json:"pinned" example:"false"` // pinned wallets are listed first
+	PinnedAt            *coreTypes.Timestamp `json:"pinnedAt,omitempty" example:"2023-01-03T00:00:00.000Z" swaggertype:"string" format:"date-time"`
+	CreatedBy           *uuid.UUID           `json:"createdBy,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"` // user who created the wallet
+	UpdatedBy           *uuid.UUID           `json:"updatedBy,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"` // user behind the last update
+	Stats               *WalletStats         `json:"stats,omitempty"`                                                    // set with include_stats=true
+	Score               *float32             `json:"score,omitempty" example:"0.83" minimum:"0" maximum:"1"`             // how well the name matches the query, set by searches with one
+}
+
+// WalletPickerItem is a wallet in the picker view of a search, only what a picker shows
+// @Description A wallet as listed by a picker
+type WalletPickerItem struct {
+	WalletID uuid.UUID `json:"walletId" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Name     string    `json:"name" example:"My Wallet"`
+	Balance  *float64  `json:"balance,omitempty" example:"100.50"`
+	Currency string    `json:"currency" example:"USD"`
 }
 
 // WalletCreatePayload represents the payload for creating a new wallet
 // @Description Request payload for creating a new wallet
 type WalletCreatePayload struct {
-	ProjectID *uuid.UUID  `json:"projectId,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"`
-	Name      string      `json:"name" example:"My Wallet" binding:"required"`
-	Balance   *float64    `json:"balance,omitempty" example:"100.50"`
-	Currency  string      `json:"currency" example:"USD" binding:"required"`
-	Tags      []uuid.UUID `json:"tags,omitempty"`
+	ProjectID           *uuid.UUID  `json:"projectId,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"`
+	GroupID             *uuid.UUID  `json:"groupId,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Name                string      `json:"name" example:"My Wallet" binding:"required"`
+	Balance             *float64    `json:"balance,omitempty" example:"100.50"`
+	Currency            string      `json:"currency" example:"USD" binding:"required"`
+	Tags                []uuid.UUID `json:"tags,omitempty"`
+	LowBalanceThreshold *float64    `json:"lowBalanceThreshold,omitempty" example:"20.00" minimum:"0"`
 }
 
 // Bind implements render.Binder interface and validates the create wallet payload
@@ -45,17 +73,32 @@ func (c *WalletCreatePayload) Bind(r *http.Request) error {
 		"currency": validation.Validate(c.Currency, validation.Required, is.CurrencyCode), // ISO 4217 currency codes are 3 characters
 		"balance":  validation.Validate(c.Balance, validation.When(c.Balance != nil, validation.Min(0.0).Error("balance must be non-negative"))),
 		"tags":     validation.Validate(c.Tags, validation.Length(0, MaxTagsCount)),
+		"low_balance_threshold": validation.Validate(c.LowBalanceThreshold, validation.When(c.LowBalanceThreshold != nil,
+			validation.Min(0.0).Error("low balance threshold must be non-negative"),
+			validate.CurrencyPrecision(c.Currency),
+		)),
 	}.Filter()
 }
 
+// walletCreateAliases maps the snake_case field names of WalletCreatePayload to their camelCase name
+var walletCreateAliases = jsoncase.AliasesOf(WalletCreatePayload{})
+
+// UnmarshalJSON accepts the snake_case field names older clients send too
+func (c *WalletCreatePayload) UnmarshalJSON(data []byte) error {
+	type payload WalletCreatePayload
+	return walletCreateAliases.Unmarshal(data, (*payload)(c))
+}
+
 // WalletUpdatePayload represents the payload for updating an existing wallet
 type WalletUpdatePayload struct {
-	WalletID  uuid.UUID   `json:"-"` // Not part of JSON, set from URL
-	ProjectID *uuid.UUID  `json:"projectId,omitempty"`
-	Name      string      `json:"name"`
-	Balance   *float64    `json:"balance,omitempty"`
-	Currency  string      `json:"currency"`
-	Tags      []uuid.UUID `json:"tags,omitempty"`
+	WalletID            uuid.UUID   `json:"-"` // Not part of JSON, set from URL
+	ProjectID           *uuid.UUID  `json:"projectId,omitempty"`
+	GroupID             *uuid.UUID  `json:"groupId,omitempty"`
+	Name                string      `json:"name"`
+	Balance             *float64    `json:"balance,omitempty"`
+	Currency            string      `json:"currency"`
+	Tags                []uuid.UUID `json:"tags,omitempty"`
+	LowBalanceThreshold *float64    `json:"lowBalanceThreshold,omitempty"`
 }
 
 // Bind implements render.Binder interface and validates the update wallet payload
@@ -65,17 +108,182 @@ func (u *WalletUpdatePayload) Bind(r *http.Request) error {
 		"currency": validation.Validate(u.Currency, validation.Required, is.CurrencyCode),
 		"balance":  validation.Validate(u.Balance, validation.When(u.Balance != nil, validation.Min(0.0).Error("balance must be non-negative"))),
 		"tags":     validation.Validate(u.Tags, validation.Length(0, MaxTagsCount)),
+		"low_balance_threshold": validation.Validate(u.LowBalanceThreshold, validation.When(u.LowBalanceThreshold != nil,
+			validation.Min(0.0).Error("low balance threshold must be non-negative"),
+			validate.CurrencyPrecision(u.Currency),
+		)),
 	}.Filter()
 }
 
+// walletUpdateAliases maps the snake_case field names of WalletUpdatePayload to their camelCase name
+var walletUpdateAliases = jsoncase.AliasesOf(WalletUpdatePayload{})
+
+// UnmarshalJSON accepts the snake_case field names older clients send too
+func (u *WalletUpdatePayload) UnmarshalJSON(data []byte) error {
+	type payload WalletUpdatePayload
+	return walletUpdateAliases.Unmarshal(data, (*payload)(u))
+}
+
 // ToUpdatePayload converts a Wallet to WalletUpdatePayload
 func (w *Wallet) ToUpdatePayload() WalletUpdatePayload {
 	return WalletUpdatePayload{
-		WalletID:  w.WalletID,
-		ProjectID: w.ProjectID,
-		Name:      w.Name,
-		Balance:   w.Balance,
-		Currency:  w.Currency,
-		Tags:      w.Tags,
+		WalletID:            w.WalletID,
+		ProjectID:           w.ProjectID,
+		GroupID:             w.GroupID,
+		Name:                w.Name,
+		Balance:             w.Balance,
+		Currency:            w.Currency,
+		Tags:                w.Tags,
+		LowBalanceThreshold: w.LowBalanceThreshold,
+	}
+}
+
+// ListQueryParams lists the query parameters accepted when listing wallets
+var ListQueryParams = append([]string{"group_id", "tags", "tag_match", "currency", "has_project"}, coreTypes.PaginationQueryParams...)
+
+// SearchQueryParams lists the query parameters accepted when searching wallets
+var SearchQueryParams = append([]string{coreTypes.ViewParam, coreTypes.TrimParam}, coreTypes.SearchQueryParams...)
+
+// ungroupedFilter is the group_id value listing the wallets outside any group
+const ungroupedFilter = "none"
+
+// Values of the tag_match query parameter
+const (
+	TagMatchAny = "any"
+	TagMatchAll = "all"
+)
+
+// FacetFields are the wallet fields the distinct values of can be counted
+var FacetFields = []string{"currency"}
+
+// WalletFilter narrows a wallet list, the zero value lists every wallet. The filters
+// set all have to match.
+type WalletFilter struct {
+	// GroupID limits the list to the wallets of the group
+	GroupID *uuid.UUID
+	// Ungrouped limits the list to the wallets outside any group
+	Ungrouped bool
+	// Tags limits the list to the wallets carrying any of the tags, all of them with MatchAllTags
+	Tags         []uuid.UUID
+	MatchAllTags bool
+	// Currency limits the list to the wallets holding the currency
+	Currency *string
+	// HasProject limits the list to the wallets inside a project when true, outside any when false
+	HasProject *bool
+}
+
+// ParseWalletFilter parses the filter query parameters of the wallet list: group_id, a
+// group ID or "none" for ungrouped wallets, tags, comma separated tag IDs matched with
+// tag_match any (the default) or all, currency and has_project. Blank ones don't filter.
+func ParseWalletFilter(query url.Values) (WalletFilter, error) {
+	var filter WalletFilter
+
+	switch value := strings.TrimSpace(query.Get("group_id")); {
+	case value == "":
+	case strings.EqualFold(value, ungroupedFilter):
+		filter.Ungrouped = true
+	default:
+		groupID, err := uuid.Parse(value)
+		if err != nil {
+			return WalletFilter{}, fmt.Errorf("group_id: must be a group ID or %q", ungroupedFilter)
+		}
+		filter.GroupID = &groupID
+	}
+
+	tags, err := parseTagsFilter(query.Get("tags"))
+	if err != nil {
+		return WalletFilter{}, err
 	}
+	filter.Tags = tags
+
+	switch match := strings.ToLower(strings.TrimSpace(query.Get("tag_match"))); match {
+	case "", TagMatchAny:
+	case TagMatchAll:
+		filter.MatchAllTags = true
+	default:
+		return WalletFilter{}, fmt.Errorf("tag_match: must be %q or %q", TagMatchAny, TagMatchAll)
+	}
+	if query.Get("tag_match") != "" && len(filter.Tags) == 0 {
+		return WalletFilter{}, fmt.Errorf("tag_match: requires tags")
+	}
+
+	if currency := strings.ToUpper(strings.TrimSpace(query.Get("currency"))); currency != "" {
+		if err := is.CurrencyCode.Validate(currency); err != nil {
+			return WalletFilter{}, fmt.Errorf("currency: must be an ISO 4217 currency code")
+		}
+		filter.Currency = &currency
+	}
+
+	if value := strings.TrimSpace(query.Get("has_project")); value != "" {
+		hasProject, err := strconv.ParseBool(value)
+		if err != nil {
+			return WalletFilter{}, fmt.Errorf("has_project: must be true or false")
+		}
+		filter.HasProject = &hasProject
+	}
+
+	return filter, nil
+}
+
+// parseTagsFilter parses the comma separated tag IDs of the tags query parameter,
+// dropping repeats, at most MaxTagsCount as a wallet can't carry more
+func parseTagsFilter(value string) ([]uuid.UUID, error) {
+	var tags []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		tagID, err := uuid.Parse(part)
+		if err != nil {
+			return nil, fmt.Errorf("tags: %q is not a valid tag ID", part)
+		}
+		if !seen[tagID] {
+			seen[tagID] = true
+			tags = append(tags, tagID)
+		}
+	}
+	if len(tags) > MaxTagsCount {
+		return nil, fmt.Errorf("tags: at most %d tags can be filtered on", MaxTagsCount)
+	}
+	return tags, nil
+}
+
+// ExpandQueryParam names the related resources to embed in a wallet list, it doesn't
+// change which wallets are listed so next_token isn't bound to it
+const ExpandQueryParam = "expand"
+
+// WalletExpand lists the related resources to include in a wallet list
+type WalletExpand struct {
+	Project bool
+}
+
+// ParseWalletExpand parses the comma separated expand query parameter, project is the
+// only resource that expands
+func ParseWalletExpand(query url.Values) (WalletExpand, error) {
+	var expand WalletExpand
+	for _, name := range strings.Split(query.Get(ExpandQueryParam), ",") {
+		switch strings.TrimSpace(name) {
+		case "":
+		case "project":
+			expand.Project = true
+		default:
+			return expand, fmt.Errorf("expand: %q can't be expanded, expected project", name)
+		}
+	}
+	return expand, nil
+}
+
+// WalletProject is the project of a wallet embedded with expand=project
+type WalletProject struct {
+	ProjectID   uuid.UUID `json:"projectId" example:"123e4567-e89b-12d3-a456-426614174000" format:"uuid"`
+	ProjectName string    `json:"projectName" example:"Home renovation"`
+}
+
+// WalletWithProject is a wallet listed with expand=project
+// @Description A wallet with its project embedded, null when the wallet is outside any project
+type WalletWithProject struct {
+	Wallet
+	Project *WalletProject `json:"project"`
 }
@@ -3,6 +3,7 @@ package requestcontext
 import (
 	"context"
 	"errors"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,6 +20,27 @@ const (
 
 	// UserIDKey is the context key for db User ID
 	UserIDKey RequestContextKey = "userID"
+
+	// QueryParamsModeKey is the context key for how unknown query parameters are handled
+	QueryParamsModeKey RequestContextKey = "queryParamsMode"
+
+	// WarningsKey is the context key for the warnings collected for the response meta
+	WarningsKey RequestContextKey = "warnings"
+
+	// MaxSearchWindowKey is the context key for how many results deep search pagination may go
+	MaxSearchWindowKey RequestContextKey = "maxSearchWindow"
+
+	// SearchTimeoutKey is the context key for how long a search may run
+	SearchTimeoutKey RequestContextKey = "searchTimeout"
+
+	// RejectEmptyUpdatesKey is the context key for whether updates naming no field are refused
+	RejectEmptyUpdatesKey RequestContextKey = "rejectEmptyUpdates"
+
+	// ClientIPKey is the context key for the client address resolved behind trusted proxies
+	ClientIPKey RequestContextKey = "clientIP"
+
+	// RequestCacheKey is the context key for the repository results memoized during the request
+	RequestCacheKey RequestContextKey = "requestCache"
 )
 
 func GetUserIDFromContext(ctx context.Context) (uuid.UUID, error) {
@@ -29,6 +51,15 @@ func GetUserIDFromContext(ctx context.Context) (uuid.UUID, error) {
 	return userID, nil
 }
 
+// GetActorIDFromContext returns the user making the request, falling back to owner when
+// the context carries none like in background jobs acting on the owner's behalf
+func GetActorIDFromContext(ctx context.Context, owner uuid.UUID) uuid.UUID {
+	if actorID, ok := ctx.Value(UserIDKey).(uuid.UUID); ok && actorID != uuid.Nil {
+		return actorID
+	}
+	return owner
+}
+
 func GetRequestIDFromContext(ctx context.Context) (uuid.UUID, error) {
 	requestID, ok := ctx.Value(RequestIDKey).(uuid.UUID)
 	if !ok {
@@ -44,3 +75,72 @@ func GetStartTimeFromContext(ctx context.Context) (time.Time, error) {
 	}
 	return startTime, nil
 }
+
+func GetClientIPFromContext(ctx context.Context) (string, error) {
+	clientIP, ok := ctx.Value(ClientIPKey).(string)
+	if !ok {
+		return "", errors.New("missing client ip from context")
+	}
+	return clientIP, nil
+}
+
+// GetQueryParamsModeFromContext returns how unknown query parameters are handled for the request,
+// empty when no mode was set
+func GetQueryParamsModeFromContext(ctx context.Context) string {
+	mode, _ := ctx.Value(QueryParamsModeKey).(string)
+	return mode
+}
+
+// warnings collects the warnings raised while handling a request
+type warnings struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+// WithWarnings returns a context that collects warnings for the response
+func WithWarnings(ctx context.Context) context.Context {
+	return context.WithValue(ctx, WarningsKey, &warnings{})
+}
+
+// AddWarning records a warning for the response, it reports false when the context
+// doesn't collect warnings
+func AddWarning(ctx context.Context, message string) bool {
+	w, ok := ctx.Value(WarningsKey).(*warnings)
+	if !ok {
+		return false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.messages = append(w.messages, message)
+	return true
+}
+
+// GetWarningsFromContext returns the warnings recorded so far
+func GetWarningsFromContext(ctx context.Context) []string {
+	w, ok := ctx.Value(WarningsKey).(*warnings)
+	if !ok {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.messages...)
+}
+
+// GetRejectEmptyUpdatesFromContext reports whether an update body naming no field, the
+// empty object, is refused rather than kept as an update changing nothing
+func GetRejectEmptyUpdatesFromContext(ctx context.Context) bool {
+	reject, _ := ctx.Value(RejectEmptyUpdatesKey).(bool)
+	return reject
+}
+
+// GetMaxSearchWindowFromContext returns the configured search window, ok is false when none was set
+func GetMaxSearchWindowFromContext(ctx context.Context) (int32, bool) {
+	window, ok := ctx.Value(MaxSearchWindowKey).(int32)
+	return window, ok
+}
+
+// GetSearchTimeoutFromContext returns the configured search timeout, ok is false when none was set
+func GetSearchTimeoutFromContext(ctx context.Context) (time.Duration, bool) {
+	timeout, ok := ctx.Value(SearchTimeoutKey).(time.Duration)
+	return timeout, ok
+}
@@ -0,0 +1,67 @@
+package requestcontext
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWarnings_KeepTheOrderAdded(t *testing.T) {
+	ctx := WithWarnings(context.Background())
+
+	assert.True(t, AddWarning(ctx, "first"))
+	assert.True(t, AddWarning(ctx, "second"))
+	assert.True(t, AddWarning(ctx, "third"))
+
+	assert.Equal(t, []string{"first", "second", "third"}, GetWarningsFromContext(ctx))
+}
+
+func TestWarnings_ConcurrentAppends(t *testing.T) {
+	ctx := WithWarnings(context.Background())
+
+	const goroutines, perGoroutine = 16, 50
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				AddWarning(ctx, fmt.Sprintf("%d-%d", g, i))
+				GetWarningsFromContext(ctx)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	warnings := GetWarningsFromContext(ctx)
+	assert.Len(t, warnings, goroutines*perGoroutine)
+	// each goroutine's warnings stay in the order it added them
+	next := make(map[int]int)
+	for _, warning := range warnings {
+		var g, i int
+		_, err := fmt.Sscanf(warning, "%d-%d", &g, &i)
+		assert.NoError(t, err)
+		assert.Equal(t, next[g], i, warning)
+		next[g] = i + 1
+	}
+}
+
+func TestWarnings_WithoutCollector(t *testing.T) {
+	ctx := context.Background()
+
+	assert.False(t, AddWarning(ctx, "dropped"))
+	assert.Nil(t, GetWarningsFromContext(ctx))
+}
+
+func TestWarnings_ReturnsACopy(t *testing.T) {
+	ctx := WithWarnings(context.Background())
+	AddWarning(ctx, "kept")
+
+	warnings := GetWarningsFromContext(ctx)
+	warnings[0] = "changed"
+
+	assert.Equal(t, []string{"kept"}, GetWarningsFromContext(ctx))
+}